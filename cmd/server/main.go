@@ -4,16 +4,26 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/events"
+	"github.com/luno/luno-mcp/internal/limiter"
 	"github.com/luno/luno-mcp/internal/logging"
+	"github.com/luno/luno-mcp/internal/loopguard"
+	"github.com/luno/luno-mcp/internal/orders"
+	"github.com/luno/luno-mcp/internal/redact"
 	"github.com/luno/luno-mcp/internal/server"
+	"github.com/luno/luno-mcp/sdk"
 	mcpserver "github.com/mark3labs/mcp-go/server"
 )
 
@@ -22,13 +32,58 @@ const (
 	appVersion = "0.1.0"
 )
 
+// envLogFormat sets the log format when --log-format is not given
+const envLogFormat = "LOG_FORMAT"
+
+// defaultHTTPAddr is where the http transport listens without --sse-address:
+// every interface, as it is meant to be run in a container with the port
+// published
+const defaultHTTPAddr = ":8080"
+
 // CliFlags holds command line flag values
 type CliFlags struct {
 	TransportType        string
 	SSEAddr              string
+	StdioPingInterval    time.Duration
 	LunoDomain           string
 	LogLevel             string
+	LogFormat            string
 	AllowWriteOperations bool
+	AllowWithdrawals     bool
+	HideUnavailableTools bool
+	Locale               string
+	OutputFormat         string
+	WebhookURL           string
+	AdminAddr            string
+	CredentialsProvider  string
+	ReferencePriceURL    string
+	TelemetryURL         string
+	DisableUpdateCheck   bool
+	TradeJournalPath     string
+	AccountAliasesPath   string
+	TrackedOrdersPath    string
+	ImportedTradesPath   string
+	CandleCacheDir       string
+	DataDir              string
+	StorageEncryption    string
+	DepositAlerts        string
+	TradingWindows       string
+	TradingTimezone      string
+	TradingPairs         string
+	Chaos                string
+	ApprovalThresholds   string
+	ApprovalCommand      string
+	AuditLogPath         string
+	AuditSigningKey      string
+	VerifyAuditLog       string
+	MaxResponseBytes     int
+	MaxPriceDeviation    float64
+	DuplicateOrderWindow time.Duration
+	MaxConcurrentCalls   int
+	MaxCallsPerTool      int
+	CallQueueTimeout     time.Duration
+	RepeatCallThreshold  int
+	RepeatCallWindow     time.Duration
 }
 
 // loadEnvFile attempts to load environment variables from various .env file locations
@@ -57,44 +112,156 @@ func loadEnvFile() bool {
 
 // parseFlags parses command line flags and returns CliFlags struct
 func parseFlags() CliFlags {
-	transportType := flag.String("transport", "streamable-http", "Transport type (stdio, sse, or streamable-http)")
-	sseAddr := flag.String("sse-address", "localhost:8080", "Address for SSE and Streamable HTTP transports")
+	transportType := flag.String("transport", "streamable-http", "Transport type (stdio, sse, streamable-http, or http for running in a container)")
+	sseAddr := flag.String("sse-address", "localhost:8080", "Address for the SSE, Streamable HTTP and HTTP transports (default for http: :8080)")
+	stdioPingInterval := flag.Duration("stdio-ping-interval", 0, "How often to ping the client over the stdio transport, to notice a client that has gone away between requests; 0 sends no pings")
 	lunoDomain := flag.String("domain", "", "Luno API domain (default: api.luno.com)")
 	logLevel := flag.String("log-level", "info", "Log level (debug, info, warn, error)")
-	allowWriteOps := flag.Bool("allow-write-operations", false, "Enable write operations (create_order, cancel_order). Also settable via ALLOW_WRITE_OPERATIONS env var")
+	logFormat := flag.String("log-format", "", "Log format: text (default) or json. Also settable via LOG_FORMAT env var")
+	allowWriteOps := flag.Bool("allow-write-operations", false, "Enable write operations (create_order, cancel_order, replace_order, execute_twap, iceberg_order, create_orders_batch, create_market_order, undo_last_action). Also settable via ALLOW_WRITE_OPERATIONS env var")
+	allowWithdrawals := flag.Bool("allow-withdrawals", false, "Enable fiat withdrawals (create_fiat_withdrawal); also requires --allow-write-operations. Also settable via ALLOW_WITHDRAWALS env var")
+	hideUnavailableTools := flag.Bool("hide-unavailable-tools", false, "Leave tools that need API credentials out of the tool list when there are none, rather than marking them unavailable. Also settable via HIDE_UNAVAILABLE_TOOLS env var")
+	locale := flag.String("locale", "", "Language of tool descriptions and messages: en (default), id or ms. Also settable via LOCALE env var")
+	outputFormat := flag.String("output-format", "", "Format of tool results that calls do not choose one for: json (default), yaml, csv or markdown. Also settable via OUTPUT_FORMAT env var")
+	adminAddr := flag.String("admin-address", "", "Address to serve the admin API on, for inspecting and revoking sessions and changing limits; requests must carry the ADMIN_TOKEN bearer token (default: off). Also settable via ADMIN_ADDR env var")
+	credentialsProvider := flag.String("credentials-provider", "", "Fetch each session's Luno API credentials for the tenant in its X-Tenant-ID header from a secrets store: vault://mount/path or aws-secretsmanager://name, containing {tenant} (default: off). Also settable via CREDENTIALS_PROVIDER env var")
+	webhookURL := flag.String("webhook-url", "", "URL to POST account events to. Also settable via WEBHOOK_URL env var; the signing secret is read from WEBHOOK_SECRET")
+	disableUpdateCheck := flag.Bool("disable-update-check", false, "Never check GitHub for newer releases, at startup or with check_updates, for environments without outbound network access. Also settable via DISABLE_UPDATE_CHECK env var")
+	telemetryURL := flag.String("telemetry-url", "", "Opt in to sending anonymous tool call and error category counts to this URL once a day (default: off). Also settable via TELEMETRY_URL env var")
+	referencePriceURL := flag.String("reference-price-url", "", "URL of a JSON price feed that get_price_premium compares Luno prices with; {pair}, {base} and {counter} are replaced with the market. Also settable via REFERENCE_PRICE_URL env var")
+	tradeJournalPath := flag.String("trade-journal", "", "File to keep trade journal notes in (default: trade-journal.jsonl in the user config directory). Also settable via TRADE_JOURNAL_PATH env var")
+	accountAliasesPath := flag.String("account-aliases", "", "File to keep account aliases in (default: account-aliases.json in the user config directory). Also settable via ACCOUNT_ALIASES_PATH env var")
+	trackedOrdersPath := flag.String("tracked-orders", "", "File to keep the orders placed through the server in, to check them after a restart (default: orders.json in the user config directory). Also settable via TRACKED_ORDERS_PATH env var")
+	importedTradesPath := flag.String("imported-trades", "", "File to keep the trades imported with import_trades in (default: imported-trades.json in the user config directory). Also settable via IMPORTED_TRADES_PATH env var")
+	candleCacheDir := flag.String("candle-cache", "", "Directory to cache the candles fetched for analysis in (default: luno-mcp/candles in the user cache directory). Also settable via CANDLE_CACHE_DIR env var")
+	dataDir := flag.String("data-dir", "", "Directory to keep the trade journal, account aliases, tracked orders, imported trades and candle cache in, unless given paths of their own (default: the user config and cache directories). Also settable via LUNO_MCP_DATA_DIR env var")
+	storageEncryption := flag.String("storage-encryption", "", "Encrypt the trade journal, account aliases, tracked orders and imported trades with a key from a passphrase (STORAGE_PASSPHRASE) or the system keychain: off, passphrase or keychain (default: off). Also settable via STORAGE_ENCRYPTION env var")
+	depositAlerts := flag.String("deposit-alerts", "", "Notify when deposits arrive, as comma-separated assets with optional minimum amounts such as XBT:0.01,ZAR:500, or * for every asset. Also settable via DEPOSIT_ALERTS env var")
+	tradingWindows := flag.String("trading-windows", "", "Only allow trading and withdrawal tools in these windows, separated by semicolons, such as \"Mon-Fri 06:00-24:00\" (default: any time). Also settable via TRADING_WINDOWS env var")
+	tradingTimezone := flag.String("trading-timezone", "", "IANA time zone the trading windows are in, such as Africa/Johannesburg (default: UTC). Also settable via TRADING_TIMEZONE env var")
+	tradingPairs := flag.String("trading-pairs", "", "Only place orders on these comma-separated pairs, such as XBTZAR,ETHZAR (default: any pair). Also settable via TRADING_PAIRS env var")
+	chaos := flag.String("chaos", "", "Test mode that delays Luno API calls and fails some of them, such as latency=200ms-2s,rate_limit=0.1,errors=0.05, or on for defaults. Never use with real funds. Also settable via CHAOS env var")
+	approvalThresholds := flag.String("approval-thresholds", "", "Hold orders and withdrawals worth more than these comma-separated amounts, such as ZAR:50000,XBT:0.5, until an approver approves them (default: off). Also settable via APPROVAL_THRESHOLDS env var")
+	approvalCommand := flag.String("approval-command", "", "Command that decides actions held for approval: it reads the request as JSON and exits 0 to approve or 1 to reject. Also settable via APPROVAL_COMMAND env var")
+	auditLogPath := flag.String("audit-log", "", "File to keep the hash-chained audit log of tool calls in, as well as in memory (default: off). Also settable via AUDIT_LOG_PATH env var")
+	auditSigningKey := flag.String("audit-signing-key", "", "PEM file with an Ed25519 private key to sign each audit log entry with, or with its public key for --verify-audit-log. Also settable via AUDIT_SIGNING_KEY_PATH env var")
+	verifyAuditLog := flag.String("verify-audit-log", "", "Verify the hash chain, and with --audit-signing-key the signatures, of this audit log file, then exit")
+	maxResponseBytes := flag.Int("max-response-bytes", config.DefaultMaxResponseBytes, "Truncate tool results larger than this many bytes, returning a cursor for fetch_more; 0 disables truncation. Also settable via MAX_RESPONSE_BYTES env var")
+	maxConcurrentCalls := flag.Int("max-concurrent-calls", limiter.DefaultMaxCalls, "Maximum number of tool calls that run at once; 0 for no limit. Also settable via MAX_CONCURRENT_CALLS env var")
+	maxCallsPerTool := flag.Int("max-concurrent-calls-per-tool", limiter.DefaultMaxCallsPerTool, "Maximum number of calls to one tool that run at once; 0 for no limit. Also settable via MAX_CONCURRENT_CALLS_PER_TOOL env var")
+	callQueueTimeout := flag.Duration("call-queue-timeout", limiter.DefaultQueueTimeout, "How long tool calls over a concurrency limit wait for a slot; 0 waits indefinitely. Also settable via CALL_QUEUE_TIMEOUT env var")
+	maxPriceDeviation := flag.Float64("max-price-deviation", config.DefaultMaxPriceDeviation, "Percentage a limit price can be from the market price before the order needs allow_far_from_market=true; 0 disables the check. Also settable via MAX_PRICE_DEVIATION env var")
+	duplicateOrderWindow := flag.Duration("duplicate-order-window", config.DefaultDuplicateOrderWindow, "How long after an order is placed a create_order with the same pair, side, price and volume needs allow_duplicate=true, as do matches of open orders; 0 disables the check. Also settable via DUPLICATE_ORDER_WINDOW env var")
+	repeatCallThreshold := flag.Int("repeat-call-threshold", loopguard.DefaultThreshold, "Identical tool calls a session can make within the repeat call window before the previous result is reused; 0 disables. Also settable via REPEAT_CALL_THRESHOLD env var")
+	repeatCallWindow := flag.Duration("repeat-call-window", loopguard.DefaultWindow, "How long identical tool calls are counted for. Also settable via REPEAT_CALL_WINDOW env var")
 	flag.Parse()
 
 	return CliFlags{
 		TransportType:        *transportType,
 		SSEAddr:              *sseAddr,
+		StdioPingInterval:    *stdioPingInterval,
 		LunoDomain:           *lunoDomain,
 		LogLevel:             *logLevel,
+		LogFormat:            *logFormat,
 		AllowWriteOperations: *allowWriteOps,
+		AllowWithdrawals:     *allowWithdrawals,
+		HideUnavailableTools: *hideUnavailableTools,
+		Locale:               *locale,
+		OutputFormat:         *outputFormat,
+		WebhookURL:           *webhookURL,
+		AdminAddr:            *adminAddr,
+		CredentialsProvider:  *credentialsProvider,
+		ReferencePriceURL:    *referencePriceURL,
+		TelemetryURL:         *telemetryURL,
+		DisableUpdateCheck:   *disableUpdateCheck,
+		TradeJournalPath:     *tradeJournalPath,
+		AccountAliasesPath:   *accountAliasesPath,
+		TrackedOrdersPath:    *trackedOrdersPath,
+		ImportedTradesPath:   *importedTradesPath,
+		CandleCacheDir:       *candleCacheDir,
+		DataDir:              *dataDir,
+		StorageEncryption:    *storageEncryption,
+		DepositAlerts:        *depositAlerts,
+		TradingWindows:       *tradingWindows,
+		TradingTimezone:      *tradingTimezone,
+		TradingPairs:         *tradingPairs,
+		Chaos:                *chaos,
+		ApprovalThresholds:   *approvalThresholds,
+		ApprovalCommand:      *approvalCommand,
+		AuditLogPath:         *auditLogPath,
+		AuditSigningKey:      *auditSigningKey,
+		VerifyAuditLog:       *verifyAuditLog,
+		MaxResponseBytes:     *maxResponseBytes,
+		MaxPriceDeviation:    *maxPriceDeviation,
+		DuplicateOrderWindow: *duplicateOrderWindow,
+		MaxConcurrentCalls:   *maxConcurrentCalls,
+		MaxCallsPerTool:      *maxCallsPerTool,
+		CallQueueTimeout:     *callQueueTimeout,
+		RepeatCallThreshold:  *repeatCallThreshold,
+		RepeatCallWindow:     *repeatCallWindow,
+	}
+}
+
+// mcpLogLevel is the level of log notifications sent to MCP clients. Clients
+// can change it with logging/setLevel.
+var mcpLogLevel = new(slog.LevelVar)
+
+// consoleOutput returns where to write logs for the transport. The stdio
+// transport's stdout carries its messages to the client, so logs go to stderr.
+func consoleOutput(transport string) io.Writer {
+	if transport == "stdio" {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// resolveLogFormat returns the log format to use: the flag if set, then the
+// LOG_FORMAT environment variable, then text
+func resolveLogFormat(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	if v := os.Getenv(envLogFormat); v != "" {
+		return v
 	}
+	return "text"
+}
+
+// newConsoleHandler returns a handler writing logs to out as JSON, one object a
+// line, for log collectors, or as text otherwise
+func newConsoleHandler(logFormat string, out io.Writer, level slog.Level) slog.Handler {
+	opts := &slog.HandlerOptions{Level: level}
+	if logFormat == "json" {
+		return slog.NewJSONHandler(out, opts)
+	}
+	return slog.NewTextHandler(out, opts)
 }
 
 // setupLogger creates and configures the basic console logger
-func setupLogger(logLevel string) *slog.Logger {
+func setupLogger(logLevel, logFormat string, out io.Writer) *slog.Logger {
 	level := parseLogLevel(logLevel)
-	consoleHandler := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: level})
-	logger := slog.New(consoleHandler)
+	consoleHandler := newConsoleHandler(logFormat, out, level)
+	logger := slog.New(logging.NewContextHandler(redact.NewHandler(consoleHandler)))
 	slog.SetDefault(logger)
 	return logger
 }
 
 // setupEnhancedLogger creates an enhanced logger with MCP notification capability
-func setupEnhancedLogger(mcpServer *mcpserver.MCPServer, logLevel string) {
+func setupEnhancedLogger(mcpServer *mcpserver.MCPServer, logLevel, logFormat string, out io.Writer) {
 	level := parseLogLevel(logLevel)
-	consoleHandler := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: level})
-	mcpHandler := logging.NewMCPNotificationHandler(mcpServer, level)
+	consoleHandler := newConsoleHandler(logFormat, out, level)
+	mcpLogLevel.Set(level)
+	mcpHandler := logging.NewMCPNotificationHandler(mcpServer, mcpLogLevel)
 	multiHandler := logging.NewMultiHandler(consoleHandler, mcpHandler)
-	enhancedLogger := slog.New(multiHandler)
+	enhancedLogger := slog.New(logging.NewContextHandler(redact.NewHandler(multiHandler)))
 	slog.SetDefault(enhancedLogger)
 }
 
 // createMCPServer creates and configures the MCP server
 func createMCPServer(cfg *config.Config) *mcpserver.MCPServer {
-	return server.NewMCPServer(appName, appVersion, cfg, logging.MCPHooks())
+	hooks := logging.MCPHooks()
+	hooks.AddAfterSetLevel(logging.SetLevelHook(mcpLogLevel, cfg.HTTPDebug))
+	return server.NewMCPServer(appName, appVersion, cfg, hooks)
 }
 
 // setupSignalHandling creates a context that will be cancelled on interrupt signals
@@ -117,48 +284,240 @@ func startServer(ctx context.Context, mcpServer *mcpserver.MCPServer, flags CliF
 	switch flags.TransportType {
 	case "stdio":
 		slog.Info("Starting Luno MCP server using stdio transport")
-		return server.ServeStdio(ctx, mcpServer)
+		return server.ServeStdio(ctx, mcpServer, flags.StdioPingInterval)
 	case "sse":
 		slog.Info("Starting Luno MCP server using SSE transport", slog.String("address", flags.SSEAddr))
 		return server.ServeSSE(ctx, mcpServer, flags.SSEAddr)
 	case "streamable-http":
 		slog.Info("Starting Luno MCP server using Streamable HTTP transport", slog.String("address", flags.SSEAddr))
 		return server.ServeStreamableHTTP(ctx, mcpServer, flags.SSEAddr)
+	case "http":
+		slog.Info("Starting Luno MCP server using HTTP transport", slog.String("address", flags.SSEAddr))
+		return server.ServeHTTP(ctx, mcpServer, flags.SSEAddr)
 	default:
-		return fmt.Errorf("invalid transport type: %s. Must be 'stdio', 'sse', or 'streamable-http'", flags.TransportType)
+		return fmt.Errorf("invalid transport type: %s. Must be 'stdio', 'sse', 'streamable-http' or 'http'", flags.TransportType)
 	}
 }
 
 func main() {
+	// Keep secrets out of anything written through the standard logger
+	log.SetOutput(redact.NewWriter(os.Stderr))
+
 	loadEnvFile()
 
 	// Parse command line flags
 	flags := parseFlags()
 
 	// Set up basic logger first
-	setupLogger(flags.LogLevel)
+	logFormat := resolveLogFormat(flags.LogFormat)
+	setupLogger(flags.LogLevel, logFormat, consoleOutput(flags.TransportType))
 
-	// Load configuration
-	cfg, err := config.Load(flags.LunoDomain)
-	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
+	if flags.VerifyAuditLog != "" {
+		keyPath := flags.AuditSigningKey
+		if keyPath == "" {
+			keyPath = os.Getenv(config.EnvAuditSigningKeyPath)
+		}
+		if err := verifyAuditLog(flags.VerifyAuditLog, keyPath, os.Stdout); err != nil {
+			log.Printf("Audit log verification failed: %v", err)
+			os.Exit(1)
+		}
+		return
 	}
 
+	// Fail fast on misconfiguration rather than at the first tool call
+	explicit := explicitFlags()
+	if flags.TransportType == "http" && !explicit["sse-address"] {
+		flags.SSEAddr = defaultHTTPAddr
+	}
+	if err := selfCheck(context.Background(), flags, explicit, http.DefaultClient); err != nil {
+		log.Printf("Startup check failed: %v", err)
+		os.Exit(exitCode(err))
+	}
+
+	// Load configuration
+	opts := []config.Option{
+		config.WithDomain(flags.LunoDomain),
+		config.WithAppInfo(appName, appVersion),
+		config.WithMiddleware(sdk.Logging(nil)),
+		config.WithTransport(flags.TransportType),
+	}
 	// CLI flag takes precedence for enabling write operations
 	if flags.AllowWriteOperations {
-		cfg.AllowWriteOperations = true
+		opts = append(opts, config.WithAllowWriteOperations(true))
+	}
+	if flags.AllowWithdrawals {
+		opts = append(opts, config.WithAllowWithdrawals(true))
+	}
+	if flags.HideUnavailableTools {
+		opts = append(opts, config.WithHideUnavailableTools(true))
+	}
+	if flags.Locale != "" {
+		opts = append(opts, config.WithLocale(flags.Locale))
+	}
+	if flags.OutputFormat != "" {
+		opts = append(opts, config.WithOutputFormat(flags.OutputFormat))
+	}
+	if flags.WebhookURL != "" {
+		opts = append(opts, config.WithWebhook(flags.WebhookURL, ""))
+	}
+	if flags.AdminAddr != "" {
+		opts = append(opts, config.WithAdmin(flags.AdminAddr, ""))
+	}
+	if flags.CredentialsProvider != "" {
+		opts = append(opts, config.WithCredentialsProviderURL(flags.CredentialsProvider))
+	}
+	if flags.DisableUpdateCheck {
+		opts = append(opts, config.WithUpdateCheck(false))
+	}
+	if flags.TelemetryURL != "" {
+		opts = append(opts, config.WithTelemetryURL(flags.TelemetryURL))
+	}
+	if flags.ReferencePriceURL != "" {
+		opts = append(opts, config.WithReferencePriceURL(flags.ReferencePriceURL))
+	}
+	if flags.TradeJournalPath != "" {
+		opts = append(opts, config.WithTradeJournalPath(flags.TradeJournalPath))
+	}
+	if flags.AccountAliasesPath != "" {
+		opts = append(opts, config.WithAccountAliasesPath(flags.AccountAliasesPath))
+	}
+	if flags.TrackedOrdersPath != "" {
+		opts = append(opts, config.WithTrackedOrdersPath(flags.TrackedOrdersPath))
+	}
+	if flags.ImportedTradesPath != "" {
+		opts = append(opts, config.WithImportedTradesPath(flags.ImportedTradesPath))
+	}
+	if flags.CandleCacheDir != "" {
+		opts = append(opts, config.WithCandleCacheDir(flags.CandleCacheDir))
+	}
+	if flags.DataDir != "" {
+		opts = append(opts, config.WithDataDir(flags.DataDir))
+	}
+	if flags.StorageEncryption != "" {
+		opts = append(opts, config.WithStorageEncryption(flags.StorageEncryption))
+	}
+	if flags.DepositAlerts != "" {
+		opts = append(opts, config.WithDepositAlerts(flags.DepositAlerts))
+	}
+	if flags.TradingWindows != "" || flags.TradingTimezone != "" {
+		windows, timezone := flags.TradingWindows, flags.TradingTimezone
+		if windows == "" {
+			windows = os.Getenv(config.EnvTradingWindows)
+		}
+		if timezone == "" {
+			timezone = os.Getenv(config.EnvTradingTimezone)
+		}
+		opts = append(opts, config.WithTradingWindows(windows, timezone))
+	}
+	if flags.TradingPairs != "" {
+		opts = append(opts, config.WithTradingPairs(strings.Split(flags.TradingPairs, ",")...))
+	}
+	if flags.Chaos != "" {
+		opts = append(opts, config.WithChaos(flags.Chaos))
+	}
+	if flags.ApprovalThresholds != "" {
+		opts = append(opts, config.WithApprovals(flags.ApprovalThresholds))
+	}
+	if flags.ApprovalCommand != "" {
+		opts = append(opts, config.WithApprovalCommand(flags.ApprovalCommand))
+	}
+	if flags.AuditLogPath != "" {
+		opts = append(opts, config.WithAuditLogPath(flags.AuditLogPath))
+	}
+	if flags.AuditSigningKey != "" {
+		opts = append(opts, config.WithAuditSigningKeyPath(flags.AuditSigningKey))
+	}
+	if explicit["max-response-bytes"] {
+		opts = append(opts, config.WithMaxResponseBytes(flags.MaxResponseBytes))
+	}
+	if explicit["max-concurrent-calls"] {
+		opts = append(opts, config.WithMaxConcurrentCalls(flags.MaxConcurrentCalls))
+	}
+	if explicit["max-concurrent-calls-per-tool"] {
+		opts = append(opts, config.WithMaxConcurrentCallsPerTool(flags.MaxCallsPerTool))
+	}
+	if explicit["call-queue-timeout"] {
+		opts = append(opts, config.WithCallQueueTimeout(flags.CallQueueTimeout))
+	}
+	if explicit["max-price-deviation"] {
+		opts = append(opts, config.WithMaxPriceDeviation(flags.MaxPriceDeviation))
+	}
+	if explicit["duplicate-order-window"] {
+		opts = append(opts, config.WithDuplicateOrderWindow(flags.DuplicateOrderWindow))
+	}
+	if explicit["repeat-call-threshold"] {
+		opts = append(opts, config.WithRepeatCallThreshold(flags.RepeatCallThreshold))
+	}
+	if explicit["repeat-call-window"] {
+		opts = append(opts, config.WithRepeatCallWindow(flags.RepeatCallWindow))
+	}
+	cfg, err := config.Load(opts...)
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
 	// Create MCP server with logging hooks
 	mcpServer := createMCPServer(cfg)
 
 	// Now enhance the logger with MCP notification capability
-	setupEnhancedLogger(mcpServer, flags.LogLevel)
+	setupEnhancedLogger(mcpServer, flags.LogLevel, logFormat, consoleOutput(flags.TransportType))
 
 	// Setup signal handling for graceful shutdown
 	ctx, cancel := setupSignalHandling()
 	defer cancel()
 
+	// Keep market metadata fresh for pair validation and completions. The
+	// requests also measure clock skew against Luno, at startup and on each refresh
+	go cfg.Markets.Run(ctx)
+
+	// Refresh the luno://tickers/all resource for subscribed clients
+	go server.RunTickers(ctx, mcpServer, cfg)
+
+	// Send scheduled portfolio digests until shutdown
+	go cfg.Reports.Run(ctx)
+
+	// Tell the user when a newer release is available, unless update checks
+	// are disabled
+	go cfg.Updates.Advise(ctx)
+
+	// Report anonymous tool usage when the operator has opted in, sending what
+	// is left on shutdown
+	go cfg.Telemetry.Run(ctx)
+	defer cfg.Telemetry.Close()
+
+	// Watch the account for events when a webhook is configured
+	if cfg.Events.Enabled() && cfg.IsAuthenticated {
+		go events.NewWatcher(cfg.LunoClient, cfg.Events).Run(ctx)
+	}
+
+	// Tell clients and the webhook about deposits matching the deposit alerts
+	if len(cfg.DepositAlerts) > 0 && cfg.IsAuthenticated {
+		go events.NewDepositWatcher(cfg.LunoClient, cfg.Events, mcpServer, cfg.DepositAlerts).Run(ctx)
+	}
+
+	// Check the orders placed before a restart and watch tracked orders until
+	// they complete
+	if cfg.IsAuthenticated {
+		go orders.NewMonitor(cfg.Orders, cfg.LunoClient, mcpServer).Run(ctx)
+	}
+
+	// Stop running TWAP executions and iceberg orders on shutdown, cancelling
+	// their open slices
+	defer cfg.TWAP.Close()
+	defer cfg.Iceberg.Close()
+
+	// Stop monitoring withdrawals on shutdown
+	defer cfg.Withdrawals.Close()
+
+	// Serve the admin API for operators of shared deployments
+	if cfg.AdminAddr != "" {
+		go func() {
+			if err := server.ServeAdmin(ctx, mcpServer, cfg); err != nil {
+				log.Fatalf("Admin API error: %v", err)
+			}
+		}()
+	}
+
 	// Start the server with the selected transport
 	if err := startServer(ctx, mcpServer, flags); err != nil {
 		log.Fatalf("Server error: %v", err)
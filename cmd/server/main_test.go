@@ -1,28 +1,37 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"flag"
+	"fmt"
+	"io"
+	"log"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/limiter"
+	"github.com/luno/luno-mcp/internal/loopguard"
+	"github.com/luno/luno-mcp/internal/redact"
 	mcpserver "github.com/mark3labs/mcp-go/server"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 const (
-	testDefaultSSEAddr   = "localhost:8080"
-	testCustomSSEAddr    = "127.0.0.1:9000"
-	testStagingDomain    = "staging.api.luno.com"
-	testCustomDomain     = "test.api.luno.com"
-	testCustomSSEAddrAlt = "0.0.0.0:8888"
-	testLogLevelInfo     = "info"
-	testLogLevelDebug    = "debug"
-	testLogLevelError    = "error"
+	testDefaultSSEAddr          = "localhost:8080"
+	testCustomSSEAddr           = "127.0.0.1:9000"
+	testStagingDomain           = "staging.api.luno.com"
+	testCustomDomain            = "test.api.luno.com"
+	testCustomSSEAddrAlt        = "0.0.0.0:8888"
+	testLogLevelInfo            = "info"
+	testLogLevelDebug           = "debug"
+	testLogLevelError           = "error"
 	testTransportStdio          = "stdio"
 	testTransportSSE            = "sse"
 	testTransportStreamableHTTP = "streamable-http"
@@ -93,6 +102,14 @@ func TestParseFlags(t *testing.T) {
 				SSEAddr:              testDefaultSSEAddr,
 				LunoDomain:           "",
 				LogLevel:             testLogLevelInfo,
+				MaxResponseBytes:     config.DefaultMaxResponseBytes,
+				MaxPriceDeviation:    config.DefaultMaxPriceDeviation,
+				DuplicateOrderWindow: config.DefaultDuplicateOrderWindow,
+				MaxConcurrentCalls:   limiter.DefaultMaxCalls,
+				MaxCallsPerTool:      limiter.DefaultMaxCallsPerTool,
+				CallQueueTimeout:     limiter.DefaultQueueTimeout,
+				RepeatCallThreshold:  loopguard.DefaultThreshold,
+				RepeatCallWindow:     loopguard.DefaultWindow,
 				AllowWriteOperations: false,
 			},
 		},
@@ -104,6 +121,14 @@ func TestParseFlags(t *testing.T) {
 				SSEAddr:              testDefaultSSEAddr,
 				LunoDomain:           "",
 				LogLevel:             testLogLevelDebug,
+				MaxResponseBytes:     config.DefaultMaxResponseBytes,
+				MaxPriceDeviation:    config.DefaultMaxPriceDeviation,
+				DuplicateOrderWindow: config.DefaultDuplicateOrderWindow,
+				MaxConcurrentCalls:   limiter.DefaultMaxCalls,
+				MaxCallsPerTool:      limiter.DefaultMaxCallsPerTool,
+				CallQueueTimeout:     limiter.DefaultQueueTimeout,
+				RepeatCallThreshold:  loopguard.DefaultThreshold,
+				RepeatCallWindow:     loopguard.DefaultWindow,
 				AllowWriteOperations: false,
 			},
 		},
@@ -115,6 +140,14 @@ func TestParseFlags(t *testing.T) {
 				SSEAddr:              testCustomSSEAddr,
 				LunoDomain:           testStagingDomain,
 				LogLevel:             testLogLevelInfo,
+				MaxResponseBytes:     config.DefaultMaxResponseBytes,
+				MaxPriceDeviation:    config.DefaultMaxPriceDeviation,
+				DuplicateOrderWindow: config.DefaultDuplicateOrderWindow,
+				MaxConcurrentCalls:   limiter.DefaultMaxCalls,
+				MaxCallsPerTool:      limiter.DefaultMaxCallsPerTool,
+				CallQueueTimeout:     limiter.DefaultQueueTimeout,
+				RepeatCallThreshold:  loopguard.DefaultThreshold,
+				RepeatCallWindow:     loopguard.DefaultWindow,
 				AllowWriteOperations: false,
 			},
 		},
@@ -126,6 +159,14 @@ func TestParseFlags(t *testing.T) {
 				SSEAddr:              testCustomSSEAddrAlt,
 				LunoDomain:           testCustomDomain,
 				LogLevel:             testLogLevelError,
+				MaxResponseBytes:     config.DefaultMaxResponseBytes,
+				MaxPriceDeviation:    config.DefaultMaxPriceDeviation,
+				DuplicateOrderWindow: config.DefaultDuplicateOrderWindow,
+				MaxConcurrentCalls:   limiter.DefaultMaxCalls,
+				MaxCallsPerTool:      limiter.DefaultMaxCallsPerTool,
+				CallQueueTimeout:     limiter.DefaultQueueTimeout,
+				RepeatCallThreshold:  loopguard.DefaultThreshold,
+				RepeatCallWindow:     loopguard.DefaultWindow,
 				AllowWriteOperations: false,
 			},
 		},
@@ -137,9 +178,519 @@ func TestParseFlags(t *testing.T) {
 				SSEAddr:              testDefaultSSEAddr,
 				LunoDomain:           "",
 				LogLevel:             testLogLevelInfo,
+				MaxResponseBytes:     config.DefaultMaxResponseBytes,
+				MaxPriceDeviation:    config.DefaultMaxPriceDeviation,
+				DuplicateOrderWindow: config.DefaultDuplicateOrderWindow,
+				MaxConcurrentCalls:   limiter.DefaultMaxCalls,
+				MaxCallsPerTool:      limiter.DefaultMaxCallsPerTool,
+				CallQueueTimeout:     limiter.DefaultQueueTimeout,
+				RepeatCallThreshold:  loopguard.DefaultThreshold,
+				RepeatCallWindow:     loopguard.DefaultWindow,
 				AllowWriteOperations: true,
 			},
 		},
+		{
+			name: "allow withdrawals flag",
+			args: []string{"-allow-write-operations", "-allow-withdrawals"},
+			expected: CliFlags{
+				TransportType:        testTransportStreamableHTTP,
+				SSEAddr:              testDefaultSSEAddr,
+				LunoDomain:           "",
+				LogLevel:             testLogLevelInfo,
+				MaxResponseBytes:     config.DefaultMaxResponseBytes,
+				MaxPriceDeviation:    config.DefaultMaxPriceDeviation,
+				DuplicateOrderWindow: config.DefaultDuplicateOrderWindow,
+				MaxConcurrentCalls:   limiter.DefaultMaxCalls,
+				MaxCallsPerTool:      limiter.DefaultMaxCallsPerTool,
+				CallQueueTimeout:     limiter.DefaultQueueTimeout,
+				RepeatCallThreshold:  loopguard.DefaultThreshold,
+				RepeatCallWindow:     loopguard.DefaultWindow,
+				AllowWriteOperations: true,
+				AllowWithdrawals:     true,
+			},
+		},
+		{
+			name: "hide unavailable tools flag",
+			args: []string{"-hide-unavailable-tools"},
+			expected: CliFlags{
+				TransportType:        testTransportStreamableHTTP,
+				SSEAddr:              testDefaultSSEAddr,
+				LogLevel:             testLogLevelInfo,
+				MaxResponseBytes:     config.DefaultMaxResponseBytes,
+				MaxPriceDeviation:    config.DefaultMaxPriceDeviation,
+				DuplicateOrderWindow: config.DefaultDuplicateOrderWindow,
+				MaxConcurrentCalls:   limiter.DefaultMaxCalls,
+				MaxCallsPerTool:      limiter.DefaultMaxCallsPerTool,
+				CallQueueTimeout:     limiter.DefaultQueueTimeout,
+				RepeatCallThreshold:  loopguard.DefaultThreshold,
+				RepeatCallWindow:     loopguard.DefaultWindow,
+				HideUnavailableTools: true,
+			},
+		},
+		{
+			name: "locale flag",
+			args: []string{"-locale=ms-MY"},
+			expected: CliFlags{
+				TransportType:        testTransportStreamableHTTP,
+				SSEAddr:              testDefaultSSEAddr,
+				LogLevel:             testLogLevelInfo,
+				MaxResponseBytes:     config.DefaultMaxResponseBytes,
+				MaxPriceDeviation:    config.DefaultMaxPriceDeviation,
+				DuplicateOrderWindow: config.DefaultDuplicateOrderWindow,
+				MaxConcurrentCalls:   limiter.DefaultMaxCalls,
+				MaxCallsPerTool:      limiter.DefaultMaxCallsPerTool,
+				CallQueueTimeout:     limiter.DefaultQueueTimeout,
+				RepeatCallThreshold:  loopguard.DefaultThreshold,
+				RepeatCallWindow:     loopguard.DefaultWindow,
+				Locale:               "ms-MY",
+			},
+		},
+		{
+			name: "container flags",
+			args: []string{"-transport=http", "-log-format=json", "-data-dir=/data", "-storage-encryption=passphrase"},
+			expected: CliFlags{
+				TransportType:        "http",
+				SSEAddr:              testDefaultSSEAddr,
+				LogLevel:             testLogLevelInfo,
+				LogFormat:            "json",
+				DataDir:              "/data",
+				StorageEncryption:    "passphrase",
+				MaxResponseBytes:     config.DefaultMaxResponseBytes,
+				MaxPriceDeviation:    config.DefaultMaxPriceDeviation,
+				DuplicateOrderWindow: config.DefaultDuplicateOrderWindow,
+				MaxConcurrentCalls:   limiter.DefaultMaxCalls,
+				MaxCallsPerTool:      limiter.DefaultMaxCallsPerTool,
+				CallQueueTimeout:     limiter.DefaultQueueTimeout,
+				RepeatCallThreshold:  loopguard.DefaultThreshold,
+				RepeatCallWindow:     loopguard.DefaultWindow,
+			},
+		},
+		{
+			name: "stdio ping interval flag",
+			args: []string{"-transport=stdio", "-stdio-ping-interval=30s"},
+			expected: CliFlags{
+				TransportType:        testTransportStdio,
+				SSEAddr:              testDefaultSSEAddr,
+				StdioPingInterval:    30 * time.Second,
+				LogLevel:             testLogLevelInfo,
+				MaxResponseBytes:     config.DefaultMaxResponseBytes,
+				MaxPriceDeviation:    config.DefaultMaxPriceDeviation,
+				DuplicateOrderWindow: config.DefaultDuplicateOrderWindow,
+				MaxConcurrentCalls:   limiter.DefaultMaxCalls,
+				MaxCallsPerTool:      limiter.DefaultMaxCallsPerTool,
+				CallQueueTimeout:     limiter.DefaultQueueTimeout,
+				RepeatCallThreshold:  loopguard.DefaultThreshold,
+				RepeatCallWindow:     loopguard.DefaultWindow,
+			},
+		},
+		{
+			name: "output format flag",
+			args: []string{"-output-format=yaml"},
+			expected: CliFlags{
+				TransportType:        testTransportStreamableHTTP,
+				SSEAddr:              testDefaultSSEAddr,
+				LogLevel:             testLogLevelInfo,
+				MaxResponseBytes:     config.DefaultMaxResponseBytes,
+				MaxPriceDeviation:    config.DefaultMaxPriceDeviation,
+				DuplicateOrderWindow: config.DefaultDuplicateOrderWindow,
+				MaxConcurrentCalls:   limiter.DefaultMaxCalls,
+				MaxCallsPerTool:      limiter.DefaultMaxCallsPerTool,
+				CallQueueTimeout:     limiter.DefaultQueueTimeout,
+				RepeatCallThreshold:  loopguard.DefaultThreshold,
+				RepeatCallWindow:     loopguard.DefaultWindow,
+				OutputFormat:         "yaml",
+			},
+		},
+		{
+			name: "webhook url flag",
+			args: []string{"-webhook-url=https://example.com/hook"},
+			expected: CliFlags{
+				TransportType:        testTransportStreamableHTTP,
+				SSEAddr:              testDefaultSSEAddr,
+				LogLevel:             testLogLevelInfo,
+				MaxResponseBytes:     config.DefaultMaxResponseBytes,
+				MaxPriceDeviation:    config.DefaultMaxPriceDeviation,
+				DuplicateOrderWindow: config.DefaultDuplicateOrderWindow,
+				MaxConcurrentCalls:   limiter.DefaultMaxCalls,
+				MaxCallsPerTool:      limiter.DefaultMaxCallsPerTool,
+				CallQueueTimeout:     limiter.DefaultQueueTimeout,
+				RepeatCallThreshold:  loopguard.DefaultThreshold,
+				RepeatCallWindow:     loopguard.DefaultWindow,
+				WebhookURL:           "https://example.com/hook",
+			},
+		},
+		{
+			name: "disable update check flag",
+			args: []string{"-disable-update-check"},
+			expected: CliFlags{
+				TransportType:        testTransportStreamableHTTP,
+				SSEAddr:              testDefaultSSEAddr,
+				LogLevel:             testLogLevelInfo,
+				MaxResponseBytes:     config.DefaultMaxResponseBytes,
+				MaxPriceDeviation:    config.DefaultMaxPriceDeviation,
+				DuplicateOrderWindow: config.DefaultDuplicateOrderWindow,
+				MaxConcurrentCalls:   limiter.DefaultMaxCalls,
+				MaxCallsPerTool:      limiter.DefaultMaxCallsPerTool,
+				CallQueueTimeout:     limiter.DefaultQueueTimeout,
+				RepeatCallThreshold:  loopguard.DefaultThreshold,
+				RepeatCallWindow:     loopguard.DefaultWindow,
+				DisableUpdateCheck:   true,
+			},
+		},
+		{
+			name: "trading windows flags",
+			args: []string{"-trading-windows=Mon-Fri 06:00-24:00", "-trading-timezone=Africa/Johannesburg"},
+			expected: CliFlags{
+				TransportType:        testTransportStreamableHTTP,
+				SSEAddr:              testDefaultSSEAddr,
+				LogLevel:             testLogLevelInfo,
+				MaxResponseBytes:     config.DefaultMaxResponseBytes,
+				MaxPriceDeviation:    config.DefaultMaxPriceDeviation,
+				DuplicateOrderWindow: config.DefaultDuplicateOrderWindow,
+				MaxConcurrentCalls:   limiter.DefaultMaxCalls,
+				MaxCallsPerTool:      limiter.DefaultMaxCallsPerTool,
+				CallQueueTimeout:     limiter.DefaultQueueTimeout,
+				RepeatCallThreshold:  loopguard.DefaultThreshold,
+				RepeatCallWindow:     loopguard.DefaultWindow,
+				TradingWindows:       "Mon-Fri 06:00-24:00",
+				TradingTimezone:      "Africa/Johannesburg",
+			},
+		},
+		{
+			name: "trading pairs flag",
+			args: []string{"-trading-pairs=XBTZAR,ETHZAR"},
+			expected: CliFlags{
+				TransportType:        testTransportStreamableHTTP,
+				SSEAddr:              testDefaultSSEAddr,
+				LogLevel:             testLogLevelInfo,
+				MaxResponseBytes:     config.DefaultMaxResponseBytes,
+				MaxPriceDeviation:    config.DefaultMaxPriceDeviation,
+				DuplicateOrderWindow: config.DefaultDuplicateOrderWindow,
+				MaxConcurrentCalls:   limiter.DefaultMaxCalls,
+				MaxCallsPerTool:      limiter.DefaultMaxCallsPerTool,
+				CallQueueTimeout:     limiter.DefaultQueueTimeout,
+				RepeatCallThreshold:  loopguard.DefaultThreshold,
+				RepeatCallWindow:     loopguard.DefaultWindow,
+				TradingPairs:         "XBTZAR,ETHZAR",
+			},
+		},
+		{
+			name: "admin address flag",
+			args: []string{"-admin-address=127.0.0.1:8081"},
+			expected: CliFlags{
+				TransportType:        testTransportStreamableHTTP,
+				SSEAddr:              testDefaultSSEAddr,
+				LogLevel:             testLogLevelInfo,
+				MaxResponseBytes:     config.DefaultMaxResponseBytes,
+				MaxPriceDeviation:    config.DefaultMaxPriceDeviation,
+				DuplicateOrderWindow: config.DefaultDuplicateOrderWindow,
+				MaxConcurrentCalls:   limiter.DefaultMaxCalls,
+				MaxCallsPerTool:      limiter.DefaultMaxCallsPerTool,
+				CallQueueTimeout:     limiter.DefaultQueueTimeout,
+				RepeatCallThreshold:  loopguard.DefaultThreshold,
+				RepeatCallWindow:     loopguard.DefaultWindow,
+				AdminAddr:            "127.0.0.1:8081",
+			},
+		},
+		{
+			name: "credentials provider flag",
+			args: []string{"-credentials-provider=vault://secret/luno/{tenant}"},
+			expected: CliFlags{
+				TransportType:        testTransportStreamableHTTP,
+				SSEAddr:              testDefaultSSEAddr,
+				LogLevel:             testLogLevelInfo,
+				MaxResponseBytes:     config.DefaultMaxResponseBytes,
+				MaxPriceDeviation:    config.DefaultMaxPriceDeviation,
+				DuplicateOrderWindow: config.DefaultDuplicateOrderWindow,
+				MaxConcurrentCalls:   limiter.DefaultMaxCalls,
+				MaxCallsPerTool:      limiter.DefaultMaxCallsPerTool,
+				CallQueueTimeout:     limiter.DefaultQueueTimeout,
+				RepeatCallThreshold:  loopguard.DefaultThreshold,
+				RepeatCallWindow:     loopguard.DefaultWindow,
+				CredentialsProvider:  "vault://secret/luno/{tenant}",
+			},
+		},
+		{
+			name: "chaos flag",
+			args: []string{"-chaos=latency=200ms-2s,errors=0.1"},
+			expected: CliFlags{
+				TransportType:        testTransportStreamableHTTP,
+				SSEAddr:              testDefaultSSEAddr,
+				LogLevel:             testLogLevelInfo,
+				MaxResponseBytes:     config.DefaultMaxResponseBytes,
+				MaxPriceDeviation:    config.DefaultMaxPriceDeviation,
+				DuplicateOrderWindow: config.DefaultDuplicateOrderWindow,
+				MaxConcurrentCalls:   limiter.DefaultMaxCalls,
+				MaxCallsPerTool:      limiter.DefaultMaxCallsPerTool,
+				CallQueueTimeout:     limiter.DefaultQueueTimeout,
+				RepeatCallThreshold:  loopguard.DefaultThreshold,
+				RepeatCallWindow:     loopguard.DefaultWindow,
+				Chaos:                "latency=200ms-2s,errors=0.1",
+			},
+		},
+		{
+			name: "approval flags",
+			args: []string{"-approval-thresholds=ZAR:50000,XBT:0.5", "-approval-command=/usr/local/bin/approve"},
+			expected: CliFlags{
+				TransportType:        testTransportStreamableHTTP,
+				SSEAddr:              testDefaultSSEAddr,
+				LogLevel:             testLogLevelInfo,
+				MaxResponseBytes:     config.DefaultMaxResponseBytes,
+				MaxPriceDeviation:    config.DefaultMaxPriceDeviation,
+				DuplicateOrderWindow: config.DefaultDuplicateOrderWindow,
+				MaxConcurrentCalls:   limiter.DefaultMaxCalls,
+				MaxCallsPerTool:      limiter.DefaultMaxCallsPerTool,
+				CallQueueTimeout:     limiter.DefaultQueueTimeout,
+				RepeatCallThreshold:  loopguard.DefaultThreshold,
+				RepeatCallWindow:     loopguard.DefaultWindow,
+				ApprovalThresholds:   "ZAR:50000,XBT:0.5",
+				ApprovalCommand:      "/usr/local/bin/approve",
+			},
+		},
+		{
+			name: "audit log flags",
+			args: []string{"-audit-log=/var/log/luno-mcp/audit.jsonl", "-audit-signing-key=/etc/luno-mcp/audit-key.pem"},
+			expected: CliFlags{
+				TransportType:        testTransportStreamableHTTP,
+				SSEAddr:              testDefaultSSEAddr,
+				LogLevel:             testLogLevelInfo,
+				MaxResponseBytes:     config.DefaultMaxResponseBytes,
+				MaxPriceDeviation:    config.DefaultMaxPriceDeviation,
+				DuplicateOrderWindow: config.DefaultDuplicateOrderWindow,
+				MaxConcurrentCalls:   limiter.DefaultMaxCalls,
+				MaxCallsPerTool:      limiter.DefaultMaxCallsPerTool,
+				CallQueueTimeout:     limiter.DefaultQueueTimeout,
+				RepeatCallThreshold:  loopguard.DefaultThreshold,
+				RepeatCallWindow:     loopguard.DefaultWindow,
+				AuditLogPath:         "/var/log/luno-mcp/audit.jsonl",
+				AuditSigningKey:      "/etc/luno-mcp/audit-key.pem",
+			},
+		},
+		{
+			name: "telemetry url flag",
+			args: []string{"-telemetry-url=https://example.com/telemetry"},
+			expected: CliFlags{
+				TransportType:        testTransportStreamableHTTP,
+				SSEAddr:              testDefaultSSEAddr,
+				LogLevel:             testLogLevelInfo,
+				MaxResponseBytes:     config.DefaultMaxResponseBytes,
+				MaxPriceDeviation:    config.DefaultMaxPriceDeviation,
+				DuplicateOrderWindow: config.DefaultDuplicateOrderWindow,
+				MaxConcurrentCalls:   limiter.DefaultMaxCalls,
+				MaxCallsPerTool:      limiter.DefaultMaxCallsPerTool,
+				CallQueueTimeout:     limiter.DefaultQueueTimeout,
+				RepeatCallThreshold:  loopguard.DefaultThreshold,
+				RepeatCallWindow:     loopguard.DefaultWindow,
+				TelemetryURL:         "https://example.com/telemetry",
+			},
+		},
+		{
+			name: "reference price url flag",
+			args: []string{"-reference-price-url=https://example.com/{pair}"},
+			expected: CliFlags{
+				TransportType:        testTransportStreamableHTTP,
+				SSEAddr:              testDefaultSSEAddr,
+				LogLevel:             testLogLevelInfo,
+				MaxResponseBytes:     config.DefaultMaxResponseBytes,
+				MaxPriceDeviation:    config.DefaultMaxPriceDeviation,
+				DuplicateOrderWindow: config.DefaultDuplicateOrderWindow,
+				MaxConcurrentCalls:   limiter.DefaultMaxCalls,
+				MaxCallsPerTool:      limiter.DefaultMaxCallsPerTool,
+				CallQueueTimeout:     limiter.DefaultQueueTimeout,
+				RepeatCallThreshold:  loopguard.DefaultThreshold,
+				RepeatCallWindow:     loopguard.DefaultWindow,
+				ReferencePriceURL:    "https://example.com/{pair}",
+			},
+		},
+		{
+			name: "trade journal flag",
+			args: []string{"-trade-journal=/var/lib/luno-mcp/journal.jsonl"},
+			expected: CliFlags{
+				TransportType:        testTransportStreamableHTTP,
+				SSEAddr:              testDefaultSSEAddr,
+				LogLevel:             testLogLevelInfo,
+				MaxResponseBytes:     config.DefaultMaxResponseBytes,
+				MaxPriceDeviation:    config.DefaultMaxPriceDeviation,
+				DuplicateOrderWindow: config.DefaultDuplicateOrderWindow,
+				MaxConcurrentCalls:   limiter.DefaultMaxCalls,
+				MaxCallsPerTool:      limiter.DefaultMaxCallsPerTool,
+				CallQueueTimeout:     limiter.DefaultQueueTimeout,
+				RepeatCallThreshold:  loopguard.DefaultThreshold,
+				RepeatCallWindow:     loopguard.DefaultWindow,
+				TradeJournalPath:     "/var/lib/luno-mcp/journal.jsonl",
+			},
+		},
+		{
+			name: "account aliases flag",
+			args: []string{"-account-aliases=/var/lib/luno-mcp/aliases.json"},
+			expected: CliFlags{
+				TransportType:        testTransportStreamableHTTP,
+				SSEAddr:              testDefaultSSEAddr,
+				LogLevel:             testLogLevelInfo,
+				MaxResponseBytes:     config.DefaultMaxResponseBytes,
+				MaxPriceDeviation:    config.DefaultMaxPriceDeviation,
+				DuplicateOrderWindow: config.DefaultDuplicateOrderWindow,
+				MaxConcurrentCalls:   limiter.DefaultMaxCalls,
+				MaxCallsPerTool:      limiter.DefaultMaxCallsPerTool,
+				CallQueueTimeout:     limiter.DefaultQueueTimeout,
+				RepeatCallThreshold:  loopguard.DefaultThreshold,
+				RepeatCallWindow:     loopguard.DefaultWindow,
+				AccountAliasesPath:   "/var/lib/luno-mcp/aliases.json",
+			},
+		},
+		{
+			name: "tracked orders flag",
+			args: []string{"-tracked-orders=/var/lib/luno-mcp/orders.json"},
+			expected: CliFlags{
+				TransportType:        testTransportStreamableHTTP,
+				SSEAddr:              testDefaultSSEAddr,
+				LogLevel:             testLogLevelInfo,
+				MaxResponseBytes:     config.DefaultMaxResponseBytes,
+				MaxPriceDeviation:    config.DefaultMaxPriceDeviation,
+				DuplicateOrderWindow: config.DefaultDuplicateOrderWindow,
+				MaxConcurrentCalls:   limiter.DefaultMaxCalls,
+				MaxCallsPerTool:      limiter.DefaultMaxCallsPerTool,
+				CallQueueTimeout:     limiter.DefaultQueueTimeout,
+				RepeatCallThreshold:  loopguard.DefaultThreshold,
+				RepeatCallWindow:     loopguard.DefaultWindow,
+				TrackedOrdersPath:    "/var/lib/luno-mcp/orders.json",
+			},
+		},
+		{
+			name: "imported trades flag",
+			args: []string{"-imported-trades=/var/lib/luno-mcp/imported-trades.json"},
+			expected: CliFlags{
+				TransportType:        testTransportStreamableHTTP,
+				SSEAddr:              testDefaultSSEAddr,
+				LogLevel:             testLogLevelInfo,
+				MaxResponseBytes:     config.DefaultMaxResponseBytes,
+				MaxPriceDeviation:    config.DefaultMaxPriceDeviation,
+				DuplicateOrderWindow: config.DefaultDuplicateOrderWindow,
+				MaxConcurrentCalls:   limiter.DefaultMaxCalls,
+				MaxCallsPerTool:      limiter.DefaultMaxCallsPerTool,
+				CallQueueTimeout:     limiter.DefaultQueueTimeout,
+				RepeatCallThreshold:  loopguard.DefaultThreshold,
+				RepeatCallWindow:     loopguard.DefaultWindow,
+				ImportedTradesPath:   "/var/lib/luno-mcp/imported-trades.json",
+			},
+		},
+		{
+			name: "candle cache flag",
+			args: []string{"-candle-cache=/var/cache/luno-mcp/candles"},
+			expected: CliFlags{
+				TransportType:        testTransportStreamableHTTP,
+				SSEAddr:              testDefaultSSEAddr,
+				LogLevel:             testLogLevelInfo,
+				MaxResponseBytes:     config.DefaultMaxResponseBytes,
+				MaxPriceDeviation:    config.DefaultMaxPriceDeviation,
+				DuplicateOrderWindow: config.DefaultDuplicateOrderWindow,
+				MaxConcurrentCalls:   limiter.DefaultMaxCalls,
+				MaxCallsPerTool:      limiter.DefaultMaxCallsPerTool,
+				CallQueueTimeout:     limiter.DefaultQueueTimeout,
+				RepeatCallThreshold:  loopguard.DefaultThreshold,
+				RepeatCallWindow:     loopguard.DefaultWindow,
+				CandleCacheDir:       "/var/cache/luno-mcp/candles",
+			},
+		},
+		{
+			name: "deposit alerts flag",
+			args: []string{"-deposit-alerts=XBT:0.01,ZAR:500"},
+			expected: CliFlags{
+				TransportType:        testTransportStreamableHTTP,
+				SSEAddr:              testDefaultSSEAddr,
+				LogLevel:             testLogLevelInfo,
+				MaxResponseBytes:     config.DefaultMaxResponseBytes,
+				MaxPriceDeviation:    config.DefaultMaxPriceDeviation,
+				DuplicateOrderWindow: config.DefaultDuplicateOrderWindow,
+				MaxConcurrentCalls:   limiter.DefaultMaxCalls,
+				MaxCallsPerTool:      limiter.DefaultMaxCallsPerTool,
+				CallQueueTimeout:     limiter.DefaultQueueTimeout,
+				RepeatCallThreshold:  loopguard.DefaultThreshold,
+				RepeatCallWindow:     loopguard.DefaultWindow,
+				DepositAlerts:        "XBT:0.01,ZAR:500",
+			},
+		},
+		{
+			name: "max response bytes flag",
+			args: []string{"-max-response-bytes=0"},
+			expected: CliFlags{
+				TransportType:        testTransportStreamableHTTP,
+				SSEAddr:              testDefaultSSEAddr,
+				LogLevel:             testLogLevelInfo,
+				MaxPriceDeviation:    config.DefaultMaxPriceDeviation,
+				DuplicateOrderWindow: config.DefaultDuplicateOrderWindow,
+				MaxConcurrentCalls:   limiter.DefaultMaxCalls,
+				MaxCallsPerTool:      limiter.DefaultMaxCallsPerTool,
+				CallQueueTimeout:     limiter.DefaultQueueTimeout,
+				RepeatCallThreshold:  loopguard.DefaultThreshold,
+				RepeatCallWindow:     loopguard.DefaultWindow,
+			},
+		},
+		{
+			name: "concurrency limit flags",
+			args: []string{"-max-concurrent-calls=0", "-max-concurrent-calls-per-tool=2", "-call-queue-timeout=5s"},
+			expected: CliFlags{
+				TransportType:        testTransportStreamableHTTP,
+				SSEAddr:              testDefaultSSEAddr,
+				LogLevel:             testLogLevelInfo,
+				MaxResponseBytes:     config.DefaultMaxResponseBytes,
+				MaxPriceDeviation:    config.DefaultMaxPriceDeviation,
+				DuplicateOrderWindow: config.DefaultDuplicateOrderWindow,
+				MaxCallsPerTool:      2,
+				CallQueueTimeout:     5 * time.Second,
+				RepeatCallThreshold:  loopguard.DefaultThreshold,
+				RepeatCallWindow:     loopguard.DefaultWindow,
+			},
+		},
+		{
+			name: "max price deviation flag",
+			args: []string{"-max-price-deviation=20"},
+			expected: CliFlags{
+				TransportType:        testTransportStreamableHTTP,
+				SSEAddr:              testDefaultSSEAddr,
+				LogLevel:             testLogLevelInfo,
+				MaxResponseBytes:     config.DefaultMaxResponseBytes,
+				MaxPriceDeviation:    20,
+				DuplicateOrderWindow: config.DefaultDuplicateOrderWindow,
+				MaxConcurrentCalls:   limiter.DefaultMaxCalls,
+				MaxCallsPerTool:      limiter.DefaultMaxCallsPerTool,
+				CallQueueTimeout:     limiter.DefaultQueueTimeout,
+				RepeatCallThreshold:  loopguard.DefaultThreshold,
+				RepeatCallWindow:     loopguard.DefaultWindow,
+			},
+		},
+		{
+			name: "duplicate order window flag",
+			args: []string{"-duplicate-order-window=0"},
+			expected: CliFlags{
+				TransportType:       testTransportStreamableHTTP,
+				SSEAddr:             testDefaultSSEAddr,
+				LogLevel:            testLogLevelInfo,
+				MaxResponseBytes:    config.DefaultMaxResponseBytes,
+				MaxPriceDeviation:   config.DefaultMaxPriceDeviation,
+				MaxConcurrentCalls:  limiter.DefaultMaxCalls,
+				MaxCallsPerTool:     limiter.DefaultMaxCallsPerTool,
+				CallQueueTimeout:    limiter.DefaultQueueTimeout,
+				RepeatCallThreshold: loopguard.DefaultThreshold,
+				RepeatCallWindow:    loopguard.DefaultWindow,
+			},
+		},
+		{
+			name: "repeat call flags",
+			args: []string{"-repeat-call-threshold=0", "-repeat-call-window=30s"},
+			expected: CliFlags{
+				TransportType:        testTransportStreamableHTTP,
+				SSEAddr:              testDefaultSSEAddr,
+				LogLevel:             testLogLevelInfo,
+				MaxResponseBytes:     config.DefaultMaxResponseBytes,
+				MaxPriceDeviation:    config.DefaultMaxPriceDeviation,
+				DuplicateOrderWindow: config.DefaultDuplicateOrderWindow,
+				MaxConcurrentCalls:   limiter.DefaultMaxCalls,
+				MaxCallsPerTool:      limiter.DefaultMaxCallsPerTool,
+				CallQueueTimeout:     limiter.DefaultQueueTimeout,
+				RepeatCallWindow:     30 * time.Second,
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -222,6 +773,36 @@ func TestLoadEnvFile(t *testing.T) {
 	}
 }
 
+func TestConsoleOutput(t *testing.T) {
+	// stdout carries the stdio transport's messages
+	assert.Same(t, os.Stderr, consoleOutput(testTransportStdio))
+	assert.Same(t, os.Stdout, consoleOutput(testTransportStreamableHTTP))
+}
+
+func TestResolveLogFormat(t *testing.T) {
+	t.Setenv(envLogFormat, "")
+	assert.Equal(t, "text", resolveLogFormat(""))
+
+	t.Setenv(envLogFormat, "json")
+	assert.Equal(t, "json", resolveLogFormat(""))
+	assert.Equal(t, "text", resolveLogFormat("text"), "the flag takes precedence")
+}
+
+func TestNewConsoleHandler(t *testing.T) {
+	var buf bytes.Buffer
+	slog.New(newConsoleHandler("json", &buf, slog.LevelInfo)).Info("Server started", slog.String("transport", "http"))
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "INFO", record["level"])
+	assert.Equal(t, "Server started", record["msg"])
+	assert.Equal(t, "http", record["transport"])
+
+	buf.Reset()
+	slog.New(newConsoleHandler("text", &buf, slog.LevelInfo)).Info("Server started")
+	assert.Contains(t, buf.String(), `msg="Server started"`)
+}
+
 func TestSetupLogger(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -247,7 +828,7 @@ func TestSetupLogger(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			logger := setupLogger(tt.logLevel)
+			logger := setupLogger(tt.logLevel, "text", os.Stdout)
 			assert.NotNil(t, logger)
 
 			// Verify the logger was set as default
@@ -257,12 +838,55 @@ func TestSetupLogger(t *testing.T) {
 	}
 }
 
+func TestNoSecretsInOutput(t *testing.T) {
+	const (
+		keyID     = "KEYID-0123456789"
+		secret    = "SECRET-abcdefghijklmnop"
+		hookToken = "WEBHOOK-SECRET-qrstuvwxyz"
+	)
+	t.Setenv(config.EnvLunoAPIKeyID, keyID)
+	t.Setenv(config.EnvLunoAPIKeySecret, secret)
+	t.Setenv(config.EnvWebhookSecret, hookToken)
+
+	// Capture everything written to stdout and stderr, including the standard logger
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	stdout, stderr, defaultLogger, logWriter := os.Stdout, os.Stderr, slog.Default(), log.Writer()
+	os.Stdout, os.Stderr = w, w
+	t.Cleanup(func() {
+		os.Stdout, os.Stderr = stdout, stderr
+		slog.SetDefault(defaultLogger)
+		log.SetOutput(logWriter)
+	})
+	log.SetOutput(redact.NewWriter(os.Stderr))
+
+	setupLogger(testLogLevelInfo, "text", os.Stdout)
+	cfg, err := config.Load(config.WithWebhook("https://example.com/hook", ""))
+	require.NoError(t, err)
+	require.True(t, cfg.IsAuthenticated)
+
+	apiErr := fmt.Errorf("Get \"https://%s:%s@api.luno.com/api/1/balance\": timeout", keyID, secret)
+	slog.Info("Calling Luno", "key_id", keyID, "authorization", "Basic "+secret)
+	slog.Warn("Luno API call failed", "error", apiErr)
+	slog.Error("Webhook delivery failed with secret " + hookToken)
+	log.Printf("luno: Request: %#v", struct{ Key, Secret string }{keyID, secret})
+
+	require.NoError(t, w.Close())
+	out, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(out), "Luno client authenticated")
+	for _, s := range []string{keyID, secret, hookToken, keyID[:4] + "***"} {
+		assert.NotContains(t, string(out), s)
+	}
+}
+
 func TestCreateMCPServer(t *testing.T) {
 	// Mock configuration - we'll need to set environment variables for this test
 	t.Setenv("LUNO_API_KEY_ID", "test_key")
 	t.Setenv("LUNO_API_SECRET", "test_secret")
 
-	cfg, err := config.Load("")
+	cfg, err := config.Load()
 	require.NoError(t, err)
 
 	server := createMCPServer(cfg)
@@ -310,6 +934,7 @@ func TestCliFlags(t *testing.T) {
 				SSEAddr:              testDefaultSSEAddr,
 				LunoDomain:           "",
 				LogLevel:             testLogLevelInfo,
+				MaxResponseBytes:     config.DefaultMaxResponseBytes,
 				AllowWriteOperations: false,
 			},
 			expected: CliFlags{
@@ -317,6 +942,7 @@ func TestCliFlags(t *testing.T) {
 				SSEAddr:              testDefaultSSEAddr,
 				LunoDomain:           "",
 				LogLevel:             testLogLevelInfo,
+				MaxResponseBytes:     config.DefaultMaxResponseBytes,
 				AllowWriteOperations: false,
 			},
 		},
@@ -327,6 +953,7 @@ func TestCliFlags(t *testing.T) {
 				SSEAddr:              testCustomSSEAddr,
 				LunoDomain:           testStagingDomain,
 				LogLevel:             testLogLevelDebug,
+				MaxResponseBytes:     config.DefaultMaxResponseBytes,
 				AllowWriteOperations: true,
 			},
 			expected: CliFlags{
@@ -334,6 +961,7 @@ func TestCliFlags(t *testing.T) {
 				SSEAddr:              testCustomSSEAddr,
 				LunoDomain:           testStagingDomain,
 				LogLevel:             testLogLevelDebug,
+				MaxResponseBytes:     config.DefaultMaxResponseBytes,
 				AllowWriteOperations: true,
 			},
 		},
@@ -386,18 +1014,18 @@ func TestMainFunctionFlow(t *testing.T) {
 	})
 
 	t.Run("setup logger", func(t *testing.T) {
-		logger := setupLogger(testLogLevelInfo)
+		logger := setupLogger(testLogLevelInfo, "text", os.Stdout)
 		assert.NotNil(t, logger)
 	})
 
 	t.Run("load config", func(t *testing.T) {
-		cfg, err := config.Load("")
+		cfg, err := config.Load()
 		assert.NoError(t, err)
 		assert.NotNil(t, cfg)
 	})
 
 	t.Run("create mcp server", func(t *testing.T) {
-		cfg, err := config.Load("")
+		cfg, err := config.Load()
 		require.NoError(t, err)
 
 		server := createMCPServer(cfg)
@@ -452,7 +1080,7 @@ func TestSetupEnhancedLogger(t *testing.T) {
 			t.Setenv("LUNO_API_SECRET", "test_secret")
 
 			// Load configuration
-			cfg, err := config.Load("")
+			cfg, err := config.Load()
 			require.NoError(t, err)
 
 			// Create MCP server
@@ -464,7 +1092,7 @@ func TestSetupEnhancedLogger(t *testing.T) {
 			defer slog.SetDefault(originalLogger)
 
 			// Test setupEnhancedLogger - this function sets the default logger
-			setupEnhancedLogger(mcpServer, tt.logLevel)
+			setupEnhancedLogger(mcpServer, tt.logLevel, "text", os.Stdout)
 
 			// Verify the logger was set as default
 			newLogger := slog.Default()
@@ -493,6 +1121,7 @@ func TestStartServer(t *testing.T) {
 				SSEAddr:              testDefaultSSEAddr,
 				LunoDomain:           "",
 				LogLevel:             testLogLevelInfo,
+				MaxResponseBytes:     config.DefaultMaxResponseBytes,
 				AllowWriteOperations: false,
 			},
 			expectError:   true,
@@ -505,6 +1134,7 @@ func TestStartServer(t *testing.T) {
 				SSEAddr:              "invalid:99999",
 				LunoDomain:           "",
 				LogLevel:             testLogLevelInfo,
+				MaxResponseBytes:     config.DefaultMaxResponseBytes,
 				AllowWriteOperations: false,
 			},
 			expectError:   true,
@@ -517,11 +1147,23 @@ func TestStartServer(t *testing.T) {
 				SSEAddr:              "invalid:99999",
 				LunoDomain:           "",
 				LogLevel:             testLogLevelInfo,
+				MaxResponseBytes:     config.DefaultMaxResponseBytes,
 				AllowWriteOperations: false,
 			},
 			expectError:   true,
 			errorContains: "invalid port",
 		},
+		{
+			name: "http transport with invalid address",
+			flags: CliFlags{
+				TransportType:    "http",
+				SSEAddr:          "invalid:99999",
+				LogLevel:         testLogLevelInfo,
+				MaxResponseBytes: config.DefaultMaxResponseBytes,
+			},
+			expectError:   true,
+			errorContains: "failed to listen on invalid:99999",
+		},
 	}
 
 	for _, tt := range tests {
@@ -531,7 +1173,7 @@ func TestStartServer(t *testing.T) {
 			t.Setenv("LUNO_API_SECRET", "test_secret")
 
 			// Load configuration
-			cfg, err := config.Load("")
+			cfg, err := config.Load()
 			require.NoError(t, err)
 
 			// Create MCP server
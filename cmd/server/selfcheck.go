@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/luno/luno-mcp/internal/config"
+)
+
+// Exit codes used when the startup self-check fails
+const (
+	exitCodeInvalidFlags      = 2
+	exitCodeInvalidConfig     = 3
+	exitCodeUnreachableDomain = 4
+)
+
+// domainCheckTimeout bounds how long the reachability check for a custom domain may take
+const domainCheckTimeout = 5 * time.Second
+
+var validTransports = []string{"stdio", "sse", "streamable-http", "http"}
+
+var validLogFormats = []string{"text", "json"}
+
+// startupError is a self-check failure with the exit code the process should use
+type startupError struct {
+	code int
+	err  error
+}
+
+func (e *startupError) Error() string {
+	return e.err.Error()
+}
+
+func (e *startupError) Unwrap() error {
+	return e.err
+}
+
+// exitCode returns the process exit code for a self-check error
+func exitCode(err error) int {
+	var se *startupError
+	if errors.As(err, &se) {
+		return se.code
+	}
+	return 1
+}
+
+// explicitFlags returns the names of the command line flags that were set explicitly
+func explicitFlags() map[string]bool {
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	return set
+}
+
+// selfCheck validates the flags and environment before the server starts so that
+// misconfiguration is reported up front rather than at the first tool call.
+func selfCheck(ctx context.Context, flags CliFlags, explicit map[string]bool, httpClient *http.Client) error {
+	if !slices.Contains(validTransports, flags.TransportType) {
+		return &startupError{exitCodeInvalidFlags, fmt.Errorf("invalid --transport %q: must be one of stdio, sse, streamable-http or http", flags.TransportType)}
+	}
+
+	if flags.TransportType == "stdio" && explicit["sse-address"] {
+		return &startupError{exitCodeInvalidFlags, errors.New("--sse-address cannot be used with --transport stdio: remove --sse-address or choose the sse, streamable-http or http transport")}
+	}
+	if flags.TransportType != "stdio" && explicit["stdio-ping-interval"] {
+		return &startupError{exitCodeInvalidFlags, fmt.Errorf("--stdio-ping-interval can only be used with --transport stdio, not %s", flags.TransportType)}
+	}
+	if flags.StdioPingInterval < 0 {
+		return &startupError{exitCodeInvalidFlags, fmt.Errorf("invalid --stdio-ping-interval %s: must be 0 or more", flags.StdioPingInterval)}
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(flags.LogLevel)); err != nil {
+		return &startupError{exitCodeInvalidFlags, fmt.Errorf("invalid --log-level %q: must be one of debug, info, warn or error", flags.LogLevel)}
+	}
+	if logFormat := resolveLogFormat(flags.LogFormat); !slices.Contains(validLogFormats, logFormat) {
+		return &startupError{exitCodeInvalidFlags, fmt.Errorf("invalid --log-format / %s %q: must be text or json", envLogFormat, logFormat)}
+	}
+
+	if flags.MaxResponseBytes < 0 {
+		return &startupError{exitCodeInvalidFlags, fmt.Errorf("invalid --max-response-bytes %d: must be 0 or more", flags.MaxResponseBytes)}
+	}
+
+	if flags.MaxConcurrentCalls < 0 {
+		return &startupError{exitCodeInvalidFlags, fmt.Errorf("invalid --max-concurrent-calls %d: must be 0 or more", flags.MaxConcurrentCalls)}
+	}
+	if flags.MaxCallsPerTool < 0 {
+		return &startupError{exitCodeInvalidFlags, fmt.Errorf("invalid --max-concurrent-calls-per-tool %d: must be 0 or more", flags.MaxCallsPerTool)}
+	}
+	if flags.CallQueueTimeout < 0 {
+		return &startupError{exitCodeInvalidFlags, fmt.Errorf("invalid --call-queue-timeout %s: must be 0 or more", flags.CallQueueTimeout)}
+	}
+	if flags.RepeatCallThreshold < 0 {
+		return &startupError{exitCodeInvalidFlags, fmt.Errorf("invalid --repeat-call-threshold %d: must be 0 or more", flags.RepeatCallThreshold)}
+	}
+	if flags.RepeatCallWindow <= 0 {
+		return &startupError{exitCodeInvalidFlags, fmt.Errorf("invalid --repeat-call-window %s: must be more than 0", flags.RepeatCallWindow)}
+	}
+
+	if err := config.CheckCredentials(); err != nil {
+		return &startupError{exitCodeInvalidConfig, err}
+	}
+
+	domain := config.ResolveDomain(flags.LunoDomain)
+	if err := config.ValidateDomain(domain); err != nil {
+		return &startupError{exitCodeInvalidConfig, fmt.Errorf("invalid Luno API domain: %w", err)}
+	}
+
+	if domain != config.DefaultLunoDomain {
+		if err := checkDomainReachable(ctx, httpClient, "https://"+domain); err != nil {
+			return &startupError{exitCodeUnreachableDomain, fmt.Errorf("custom Luno API domain %s is unreachable: %w. Check --domain / %s and your network connection", domain, err, config.EnvLunoAPIDomain)}
+		}
+	}
+
+	return nil
+}
+
+// checkDomainReachable makes a request to a public endpoint on baseURL. Any HTTP response,
+// including an error status, means the domain is reachable.
+func checkDomainReachable(ctx context.Context, httpClient *http.Client, baseURL string) error {
+	ctx, cancel := context.WithTimeout(ctx, domainCheckTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/api/1/tickers", nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = res.Body.Close()
+	return nil
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/loopguard"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelfCheck(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer ts.Close()
+	reachableDomain := strings.TrimPrefix(ts.URL, "https://")
+
+	validFlags := CliFlags{
+		TransportType:    testTransportStreamableHTTP,
+		SSEAddr:          testDefaultSSEAddr,
+		LogLevel:         testLogLevelInfo,
+		RepeatCallWindow: loopguard.DefaultWindow,
+	}
+
+	tests := []struct {
+		name          string
+		flags         func(CliFlags) CliFlags
+		explicit      map[string]bool
+		env           map[string]string
+		expectedCode  int
+		errorContains string
+	}{
+		{
+			name:  "valid defaults",
+			flags: func(f CliFlags) CliFlags { return f },
+		},
+		{
+			name: "invalid transport",
+			flags: func(f CliFlags) CliFlags {
+				f.TransportType = "websocket"
+				return f
+			},
+			expectedCode:  exitCodeInvalidFlags,
+			errorContains: "invalid --transport",
+		},
+		{
+			name: "sse address with stdio transport",
+			flags: func(f CliFlags) CliFlags {
+				f.TransportType = testTransportStdio
+				return f
+			},
+			explicit:      map[string]bool{"sse-address": true},
+			expectedCode:  exitCodeInvalidFlags,
+			errorContains: "--sse-address cannot be used with --transport stdio",
+		},
+		{
+			name:          "stdio ping interval with http transport",
+			flags:         func(f CliFlags) CliFlags { return f },
+			explicit:      map[string]bool{"stdio-ping-interval": true},
+			expectedCode:  exitCodeInvalidFlags,
+			errorContains: "--stdio-ping-interval can only be used with --transport stdio, not streamable-http",
+		},
+		{
+			name: "negative stdio ping interval",
+			flags: func(f CliFlags) CliFlags {
+				f.TransportType = testTransportStdio
+				f.StdioPingInterval = -time.Second
+				return f
+			},
+			explicit:      map[string]bool{"stdio-ping-interval": true},
+			expectedCode:  exitCodeInvalidFlags,
+			errorContains: "invalid --stdio-ping-interval -1s",
+		},
+		{
+			name: "stdio transport with default sse address",
+			flags: func(f CliFlags) CliFlags {
+				f.TransportType = testTransportStdio
+				return f
+			},
+		},
+		{
+			name: "invalid log level",
+			flags: func(f CliFlags) CliFlags {
+				f.LogLevel = "verbose"
+				return f
+			},
+			expectedCode:  exitCodeInvalidFlags,
+			errorContains: "invalid --log-level",
+		},
+		{
+			name: "negative max response bytes",
+			flags: func(f CliFlags) CliFlags {
+				f.MaxResponseBytes = -1
+				return f
+			},
+			expectedCode:  exitCodeInvalidFlags,
+			errorContains: "invalid --max-response-bytes",
+		},
+		{
+			name: "negative concurrency limit",
+			flags: func(f CliFlags) CliFlags {
+				f.MaxCallsPerTool = -1
+				return f
+			},
+			expectedCode:  exitCodeInvalidFlags,
+			errorContains: "invalid --max-concurrent-calls-per-tool",
+		},
+		{
+			name: "negative call queue timeout",
+			flags: func(f CliFlags) CliFlags {
+				f.CallQueueTimeout = -time.Second
+				return f
+			},
+			expectedCode:  exitCodeInvalidFlags,
+			errorContains: "invalid --call-queue-timeout",
+		},
+		{
+			name: "negative repeat call threshold",
+			flags: func(f CliFlags) CliFlags {
+				f.RepeatCallThreshold = -1
+				return f
+			},
+			expectedCode:  exitCodeInvalidFlags,
+			errorContains: "invalid --repeat-call-threshold",
+		},
+		{
+			name: "zero repeat call window",
+			flags: func(f CliFlags) CliFlags {
+				f.RepeatCallWindow = 0
+				return f
+			},
+			expectedCode:  exitCodeInvalidFlags,
+			errorContains: "invalid --repeat-call-window",
+		},
+		{
+			name:          "key id without secret",
+			flags:         func(f CliFlags) CliFlags { return f },
+			env:           map[string]string{config.EnvLunoAPIKeyID: "key"},
+			expectedCode:  exitCodeInvalidConfig,
+			errorContains: config.EnvLunoAPIKeySecret + " is not",
+		},
+		{
+			name: "malformed domain",
+			flags: func(f CliFlags) CliFlags {
+				f.LunoDomain = "https://api.luno.com"
+				return f
+			},
+			expectedCode:  exitCodeInvalidConfig,
+			errorContains: "must not include a scheme",
+		},
+		{
+			name: "json log format",
+			flags: func(f CliFlags) CliFlags {
+				f.LogFormat = "json"
+				return f
+			},
+		},
+		{
+			name: "invalid log format",
+			flags: func(f CliFlags) CliFlags {
+				f.LogFormat = "logfmt"
+				return f
+			},
+			expectedCode:  exitCodeInvalidFlags,
+			errorContains: `invalid --log-format / LOG_FORMAT "logfmt"`,
+		},
+		{
+			name:          "invalid log format from environment",
+			flags:         func(f CliFlags) CliFlags { return f },
+			env:           map[string]string{envLogFormat: "JSON"},
+			expectedCode:  exitCodeInvalidFlags,
+			errorContains: "must be text or json",
+		},
+		{
+			name:          "unreadable secret file",
+			flags:         func(f CliFlags) CliFlags { return f },
+			env:           map[string]string{config.EnvLunoAPIKeySecret + "_FILE": "/nonexistent/luno-secret"},
+			expectedCode:  exitCodeInvalidConfig,
+			errorContains: "failed to read LUNO_API_SECRET_FILE",
+		},
+		{
+			name:          "malformed domain from environment",
+			flags:         func(f CliFlags) CliFlags { return f },
+			env:           map[string]string{config.EnvLunoAPIDomain: "api.luno.com/api"},
+			expectedCode:  exitCodeInvalidConfig,
+			errorContains: "without a path",
+		},
+		{
+			name: "reachable custom domain",
+			flags: func(f CliFlags) CliFlags {
+				f.LunoDomain = reachableDomain
+				return f
+			},
+		},
+		{
+			name: "unreachable custom domain",
+			flags: func(f CliFlags) CliFlags {
+				f.LunoDomain = "127.0.0.1:1"
+				return f
+			},
+			expectedCode:  exitCodeUnreachableDomain,
+			errorContains: "is unreachable",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(config.EnvLunoAPIKeyID, "")
+			t.Setenv(config.EnvLunoAPIKeySecret, "")
+			t.Setenv(config.EnvLunoAPIDomain, "")
+			t.Setenv(envLogFormat, "")
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			err := selfCheck(context.Background(), tt.flags(validFlags), tt.explicit, ts.Client())
+			if tt.errorContains == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorContains)
+			assert.Equal(t, tt.expectedCode, exitCode(err))
+		})
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{"startup error", &startupError{exitCodeInvalidConfig, errors.New("bad")}, exitCodeInvalidConfig},
+		{"other error", errors.New("bad"), 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, exitCode(tt.err))
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/luno/luno-mcp/internal/audit"
+)
+
+// verifyAuditLog verifies the audit log file at path for --verify-audit-log,
+// checking signatures with the key in keyPath when given, and reports the
+// result to out
+func verifyAuditLog(path, keyPath string, out io.Writer) error {
+	var key ed25519.PublicKey
+	if keyPath != "" {
+		var err error
+		if _, key, err = audit.LoadKey(keyPath); err != nil {
+			return err
+		}
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("opening audit log: %w", err)
+	}
+	defer f.Close()
+
+	v, err := audit.Verify(f, key)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	fmt.Fprintf(out, "%s: %d entries verified", path, v.Entries)
+	if key != nil {
+		fmt.Fprint(out, ", all signed by the key")
+	} else if v.Signed > 0 {
+		fmt.Fprintf(out, "; %d signed, but signatures were not checked without a key", v.Signed)
+	}
+	fmt.Fprintln(out)
+	if v.Entries > 0 {
+		fmt.Fprintf(out, "Last entry: %d, hash %s\n", v.LastSeq, v.LastHash)
+	}
+	return nil
+}
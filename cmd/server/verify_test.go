@@ -0,0 +1,46 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/luno/luno-mcp/internal/audit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyAuditLog(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	dir := t.TempDir()
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	require.NoError(t, err)
+	keyPath := filepath.Join(dir, "audit-key.pub")
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600))
+
+	path := filepath.Join(dir, "audit.jsonl")
+	l := audit.NewLog(10)
+	l.SetSigner(priv)
+	require.NoError(t, l.Open(path))
+	l.Record(audit.Entry{Tool: "create_order"})
+	last := l.Record(audit.Entry{Tool: "cancel_order"})
+
+	var out strings.Builder
+	require.NoError(t, verifyAuditLog(path, keyPath, &out))
+	assert.Equal(t, path+": 2 entries verified, all signed by the key\nLast entry: 2, hash "+last.Hash+"\n", out.String())
+
+	out.Reset()
+	require.NoError(t, verifyAuditLog(path, "", &out))
+	assert.Contains(t, out.String(), "2 signed, but signatures were not checked")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, []byte(strings.Replace(string(data), "cancel_order", "create_order", 1)), 0o600))
+	assert.ErrorContains(t, verifyAuditLog(path, keyPath, &out), "does not match its hash")
+	assert.Error(t, verifyAuditLog(filepath.Join(dir, "missing.jsonl"), "", &out))
+}
@@ -0,0 +1,216 @@
+// Package aliases keeps friendly names for Luno accounts, such as "spending"
+// or "hodl", so that they can be used in place of account IDs.
+package aliases
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luno/luno-mcp/internal/fsutil"
+	"github.com/luno/luno-mcp/internal/vault"
+)
+
+// MaxNameLength is the longest alias accepted, in bytes
+const MaxNameLength = 64
+
+// Alias names an account
+type Alias struct {
+	Name      string    `json:"alias"`
+	AccountID string    `json:"account_id"`
+	CreatedAt time.Time `json:"created_at"`
+	// Tenant is the tenant the alias belongs to, in multi-tenant deployments
+	Tenant string `json:"tenant,omitempty"`
+}
+
+// is reports whether a is the alias name of tenant
+func (a Alias) is(tenant, name string) bool {
+	return a.Tenant == tenant && strings.EqualFold(a.Name, strings.TrimSpace(name))
+}
+
+// Store holds account aliases in a JSON file. The file is read on first use
+// and rewritten whenever an alias changes. Aliases are matched ignoring case,
+// and each tenant has its own. It is safe for concurrent use.
+type Store struct {
+	path   string
+	cipher *vault.Cipher
+
+	mu      sync.Mutex
+	loaded  bool
+	aliases []Alias
+
+	now func() time.Time
+}
+
+// New creates a store kept at path. An empty path keeps aliases in memory only.
+func New(path string) *Store {
+	return &Store{path: path, now: time.Now}
+}
+
+// SetCipher encrypts the file with c. It must be called before the store is
+// used; a file written without encryption is encrypted when it is first read.
+func (s *Store) SetCipher(c *vault.Cipher) {
+	s.cipher = c
+}
+
+// Path returns where the aliases are stored, or an empty string if they are
+// only kept in memory
+func (s *Store) Path() string {
+	return s.path
+}
+
+// FileName is the name of the file the aliases are kept in by default
+const FileName = "account-aliases.json"
+
+// DefaultPath returns the default location of the aliases in the user's
+// config directory, or an empty string if there isn't one
+func DefaultPath() string {
+	return fsutil.ConfigPath(FileName)
+}
+
+// Set names accountID name for tenant, replacing any account the name was
+// given before
+func (s *Store) Set(tenant, name, accountID string) (Alias, error) {
+	name = strings.TrimSpace(name)
+	switch {
+	case name == "":
+		return Alias{}, errors.New("alias must not be empty")
+	case len(name) > MaxNameLength:
+		return Alias{}, fmt.Errorf("alias must be at most %d bytes", MaxNameLength)
+	case isNumeric(name):
+		return Alias{}, errors.New("alias must not be a number, so that it cannot be mistaken for an account ID")
+	}
+	if !isNumeric(accountID) {
+		return Alias{}, fmt.Errorf("invalid account ID %q", accountID)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return Alias{}, err
+	}
+	a := Alias{Name: name, AccountID: accountID, CreatedAt: s.now().UTC(), Tenant: tenant}
+	aliases := slices.DeleteFunc(slices.Clone(s.aliases), func(o Alias) bool { return o.is(tenant, name) })
+	aliases = append(aliases, a)
+	if err := s.save(aliases); err != nil {
+		return Alias{}, err
+	}
+	s.aliases = aliases
+	return a, nil
+}
+
+// Remove deletes the alias name of tenant, reporting whether there was one
+func (s *Store) Remove(tenant, name string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return false, err
+	}
+	aliases := slices.DeleteFunc(slices.Clone(s.aliases), func(o Alias) bool { return o.is(tenant, name) })
+	if len(aliases) == len(s.aliases) {
+		return false, nil
+	}
+	if err := s.save(aliases); err != nil {
+		return false, err
+	}
+	s.aliases = aliases
+	return true, nil
+}
+
+// Lookup returns the alias name of tenant
+func (s *Store) Lookup(tenant, name string) (Alias, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return Alias{}, false, err
+	}
+	i := slices.IndexFunc(s.aliases, func(a Alias) bool { return a.is(tenant, name) })
+	if i < 0 {
+		return Alias{}, false, nil
+	}
+	return s.aliases[i], true, nil
+}
+
+// List returns every alias of tenant, ordered by name
+func (s *Store) List(tenant string) ([]Alias, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	out := slices.DeleteFunc(slices.Clone(s.aliases), func(a Alias) bool { return a.Tenant != tenant })
+	slices.SortFunc(out, func(a, b Alias) int { return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name)) })
+	return out, nil
+}
+
+// ByAccount returns the names of each account tenant aliased, ordered by name
+func (s *Store) ByAccount(tenant string) (map[string][]string, error) {
+	aliases, err := s.List(tenant)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string][]string)
+	for _, a := range aliases {
+		out[a.AccountID] = append(out[a.AccountID], a.Name)
+	}
+	return out, nil
+}
+
+// load reads the aliases file the first time it is needed. The caller must
+// hold s.mu.
+func (s *Store) load() error {
+	if s.loaded || s.path == "" {
+		s.loaded = true
+		return nil
+	}
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		s.loaded = true
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading account aliases: %w", err)
+	}
+	sealed := vault.IsSealed(data)
+	if data, err = s.cipher.Open(data); err != nil {
+		return fmt.Errorf("reading account aliases %s: %w", s.path, err)
+	}
+	var aliases []Alias
+	if err := json.Unmarshal(data, &aliases); err != nil {
+		return fmt.Errorf("reading account aliases %s: %w", s.path, err)
+	}
+	s.aliases = aliases
+	s.loaded = true
+	if s.cipher.Enabled() && !sealed {
+		return s.save(aliases)
+	}
+	return nil
+}
+
+// save replaces the aliases file with aliases. The file is written alongside
+// and renamed into place so that it is never left half written. The caller
+// must hold s.mu.
+func (s *Store) save(aliases []Alias) error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(aliases, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding account aliases: %w", err)
+	}
+	if err := fsutil.WriteFile(s.path, append(s.cipher.Seal(data), '\n'), 0o600); err != nil {
+		return fmt.Errorf("writing account aliases: %w", err)
+	}
+	return nil
+}
+
+func isNumeric(s string) bool {
+	_, err := strconv.ParseInt(s, 10, 64)
+	return err == nil
+}
@@ -0,0 +1,130 @@
+package aliases
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-mcp/internal/vault"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "luno-mcp", "account-aliases.json")
+	s := New(path)
+	s.now = func() time.Time { return time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC) }
+
+	spending, err := s.Set("", "  Spending ", "100")
+	require.NoError(t, err)
+	assert.Equal(t, Alias{Name: "Spending", AccountID: "100", CreatedAt: s.now()}, spending)
+	_, err = s.Set("", "hodl", "200")
+	require.NoError(t, err)
+	_, err = s.Set("", "cold", "200")
+	require.NoError(t, err)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// A new store on the same file sees everything written so far
+	reopened := New(path)
+	got, ok, err := reopened.Lookup("", "SPENDING")
+	require.NoError(t, err)
+	assert.True(t, ok, "aliases match ignoring case")
+	assert.Equal(t, spending, got)
+
+	byAccount, err := reopened.ByAccount("")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"100": {"Spending"}, "200": {"cold", "hodl"}}, byAccount)
+
+	// Setting an alias again moves it to the new account
+	_, err = reopened.Set("", "spending", "300")
+	require.NoError(t, err)
+	list, err := reopened.List("")
+	require.NoError(t, err)
+	require.Len(t, list, 3)
+	assert.Equal(t, "cold", list[0].Name)
+	assert.Equal(t, Alias{Name: "spending", AccountID: "300", CreatedAt: list[2].CreatedAt}, list[2])
+
+	removed, err := reopened.Remove("", "Hodl")
+	require.NoError(t, err)
+	assert.True(t, removed)
+	removed, err = reopened.Remove("", "hodl")
+	require.NoError(t, err)
+	assert.False(t, removed)
+
+	_, ok, err = New(path).Lookup("", "hodl")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestStoreSetInvalid(t *testing.T) {
+	s := New("")
+	for name, tc := range map[string]struct{ alias, accountID, err string }{
+		"empty":           {alias: " ", accountID: "100", err: "must not be empty"},
+		"too long":        {alias: strings.Repeat("a", MaxNameLength+1), accountID: "100", err: "at most 64 bytes"},
+		"numeric":         {alias: "12345", accountID: "100", err: "must not be a number"},
+		"invalid account": {alias: "spending", accountID: "XBT", err: "invalid account ID"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := s.Set("", tc.alias, tc.accountID)
+			assert.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestStoreCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "account-aliases.json")
+	require.NoError(t, os.WriteFile(path, []byte("not json"), 0o600))
+	_, err := New(path).List("")
+	assert.ErrorContains(t, err, "reading account aliases")
+}
+
+func TestStoreEncrypted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "account-aliases.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[{"alias":"hodl","account_id":"200"}]`), 0o600))
+	c, err := vault.New(bytes.Repeat([]byte{1}, vault.KeySize))
+	require.NoError(t, err)
+
+	s := New(path)
+	s.SetCipher(c)
+	got, ok, err := s.Lookup("", "hodl")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "200", got.AccountID)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.True(t, vault.IsSealed(data), "a file written before encryption is encrypted when read")
+
+	_, _, err = New(path).Lookup("", "hodl")
+	assert.ErrorIs(t, err, vault.ErrEncrypted)
+}
+
+func TestStoreTenants(t *testing.T) {
+	s := New("")
+	_, err := s.Set("acme", "spending", "100")
+	require.NoError(t, err)
+	_, err = s.Set("globex", "spending", "200")
+	require.NoError(t, err)
+
+	got, ok, err := s.Lookup("acme", "Spending")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "100", got.AccountID)
+
+	_, ok, err = s.Lookup("", "spending")
+	require.NoError(t, err)
+	assert.False(t, ok, "aliases of tenants are not found without one")
+
+	removed, err := s.Remove("globex", "spending")
+	require.NoError(t, err)
+	assert.True(t, removed)
+	list, err := s.List("acme")
+	require.NoError(t, err)
+	assert.Len(t, list, 1, "removing one tenant's alias leaves another's")
+}
@@ -0,0 +1,301 @@
+// Package approvals holds high-value actions, such as large orders and
+// withdrawals, until someone outside the MCP session approves them. Actions
+// worth more than a threshold are parked in a Queue as pending Requests, and
+// an approver decides them through the admin API or a command. Once approved,
+// the same call can be made once more to run it.
+package approvals
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luno/luno-go/decimal"
+)
+
+const (
+	// DefaultTimeout is how long a request waits for a decision, and then
+	// how long an approved request waits to be run, before it expires
+	DefaultTimeout = 30 * time.Minute
+
+	// maxRequests bounds the number of requests kept, dropping the oldest
+	// decided ones first
+	maxRequests = 1000
+)
+
+var (
+	// ErrNotFound is returned for a request ID the queue doesn't hold
+	ErrNotFound = errors.New("approval request not found")
+	// ErrDecided is returned when deciding a request that is no longer pending
+	ErrDecided = errors.New("approval request is no longer pending")
+)
+
+// Status is where a Request is in its lifecycle
+type Status string
+
+const (
+	StatusPending  Status = "pending"
+	StatusApproved Status = "approved"
+	StatusRejected Status = "rejected"
+	StatusExpired  Status = "expired"
+	// StatusExecuted is an approved request whose call has been run
+	StatusExecuted Status = "executed"
+)
+
+// Amount is what an action is worth in one currency
+type Amount struct {
+	Currency string          `json:"currency"`
+	Value    decimal.Decimal `json:"value"`
+}
+
+// Thresholds are the largest amounts, per currency, that actions may be worth
+// without approval
+type Thresholds map[string]decimal.Decimal
+
+// ParseThresholds parses a comma-separated list of currencies with amounts,
+// such as "ZAR:50000,XBT:0.5"
+func ParseThresholds(s string) (Thresholds, error) {
+	t := make(Thresholds)
+	for item := range strings.SplitSeq(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		currency, amount, ok := strings.Cut(item, ":")
+		currency = strings.ToUpper(strings.TrimSpace(currency))
+		if currency == "" || !ok {
+			return nil, fmt.Errorf("approval threshold %q must be a currency and amount, such as ZAR:50000", item)
+		}
+		if _, seen := t[currency]; seen {
+			return nil, fmt.Errorf("approval threshold for %s is given more than once", currency)
+		}
+		d, err := decimal.NewFromString(strings.TrimSpace(amount))
+		if err != nil || d.Sign() < 0 {
+			return nil, fmt.Errorf("approval threshold %q must have an amount of 0 or more", item)
+		}
+		t[currency] = d
+	}
+	if len(t) == 0 {
+		return nil, errors.New("no approval thresholds given")
+	}
+	return t, nil
+}
+
+// Exceeded returns the amounts above their currency's threshold. Currencies
+// without a threshold are never exceeded.
+func (t Thresholds) Exceeded(amounts []Amount) []Amount {
+	var out []Amount
+	for _, a := range amounts {
+		if limit, ok := t[a.Currency]; ok && a.Value.Cmp(limit) > 0 {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// String lists the thresholds in the form ParseThresholds reads
+func (t Thresholds) String() string {
+	items := make([]string, 0, len(t))
+	for currency, limit := range t {
+		items = append(items, currency+":"+limit.String())
+	}
+	slices.Sort(items)
+	return strings.Join(items, ",")
+}
+
+// Request is an action waiting for, or given, a decision
+type Request struct {
+	ID        string         `json:"id"`
+	SessionID string         `json:"session_id,omitempty"`
+	Tool      string         `json:"tool"`
+	Arguments map[string]any `json:"arguments"`
+	// Amounts are what the action is worth. Exceeded are those above their
+	// threshold, or empty when the worth couldn't be worked out.
+	Amounts  []Amount `json:"amounts"`
+	Exceeded []Amount `json:"exceeded"`
+	Note     string   `json:"note,omitempty"`
+
+	Status Status `json:"status"`
+	// Reason is the approver's reason for their decision
+	Reason    string    `json:"reason,omitempty"`
+	DecidedBy string    `json:"decided_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	DecidedAt time.Time `json:"decided_at,omitzero"`
+	ExpiresAt time.Time `json:"expires_at"`
+
+	key string
+}
+
+// Queue holds approval requests in memory. It is safe for concurrent use.
+type Queue struct {
+	thresholds Thresholds
+	timeout    time.Duration
+	now        func() time.Time
+
+	mu       sync.Mutex
+	requests []*Request
+	watchers []func(Request)
+}
+
+// NewQueue creates a queue holding actions above thresholds for timeout
+func NewQueue(thresholds Thresholds, timeout time.Duration) *Queue {
+	return &Queue{thresholds: thresholds, timeout: timeout, now: time.Now}
+}
+
+// Thresholds returns the thresholds actions are held above
+func (q *Queue) Thresholds() Thresholds {
+	return q.thresholds
+}
+
+// Watch calls f, in a goroutine of its own, with each new request, e.g. to
+// ask an approver about it
+func (q *Queue) Watch(f func(Request)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.watchers = append(q.watchers, f)
+}
+
+// Park adds a pending request for a call. A call identical to one already
+// pending gets that request back, so that retries don't ask twice.
+func (q *Queue) Park(sessionID, tool string, args map[string]any, amounts, exceeded []Amount, note string) (Request, error) {
+	key, err := callKey(sessionID, tool, args)
+	if err != nil {
+		return Request{}, err
+	}
+	now := q.now()
+	q.mu.Lock()
+	q.expire(now)
+	for _, r := range q.requests {
+		if r.key == key && r.Status == StatusPending {
+			q.mu.Unlock()
+			return *r, nil
+		}
+	}
+	r := &Request{
+		ID:        rand.Text(),
+		SessionID: sessionID,
+		Tool:      tool,
+		Arguments: maps.Clone(args),
+		Amounts:   amounts,
+		Exceeded:  exceeded,
+		Note:      note,
+		Status:    StatusPending,
+		CreatedAt: now.UTC(),
+		ExpiresAt: now.Add(q.timeout).UTC(),
+		key:       key,
+	}
+	q.requests = append(q.requests, r)
+	q.trim()
+	req, watchers := *r, q.watchers
+	q.mu.Unlock()
+
+	for _, f := range watchers {
+		go f(req)
+	}
+	return req, nil
+}
+
+// Take marks the approved request for an identical call as executed and
+// returns it. Each approval runs its call once.
+func (q *Queue) Take(sessionID, tool string, args map[string]any) (Request, bool) {
+	key, err := callKey(sessionID, tool, args)
+	if err != nil {
+		return Request{}, false
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.expire(q.now())
+	for _, r := range q.requests {
+		if r.key == key && r.Status == StatusApproved {
+			r.Status = StatusExecuted
+			return *r, true
+		}
+	}
+	return Request{}, false
+}
+
+// Get returns the request with id
+func (q *Queue) Get(id string) (Request, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.expire(q.now())
+	for _, r := range q.requests {
+		if r.ID == id {
+			return *r, true
+		}
+	}
+	return Request{}, false
+}
+
+// List returns every request, newest first
+func (q *Queue) List() []Request {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.expire(q.now())
+	out := make([]Request, 0, len(q.requests))
+	for _, r := range slices.Backward(q.requests) {
+		out = append(out, *r)
+	}
+	return out
+}
+
+// Decide approves or rejects the pending request with id. An approved
+// request can then be run until it expires, timeout after the decision.
+func (q *Queue) Decide(id string, approve bool, by, reason string) (Request, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	now := q.now()
+	q.expire(now)
+	for _, r := range q.requests {
+		if r.ID != id {
+			continue
+		}
+		if r.Status != StatusPending {
+			return *r, ErrDecided
+		}
+		r.Status = StatusRejected
+		if approve {
+			r.Status = StatusApproved
+			r.ExpiresAt = now.Add(q.timeout).UTC()
+		}
+		r.DecidedBy, r.Reason, r.DecidedAt = by, reason, now.UTC()
+		return *r, nil
+	}
+	return Request{}, ErrNotFound
+}
+
+// expire marks pending and approved requests past their expiry as expired
+func (q *Queue) expire(now time.Time) {
+	for _, r := range q.requests {
+		if (r.Status == StatusPending || r.Status == StatusApproved) && !now.Before(r.ExpiresAt) {
+			r.Status = StatusExpired
+		}
+	}
+}
+
+// trim drops the oldest requests that can no longer run once there are too many
+func (q *Queue) trim() {
+	for i := 0; len(q.requests) > maxRequests && i < len(q.requests); {
+		if s := q.requests[i].Status; s == StatusPending || s == StatusApproved {
+			i++
+			continue
+		}
+		q.requests = slices.Delete(q.requests, i, i+1)
+	}
+}
+
+// callKey identifies a call, so that an approval only runs the call it was
+// given for. Maps marshal with sorted keys, so equal arguments match.
+func callKey(sessionID, tool string, args map[string]any) (string, error) {
+	b, err := json.Marshal(args)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode arguments: %w", err)
+	}
+	return sessionID + "\x00" + tool + "\x00" + string(b), nil
+}
@@ -0,0 +1,107 @@
+package approvals
+
+import (
+	"testing"
+	"time"
+
+	"github.com/luno/luno-go/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func amount(currency, value string) Amount {
+	d, err := decimal.NewFromString(value)
+	if err != nil {
+		panic(err)
+	}
+	return Amount{Currency: currency, Value: d}
+}
+
+func TestParseThresholds(t *testing.T) {
+	thresholds, err := ParseThresholds(" zar:50000, XBT:0.5,")
+	require.NoError(t, err)
+	assert.Equal(t, "XBT:0.5,ZAR:50000", thresholds.String())
+
+	exceeded := thresholds.Exceeded([]Amount{amount("ZAR", "50000"), amount("XBT", "0.51"), amount("ETH", "100")})
+	assert.Equal(t, []Amount{amount("XBT", "0.51")}, exceeded, "only amounts above a threshold of their currency are exceeded")
+
+	for _, spec := range []string{"", "ZAR", ":100", "ZAR:-1", "ZAR:lots", "ZAR:1,zar:2"} {
+		_, err := ParseThresholds(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestQueue(t *testing.T) {
+	thresholds, err := ParseThresholds("ZAR:1000")
+	require.NoError(t, err)
+	q := NewQueue(thresholds, time.Minute)
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	q.now = func() time.Time { return now }
+	args := map[string]any{"pair": "XBTZAR", "volume": "1", "price": "2000"}
+
+	r, err := q.Park("session-1", "create_order", args, []Amount{amount("ZAR", "2000")}, []Amount{amount("ZAR", "2000")}, "")
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, r.Status)
+	assert.Equal(t, now.Add(time.Minute), r.ExpiresAt)
+
+	again, err := q.Park("session-1", "create_order", map[string]any{"price": "2000", "volume": "1", "pair": "XBTZAR"}, nil, nil, "")
+	require.NoError(t, err)
+	assert.Equal(t, r.ID, again.ID, "an identical call gets the pending request back")
+	other, err := q.Park("session-2", "create_order", args, nil, nil, "")
+	require.NoError(t, err)
+	assert.NotEqual(t, r.ID, other.ID, "another session's call is a request of its own")
+
+	_, ok := q.Take("session-1", "create_order", args)
+	assert.False(t, ok, "pending requests can't be run")
+
+	now = now.Add(30 * time.Second)
+	decided, err := q.Decide(r.ID, true, "admin", "looks fine")
+	require.NoError(t, err)
+	assert.Equal(t, StatusApproved, decided.Status)
+	assert.Equal(t, "admin", decided.DecidedBy)
+	assert.Equal(t, now.Add(time.Minute), decided.ExpiresAt, "approved requests can be run for the timeout after the decision")
+	_, err = q.Decide(r.ID, false, "admin", "")
+	assert.ErrorIs(t, err, ErrDecided)
+	_, err = q.Decide("unknown", true, "admin", "")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, ok = q.Take("session-1", "create_order", map[string]any{"pair": "XBTZAR", "volume": "2", "price": "2000"})
+	assert.False(t, ok, "an approval only runs the call it was given for")
+	taken, ok := q.Take("session-1", "create_order", args)
+	require.True(t, ok)
+	assert.Equal(t, r.ID, taken.ID)
+	_, ok = q.Take("session-1", "create_order", args)
+	assert.False(t, ok, "an approval runs its call once")
+
+	got, ok := q.Get(r.ID)
+	require.True(t, ok)
+	assert.Equal(t, StatusExecuted, got.Status)
+	got, ok = q.Get(other.ID)
+	require.True(t, ok)
+	assert.Equal(t, StatusPending, got.Status)
+
+	now = now.Add(time.Minute)
+	list := q.List()
+	require.Len(t, list, 2)
+	assert.Equal(t, other.ID, list[0].ID, "newest first")
+	assert.Equal(t, StatusExpired, list[0].Status)
+	_, err = q.Decide(other.ID, true, "admin", "")
+	assert.ErrorIs(t, err, ErrDecided, "expired requests can't be approved")
+}
+
+func TestQueueWatch(t *testing.T) {
+	thresholds, err := ParseThresholds("ZAR:1000")
+	require.NoError(t, err)
+	q := NewQueue(thresholds, time.Minute)
+	seen := make(chan Request, 1)
+	q.Watch(func(r Request) { seen <- r })
+
+	r, err := q.Park("session-1", "create_order", map[string]any{"volume": "1"}, nil, nil, "")
+	require.NoError(t, err)
+	select {
+	case got := <-seen:
+		assert.Equal(t, r.ID, got.ID)
+	case <-time.After(time.Second):
+		t.Fatal("watcher not called")
+	}
+}
@@ -0,0 +1,49 @@
+package approvals
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os/exec"
+	"strings"
+)
+
+// commandApprover is the DecidedBy of requests decided by a command
+const commandApprover = "command"
+
+// CommandApprover returns a watcher for q that runs command to decide each
+// request. The request is written to the command's standard input as JSON.
+// Exiting with status 0 approves it and 1 rejects it, with the first line the
+// command prints as the reason. Anything else, such as the command not
+// finishing before the request expires, leaves the request pending for
+// another approver.
+func CommandApprover(q *Queue, command []string) func(Request) {
+	return func(r Request) {
+		log := slog.With(slog.String("approval_id", r.ID), slog.String("tool", r.Tool))
+		input, err := json.Marshal(r)
+		if err != nil {
+			log.Warn("Failed to encode approval request for the approval command", slog.Any("error", err))
+			return
+		}
+		ctx, cancel := context.WithDeadline(context.Background(), r.ExpiresAt)
+		defer cancel()
+		cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+		cmd.Stdin = bytes.NewReader(input)
+		out, err := cmd.Output()
+
+		var exitErr *exec.ExitError
+		approve := err == nil
+		if !approve && (!errors.As(err, &exitErr) || exitErr.ExitCode() != 1) {
+			log.Warn("Approval command failed; the request is left pending", slog.Any("error", err))
+			return
+		}
+		reason, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+		if _, err := q.Decide(r.ID, approve, commandApprover, strings.TrimSpace(reason)); err != nil {
+			log.Info("Approval command's decision not recorded", slog.Any("error", err))
+			return
+		}
+		log.Info("Approval request decided by the approval command", slog.Bool("approved", approve))
+	}
+}
@@ -0,0 +1,42 @@
+package approvals
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandApprover(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	thresholds, err := ParseThresholds("ZAR:1000")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name           string
+		script         string
+		expectedStatus Status
+		expectedReason string
+	}{
+		{name: "approve", script: `grep -q '"tool":"create_order"' && echo "within the desk's limits"`, expectedStatus: StatusApproved, expectedReason: "within the desk's limits"},
+		{name: "reject", script: "echo 'too large'; echo more; exit 1", expectedStatus: StatusRejected, expectedReason: "too large"},
+		{name: "failure leaves pending", script: "exit 2", expectedStatus: StatusPending},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := NewQueue(thresholds, time.Minute)
+			r, err := q.Park("session-1", "create_order", map[string]any{"volume": "1"}, nil, nil, "")
+			require.NoError(t, err)
+
+			CommandApprover(q, []string{"sh", "-c", tc.script})(r)
+			got, ok := q.Get(r.ID)
+			require.True(t, ok)
+			assert.Equal(t, tc.expectedStatus, got.Status)
+			assert.Equal(t, tc.expectedReason, got.Reason)
+		})
+	}
+}
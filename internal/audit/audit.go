@@ -0,0 +1,157 @@
+// Package audit keeps a record of the tool calls made to the server, so users
+// can review everything an agent did. Entries are chained by hash, and can be
+// signed and kept in a file, so the record can be shown to be untampered.
+package audit
+
+import (
+	"crypto/ed25519"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/luno/luno-mcp/internal/balances"
+)
+
+// DefaultCapacity is how many entries a Log keeps by default
+const DefaultCapacity = 1000
+
+// Entry is a tool call in the audit log
+type Entry struct {
+	// Seq numbers entries in the order they were recorded, starting at 1
+	Seq  int64     `json:"seq"`
+	Time time.Time `json:"time"`
+	Tool string    `json:"tool"`
+	// Arguments are the call's arguments as JSON, with secrets redacted
+	Arguments string `json:"arguments,omitempty"`
+	SessionID string `json:"session_id,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+	// Tenant is the tenant of the session, in multi-tenant deployments
+	Tenant     string `json:"tenant,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+	// Error is set when the call failed
+	Error string `json:"error,omitempty"`
+	// BalanceChanges are the changes in account balances made by a call to a
+	// trading tool
+	BalanceChanges []balances.Change `json:"balance_changes,omitempty"`
+
+	// PrevHash is the Hash of the entry before, empty for the first entry
+	PrevHash string `json:"prev_hash,omitempty"`
+	// Hash is the hex SHA-256 of the entry without Hash and Signature
+	Hash string `json:"hash,omitempty"`
+	// Signature is the base64 Ed25519 signature of Hash, when the log is signed
+	Signature string `json:"signature,omitempty"`
+	// WriteFailed is set on entries held in memory that couldn't be written
+	// to the log's file yet. It is never written itself.
+	WriteFailed bool `json:"write_failed,omitempty"`
+}
+
+// Log is an in-memory audit log holding the most recent entries. It is safe
+// for concurrent use.
+type Log struct {
+	mu       sync.Mutex
+	entries  []Entry
+	capacity int
+	lastSeq  int64
+	lastHash string
+	// pending are entries not yet written to the file, oldest first
+	pending []Entry
+
+	signer ed25519.PrivateKey
+	path   string
+}
+
+// NewLog creates a Log that keeps the last capacity entries. A capacity below
+// 1 uses DefaultCapacity.
+func NewLog(capacity int) *Log {
+	if capacity < 1 {
+		capacity = DefaultCapacity
+	}
+	return &Log{capacity: capacity}
+}
+
+// Record adds e to the log, assigning its sequence number and chaining it to
+// the entry before, and drops the oldest entry once the log is full. With a
+// file open, the entry is appended to it. A failure to write is logged, as
+// the call it records has already been made, and the entry is kept with
+// WriteFailed set. Unwritten entries are written ahead of the next one, so
+// the file stays a complete chain once writes succeed again. At most the log's
+// capacity of them are held; older ones are dropped, which Verify then reports
+// as a break in the chain.
+func (l *Log) Record(e Entry) Entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e.Seq = l.lastSeq + 1
+	if err := l.seal(&e); err != nil {
+		slog.Error("Failed to chain audit log entry", slog.Int64("seq", e.Seq), slog.Any("error", err))
+	}
+	l.lastSeq, l.lastHash = e.Seq, e.Hash
+
+	if len(l.pending) == l.capacity {
+		slog.Error("Dropping unwritten audit log entry", slog.Int64("seq", l.pending[0].Seq), slog.String("path", l.path))
+		l.pending = l.pending[1:]
+	}
+	l.pending = append(l.pending, e)
+	written, err := l.flush()
+	if err != nil {
+		slog.Error("Failed to write audit log entry", slog.Int64("seq", e.Seq), slog.String("tool", e.Tool),
+			slog.String("session_id", e.SessionID), slog.String("path", l.path), slog.Any("error", err))
+	}
+	for i := range l.entries {
+		if s := l.entries[i].Seq; s >= written.from && s <= written.to {
+			l.entries[i].WriteFailed = false
+		}
+	}
+	e.WriteFailed = len(l.pending) > 0
+	l.keep(e)
+	return e
+}
+
+// seqRange is the sequence numbers from and to, inclusive
+type seqRange struct{ from, to int64 }
+
+// flush writes the pending entries in order, stopping at the first that fails,
+// and returns the range of those written. The caller must hold l.mu.
+func (l *Log) flush() (seqRange, error) {
+	var written seqRange
+	for len(l.pending) > 0 {
+		e := l.pending[0]
+		if err := l.append(e); err != nil {
+			return written, err
+		}
+		if written.from == 0 {
+			written.from = e.Seq
+		}
+		written.to = e.Seq
+		l.pending = l.pending[1:]
+	}
+	return written, nil
+}
+
+// keep adds e to the entries held in memory. The caller must hold l.mu.
+func (l *Log) keep(e Entry) {
+	if len(l.entries) == l.capacity {
+		l.entries = append(l.entries[:0], l.entries[1:]...)
+	}
+	l.entries = append(l.entries, e)
+}
+
+// Recent returns up to limit entries of tenant, newest first, starting after
+// the entry numbered before. A before of 0 starts at the newest entry. next is
+// the before value for the following page, or 0 when there are no older
+// entries.
+func (l *Log) Recent(tenant string, before int64, limit int) (entries []Entry, next int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entries = []Entry{}
+	for i := len(l.entries) - 1; i >= 0; i-- {
+		e := l.entries[i]
+		if e.Tenant != tenant || (before > 0 && e.Seq >= before) {
+			continue
+		}
+		if len(entries) == limit {
+			return entries, entries[len(entries)-1].Seq
+		}
+		entries = append(entries, e)
+	}
+	return entries, 0
+}
@@ -0,0 +1,71 @@
+package audit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func seqs(entries []Entry) []int64 {
+	out := make([]int64, len(entries))
+	for i, e := range entries {
+		out[i] = e.Seq
+	}
+	return out
+}
+
+func TestLogRecent(t *testing.T) {
+	l := NewLog(4)
+	entries, next := l.Recent("", 0, 10)
+	assert.Empty(t, entries)
+	assert.Zero(t, next)
+
+	for range 6 {
+		l.Record(Entry{Tool: "get_ticker"})
+	}
+
+	// Only the last four are kept
+	entries, next = l.Recent("", 0, 10)
+	assert.Equal(t, []int64{6, 5, 4, 3}, seqs(entries))
+	assert.Zero(t, next)
+
+	entries, next = l.Recent("", 0, 3)
+	assert.Equal(t, []int64{6, 5, 4}, seqs(entries))
+	assert.Equal(t, int64(4), next)
+
+	entries, next = l.Recent("", next, 3)
+	assert.Equal(t, []int64{3}, seqs(entries))
+	assert.Zero(t, next)
+
+	entries, next = l.Recent("", 0, 4)
+	assert.Len(t, entries, 4)
+	assert.Zero(t, next, "no next page when the last entry fills the page")
+}
+
+func TestLogRecentTenants(t *testing.T) {
+	l := NewLog(10)
+	l.Record(Entry{Tool: "get_ticker", Tenant: "acme"})
+	l.Record(Entry{Tool: "create_order", Tenant: "globex"})
+	l.Record(Entry{Tool: "get_balances", Tenant: "acme"})
+	l.Record(Entry{Tool: "get_ticker"})
+
+	entries, next := l.Recent("acme", 0, 1)
+	assert.Equal(t, []int64{3}, seqs(entries))
+	entries, next = l.Recent("acme", next, 1)
+	assert.Equal(t, []int64{1}, seqs(entries))
+	assert.Zero(t, next)
+
+	entries, _ = l.Recent("globex", 0, 10)
+	assert.Equal(t, []int64{2}, seqs(entries))
+	entries, _ = l.Recent("", 0, 10)
+	assert.Equal(t, []int64{4}, seqs(entries), "entries without a tenant are kept apart too")
+}
+
+func TestNewLogDefaultCapacity(t *testing.T) {
+	l := NewLog(0)
+	assert.Equal(t, DefaultCapacity, l.capacity)
+
+	e := l.Record(Entry{Tool: "create_order", Error: "insufficient balance"})
+	assert.Equal(t, int64(1), e.Seq)
+	assert.Equal(t, "create_order", e.Tool)
+}
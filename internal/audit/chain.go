@@ -0,0 +1,230 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// maxLineBytes bounds the length of an entry in an audit log file
+const maxLineBytes = 1024 * 1024
+
+// SetSigner signs each entry recorded from now on with key. It must be
+// called before the log is used.
+func (l *Log) SetSigner(key ed25519.PrivateKey) {
+	l.signer = key
+}
+
+// Open keeps the log in the file at path as JSON lines, creating it if needed.
+// An existing file is verified first, and its chain is continued, so that the
+// file stays verifiable across restarts. Its latest entries are loaded into
+// memory. It must be called before the log is used.
+func (l *Log) Open(path string) error {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		l.path = path
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("opening audit log: %w", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	_, err = verify(f, nil, func(e Entry) {
+		if len(entries) == l.capacity {
+			entries = entries[1:]
+		}
+		entries = append(entries, e)
+	})
+	if err != nil {
+		return fmt.Errorf("audit log %s fails verification, move it aside to start a new one: %w", path, err)
+	}
+	if len(entries) > 0 {
+		last := entries[len(entries)-1]
+		l.lastSeq, l.lastHash = last.Seq, last.Hash
+	}
+	l.entries = append(make([]Entry, 0, l.capacity), entries...)
+	l.path = path
+	return nil
+}
+
+// Path returns the file the log is kept in, or an empty string if it is only
+// kept in memory
+func (l *Log) Path() string {
+	return l.path
+}
+
+// seal chains e to the entry before and signs it. The caller must hold l.mu.
+func (l *Log) seal(e *Entry) error {
+	e.PrevHash, e.Hash, e.Signature = l.lastHash, "", ""
+	b, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("encoding audit log entry: %w", err)
+	}
+	if e.Hash, err = digest(b); err != nil {
+		return err
+	}
+	if l.signer != nil {
+		e.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(l.signer, []byte(e.Hash)))
+	}
+	return nil
+}
+
+// append writes e to the end of the log's file. A partly written line is
+// truncated, so the next entry starts on a line of its own. The caller must
+// hold l.mu.
+func (l *Log) append(e Entry) error {
+	if l.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("encoding audit log entry: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o700); err != nil {
+		return fmt.Errorf("creating audit log directory: %w", err)
+	}
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("opening audit log: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("opening audit log: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		_ = f.Truncate(info.Size())
+		f.Close()
+		return fmt.Errorf("writing audit log: %w", err)
+	}
+	return f.Close()
+}
+
+// digest returns the hash of an encoded entry. The entry is decoded and
+// encoded again with sorted keys and without its hash and signature, so the
+// hash doesn't depend on field order or on fields added in later versions
+// being left out of older entries.
+func digest(data []byte) (string, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var fields map[string]any
+	if err := dec.Decode(&fields); err != nil {
+		return "", fmt.Errorf("decoding audit log entry: %w", err)
+	}
+	delete(fields, "hash")
+	delete(fields, "signature")
+	canonical, err := json.Marshal(fields)
+	if err != nil {
+		return "", fmt.Errorf("encoding audit log entry: %w", err)
+	}
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Verification is the result of verifying an audit log file
+type Verification struct {
+	// Entries is how many entries the file holds, and Signed how many of
+	// them carry a signature
+	Entries int
+	Signed  int
+	// LastSeq and LastHash identify the last entry. Entries removed from the
+	// end of a file leave a valid chain, so compare them with a copy kept
+	// elsewhere to show nothing was.
+	LastSeq  int64
+	LastHash string
+}
+
+// Verify checks that every entry of the audit log in r is chained to the one
+// before. With a public key, every entry must also carry a valid signature
+// by its private key; without one, signatures aren't checked.
+func Verify(r io.Reader, key ed25519.PublicKey) (Verification, error) {
+	return verify(r, key, nil)
+}
+
+// verify is Verify, calling each with every entry once it is verified
+func verify(r io.Reader, key ed25519.PublicKey, each func(Entry)) (Verification, error) {
+	var v Verification
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineBytes)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return v, fmt.Errorf("line %d: %w", line, err)
+		}
+		if v.Entries > 0 && e.Seq != v.LastSeq+1 {
+			return v, fmt.Errorf("line %d: entry %d follows entry %d", line, e.Seq, v.LastSeq)
+		}
+		if e.PrevHash != v.LastHash {
+			return v, fmt.Errorf("line %d: entry %d is not chained to the entry before it", line, e.Seq)
+		}
+		hash, err := digest(scanner.Bytes())
+		if err != nil {
+			return v, fmt.Errorf("line %d: %w", line, err)
+		}
+		if e.Hash != hash {
+			return v, fmt.Errorf("line %d: entry %d does not match its hash", line, e.Seq)
+		}
+		if e.Signature != "" {
+			v.Signed++
+		}
+		if key != nil {
+			sig, err := base64.StdEncoding.DecodeString(e.Signature)
+			if err != nil || !ed25519.Verify(key, []byte(e.Hash), sig) {
+				return v, fmt.Errorf("line %d: entry %d is not signed by the key", line, e.Seq)
+			}
+		}
+		v.Entries++
+		v.LastSeq, v.LastHash = e.Seq, e.Hash
+		if each != nil {
+			each(e)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return v, fmt.Errorf("reading audit log: %w", err)
+	}
+	return v, nil
+}
+
+// LoadKey reads an Ed25519 key from a PEM file, such as one written by
+// openssl genpkey -algorithm ed25519. A file holding a private key returns
+// both keys; one holding only a public key, which can verify but not sign,
+// returns a nil private key.
+func LoadKey(path string) (ed25519.PrivateKey, ed25519.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading audit log key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, nil, fmt.Errorf("audit log key %s is not a PEM file", path)
+	}
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if priv, ok := key.(ed25519.PrivateKey); err == nil && ok {
+			return priv, priv.Public().(ed25519.PublicKey), nil
+		}
+	case "PUBLIC KEY":
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if pub, ok := key.(ed25519.PublicKey); err == nil && ok {
+			return nil, pub, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("audit log key %s is not an Ed25519 private or public key", path)
+}
@@ -0,0 +1,200 @@
+package audit
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-mcp/internal/balances"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func recordCalls(l *Log, n int) {
+	at := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	for i := range n {
+		l.Record(Entry{
+			Time:           at.Add(time.Duration(i) * time.Minute),
+			Tool:           "create_order",
+			Arguments:      `{"pair":"XBTZAR","price":"<1000000>"}`,
+			BalanceChanges: []balances.Change{{AccountID: "1", Asset: "ZAR", Change: "0", ReservedChange: "+1000", Balance: "5000"}},
+		})
+	}
+}
+
+func readLines(t *testing.T, path string) []string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+func TestLogChain(t *testing.T) {
+	l := NewLog(10)
+	recordCalls(l, 3)
+	entries, _ := l.Recent("", 0, 10)
+
+	assert.Empty(t, entries[2].PrevHash)
+	assert.Equal(t, entries[2].Hash, entries[1].PrevHash)
+	assert.Equal(t, entries[1].Hash, entries[0].PrevHash)
+	assert.Len(t, entries[0].Hash, 64)
+	assert.Empty(t, entries[0].Signature)
+}
+
+func TestLogOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "audit-log.jsonl")
+	l := NewLog(2)
+	require.NoError(t, l.Open(path))
+	assert.Equal(t, path, l.Path())
+	recordCalls(l, 3)
+
+	// A restart continues the chain, and loads the latest entries
+	l = NewLog(2)
+	require.NoError(t, l.Open(path))
+	entries, _ := l.Recent("", 0, 10)
+	assert.Equal(t, []int64{3, 2}, seqs(entries))
+	e := l.Record(Entry{Tool: "cancel_order"})
+	assert.Equal(t, int64(4), e.Seq)
+	assert.Equal(t, entries[0].Hash, e.PrevHash)
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	v, err := Verify(f, nil)
+	require.NoError(t, err)
+	assert.Equal(t, Verification{Entries: 4, LastSeq: 4, LastHash: e.Hash}, v)
+}
+
+func TestLogWriteFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit-log.jsonl")
+	l := NewLog(10)
+	require.NoError(t, l.Open(path))
+	recordCalls(l, 2)
+
+	// A directory in place of the file fails the next write
+	require.NoError(t, os.Rename(path, path+".moved"))
+	require.NoError(t, os.Mkdir(path, 0o700))
+	failed := l.Record(Entry{Tool: "cancel_order"})
+	assert.True(t, failed.WriteFailed)
+	entries, _ := l.Recent("", 0, 10)
+	assert.Equal(t, []int64{3, 2, 1}, seqs(entries), "the unwritten entry is kept")
+	assert.True(t, entries[0].WriteFailed)
+	require.NoError(t, os.Remove(path))
+	require.NoError(t, os.Rename(path+".moved", path))
+
+	e := l.Record(Entry{Tool: "create_order"})
+	assert.Equal(t, int64(4), e.Seq)
+	assert.False(t, e.WriteFailed)
+	assert.Equal(t, failed.Hash, e.PrevHash)
+	entries, _ = l.Recent("", 0, 10)
+	assert.Equal(t, []int64{4, 3, 2, 1}, seqs(entries))
+	assert.False(t, entries[1].WriteFailed, "the unwritten entry is written ahead of the next")
+	assert.NotContains(t, strings.Join(readLines(t, path), "\n"), "write_failed")
+
+	// A restart verifies the file and continues it
+	l = NewLog(10)
+	require.NoError(t, l.Open(path))
+	e = l.Record(Entry{Tool: "get_ticker"})
+	assert.Equal(t, int64(5), e.Seq)
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	v, err := Verify(f, nil)
+	require.NoError(t, err)
+	assert.Equal(t, Verification{Entries: 5, LastSeq: 5, LastHash: e.Hash}, v)
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit-log.jsonl")
+	l := NewLog(10)
+	require.NoError(t, l.Open(path))
+	recordCalls(l, 3)
+	lines := readLines(t, path)
+
+	testCases := map[string][]string{
+		"edited":    {lines[0], strings.Replace(lines[1], "XBTZAR", "ETHZAR", 1), lines[2]},
+		"removed":   {lines[0], lines[2]},
+		"reordered": {lines[0], lines[2], lines[1]},
+		"truncated": {lines[1], lines[2]},
+	}
+	for name, tampered := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := Verify(strings.NewReader(strings.Join(tampered, "\n")), nil)
+			assert.Error(t, err)
+
+			require.NoError(t, os.WriteFile(path, []byte(strings.Join(tampered, "\n")), 0o600))
+			assert.ErrorContains(t, NewLog(10).Open(path), "fails verification")
+		})
+	}
+}
+
+func TestLogSigned(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "audit-log.jsonl")
+	l := NewLog(10)
+	l.SetSigner(priv)
+	require.NoError(t, l.Open(path))
+	recordCalls(l, 2)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	v, err := Verify(bytes.NewReader(data), pub)
+	require.NoError(t, err)
+	assert.Equal(t, 2, v.Signed)
+
+	other, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	_, err = Verify(bytes.NewReader(data), other)
+	assert.ErrorContains(t, err, "not signed by the key")
+
+	// Entries written without signing fail verification with a key
+	unsigned := NewLog(10)
+	unsignedPath := filepath.Join(t.TempDir(), "audit-log.jsonl")
+	require.NoError(t, unsigned.Open(unsignedPath))
+	recordCalls(unsigned, 1)
+	f, err := os.Open(unsignedPath)
+	require.NoError(t, err)
+	defer f.Close()
+	_, err = Verify(f, pub)
+	assert.Error(t, err)
+}
+
+func TestLoadKey(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	dir := t.TempDir()
+	writePEM := func(name, typ string, der []byte) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0o600))
+		return path
+	}
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	require.NoError(t, err)
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	require.NoError(t, err)
+
+	gotPriv, gotPub, err := LoadKey(writePEM("private.pem", "PRIVATE KEY", privDER))
+	require.NoError(t, err)
+	assert.Equal(t, priv, gotPriv)
+	assert.Equal(t, pub, gotPub)
+
+	gotPriv, gotPub, err = LoadKey(writePEM("public.pem", "PUBLIC KEY", pubDER))
+	require.NoError(t, err)
+	assert.Nil(t, gotPriv)
+	assert.Equal(t, pub, gotPub)
+
+	_, _, err = LoadKey(writePEM("garbage.pem", "PRIVATE KEY", []byte("garbage")))
+	assert.Error(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "plain.txt"), []byte("not pem"), 0o600))
+	_, _, err = LoadKey(filepath.Join(dir, "plain.txt"))
+	assert.ErrorContains(t, err, "not a PEM file")
+	_, _, err = LoadKey(filepath.Join(dir, "missing.pem"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,380 @@
+// Package backtest replays simple trading strategies over historical candles.
+// Runs are deterministic: the same candles and parameters always give the same
+// trades. Orders fill at the close of the candle that signalled them, so
+// slippage and order book depth are not modelled.
+package backtest
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/luno/luno-go"
+)
+
+// Strategy names
+const (
+	SMACrossover = "sma_crossover"
+	RSI          = "rsi"
+	DCA          = "dca"
+)
+
+// Strategies are the strategies Run supports
+var Strategies = []string{SMACrossover, RSI, DCA}
+
+// Trade sides
+const (
+	Buy  = "buy"
+	Sell = "sell"
+)
+
+// Params configures a backtest
+type Params struct {
+	Strategy string
+	// Capital is the starting balance in the counter currency
+	Capital float64
+	// FeePercent is charged on the counter amount of every trade
+	FeePercent float64
+
+	// FastPeriod and SlowPeriod are the moving average lengths, in candles, for
+	// SMACrossover. It buys when the fast average crosses above the slow one and
+	// sells when it crosses back below.
+	FastPeriod int
+	SlowPeriod int
+
+	// RSIPeriod is the RSI length in candles for RSI. It buys when the RSI falls
+	// below RSILower and sells when it rises above RSIUpper.
+	RSIPeriod int
+	RSILower  float64
+	RSIUpper  float64
+
+	// DCAInterval is the number of candles between DCA buys, and DCAAmount the
+	// counter amount spent on each. A zero DCAAmount spreads Capital evenly.
+	DCAInterval int
+	DCAAmount   float64
+}
+
+// Validate checks that p describes a strategy Run can replay
+func (p Params) Validate() error {
+	if p.Capital <= 0 {
+		return errors.New("capital must be more than 0")
+	}
+	if p.FeePercent < 0 || p.FeePercent >= 100 {
+		return errors.New("fee percent must be at least 0 and less than 100")
+	}
+	switch p.Strategy {
+	case SMACrossover:
+		if p.FastPeriod < 1 || p.SlowPeriod <= p.FastPeriod {
+			return errors.New("fast period must be at least 1 and less than the slow period")
+		}
+	case RSI:
+		if p.RSIPeriod < 2 {
+			return errors.New("RSI period must be at least 2")
+		}
+		if p.RSILower <= 0 || p.RSIUpper >= 100 || p.RSILower >= p.RSIUpper {
+			return errors.New("RSI bounds must satisfy 0 < lower < upper < 100")
+		}
+	case DCA:
+		if p.DCAInterval < 1 {
+			return errors.New("DCA interval must be at least 1 candle")
+		}
+		if p.DCAAmount < 0 {
+			return errors.New("DCA amount must not be negative")
+		}
+	default:
+		return fmt.Errorf("unknown strategy %q", p.Strategy)
+	}
+	return nil
+}
+
+// warmup is the number of candles a strategy needs before it can signal
+func (p Params) warmup() int {
+	switch p.Strategy {
+	case SMACrossover:
+		return p.SlowPeriod + 1
+	case RSI:
+		return p.RSIPeriod + 1
+	default:
+		return 1
+	}
+}
+
+// Trade is a simulated fill
+type Trade struct {
+	Time   time.Time `json:"time"`
+	Side   string    `json:"side"`
+	Price  float64   `json:"price"`
+	Volume float64   `json:"volume"`
+	// Counter is the counter amount paid for a buy, including the fee, or
+	// received for a sell, after it
+	Counter float64 `json:"counter"`
+	Fee     float64 `json:"fee"`
+	// PnL is the profit of the round trip a sell closes
+	PnL *float64 `json:"pnl,omitempty"`
+}
+
+// Result is the outcome of a backtest
+type Result struct {
+	Strategy   string    `json:"strategy"`
+	Candles    int       `json:"candles"`
+	StartTime  time.Time `json:"start_time"`
+	EndTime    time.Time `json:"end_time"`
+	StartPrice float64   `json:"start_price"`
+	EndPrice   float64   `json:"end_price"`
+
+	StartingCapital float64 `json:"starting_capital"`
+	// FinalValue is the cash left plus any open position valued at EndPrice
+	FinalValue float64 `json:"final_value"`
+	PnL        float64 `json:"pnl"`
+	PnLPercent float64 `json:"pnl_percent"`
+	// BuyAndHoldPercent is the return of buying with all the capital at the
+	// first candle and holding, for comparison
+	BuyAndHoldPercent  float64 `json:"buy_and_hold_percent"`
+	MaxDrawdownPercent float64 `json:"max_drawdown_percent"`
+	FeesPaid           float64 `json:"fees_paid"`
+
+	// RoundTrips is how many buys were closed by a sell, and WinRatePercent
+	// the share of them that made a profit
+	RoundTrips     int      `json:"round_trips"`
+	WinRatePercent *float64 `json:"win_rate_percent,omitempty"`
+	// OpenVolume is the base volume still held at the end
+	OpenVolume float64 `json:"open_volume"`
+
+	Trades []Trade `json:"trades"`
+}
+
+// Run replays p over candles, which must be in time order
+func Run(candles []luno.Candle, p Params) (*Result, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	if len(candles) < p.warmup()+1 {
+		return nil, fmt.Errorf("%d candles is too few for %s, which needs at least %d", len(candles), p.Strategy, p.warmup()+1)
+	}
+
+	closes := make([]float64, len(candles))
+	for i, c := range candles {
+		closes[i] = c.Close.Float64()
+		if closes[i] <= 0 {
+			return nil, fmt.Errorf("candle at %s has no close price", time.Time(c.Timestamp).UTC())
+		}
+	}
+
+	s := &sim{fee: p.FeePercent / 100, cash: p.Capital, result: Result{
+		Strategy:        p.Strategy,
+		Candles:         len(candles),
+		StartTime:       time.Time(candles[0].Timestamp).UTC(),
+		EndTime:         time.Time(candles[len(candles)-1].Timestamp).UTC(),
+		StartPrice:      closes[0],
+		EndPrice:        closes[len(closes)-1],
+		StartingCapital: p.Capital,
+		Trades:          []Trade{},
+	}}
+
+	signal := signals(p, closes)
+	peak := p.Capital
+	for i, c := range candles {
+		at, price := time.Time(c.Timestamp).UTC(), closes[i]
+		switch signal(i) {
+		case Buy:
+			amount := s.cash
+			if p.Strategy == DCA {
+				amount = p.DCAAmount
+				if amount == 0 {
+					amount = p.Capital / float64((len(candles)+p.DCAInterval-1)/p.DCAInterval)
+				}
+				amount = min(amount, s.cash)
+			}
+			s.buy(at, price, amount)
+		case Sell:
+			s.sell(at, price)
+		}
+
+		equity := s.cash + s.base*price
+		peak = max(peak, equity)
+		if drawdown := (peak - equity) / peak * 100; drawdown > s.result.MaxDrawdownPercent {
+			s.result.MaxDrawdownPercent = drawdown
+		}
+	}
+
+	r := &s.result
+	r.FinalValue = s.cash + s.base*r.EndPrice
+	r.PnL = r.FinalValue - p.Capital
+	r.PnLPercent = r.PnL / p.Capital * 100
+	r.BuyAndHoldPercent = ((1-s.fee)*(1-s.fee)*r.EndPrice/r.StartPrice - 1) * 100
+	r.OpenVolume = s.base
+	if r.RoundTrips > 0 {
+		rate := float64(s.wins) / float64(r.RoundTrips) * 100
+		r.WinRatePercent = &rate
+	}
+	round(r)
+	return r, nil
+}
+
+// sim is the state of a backtest in progress
+type sim struct {
+	fee    float64
+	cash   float64
+	base   float64
+	cost   float64
+	wins   int
+	result Result
+}
+
+func (s *sim) buy(at time.Time, price, amount float64) {
+	if amount <= 0 {
+		return
+	}
+	fee := amount * s.fee
+	volume := (amount - fee) / price
+	s.cash -= amount
+	s.base += volume
+	s.cost += amount
+	s.result.FeesPaid += fee
+	s.result.Trades = append(s.result.Trades, Trade{Time: at, Side: Buy, Price: price, Volume: volume, Counter: amount, Fee: fee})
+}
+
+func (s *sim) sell(at time.Time, price float64) {
+	if s.base <= 0 {
+		return
+	}
+	gross := s.base * price
+	fee := gross * s.fee
+	proceeds := gross - fee
+	pnl := proceeds - s.cost
+	s.result.Trades = append(s.result.Trades, Trade{Time: at, Side: Sell, Price: price, Volume: s.base, Counter: proceeds, Fee: fee, PnL: &pnl})
+	s.result.FeesPaid += fee
+	s.result.RoundTrips++
+	if pnl > 0 {
+		s.wins++
+	}
+	s.cash += proceeds
+	s.base, s.cost = 0, 0
+}
+
+// signals returns the trade, if any, that p makes at the close of candle i.
+// Buys are only signalled when flat and sells when holding, except for DCA.
+func signals(p Params, closes []float64) func(i int) string {
+	holding := false
+	next := func(i int) string { return "" }
+	switch p.Strategy {
+	case SMACrossover:
+		fast, slow := sma(closes, p.FastPeriod), sma(closes, p.SlowPeriod)
+		next = func(i int) string {
+			if i < p.SlowPeriod {
+				return ""
+			}
+			above, wasAbove := fast[i] > slow[i], fast[i-1] > slow[i-1]
+			switch {
+			case above && !wasAbove:
+				return Buy
+			case !above && wasAbove:
+				return Sell
+			}
+			return ""
+		}
+	case RSI:
+		rsi := rsi(closes, p.RSIPeriod)
+		next = func(i int) string {
+			switch {
+			case i < p.RSIPeriod:
+				return ""
+			case rsi[i] < p.RSILower:
+				return Buy
+			case rsi[i] > p.RSIUpper:
+				return Sell
+			}
+			return ""
+		}
+	case DCA:
+		return func(i int) string {
+			if i%p.DCAInterval == 0 {
+				return Buy
+			}
+			return ""
+		}
+	}
+	return func(i int) string {
+		switch sig := next(i); {
+		case sig == Buy && !holding:
+			holding = true
+			return Buy
+		case sig == Sell && holding:
+			holding = false
+			return Sell
+		}
+		return ""
+	}
+}
+
+// sma returns the simple moving average of the period closes ending at each
+// index, or 0 before there are enough
+func sma(closes []float64, period int) []float64 {
+	out := make([]float64, len(closes))
+	var sum float64
+	for i, c := range closes {
+		sum += c
+		if i >= period {
+			sum -= closes[i-period]
+		}
+		if i >= period-1 {
+			out[i] = sum / float64(period)
+		}
+	}
+	return out
+}
+
+// rsi returns Wilder's relative strength index at each index, or 0 before
+// there are period changes
+func rsi(closes []float64, period int) []float64 {
+	out := make([]float64, len(closes))
+	var gain, loss float64
+	for i := 1; i < len(closes); i++ {
+		change := closes[i] - closes[i-1]
+		up, down := max(change, 0), max(-change, 0)
+		switch {
+		case i < period:
+			gain += up
+			loss += down
+			continue
+		case i == period:
+			gain = (gain + up) / float64(period)
+			loss = (loss + down) / float64(period)
+		default:
+			gain = (gain*float64(period-1) + up) / float64(period)
+			loss = (loss*float64(period-1) + down) / float64(period)
+		}
+		if loss == 0 {
+			out[i] = 100
+		} else {
+			out[i] = 100 - 100/(1+gain/loss)
+		}
+	}
+	return out
+}
+
+// round rounds the amounts of r to 8 decimal places and percentages to 2
+func round(r *Result) {
+	amount := func(v *float64) { *v = math.Round(*v*1e8) / 1e8 }
+	percent := func(v *float64) { *v = math.Round(*v*100) / 100 }
+	amount(&r.FinalValue)
+	amount(&r.PnL)
+	amount(&r.FeesPaid)
+	amount(&r.OpenVolume)
+	percent(&r.PnLPercent)
+	percent(&r.BuyAndHoldPercent)
+	percent(&r.MaxDrawdownPercent)
+	if r.WinRatePercent != nil {
+		percent(r.WinRatePercent)
+	}
+	for i := range r.Trades {
+		t := &r.Trades[i]
+		amount(&t.Volume)
+		amount(&t.Counter)
+		amount(&t.Fee)
+		if t.PnL != nil {
+			amount(t.PnL)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package backtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testCandles(t *testing.T, closes ...string) []luno.Candle {
+	t.Helper()
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	out := make([]luno.Candle, len(closes))
+	for i, c := range closes {
+		d, err := decimal.NewFromString(c)
+		require.NoError(t, err)
+		out[i] = luno.Candle{Timestamp: luno.Time(start.Add(time.Duration(i) * 24 * time.Hour)), Close: d}
+	}
+	return out
+}
+
+func TestRunSMACrossover(t *testing.T) {
+	candles := testCandles(t, "100", "100", "100", "90", "110", "120", "130", "120", "100", "90", "95")
+	r, err := Run(candles, Params{Strategy: SMACrossover, Capital: 1000, FastPeriod: 1, SlowPeriod: 3})
+	require.NoError(t, err)
+
+	require.Len(t, r.Trades, 2)
+	assert.Equal(t, Buy, r.Trades[0].Side)
+	assert.Equal(t, 110.0, r.Trades[0].Price)
+	assert.Equal(t, Sell, r.Trades[1].Side)
+	assert.Equal(t, 120.0, r.Trades[1].Price)
+	assert.InDelta(t, 90.90909091, *r.Trades[1].PnL, 1e-8)
+	assert.Equal(t, 1, r.RoundTrips)
+	require.NotNil(t, r.WinRatePercent)
+	assert.Equal(t, 100.0, *r.WinRatePercent)
+	assert.InDelta(t, 1090.90909091, r.FinalValue, 1e-8)
+	assert.Equal(t, 9.09, r.PnLPercent)
+	assert.Equal(t, -5.0, r.BuyAndHoldPercent)
+	assert.Equal(t, 7.69, r.MaxDrawdownPercent, "from the peak at 130 to the sell at 120")
+	assert.Zero(t, r.OpenVolume)
+}
+
+func TestRunRSI(t *testing.T) {
+	candles := testCandles(t, "100", "95", "90", "85", "80", "90", "100", "110", "120", "115")
+	r, err := Run(candles, Params{Strategy: RSI, Capital: 1000, FeePercent: 1, RSIPeriod: 3, RSILower: 30, RSIUpper: 70})
+	require.NoError(t, err)
+
+	require.Len(t, r.Trades, 2)
+	assert.Equal(t, Buy, r.Trades[0].Side)
+	assert.Equal(t, 85.0, r.Trades[0].Price, "the RSI first falls below 30 after three falls")
+	assert.Equal(t, 10.0, r.Trades[0].Fee)
+	assert.Equal(t, Sell, r.Trades[1].Side)
+	assert.Equal(t, 100.0, r.Trades[1].Price)
+	require.NotNil(t, r.WinRatePercent)
+	assert.Equal(t, 100.0, *r.WinRatePercent)
+	assert.Greater(t, r.PnL, 0.0)
+}
+
+func TestRunDCA(t *testing.T) {
+	candles := testCandles(t, "100", "50", "100", "50")
+	r, err := Run(candles, Params{Strategy: DCA, Capital: 1000, DCAInterval: 1})
+	require.NoError(t, err)
+
+	require.Len(t, r.Trades, 4)
+	for _, tr := range r.Trades {
+		assert.Equal(t, Buy, tr.Side)
+		assert.Equal(t, 250.0, tr.Counter)
+	}
+	assert.Equal(t, 15.0, r.OpenVolume)
+	assert.Equal(t, 750.0, r.FinalValue)
+	assert.Equal(t, 0, r.RoundTrips)
+	assert.Nil(t, r.WinRatePercent)
+}
+
+func TestRunDeterministic(t *testing.T) {
+	candles := testCandles(t, "100", "104", "98", "110", "107", "99", "120", "115", "101", "97", "130", "128")
+	p := Params{Strategy: SMACrossover, Capital: 500, FeePercent: 0.1, FastPeriod: 2, SlowPeriod: 4}
+	first, err := Run(candles, p)
+	require.NoError(t, err)
+	second, err := Run(candles, p)
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+}
+
+func TestRunErrors(t *testing.T) {
+	candles := testCandles(t, "100", "101", "102")
+	tests := []struct {
+		name   string
+		params Params
+		errMsg string
+	}{
+		{name: "unknown strategy", params: Params{Strategy: "martingale", Capital: 1}, errMsg: `unknown strategy "martingale"`},
+		{name: "no capital", params: Params{Strategy: DCA, DCAInterval: 1}, errMsg: "capital must be more than 0"},
+		{name: "periods reversed", params: Params{Strategy: SMACrossover, Capital: 1, FastPeriod: 5, SlowPeriod: 2}, errMsg: "fast period"},
+		{name: "RSI bounds", params: Params{Strategy: RSI, Capital: 1, RSIPeriod: 14, RSILower: 70, RSIUpper: 30}, errMsg: "RSI bounds"},
+		{name: "too few candles", params: Params{Strategy: SMACrossover, Capital: 1, FastPeriod: 1, SlowPeriod: 2}, errMsg: "3 candles is too few"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Run(candles, tt.params)
+			assert.ErrorContains(t, err, tt.errMsg)
+		})
+	}
+}
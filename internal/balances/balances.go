@@ -0,0 +1,104 @@
+// Package balances compares account balances taken before and after a tool
+// call, so that the effect of each trade or withdrawal is shown with it.
+package balances
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/sdk"
+)
+
+// Change is how an account's balance changed
+type Change struct {
+	AccountID string `json:"account_id"`
+	Asset     string `json:"asset"`
+	// Change is the change in the balance, and ReservedChange in the part of
+	// it reserved for open orders and withdrawals
+	Change         string `json:"change"`
+	ReservedChange string `json:"reserved_change"`
+	// Balance is the balance afterwards
+	Balance string `json:"balance"`
+}
+
+// Snapshot returns the balances of every account
+func Snapshot(ctx context.Context, client sdk.LunoClient) ([]luno.AccountBalance, error) {
+	res, err := client.GetBalances(ctx, &luno.GetBalancesRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return res.Balance, nil
+}
+
+// Diff returns the accounts whose balance or reserved amount differs between
+// the before and after snapshots, ordered by asset. Accounts missing from a
+// snapshot count as empty.
+func Diff(before, after []luno.AccountBalance) []Change {
+	previous := make(map[string]luno.AccountBalance, len(before))
+	for _, b := range before {
+		previous[b.AccountId] = b
+	}
+	changes := []Change{}
+	seen := make(map[string]bool, len(after))
+	for _, a := range after {
+		seen[a.AccountId] = true
+		b, ok := previous[a.AccountId]
+		if !ok {
+			b = luno.AccountBalance{Balance: decimal.Zero(), Reserved: decimal.Zero()}
+		}
+		if c, changed := diff(a.AccountId, a.Asset, b, a); changed {
+			changes = append(changes, c)
+		}
+	}
+	for _, b := range before {
+		if !seen[b.AccountId] {
+			if c, changed := diff(b.AccountId, b.Asset, b, luno.AccountBalance{Balance: decimal.Zero(), Reserved: decimal.Zero()}); changed {
+				changes = append(changes, c)
+			}
+		}
+	}
+	slices.SortStableFunc(changes, func(x, y Change) int {
+		return cmp.Or(strings.Compare(x.Asset, y.Asset), strings.Compare(x.AccountID, y.AccountID))
+	})
+	return changes
+}
+
+func diff(accountID, asset string, before, after luno.AccountBalance) (Change, bool) {
+	balance := after.Balance.Sub(before.Balance)
+	reserved := after.Reserved.Sub(before.Reserved)
+	if balance.Sign() == 0 && reserved.Sign() == 0 {
+		return Change{}, false
+	}
+	return Change{
+		AccountID:      accountID,
+		Asset:          asset,
+		Change:         signed(balance),
+		ReservedChange: signed(reserved),
+		Balance:        after.Balance.String(),
+	}, true
+}
+
+// signed formats d with a + when it is positive
+func signed(d decimal.Decimal) string {
+	if d.Sign() > 0 {
+		return "+" + d.String()
+	}
+	return d.String()
+}
+
+// Summary describes changes in a sentence for a tool result
+func Summary(changes []Change) string {
+	if len(changes) == 0 {
+		return "Balances did not change."
+	}
+	parts := make([]string, len(changes))
+	for i, c := range changes {
+		parts[i] = fmt.Sprintf("%s %s (reserved %s, balance now %s)", c.Asset, c.Change, c.ReservedChange, c.Balance)
+	}
+	return "Balance changes: " + strings.Join(parts, "; ") + "."
+}
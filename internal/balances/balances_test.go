@@ -0,0 +1,46 @@
+package balances
+
+import (
+	"testing"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func balance(t *testing.T, id, asset, amount, reserved string) luno.AccountBalance {
+	t.Helper()
+	b, err := decimal.NewFromString(amount)
+	require.NoError(t, err)
+	r, err := decimal.NewFromString(reserved)
+	require.NoError(t, err)
+	return luno.AccountBalance{AccountId: id, Asset: asset, Balance: b, Reserved: r}
+}
+
+func TestDiff(t *testing.T) {
+	before := []luno.AccountBalance{
+		balance(t, "1", "ZAR", "5000", "0"),
+		balance(t, "2", "XBT", "0.1", "0"),
+		balance(t, "3", "ETH", "2", "0"),
+		balance(t, "4", "SOL", "10", "0"),
+	}
+	after := []luno.AccountBalance{
+		balance(t, "1", "ZAR", "5000", "1000"),
+		balance(t, "2", "XBT", "0.1005", "0"),
+		balance(t, "3", "ETH", "2.0", "0"),
+		balance(t, "5", "USDC", "25", "0"),
+	}
+
+	changes := Diff(before, after)
+	assert.Equal(t, []Change{
+		{AccountID: "4", Asset: "SOL", Change: "-10", ReservedChange: "0", Balance: "0"},
+		{AccountID: "5", Asset: "USDC", Change: "+25", ReservedChange: "0", Balance: "25"},
+		{AccountID: "2", Asset: "XBT", Change: "+0.0005", ReservedChange: "0", Balance: "0.1005"},
+		{AccountID: "1", Asset: "ZAR", Change: "0", ReservedChange: "+1000", Balance: "5000"},
+	}, changes)
+
+	assert.Equal(t, "Balance changes: SOL -10 (reserved 0, balance now 0); USDC +25 (reserved 0, balance now 25); "+
+		"XBT +0.0005 (reserved 0, balance now 0.1005); ZAR 0 (reserved +1000, balance now 5000).", Summary(changes))
+	assert.Equal(t, "Balances did not change.", Summary(Diff(before, before)))
+}
@@ -0,0 +1,374 @@
+// Package candles caches the candles fetched from Luno on disk, one file per
+// market and candle duration, so that analysing the same period again does not
+// fetch it again and longer histories can be built up over several sessions.
+//
+// Only closed candles are cached. The candle still forming is fetched every
+// time it is asked for, since it changes until it closes.
+package candles
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/fsutil"
+)
+
+const (
+	// PageSize is the most candles Luno returns for one request
+	PageSize = 1000
+	// MaxPages is the most requests Get makes to fill in a range
+	MaxPages = 10
+	// MaxCandles is the most candles Get can fetch at once
+	MaxCandles = PageSize * MaxPages
+)
+
+// Fetcher gets candles from Luno. sdk.LunoClient is a Fetcher.
+type Fetcher interface {
+	GetCandles(ctx context.Context, req *luno.GetCandlesRequest) (*luno.GetCandlesResponse, error)
+}
+
+// Span is a range of candle start times, From inclusive and To exclusive
+type Span struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// series is the cached candles of one market and duration
+type series struct {
+	mu     sync.Mutex
+	loaded bool
+
+	Pair     string `json:"pair"`
+	Duration int64  `json:"duration"`
+	// Covered are the spans every candle of which is cached. A span can hold
+	// no candles at all when the market did not trade.
+	Covered []Span        `json:"covered"`
+	Candles []luno.Candle `json:"candles"`
+}
+
+type key struct {
+	pair     string
+	duration int64
+}
+
+// Store caches candles in a directory. Each series is read the first time it
+// is used and rewritten whenever candles are added to it. A nil Store fetches
+// every request from Luno. It is safe for concurrent use.
+type Store struct {
+	dir string
+
+	mu     sync.Mutex
+	series map[key]*series
+
+	now func() time.Time
+}
+
+// New creates a store kept in dir. An empty dir keeps candles in memory only.
+func New(dir string) *Store {
+	return &Store{dir: dir, series: make(map[key]*series), now: time.Now}
+}
+
+// Dir returns where the candles are stored, or an empty string if they are
+// only kept in memory
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// DirName is the name of the directory the candles are cached in by default
+const DirName = "candles"
+
+// DefaultDir returns the default location of the candles in the user's cache
+// directory, or an empty string if there isn't one
+func DefaultDir() string {
+	return fsutil.CachePath(DirName)
+}
+
+// Get returns the candles of duration seconds on pair starting from since and
+// before until, oldest first. Cached candles are not fetched again; the rest
+// are fetched from client in pages of PageSize, up to MaxPages.
+func (s *Store) Get(ctx context.Context, client Fetcher, pair string, duration int64, since, until time.Time) ([]luno.Candle, error) {
+	if duration <= 0 {
+		return nil, fmt.Errorf("invalid candle duration %d", duration)
+	}
+	if s == nil {
+		return fetch(ctx, client, pair, duration, since, until)
+	}
+
+	se, err := s.get(pair, duration)
+	if err != nil {
+		return nil, err
+	}
+	se.mu.Lock()
+	defer se.mu.Unlock()
+
+	length := time.Duration(duration) * time.Second
+	from := alignUp(since, duration)
+	// Candles starting before closed have closed and can be cached
+	closed := alignDown(s.now(), duration)
+	to := until
+	if to.After(closed) {
+		to = closed
+	}
+
+	var forming []luno.Candle
+	reachedNow := false
+	pages := 0
+	added := false
+	for _, gap := range se.missing(from, to) {
+		cursor := gap.From
+		for cursor.Before(gap.To) {
+			if pages == MaxPages {
+				return nil, fmt.Errorf("more than %d candles are needed; shorten the range or use a longer duration", MaxCandles)
+			}
+			pages++
+			res, err := client.GetCandles(ctx, &luno.GetCandlesRequest{Pair: pair, Since: luno.Time(cursor), Duration: duration})
+			if err != nil {
+				return nil, err
+			}
+			end := closed
+			if len(res.Candles) >= PageSize {
+				last := slices.MaxFunc(res.Candles, func(a, b luno.Candle) int {
+					return time.Time(a.Timestamp).Compare(time.Time(b.Timestamp))
+				})
+				end = minTime(closed, time.Time(last.Timestamp).Add(length))
+			} else {
+				reachedNow = true
+			}
+			for _, c := range res.Candles {
+				if !time.Time(c.Timestamp).Before(closed) {
+					forming = append(forming, c)
+				}
+			}
+			if !end.After(cursor) {
+				break
+			}
+			se.add(Span{From: cursor, To: end}, res.Candles, closed)
+			added = true
+			cursor = end
+		}
+	}
+	if added {
+		if err := s.save(se); err != nil {
+			return nil, err
+		}
+	}
+
+	out := se.between(from, to)
+	if start := maxTime(from, closed); until.After(start) {
+		if !reachedNow {
+			forming, err = fetch(ctx, client, pair, duration, start, until)
+			if err != nil {
+				return nil, err
+			}
+		}
+		for _, c := range forming {
+			if ts := time.Time(c.Timestamp); !ts.Before(start) && ts.Before(until) {
+				out = append(out, c)
+			}
+		}
+	}
+	return out, nil
+}
+
+// fetch gets the candles from since and before until from client without
+// caching them
+func fetch(ctx context.Context, client Fetcher, pair string, duration int64, since, until time.Time) ([]luno.Candle, error) {
+	var out []luno.Candle
+	cursor := since
+	for range MaxPages {
+		res, err := client.GetCandles(ctx, &luno.GetCandlesRequest{Pair: pair, Since: luno.Time(cursor), Duration: duration})
+		if err != nil {
+			return nil, err
+		}
+		latest := cursor
+		for _, c := range res.Candles {
+			ts := time.Time(c.Timestamp)
+			if ts.Before(until) {
+				out = append(out, c)
+			}
+			if ts.After(latest) {
+				latest = ts
+			}
+		}
+		next := latest.Add(time.Duration(duration) * time.Second)
+		if len(res.Candles) < PageSize || !next.Before(until) {
+			return out, nil
+		}
+		cursor = next
+	}
+	return nil, fmt.Errorf("more than %d candles are needed; shorten the range or use a longer duration", MaxCandles)
+}
+
+// get returns the series of pair and duration, reading it the first time
+func (s *Store) get(pair string, duration int64) (*series, error) {
+	s.mu.Lock()
+	k := key{pair: pair, duration: duration}
+	se, ok := s.series[k]
+	if !ok {
+		se = &series{Pair: pair, Duration: duration}
+		s.series[k] = se
+	}
+	s.mu.Unlock()
+
+	se.mu.Lock()
+	defer se.mu.Unlock()
+	if se.loaded {
+		return se, nil
+	}
+	path := s.path(pair, duration)
+	if path == "" {
+		se.loaded = true
+		return se, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		se.loaded = true
+		return se, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading cached candles: %w", err)
+	}
+	if err := json.Unmarshal(data, se); err != nil {
+		return nil, fmt.Errorf("reading cached candles %s: %w", path, err)
+	}
+	se.loaded = true
+	return se, nil
+}
+
+// path returns the file of a series, or an empty string if it is kept in
+// memory only. Pairs that are not plain letters and digits are never written
+// to disk, so they cannot name files outside the directory.
+func (s *Store) path(pair string, duration int64) string {
+	if s.dir == "" || pair == "" {
+		return ""
+	}
+	for _, r := range pair {
+		if (r < 'A' || r > 'Z') && (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			return ""
+		}
+	}
+	return filepath.Join(s.dir, pair+"-"+strconv.FormatInt(duration, 10)+".json")
+}
+
+// save replaces the file of se. The caller must hold se.mu.
+func (s *Store) save(se *series) error {
+	path := s.path(se.Pair, se.Duration)
+	if path == "" {
+		return nil
+	}
+	data, err := json.Marshal(se)
+	if err != nil {
+		return fmt.Errorf("encoding cached candles: %w", err)
+	}
+	if err := fsutil.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("writing cached candles: %w", err)
+	}
+	return nil
+}
+
+// missing returns the parts of [from, to) that are not covered, oldest first
+func (se *series) missing(from, to time.Time) []Span {
+	var gaps []Span
+	cursor := from
+	for _, c := range se.Covered {
+		if !cursor.Before(to) {
+			break
+		}
+		if !c.To.After(cursor) {
+			continue
+		}
+		if c.From.After(cursor) {
+			gaps = append(gaps, Span{From: cursor, To: minTime(c.From, to)})
+		}
+		cursor = c.To
+	}
+	if cursor.Before(to) {
+		gaps = append(gaps, Span{From: cursor, To: to})
+	}
+	return gaps
+}
+
+// add caches the closed candles fetched for span and marks it covered
+func (se *series) add(span Span, candles []luno.Candle, closed time.Time) {
+	for _, c := range candles {
+		ts := time.Time(c.Timestamp)
+		if !ts.Before(closed) {
+			continue
+		}
+		i, found := slices.BinarySearchFunc(se.Candles, ts, func(e luno.Candle, t time.Time) int {
+			return time.Time(e.Timestamp).Compare(t)
+		})
+		if found {
+			se.Candles[i] = c
+		} else {
+			se.Candles = slices.Insert(se.Candles, i, c)
+		}
+	}
+
+	covered := append(slices.Clone(se.Covered), span)
+	slices.SortFunc(covered, func(a, b Span) int { return a.From.Compare(b.From) })
+	merged := covered[:1]
+	for _, c := range covered[1:] {
+		last := &merged[len(merged)-1]
+		if c.From.After(last.To) {
+			merged = append(merged, c)
+			continue
+		}
+		last.To = maxTime(last.To, c.To)
+	}
+	se.Covered = merged
+}
+
+// between returns the cached candles starting from from and before to
+func (se *series) between(from, to time.Time) []luno.Candle {
+	var out []luno.Candle
+	for _, c := range se.Candles {
+		if ts := time.Time(c.Timestamp); !ts.Before(from) && ts.Before(to) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// alignDown returns the start of the candle of duration seconds holding t
+func alignDown(t time.Time, duration int64) time.Time {
+	sec := t.Unix()
+	return time.Unix(sec-mod(sec, duration), 0).UTC()
+}
+
+// alignUp returns the start of the first candle of duration seconds starting
+// at or after t
+func alignUp(t time.Time, duration int64) time.Time {
+	down := alignDown(t, duration)
+	if down.Before(t) {
+		return down.Add(time.Duration(duration) * time.Second)
+	}
+	return down
+}
+
+func mod(a, b int64) int64 {
+	return ((a % b) + b) % b
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,163 @@
+package candles
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	ctx   = context.Background()
+	start = time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// market serves a candle every minute from start until now, the last still
+// forming, and records the requests made
+type market struct {
+	now      time.Time
+	requests []time.Time
+}
+
+func (m *market) GetCandles(_ context.Context, req *luno.GetCandlesRequest) (*luno.GetCandlesResponse, error) {
+	since := time.Time(req.Since)
+	m.requests = append(m.requests, since)
+	res := &luno.GetCandlesResponse{Pair: req.Pair, Duration: req.Duration}
+	length := time.Duration(req.Duration) * time.Second
+	for ts := start; !ts.After(m.now) && len(res.Candles) < PageSize; ts = ts.Add(length) {
+		if ts.Before(since) {
+			continue
+		}
+		res.Candles = append(res.Candles, luno.Candle{
+			Timestamp: luno.Time(ts),
+			Close:     decimal.NewFromInt64(ts.Unix()),
+			Volume:    decimal.NewFromInt64(m.now.Unix()),
+		})
+	}
+	return res, nil
+}
+
+func newStore(dir string, now time.Time) *Store {
+	s := New(dir)
+	s.now = func() time.Time { return now }
+	return s
+}
+
+func timestamps(candles []luno.Candle) []time.Time {
+	var out []time.Time
+	for _, c := range candles {
+		out = append(out, time.Time(c.Timestamp))
+	}
+	return out
+}
+
+func TestGetCaches(t *testing.T) {
+	dir := t.TempDir()
+	now := start.Add(3*time.Hour + 30*time.Second)
+	m := &market{now: now}
+	s := newStore(dir, now)
+
+	got, err := s.Get(ctx, m, "XBTZAR", 60, start.Add(10*time.Minute), start.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, got, 50)
+	assert.Equal(t, start.Add(10*time.Minute), time.Time(got[0].Timestamp))
+	assert.Equal(t, []time.Time{start.Add(10 * time.Minute)}, m.requests)
+
+	m.requests = nil
+	again, err := s.Get(ctx, m, "XBTZAR", 60, start.Add(20*time.Minute), start.Add(30*time.Minute))
+	require.NoError(t, err)
+	assert.Len(t, again, 10)
+	assert.Empty(t, m.requests, "the range was cached")
+
+	// The first request reached now, so everything after it is cached too
+	_, err = s.Get(ctx, m, "XBTZAR", 60, start.Add(2*time.Hour), start.Add(3*time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, m.requests)
+
+	// Only the gap before the cached range is fetched
+	_, err = s.Get(ctx, m, "XBTZAR", 60, start, start.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, []time.Time{start}, m.requests)
+
+	assert.FileExists(t, filepath.Join(dir, "XBTZAR-60.json"))
+	m.requests = nil
+	reopened := newStore(dir, now)
+	got, err = reopened.Get(ctx, m, "XBTZAR", 60, start, start.Add(3*time.Hour))
+	require.NoError(t, err)
+	assert.Len(t, got, 180)
+	assert.Empty(t, m.requests, "the cache is read back from disk")
+}
+
+func TestGetForming(t *testing.T) {
+	now := start.Add(time.Hour + 30*time.Second)
+	m := &market{now: now}
+	s := newStore("", now)
+
+	got, err := s.Get(ctx, m, "XBTZAR", 60, start.Add(50*time.Minute), now)
+	require.NoError(t, err)
+	require.Len(t, got, 11)
+	assert.Equal(t, start.Add(time.Hour), time.Time(got[10].Timestamp), "the forming candle is returned")
+	assert.Len(t, m.requests, 1, "the forming candle came with the history")
+
+	m.requests = nil
+	got, err = s.Get(ctx, m, "XBTZAR", 60, start.Add(50*time.Minute), now)
+	require.NoError(t, err)
+	assert.Len(t, got, 11)
+	assert.Equal(t, []time.Time{start.Add(time.Hour)}, m.requests, "the forming candle is fetched again")
+}
+
+func TestGetPages(t *testing.T) {
+	now := start.Add(2500 * time.Minute)
+	m := &market{now: now}
+	s := newStore("", now)
+
+	got, err := s.Get(ctx, m, "XBTZAR", 60, start, start.Add(2200*time.Minute))
+	require.NoError(t, err)
+	assert.Len(t, got, 2200)
+	assert.Equal(t, []time.Time{start, start.Add(1000 * time.Minute), start.Add(2000 * time.Minute)}, m.requests)
+
+	got, err = s.Get(ctx, m, "XBTZAR", 60, start, start.Add(5*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, []time.Time{start, start.Add(time.Minute), start.Add(2 * time.Minute), start.Add(3 * time.Minute), start.Add(4 * time.Minute)},
+		timestamps(got))
+
+	far := &market{now: start.Add(20000 * time.Minute)}
+	_, err = newStore("", far.now).Get(ctx, far, "XBTZAR", 60, start, far.now)
+	assert.ErrorContains(t, err, "more than 10000 candles are needed")
+}
+
+func TestGetNilStore(t *testing.T) {
+	now := start.Add(1500 * time.Minute)
+	m := &market{now: now}
+	var s *Store
+
+	got, err := s.Get(ctx, m, "XBTZAR", 60, start, start.Add(1200*time.Minute))
+	require.NoError(t, err)
+	assert.Len(t, got, 1200)
+	assert.Equal(t, []time.Time{start, start.Add(1000 * time.Minute)}, m.requests)
+
+	_, err = s.Get(ctx, m, "XBTZAR", 0, start, now)
+	assert.Error(t, err)
+}
+
+func TestPath(t *testing.T) {
+	s := New("/cache")
+	assert.Equal(t, filepath.Join("/cache", "XBTZAR-3600.json"), s.path("XBTZAR", 3600))
+	assert.Empty(t, s.path("../XBTZAR", 3600))
+	assert.Empty(t, New("").path("XBTZAR", 3600))
+}
+
+func TestGetCorruptCache(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "XBTZAR-60.json"), []byte("not json"), 0o600))
+	m := &market{now: start.Add(time.Hour)}
+
+	_, err := newStore(dir, m.now).Get(ctx, m, "XBTZAR", 60, start, m.now)
+	assert.ErrorContains(t, err, "reading cached candles")
+}
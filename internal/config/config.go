@@ -1,28 +1,133 @@
 package config
 
 import (
+	"cmp"
+	"context"
+	"encoding/base64"
 	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/luno/luno-go"
 	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/internal/aliases"
+	"github.com/luno/luno-mcp/internal/approvals"
+	"github.com/luno/luno-mcp/internal/audit"
+	"github.com/luno/luno-mcp/internal/candles"
+	"github.com/luno/luno-mcp/internal/continuation"
+	"github.com/luno/luno-mcp/internal/events"
+	"github.com/luno/luno-mcp/internal/fsutil"
+	"github.com/luno/luno-mcp/internal/i18n"
+	"github.com/luno/luno-mcp/internal/iceberg"
+	"github.com/luno/luno-mcp/internal/journal"
+	"github.com/luno/luno-mcp/internal/limiter"
+	"github.com/luno/luno-mcp/internal/loopguard"
+	"github.com/luno/luno-mcp/internal/markets"
+	"github.com/luno/luno-mcp/internal/orders"
+	"github.com/luno/luno-mcp/internal/redact"
+	"github.com/luno/luno-mcp/internal/reference"
+	"github.com/luno/luno-mcp/internal/render"
+	"github.com/luno/luno-mcp/internal/reports"
+	"github.com/luno/luno-mcp/internal/roles"
+	"github.com/luno/luno-mcp/internal/telemetry"
+	"github.com/luno/luno-mcp/internal/tenants"
+	"github.com/luno/luno-mcp/internal/trades"
+	"github.com/luno/luno-mcp/internal/tradinghours"
+	"github.com/luno/luno-mcp/internal/transcript"
+	"github.com/luno/luno-mcp/internal/twap"
+	"github.com/luno/luno-mcp/internal/updates"
+	"github.com/luno/luno-mcp/internal/usage"
+	"github.com/luno/luno-mcp/internal/vault"
 	"github.com/luno/luno-mcp/sdk"
 )
 
 const (
 	// Environment variables
-	EnvLunoAPIKeyID          = "LUNO_API_KEY_ID"
-	EnvLunoAPIKeySecret      = "LUNO_API_SECRET"
-	EnvLunoAPIDomain         = "LUNO_API_DOMAIN"
-	EnvLunoAPIDebug          = "LUNO_API_DEBUG"
-	EnvAllowWriteOperations  = "ALLOW_WRITE_OPERATIONS"
+	EnvLunoAPIKeyID         = "LUNO_API_KEY_ID"
+	EnvLunoAPIKeySecret     = "LUNO_API_SECRET"
+	EnvLunoAPIDomain        = "LUNO_API_DOMAIN"
+	EnvLunoAPIDebug         = "LUNO_API_DEBUG"
+	EnvAllowWriteOperations = "ALLOW_WRITE_OPERATIONS"
+	EnvAllowWithdrawals     = "ALLOW_WITHDRAWALS"
+	EnvWebhookURL           = "WEBHOOK_URL"
+	EnvWebhookSecret        = "WEBHOOK_SECRET"
+	EnvMaxResponseBytes     = "MAX_RESPONSE_BYTES"
+	EnvMaxConcurrentCalls   = "MAX_CONCURRENT_CALLS"
+	EnvMaxCallsPerTool      = "MAX_CONCURRENT_CALLS_PER_TOOL"
+	EnvCallQueueTimeout     = "CALL_QUEUE_TIMEOUT"
+	EnvReferencePriceURL    = "REFERENCE_PRICE_URL"
+	EnvReferencePriceField  = "REFERENCE_PRICE_FIELD"
+	EnvTradeJournalPath     = "TRADE_JOURNAL_PATH"
+	EnvAccountAliasesPath   = "ACCOUNT_ALIASES_PATH"
+	EnvTrackedOrdersPath    = "TRACKED_ORDERS_PATH"
+	EnvImportedTradesPath   = "IMPORTED_TRADES_PATH"
+	EnvCandleCacheDir       = "CANDLE_CACHE_DIR"
+	EnvRepeatCallThreshold  = "REPEAT_CALL_THRESHOLD"
+	EnvRepeatCallWindow     = "REPEAT_CALL_WINDOW"
+	EnvTickersInterval      = "TICKERS_REFRESH_INTERVAL"
+	EnvDepositAlerts        = "DEPOSIT_ALERTS"
+	EnvHideUnavailableTools = "HIDE_UNAVAILABLE_TOOLS"
+	EnvLocale               = "LOCALE"
+	EnvOutputFormat         = "OUTPUT_FORMAT"
+	EnvDataDir              = "LUNO_MCP_DATA_DIR"
+	EnvStorageEncryption    = "STORAGE_ENCRYPTION"
+	EnvStoragePassphrase    = "STORAGE_PASSPHRASE"
+	EnvTelemetryURL         = "TELEMETRY_URL"
+	EnvDisableUpdateCheck   = "DISABLE_UPDATE_CHECK"
+	EnvTradingWindows       = "TRADING_WINDOWS"
+	EnvTradingTimezone      = "TRADING_TIMEZONE"
+	EnvTradingPairs         = "TRADING_PAIRS"
+	EnvChaos                = "CHAOS"
+	EnvAdminAddr            = "ADMIN_ADDR"
+	EnvAdminToken           = "ADMIN_TOKEN"
+	EnvCredentialsProvider  = "CREDENTIALS_PROVIDER"
+	EnvCredentialsCacheTTL  = "CREDENTIALS_CACHE_TTL"
+	EnvTenantHeader         = "TENANT_HEADER"
+	EnvVaultAddr            = "VAULT_ADDR"
+	EnvVaultToken           = "VAULT_TOKEN"
+	EnvAccessTokens         = "ACCESS_TOKENS"
+	EnvApprovalThresholds   = "APPROVAL_THRESHOLDS"
+	EnvApprovalCommand      = "APPROVAL_COMMAND"
+	EnvApprovalTimeout      = "APPROVAL_TIMEOUT"
+	EnvAuditLogPath         = "AUDIT_LOG_PATH"
+	EnvAuditSigningKeyPath  = "AUDIT_SIGNING_KEY_PATH"
+	EnvMaxPriceDeviation    = "MAX_PRICE_DEVIATION"
+	EnvDuplicateOrderWindow = "DUPLICATE_ORDER_WINDOW"
 
 	// Default Luno API domain
 	DefaultLunoDomain = "api.luno.com"
+
+	// DefaultMaxResponseBytes is the default size above which tool results are
+	// truncated and continued with fetch_more
+	DefaultMaxResponseBytes = 20_000
+
+	// DefaultMaxPriceDeviation is the default percentage a limit price can be
+	// from the market price before orders need allow_far_from_market
+	DefaultMaxPriceDeviation = 50.0
+
+	// DefaultDuplicateOrderWindow is the default time within which an order
+	// matching a new one, even if no longer open, makes it a likely duplicate
+	DefaultDuplicateOrderWindow = 10 * time.Minute
+
+	// defaultHTTPTimeout matches the timeout luno-go uses for its own client
+	defaultHTTPTimeout = 10 * time.Second
+
+	// Connection pool settings for the Luno API client. All calls go to a single
+	// host, so keep enough idle connections for bursts of parallel tool calls.
+	maxIdleConns        = 64
+	maxIdleConnsPerHost = 32
+	idleConnTimeout     = 90 * time.Second
 )
 
-// Config holds the configuration for the application
+// Config holds the configuration for the application. A Config is shared by all
+// MCP sessions and must not be modified after Load; use Sessions to override
+// settings for a single session.
 type Config struct {
 	// Luno client
 	LunoClient sdk.LunoClient
@@ -30,48 +135,396 @@ type Config struct {
 	// If false, only public API calls can be made.
 	IsAuthenticated bool
 
-	// AllowWriteOperations controls whether write operations (create_order, cancel_order) are exposed
+	// AllowWriteOperations controls whether write operations (create_order, cancel_order, replace_order) are exposed
 	AllowWriteOperations bool
+	// AllowWithdrawals controls whether fiat withdrawals can be created. It only
+	// takes effect when AllowWriteOperations is also set.
+	AllowWithdrawals bool
+	// HideUnavailableTools leaves tools that need API credentials out of the
+	// tool list of sessions without them, rather than marking them unavailable
+	HideUnavailableTools bool
+	// Locale is the language of tool descriptions, error messages and notes
+	Locale i18n.Locale
+	// OutputFormat is the format of tool results when a call does not ask
+	// for one
+	OutputFormat render.Format
+
+	// HTTPDebug logs Luno API requests and responses while enabled. It starts
+	// enabled with LUNO_API_DEBUG and can be switched at runtime.
+	HTTPDebug *sdk.HTTPDebug
+	// APICompat holds deprecation notices and undecodable responses seen from
+	// the Luno API, signs that it changed under the server
+	APICompat *sdk.APICompat
+	// Clock measures how far the local clock is from Luno's
+	Clock *sdk.ClockSkew
+
+	// Domain is the Luno API domain the client talks to
+	Domain string
+	// Transport is the MCP transport the server is running on (stdio, sse or streamable-http)
+	Transport string
+
+	// MaxResponseBytes is the size above which tool results are truncated. The
+	// rest is kept in Continuations for fetch_more. Zero disables truncation.
+	MaxResponseBytes int
+	// Continuations holds the rest of truncated tool results
+	Continuations *continuation.Store
+
+	// Limiter bounds the number of tool calls running at once. Calls are not
+	// limited when it is nil.
+	Limiter *limiter.Limiter
+
+	// MaxPriceDeviation is how far, as a percentage of the market price, a limit
+	// price can be before the order needs allow_far_from_market. Zero disables
+	// the check.
+	MaxPriceDeviation float64
+
+	// DuplicateOrderWindow is how long after an order is placed a limit order
+	// with the same pair, side, price and volume needs allow_duplicate, as do
+	// matches of open orders. Zero disables the check.
+	DuplicateOrderWindow time.Duration
+
+	// LoopGuard reuses the previous result when a session keeps repeating an
+	// identical tool call. Results are never reused when it is nil.
+	LoopGuard *loopguard.Guard
+
+	// Sessions holds per-session overlays, applied to a request by ForContext
+	Sessions *SessionOverlays
+	// Tenant is the tenant of the session a request comes from, set by
+	// ForContext. It is empty outside multi-tenant deployments. The audit
+	// log, trade journal, aliases, tracked orders, TWAP executions and
+	// iceberg orders are kept apart by tenant.
+	Tenant string
+
+	// Reports holds scheduled portfolio digests. They are only sent while its Run
+	// loop is running.
+	Reports *reports.Scheduler
+
+	// Markets caches the market list used to validate and complete pairs. It is
+	// loaded on first use and only refreshed while its Run loop is running.
+	Markets *markets.Cache
+
+	// Tickers holds the tickers of every market for the luno://tickers/all
+	// resource. They are only refreshed while its Run loop is running.
+	Tickers *markets.Tickers
+
+	// Events publishes account events to the configured webhook. It has no sinks
+	// when no webhook is set.
+	Events *events.Bus
+
+	// DepositAlerts selects the deposits reported to MCP clients and the webhook.
+	// Deposits are not watched when it is empty.
+	DepositAlerts []events.DepositRule
+
+	// TradingHours restricts the tools that trade or move funds to the allowed
+	// times of the week. It is nil, allowing them at any time, unless trading
+	// windows are set.
+	TradingHours *tradinghours.Policy
+	// TradingPairs are the only pairs the trading tools place orders on. Orders
+	// may be placed on any pair when it is empty.
+	TradingPairs []string
+
+	// AdminAddr is the address the admin API is served on, separately from
+	// the MCP transport. The admin API is off when it is empty.
+	AdminAddr string
+	// AdminToken is the bearer token admin API requests must carry
+	AdminToken string
+
+	// Tenants fetches the Luno API credentials of each session's tenant when
+	// the server is shared by several accounts. It is nil otherwise.
+	Tenants tenants.Provider
+	// TenantHeader is the HTTP header naming a session's tenant
+	TenantHeader string
+
+	// Approvals holds orders and withdrawals worth more than its thresholds
+	// until an approver decides them. Actions are never held when it is nil.
+	Approvals *approvals.Queue
+
+	// AccessTokens are the bearer tokens MCP clients calling over HTTP must
+	// carry, each with the role deciding which tools it may call. Any client
+	// may call any tool when it is nil.
+	AccessTokens *roles.Tokens
+
+	// newClient creates an unauthenticated Luno client like LunoClient, for
+	// SessionClient
+	newClient func() sdk.LunoClient
+
+	// Withdrawals monitors withdrawals made with create_fiat_withdrawal, when asked
+	// to. Close it on shutdown.
+	Withdrawals *events.WithdrawalMonitor
+
+	// TWAP runs execute_twap orders in the background. Close it on shutdown to
+	// cancel their open slices.
+	TWAP *twap.Manager
+
+	// Iceberg works iceberg_order orders in the background. Close it on shutdown
+	// to cancel their visible slices.
+	Iceberg *iceberg.Manager
+
+	// Reference is the external price feed get_price_premium compares Luno's
+	// prices against. It is nil when no feed is configured.
+	Reference reference.Source
+
+	// Journal holds the trade notes written with log_trade_note
+	Journal *journal.Journal
+	// Aliases holds the account names set with alias_account
+	Aliases *aliases.Store
+	// Orders tracks the orders placed through the server, so that they can be
+	// checked after a restart
+	Orders *orders.Store
+	// Trades holds the trades made outside the server imported with import_trades
+	Trades *trades.Store
+	// Candles caches the candles fetched for analysis tools
+	Candles *candles.Store
+	// StorageEncryption is where the key encrypting the journal, aliases,
+	// tracked orders and imported trades comes from: off, passphrase or
+	// keychain. The cached candles are public and not encrypted.
+	StorageEncryption string
+
+	// Audit records the tool calls made to the server, chained by hash. It
+	// is kept in a file, and signed, when AUDIT_LOG_PATH and
+	// AUDIT_SIGNING_KEY_PATH are set.
+	Audit *audit.Log
+
+	// Transcripts records each session's tool calls and results for
+	// export_session
+	Transcripts *transcript.Store
+
+	// Usage adds up the size of tool calls and results by tool. Sizes are not
+	// measured when it is nil.
+	Usage *usage.Tracker
+
+	// Telemetry counts tool calls for the anonymous usage reports the operator
+	// opted in to. It is nil, and sends nothing, unless a telemetry URL is set.
+	Telemetry *telemetry.Reporter
+
+	// Updates checks GitHub for newer releases. It is nil when update checks
+	// are disabled, so that the server makes no such network calls.
+	Updates *updates.Checker
 }
 
-// Mask a string to show only the first 4 characters and replace the rest with asterisks
-func maskValue(s string) string {
-	if len(s) <= 4 {
-		return strings.Repeat("*", len(s))
+// Load builds the configuration. Anything not set through opts is read from
+// environment variables, falling back to defaults.
+func Load(opts ...Option) (*Config, error) {
+	o := options{credentials: EnvCredentials}
+	for _, opt := range opts {
+		opt(&o)
 	}
-	return s[:4] + strings.Repeat("*", len(s)-4)
-}
 
-// Load loads the configuration from environment variables
-func Load(domainOverride string) (*Config, error) {
-	apiKeyID := os.Getenv(strings.TrimSpace(EnvLunoAPIKeyID))
-	apiKeySecret := os.Getenv(strings.TrimSpace(EnvLunoAPIKeySecret))
+	apiKeyID, apiKeySecret, err := o.credentials()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Luno API credentials: %w", err)
+	}
 
-	fmt.Printf("LUNO_API_KEY_ID value: %s (length: %d)\n", maskValue(apiKeyID), len(apiKeyID))
-	fmt.Printf("LUNO_API_SECRET value: %s (length: %d)\n", maskValue(apiKeySecret), len(apiKeySecret))
+	// Credentials are never printed, not even masked, and are scrubbed from logs
+	// and errors should they end up in one
+	redact.Register(apiKeyID, apiKeySecret)
+
+	debugMode := parseBoolEnv(EnvLunoAPIDebug)
+	if o.debug != nil {
+		debugMode = *o.debug
+	}
+	if debugMode {
+		slog.Info("Debug mode enabled")
+	}
+	// luno-go's own debug mode prints raw requests and responses, so it is left
+	// off in favour of the redacting HTTPDebug in the transport
+	httpDebug := sdk.NewHTTPDebug(debugMode)
+	apiCompat := sdk.NewAPICompat()
+	clock := sdk.NewClockSkew(sdk.DefaultMaxClockSkew)
+
+	httpClient := newHTTPClient(o, &sdk.MCPRoundTripper{Debug: httpDebug, Compat: apiCompat, Clock: clock})
+	client := luno.NewClient()
+	client.SetHTTPClient(httpClient)
+
+	webhookURL, webhookSecret := o.webhookURL, o.webhookSecret
+	if webhookURL == "" {
+		webhookURL = os.Getenv(EnvWebhookURL)
+	}
+	if webhookSecret == "" {
+		webhookSecret, err = secretEnv(EnvWebhookSecret)
+		if err != nil {
+			return nil, err
+		}
+	}
+	redact.Register(webhookSecret)
+
+	// Text that looks like instructions is wrapped outermost, so that every
+	// other middleware sees responses as Luno sent them.
+	//
+	// Chaos mode - option override, then env var. Its middleware goes last so
+	// that logging and metrics middleware see the failures it injects.
+	middleware := append([]sdk.Middleware{sdk.GuardInjection(), sdk.DetectAPIChanges(apiCompat)}, o.middleware...)
+	chaos := os.Getenv(EnvChaos)
+	if o.chaos != nil {
+		chaos = *o.chaos
+	}
+	if strings.TrimSpace(chaos) != "" {
+		chaosCfg, err := sdk.ParseChaos(chaos)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", EnvChaos, err)
+		}
+		middleware = append(middleware, sdk.Chaos(chaosCfg))
+		slog.Warn("Chaos mode enabled: Luno API calls are delayed and fail on purpose; do not use with real funds",
+			slog.String("chaos", chaosCfg.String()))
+	}
 
 	cfg := &Config{
-		LunoClient: luno.NewClient(),
+		LunoClient:    sdk.Wrap(client, middleware...),
+		HTTPDebug:     httpDebug,
+		APICompat:     apiCompat,
+		Clock:         clock,
+		Transport:     o.transport,
+		Continuations: continuation.NewStore(continuation.DefaultTTL),
+		Sessions:      NewSessionOverlays(),
+		Reports:       reports.NewScheduler(webhookSecret),
+		Events:        events.NewBus(),
+		Withdrawals:   events.NewWithdrawalMonitor(events.DefaultWatchInterval),
+		TWAP:          twap.NewManager(),
+		Iceberg:       iceberg.NewManager(),
+		Audit:         audit.NewLog(audit.DefaultCapacity),
+		Transcripts:   transcript.NewStore(),
+		Usage:         usage.NewTracker(),
+	}
+	cfg.Markets = markets.NewCache(cfg.LunoClient)
+
+	if webhookURL != "" {
+		hook, err := events.NewWebhook(webhookURL, webhookSecret)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Events.AddSink(hook)
+		if webhookSecret == "" {
+			slog.Warn("Event webhook enabled without a secret; requests will not be signed")
+		} else {
+			slog.Info("Event webhook enabled")
+		}
+	}
+
+	telemetryURL := o.telemetryURL
+	if telemetryURL == "" {
+		telemetryURL = os.Getenv(EnvTelemetryURL)
+	}
+	if telemetryURL != "" {
+		if cfg.Telemetry, err = telemetry.New(telemetryURL, o.appVersion); err != nil {
+			return nil, err
+		}
+		slog.Info("Anonymous usage telemetry enabled", slog.String("url", telemetryURL))
+	}
+
+	updateCheck := !parseBoolEnv(EnvDisableUpdateCheck)
+	if o.updateCheck != nil {
+		updateCheck = *o.updateCheck
+	}
+	if updateCheck {
+		cfg.Updates = updates.NewChecker(updates.ReleasesURL, o.appVersion)
+	}
+
+	cfg.Reference = o.referenceSource
+	if cfg.Reference == nil {
+		referenceURL := o.referencePriceURL
+		if referenceURL == "" {
+			referenceURL = os.Getenv(EnvReferencePriceURL)
+		}
+		if referenceURL != "" {
+			src, err := reference.NewHTTPSource(referenceURL, os.Getenv(EnvReferencePriceField))
+			if err != nil {
+				return nil, err
+			}
+			cfg.Reference = src
+		}
+	}
+
+	// Data directory - option override, then env var. Without one, files are
+	// kept in the user's config and cache directories.
+	dataDir := o.dataDir
+	if dataDir == "" {
+		dataDir = os.Getenv(EnvDataDir)
+	}
+
+	// Trade journal path - option override, then env var, then the data directory
+	journalPath := os.Getenv(EnvTradeJournalPath)
+	if o.tradeJournalPath != nil {
+		journalPath = *o.tradeJournalPath
+	} else if journalPath == "" {
+		journalPath = dataPath(dataDir, journal.FileName, journal.DefaultPath)
+	}
+	cfg.Journal = journal.New(journalPath)
+
+	// Account aliases path - option override, then env var, then the data directory
+	aliasesPath := os.Getenv(EnvAccountAliasesPath)
+	if o.accountAliasesPath != nil {
+		aliasesPath = *o.accountAliasesPath
+	} else if aliasesPath == "" {
+		aliasesPath = dataPath(dataDir, aliases.FileName, aliases.DefaultPath)
+	}
+	cfg.Aliases = aliases.New(aliasesPath)
+
+	// Tracked orders path - option override, then env var, then the data directory
+	ordersPath := os.Getenv(EnvTrackedOrdersPath)
+	if o.trackedOrdersPath != nil {
+		ordersPath = *o.trackedOrdersPath
+	} else if ordersPath == "" {
+		ordersPath = dataPath(dataDir, orders.FileName, orders.DefaultPath)
 	}
+	cfg.Orders = orders.New(ordersPath)
 
-	// Set domain - first check command line override, then env var, then default
-	domain := DefaultLunoDomain
+	// Imported trades path - option override, then env var, then the data directory
+	tradesPath := os.Getenv(EnvImportedTradesPath)
+	if o.importedTradesPath != nil {
+		tradesPath = *o.importedTradesPath
+	} else if tradesPath == "" {
+		tradesPath = dataPath(dataDir, trades.FileName, trades.DefaultPath)
+	}
+	cfg.Trades = trades.New(tradesPath)
 
-	// Check for environment variable override
-	if envDomain := os.Getenv(strings.TrimSpace(EnvLunoAPIDomain)); envDomain != "" {
-		domain = envDomain
-		fmt.Printf("Using domain from environment variable: %s\n", domain)
+	// Candle cache directory - option override, then env var, then the data directory
+	candleDir := os.Getenv(EnvCandleCacheDir)
+	if o.candleCacheDir != nil {
+		candleDir = *o.candleCacheDir
+	} else if candleDir == "" {
+		candleDir = dataPath(dataDir, candles.DirName, candles.DefaultDir)
 	}
+	cfg.Candles = candles.New(candleDir)
 
-	// Command line override takes precedence if provided
-	if domainOverride != "" {
-		domain = domainOverride
-		fmt.Printf("Using domain from command line: %s\n", domain)
+	// Storage encryption - option override, then env var, then off
+	cfg.StorageEncryption = os.Getenv(EnvStorageEncryption)
+	if o.storageEncryption != nil {
+		cfg.StorageEncryption = *o.storageEncryption
+	}
+	storage, err := storageCipher(o, cfg.StorageEncryption, dataDir)
+	if err != nil {
+		return nil, fmt.Errorf("storage encryption: %w", err)
+	}
+	if storage.Enabled() {
+		slog.Info("Local storage encrypted", slog.String("key", cfg.StorageEncryption))
+	} else {
+		cfg.StorageEncryption = vault.ModeOff
 	}
+	cfg.Journal.SetCipher(storage)
+	cfg.Aliases.SetCipher(storage)
+	cfg.Orders.SetCipher(storage)
+	cfg.Trades.SetCipher(storage)
+
+	// Set domain - option override, then env var, then default
+	domain := ResolveDomain(o.domain)
+	if o.domain != "" {
+		slog.Info("Using domain from command line", slog.String("domain", domain))
+	} else if domain != DefaultLunoDomain {
+		slog.Info("Using domain from environment variable", slog.String("domain", domain))
+	}
+	cfg.Domain = domain
 
 	if domain != DefaultLunoDomain {
 		cfg.LunoClient.SetBaseURL(fmt.Sprintf("https://%s", domain))
 	}
+	cfg.newClient = func() sdk.LunoClient {
+		c := luno.NewClient()
+		c.SetHTTPClient(httpClient)
+		if domain != DefaultLunoDomain {
+			c.SetBaseURL(fmt.Sprintf("https://%s", domain))
+		}
+		return sdk.Wrap(c, middleware...)
+	}
 
 	// Only set authentication if both API Key ID and Secret are provided
 	if apiKeyID != "" && apiKeySecret != "" {
@@ -80,26 +533,382 @@ func Load(domainOverride string) (*Config, error) {
 			return nil, fmt.Errorf("failed to set Luno API credentials: %w", err)
 		}
 		cfg.IsAuthenticated = true
-		fmt.Println("Luno client authenticated with provided API credentials.")
+		slog.Info("Luno client authenticated with provided API credentials")
 	} else {
 		cfg.IsAuthenticated = false
-		fmt.Println("Luno API credentials not found. Operating in unauthenticated mode.")
-	}
-
-	debugMode := parseBoolEnv(EnvLunoAPIDebug)
-	if debugMode {
-		fmt.Println("Debug mode enabled via environment variable")
+		slog.Info("Luno API credentials not found, operating in unauthenticated mode")
 	}
-	cfg.LunoClient.SetDebug(debugMode)
 
 	allowWriteOps := parseBoolEnv(EnvAllowWriteOperations)
+	if o.allowWriteOperations != nil {
+		allowWriteOps = *o.allowWriteOperations
+	}
 	if allowWriteOps {
-		fmt.Println("Write operations enabled via environment variable")
+		slog.Info("Write operations enabled")
 	}
 	cfg.AllowWriteOperations = allowWriteOps
+
+	allowWithdrawals := parseBoolEnv(EnvAllowWithdrawals)
+	if o.allowWithdrawals != nil {
+		allowWithdrawals = *o.allowWithdrawals
+	}
+	if allowWithdrawals {
+		slog.Info("Withdrawals enabled")
+	}
+	cfg.AllowWithdrawals = allowWithdrawals
+
+	cfg.HideUnavailableTools = parseBoolEnv(EnvHideUnavailableTools)
+	if o.hideUnavailableTools != nil {
+		cfg.HideUnavailableTools = *o.hideUnavailableTools
+	}
+
+	// Locale - option override, then env var, then English
+	locale := os.Getenv(EnvLocale)
+	if o.locale != nil {
+		locale = *o.locale
+	}
+	cfg.Locale, err = i18n.Parse(locale)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", EnvLocale, err)
+	}
+
+	// Output format - option override, then env var, then JSON
+	outputFormat := os.Getenv(EnvOutputFormat)
+	if o.outputFormat != nil {
+		outputFormat = *o.outputFormat
+	}
+	cfg.OutputFormat, err = render.Parse(outputFormat)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", EnvOutputFormat, err)
+	}
+
+	maxResponseBytes := DefaultMaxResponseBytes
+	if v := os.Getenv(EnvMaxResponseBytes); v != "" {
+		maxResponseBytes, err = strconv.Atoi(v)
+		if err != nil || maxResponseBytes < 0 {
+			return nil, fmt.Errorf("invalid %s %q: must be a number of bytes, or 0 to disable truncation", EnvMaxResponseBytes, v)
+		}
+	}
+	if o.maxResponseBytes != nil {
+		maxResponseBytes = *o.maxResponseBytes
+	}
+	cfg.MaxResponseBytes = maxResponseBytes
+
+	maxPriceDeviation := DefaultMaxPriceDeviation
+	if v := os.Getenv(EnvMaxPriceDeviation); v != "" {
+		maxPriceDeviation, err = strconv.ParseFloat(v, 64)
+		if err != nil || math.IsNaN(maxPriceDeviation) || math.IsInf(maxPriceDeviation, 0) || maxPriceDeviation < 0 {
+			return nil, fmt.Errorf("invalid %s %q: must be a percentage, or 0 to disable the check", EnvMaxPriceDeviation, v)
+		}
+	}
+	if o.maxPriceDeviation != nil {
+		maxPriceDeviation = *o.maxPriceDeviation
+	}
+	cfg.MaxPriceDeviation = maxPriceDeviation
+
+	duplicateWindow := DefaultDuplicateOrderWindow
+	if v := os.Getenv(EnvDuplicateOrderWindow); v != "" {
+		duplicateWindow, err = time.ParseDuration(v)
+		if err != nil || duplicateWindow < 0 {
+			return nil, fmt.Errorf("invalid %s %q: must be a duration such as 10m, or 0 to disable the check", EnvDuplicateOrderWindow, v)
+		}
+	}
+	if o.duplicateOrderWindow != nil {
+		duplicateWindow = *o.duplicateOrderWindow
+	}
+	cfg.DuplicateOrderWindow = duplicateWindow
+
+	maxCalls, err := intEnv(EnvMaxConcurrentCalls, limiter.DefaultMaxCalls)
+	if err != nil {
+		return nil, err
+	}
+	if o.maxConcurrentCalls != nil {
+		maxCalls = *o.maxConcurrentCalls
+	}
+	maxCallsPerTool, err := intEnv(EnvMaxCallsPerTool, limiter.DefaultMaxCallsPerTool)
+	if err != nil {
+		return nil, err
+	}
+	if o.maxCallsPerTool != nil {
+		maxCallsPerTool = *o.maxCallsPerTool
+	}
+	queueTimeout := limiter.DefaultQueueTimeout
+	if v := os.Getenv(EnvCallQueueTimeout); v != "" {
+		queueTimeout, err = time.ParseDuration(v)
+		if err != nil || queueTimeout < 0 {
+			return nil, fmt.Errorf("invalid %s %q: must be a duration such as 30s, or 0 to wait indefinitely", EnvCallQueueTimeout, v)
+		}
+	}
+	if o.callQueueTimeout != nil {
+		queueTimeout = *o.callQueueTimeout
+	}
+	cfg.Limiter = limiter.New(maxCalls, maxCallsPerTool, queueTimeout)
+
+	repeatThreshold, err := intEnv(EnvRepeatCallThreshold, loopguard.DefaultThreshold)
+	if err != nil {
+		return nil, err
+	}
+	if o.repeatCallThreshold != nil {
+		repeatThreshold = *o.repeatCallThreshold
+	}
+	repeatWindow := loopguard.DefaultWindow
+	if v := os.Getenv(EnvRepeatCallWindow); v != "" {
+		repeatWindow, err = time.ParseDuration(v)
+		if err != nil || repeatWindow <= 0 {
+			return nil, fmt.Errorf("invalid %s %q: must be a positive duration such as 1m", EnvRepeatCallWindow, v)
+		}
+	}
+	if o.repeatCallWindow != nil {
+		repeatWindow = *o.repeatCallWindow
+	}
+	cfg.LoopGuard = loopguard.New(repeatThreshold, repeatWindow)
+
+	tickersInterval := markets.DefaultTickerInterval
+	if v := os.Getenv(EnvTickersInterval); v != "" {
+		tickersInterval, err = time.ParseDuration(v)
+		if err != nil || tickersInterval < 0 {
+			return nil, fmt.Errorf("invalid %s %q: must be a duration such as 30s, or 0 to not refresh in the background", EnvTickersInterval, v)
+		}
+	}
+	if o.tickersInterval != nil {
+		tickersInterval = *o.tickersInterval
+	}
+	cfg.Tickers = markets.NewTickers(cfg.LunoClient, tickersInterval)
+
+	// Deposit alerts - option override, then env var
+	depositAlerts := os.Getenv(EnvDepositAlerts)
+	if o.depositAlerts != nil {
+		depositAlerts = *o.depositAlerts
+	}
+	cfg.DepositAlerts, err = events.ParseDepositRules(depositAlerts)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", EnvDepositAlerts, err)
+	}
+
+	// Trading windows - option override, then env vars
+	tradingWindows, tradingTimezone := os.Getenv(EnvTradingWindows), os.Getenv(EnvTradingTimezone)
+	if o.tradingWindows != nil {
+		tradingWindows, tradingTimezone = *o.tradingWindows, o.tradingTimezone
+	}
+	location := time.UTC
+	if tradingTimezone != "" {
+		if location, err = time.LoadLocation(tradingTimezone); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", EnvTradingTimezone, err)
+		}
+	}
+	if cfg.TradingHours, err = tradinghours.Parse(tradingWindows, location); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", EnvTradingWindows, err)
+	}
+	if cfg.TradingHours != nil {
+		slog.Info("Trading restricted to windows", slog.String("windows", cfg.TradingHours.String()))
+	}
+
+	// Trading pairs - option override, then env var
+	tradingPairs := strings.Split(os.Getenv(EnvTradingPairs), ",")
+	if o.tradingPairs != nil {
+		tradingPairs = *o.tradingPairs
+	}
+	cfg.TradingPairs = upperList(tradingPairs)
+	if len(cfg.TradingPairs) > 0 {
+		slog.Info("Trading restricted to pairs", slog.Any("pairs", cfg.TradingPairs))
+	}
+
+	// Admin API - option override, then env vars
+	cfg.AdminAddr, cfg.AdminToken = o.adminAddr, o.adminToken
+	if cfg.AdminAddr == "" {
+		cfg.AdminAddr = os.Getenv(EnvAdminAddr)
+	}
+	if cfg.AdminToken == "" {
+		if cfg.AdminToken, err = secretEnv(EnvAdminToken); err != nil {
+			return nil, err
+		}
+	}
+	redact.Register(cfg.AdminToken)
+	if cfg.AdminAddr != "" && cfg.AdminToken == "" {
+		return nil, fmt.Errorf("%s must be set to serve the admin API on %s", EnvAdminToken, cfg.AdminAddr)
+	}
+
+	// Audit log file and signing key - option overrides, then env vars
+	auditLogPath, auditKeyPath := o.auditLogPath, o.auditSigningKeyPath
+	if auditLogPath == "" {
+		auditLogPath = os.Getenv(EnvAuditLogPath)
+	}
+	if auditKeyPath == "" {
+		auditKeyPath = os.Getenv(EnvAuditSigningKeyPath)
+	}
+	if auditKeyPath != "" {
+		key, pub, err := audit.LoadKey(auditKeyPath)
+		if err != nil {
+			return nil, err
+		}
+		if key == nil {
+			return nil, fmt.Errorf("%s must hold a private key to sign the audit log, not a public key", EnvAuditSigningKeyPath)
+		}
+		cfg.Audit.SetSigner(key)
+		slog.Info("Audit log entries are signed", slog.String("public_key", base64.StdEncoding.EncodeToString(pub)))
+	}
+	if auditLogPath != "" {
+		if err := cfg.Audit.Open(auditLogPath); err != nil {
+			return nil, err
+		}
+		slog.Info("Audit log kept in a file", slog.String("path", auditLogPath))
+	}
+
+	// Approvals - option overrides, then env vars
+	approvalThresholds, approvalCommand := o.approvalThresholds, o.approvalCommand
+	if approvalThresholds == "" {
+		approvalThresholds = os.Getenv(EnvApprovalThresholds)
+	}
+	if approvalCommand == "" {
+		approvalCommand = os.Getenv(EnvApprovalCommand)
+	}
+	if approvalThresholds != "" {
+		thresholds, err := approvals.ParseThresholds(approvalThresholds)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", EnvApprovalThresholds, err)
+		}
+		timeout := approvals.DefaultTimeout
+		if v := os.Getenv(EnvApprovalTimeout); v != "" {
+			if timeout, err = time.ParseDuration(v); err != nil || timeout <= 0 {
+				return nil, fmt.Errorf("invalid %s %q: want a duration such as 30m", EnvApprovalTimeout, v)
+			}
+		}
+		cfg.Approvals = approvals.NewQueue(thresholds, timeout)
+		cfg.Approvals.Watch(func(r approvals.Request) {
+			cfg.Events.Publish(context.Background(), events.New(events.ApprovalRequested, r))
+		})
+		if command := strings.Fields(approvalCommand); len(command) > 0 {
+			cfg.Approvals.Watch(approvals.CommandApprover(cfg.Approvals, command))
+		}
+		slog.Info("Actions above thresholds held for approval", slog.String("thresholds", thresholds.String()))
+		if approvalCommand == "" && cfg.AdminAddr == "" {
+			slog.Warn("Actions are held for approval, but neither an approval command nor the admin API is set up to approve them")
+		}
+	}
+
+	// Access tokens - option override, then env var
+	accessTokens := o.accessTokens
+	if accessTokens == "" {
+		if accessTokens, err = secretEnv(EnvAccessTokens); err != nil {
+			return nil, err
+		}
+	}
+	if accessTokens != "" {
+		if cfg.AccessTokens, err = roles.ParseTokens(accessTokens); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", EnvAccessTokens, err)
+		}
+		slog.Info("Tool calls restricted by the role of their access token", slog.Int("access_tokens", cfg.AccessTokens.Len()))
+	}
+
+	// Tenant credentials - provider option, then URL option, then env vars
+	provider := o.tenantsProvider
+	if provider == nil {
+		providerURL := o.credentialsProviderURL
+		if providerURL == "" {
+			providerURL = os.Getenv(EnvCredentialsProvider)
+		}
+		if provider, err = credentialsProvider(providerURL); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", EnvCredentialsProvider, err)
+		}
+	}
+	if provider != nil {
+		cacheTTL := tenants.DefaultCacheTTL
+		if v := os.Getenv(EnvCredentialsCacheTTL); v != "" {
+			if cacheTTL, err = time.ParseDuration(v); err != nil || cacheTTL < 0 {
+				return nil, fmt.Errorf("invalid %s %q: want a duration such as 5m", EnvCredentialsCacheTTL, v)
+			}
+		}
+		cfg.Tenants = tenants.NewCache(provider, cacheTTL)
+		cfg.TenantHeader = cmp.Or(o.tenantHeader, os.Getenv(EnvTenantHeader), tenants.DefaultHeader)
+		slog.Info("Sessions use the Luno API credentials of their tenant", slog.String("tenant_header", cfg.TenantHeader))
+		if cfg.IsAuthenticated {
+			slog.Warn("Server API credentials are set but not used by sessions, which use their tenant's credentials")
+		}
+	}
 	return cfg, nil
 }
 
+// storageCipher returns the cipher for mode, or nil when storage is not
+// encrypted. The key file is kept in the data directory or the user's config
+// directory.
+func storageCipher(o options, mode, dataDir string) (*vault.Cipher, error) {
+	keyPath := dataPath(dataDir, vault.KeyFileName, func() string { return fsutil.ConfigPath(vault.KeyFileName) })
+	switch mode {
+	case "", vault.ModeOff:
+		return nil, nil
+	case vault.ModePassphrase:
+		passphrase, err := secretEnv(EnvStoragePassphrase)
+		if err != nil {
+			return nil, err
+		}
+		if passphrase == "" {
+			return nil, fmt.Errorf("%s=passphrase needs %s or %s_FILE", EnvStorageEncryption, EnvStoragePassphrase, EnvStoragePassphrase)
+		}
+		redact.Register(passphrase)
+		return vault.FromPassphrase(passphrase, keyPath)
+	case vault.ModeKeychain:
+		kc := o.keychain
+		if kc == nil {
+			var err error
+			if kc, err = vault.SystemKeychain(); err != nil {
+				return nil, err
+			}
+		}
+		return vault.FromKeychain(kc, keyPath)
+	default:
+		return nil, fmt.Errorf("invalid %s %q: must be off, passphrase or keychain", EnvStorageEncryption, mode)
+	}
+}
+
+// dataPath returns name in dataDir, or def's path when there is no data directory
+func dataPath(dataDir, name string, def func() string) string {
+	if dataDir == "" {
+		return def()
+	}
+	return filepath.Join(dataDir, name)
+}
+
+// intEnv reads a non-negative integer from the environment variable key,
+// returning def if it is not set
+func intEnv(key string, def int) (int, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid %s %q: must be a number, or 0 for no limit", key, v)
+	}
+	return n, nil
+}
+
+// newHTTPClient returns the HTTP client for the Luno client, wrapping the
+// configured client's transport in rt, which is given the application name and
+// version from o.
+func newHTTPClient(o options, rt *sdk.MCPRoundTripper) *http.Client {
+	hc := &http.Client{Timeout: defaultHTTPTimeout, Transport: newTransport()}
+	if o.httpClient != nil {
+		c := *o.httpClient
+		hc = &c
+	}
+
+	rt.Next = hc.Transport
+	rt.AppName = o.appName
+	rt.AppVersion = o.appVersion
+	hc.Transport = rt
+	return hc
+}
+
+// newTransport returns an http.Transport tuned for many concurrent requests to the Luno API
+func newTransport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConns = maxIdleConns
+	t.MaxIdleConnsPerHost = maxIdleConnsPerHost
+	t.IdleConnTimeout = idleConnTimeout
+	t.ForceAttemptHTTP2 = true
+	return t
+}
+
 // parseBoolEnv returns true if the environment variable is set to "true", "1", or "yes" (case-insensitive).
 func parseBoolEnv(key string) bool {
 	val := os.Getenv(strings.TrimSpace(key))
@@ -108,6 +917,17 @@ func parseBoolEnv(key string) bool {
 		strings.ToLower(val) == "yes"
 }
 
+// upperList trims and upper-cases the items of list, leaving out empty ones
+func upperList(list []string) []string {
+	var out []string
+	for _, item := range list {
+		if item = strings.ToUpper(strings.TrimSpace(item)); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
 // FormatCurrency formats a decimal amount with the currency code
 func FormatCurrency(amount decimal.Decimal, currency string) string {
 	return fmt.Sprintf("%s %s", amount.String(), strings.ToUpper(currency))
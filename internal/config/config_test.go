@@ -1,39 +1,39 @@
 package config
 
 import (
+	"context"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/luno/luno-go"
 	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/internal/aliases"
+	"github.com/luno/luno-mcp/internal/audit"
+	"github.com/luno/luno-mcp/internal/candles"
+	"github.com/luno/luno-mcp/internal/i18n"
+	"github.com/luno/luno-mcp/internal/journal"
+	"github.com/luno/luno-mcp/internal/limiter"
+	"github.com/luno/luno-mcp/internal/loopguard"
+	"github.com/luno/luno-mcp/internal/markets"
+	"github.com/luno/luno-mcp/internal/orders"
+	"github.com/luno/luno-mcp/internal/reference"
+	"github.com/luno/luno-mcp/internal/render"
+	"github.com/luno/luno-mcp/internal/tenants"
+	"github.com/luno/luno-mcp/internal/trades"
+	"github.com/luno/luno-mcp/internal/vault"
+	"github.com/luno/luno-mcp/sdk"
 )
 
-func TestMaskValue(t *testing.T) {
-	tests := []struct {
-		name     string
-		input    string
-		expected string
-	}{
-		{"empty string", "", ""},
-		{"single character", "a", "*"},
-		{"two characters", "ab", "**"},
-		{"three characters", "abc", "***"},
-		{"four characters", "abcd", "****"},
-		{"five characters", "abcde", "abcd*"},
-		{"long string", "verylongstring", "very**********"},
-		{"api key example", "testkey123", "test******"},
-	}
-
-	for _, tc := range tests {
-		t.Run(tc.name, func(t *testing.T) {
-			result := maskValue(tc.input)
-			if result != tc.expected {
-				t.Errorf("maskValue(%q) = %q, want %q", tc.input, result, tc.expected)
-			}
-		})
-	}
-}
-
 func TestFormatCurrency(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -90,6 +90,7 @@ func TestLoad(t *testing.T) {
 		expectedError         string
 		expectedDomain        string
 		expectAuth            bool
+		expectedDebug         bool
 		expectedAllowWriteOps bool
 	}{
 		{
@@ -130,25 +131,28 @@ func TestLoad(t *testing.T) {
 			expectAuth:     true,
 		},
 		{
-			name:       "debug mode enabled with true",
-			apiKeyID:   "test_key_id",
-			apiSecret:  "test_secret",
-			debugEnv:   "true",
-			expectAuth: true,
+			name:          "debug mode enabled with true",
+			apiKeyID:      "test_key_id",
+			apiSecret:     "test_secret",
+			debugEnv:      "true",
+			expectAuth:    true,
+			expectedDebug: true,
 		},
 		{
-			name:       "debug mode enabled with 1",
-			apiKeyID:   "test_key_id",
-			apiSecret:  "test_secret",
-			debugEnv:   "1",
-			expectAuth: true,
+			name:          "debug mode enabled with 1",
+			apiKeyID:      "test_key_id",
+			apiSecret:     "test_secret",
+			debugEnv:      "1",
+			expectAuth:    true,
+			expectedDebug: true,
 		},
 		{
-			name:       "debug mode enabled with yes",
-			apiKeyID:   "test_key_id",
-			apiSecret:  "test_secret",
-			debugEnv:   "yes",
-			expectAuth: true,
+			name:          "debug mode enabled with yes",
+			apiKeyID:      "test_key_id",
+			apiSecret:     "test_secret",
+			debugEnv:      "yes",
+			expectAuth:    true,
+			expectedDebug: true,
 		},
 		{
 			name:       "debug mode disabled with false",
@@ -216,7 +220,7 @@ func TestLoad(t *testing.T) {
 			setEnvVar(EnvLunoAPIDebug, tc.debugEnv)
 			setEnvVar(EnvAllowWriteOperations, tc.allowWriteOpsEnv)
 
-			cfg, err := Load(tc.domainOverride)
+			cfg, err := Load(WithDomain(tc.domainOverride))
 
 			if tc.expectedError != "" {
 				if err == nil {
@@ -247,6 +251,14 @@ func TestLoad(t *testing.T) {
 				t.Errorf("Expected IsAuthenticated to be %v, but got %v", tc.expectAuth, cfg.IsAuthenticated)
 			}
 
+			if tc.expectedDomain != "" && cfg.Domain != tc.expectedDomain {
+				t.Errorf("Expected Domain to be %q, but got %q", tc.expectedDomain, cfg.Domain)
+			}
+
+			if cfg.HTTPDebug.Enabled() != tc.expectedDebug {
+				t.Errorf("Expected HTTPDebug enabled to be %v, but got %v", tc.expectedDebug, cfg.HTTPDebug.Enabled())
+			}
+
 			if cfg.AllowWriteOperations != tc.expectedAllowWriteOps {
 				t.Errorf("%s: expected AllowWriteOperations=%v, got %v", tc.name, tc.expectedAllowWriteOps, cfg.AllowWriteOperations)
 			}
@@ -262,3 +274,1405 @@ func setEnvVar(key, value string) {
 		os.Setenv(key, value)
 	}
 }
+
+func TestLoadOptions(t *testing.T) {
+	var userAgent string
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+		_, _ = w.Write([]byte(`{"pair":"XBTZAR"}`))
+	}))
+	defer ts.Close()
+	domain := strings.TrimPrefix(ts.URL, "https://")
+
+	tests := []struct {
+		name                  string
+		env                   map[string]string
+		opts                  []Option
+		expectedError         string
+		expectAuth            bool
+		expectedAllowWriteOps bool
+		expectedWithdrawals   bool
+		expectedHideTools     bool
+		expectedEvents        bool
+		expectedUserAgent     string
+	}{
+		{
+			name:       "static credentials override environment",
+			opts:       []Option{WithCredentials("id", "secret")},
+			expectAuth: true,
+		},
+		{
+			name: "credentials source error",
+			opts: []Option{WithCredentialsSource(func() (string, string, error) {
+				return "", "", errors.New("vault sealed")
+			})},
+			expectedError: "vault sealed",
+		},
+		{
+			name:                  "write operations option overrides environment",
+			env:                   map[string]string{EnvAllowWriteOperations: "true"},
+			opts:                  []Option{WithAllowWriteOperations(false)},
+			expectedAllowWriteOps: false,
+		},
+		{
+			name:                  "write operations from environment",
+			env:                   map[string]string{EnvAllowWriteOperations: "true"},
+			expectedAllowWriteOps: true,
+		},
+		{
+			name:                "withdrawals from environment",
+			env:                 map[string]string{EnvAllowWithdrawals: "yes"},
+			expectedWithdrawals: true,
+		},
+		{
+			name:                "withdrawals option overrides environment",
+			env:                 map[string]string{EnvAllowWithdrawals: "true"},
+			opts:                []Option{WithAllowWithdrawals(false)},
+			expectedWithdrawals: false,
+		},
+		{
+			name:              "hide unavailable tools from environment",
+			env:               map[string]string{EnvHideUnavailableTools: "1"},
+			expectedHideTools: true,
+		},
+		{
+			name:              "hide unavailable tools option overrides environment",
+			env:               map[string]string{EnvHideUnavailableTools: "true"},
+			opts:              []Option{WithHideUnavailableTools(false)},
+			expectedHideTools: false,
+		},
+		{
+			name:           "webhook from environment",
+			env:            map[string]string{EnvWebhookURL: "https://example.com/hook", EnvWebhookSecret: "secret"},
+			expectedEvents: true,
+		},
+		{
+			name:           "webhook option",
+			opts:           []Option{WithWebhook("http://localhost:8080/hook", "")},
+			expectedEvents: true,
+		},
+		{
+			name:          "invalid webhook URL",
+			env:           map[string]string{EnvWebhookURL: "example.com/hook"},
+			expectedError: "invalid webhook URL",
+		},
+		{
+			name:              "app info is sent in the user agent",
+			opts:              []Option{WithDomain(domain), WithHTTPClient(ts.Client()), WithAppInfo("luno-mcp", "1.2.3"), WithDebug(false)},
+			expectedUserAgent: "luno-mcp/1.2.3 LunoGoSDK/",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(EnvLunoAPIKeyID, "")
+			t.Setenv(EnvLunoAPIKeySecret, "")
+			t.Setenv(EnvLunoAPIDomain, "")
+			t.Setenv(EnvAllowWriteOperations, "")
+			t.Setenv(EnvAllowWithdrawals, "")
+			t.Setenv(EnvHideUnavailableTools, "")
+			t.Setenv(EnvWebhookURL, "")
+			t.Setenv(EnvWebhookSecret, "")
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+
+			cfg, err := Load(tc.opts...)
+			if tc.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+					t.Fatalf("Expected error containing %q, got %v", tc.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if cfg.IsAuthenticated != tc.expectAuth {
+				t.Errorf("Expected IsAuthenticated to be %v, but got %v", tc.expectAuth, cfg.IsAuthenticated)
+			}
+			if cfg.AllowWriteOperations != tc.expectedAllowWriteOps {
+				t.Errorf("Expected AllowWriteOperations to be %v, but got %v", tc.expectedAllowWriteOps, cfg.AllowWriteOperations)
+			}
+			if cfg.AllowWithdrawals != tc.expectedWithdrawals {
+				t.Errorf("Expected AllowWithdrawals to be %v, but got %v", tc.expectedWithdrawals, cfg.AllowWithdrawals)
+			}
+			if cfg.HideUnavailableTools != tc.expectedHideTools {
+				t.Errorf("Expected HideUnavailableTools to be %v, but got %v", tc.expectedHideTools, cfg.HideUnavailableTools)
+			}
+			if cfg.Events.Enabled() != tc.expectedEvents {
+				t.Errorf("Expected Events.Enabled() to be %v, but got %v", tc.expectedEvents, cfg.Events.Enabled())
+			}
+
+			if tc.expectedUserAgent != "" {
+				if _, err := cfg.LunoClient.GetTicker(context.Background(), &luno.GetTickerRequest{Pair: "XBTZAR"}); err != nil {
+					t.Fatalf("GetTicker failed: %v", err)
+				}
+				if !strings.HasPrefix(userAgent, tc.expectedUserAgent) {
+					t.Errorf("Expected User-Agent to start with %q, got %q", tc.expectedUserAgent, userAgent)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadMaxResponseBytes(t *testing.T) {
+	tests := []struct {
+		name          string
+		env           string
+		opts          []Option
+		expected      int
+		expectedError string
+	}{
+		{name: "default", expected: DefaultMaxResponseBytes},
+		{name: "from environment", env: "50000", expected: 50000},
+		{name: "disabled from environment", env: "0", expected: 0},
+		{name: "option overrides environment", env: "50000", opts: []Option{WithMaxResponseBytes(1000)}, expected: 1000},
+		{name: "invalid", env: "20KB", expectedError: "invalid MAX_RESPONSE_BYTES"},
+		{name: "negative", env: "-1", expectedError: "invalid MAX_RESPONSE_BYTES"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(EnvLunoAPIKeyID, "")
+			t.Setenv(EnvLunoAPIKeySecret, "")
+			t.Setenv(EnvMaxResponseBytes, tc.env)
+
+			cfg, err := Load(tc.opts...)
+			if tc.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+					t.Fatalf("Expected error containing %q, got %v", tc.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if cfg.MaxResponseBytes != tc.expected {
+				t.Errorf("Expected MaxResponseBytes to be %d, but got %d", tc.expected, cfg.MaxResponseBytes)
+			}
+		})
+	}
+}
+
+func TestLoadMaxPriceDeviation(t *testing.T) {
+	tests := []struct {
+		name          string
+		env           string
+		opts          []Option
+		expected      float64
+		expectedError string
+	}{
+		{name: "default", expected: DefaultMaxPriceDeviation},
+		{name: "from environment", env: "12.5", expected: 12.5},
+		{name: "disabled from environment", env: "0", expected: 0},
+		{name: "option overrides environment", env: "12.5", opts: []Option{WithMaxPriceDeviation(5)}, expected: 5},
+		{name: "invalid", env: "10%", expectedError: "invalid MAX_PRICE_DEVIATION"},
+		{name: "negative", env: "-1", expectedError: "invalid MAX_PRICE_DEVIATION"},
+		{name: "not a number", env: "NaN", expectedError: "invalid MAX_PRICE_DEVIATION"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(EnvLunoAPIKeyID, "")
+			t.Setenv(EnvLunoAPIKeySecret, "")
+			t.Setenv(EnvMaxPriceDeviation, tc.env)
+
+			cfg, err := Load(tc.opts...)
+			if tc.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+					t.Fatalf("Expected error containing %q, got %v", tc.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if cfg.MaxPriceDeviation != tc.expected {
+				t.Errorf("Expected MaxPriceDeviation to be %g, but got %g", tc.expected, cfg.MaxPriceDeviation)
+			}
+		})
+	}
+}
+
+func TestLoadDuplicateOrderWindow(t *testing.T) {
+	tests := []struct {
+		name          string
+		env           string
+		opts          []Option
+		expected      time.Duration
+		expectedError string
+	}{
+		{name: "default", expected: DefaultDuplicateOrderWindow},
+		{name: "from environment", env: "2m", expected: 2 * time.Minute},
+		{name: "disabled from environment", env: "0", expected: 0},
+		{name: "option overrides environment", env: "2m", opts: []Option{WithDuplicateOrderWindow(time.Hour)}, expected: time.Hour},
+		{name: "invalid", env: "10", expectedError: "invalid DUPLICATE_ORDER_WINDOW"},
+		{name: "negative", env: "-1m", expectedError: "invalid DUPLICATE_ORDER_WINDOW"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(EnvLunoAPIKeyID, "")
+			t.Setenv(EnvLunoAPIKeySecret, "")
+			t.Setenv(EnvDuplicateOrderWindow, tc.env)
+
+			cfg, err := Load(tc.opts...)
+			if tc.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+					t.Fatalf("Expected error containing %q, got %v", tc.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if cfg.DuplicateOrderWindow != tc.expected {
+				t.Errorf("Expected DuplicateOrderWindow to be %s, but got %s", tc.expected, cfg.DuplicateOrderWindow)
+			}
+		})
+	}
+}
+
+func TestLoadReference(t *testing.T) {
+	custom := reference.SourceFunc(func(context.Context, reference.Pair) (reference.Quote, error) {
+		return reference.Quote{}, nil
+	})
+
+	tests := []struct {
+		name          string
+		env           map[string]string
+		opts          []Option
+		expectedURL   string
+		expectedField string
+		expectedFunc  bool
+		expectedError string
+	}{
+		{name: "not configured"},
+		{
+			name:          "from environment",
+			env:           map[string]string{EnvReferencePriceURL: "https://example.com/{pair}", EnvReferencePriceField: "data.rate"},
+			expectedURL:   "https://example.com/{pair}",
+			expectedField: "data.rate",
+		},
+		{
+			name:          "option overrides environment",
+			env:           map[string]string{EnvReferencePriceURL: "https://example.com/{pair}"},
+			opts:          []Option{WithReferencePriceURL("https://index.example.com/{base}/{counter}")},
+			expectedURL:   "https://index.example.com/{base}/{counter}",
+			expectedField: reference.DefaultPriceField,
+		},
+		{
+			name:         "custom source overrides URL",
+			env:          map[string]string{EnvReferencePriceURL: "https://example.com/{pair}"},
+			opts:         []Option{WithReferenceSource(custom)},
+			expectedFunc: true,
+		},
+		{
+			name:          "invalid URL",
+			env:           map[string]string{EnvReferencePriceURL: "example.com/price"},
+			expectedError: "invalid reference price URL",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(EnvReferencePriceURL, "")
+			t.Setenv(EnvReferencePriceField, "")
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+
+			cfg, err := Load(tc.opts...)
+			if tc.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+					t.Fatalf("Expected error containing %q, got %v", tc.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			switch src := cfg.Reference.(type) {
+			case nil:
+				if tc.expectedURL != "" || tc.expectedFunc {
+					t.Errorf("Expected a reference source, got nil")
+				}
+			case *reference.HTTPSource:
+				if src.URL != tc.expectedURL || src.Field != tc.expectedField {
+					t.Errorf("Expected HTTP source %q with field %q, got %q with field %q", tc.expectedURL, tc.expectedField, src.URL, src.Field)
+				}
+			case reference.SourceFunc:
+				if !tc.expectedFunc {
+					t.Errorf("Unexpected custom reference source")
+				}
+			default:
+				t.Errorf("Unexpected reference source %T", src)
+			}
+		})
+	}
+}
+
+func TestLoadTradeJournal(t *testing.T) {
+	tests := []struct {
+		name         string
+		env          string
+		opts         []Option
+		expectedPath string
+	}{
+		{name: "default", expectedPath: journal.DefaultPath()},
+		{name: "from environment", env: "/tmp/journal.jsonl", expectedPath: "/tmp/journal.jsonl"},
+		{
+			name:         "option overrides environment",
+			env:          "/tmp/journal.jsonl",
+			opts:         []Option{WithTradeJournalPath("/var/lib/luno-mcp/journal.jsonl")},
+			expectedPath: "/var/lib/luno-mcp/journal.jsonl",
+		},
+		{name: "in memory", env: "/tmp/journal.jsonl", opts: []Option{WithTradeJournalPath("")}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(EnvTradeJournalPath, tc.env)
+
+			cfg, err := Load(tc.opts...)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if cfg.Journal == nil {
+				t.Fatal("Expected a trade journal")
+			}
+			if got := cfg.Journal.Path(); got != tc.expectedPath {
+				t.Errorf("Expected journal path %q, got %q", tc.expectedPath, got)
+			}
+		})
+	}
+}
+
+func TestLoadAccountAliases(t *testing.T) {
+	tests := []struct {
+		name         string
+		env          string
+		opts         []Option
+		expectedPath string
+	}{
+		{name: "default", expectedPath: aliases.DefaultPath()},
+		{name: "from environment", env: "/tmp/aliases.json", expectedPath: "/tmp/aliases.json"},
+		{
+			name:         "option overrides environment",
+			env:          "/tmp/aliases.json",
+			opts:         []Option{WithAccountAliasesPath("/var/lib/luno-mcp/aliases.json")},
+			expectedPath: "/var/lib/luno-mcp/aliases.json",
+		},
+		{name: "in memory", env: "/tmp/aliases.json", opts: []Option{WithAccountAliasesPath("")}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(EnvAccountAliasesPath, tc.env)
+
+			cfg, err := Load(tc.opts...)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if cfg.Aliases == nil {
+				t.Fatal("Expected an alias store")
+			}
+			if got := cfg.Aliases.Path(); got != tc.expectedPath {
+				t.Errorf("Expected aliases path %q, got %q", tc.expectedPath, got)
+			}
+		})
+	}
+}
+
+func TestLoadTrackedOrders(t *testing.T) {
+	tests := []struct {
+		name         string
+		env          string
+		opts         []Option
+		expectedPath string
+	}{
+		{name: "default", expectedPath: orders.DefaultPath()},
+		{name: "from environment", env: "/tmp/orders.json", expectedPath: "/tmp/orders.json"},
+		{
+			name:         "option overrides environment",
+			env:          "/tmp/orders.json",
+			opts:         []Option{WithTrackedOrdersPath("/var/lib/luno-mcp/orders.json")},
+			expectedPath: "/var/lib/luno-mcp/orders.json",
+		},
+		{name: "in memory", env: "/tmp/orders.json", opts: []Option{WithTrackedOrdersPath("")}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(EnvTrackedOrdersPath, tc.env)
+
+			cfg, err := Load(tc.opts...)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if cfg.Orders == nil {
+				t.Fatal("Expected an order store")
+			}
+			if got := cfg.Orders.Path(); got != tc.expectedPath {
+				t.Errorf("Expected tracked orders path %q, got %q", tc.expectedPath, got)
+			}
+		})
+	}
+}
+
+func TestLoadImportedTrades(t *testing.T) {
+	tests := []struct {
+		name         string
+		env          string
+		opts         []Option
+		expectedPath string
+	}{
+		{name: "default", expectedPath: trades.DefaultPath()},
+		{name: "from environment", env: "/tmp/imported-trades.json", expectedPath: "/tmp/imported-trades.json"},
+		{
+			name:         "option overrides environment",
+			env:          "/tmp/imported-trades.json",
+			opts:         []Option{WithImportedTradesPath("/var/lib/luno-mcp/imported-trades.json")},
+			expectedPath: "/var/lib/luno-mcp/imported-trades.json",
+		},
+		{name: "in memory", env: "/tmp/imported-trades.json", opts: []Option{WithImportedTradesPath("")}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(EnvImportedTradesPath, tc.env)
+
+			cfg, err := Load(tc.opts...)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if cfg.Trades == nil {
+				t.Fatal("Expected a trade store")
+			}
+			if got := cfg.Trades.Path(); got != tc.expectedPath {
+				t.Errorf("Expected imported trades path %q, got %q", tc.expectedPath, got)
+			}
+		})
+	}
+}
+
+func TestLoadCandleCache(t *testing.T) {
+	tests := []struct {
+		name        string
+		env         string
+		opts        []Option
+		expectedDir string
+	}{
+		{name: "default", expectedDir: candles.DefaultDir()},
+		{name: "from environment", env: "/tmp/candles", expectedDir: "/tmp/candles"},
+		{
+			name:        "option overrides environment",
+			env:         "/tmp/candles",
+			opts:        []Option{WithCandleCacheDir("/var/cache/luno-mcp/candles")},
+			expectedDir: "/var/cache/luno-mcp/candles",
+		},
+		{name: "in memory", env: "/tmp/candles", opts: []Option{WithCandleCacheDir("")}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(EnvCandleCacheDir, tc.env)
+
+			cfg, err := Load(tc.opts...)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if cfg.Candles == nil {
+				t.Fatal("Expected a candle store")
+			}
+			if got := cfg.Candles.Dir(); got != tc.expectedDir {
+				t.Errorf("Expected candle cache directory %q, got %q", tc.expectedDir, got)
+			}
+		})
+	}
+}
+
+func TestLoadTelemetry(t *testing.T) {
+	t.Setenv(EnvTelemetryURL, "")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.Telemetry != nil {
+		t.Error("Expected telemetry to be off unless a URL is set")
+	}
+
+	t.Setenv(EnvTelemetryURL, "https://example.com/telemetry")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if status := cfg.Telemetry.Status(); !status.Enabled || status.URL != "https://example.com/telemetry" {
+		t.Errorf("Expected telemetry to be sent to %s, got %+v", "https://example.com/telemetry", status)
+	}
+
+	_, err = Load(WithTelemetryURL("example.com"))
+	if err == nil || err.Error() != `invalid telemetry URL "example.com": must be an http or https URL` {
+		t.Errorf("Expected the option to take precedence and be checked, got %v", err)
+	}
+}
+
+func TestLoadUpdateCheck(t *testing.T) {
+	t.Setenv(EnvDisableUpdateCheck, "")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.Updates == nil {
+		t.Error("Expected update checks to be on by default")
+	}
+
+	t.Setenv(EnvDisableUpdateCheck, "true")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.Updates != nil {
+		t.Errorf("Expected %s to disable update checks", EnvDisableUpdateCheck)
+	}
+
+	cfg, err = Load(WithUpdateCheck(true))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.Updates == nil {
+		t.Error("Expected the option to take precedence")
+	}
+}
+
+func TestLoadDataDir(t *testing.T) {
+	t.Setenv(EnvDataDir, "/data")
+	t.Setenv(EnvTrackedOrdersPath, "/tmp/orders.json")
+
+	cfg, err := Load(WithImportedTradesPath("/var/lib/luno-mcp/trades.json"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	paths := map[string]string{
+		"journal":         cfg.Journal.Path(),
+		"aliases":         cfg.Aliases.Path(),
+		"tracked orders":  cfg.Orders.Path(),
+		"imported trades": cfg.Trades.Path(),
+		"candle cache":    cfg.Candles.Dir(),
+	}
+	expected := map[string]string{
+		"journal":         "/data/trade-journal.jsonl",
+		"aliases":         "/data/account-aliases.json",
+		"tracked orders":  "/tmp/orders.json",
+		"imported trades": "/var/lib/luno-mcp/trades.json",
+		"candle cache":    "/data/candles",
+	}
+	for name, want := range expected {
+		if paths[name] != want {
+			t.Errorf("Expected %s path %q, got %q", name, want, paths[name])
+		}
+	}
+
+	cfg, err = Load(WithDataDir("/srv/luno-mcp"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := cfg.Journal.Path(); got != "/srv/luno-mcp/trade-journal.jsonl" {
+		t.Errorf("Expected the option to take precedence over %s, got journal path %q", EnvDataDir, got)
+	}
+}
+
+// memoryKeychain is a vault.Keychain holding its key in memory
+type memoryKeychain struct{ key []byte }
+
+func (k *memoryKeychain) Get() ([]byte, error) {
+	if k.key == nil {
+		return nil, vault.ErrNotFound
+	}
+	return k.key, nil
+}
+
+func (k *memoryKeychain) Set(key []byte) error {
+	k.key = key
+	return nil
+}
+
+func TestLoadStorageEncryption(t *testing.T) {
+	tests := []struct {
+		name          string
+		env           map[string]string
+		opts          []Option
+		expectedMode  string
+		expectedError string
+	}{
+		{
+			name:         "off by default",
+			expectedMode: vault.ModeOff,
+		},
+		{
+			name:         "passphrase",
+			env:          map[string]string{EnvStorageEncryption: "passphrase", EnvStoragePassphrase: "correct horse battery"},
+			expectedMode: vault.ModePassphrase,
+		},
+		{
+			name:         "keychain",
+			opts:         []Option{WithStorageEncryption("keychain"), func(o *options) { o.keychain = &memoryKeychain{} }},
+			expectedMode: vault.ModeKeychain,
+		},
+		{
+			name:         "option overrides environment",
+			env:          map[string]string{EnvStorageEncryption: "passphrase"},
+			opts:         []Option{WithStorageEncryption("off")},
+			expectedMode: vault.ModeOff,
+		},
+		{
+			name:          "passphrase missing",
+			env:           map[string]string{EnvStorageEncryption: "passphrase"},
+			expectedError: "storage encryption: STORAGE_ENCRYPTION=passphrase needs STORAGE_PASSPHRASE or STORAGE_PASSPHRASE_FILE",
+		},
+		{
+			name:          "invalid",
+			env:           map[string]string{EnvStorageEncryption: "aes"},
+			expectedError: `storage encryption: invalid STORAGE_ENCRYPTION "aes": must be off, passphrase or keychain`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(EnvStorageEncryption, "")
+			t.Setenv(EnvStoragePassphrase, "")
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+			dir := t.TempDir()
+
+			cfg, err := Load(append([]Option{WithDataDir(dir)}, tc.opts...)...)
+			if tc.expectedError != "" {
+				if err == nil || err.Error() != tc.expectedError {
+					t.Fatalf("Expected error %q, got %v", tc.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if cfg.StorageEncryption != tc.expectedMode {
+				t.Errorf("Expected storage encryption %q, got %q", tc.expectedMode, cfg.StorageEncryption)
+			}
+			_, err = os.Stat(filepath.Join(dir, vault.KeyFileName))
+			if encrypted := tc.expectedMode != vault.ModeOff; encrypted == os.IsNotExist(err) {
+				t.Errorf("Expected a key file only when storage is encrypted, got %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadConcurrencyLimits(t *testing.T) {
+	tests := []struct {
+		name                    string
+		env                     map[string]string
+		opts                    []Option
+		expectedMaxCalls        int
+		expectedMaxCallsPerTool int
+		expectedQueueTimeout    time.Duration
+		expectedError           string
+	}{
+		{
+			name:                    "defaults",
+			expectedMaxCalls:        limiter.DefaultMaxCalls,
+			expectedMaxCallsPerTool: limiter.DefaultMaxCallsPerTool,
+			expectedQueueTimeout:    limiter.DefaultQueueTimeout,
+		},
+		{
+			name:                    "from environment",
+			env:                     map[string]string{EnvMaxConcurrentCalls: "32", EnvMaxCallsPerTool: "0", EnvCallQueueTimeout: "5s"},
+			expectedMaxCalls:        32,
+			expectedMaxCallsPerTool: 0,
+			expectedQueueTimeout:    5 * time.Second,
+		},
+		{
+			name: "options override environment",
+			env:  map[string]string{EnvMaxConcurrentCalls: "32", EnvMaxCallsPerTool: "8", EnvCallQueueTimeout: "5s"},
+			opts: []Option{
+				WithMaxConcurrentCalls(2),
+				WithMaxConcurrentCallsPerTool(1),
+				WithCallQueueTimeout(time.Minute),
+			},
+			expectedMaxCalls:        2,
+			expectedMaxCallsPerTool: 1,
+			expectedQueueTimeout:    time.Minute,
+		},
+		{
+			name:          "invalid limit",
+			env:           map[string]string{EnvMaxConcurrentCalls: "lots"},
+			expectedError: "invalid MAX_CONCURRENT_CALLS",
+		},
+		{
+			name:          "negative per-tool limit",
+			env:           map[string]string{EnvMaxCallsPerTool: "-1"},
+			expectedError: "invalid MAX_CONCURRENT_CALLS_PER_TOOL",
+		},
+		{
+			name:          "invalid queue timeout",
+			env:           map[string]string{EnvCallQueueTimeout: "30"},
+			expectedError: "invalid CALL_QUEUE_TIMEOUT",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(EnvLunoAPIKeyID, "")
+			t.Setenv(EnvLunoAPIKeySecret, "")
+			for _, key := range []string{EnvMaxConcurrentCalls, EnvMaxCallsPerTool, EnvCallQueueTimeout} {
+				t.Setenv(key, tc.env[key])
+			}
+
+			cfg, err := Load(tc.opts...)
+			if tc.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+					t.Fatalf("Expected error containing %q, got %v", tc.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := cfg.Limiter.MaxCalls(); got != tc.expectedMaxCalls {
+				t.Errorf("Expected MaxCalls to be %d, but got %d", tc.expectedMaxCalls, got)
+			}
+			if got := cfg.Limiter.MaxCallsPerTool(); got != tc.expectedMaxCallsPerTool {
+				t.Errorf("Expected MaxCallsPerTool to be %d, but got %d", tc.expectedMaxCallsPerTool, got)
+			}
+			if got := cfg.Limiter.QueueTimeout(); got != tc.expectedQueueTimeout {
+				t.Errorf("Expected QueueTimeout to be %v, but got %v", tc.expectedQueueTimeout, got)
+			}
+		})
+	}
+}
+
+func TestLoadRepeatCallGuard(t *testing.T) {
+	tests := []struct {
+		name              string
+		env               map[string]string
+		opts              []Option
+		expectedThreshold int
+		expectedWindow    time.Duration
+		expectedError     string
+	}{
+		{
+			name:              "defaults",
+			expectedThreshold: loopguard.DefaultThreshold,
+			expectedWindow:    loopguard.DefaultWindow,
+		},
+		{
+			name:              "from environment",
+			env:               map[string]string{EnvRepeatCallThreshold: "0", EnvRepeatCallWindow: "30s"},
+			expectedThreshold: 0,
+			expectedWindow:    30 * time.Second,
+		},
+		{
+			name:              "options override environment",
+			env:               map[string]string{EnvRepeatCallThreshold: "3", EnvRepeatCallWindow: "30s"},
+			opts:              []Option{WithRepeatCallThreshold(10), WithRepeatCallWindow(5 * time.Minute)},
+			expectedThreshold: 10,
+			expectedWindow:    5 * time.Minute,
+		},
+		{
+			name:          "invalid threshold",
+			env:           map[string]string{EnvRepeatCallThreshold: "-2"},
+			expectedError: "invalid REPEAT_CALL_THRESHOLD",
+		},
+		{
+			name:          "zero window",
+			env:           map[string]string{EnvRepeatCallWindow: "0s"},
+			expectedError: "invalid REPEAT_CALL_WINDOW",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(EnvLunoAPIKeyID, "")
+			t.Setenv(EnvLunoAPIKeySecret, "")
+			for _, key := range []string{EnvRepeatCallThreshold, EnvRepeatCallWindow} {
+				t.Setenv(key, tc.env[key])
+			}
+
+			cfg, err := Load(tc.opts...)
+			if tc.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+					t.Fatalf("Expected error containing %q, got %v", tc.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := cfg.LoopGuard.Threshold(); got != tc.expectedThreshold {
+				t.Errorf("Expected Threshold to be %d, but got %d", tc.expectedThreshold, got)
+			}
+			if got := cfg.LoopGuard.Window(); got != tc.expectedWindow {
+				t.Errorf("Expected Window to be %v, but got %v", tc.expectedWindow, got)
+			}
+		})
+	}
+}
+
+func TestLoadTickersInterval(t *testing.T) {
+	tests := []struct {
+		name             string
+		env              string
+		opts             []Option
+		expectedInterval time.Duration
+		expectedError    string
+	}{
+		{name: "default", expectedInterval: markets.DefaultTickerInterval},
+		{name: "from environment", env: "10s", expectedInterval: 10 * time.Second},
+		{name: "disabled", env: "0", expectedInterval: 0},
+		{name: "option overrides environment", env: "10s", opts: []Option{WithTickersRefreshInterval(time.Minute)}, expectedInterval: time.Minute},
+		{name: "negative", env: "-5s", expectedError: "invalid TICKERS_REFRESH_INTERVAL"},
+		{name: "not a duration", env: "often", expectedError: "invalid TICKERS_REFRESH_INTERVAL"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(EnvLunoAPIKeyID, "")
+			t.Setenv(EnvLunoAPIKeySecret, "")
+			t.Setenv(EnvTickersInterval, tc.env)
+
+			cfg, err := Load(tc.opts...)
+			if tc.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+					t.Fatalf("Expected error containing %q, got %v", tc.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := cfg.Tickers.Interval(); got != tc.expectedInterval {
+				t.Errorf("Expected Interval to be %v, but got %v", tc.expectedInterval, got)
+			}
+		})
+	}
+}
+
+func TestLoadDepositAlerts(t *testing.T) {
+	tests := []struct {
+		name           string
+		env            string
+		opts           []Option
+		expectedAssets []string
+		expectedError  string
+	}{
+		{name: "default"},
+		{name: "from environment", env: "XBT:0.01,ZAR", expectedAssets: []string{"XBT", "ZAR"}},
+		{name: "option overrides environment", env: "XBT", opts: []Option{WithDepositAlerts("*")}, expectedAssets: []string{"*"}},
+		{name: "option disables", env: "XBT", opts: []Option{WithDepositAlerts("")}},
+		{name: "invalid", env: "XBT:-1", expectedError: "invalid DEPOSIT_ALERTS"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(EnvDepositAlerts, tc.env)
+
+			cfg, err := Load(tc.opts...)
+			if tc.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+					t.Fatalf("Expected error containing %q, got %v", tc.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var assets []string
+			for _, r := range cfg.DepositAlerts {
+				assets = append(assets, r.Asset)
+			}
+			if !slices.Equal(assets, tc.expectedAssets) {
+				t.Errorf("Expected deposit alerts for %v, got %v", tc.expectedAssets, assets)
+			}
+		})
+	}
+}
+
+func TestLoadTradingWindows(t *testing.T) {
+	tests := []struct {
+		name          string
+		windows       string
+		timezone      string
+		opts          []Option
+		expected      string
+		expectedError string
+	}{
+		{name: "default"},
+		{name: "from environment", windows: "weekdays", expected: "weekdays (UTC)"},
+		{name: "time zone", windows: "06:00-18:00", timezone: "Africa/Johannesburg", expected: "06:00-18:00 (Africa/Johannesburg)"},
+		{name: "option overrides environment", windows: "weekdays", opts: []Option{WithTradingWindows("Sat,Sun", "")}, expected: "Sat,Sun (UTC)"},
+		{name: "option disables", windows: "weekdays", opts: []Option{WithTradingWindows("", "")}},
+		{name: "invalid windows", windows: "Mon 9am-5pm", expectedError: "invalid TRADING_WINDOWS"},
+		{name: "invalid time zone", windows: "weekdays", timezone: "Mars/Olympus", expectedError: "invalid TRADING_TIMEZONE"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(EnvTradingWindows, tc.windows)
+			t.Setenv(EnvTradingTimezone, tc.timezone)
+
+			cfg, err := Load(tc.opts...)
+			if tc.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+					t.Fatalf("Expected error containing %q, got %v", tc.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := cfg.TradingHours.String(); got != tc.expected {
+				t.Errorf("Expected trading windows %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestLoadTradingPairs(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      string
+		opts     []Option
+		expected []string
+	}{
+		{name: "default"},
+		{name: "from environment", env: " xbtzar, ETHZAR,,", expected: []string{"XBTZAR", "ETHZAR"}},
+		{name: "option overrides environment", env: "XBTZAR", opts: []Option{WithTradingPairs("solzar")}, expected: []string{"SOLZAR"}},
+		{name: "option allows every pair", env: "XBTZAR", opts: []Option{WithTradingPairs()}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(EnvTradingPairs, tc.env)
+
+			cfg, err := Load(tc.opts...)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !slices.Equal(cfg.TradingPairs, tc.expected) {
+				t.Errorf("Expected trading pairs %v, got %v", tc.expected, cfg.TradingPairs)
+			}
+		})
+	}
+}
+
+func TestLoadAdmin(t *testing.T) {
+	tests := []struct {
+		name          string
+		addr          string
+		token         string
+		opts          []Option
+		expectedAddr  string
+		expectedError string
+	}{
+		{name: "default"},
+		{name: "from environment", addr: "127.0.0.1:8081", token: "admin-token", expectedAddr: "127.0.0.1:8081"},
+		{name: "option overrides environment", addr: "127.0.0.1:8081", opts: []Option{WithAdmin(":9090", "option-token")}, expectedAddr: ":9090"},
+		{name: "token required", addr: "127.0.0.1:8081", expectedError: "ADMIN_TOKEN must be set"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(EnvAdminAddr, tc.addr)
+			t.Setenv(EnvAdminToken, tc.token)
+
+			cfg, err := Load(tc.opts...)
+			if tc.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+					t.Fatalf("Expected error containing %q, got %v", tc.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if cfg.AdminAddr != tc.expectedAddr {
+				t.Errorf("Expected admin address %q, got %q", tc.expectedAddr, cfg.AdminAddr)
+			}
+			if cfg.AdminAddr != "" && cfg.AdminToken == "" {
+				t.Errorf("Expected an admin token")
+			}
+		})
+	}
+}
+
+func TestLoadCredentialsProvider(t *testing.T) {
+	tests := []struct {
+		name           string
+		env            map[string]string
+		opts           []Option
+		expectedHeader string
+		expectedError  string
+	}{
+		{name: "default"},
+		{
+			name:           "vault from environment",
+			env:            map[string]string{EnvCredentialsProvider: "vault://secret/luno/{tenant}", EnvVaultAddr: "https://vault.example.com:8200", EnvVaultToken: "vault-token"},
+			expectedHeader: "X-Tenant-ID",
+		},
+		{
+			name:           "option overrides environment",
+			env:            map[string]string{EnvCredentialsProvider: "unknown://", "AWS_REGION": "eu-west-1", "AWS_ACCESS_KEY_ID": "AKIDEXAMPLE", "AWS_SECRET_ACCESS_KEY": "aws-secret-key"},
+			opts:           []Option{WithCredentialsProviderURL("aws-secretsmanager://luno/{tenant}"), WithTenantHeader("X-Account")},
+			expectedHeader: "X-Account",
+		},
+		{
+			name:           "header from environment",
+			env:            map[string]string{EnvTenantHeader: "X-Org"},
+			opts:           []Option{WithCredentialsProvider(tenantsStub)},
+			expectedHeader: "X-Org",
+		},
+		{name: "unknown scheme", env: map[string]string{EnvCredentialsProvider: "consul://luno/{tenant}"}, expectedError: "unknown credentials provider"},
+		{name: "vault token required", env: map[string]string{EnvCredentialsProvider: "vault://secret/luno/{tenant}", EnvVaultAddr: "https://vault.example.com"}, expectedError: "Vault token is required"},
+		{name: "tenant placeholder required", env: map[string]string{EnvCredentialsProvider: "vault://secret/luno", EnvVaultAddr: "https://vault.example.com", EnvVaultToken: "vault-token"}, expectedError: "{tenant}"},
+		{name: "aws region required", env: map[string]string{EnvCredentialsProvider: "aws-secretsmanager://luno/{tenant}"}, expectedError: "AWS region is required"},
+		{name: "invalid cache TTL", env: map[string]string{EnvCredentialsCacheTTL: "soon"}, opts: []Option{WithCredentialsProvider(tenantsStub)}, expectedError: "invalid CREDENTIALS_CACHE_TTL"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, key := range []string{EnvCredentialsProvider, EnvCredentialsCacheTTL, EnvTenantHeader, EnvVaultAddr, EnvVaultToken,
+				"AWS_REGION", "AWS_DEFAULT_REGION", "AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN"} {
+				t.Setenv(key, tc.env[key])
+			}
+
+			cfg, err := Load(tc.opts...)
+			if tc.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+					t.Fatalf("Expected error containing %q, got %v", tc.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if (cfg.Tenants != nil) != (tc.expectedHeader != "") {
+				t.Errorf("Expected a credentials provider: %v, got %v", tc.expectedHeader != "", cfg.Tenants)
+			}
+			if cfg.TenantHeader != tc.expectedHeader {
+				t.Errorf("Expected tenant header %q, got %q", tc.expectedHeader, cfg.TenantHeader)
+			}
+		})
+	}
+}
+
+func TestLoadAccessTokens(t *testing.T) {
+	tests := []struct {
+		name          string
+		env           string
+		opts          []Option
+		expectedLen   int
+		expectedError string
+	}{
+		{name: "default"},
+		{name: "from environment", env: "viewer-token:viewer,trader-token:trader", expectedLen: 2},
+		{name: "option overrides environment", env: "viewer-token:viewer,trader-token:trader", opts: []Option{WithAccessTokens("admin-token:admin")}, expectedLen: 1},
+		{name: "unknown role", env: "owner-token:owner", expectedError: "invalid ACCESS_TOKENS"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(EnvAccessTokens, tc.env)
+
+			cfg, err := Load(tc.opts...)
+			if tc.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+					t.Fatalf("Expected error containing %q, got %v", tc.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if tc.expectedLen == 0 {
+				if cfg.AccessTokens != nil {
+					t.Errorf("Expected no access tokens")
+				}
+				return
+			}
+			if cfg.AccessTokens == nil || cfg.AccessTokens.Len() != tc.expectedLen {
+				t.Errorf("Expected %d access tokens, got %v", tc.expectedLen, cfg.AccessTokens)
+			}
+		})
+	}
+}
+
+func TestLoadApprovals(t *testing.T) {
+	tests := []struct {
+		name               string
+		thresholds         string
+		timeout            string
+		opts               []Option
+		expectedThresholds string
+		expectedError      string
+	}{
+		{name: "default"},
+		{name: "from environment", thresholds: "zar:50000,XBT:0.5", expectedThresholds: "XBT:0.5,ZAR:50000"},
+		{name: "option overrides environment", thresholds: "ZAR:50000", opts: []Option{WithApprovals("ZAR:1000"), WithApprovalCommand("approve-order --desk")}, expectedThresholds: "ZAR:1000"},
+		{name: "timeout", thresholds: "ZAR:50000", timeout: "1h", expectedThresholds: "ZAR:50000"},
+		{name: "invalid threshold", thresholds: "ZAR:lots", expectedError: "invalid APPROVAL_THRESHOLDS"},
+		{name: "invalid timeout", thresholds: "ZAR:50000", timeout: "0s", expectedError: "invalid APPROVAL_TIMEOUT"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(EnvApprovalThresholds, tc.thresholds)
+			t.Setenv(EnvApprovalTimeout, tc.timeout)
+			t.Setenv(EnvApprovalCommand, "")
+
+			cfg, err := Load(tc.opts...)
+			if tc.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+					t.Fatalf("Expected error containing %q, got %v", tc.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if tc.expectedThresholds == "" {
+				if cfg.Approvals != nil {
+					t.Errorf("Expected no approvals")
+				}
+				return
+			}
+			if cfg.Approvals == nil {
+				t.Fatalf("Expected approvals")
+			}
+			if got := cfg.Approvals.Thresholds().String(); got != tc.expectedThresholds {
+				t.Errorf("Expected thresholds %q, got %q", tc.expectedThresholds, got)
+			}
+		})
+	}
+}
+
+func TestLoadAuditLog(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	keyPath := filepath.Join(dir, "audit-key.pem")
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(priv.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubPath := filepath.Join(dir, "audit-key.pub")
+	if err := os.WriteFile(pubPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tamperedPath := filepath.Join(dir, "tampered.jsonl")
+	if err := os.WriteFile(tamperedPath, []byte(`{"seq":1,"tool":"create_order","hash":"0000"}`+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		path          string
+		keyPath       string
+		opts          []Option
+		expectedPath  string
+		expectSigned  bool
+		expectedError string
+	}{
+		{name: "default"},
+		{name: "from environment", path: filepath.Join(dir, "env.jsonl"), keyPath: keyPath, expectedPath: filepath.Join(dir, "env.jsonl"), expectSigned: true},
+		{name: "option overrides environment", path: filepath.Join(dir, "env.jsonl"), opts: []Option{WithAuditLogPath(filepath.Join(dir, "option.jsonl")), WithAuditSigningKeyPath(keyPath)}, expectedPath: filepath.Join(dir, "option.jsonl"), expectSigned: true},
+		{name: "unsigned", path: filepath.Join(dir, "unsigned.jsonl"), expectedPath: filepath.Join(dir, "unsigned.jsonl")},
+		{name: "public key", keyPath: pubPath, expectedError: "must hold a private key"},
+		{name: "missing key", keyPath: filepath.Join(dir, "missing.pem"), expectedError: "reading audit log key"},
+		{name: "tampered file", path: tamperedPath, expectedError: "fails verification"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(EnvAuditLogPath, tc.path)
+			t.Setenv(EnvAuditSigningKeyPath, tc.keyPath)
+
+			cfg, err := Load(tc.opts...)
+			if tc.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+					t.Fatalf("Expected error containing %q, got %v", tc.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := cfg.Audit.Path(); got != tc.expectedPath {
+				t.Errorf("Expected audit log path %q, got %q", tc.expectedPath, got)
+			}
+			if e := cfg.Audit.Record(audit.Entry{Tool: "get_ticker"}); (e.Signature != "") != tc.expectSigned {
+				t.Errorf("Expected signed %v, got signature %q", tc.expectSigned, e.Signature)
+			}
+		})
+	}
+}
+
+var tenantsStub = tenants.ProviderFunc(func(context.Context, string) (tenants.Credentials, error) {
+	return tenants.Credentials{}, tenants.ErrUnknownTenant
+})
+
+func TestSessionClient(t *testing.T) {
+	if _, err := (&Config{}).SessionClient("key-id", "secret"); err == nil {
+		t.Errorf("Expected an error for a Config not built by Load")
+	}
+
+	t.Setenv(EnvLunoAPIKeyID, "")
+	t.Setenv(EnvLunoAPIKeySecret, "")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	client, err := cfg.SessionClient("session-key-id", "session-secret")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if client == nil || client == cfg.LunoClient {
+		t.Errorf("Expected a new client for the session")
+	}
+}
+
+func TestLoadLocale(t *testing.T) {
+	tests := []struct {
+		name           string
+		env            string
+		opts           []Option
+		expectedLocale i18n.Locale
+		expectedError  string
+	}{
+		{name: "default", expectedLocale: i18n.English},
+		{name: "from environment", env: "id_ID", expectedLocale: i18n.Indonesian},
+		{name: "option overrides environment", env: "id", opts: []Option{WithLocale("ms")}, expectedLocale: i18n.Malay},
+		{name: "unsupported", env: "xx", expectedError: "invalid LOCALE: unsupported locale"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(EnvLocale, tc.env)
+
+			cfg, err := Load(tc.opts...)
+			if tc.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+					t.Fatalf("Expected error containing %q, got %v", tc.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if cfg.Locale != tc.expectedLocale {
+				t.Errorf("Expected locale %q, got %q", tc.expectedLocale, cfg.Locale)
+			}
+		})
+	}
+}
+
+func TestLoadOutputFormat(t *testing.T) {
+	tests := []struct {
+		name           string
+		env            string
+		opts           []Option
+		expectedFormat render.Format
+		expectedError  string
+	}{
+		{name: "default", expectedFormat: render.JSON},
+		{name: "from environment", env: "YAML", expectedFormat: render.YAML},
+		{name: "option overrides environment", env: "yaml", opts: []Option{WithOutputFormat("md")}, expectedFormat: render.Markdown},
+		{name: "unsupported", env: "xml", expectedError: "invalid OUTPUT_FORMAT: must be one of json, yaml, csv, markdown"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(EnvOutputFormat, tc.env)
+
+			cfg, err := Load(tc.opts...)
+			if tc.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+					t.Fatalf("Expected error containing %q, got %v", tc.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if cfg.OutputFormat != tc.expectedFormat {
+				t.Errorf("Expected output format %q, got %q", tc.expectedFormat, cfg.OutputFormat)
+			}
+		})
+	}
+}
+
+func TestLoadWithMiddleware(t *testing.T) {
+	cfg, err := Load(WithCredentials("id", "secret"), WithMiddleware(sdk.DryRun()))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	_, err = cfg.LunoClient.PostLimitOrder(context.Background(), &luno.PostLimitOrderRequest{Pair: "XBTZAR"})
+	if !errors.Is(err, sdk.ErrDryRun) {
+		t.Errorf("Expected ErrDryRun, got %v", err)
+	}
+}
+
+func TestLoadChaos(t *testing.T) {
+	t.Setenv(EnvChaos, "latency=2s")
+	cfg, err := Load(WithChaos("rate_limit=1"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	_, err = cfg.LunoClient.GetTicker(context.Background(), &luno.GetTickerRequest{Pair: "XBTZAR"})
+	if err == nil || err.Error() != "luno: too many requests" {
+		t.Errorf("Expected a rate limit error, got %v", err)
+	}
+
+	t.Setenv(EnvChaos, "errors=2")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "invalid CHAOS") {
+		t.Errorf("Expected error containing %q, got %v", "invalid CHAOS", err)
+	}
+}
+
+func TestNewHTTPClientTransport(t *testing.T) {
+	hc := newHTTPClient(options{}, &sdk.MCPRoundTripper{})
+	rt, ok := hc.Transport.(*sdk.MCPRoundTripper)
+	if !ok {
+		t.Fatalf("Expected *sdk.MCPRoundTripper, got %T", hc.Transport)
+	}
+	tr, ok := rt.Next.(*http.Transport)
+	if !ok {
+		t.Fatalf("Expected *http.Transport, got %T", rt.Next)
+	}
+	if tr == http.DefaultTransport {
+		t.Errorf("Expected a dedicated transport, not http.DefaultTransport")
+	}
+	if tr.MaxIdleConnsPerHost != maxIdleConnsPerHost {
+		t.Errorf("Expected MaxIdleConnsPerHost %d, got %d", maxIdleConnsPerHost, tr.MaxIdleConnsPerHost)
+	}
+
+	custom := &http.Client{Timeout: time.Second}
+	hc = newHTTPClient(options{httpClient: custom}, &sdk.MCPRoundTripper{})
+	if hc.Timeout != time.Second {
+		t.Errorf("Expected custom client timeout to be kept")
+	}
+	if custom.Transport != nil {
+		t.Errorf("Custom client was modified")
+	}
+}
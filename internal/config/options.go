@@ -0,0 +1,483 @@
+package config
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/luno/luno-mcp/internal/reference"
+	"github.com/luno/luno-mcp/internal/tenants"
+	"github.com/luno/luno-mcp/internal/vault"
+	"github.com/luno/luno-mcp/sdk"
+)
+
+// CredentialsSource returns the Luno API key ID and secret. Returning empty
+// values runs the server in unauthenticated mode.
+type CredentialsSource func() (keyID, secret string, err error)
+
+// EnvCredentials reads the API credentials from the LUNO_API_KEY_ID and
+// LUNO_API_SECRET environment variables, or from the files named by
+// LUNO_API_KEY_ID_FILE and LUNO_API_SECRET_FILE
+func EnvCredentials() (string, string, error) {
+	keyID, err := secretEnv(EnvLunoAPIKeyID)
+	if err != nil {
+		return "", "", err
+	}
+	secret, err := secretEnv(EnvLunoAPIKeySecret)
+	if err != nil {
+		return "", "", err
+	}
+	return keyID, secret, nil
+}
+
+// Option configures how Load builds a Config
+type Option func(*options)
+
+type options struct {
+	domain                 string
+	appName                string
+	appVersion             string
+	httpClient             *http.Client
+	credentials            CredentialsSource
+	debug                  *bool
+	allowWriteOperations   *bool
+	allowWithdrawals       *bool
+	hideUnavailableTools   *bool
+	locale                 *string
+	outputFormat           *string
+	webhookURL             string
+	webhookSecret          string
+	maxResponseBytes       *int
+	maxPriceDeviation      *float64
+	duplicateOrderWindow   *time.Duration
+	maxConcurrentCalls     *int
+	maxCallsPerTool        *int
+	callQueueTimeout       *time.Duration
+	repeatCallThreshold    *int
+	repeatCallWindow       *time.Duration
+	tickersInterval        *time.Duration
+	middleware             []sdk.Middleware
+	transport              string
+	referencePriceURL      string
+	telemetryURL           string
+	updateCheck            *bool
+	referenceSource        reference.Source
+	tradeJournalPath       *string
+	accountAliasesPath     *string
+	trackedOrdersPath      *string
+	importedTradesPath     *string
+	candleCacheDir         *string
+	dataDir                string
+	storageEncryption      *string
+	keychain               vault.Keychain
+	depositAlerts          *string
+	tradingWindows         *string
+	tradingTimezone        string
+	tradingPairs           *[]string
+	chaos                  *string
+	adminAddr              string
+	adminToken             string
+	tenantsProvider        tenants.Provider
+	credentialsProviderURL string
+	tenantHeader           string
+	accessTokens           string
+	approvalThresholds     string
+	approvalCommand        string
+	auditLogPath           string
+	auditSigningKeyPath    string
+}
+
+// WithDomain overrides the Luno API domain, taking precedence over LUNO_API_DOMAIN
+func WithDomain(domain string) Option {
+	return func(o *options) {
+		o.domain = domain
+	}
+}
+
+// WithAppInfo sets the application name and version reported to Luno in the User-Agent header
+func WithAppInfo(name, version string) Option {
+	return func(o *options) {
+		o.appName = name
+		o.appVersion = version
+	}
+}
+
+// WithHTTPClient sets the HTTP client used for Luno API calls. The client's
+// transport is wrapped, the client itself is not modified.
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = client
+	}
+}
+
+// WithCredentialsSource sets where the API credentials are read from (default: EnvCredentials)
+func WithCredentialsSource(source CredentialsSource) Option {
+	return func(o *options) {
+		o.credentials = source
+	}
+}
+
+// WithCredentials sets static API credentials
+func WithCredentials(keyID, secret string) Option {
+	return WithCredentialsSource(func() (string, string, error) {
+		return keyID, secret, nil
+	})
+}
+
+// WithDebug enables or disables logging of Luno API requests and responses,
+// taking precedence over LUNO_API_DEBUG
+func WithDebug(debug bool) Option {
+	return func(o *options) {
+		o.debug = &debug
+	}
+}
+
+// WithAllowWriteOperations enables or disables write operations, taking precedence over ALLOW_WRITE_OPERATIONS
+func WithAllowWriteOperations(allow bool) Option {
+	return func(o *options) {
+		o.allowWriteOperations = &allow
+	}
+}
+
+// WithAllowWithdrawals enables or disables fiat withdrawals, taking precedence over ALLOW_WITHDRAWALS.
+// Withdrawals also need write operations to be enabled.
+func WithAllowWithdrawals(allow bool) Option {
+	return func(o *options) {
+		o.allowWithdrawals = &allow
+	}
+}
+
+// WithHideUnavailableTools hides tools that need API credentials from sessions
+// without them instead of marking them unavailable, taking precedence over HIDE_UNAVAILABLE_TOOLS
+func WithHideUnavailableTools(hide bool) Option {
+	return func(o *options) {
+		o.hideUnavailableTools = &hide
+	}
+}
+
+// WithLocale sets the language of tool descriptions and messages, such as "id"
+// or "ms-MY", taking precedence over LOCALE. See i18n.Locales for the
+// supported locales.
+func WithLocale(locale string) Option {
+	return func(o *options) {
+		o.locale = &locale
+	}
+}
+
+// WithOutputFormat sets the format of tool results that calls do not choose
+// one for, such as "yaml" or "markdown", taking precedence over OUTPUT_FORMAT
+func WithOutputFormat(format string) Option {
+	return func(o *options) {
+		o.outputFormat = &format
+	}
+}
+
+// WithWebhook sends events to url, signed with secret when it is set. It takes
+// precedence over WEBHOOK_URL and WEBHOOK_SECRET.
+func WithWebhook(url, secret string) Option {
+	return func(o *options) {
+		o.webhookURL = url
+		o.webhookSecret = secret
+	}
+}
+
+// WithMaxResponseBytes sets the size above which tool results are truncated and
+// continued with fetch_more, taking precedence over MAX_RESPONSE_BYTES. Zero
+// disables truncation.
+func WithMaxResponseBytes(n int) Option {
+	return func(o *options) {
+		o.maxResponseBytes = &n
+	}
+}
+
+// WithMaxConcurrentCalls sets how many tool calls can run at once, taking
+// precedence over MAX_CONCURRENT_CALLS. Zero removes the limit.
+func WithMaxConcurrentCalls(n int) Option {
+	return func(o *options) {
+		o.maxConcurrentCalls = &n
+	}
+}
+
+// WithMaxConcurrentCallsPerTool sets how many calls to one tool can run at once,
+// taking precedence over MAX_CONCURRENT_CALLS_PER_TOOL. Zero removes the limit.
+func WithMaxConcurrentCallsPerTool(n int) Option {
+	return func(o *options) {
+		o.maxCallsPerTool = &n
+	}
+}
+
+// WithCallQueueTimeout sets how long calls over a concurrency limit wait for a
+// slot, taking precedence over CALL_QUEUE_TIMEOUT. Zero waits indefinitely.
+func WithCallQueueTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.callQueueTimeout = &d
+	}
+}
+
+// WithRepeatCallThreshold sets how many identical tool calls a session can make
+// within the repeat call window before the previous result is reused, taking
+// precedence over REPEAT_CALL_THRESHOLD. Zero never reuses results.
+func WithRepeatCallThreshold(n int) Option {
+	return func(o *options) {
+		o.repeatCallThreshold = &n
+	}
+}
+
+// WithRepeatCallWindow sets how long identical tool calls are counted for,
+// taking precedence over REPEAT_CALL_WINDOW
+func WithRepeatCallWindow(d time.Duration) Option {
+	return func(o *options) {
+		o.repeatCallWindow = &d
+	}
+}
+
+// WithTickersRefreshInterval sets how often the luno://tickers/all resource is
+// refreshed in the background, taking precedence over TICKERS_REFRESH_INTERVAL.
+// Zero only loads the tickers when the resource is first read.
+func WithTickersRefreshInterval(d time.Duration) Option {
+	return func(o *options) {
+		o.tickersInterval = &d
+	}
+}
+
+// WithMiddleware wraps the Luno client in the given middleware, see sdk.Wrap
+func WithMiddleware(mws ...sdk.Middleware) Option {
+	return func(o *options) {
+		o.middleware = append(o.middleware, mws...)
+	}
+}
+
+// WithTransport records the MCP transport the server runs on, reported by get_server_info
+func WithTransport(transport string) Option {
+	return func(o *options) {
+		o.transport = transport
+	}
+}
+
+// WithReferencePriceURL reads reference prices for get_price_premium from a JSON
+// feed at url, taking precedence over REFERENCE_PRICE_URL. See
+// reference.HTTPSource for the placeholders url can contain.
+func WithReferencePriceURL(url string) Option {
+	return func(o *options) {
+		o.referencePriceURL = url
+	}
+}
+
+// WithReferenceSource sets the reference price feed for get_price_premium,
+// taking precedence over WithReferencePriceURL and REFERENCE_PRICE_URL
+func WithReferenceSource(src reference.Source) Option {
+	return func(o *options) {
+		o.referenceSource = src
+	}
+}
+
+// WithTradeJournalPath stores the trade journal at path, taking precedence over
+// TRADE_JOURNAL_PATH. An empty path keeps the journal in memory only.
+func WithTradeJournalPath(path string) Option {
+	return func(o *options) {
+		o.tradeJournalPath = &path
+	}
+}
+
+// WithAccountAliasesPath stores account aliases at path, taking precedence over
+// ACCOUNT_ALIASES_PATH. An empty path keeps aliases in memory only.
+func WithAccountAliasesPath(path string) Option {
+	return func(o *options) {
+		o.accountAliasesPath = &path
+	}
+}
+
+// WithTrackedOrdersPath stores the orders placed through the server at path,
+// taking precedence over TRACKED_ORDERS_PATH. An empty path keeps them in
+// memory only, so they are not checked after a restart.
+func WithTrackedOrdersPath(path string) Option {
+	return func(o *options) {
+		o.trackedOrdersPath = &path
+	}
+}
+
+// WithImportedTradesPath stores the trades imported with import_trades at
+// path, taking precedence over IMPORTED_TRADES_PATH. An empty path keeps them
+// in memory only.
+func WithImportedTradesPath(path string) Option {
+	return func(o *options) {
+		o.importedTradesPath = &path
+	}
+}
+
+// WithCandleCacheDir caches the candles fetched for analysis in dir, taking
+// precedence over CANDLE_CACHE_DIR. An empty dir keeps them in memory only, so
+// they are fetched again after a restart.
+func WithCandleCacheDir(dir string) Option {
+	return func(o *options) {
+		o.candleCacheDir = &dir
+	}
+}
+
+// WithDataDir keeps the trade journal, account aliases, tracked orders,
+// imported trades and candle cache in dir rather than the user's config and
+// cache directories, taking precedence over LUNO_MCP_DATA_DIR. Paths set for
+// them explicitly are still used.
+func WithDataDir(dir string) Option {
+	return func(o *options) {
+		o.dataDir = dir
+	}
+}
+
+// WithStorageEncryption sets where the key encrypting the files the server
+// keeps comes from, taking precedence over STORAGE_ENCRYPTION: off,
+// passphrase (read from STORAGE_PASSPHRASE) or keychain
+func WithStorageEncryption(mode string) Option {
+	return func(o *options) {
+		o.storageEncryption = &mode
+	}
+}
+
+// WithTelemetryURL opts in to sending anonymous tool usage counts to url,
+// taking precedence over TELEMETRY_URL. See the telemetry package for what is
+// sent.
+func WithTelemetryURL(url string) Option {
+	return func(o *options) {
+		o.telemetryURL = url
+	}
+}
+
+// WithUpdateCheck sets whether the server checks GitHub for newer releases,
+// taking precedence over DISABLE_UPDATE_CHECK. Checks are on by default.
+func WithUpdateCheck(enabled bool) Option {
+	return func(o *options) {
+		o.updateCheck = &enabled
+	}
+}
+
+// WithDepositAlerts sets the deposits reported to MCP clients and the webhook,
+// taking precedence over DEPOSIT_ALERTS. See events.ParseDepositRules for the
+// format of rules; an empty string watches no deposits.
+func WithDepositAlerts(rules string) Option {
+	return func(o *options) {
+		o.depositAlerts = &rules
+	}
+}
+
+// WithTradingWindows restricts the tools that trade or move funds to windows
+// read in timezone, an IANA name or empty for UTC, taking precedence over
+// TRADING_WINDOWS and TRADING_TIMEZONE. See tradinghours.Parse for the format
+// of windows; an empty string allows trading at any time.
+func WithTradingWindows(windows, timezone string) Option {
+	return func(o *options) {
+		o.tradingWindows = &windows
+		o.tradingTimezone = timezone
+	}
+}
+
+// WithTradingPairs restricts the orders placed by the trading tools to pairs,
+// taking precedence over TRADING_PAIRS. No pairs allows orders on any pair.
+func WithTradingPairs(pairs ...string) Option {
+	return func(o *options) {
+		o.tradingPairs = &pairs
+	}
+}
+
+// WithChaos adds latency and failures to Luno API calls for testing agents,
+// taking precedence over CHAOS. See sdk.ParseChaos for the format of spec; an
+// empty string turns chaos mode off.
+func WithChaos(spec string) Option {
+	return func(o *options) {
+		o.chaos = &spec
+	}
+}
+
+// WithAdmin serves the admin API on addr, for requests carrying token as a
+// bearer token. It takes precedence over ADMIN_ADDR and ADMIN_TOKEN.
+func WithAdmin(addr, token string) Option {
+	return func(o *options) {
+		o.adminAddr = addr
+		o.adminToken = token
+	}
+}
+
+// WithCredentialsProviderURL fetches each session's Luno API credentials from
+// the secrets store at url, taking precedence over CREDENTIALS_PROVIDER: a
+// vault://mount/path or aws-secretsmanager://name URL containing {tenant}.
+func WithCredentialsProviderURL(url string) Option {
+	return func(o *options) {
+		o.credentialsProviderURL = url
+	}
+}
+
+// WithCredentialsProvider fetches each session's Luno API credentials from p,
+// taking precedence over WithCredentialsProviderURL and CREDENTIALS_PROVIDER
+func WithCredentialsProvider(p tenants.Provider) Option {
+	return func(o *options) {
+		o.tenantsProvider = p
+	}
+}
+
+// WithTenantHeader sets the HTTP header naming a session's tenant, taking
+// precedence over TENANT_HEADER (default: X-Tenant-ID)
+func WithTenantHeader(header string) Option {
+	return func(o *options) {
+		o.tenantHeader = header
+	}
+}
+
+// WithAccessTokens restricts the tools MCP clients calling over HTTP may use
+// to the role of their bearer token, given as comma-separated token:role
+// pairs where the role is viewer, trader or admin. It takes precedence over
+// ACCESS_TOKENS.
+func WithAccessTokens(spec string) Option {
+	return func(o *options) {
+		o.accessTokens = spec
+	}
+}
+
+// WithApprovals holds orders and withdrawals worth more than thresholds until
+// they are approved, taking precedence over APPROVAL_THRESHOLDS. Thresholds
+// are comma-separated currencies with amounts, such as ZAR:50000,XBT:0.5.
+func WithApprovals(thresholds string) Option {
+	return func(o *options) {
+		o.approvalThresholds = thresholds
+	}
+}
+
+// WithApprovalCommand runs command, split on spaces, to decide each action
+// held for approval, taking precedence over APPROVAL_COMMAND
+func WithApprovalCommand(command string) Option {
+	return func(o *options) {
+		o.approvalCommand = command
+	}
+}
+
+// WithAuditLogPath keeps the audit log in a file at path as well as in memory,
+// taking precedence over AUDIT_LOG_PATH. An existing file is verified and its
+// hash chain continued.
+func WithAuditLogPath(path string) Option {
+	return func(o *options) {
+		o.auditLogPath = path
+	}
+}
+
+// WithAuditSigningKeyPath signs each audit log entry with the Ed25519 private
+// key in the PEM file at path, taking precedence over AUDIT_SIGNING_KEY_PATH
+func WithAuditSigningKeyPath(path string) Option {
+	return func(o *options) {
+		o.auditSigningKeyPath = path
+	}
+}
+
+// WithMaxPriceDeviation sets how far, as a percentage of the market price, a
+// limit price can be before the order needs allow_far_from_market, taking
+// precedence over MAX_PRICE_DEVIATION. Zero disables the check.
+func WithMaxPriceDeviation(percent float64) Option {
+	return func(o *options) {
+		o.maxPriceDeviation = &percent
+	}
+}
+
+// WithDuplicateOrderWindow sets how long after an order is placed a limit
+// order with the same pair, side, price and volume needs allow_duplicate,
+// taking precedence over DUPLICATE_ORDER_WINDOW. Zero disables the check.
+func WithDuplicateOrderWindow(d time.Duration) Option {
+	return func(o *options) {
+		o.duplicateOrderWindow = &d
+	}
+}
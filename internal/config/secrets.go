@@ -0,0 +1,70 @@
+package config
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/luno/luno-mcp/internal/redact"
+	"github.com/luno/luno-mcp/internal/tenants"
+)
+
+// fileEnvSuffix is appended to the name of an environment variable holding a
+// secret to name one holding the path of a file to read it from instead, such
+// as a Docker or Kubernetes secret mounted into the container
+const fileEnvSuffix = "_FILE"
+
+// secretEnv returns the secret in the environment variable key, or read from
+// the file named by key_FILE with surrounding whitespace removed. It is an
+// error to set both.
+func secretEnv(key string) (string, error) {
+	key = strings.TrimSpace(key)
+	value := os.Getenv(key)
+	path := os.Getenv(key + fileEnvSuffix)
+	if path == "" {
+		return value, nil
+	}
+	if value != "" {
+		return "", fmt.Errorf("%s and %s are both set; set only one", key, key+fileEnvSuffix)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		// The error names the file, never its contents
+		return "", fmt.Errorf("failed to read %s: %w", key+fileEnvSuffix, err)
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// credentialsProvider returns the tenant credentials provider for rawURL, or
+// nil when it is empty. Vault is reached at VAULT_ADDR with VAULT_TOKEN, and
+// AWS with the standard AWS_* environment variables.
+func credentialsProvider(rawURL string) (tenants.Provider, error) {
+	if rawURL == "" {
+		return nil, nil
+	}
+	scheme, rest, ok := strings.Cut(rawURL, "://")
+	if !ok {
+		return nil, fmt.Errorf("%q is not a vault:// or aws-secretsmanager:// URL", rawURL)
+	}
+	switch scheme {
+	case "vault":
+		token, err := secretEnv(EnvVaultToken)
+		if err != nil {
+			return nil, err
+		}
+		redact.Register(token)
+		mount, path, _ := strings.Cut(rest, "/")
+		return tenants.NewVaultProvider(os.Getenv(EnvVaultAddr), token, mount, path)
+	case "aws-secretsmanager":
+		aws := tenants.AWSCredentials{
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		}
+		redact.Register(aws.SecretAccessKey, aws.SessionToken)
+		return tenants.NewSecretsManagerProvider(cmp.Or(os.Getenv("AWS_REGION"), os.Getenv("AWS_DEFAULT_REGION")), rest, aws)
+	default:
+		return nil, fmt.Errorf("unknown credentials provider %q: want vault or aws-secretsmanager", scheme)
+	}
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSecretEnv(t *testing.T) {
+	dir := t.TempDir()
+	secretFile := filepath.Join(dir, "secret")
+	if err := os.WriteFile(secretFile, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		value         string
+		file          string
+		expected      string
+		errorContains string
+	}{
+		{name: "unset"},
+		{name: "value", value: "from-env", expected: "from-env"},
+		{name: "file", file: secretFile, expected: "from-file"},
+		{name: "both", value: "from-env", file: secretFile, errorContains: "WEBHOOK_SECRET and WEBHOOK_SECRET_FILE are both set"},
+		{name: "missing file", file: filepath.Join(dir, "missing"), errorContains: "failed to read WEBHOOK_SECRET_FILE"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(EnvWebhookSecret, tc.value)
+			t.Setenv(EnvWebhookSecret+"_FILE", tc.file)
+
+			got, err := secretEnv(EnvWebhookSecret)
+			if tc.errorContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.errorContains) {
+					t.Fatalf("secretEnv() = %v, want error containing %q", err, tc.errorContains)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
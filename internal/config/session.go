@@ -0,0 +1,146 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/luno/luno-mcp/internal/redact"
+	"github.com/luno/luno-mcp/sdk"
+)
+
+// Overlay overrides parts of the shared Config for a single MCP session, e.g.
+// to call Luno with the session's own API credentials.
+type Overlay struct {
+	// LunoClient replaces the shared client when set
+	LunoClient sdk.LunoClient
+	// IsAuthenticated reports whether LunoClient has API credentials
+	IsAuthenticated bool
+	// Tenant is the tenant whose credentials LunoClient uses. It keys what
+	// the server keeps for the session, such as its trade notes, tracked
+	// orders and TWAP executions, so that tenants only see their own.
+	Tenant string
+}
+
+// SessionOverlays holds the overlays for active MCP sessions, keyed by session ID.
+// It is safe for concurrent use. Overlays are not removed automatically; delete them
+// when the session ends, e.g. from an OnUnregisterSession hook.
+type SessionOverlays struct {
+	mu       sync.RWMutex
+	overlays map[string]Overlay
+	revoked  map[string]bool
+	watchers []func(sessionID string)
+}
+
+// NewSessionOverlays creates an empty set of session overlays
+func NewSessionOverlays() *SessionOverlays {
+	return &SessionOverlays{overlays: make(map[string]Overlay), revoked: make(map[string]bool)}
+}
+
+// Set stores the overlay for a session, replacing any existing one
+func (s *SessionOverlays) Set(sessionID string, o Overlay) {
+	s.mu.Lock()
+	s.overlays[sessionID] = o
+	watchers := s.watchers
+	s.mu.Unlock()
+	for _, f := range watchers {
+		f(sessionID)
+	}
+}
+
+// Get returns the overlay for a session, if any
+func (s *SessionOverlays) Get(sessionID string) (Overlay, bool) {
+	if s == nil {
+		return Overlay{}, false
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	o, ok := s.overlays[sessionID]
+	return o, ok
+}
+
+// Delete removes the overlay for a session
+func (s *SessionOverlays) Delete(sessionID string) {
+	s.mu.Lock()
+	_, ok := s.overlays[sessionID]
+	delete(s.overlays, sessionID)
+	watchers := s.watchers
+	s.mu.Unlock()
+	if !ok {
+		return
+	}
+	for _, f := range watchers {
+		f(sessionID)
+	}
+}
+
+// Revoke deletes a session's overlay and marks the session revoked, so that
+// the server refuses its calls from then on
+func (s *SessionOverlays) Revoke(sessionID string) {
+	s.mu.Lock()
+	s.revoked[sessionID] = true
+	s.mu.Unlock()
+	s.Delete(sessionID)
+}
+
+// Revoked reports whether a session has been revoked
+func (s *SessionOverlays) Revoked(sessionID string) bool {
+	if s == nil {
+		return false
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.revoked[sessionID]
+}
+
+// Watch calls f with the session ID after each Set, and each Delete that
+// removes an overlay. The tools a session can use depend on its overlay, so
+// the server uses this to tell the session's client to list them again.
+func (s *SessionOverlays) Watch(f func(sessionID string)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.watchers = append(s.watchers, f)
+}
+
+type overlayKey struct{}
+
+// ContextWithOverlay returns a copy of ctx carrying o
+func ContextWithOverlay(ctx context.Context, o Overlay) context.Context {
+	return context.WithValue(ctx, overlayKey{}, o)
+}
+
+// SessionClient returns a Luno client for a session's own API credentials, or
+// an unauthenticated one when they are empty, for use in an Overlay. It shares
+// the HTTP client, middleware and domain of LunoClient.
+func (c *Config) SessionClient(keyID, secret string) (sdk.LunoClient, error) {
+	if c.newClient == nil {
+		return nil, errors.New("session clients can only be created from a Config built by Load")
+	}
+	client := c.newClient()
+	if keyID == "" && secret == "" {
+		return client, nil
+	}
+	redact.Register(keyID, secret)
+	if err := client.SetAuth(keyID, secret); err != nil {
+		return nil, fmt.Errorf("failed to set Luno API credentials: %w", err)
+	}
+	return client, nil
+}
+
+// ForContext returns the Config to use for a request: c itself, or a copy of c
+// with the overlay stored in ctx applied. c is never modified.
+func (c *Config) ForContext(ctx context.Context) *Config {
+	if c == nil {
+		return nil
+	}
+	o, ok := ctx.Value(overlayKey{}).(Overlay)
+	if !ok || o.LunoClient == nil {
+		return c
+	}
+	cp := *c
+	cp.LunoClient = o.LunoClient
+	cp.IsAuthenticated = o.IsAuthenticated
+	cp.Tenant = o.Tenant
+	return &cp
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/luno/luno-go"
+)
+
+func TestConfigForContext(t *testing.T) {
+	shared := luno.NewClient()
+	sessionClient := luno.NewClient()
+	base := &Config{LunoClient: shared, Domain: DefaultLunoDomain}
+
+	tests := []struct {
+		name         string
+		ctx          context.Context
+		expectClient *luno.Client
+		expectAuth   bool
+		expectTenant string
+		expectSame   bool
+	}{
+		{
+			name:         "no overlay returns the shared config",
+			ctx:          context.Background(),
+			expectClient: shared,
+			expectSame:   true,
+		},
+		{
+			name:         "overlay without client is ignored",
+			ctx:          ContextWithOverlay(context.Background(), Overlay{IsAuthenticated: true}),
+			expectClient: shared,
+			expectSame:   true,
+		},
+		{
+			name:         "overlay client replaces shared client",
+			ctx:          ContextWithOverlay(context.Background(), Overlay{LunoClient: sessionClient, IsAuthenticated: true, Tenant: "acme"}),
+			expectClient: sessionClient,
+			expectAuth:   true,
+			expectTenant: "acme",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := base.ForContext(tc.ctx)
+			if got.LunoClient != tc.expectClient {
+				t.Errorf("Unexpected Luno client")
+			}
+			if got.IsAuthenticated != tc.expectAuth {
+				t.Errorf("Expected IsAuthenticated %v, got %v", tc.expectAuth, got.IsAuthenticated)
+			}
+			if got.Tenant != tc.expectTenant {
+				t.Errorf("Expected tenant %q, got %q", tc.expectTenant, got.Tenant)
+			}
+			if (got == base) != tc.expectSame {
+				t.Errorf("Expected same config %v, got %v", tc.expectSame, got == base)
+			}
+			if got.Domain != base.Domain {
+				t.Errorf("Expected domain to be copied from the shared config")
+			}
+			if base.LunoClient != shared || base.IsAuthenticated {
+				t.Errorf("Shared config was modified")
+			}
+		})
+	}
+
+	var nilCfg *Config
+	if nilCfg.ForContext(context.Background()) != nil {
+		t.Errorf("Expected nil config to stay nil")
+	}
+}
+
+func TestSessionOverlays(t *testing.T) {
+	s := NewSessionOverlays()
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			id := fmt.Sprintf("session-%d", i)
+			s.Set(id, Overlay{IsAuthenticated: true})
+			if _, ok := s.Get(id); !ok {
+				t.Errorf("Expected overlay for %s", id)
+			}
+			s.Delete(id)
+		}()
+	}
+	wg.Wait()
+
+	if _, ok := s.Get("session-1"); ok {
+		t.Errorf("Expected overlay to be deleted")
+	}
+
+	var nilOverlays *SessionOverlays
+	if _, ok := nilOverlays.Get("session-1"); ok {
+		t.Errorf("Expected no overlay from nil SessionOverlays")
+	}
+}
+
+func TestSessionOverlaysWatch(t *testing.T) {
+	s := NewSessionOverlays()
+	var changed []string
+	s.Watch(func(sessionID string) {
+		// Watchers may read the overlays
+		s.Get(sessionID)
+		changed = append(changed, sessionID)
+	})
+
+	s.Set("session-1", Overlay{IsAuthenticated: true})
+	s.Set("session-1", Overlay{})
+	s.Delete("session-1")
+	s.Delete("session-2")
+
+	expected := []string{"session-1", "session-1", "session-1"}
+	if !slices.Equal(changed, expected) {
+		t.Errorf("Expected changes %v, got %v", expected, changed)
+	}
+}
+
+func TestSessionOverlaysRevoke(t *testing.T) {
+	s := NewSessionOverlays()
+	s.Set("session-1", Overlay{IsAuthenticated: true})
+
+	s.Revoke("session-1")
+	if _, ok := s.Get("session-1"); ok {
+		t.Errorf("Expected the revoked session's overlay to be deleted")
+	}
+	if !s.Revoked("session-1") || s.Revoked("session-2") {
+		t.Errorf("Expected only session-1 to be revoked")
+	}
+
+	var nilOverlays *SessionOverlays
+	if nilOverlays.Revoked("session-1") {
+		t.Errorf("Expected no revoked sessions in nil SessionOverlays")
+	}
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// hostnameLabel matches a single DNS label
+var hostnameLabel = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?$`)
+
+// ResolveDomain returns the Luno API domain to use: the override if provided,
+// then the LUNO_API_DOMAIN environment variable, then the default.
+func ResolveDomain(domainOverride string) string {
+	if domainOverride != "" {
+		return domainOverride
+	}
+	if envDomain := os.Getenv(strings.TrimSpace(EnvLunoAPIDomain)); envDomain != "" {
+		return envDomain
+	}
+	return DefaultLunoDomain
+}
+
+// ValidateDomain checks that domain is a bare host name (optionally with a port),
+// e.g. "api.luno.com" rather than "https://api.luno.com/".
+func ValidateDomain(domain string) error {
+	if domain == "" {
+		return fmt.Errorf("domain is empty")
+	}
+	if strings.Contains(domain, "://") {
+		return fmt.Errorf("domain %q must not include a scheme, use e.g. %q", domain, DefaultLunoDomain)
+	}
+	if strings.ContainsAny(domain, "/?# ") {
+		return fmt.Errorf("domain %q must be a host name without a path, query or spaces", domain)
+	}
+
+	host := domain
+	if h, port, err := net.SplitHostPort(domain); err == nil {
+		p, err := strconv.Atoi(port)
+		if err != nil || p < 1 || p > 65535 {
+			return fmt.Errorf("domain %q has an invalid port", domain)
+		}
+		host = h
+	}
+
+	if net.ParseIP(host) != nil {
+		return nil
+	}
+	for _, label := range strings.Split(host, ".") {
+		if !hostnameLabel.MatchString(label) {
+			return fmt.Errorf("domain %q is not a valid host name", domain)
+		}
+	}
+	return nil
+}
+
+// CheckCredentials returns an error if only one of the API key ID and secret is set.
+// Setting neither is valid and runs the server in unauthenticated mode.
+func CheckCredentials() error {
+	keyID, secret, err := EnvCredentials()
+	if err != nil {
+		return err
+	}
+	hasID, hasSecret := keyID != "", secret != ""
+
+	switch {
+	case hasID && !hasSecret:
+		return fmt.Errorf("%s is set but %s is not; set both to authenticate or neither for public data only", EnvLunoAPIKeyID, EnvLunoAPIKeySecret)
+	case hasSecret && !hasID:
+		return fmt.Errorf("%s is set but %s is not; set both to authenticate or neither for public data only", EnvLunoAPIKeySecret, EnvLunoAPIKeyID)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateDomain(t *testing.T) {
+	tests := []struct {
+		name          string
+		domain        string
+		errorContains string
+	}{
+		{name: "default domain", domain: DefaultLunoDomain},
+		{name: "staging domain", domain: "api.staging.luno.com"},
+		{name: "domain with port", domain: "localhost:8443"},
+		{name: "ip address", domain: "127.0.0.1"},
+		{name: "empty", domain: "", errorContains: "empty"},
+		{name: "with scheme", domain: "https://api.luno.com", errorContains: "scheme"},
+		{name: "with path", domain: "api.luno.com/api/1", errorContains: "without a path"},
+		{name: "with space", domain: "api luno.com", errorContains: "without a path"},
+		{name: "invalid port", domain: "api.luno.com:99999", errorContains: "invalid port"},
+		{name: "invalid label", domain: "api..luno.com", errorContains: "not a valid host name"},
+		{name: "underscore", domain: "api_luno.com", errorContains: "not a valid host name"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateDomain(tc.domain)
+			if tc.errorContains == "" {
+				if err != nil {
+					t.Errorf("ValidateDomain(%q) returned unexpected error: %v", tc.domain, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.errorContains) {
+				t.Errorf("ValidateDomain(%q) = %v, want error containing %q", tc.domain, err, tc.errorContains)
+			}
+		})
+	}
+}
+
+func TestResolveDomain(t *testing.T) {
+	tests := []struct {
+		name     string
+		override string
+		env      string
+		expected string
+	}{
+		{"default", "", "", DefaultLunoDomain},
+		{"environment", "", "env.luno.com", "env.luno.com"},
+		{"override wins", "override.luno.com", "env.luno.com", "override.luno.com"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(EnvLunoAPIDomain, tc.env)
+			if got := ResolveDomain(tc.override); got != tc.expected {
+				t.Errorf("ResolveDomain(%q) = %q, want %q", tc.override, got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestCheckCredentials(t *testing.T) {
+	tests := []struct {
+		name          string
+		keyID         string
+		secret        string
+		secretFile    string
+		errorContains string
+	}{
+		{name: "both set", keyID: "id", secret: "secret"},
+		{name: "neither set"},
+		{name: "id without secret", keyID: "id", errorContains: EnvLunoAPIKeySecret + " is not"},
+		{name: "secret without id", secret: "secret", errorContains: EnvLunoAPIKeyID + " is not"},
+		{name: "id with secret file", keyID: "id", secretFile: "secret"},
+		{name: "secret file without id", secretFile: "secret", errorContains: EnvLunoAPIKeyID + " is not"},
+		{name: "unreadable secret file", keyID: "id", secretFile: "missing", errorContains: "failed to read " + EnvLunoAPIKeySecret + "_FILE"},
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "secret"), []byte("secret"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(EnvLunoAPIKeyID, tc.keyID)
+			t.Setenv(EnvLunoAPIKeySecret, tc.secret)
+			secretFile := ""
+			if tc.secretFile != "" {
+				secretFile = filepath.Join(dir, tc.secretFile)
+			}
+			t.Setenv(EnvLunoAPIKeySecret+"_FILE", secretFile)
+
+			err := CheckCredentials()
+			if tc.errorContains == "" {
+				if err != nil {
+					t.Errorf("CheckCredentials() returned unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.errorContains) {
+				t.Errorf("CheckCredentials() = %v, want error containing %q", err, tc.errorContains)
+			}
+		})
+	}
+}
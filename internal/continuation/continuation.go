@@ -0,0 +1,108 @@
+// Package continuation splits oversized tool results into chunks that clients
+// fetch one at a time with an opaque cursor.
+package continuation
+
+import (
+	"crypto/rand"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// DefaultTTL is how long the rest of a truncated result can be fetched
+	DefaultTTL = 10 * time.Minute
+	// maxEntries bounds the number of pending results kept in memory
+	maxEntries = 256
+)
+
+// Store holds the remaining text of truncated results, keyed by cursor. Each
+// cursor can be used once. It is safe for concurrent use.
+type Store struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]entry
+}
+
+type entry struct {
+	text    string
+	expires time.Time
+}
+
+// NewStore creates an empty Store whose cursors expire after ttl
+func NewStore(ttl time.Duration) *Store {
+	return &Store{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]entry),
+	}
+}
+
+// Put stores text and returns the cursor for it. When the store is full the
+// entry closest to expiry is dropped.
+func (s *Store) Put(text string) string {
+	cursor := rand.Text()
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.evict(now)
+	s.entries[cursor] = entry{text: text, expires: now.Add(s.ttl)}
+	return cursor
+}
+
+// Take returns the text stored for cursor and forgets it. It returns false for
+// unknown, used or expired cursors.
+func (s *Store) Take(cursor string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[cursor]
+	delete(s.entries, cursor)
+	if !ok || !s.now().Before(e.expires) {
+		return "", false
+	}
+	return e.text, true
+}
+
+// evict drops expired entries and makes room for one more. The caller must hold mu.
+func (s *Store) evict(now time.Time) {
+	var oldest string
+	for cursor, e := range s.entries {
+		if !now.Before(e.expires) {
+			delete(s.entries, cursor)
+			continue
+		}
+		if oldest == "" || e.expires.Before(s.entries[oldest].expires) {
+			oldest = cursor
+		}
+	}
+	if len(s.entries) >= maxEntries {
+		delete(s.entries, oldest)
+	}
+}
+
+// Split cuts text into a head of at most budget bytes and the rest. It cuts at
+// the last line break in the second half of the budget if there is one, and
+// never inside a UTF-8 sequence.
+func Split(text string, budget int) (head, rest string) {
+	if budget <= 0 || len(text) <= budget {
+		return text, ""
+	}
+
+	cut := budget
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	if i := strings.LastIndexByte(text[:cut], '\n'); i >= cut/2 {
+		cut = i + 1
+	}
+	if cut == 0 {
+		// A single rune is larger than the budget
+		_, size := utf8.DecodeRuneInString(text)
+		cut = size
+	}
+	return text[:cut], text[cut:]
+}
@@ -0,0 +1,107 @@
+package continuation
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name         string
+		text         string
+		budget       int
+		expectedHead string
+		expectedRest string
+	}{
+		{
+			name:         "within budget",
+			text:         "short",
+			budget:       10,
+			expectedHead: "short",
+		},
+		{
+			name:         "no budget",
+			text:         "anything",
+			expectedHead: "anything",
+		},
+		{
+			name:         "cut at line break",
+			text:         "line one\nline two\nline three",
+			budget:       20,
+			expectedHead: "line one\nline two\n",
+			expectedRest: "line three",
+		},
+		{
+			name:         "line break too early is ignored",
+			text:         "a\nbcdefghijklmnop",
+			budget:       10,
+			expectedHead: "a\nbcdefghi",
+			expectedRest: "jklmnop",
+		},
+		{
+			name:         "never splits a rune",
+			text:         "aé€",
+			budget:       4,
+			expectedHead: "aé",
+			expectedRest: "€",
+		},
+		{
+			name:         "rune larger than the budget",
+			text:         "€uro",
+			budget:       1,
+			expectedHead: "€",
+			expectedRest: "uro",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			head, rest := Split(tt.text, tt.budget)
+			assert.Equal(t, tt.expectedHead, head)
+			assert.Equal(t, tt.expectedRest, rest)
+		})
+	}
+}
+
+func TestStore(t *testing.T) {
+	now := time.Date(2026, 10, 15, 8, 0, 0, 0, time.UTC)
+	s := NewStore(time.Minute)
+	s.now = func() time.Time { return now }
+
+	cursor := s.Put("rest of the result")
+	assert.NotEmpty(t, cursor)
+
+	text, ok := s.Take(cursor)
+	assert.True(t, ok)
+	assert.Equal(t, "rest of the result", text)
+
+	_, ok = s.Take(cursor)
+	assert.False(t, ok, "cursors can only be used once")
+
+	_, ok = s.Take("unknown")
+	assert.False(t, ok)
+
+	expired := s.Put("too late")
+	now = now.Add(time.Minute)
+	_, ok = s.Take(expired)
+	assert.False(t, ok, "cursors expire")
+}
+
+func TestStoreEvictsWhenFull(t *testing.T) {
+	now := time.Date(2026, 10, 15, 8, 0, 0, 0, time.UTC)
+	s := NewStore(time.Hour)
+	s.now = func() time.Time { return now }
+
+	first := s.Put("first")
+	for i := range maxEntries {
+		now = now.Add(time.Second)
+		s.Put(strings.Repeat("x", i))
+	}
+
+	assert.Len(t, s.entries, maxEntries)
+	_, ok := s.Take(first)
+	assert.False(t, ok, "the oldest entry is dropped")
+}
@@ -0,0 +1,219 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/sdk"
+)
+
+const (
+	// AnyAsset is the DepositRule asset that matches deposits of every asset
+	AnyAsset = "*"
+	// watchedTransfers is how many recent transfers are checked per account per poll
+	watchedTransfers = 20
+	// depositNotificationLogger names the source of deposit notifications
+	depositNotificationLogger = "luno-mcp/deposits"
+)
+
+// DepositDetectedData is the payload of a DepositDetected event
+type DepositDetectedData struct {
+	AccountID     string          `json:"account_id"`
+	Asset         string          `json:"asset"`
+	TransferID    string          `json:"transfer_id"`
+	Amount        decimal.Decimal `json:"amount"`
+	Fee           decimal.Decimal `json:"fee"`
+	TransactionID string          `json:"transaction_id,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// DepositRule selects the deposits a DepositWatcher reports
+type DepositRule struct {
+	// Asset is the currency code, such as XBT, or AnyAsset
+	Asset string
+	// MinAmount is the smallest deposit reported. Zero reports every deposit.
+	MinAmount decimal.Decimal
+}
+
+// ParseDepositRules parses a comma-separated list of rules, each an asset with
+// an optional minimum amount, such as "XBT:0.01,ZAR:500,ETH". The asset "*"
+// matches every asset without a rule of its own.
+func ParseDepositRules(s string) ([]DepositRule, error) {
+	var rules []DepositRule
+	seen := make(map[string]bool)
+	for item := range strings.SplitSeq(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		asset, minAmount, hasMin := strings.Cut(item, ":")
+		asset = strings.ToUpper(strings.TrimSpace(asset))
+		if asset == "" {
+			return nil, fmt.Errorf("deposit alert %q has no asset", item)
+		}
+		if seen[asset] {
+			return nil, fmt.Errorf("deposit alert for %s is given more than once", asset)
+		}
+		seen[asset] = true
+
+		rule := DepositRule{Asset: asset, MinAmount: decimal.Zero()}
+		if hasMin {
+			amount, err := decimal.NewFromString(strings.TrimSpace(minAmount))
+			if err != nil || amount.Sign() < 0 {
+				return nil, fmt.Errorf("deposit alert %q must have a minimum amount of 0 or more", item)
+			}
+			rule.MinAmount = amount
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// Notifier sends notifications to connected MCP clients
+type Notifier interface {
+	SendNotificationToAllClients(method string, params map[string]any)
+}
+
+// DepositWatcher polls the transfers of an account's balances and reports
+// deposits that match its rules, publishing DepositDetected events and sending
+// them to connected MCP clients. Luno only lists transfers once they are
+// confirmed. Deposits made before the first poll are not reported.
+type DepositWatcher struct {
+	client   sdk.LunoClient
+	bus      *Bus
+	notifier Notifier
+	rules    map[string]DepositRule
+	interval time.Duration
+
+	started time.Time
+	primed  bool
+	// transfers holds the transfers seen on each account
+	transfers map[int64]map[string]bool
+}
+
+// NewDepositWatcher creates a DepositWatcher that polls client every
+// DefaultWatchInterval. Either of bus and notifier can be nil.
+func NewDepositWatcher(client sdk.LunoClient, bus *Bus, notifier Notifier, rules []DepositRule) *DepositWatcher {
+	byAsset := make(map[string]DepositRule, len(rules))
+	for _, r := range rules {
+		byAsset[r.Asset] = r
+	}
+	return &DepositWatcher{
+		client:    client,
+		bus:       bus,
+		notifier:  notifier,
+		rules:     byAsset,
+		interval:  DefaultWatchInterval,
+		started:   time.Now(),
+		transfers: make(map[int64]map[string]bool),
+	}
+}
+
+// Run polls until ctx is cancelled
+func (w *DepositWatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		if err := w.Poll(ctx); err != nil {
+			slog.WarnContext(ctx, "Failed to poll account for deposits", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll checks the transfers of every watched account once and reports any new
+// deposits
+func (w *DepositWatcher) Poll(ctx context.Context) error {
+	res, err := w.client.GetBalances(ctx, &luno.GetBalancesRequest{})
+	if err != nil {
+		return fmt.Errorf("listing balances: %w", err)
+	}
+
+	var errs []error
+	for _, b := range res.Balance {
+		rule, ok := w.rule(b.Asset)
+		if !ok {
+			continue
+		}
+		if err := w.pollAccount(ctx, b, rule); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	w.primed = true
+	return errors.Join(errs...)
+}
+
+// rule returns the rule for asset, falling back to the AnyAsset rule
+func (w *DepositWatcher) rule(asset string) (DepositRule, bool) {
+	if r, ok := w.rules[asset]; ok {
+		return r, true
+	}
+	r, ok := w.rules[AnyAsset]
+	return r, ok
+}
+
+func (w *DepositWatcher) pollAccount(ctx context.Context, b luno.AccountBalance, rule DepositRule) error {
+	accountID, err := strconv.ParseInt(b.AccountId, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid account ID %q", b.AccountId)
+	}
+	res, err := w.client.ListTransfers(ctx, &luno.ListTransfersRequest{AccountId: accountID, Limit: watchedTransfers})
+	if err != nil {
+		return fmt.Errorf("listing transfers of account %d: %w", accountID, err)
+	}
+
+	seen, known := w.transfers[accountID]
+	current := make(map[string]bool, len(res.Transfers))
+	for _, t := range res.Transfers {
+		current[t.Id] = true
+		createdAt := time.Time(t.CreatedAt)
+		switch {
+		case !w.primed || seen[t.Id] || !t.Inbound:
+			continue
+		case !known && createdAt.Before(w.started):
+			// The account was opened after the first poll, so only report
+			// its transfers made since the watcher started
+			continue
+		case t.Amount.Cmp(rule.MinAmount) < 0:
+			continue
+		}
+		w.report(ctx, DepositDetectedData{
+			AccountID:     b.AccountId,
+			Asset:         b.Asset,
+			TransferID:    t.Id,
+			Amount:        t.Amount,
+			Fee:           t.Fee,
+			TransactionID: t.TransactionId,
+			CreatedAt:     createdAt,
+		})
+	}
+
+	w.transfers[accountID] = current
+	return nil
+}
+
+func (w *DepositWatcher) report(ctx context.Context, d DepositDetectedData) {
+	w.bus.Publish(ctx, New(DepositDetected, d))
+	if w.notifier == nil {
+		return
+	}
+	w.notifier.SendNotificationToAllClients("notifications/message", map[string]any{
+		"level":  "info",
+		"logger": depositNotificationLogger,
+		"data": map[string]any{
+			"message": fmt.Sprintf("Deposit of %s %s received", d.Amount, d.Asset),
+			"deposit": d,
+		},
+	})
+}
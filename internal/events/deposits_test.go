@@ -0,0 +1,166 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type notifierFunc func(method string, params map[string]any)
+
+func (f notifierFunc) SendNotificationToAllClients(method string, params map[string]any) {
+	f(method, params)
+}
+
+func TestParseDepositRules(t *testing.T) {
+	tests := []struct {
+		name          string
+		spec          string
+		expected      []DepositRule
+		errorContains string
+	}{
+		{name: "empty", spec: " , "},
+		{
+			name: "assets with and without minimums",
+			spec: "xbt:0.01, ZAR:500,ETH,*",
+			expected: []DepositRule{
+				{Asset: "XBT", MinAmount: dec(t, "0.01")},
+				{Asset: "ZAR", MinAmount: dec(t, "500")},
+				{Asset: "ETH", MinAmount: decimal.Zero()},
+				{Asset: AnyAsset, MinAmount: decimal.Zero()},
+			},
+		},
+		{name: "missing asset", spec: ":5", errorContains: `deposit alert ":5" has no asset`},
+		{name: "negative minimum", spec: "XBT:-1", errorContains: "minimum amount of 0 or more"},
+		{name: "invalid minimum", spec: "XBT:lots", errorContains: "minimum amount of 0 or more"},
+		{name: "duplicate asset", spec: "XBT,xbt:1", errorContains: "deposit alert for XBT is given more than once"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := ParseDepositRules(tt.spec)
+			if tt.errorContains != "" {
+				assert.ErrorContains(t, err, tt.errorContains)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, rules, len(tt.expected))
+			for i, exp := range tt.expected {
+				assert.Equal(t, exp.Asset, rules[i].Asset)
+				assert.Zero(t, exp.MinAmount.Cmp(rules[i].MinAmount), "minimum of %s", exp.Asset)
+			}
+		})
+	}
+}
+
+func TestDepositWatcher(t *testing.T) {
+	ctx := context.Background()
+	client := sdk.NewMockLunoClient(t)
+	bus, published := recorder()
+	var notified []map[string]any
+	notifier := notifierFunc(func(method string, params map[string]any) {
+		assert.Equal(t, "notifications/message", method)
+		notified = append(notified, params)
+	})
+	rules, err := ParseDepositRules("XBT:0.01,*")
+	require.NoError(t, err)
+	w := NewDepositWatcher(client, bus, notifier, rules)
+	w.started = time.Date(2026, 10, 15, 8, 0, 0, 0, time.UTC)
+
+	balances := func(accounts ...luno.AccountBalance) {
+		client.EXPECT().GetBalances(ctx, &luno.GetBalancesRequest{}).
+			Return(&luno.GetBalancesResponse{Balance: accounts}, nil).Once()
+	}
+	transfers := func(accountID int64, ts ...luno.Transfer) {
+		client.EXPECT().ListTransfers(ctx, &luno.ListTransfersRequest{AccountId: accountID, Limit: watchedTransfers}).
+			Return(&luno.ListTransfersResponse{Transfers: ts}, nil).Once()
+	}
+	xbt := luno.AccountBalance{AccountId: "1", Asset: "XBT"}
+	zar := luno.AccountBalance{AccountId: "2", Asset: "ZAR"}
+	before := luno.Time(w.started.Add(-time.Hour))
+	after := luno.Time(w.started.Add(time.Minute))
+
+	balances(xbt, zar)
+	transfers(1, luno.Transfer{Id: "old", Inbound: true, Amount: dec(t, "1")})
+	transfers(2)
+	require.NoError(t, w.Poll(ctx))
+	assert.Empty(t, *published, "deposits made before the first poll are not reported")
+
+	eth := luno.AccountBalance{AccountId: "3", Asset: "ETH"}
+	balances(xbt, zar, eth)
+	transfers(1,
+		luno.Transfer{Id: "big", Inbound: true, Amount: dec(t, "0.5"), Fee: dec(t, "0.0001"), TransactionId: "tx1", CreatedAt: after},
+		luno.Transfer{Id: "dust", Inbound: true, Amount: dec(t, "0.001")},
+		luno.Transfer{Id: "out", Inbound: false, Amount: dec(t, "1")},
+		luno.Transfer{Id: "old", Inbound: true, Amount: dec(t, "1")},
+	)
+	client.EXPECT().ListTransfers(ctx, &luno.ListTransfersRequest{AccountId: 2, Limit: watchedTransfers}).
+		Return(nil, errors.New("timeout")).Once()
+	transfers(3,
+		luno.Transfer{Id: "eth-new", Inbound: true, Amount: dec(t, "2"), CreatedAt: after},
+		luno.Transfer{Id: "eth-old", Inbound: true, Amount: dec(t, "3"), CreatedAt: before},
+	)
+	err = w.Poll(ctx)
+	assert.ErrorContains(t, err, "listing transfers of account 2")
+
+	require.Len(t, *published, 2)
+	assert.Equal(t, DepositDetected, (*published)[0].Type)
+	assert.Equal(t, DepositDetectedData{
+		AccountID: "1", Asset: "XBT", TransferID: "big", Amount: dec(t, "0.5"), Fee: dec(t, "0.0001"),
+		TransactionID: "tx1", CreatedAt: time.Time(after),
+	}, (*published)[0].Data)
+	assert.Equal(t, "eth-new", (*published)[1].Data.(DepositDetectedData).TransferID,
+		"accounts opened after the first poll only report new deposits")
+
+	require.Len(t, notified, 2)
+	assert.Equal(t, "info", notified[0]["level"])
+	assert.Equal(t, depositNotificationLogger, notified[0]["logger"])
+	assert.Equal(t, "Deposit of 0.5 XBT received", notified[0]["data"].(map[string]any)["message"])
+
+	balances(xbt, zar, eth)
+	transfers(1, luno.Transfer{Id: "big", Inbound: true, Amount: dec(t, "0.5")})
+	transfers(2, luno.Transfer{Id: "rand", Inbound: true, Amount: dec(t, "100"), CreatedAt: after})
+	transfers(3, luno.Transfer{Id: "eth-new", Inbound: true, Amount: dec(t, "2")})
+	require.NoError(t, w.Poll(ctx))
+	require.Len(t, *published, 3, "each deposit is reported once")
+	assert.Equal(t, "rand", (*published)[2].Data.(DepositDetectedData).TransferID)
+}
+
+func TestDepositWatcherRules(t *testing.T) {
+	ctx := context.Background()
+	client := sdk.NewMockLunoClient(t)
+	bus, published := recorder()
+	w := NewDepositWatcher(client, bus, nil, []DepositRule{{Asset: "ZAR", MinAmount: dec(t, "500")}})
+	balances := &luno.GetBalancesResponse{Balance: []luno.AccountBalance{
+		{AccountId: "1", Asset: "XBT"},
+		{AccountId: "2", Asset: "ZAR"},
+	}}
+	client.EXPECT().GetBalances(ctx, &luno.GetBalancesRequest{}).Return(balances, nil).Twice()
+	client.EXPECT().ListTransfers(ctx, &luno.ListTransfersRequest{AccountId: 2, Limit: watchedTransfers}).
+		Return(&luno.ListTransfersResponse{}, nil).Once()
+	require.NoError(t, w.Poll(ctx))
+
+	client.EXPECT().ListTransfers(ctx, &luno.ListTransfersRequest{AccountId: 2, Limit: watchedTransfers}).
+		Return(&luno.ListTransfersResponse{Transfers: []luno.Transfer{
+			{Id: "a", Inbound: true, Amount: dec(t, "500")},
+			{Id: "b", Inbound: true, Amount: dec(t, "499.99")},
+		}}, nil).Once()
+	require.NoError(t, w.Poll(ctx))
+	require.Len(t, *published, 1, "assets without a rule are not polled, and smaller deposits are not reported")
+	assert.Equal(t, "a", (*published)[0].Data.(DepositDetectedData).TransferID)
+}
+
+func TestDepositWatcherBalancesError(t *testing.T) {
+	ctx := context.Background()
+	client := sdk.NewMockLunoClient(t)
+	w := NewDepositWatcher(client, nil, nil, []DepositRule{{Asset: AnyAsset, MinAmount: decimal.Zero()}})
+	client.EXPECT().GetBalances(ctx, &luno.GetBalancesRequest{}).Return(nil, errors.New("unauthorised"))
+	assert.ErrorContains(t, w.Poll(ctx), "listing balances: unauthorised")
+}
@@ -0,0 +1,93 @@
+// Package events publishes account events, such as filled orders, to outbound sinks.
+package events
+
+import (
+	"context"
+	"crypto/rand"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Type identifies the kind of an event
+type Type string
+
+const (
+	OrderFilled         Type = "order.filled"
+	PriceAlertTriggered Type = "price_alert.triggered"
+	WithdrawalCompleted Type = "withdrawal.completed"
+	WithdrawalFailed    Type = "withdrawal.failed"
+	DepositDetected     Type = "deposit.detected"
+	LimitBreached       Type = "limit.breached"
+	ReportDigest        Type = "report.digest"
+	ApprovalRequested   Type = "approval.requested"
+)
+
+// Event is the envelope delivered to sinks
+type Event struct {
+	ID   string    `json:"id"`
+	Type Type      `json:"type"`
+	Time time.Time `json:"time"`
+	Data any       `json:"data"`
+}
+
+// New creates an event of type t with a unique ID
+func New(t Type, data any) Event {
+	return Event{
+		ID:   rand.Text(),
+		Type: t,
+		Time: time.Now().UTC(),
+		Data: data,
+	}
+}
+
+// Sink receives published events
+type Sink interface {
+	Send(ctx context.Context, e Event) error
+}
+
+// Bus fans events out to its sinks. It is safe for concurrent use, and a nil
+// Bus discards events.
+type Bus struct {
+	mu    sync.RWMutex
+	sinks []Sink
+}
+
+// NewBus creates a Bus that publishes to sinks
+func NewBus(sinks ...Sink) *Bus {
+	return &Bus{sinks: sinks}
+}
+
+// AddSink adds a sink to the bus
+func (b *Bus) AddSink(s Sink) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.sinks = append(b.sinks, s)
+}
+
+// Enabled reports whether any sinks are configured
+func (b *Bus) Enabled() bool {
+	if b == nil {
+		return false
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.sinks) > 0
+}
+
+// Publish sends e to every sink. Delivery failures are logged, not returned, so
+// that one broken sink does not stop the others.
+func (b *Bus) Publish(ctx context.Context, e Event) {
+	if b == nil {
+		return
+	}
+	b.mu.RLock()
+	sinks := b.sinks
+	b.mu.RUnlock()
+
+	for _, s := range sinks {
+		if err := s.Send(ctx, e); err != nil {
+			slog.WarnContext(ctx, "Failed to deliver event", "event_id", e.ID, "type", e.Type, "error", err)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type sinkFunc func(ctx context.Context, e Event) error
+
+func (f sinkFunc) Send(ctx context.Context, e Event) error {
+	return f(ctx, e)
+}
+
+func TestNew(t *testing.T) {
+	a := New(OrderFilled, "data")
+	b := New(OrderFilled, "data")
+
+	assert.Equal(t, OrderFilled, a.Type)
+	assert.Equal(t, "data", a.Data)
+	assert.NotEmpty(t, a.ID)
+	assert.NotEqual(t, a.ID, b.ID)
+	assert.Equal(t, "UTC", a.Time.Location().String())
+}
+
+func TestBusPublish(t *testing.T) {
+	var received []Type
+	failing := sinkFunc(func(context.Context, Event) error {
+		return errors.New("unreachable")
+	})
+	recording := sinkFunc(func(_ context.Context, e Event) error {
+		received = append(received, e.Type)
+		return nil
+	})
+
+	bus := NewBus(failing)
+	assert.True(t, bus.Enabled())
+	bus.AddSink(recording)
+
+	bus.Publish(context.Background(), New(LimitBreached, nil))
+	assert.Equal(t, []Type{LimitBreached}, received, "a failing sink does not stop the others")
+}
+
+func TestBusDisabled(t *testing.T) {
+	assert.False(t, NewBus().Enabled())
+
+	var bus *Bus
+	assert.False(t, bus.Enabled())
+	assert.NotPanics(t, func() {
+		bus.Publish(context.Background(), New(OrderFilled, nil))
+	})
+}
@@ -0,0 +1,186 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/sdk"
+)
+
+const (
+	// DefaultWatchInterval is how often the Watcher polls the account
+	DefaultWatchInterval = 30 * time.Second
+	// watchedWithdrawals is how many recent withdrawals are checked per poll
+	watchedWithdrawals = 20
+)
+
+// OrderFilledData is the payload of an OrderFilled event
+type OrderFilledData struct {
+	OrderID     string          `json:"order_id"`
+	Pair        string          `json:"pair"`
+	Type        luno.OrderType  `json:"type"`
+	LimitPrice  decimal.Decimal `json:"limit_price,omitzero"`
+	LimitVolume decimal.Decimal `json:"limit_volume,omitzero"`
+	Base        decimal.Decimal `json:"base"`
+	Counter     decimal.Decimal `json:"counter"`
+	// Partial is set when the order was cancelled after only part of it traded
+	Partial bool `json:"partial"`
+}
+
+// WithdrawalCompletedData is the payload of a WithdrawalCompleted event
+type WithdrawalCompletedData struct {
+	WithdrawalID string          `json:"withdrawal_id"`
+	Currency     string          `json:"currency"`
+	Amount       decimal.Decimal `json:"amount"`
+	Fee          decimal.Decimal `json:"fee"`
+}
+
+// WithdrawalFailedData is the payload of a WithdrawalFailed event
+type WithdrawalFailedData struct {
+	WithdrawalID string          `json:"withdrawal_id"`
+	Currency     string          `json:"currency"`
+	Amount       decimal.Decimal `json:"amount"`
+	Status       luno.Status     `json:"status"`
+}
+
+// Watcher polls an account and publishes OrderFilled, WithdrawalCompleted and
+// WithdrawalFailed events when it sees orders trade and withdrawals complete or
+// fail. The first poll only records the current state.
+type Watcher struct {
+	client   sdk.LunoClient
+	bus      *Bus
+	interval time.Duration
+
+	primed        bool
+	pendingOrders map[string]bool
+	withdrawals   map[string]luno.Status
+}
+
+// NewWatcher creates a Watcher that polls client every DefaultWatchInterval
+func NewWatcher(client sdk.LunoClient, bus *Bus) *Watcher {
+	return &Watcher{
+		client:        client,
+		bus:           bus,
+		interval:      DefaultWatchInterval,
+		pendingOrders: make(map[string]bool),
+		withdrawals:   make(map[string]luno.Status),
+	}
+}
+
+// Run polls until ctx is cancelled
+func (w *Watcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		if err := w.Poll(ctx); err != nil {
+			slog.WarnContext(ctx, "Failed to poll account for events", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll checks the account once and publishes any new events
+func (w *Watcher) Poll(ctx context.Context) error {
+	err := errors.Join(w.pollOrders(ctx), w.pollWithdrawals(ctx))
+	w.primed = true
+	return err
+}
+
+func (w *Watcher) pollOrders(ctx context.Context) error {
+	res, err := w.client.ListOrders(ctx, &luno.ListOrdersRequest{State: luno.OrderStatePending})
+	if err != nil {
+		return fmt.Errorf("listing open orders: %w", err)
+	}
+
+	current := make(map[string]bool, len(res.Orders))
+	for _, o := range res.Orders {
+		current[o.OrderId] = true
+	}
+
+	var errs []error
+	for id := range w.pendingOrders {
+		if current[id] {
+			continue
+		}
+		o, err := w.client.GetOrder(ctx, &luno.GetOrderRequest{Id: id})
+		if err != nil {
+			// Check again on the next poll
+			current[id] = true
+			errs = append(errs, fmt.Errorf("getting order %s: %w", id, err))
+			continue
+		}
+		if o.State != luno.OrderStateComplete || o.Base.Sign() <= 0 {
+			continue
+		}
+		w.bus.Publish(ctx, New(OrderFilled, OrderFilledData{
+			OrderID:     o.OrderId,
+			Pair:        o.Pair,
+			Type:        o.Type,
+			LimitPrice:  o.LimitPrice,
+			LimitVolume: o.LimitVolume,
+			Base:        o.Base,
+			Counter:     o.Counter,
+			Partial:     o.LimitVolume.Sign() > 0 && o.Base.Cmp(o.LimitVolume) < 0,
+		}))
+	}
+
+	w.pendingOrders = current
+	return errors.Join(errs...)
+}
+
+func (w *Watcher) pollWithdrawals(ctx context.Context) error {
+	res, err := w.client.ListWithdrawals(ctx, &luno.ListWithdrawalsRequest{Limit: watchedWithdrawals})
+	if err != nil {
+		return fmt.Errorf("listing withdrawals: %w", err)
+	}
+
+	current := make(map[string]luno.Status, len(res.Withdrawals))
+	for _, wd := range res.Withdrawals {
+		previous, seen := w.withdrawals[wd.Id]
+		current[wd.Id] = wd.Status
+		if !w.primed || (seen && withdrawalFinished(previous)) {
+			continue
+		}
+		switch {
+		case withdrawalComplete(wd.Status):
+			w.bus.Publish(ctx, New(WithdrawalCompleted, WithdrawalCompletedData{
+				WithdrawalID: wd.Id,
+				Currency:     wd.Currency,
+				Amount:       wd.Amount,
+				Fee:          wd.Fee,
+			}))
+		case withdrawalFailed(wd.Status):
+			w.bus.Publish(ctx, New(WithdrawalFailed, WithdrawalFailedData{
+				WithdrawalID: wd.Id,
+				Currency:     wd.Currency,
+				Amount:       wd.Amount,
+				Status:       wd.Status,
+			}))
+		}
+	}
+
+	w.withdrawals = current
+	return nil
+}
+
+func withdrawalComplete(s luno.Status) bool {
+	return s == luno.StatusComplete || s == luno.StatusCompleted
+}
+
+func withdrawalFailed(s luno.Status) bool {
+	return s == luno.StatusCancelled || s == luno.StatusFailed
+}
+
+// withdrawalFinished reports whether a withdrawal with status s will not change again
+func withdrawalFinished(s luno.Status) bool {
+	return withdrawalComplete(s) || withdrawalFailed(s)
+}
@@ -0,0 +1,136 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func dec(t *testing.T, s string) decimal.Decimal {
+	t.Helper()
+	d, err := decimal.NewFromString(s)
+	require.NoError(t, err)
+	return d
+}
+
+// recorder returns a bus that records the events published to it
+func recorder() (*Bus, *[]Event) {
+	var published []Event
+	return NewBus(sinkFunc(func(_ context.Context, e Event) error {
+		published = append(published, e)
+		return nil
+	})), &published
+}
+
+func TestWatcherOrders(t *testing.T) {
+	ctx := context.Background()
+	client := sdk.NewMockLunoClient(t)
+	bus, published := recorder()
+	w := NewWatcher(client, bus)
+	client.EXPECT().ListWithdrawals(ctx, &luno.ListWithdrawalsRequest{Limit: watchedWithdrawals}).Return(&luno.ListWithdrawalsResponse{}, nil)
+
+	pending := func(ids ...string) {
+		var orders []luno.Order
+		for _, id := range ids {
+			orders = append(orders, luno.Order{OrderId: id})
+		}
+		client.EXPECT().ListOrders(ctx, &luno.ListOrdersRequest{State: luno.OrderStatePending}).
+			Return(&luno.ListOrdersResponse{Orders: orders}, nil).Once()
+	}
+
+	pending("filled", "partial", "cancelled", "flaky")
+	require.NoError(t, w.Poll(ctx))
+	assert.Empty(t, *published, "the first poll only records open orders")
+
+	pending()
+	client.EXPECT().GetOrder(ctx, &luno.GetOrderRequest{Id: "filled"}).Return(&luno.GetOrderResponse{
+		OrderId: "filled", Pair: "XBTZAR", Type: luno.OrderTypeBid, State: luno.OrderStateComplete,
+		LimitVolume: dec(t, "0.1"), Base: dec(t, "0.1"), Counter: dec(t, "100000"),
+	}, nil)
+	client.EXPECT().GetOrder(ctx, &luno.GetOrderRequest{Id: "partial"}).Return(&luno.GetOrderResponse{
+		OrderId: "partial", Pair: "XBTZAR", State: luno.OrderStateComplete,
+		LimitVolume: dec(t, "0.1"), Base: dec(t, "0.04"),
+	}, nil)
+	client.EXPECT().GetOrder(ctx, &luno.GetOrderRequest{Id: "cancelled"}).Return(&luno.GetOrderResponse{
+		OrderId: "cancelled", State: luno.OrderStateComplete, LimitVolume: dec(t, "0.1"), Base: dec(t, "0"),
+	}, nil)
+	client.EXPECT().GetOrder(ctx, &luno.GetOrderRequest{Id: "flaky"}).Return(nil, errors.New("timeout")).Once()
+	err := w.Poll(ctx)
+	assert.ErrorContains(t, err, "getting order flaky")
+
+	fills := map[string]bool{}
+	for _, e := range *published {
+		assert.Equal(t, OrderFilled, e.Type)
+		data, ok := e.Data.(OrderFilledData)
+		require.True(t, ok)
+		fills[data.OrderID] = data.Partial
+	}
+	assert.Equal(t, map[string]bool{"filled": false, "partial": true}, fills)
+
+	pending()
+	client.EXPECT().GetOrder(ctx, &luno.GetOrderRequest{Id: "flaky"}).Return(&luno.GetOrderResponse{
+		OrderId: "flaky", State: luno.OrderStateComplete, LimitVolume: dec(t, "1"), Base: dec(t, "1"),
+	}, nil).Once()
+	require.NoError(t, w.Poll(ctx))
+	require.Len(t, *published, 3, "orders that could not be read are checked again")
+	assert.Equal(t, "flaky", (*published)[2].Data.(OrderFilledData).OrderID)
+}
+
+func TestWatcherWithdrawals(t *testing.T) {
+	ctx := context.Background()
+	client := sdk.NewMockLunoClient(t)
+	bus, published := recorder()
+	w := NewWatcher(client, bus)
+	client.EXPECT().ListOrders(ctx, &luno.ListOrdersRequest{State: luno.OrderStatePending}).Return(&luno.ListOrdersResponse{}, nil)
+
+	withdrawals := func(ws ...luno.Withdrawal) {
+		client.EXPECT().ListWithdrawals(ctx, &luno.ListWithdrawalsRequest{Limit: watchedWithdrawals}).
+			Return(&luno.ListWithdrawalsResponse{Withdrawals: ws}, nil).Once()
+	}
+
+	withdrawals(
+		luno.Withdrawal{Id: "1", Status: luno.StatusComplete},
+		luno.Withdrawal{Id: "2", Status: luno.StatusPending},
+	)
+	require.NoError(t, w.Poll(ctx))
+	assert.Empty(t, *published, "withdrawals completed before the first poll are not reported")
+
+	withdrawals(
+		luno.Withdrawal{Id: "1", Status: luno.StatusComplete},
+		luno.Withdrawal{Id: "2", Status: luno.StatusCompleted, Currency: "ZAR", Amount: dec(t, "500"), Fee: dec(t, "8.5")},
+		luno.Withdrawal{Id: "3", Status: luno.StatusComplete, Currency: "ZAR", Amount: dec(t, "100")},
+	)
+	require.NoError(t, w.Poll(ctx))
+	require.Len(t, *published, 2)
+	assert.Equal(t, WithdrawalCompleted, (*published)[0].Type)
+	first := (*published)[0].Data.(WithdrawalCompletedData)
+	assert.Equal(t, "2", first.WithdrawalID)
+	assert.Equal(t, "ZAR", first.Currency)
+	assert.Equal(t, "3", (*published)[1].Data.(WithdrawalCompletedData).WithdrawalID)
+
+	withdrawals(
+		luno.Withdrawal{Id: "2", Status: luno.StatusCompleted},
+		luno.Withdrawal{Id: "3", Status: luno.StatusComplete},
+	)
+	require.NoError(t, w.Poll(ctx))
+	assert.Len(t, *published, 2, "each withdrawal is reported once")
+
+	withdrawals(
+		luno.Withdrawal{Id: "3", Status: luno.StatusComplete},
+		luno.Withdrawal{Id: "4", Status: luno.StatusFailed, Currency: "ZAR", Amount: dec(t, "200")},
+	)
+	require.NoError(t, w.Poll(ctx))
+	require.Len(t, *published, 3)
+	assert.Equal(t, WithdrawalFailed, (*published)[2].Type)
+	assert.Equal(t, WithdrawalFailedData{WithdrawalID: "4", Currency: "ZAR", Amount: dec(t, "200"), Status: luno.StatusFailed}, (*published)[2].Data)
+
+	withdrawals(luno.Withdrawal{Id: "4", Status: luno.StatusFailed})
+	require.NoError(t, w.Poll(ctx))
+	assert.Len(t, *published, 3, "failures are reported once")
+}
@@ -0,0 +1,92 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Headers set on webhook requests. The signature covers the timestamp and body,
+// see Sign.
+const (
+	EventHeader     = "X-Luno-MCP-Event"
+	TimestampHeader = "X-Luno-MCP-Timestamp"
+	SignatureHeader = "X-Luno-MCP-Signature"
+)
+
+// webhookTimeout bounds a single webhook delivery
+const webhookTimeout = 10 * time.Second
+
+// Webhook is a Sink that POSTs events as JSON to a URL. When Secret is set each
+// request is signed with HMAC-SHA256.
+type Webhook struct {
+	URL    string
+	Secret string
+	Client *http.Client
+}
+
+// NewWebhook creates a Webhook sink for an http or https URL
+func NewWebhook(rawURL, secret string) (*Webhook, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return nil, fmt.Errorf("invalid webhook URL %q: must be an http or https URL", rawURL)
+	}
+	return &Webhook{
+		URL:    rawURL,
+		Secret: secret,
+		Client: &http.Client{Timeout: webhookTimeout},
+	}, nil
+}
+
+// Send implements Sink
+func (w *Webhook) Send(ctx context.Context, e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(e.Type))
+	req.Header.Set(TimestampHeader, timestamp)
+	if w.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(w.Secret, timestamp, body))
+	}
+
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("posting %s event: %w", e.Type, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("posting %s event: webhook returned %s", e.Type, resp.Status)
+	}
+	return nil
+}
+
+// Sign returns the signature header value for a webhook request: "sha256=" followed
+// by the hex HMAC-SHA256 of "<timestamp>.<body>" keyed with secret. Receivers should
+// recompute it, compare in constant time and reject stale timestamps.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,112 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWebhook(t *testing.T) {
+	tests := []struct {
+		name          string
+		url           string
+		expectedError bool
+	}{
+		{name: "https", url: "https://example.com/hook"},
+		{name: "http", url: "http://localhost:8080/hook"},
+		{name: "no scheme", url: "example.com/hook", expectedError: true},
+		{name: "unsupported scheme", url: "ftp://example.com", expectedError: true},
+		{name: "no host", url: "https:///hook", expectedError: true},
+		{name: "empty", url: "", expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hook, err := NewWebhook(tt.url, "secret")
+			if tt.expectedError {
+				assert.ErrorContains(t, err, "invalid webhook URL")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.url, hook.URL)
+		})
+	}
+}
+
+func TestWebhookSend(t *testing.T) {
+	tests := []struct {
+		name              string
+		secret            string
+		status            int
+		expectedSignature bool
+		expectedError     string
+	}{
+		{
+			name:              "signed",
+			secret:            "secret",
+			status:            http.StatusNoContent,
+			expectedSignature: true,
+		},
+		{
+			name:   "unsigned without a secret",
+			status: http.StatusOK,
+		},
+		{
+			name:              "error status",
+			secret:            "secret",
+			status:            http.StatusInternalServerError,
+			expectedSignature: true,
+			expectedError:     "webhook returned 500",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received Event
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+				assert.Equal(t, string(OrderFilled), r.Header.Get(EventHeader))
+
+				body, err := io.ReadAll(r.Body)
+				assert.NoError(t, err)
+				signature := r.Header.Get(SignatureHeader)
+				if tt.expectedSignature {
+					assert.Equal(t, Sign(tt.secret, r.Header.Get(TimestampHeader), body), signature)
+				} else {
+					assert.Empty(t, signature)
+				}
+				assert.NoError(t, json.Unmarshal(body, &received))
+				w.WriteHeader(tt.status)
+			}))
+			defer ts.Close()
+
+			hook, err := NewWebhook(ts.URL, tt.secret)
+			require.NoError(t, err)
+
+			e := New(OrderFilled, map[string]string{"order_id": "BXMC2CJ7HNB88U4"})
+			err = hook.Send(context.Background(), e)
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, e.ID, received.ID)
+			assert.Equal(t, map[string]any{"order_id": "BXMC2CJ7HNB88U4"}, received.Data)
+		})
+	}
+}
+
+func TestSign(t *testing.T) {
+	// Computed independently with: printf '1700000000.{}' | openssl dgst -sha256 -hmac secret
+	assert.Equal(t,
+		"sha256=b8569b78799ff9e3cbff0fc2d63a33a2b57f3282abd07c37ae5e8e7d79a5f163",
+		Sign("secret", "1700000000", []byte("{}")),
+	)
+}
@@ -0,0 +1,161 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/sdk"
+)
+
+const (
+	// maxMonitoredWithdrawals bounds how many withdrawals are monitored at once
+	maxMonitoredWithdrawals = 50
+	// maxWithdrawalMonitor is how long a withdrawal is monitored before giving
+	// up. Bank withdrawals usually complete within a few business days.
+	maxWithdrawalMonitor = 7 * 24 * time.Hour
+)
+
+// WithdrawalUpdate reports a change to a monitored withdrawal
+type WithdrawalUpdate struct {
+	WithdrawalID string                      `json:"withdrawal_id"`
+	Status       luno.Status                 `json:"status"`
+	Withdrawal   *luno.GetWithdrawalResponse `json:"withdrawal,omitempty"`
+	// Done is set on the last update, once the withdrawal has completed or
+	// failed or monitoring has stopped
+	Done    bool   `json:"done"`
+	Message string `json:"message"`
+}
+
+// WithdrawalNotifyFunc is told when a monitored withdrawal changes status, and
+// when monitoring it stops
+type WithdrawalNotifyFunc func(u WithdrawalUpdate)
+
+// WithdrawalMonitor polls individual withdrawals in the background until they
+// complete or fail. It is safe for concurrent use. Monitoring is kept in memory
+// and stops with the process.
+type WithdrawalMonitor struct {
+	interval time.Duration
+
+	mu       sync.Mutex
+	watching map[string]context.CancelFunc
+	wg       sync.WaitGroup
+
+	now   func() time.Time
+	after func(time.Duration) <-chan time.Time
+}
+
+// NewWithdrawalMonitor creates a WithdrawalMonitor that polls every interval
+func NewWithdrawalMonitor(interval time.Duration) *WithdrawalMonitor {
+	return &WithdrawalMonitor{
+		interval: interval,
+		watching: make(map[string]context.CancelFunc),
+		now:      time.Now,
+		after:    time.After,
+	}
+}
+
+// Watch starts monitoring the withdrawal id with client, calling notify when
+// its status changes. Watching a withdrawal that is already monitored does
+// nothing.
+func (m *WithdrawalMonitor) Watch(id string, client sdk.LunoClient, notify WithdrawalNotifyFunc) error {
+	if client == nil || notify == nil {
+		return errors.New("a Luno client and notify function are required")
+	}
+	numericID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid withdrawal ID %q", id)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.watching[id]; ok {
+		return nil
+	}
+	if len(m.watching) >= maxMonitoredWithdrawals {
+		return fmt.Errorf("already monitoring %d withdrawals, the most allowed", maxMonitoredWithdrawals)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.watching[id] = cancel
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		defer m.stop(id)
+		m.run(ctx, id, numericID, client, notify)
+	}()
+	return nil
+}
+
+// Watching reports whether the withdrawal id is being monitored
+func (m *WithdrawalMonitor) Watching(id string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.watching[id]
+	return ok
+}
+
+// Close stops monitoring every withdrawal and waits for the monitors to exit
+func (m *WithdrawalMonitor) Close() {
+	m.mu.Lock()
+	for _, cancel := range m.watching {
+		cancel()
+	}
+	m.mu.Unlock()
+	m.wg.Wait()
+}
+
+func (m *WithdrawalMonitor) stop(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if cancel, ok := m.watching[id]; ok {
+		cancel()
+		delete(m.watching, id)
+	}
+}
+
+// run polls the withdrawal until it completes or fails, monitoring times out
+// or ctx is cancelled. Failed polls are retried at the next interval.
+func (m *WithdrawalMonitor) run(ctx context.Context, id string, numericID int64, client sdk.LunoClient, notify WithdrawalNotifyFunc) {
+	deadline := m.now().Add(maxWithdrawalMonitor)
+	var last luno.Status
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.after(m.interval):
+		}
+
+		wd, err := client.GetWithdrawal(ctx, &luno.GetWithdrawalRequest{Id: numericID})
+		switch {
+		case ctx.Err() != nil:
+			return
+		case err != nil:
+			slog.WarnContext(ctx, "Failed to poll monitored withdrawal", "withdrawal_id", id, "error", err)
+		case withdrawalComplete(wd.Status):
+			notify(WithdrawalUpdate{WithdrawalID: id, Status: wd.Status, Withdrawal: wd, Done: true,
+				Message: fmt.Sprintf("Withdrawal %s of %s %s completed", id, wd.Amount, wd.Currency)})
+			return
+		case withdrawalFailed(wd.Status):
+			notify(WithdrawalUpdate{WithdrawalID: id, Status: wd.Status, Withdrawal: wd, Done: true,
+				Message: fmt.Sprintf("Withdrawal %s of %s %s failed with status %s", id, wd.Amount, wd.Currency, wd.Status)})
+			return
+		case wd.Status != last:
+			if last != "" {
+				notify(WithdrawalUpdate{WithdrawalID: id, Status: wd.Status, Withdrawal: wd,
+					Message: fmt.Sprintf("Withdrawal %s is now %s", id, wd.Status)})
+			}
+			last = wd.Status
+		}
+
+		if !m.now().Before(deadline) {
+			notify(WithdrawalUpdate{WithdrawalID: id, Status: last, Done: true,
+				Message: fmt.Sprintf("Stopped monitoring withdrawal %s after %d days; use get_fiat_withdrawal to check it", id, maxWithdrawalMonitor/(24*time.Hour))})
+			return
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// testMonitor returns a monitor that polls whenever a tick is sent
+func testMonitor() (*WithdrawalMonitor, chan time.Time) {
+	ticks := make(chan time.Time)
+	m := NewWithdrawalMonitor(time.Minute)
+	m.after = func(time.Duration) <-chan time.Time { return ticks }
+	return m, ticks
+}
+
+func nextUpdate(t *testing.T, updates <-chan WithdrawalUpdate) WithdrawalUpdate {
+	t.Helper()
+	select {
+	case u := <-updates:
+		return u
+	case <-time.After(5 * time.Second):
+		t.Fatal("no withdrawal update")
+		return WithdrawalUpdate{}
+	}
+}
+
+func TestWithdrawalMonitorCompleted(t *testing.T) {
+	client := sdk.NewMockLunoClient(t)
+	m, ticks := testMonitor()
+	updates := make(chan WithdrawalUpdate, 10)
+	status := func(s luno.Status) {
+		client.EXPECT().GetWithdrawal(mock.Anything, &luno.GetWithdrawalRequest{Id: 123}).
+			Return(&luno.GetWithdrawalResponse{Id: "123", Status: s, Amount: dec(t, "1000"), Currency: "ZAR"}, nil).Once()
+	}
+
+	require.NoError(t, m.Watch("123", client, func(u WithdrawalUpdate) { updates <- u }))
+	assert.True(t, m.Watching("123"))
+
+	status(luno.StatusPending)
+	ticks <- time.Time{}
+	client.EXPECT().GetWithdrawal(mock.Anything, &luno.GetWithdrawalRequest{Id: 123}).Return(nil, errors.New("timeout")).Once()
+	ticks <- time.Time{}
+	status(luno.StatusPending)
+	ticks <- time.Time{}
+	assert.Empty(t, updates, "the first status and failed polls are not reported")
+
+	status(luno.StatusProcessing)
+	ticks <- time.Time{}
+	u := nextUpdate(t, updates)
+	assert.Equal(t, luno.StatusProcessing, u.Status)
+	assert.False(t, u.Done)
+	assert.Equal(t, "Withdrawal 123 is now PROCESSING", u.Message)
+
+	status(luno.StatusCompleted)
+	ticks <- time.Time{}
+	u = nextUpdate(t, updates)
+	assert.True(t, u.Done)
+	assert.Equal(t, "Withdrawal 123 of 1000 ZAR completed", u.Message)
+	require.NotNil(t, u.Withdrawal)
+
+	m.Close()
+	assert.False(t, m.Watching("123"))
+}
+
+func TestWithdrawalMonitorFailed(t *testing.T) {
+	client := sdk.NewMockLunoClient(t)
+	m, ticks := testMonitor()
+	updates := make(chan WithdrawalUpdate, 10)
+	client.EXPECT().GetWithdrawal(mock.Anything, &luno.GetWithdrawalRequest{Id: 7}).
+		Return(&luno.GetWithdrawalResponse{Id: "7", Status: luno.StatusCancelled, Amount: dec(t, "50"), Currency: "NGN"}, nil).Once()
+
+	require.NoError(t, m.Watch("7", client, func(u WithdrawalUpdate) { updates <- u }))
+	ticks <- time.Time{}
+	u := nextUpdate(t, updates)
+	assert.True(t, u.Done)
+	assert.Equal(t, luno.StatusCancelled, u.Status)
+	assert.Equal(t, "Withdrawal 7 of 50 NGN failed with status CANCELLED", u.Message)
+	m.Close()
+}
+
+func TestWithdrawalMonitorGivesUp(t *testing.T) {
+	client := sdk.NewMockLunoClient(t)
+	m, ticks := testMonitor()
+	now := time.Date(2026, 10, 15, 8, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+	updates := make(chan WithdrawalUpdate, 10)
+	client.EXPECT().GetWithdrawal(mock.Anything, &luno.GetWithdrawalRequest{Id: 5}).
+		Return(&luno.GetWithdrawalResponse{Id: "5", Status: luno.StatusPending}, nil).
+		Run(func(context.Context, *luno.GetWithdrawalRequest) { now = now.Add(maxWithdrawalMonitor) }).Once()
+
+	require.NoError(t, m.Watch("5", client, func(u WithdrawalUpdate) { updates <- u }))
+	ticks <- time.Time{}
+	u := nextUpdate(t, updates)
+	assert.True(t, u.Done)
+	assert.Equal(t, luno.StatusPending, u.Status)
+	assert.Contains(t, u.Message, "Stopped monitoring withdrawal 5 after 7 days")
+	m.Close()
+}
+
+func TestWithdrawalMonitorWatch(t *testing.T) {
+	client := sdk.NewMockLunoClient(t)
+	m, _ := testMonitor()
+	defer m.Close()
+	notify := func(WithdrawalUpdate) {}
+
+	assert.ErrorContains(t, m.Watch("abc", client, notify), `invalid withdrawal ID "abc"`)
+	assert.Error(t, m.Watch("1", client, nil))
+	assert.Error(t, m.Watch("1", nil, notify))
+
+	for i := range maxMonitoredWithdrawals {
+		require.NoError(t, m.Watch(strconv.Itoa(i+1), client, notify))
+	}
+	assert.NoError(t, m.Watch("1", client, notify), "watching a withdrawal twice does nothing")
+	assert.ErrorContains(t, m.Watch("1000", client, notify), "already monitoring 50 withdrawals")
+}
@@ -0,0 +1,575 @@
+// Package filter narrows, sorts and trims the results of list tools.
+//
+// Filter expressions, such as `balance_delta > 1000 AND kind == 'TRANSFER'`,
+// select the results to return.
+// An expression compares fields with literals using ==, !=, >, >=, <, <= and
+// contains, and combines comparisons with AND, OR, NOT and parentheses. Fields
+// are the JSON field names of the results, with dots selecting nested fields,
+// e.g. details.txid. Literals are quoted strings, numbers, true, false and
+// null. Strings are compared ignoring case, and numbers exactly, including
+// amounts the results hold as decimal strings.
+//
+// Sort orders results by a field, and Project keeps only the fields asked for.
+package filter
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/luno/luno-go/decimal"
+)
+
+// MaxLength is the longest expression accepted
+const MaxLength = 1000
+
+// Expr is a parsed filter expression
+type Expr struct {
+	src    string
+	root   node
+	fields []string
+}
+
+// Parse parses a filter expression
+func Parse(s string) (*Expr, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, errors.New("the filter is empty")
+	}
+	if len(s) > MaxLength {
+		return nil, fmt.Errorf("the filter is longer than %d characters", MaxLength)
+	}
+	tokens, err := lex(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos+1)
+	}
+	e := &Expr{src: s, root: root}
+	root.walk(func(c *comparison) {
+		if top, _, _ := strings.Cut(c.field, "."); !slices.Contains(e.fields, top) {
+			e.fields = append(e.fields, top)
+		}
+	})
+	return e, nil
+}
+
+// String returns the expression as it was given
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Apply returns the items matching e. Each item is compared as it encodes to
+// JSON. It is an error for e to name a field none of the items have, since
+// that is almost always a typo.
+func Apply[T any](e *Expr, items []T) ([]T, error) {
+	seen := make(map[string]bool)
+	var out []T
+	for _, item := range items {
+		rec, err := record(item)
+		if err != nil {
+			return nil, err
+		}
+		for k := range rec {
+			seen[k] = true
+		}
+		if e.root.eval(rec) {
+			out = append(out, item)
+		}
+	}
+	if len(items) > 0 {
+		for _, f := range e.fields {
+			if !seen[f] {
+				return nil, fmt.Errorf("the results have no field %q", f)
+			}
+		}
+	}
+	if out == nil {
+		out = []T{}
+	}
+	return out, nil
+}
+
+// Sort sorts items by field in place, largest first if desc. Numbers, including
+// decimal strings, are compared as numbers and other strings ignoring case.
+// Items without the field sort last either way, and the sort is stable.
+func Sort[T any](items []T, field string, desc bool) error {
+	field = strings.ToLower(strings.TrimSpace(field))
+	type keyed struct {
+		item T
+		key  any
+	}
+	keys := make([]keyed, len(items))
+	found := false
+	for i, item := range items {
+		rec, err := record(item)
+		if err != nil {
+			return err
+		}
+		keys[i] = keyed{item: item, key: lookup(rec, field)}
+		found = found || keys[i].key != nil
+	}
+	if len(items) > 0 && !found {
+		return fmt.Errorf("the results have no field %q to sort by", field)
+	}
+	slices.SortStableFunc(keys, func(a, b keyed) int {
+		switch {
+		case a.key == nil && b.key == nil:
+			return 0
+		case a.key == nil:
+			return 1
+		case b.key == nil:
+			return -1
+		}
+		c := compare(a.key, b.key)
+		if desc {
+			return -c
+		}
+		return c
+	})
+	for i, k := range keys {
+		items[i] = k.item
+	}
+	return nil
+}
+
+// compare orders two field values, numbers before strings before anything else
+func compare(a, b any) int {
+	an, aNum := number(a)
+	bn, bNum := number(b)
+	switch {
+	case aNum && bNum:
+		return an.Cmp(bn)
+	case aNum:
+		return -1
+	case bNum:
+		return 1
+	}
+	as, aStr := a.(string)
+	bs, bStr := b.(string)
+	switch {
+	case aStr && bStr:
+		return cmp.Compare(strings.ToLower(as), strings.ToLower(bs))
+	case aStr:
+		return -1
+	case bStr:
+		return 1
+	}
+	ab, _ := a.(bool)
+	bb, _ := b.(bool)
+	switch {
+	case ab == bb:
+		return 0
+	case bb:
+		return -1
+	}
+	return 1
+}
+
+// Project returns items with only fields kept. Dotted fields keep just that
+// part of a nested object. It is an error to ask for a field none of the items
+// have.
+func Project[T any](items []T, fields []string) ([]map[string]any, error) {
+	seen := make(map[string]bool)
+	out := make([]map[string]any, 0, len(items))
+	for _, item := range items {
+		rec, err := record(item)
+		if err != nil {
+			return nil, err
+		}
+		trimmed := make(map[string]any, len(fields))
+		for _, f := range fields {
+			v, ok := lookupOK(rec, f)
+			if !ok {
+				continue
+			}
+			seen[f] = true
+			set(trimmed, f, v)
+		}
+		out = append(out, trimmed)
+	}
+	if len(items) > 0 {
+		for _, f := range fields {
+			if !seen[f] {
+				return nil, fmt.Errorf("the results have no field %q", f)
+			}
+		}
+	}
+	return out, nil
+}
+
+// ParseFields splits a comma-separated list of field names, dropping blanks
+// and duplicates
+func ParseFields(s string) []string {
+	var fields []string
+	for f := range strings.SplitSeq(s, ",") {
+		f = strings.ToLower(strings.TrimSpace(f))
+		if f != "" && !slices.Contains(fields, f) {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// set stores v at a dotted field of rec, creating nested objects as needed
+func set(rec map[string]any, field string, v any) {
+	name, rest, nested := strings.Cut(field, ".")
+	if !nested {
+		rec[name] = v
+		return
+	}
+	m, ok := rec[name].(map[string]any)
+	if !ok {
+		m = make(map[string]any)
+		rec[name] = m
+	}
+	set(m, rest, v)
+}
+
+func record(v any) (map[string]any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("encoding result: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var rec map[string]any
+	if err := dec.Decode(&rec); err != nil {
+		return nil, fmt.Errorf("filter only applies to objects: %w", err)
+	}
+	return rec, nil
+}
+
+// ===== Evaluation =====
+
+type node interface {
+	eval(rec map[string]any) bool
+	walk(fn func(*comparison))
+}
+
+type and struct{ left, right node }
+
+func (n *and) eval(rec map[string]any) bool { return n.left.eval(rec) && n.right.eval(rec) }
+func (n *and) walk(fn func(*comparison))    { n.left.walk(fn); n.right.walk(fn) }
+
+type or struct{ left, right node }
+
+func (n *or) eval(rec map[string]any) bool { return n.left.eval(rec) || n.right.eval(rec) }
+func (n *or) walk(fn func(*comparison))    { n.left.walk(fn); n.right.walk(fn) }
+
+type not struct{ inner node }
+
+func (n *not) eval(rec map[string]any) bool { return !n.inner.eval(rec) }
+func (n *not) walk(fn func(*comparison))    { n.inner.walk(fn) }
+
+type comparison struct {
+	field string
+	op    string
+	value any // string, decimal.Decimal, bool or nil
+}
+
+func (c *comparison) walk(fn func(*comparison)) { fn(c) }
+
+func (c *comparison) eval(rec map[string]any) bool {
+	v := lookup(rec, c.field)
+	switch c.op {
+	case "==":
+		return equal(v, c.value)
+	case "!=":
+		return !equal(v, c.value)
+	case "contains":
+		s, ok := v.(string)
+		return ok && strings.Contains(strings.ToLower(s), strings.ToLower(c.value.(string)))
+	}
+	// Ordering only applies to numbers
+	want := c.value.(decimal.Decimal)
+	got, ok := number(v)
+	if !ok {
+		return false
+	}
+	cmp := got.Cmp(want)
+	switch c.op {
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	default:
+		return cmp <= 0
+	}
+}
+
+// lookup returns the value of a dotted field, or nil if it is missing
+func lookup(rec map[string]any, field string) any {
+	v, _ := lookupOK(rec, field)
+	return v
+}
+
+// lookupOK returns the value of a dotted field and whether it is present
+func lookupOK(rec map[string]any, field string) (any, bool) {
+	var v any = rec
+	for name := range strings.SplitSeq(field, ".") {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if v, ok = m[name]; !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+func equal(v, want any) bool {
+	switch want := want.(type) {
+	case nil:
+		return v == nil || v == ""
+	case bool:
+		b, ok := v.(bool)
+		return ok && b == want
+	case decimal.Decimal:
+		got, ok := number(v)
+		return ok && got.Cmp(want) == 0
+	case string:
+		s, ok := v.(string)
+		return ok && strings.EqualFold(s, want)
+	}
+	return false
+}
+
+// number reads a JSON number, or a string holding one as decimal amounts are encoded
+func number(v any) (decimal.Decimal, bool) {
+	var s string
+	switch v := v.(type) {
+	case json.Number:
+		s = v.String()
+	case string:
+		s = v
+	default:
+		return decimal.Decimal{}, false
+	}
+	d, err := decimal.NewFromString(s)
+	return d, err == nil
+}
+
+// ===== Parsing =====
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokOp
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func lex(s string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(s); {
+		c := rune(s[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '(':
+			tokens = append(tokens, token{tokLParen, "(", i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{tokRParen, ")", i})
+			i++
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(s[i+1:], s[i])
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string at position %d", i+1)
+			}
+			tokens = append(tokens, token{tokString, s[i+1 : i+1+end], i})
+			i += end + 2
+		case c == '-' || c == '.' || unicode.IsDigit(c):
+			j := i + 1
+			for j < len(s) && (s[j] == '.' || unicode.IsDigit(rune(s[j]))) {
+				j++
+			}
+			tokens = append(tokens, token{tokNumber, s[i:j], i})
+			i = j
+		case c == '_' || unicode.IsLetter(c):
+			j := i + 1
+			for j < len(s) && (s[j] == '_' || s[j] == '.' || unicode.IsLetter(rune(s[j])) || unicode.IsDigit(rune(s[j]))) {
+				j++
+			}
+			tokens = append(tokens, token{tokIdent, s[i:j], i})
+			i = j
+		default:
+			j := i
+			for j < len(s) && strings.ContainsRune("=!<>&|", rune(s[j])) {
+				j++
+			}
+			op := s[i:j]
+			switch op {
+			case "=":
+				op = "=="
+			case "==", "!=", ">", ">=", "<", "<=", "&&", "||", "!":
+			default:
+				if op == "" {
+					op = s[i : i+1]
+				}
+				return nil, fmt.Errorf("unexpected %q at position %d", op, i+1)
+			}
+			tokens = append(tokens, token{tokOp, op, i})
+			i = j
+		}
+	}
+	return append(tokens, token{kind: tokEOF, text: "end of filter", pos: len(s)}), nil
+}
+
+type parser struct {
+	tokens []token
+	i      int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.i]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+// keyword reports whether the next token is one of words, consuming it if so
+func (p *parser) keyword(words ...string) bool {
+	t := p.peek()
+	if (t.kind == tokIdent || t.kind == tokOp) && slices.ContainsFunc(words, func(w string) bool { return strings.EqualFold(t.text, w) }) {
+		p.i++
+		return true
+	}
+	return false
+}
+
+func (p *parser) or() (node, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("OR", "||") {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = &or{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) and() (node, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("AND", "&&") {
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = &and{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) unary() (node, error) {
+	if p.keyword("NOT", "!") {
+		inner, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &not{inner}, nil
+	}
+	if p.peek().kind == tokLParen {
+		p.next()
+		inner, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.next(); t.kind != tokRParen {
+			return nil, fmt.Errorf("expected ) at position %d, found %q", t.pos+1, t.text)
+		}
+		return inner, nil
+	}
+	return p.comparison()
+}
+
+func (p *parser) comparison() (node, error) {
+	field := p.next()
+	if field.kind != tokIdent || isKeyword(field.text) {
+		return nil, fmt.Errorf("expected a field name at position %d, found %q", field.pos+1, field.text)
+	}
+	c := &comparison{field: strings.ToLower(field.text)}
+
+	op := p.next()
+	switch {
+	case op.kind == tokOp && op.text != "&&" && op.text != "||" && op.text != "!":
+		c.op = op.text
+	case op.kind == tokIdent && strings.EqualFold(op.text, "contains"):
+		c.op = "contains"
+	default:
+		return nil, fmt.Errorf("expected an operator after %s at position %d, found %q", field.text, op.pos+1, op.text)
+	}
+
+	lit := p.next()
+	switch {
+	case lit.kind == tokString:
+		c.value = lit.text
+	case lit.kind == tokNumber:
+		d, err := decimal.NewFromString(lit.text)
+		if err != nil {
+			return nil, fmt.Errorf("%q at position %d is not a number", lit.text, lit.pos+1)
+		}
+		c.value = d
+	case lit.kind == tokIdent && strings.EqualFold(lit.text, "true"):
+		c.value = true
+	case lit.kind == tokIdent && strings.EqualFold(lit.text, "false"):
+		c.value = false
+	case lit.kind == tokIdent && strings.EqualFold(lit.text, "null"):
+		c.value = nil
+	default:
+		return nil, fmt.Errorf("expected a value after %s %s at position %d, found %q (quote strings)", field.text, c.op, lit.pos+1, lit.text)
+	}
+
+	switch c.op {
+	case ">", ">=", "<", "<=":
+		if _, ok := c.value.(decimal.Decimal); !ok {
+			return nil, fmt.Errorf("%s %s needs a number", field.text, c.op)
+		}
+	case "contains":
+		if _, ok := c.value.(string); !ok {
+			return nil, fmt.Errorf("%s contains needs a quoted string", field.text)
+		}
+	}
+	return c, nil
+}
+
+func isKeyword(s string) bool {
+	return slices.ContainsFunc([]string{"AND", "OR", "NOT", "CONTAINS"}, func(k string) bool { return strings.EqualFold(s, k) })
+}
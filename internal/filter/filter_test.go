@@ -0,0 +1,149 @@
+package filter
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/luno/luno-go/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tx struct {
+	Kind         string            `json:"kind"`
+	BalanceDelta decimal.Decimal   `json:"balance_delta"`
+	RowIndex     int64             `json:"row_index"`
+	Description  string            `json:"description"`
+	Pending      bool              `json:"pending"`
+	Details      map[string]string `json:"details"`
+}
+
+func txs(t *testing.T) []tx {
+	t.Helper()
+	amount := func(s string) decimal.Decimal {
+		d, err := decimal.NewFromString(s)
+		require.NoError(t, err)
+		return d
+	}
+	return []tx{
+		{Kind: "TRANSFER", BalanceDelta: amount("1500.00"), RowIndex: 1, Description: "Received Bitcoin", Details: map[string]string{"txid": "abc"}},
+		{Kind: "FEE", BalanceDelta: amount("-2.50"), RowIndex: 2, Description: "Trading fee"},
+		{Kind: "EXCHANGE", BalanceDelta: amount("1000"), RowIndex: 3, Description: "Bought BTC", Pending: true},
+		{Kind: "TRANSFER", BalanceDelta: amount("-300"), RowIndex: 4, Description: "Sent Bitcoin"},
+	}
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter string
+		rows   []int64
+	}{
+		{name: "and", filter: "balance_delta > 1000 AND kind == 'transfer'", rows: []int64{1}},
+		{name: "exact decimal", filter: "balance_delta == 1500", rows: []int64{1}},
+		{name: "greater or equal", filter: "balance_delta >= 1000", rows: []int64{1, 3}},
+		{name: "negative", filter: "balance_delta < -2.5", rows: []int64{4}},
+		{name: "or", filter: `kind = "FEE" or row_index <= 1`, rows: []int64{1, 2}},
+		{name: "not and parentheses", filter: "NOT (kind == 'TRANSFER' || pending == true)", rows: []int64{2}},
+		{name: "and binds tighter than or", filter: "kind == 'FEE' OR kind == 'TRANSFER' AND balance_delta > 0", rows: []int64{1, 2}},
+		{name: "contains", filter: "description contains 'bitcoin'", rows: []int64{1, 4}},
+		{name: "nested", filter: "details.txid == 'abc'", rows: []int64{1}},
+		{name: "null", filter: "details.txid != null", rows: []int64{1}},
+		{name: "no match", filter: "row_index > 10", rows: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := Parse(tt.filter)
+			require.NoError(t, err)
+			got, err := Apply(e, txs(t))
+			require.NoError(t, err)
+			require.NotNil(t, got)
+			var rows []int64
+			for _, r := range got {
+				rows = append(rows, r.RowIndex)
+			}
+			assert.Equal(t, tt.rows, rows)
+		})
+	}
+}
+
+func TestApplyUnknownField(t *testing.T) {
+	e, err := Parse("amount > 1000")
+	require.NoError(t, err)
+	_, err = Apply(e, txs(t))
+	assert.ErrorContains(t, err, `the results have no field "amount"`)
+
+	// Nothing to check the field against
+	got, err := Apply(e, []tx{})
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		filter string
+		err    string
+	}{
+		{filter: " ", err: "the filter is empty"},
+		{filter: "kind == 'FEE", err: "unterminated string at position 9"},
+		{filter: "kind == FEE", err: `expected a value after kind == at position 9, found "FEE" (quote strings)`},
+		{filter: "kind 'FEE'", err: "expected an operator after kind"},
+		{filter: "kind > 'FEE'", err: "kind > needs a number"},
+		{filter: "row_index contains 1", err: "row_index contains needs a quoted string"},
+		{filter: "(kind == 'FEE'", err: "expected ) at position 15"},
+		{filter: "kind == 'FEE' row_index > 1", err: `unexpected "row_index" at position 15`},
+		{filter: "AND kind == 'FEE'", err: "expected a field name at position 1"},
+		{filter: "kind =< 1", err: `unexpected "=<" at position 6`},
+		{filter: "kind ~ 1", err: `unexpected "~" at position 6`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			_, err := Parse(tt.filter)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestSort(t *testing.T) {
+	rows := func(items []tx) []int64 {
+		var out []int64
+		for _, r := range items {
+			out = append(out, r.RowIndex)
+		}
+		return out
+	}
+
+	items := txs(t)
+	require.NoError(t, Sort(items, "balance_delta", true))
+	assert.Equal(t, []int64{1, 3, 2, 4}, rows(items))
+
+	require.NoError(t, Sort(items, "Kind", false))
+	assert.Equal(t, []int64{3, 2, 1, 4}, rows(items), "strings sort ignoring case and ties keep their order")
+
+	// Items without the field sort last either way
+	require.NoError(t, Sort(items, "details.txid", true))
+	assert.Equal(t, int64(1), rows(items)[0])
+	require.NoError(t, Sort(items, "details.txid", false))
+	assert.Equal(t, int64(1), rows(items)[0])
+
+	assert.ErrorContains(t, Sort(items, "amount", false), `the results have no field "amount" to sort by`)
+	assert.NoError(t, Sort([]tx{}, "amount", false))
+}
+
+func TestProject(t *testing.T) {
+	got, err := Project(txs(t)[:2], ParseFields(" row_index, Balance_Delta,,details.txid,row_index"))
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]any{
+		{"row_index": json.Number("1"), "balance_delta": "1500.00", "details": map[string]any{"txid": "abc"}},
+		{"row_index": json.Number("2"), "balance_delta": "-2.50"},
+	}, got)
+
+	_, err = Project(txs(t), []string{"row_index", "amount"})
+	assert.ErrorContains(t, err, `the results have no field "amount"`)
+
+	got, err = Project([]tx{}, []string{"amount"})
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
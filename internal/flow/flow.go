@@ -0,0 +1,188 @@
+// Package flow measures order flow from public trades: how much volume takers
+// bought and sold over recent windows, and how much of it came in large
+// trades. A trade's side is the taker's, so buy volume is volume that lifted
+// asks and sell volume is volume that hit bids.
+package flow
+
+import (
+	"cmp"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+)
+
+// Biases a window can show
+const (
+	Buy     = "buy"
+	Sell    = "sell"
+	Neutral = "neutral"
+)
+
+const (
+	// biasRatio is how far the buy ratio must be from even for a window to
+	// show a buy or sell bias
+	biasRatio = 0.1
+	// largeShare is one over the share of trades, by volume, at or above the
+	// automatic large trade threshold
+	largeShare = 10
+)
+
+// Params are what Analyze measures
+type Params struct {
+	// Windows are the lengths of the windows to measure, each ending now
+	Windows []time.Duration
+	// LargeVolume is the smallest volume of a large trade. Zero uses the
+	// volume of the largest tenth of the trades in the longest window.
+	LargeVolume decimal.Decimal
+	// CompleteSince is the time from which trades are known to be complete.
+	// Windows starting before it are marked incomplete.
+	CompleteSince time.Time
+}
+
+// Window is the order flow over one window
+type Window struct {
+	Window string    `json:"window"`
+	Since  time.Time `json:"since"`
+	Trades int       `json:"trades"`
+	// BuyVolume and SellVolume are the base volume bought and sold by takers,
+	// and NetVolume the difference
+	BuyVolume  decimal.Decimal `json:"buy_volume"`
+	SellVolume decimal.Decimal `json:"sell_volume"`
+	NetVolume  decimal.Decimal `json:"net_volume"`
+	// NetCounter is the counter value of the buy volume less the sell volume
+	NetCounter decimal.Decimal `json:"net_counter"`
+	// BuyRatio is the share of the volume bought, from 0 to 1, or nil without trades
+	BuyRatio *float64 `json:"buy_ratio,omitempty"`
+	Bias     string   `json:"bias"`
+	// LargeBuys and LargeSells count the trades of at least the large volume
+	LargeBuys      int             `json:"large_buys"`
+	LargeSells     int             `json:"large_sells"`
+	LargeNetVolume decimal.Decimal `json:"large_net_volume"`
+	// Complete is false when trades early in the window could not be fetched
+	Complete bool `json:"complete"`
+}
+
+// Result is the order flow over each window
+type Result struct {
+	// LargeVolume is the smallest volume counted as a large trade
+	LargeVolume decimal.Decimal `json:"large_volume"`
+	Windows     []Window        `json:"windows"`
+}
+
+// Analyze measures the order flow of trades over each window ending at now
+func Analyze(trades []luno.PublicTrade, now time.Time, p Params) (*Result, error) {
+	if len(p.Windows) == 0 {
+		return nil, errors.New("no windows to measure")
+	}
+	longest := slices.Max(p.Windows)
+
+	large := p.LargeVolume
+	if large.Sign() <= 0 {
+		large = largeVolume(trades, now.Add(-longest))
+	}
+
+	res := &Result{LargeVolume: large}
+	for _, w := range p.Windows {
+		res.Windows = append(res.Windows, window(trades, now, w, large, p.CompleteSince))
+	}
+	return res, nil
+}
+
+func window(trades []luno.PublicTrade, now time.Time, length time.Duration, large decimal.Decimal, completeSince time.Time) Window {
+	since := now.Add(-length)
+	w := Window{
+		Window:         label(length),
+		Since:          since.UTC(),
+		BuyVolume:      decimal.Zero(),
+		SellVolume:     decimal.Zero(),
+		NetCounter:     decimal.Zero(),
+		LargeNetVolume: decimal.Zero(),
+		Bias:           Neutral,
+		Complete:       !since.Before(completeSince),
+	}
+	for _, t := range trades {
+		ts := time.Time(t.Timestamp)
+		if ts.Before(since) || ts.After(now) {
+			continue
+		}
+		w.Trades++
+		isLarge := large.Sign() > 0 && t.Volume.Cmp(large) >= 0
+		counter := t.Volume.Mul(t.Price)
+		if t.IsBuy {
+			w.BuyVolume = w.BuyVolume.Add(t.Volume)
+			w.NetCounter = w.NetCounter.Add(counter)
+			if isLarge {
+				w.LargeBuys++
+				w.LargeNetVolume = w.LargeNetVolume.Add(t.Volume)
+			}
+		} else {
+			w.SellVolume = w.SellVolume.Add(t.Volume)
+			w.NetCounter = w.NetCounter.Sub(counter)
+			if isLarge {
+				w.LargeSells++
+				w.LargeNetVolume = w.LargeNetVolume.Sub(t.Volume)
+			}
+		}
+	}
+	w.NetVolume = w.BuyVolume.Sub(w.SellVolume)
+
+	total := w.BuyVolume.Add(w.SellVolume)
+	if total.Sign() > 0 {
+		ratio := w.BuyVolume.Float64() / total.Float64()
+		w.BuyRatio = &ratio
+		switch {
+		case ratio >= 0.5+biasRatio:
+			w.Bias = Buy
+		case ratio <= 0.5-biasRatio:
+			w.Bias = Sell
+		}
+	}
+	return w
+}
+
+// label formats a window length as it is usually written, e.g. 5m or 1h30m
+func label(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+// largeVolume returns the volume of the largest tenth of the trades since since
+func largeVolume(trades []luno.PublicTrade, since time.Time) decimal.Decimal {
+	var volumes []decimal.Decimal
+	for _, t := range trades {
+		if !time.Time(t.Timestamp).Before(since) {
+			volumes = append(volumes, t.Volume)
+		}
+	}
+	if len(volumes) == 0 {
+		return decimal.Zero()
+	}
+	slices.SortFunc(volumes, func(a, b decimal.Decimal) int { return a.Cmp(b) })
+	return volumes[len(volumes)-max(1, len(volumes)/largeShare)]
+}
+
+// Merge combines pages of trades, dropping repeats by sequence, newest first
+func Merge(pages ...[]luno.PublicTrade) []luno.PublicTrade {
+	seen := make(map[int64]bool)
+	var out []luno.PublicTrade
+	for _, page := range pages {
+		for _, t := range page {
+			if !seen[t.Sequence] {
+				seen[t.Sequence] = true
+				out = append(out, t)
+			}
+		}
+	}
+	slices.SortFunc(out, func(a, b luno.PublicTrade) int { return cmp.Compare(b.Sequence, a.Sequence) })
+	return out
+}
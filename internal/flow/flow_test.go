@@ -0,0 +1,102 @@
+package flow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+
+func dec(t *testing.T, s string) decimal.Decimal {
+	t.Helper()
+	d, err := decimal.NewFromString(s)
+	require.NoError(t, err)
+	return d
+}
+
+func trade(t *testing.T, seq int64, ago time.Duration, isBuy bool, volume string) luno.PublicTrade {
+	return luno.PublicTrade{Sequence: seq, Timestamp: luno.Time(now.Add(-ago)), IsBuy: isBuy, Price: dec(t, "100"), Volume: dec(t, volume)}
+}
+
+func TestAnalyze(t *testing.T) {
+	trades := []luno.PublicTrade{
+		trade(t, 6, time.Minute, true, "2"),
+		trade(t, 5, 2*time.Minute, true, "1"),
+		trade(t, 4, 3*time.Minute, false, "1"),
+		trade(t, 3, 10*time.Minute, false, "5"),
+		trade(t, 2, 20*time.Minute, false, "1"),
+		trade(t, 1, 2*time.Hour, true, "9"),
+	}
+
+	res, err := Analyze(trades, now, Params{Windows: []time.Duration{5 * time.Minute, time.Hour}, LargeVolume: dec(t, "2")})
+	require.NoError(t, err)
+	assert.Equal(t, "2", res.LargeVolume.String())
+	require.Len(t, res.Windows, 2)
+
+	short := res.Windows[0]
+	assert.Equal(t, "5m", short.Window)
+	assert.Equal(t, now.Add(-5*time.Minute), short.Since)
+	assert.Equal(t, 3, short.Trades)
+	assert.Equal(t, "3", short.BuyVolume.String())
+	assert.Equal(t, "1", short.SellVolume.String())
+	assert.Equal(t, "2", short.NetVolume.String())
+	assert.Equal(t, "200", short.NetCounter.String())
+	require.NotNil(t, short.BuyRatio)
+	assert.InDelta(t, 0.75, *short.BuyRatio, 1e-9)
+	assert.Equal(t, Buy, short.Bias)
+	assert.Equal(t, 1, short.LargeBuys)
+	assert.Equal(t, 0, short.LargeSells)
+	assert.Equal(t, "2", short.LargeNetVolume.String())
+	assert.True(t, short.Complete)
+
+	hour := res.Windows[1]
+	assert.Equal(t, "1h", hour.Window)
+	assert.Equal(t, 5, hour.Trades, "trades before the window are left out")
+	assert.Equal(t, "-4", hour.NetVolume.String())
+	assert.InDelta(t, 3.0/10, *hour.BuyRatio, 1e-9)
+	assert.Equal(t, Sell, hour.Bias)
+	assert.Equal(t, 1, hour.LargeSells)
+	assert.Equal(t, "-3", hour.LargeNetVolume.String())
+}
+
+func TestAnalyzeDefaults(t *testing.T) {
+	var trades []luno.PublicTrade
+	for i := range 10 {
+		trades = append(trades, trade(t, int64(i+1), time.Duration(i+1)*time.Minute, i%2 == 0, "1"))
+	}
+	trades = append(trades, trade(t, 11, 30*time.Second, false, "10"))
+
+	res, err := Analyze(trades, now, Params{Windows: []time.Duration{90 * time.Minute}, CompleteSince: now.Add(-time.Hour)})
+	require.NoError(t, err)
+	assert.Equal(t, "10", res.LargeVolume.String(), "the largest tenth of the trades are large")
+	w := res.Windows[0]
+	assert.Equal(t, "1h30m", w.Window)
+	assert.Equal(t, 1, w.LargeSells)
+	assert.False(t, w.Complete)
+
+	res, err = Analyze(nil, now, Params{Windows: []time.Duration{time.Minute}})
+	require.NoError(t, err)
+	assert.Equal(t, Neutral, res.Windows[0].Bias)
+	assert.Nil(t, res.Windows[0].BuyRatio)
+	assert.Equal(t, 0, res.Windows[0].LargeBuys)
+
+	_, err = Analyze(trades, now, Params{})
+	assert.Error(t, err)
+}
+
+func TestMerge(t *testing.T) {
+	got := Merge(
+		[]luno.PublicTrade{trade(t, 5, 0, true, "1"), trade(t, 4, 0, true, "1")},
+		[]luno.PublicTrade{trade(t, 1, 0, true, "1"), trade(t, 4, 0, true, "1"), trade(t, 3, 0, true, "1")},
+	)
+	var seqs []int64
+	for _, tr := range got {
+		seqs = append(seqs, tr.Sequence)
+	}
+	assert.Equal(t, []int64{5, 4, 3, 1}, seqs)
+}
@@ -0,0 +1,94 @@
+// Package fsutil keeps the files the server persists in the same places and
+// writes them the same way on every OS an MCP client may run it on. Files live
+// in the user's config or cache directory as the OS defines it, such as
+// ~/.config on Linux, ~/Library/Application Support on macOS and %AppData% on
+// Windows, and are replaced atomically so that a crash never leaves one half
+// written.
+package fsutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// AppDir is the directory the server's files are kept in within the user's
+// config and cache directories
+const AppDir = "luno-mcp"
+
+// renameRetries is how many times replacing a file is retried while another
+// process has it open, which Windows does not allow
+const renameRetries = 10
+
+// Replaced in tests
+var (
+	rename      = os.Rename
+	isRetryable = retryable
+)
+
+// ConfigPath returns name in the server's directory in the user's config
+// directory, or an empty string if the user has none
+func ConfigPath(name string) string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, AppDir, name)
+}
+
+// CachePath returns name in the server's directory in the user's cache
+// directory, or an empty string if the user has none
+func CachePath(name string) string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, AppDir, name)
+}
+
+// WriteFile replaces the file at path with data, creating its directory if
+// needed. The data is written to a temporary file in the same directory and
+// renamed over path, so readers see the old file or the new one, never part
+// of it.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	// Windows can't rename an open file
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return replace(tmp.Name(), path)
+}
+
+// replace renames oldpath to newpath, retrying while newpath is open in
+// another process, such as a virus scanner or another server reading it
+func replace(oldpath, newpath string) error {
+	var err error
+	for i := range renameRetries {
+		if err = rename(oldpath, newpath); err == nil || !isRetryable(err) {
+			return err
+		}
+		time.Sleep(time.Duration(i+1) * 10 * time.Millisecond)
+	}
+	return fmt.Errorf("%w (still in use after %d attempts)", err, renameRetries)
+}
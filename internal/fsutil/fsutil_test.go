@@ -0,0 +1,79 @@
+package fsutil
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "orders.json")
+
+	require.NoError(t, WriteFile(path, []byte("[]\n"), 0o600))
+	require.NoError(t, WriteFile(path, []byte(`[{"id":"1"}]`+"\n"), 0o600))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `[{"id":"1"}]`+"\n", string(data))
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "temporary files are removed")
+}
+
+func TestReplaceRetries(t *testing.T) {
+	errInUse := errors.New("in use")
+	tests := []struct {
+		name          string
+		failures      int
+		err           error
+		expectedCalls int
+		expectedError string
+	}{
+		{name: "in use briefly", failures: 2, err: errInUse, expectedCalls: 3},
+		{name: "still in use", failures: renameRetries, err: errInUse, expectedCalls: renameRetries, expectedError: "in use (still in use after 10 attempts)"},
+		{name: "other error", failures: 1, err: os.ErrPermission, expectedCalls: 1, expectedError: os.ErrPermission.Error()},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			rename = func(string, string) error {
+				calls++
+				if calls <= tc.failures {
+					return tc.err
+				}
+				return nil
+			}
+			isRetryable = func(err error) bool { return errors.Is(err, errInUse) }
+			t.Cleanup(func() { rename, isRetryable = os.Rename, retryable })
+
+			err := replace("old", "new")
+			assert.Equal(t, tc.expectedCalls, calls)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestConfigPath(t *testing.T) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		assert.Empty(t, ConfigPath("orders.json"))
+		return
+	}
+	assert.Equal(t, filepath.Join(dir, AppDir, "orders.json"), ConfigPath("orders.json"))
+}
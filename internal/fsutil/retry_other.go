@@ -0,0 +1,9 @@
+//go:build !windows
+
+package fsutil
+
+// retryable reports whether err is from a file being in use. Other systems
+// let a file be replaced while it is open.
+func retryable(error) bool {
+	return false
+}
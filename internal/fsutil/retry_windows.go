@@ -0,0 +1,21 @@
+package fsutil
+
+import (
+	"errors"
+	"syscall"
+)
+
+// Errors for a file another process has open without sharing it. They clear
+// once the other process closes the file.
+const (
+	errorAccessDenied     syscall.Errno = 5
+	errorSharingViolation syscall.Errno = 32
+	errorLockViolation    syscall.Errno = 33
+)
+
+// retryable reports whether err is from a file being in use
+func retryable(err error) bool {
+	return errors.Is(err, errorAccessDenied) ||
+		errors.Is(err, errorSharingViolation) ||
+		errors.Is(err, errorLockViolation)
+}
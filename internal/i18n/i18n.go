@@ -0,0 +1,124 @@
+// Package i18n translates the text the server shows MCP clients: tool
+// descriptions, error messages and the notes in tool results. The source text
+// is English and doubles as the key into each locale's catalog, so anything
+// not translated yet is shown in English.
+//
+// To add a locale, add a catalog file mapping English text to its translation
+// and list it in catalogs.
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Locale is a language the server can show text in, as an ISO 639-1 code
+type Locale string
+
+const (
+	// English is the language of the source text, for Luno's markets in South
+	// Africa and Nigeria among others
+	English Locale = "en"
+	// Indonesian is Bahasa Indonesia, for Luno's market in Indonesia
+	Indonesian Locale = "id"
+	// Malay is Bahasa Melayu, for Luno's market in Malaysia
+	Malay Locale = "ms"
+
+	// Default is used when no locale is configured
+	Default = English
+)
+
+// catalogs holds the translations of each locale, keyed by the English text
+var catalogs = map[Locale]map[string]string{
+	English:    {},
+	Indonesian: indonesian,
+	Malay:      malay,
+}
+
+// messageSeparator separates the parts of error messages such as
+// "Failed to get balances: <error from Luno>"
+const messageSeparator = ": "
+
+// Locales lists the supported locales
+func Locales() []Locale {
+	return slices.Sorted(maps.Keys(catalogs))
+}
+
+// Parse returns the locale for s, an ISO 639-1 language code optionally
+// followed by a region such as "ms-MY" or "id_ID". An empty s is the default.
+func Parse(s string) (Locale, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Default, nil
+	}
+	lang, _, _ := strings.Cut(strings.ReplaceAll(s, "_", "-"), "-")
+	l := Locale(strings.ToLower(lang))
+	if _, ok := catalogs[l]; !ok {
+		names := make([]string, 0, len(catalogs))
+		for _, l := range Locales() {
+			names = append(names, string(l))
+		}
+		return "", fmt.Errorf("unsupported locale %q: must be one of %s", s, strings.Join(names, ", "))
+	}
+	return l, nil
+}
+
+// Keys lists the English text l has translations for, so that tests can check
+// catalogs against the text they translate
+func Keys(l Locale) []string {
+	return slices.Sorted(maps.Keys(catalogs[l]))
+}
+
+// Translate returns the translation of s, or s when l has none
+func (l Locale) Translate(s string) string {
+	if t, ok := catalogs[l][s]; ok {
+		return t
+	}
+	return s
+}
+
+// TranslateMessage translates an error message part by part, so that the
+// fixed parts of messages such as "Failed to get balances: <error>" are
+// translated while errors from Luno are kept as they are
+func (l Locale) TranslateMessage(s string) string {
+	if len(catalogs[l]) == 0 {
+		return s
+	}
+	if t, ok := catalogs[l][s]; ok {
+		return t
+	}
+	parts := strings.Split(s, messageSeparator)
+	for i, p := range parts {
+		parts[i] = l.Translate(p)
+	}
+	return strings.Join(parts, messageSeparator)
+}
+
+// TranslateJSON translates the strings in a JSON document that are exactly the
+// English text of a translation, such as the fixed notes in tool results. The
+// document is otherwise left as it is, keeping its layout and field order.
+func (l Locale) TranslateJSON(doc string) string {
+	catalog := catalogs[l]
+	if len(catalog) == 0 {
+		return doc
+	}
+	var pairs []string
+	for en, t := range catalog {
+		quoted, err := json.Marshal(en)
+		if err != nil || !strings.Contains(doc, string(quoted)) {
+			continue
+		}
+		translated, err := json.Marshal(t)
+		if err != nil {
+			continue
+		}
+		pairs = append(pairs, string(quoted), string(translated))
+	}
+	if len(pairs) == 0 {
+		return doc
+	}
+	return strings.NewReplacer(pairs...).Replace(doc)
+}
@@ -0,0 +1,105 @@
+package i18n
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		expected      Locale
+		errorContains string
+	}{
+		{name: "empty is the default", input: "", expected: English},
+		{name: "language code", input: "id", expected: Indonesian},
+		{name: "language and region", input: "ms-MY", expected: Malay},
+		{name: "posix style", input: "id_ID", expected: Indonesian},
+		{name: "upper case", input: " EN ", expected: English},
+		{name: "unsupported", input: "fr-FR", errorContains: `unsupported locale "fr-FR": must be one of en, id, ms`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			if tt.errorContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	assert.Equal(t, "Pasangan perdagangan (mis., XBTZAR)", Indonesian.Translate("Trading pair (e.g., XBTZAR)"))
+	assert.Equal(t, "Pasangan dagangan (cth., XBTZAR)", Malay.Translate("Trading pair (e.g., XBTZAR)"))
+	assert.Equal(t, "Trading pair (e.g., XBTZAR)", English.Translate("Trading pair (e.g., XBTZAR)"))
+	assert.Equal(t, "Not in any catalog", Malay.Translate("Not in any catalog"))
+	assert.Equal(t, "Trading pair (e.g., XBTZAR)", Locale("fr").Translate("Trading pair (e.g., XBTZAR)"))
+}
+
+func TestTranslateMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		locale   Locale
+		input    string
+		expected string
+	}{
+		{
+			name:     "whole message",
+			locale:   Indonesian,
+			input:    "Trading pair is required",
+			expected: "Pasangan perdagangan wajib diisi",
+		},
+		{
+			name:     "error from Luno kept",
+			locale:   Malay,
+			input:    "Failed to get balances: api error ErrUnauthorised: unauthorised",
+			expected: "Gagal mendapatkan baki: api error ErrUnauthorised: unauthorised",
+		},
+		{
+			name:     "fixed detail translated",
+			locale:   Indonesian,
+			input:    "Unable to create order for DOGEZAR: unknown market, use get_markets_info to list valid pairs",
+			expected: "Unable to create order for DOGEZAR: pasar tidak dikenal, gunakan get_markets_info untuk melihat pasangan yang valid",
+		},
+		{
+			name:     "english unchanged",
+			locale:   English,
+			input:    "Failed to get balances: boom",
+			expected: "Failed to get balances: boom",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.locale.TranslateMessage(tt.input))
+		})
+	}
+}
+
+func TestTranslateJSON(t *testing.T) {
+	doc := "{\n  \"pair\": \"XBTZAR\",\n  \"notes\": [\n    \"Trading pair is required\",\n    \"Trading pair is required, or not\"\n  ]\n}"
+	assert.Equal(t,
+		"{\n  \"pair\": \"XBTZAR\",\n  \"notes\": [\n    \"Pasangan dagangan diperlukan\",\n    \"Trading pair is required, or not\"\n  ]\n}",
+		Malay.TranslateJSON(doc))
+	assert.Equal(t, doc, English.TranslateJSON(doc))
+}
+
+func TestCatalogs(t *testing.T) {
+	assert.Equal(t, []Locale{English, Indonesian, Malay}, Locales())
+	assert.Empty(t, Keys(English))
+	for _, l := range Locales() {
+		for en, translated := range catalogs[l] {
+			assert.NotEmpty(t, translated, "%s: %q", l, en)
+		}
+	}
+	// Locales translate the same text, so none lags behind the others
+	assert.Equal(t, Keys(Indonesian), Keys(Malay))
+}
@@ -0,0 +1,53 @@
+package i18n
+
+// indonesian translates into Bahasa Indonesia
+var indonesian = map[string]string{
+	// Errors
+	"API credentials are required for this operation. Please set LUNO_API_KEY_ID and LUNO_API_SECRET environment variables.":                                                                      "Operasi ini memerlukan kredensial API. Atur variabel lingkungan LUNO_API_KEY_ID dan LUNO_API_SECRET.",
+	"Write operations are disabled. To enable, restart the server with the --allow-write-operations flag or set the ALLOW_WRITE_OPERATIONS=true environment variable.":                            "Operasi tulis dinonaktifkan. Untuk mengaktifkannya, jalankan ulang server dengan flag --allow-write-operations atau atur variabel lingkungan ALLOW_WRITE_OPERATIONS=true.",
+	"Withdrawals are disabled. To enable, restart the server with both the --allow-write-operations and --allow-withdrawals flags or set ALLOW_WRITE_OPERATIONS=true and ALLOW_WITHDRAWALS=true.": "Penarikan dinonaktifkan. Untuk mengaktifkannya, jalankan ulang server dengan flag --allow-write-operations dan --allow-withdrawals atau atur ALLOW_WRITE_OPERATIONS=true dan ALLOW_WITHDRAWALS=true.",
+	"Trading pair is required":                                 "Pasangan perdagangan wajib diisi",
+	"unknown market, use get_markets_info to list valid pairs": "pasar tidak dikenal, gunakan get_markets_info untuk melihat pasangan yang valid",
+	"Failed to get balances":                                   "Gagal mengambil saldo",
+	"Unable to create order":                                   "Tidak dapat membuat order",
+	"Invalid volume format":                                    "Format volume tidak valid",
+	"Invalid price format":                                     "Format harga tidak valid",
+	"Order type must be 'BUY' or 'SELL'":                       "Jenis order harus 'BUY' atau 'SELL'",
+	"side must be 'BUY' or 'SELL'":                             "side harus 'BUY' atau 'SELL'",
+	"The trade journal is not available on this server":        "Jurnal perdagangan tidak tersedia di server ini",
+	"Report scheduling is not available on this server":        "Penjadwalan laporan tidak tersedia di server ini",
+
+	// Notices and notes
+	" (currently unavailable: no API credentials)": " (saat ini tidak tersedia: tidak ada kredensial API)",
+	"The Luno API does not expose the account's verification level, country or referral details; the user can find them in the Luno app under Profile. Luno opens an account for each currency it offers in the user's country, so a market whose currencies have no account is most likely not available to this user.": "API Luno tidak menampilkan tingkat verifikasi, negara, atau detail referral akun; pengguna dapat melihatnya di aplikasi Luno pada menu Profil. Luno membuka akun untuk setiap mata uang yang tersedia di negara pengguna, sehingga pasar yang mata uangnya tidak memiliki akun kemungkinan besar tidak tersedia bagi pengguna ini.",
+
+	// Parameters
+	"Trading pair (e.g., XBTZAR)":                            "Pasangan perdagangan (mis., XBTZAR)",
+	"Order type (BUY or SELL)":                               "Jenis order (BUY atau SELL)",
+	"Trade direction (BUY or SELL)":                          "Arah perdagangan (BUY atau SELL)",
+	"Limit price as a decimal string":                        "Harga limit sebagai string desimal",
+	"Order volume (amount of cryptocurrency to buy or sell)": "Volume order (jumlah kripto yang dibeli atau dijual)",
+	"Order ID to cancel":                                     "ID order yang akan dibatalkan",
+	"Set to true to place the order after the user has reviewed the preview (default: false)":                    "Atur ke true untuk menempatkan order setelah pengguna meninjau pratinjau (bawaan: false)",
+	"Account ID. An alias set with alias_account, an asset code such as XBT or an account name is also accepted": "ID akun. Alias yang diatur dengan alias_account, kode aset seperti XBT, atau nama akun juga diterima",
+	"Asset of the account, e.g. XBT, when account_id is not known":                                               "Aset akun, mis. XBT, jika account_id tidak diketahui",
+	"Candle duration in seconds (e.g., 60 for 1m, 300 for 5m, 3600 for 1h)":                                      "Durasi candle dalam detik (mis., 60 untuk 1m, 300 untuk 5m, 3600 untuk 1j)",
+	"Currency to value the balances in, e.g. ZAR or USDC":                                                        "Mata uang untuk menilai saldo, mis. ZAR atau USDC",
+
+	// Tools
+	"Get ticker information for a trading pair": "Ambil informasi ticker untuk sebuah pasangan perdagangan",
+	"List tickers for all currency pairs. Unknown pairs are reported in an errors section instead of failing the whole request.": "Tampilkan ticker untuk semua pasangan mata uang. Pasangan yang tidak dikenal dilaporkan di bagian errors alih-alih menggagalkan seluruh permintaan.",
+	"Get order book for a trading pair":                "Ambil order book untuk sebuah pasangan perdagangan",
+	"Get candlestick market data for a currency pair":  "Ambil data pasar candlestick untuk sebuah pasangan mata uang",
+	"List recent trades for a currency pair":           "Tampilkan perdagangan terbaru untuk sebuah pasangan mata uang",
+	"List all supported markets parameter information": "Tampilkan informasi parameter semua pasar yang didukung",
+	"Get balances for all Luno accounts. Set convert_to to also value each balance in one currency at the latest trade prices, with a grand total.":                                                                                                                          "Ambil saldo semua akun Luno. Atur convert_to untuk juga menilai setiap saldo dalam satu mata uang dengan harga perdagangan terakhir, beserta total keseluruhan.",
+	"Get what the account can do: the currencies it holds accounts in, which markets it can trade and why not, its trading fees on a market, and whether this server allows orders and withdrawals. Use this to explain why a market or feature is unavailable to the user.": "Ambil apa yang dapat dilakukan akun: mata uang yang memiliki akun, pasar mana yang dapat diperdagangkan dan alasannya jika tidak, biaya perdagangannya di suatu pasar, serta apakah server ini mengizinkan order dan penarikan. Gunakan ini untuk menjelaskan mengapa suatu pasar atau fitur tidak tersedia bagi pengguna.",
+	"Check whether the Luno API is healthy and which markets are active, post-only or disabled. Use this to explain why orders are being rejected, e.g. during maintenance windows.":                                                                                         "Periksa apakah API Luno berjalan normal dan pasar mana yang aktif, post-only, atau dinonaktifkan. Gunakan ini untuk menjelaskan mengapa order ditolak, mis. selama masa pemeliharaan.",
+	"List orders, newest first, with a summary of open order exposure, each open order's distance from the market price and any trade journal notes. Use state to review open or historical orders and next_created_before from the result to fetch the next page.":          "Tampilkan order, yang terbaru lebih dulu, dengan ringkasan eksposur order terbuka, jarak setiap order terbuka dari harga pasar, dan catatan jurnal perdagangan. Gunakan state untuk meninjau order terbuka atau riwayat, dan next_created_before dari hasil untuk mengambil halaman berikutnya.",
+	"List transactions for an account, given by account ID, asset or account name":                                                                                              "Tampilkan transaksi sebuah akun, berdasarkan ID akun, aset, atau nama akun",
+	"Get details of a specific transaction in an account, given by account ID, asset or account name":                                                                           "Ambil detail transaksi tertentu dalam sebuah akun, berdasarkan ID akun, aset, atau nama akun",
+	"Create a new limit order. This is a write operation that must be explicitly enabled via the --allow-write-operations flag or ALLOW_WRITE_OPERATIONS environment variable.": "Buat order limit baru. Ini adalah operasi tulis yang harus diaktifkan secara eksplisit melalui flag --allow-write-operations atau variabel lingkungan ALLOW_WRITE_OPERATIONS.",
+	"Cancel an order. This is a write operation that must be explicitly enabled via the --allow-write-operations flag or ALLOW_WRITE_OPERATIONS environment variable.":          "Batalkan sebuah order. Ini adalah operasi tulis yang harus diaktifkan secara eksplisit melalui flag --allow-write-operations atau variabel lingkungan ALLOW_WRITE_OPERATIONS.",
+	"Get the next part of a tool result that was truncated for being too large. Truncated results end with a note giving the cursor to pass here.":                              "Ambil bagian berikutnya dari hasil tool yang dipotong karena terlalu besar. Hasil yang dipotong diakhiri dengan catatan berisi cursor yang diteruskan ke sini.",
+}
@@ -0,0 +1,53 @@
+package i18n
+
+// malay translates into Bahasa Melayu
+var malay = map[string]string{
+	// Errors
+	"API credentials are required for this operation. Please set LUNO_API_KEY_ID and LUNO_API_SECRET environment variables.":                                                                      "Operasi ini memerlukan kelayakan API. Sila tetapkan pemboleh ubah persekitaran LUNO_API_KEY_ID dan LUNO_API_SECRET.",
+	"Write operations are disabled. To enable, restart the server with the --allow-write-operations flag or set the ALLOW_WRITE_OPERATIONS=true environment variable.":                            "Operasi tulis dilumpuhkan. Untuk mendayakannya, mulakan semula pelayan dengan bendera --allow-write-operations atau tetapkan pemboleh ubah persekitaran ALLOW_WRITE_OPERATIONS=true.",
+	"Withdrawals are disabled. To enable, restart the server with both the --allow-write-operations and --allow-withdrawals flags or set ALLOW_WRITE_OPERATIONS=true and ALLOW_WITHDRAWALS=true.": "Pengeluaran dilumpuhkan. Untuk mendayakannya, mulakan semula pelayan dengan bendera --allow-write-operations dan --allow-withdrawals atau tetapkan ALLOW_WRITE_OPERATIONS=true dan ALLOW_WITHDRAWALS=true.",
+	"Trading pair is required":                                 "Pasangan dagangan diperlukan",
+	"unknown market, use get_markets_info to list valid pairs": "pasaran tidak diketahui, gunakan get_markets_info untuk menyenaraikan pasangan yang sah",
+	"Failed to get balances":                                   "Gagal mendapatkan baki",
+	"Unable to create order":                                   "Tidak dapat membuat pesanan",
+	"Invalid volume format":                                    "Format volum tidak sah",
+	"Invalid price format":                                     "Format harga tidak sah",
+	"Order type must be 'BUY' or 'SELL'":                       "Jenis pesanan mestilah 'BUY' atau 'SELL'",
+	"side must be 'BUY' or 'SELL'":                             "side mestilah 'BUY' atau 'SELL'",
+	"The trade journal is not available on this server":        "Jurnal dagangan tidak tersedia pada pelayan ini",
+	"Report scheduling is not available on this server":        "Penjadualan laporan tidak tersedia pada pelayan ini",
+
+	// Notices and notes
+	" (currently unavailable: no API credentials)": " (tidak tersedia buat masa ini: tiada kelayakan API)",
+	"The Luno API does not expose the account's verification level, country or referral details; the user can find them in the Luno app under Profile. Luno opens an account for each currency it offers in the user's country, so a market whose currencies have no account is most likely not available to this user.": "API Luno tidak mendedahkan tahap pengesahan, negara atau butiran rujukan akaun; pengguna boleh mendapatkannya dalam aplikasi Luno di bawah Profil. Luno membuka akaun bagi setiap mata wang yang ditawarkan di negara pengguna, jadi pasaran yang mata wangnya tiada akaun berkemungkinan besar tidak tersedia untuk pengguna ini.",
+
+	// Parameters
+	"Trading pair (e.g., XBTZAR)":                            "Pasangan dagangan (cth., XBTZAR)",
+	"Order type (BUY or SELL)":                               "Jenis pesanan (BUY atau SELL)",
+	"Trade direction (BUY or SELL)":                          "Arah dagangan (BUY atau SELL)",
+	"Limit price as a decimal string":                        "Harga had sebagai rentetan perpuluhan",
+	"Order volume (amount of cryptocurrency to buy or sell)": "Volum pesanan (jumlah mata wang kripto untuk dibeli atau dijual)",
+	"Order ID to cancel":                                     "ID pesanan untuk dibatalkan",
+	"Set to true to place the order after the user has reviewed the preview (default: false)":                    "Tetapkan kepada true untuk membuat pesanan selepas pengguna menyemak pratonton (lalai: false)",
+	"Account ID. An alias set with alias_account, an asset code such as XBT or an account name is also accepted": "ID akaun. Alias yang ditetapkan dengan alias_account, kod aset seperti XBT atau nama akaun juga diterima",
+	"Asset of the account, e.g. XBT, when account_id is not known":                                               "Aset akaun, cth. XBT, apabila account_id tidak diketahui",
+	"Candle duration in seconds (e.g., 60 for 1m, 300 for 5m, 3600 for 1h)":                                      "Tempoh lilin dalam saat (cth., 60 untuk 1m, 300 untuk 5m, 3600 untuk 1j)",
+	"Currency to value the balances in, e.g. ZAR or USDC":                                                        "Mata wang untuk menilai baki, cth. ZAR atau USDC",
+
+	// Tools
+	"Get ticker information for a trading pair": "Dapatkan maklumat ticker bagi pasangan dagangan",
+	"List tickers for all currency pairs. Unknown pairs are reported in an errors section instead of failing the whole request.": "Senaraikan ticker bagi semua pasangan mata wang. Pasangan yang tidak diketahui dilaporkan dalam bahagian errors dan bukannya menggagalkan keseluruhan permintaan.",
+	"Get order book for a trading pair":                "Dapatkan buku pesanan bagi pasangan dagangan",
+	"Get candlestick market data for a currency pair":  "Dapatkan data pasaran carta lilin bagi pasangan mata wang",
+	"List recent trades for a currency pair":           "Senaraikan dagangan terkini bagi pasangan mata wang",
+	"List all supported markets parameter information": "Senaraikan maklumat parameter semua pasaran yang disokong",
+	"Get balances for all Luno accounts. Set convert_to to also value each balance in one currency at the latest trade prices, with a grand total.":                                                                                                                          "Dapatkan baki semua akaun Luno. Tetapkan convert_to untuk turut menilai setiap baki dalam satu mata wang pada harga dagangan terkini, berserta jumlah keseluruhan.",
+	"Get what the account can do: the currencies it holds accounts in, which markets it can trade and why not, its trading fees on a market, and whether this server allows orders and withdrawals. Use this to explain why a market or feature is unavailable to the user.": "Dapatkan perkara yang boleh dilakukan oleh akaun: mata wang yang mempunyai akaun, pasaran yang boleh didagangkan dan sebabnya jika tidak, yuran dagangannya pada sesuatu pasaran, dan sama ada pelayan ini membenarkan pesanan dan pengeluaran. Gunakan ini untuk menerangkan sebab sesuatu pasaran atau ciri tidak tersedia kepada pengguna.",
+	"Check whether the Luno API is healthy and which markets are active, post-only or disabled. Use this to explain why orders are being rejected, e.g. during maintenance windows.":                                                                                         "Semak sama ada API Luno berfungsi dengan baik dan pasaran mana yang aktif, post-only atau dilumpuhkan. Gunakan ini untuk menerangkan sebab pesanan ditolak, cth. semasa tempoh penyelenggaraan.",
+	"List orders, newest first, with a summary of open order exposure, each open order's distance from the market price and any trade journal notes. Use state to review open or historical orders and next_created_before from the result to fetch the next page.":          "Senaraikan pesanan, yang terbaharu dahulu, dengan ringkasan pendedahan pesanan terbuka, jarak setiap pesanan terbuka dari harga pasaran dan sebarang nota jurnal dagangan. Gunakan state untuk menyemak pesanan terbuka atau sejarah, dan next_created_before daripada hasil untuk mendapatkan halaman seterusnya.",
+	"List transactions for an account, given by account ID, asset or account name":                                                                                              "Senaraikan transaksi bagi sesuatu akaun, mengikut ID akaun, aset atau nama akaun",
+	"Get details of a specific transaction in an account, given by account ID, asset or account name":                                                                           "Dapatkan butiran transaksi tertentu dalam sesuatu akaun, mengikut ID akaun, aset atau nama akaun",
+	"Create a new limit order. This is a write operation that must be explicitly enabled via the --allow-write-operations flag or ALLOW_WRITE_OPERATIONS environment variable.": "Buat pesanan had baharu. Ini ialah operasi tulis yang mesti didayakan secara eksplisit melalui bendera --allow-write-operations atau pemboleh ubah persekitaran ALLOW_WRITE_OPERATIONS.",
+	"Cancel an order. This is a write operation that must be explicitly enabled via the --allow-write-operations flag or ALLOW_WRITE_OPERATIONS environment variable.":          "Batalkan pesanan. Ini ialah operasi tulis yang mesti didayakan secara eksplisit melalui bendera --allow-write-operations atau pemboleh ubah persekitaran ALLOW_WRITE_OPERATIONS.",
+	"Get the next part of a tool result that was truncated for being too large. Truncated results end with a note giving the cursor to pass here.":                              "Dapatkan bahagian seterusnya bagi hasil tool yang dipotong kerana terlalu besar. Hasil yang dipotong berakhir dengan nota yang memberikan cursor untuk dihantar ke sini.",
+}
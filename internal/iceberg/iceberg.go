@@ -0,0 +1,463 @@
+// Package iceberg works large limit orders while only showing part of them on
+// the order book. A visible slice is placed at the order's price and replaced
+// with the next slice each time it fills, until the whole volume has traded.
+package iceberg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/internal/markets"
+	"github.com/luno/luno-mcp/sdk"
+)
+
+// PollInterval is how often the visible slice is checked for fills
+const PollInterval = 5 * time.Second
+
+// settleTimeout bounds cancelling the visible slice once an order is stopped
+const settleTimeout = 30 * time.Second
+
+// State is the state of an iceberg order
+type State string
+
+const (
+	StateRunning   State = "running"
+	StateCompleted State = "completed"
+	StateCancelled State = "cancelled"
+	StateFailed    State = "failed"
+)
+
+// Plan describes an iceberg order
+type Plan struct {
+	Market luno.MarketInfo
+	// Side is luno.OrderTypeBid to buy or luno.OrderTypeAsk to sell
+	Side   luno.OrderType
+	Volume decimal.Decimal
+	Price  decimal.Decimal
+	// VisibleVolume is the most shown on the order book at once
+	VisibleVolume decimal.Decimal
+	// Tenant owns the order in multi-tenant deployments. Only calls for the
+	// same tenant see or cancel it.
+	Tenant string
+	// Allowed reports whether slices may be placed at a time, such as
+	// tradinghours.Policy.Allowed. The order pauses while it is false. Nil
+	// allows any time.
+	Allowed func(time.Time) bool
+}
+
+// Validate checks that every slice of p is an order the market accepts
+func (p Plan) Validate() error {
+	if p.Side != luno.OrderTypeBid && p.Side != luno.OrderTypeAsk {
+		return fmt.Errorf("invalid side %q", p.Side)
+	}
+	if p.Volume.Sign() <= 0 {
+		return errors.New("volume must be positive")
+	}
+	if p.Price.Sign() <= 0 {
+		return errors.New("price must be positive")
+	}
+	if p.VisibleVolume.Sign() <= 0 || p.VisibleVolume.Cmp(p.Volume) >= 0 {
+		return errors.New("visible volume must be positive and less than the volume")
+	}
+	if err := markets.CheckScales(p.Market, p.Volume, p.Price); err != nil {
+		return err
+	}
+	// Every slice shows the visible volume, except the last which shows what
+	// remains
+	err := markets.CheckOrder(p.Market, p.VisibleVolume, p.Price)
+	if errors.Is(err, markets.ErrVolumeBelowMinimum) || errors.Is(err, markets.ErrVolumeAboveMaximum) {
+		return fmt.Errorf("visible volume: %w", err)
+	}
+	return err
+}
+
+// Slice is one visible order placed for an iceberg order
+type Slice struct {
+	OrderID  string          `json:"order_id"`
+	Volume   decimal.Decimal `json:"volume"`
+	PlacedAt time.Time       `json:"placed_at"`
+	State    luno.OrderState `json:"state"`
+	// Filled and FilledCounter are the base volume traded and the counter
+	// amount it cost or raised
+	Filled        decimal.Decimal `json:"filled"`
+	FilledCounter decimal.Decimal `json:"filled_counter"`
+}
+
+// AuditEntry records something an iceberg order did
+type AuditEntry struct {
+	Time    time.Time `json:"time"`
+	Message string    `json:"message"`
+}
+
+// Order is the progress of an iceberg order
+type Order struct {
+	ID            string          `json:"id"`
+	Pair          string          `json:"pair"`
+	Side          luno.OrderType  `json:"side"`
+	Volume        decimal.Decimal `json:"volume"`
+	VisibleVolume decimal.Decimal `json:"visible_volume"`
+	Price         decimal.Decimal `json:"price"`
+	State         State           `json:"state"`
+	StartedAt     time.Time       `json:"started_at"`
+	FinishedAt    time.Time       `json:"finished_at,omitzero"`
+	Tenant        string          `json:"tenant,omitempty"`
+	// Filled is the base volume traded so far, FilledCounter what it cost or raised
+	Filled        decimal.Decimal `json:"filled"`
+	FilledCounter decimal.Decimal `json:"filled_counter"`
+	Slices        []Slice         `json:"slices"`
+	Audit         []AuditEntry    `json:"audit"`
+	Error         string          `json:"error,omitempty"`
+}
+
+// Done reports whether the iceberg order has stopped
+func (o Order) Done() bool {
+	return o.State != StateRunning
+}
+
+// NotifyFunc is told about an iceberg order after each slice and when it finishes
+type NotifyFunc func(o Order)
+
+// Manager works iceberg orders in the background. It is safe for concurrent
+// use. Orders are kept in memory and stop with the process; their visible slice
+// is cancelled when they are stopped with Cancel or Close.
+type Manager struct {
+	mu     sync.Mutex
+	orders map[string]*order
+	lastID int
+	wg     sync.WaitGroup
+
+	now   func() time.Time
+	after func(time.Duration) <-chan time.Time
+}
+
+type order struct {
+	Order
+	plan   Plan
+	cancel context.CancelFunc
+}
+
+// NewManager creates a Manager with no orders
+func NewManager() *Manager {
+	return &Manager{
+		orders: make(map[string]*order),
+		now:    time.Now,
+		after:  time.After,
+	}
+}
+
+// Start validates plan and starts working it with client in the background.
+// notify, if not nil, is called after each slice and when the order ends.
+func (m *Manager) Start(plan Plan, client sdk.LunoClient, notify NotifyFunc) (Order, error) {
+	if client == nil {
+		return Order{}, errors.New("a Luno client is required")
+	}
+	if err := plan.Validate(); err != nil {
+		return Order{}, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.mu.Lock()
+	m.lastID++
+	o := &order{
+		Order: Order{
+			ID:            "iceberg-" + strconv.Itoa(m.lastID),
+			Pair:          plan.Market.MarketId,
+			Side:          plan.Side,
+			Volume:        plan.Volume,
+			VisibleVolume: plan.VisibleVolume,
+			Price:         plan.Price,
+			State:         StateRunning,
+			StartedAt:     m.now(),
+			Tenant:        plan.Tenant,
+			Filled:        decimal.Zero(),
+			FilledCounter: decimal.Zero(),
+			Slices:        []Slice{},
+			Audit:         []AuditEntry{},
+		},
+		plan:   plan,
+		cancel: cancel,
+	}
+	m.orders[o.ID] = o
+	m.audit(o, fmt.Sprintf("Started %s %s %s at %s, showing %s at a time", o.Side, o.Volume, o.Pair, o.Price, o.VisibleVolume),
+		"pair", o.Pair, "side", o.Side, "volume", o.Volume.String(), "visible_volume", o.VisibleVolume.String(), "price", o.Price.String())
+	snapshot := o.snapshot()
+	m.mu.Unlock()
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		m.run(ctx, o, client, notify)
+	}()
+	return snapshot, nil
+}
+
+// Get returns an iceberg order of tenant by ID
+func (m *Manager) Get(tenant, id string) (Order, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	o, ok := m.orders[id]
+	if !ok || o.Tenant != tenant {
+		return Order{}, false
+	}
+	return o.snapshot(), true
+}
+
+// List returns the iceberg orders of tenant ordered by start time
+func (m *Manager) List(tenant string) []Order {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	list := make([]Order, 0, len(m.orders))
+	for _, o := range m.orders {
+		if o.Tenant == tenant {
+			list = append(list, o.snapshot())
+		}
+	}
+	slices.SortFunc(list, func(a, b Order) int {
+		if c := a.StartedAt.Compare(b.StartedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return list
+}
+
+// Cancel stops a running iceberg order of tenant, cancelling its visible
+// slice. It reports whether the order exists.
+func (m *Manager) Cancel(tenant, id string) bool {
+	m.mu.Lock()
+	o, ok := m.orders[id]
+	ok = ok && o.Tenant == tenant
+	m.mu.Unlock()
+	if ok {
+		o.cancel()
+	}
+	return ok
+}
+
+// Close cancels every running iceberg order and waits for them to stop
+func (m *Manager) Close() {
+	m.mu.Lock()
+	for _, o := range m.orders {
+		o.cancel()
+	}
+	m.mu.Unlock()
+	m.wg.Wait()
+}
+
+// run places a slice, waits for it to fill and places the next, until the whole
+// volume has traded, the order is cancelled or an API call fails
+func (m *Manager) run(ctx context.Context, o *order, client sdk.LunoClient, notify NotifyFunc) {
+	plan := o.plan
+	var (
+		open   string
+		err    error
+		paused bool
+	)
+	finish := func(state State) {
+		// The visible slice is cancelled even if ctx is, so nothing is left resting
+		settleCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), settleTimeout)
+		defer cancel()
+		if open != "" {
+			if _, settleErr := m.settle(settleCtx, o, client, open, true); settleErr != nil && err == nil {
+				err = settleErr
+				state = StateFailed
+			}
+		}
+		m.mu.Lock()
+		o.State = state
+		o.FinishedAt = m.now()
+		if err != nil {
+			o.Error = err.Error()
+		}
+		m.audit(o, fmt.Sprintf("Finished %s: %s of %s filled", state, o.Filled, o.Volume),
+			"state", state, "filled", o.Filled.String(), "filled_counter", o.FilledCounter.String(), "error", o.Error)
+		snapshot := o.snapshot()
+		m.mu.Unlock()
+		if notify != nil {
+			notify(snapshot)
+		}
+	}
+	// fail stops the order after an API error, unless the error is only because
+	// the order was cancelled
+	fail := func(failure error) {
+		if ctx.Err() != nil {
+			finish(StateCancelled)
+			return
+		}
+		err = failure
+		finish(StateFailed)
+	}
+
+	for {
+		if open != "" {
+			select {
+			case <-ctx.Done():
+				finish(StateCancelled)
+				return
+			case <-m.after(PollInterval):
+			}
+			done, settleErr := m.settle(ctx, o, client, open, false)
+			if settleErr != nil {
+				fail(settleErr)
+				return
+			}
+			if !done {
+				continue
+			}
+			open = ""
+
+			m.mu.Lock()
+			last := o.Slices[len(o.Slices)-1]
+			m.mu.Unlock()
+			if last.Filled.Cmp(last.Volume) < 0 {
+				// Luno reports cancelled orders as complete, so a slice that
+				// stopped short was cancelled by someone else
+				err = fmt.Errorf("slice %s was cancelled outside the iceberg order after filling %s of %s", last.OrderID, last.Filled, last.Volume)
+				finish(StateCancelled)
+				return
+			}
+		}
+		if ctx.Err() != nil {
+			finish(StateCancelled)
+			return
+		}
+
+		m.mu.Lock()
+		remaining := plan.Volume.Sub(o.Filled)
+		m.mu.Unlock()
+		if remaining.Sign() <= 0 {
+			finish(StateCompleted)
+			return
+		}
+		if minVolume := plan.Market.MinVolume; minVolume.Sign() > 0 && remaining.Cmp(minVolume) < 0 {
+			m.mu.Lock()
+			m.audit(o, fmt.Sprintf("Remaining %s is below the minimum volume of %s, stopping", remaining, minVolume), "remaining", remaining.String())
+			m.mu.Unlock()
+			finish(StateCompleted)
+			return
+		}
+		volume := plan.VisibleVolume
+		if remaining.Cmp(volume) < 0 {
+			volume = remaining
+		}
+
+		if plan.Allowed != nil && !plan.Allowed(m.now()) {
+			if !paused {
+				paused = true
+				m.mu.Lock()
+				m.audit(o, "Paused outside trading windows")
+				m.mu.Unlock()
+			}
+			select {
+			case <-ctx.Done():
+				finish(StateCancelled)
+				return
+			case <-m.after(PollInterval):
+			}
+			continue
+		}
+		if paused {
+			paused = false
+			m.mu.Lock()
+			m.audit(o, "Resumed in trading windows")
+			m.mu.Unlock()
+		}
+
+		res, postErr := client.PostLimitOrder(ctx, &luno.PostLimitOrderRequest{
+			Pair:     plan.Market.MarketId,
+			Type:     plan.Side,
+			Volume:   volume,
+			Price:    plan.Price,
+			PostOnly: plan.Market.TradingStatus == luno.TradingStatusPost_only,
+		})
+		if postErr != nil {
+			fail(fmt.Errorf("placing slice: %w", postErr))
+			return
+		}
+		open = res.OrderId
+
+		m.mu.Lock()
+		o.Slices = append(o.Slices, Slice{
+			OrderID:       res.OrderId,
+			Volume:        volume,
+			PlacedAt:      m.now(),
+			State:         luno.OrderStatePending,
+			Filled:        decimal.Zero(),
+			FilledCounter: decimal.Zero(),
+		})
+		m.audit(o, fmt.Sprintf("Placed slice %s for %s", res.OrderId, volume), "order_id", res.OrderId, "volume", volume.String())
+		snapshot := o.snapshot()
+		m.mu.Unlock()
+		if notify != nil {
+			notify(snapshot)
+		}
+	}
+}
+
+// settle checks orderID, cancelling it first if stop is set and it is still
+// resting. Once the order is no longer pending its fills are recorded and done
+// is true.
+func (m *Manager) settle(ctx context.Context, o *order, client sdk.LunoClient, orderID string, stop bool) (bool, error) {
+	res, err := client.GetOrder(ctx, &luno.GetOrderRequest{Id: orderID})
+	if err != nil {
+		return false, fmt.Errorf("checking order %s: %w", orderID, err)
+	}
+	if res.State == luno.OrderStatePending {
+		if !stop {
+			return false, nil
+		}
+		if _, err := client.StopOrder(ctx, &luno.StopOrderRequest{OrderId: orderID}); err != nil {
+			return false, fmt.Errorf("cancelling order %s: %w", orderID, err)
+		}
+		m.mu.Lock()
+		m.audit(o, fmt.Sprintf("Cancelled slice %s", orderID), "order_id", orderID)
+		m.mu.Unlock()
+		// Fetch the order again in case it filled before it was cancelled
+		if res, err = client.GetOrder(ctx, &luno.GetOrderRequest{Id: orderID}); err != nil {
+			return false, fmt.Errorf("checking order %s: %w", orderID, err)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range o.Slices {
+		s := &o.Slices[i]
+		if s.OrderID != orderID {
+			continue
+		}
+		s.State = res.State
+		s.Filled = res.Base
+		s.FilledCounter = res.Counter
+		o.Filled = o.Filled.Add(res.Base)
+		o.FilledCounter = o.FilledCounter.Add(res.Counter)
+		if res.Base.Sign() > 0 {
+			m.audit(o, fmt.Sprintf("Slice %s filled %s for %s", orderID, res.Base, res.Counter),
+				"order_id", orderID, "filled", res.Base.String(), "filled_counter", res.Counter.String())
+		}
+	}
+	return true, nil
+}
+
+// audit records message on o and logs it with attrs. The caller must hold the
+// manager's lock.
+func (m *Manager) audit(o *order, message string, attrs ...any) {
+	o.Audit = append(o.Audit, AuditEntry{Time: m.now(), Message: message})
+	slog.Info("Iceberg order: "+message, append([]any{"iceberg", o.ID}, attrs...)...)
+}
+
+// snapshot copies o. The caller must hold the manager's lock.
+func (o *order) snapshot() Order {
+	out := o.Order
+	out.Slices = slices.Clone(o.Slices)
+	out.Audit = slices.Clone(o.Audit)
+	return out
+}
@@ -0,0 +1,313 @@
+package iceberg
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func dec(t *testing.T, s string) decimal.Decimal {
+	t.Helper()
+	d, err := decimal.NewFromString(s)
+	require.NoError(t, err)
+	return d
+}
+
+func testPlan(t *testing.T) Plan {
+	return Plan{
+		Market: luno.MarketInfo{
+			MarketId:      "XBTZAR",
+			TradingStatus: luno.TradingStatusActive,
+			VolumeScale:   4,
+			PriceScale:    0,
+			MinVolume:     dec(t, "0.0005"),
+			MaxVolume:     dec(t, "10"),
+		},
+		Side:          luno.OrderTypeAsk,
+		Volume:        dec(t, "0.0250"),
+		Price:         dec(t, "1000000"),
+		VisibleVolume: dec(t, "0.0100"),
+	}
+}
+
+// newTestManager returns a Manager whose poll intervals pass immediately
+func newTestManager() *Manager {
+	m := NewManager()
+	m.now = func() time.Time { return time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC) }
+	m.after = func(time.Duration) <-chan time.Time {
+		ch := make(chan time.Time, 1)
+		ch <- time.Time{}
+		return ch
+	}
+	return m
+}
+
+func TestPlanValidate(t *testing.T) {
+	tests := []struct {
+		name          string
+		modify        func(*Plan)
+		expectedError string
+	}{
+		{name: "valid", modify: func(*Plan) {}},
+		{name: "suspended market", modify: func(p *Plan) { p.Market.TradingStatus = luno.TradingStatusSuspended }, expectedError: "suspended"},
+		{name: "invalid side", modify: func(p *Plan) { p.Side = "SELL" }, expectedError: "invalid side"},
+		{name: "zero volume", modify: func(p *Plan) { p.Volume = decimal.Zero() }, expectedError: "volume must be positive"},
+		{name: "zero price", modify: func(p *Plan) { p.Price = decimal.Zero() }, expectedError: "price must be positive"},
+		{name: "visible volume not less than volume", modify: func(p *Plan) { p.VisibleVolume = p.Volume }, expectedError: "less than the volume"},
+		{name: "visible volume below minimum", modify: func(p *Plan) { p.VisibleVolume = dec(t, "0.0001") }, expectedError: "visible volume: volume 0.0001 is below the minimum of 0.0005 for XBTZAR"},
+		{name: "visible volume above maximum", modify: func(p *Plan) { p.Volume, p.VisibleVolume = dec(t, "50"), dec(t, "20") }, expectedError: "visible volume: volume 20 is above the maximum of"},
+		{name: "price below minimum", modify: func(p *Plan) { p.Market.MinPrice = dec(t, "2000000") }, expectedError: "price 1000000 is below the minimum"},
+		{name: "volume scale", modify: func(p *Plan) { p.Volume = dec(t, "0.02501") }, expectedError: "more than the 4 decimal places"},
+		{name: "price scale", modify: func(p *Plan) { p.Price = dec(t, "1000000.5") }, expectedError: "more than the 0 decimal places"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := testPlan(t)
+			tt.modify(&p)
+			err := p.Validate()
+			if tt.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.expectedError)
+		})
+	}
+}
+
+func TestManagerRun(t *testing.T) {
+	client := sdk.NewMockLunoClient(t)
+
+	// Two full slices and then what's left, each replaced once it has filled
+	for _, o := range []struct{ id, volume string }{{"1", "0.0100"}, {"2", "0.0100"}, {"3", "0.0050"}} {
+		client.EXPECT().PostLimitOrder(mock.Anything, &luno.PostLimitOrderRequest{
+			Pair: "XBTZAR", Type: luno.OrderTypeAsk, Volume: dec(t, o.volume), Price: dec(t, "1000000"),
+		}).Return(&luno.PostLimitOrderResponse{OrderId: o.id}, nil).Once()
+	}
+	// The first slice is still resting, partly filled, when it is first checked
+	client.EXPECT().GetOrder(mock.Anything, &luno.GetOrderRequest{Id: "1"}).
+		Return(&luno.GetOrderResponse{State: luno.OrderStatePending, Base: dec(t, "0.0040"), Counter: dec(t, "4000.00")}, nil).Once()
+	client.EXPECT().GetOrder(mock.Anything, &luno.GetOrderRequest{Id: "1"}).
+		Return(&luno.GetOrderResponse{State: luno.OrderStateComplete, Base: dec(t, "0.0100"), Counter: dec(t, "10000.00")}, nil).Once()
+	client.EXPECT().GetOrder(mock.Anything, &luno.GetOrderRequest{Id: "2"}).
+		Return(&luno.GetOrderResponse{State: luno.OrderStateComplete, Base: dec(t, "0.0100"), Counter: dec(t, "10000.00")}, nil).Once()
+	client.EXPECT().GetOrder(mock.Anything, &luno.GetOrderRequest{Id: "3"}).
+		Return(&luno.GetOrderResponse{State: luno.OrderStateComplete, Base: dec(t, "0.0050"), Counter: dec(t, "5000.00")}, nil).Once()
+
+	m := newTestManager()
+	var (
+		mu       sync.Mutex
+		notified []Order
+	)
+	started, err := m.Start(testPlan(t), client, func(o Order) {
+		mu.Lock()
+		defer mu.Unlock()
+		notified = append(notified, o)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "iceberg-1", started.ID)
+	assert.Equal(t, StateRunning, started.State)
+
+	m.wg.Wait()
+
+	got, ok := m.Get("", "iceberg-1")
+	require.True(t, ok)
+	assert.Equal(t, StateCompleted, got.State)
+	assert.Empty(t, got.Error)
+	assert.Equal(t, "0.0250", got.Filled.String())
+	assert.Equal(t, "25000.00", got.FilledCounter.String())
+	require.Len(t, got.Slices, 3)
+	assert.Equal(t, luno.OrderStateComplete, got.Slices[2].State)
+
+	var audit []string
+	for _, e := range got.Audit {
+		audit = append(audit, e.Message)
+	}
+	assert.Equal(t, []string{
+		"Started ASK 0.0250 XBTZAR at 1000000, showing 0.0100 at a time",
+		"Placed slice 1 for 0.0100",
+		"Slice 1 filled 0.0100 for 10000.00",
+		"Placed slice 2 for 0.0100",
+		"Slice 2 filled 0.0100 for 10000.00",
+		"Placed slice 3 for 0.0050",
+		"Slice 3 filled 0.0050 for 5000.00",
+		"Finished completed: 0.0250 of 0.0250 filled",
+	}, audit)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, notified, 4, "one notification per slice and one at the end")
+	assert.Equal(t, StateCompleted, notified[3].State)
+	assert.Equal(t, []Order{got}, m.List(""))
+}
+
+func TestManagerOutsideTradingWindows(t *testing.T) {
+	client := sdk.NewMockLunoClient(t)
+	for _, o := range []struct{ id, volume string }{{"1", "0.0100"}, {"2", "0.0100"}, {"3", "0.0050"}} {
+		client.EXPECT().PostLimitOrder(mock.Anything, &luno.PostLimitOrderRequest{
+			Pair: "XBTZAR", Type: luno.OrderTypeAsk, Volume: dec(t, o.volume), Price: dec(t, "1000000"),
+		}).Return(&luno.PostLimitOrderResponse{OrderId: o.id}, nil).Once()
+	}
+	for _, o := range []struct{ id, volume, counter string }{{"1", "0.0100", "10000.00"}, {"2", "0.0100", "10000.00"}, {"3", "0.0050", "5000.00"}} {
+		client.EXPECT().GetOrder(mock.Anything, &luno.GetOrderRequest{Id: o.id}).
+			Return(&luno.GetOrderResponse{State: luno.OrderStateComplete, Base: dec(t, o.volume), Counter: dec(t, o.counter)}, nil).Once()
+	}
+
+	m := newTestManager()
+	plan := testPlan(t)
+	// The windows close after the first slice fills, for two checks
+	var checks int
+	plan.Allowed = func(time.Time) bool {
+		checks++
+		return checks != 2 && checks != 3
+	}
+	_, err := m.Start(plan, client, nil)
+	require.NoError(t, err)
+	m.wg.Wait()
+
+	got, ok := m.Get("", "iceberg-1")
+	require.True(t, ok)
+	assert.Equal(t, StateCompleted, got.State)
+	assert.Equal(t, "0.0250", got.Filled.String())
+	var audit []string
+	for _, e := range got.Audit {
+		audit = append(audit, e.Message)
+	}
+	assert.Equal(t, []string{
+		"Started ASK 0.0250 XBTZAR at 1000000, showing 0.0100 at a time",
+		"Placed slice 1 for 0.0100",
+		"Slice 1 filled 0.0100 for 10000.00",
+		"Paused outside trading windows",
+		"Resumed in trading windows",
+		"Placed slice 2 for 0.0100",
+		"Slice 2 filled 0.0100 for 10000.00",
+		"Placed slice 3 for 0.0050",
+		"Slice 3 filled 0.0050 for 5000.00",
+		"Finished completed: 0.0250 of 0.0250 filled",
+	}, audit)
+}
+
+func TestManagerRemainderBelowMinimum(t *testing.T) {
+	client := sdk.NewMockLunoClient(t)
+	client.EXPECT().PostLimitOrder(mock.Anything, mock.Anything).Return(&luno.PostLimitOrderResponse{OrderId: "1"}, nil).Once()
+	client.EXPECT().GetOrder(mock.Anything, &luno.GetOrderRequest{Id: "1"}).
+		Return(&luno.GetOrderResponse{State: luno.OrderStateComplete, Base: dec(t, "0.0100"), Counter: dec(t, "10000.00")}, nil).Once()
+
+	p := testPlan(t)
+	p.Volume = dec(t, "0.0103")
+	m := newTestManager()
+	_, err := m.Start(p, client, nil)
+	require.NoError(t, err)
+	m.wg.Wait()
+
+	got, _ := m.Get("", "iceberg-1")
+	assert.Equal(t, StateCompleted, got.State)
+	assert.Equal(t, "0.0100", got.Filled.String())
+	assert.Equal(t, "Remaining 0.0003 is below the minimum volume of 0.0005, stopping", got.Audit[len(got.Audit)-2].Message)
+}
+
+func TestManagerCancel(t *testing.T) {
+	client := sdk.NewMockLunoClient(t)
+	client.EXPECT().PostLimitOrder(mock.Anything, mock.Anything).Return(&luno.PostLimitOrderResponse{OrderId: "1"}, nil).Once()
+	client.EXPECT().GetOrder(mock.Anything, &luno.GetOrderRequest{Id: "1"}).
+		Return(&luno.GetOrderResponse{State: luno.OrderStatePending, Base: dec(t, "0.0020"), Counter: dec(t, "2000.00")}, nil).Once()
+	client.EXPECT().StopOrder(mock.Anything, &luno.StopOrderRequest{OrderId: "1"}).Return(&luno.StopOrderResponse{Success: true}, nil).Once()
+	client.EXPECT().GetOrder(mock.Anything, &luno.GetOrderRequest{Id: "1"}).
+		Return(&luno.GetOrderResponse{State: luno.OrderStateComplete, Base: dec(t, "0.0020"), Counter: dec(t, "2000.00")}, nil).Once()
+
+	m := newTestManager()
+	// Polls never come round, so the visible slice rests until cancelled
+	m.after = func(time.Duration) <-chan time.Time { return nil }
+	placed := make(chan struct{})
+	_, err := m.Start(testPlan(t), client, func(o Order) {
+		if len(o.Slices) == 1 && !o.Done() {
+			close(placed)
+		}
+	})
+	require.NoError(t, err)
+
+	<-placed
+	assert.True(t, m.Cancel("", "iceberg-1"))
+	assert.False(t, m.Cancel("", "iceberg-2"))
+	m.Close()
+
+	got, ok := m.Get("", "iceberg-1")
+	require.True(t, ok)
+	assert.Equal(t, StateCancelled, got.State)
+	assert.Equal(t, "0.0020", got.Filled.String())
+	assert.Equal(t, "Cancelled slice 1", got.Audit[2].Message)
+}
+
+func TestManagerSliceCancelledElsewhere(t *testing.T) {
+	client := sdk.NewMockLunoClient(t)
+	client.EXPECT().PostLimitOrder(mock.Anything, mock.Anything).Return(&luno.PostLimitOrderResponse{OrderId: "1"}, nil).Once()
+	client.EXPECT().GetOrder(mock.Anything, &luno.GetOrderRequest{Id: "1"}).
+		Return(&luno.GetOrderResponse{State: luno.OrderStateComplete, Base: dec(t, "0.0030"), Counter: dec(t, "3000.00")}, nil).Once()
+
+	m := newTestManager()
+	_, err := m.Start(testPlan(t), client, nil)
+	require.NoError(t, err)
+	m.wg.Wait()
+
+	got, _ := m.Get("", "iceberg-1")
+	assert.Equal(t, StateCancelled, got.State)
+	assert.Equal(t, "0.0030", got.Filled.String())
+	assert.Equal(t, "slice 1 was cancelled outside the iceberg order after filling 0.0030 of 0.0100", got.Error)
+}
+
+func TestManagerFailure(t *testing.T) {
+	client := sdk.NewMockLunoClient(t)
+	client.EXPECT().PostLimitOrder(mock.Anything, mock.Anything).Return(nil, errors.New("insufficient balance")).Once()
+
+	m := newTestManager()
+	_, err := m.Start(testPlan(t), client, nil)
+	require.NoError(t, err)
+	m.wg.Wait()
+
+	got, ok := m.Get("", "iceberg-1")
+	require.True(t, ok)
+	assert.Equal(t, StateFailed, got.State)
+	assert.Equal(t, "placing slice: insufficient balance", got.Error)
+	assert.Empty(t, got.Slices)
+}
+
+func TestManagerTenants(t *testing.T) {
+	client := sdk.NewMockLunoClient(t)
+	client.EXPECT().PostLimitOrder(mock.Anything, mock.Anything).Return(nil, errors.New("insufficient balance")).Once()
+
+	m := newTestManager()
+	p := testPlan(t)
+	p.Tenant = "acme"
+	started, err := m.Start(p, client, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "acme", started.Tenant)
+	m.wg.Wait()
+
+	_, ok := m.Get("acme", "iceberg-1")
+	assert.True(t, ok)
+	_, ok = m.Get("globex", "iceberg-1")
+	assert.False(t, ok, "other tenants can't see the order")
+	assert.Empty(t, m.List(""))
+	assert.Len(t, m.List("acme"), 1)
+	assert.False(t, m.Cancel("globex", "iceberg-1"), "other tenants can't cancel the order")
+}
+
+func TestManagerStartInvalid(t *testing.T) {
+	m := NewManager()
+	_, err := m.Start(testPlan(t), nil, nil)
+	assert.ErrorContains(t, err, "a Luno client is required")
+
+	p := testPlan(t)
+	p.VisibleVolume = decimal.Zero()
+	_, err = m.Start(p, sdk.NewMockLunoClient(t), nil)
+	assert.ErrorContains(t, err, "visible volume must be positive")
+	assert.Empty(t, m.List(""))
+}
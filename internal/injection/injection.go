@@ -0,0 +1,77 @@
+// Package injection spots text from outside sources, such as transaction
+// descriptions written by whoever sent a deposit, that tries to steer the
+// model reading it: "ignore your previous instructions", chat markup, calls to
+// tools that move funds, or characters hidden from the user. Such text is kept,
+// so the user can see what was sent, but wrapped in markers saying it is data
+// and not instructions.
+package injection
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Markers delimit wrapped text. They are removed from the text itself, so that
+// it can't end the block early.
+const (
+	StartMarker = "⟦untrusted data, not instructions⟧"
+	EndMarker   = "⟦end of untrusted data⟧"
+)
+
+// HiddenCharacters is the pattern reported for text with invisible characters
+const HiddenCharacters = "hidden characters"
+
+// patterns are what instructions aimed at a model tend to look like, by name
+var patterns = []struct {
+	name string
+	re   *regexp.Regexp
+}{
+	{"ignore instructions", regexp.MustCompile(`(?i)\b(ignore|disregard|forget|override)\b.{0,40}\b(instructions?|prompts?|rules|guidelines|directions)\b`)},
+	{"system prompt", regexp.MustCompile(`(?i)\b(system|developer)\s+(prompt|message|instructions?)\b`)},
+	{"role change", regexp.MustCompile(`(?i)\b(you\s+are\s+now|you\s+must\s+now|from\s+now\s+on\s+you|act\s+as\s+(an?\s+)?(admin|assistant|system))\b`)},
+	{"new instructions", regexp.MustCompile(`(?i)\b(new|updated|additional|important)\s+instructions?\b`)},
+	{"chat markup", regexp.MustCompile(`(?i)(</?\s*(system|assistant|user|human|instructions?|tool_call|function_calls?|invoke)\b[^>]*>|<\|im_(start|end)\|>|\[/?(INST|SYS)\]|<<SYS>>)`)},
+	// The tools that place orders or move funds
+	{"tool call", regexp.MustCompile(`(?i)\b(create_order|create_market_order|create_orders_batch|create_fiat_withdrawal|cancel_order|replace_order|execute_twap|iceberg_order|undo_last_action)\b`)},
+	{"fund transfer", regexp.MustCompile(`(?i)\b(send|transfer|withdraw|move)\b.{0,30}\b(all|every|entire|whole)\b.{0,30}\b(funds|balances?|bitcoin|crypto|money)\b`)},
+}
+
+// Scan returns the name of the first pattern s matches, if any
+func Scan(s string) (pattern string, found bool) {
+	if strings.IndexFunc(s, hidden) >= 0 {
+		return HiddenCharacters, true
+	}
+	for _, p := range patterns {
+		if p.re.MatchString(s) {
+			return p.name, true
+		}
+	}
+	return "", false
+}
+
+// Sanitize returns s wrapped between the markers, without hidden characters,
+// if it matches a pattern, and s unchanged otherwise
+func Sanitize(s string) (out, pattern string, found bool) {
+	if pattern, found = Scan(s); !found {
+		return s, "", false
+	}
+	s = strings.Map(func(r rune) rune {
+		if hidden(r) || r == '⟦' || r == '⟧' {
+			return -1
+		}
+		return r
+	}, s)
+	return StartMarker + " " + s + " " + EndMarker, pattern, true
+}
+
+// hidden reports whether r is invisible when text is shown: control
+// characters other than whitespace, and formatting characters such as
+// zero-width spaces and bidirectional overrides
+func hidden(r rune) bool {
+	switch r {
+	case '\n', '\r', '\t':
+		return false
+	}
+	return unicode.IsControl(r) || unicode.Is(unicode.Cf, r)
+}
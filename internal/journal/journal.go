@@ -0,0 +1,248 @@
+// Package journal keeps a local trade journal: free-text notes recording why
+// orders were placed, so trades can be reviewed later alongside their reasons.
+package journal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luno/luno-mcp/internal/fsutil"
+	"github.com/luno/luno-mcp/internal/vault"
+)
+
+// MaxNoteLength is the longest note accepted, in bytes
+const MaxNoteLength = 4000
+
+// Entry is a note in the journal
+type Entry struct {
+	ID string `json:"id"`
+	// OrderID is the order the note is about. It is empty for general notes.
+	OrderID string `json:"order_id,omitempty"`
+	Pair    string `json:"pair,omitempty"`
+	Note    string `json:"note"`
+	// SessionID is the MCP session the note was written in
+	SessionID string `json:"session_id,omitempty"`
+	// Tenant is the tenant of the session, in multi-tenant deployments
+	Tenant    string    `json:"tenant,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Filter selects journal entries. Empty fields match everything, except
+// Tenant, which must always match, so that tenants only see their own notes.
+type Filter struct {
+	OrderID   string
+	Pair      string
+	SessionID string
+	Tenant    string
+}
+
+func (f Filter) matches(e Entry) bool {
+	return e.Tenant == f.Tenant &&
+		(f.OrderID == "" || e.OrderID == f.OrderID) &&
+		(f.Pair == "" || e.Pair == f.Pair) &&
+		(f.SessionID == "" || e.SessionID == f.SessionID)
+}
+
+// Journal is a trade journal stored as JSON lines in a file. The file is read
+// on first use and each entry is appended as it is added. It is safe for
+// concurrent use.
+type Journal struct {
+	path   string
+	cipher *vault.Cipher
+
+	mu      sync.Mutex
+	loaded  bool
+	entries []Entry
+
+	now func() time.Time
+}
+
+// New creates a journal stored at path. An empty path keeps the journal in
+// memory only.
+func New(path string) *Journal {
+	return &Journal{path: path, now: time.Now}
+}
+
+// SetCipher encrypts the journal with c, each line on its own. It must be
+// called before the journal is used; lines written without encryption are
+// encrypted when the journal is first read.
+func (j *Journal) SetCipher(c *vault.Cipher) {
+	j.cipher = c
+}
+
+// Path returns where the journal is stored, or an empty string if it is only
+// kept in memory
+func (j *Journal) Path() string {
+	return j.path
+}
+
+// FileName is the name of the file the journal is kept in by default
+const FileName = "trade-journal.jsonl"
+
+// DefaultPath returns the journal's default location in the user's config
+// directory, or an empty string if there isn't one
+func DefaultPath() string {
+	return fsutil.ConfigPath(FileName)
+}
+
+// Add validates e, assigns its ID and creation time and saves it
+func (j *Journal) Add(e Entry) (Entry, error) {
+	e.Note = strings.TrimSpace(e.Note)
+	if e.Note == "" {
+		return Entry{}, errors.New("note must not be empty")
+	}
+	if len(e.Note) > MaxNoteLength {
+		return Entry{}, fmt.Errorf("note must be at most %d bytes", MaxNoteLength)
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err := j.load(); err != nil {
+		return Entry{}, err
+	}
+	e.ID = "note-" + strconv.Itoa(len(j.entries)+1)
+	e.CreatedAt = j.now().UTC()
+	if err := j.append(e); err != nil {
+		return Entry{}, err
+	}
+	j.entries = append(j.entries, e)
+	return e, nil
+}
+
+// List returns the entries matching f, oldest first
+func (j *Journal) List(f Filter) ([]Entry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err := j.load(); err != nil {
+		return nil, err
+	}
+	var out []Entry
+	for _, e := range j.entries {
+		if f.matches(e) {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+// ByOrder returns the entries of tenant for each of orderIDs that has any,
+// oldest first
+func (j *Journal) ByOrder(tenant string, orderIDs []string) (map[string][]Entry, error) {
+	want := make(map[string]bool, len(orderIDs))
+	for _, id := range orderIDs {
+		want[id] = true
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err := j.load(); err != nil {
+		return nil, err
+	}
+	out := make(map[string][]Entry)
+	for _, e := range j.entries {
+		if e.Tenant == tenant && e.OrderID != "" && want[e.OrderID] {
+			out[e.OrderID] = append(out[e.OrderID], e)
+		}
+	}
+	return out, nil
+}
+
+// load reads the journal file the first time it is needed. The caller must
+// hold j.mu.
+func (j *Journal) load() error {
+	if j.loaded || j.path == "" {
+		j.loaded = true
+		return nil
+	}
+	f, err := os.Open(j.path)
+	if errors.Is(err, os.ErrNotExist) {
+		j.loaded = true
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("opening trade journal: %w", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	plaintext := false
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		plaintext = plaintext || !vault.IsSealed(scanner.Bytes())
+		data, err := j.cipher.Open(scanner.Bytes())
+		if err != nil {
+			return fmt.Errorf("reading trade journal %s line %d: %w", j.path, line, err)
+		}
+		var e Entry
+		if err := json.Unmarshal(data, &e); err != nil {
+			return fmt.Errorf("reading trade journal %s line %d: %w", j.path, line, err)
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading trade journal: %w", err)
+	}
+	j.entries = entries
+	j.loaded = true
+	if j.cipher.Enabled() && plaintext {
+		return j.rewrite()
+	}
+	return nil
+}
+
+// rewrite replaces the journal file with the entries, encrypting any that were
+// written without encryption. The caller must hold j.mu.
+func (j *Journal) rewrite() error {
+	var buf bytes.Buffer
+	for _, e := range j.entries {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("encoding journal entry: %w", err)
+		}
+		buf.Write(j.cipher.Seal(data))
+		buf.WriteByte('\n')
+	}
+	if err := fsutil.WriteFile(j.path, buf.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("writing trade journal: %w", err)
+	}
+	return nil
+}
+
+// append writes e to the end of the journal file. The caller must hold j.mu.
+func (j *Journal) append(e Entry) error {
+	if j.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("encoding journal entry: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(j.path), 0o700); err != nil {
+		return fmt.Errorf("creating trade journal directory: %w", err)
+	}
+	f, err := os.OpenFile(j.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("opening trade journal: %w", err)
+	}
+	if _, err := f.Write(append(j.cipher.Seal(data), '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("writing trade journal: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("writing trade journal: %w", err)
+	}
+	return nil
+}
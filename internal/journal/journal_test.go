@@ -0,0 +1,145 @@
+package journal
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-mcp/internal/vault"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "luno-mcp", "trade-journal.jsonl")
+	j := New(path)
+	j.now = func() time.Time { return time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC) }
+
+	first, err := j.Add(Entry{OrderID: "BXMC2CJ7HNB88U4", Pair: "XBTZAR", Note: "  Buying the dip below 1m  ", SessionID: "s1"})
+	require.NoError(t, err)
+	assert.Equal(t, "note-1", first.ID)
+	assert.Equal(t, "Buying the dip below 1m", first.Note)
+	assert.Equal(t, j.now(), first.CreatedAt)
+
+	_, err = j.Add(Entry{Pair: "ETHZAR", Note: "Watching ETH for a breakout", SessionID: "s2"})
+	require.NoError(t, err)
+	_, err = j.Add(Entry{OrderID: "BXMC2CJ7HNB88U4", Pair: "XBTZAR", Note: "Filled, moving stop up", SessionID: "s2"})
+	require.NoError(t, err)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// A new journal on the same file sees everything written so far
+	reopened := New(path)
+	all, err := reopened.List(Filter{})
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	assert.Equal(t, first, all[0])
+
+	bySession, err := reopened.List(Filter{SessionID: "s2"})
+	require.NoError(t, err)
+	assert.Len(t, bySession, 2)
+
+	byPair, err := reopened.List(Filter{Pair: "XBTZAR", SessionID: "s2"})
+	require.NoError(t, err)
+	require.Len(t, byPair, 1)
+	assert.Equal(t, "Filled, moving stop up", byPair[0].Note)
+
+	byOrder, err := reopened.ByOrder("", []string{"BXMC2CJ7HNB88U4", "BXHW6PFRRXKFSB4"})
+	require.NoError(t, err)
+	assert.Len(t, byOrder, 1)
+	assert.Len(t, byOrder["BXMC2CJ7HNB88U4"], 2)
+
+	next, err := reopened.Add(Entry{Note: "End of day review"})
+	require.NoError(t, err)
+	assert.Equal(t, "note-4", next.ID)
+}
+
+func TestJournalTenants(t *testing.T) {
+	j := New("")
+	_, err := j.Add(Entry{OrderID: "BXACME", Note: "Acme's note", Tenant: "acme"})
+	require.NoError(t, err)
+	_, err = j.Add(Entry{OrderID: "BXGLOBEX", Note: "Globex's note", Tenant: "globex"})
+	require.NoError(t, err)
+
+	acme, err := j.List(Filter{Tenant: "acme"})
+	require.NoError(t, err)
+	require.Len(t, acme, 1)
+	assert.Equal(t, "Acme's note", acme[0].Note)
+
+	none, err := j.List(Filter{})
+	require.NoError(t, err)
+	assert.Empty(t, none, "notes of tenants are not listed without one")
+
+	byOrder, err := j.ByOrder("acme", []string{"BXACME", "BXGLOBEX"})
+	require.NoError(t, err)
+	assert.Len(t, byOrder, 1)
+	assert.Contains(t, byOrder, "BXACME")
+}
+
+func TestJournalAddInvalid(t *testing.T) {
+	j := New("")
+	_, err := j.Add(Entry{Note: "   "})
+	assert.EqualError(t, err, "note must not be empty")
+
+	_, err = j.Add(Entry{Note: strings.Repeat("x", MaxNoteLength+1)})
+	assert.ErrorContains(t, err, "note must be at most")
+
+	entries, err := j.List(Filter{})
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestJournalInMemory(t *testing.T) {
+	j := New("")
+	_, err := j.Add(Entry{Note: "Not written anywhere"})
+	require.NoError(t, err)
+
+	entries, err := j.List(Filter{})
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Empty(t, j.Path())
+}
+
+func TestJournalCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trade-journal.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte("{\"id\":\"note-1\",\"note\":\"ok\"}\nnot json\n"), 0o600))
+
+	_, err := New(path).List(Filter{})
+	assert.ErrorContains(t, err, "line 2")
+}
+
+func TestJournalEncrypted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trade-journal.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte(`{"id":"note-1","order_id":"BXMC2CJ7HNB88U4","note":"Written before encryption"}`+"\n"), 0o600))
+	c, err := vault.New(bytes.Repeat([]byte{1}, vault.KeySize))
+	require.NoError(t, err)
+
+	j := New(path)
+	j.SetCipher(c)
+	_, err = j.Add(Entry{Note: "Encrypted from the start"})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+	for _, line := range lines {
+		assert.True(t, vault.IsSealed([]byte(line)), "every line is encrypted, including those written before")
+	}
+	assert.NotContains(t, string(data), "BXMC2CJ7HNB88U4")
+
+	reopened := New(path)
+	reopened.SetCipher(c)
+	all, err := reopened.List(Filter{})
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, "Written before encryption", all[0].Note)
+
+	_, err = New(path).List(Filter{})
+	assert.ErrorIs(t, err, vault.ErrEncrypted)
+}
@@ -0,0 +1,293 @@
+// Package levels estimates support and resistance levels from historical
+// candles and the order book. Each method scores the levels it finds from 0 to
+// 1; levels found by several methods at about the same price are merged and
+// their scores added, so levels confirmed by more than one method rank higher.
+package levels
+
+import (
+	"cmp"
+	"errors"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/luno/luno-go"
+)
+
+// Methods that find levels
+const (
+	// Swing finds prices where candles repeatedly turned, from their swing highs and lows
+	Swing = "swing"
+	// VolumeProfile finds prices where the most volume traded
+	VolumeProfile = "volume_profile"
+	// OrderBook finds prices where open orders cluster
+	OrderBook = "order_book"
+)
+
+// Kinds of level, relative to the current price
+const (
+	Support    = "support"
+	Resistance = "resistance"
+)
+
+const (
+	// swingWindow is how many candles on each side a swing high or low must exceed
+	swingWindow = 2
+	// nodeFactor is how many times the average bucket volume a price must have
+	// to be a volume or order book node
+	nodeFactor = 1.5
+	// maxBookDistance bounds how far from the price, as a share of it, order
+	// book clusters are looked for
+	maxBookDistance = 0.1
+)
+
+// Params configures Find
+type Params struct {
+	// Price is the current price, which divides support from resistance
+	Price float64
+	// Tolerance is the share of Price within which prices count as one level
+	Tolerance float64
+	// Limit is the most levels returned
+	Limit int
+}
+
+// Level is a support or resistance level
+type Level struct {
+	Price float64 `json:"price"`
+	Kind  string  `json:"kind"`
+	// DistancePercent is how far the level is from the current price
+	DistancePercent float64 `json:"distance_percent"`
+	// Score ranks the levels. Each method that found the level adds up to 1.
+	Score   float64  `json:"score"`
+	Methods []string `json:"methods"`
+	// Touches is how many swing highs and lows were at the level
+	Touches int `json:"touches,omitempty"`
+	// LastTouched is the time of the latest of those
+	LastTouched *time.Time `json:"last_touched,omitempty"`
+	// TradedVolume is the base volume the candles traded at the level
+	TradedVolume float64 `json:"traded_volume,omitempty"`
+	// BookVolume is the base volume of open orders at the level
+	BookVolume float64 `json:"book_volume,omitempty"`
+}
+
+// candidate is a price a single method found
+type candidate struct {
+	price  float64
+	method string
+	score  float64
+	// touches and last are set by Swing
+	touches int
+	last    time.Time
+	// volume is set by VolumeProfile and OrderBook
+	volume float64
+}
+
+// Find returns the strongest levels in candles, which must be in time order,
+// and the order book entries, ranked by score. Either can be empty.
+func Find(candles []luno.Candle, bids, asks []luno.OrderBookEntry, p Params) ([]Level, error) {
+	if p.Price <= 0 {
+		return nil, errors.New("price must be more than 0")
+	}
+	if p.Tolerance <= 0 || p.Tolerance >= 1 {
+		return nil, errors.New("tolerance must be more than 0 and less than 1")
+	}
+	if p.Limit < 1 {
+		return nil, errors.New("limit must be at least 1")
+	}
+
+	width := p.Price * p.Tolerance
+	var found []candidate
+	found = append(found, swings(candles, p.Tolerance)...)
+	found = append(found, volumeNodes(candles, width)...)
+	found = append(found, bookNodes(slices.Concat(bids, asks), p.Price, width)...)
+
+	levels := merge(found, p)
+	slices.SortStableFunc(levels, func(a, b Level) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.DistancePercent, b.DistancePercent)
+	})
+	if len(levels) > p.Limit {
+		levels = levels[:p.Limit]
+	}
+	return levels, nil
+}
+
+// swings finds the swing highs and lows of candles, grouping those within
+// tolerance of each other
+func swings(candles []luno.Candle, tolerance float64) []candidate {
+	var points []candidate
+	for i := swingWindow; i < len(candles)-swingWindow; i++ {
+		high, low := candles[i].High.Float64(), candles[i].Low.Float64()
+		isHigh, isLow := high > 0, low > 0
+		for j := i - swingWindow; j <= i+swingWindow; j++ {
+			if j == i {
+				continue
+			}
+			isHigh = isHigh && high > candles[j].High.Float64()
+			isLow = isLow && low < candles[j].Low.Float64()
+		}
+		at := time.Time(candles[i].Timestamp).UTC()
+		if isHigh {
+			points = append(points, candidate{price: high, method: Swing, touches: 1, last: at})
+		}
+		if isLow {
+			points = append(points, candidate{price: low, method: Swing, touches: 1, last: at})
+		}
+	}
+
+	groups := group(points, tolerance)
+	var most int
+	for _, g := range groups {
+		most = max(most, g.touches)
+	}
+	for i := range groups {
+		groups[i].score = float64(groups[i].touches) / float64(most)
+	}
+	return groups
+}
+
+// volumeNodes finds the prices where the candles traded the most volume,
+// assigning each candle's volume to its typical price
+func volumeNodes(candles []luno.Candle, width float64) []candidate {
+	buckets := make(map[int64]float64)
+	for _, c := range candles {
+		typical := (c.High.Float64() + c.Low.Float64() + c.Close.Float64()) / 3
+		if v := c.Volume.Float64(); typical > 0 && v > 0 {
+			buckets[int64(math.Floor(typical/width))] += v
+		}
+	}
+	return nodes(buckets, width, VolumeProfile)
+}
+
+// bookNodes finds the prices near price where open orders cluster
+func bookNodes(entries []luno.OrderBookEntry, price, width float64) []candidate {
+	buckets := make(map[int64]float64)
+	for _, e := range entries {
+		p := e.Price.Float64()
+		if p <= 0 || math.Abs(p-price) > maxBookDistance*price {
+			continue
+		}
+		buckets[int64(math.Floor(p/width))] += e.Volume.Float64()
+	}
+	return nodes(buckets, width, OrderBook)
+}
+
+// nodes returns the buckets holding at least nodeFactor times the average
+// bucket volume that hold more than their neighbours, scored by their share of
+// the largest
+func nodes(buckets map[int64]float64, width float64, method string) []candidate {
+	if len(buckets) == 0 {
+		return nil
+	}
+	var total, most float64
+	for _, v := range buckets {
+		total += v
+		most = max(most, v)
+	}
+	threshold := nodeFactor * total / float64(len(buckets))
+
+	var out []candidate
+	for b, v := range buckets {
+		if v < threshold || v < buckets[b-1] || v < buckets[b+1] {
+			continue
+		}
+		out = append(out, candidate{price: (float64(b) + 0.5) * width, method: method, score: v / most, volume: v})
+	}
+	return out
+}
+
+// group merges candidates within tolerance of the average price of their group
+// into one, adding their touches and volumes
+func group(cs []candidate, tolerance float64) []candidate {
+	slices.SortFunc(cs, func(a, b candidate) int { return cmp.Compare(a.price, b.price) })
+	var out []candidate
+	var sum float64
+	var n int
+	for _, c := range cs {
+		if n > 0 {
+			last := &out[len(out)-1]
+			if c.price-sum/float64(n) <= tolerance*sum/float64(n) {
+				sum += c.price
+				n++
+				last.price = sum / float64(n)
+				last.touches += c.touches
+				last.volume += c.volume
+				if c.last.After(last.last) {
+					last.last = c.last
+				}
+				continue
+			}
+		}
+		out = append(out, c)
+		sum, n = c.price, 1
+	}
+	return out
+}
+
+// merge combines the candidates of every method within tolerance of each
+// other into levels, keeping the best candidate of each method
+func merge(found []candidate, p Params) []Level {
+	slices.SortFunc(found, func(a, b candidate) int { return cmp.Compare(a.price, b.price) })
+
+	var levels []Level
+	var best map[string]candidate
+	flush := func() {
+		if len(best) == 0 {
+			return
+		}
+		var l Level
+		var weighted float64
+		for _, m := range []string{Swing, VolumeProfile, OrderBook} {
+			c, ok := best[m]
+			if !ok {
+				continue
+			}
+			l.Methods = append(l.Methods, m)
+			l.Score += c.score
+			weighted += c.price * c.score
+			switch m {
+			case Swing:
+				l.Touches = c.touches
+				last := c.last
+				l.LastTouched = &last
+			case VolumeProfile:
+				l.TradedVolume = round(c.volume, 8)
+			case OrderBook:
+				l.BookVolume = round(c.volume, 8)
+			}
+		}
+		l.Price = weighted / l.Score
+		l.Kind = Support
+		if l.Price > p.Price {
+			l.Kind = Resistance
+		}
+		l.DistancePercent = round(math.Abs(l.Price-p.Price)/p.Price*100, 2)
+		l.Price = round(l.Price, 8)
+		l.Score = round(l.Score, 2)
+		levels = append(levels, l)
+	}
+
+	var start float64
+	for _, c := range found {
+		if best != nil && c.price-start > p.Tolerance*p.Price {
+			flush()
+			best = nil
+		}
+		if best == nil {
+			best = make(map[string]candidate)
+			start = c.price
+		}
+		if prev, ok := best[c.method]; !ok || c.score > prev.score {
+			best[c.method] = c
+		}
+	}
+	flush()
+	return levels
+}
+
+func round(v float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(v*scale) / scale
+}
@@ -0,0 +1,103 @@
+package levels
+
+import (
+	"testing"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var start = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func dec(t *testing.T, f float64) decimal.Decimal {
+	t.Helper()
+	return decimal.NewFromFloat64(f, 8)
+}
+
+// testCandles returns hourly candles, each given as {high, low, close, volume}
+func testCandles(t *testing.T, hlcv ...[4]float64) []luno.Candle {
+	t.Helper()
+	out := make([]luno.Candle, len(hlcv))
+	for i, c := range hlcv {
+		out[i] = luno.Candle{
+			Timestamp: luno.Time(start.Add(time.Duration(i) * time.Hour)),
+			High:      dec(t, c[0]),
+			Low:       dec(t, c[1]),
+			Close:     dec(t, c[2]),
+			Volume:    dec(t, c[3]),
+		}
+	}
+	return out
+}
+
+func entries(t *testing.T, pv ...[2]float64) []luno.OrderBookEntry {
+	t.Helper()
+	out := make([]luno.OrderBookEntry, len(pv))
+	for i, e := range pv {
+		out[i] = luno.OrderBookEntry{Price: dec(t, e[0]), Volume: dec(t, e[1])}
+	}
+	return out
+}
+
+func TestFind(t *testing.T) {
+	candles := testCandles(t,
+		[4]float64{100, 95, 97, 1},
+		[4]float64{103, 96, 100, 1},
+		[4]float64{110, 99, 105, 1}, // swing high
+		[4]float64{104, 95, 96, 1},
+		[4]float64{101, 90, 94, 1}, // swing low
+		[4]float64{102, 94, 98, 10},
+		[4]float64{110.2, 100, 103, 1}, // swing high
+		[4]float64{103, 96, 97, 1},
+		[4]float64{100, 90.3, 92, 1}, // swing low
+		[4]float64{101, 94, 99, 10},
+		[4]float64{102, 96, 101, 1},
+	)
+	bids := entries(t, [2]float64{99.9, 0.1}, [2]float64{99.5, 0.2}, [2]float64{98.3, 5}, [2]float64{95, 5})
+	asks := entries(t, [2]float64{100.1, 0.1}, [2]float64{105, 5}, [2]float64{150, 100})
+
+	levels, err := Find(candles, bids, asks, Params{Price: 100, Tolerance: 0.005, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, levels, 5)
+
+	assert.Equal(t, Level{
+		Price: 98.25, Kind: Support, DistancePercent: 1.75, Score: 2,
+		Methods: []string{VolumeProfile, OrderBook}, TradedVolume: 21, BookVolume: 5,
+	}, levels[0], "levels found by two methods rank first")
+
+	assert.Equal(t, 95.25, levels[1].Price)
+	assert.Equal(t, []string{OrderBook}, levels[1].Methods)
+	assert.Equal(t, 105.25, levels[2].Price)
+	assert.Equal(t, Resistance, levels[2].Kind)
+
+	lastLow := start.Add(8 * time.Hour)
+	assert.Equal(t, Level{
+		Price: 90.15, Kind: Support, DistancePercent: 9.85, Score: 1,
+		Methods: []string{Swing}, Touches: 2, LastTouched: &lastLow,
+	}, levels[3])
+	assert.Equal(t, 110.1, levels[4].Price)
+	assert.Equal(t, 2, levels[4].Touches)
+	assert.Equal(t, Resistance, levels[4].Kind)
+
+	limited, err := Find(candles, bids, asks, Params{Price: 100, Tolerance: 0.005, Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, levels[:2], limited)
+}
+
+func TestFindWithoutData(t *testing.T) {
+	levels, err := Find(nil, nil, nil, Params{Price: 100, Tolerance: 0.01, Limit: 5})
+	require.NoError(t, err)
+	assert.Empty(t, levels)
+}
+
+func TestFindParams(t *testing.T) {
+	_, err := Find(nil, nil, nil, Params{Tolerance: 0.01, Limit: 5})
+	assert.ErrorContains(t, err, "price must be more than 0")
+	_, err = Find(nil, nil, nil, Params{Price: 100, Limit: 5})
+	assert.ErrorContains(t, err, "tolerance must be more than 0")
+	_, err = Find(nil, nil, nil, Params{Price: 100, Tolerance: 0.01})
+	assert.ErrorContains(t, err, "limit must be at least 1")
+}
@@ -0,0 +1,160 @@
+// Package limiter bounds how many tool calls run at once, overall and per tool,
+// so that a burst of parallel calls cannot open an unbounded number of Luno
+// requests. Calls over the limit queue until a slot frees up or they time out.
+package limiter
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultMaxCalls is the default number of tool calls that can run at once
+	DefaultMaxCalls = 16
+	// DefaultMaxCallsPerTool is the default number of calls to one tool that can run at once
+	DefaultMaxCallsPerTool = 4
+	// DefaultQueueTimeout is how long a call waits for a slot by default
+	DefaultQueueTimeout = 30 * time.Second
+)
+
+// ErrQueueTimeout is returned when a call waited longer than the queue timeout
+var ErrQueueTimeout = errors.New("timed out waiting for a free tool call slot")
+
+// Limiter limits concurrent tool calls. A nil Limiter allows every call. It is
+// safe for concurrent use.
+type Limiter struct {
+	queueTimeout time.Duration
+
+	mu              sync.Mutex
+	maxCalls        int
+	maxCallsPerTool int
+	global          chan struct{}
+	tools           map[string]chan struct{}
+}
+
+// New creates a Limiter that runs at most maxCalls calls, and at most
+// maxCallsPerTool calls to any one tool, at once. A limit of zero is no limit.
+// Calls over a limit wait up to queueTimeout, or indefinitely if it is zero.
+func New(maxCalls, maxCallsPerTool int, queueTimeout time.Duration) *Limiter {
+	l := &Limiter{queueTimeout: queueTimeout}
+	l.SetLimits(maxCalls, maxCallsPerTool)
+	return l
+}
+
+// SetLimits changes the overall and per-tool limits. Calls already running,
+// or queued, hold and wait for slots under the old limits, so the new limits
+// apply fully once those calls are done.
+func (l *Limiter) SetLimits(maxCalls, maxCallsPerTool int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.maxCalls, l.maxCallsPerTool = maxCalls, maxCallsPerTool
+	l.global = nil
+	if maxCalls > 0 {
+		l.global = make(chan struct{}, maxCalls)
+	}
+	l.tools = make(map[string]chan struct{})
+}
+
+// MaxCalls returns the overall limit, zero if there is none
+func (l *Limiter) MaxCalls() int {
+	if l == nil {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.maxCalls
+}
+
+// MaxCallsPerTool returns the per-tool limit, zero if there is none
+func (l *Limiter) MaxCallsPerTool() int {
+	if l == nil {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.maxCallsPerTool
+}
+
+// QueueTimeout returns how long calls wait for a slot, zero if they wait indefinitely
+func (l *Limiter) QueueTimeout() time.Duration {
+	if l == nil {
+		return 0
+	}
+	return l.queueTimeout
+}
+
+// Acquire waits for a slot to call tool. The returned release func must be
+// called once the call is done. It returns ErrQueueTimeout if no slot frees up
+// in time, or the context's error if ctx is done first.
+func (l *Limiter) Acquire(ctx context.Context, tool string) (release func(), err error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	if l.queueTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, l.queueTimeout, ErrQueueTimeout)
+		defer cancel()
+	}
+
+	// Take the tool's slot first so that calls queued on a busy tool do not
+	// hold overall slots that calls to other tools could use
+	global, perTool := l.slots(tool)
+	if err := acquire(ctx, perTool); err != nil {
+		return nil, err
+	}
+	if err := acquire(ctx, global); err != nil {
+		releaseSlot(perTool)
+		return nil, err
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			releaseSlot(global)
+			releaseSlot(perTool)
+		})
+	}, nil
+}
+
+// slots returns the overall semaphore and the one for tool, nil where there
+// is no limit
+func (l *Limiter) slots(tool string) (global, perTool chan struct{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.maxCallsPerTool <= 0 {
+		return l.global, nil
+	}
+	perTool, ok := l.tools[tool]
+	if !ok {
+		perTool = make(chan struct{}, l.maxCallsPerTool)
+		l.tools[tool] = perTool
+	}
+	return l.global, perTool
+}
+
+// acquire takes a slot from sem, which is unlimited when nil
+func acquire(ctx context.Context, sem chan struct{}) error {
+	if sem == nil {
+		return nil
+	}
+	select {
+	case sem <- struct{}{}:
+		return nil
+	default:
+	}
+	select {
+	case sem <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+}
+
+func releaseSlot(sem chan struct{}) {
+	if sem != nil {
+		<-sem
+	}
+}
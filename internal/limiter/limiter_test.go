@@ -0,0 +1,126 @@
+package limiter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiterPerTool(t *testing.T) {
+	ctx := context.Background()
+	l := New(0, 2, 10*time.Millisecond)
+
+	first, err := l.Acquire(ctx, "get_ticker")
+	require.NoError(t, err)
+	_, err = l.Acquire(ctx, "get_ticker")
+	require.NoError(t, err)
+
+	_, err = l.Acquire(ctx, "get_ticker")
+	assert.ErrorIs(t, err, ErrQueueTimeout)
+
+	_, err = l.Acquire(ctx, "get_balances")
+	assert.NoError(t, err, "other tools have their own slots")
+
+	first()
+	first()
+	_, err = l.Acquire(ctx, "get_ticker")
+	assert.NoError(t, err, "released slots can be reused, and releasing twice is harmless")
+	_, err = l.Acquire(ctx, "get_ticker")
+	assert.ErrorIs(t, err, ErrQueueTimeout)
+}
+
+func TestLimiterGlobal(t *testing.T) {
+	ctx := context.Background()
+	l := New(1, 0, 10*time.Millisecond)
+
+	release, err := l.Acquire(ctx, "get_ticker")
+	require.NoError(t, err)
+	_, err = l.Acquire(ctx, "get_balances")
+	assert.ErrorIs(t, err, ErrQueueTimeout)
+
+	release()
+	_, err = l.Acquire(ctx, "get_balances")
+	assert.NoError(t, err)
+}
+
+func TestLimiterQueues(t *testing.T) {
+	ctx := context.Background()
+	l := New(1, 1, time.Second)
+
+	release, err := l.Acquire(ctx, "get_ticker")
+	require.NoError(t, err)
+
+	acquired := make(chan error, 1)
+	go func() {
+		_, err := l.Acquire(ctx, "get_ticker")
+		acquired <- err
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("call ran over the limit")
+	case <-time.After(20 * time.Millisecond):
+	}
+	release()
+	assert.NoError(t, <-acquired, "queued calls run once a slot frees up")
+}
+
+func TestLimiterTimeoutReleasesToolSlot(t *testing.T) {
+	ctx := context.Background()
+	l := New(1, 1, 10*time.Millisecond)
+
+	release, err := l.Acquire(ctx, "get_ticker")
+	require.NoError(t, err)
+	_, err = l.Acquire(ctx, "get_balances")
+	require.ErrorIs(t, err, ErrQueueTimeout)
+
+	release()
+	_, err = l.Acquire(ctx, "get_balances")
+	assert.NoError(t, err, "a call that timed out on the overall limit gives back its tool slot")
+}
+
+func TestLimiterCancelled(t *testing.T) {
+	l := New(1, 0, 0)
+	_, err := l.Acquire(context.Background(), "get_ticker")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = l.Acquire(ctx, "get_ticker")
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestLimiterSetLimits(t *testing.T) {
+	ctx := context.Background()
+	l := New(1, 1, 10*time.Millisecond)
+
+	release, err := l.Acquire(ctx, "get_ticker")
+	require.NoError(t, err)
+
+	l.SetLimits(2, 0)
+	assert.Equal(t, 2, l.MaxCalls())
+	assert.Zero(t, l.MaxCallsPerTool())
+	_, err = l.Acquire(ctx, "get_ticker")
+	require.NoError(t, err)
+	_, err = l.Acquire(ctx, "get_ticker")
+	require.NoError(t, err, "the call running under the old limits does not count towards the new ones")
+	_, err = l.Acquire(ctx, "get_balances")
+	assert.ErrorIs(t, err, ErrQueueTimeout)
+
+	release()
+	_, err = l.Acquire(ctx, "get_balances")
+	assert.ErrorIs(t, err, ErrQueueTimeout, "releasing a slot of the old limits frees nothing under the new ones")
+}
+
+func TestNilLimiter(t *testing.T) {
+	var l *Limiter
+	release, err := l.Acquire(context.Background(), "get_ticker")
+	require.NoError(t, err)
+	release()
+	assert.Zero(t, l.MaxCalls())
+	assert.Zero(t, l.MaxCallsPerTool())
+	assert.Zero(t, l.QueueTimeout())
+}
@@ -25,6 +25,7 @@ import (
 	"context"
 	"log/slog"
 
+	"github.com/luno/luno-mcp/sdk"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
@@ -81,6 +82,41 @@ func (m *MultiHandler) WithGroup(name string) slog.Handler {
 	return NewMultiHandler(groupedHandlers...)
 }
 
+// ContextHandler is a handler that adds the request metadata stored in the
+// record's context (see sdk.RequestMetadata) to every record
+type ContextHandler struct {
+	handler slog.Handler
+}
+
+// NewContextHandler creates a handler that adds request metadata to records before forwarding them
+func NewContextHandler(handler slog.Handler) *ContextHandler {
+	return &ContextHandler{handler: handler}
+}
+
+// Enabled implements slog.Handler
+func (h *ContextHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+// Handle implements slog.Handler
+func (h *ContextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if md, ok := sdk.RequestMetadataFromContext(ctx); ok {
+		record = record.Clone()
+		record.AddAttrs(md.LogAttrs()...)
+	}
+	return h.handler.Handle(ctx, record)
+}
+
+// WithAttrs implements slog.Handler
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ContextHandler{handler: h.handler.WithAttrs(attrs)}
+}
+
+// WithGroup implements slog.Handler
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return &ContextHandler{handler: h.handler.WithGroup(name)}
+}
+
 // NotificationSender interface for sending notifications to clients
 type NotificationSender interface {
 	SendNotificationToAllClients(method string, params map[string]any)
@@ -89,11 +125,12 @@ type NotificationSender interface {
 // MCPNotificationHandler is a handler that sends logs as MCP notifications
 type MCPNotificationHandler struct {
 	s     NotificationSender
-	level slog.Level
+	level slog.Leveler
 }
 
-// NewMCPNotificationHandler creates a new handler that forwards logs to MCP clients
-func NewMCPNotificationHandler(s NotificationSender, level slog.Level) *MCPNotificationHandler {
+// NewMCPNotificationHandler creates a new handler that forwards logs to MCP clients.
+// Pass a *slog.LevelVar to change the level at runtime, see SetLevelHook.
+func NewMCPNotificationHandler(s NotificationSender, level slog.Leveler) *MCPNotificationHandler {
 	return &MCPNotificationHandler{
 		s:     s,
 		level: level,
@@ -102,7 +139,7 @@ func NewMCPNotificationHandler(s NotificationSender, level slog.Level) *MCPNotif
 
 // Enabled implements slog.Handler
 func (h *MCPNotificationHandler) Enabled(ctx context.Context, level slog.Level) bool {
-	return level >= h.level
+	return level >= h.level.Level()
 }
 
 // Handle implements slog.Handler
@@ -157,6 +194,37 @@ func slogLevelToMCPLevel(level slog.Level) mcp.LoggingLevel {
 	}
 }
 
+// mcpLevelToSlogLevel converts an MCP LoggingLevel to a slog.Level
+func mcpLevelToSlogLevel(level mcp.LoggingLevel) slog.Level {
+	switch level {
+	case mcp.LoggingLevelDebug:
+		return slog.LevelDebug
+	case mcp.LoggingLevelInfo, mcp.LoggingLevelNotice:
+		return slog.LevelInfo
+	case mcp.LoggingLevelWarning:
+		return slog.LevelWarn
+	default:
+		return slog.LevelError
+	}
+}
+
+// SetLevelHook returns a hook for logging/setLevel requests. It sets level, the
+// level of log notifications sent to clients, to the requested level, and logs
+// Luno API requests through httpDebug while it is debug. Below debug, httpDebug
+// goes back to how it was configured at startup. The level is shared by all
+// sessions, so the last client to set it wins.
+func SetLevelHook(level *slog.LevelVar, httpDebug *sdk.HTTPDebug) server.OnAfterSetLevelFunc {
+	configured := httpDebug.Enabled()
+	return func(ctx context.Context, id any, message *mcp.SetLevelRequest, result *mcp.EmptyResult) {
+		l := mcpLevelToSlogLevel(message.Params.Level)
+		level.Set(l)
+		httpDebug.SetEnabled(configured || l <= slog.LevelDebug)
+		slog.DebugContext(ctx, "MCP log level changed",
+			slog.String("level", string(message.Params.Level)),
+			slog.Bool("http_debug", httpDebug.Enabled()))
+	}
+}
+
 // LogRequestHook is the function registered for BeforeAny hook.
 // It logs all incoming requests at debug level.
 func LogRequestHook(ctx context.Context, id any, method mcp.MCPMethod, message any) {
@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/luno/luno-mcp/sdk"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/stretchr/testify/assert"
 )
@@ -62,6 +63,48 @@ func TestMCPNotificationHandlerEnabled(t *testing.T) {
 	assert.False(t, handler.Enabled(context.Background(), slog.LevelDebug), "Debug level should be disabled")
 }
 
+func TestSetLevelHook(t *testing.T) {
+	testCases := []struct {
+		name              string
+		configuredDebug   bool
+		level             mcp.LoggingLevel
+		expectedLevel     slog.Level
+		expectedHTTPDebug bool
+	}{
+		{"debug enables http debug", false, mcp.LoggingLevelDebug, slog.LevelDebug, true},
+		{"info leaves http debug off", false, mcp.LoggingLevelInfo, slog.LevelInfo, false},
+		{"notice maps to info", false, mcp.LoggingLevelNotice, slog.LevelInfo, false},
+		{"warning", false, mcp.LoggingLevelWarning, slog.LevelWarn, false},
+		{"critical maps to error", false, mcp.LoggingLevelCritical, slog.LevelError, false},
+		{"configured http debug stays on", true, mcp.LoggingLevelError, slog.LevelError, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			level := new(slog.LevelVar)
+			httpDebug := sdk.NewHTTPDebug(tc.configuredDebug)
+			hook := SetLevelHook(level, httpDebug)
+
+			req := &mcp.SetLevelRequest{Params: mcp.SetLevelParams{Level: tc.level}}
+			hook(context.Background(), 1, req, &mcp.EmptyResult{})
+
+			assert.Equal(t, tc.expectedLevel, level.Level())
+			assert.Equal(t, tc.expectedHTTPDebug, httpDebug.Enabled())
+		})
+	}
+}
+
+func TestMCPNotificationHandlerFollowsLevelVar(t *testing.T) {
+	level := new(slog.LevelVar)
+	handler := NewMCPNotificationHandler(&MockNotificationSender{}, level)
+	assert.False(t, handler.Enabled(context.Background(), slog.LevelDebug))
+
+	SetLevelHook(level, nil)(context.Background(), 1, &mcp.SetLevelRequest{
+		Params: mcp.SetLevelParams{Level: mcp.LoggingLevelDebug},
+	}, &mcp.EmptyResult{})
+	assert.True(t, handler.Enabled(context.Background(), slog.LevelDebug), "clients can lower the level at runtime")
+}
+
 func TestMCPNotificationHandlerHandleNotificationFormat(t *testing.T) {
 	mockS := new(MockNotificationSender)
 	handler := NewMCPNotificationHandler(mockS, slog.LevelDebug) // Enable all levels for this test
@@ -171,6 +214,27 @@ func TestMultiHandlerWithGroup(t *testing.T) {
 	assert.True(t, strings.Contains(buf1.String(), "attrValue"), "Log output should contain the attribute value")
 }
 
+func TestContextHandlerAddsRequestMetadata(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewContextHandler(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	logger := slog.New(h.WithAttrs([]slog.Attr{slog.String("component", "test")}))
+
+	logger.InfoContext(context.Background(), testMessageDefault)
+	assert.NotContains(t, buf.String(), "request_id", "no metadata without a request context")
+
+	buf.Reset()
+	ctx := sdk.ContextWithRequestMetadata(context.Background(), sdk.RequestMetadata{
+		RequestID:  "req-1",
+		SessionID:  "session-1",
+		ClientName: "cursor",
+	})
+	logger.InfoContext(ctx, testMessageDefault)
+	assert.Contains(t, buf.String(), `"request_id":"req-1"`)
+	assert.Contains(t, buf.String(), `"session_id":"session-1"`)
+	assert.Contains(t, buf.String(), `"client_name":"cursor"`)
+	assert.Contains(t, buf.String(), jsonTestComponentAttr)
+}
+
 func TestLoggingHooksExecution(t *testing.T) {
 	var logOutput bytes.Buffer
 	logger := slog.New(slog.NewJSONHandler(&logOutput, &slog.HandlerOptions{Level: slog.LevelDebug}))
@@ -0,0 +1,125 @@
+// Package loopguard detects an agent calling the same tool with the same
+// arguments over and over, a common failure mode where a model loops on a
+// result it does not understand. Once identical calls pass a threshold within
+// a window, the previous result is served again instead of calling Luno, for
+// at most a window after it was fetched.
+package loopguard
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultThreshold is how many identical calls within the window run
+	// normally before the previous result is reused
+	DefaultThreshold = 5
+	// DefaultWindow is how long identical calls are counted for
+	DefaultWindow = time.Minute
+)
+
+// Guard counts identical calls by key. A nil Guard, or one with a zero
+// threshold, never reuses results. It is safe for concurrent use.
+type Guard struct {
+	threshold int
+	window    time.Duration
+	now       func() time.Time
+
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+type call struct {
+	times  []time.Time
+	result any
+	// stored is when result was stored
+	stored time.Time
+}
+
+// New creates a Guard that reuses the previous result once more than
+// threshold identical calls are made within window
+func New(threshold int, window time.Duration) *Guard {
+	return &Guard{
+		threshold: threshold,
+		window:    window,
+		now:       time.Now,
+		calls:     make(map[string]*call),
+	}
+}
+
+// Threshold returns how many identical calls run before results are reused,
+// zero if they never are
+func (g *Guard) Threshold() int {
+	if g == nil {
+		return 0
+	}
+	return g.threshold
+}
+
+// Window returns how long identical calls are counted for
+func (g *Guard) Window() time.Duration {
+	if g == nil {
+		return 0
+	}
+	return g.window
+}
+
+// Observe records a call with key and returns how many identical calls,
+// including this one, were made within the window. If that is over the
+// threshold and a previous result was stored within the window, it is
+// returned with ok true and the call should not be made again. Older results
+// are not reused, so a client that keeps repeating a call still gets data at
+// most a window old.
+func (g *Guard) Observe(key string) (count int, result any, ok bool) {
+	if g == nil || g.threshold <= 0 {
+		return 0, nil, false
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := g.now()
+	g.expire(now)
+
+	c := g.calls[key]
+	if c == nil {
+		c = &call{}
+		g.calls[key] = c
+	}
+	c.times = append(c.times, now)
+	count = len(c.times)
+	if count > g.threshold && c.result != nil && now.Sub(c.stored) < g.window {
+		return count, c.result, true
+	}
+	return count, nil, false
+}
+
+// Store keeps result as the one to reuse for further calls with key
+func (g *Guard) Store(key string, result any) {
+	if g == nil || g.threshold <= 0 {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if c := g.calls[key]; c != nil {
+		c.result = result
+		c.stored = g.now()
+	}
+}
+
+// expire drops calls older than the window, and keys with no calls left
+func (g *Guard) expire(now time.Time) {
+	cutoff := now.Add(-g.window)
+	for key, c := range g.calls {
+		i := 0
+		for i < len(c.times) && !c.times[i].After(cutoff) {
+			i++
+		}
+		c.times = c.times[i:]
+		if len(c.times) == 0 {
+			delete(g.calls, key)
+		}
+	}
+}
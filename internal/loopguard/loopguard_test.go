@@ -0,0 +1,83 @@
+package loopguard
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGuardObserve(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	g := New(2, time.Minute)
+	g.now = func() time.Time { return now }
+
+	for i := 1; i <= 2; i++ {
+		count, _, ok := g.Observe("get_ticker")
+		assert.Equal(t, i, count)
+		assert.False(t, ok)
+		g.Store("get_ticker", i)
+	}
+
+	count, result, ok := g.Observe("get_ticker")
+	assert.Equal(t, 3, count)
+	assert.True(t, ok)
+	assert.Equal(t, 2, result, "the last stored result is reused")
+
+	_, _, ok = g.Observe("get_balances")
+	assert.False(t, ok, "other keys are counted separately")
+
+	now = now.Add(time.Minute)
+	count, _, ok = g.Observe("get_ticker")
+	assert.Equal(t, 1, count, "calls outside the window are forgotten")
+	assert.False(t, ok)
+}
+
+func TestGuardStaleResult(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	g := New(1, time.Minute)
+	g.now = func() time.Time { return now }
+
+	g.Observe("get_ticker")
+	g.Store("get_ticker", "first")
+	for range 2 {
+		now = now.Add(20 * time.Second)
+		_, result, ok := g.Observe("get_ticker")
+		assert.True(t, ok)
+		assert.Equal(t, "first", result)
+	}
+
+	// Repeated calls keep the count over the threshold, but the result is a
+	// window old
+	now = now.Add(20 * time.Second)
+	count, _, ok := g.Observe("get_ticker")
+	assert.Equal(t, 3, count)
+	assert.False(t, ok, "a result a window old is not reused")
+	g.Store("get_ticker", "second")
+
+	now = now.Add(20 * time.Second)
+	_, result, ok := g.Observe("get_ticker")
+	assert.True(t, ok)
+	assert.Equal(t, "second", result, "the fresh result is reused next")
+}
+
+func TestGuardNothingStored(t *testing.T) {
+	g := New(1, time.Minute)
+	g.Observe("list_orders")
+	count, _, ok := g.Observe("list_orders")
+	assert.Equal(t, 2, count)
+	assert.False(t, ok, "there is no result to reuse until one is stored")
+}
+
+func TestGuardDisabled(t *testing.T) {
+	for name, g := range map[string]*Guard{"nil": nil, "zero threshold": New(0, time.Minute)} {
+		t.Run(name, func(t *testing.T) {
+			for range 10 {
+				g.Store("get_ticker", "result")
+				_, _, ok := g.Observe("get_ticker")
+				assert.False(t, ok)
+			}
+			assert.Zero(t, g.Threshold())
+		})
+	}
+}
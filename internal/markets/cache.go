@@ -0,0 +1,190 @@
+// Package markets caches Luno market metadata so that tools can validate and
+// complete currency pairs without calling the API on every request.
+package markets
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/usage"
+	"github.com/luno/luno-mcp/sdk"
+)
+
+const (
+	// DefaultRefreshInterval is how often Run reloads the market list
+	DefaultRefreshInterval = 15 * time.Minute
+	// missRefreshAge is how old the cache must be before a lookup for an unknown
+	// pair reloads it, so that newly listed markets are found between refreshes
+	missRefreshAge = time.Minute
+)
+
+// Cache holds the market list. Lookups load it on first use, and Run keeps it
+// fresh in the background. It is safe for concurrent use.
+type Cache struct {
+	client   sdk.LunoClient
+	interval time.Duration
+	now      func() time.Time
+
+	// loadMu serialises loads so that concurrent cold lookups make one request
+	loadMu sync.Mutex
+
+	mu      sync.RWMutex
+	markets map[string]luno.MarketInfo
+	// list has the markets ordered by pair, ready to copy for Cached
+	list    []luno.MarketInfo
+	updated time.Time
+}
+
+// NewCache creates an empty Cache that loads markets with client
+func NewCache(client sdk.LunoClient) *Cache {
+	return &Cache{
+		client:   client,
+		interval: DefaultRefreshInterval,
+		now:      time.Now,
+	}
+}
+
+// Run loads the market list and reloads it every DefaultRefreshInterval until
+// ctx is cancelled. Failed reloads are logged and the previous list is kept.
+func (c *Cache) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		if err := c.Refresh(ctx); err != nil {
+			slog.WarnContext(ctx, "Failed to refresh markets", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh reloads the market list
+func (c *Cache) Refresh(ctx context.Context) error {
+	c.loadMu.Lock()
+	defer c.loadMu.Unlock()
+	return c.load(ctx)
+}
+
+// Markets returns every market ordered by pair, loading them if needed
+func (c *Cache) Markets(ctx context.Context) ([]luno.MarketInfo, error) {
+	loaded, err := c.ensureLoaded(ctx)
+	if err != nil {
+		return nil, err
+	}
+	usage.SessionFromContext(ctx).CacheLookup(!loaded)
+	list, _ := c.Cached()
+	return list, nil
+}
+
+// Cached returns the markets from the last successful load and when it happened.
+// It never calls the API, so it returns nothing until the list has been loaded.
+func (c *Cache) Cached() ([]luno.MarketInfo, time.Time) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return slices.Clone(c.list), c.updated
+}
+
+// Market returns the cached market for pair. It never calls the API, so it
+// finds nothing until the list has been loaded.
+func (c *Cache) Market(pair string) (luno.MarketInfo, bool) {
+	return c.get(pair)
+}
+
+// Lookup returns the market for pair, loading the market list if needed. A pair
+// that is not in a list more than a minute old reloads it once before giving up.
+func (c *Cache) Lookup(ctx context.Context, pair string) (luno.MarketInfo, bool, error) {
+	loaded, err := c.ensureLoaded(ctx)
+	if err != nil {
+		return luno.MarketInfo{}, false, err
+	}
+	if m, ok := c.get(pair); ok {
+		usage.SessionFromContext(ctx).CacheLookup(!loaded)
+		return m, true, nil
+	}
+
+	c.loadMu.Lock()
+	defer c.loadMu.Unlock()
+	if c.now().Sub(c.updatedAt()) >= missRefreshAge {
+		if err := c.load(ctx); err != nil {
+			return luno.MarketInfo{}, false, err
+		}
+		loaded = true
+	}
+	usage.SessionFromContext(ctx).CacheLookup(!loaded)
+	m, ok := c.get(pair)
+	return m, ok, nil
+}
+
+// Complete returns the cached pairs that start with prefix, ignoring case. It
+// never calls the API, so it returns nothing until the list has been loaded.
+func (c *Cache) Complete(prefix string) []string {
+	prefix = strings.ToUpper(prefix)
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var matches []string
+	for _, m := range c.list {
+		if strings.HasPrefix(m.MarketId, prefix) {
+			matches = append(matches, m.MarketId)
+		}
+	}
+	return matches
+}
+
+// ensureLoaded loads the market list if it has never been loaded, reporting
+// whether it did
+func (c *Cache) ensureLoaded(ctx context.Context) (bool, error) {
+	if !c.updatedAt().IsZero() {
+		return false, nil
+	}
+	c.loadMu.Lock()
+	defer c.loadMu.Unlock()
+	if !c.updatedAt().IsZero() {
+		return false, nil
+	}
+	return true, c.load(ctx)
+}
+
+// load fetches the market list. The caller must hold loadMu.
+func (c *Cache) load(ctx context.Context) error {
+	res, err := c.client.Markets(ctx, &luno.MarketsRequest{})
+	if err != nil {
+		return fmt.Errorf("listing markets: %w", err)
+	}
+
+	markets := make(map[string]luno.MarketInfo, len(res.Markets))
+	for _, m := range res.Markets {
+		markets[m.MarketId] = m
+	}
+	list := slices.Collect(maps.Values(markets))
+	slices.SortFunc(list, func(a, b luno.MarketInfo) int { return strings.Compare(a.MarketId, b.MarketId) })
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.markets = markets
+	c.list = list
+	c.updated = c.now()
+	return nil
+}
+
+func (c *Cache) get(pair string) (luno.MarketInfo, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	m, ok := c.markets[pair]
+	return m, ok
+}
+
+func (c *Cache) updatedAt() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.updated
+}
@@ -0,0 +1,164 @@
+package markets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/usage"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var testMarkets = &luno.MarketsResponse{Markets: []luno.MarketInfo{
+	{MarketId: "XBTZAR", TradingStatus: luno.TradingStatusActive},
+	{MarketId: "ETHZAR", TradingStatus: luno.TradingStatusActive},
+	{MarketId: "XBTEUR", TradingStatus: luno.TradingStatusSuspended},
+}}
+
+func newTestCache(t *testing.T) (*Cache, *sdk.MockLunoClient, *time.Time) {
+	client := sdk.NewMockLunoClient(t)
+	now := time.Date(2026, 10, 15, 8, 0, 0, 0, time.UTC)
+	c := NewCache(client)
+	c.now = func() time.Time { return now }
+	return c, client, &now
+}
+
+func TestCacheMarkets(t *testing.T) {
+	ctx := context.Background()
+	c, client, _ := newTestCache(t)
+	client.EXPECT().Markets(ctx, &luno.MarketsRequest{}).Return(testMarkets, nil).Once()
+
+	list, err := c.Markets(ctx)
+	require.NoError(t, err)
+	var pairs []string
+	for _, m := range list {
+		pairs = append(pairs, m.MarketId)
+	}
+	assert.Equal(t, []string{"ETHZAR", "XBTEUR", "XBTZAR"}, pairs)
+
+	_, err = c.Markets(ctx)
+	require.NoError(t, err, "the second call is served from the cache")
+}
+
+func TestCacheLookup(t *testing.T) {
+	session := usage.NewTracker().Session("s1")
+	ctx := usage.ContextWithSession(context.Background(), session)
+	c, client, now := newTestCache(t)
+	client.EXPECT().Markets(ctx, &luno.MarketsRequest{}).Return(testMarkets, nil).Once()
+
+	m, ok, err := c.Lookup(ctx, "XBTEUR")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, luno.TradingStatusSuspended, m.TradingStatus)
+
+	_, ok, err = c.Lookup(ctx, "SOLZAR")
+	require.NoError(t, err)
+	assert.False(t, ok, "a fresh cache is not reloaded for unknown pairs")
+
+	*now = now.Add(missRefreshAge)
+	listed := &luno.MarketsResponse{Markets: append(testMarkets.Markets, luno.MarketInfo{MarketId: "SOLZAR"})}
+	client.EXPECT().Markets(ctx, &luno.MarketsRequest{}).Return(listed, nil).Once()
+	_, ok, err = c.Lookup(ctx, "SOLZAR")
+	require.NoError(t, err)
+	assert.True(t, ok, "newly listed markets are found after a reload")
+	assert.Equal(t, usage.CacheStats{Hits: 1, Misses: 2, HitRate: 1.0 / 3}, session.Stats().Cache)
+}
+
+func TestCacheLoadError(t *testing.T) {
+	ctx := context.Background()
+	c, client, _ := newTestCache(t)
+	client.EXPECT().Markets(ctx, &luno.MarketsRequest{}).Return(nil, errors.New("unavailable")).Once()
+
+	_, _, err := c.Lookup(ctx, "XBTZAR")
+	assert.ErrorContains(t, err, "listing markets: unavailable")
+
+	client.EXPECT().Markets(ctx, &luno.MarketsRequest{}).Return(testMarkets, nil).Once()
+	_, ok, err := c.Lookup(ctx, "XBTZAR")
+	require.NoError(t, err)
+	assert.True(t, ok, "a failed load is retried on the next lookup")
+}
+
+func TestCacheRefreshKeepsPreviousList(t *testing.T) {
+	ctx := context.Background()
+	c, client, _ := newTestCache(t)
+	client.EXPECT().Markets(ctx, &luno.MarketsRequest{}).Return(testMarkets, nil).Once()
+	require.NoError(t, c.Refresh(ctx))
+
+	client.EXPECT().Markets(ctx, &luno.MarketsRequest{}).Return(nil, errors.New("unavailable")).Once()
+	assert.Error(t, c.Refresh(ctx))
+	assert.Equal(t, []string{"XBTEUR", "XBTZAR"}, c.Complete("xbt"))
+}
+
+func TestCacheCached(t *testing.T) {
+	ctx := context.Background()
+	c, client, now := newTestCache(t)
+	list, updated := c.Cached()
+	assert.Empty(t, list, "the cache is never loaded by Cached")
+	assert.True(t, updated.IsZero())
+
+	client.EXPECT().Markets(ctx, &luno.MarketsRequest{}).Return(testMarkets, nil).Once()
+	require.NoError(t, c.Refresh(ctx))
+	list, updated = c.Cached()
+	assert.Len(t, list, 3)
+	assert.Equal(t, *now, updated)
+}
+
+func TestCacheComplete(t *testing.T) {
+	ctx := context.Background()
+	c, client, _ := newTestCache(t)
+	assert.Empty(t, c.Complete(""), "completions never load the list")
+
+	client.EXPECT().Markets(ctx, &luno.MarketsRequest{}).Return(testMarkets, nil).Once()
+	require.NoError(t, c.Refresh(ctx))
+
+	assert.Equal(t, []string{"ETHZAR", "XBTEUR", "XBTZAR"}, c.Complete(""))
+	assert.Equal(t, []string{"XBTEUR", "XBTZAR"}, c.Complete("xb"))
+	assert.Empty(t, c.Complete("DOGE"))
+}
+
+// benchmarkCache returns a cache loaded with pairs markets
+func benchmarkCache(b *testing.B, pairs int) *Cache {
+	res := &luno.MarketsResponse{}
+	for i := range pairs {
+		res.Markets = append(res.Markets, luno.MarketInfo{MarketId: fmt.Sprintf("C%03dZAR", i), TradingStatus: luno.TradingStatusActive})
+	}
+	client := sdk.NewMockLunoClient(b)
+	client.EXPECT().Markets(mock.Anything, &luno.MarketsRequest{}).Return(res, nil).Once()
+	c := NewCache(client)
+	require.NoError(b, c.Refresh(context.Background()))
+	return c
+}
+
+func BenchmarkCache(b *testing.B) {
+	ctx := usage.ContextWithSession(context.Background(), usage.NewTracker().Session("s1"))
+	c := benchmarkCache(b, 200)
+
+	b.Run("Lookup", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, ok, err := c.Lookup(ctx, "C100ZAR"); !ok || err != nil {
+				b.Fatal(ok, err)
+			}
+		}
+	})
+	b.Run("Markets", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := c.Markets(ctx); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Complete", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			c.Complete("c1")
+		}
+	})
+}
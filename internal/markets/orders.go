@@ -0,0 +1,74 @@
+package markets
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+)
+
+var (
+	// ErrVolumeBelowMinimum is wrapped by CheckOrder errors for orders smaller
+	// than the market's minimum volume
+	ErrVolumeBelowMinimum = errors.New("volume below the market minimum")
+	// ErrVolumeAboveMaximum is wrapped by CheckOrder errors for orders larger
+	// than the market's maximum volume
+	ErrVolumeAboveMaximum = errors.New("volume above the market maximum")
+)
+
+// limitError keeps CheckOrder's message while letting callers match the limit
+// that was broken with errors.Is
+type limitError struct {
+	msg string
+	err error
+}
+
+func (e *limitError) Error() string { return e.msg }
+
+func (e *limitError) Unwrap() error { return e.err }
+
+// CheckOrder validates a limit order against the market's trading status and
+// limits, so that obviously invalid orders are rejected before reaching Luno
+func CheckOrder(m luno.MarketInfo, volume, price decimal.Decimal) error {
+	if m.TradingStatus == luno.TradingStatusSuspended {
+		return fmt.Errorf("trading on %s is suspended and new orders are rejected until it resumes, use get_exchange_status to check market status", m.MarketId)
+	}
+	if m.MinVolume.Sign() > 0 && volume.Cmp(m.MinVolume) < 0 {
+		return &limitError{
+			msg: fmt.Sprintf("volume %s is below the minimum of %s for %s", volume, m.MinVolume, m.MarketId),
+			err: ErrVolumeBelowMinimum,
+		}
+	}
+	if m.MaxVolume.Sign() > 0 && volume.Cmp(m.MaxVolume) > 0 {
+		return &limitError{
+			msg: fmt.Sprintf("volume %s is above the maximum of %s for %s", volume, m.MaxVolume, m.MarketId),
+			err: ErrVolumeAboveMaximum,
+		}
+	}
+	if m.MinPrice.Sign() > 0 && price.Cmp(m.MinPrice) < 0 {
+		return fmt.Errorf("price %s is below the minimum of %s for %s", price, m.MinPrice, m.MarketId)
+	}
+	if m.MaxPrice.Sign() > 0 && price.Cmp(m.MaxPrice) > 0 {
+		return fmt.Errorf("price %s is above the maximum of %s for %s", price, m.MaxPrice, m.MarketId)
+	}
+	return CheckScales(m, volume, price)
+}
+
+// CheckScales reports a volume or price with more decimal places than the
+// market allows. Orders split into several, such as TWAP and iceberg orders,
+// check their total volume with it, as only the parts are placed.
+func CheckScales(m luno.MarketInfo, volume, price decimal.Decimal) error {
+	// Every market trades fractional volumes, so a zero volume scale means the
+	// market didn't report its scales
+	if m.VolumeScale == 0 {
+		return nil
+	}
+	if volume.ToScale(int(m.VolumeScale)).Cmp(volume) != 0 {
+		return fmt.Errorf("volume %s has more than the %d decimal places allowed for %s", volume, m.VolumeScale, m.MarketId)
+	}
+	if price.ToScale(int(m.PriceScale)).Cmp(price) != 0 {
+		return fmt.Errorf("price %s has more than the %d decimal places allowed for %s", price, m.PriceScale, m.MarketId)
+	}
+	return nil
+}
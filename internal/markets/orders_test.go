@@ -0,0 +1,67 @@
+package markets
+
+import (
+	"testing"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func dec(t *testing.T, s string) decimal.Decimal {
+	t.Helper()
+	d, err := decimal.NewFromString(s)
+	require.NoError(t, err)
+	return d
+}
+
+func TestCheckOrder(t *testing.T) {
+	market := luno.MarketInfo{
+		MarketId:      "XBTZAR",
+		TradingStatus: luno.TradingStatusActive,
+		VolumeScale:   4,
+		PriceScale:    0,
+		MinVolume:     dec(t, "0.0005"),
+		MaxVolume:     dec(t, "100"),
+		MinPrice:      dec(t, "1"),
+		MaxPrice:      dec(t, "10000000"),
+	}
+
+	tests := []struct {
+		name          string
+		modify        func(m *luno.MarketInfo)
+		volume        string
+		price         string
+		expectedError string
+		expectedIs    error
+	}{
+		{name: "valid", volume: "0.01", price: "1000000"},
+		{name: "suspended", modify: func(m *luno.MarketInfo) { m.TradingStatus = luno.TradingStatusSuspended }, volume: "0.01", price: "1000000", expectedError: "trading on XBTZAR is suspended"},
+		{name: "volume below minimum", volume: "0.0001", price: "1000000", expectedError: "volume 0.0001 is below the minimum of 0.0005 for XBTZAR", expectedIs: ErrVolumeBelowMinimum},
+		{name: "volume above maximum", volume: "101", price: "1000000", expectedError: "volume 101 is above the maximum of 100 for XBTZAR", expectedIs: ErrVolumeAboveMaximum},
+		{name: "price below minimum", volume: "0.01", price: "0.5", expectedError: "price 0.5 is below the minimum of 1 for XBTZAR"},
+		{name: "price above maximum", volume: "0.01", price: "20000000", expectedError: "price 20000000 is above the maximum of 10000000 for XBTZAR"},
+		{name: "volume scale", volume: "0.01001", price: "1000000", expectedError: "volume 0.01001 has more than the 4 decimal places allowed for XBTZAR"},
+		{name: "price scale", volume: "0.01", price: "1000000.5", expectedError: "price 1000000.5 has more than the 0 decimal places allowed for XBTZAR"},
+		{name: "scales not reported", modify: func(m *luno.MarketInfo) { m.VolumeScale = 0 }, volume: "0.01001", price: "1000000.5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := market
+			if tt.modify != nil {
+				tt.modify(&m)
+			}
+			err := CheckOrder(m, dec(t, tt.volume), dec(t, tt.price))
+			if tt.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.expectedError)
+			if tt.expectedIs != nil {
+				assert.ErrorIs(t, err, tt.expectedIs)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package markets
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/usage"
+	"github.com/luno/luno-mcp/sdk"
+)
+
+// DefaultTickerInterval is how often Tickers.Run reloads the tickers
+const DefaultTickerInterval = 30 * time.Second
+
+// Tickers holds the tickers of every market, loaded with a single request.
+// Reads load them on first use, and Run keeps them fresh in the background. It
+// is safe for concurrent use.
+type Tickers struct {
+	client   sdk.LunoClient
+	interval time.Duration
+	now      func() time.Time
+
+	// loadMu serialises loads so that concurrent cold reads make one request
+	loadMu sync.Mutex
+
+	mu      sync.RWMutex
+	tickers []luno.Ticker
+	updated time.Time
+}
+
+// NewTickers creates an empty Tickers that loads tickers with client every
+// interval while Run is running
+func NewTickers(client sdk.LunoClient, interval time.Duration) *Tickers {
+	return &Tickers{
+		client:   client,
+		interval: interval,
+		now:      time.Now,
+	}
+}
+
+// Interval returns how often Run reloads the tickers, or zero if it doesn't
+func (t *Tickers) Interval() time.Duration {
+	return t.interval
+}
+
+// Run loads the tickers and reloads them every interval until ctx is
+// cancelled, calling updated after each successful load. Failed reloads are
+// logged and the previous tickers are kept. It returns at once if the
+// interval is not positive.
+func (t *Tickers) Run(ctx context.Context, updated func()) {
+	if t.interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+	for {
+		if err := t.Refresh(ctx); err != nil {
+			slog.WarnContext(ctx, "Failed to refresh tickers", "error", err)
+		} else if updated != nil {
+			updated()
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh reloads the tickers
+func (t *Tickers) Refresh(ctx context.Context) error {
+	t.loadMu.Lock()
+	defer t.loadMu.Unlock()
+	return t.load(ctx)
+}
+
+// All returns the tickers ordered by pair and when they were loaded, loading
+// them if they never have been
+func (t *Tickers) All(ctx context.Context) ([]luno.Ticker, time.Time, error) {
+	var loaded bool
+	if t.updatedAt().IsZero() {
+		t.loadMu.Lock()
+		var err error
+		if t.updatedAt().IsZero() {
+			err = t.load(ctx)
+			loaded = true
+		}
+		t.loadMu.Unlock()
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+	}
+	usage.SessionFromContext(ctx).CacheLookup(!loaded)
+	list, updated := t.Cached()
+	return list, updated, nil
+}
+
+// Cached returns the tickers from the last successful load and when it
+// happened. It never calls the API, so it returns nothing until they have
+// been loaded.
+func (t *Tickers) Cached() ([]luno.Ticker, time.Time) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return slices.Clone(t.tickers), t.updated
+}
+
+// load fetches the tickers. The caller must hold loadMu.
+func (t *Tickers) load(ctx context.Context) error {
+	res, err := t.client.GetTickers(ctx, &luno.GetTickersRequest{})
+	if err != nil {
+		return fmt.Errorf("getting tickers: %w", err)
+	}
+	tickers := slices.Clone(res.Tickers)
+	slices.SortFunc(tickers, func(a, b luno.Ticker) int { return strings.Compare(a.Pair, b.Pair) })
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.tickers = tickers
+	t.updated = t.now()
+	return nil
+}
+
+func (t *Tickers) updatedAt() time.Time {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.updated
+}
@@ -0,0 +1,109 @@
+package markets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/usage"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var testTickers = &luno.GetTickersResponse{Tickers: []luno.Ticker{
+	{Pair: "XBTZAR", Status: luno.StatusActive},
+	{Pair: "ETHZAR", Status: luno.StatusActive},
+}}
+
+func TestTickersAll(t *testing.T) {
+	session := usage.NewTracker().Session("s1")
+	ctx := usage.ContextWithSession(context.Background(), session)
+	client := sdk.NewMockLunoClient(t)
+	now := time.Date(2026, 10, 15, 8, 0, 0, 0, time.UTC)
+	tk := NewTickers(client, DefaultTickerInterval)
+	tk.now = func() time.Time { return now }
+
+	_, updated := tk.Cached()
+	assert.True(t, updated.IsZero(), "nothing is cached before the first load")
+
+	client.EXPECT().GetTickers(ctx, &luno.GetTickersRequest{}).Return(testTickers, nil).Once()
+	list, updated, err := tk.All(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, now, updated)
+	require.Len(t, list, 2)
+	assert.Equal(t, "ETHZAR", list[0].Pair)
+	assert.Equal(t, "XBTZAR", list[1].Pair)
+
+	_, _, err = tk.All(ctx)
+	require.NoError(t, err, "the second read is served from the cache")
+	assert.Equal(t, usage.CacheStats{Hits: 1, Misses: 1, HitRate: 0.5}, session.Stats().Cache)
+}
+
+func TestTickersRefreshError(t *testing.T) {
+	ctx := context.Background()
+	client := sdk.NewMockLunoClient(t)
+	tk := NewTickers(client, DefaultTickerInterval)
+
+	client.EXPECT().GetTickers(ctx, &luno.GetTickersRequest{}).Return(testTickers, nil).Once()
+	require.NoError(t, tk.Refresh(ctx))
+
+	client.EXPECT().GetTickers(ctx, &luno.GetTickersRequest{}).Return(nil, errors.New("unavailable")).Once()
+	assert.ErrorContains(t, tk.Refresh(ctx), "getting tickers: unavailable")
+	list, _ := tk.Cached()
+	assert.Len(t, list, 2, "the previous tickers are kept")
+}
+
+func TestTickersRun(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := sdk.NewMockLunoClient(t)
+	tk := NewTickers(client, time.Millisecond)
+
+	updates := make(chan struct{})
+	client.EXPECT().GetTickers(ctx, &luno.GetTickersRequest{}).Return(testTickers, nil)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		tk.Run(ctx, func() { updates <- struct{}{} })
+	}()
+
+	<-updates
+	<-updates
+	cancel()
+	// Let Run return even if it refreshed again before seeing the cancellation
+	go func() {
+		for range updates {
+		}
+	}()
+	<-done
+	close(updates)
+}
+
+func TestTickersRunDisabled(t *testing.T) {
+	tk := NewTickers(sdk.NewMockLunoClient(t), 0)
+	tk.Run(context.Background(), func() { t.Error("unexpected update") })
+}
+
+func BenchmarkTickersAll(b *testing.B) {
+	res := &luno.GetTickersResponse{}
+	for i := range 200 {
+		res.Tickers = append(res.Tickers, luno.Ticker{Pair: fmt.Sprintf("C%03dZAR", i), Status: luno.StatusActive})
+	}
+	client := sdk.NewMockLunoClient(b)
+	client.EXPECT().GetTickers(mock.Anything, &luno.GetTickersRequest{}).Return(res, nil).Once()
+	tk := NewTickers(client, DefaultTickerInterval)
+	ctx := context.Background()
+	require.NoError(b, tk.Refresh(ctx))
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, _, err := tk.All(ctx); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package orders
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/events"
+	"github.com/luno/luno-mcp/sdk"
+)
+
+// notificationLogger names the source of order notifications
+const notificationLogger = "luno-mcp/orders"
+
+// Reconciliation is the state on Luno of the tracked orders that were open
+type Reconciliation struct {
+	CheckedAt time.Time `json:"checked_at"`
+	// Open orders are still on the order book, possibly part filled
+	Open []Order `json:"open"`
+	// Filled orders have left the order book after trading, fully or in part
+	Filled []Order `json:"filled"`
+	// Cancelled orders have left the order book without trading
+	Cancelled []Order `json:"cancelled"`
+	// Errors are the orders that could not be checked. They are checked again
+	// next time.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// Reconcile checks the open orders of tenant in store against Luno, with
+// client holding the tenant's credentials, and saves their state
+func Reconcile(ctx context.Context, store *Store, client sdk.LunoClient, tenant string) (Reconciliation, error) {
+	r := Reconciliation{CheckedAt: time.Now().UTC()}
+	open, err := store.Open(tenant)
+	if err != nil || len(open) == 0 {
+		return r, err
+	}
+
+	res, err := client.ListOrders(ctx, &luno.ListOrdersRequest{State: luno.OrderStatePending})
+	if err != nil {
+		return r, fmt.Errorf("listing open orders: %w", err)
+	}
+	pending := make(map[string]luno.Order, len(res.Orders))
+	for _, o := range res.Orders {
+		pending[o.OrderId] = o
+	}
+
+	var updated []Order
+	for _, o := range open {
+		if p, ok := pending[o.OrderID]; ok {
+			o.State, o.Base, o.Counter = p.State, p.Base, p.Counter
+		} else {
+			// Orders that have left the order book, or are past the end of the list
+			got, err := client.GetOrder(ctx, &luno.GetOrderRequest{Id: o.OrderID})
+			if err != nil {
+				r.Errors = append(r.Errors, fmt.Sprintf("getting order %s: %v", o.OrderID, err))
+				continue
+			}
+			o.State, o.Base, o.Counter = got.State, got.Base, got.Counter
+		}
+		o.CheckedAt = r.CheckedAt
+		updated = append(updated, o)
+
+		switch {
+		case o.Open():
+			r.Open = append(r.Open, o)
+		case o.Base.Sign() > 0:
+			r.Filled = append(r.Filled, o)
+		default:
+			r.Cancelled = append(r.Cancelled, o)
+		}
+	}
+	if err := store.Update(updated...); err != nil {
+		return r, err
+	}
+	return r, nil
+}
+
+// Monitor watches the tracked orders placed with the server's own
+// credentials until they complete, telling connected MCP clients when they
+// do. The first check reports what happened to the orders placed before the
+// server started. Orders of tenants are left to reconcile_orders.
+type Monitor struct {
+	store    *Store
+	client   sdk.LunoClient
+	notifier events.Notifier
+	interval time.Duration
+}
+
+// NewMonitor creates a Monitor that checks store against client every
+// events.DefaultWatchInterval. The notifier can be nil.
+func NewMonitor(store *Store, client sdk.LunoClient, notifier events.Notifier) *Monitor {
+	return &Monitor{
+		store:    store,
+		client:   client,
+		notifier: notifier,
+		interval: events.DefaultWatchInterval,
+	}
+}
+
+// Run checks the orders until ctx is cancelled
+func (m *Monitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for restarted := true; ; {
+		r, err := Reconcile(ctx, m.store, m.client, "")
+		if err != nil {
+			slog.WarnContext(ctx, "Failed to check tracked orders", "error", err)
+		} else {
+			m.report(ctx, r, restarted)
+			restarted = false
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// report logs and notifies the orders that completed. After a restart it also
+// logs the orders that are still open, since no session is watching them.
+func (m *Monitor) report(ctx context.Context, r Reconciliation, restarted bool) {
+	when := ""
+	if restarted {
+		when = " while the server was not running"
+		if len(r.Open) > 0 {
+			slog.InfoContext(ctx, "Resumed monitoring orders placed before the server started", "open_orders", len(r.Open))
+		}
+	}
+	for _, o := range r.Filled {
+		m.notify(ctx, o, fmt.Sprintf("Order %s on %s filled%s: %s base for %s counter", o.OrderID, o.Pair, when, o.Base, o.Counter))
+	}
+	for _, o := range r.Cancelled {
+		m.notify(ctx, o, fmt.Sprintf("Order %s on %s was cancelled without trading%s", o.OrderID, o.Pair, when))
+	}
+	for _, e := range r.Errors {
+		slog.WarnContext(ctx, "Failed to check tracked order", "error", e)
+	}
+}
+
+func (m *Monitor) notify(ctx context.Context, o Order, message string) {
+	slog.InfoContext(ctx, message, "order_id", o.OrderID, "tool", o.Tool)
+	if m.notifier == nil {
+		return
+	}
+	m.notifier.SendNotificationToAllClients("notifications/message", map[string]any{
+		"level":  "info",
+		"logger": notificationLogger,
+		"data": map[string]any{
+			"message": message,
+			"order":   o,
+		},
+	})
+}
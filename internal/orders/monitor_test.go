@@ -0,0 +1,125 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type notifierFunc func(method string, params map[string]any)
+
+func (f notifierFunc) SendNotificationToAllClients(method string, params map[string]any) {
+	f(method, params)
+}
+
+// newTrackedStore tracks an order that is still open, one that filled, one
+// that was cancelled, one Luno can't return and one already complete
+func newTrackedStore(t *testing.T) (*Store, *sdk.MockLunoClient) {
+	s := New("")
+	for _, id := range []string{"BXOPEN1", "BXFILL1", "BXCANC1", "BXFAIL1"} {
+		require.NoError(t, s.Add(Order{OrderID: id, Pair: "XBTZAR", Type: luno.OrderTypeBid, Tool: "create_order"}))
+	}
+	require.NoError(t, s.Add(Order{OrderID: "BXDONE1", State: luno.OrderStateComplete}))
+
+	client := sdk.NewMockLunoClient(t)
+	client.EXPECT().ListOrders(context.Background(), &luno.ListOrdersRequest{State: luno.OrderStatePending}).
+		Return(&luno.ListOrdersResponse{Orders: []luno.Order{
+			{OrderId: "BXOPEN1", State: luno.OrderStatePending, Base: decimal.NewFromFloat64(0.5, 1)},
+			{OrderId: "untracked", State: luno.OrderStatePending},
+		}}, nil).Once()
+	client.EXPECT().GetOrder(context.Background(), &luno.GetOrderRequest{Id: "BXFILL1"}).
+		Return(&luno.GetOrderResponse{OrderId: "BXFILL1", State: luno.OrderStateComplete, Base: decimal.NewFromInt64(1), Counter: decimal.NewFromInt64(1_000_000)}, nil).Once()
+	client.EXPECT().GetOrder(context.Background(), &luno.GetOrderRequest{Id: "BXCANC1"}).
+		Return(&luno.GetOrderResponse{OrderId: "BXCANC1", State: luno.OrderStateComplete}, nil).Once()
+	client.EXPECT().GetOrder(context.Background(), &luno.GetOrderRequest{Id: "BXFAIL1"}).
+		Return(nil, errors.New("connection reset")).Once()
+	return s, client
+}
+
+func TestReconcile(t *testing.T) {
+	s, client := newTrackedStore(t)
+
+	r, err := Reconcile(context.Background(), s, client, "")
+	require.NoError(t, err)
+	require.Len(t, r.Open, 1)
+	assert.Equal(t, "0.5", r.Open[0].Base.String(), "part fills are recorded")
+	require.Len(t, r.Filled, 1)
+	assert.Equal(t, "BXFILL1", r.Filled[0].OrderID)
+	assert.Equal(t, "1000000", r.Filled[0].Counter.String())
+	require.Len(t, r.Cancelled, 1)
+	assert.Equal(t, "BXCANC1", r.Cancelled[0].OrderID)
+	assert.Equal(t, []string{"getting order BXFAIL1: connection reset"}, r.Errors)
+
+	open, err := s.Open("")
+	require.NoError(t, err)
+	var ids []string
+	for _, o := range open {
+		ids = append(ids, o.OrderID)
+	}
+	assert.Equal(t, []string{"BXOPEN1", "BXFAIL1"}, ids, "orders that could not be checked stay open")
+	assert.Equal(t, r.CheckedAt, open[0].CheckedAt)
+}
+
+func TestReconcileNothingOpen(t *testing.T) {
+	r, err := Reconcile(context.Background(), New(""), sdk.NewMockLunoClient(t), "")
+	require.NoError(t, err)
+	assert.Empty(t, r.Open)
+}
+
+func TestReconcileTenant(t *testing.T) {
+	s := New("")
+	require.NoError(t, s.Add(Order{OrderID: "BXACME1", Pair: "XBTZAR", Tenant: "acme"}))
+	require.NoError(t, s.Add(Order{OrderID: "BXOWN1", Pair: "XBTZAR"}))
+	client := sdk.NewMockLunoClient(t)
+	client.EXPECT().ListOrders(context.Background(), &luno.ListOrdersRequest{State: luno.OrderStatePending}).
+		Return(&luno.ListOrdersResponse{Orders: []luno.Order{{OrderId: "BXACME1", State: luno.OrderStatePending}}}, nil).Once()
+
+	r, err := Reconcile(context.Background(), s, client, "acme")
+	require.NoError(t, err)
+	require.Len(t, r.Open, 1, "only the tenant's orders are checked, with its client")
+	assert.Equal(t, "BXACME1", r.Open[0].OrderID)
+
+	own, err := s.List("")
+	require.NoError(t, err)
+	require.Len(t, own, 1)
+	assert.Equal(t, "BXOWN1", own[0].OrderID)
+}
+
+func TestReconcileListError(t *testing.T) {
+	s := New("")
+	require.NoError(t, s.Add(Order{OrderID: "open"}))
+	client := sdk.NewMockLunoClient(t)
+	client.EXPECT().ListOrders(context.Background(), &luno.ListOrdersRequest{State: luno.OrderStatePending}).
+		Return(nil, errors.New("unauthorised")).Once()
+
+	_, err := Reconcile(context.Background(), s, client, "")
+	assert.ErrorContains(t, err, "listing open orders: unauthorised")
+}
+
+func TestMonitorReport(t *testing.T) {
+	s, client := newTrackedStore(t)
+	var messages []string
+	m := NewMonitor(s, client, notifierFunc(func(method string, params map[string]any) {
+		assert.Equal(t, "notifications/message", method)
+		assert.Equal(t, notificationLogger, params["logger"])
+		messages = append(messages, params["data"].(map[string]any)["message"].(string))
+	}))
+
+	r, err := Reconcile(context.Background(), s, client, "")
+	require.NoError(t, err)
+	m.report(context.Background(), r, true)
+	assert.Equal(t, []string{
+		"Order BXFILL1 on XBTZAR filled while the server was not running: 1 base for 1000000 counter",
+		"Order BXCANC1 on XBTZAR was cancelled without trading while the server was not running",
+	}, messages)
+
+	messages = nil
+	m.report(context.Background(), Reconciliation{Filled: r.Filled}, false)
+	assert.Equal(t, []string{"Order BXFILL1 on XBTZAR filled: 1 base for 1000000 counter"}, messages)
+}
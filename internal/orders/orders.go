@@ -0,0 +1,211 @@
+// Package orders keeps track of the orders placed through the server, so that
+// after a restart they can be checked against Luno and watched until they
+// complete.
+package orders
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/internal/fsutil"
+	"github.com/luno/luno-mcp/internal/vault"
+)
+
+// Retention is how long completed orders are kept after they were last checked
+const Retention = 30 * 24 * time.Hour
+
+// Order is an order placed through the server, with what it was placed for and
+// its state on Luno when last checked
+type Order struct {
+	OrderID string `json:"order_id"`
+	Pair    string `json:"pair"`
+	// Type is BID or ASK for limit orders and BUY or SELL for market orders
+	Type   luno.OrderType  `json:"type"`
+	Volume decimal.Decimal `json:"volume,omitzero"`
+	// Price is the limit price. It is zero for market orders.
+	Price decimal.Decimal `json:"price,omitzero"`
+	// Tool is the tool that placed the order
+	Tool string `json:"tool"`
+	// Confirmation holds what the user was shown and confirmed before the
+	// order was placed, such as the expected price of a market order
+	Confirmation map[string]string `json:"confirmation,omitempty"`
+	// SessionID is the MCP session the order was placed in, and RequestID the
+	// tool call that placed it
+	SessionID string `json:"session_id,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+	// Tenant is the tenant whose account the order is on, in multi-tenant
+	// deployments
+	Tenant   string    `json:"tenant,omitempty"`
+	PlacedAt time.Time `json:"placed_at"`
+
+	State     luno.OrderState `json:"state"`
+	Base      decimal.Decimal `json:"base,omitzero"`
+	Counter   decimal.Decimal `json:"counter,omitzero"`
+	CheckedAt time.Time       `json:"checked_at,omitzero"`
+}
+
+// Open reports whether the order was still on the order book when last checked
+func (o Order) Open() bool {
+	return o.State != luno.OrderStateComplete
+}
+
+// Store holds tracked orders in a JSON file. The file is read on first use and
+// rewritten whenever an order changes. It is safe for concurrent use.
+type Store struct {
+	path   string
+	cipher *vault.Cipher
+
+	mu     sync.Mutex
+	loaded bool
+	orders []Order
+
+	now func() time.Time
+}
+
+// New creates a store kept at path. An empty path keeps orders in memory only.
+func New(path string) *Store {
+	return &Store{path: path, now: time.Now}
+}
+
+// SetCipher encrypts the file with c. It must be called before the store is
+// used; a file written without encryption is encrypted when it is first read.
+func (s *Store) SetCipher(c *vault.Cipher) {
+	s.cipher = c
+}
+
+// Path returns where the orders are stored, or an empty string if they are
+// only kept in memory
+func (s *Store) Path() string {
+	return s.path
+}
+
+// FileName is the name of the file the orders are kept in by default
+const FileName = "orders.json"
+
+// DefaultPath returns the default location of the orders in the user's config
+// directory, or an empty string if there isn't one
+func DefaultPath() string {
+	return fsutil.ConfigPath(FileName)
+}
+
+// Add starts tracking o, which has just been placed. Completed orders past
+// Retention are dropped.
+func (s *Store) Add(o Order) error {
+	if o.OrderID == "" {
+		return errors.New("order ID must not be empty")
+	}
+	o.PlacedAt = s.now().UTC()
+	if o.State == "" {
+		o.State = luno.OrderStatePending
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return err
+	}
+	cutoff := o.PlacedAt.Add(-Retention)
+	orders := slices.DeleteFunc(slices.Clone(s.orders), func(e Order) bool {
+		return e.OrderID == o.OrderID || (!e.Open() && e.CheckedAt.Before(cutoff))
+	})
+	orders = append(orders, o)
+	if err := s.save(orders); err != nil {
+		return err
+	}
+	s.orders = orders
+	return nil
+}
+
+// Update saves the state of tracked orders. Orders that are not tracked are
+// ignored.
+func (s *Store) Update(updated ...Order) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return err
+	}
+	orders := slices.Clone(s.orders)
+	for _, o := range updated {
+		if i := slices.IndexFunc(orders, func(e Order) bool { return e.OrderID == o.OrderID }); i >= 0 {
+			orders[i] = o
+		}
+	}
+	if err := s.save(orders); err != nil {
+		return err
+	}
+	s.orders = orders
+	return nil
+}
+
+// List returns the tracked orders of tenant, oldest first
+func (s *Store) List(tenant string) ([]Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(slices.Clone(s.orders), func(o Order) bool { return o.Tenant != tenant }), nil
+}
+
+// Open returns the tracked orders of tenant that were on the order book when
+// last checked, oldest first
+func (s *Store) Open(tenant string) ([]Order, error) {
+	all, err := s.List(tenant)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(all, func(o Order) bool { return !o.Open() }), nil
+}
+
+// load reads the orders file the first time it is needed. The caller must
+// hold s.mu.
+func (s *Store) load() error {
+	if s.loaded || s.path == "" {
+		s.loaded = true
+		return nil
+	}
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		s.loaded = true
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading tracked orders: %w", err)
+	}
+	sealed := vault.IsSealed(data)
+	if data, err = s.cipher.Open(data); err != nil {
+		return fmt.Errorf("reading tracked orders %s: %w", s.path, err)
+	}
+	var orders []Order
+	if err := json.Unmarshal(data, &orders); err != nil {
+		return fmt.Errorf("reading tracked orders %s: %w", s.path, err)
+	}
+	s.orders = orders
+	s.loaded = true
+	if s.cipher.Enabled() && !sealed {
+		return s.save(orders)
+	}
+	return nil
+}
+
+// save replaces the orders file with orders. The caller must hold s.mu.
+func (s *Store) save(orders []Order) error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(orders, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding tracked orders: %w", err)
+	}
+	if err := fsutil.WriteFile(s.path, append(s.cipher.Seal(data), '\n'), 0o600); err != nil {
+		return fmt.Errorf("writing tracked orders: %w", err)
+	}
+	return nil
+}
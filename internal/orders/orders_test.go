@@ -0,0 +1,107 @@
+package orders
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/internal/vault"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "luno-mcp", "orders.json")
+	s := New(path)
+	now := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	placed := Order{
+		OrderID:      "BXMC2CJ7HNB88U4",
+		Pair:         "XBTZAR",
+		Type:         luno.OrderTypeBid,
+		Volume:       decimal.NewFromFloat64(0.01, 2),
+		Price:        decimal.NewFromInt64(1_000_000),
+		Tool:         "create_order",
+		Confirmation: map[string]string{"replaces_order_id": "BXHW6PFRRXKFSB4"},
+		SessionID:    "s1",
+	}
+	require.NoError(t, s.Add(placed))
+	require.NoError(t, s.Add(Order{OrderID: "BXJ8GD6Y5CN8Q2R", Pair: "ETHZAR", Type: luno.OrderTypeSell, Tool: "create_market_order"}))
+	assert.ErrorContains(t, s.Add(Order{}), "order ID must not be empty")
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// A new store on the same file sees everything written so far
+	reopened := New(path)
+	list, err := reopened.List("")
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, placed.OrderID, list[0].OrderID)
+	assert.Equal(t, luno.OrderStatePending, list[0].State)
+	assert.Equal(t, now, list[0].PlacedAt)
+	assert.Equal(t, placed.Confirmation, list[0].Confirmation)
+	assert.Equal(t, "0.01", list[0].Volume.String())
+
+	filled := list[1]
+	filled.State, filled.Base, filled.CheckedAt = luno.OrderStateComplete, decimal.NewFromInt64(1), now
+	require.NoError(t, reopened.Update(filled, Order{OrderID: "untracked"}))
+	open, err := New(path).Open("")
+	require.NoError(t, err)
+	require.Len(t, open, 1)
+	assert.Equal(t, placed.OrderID, open[0].OrderID)
+
+	// Completed orders are dropped once they are past Retention
+	reopened.now = func() time.Time { return now.Add(Retention + time.Hour) }
+	require.NoError(t, reopened.Add(Order{OrderID: "BXBC2CJ7HNB88U5"}))
+	list, err = New(path).List("")
+	require.NoError(t, err)
+	var ids []string
+	for _, o := range list {
+		ids = append(ids, o.OrderID)
+	}
+	assert.Equal(t, []string{placed.OrderID, "BXBC2CJ7HNB88U5"}, ids)
+}
+
+func TestStoreInMemory(t *testing.T) {
+	s := New("")
+	require.NoError(t, s.Add(Order{OrderID: "BXMC2CJ7HNB88U4"}))
+	open, err := s.Open("")
+	require.NoError(t, err)
+	assert.Len(t, open, 1)
+}
+
+func TestStoreCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orders.json")
+	require.NoError(t, os.WriteFile(path, []byte("not json"), 0o600))
+	_, err := New(path).List("")
+	assert.ErrorContains(t, err, "reading tracked orders")
+}
+
+func TestStoreEncrypted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orders.json")
+	c, err := vault.New(bytes.Repeat([]byte{1}, vault.KeySize))
+	require.NoError(t, err)
+
+	s := New(path)
+	s.SetCipher(c)
+	require.NoError(t, s.Add(Order{OrderID: "BXMC2CJ7HNB88U4", Pair: "XBTZAR", Type: luno.OrderTypeBid}))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.True(t, vault.IsSealed(data))
+	assert.NotContains(t, string(data), "BXMC2CJ7HNB88U4")
+
+	reopened := New(path)
+	reopened.SetCipher(c)
+	listed, err := reopened.List("")
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Equal(t, "BXMC2CJ7HNB88U4", listed[0].OrderID)
+}
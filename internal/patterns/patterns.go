@@ -0,0 +1,228 @@
+// Package patterns finds candlestick patterns in historical candles. Detection
+// is deterministic and uses fixed thresholds on candle bodies and shadows, so
+// confidence scores describe how closely candles match the textbook shape, not
+// how likely the price is to move.
+package patterns
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/luno/luno-go"
+)
+
+// Pattern names
+const (
+	Engulfing          = "engulfing"
+	Doji               = "doji"
+	Hammer             = "hammer"
+	ThreeWhiteSoldiers = "three_white_soldiers"
+)
+
+// Patterns are the patterns Detect finds
+var Patterns = []string{Engulfing, Doji, Hammer, ThreeWhiteSoldiers}
+
+// Directions a pattern points in
+const (
+	Bullish = "bullish"
+	Bearish = "bearish"
+	Neutral = "neutral"
+)
+
+const (
+	// dojiBody is the largest body, as a share of the range, of a doji
+	dojiBody = 0.1
+	// hammerShadow is the smallest lower shadow of a hammer, as a multiple of its body
+	hammerShadow = 2
+	// hammerUpperShadow is the largest upper shadow of a hammer, as a share of the range
+	hammerUpperShadow = 0.25
+	// soldierUpperShadow is the largest upper shadow of each of three white
+	// soldiers, as a share of its body
+	soldierUpperShadow = 0.5
+	// trendCandles is how many candles before a pattern set the trend it reverses
+	trendCandles = 3
+)
+
+// Match is a pattern found in the candles
+type Match struct {
+	Pattern   string `json:"pattern"`
+	Direction string `json:"direction"`
+	// Time is the start of the last candle of the pattern, and StartTime of the first
+	Time      time.Time `json:"time"`
+	StartTime time.Time `json:"start_time"`
+	Candles   int       `json:"candles"`
+	// Confidence is from 0 to 1, higher for candles closer to the textbook shape
+	// and for reversal patterns that follow the trend they reverse
+	Confidence  float64 `json:"confidence"`
+	Description string  `json:"description"`
+}
+
+// candle holds the prices of a luno.Candle as floats
+type candle struct {
+	time                   time.Time
+	open, high, low, close float64
+}
+
+func (c candle) body() float64        { return math.Abs(c.close - c.open) }
+func (c candle) span() float64        { return c.high - c.low }
+func (c candle) upperShadow() float64 { return c.high - max(c.open, c.close) }
+func (c candle) lowerShadow() float64 { return min(c.open, c.close) - c.low }
+func (c candle) bullish() bool        { return c.close > c.open }
+func (c candle) bearish() bool        { return c.close < c.open }
+
+// Detect returns the patterns found in candles, which must be in time order,
+// ordered by the time they completed. Only the named patterns are looked for;
+// none looks for all of them.
+func Detect(candles []luno.Candle, names ...string) ([]Match, error) {
+	want := make(map[string]bool, len(Patterns))
+	for _, n := range names {
+		if !slices.Contains(Patterns, n) {
+			return nil, fmt.Errorf("unknown pattern %q", n)
+		}
+		want[n] = true
+	}
+	if len(want) == 0 {
+		for _, n := range Patterns {
+			want[n] = true
+		}
+	}
+
+	cs := make([]candle, len(candles))
+	for i, c := range candles {
+		cs[i] = candle{
+			time:  time.Time(c.Timestamp).UTC(),
+			open:  c.Open.Float64(),
+			high:  c.High.Float64(),
+			low:   c.Low.Float64(),
+			close: c.Close.Float64(),
+		}
+		if cs[i].low <= 0 || cs[i].high < cs[i].low {
+			return nil, fmt.Errorf("candle at %s has invalid prices", cs[i].time)
+		}
+	}
+
+	matches := []Match{}
+	for i := range cs {
+		for _, detect := range []func([]candle, int) (Match, bool){engulfing, doji, hammer, threeWhiteSoldiers} {
+			if m, ok := detect(cs, i); ok && want[m.Pattern] {
+				m.Confidence = math.Round(m.Confidence*100) / 100
+				matches = append(matches, m)
+			}
+		}
+	}
+	return matches, nil
+}
+
+// trend reports whether the closes fell (-1) or rose (1) over the trendCandles
+// before candle i, or 0 if they did neither or there are too few candles
+func trend(cs []candle, i int) int {
+	if i < trendCandles+1 {
+		return 0
+	}
+	last, first := cs[i-1].close, cs[i-1-trendCandles].close
+	switch {
+	case last < first:
+		return -1
+	case last > first:
+		return 1
+	}
+	return 0
+}
+
+func newMatch(cs []candle, first, last int, pattern, direction string, confidence float64, description string) Match {
+	return Match{
+		Pattern:     pattern,
+		Direction:   direction,
+		Time:        cs[last].time,
+		StartTime:   cs[first].time,
+		Candles:     last - first + 1,
+		Confidence:  min(max(confidence, 0), 1),
+		Description: description,
+	}
+}
+
+// engulfing finds a candle whose body engulfs the opposite body before it
+func engulfing(cs []candle, i int) (Match, bool) {
+	if i < 1 {
+		return Match{}, false
+	}
+	prev, c := cs[i-1], cs[i]
+	if prev.body() == 0 || c.body() <= prev.body() {
+		return Match{}, false
+	}
+	// How much larger the engulfing body is, up to twice as large
+	confidence := 0.5 + 0.3*min(c.body()/prev.body()-1, 1)
+	switch {
+	case prev.bearish() && c.bullish() && c.open <= prev.close && c.close >= prev.open:
+		if trend(cs, i-1) < 0 {
+			confidence += 0.2
+		}
+		return newMatch(cs, i-1, i, Engulfing, Bullish, confidence,
+			"A rising candle whose body engulfs the falling body before it, a possible bullish reversal"), true
+	case prev.bullish() && c.bearish() && c.open >= prev.close && c.close <= prev.open:
+		if trend(cs, i-1) > 0 {
+			confidence += 0.2
+		}
+		return newMatch(cs, i-1, i, Engulfing, Bearish, confidence,
+			"A falling candle whose body engulfs the rising body before it, a possible bearish reversal"), true
+	}
+	return Match{}, false
+}
+
+// doji finds a candle that opened and closed at almost the same price
+func doji(cs []candle, i int) (Match, bool) {
+	c := cs[i]
+	if c.span() == 0 || c.body() > dojiBody*c.span() {
+		return Match{}, false
+	}
+	// Smaller bodies are closer to a textbook doji
+	confidence := 0.5 + 0.5*(1-c.body()/(dojiBody*c.span()))
+	return newMatch(cs, i, i, Doji, Neutral, confidence,
+		"Opened and closed at almost the same price, showing indecision"), true
+}
+
+// hammer finds a small-bodied candle with a long lower shadow after a fall
+func hammer(cs []candle, i int) (Match, bool) {
+	c := cs[i]
+	body := c.body()
+	if body <= dojiBody*c.span() || c.lowerShadow() < hammerShadow*body ||
+		c.upperShadow() > hammerUpperShadow*c.span() || trend(cs, i) >= 0 {
+		return Match{}, false
+	}
+	// Longer lower shadows and shorter upper shadows are stronger
+	confidence := 0.5 + 0.3*min((c.lowerShadow()/body-hammerShadow)/hammerShadow, 1) +
+		0.2*(1-c.upperShadow()/(hammerUpperShadow*c.span()))
+	return newMatch(cs, i, i, Hammer, Bullish, confidence,
+		"A small body with a long lower shadow after a fall, as buyers pushed the price back up"), true
+}
+
+// threeWhiteSoldiers finds three rising candles that each open within the body
+// of the one before and close near their high
+func threeWhiteSoldiers(cs []candle, i int) (Match, bool) {
+	if i < 2 {
+		return Match{}, false
+	}
+	var strength float64
+	for j := i - 2; j <= i; j++ {
+		c := cs[j]
+		if !c.bullish() || c.upperShadow() > soldierUpperShadow*c.body() {
+			return Match{}, false
+		}
+		if j > i-2 {
+			prev := cs[j-1]
+			if c.close <= prev.close || c.open < prev.open || c.open > prev.close {
+				return Match{}, false
+			}
+		}
+		strength += c.body() / c.span()
+	}
+	// Bodies that fill more of each candle's range are stronger
+	confidence := 0.4 + 0.4*strength/3
+	if trend(cs, i-2) < 0 {
+		confidence += 0.2
+	}
+	return newMatch(cs, i-2, i, ThreeWhiteSoldiers, Bullish, confidence,
+		"Three rising candles, each opening within the body before it and closing near its high"), true
+}
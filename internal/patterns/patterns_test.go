@@ -0,0 +1,113 @@
+package patterns
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var start = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// testCandles returns hourly candles, each given as "open high low close"
+func testCandles(t *testing.T, ohlc ...string) []luno.Candle {
+	t.Helper()
+	out := make([]luno.Candle, len(ohlc))
+	for i, s := range ohlc {
+		var prices [4]decimal.Decimal
+		fields := strings.Fields(s)
+		require.Len(t, fields, 4)
+		for j, f := range fields {
+			d, err := decimal.NewFromString(f)
+			require.NoError(t, err)
+			prices[j] = d
+		}
+		out[i] = luno.Candle{
+			Timestamp: luno.Time(start.Add(time.Duration(i) * time.Hour)),
+			Open:      prices[0],
+			High:      prices[1],
+			Low:       prices[2],
+			Close:     prices[3],
+		}
+	}
+	return out
+}
+
+func hour(i int) time.Time {
+	return start.Add(time.Duration(i) * time.Hour)
+}
+
+func TestDetect(t *testing.T) {
+	candles := testCandles(t,
+		"125 125 120 120",
+		"120 120 115 115",
+		"115 115 110 110",
+		"110 110 105 105",
+		"105 105 100 100",
+		"99 108 99 107",     // bullish engulfing after a fall
+		"107 110 104 107.2", // doji
+		"107.1 112 107 111.5",
+		"110 115 109.5 114.5",
+		"113 118.2 112.5 118", // three white soldiers
+		"118 118 112 112",     // bearish engulfing after a rise
+		"112 112 106 106",
+		"106 106 100 100",
+		"100 102 92 101.5", // hammer after a fall
+	)
+
+	matches, err := Detect(candles)
+	require.NoError(t, err)
+	require.Len(t, matches, 5)
+
+	assert.Equal(t, Match{Pattern: Engulfing, Direction: Bullish, Time: hour(5), StartTime: hour(4), Candles: 2, Confidence: 0.88,
+		Description: matches[0].Description}, matches[0])
+	assert.Equal(t, Match{Pattern: Doji, Direction: Neutral, Time: hour(6), StartTime: hour(6), Candles: 1, Confidence: 0.83,
+		Description: matches[1].Description}, matches[1])
+	assert.Equal(t, Match{Pattern: ThreeWhiteSoldiers, Direction: Bullish, Time: hour(9), StartTime: hour(7), Candles: 3, Confidence: 0.74,
+		Description: matches[2].Description}, matches[2])
+	assert.Equal(t, Match{Pattern: Engulfing, Direction: Bearish, Time: hour(10), StartTime: hour(9), Candles: 2, Confidence: 0.76,
+		Description: matches[3].Description}, matches[3])
+	assert.Equal(t, Match{Pattern: Hammer, Direction: Bullish, Time: hour(13), StartTime: hour(13), Candles: 1, Confidence: 0.96,
+		Description: matches[4].Description}, matches[4])
+	for _, m := range matches {
+		assert.NotEmpty(t, m.Description)
+	}
+
+	only, err := Detect(candles, Engulfing, Hammer)
+	require.NoError(t, err)
+	var names []string
+	for _, m := range only {
+		names = append(names, m.Pattern)
+	}
+	assert.Equal(t, []string{Engulfing, Engulfing, Hammer}, names)
+}
+
+func TestDetectNeedsTrend(t *testing.T) {
+	// The same hammer shape after a rise is not a hammer
+	candles := testCandles(t,
+		"100 105 100 105",
+		"105 110 105 110",
+		"110 115 110 115",
+		"115 120 115 120",
+		"120 122 112 121.5",
+	)
+	matches, err := Detect(candles, Hammer)
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+}
+
+func TestDetectErrors(t *testing.T) {
+	_, err := Detect(nil, "head_and_shoulders")
+	assert.ErrorContains(t, err, `unknown pattern "head_and_shoulders"`)
+
+	_, err = Detect(testCandles(t, "100 90 95 100"))
+	assert.ErrorContains(t, err, "has invalid prices")
+
+	matches, err := Detect(nil)
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+}
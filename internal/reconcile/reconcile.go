@@ -0,0 +1,256 @@
+// Package reconcile compares the activity recorded by the server with what
+// happened on the Luno account, to catch trades and transfers made elsewhere
+// and fills the server did not see.
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/internal/orders"
+	"github.com/luno/luno-mcp/sdk"
+)
+
+const (
+	// maxRows is how many of the latest transactions are read per account
+	maxRows = 1000
+	// maxOrders is how many of the latest orders are listed
+	maxOrders = 1000
+)
+
+// Kinds of Discrepancy
+const (
+	// UntrackedOrder is an order that traded on Luno but was not placed through the server
+	UntrackedOrder = "untracked_order"
+	// MissedFill is a tracked order that filled more on Luno than the server recorded
+	MissedFill = "missed_fill"
+	// UnexplainedTrades is a change in a currency from trades the server did not place
+	UnexplainedTrades = "unexplained_trades"
+	// UnexplainedTransfers is a change in a currency from deposits, withdrawals
+	// or sends the server did not make
+	UnexplainedTransfers = "unexplained_transfers"
+)
+
+// Withdrawal is a withdrawal recorded by the server
+type Withdrawal struct {
+	Currency string
+	Amount   decimal.Decimal
+	Time     time.Time
+}
+
+// MarketFunc looks up the market of a pair
+type MarketFunc func(ctx context.Context, pair string) (luno.MarketInfo, bool, error)
+
+// Input is the activity recorded by the server
+type Input struct {
+	Since, Now  time.Time
+	Tracked     []orders.Order
+	Withdrawals []Withdrawal
+	Market      MarketFunc
+}
+
+// Report compares the balance changes on the account over a window with those
+// explained by the activity the server recorded
+type Report struct {
+	Since       time.Time        `json:"since"`
+	GeneratedAt time.Time        `json:"generated_at"`
+	Currencies  []CurrencyChange `json:"currencies"`
+	// Discrepancies are empty when everything on the account is explained
+	Discrepancies []Discrepancy `json:"discrepancies"`
+	// Errors are the checks that could not be made
+	Errors []string `json:"errors,omitempty"`
+}
+
+// CurrencyChange is how the balance of a currency changed over the window,
+// across all accounts, by kind of transaction
+type CurrencyChange struct {
+	Currency string `json:"currency"`
+	// Trades is the change from trades, and RecordedTrades the part of it from
+	// orders the server placed
+	Trades         decimal.Decimal `json:"trades"`
+	RecordedTrades decimal.Decimal `json:"recorded_trades"`
+	// Transfers is the change from deposits, withdrawals and sends, and
+	// RecordedTransfers the part of it from withdrawals the server made
+	Transfers         decimal.Decimal `json:"transfers"`
+	RecordedTransfers decimal.Decimal `json:"recorded_transfers"`
+	Fees              decimal.Decimal `json:"fees"`
+}
+
+// Discrepancy is activity on the account that the server's records do not explain
+type Discrepancy struct {
+	Kind     string          `json:"kind"`
+	OrderID  string          `json:"order_id,omitempty"`
+	Currency string          `json:"currency,omitempty"`
+	Amount   decimal.Decimal `json:"amount,omitzero"`
+	Message  string          `json:"message"`
+}
+
+// Build compares the account behind client with in. Only a failure to read
+// balances is an error; other failures are recorded in Report.Errors.
+func Build(ctx context.Context, client sdk.LunoClient, in Input) (*Report, error) {
+	balances, err := client.GetBalances(ctx, &luno.GetBalancesRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("getting balances: %w", err)
+	}
+
+	r := &Report{Since: in.Since.UTC(), GeneratedAt: in.Now.UTC(), Discrepancies: []Discrepancy{}}
+	changes := make(map[string]*CurrencyChange)
+	change := func(currency string) *CurrencyChange {
+		c, ok := changes[currency]
+		if !ok {
+			c = &CurrencyChange{Currency: currency, Trades: decimal.Zero(), RecordedTrades: decimal.Zero(),
+				Transfers: decimal.Zero(), RecordedTransfers: decimal.Zero(), Fees: decimal.Zero()}
+			changes[currency] = c
+		}
+		return c
+	}
+
+	for _, b := range balances.Balance {
+		r.addTransactions(ctx, client, b, in.Since, change)
+	}
+	r.addOrders(ctx, client, in, change)
+	for _, w := range in.Withdrawals {
+		if !w.Time.Before(in.Since) {
+			c := change(w.Currency)
+			c.RecordedTransfers = c.RecordedTransfers.Sub(w.Amount)
+		}
+	}
+
+	for _, currency := range slices.Sorted(maps.Keys(changes)) {
+		c := changes[currency]
+		r.Currencies = append(r.Currencies, *c)
+		if d := c.Trades.Sub(c.RecordedTrades); d.Sign() != 0 {
+			r.Discrepancies = append(r.Discrepancies, Discrepancy{Kind: UnexplainedTrades, Currency: currency, Amount: d,
+				Message: fmt.Sprintf("%s balance changed by %s from trades not placed through this server", currency, d)})
+		}
+		if d := c.Transfers.Sub(c.RecordedTransfers); d.Sign() != 0 {
+			r.Discrepancies = append(r.Discrepancies, Discrepancy{Kind: UnexplainedTransfers, Currency: currency, Amount: d,
+				Message: fmt.Sprintf("%s balance changed by %s from deposits, withdrawals or sends not made through this server", currency, d)})
+		}
+	}
+	return r, nil
+}
+
+// addTransactions adds the transactions of an account since the start of the
+// window to the changes of its currency
+func (r *Report) addTransactions(ctx context.Context, client sdk.LunoClient, b luno.AccountBalance, since time.Time, change func(string) *CurrencyChange) {
+	id, err := strconv.ParseInt(b.AccountId, 10, 64)
+	if err != nil {
+		r.Errors = append(r.Errors, fmt.Sprintf("invalid account ID %q", b.AccountId))
+		return
+	}
+	res, err := client.ListTransactions(ctx, &luno.ListTransactionsRequest{Id: id, MinRow: -maxRows, MaxRow: 0})
+	if err != nil {
+		r.Errors = append(r.Errors, fmt.Sprintf("listing transactions of account %d: %v", id, err))
+		return
+	}
+	if len(res.Transactions) == maxRows && !oldest(res.Transactions).Before(since) {
+		r.Errors = append(r.Errors, fmt.Sprintf("account %d has more than %d transactions in the window; only the latest are counted", id, maxRows))
+	}
+
+	c := change(b.Asset)
+	for _, t := range res.Transactions {
+		if time.Time(t.Timestamp).Before(since) {
+			continue
+		}
+		switch t.Kind {
+		case luno.KindExchange:
+			c.Trades = c.Trades.Add(t.BalanceDelta)
+		case luno.KindFee:
+			c.Fees = c.Fees.Add(t.BalanceDelta)
+		default:
+			c.Transfers = c.Transfers.Add(t.BalanceDelta)
+		}
+	}
+}
+
+func oldest(txs []luno.Transaction) time.Time {
+	t := time.Time(txs[0].Timestamp)
+	for _, tx := range txs[1:] {
+		if ts := time.Time(tx.Timestamp); ts.Before(t) {
+			t = ts
+		}
+	}
+	return t
+}
+
+// addOrders matches the orders on Luno in the window with the tracked orders,
+// adding the fills of tracked orders to the recorded trades
+func (r *Report) addOrders(ctx context.Context, client sdk.LunoClient, in Input, change func(string) *CurrencyChange) {
+	res, err := client.ListOrders(ctx, &luno.ListOrdersRequest{Limit: maxOrders})
+	if err != nil {
+		r.Errors = append(r.Errors, fmt.Sprintf("listing orders: %v", err))
+		return
+	}
+	onLuno := make(map[string]luno.Order)
+	for _, o := range res.Orders {
+		created, completed := time.Time(o.CreationTimestamp), time.Time(o.CompletedTimestamp)
+		if !created.Before(in.Since) || !completed.Before(in.Since) {
+			onLuno[o.OrderId] = o
+		}
+	}
+
+	tracked := make(map[string]bool, len(in.Tracked))
+	for _, t := range in.Tracked {
+		o, ok := onLuno[t.OrderID]
+		if !ok {
+			if t.PlacedAt.Before(in.Since) {
+				continue
+			}
+			// Placed in the window but past the end of the list
+			got, err := client.GetOrder(ctx, &luno.GetOrderRequest{Id: t.OrderID})
+			if err != nil {
+				r.Errors = append(r.Errors, fmt.Sprintf("getting order %s: %v", t.OrderID, err))
+				continue
+			}
+			o = luno.Order{OrderId: got.OrderId, Pair: got.Pair, Type: got.Type, Base: got.Base, Counter: got.Counter}
+		}
+		tracked[t.OrderID] = true
+
+		if o.Base.Cmp(t.Base) > 0 {
+			r.Discrepancies = append(r.Discrepancies, Discrepancy{Kind: MissedFill, OrderID: t.OrderID, Amount: o.Base.Sub(t.Base),
+				Message: fmt.Sprintf("Order %s on %s filled %s on Luno but the server last saw %s; call reconcile_orders to update it", t.OrderID, t.Pair, o.Base, t.Base)})
+		}
+		r.addFill(ctx, in.Market, o, change)
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(onLuno)) {
+		o := onLuno[id]
+		if tracked[id] || o.Base.Sign() <= 0 {
+			continue
+		}
+		r.Discrepancies = append(r.Discrepancies, Discrepancy{Kind: UntrackedOrder, OrderID: id,
+			Message: fmt.Sprintf("Order %s on %s traded %s but was not placed through this server, or was a TWAP or iceberg slice", id, o.Pair, o.Base)})
+	}
+}
+
+// addFill adds what a tracked order traded to the recorded trades of its
+// market's currencies
+func (r *Report) addFill(ctx context.Context, market MarketFunc, o luno.Order, change func(string) *CurrencyChange) {
+	if o.Base.Sign() <= 0 {
+		return
+	}
+	m, ok, err := market(ctx, o.Pair)
+	switch {
+	case err != nil:
+		r.Errors = append(r.Errors, fmt.Sprintf("looking up market %s: %v", o.Pair, err))
+		return
+	case !ok:
+		r.Errors = append(r.Errors, fmt.Sprintf("unknown market %s of order %s", o.Pair, o.OrderId))
+		return
+	}
+	base, counter := change(m.BaseCurrency), change(m.CounterCurrency)
+	if o.Type == luno.OrderTypeBid || o.Type == luno.OrderTypeBuy {
+		base.RecordedTrades = base.RecordedTrades.Add(o.Base)
+		counter.RecordedTrades = counter.RecordedTrades.Sub(o.Counter)
+	} else {
+		base.RecordedTrades = base.RecordedTrades.Sub(o.Base)
+		counter.RecordedTrades = counter.RecordedTrades.Add(o.Counter)
+	}
+}
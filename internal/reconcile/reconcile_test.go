@@ -0,0 +1,171 @@
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/internal/orders"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	now   = time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	since = now.Add(-24 * time.Hour)
+)
+
+func xbtzar(_ context.Context, pair string) (luno.MarketInfo, bool, error) {
+	if pair != "XBTZAR" {
+		return luno.MarketInfo{}, false, nil
+	}
+	return luno.MarketInfo{MarketId: "XBTZAR", BaseCurrency: "XBT", CounterCurrency: "ZAR"}, true, nil
+}
+
+func tx(t *testing.T, at time.Time, kind luno.Kind, delta string) luno.Transaction {
+	d, err := decimal.NewFromString(delta)
+	require.NoError(t, err)
+	return luno.Transaction{Timestamp: luno.Time(at), Kind: kind, BalanceDelta: d}
+}
+
+func TestBuild(t *testing.T) {
+	ctx := context.Background()
+	client := sdk.NewMockLunoClient(t)
+	client.EXPECT().GetBalances(ctx, &luno.GetBalancesRequest{}).
+		Return(&luno.GetBalancesResponse{Balance: []luno.AccountBalance{
+			{AccountId: "1", Asset: "XBT"},
+			{AccountId: "2", Asset: "ZAR"},
+		}}, nil)
+	client.EXPECT().ListTransactions(ctx, &luno.ListTransactionsRequest{Id: 1, MinRow: -maxRows, MaxRow: 0}).
+		Return(&luno.ListTransactionsResponse{Transactions: []luno.Transaction{
+			tx(t, since.Add(-time.Hour), luno.KindExchange, "5"), // before the window
+			tx(t, now.Add(-2*time.Hour), luno.KindExchange, "1"),
+			tx(t, now.Add(-time.Hour), luno.KindExchange, "0.5"),
+		}}, nil)
+	client.EXPECT().ListTransactions(ctx, &luno.ListTransactionsRequest{Id: 2, MinRow: -maxRows, MaxRow: 0}).
+		Return(&luno.ListTransactionsResponse{Transactions: []luno.Transaction{
+			tx(t, now.Add(-2*time.Hour), luno.KindExchange, "-1000"),
+			tx(t, now.Add(-2*time.Hour), luno.KindFee, "-10"),
+			tx(t, now.Add(-time.Hour), luno.KindExchange, "-500"),
+			tx(t, now.Add(-30*time.Minute), luno.KindTransfer, "-200"),
+			tx(t, now.Add(-20*time.Minute), luno.KindTransfer, "50"),
+		}}, nil)
+	client.EXPECT().ListOrders(ctx, &luno.ListOrdersRequest{Limit: maxOrders}).
+		Return(&luno.ListOrdersResponse{Orders: []luno.Order{
+			{OrderId: "BXTRACK1", Pair: "XBTZAR", Type: luno.OrderTypeBid, Base: decimal.NewFromInt64(1), Counter: decimal.NewFromInt64(1000),
+				CreationTimestamp: luno.Time(now.Add(-2 * time.Hour)), CompletedTimestamp: luno.Time(now.Add(-2 * time.Hour))},
+			{OrderId: "BXOTHER1", Pair: "XBTZAR", Type: luno.OrderTypeBid, Base: decimal.NewFromFloat64(0.5, 1), Counter: decimal.NewFromInt64(500),
+				CreationTimestamp: luno.Time(now.Add(-time.Hour)), CompletedTimestamp: luno.Time(now.Add(-time.Hour))},
+			{OrderId: "BXNOFILL", Pair: "XBTZAR", Type: luno.OrderTypeAsk, CreationTimestamp: luno.Time(now.Add(-time.Hour))},
+			{OrderId: "BXOLD1", Pair: "XBTZAR", Type: luno.OrderTypeAsk, Base: decimal.NewFromInt64(2),
+				CreationTimestamp: luno.Time(since.Add(-time.Hour)), CompletedTimestamp: luno.Time(since.Add(-time.Hour))},
+		}}, nil)
+
+	r, err := Build(ctx, client, Input{
+		Since: since,
+		Now:   now,
+		Tracked: []orders.Order{
+			// Filled after the server last checked it
+			{OrderID: "BXTRACK1", Pair: "XBTZAR", PlacedAt: now.Add(-2 * time.Hour), Base: decimal.Zero()},
+		},
+		Withdrawals: []Withdrawal{
+			{Currency: "ZAR", Amount: decimal.NewFromInt64(200), Time: now.Add(-30 * time.Minute)},
+			{Currency: "ZAR", Amount: decimal.NewFromInt64(70), Time: since.Add(-time.Hour)},
+		},
+		Market: xbtzar,
+	})
+	require.NoError(t, err)
+	assert.Empty(t, r.Errors)
+	assert.Equal(t, since, r.Since)
+
+	require.Len(t, r.Currencies, 2)
+	assert.Equal(t, "XBT", r.Currencies[0].Currency)
+	assert.Equal(t, "1.5", r.Currencies[0].Trades.String())
+	assert.Equal(t, "1", r.Currencies[0].RecordedTrades.String())
+	zar := r.Currencies[1]
+	assert.Equal(t, "-1500", zar.Trades.String())
+	assert.Equal(t, "-1000", zar.RecordedTrades.String())
+	assert.Equal(t, "-150", zar.Transfers.String())
+	assert.Equal(t, "-200", zar.RecordedTransfers.String())
+	assert.Equal(t, "-10", zar.Fees.String())
+
+	var kinds []string
+	for _, d := range r.Discrepancies {
+		kinds = append(kinds, d.Kind+" "+d.OrderID+d.Currency+" "+d.Amount.String())
+	}
+	assert.Equal(t, []string{
+		"missed_fill BXTRACK1 1",
+		"untracked_order BXOTHER1 0",
+		"unexplained_trades XBT 0.5",
+		"unexplained_trades ZAR -500",
+		"unexplained_transfers ZAR 50",
+	}, kinds)
+}
+
+func TestBuildTrackedOrderNotListed(t *testing.T) {
+	ctx := context.Background()
+	client := sdk.NewMockLunoClient(t)
+	client.EXPECT().GetBalances(ctx, &luno.GetBalancesRequest{}).Return(&luno.GetBalancesResponse{}, nil)
+	client.EXPECT().ListOrders(ctx, &luno.ListOrdersRequest{Limit: maxOrders}).Return(&luno.ListOrdersResponse{}, nil)
+	client.EXPECT().GetOrder(ctx, &luno.GetOrderRequest{Id: "BXTRACK1"}).
+		Return(&luno.GetOrderResponse{OrderId: "BXTRACK1", Pair: "XBTZAR", Type: luno.OrderTypeAsk,
+			Base: decimal.NewFromInt64(1), Counter: decimal.NewFromInt64(1000)}, nil)
+	client.EXPECT().GetOrder(ctx, &luno.GetOrderRequest{Id: "BXFAIL1"}).Return(nil, errors.New("connection reset"))
+
+	r, err := Build(ctx, client, Input{
+		Since: since,
+		Now:   now,
+		Tracked: []orders.Order{
+			{OrderID: "BXTRACK1", Pair: "XBTZAR", PlacedAt: now.Add(-time.Hour), Base: decimal.NewFromInt64(1)},
+			{OrderID: "BXFAIL1", Pair: "XBTZAR", PlacedAt: now.Add(-time.Hour)},
+			// Placed before the window, so not looked up
+			{OrderID: "BXOLD1", Pair: "XBTZAR", PlacedAt: since.Add(-time.Hour)},
+		},
+		Market: xbtzar,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"getting order BXFAIL1: connection reset"}, r.Errors)
+	require.Len(t, r.Currencies, 2)
+	assert.Equal(t, "-1", r.Currencies[0].RecordedTrades.String())
+	assert.Equal(t, "1000", r.Currencies[1].RecordedTrades.String())
+
+	// Recorded trades with no matching transactions are discrepancies too
+	require.Len(t, r.Discrepancies, 2)
+	assert.Equal(t, UnexplainedTrades, r.Discrepancies[0].Kind)
+	assert.Equal(t, "1", r.Discrepancies[0].Amount.String())
+}
+
+func TestBuildErrors(t *testing.T) {
+	ctx := context.Background()
+	client := sdk.NewMockLunoClient(t)
+	client.EXPECT().GetBalances(ctx, &luno.GetBalancesRequest{}).Return(nil, errors.New("unauthorised")).Once()
+
+	_, err := Build(ctx, client, Input{Since: since, Now: now, Market: xbtzar})
+	assert.ErrorContains(t, err, "getting balances: unauthorised")
+
+	full := make([]luno.Transaction, maxRows)
+	for i := range full {
+		full[i] = tx(t, now, luno.KindExchange, "0")
+	}
+	client.EXPECT().GetBalances(ctx, &luno.GetBalancesRequest{}).
+		Return(&luno.GetBalancesResponse{Balance: []luno.AccountBalance{{AccountId: "bad"}, {AccountId: "1", Asset: "XBT"}, {AccountId: "2", Asset: "ZAR"}}}, nil)
+	client.EXPECT().ListTransactions(ctx, &luno.ListTransactionsRequest{Id: 1, MinRow: -maxRows, MaxRow: 0}).
+		Return(&luno.ListTransactionsResponse{Transactions: full}, nil)
+	client.EXPECT().ListTransactions(ctx, &luno.ListTransactionsRequest{Id: 2, MinRow: -maxRows, MaxRow: 0}).
+		Return(nil, errors.New("timeout"))
+	client.EXPECT().ListOrders(ctx, &luno.ListOrdersRequest{Limit: maxOrders}).Return(nil, errors.New("timeout"))
+
+	r, err := Build(ctx, client, Input{Since: since, Now: now, Market: xbtzar})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		`invalid account ID "bad"`,
+		"account 1 has more than 1000 transactions in the window; only the latest are counted",
+		"listing transactions of account 2: timeout",
+		"listing orders: timeout",
+	}, r.Errors)
+	assert.Empty(t, r.Discrepancies)
+}
@@ -0,0 +1,94 @@
+package redact
+
+import (
+	"context"
+	"io"
+	"log/slog"
+)
+
+// Handler is a slog.Handler that redacts record messages and attribute values
+// before passing records on
+type Handler struct {
+	next slog.Handler
+}
+
+// NewHandler wraps next so that nothing it logs contains secrets
+func NewHandler(next slog.Handler) *Handler {
+	return &Handler{next: next}
+}
+
+// Enabled implements slog.Handler
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle implements slog.Handler
+func (h *Handler) Handle(ctx context.Context, record slog.Record) error {
+	out := slog.NewRecord(record.Time, record.Level, String(record.Message), record.PC)
+	record.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(attr(a))
+		return true
+	})
+	return h.next.Handle(ctx, out)
+}
+
+// WithAttrs implements slog.Handler
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = attr(a)
+	}
+	return &Handler{next: h.next.WithAttrs(redacted)}
+}
+
+// WithGroup implements slog.Handler
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{next: h.next.WithGroup(name)}
+}
+
+// attr redacts the value of a, including errors, stringers and groups
+func attr(a slog.Attr) slog.Attr {
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, String(v.String()))
+	case slog.KindGroup:
+		group := v.Group()
+		redacted := make([]any, len(group))
+		for i, ga := range group {
+			redacted[i] = attr(ga)
+		}
+		return slog.Group(a.Key, redacted...)
+	case slog.KindAny:
+		if err, ok := v.Any().(error); ok {
+			return slog.Any(a.Key, Error(err))
+		}
+		return slog.String(a.Key, String(v.String()))
+	default:
+		return slog.Attr{Key: a.Key, Value: v}
+	}
+}
+
+// writer redacts everything written to it, see NewWriter
+type writer struct {
+	w io.Writer
+}
+
+// NewWriter returns a writer that redacts each write before passing it to w. It
+// is meant for line-based output such as the standard logger; a secret split
+// across two writes is not redacted.
+func NewWriter(w io.Writer) io.Writer {
+	return &writer{w: w}
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	redacted := String(string(p))
+	if redacted == string(p) {
+		return w.w.Write(p)
+	}
+	if _, err := w.w.Write([]byte(redacted)); err != nil {
+		return 0, err
+	}
+	// Report the original length so that callers don't treat this as a short write
+	return len(p), nil
+}
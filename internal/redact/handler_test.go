@@ -0,0 +1,43 @@
+package redact
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler(t *testing.T) {
+	Register("handler-secret-value")
+
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(slog.NewTextHandler(&buf, nil))).
+		With("key", "handler-secret-value")
+	logger.Info("calling with handler-secret-value",
+		"error", errors.New("rejected handler-secret-value"),
+		slog.Group("request", "header", "Authorization: Basic dXNlcjpwYXNz"),
+		"count", 3,
+	)
+
+	out := buf.String()
+	assert.NotContains(t, out, "handler-secret-value")
+	assert.NotContains(t, out, "dXNlcjpwYXNz")
+	assert.Contains(t, out, `msg="calling with [REDACTED]"`)
+	assert.Contains(t, out, `error="rejected [REDACTED]"`)
+	assert.Contains(t, out, "count=3")
+	assert.Contains(t, out, "key=[REDACTED]")
+}
+
+func TestWriter(t *testing.T) {
+	Register("writer-secret-value")
+
+	var buf bytes.Buffer
+	logger := log.New(NewWriter(&buf), "", 0)
+	logger.Printf("luno: Request: %#v", struct{ Secret string }{"writer-secret-value"})
+	logger.Printf("luno: Call: GET /api/1/balance")
+
+	assert.Equal(t, "luno: Request: struct { Secret string }{Secret:\"[REDACTED]\"}\nluno: Call: GET /api/1/balance\n", buf.String())
+}
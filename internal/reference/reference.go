@@ -0,0 +1,166 @@
+// Package reference fetches prices from outside Luno, such as a market index or
+// another exchange, so that Luno's prices can be compared against them. Sources
+// are pluggable: anything implementing Source can be configured, and HTTPSource
+// reads a price from a JSON feed.
+package reference
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/luno/luno-go/decimal"
+)
+
+// requestTimeout bounds a single request to an HTTP reference feed
+const requestTimeout = 10 * time.Second
+
+// maxResponseBytes is the largest feed response HTTPSource reads
+const maxResponseBytes = 1 << 20
+
+// DefaultPriceField is the JSON field HTTPSource reads the price from
+const DefaultPriceField = "price"
+
+// Quote is a reference price for a pair, in the pair's counter currency
+type Quote struct {
+	Price decimal.Decimal
+	// Source names where the price came from, e.g. the feed's host
+	Source string
+	// Time is when the price was fetched
+	Time time.Time
+}
+
+// Pair identifies the market a reference price is requested for
+type Pair struct {
+	// Pair is the Luno market ID, e.g. XBTZAR
+	Pair    string
+	Base    string
+	Counter string
+}
+
+// Source returns reference prices. Implementations must be safe for concurrent use.
+type Source interface {
+	Quote(ctx context.Context, pair Pair) (Quote, error)
+}
+
+// SourceFunc adapts a function to a Source
+type SourceFunc func(ctx context.Context, pair Pair) (Quote, error)
+
+// Quote implements Source
+func (f SourceFunc) Quote(ctx context.Context, pair Pair) (Quote, error) {
+	return f(ctx, pair)
+}
+
+// HTTPSource reads reference prices from a JSON feed. The placeholders {pair},
+// {base} and {counter} in URL are replaced with the requested market, e.g.
+// https://example.com/index/{base}-{counter}. The price is read from Field of
+// the response object; dots select nested objects, e.g. data.rate. The value
+// can be a JSON number or a decimal string.
+type HTTPSource struct {
+	URL    string
+	Field  string
+	Client *http.Client
+}
+
+// NewHTTPSource creates an HTTPSource for an http or https URL template,
+// reading the price from field, or DefaultPriceField when field is empty
+func NewHTTPSource(rawURL, field string) (*HTTPSource, error) {
+	u, err := url.Parse(expandURL(rawURL, Pair{Pair: "pair", Base: "base", Counter: "counter"}))
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return nil, fmt.Errorf("invalid reference price URL %q: must be an http or https URL", rawURL)
+	}
+	if field == "" {
+		field = DefaultPriceField
+	}
+	return &HTTPSource{
+		URL:    rawURL,
+		Field:  field,
+		Client: &http.Client{Timeout: requestTimeout},
+	}, nil
+}
+
+// Quote implements Source
+func (s *HTTPSource) Quote(ctx context.Context, pair Pair) (Quote, error) {
+	rawURL := expandURL(s.URL, pair)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return Quote{}, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return Quote{}, fmt.Errorf("reference price request failed: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return Quote{}, fmt.Errorf("reference price feed returned %s", res.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxResponseBytes))
+	if err != nil {
+		return Quote{}, fmt.Errorf("failed to read reference price: %w", err)
+	}
+	price, err := parsePrice(body, s.Field)
+	if err != nil {
+		return Quote{}, err
+	}
+	return Quote{Price: price, Source: req.URL.Host, Time: time.Now()}, nil
+}
+
+// expandURL replaces the placeholders in rawURL with pair, escaping each value
+func expandURL(rawURL string, pair Pair) string {
+	return strings.NewReplacer(
+		"{pair}", url.PathEscape(pair.Pair),
+		"{base}", url.PathEscape(pair.Base),
+		"{counter}", url.PathEscape(pair.Counter),
+	).Replace(rawURL)
+}
+
+// parsePrice reads the decimal at the dotted field path of a JSON object
+func parsePrice(body []byte, field string) (decimal.Decimal, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return decimal.Decimal{}, fmt.Errorf("reference price feed returned invalid JSON: %w", err)
+	}
+	for _, key := range strings.Split(field, ".") {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return decimal.Decimal{}, fmt.Errorf("reference price field %q not found", field)
+		}
+		if v, ok = obj[key]; !ok {
+			return decimal.Decimal{}, fmt.Errorf("reference price field %q not found", field)
+		}
+	}
+
+	var s string
+	switch p := v.(type) {
+	case json.Number:
+		s = p.String()
+	case string:
+		s = p
+	default:
+		return decimal.Decimal{}, fmt.Errorf("reference price field %q is not a number", field)
+	}
+	price, err := decimal.NewFromString(strings.TrimSpace(s))
+	if err != nil {
+		return decimal.Decimal{}, fmt.Errorf("reference price %q is not a decimal number", s)
+	}
+	if price.Sign() <= 0 {
+		return decimal.Decimal{}, errors.New("reference price must be positive")
+	}
+	return price, nil
+}
@@ -0,0 +1,93 @@
+package reference
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHTTPSource(t *testing.T) {
+	tests := []struct {
+		name          string
+		url           string
+		field         string
+		expectedField string
+		expectedError bool
+	}{
+		{name: "https", url: "https://example.com/price", expectedField: DefaultPriceField},
+		{name: "placeholders", url: "https://example.com/{base}-{counter}?pair={pair}", expectedField: DefaultPriceField},
+		{name: "custom field", url: "http://localhost:8080/", field: "data.rate", expectedField: "data.rate"},
+		{name: "placeholder host", url: "https://{pair}/", expectedField: DefaultPriceField},
+		{name: "no scheme", url: "example.com/price", expectedError: true},
+		{name: "unsupported scheme", url: "ftp://example.com", expectedError: true},
+		{name: "no host", url: "https:///price", expectedError: true},
+		{name: "empty", url: "", expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src, err := NewHTTPSource(tt.url, tt.field)
+			if tt.expectedError {
+				assert.ErrorContains(t, err, "invalid reference price URL")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.url, src.URL)
+			assert.Equal(t, tt.expectedField, src.Field)
+		})
+	}
+}
+
+func TestHTTPSourceQuote(t *testing.T) {
+	tests := []struct {
+		name          string
+		field         string
+		status        int
+		body          string
+		expectedPrice string
+		expectedError string
+	}{
+		{name: "number", body: `{"price": 1234567.89}`, expectedPrice: "1234567.89"},
+		{name: "string", body: `{"price": "0.00001234"}`, expectedPrice: "0.00001234"},
+		{name: "nested field", field: "data.rates.ZAR", body: `{"data": {"rates": {"ZAR": "18.25"}}}`, expectedPrice: "18.25"},
+		{name: "missing field", body: `{"last": 1}`, expectedError: `reference price field "price" not found`},
+		{name: "missing nested field", field: "data.rate", body: `{"data": 5}`, expectedError: `reference price field "data.rate" not found`},
+		{name: "not a number", body: `{"price": true}`, expectedError: "is not a number"},
+		{name: "not a decimal", body: `{"price": "abc"}`, expectedError: "is not a decimal number"},
+		{name: "zero", body: `{"price": 0}`, expectedError: "must be positive"},
+		{name: "invalid JSON", body: `<html>`, expectedError: "invalid JSON"},
+		{name: "error status", status: http.StatusServiceUnavailable, expectedError: "reference price feed returned 503"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var path string
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path = r.URL.RequestURI()
+				if tt.status != 0 {
+					w.WriteHeader(tt.status)
+				}
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer ts.Close()
+
+			src, err := NewHTTPSource(ts.URL+"/index/{base}-{counter}?market={pair}", tt.field)
+			require.NoError(t, err)
+
+			quote, err := src.Quote(context.Background(), Pair{Pair: "XBTZAR", Base: "XBT", Counter: "ZAR"})
+			assert.Equal(t, "/index/XBT-ZAR?market=XBTZAR", path)
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedPrice, quote.Price.String())
+			assert.Equal(t, ts.Listener.Addr().String(), quote.Source)
+			assert.False(t, quote.Time.IsZero())
+		})
+	}
+}
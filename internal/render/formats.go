@@ -0,0 +1,174 @@
+package render
+
+import (
+	"encoding/csv"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// serializeYAML writes v as YAML, keeping the order of fields. Strings that
+// would read as another type, such as amounts, are quoted.
+func serializeYAML(v Value) (string, error) {
+	var b strings.Builder
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(yamlNode(v)); err != nil {
+		return "", err
+	}
+	if err := enc.Close(); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+func yamlNode(v Value) *yaml.Node {
+	switch v.Kind {
+	case Object:
+		n := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, f := range v.Fields {
+			n.Content = append(n.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: f.Name}, yamlNode(f.Value))
+		}
+		return n
+	case Array:
+		n := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, item := range v.Items {
+			n.Content = append(n.Content, yamlNode(item))
+		}
+		return n
+	case String:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v.Text}
+	case Bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: v.Text}
+	case Number:
+		tag := "!!int"
+		if strings.ContainsAny(v.Text, ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: v.Text}
+	}
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+}
+
+// serializeCSV writes v as CSV. A result with one list is written as its
+// rows. Several lists are written together, with a list column naming the
+// list each row is from, and the other fields of the result follow the rows
+// as "# name: value" comment lines. A result without lists is one row.
+func serializeCSV(v Value) (string, error) {
+	d := layout(v)
+	if len(d.tables) > 1 {
+		// Empty lists have no rows to write
+		d.tables = slices.DeleteFunc(d.tables, func(t table) bool { return len(t.rows) == 0 })
+	}
+	var records [][]string
+	switch len(d.tables) {
+	case 0:
+		header := make([]string, len(d.fields))
+		row := make([]string, len(d.fields))
+		for i, f := range d.fields {
+			header[i], row[i] = f.name, f.value
+		}
+		records = [][]string{header, row}
+		d.fields = nil
+	case 1:
+		t := d.tables[0]
+		records = append([][]string{t.columns}, t.rows...)
+	default:
+		records = mergeTables(d.tables)
+	}
+
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	if err := w.WriteAll(records); err != nil {
+		return "", err
+	}
+	for _, f := range d.fields {
+		b.WriteString("# " + f.name + ": " + strings.ReplaceAll(f.value, "\n", " ") + "\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// mergeTables returns the rows of tables under one header, starting with a
+// list column naming the table of each row
+func mergeTables(tables []table) [][]string {
+	header := []string{"list"}
+	index := make(map[string]int)
+	for _, t := range tables {
+		for _, c := range t.columns {
+			if _, ok := index[c]; !ok {
+				index[c] = len(header)
+				header = append(header, c)
+			}
+		}
+	}
+	records := [][]string{header}
+	for _, t := range tables {
+		for _, row := range t.rows {
+			record := make([]string, len(header))
+			record[0] = t.name
+			for i, value := range row {
+				record[index[t.columns[i]]] = value
+			}
+			records = append(records, record)
+		}
+	}
+	return records
+}
+
+// serializeMarkdown writes v as markdown. Lists are tables, under a heading
+// naming them, and the other fields of the result a bulleted list above
+// them. A result without lists is a table of its fields.
+func serializeMarkdown(v Value) (string, error) {
+	d := layout(v)
+	var b strings.Builder
+	if len(d.tables) == 0 {
+		rows := make([][]string, len(d.fields))
+		for i, f := range d.fields {
+			rows[i] = []string{f.name, f.value}
+		}
+		writeMarkdownTable(&b, []string{"Field", "Value"}, rows)
+		return strings.TrimSuffix(b.String(), "\n"), nil
+	}
+
+	for _, f := range d.fields {
+		b.WriteString("- **" + f.name + "**: " + markdownCell(f.value) + "\n")
+	}
+	for _, t := range d.tables {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		if t.name != "" {
+			b.WriteString("### " + t.name + "\n\n")
+		}
+		if len(t.rows) == 0 {
+			b.WriteString("_None_\n")
+			continue
+		}
+		writeMarkdownTable(&b, t.columns, t.rows)
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+func writeMarkdownTable(b *strings.Builder, columns []string, rows [][]string) {
+	writeRow := func(cells []string) {
+		b.WriteString("|")
+		for _, c := range cells {
+			b.WriteString(" " + markdownCell(c) + " |")
+		}
+		b.WriteString("\n")
+	}
+	writeRow(columns)
+	b.WriteString("|" + strings.Repeat(" --- |", len(columns)) + "\n")
+	for _, row := range rows {
+		writeRow(row)
+	}
+}
+
+// markdownCell escapes s for a table cell, which must be one line without
+// unescaped pipes
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	s = strings.ReplaceAll(s, "\r\n", "<br>")
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
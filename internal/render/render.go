@@ -0,0 +1,93 @@
+// Package render writes tool results in the format a client asks for: JSON,
+// YAML, CSV or markdown tables. Tools build their results as JSON, and a
+// Serializer rewrites that JSON in another format, so a new format needs a
+// Serializer and an entry in serializers rather than a change to every tool.
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Format is the name of an output format
+type Format string
+
+const (
+	JSON     Format = "json"
+	YAML     Format = "yaml"
+	CSV      Format = "csv"
+	Markdown Format = "markdown"
+)
+
+// Serializer writes a decoded JSON result in one format
+type Serializer interface {
+	Serialize(v Value) (string, error)
+}
+
+// SerializerFunc is a Serializer written as a function
+type SerializerFunc func(v Value) (string, error)
+
+func (f SerializerFunc) Serialize(v Value) (string, error) {
+	return f(v)
+}
+
+// serializers are the supported formats
+var serializers = map[Format]Serializer{
+	JSON:     SerializerFunc(serializeJSON),
+	YAML:     SerializerFunc(serializeYAML),
+	CSV:      SerializerFunc(serializeCSV),
+	Markdown: SerializerFunc(serializeMarkdown),
+}
+
+// Formats returns the supported formats, JSON first
+func Formats() []Format {
+	return []Format{JSON, YAML, CSV, Markdown}
+}
+
+// Help describes the formats for tool parameter descriptions
+const Help = "json (default), yaml, csv or markdown. csv and markdown lay lists out as tables"
+
+// Parse returns the format named s, ignoring case. An empty s is JSON.
+func Parse(s string) (Format, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return JSON, nil
+	}
+	if s == "md" {
+		return Markdown, nil
+	}
+	if f := Format(s); slices.Contains(Formats(), f) {
+		return f, nil
+	}
+	names := make([]string, 0, len(serializers))
+	for _, f := range Formats() {
+		names = append(names, string(f))
+	}
+	return "", fmt.Errorf("must be one of %s, got %q", strings.Join(names, ", "), s)
+}
+
+// Render rewrites doc, a JSON document, in format f. ok is false, and doc is
+// returned as it is, if doc is not JSON: tools also return plain messages.
+func Render(f Format, doc string) (out string, ok bool, err error) {
+	s, known := serializers[f]
+	if !known {
+		return "", false, fmt.Errorf("unknown output format %q", f)
+	}
+	v, err := Decode([]byte(doc))
+	if err != nil {
+		return doc, false, nil
+	}
+	out, err = s.Serialize(v)
+	if err != nil {
+		return "", false, fmt.Errorf("writing %s: %w", f, err)
+	}
+	return out, true, nil
+}
+
+// serializeJSON writes v as JSON indented by two spaces, as tools do
+func serializeJSON(v Value) (string, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	return string(data), err
+}
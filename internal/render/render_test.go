@@ -0,0 +1,141 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const ordersDoc = `{
+  "orders": [
+    {
+      "order_id": "BXMC2CJ7HNB88U4",
+      "pair": "XBTZAR",
+      "limit_price": "800000",
+      "fee": {"value": "0.0001", "currency": "XBT"},
+      "note": "first | second\nline"
+    },
+    {
+      "order_id": "BXHW6PFRRXKFSB4",
+      "pair": "ETHZAR",
+      "limit_price": "45000",
+      "fee": {"value": "0", "currency": "ETH"},
+      "tags": ["dca", "weekly"]
+    }
+  ],
+  "count": 2,
+  "truncated": false,
+  "errors": []
+}`
+
+func TestParse(t *testing.T) {
+	for in, want := range map[string]Format{"": JSON, "json": JSON, " YAML ": YAML, "csv": CSV, "Markdown": Markdown, "md": Markdown} {
+		got, err := Parse(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+	_, err := Parse("xml")
+	assert.EqualError(t, err, `must be one of json, yaml, csv, markdown, got "xml"`)
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		doc    string
+		want   string
+	}{
+		{
+			name:   "json keeps the order of fields",
+			format: JSON,
+			doc:    `{"pair":"XBTZAR","bid":"799900","ask":"800100","volume":12.5,"active":true,"note":null}`,
+			want:   "{\n  \"pair\": \"XBTZAR\",\n  \"bid\": \"799900\",\n  \"ask\": \"800100\",\n  \"volume\": 12.5,\n  \"active\": true,\n  \"note\": null\n}",
+		},
+		{
+			name:   "yaml quotes strings that read as numbers",
+			format: YAML,
+			doc:    `{"pair":"XBTZAR","bid":"799900","volume":12.5,"rows":7,"active":true,"note":null,"levels":[{"price":"800100"}],"empty":{}}`,
+			want:   "pair: XBTZAR\nbid: \"799900\"\nvolume: 12.5\nrows: 7\nactive: true\nnote: null\nlevels:\n  - price: \"800100\"\nempty: {}",
+		},
+		{
+			name:   "csv of one list with other fields as comments",
+			format: CSV,
+			doc:    ordersDoc,
+			want: "order_id,pair,limit_price,fee.value,fee.currency,note,tags\n" +
+				"BXMC2CJ7HNB88U4,XBTZAR,800000,0.0001,XBT,\"first | second\nline\",\n" +
+				"BXHW6PFRRXKFSB4,ETHZAR,45000,0,ETH,,\"dca, weekly\"\n" +
+				"# count: 2\n" +
+				"# truncated: false",
+		},
+		{
+			name:   "csv of several lists",
+			format: CSV,
+			doc:    `{"timestamp":1640995200000,"asks":[{"price":"800100","volume":"0.25"}],"bids":[{"price":"799900","volume":"0.5"}]}`,
+			want:   "list,price,volume\nasks,800100,0.25\nbids,799900,0.5\n# timestamp: 1640995200000",
+		},
+		{
+			name:   "csv of an object",
+			format: CSV,
+			doc:    `{"pair":"XBTZAR","last_trade":"800050","fees":{"maker":"0","taker":"0.001"}}`,
+			want:   "pair,last_trade,fees.maker,fees.taker\nXBTZAR,800050,0,0.001",
+		},
+		{
+			name:   "csv of a list of values",
+			format: CSV,
+			doc:    `["XBTZAR","ETHZAR"]`,
+			want:   "value\nXBTZAR\nETHZAR",
+		},
+		{
+			name:   "markdown tables under their names",
+			format: Markdown,
+			doc:    ordersDoc,
+			want: "- **count**: 2\n" +
+				"- **truncated**: false\n" +
+				"\n" +
+				"### orders\n" +
+				"\n" +
+				"| order_id | pair | limit_price | fee.value | fee.currency | note | tags |\n" +
+				"| --- | --- | --- | --- | --- | --- | --- |\n" +
+				"| BXMC2CJ7HNB88U4 | XBTZAR | 800000 | 0.0001 | XBT | first \\| second<br>line |  |\n" +
+				"| BXHW6PFRRXKFSB4 | ETHZAR | 45000 | 0 | ETH |  | dca, weekly |\n" +
+				"\n" +
+				"### errors\n" +
+				"\n" +
+				"_None_",
+		},
+		{
+			name:   "markdown of an object",
+			format: Markdown,
+			doc:    `{"pair":"XBTZAR","last_trade":"800050"}`,
+			want:   "| Field | Value |\n| --- | --- |\n| pair | XBTZAR |\n| last_trade | 800050 |",
+		},
+		{
+			name:   "markdown of a list",
+			format: Markdown,
+			doc:    `[{"pair":"XBTZAR"},{"pair":"ETHZAR","status":"ACTIVE"}]`,
+			want:   "| pair | status |\n| --- | --- |\n| XBTZAR |  |\n| ETHZAR | ACTIVE |",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := Render(tt.format, tt.doc)
+			require.NoError(t, err)
+			assert.True(t, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRenderText(t *testing.T) {
+	for _, doc := range []string{"Removed alias \"savings\"", `{"pair":"XBTZAR"} and more`, ""} {
+		got, ok, err := Render(Markdown, doc)
+		require.NoError(t, err)
+		assert.False(t, ok, "%q is not JSON", doc)
+		assert.Equal(t, doc, got)
+	}
+
+	_, _, err := Render("xml", `{}`)
+	assert.Error(t, err)
+}
@@ -0,0 +1,136 @@
+package render
+
+import (
+	"strings"
+)
+
+// document is a result laid out for the tabular formats: its lists as tables,
+// and its other fields as name and value pairs
+type document struct {
+	fields []cell
+	tables []table
+}
+
+type cell struct {
+	name  string
+	value string
+}
+
+// table is a list of records. Nested fields of the records become columns
+// named by their path, such as fee.value.
+type table struct {
+	// name is the field holding the list, empty for a result that is a list
+	name    string
+	columns []string
+	rows    [][]string
+}
+
+// layout splits v into tables and fields. A result that is a list is one
+// table, and an object's fields that are lists of objects are a table each.
+// Everything else is a field, flattened to its leaves.
+func layout(v Value) document {
+	var d document
+	addField := func(name, value string) {
+		d.fields = append(d.fields, cell{name: name, value: value})
+	}
+	switch {
+	case v.Kind == Array:
+		d.tables = append(d.tables, newTable("", v.Items))
+	case v.Kind == Object:
+		for _, f := range v.Fields {
+			if isRecords(f.Value) {
+				d.tables = append(d.tables, newTable(f.Name, f.Value.Items))
+				continue
+			}
+			flatten(f.Name, f.Value, addField)
+		}
+	default:
+		addField("value", cellText(v))
+	}
+	return d
+}
+
+// isRecords reports whether v is a list of objects. An empty list is taken
+// to be one, as it is usually an empty list of records.
+func isRecords(v Value) bool {
+	if v.Kind != Array {
+		return false
+	}
+	for _, item := range v.Items {
+		if item.Kind != Object {
+			return false
+		}
+	}
+	return true
+}
+
+func newTable(name string, items []Value) table {
+	t := table{name: name}
+	index := make(map[string]int)
+	values := make([]map[string]string, len(items))
+	for i, item := range items {
+		values[i] = make(map[string]string)
+		flatten("", item, func(column, value string) {
+			if column == "" {
+				column = "value"
+			}
+			if _, ok := index[column]; !ok {
+				index[column] = len(t.columns)
+				t.columns = append(t.columns, column)
+			}
+			values[i][column] = value
+		})
+	}
+	for _, row := range values {
+		cells := make([]string, len(t.columns))
+		for column, value := range row {
+			cells[index[column]] = value
+		}
+		t.rows = append(t.rows, cells)
+	}
+	return t
+}
+
+// flatten calls add with every leaf of v, named by its path from name
+func flatten(name string, v Value, add func(name, value string)) {
+	if v.Kind != Object || len(v.Fields) == 0 {
+		add(name, cellText(v))
+		return
+	}
+	for _, f := range v.Fields {
+		path := f.Name
+		if name != "" {
+			path = name + "." + f.Name
+		}
+		flatten(path, f.Value, add)
+	}
+}
+
+// cellText writes v as the text of a cell. Lists of plain values are joined
+// with commas, and other lists and objects written as JSON.
+func cellText(v Value) string {
+	switch v.Kind {
+	case Null:
+		return ""
+	case Array:
+		parts := make([]string, len(v.Items))
+		for i, item := range v.Items {
+			if !item.scalar() {
+				return compactJSON(v)
+			}
+			parts[i] = cellText(item)
+		}
+		return strings.Join(parts, ", ")
+	case Object:
+		return compactJSON(v)
+	}
+	return v.Text
+}
+
+func compactJSON(v Value) string {
+	data, err := v.MarshalJSON()
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
@@ -0,0 +1,149 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Kind is the type of a JSON value
+type Kind int
+
+const (
+	Null Kind = iota
+	Bool
+	Number
+	String
+	Array
+	Object
+)
+
+// Value is a decoded JSON value. Unlike a map, it keeps the fields of an
+// object in the order they were written, which tools choose to put the most
+// useful fields first.
+type Value struct {
+	Kind Kind
+	// Text is a string, or a number or boolean as it was written
+	Text string
+	// Items are the elements of an array
+	Items []Value
+	// Fields are the fields of an object, in order
+	Fields []Field
+}
+
+// Field is a named field of an object
+type Field struct {
+	Name  string
+	Value Value
+}
+
+// Decode reads doc, which must hold a single JSON value
+func Decode(doc []byte) (Value, error) {
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	dec.UseNumber()
+	v, err := decodeValue(dec)
+	if err != nil {
+		return Value{}, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return Value{}, errors.New("more than one JSON value")
+	}
+	return v, nil
+}
+
+func decodeValue(dec *json.Decoder) (Value, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return Value{}, err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		if t == '[' {
+			v := Value{Kind: Array}
+			for dec.More() {
+				item, err := decodeValue(dec)
+				if err != nil {
+					return Value{}, err
+				}
+				v.Items = append(v.Items, item)
+			}
+			_, err := dec.Token()
+			return v, err
+		}
+		v := Value{Kind: Object}
+		for dec.More() {
+			name, err := dec.Token()
+			if err != nil {
+				return Value{}, err
+			}
+			value, err := decodeValue(dec)
+			if err != nil {
+				return Value{}, err
+			}
+			v.Fields = append(v.Fields, Field{Name: name.(string), Value: value})
+		}
+		_, err := dec.Token()
+		return v, err
+	case string:
+		return Value{Kind: String, Text: t}, nil
+	case json.Number:
+		return Value{Kind: Number, Text: t.String()}, nil
+	case bool:
+		return Value{Kind: Bool, Text: strconv.FormatBool(t)}, nil
+	case nil:
+		return Value{Kind: Null}, nil
+	}
+	return Value{}, fmt.Errorf("unexpected JSON token %v", tok)
+}
+
+// MarshalJSON writes v as compact JSON, with the fields of objects in order
+func (v Value) MarshalJSON() ([]byte, error) {
+	return v.appendJSON(nil)
+}
+
+func (v Value) appendJSON(b []byte) ([]byte, error) {
+	switch v.Kind {
+	case Bool, Number:
+		return append(b, v.Text...), nil
+	case String:
+		s, err := json.Marshal(v.Text)
+		return append(b, s...), err
+	case Array:
+		b = append(b, '[')
+		for i, item := range v.Items {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			var err error
+			if b, err = item.appendJSON(b); err != nil {
+				return nil, err
+			}
+		}
+		return append(b, ']'), nil
+	case Object:
+		b = append(b, '{')
+		for i, f := range v.Fields {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			name, err := json.Marshal(f.Name)
+			if err != nil {
+				return nil, err
+			}
+			b = append(append(b, name...), ':')
+			if b, err = f.Value.appendJSON(b); err != nil {
+				return nil, err
+			}
+		}
+		return append(b, '}'), nil
+	}
+	return append(b, "null"...), nil
+}
+
+// scalar reports whether v is a string, number, boolean or null
+func (v Value) scalar() bool {
+	return v.Kind != Array && v.Kind != Object
+}
@@ -0,0 +1,33 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecode(t *testing.T) {
+	v, err := Decode([]byte(`{"b": [1, "two", true, null], "a": {"c": 0.10}}`))
+	require.NoError(t, err)
+	assert.Equal(t, Value{Kind: Object, Fields: []Field{
+		{Name: "b", Value: Value{Kind: Array, Items: []Value{
+			{Kind: Number, Text: "1"},
+			{Kind: String, Text: "two"},
+			{Kind: Bool, Text: "true"},
+			{Kind: Null},
+		}}},
+		{Name: "a", Value: Value{Kind: Object, Fields: []Field{{Name: "c", Value: Value{Kind: Number, Text: "0.10"}}}}},
+	}}, v)
+
+	data, err := v.MarshalJSON()
+	require.NoError(t, err)
+	assert.Equal(t, `{"b":[1,"two",true,null],"a":{"c":0.10}}`, string(data), "fields and numbers are written as they were read")
+}
+
+func TestDecodeErrors(t *testing.T) {
+	for _, doc := range []string{"", "Order created", `{"pair":`, `{} {}`, `[1,]`} {
+		_, err := Decode([]byte(doc))
+		assert.Error(t, err, doc)
+	}
+}
@@ -0,0 +1,214 @@
+// Package reports builds portfolio digests and delivers them on a schedule.
+package reports
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/sdk"
+)
+
+// maxDigestOrders caps the completed orders fetched when looking for executed trades
+const maxDigestOrders = 100
+
+// Digest is a snapshot of a portfolio: its value, how that changed over the last
+// 24 hours, open orders and the orders that traded since the previous digest.
+type Digest struct {
+	GeneratedAt   time.Time `json:"generated_at"`
+	Since         time.Time `json:"since"`
+	QuoteCurrency string    `json:"quote_currency"`
+	// TotalValue is the value of all priced assets in QuoteCurrency
+	TotalValue decimal.Decimal `json:"total_value"`
+	// Change24hPercent is the change in TotalValue over 24 hours at current balances
+	Change24hPercent *float64         `json:"change_24h_percent,omitempty"`
+	Assets           []AssetValuation `json:"assets"`
+	OpenOrders       []OrderLine      `json:"open_orders"`
+	ExecutedOrders   []OrderLine      `json:"executed_orders"`
+	Errors           []string         `json:"errors,omitempty"`
+}
+
+// AssetValuation is the value of one asset held across all accounts
+type AssetValuation struct {
+	Asset   string          `json:"asset"`
+	Balance decimal.Decimal `json:"balance"`
+	// Price is the last trade price in the digest's quote currency; unset when the
+	// asset has no market against it
+	Price            decimal.Decimal `json:"price,omitzero"`
+	Value            decimal.Decimal `json:"value,omitzero"`
+	Change24hPercent *float64        `json:"change_24h_percent,omitempty"`
+}
+
+// OrderLine summarises an order in a digest
+type OrderLine struct {
+	OrderID     string          `json:"order_id"`
+	Pair        string          `json:"pair"`
+	Type        luno.OrderType  `json:"type"`
+	LimitPrice  decimal.Decimal `json:"limit_price,omitzero"`
+	LimitVolume decimal.Decimal `json:"limit_volume,omitzero"`
+	Base        decimal.Decimal `json:"base"`
+	Counter     decimal.Decimal `json:"counter"`
+}
+
+// BuildDigest builds a digest of the account behind client, valued in quote. Orders
+// completed after since are reported as executed. Only a failure to read balances
+// is an error; other failures are recorded in Digest.Errors.
+func BuildDigest(ctx context.Context, client sdk.LunoClient, quote string, since, now time.Time) (*Digest, error) {
+	quote = strings.ToUpper(quote)
+	balances, err := client.GetBalances(ctx, &luno.GetBalancesRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("getting balances: %w", err)
+	}
+
+	d := &Digest{
+		GeneratedAt:    now.UTC(),
+		Since:          since.UTC(),
+		QuoteCurrency:  quote,
+		TotalValue:     decimal.Zero(),
+		Assets:         []AssetValuation{},
+		OpenOrders:     []OrderLine{},
+		ExecutedOrders: []OrderLine{},
+	}
+	d.valueAssets(ctx, client, balances.Balance, now)
+	d.addOrders(ctx, client)
+	return d, nil
+}
+
+// valueAssets totals balances per asset and values them at the latest prices
+func (d *Digest) valueAssets(ctx context.Context, client sdk.LunoClient, balances []luno.AccountBalance, now time.Time) {
+	totals := make(map[string]decimal.Decimal)
+	for _, b := range balances {
+		held := b.Balance.Add(b.Reserved)
+		if held.Sign() <= 0 {
+			continue
+		}
+		if t, ok := totals[b.Asset]; ok {
+			held = held.Add(t)
+		}
+		totals[b.Asset] = held
+	}
+
+	assets := make([]string, 0, len(totals))
+	var pairs []string
+	for asset := range totals {
+		assets = append(assets, asset)
+		if asset != d.QuoteCurrency {
+			pairs = append(pairs, asset+d.QuoteCurrency)
+		}
+	}
+	slices.Sort(assets)
+	slices.Sort(pairs)
+
+	prices := map[string]decimal.Decimal{}
+	if len(pairs) > 0 {
+		tickers, err := client.GetTickers(ctx, &luno.GetTickersRequest{Pair: pairs})
+		if err != nil {
+			d.Errors = append(d.Errors, fmt.Sprintf("getting prices: %v", err))
+		} else {
+			for _, t := range tickers.Tickers {
+				prices[t.Pair] = t.LastTrade
+			}
+		}
+	}
+
+	total, total24hAgo := decimal.Zero(), decimal.Zero()
+	for _, asset := range assets {
+		v := AssetValuation{Asset: asset, Balance: totals[asset]}
+		if asset == d.QuoteCurrency {
+			v.Value = v.Balance
+			total = total.Add(v.Value)
+			total24hAgo = total24hAgo.Add(v.Value)
+			d.Assets = append(d.Assets, v)
+			continue
+		}
+
+		pair := asset + d.QuoteCurrency
+		price, ok := prices[pair]
+		if !ok || price.Sign() <= 0 {
+			d.Assets = append(d.Assets, v)
+			continue
+		}
+		v.Price = price
+		v.Value = v.Balance.Mul(price)
+		total = total.Add(v.Value)
+
+		if open, err := priceAt(ctx, client, pair, now.Add(-24*time.Hour)); err != nil {
+			d.Errors = append(d.Errors, fmt.Sprintf("getting 24h price for %s: %v", pair, err))
+			total24hAgo = total24hAgo.Add(v.Value)
+		} else {
+			change := percentChange(open, price)
+			v.Change24hPercent = &change
+			total24hAgo = total24hAgo.Add(v.Balance.Mul(open))
+		}
+		d.Assets = append(d.Assets, v)
+	}
+
+	d.TotalValue = total
+	if total24hAgo.Sign() > 0 {
+		change := percentChange(total24hAgo, total)
+		d.Change24hPercent = &change
+	}
+}
+
+// addOrders adds open orders and orders that completed after d.Since
+func (d *Digest) addOrders(ctx context.Context, client sdk.LunoClient) {
+	open, err := client.ListOrders(ctx, &luno.ListOrdersRequest{State: luno.OrderStatePending})
+	if err != nil {
+		d.Errors = append(d.Errors, fmt.Sprintf("listing open orders: %v", err))
+	} else {
+		for _, o := range open.Orders {
+			d.OpenOrders = append(d.OpenOrders, orderLine(o))
+		}
+	}
+
+	completed, err := client.ListOrders(ctx, &luno.ListOrdersRequest{State: luno.OrderStateComplete, Limit: maxDigestOrders})
+	if err != nil {
+		d.Errors = append(d.Errors, fmt.Sprintf("listing completed orders: %v", err))
+		return
+	}
+	for _, o := range completed.Orders {
+		if o.Base.Sign() > 0 && time.Time(o.CompletedTimestamp).After(d.Since) {
+			d.ExecutedOrders = append(d.ExecutedOrders, orderLine(o))
+		}
+	}
+}
+
+// priceAt returns the opening price of the first hourly candle at or after t
+func priceAt(ctx context.Context, client sdk.LunoClient, pair string, t time.Time) (decimal.Decimal, error) {
+	res, err := client.GetCandles(ctx, &luno.GetCandlesRequest{
+		Pair:     pair,
+		Since:    luno.Time(t),
+		Duration: int64(time.Hour / time.Second),
+	})
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+	if len(res.Candles) == 0 || res.Candles[0].Open.Sign() <= 0 {
+		return decimal.Decimal{}, fmt.Errorf("no candles since %s", t.UTC().Format(time.RFC3339))
+	}
+	return res.Candles[0].Open, nil
+}
+
+func orderLine(o luno.Order) OrderLine {
+	return OrderLine{
+		OrderID:     o.OrderId,
+		Pair:        o.Pair,
+		Type:        o.Type,
+		LimitPrice:  o.LimitPrice,
+		LimitVolume: o.LimitVolume,
+		Base:        o.Base,
+		Counter:     o.Counter,
+	}
+}
+
+// percentChange returns the change from before to after as a percentage of before
+func percentChange(before, after decimal.Decimal) float64 {
+	if before.Sign() == 0 {
+		return 0
+	}
+	return after.Sub(before).Float64() / before.Float64() * 100
+}
@@ -0,0 +1,93 @@
+package reports
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testNow = time.Date(2026, 10, 15, 8, 0, 0, 0, time.UTC)
+
+func dec(t *testing.T, s string) decimal.Decimal {
+	t.Helper()
+	d, err := decimal.NewFromString(s)
+	require.NoError(t, err)
+	return d
+}
+
+func TestBuildDigest(t *testing.T) {
+	ctx := context.Background()
+	client := sdk.NewMockLunoClient(t)
+
+	client.EXPECT().GetBalances(ctx, &luno.GetBalancesRequest{}).Return(&luno.GetBalancesResponse{Balance: []luno.AccountBalance{
+		{Asset: "XBT", Balance: dec(t, "0.4"), Reserved: dec(t, "0.1")},
+		{Asset: "ZAR", Balance: dec(t, "1000"), Reserved: dec(t, "0")},
+		{Asset: "ETH", Balance: dec(t, "0"), Reserved: dec(t, "0")},
+		{Asset: "USDC", Balance: dec(t, "10"), Reserved: dec(t, "0")},
+	}}, nil)
+	client.EXPECT().GetTickers(ctx, &luno.GetTickersRequest{Pair: []string{"USDCZAR", "XBTZAR"}}).Return(&luno.GetTickersResponse{Tickers: []luno.Ticker{
+		{Pair: "XBTZAR", LastTrade: dec(t, "1000000")},
+	}}, nil)
+	client.EXPECT().GetCandles(ctx, &luno.GetCandlesRequest{Pair: "XBTZAR", Since: luno.Time(testNow.Add(-24 * time.Hour)), Duration: 3600}).
+		Return(&luno.GetCandlesResponse{Candles: []luno.Candle{{Open: dec(t, "800000")}}}, nil)
+	client.EXPECT().ListOrders(ctx, &luno.ListOrdersRequest{State: luno.OrderStatePending}).
+		Return(&luno.ListOrdersResponse{Orders: []luno.Order{{OrderId: "open", Pair: "XBTZAR", Type: luno.OrderTypeBid}}}, nil)
+	client.EXPECT().ListOrders(ctx, &luno.ListOrdersRequest{State: luno.OrderStateComplete, Limit: maxDigestOrders}).
+		Return(&luno.ListOrdersResponse{Orders: []luno.Order{
+			{OrderId: "recent", Base: dec(t, "0.1"), CompletedTimestamp: luno.Time(testNow.Add(-time.Hour))},
+			{OrderId: "old", Base: dec(t, "0.1"), CompletedTimestamp: luno.Time(testNow.Add(-48 * time.Hour))},
+			{OrderId: "cancelled", Base: dec(t, "0"), CompletedTimestamp: luno.Time(testNow.Add(-time.Hour))},
+		}}, nil)
+
+	d, err := BuildDigest(ctx, client, "zar", testNow.Add(-24*time.Hour), testNow)
+	require.NoError(t, err)
+
+	assert.Equal(t, "ZAR", d.QuoteCurrency)
+	assert.Equal(t, 0, d.TotalValue.Cmp(dec(t, "501000")), "got %s", d.TotalValue)
+	require.NotNil(t, d.Change24hPercent)
+	assert.InDelta(t, 24.94, *d.Change24hPercent, 0.01, "USDC has no price so is left out of the change")
+
+	require.Len(t, d.Assets, 3)
+	assert.Equal(t, "USDC", d.Assets[0].Asset)
+	assert.Equal(t, 0, d.Assets[0].Value.Sign(), "assets without a market are not valued")
+	assert.Equal(t, "XBT", d.Assets[1].Asset)
+	assert.Equal(t, "0.5", d.Assets[1].Balance.String())
+	require.NotNil(t, d.Assets[1].Change24hPercent)
+	assert.InDelta(t, 25, *d.Assets[1].Change24hPercent, 0.01)
+
+	require.Len(t, d.OpenOrders, 1)
+	require.Len(t, d.ExecutedOrders, 1)
+	assert.Equal(t, "recent", d.ExecutedOrders[0].OrderID)
+	assert.Empty(t, d.Errors)
+}
+
+func TestBuildDigestErrors(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("balances error fails the digest", func(t *testing.T) {
+		client := sdk.NewMockLunoClient(t)
+		client.EXPECT().GetBalances(ctx, &luno.GetBalancesRequest{}).Return(nil, errors.New("unauthorised"))
+
+		_, err := BuildDigest(ctx, client, "ZAR", testNow.Add(-24*time.Hour), testNow)
+		require.ErrorContains(t, err, "unauthorised")
+	})
+
+	t.Run("order errors are recorded", func(t *testing.T) {
+		client := sdk.NewMockLunoClient(t)
+		client.EXPECT().GetBalances(ctx, &luno.GetBalancesRequest{}).Return(&luno.GetBalancesResponse{}, nil)
+		client.EXPECT().ListOrders(ctx, &luno.ListOrdersRequest{State: luno.OrderStatePending}).Return(nil, errors.New("boom"))
+		client.EXPECT().ListOrders(ctx, &luno.ListOrdersRequest{State: luno.OrderStateComplete, Limit: maxDigestOrders}).Return(nil, errors.New("boom"))
+
+		d, err := BuildDigest(ctx, client, "ZAR", testNow.Add(-24*time.Hour), testNow)
+		require.NoError(t, err)
+		assert.Len(t, d.Errors, 2)
+		assert.Nil(t, d.Change24hPercent)
+	})
+}
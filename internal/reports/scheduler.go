@@ -0,0 +1,326 @@
+package reports
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luno/luno-mcp/internal/events"
+	"github.com/luno/luno-mcp/internal/fsutil"
+	"github.com/luno/luno-mcp/sdk"
+)
+
+const (
+	// pollInterval is how often Run checks for due schedules
+	pollInterval = time.Minute
+	// webhookTimeout bounds a single webhook delivery
+	webhookTimeout = 10 * time.Second
+	// notificationLogger is the logger name used for digest notifications
+	notificationLogger = "luno-mcp/reports"
+)
+
+// Frequency is how often a digest is sent
+type Frequency string
+
+const (
+	FrequencyDaily  Frequency = "daily"
+	FrequencyWeekly Frequency = "weekly"
+)
+
+// DestinationType is where a digest is delivered
+type DestinationType string
+
+const (
+	// DestinationNotification sends the digest as an MCP logging notification to connected clients
+	DestinationNotification DestinationType = "notification"
+	// DestinationWebhook POSTs the digest to a URL as a report.digest event
+	DestinationWebhook DestinationType = "webhook"
+	// DestinationFile writes the digest as JSON to a local file, replacing the previous digest
+	DestinationFile DestinationType = "file"
+)
+
+// Notifier sends notifications to connected MCP clients
+type Notifier interface {
+	SendNotificationToAllClients(method string, params map[string]any)
+}
+
+// Destination describes where a digest is delivered
+type Destination struct {
+	Type DestinationType `json:"type"`
+	URL  string          `json:"url,omitempty"`
+	Path string          `json:"path,omitempty"`
+}
+
+// Schedule is a recurring digest. Times are in UTC.
+type Schedule struct {
+	ID        string    `json:"id"`
+	Frequency Frequency `json:"frequency"`
+	// Weekday is the day weekly digests are sent on, e.g. "monday"
+	Weekday string `json:"weekday,omitempty"`
+	// At is the time of day the digest is sent, as HH:MM
+	At            string      `json:"at"`
+	QuoteCurrency string      `json:"quote_currency"`
+	Destination   Destination `json:"destination"`
+	NextRun       time.Time   `json:"next_run"`
+	LastRun       time.Time   `json:"last_run,omitzero"`
+	LastError     string      `json:"last_error,omitempty"`
+	// Tenant is the tenant that scheduled the digest, in multi-tenant
+	// deployments
+	Tenant string `json:"tenant,omitempty"`
+
+	client   sdk.LunoClient
+	notifier Notifier
+	weekday  time.Weekday
+	hour     int
+	minute   int
+}
+
+// Scheduler runs digest schedules. It is safe for concurrent use. Schedules are
+// kept in memory and only fire while Run is running.
+type Scheduler struct {
+	mu            sync.Mutex
+	schedules     map[string]*Schedule
+	lastID        int
+	webhookSecret string
+	httpClient    *http.Client
+	now           func() time.Time
+}
+
+// NewScheduler creates an empty Scheduler. Webhook deliveries are signed with
+// webhookSecret when it is set, see events.Sign.
+func NewScheduler(webhookSecret string) *Scheduler {
+	return &Scheduler{
+		schedules:     make(map[string]*Schedule),
+		webhookSecret: webhookSecret,
+		httpClient:    &http.Client{Timeout: webhookTimeout},
+		now:           time.Now,
+	}
+}
+
+// Add validates s and schedules it. Digests are built with client and, for
+// notification destinations, sent through notifier. It returns the stored schedule.
+func (sc *Scheduler) Add(s Schedule, client sdk.LunoClient, notifier Notifier) (Schedule, error) {
+	if client == nil {
+		return Schedule{}, errors.New("a Luno client is required")
+	}
+	if err := s.validate(notifier); err != nil {
+		return Schedule{}, err
+	}
+	s.client = client
+	s.notifier = notifier
+
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.lastID++
+	s.ID = "report-" + strconv.Itoa(sc.lastID)
+	s.NextRun = s.next(sc.now())
+	sc.schedules[s.ID] = &s
+	return s, nil
+}
+
+// Remove deletes one of tenant's schedules, reporting whether it existed
+func (sc *Scheduler) Remove(tenant, id string) bool {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	s, ok := sc.schedules[id]
+	if !ok || s.Tenant != tenant {
+		return false
+	}
+	delete(sc.schedules, id)
+	return true
+}
+
+// List returns tenant's schedules ordered by ID
+func (sc *Scheduler) List(tenant string) []Schedule {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	list := make([]Schedule, 0, len(sc.schedules))
+	for _, s := range sc.schedules {
+		if s.Tenant == tenant {
+			list = append(list, *s)
+		}
+	}
+	slices.SortFunc(list, func(a, b Schedule) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	return list
+}
+
+// Run sends due digests until ctx is cancelled
+func (sc *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			sc.RunDue(ctx)
+		}
+	}
+}
+
+// RunDue builds and delivers every digest that is due
+func (sc *Scheduler) RunDue(ctx context.Context) {
+	now := sc.now()
+
+	sc.mu.Lock()
+	var due []Schedule
+	for _, s := range sc.schedules {
+		if !s.NextRun.After(now) {
+			due = append(due, *s)
+		}
+	}
+	sc.mu.Unlock()
+
+	for _, s := range due {
+		err := sc.send(ctx, s, now)
+		if err != nil {
+			slog.WarnContext(ctx, "Failed to send portfolio digest", "schedule", s.ID, "error", err)
+		}
+
+		sc.mu.Lock()
+		if stored, ok := sc.schedules[s.ID]; ok {
+			stored.LastRun = now
+			stored.LastError = ""
+			if err != nil {
+				stored.LastError = err.Error()
+			}
+			stored.NextRun = stored.next(now)
+		}
+		sc.mu.Unlock()
+	}
+}
+
+// send builds the digest for s and delivers it
+func (sc *Scheduler) send(ctx context.Context, s Schedule, now time.Time) error {
+	since := s.LastRun
+	if since.IsZero() {
+		since = now.Add(-s.period())
+	}
+	digest, err := BuildDigest(ctx, s.client, s.QuoteCurrency, since, now)
+	if err != nil {
+		return err
+	}
+	return sc.deliver(ctx, s.Destination, s.notifier, digest)
+}
+
+// deliver sends digest to dest
+func (sc *Scheduler) deliver(ctx context.Context, dest Destination, notifier Notifier, digest *Digest) error {
+	switch dest.Type {
+	case DestinationNotification:
+		notifier.SendNotificationToAllClients("notifications/message", map[string]any{
+			"level":  "info",
+			"logger": notificationLogger,
+			"data":   digest,
+		})
+		return nil
+	case DestinationWebhook:
+		hook, err := events.NewWebhook(dest.URL, sc.webhookSecret)
+		if err != nil {
+			return err
+		}
+		hook.Client = sc.httpClient
+		return hook.Send(ctx, events.New(events.ReportDigest, digest))
+	case DestinationFile:
+		data, err := json.MarshalIndent(digest, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(dest.Path), 0o755); err != nil {
+			return fmt.Errorf("creating directory for %s: %w", dest.Path, err)
+		}
+		if err := fsutil.WriteFile(dest.Path, data, 0o600); err != nil {
+			return fmt.Errorf("writing %s: %w", dest.Path, err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown destination %q", dest.Type)
+	}
+}
+
+// validate checks s and fills in its parsed time fields
+func (s *Schedule) validate(notifier Notifier) error {
+	switch s.Frequency {
+	case FrequencyDaily:
+		s.Weekday = ""
+	case FrequencyWeekly:
+		if s.Weekday == "" {
+			s.Weekday = "monday"
+		}
+		day, err := parseWeekday(s.Weekday)
+		if err != nil {
+			return err
+		}
+		s.weekday = day
+		s.Weekday = strings.ToLower(day.String())
+	default:
+		return fmt.Errorf("invalid frequency %q: must be daily or weekly", s.Frequency)
+	}
+
+	at, err := time.Parse("15:04", s.At)
+	if err != nil {
+		return fmt.Errorf("invalid time %q: must be HH:MM in UTC", s.At)
+	}
+	s.hour, s.minute = at.Hour(), at.Minute()
+
+	if s.QuoteCurrency == "" {
+		return errors.New("a quote currency is required")
+	}
+	s.QuoteCurrency = strings.ToUpper(s.QuoteCurrency)
+
+	switch s.Destination.Type {
+	case DestinationNotification:
+		if notifier == nil {
+			return errors.New("notifications need a connected MCP client")
+		}
+	case DestinationWebhook:
+		if _, err := events.NewWebhook(s.Destination.URL, ""); err != nil {
+			return err
+		}
+	case DestinationFile:
+		if !filepath.IsAbs(s.Destination.Path) {
+			return fmt.Errorf("invalid file path %q: must be absolute", s.Destination.Path)
+		}
+	default:
+		return fmt.Errorf("invalid destination %q: must be notification, webhook or file", s.Destination.Type)
+	}
+	return nil
+}
+
+// next returns the first run time strictly after t
+func (s *Schedule) next(t time.Time) time.Time {
+	t = t.UTC()
+	run := time.Date(t.Year(), t.Month(), t.Day(), s.hour, s.minute, 0, 0, time.UTC)
+	for !run.After(t) || (s.Frequency == FrequencyWeekly && run.Weekday() != s.weekday) {
+		run = run.AddDate(0, 0, 1)
+	}
+	return run
+}
+
+// period is the time between runs
+func (s *Schedule) period() time.Duration {
+	if s.Frequency == FrequencyWeekly {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+func parseWeekday(s string) (time.Weekday, error) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.EqualFold(d.String(), s) {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid weekday %q", s)
+}
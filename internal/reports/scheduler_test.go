@@ -0,0 +1,217 @@
+package reports
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/events"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type notifierFunc func(method string, params map[string]any)
+
+func (f notifierFunc) SendNotificationToAllClients(method string, params map[string]any) {
+	f(method, params)
+}
+
+// emptyAccount returns a client for an account with no balances or orders
+func emptyAccount(t *testing.T) *sdk.MockLunoClient {
+	client := sdk.NewMockLunoClient(t)
+	client.EXPECT().GetBalances(context.Background(), &luno.GetBalancesRequest{}).Return(&luno.GetBalancesResponse{}, nil).Maybe()
+	client.EXPECT().ListOrders(context.Background(), &luno.ListOrdersRequest{State: luno.OrderStatePending}).Return(&luno.ListOrdersResponse{}, nil).Maybe()
+	client.EXPECT().ListOrders(context.Background(), &luno.ListOrdersRequest{State: luno.OrderStateComplete, Limit: maxDigestOrders}).Return(&luno.ListOrdersResponse{}, nil).Maybe()
+	return client
+}
+
+func newTestScheduler(now time.Time) *Scheduler {
+	sc := NewScheduler("")
+	sc.now = func() time.Time { return now }
+	return sc
+}
+
+func TestSchedulerAdd(t *testing.T) {
+	notifier := notifierFunc(func(string, map[string]any) {})
+
+	tests := []struct {
+		name            string
+		schedule        Schedule
+		notifier        Notifier
+		expectedError   string
+		expectedNextRun time.Time
+	}{
+		{
+			name:            "daily later today",
+			schedule:        Schedule{Frequency: FrequencyDaily, At: "09:30", QuoteCurrency: "zar", Destination: Destination{Type: DestinationNotification}},
+			notifier:        notifier,
+			expectedNextRun: time.Date(2026, 10, 15, 9, 30, 0, 0, time.UTC),
+		},
+		{
+			name:            "daily at the current time runs tomorrow",
+			schedule:        Schedule{Frequency: FrequencyDaily, At: "08:00", QuoteCurrency: "ZAR", Destination: Destination{Type: DestinationNotification}},
+			notifier:        notifier,
+			expectedNextRun: time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name:            "weekly on the next monday",
+			schedule:        Schedule{Frequency: FrequencyWeekly, At: "07:00", QuoteCurrency: "ZAR", Destination: Destination{Type: DestinationWebhook, URL: "https://example.com/hook"}},
+			expectedNextRun: time.Date(2026, 10, 19, 7, 0, 0, 0, time.UTC),
+		},
+		{
+			name:            "weekly on a named day",
+			schedule:        Schedule{Frequency: FrequencyWeekly, Weekday: "Friday", At: "07:00", QuoteCurrency: "ZAR", Destination: Destination{Type: DestinationFile, Path: "/tmp/digest.json"}},
+			expectedNextRun: time.Date(2026, 10, 16, 7, 0, 0, 0, time.UTC),
+		},
+		{
+			name:          "invalid frequency",
+			schedule:      Schedule{Frequency: "hourly", At: "07:00", QuoteCurrency: "ZAR", Destination: Destination{Type: DestinationNotification}},
+			notifier:      notifier,
+			expectedError: "invalid frequency",
+		},
+		{
+			name:          "invalid time",
+			schedule:      Schedule{Frequency: FrequencyDaily, At: "7am", QuoteCurrency: "ZAR", Destination: Destination{Type: DestinationNotification}},
+			notifier:      notifier,
+			expectedError: "invalid time",
+		},
+		{
+			name:          "invalid weekday",
+			schedule:      Schedule{Frequency: FrequencyWeekly, Weekday: "someday", At: "07:00", QuoteCurrency: "ZAR", Destination: Destination{Type: DestinationNotification}},
+			notifier:      notifier,
+			expectedError: "invalid weekday",
+		},
+		{
+			name:          "notification without a client",
+			schedule:      Schedule{Frequency: FrequencyDaily, At: "07:00", QuoteCurrency: "ZAR", Destination: Destination{Type: DestinationNotification}},
+			expectedError: "connected MCP client",
+		},
+		{
+			name:          "invalid webhook URL",
+			schedule:      Schedule{Frequency: FrequencyDaily, At: "07:00", QuoteCurrency: "ZAR", Destination: Destination{Type: DestinationWebhook, URL: "ftp://example.com"}},
+			expectedError: "invalid webhook URL",
+		},
+		{
+			name:          "relative file path",
+			schedule:      Schedule{Frequency: FrequencyDaily, At: "07:00", QuoteCurrency: "ZAR", Destination: Destination{Type: DestinationFile, Path: "digest.json"}},
+			expectedError: "must be absolute",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc := newTestScheduler(testNow)
+			s, err := sc.Add(tt.schedule, sdk.NewMockLunoClient(t), tt.notifier)
+			if tt.expectedError != "" {
+				require.ErrorContains(t, err, tt.expectedError)
+				assert.Empty(t, sc.List(""))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "report-1", s.ID)
+			assert.Equal(t, "ZAR", s.QuoteCurrency)
+			assert.Equal(t, tt.expectedNextRun, s.NextRun)
+			list := sc.List("")
+			require.Len(t, list, 1)
+			assert.Equal(t, s.ID, list[0].ID)
+		})
+	}
+}
+
+func TestSchedulerRemove(t *testing.T) {
+	sc := newTestScheduler(testNow)
+	s, err := sc.Add(Schedule{Frequency: FrequencyDaily, At: "09:00", QuoteCurrency: "ZAR", Destination: Destination{Type: DestinationWebhook, URL: "https://example.com"}}, sdk.NewMockLunoClient(t), nil)
+	require.NoError(t, err)
+
+	assert.True(t, sc.Remove("", s.ID))
+	assert.False(t, sc.Remove("", s.ID))
+	assert.Empty(t, sc.List(""))
+}
+
+func TestSchedulerTenants(t *testing.T) {
+	sc := newTestScheduler(testNow)
+	s, err := sc.Add(Schedule{Frequency: FrequencyDaily, At: "09:00", QuoteCurrency: "ZAR", Tenant: "acme", Destination: Destination{Type: DestinationWebhook, URL: "https://example.com"}}, sdk.NewMockLunoClient(t), nil)
+	require.NoError(t, err)
+
+	assert.Empty(t, sc.List(""))
+	assert.Empty(t, sc.List("globex"))
+	assert.False(t, sc.Remove("globex", s.ID), "another tenant's schedule can't be removed")
+	require.Len(t, sc.List("acme"), 1)
+	assert.True(t, sc.Remove("acme", s.ID))
+}
+
+func TestSchedulerRunDue(t *testing.T) {
+	var posted struct {
+		Type events.Type `json:"type"`
+		Data Digest      `json:"data"`
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, events.Sign("secret", r.Header.Get(events.TimestampHeader), body), r.Header.Get(events.SignatureHeader))
+		assert.NoError(t, json.Unmarshal(body, &posted))
+	}))
+	defer ts.Close()
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer failing.Close()
+
+	var notified map[string]any
+	notifier := notifierFunc(func(method string, params map[string]any) {
+		assert.Equal(t, "notifications/message", method)
+		notified = params
+	})
+
+	path := filepath.Join(t.TempDir(), "reports", "digest.json")
+	client := emptyAccount(t)
+
+	now := testNow
+	sc := NewScheduler("secret")
+	sc.now = func() time.Time { return now }
+
+	add := func(dest Destination) Schedule {
+		s, err := sc.Add(Schedule{Frequency: FrequencyDaily, At: "09:00", QuoteCurrency: "ZAR", Destination: dest}, client, notifier)
+		require.NoError(t, err)
+		return s
+	}
+	add(Destination{Type: DestinationWebhook, URL: ts.URL})
+	add(Destination{Type: DestinationNotification})
+	add(Destination{Type: DestinationFile, Path: path})
+	failed := add(Destination{Type: DestinationWebhook, URL: failing.URL})
+
+	sc.RunDue(context.Background())
+	assert.Nil(t, notified, "nothing is due yet")
+
+	now = time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	sc.RunDue(context.Background())
+
+	assert.Equal(t, events.ReportDigest, posted.Type)
+	assert.Equal(t, "ZAR", posted.Data.QuoteCurrency)
+	assert.Equal(t, now.Add(-24*time.Hour), posted.Data.Since, "the first digest covers one period")
+	require.NotNil(t, notified)
+	assert.Equal(t, notificationLogger, notified["logger"])
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"quote_currency": "ZAR"`)
+
+	for _, s := range sc.List("") {
+		assert.Equal(t, now, s.LastRun)
+		assert.Equal(t, now.Add(24*time.Hour), s.NextRun)
+		if s.ID == failed.ID {
+			assert.Contains(t, s.LastError, "502")
+		} else {
+			assert.Empty(t, s.LastError)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package resources
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Resource templates for the market data of one pair
+const (
+	OrderBookTemplateURI = "luno://orderbook/{pair}"
+	CandlesTemplateURI   = "luno://candles/{pair}/{duration}"
+)
+
+// resourceCandles is how many of the latest candles the candles template returns
+const resourceCandles = 200
+
+// CandleDurations are the candle durations Luno supports, in seconds, offered
+// as completions of the candles template
+var CandleDurations = []int64{60, 300, 900, 1800, 3600, 10800, 14400, 28800, 86400, 259200, 604800}
+
+// NewOrderBookTemplate creates a resource template for the order book of a market
+func NewOrderBookTemplate() mcp.ResourceTemplate {
+	return mcp.NewResourceTemplate(
+		OrderBookTemplateURI,
+		"Luno Order Book",
+		mcp.WithTemplateDescription("Returns the bids and asks of a market, e.g. luno://orderbook/XBTZAR"),
+		mcp.WithTemplateMIMEType("application/json"),
+	)
+}
+
+// HandleOrderBookTemplate returns a handler for the order book resource template
+func HandleOrderBookTemplate(cfg *config.Config) server.ResourceTemplateHandlerFunc {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		cfg := cfg.ForContext(ctx)
+		if cfg == nil {
+			return nil, fmt.Errorf("configuration is nil")
+		}
+		if cfg.LunoClient == nil {
+			return nil, fmt.Errorf("Luno client is not configured")
+		}
+
+		uri := request.Params.URI
+		pair, ok := strings.CutPrefix(uri, "luno://orderbook/")
+		if !ok || pair == "" || strings.Contains(pair, "/") {
+			return nil, fmt.Errorf("invalid order book URI format")
+		}
+
+		orderBook, err := cfg.LunoClient.GetOrderBook(ctx, &luno.GetOrderBookRequest{Pair: strings.ToUpper(pair)})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get order book: %w", err)
+		}
+		return jsonContents(uri, orderBook)
+	}
+}
+
+// NewCandlesTemplate creates a resource template for the latest candles of a market
+func NewCandlesTemplate() mcp.ResourceTemplate {
+	return mcp.NewResourceTemplate(
+		CandlesTemplateURI,
+		"Luno Candles",
+		mcp.WithTemplateDescription(fmt.Sprintf("Returns the latest %d candles of a market as CSV, oldest first. "+
+			"The duration is in seconds or written like 1h, e.g. luno://candles/XBTZAR/3600 or luno://candles/XBTZAR/1h.", resourceCandles)),
+		mcp.WithTemplateMIMEType("text/csv"),
+	)
+}
+
+// HandleCandlesTemplate returns a handler for the candles resource template.
+// Closed candles are served from the candle cache.
+func HandleCandlesTemplate(cfg *config.Config) server.ResourceTemplateHandlerFunc {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		cfg := cfg.ForContext(ctx)
+		if cfg == nil {
+			return nil, fmt.Errorf("configuration is nil")
+		}
+		if cfg.LunoClient == nil {
+			return nil, fmt.Errorf("Luno client is not configured")
+		}
+
+		uri := request.Params.URI
+		rest, ok := strings.CutPrefix(uri, "luno://candles/")
+		pair, d, found := strings.Cut(rest, "/")
+		if !ok || !found || pair == "" {
+			return nil, fmt.Errorf("invalid candles URI format")
+		}
+		duration, err := parseCandleDuration(d)
+		if err != nil {
+			return nil, err
+		}
+
+		now := time.Now()
+		since := now.Add(-resourceCandles * time.Duration(duration) * time.Second)
+		cs, err := cfg.Candles.Get(ctx, cfg.LunoClient, strings.ToUpper(pair), duration, since, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get candles: %w", err)
+		}
+
+		text, err := candlesCSV(cs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to write candles: %w", err)
+		}
+		return []mcp.ResourceContents{
+			mcp.TextResourceContents{
+				URI:      uri,
+				MIMEType: "text/csv",
+				Text:     text,
+			},
+		}, nil
+	}
+}
+
+// parseCandleDuration reads a candle duration in seconds, such as 3600, or
+// written as a Go duration, such as 1h
+func parseCandleDuration(s string) (int64, error) {
+	seconds, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		d, derr := time.ParseDuration(s)
+		if derr != nil || d%time.Second != 0 {
+			return 0, fmt.Errorf("invalid candle duration %q: use seconds, such as 3600, or a duration such as 1h", s)
+		}
+		seconds = int64(d / time.Second)
+	}
+	if seconds <= 0 {
+		return 0, fmt.Errorf("invalid candle duration %q: must be positive", s)
+	}
+	return seconds, nil
+}
+
+// candlesCSV writes candles with a header row, their start times in RFC 3339
+func candlesCSV(cs []luno.Candle) (string, error) {
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	if err := w.Write([]string{"timestamp", "open", "high", "low", "close", "volume"}); err != nil {
+		return "", err
+	}
+	for _, c := range cs {
+		record := []string{
+			time.Time(c.Timestamp).UTC().Format(time.RFC3339),
+			c.Open.String(), c.High.String(), c.Low.String(), c.Close.String(), c.Volume.String(),
+		}
+		if err := w.Write(record); err != nil {
+			return "", err
+		}
+	}
+	w.Flush()
+	return b.String(), w.Error()
+}
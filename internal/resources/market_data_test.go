@@ -0,0 +1,86 @@
+package resources
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/testutil"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readResource(handler func(context.Context, mcp.ReadResourceRequest) ([]mcp.ResourceContents, error), uri string) (mcp.TextResourceContents, error) {
+	req := mcp.ReadResourceRequest{}
+	req.Params.URI = uri
+	result, err := handler(context.Background(), req)
+	if err != nil {
+		return mcp.TextResourceContents{}, err
+	}
+	if len(result) != 1 {
+		return mcp.TextResourceContents{}, errors.New("expected one content")
+	}
+	return result[0].(mcp.TextResourceContents), nil
+}
+
+func TestMarketDataTemplatesMatch(t *testing.T) {
+	assert.True(t, NewOrderBookTemplate().URITemplate.Regexp().MatchString("luno://orderbook/XBTZAR"))
+	assert.True(t, NewCandlesTemplate().URITemplate.Regexp().MatchString("luno://candles/XBTZAR/3600"))
+	assert.Equal(t, "text/csv", NewCandlesTemplate().MIMEType)
+}
+
+func TestHandleOrderBookTemplate(t *testing.T) {
+	client := testutil.NewFakeLunoClient(t).Respond("GetOrderBook", &luno.GetOrderBookResponse{
+		Asks: []luno.OrderBookEntry{{Price: decimal.NewFromInt64(800100), Volume: decimal.NewFromFloat64(0.5, 2)}},
+		Bids: []luno.OrderBookEntry{{Price: decimal.NewFromInt64(799900), Volume: decimal.NewFromFloat64(0.25, 2)}},
+	}, nil)
+	handler := HandleOrderBookTemplate(&config.Config{LunoClient: client})
+
+	contents, err := readResource(handler, "luno://orderbook/xbtzar")
+	require.NoError(t, err)
+	assert.Equal(t, "luno://orderbook/xbtzar", contents.URI)
+	assert.Equal(t, "application/json", contents.MIMEType)
+	assert.Contains(t, contents.Text, `"price": "800100"`)
+	client.AssertCalled(t, "GetOrderBook", &luno.GetOrderBookRequest{Pair: "XBTZAR"})
+
+	_, err = readResource(handler, "luno://orderbook/")
+	assert.EqualError(t, err, "invalid order book URI format")
+}
+
+func TestHandleCandlesTemplate(t *testing.T) {
+	start := time.Now().Add(-2 * time.Hour).Truncate(time.Hour)
+	client := testutil.NewFakeLunoClient(t).Respond("GetCandles", &luno.GetCandlesResponse{Candles: []luno.Candle{
+		{Timestamp: luno.Time(start), Open: decimal.NewFromInt64(100), High: decimal.NewFromInt64(120), Low: decimal.NewFromInt64(90), Close: decimal.NewFromInt64(110), Volume: decimal.NewFromFloat64(1.5, 2)},
+	}}, nil)
+	handler := HandleCandlesTemplate(&config.Config{LunoClient: client})
+
+	contents, err := readResource(handler, "luno://candles/xbtzar/1h")
+	require.NoError(t, err)
+	assert.Equal(t, "text/csv", contents.MIMEType)
+	assert.Equal(t, "timestamp,open,high,low,close,volume\n"+
+		start.UTC().Format(time.RFC3339)+",100,120,90,110,1.50\n", contents.Text)
+	req := client.Requests("GetCandles")[0].(*luno.GetCandlesRequest)
+	assert.Equal(t, "XBTZAR", req.Pair)
+	assert.Equal(t, int64(3600), req.Duration)
+
+	tests := []struct {
+		uri           string
+		expectedError string
+	}{
+		{uri: "luno://candles/XBTZAR", expectedError: "invalid candles URI format"},
+		{uri: "luno://candles/XBTZAR/hourly", expectedError: `invalid candle duration "hourly"`},
+		{uri: "luno://candles/XBTZAR/0", expectedError: `invalid candle duration "0": must be positive`},
+		{uri: "luno://candles/XBTZAR/1500ms", expectedError: `invalid candle duration "1500ms"`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.uri, func(t *testing.T) {
+			_, err := readResource(handler, tc.uri)
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
@@ -4,11 +4,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/audit"
 	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/journal"
+	"github.com/luno/luno-mcp/internal/markets"
+	"github.com/luno/luno-mcp/internal/tools"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
@@ -18,6 +24,19 @@ const (
 	WalletResourceURI       = "luno://wallets"
 	TransactionsResourceURI = "luno://transactions"
 	AccountTemplateURI      = "luno://accounts/{id}"
+	MarketTemplateURI       = "luno://markets/{pair}"
+	TickersResourceURI      = "luno://tickers/all"
+	ServerInfoResourceURI   = "luno://server/info"
+	AuditResourceURI        = "luno://audit/recent"
+	AuditTemplateURI        = "luno://audit/recent{?cursor,limit}"
+	JournalResourceURI      = "luno://journal"
+	JournalTemplateURI      = "luno://journal{?cursor,limit}"
+)
+
+// Page sizes for the audit log and trade journal resources
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
 )
 
 // NewWalletResource creates a new resource for Luno wallets
@@ -33,6 +52,7 @@ func NewWalletResource() mcp.Resource {
 // HandleWalletResource returns a handler for the wallet resource
 func HandleWalletResource(cfg *config.Config) server.ResourceHandlerFunc {
 	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		cfg := cfg.ForContext(ctx)
 		if cfg == nil {
 			return nil, fmt.Errorf("configuration is nil")
 		}
@@ -73,6 +93,7 @@ func NewTransactionsResource() mcp.Resource {
 // HandleTransactionsResource returns a handler for the transactions resource
 func HandleTransactionsResource(cfg *config.Config) server.ResourceHandlerFunc {
 	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		cfg := cfg.ForContext(ctx)
 		if cfg == nil {
 			return nil, fmt.Errorf("configuration is nil")
 		}
@@ -153,6 +174,7 @@ func NewAccountTemplate() mcp.ResourceTemplate {
 // HandleAccountTemplate returns a handler for the account resource template
 func HandleAccountTemplate(cfg *config.Config) server.ResourceTemplateHandlerFunc {
 	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		cfg := cfg.ForContext(ctx)
 		if cfg == nil {
 			return nil, fmt.Errorf("configuration is nil")
 		}
@@ -225,6 +247,303 @@ func HandleAccountTemplate(cfg *config.Config) server.ResourceTemplateHandlerFun
 	}
 }
 
+// NewMarketTemplate creates a new resource template for Luno market parameters
+func NewMarketTemplate() mcp.ResourceTemplate {
+	return mcp.NewResourceTemplate(
+		MarketTemplateURI,
+		"Luno Market",
+		mcp.WithTemplateDescription("Returns the trading status, order limits and decimal places for a market, e.g. luno://markets/XBTZAR"),
+		mcp.WithTemplateMIMEType("application/json"),
+	)
+}
+
+// HandleMarketTemplate returns a handler for the market resource template. Markets
+// are served from the market cache.
+func HandleMarketTemplate(cfg *config.Config) server.ResourceTemplateHandlerFunc {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		cfg := cfg.ForContext(ctx)
+		if cfg == nil {
+			return nil, fmt.Errorf("configuration is nil")
+		}
+
+		uri := request.Params.URI
+		pair, ok := strings.CutPrefix(uri, "luno://markets/")
+		if !ok || pair == "" {
+			return nil, fmt.Errorf("invalid market URI format")
+		}
+		pair = strings.ToUpper(pair)
+
+		if cfg.Markets == nil && cfg.LunoClient == nil {
+			return nil, fmt.Errorf("Luno client is not configured")
+		}
+		market, found, err := tools.MarketCache(cfg).Lookup(ctx, pair)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get market: %w", err)
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown market %q", pair)
+		}
+
+		marketJSON, err := json.MarshalIndent(market, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal market: %w", err)
+		}
+
+		return []mcp.ResourceContents{
+			mcp.TextResourceContents{
+				URI:      uri,
+				MIMEType: "application/json",
+				Text:     string(marketJSON),
+			},
+		}, nil
+	}
+}
+
+// NewTickersResource creates a new resource for the tickers of every market
+func NewTickersResource() mcp.Resource {
+	return mcp.NewResource(
+		TickersResourceURI,
+		"Luno Tickers",
+		mcp.WithResourceDescription("Returns the latest ticker of every Luno market, refreshed in the background. "+
+			"Clients are sent notifications/resources/updated after each refresh."),
+		mcp.WithMIMEType("application/json"),
+	)
+}
+
+// TickersPage is the content of the tickers resource
+type TickersPage struct {
+	Tickers   []luno.Ticker `json:"tickers"`
+	UpdatedAt time.Time     `json:"updated_at"`
+	// RefreshIntervalSeconds is how often the tickers are reloaded, or zero if
+	// they are not refreshed in the background
+	RefreshIntervalSeconds float64 `json:"refresh_interval_seconds"`
+}
+
+// HandleTickersResource returns a handler for the tickers resource. Tickers are
+// served from cfg.Tickers, which makes a single request for every market.
+func HandleTickersResource(cfg *config.Config) server.ResourceHandlerFunc {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		cfg := cfg.ForContext(ctx)
+		if cfg == nil {
+			return nil, fmt.Errorf("configuration is nil")
+		}
+		feed := cfg.Tickers
+		if feed == nil {
+			if cfg.LunoClient == nil {
+				return nil, fmt.Errorf("Luno client is not configured")
+			}
+			feed = markets.NewTickers(cfg.LunoClient, 0)
+		}
+
+		tickers, updated, err := feed.All(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tickers: %w", err)
+		}
+		page := TickersPage{
+			Tickers:                tickers,
+			UpdatedAt:              updated.UTC(),
+			RefreshIntervalSeconds: feed.Interval().Seconds(),
+		}
+		pageJSON, err := json.MarshalIndent(page, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal tickers: %w", err)
+		}
+
+		return []mcp.ResourceContents{
+			mcp.TextResourceContents{
+				URI:      TickersResourceURI,
+				MIMEType: "application/json",
+				Text:     string(pageJSON),
+			},
+		}, nil
+	}
+}
+
+// NewServerInfoResource creates a new resource describing the running server
+func NewServerInfoResource() mcp.Resource {
+	return mcp.NewResource(
+		ServerInfoResourceURI,
+		"Luno MCP Server Info",
+		mcp.WithResourceDescription("Returns the server version, enabled tools, configured limits, transport, authentication state and Luno domain"),
+		mcp.WithMIMEType("application/json"),
+	)
+}
+
+// HandleServerInfoResource returns a handler for the server info resource
+func HandleServerInfoResource(cfg *config.Config, name, version string) server.ResourceHandlerFunc {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		cfg := cfg.ForContext(ctx)
+		if cfg == nil {
+			return nil, fmt.Errorf("configuration is nil")
+		}
+
+		infoJSON, err := json.MarshalIndent(tools.BuildServerInfo(ctx, cfg, name, version), "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal server info: %w", err)
+		}
+
+		return []mcp.ResourceContents{
+			mcp.TextResourceContents{
+				URI:      ServerInfoResourceURI,
+				MIMEType: "application/json",
+				Text:     string(infoJSON),
+			},
+		}, nil
+	}
+}
+
+// NewAuditResource creates a resource listing the most recent tool calls
+func NewAuditResource() mcp.Resource {
+	return mcp.NewResource(
+		AuditResourceURI,
+		"Luno MCP Audit Log",
+		mcp.WithResourceDescription(fmt.Sprintf("Returns the most recent tool calls made to this server, newest first, "+
+			"with their arguments and any error. Read next_uri for older calls, or add ?limit= for up to %d per page.", maxPageSize)),
+		mcp.WithMIMEType("application/json"),
+	)
+}
+
+// NewAuditTemplate creates a resource template for paging through the audit log
+func NewAuditTemplate() mcp.ResourceTemplate {
+	return mcp.NewResourceTemplate(
+		AuditTemplateURI,
+		"Luno MCP Audit Log Page",
+		mcp.WithTemplateDescription("Returns a page of the audit log, e.g. luno://audit/recent?cursor=120&limit=50. Use next_uri from the previous page."),
+		mcp.WithTemplateMIMEType("application/json"),
+	)
+}
+
+// AuditPage is a page of the audit log resource
+type AuditPage struct {
+	Entries []audit.Entry `json:"entries"`
+	// NextURI reads the next, older page. It is empty on the last page.
+	NextURI string `json:"next_uri,omitempty"`
+}
+
+// HandleAuditResource returns a handler for the audit log resource and its
+// paging template
+func HandleAuditResource(cfg *config.Config) server.ResourceHandlerFunc {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		cfg := cfg.ForContext(ctx)
+		if cfg == nil || cfg.Audit == nil {
+			return nil, fmt.Errorf("the audit log is not available on this server")
+		}
+		cursor, limit, err := pageParams(request.Params.URI)
+		if err != nil {
+			return nil, err
+		}
+
+		var page AuditPage
+		entries, next := cfg.Audit.Recent(cfg.Tenant, int64(cursor), limit)
+		page.Entries = entries
+		if next > 0 {
+			page.NextURI = pageURI(AuditResourceURI, int(next), limit)
+		}
+		return jsonContents(request.Params.URI, page)
+	}
+}
+
+// NewJournalResource creates a resource listing the trade journal
+func NewJournalResource() mcp.Resource {
+	return mcp.NewResource(
+		JournalResourceURI,
+		"Luno Trade Journal",
+		mcp.WithResourceDescription(fmt.Sprintf("Returns the notes written with log_trade_note, newest first. "+
+			"Read next_uri for older notes, or add ?limit= for up to %d per page.", maxPageSize)),
+		mcp.WithMIMEType("application/json"),
+	)
+}
+
+// NewJournalTemplate creates a resource template for paging through the trade journal
+func NewJournalTemplate() mcp.ResourceTemplate {
+	return mcp.NewResourceTemplate(
+		JournalTemplateURI,
+		"Luno Trade Journal Page",
+		mcp.WithTemplateDescription("Returns a page of the trade journal, e.g. luno://journal?cursor=50&limit=50. Use next_uri from the previous page."),
+		mcp.WithTemplateMIMEType("application/json"),
+	)
+}
+
+// JournalPage is a page of the trade journal resource
+type JournalPage struct {
+	Entries []journal.Entry `json:"entries"`
+	// NextURI reads the next, older page. It is empty on the last page.
+	NextURI string `json:"next_uri,omitempty"`
+}
+
+// HandleJournalResource returns a handler for the trade journal resource and
+// its paging template
+func HandleJournalResource(cfg *config.Config) server.ResourceHandlerFunc {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		cfg := cfg.ForContext(ctx)
+		if cfg == nil || cfg.Journal == nil {
+			return nil, fmt.Errorf("the trade journal is not available on this server")
+		}
+		offset, limit, err := pageParams(request.Params.URI)
+		if err != nil {
+			return nil, err
+		}
+
+		entries, err := cfg.Journal.List(journal.Filter{Tenant: cfg.Tenant})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read trade journal: %w", err)
+		}
+		page := JournalPage{Entries: []journal.Entry{}}
+		for i := len(entries) - 1 - offset; i >= 0 && len(page.Entries) < limit; i-- {
+			page.Entries = append(page.Entries, entries[i])
+		}
+		if end := offset + len(page.Entries); end < len(entries) {
+			page.NextURI = pageURI(JournalResourceURI, end, limit)
+		}
+		return jsonContents(request.Params.URI, page)
+	}
+}
+
+// pageParams reads the cursor and limit query parameters of a paged resource URI
+func pageParams(uri string) (cursor, limit int, err error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid resource URI %q: %w", uri, err)
+	}
+	q := u.Query()
+	limit = defaultPageSize
+	if s := q.Get("limit"); s != "" {
+		if limit, err = strconv.Atoi(s); err != nil || limit < 1 || limit > maxPageSize {
+			return 0, 0, fmt.Errorf("invalid limit %q: must be between 1 and %d", s, maxPageSize)
+		}
+	}
+	if s := q.Get("cursor"); s != "" {
+		if cursor, err = strconv.Atoi(s); err != nil || cursor < 0 {
+			return 0, 0, fmt.Errorf("invalid cursor %q: use next_uri from the previous page", s)
+		}
+	}
+	return cursor, limit, nil
+}
+
+// pageURI builds the URI of a page of the resource at base
+func pageURI(base string, cursor, limit int) string {
+	q := url.Values{}
+	q.Set("cursor", strconv.Itoa(cursor))
+	if limit != defaultPageSize {
+		q.Set("limit", strconv.Itoa(limit))
+	}
+	return base + "?" + q.Encode()
+}
+
+func jsonContents(uri string, v any) ([]mcp.ResourceContents, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s: %w", uri, err)
+	}
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      uri,
+			MIMEType: "application/json",
+			Text:     string(data),
+		},
+	}, nil
+}
+
 // extractAccountID extracts the account ID from a URI like "luno://accounts/{id}"
 func extractAccountID(uri string) string {
 	// Simple extraction assuming the URI is in the format "luno://accounts/123"
@@ -3,11 +3,19 @@ package resources
 import (
 	"context"
 	"encoding/json"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/audit"
 	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/journal"
+	"github.com/luno/luno-mcp/internal/markets"
+	"github.com/luno/luno-mcp/sdk"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -31,6 +39,28 @@ func TestNewTransactionsResource(t *testing.T) {
 	assert.Equal(t, expectedMIMEType, resource.MIMEType)
 }
 
+func TestNewServerInfoResource(t *testing.T) {
+	resource := NewServerInfoResource()
+
+	assert.Equal(t, ServerInfoResourceURI, resource.URI)
+	assert.Equal(t, "Luno MCP Server Info", resource.Name)
+	assert.Equal(t, expectedMIMEType, resource.MIMEType)
+}
+
+func TestHandleServerInfoResource(t *testing.T) {
+	cfg := &config.Config{IsAuthenticated: true, Domain: "api.luno.com"}
+
+	contents, err := HandleServerInfoResource(cfg, "luno-mcp", "1.0.0")(context.Background(), mcp.ReadResourceRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, contents, 1)
+
+	text, ok := contents[0].(mcp.TextResourceContents)
+	assert.True(t, ok)
+	assert.Equal(t, ServerInfoResourceURI, text.URI)
+	assert.Contains(t, text.Text, `"authenticated": true`)
+	assert.Contains(t, text.Text, `"version": "1.0.0"`)
+}
+
 func TestNewAccountTemplate(t *testing.T) {
 	expectedJSON := `{
 		"uriTemplate": "luno://accounts/{id}",
@@ -263,3 +293,166 @@ func TestHandleAccountTemplateIntegration(t *testing.T) {
 		})
 	}
 }
+
+func TestNewMarketTemplate(t *testing.T) {
+	expectedJSON := `{
+		"uriTemplate": "luno://markets/{pair}",
+		"name": "Luno Market",
+		"description": "Returns the trading status, order limits and decimal places for a market, e.g. luno://markets/XBTZAR",
+		"mimeType": "application/json"
+	}`
+
+	actualJSON, err := json.Marshal(NewMarketTemplate())
+	assert.NoError(t, err)
+	assert.JSONEq(t, expectedJSON, string(actualJSON))
+}
+
+func TestHandleMarketTemplate(t *testing.T) {
+	tests := []struct {
+		name          string
+		uri           string
+		mockSetup     func(*sdk.MockLunoClient)
+		expectedError string
+	}{
+		{
+			name: "known market",
+			uri:  "luno://markets/xbtzar",
+			mockSetup: func(client *sdk.MockLunoClient) {
+				client.EXPECT().Markets(context.Background(), &luno.MarketsRequest{}).
+					Return(&luno.MarketsResponse{Markets: []luno.MarketInfo{{MarketId: "XBTZAR", TradingStatus: luno.TradingStatusActive}}}, nil)
+			},
+		},
+		{
+			name: "unknown market",
+			uri:  "luno://markets/XBTZRA",
+			mockSetup: func(client *sdk.MockLunoClient) {
+				client.EXPECT().Markets(context.Background(), &luno.MarketsRequest{}).
+					Return(&luno.MarketsResponse{Markets: []luno.MarketInfo{{MarketId: "XBTZAR"}}}, nil)
+			},
+			expectedError: `unknown market "XBTZRA"`,
+		},
+		{
+			name:          "missing pair",
+			uri:           "luno://markets/",
+			mockSetup:     func(*sdk.MockLunoClient) {},
+			expectedError: "invalid market URI format",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client := sdk.NewMockLunoClient(t)
+			tc.mockSetup(client)
+			cfg := &config.Config{LunoClient: client, Markets: markets.NewCache(client)}
+
+			req := mcp.ReadResourceRequest{}
+			req.Params.URI = tc.uri
+			result, err := HandleMarketTemplate(cfg)(context.Background(), req)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, result, 1)
+			text := result[0].(mcp.TextResourceContents).Text
+			assert.Contains(t, text, `"trading_status": "ACTIVE"`)
+		})
+	}
+}
+
+func TestHandleTickersResource(t *testing.T) {
+	ctx := context.Background()
+	client := sdk.NewMockLunoClient(t)
+	client.EXPECT().GetTickers(ctx, &luno.GetTickersRequest{}).
+		Return(&luno.GetTickersResponse{Tickers: []luno.Ticker{{Pair: "XBTZAR"}, {Pair: "ETHZAR"}}}, nil).Once()
+	cfg := &config.Config{LunoClient: client, Tickers: markets.NewTickers(client, 30*time.Second)}
+
+	var page TickersPage
+	for range 2 {
+		require.NoError(t, readPage(t, HandleTickersResource(cfg), TickersResourceURI, &page))
+	}
+	require.Len(t, page.Tickers, 2)
+	assert.Equal(t, "ETHZAR", page.Tickers[0].Pair)
+	assert.False(t, page.UpdatedAt.IsZero())
+	assert.Equal(t, 30.0, page.RefreshIntervalSeconds)
+}
+
+func readPage(t *testing.T, handler func(context.Context, mcp.ReadResourceRequest) ([]mcp.ResourceContents, error), uri string, page any) error {
+	t.Helper()
+	req := mcp.ReadResourceRequest{}
+	req.Params.URI = uri
+	contents, err := handler(context.Background(), req)
+	if err != nil {
+		return err
+	}
+	require.Len(t, contents, 1)
+	text, ok := contents[0].(mcp.TextResourceContents)
+	require.True(t, ok)
+	assert.Equal(t, uri, text.URI)
+	require.NoError(t, json.Unmarshal([]byte(text.Text), page))
+	return nil
+}
+
+func TestHandleAuditResource(t *testing.T) {
+	cfg := &config.Config{Audit: audit.NewLog(100)}
+	for _, tool := range []string{"get_ticker", "create_order", "list_orders"} {
+		cfg.Audit.Record(audit.Entry{Tool: tool})
+	}
+	handler := HandleAuditResource(cfg)
+
+	var page AuditPage
+	require.NoError(t, readPage(t, handler, AuditResourceURI, &page))
+	assert.Len(t, page.Entries, 3)
+	assert.Equal(t, "list_orders", page.Entries[0].Tool)
+	assert.Empty(t, page.NextURI)
+
+	page = AuditPage{}
+	require.NoError(t, readPage(t, handler, "luno://audit/recent?limit=2", &page))
+	assert.Len(t, page.Entries, 2)
+	assert.Equal(t, "luno://audit/recent?cursor=2&limit=2", page.NextURI)
+
+	page = AuditPage{}
+	require.NoError(t, readPage(t, handler, "luno://audit/recent?cursor=2&limit=2", &page))
+	require.Len(t, page.Entries, 1)
+	assert.Equal(t, "get_ticker", page.Entries[0].Tool)
+	assert.Empty(t, page.NextURI)
+
+	assert.ErrorContains(t, readPage(t, handler, "luno://audit/recent?limit=1000", &page), "invalid limit")
+	assert.ErrorContains(t, readPage(t, handler, "luno://audit/recent?cursor=abc", &page), "invalid cursor")
+	assert.ErrorContains(t, readPage(t, HandleAuditResource(&config.Config{}), AuditResourceURI, &page), "not available")
+}
+
+func TestHandleJournalResource(t *testing.T) {
+	cfg := &config.Config{Journal: journal.New("")}
+	for _, note := range []string{"First", "Second", "Third"} {
+		_, err := cfg.Journal.Add(journal.Entry{Note: note})
+		require.NoError(t, err)
+	}
+	handler := HandleJournalResource(cfg)
+
+	var page JournalPage
+	require.NoError(t, readPage(t, handler, JournalResourceURI, &page))
+	require.Len(t, page.Entries, 3)
+	assert.Equal(t, "Third", page.Entries[0].Note)
+	assert.Empty(t, page.NextURI)
+
+	page = JournalPage{}
+	require.NoError(t, readPage(t, handler, "luno://journal?limit=2", &page))
+	assert.Len(t, page.Entries, 2)
+	assert.Equal(t, "luno://journal?cursor=2&limit=2", page.NextURI)
+
+	page = JournalPage{}
+	require.NoError(t, readPage(t, handler, "luno://journal?cursor=2&limit=2", &page))
+	require.Len(t, page.Entries, 1)
+	assert.Equal(t, "First", page.Entries[0].Note)
+	assert.Empty(t, page.NextURI)
+}
+
+func TestPageTemplatesMatchPages(t *testing.T) {
+	for _, tpl := range []mcp.ResourceTemplate{NewAuditTemplate(), NewJournalTemplate()} {
+		for _, query := range []string{"", "?cursor=50", "?cursor=50&limit=10"} {
+			base := strings.TrimSuffix(tpl.URITemplate.Raw(), "{?cursor,limit}")
+			assert.True(t, tpl.URITemplate.Regexp().MatchString(base+query), base+query)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+// Package roles maps the access tokens of a server shared over HTTP to roles,
+// which decide the tools each caller may use.
+package roles
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/luno/luno-mcp/internal/redact"
+)
+
+// Role is what a caller may do. Each role may do everything the ones before
+// it may.
+type Role string
+
+const (
+	// Viewer may only use tools without side effects
+	Viewer Role = "viewer"
+	// Trader may also place and cancel orders, and use tools that write files
+	// or deliver reports
+	Trader Role = "trader"
+	// Admin may also withdraw funds
+	Admin Role = "admin"
+)
+
+// all lists the roles from least to most privileged
+var all = []Role{Viewer, Trader, Admin}
+
+// Parse returns the role named s
+func Parse(s string) (Role, error) {
+	r := Role(strings.ToLower(strings.TrimSpace(s)))
+	if !slices.Contains(all, r) {
+		return "", fmt.Errorf("unknown role %q: want viewer, trader or admin", s)
+	}
+	return r, nil
+}
+
+// Allows reports whether r may do everything required may
+func (r Role) Allows(required Role) bool {
+	return slices.Index(all, r) >= slices.Index(all, required)
+}
+
+type tokenRole struct {
+	token string
+	role  Role
+}
+
+// Tokens are the access tokens callers authenticate with and their roles
+type Tokens struct {
+	tokens []tokenRole
+}
+
+// ParseTokens reads comma-separated token:role pairs, such as
+// "s3cret:viewer,0ther:trader". The tokens are registered with redact.
+func ParseTokens(spec string) (*Tokens, error) {
+	t := &Tokens{}
+	for entry := range strings.SplitSeq(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		i := strings.LastIndex(entry, ":")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid access token entry: want token:role")
+		}
+		token := entry[:i]
+		role, err := Parse(entry[i+1:])
+		if err != nil {
+			return nil, err
+		}
+		if slices.ContainsFunc(t.tokens, func(tr tokenRole) bool { return tr.token == token }) {
+			return nil, fmt.Errorf("an access token is listed more than once")
+		}
+		redact.Register(token)
+		t.tokens = append(t.tokens, tokenRole{token: token, role: role})
+	}
+	if len(t.tokens) == 0 {
+		return nil, fmt.Errorf("no access tokens given")
+	}
+	return t, nil
+}
+
+// Len returns the number of tokens
+func (t *Tokens) Len() int {
+	return len(t.tokens)
+}
+
+// Role returns the role of token. Every token is compared, in constant time,
+// so that timing doesn't reveal which ones are close.
+func (t *Tokens) Role(token string) (Role, bool) {
+	var found Role
+	for _, tr := range t.tokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(tr.token)) == 1 {
+			found = tr.role
+		}
+	}
+	return found, found != ""
+}
+
+// FromHeader returns the role of the bearer token in h's Authorization header
+func (t *Tokens) FromHeader(h http.Header) (Role, bool) {
+	token, ok := strings.CutPrefix(h.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return "", false
+	}
+	return t.Role(token)
+}
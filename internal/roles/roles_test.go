@@ -0,0 +1,54 @@
+package roles
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoleAllows(t *testing.T) {
+	assert.True(t, Viewer.Allows(Viewer))
+	assert.False(t, Viewer.Allows(Trader))
+	assert.True(t, Trader.Allows(Viewer))
+	assert.False(t, Trader.Allows(Admin))
+	assert.True(t, Admin.Allows(Trader))
+	assert.False(t, Role("").Allows(Viewer))
+}
+
+func TestParseTokens(t *testing.T) {
+	tokens, err := ParseTokens(" viewer-token:viewer, trader:token:Trader ,")
+	require.NoError(t, err)
+	assert.Equal(t, 2, tokens.Len())
+
+	role, ok := tokens.Role("viewer-token")
+	assert.True(t, ok)
+	assert.Equal(t, Viewer, role)
+	role, ok = tokens.Role("trader:token")
+	assert.True(t, ok)
+	assert.Equal(t, Trader, role)
+	_, ok = tokens.Role("viewer")
+	assert.False(t, ok)
+	_, ok = tokens.Role("")
+	assert.False(t, ok)
+
+	for _, spec := range []string{"", "token", ":viewer", "token:owner", "token:viewer,token:admin"} {
+		_, err := ParseTokens(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestFromHeader(t *testing.T) {
+	tokens, err := ParseTokens("admin-token:admin")
+	require.NoError(t, err)
+
+	role, ok := tokens.FromHeader(http.Header{"Authorization": {"Bearer admin-token"}})
+	assert.True(t, ok)
+	assert.Equal(t, Admin, role)
+
+	for _, h := range []http.Header{nil, {"Authorization": {"admin-token"}}, {"Authorization": {"Bearer "}}, {"Authorization": {"Bearer other"}}} {
+		_, ok := tokens.FromHeader(h)
+		assert.False(t, ok, h)
+	}
+}
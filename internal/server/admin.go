@@ -0,0 +1,319 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/luno/luno-mcp/internal/approvals"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/usage"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// adminMaxBodyBytes bounds the size of admin API request bodies
+const adminMaxBodyBytes = 64 << 10
+
+// AdminSession is a session as listed by the admin API
+type AdminSession struct {
+	usage.SessionStats
+	// OwnCredentials is set when the session calls Luno with its own API
+	// credentials rather than the server's
+	OwnCredentials bool `json:"own_credentials"`
+	Revoked        bool `json:"revoked"`
+}
+
+// AdminLimits are the concurrency limits read and set through the admin API.
+// Zero is no limit. The queue timeout is reported but can't be changed.
+type AdminLimits struct {
+	MaxConcurrentCalls        *int `json:"max_concurrent_calls"`
+	MaxConcurrentCallsPerTool *int `json:"max_concurrent_calls_per_tool"`
+	CallQueueTimeoutSeconds   int  `json:"call_queue_timeout_seconds"`
+}
+
+// AdminMetrics are the totals of the sessions active in the last day, and of
+// each tool since Since
+type AdminMetrics struct {
+	Since          time.Time          `json:"since"`
+	ActiveSessions int                `json:"active_sessions"`
+	Calls          int64              `json:"calls"`
+	Errors         int64              `json:"errors"`
+	ResponseBytes  int64              `json:"response_bytes"`
+	API            usage.APIStats     `json:"luno_api"`
+	Cache          usage.CacheStats   `json:"cache"`
+	RateLimits     usage.RateLimits   `json:"rate_limits"`
+	Trading        usage.TradingStats `json:"trading"`
+	Tools          []usage.ToolStats  `json:"tools"`
+}
+
+// ServeAdmin serves the admin API on cfg.AdminAddr until ctx is cancelled. It
+// is separate from the MCP transport so that it can be kept off the network
+// MCP clients reach, and every request must carry cfg.AdminToken as a bearer
+// token.
+func ServeAdmin(ctx context.Context, s *mcpserver.MCPServer, cfg *config.Config) error {
+	ln, err := net.Listen("tcp", cfg.AdminAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", cfg.AdminAddr, err)
+	}
+	srv := &http.Server{
+		Handler:           adminHandler(s, cfg),
+		ReadHeaderTimeout: httpReadHeaderTimeout,
+		IdleTimeout:       httpIdleTimeout,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.Serve(ln)
+	}()
+	slog.Info("Admin API listening", slog.String("address", ln.Addr().String()))
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), httpShutdownTimeout)
+	defer cancel()
+	return srv.Shutdown(shutdownCtx)
+}
+
+// adminHandler routes the admin API:
+//
+//	GET    /sessions       sessions active in the last day
+//	GET    /sessions/{id}  one session
+//	DELETE /sessions/{id}  revoke a session
+//	GET    /limits         concurrency limits
+//	PUT    /limits         change concurrency limits
+//	GET    /metrics        totals of every session and tool
+//
+//	GET    /approvals               actions held for approval, newest first
+//	GET    /approvals/{id}          one approval request
+//	POST   /approvals/{id}/approve  approve a pending request
+//	POST   /approvals/{id}/reject   reject a pending request
+func adminHandler(s *mcpserver.MCPServer, cfg *config.Config) http.Handler {
+	a := admin{server: s, cfg: cfg}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /sessions", a.listSessions)
+	mux.HandleFunc("GET /sessions/{id}", a.getSession)
+	mux.HandleFunc("DELETE /sessions/{id}", a.revokeSession)
+	mux.HandleFunc("GET /limits", a.getLimits)
+	mux.HandleFunc("PUT /limits", a.setLimits)
+	mux.HandleFunc("GET /metrics", a.metrics)
+	mux.HandleFunc("GET /approvals", a.listApprovals)
+	mux.HandleFunc("GET /approvals/{id}", a.getApproval)
+	mux.HandleFunc("POST /approvals/{id}/approve", a.decideApproval(true))
+	mux.HandleFunc("POST /approvals/{id}/reject", a.decideApproval(false))
+	return requireBearerToken(cfg.AdminToken, mux)
+}
+
+// requireBearerToken refuses requests that don't carry token
+func requireBearerToken(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == "" || !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeAdminError(w, http.StatusUnauthorized, "a valid admin token is required")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+type admin struct {
+	server *mcpserver.MCPServer
+	cfg    *config.Config
+}
+
+func (a admin) listSessions(w http.ResponseWriter, _ *http.Request) {
+	sessions := []AdminSession{}
+	for _, st := range a.sessionStats() {
+		sessions = append(sessions, a.session(st))
+	}
+	writeAdminJSON(w, http.StatusOK, sessions)
+}
+
+func (a admin) getSession(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if a.cfg.Usage == nil {
+		writeAdminError(w, http.StatusNotFound, "session not found")
+		return
+	}
+	st, ok := a.cfg.Usage.LookupSession(id)
+	if !ok {
+		writeAdminError(w, http.StatusNotFound, "session not found")
+		return
+	}
+	writeAdminJSON(w, http.StatusOK, a.session(st))
+}
+
+// revokeSession refuses the session's further calls, drops any credentials it
+// was given and disconnects it. Revoking an unknown session still stops it
+// from being used later.
+func (a admin) revokeSession(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if a.cfg.Sessions == nil {
+		writeAdminError(w, http.StatusNotImplemented, "sessions can't be revoked on this server")
+		return
+	}
+	a.cfg.Sessions.Revoke(id)
+	a.server.UnregisterSession(r.Context(), id)
+	slog.Info("Session revoked through the admin API", slog.String("session_id", id))
+	writeAdminJSON(w, http.StatusOK, map[string]any{"session_id": id, "revoked": true})
+}
+
+func (a admin) getLimits(w http.ResponseWriter, _ *http.Request) {
+	writeAdminJSON(w, http.StatusOK, a.limits())
+}
+
+// setLimits changes the limits given in the body, leaving the others as they are
+func (a admin) setLimits(w http.ResponseWriter, r *http.Request) {
+	if a.cfg.Limiter == nil {
+		writeAdminError(w, http.StatusNotImplemented, "tool calls are not limited on this server")
+		return
+	}
+	var req AdminLimits
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, adminMaxBodyBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeAdminError(w, http.StatusBadRequest, fmt.Sprintf("invalid limits: %v", err))
+		return
+	}
+	maxCalls, maxCallsPerTool := a.cfg.Limiter.MaxCalls(), a.cfg.Limiter.MaxCallsPerTool()
+	if req.MaxConcurrentCalls != nil {
+		maxCalls = *req.MaxConcurrentCalls
+	}
+	if req.MaxConcurrentCallsPerTool != nil {
+		maxCallsPerTool = *req.MaxConcurrentCallsPerTool
+	}
+	if maxCalls < 0 || maxCallsPerTool < 0 {
+		writeAdminError(w, http.StatusBadRequest, "limits must be zero, for no limit, or more")
+		return
+	}
+	a.cfg.Limiter.SetLimits(maxCalls, maxCallsPerTool)
+	slog.Info("Concurrency limits changed through the admin API",
+		slog.Int("max_concurrent_calls", maxCalls), slog.Int("max_concurrent_calls_per_tool", maxCallsPerTool))
+	writeAdminJSON(w, http.StatusOK, a.limits())
+}
+
+func (a admin) metrics(w http.ResponseWriter, _ *http.Request) {
+	m := AdminMetrics{Tools: []usage.ToolStats{}}
+	if a.cfg.Usage != nil {
+		m.Tools, m.Since = a.cfg.Usage.Stats()
+		m.Since = m.Since.UTC()
+	}
+	for _, st := range a.sessionStats() {
+		m.ActiveSessions++
+		m.Calls += st.Calls
+		m.Errors += st.Errors
+		m.ResponseBytes += st.ResponseBytes
+		m.API.Calls += st.API.Calls
+		m.API.Errors += st.API.Errors
+		m.API.BytesSent += st.API.BytesSent
+		m.API.BytesReceived += st.API.BytesReceived
+		m.Cache.Hits += st.Cache.Hits
+		m.Cache.Misses += st.Cache.Misses
+		m.RateLimits.LunoRejected += st.RateLimits.LunoRejected
+		m.RateLimits.CallsRejected += st.RateLimits.CallsRejected
+		m.RateLimits.RepeatsReused += st.RateLimits.RepeatsReused
+		m.Trading.OrdersPlaced += st.Trading.OrdersPlaced
+		m.Trading.OrdersCancelled += st.Trading.OrdersCancelled
+		m.Trading.WithdrawalsCreated += st.Trading.WithdrawalsCreated
+	}
+	if lookups := m.Cache.Hits + m.Cache.Misses; lookups > 0 {
+		m.Cache.HitRate = float64(m.Cache.Hits) / float64(lookups)
+	}
+	writeAdminJSON(w, http.StatusOK, m)
+}
+
+func (a admin) listApprovals(w http.ResponseWriter, _ *http.Request) {
+	if a.cfg.Approvals == nil {
+		writeAdminError(w, http.StatusNotImplemented, "actions are not held for approval on this server")
+		return
+	}
+	writeAdminJSON(w, http.StatusOK, a.cfg.Approvals.List())
+}
+
+func (a admin) getApproval(w http.ResponseWriter, r *http.Request) {
+	if a.cfg.Approvals == nil {
+		writeAdminError(w, http.StatusNotImplemented, "actions are not held for approval on this server")
+		return
+	}
+	req, ok := a.cfg.Approvals.Get(r.PathValue("id"))
+	if !ok {
+		writeAdminError(w, http.StatusNotFound, "approval request not found")
+		return
+	}
+	writeAdminJSON(w, http.StatusOK, req)
+}
+
+// decideApproval approves or rejects a pending request, with an optional
+// {"reason": "..."} body
+func (a admin) decideApproval(approve bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if a.cfg.Approvals == nil {
+			writeAdminError(w, http.StatusNotImplemented, "actions are not held for approval on this server")
+			return
+		}
+		var body struct {
+			Reason string `json:"reason"`
+		}
+		if r.ContentLength != 0 {
+			dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, adminMaxBodyBytes))
+			dec.DisallowUnknownFields()
+			if err := dec.Decode(&body); err != nil {
+				writeAdminError(w, http.StatusBadRequest, fmt.Sprintf("invalid decision: %v", err))
+				return
+			}
+		}
+		req, err := a.cfg.Approvals.Decide(r.PathValue("id"), approve, "admin", body.Reason)
+		switch {
+		case errors.Is(err, approvals.ErrNotFound):
+			writeAdminError(w, http.StatusNotFound, "approval request not found")
+			return
+		case errors.Is(err, approvals.ErrDecided):
+			writeAdminError(w, http.StatusConflict, fmt.Sprintf("approval request is already %s", req.Status))
+			return
+		}
+		slog.Info("Approval request decided through the admin API",
+			slog.String("approval_id", req.ID), slog.String("tool", req.Tool), slog.Bool("approved", approve))
+		writeAdminJSON(w, http.StatusOK, req)
+	}
+}
+
+func (a admin) sessionStats() []usage.SessionStats {
+	if a.cfg.Usage == nil {
+		return nil
+	}
+	return a.cfg.Usage.Sessions()
+}
+
+func (a admin) session(st usage.SessionStats) AdminSession {
+	_, own := a.cfg.Sessions.Get(st.SessionID)
+	return AdminSession{SessionStats: st, OwnCredentials: own, Revoked: a.cfg.Sessions.Revoked(st.SessionID)}
+}
+
+func (a admin) limits() AdminLimits {
+	maxCalls, maxCallsPerTool := a.cfg.Limiter.MaxCalls(), a.cfg.Limiter.MaxCallsPerTool()
+	return AdminLimits{
+		MaxConcurrentCalls:        &maxCalls,
+		MaxConcurrentCallsPerTool: &maxCallsPerTool,
+		CallQueueTimeoutSeconds:   int(a.cfg.Limiter.QueueTimeout().Seconds()),
+	}
+}
+
+func writeAdminJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeAdminError(w http.ResponseWriter, status int, msg string) {
+	writeAdminJSON(w, status, map[string]string{"error": msg})
+}
@@ -0,0 +1,178 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-mcp/internal/approvals"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/limiter"
+	"github.com/luno/luno-mcp/internal/usage"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testAdminToken = "admin-token"
+
+func newTestAdmin(t *testing.T) (*config.Config, *mcpserver.MCPServer, func(method, path, body string) *httptest.ResponseRecorder) {
+	t.Helper()
+	cfg := &config.Config{
+		Usage:      usage.NewTracker(),
+		Sessions:   config.NewSessionOverlays(),
+		Limiter:    limiter.New(16, 4, 30*time.Second),
+		AdminToken: testAdminToken,
+	}
+	srv := mcpserver.NewMCPServer(testServerName, testVersion1)
+	h := adminHandler(srv, cfg)
+	return cfg, srv, func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+testAdminToken)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+}
+
+func TestAdminRequiresToken(t *testing.T) {
+	cfg, srv, _ := newTestAdmin(t)
+	h := adminHandler(srv, cfg)
+
+	for _, auth := range []string{"", "Bearer wrong", testAdminToken} {
+		req := httptest.NewRequest(http.MethodGet, "/sessions", nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, auth)
+		assert.Equal(t, "Bearer", rec.Header().Get("WWW-Authenticate"))
+	}
+}
+
+func TestAdminSessions(t *testing.T) {
+	cfg, _, do := newTestAdmin(t)
+	cfg.Usage.Session("session-1").ToolCall("get_ticker", 100, false)
+	cfg.Sessions.Set("session-1", config.Overlay{IsAuthenticated: true})
+
+	rec := do(http.MethodGet, "/sessions", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var sessions []AdminSession
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &sessions))
+	require.Len(t, sessions, 1)
+	assert.Equal(t, "session-1", sessions[0].SessionID)
+	assert.Equal(t, int64(1), sessions[0].Calls)
+	assert.True(t, sessions[0].OwnCredentials)
+	assert.False(t, sessions[0].Revoked)
+
+	assert.Equal(t, http.StatusNotFound, do(http.MethodGet, "/sessions/unknown", "").Code)
+
+	rec = do(http.MethodDelete, "/sessions/session-1", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"session_id":"session-1","revoked":true}`, rec.Body.String())
+
+	rec = do(http.MethodGet, "/sessions/session-1", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var session AdminSession
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &session))
+	assert.True(t, session.Revoked)
+	assert.False(t, session.OwnCredentials, "revoking drops the session's credentials")
+}
+
+func TestAdminLimits(t *testing.T) {
+	cfg, _, do := newTestAdmin(t)
+
+	rec := do(http.MethodGet, "/limits", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"max_concurrent_calls":16,"max_concurrent_calls_per_tool":4,"call_queue_timeout_seconds":30}`, rec.Body.String())
+
+	rec = do(http.MethodPut, "/limits", `{"max_concurrent_calls_per_tool":1}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"max_concurrent_calls":16,"max_concurrent_calls_per_tool":1,"call_queue_timeout_seconds":30}`, rec.Body.String())
+	assert.Equal(t, 1, cfg.Limiter.MaxCallsPerTool())
+
+	assert.Equal(t, http.StatusBadRequest, do(http.MethodPut, "/limits", `{"max_concurrent_calls":-1}`).Code)
+	assert.Equal(t, http.StatusBadRequest, do(http.MethodPut, "/limits", `{"max_calls":1}`).Code)
+	assert.Equal(t, 16, cfg.Limiter.MaxCalls())
+}
+
+func TestAdminMetrics(t *testing.T) {
+	cfg, _, do := newTestAdmin(t)
+	cfg.Usage.Record(usage.Call{Tool: "get_ticker", ResponseBytes: 100})
+	cfg.Usage.Session("session-1").ToolCall("get_ticker", 100, false)
+	cfg.Usage.Session("session-2").ToolCall("get_ticker", 50, true)
+	cfg.Usage.Session("session-2").CacheLookup(true)
+
+	rec := do(http.MethodGet, "/metrics", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var m AdminMetrics
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &m))
+	assert.Equal(t, 2, m.ActiveSessions)
+	assert.Equal(t, int64(2), m.Calls)
+	assert.Equal(t, int64(1), m.Errors)
+	assert.Equal(t, int64(150), m.ResponseBytes)
+	assert.Equal(t, 1.0, m.Cache.HitRate)
+	require.Len(t, m.Tools, 1)
+	assert.Equal(t, "get_ticker", m.Tools[0].Tool)
+}
+
+func TestAdminApprovals(t *testing.T) {
+	cfg, _, do := newTestAdmin(t)
+	assert.Equal(t, http.StatusNotImplemented, do(http.MethodGet, "/approvals", "").Code)
+
+	thresholds, err := approvals.ParseThresholds("ZAR:1000")
+	require.NoError(t, err)
+	cfg.Approvals = approvals.NewQueue(thresholds, time.Minute)
+	first, err := cfg.Approvals.Park("session-1", "create_order", map[string]any{"volume": "1"}, nil, nil, "")
+	require.NoError(t, err)
+	second, err := cfg.Approvals.Park("session-1", "create_order", map[string]any{"volume": "2"}, nil, nil, "")
+	require.NoError(t, err)
+
+	rec := do(http.MethodGet, "/approvals", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var list []approvals.Request
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	require.Len(t, list, 2)
+	assert.Equal(t, second.ID, list[0].ID)
+
+	rec = do(http.MethodPost, "/approvals/"+first.ID+"/approve", `{"reason":"checked with the desk"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var decided approvals.Request
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &decided))
+	assert.Equal(t, approvals.StatusApproved, decided.Status)
+	assert.Equal(t, "checked with the desk", decided.Reason)
+	assert.Equal(t, "admin", decided.DecidedBy)
+	assert.Equal(t, http.StatusConflict, do(http.MethodPost, "/approvals/"+first.ID+"/reject", "").Code)
+
+	rec = do(http.MethodPost, "/approvals/"+second.ID+"/reject", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	rec = do(http.MethodGet, "/approvals/"+second.ID, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"status":"rejected"`)
+
+	assert.Equal(t, http.StatusNotFound, do(http.MethodPost, "/approvals/unknown/approve", "").Code)
+	assert.Equal(t, http.StatusBadRequest, do(http.MethodPost, "/approvals/"+first.ID+"/approve", `{"approve":true}`).Code)
+}
+
+func TestRevokedSessionMiddleware(t *testing.T) {
+	cfg, srv, _ := newTestAdmin(t)
+	handler := revokedSessionMiddleware(cfg)(func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	})
+	ctx := srv.WithContext(context.Background(), mcpserver.NewInProcessSession("session-1", nil))
+
+	res, err := handler(ctx, mcp.CallToolRequest{})
+	require.NoError(t, err)
+	assert.False(t, res.IsError)
+
+	cfg.Sessions.Revoke("session-1")
+	res, err = handler(ctx, mcp.CallToolRequest{})
+	require.NoError(t, err)
+	assert.True(t, res.IsError)
+}
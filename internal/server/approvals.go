@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/luno/luno-mcp/internal/approvals"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/tools"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// approvalMiddleware holds orders and withdrawals worth more than the
+// thresholds of cfg.Approvals, returning a pending_approval result instead of
+// running them. Once approved, the call runs when it is made again with the
+// same arguments. It runs inside tradingHoursMiddleware, so calls outside the
+// trading windows are refused rather than held, and inside auditMiddleware,
+// so held calls are in the audit log.
+func approvalMiddleware(cfg *config.Config) mcpserver.ToolHandlerMiddleware {
+	return func(next mcpserver.ToolHandlerFunc) mcpserver.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if cfg.Approvals == nil {
+				return next(ctx, request)
+			}
+			tool, args := request.Params.Name, request.GetArguments()
+			amounts, action, err := tools.ActionAmounts(ctx, cfg.ForContext(withSessionOverlay(ctx, cfg)), tool, args)
+			if !action {
+				return next(ctx, request)
+			}
+			sessionID := ""
+			if session := mcpserver.ClientSessionFromContext(ctx); session != nil {
+				sessionID = session.SessionID()
+			}
+			if r, ok := cfg.Approvals.Take(sessionID, tool, args); ok {
+				slog.InfoContext(ctx, "Running approved call", slog.String("tool", tool), slog.String("approval_id", r.ID))
+				return next(ctx, request)
+			}
+
+			// Calls that can't be valued are held too, rather than let through
+			exceeded := cfg.Approvals.Thresholds().Exceeded(amounts)
+			note := ""
+			if err != nil {
+				note = fmt.Sprintf("Held because its value could not be worked out: %v", err)
+			} else if len(exceeded) == 0 {
+				return next(ctx, request)
+			}
+			r, err := cfg.Approvals.Park(sessionID, tool, args, amounts, exceeded, note)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to hold %s for approval: %v", tool, err)), nil
+			}
+			slog.InfoContext(ctx, "Call held for approval", slog.String("tool", tool), slog.String("approval_id", r.ID))
+			return pendingApprovalResult(r), nil
+		}
+	}
+}
+
+// pendingApprovalResult tells the caller its call is waiting for approval.
+// It is not an error: the call may yet run.
+func pendingApprovalResult(r approvals.Request) *mcp.CallToolResult {
+	text := fmt.Sprintf("Approval required: %s was not run because its value is above the approval threshold. "+
+		"It is waiting for an approver as request %s, until %s. Tell the user, and check on it with %s; "+
+		"once approved, call %s again with exactly the same arguments to run it.",
+		r.Tool, r.ID, r.ExpiresAt.Format(time.RFC3339), tools.GetApprovalStatusToolID, r.Tool)
+	if r.Note != "" {
+		text += " " + r.Note + "."
+	}
+	result := mcp.NewToolResultText(text)
+	result.StructuredContent = map[string]any{
+		"status":      "pending_approval",
+		"approval_id": r.ID,
+		"tool":        r.Tool,
+		"amounts":     r.Amounts,
+		"exceeded":    r.Exceeded,
+		"expires_at":  r.ExpiresAt.Format(time.RFC3339),
+		"next":        tools.ApprovalNext(r),
+	}
+	return result
+}
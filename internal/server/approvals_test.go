@@ -0,0 +1,118 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/internal/approvals"
+	"github.com/luno/luno-mcp/internal/audit"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/markets"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApprovalMiddleware(t *testing.T) {
+	ctx := context.Background()
+	mockClient := sdk.NewMockLunoClient(t)
+	mockClient.EXPECT().Markets(mock.Anything, &luno.MarketsRequest{}).Return(&luno.MarketsResponse{Markets: []luno.MarketInfo{
+		{MarketId: "XBTZAR", BaseCurrency: "XBT", CounterCurrency: "ZAR"},
+	}}, nil).Once()
+	thresholds, err := approvals.ParseThresholds("ZAR:100000")
+	require.NoError(t, err)
+	cfg := &config.Config{
+		LunoClient: mockClient,
+		Markets:    markets.NewCache(mockClient),
+		Audit:      audit.NewLog(10),
+		Approvals:  approvals.NewQueue(thresholds, time.Minute),
+	}
+
+	called := 0
+	handler := auditMiddleware(cfg)(approvalMiddleware(cfg)(func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		called++
+		return mcp.NewToolResultText("Order created"), nil
+	}))
+	call := func(volume string) *mcp.CallToolResult {
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "create_order"
+		req.Params.Arguments = map[string]any{"pair": "XBTZAR", "type": "BUY", "volume": volume, "price": "1000000"}
+		result, err := handler(ctx, req)
+		require.NoError(t, err)
+		return result
+	}
+
+	assert.Equal(t, "Order created", call("0.05").Content[0].(mcp.TextContent).Text, "orders below the thresholds run")
+	assert.Equal(t, 1, called)
+
+	result := call("0.5")
+	assert.False(t, result.IsError)
+	structured := result.StructuredContent.(map[string]any)
+	assert.Equal(t, "pending_approval", structured["status"])
+	id := structured["approval_id"].(string)
+	assert.Equal(t, 1, called, "orders above the thresholds are held")
+	assert.Equal(t, id, call("0.5").StructuredContent.(map[string]any)["approval_id"], "retries get the same request")
+
+	_, err = cfg.Approvals.Decide(id, true, "admin", "")
+	require.NoError(t, err)
+	assert.Equal(t, "pending_approval", call("0.6").StructuredContent.(map[string]any)["status"], "an approval only runs its own call")
+	assert.Equal(t, "Order created", call("0.5").Content[0].(mcp.TextContent).Text)
+	assert.Equal(t, 2, called)
+	assert.Equal(t, "pending_approval", call("0.5").StructuredContent.(map[string]any)["status"], "an approval runs its call once")
+
+	entries, _ := cfg.Audit.Recent("", 0, 10)
+	assert.Len(t, entries, 6, "held calls are audited")
+
+	cfg.Approvals = nil
+	call("5")
+	assert.Equal(t, 3, called, "nothing is held without approvals")
+}
+
+func TestApprovalMiddlewareReplaceOrder(t *testing.T) {
+	ctx := context.Background()
+	mockClient := sdk.NewMockLunoClient(t)
+	mockClient.EXPECT().Markets(mock.Anything, &luno.MarketsRequest{}).Return(&luno.MarketsResponse{Markets: []luno.MarketInfo{
+		{MarketId: "XBTZAR", BaseCurrency: "XBT", CounterCurrency: "ZAR"},
+	}}, nil).Once()
+	mockClient.EXPECT().GetOrder(mock.Anything, &luno.GetOrderRequest{Id: "BXSMALL"}).Return(&luno.GetOrderResponse{
+		OrderId:     "BXSMALL",
+		Pair:        "XBTZAR",
+		State:       luno.OrderStatePending,
+		LimitVolume: decimal.NewFromInt64(1),
+		LimitPrice:  decimal.NewFromInt64(50000),
+	}, nil)
+	thresholds, err := approvals.ParseThresholds("ZAR:100000")
+	require.NoError(t, err)
+	cfg := &config.Config{
+		LunoClient: mockClient,
+		Markets:    markets.NewCache(mockClient),
+		Approvals:  approvals.NewQueue(thresholds, time.Minute),
+	}
+
+	called := 0
+	handler := approvalMiddleware(cfg)(func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		called++
+		return mcp.NewToolResultText("Order replaced"), nil
+	})
+	call := func(args map[string]any) *mcp.CallToolResult {
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "replace_order"
+		req.Params.Arguments = args
+		result, err := handler(ctx, req)
+		require.NoError(t, err)
+		return result
+	}
+
+	assert.Equal(t, "Order replaced", call(map[string]any{"order_id": "BXSMALL", "price": "60000"}).Content[0].(mcp.TextContent).Text,
+		"small replacements run")
+	assert.Equal(t, 1, called)
+
+	result := call(map[string]any{"order_id": "BXSMALL", "volume": "5"})
+	assert.Equal(t, "pending_approval", result.StructuredContent.(map[string]any)["status"], "a small order can't be replaced with a large one")
+	assert.Equal(t, 1, called)
+}
@@ -0,0 +1,70 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/luno/luno-mcp/internal/audit"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/redact"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// maxAuditedBytes caps the arguments and error text kept for each audited call
+const maxAuditedBytes = 1024
+
+// auditMiddleware records every tool call in cfg.Audit
+func auditMiddleware(cfg *config.Config) mcpserver.ToolHandlerMiddleware {
+	return func(next mcpserver.ToolHandlerFunc) mcpserver.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if cfg.Audit == nil {
+				return next(ctx, request)
+			}
+
+			start := time.Now()
+			ctx, changes := contextWithBalanceChanges(ctx)
+			result, err := next(ctx, request)
+
+			entry := audit.Entry{
+				Time:           start.UTC(),
+				Tool:           request.Params.Name,
+				DurationMS:     time.Since(start).Milliseconds(),
+				BalanceChanges: *changes,
+				Tenant:         cfg.ForContext(withSessionOverlay(ctx, cfg)).Tenant,
+			}
+			if args := request.GetArguments(); len(args) > 0 {
+				if b, marshalErr := json.Marshal(args); marshalErr == nil {
+					entry.Arguments = truncateAudited(redact.String(string(b)))
+				}
+			}
+			if md, ok := sdk.RequestMetadataFromContext(ctx); ok {
+				entry.SessionID = md.SessionID
+				entry.RequestID = md.RequestID
+			}
+			switch {
+			case err != nil:
+				entry.Error = truncateAudited(redact.String(err.Error()))
+			case result != nil && result.IsError:
+				entry.Error = "tool returned an error"
+				for _, c := range result.Content {
+					if text, ok := c.(mcp.TextContent); ok {
+						entry.Error = truncateAudited(redact.String(text.Text))
+						break
+					}
+				}
+			}
+			cfg.Audit.Record(entry)
+			return result, err
+		}
+	}
+}
+
+func truncateAudited(s string) string {
+	if len(s) <= maxAuditedBytes {
+		return s
+	}
+	return s[:maxAuditedBytes] + "…"
+}
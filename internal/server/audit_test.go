@@ -0,0 +1,86 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/audit"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/redact"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditMiddleware(t *testing.T) {
+	redact.Register("audit-secret-value")
+	cfg := &config.Config{Audit: audit.NewLog(10)}
+
+	call := func(name string, args map[string]any, result *mcp.CallToolResult, err error) {
+		t.Helper()
+		handler := auditMiddleware(cfg)(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return result, err
+		})
+		ctx := sdk.ContextWithRequestMetadata(context.Background(), sdk.RequestMetadata{RequestID: "req-1", SessionID: "session-1"})
+		req := mcp.CallToolRequest{}
+		req.Params.Name = name
+		req.Params.Arguments = args
+		gotResult, gotErr := handler(ctx, req)
+		assert.Equal(t, result, gotResult)
+		assert.Equal(t, err, gotErr)
+	}
+
+	call("create_order", map[string]any{"pair": "XBTZAR", "note": "key audit-secret-value"}, mcp.NewToolResultText("ok"), nil)
+	call("cancel_order", map[string]any{"order_id": "BXMC2CJ7HNB88U4"}, mcp.NewToolResultError("Failed to cancel order: not found"), nil)
+	call("get_balances", nil, nil, errors.New("connection reset"))
+	call("list_orders", map[string]any{"pair": strings.Repeat("x", 2000)}, mcp.NewToolResultText("ok"), nil)
+
+	entries, _ := cfg.Audit.Recent("", 0, 10)
+	require.Len(t, entries, 4)
+	listOrders, getBalances, cancelOrder, createOrder := entries[0], entries[1], entries[2], entries[3]
+
+	assert.Equal(t, "create_order", createOrder.Tool)
+	assert.Equal(t, `{"note":"key [REDACTED]","pair":"XBTZAR"}`, createOrder.Arguments)
+	assert.Equal(t, "req-1", createOrder.RequestID)
+	assert.Equal(t, "session-1", createOrder.SessionID)
+	assert.Empty(t, createOrder.Error)
+
+	assert.Equal(t, "Failed to cancel order: not found", cancelOrder.Error)
+
+	assert.Empty(t, getBalances.Arguments)
+	assert.Equal(t, "connection reset", getBalances.Error)
+
+	assert.Len(t, listOrders.Arguments, maxAuditedBytes+len("…"))
+}
+
+func TestAuditMiddlewareDisabled(t *testing.T) {
+	handler := auditMiddleware(&config.Config{})(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	})
+	result, err := handler(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+}
+
+func TestAuditMiddlewareTenant(t *testing.T) {
+	cfg := &config.Config{Audit: audit.NewLog(10), Sessions: config.NewSessionOverlays()}
+	cfg.Sessions.Set("session-1", config.Overlay{LunoClient: luno.NewClient(), IsAuthenticated: true, Tenant: "acme"})
+	handler := auditMiddleware(cfg)(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	})
+	srv := mcpserver.NewMCPServer(testServerName, testVersion1)
+	ctx := srv.WithContext(context.Background(), mcpserver.NewInProcessSession("session-1", nil))
+	_, err := handler(ctx, mcp.CallToolRequest{})
+	require.NoError(t, err)
+
+	entries, _ := cfg.Audit.Recent("acme", 0, 10)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "acme", entries[0].Tenant)
+	entries, _ = cfg.Audit.Recent("", 0, 10)
+	assert.Empty(t, entries)
+}
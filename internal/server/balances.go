@@ -0,0 +1,58 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/luno/luno-mcp/internal/balances"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/tools"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+type balanceChangesKey struct{}
+
+// contextWithBalanceChanges returns a copy of ctx that balanceSnapshotMiddleware
+// records the balance changes of the call to, for auditMiddleware
+func contextWithBalanceChanges(ctx context.Context) (context.Context, *[]balances.Change) {
+	changes := new([]balances.Change)
+	return context.WithValue(ctx, balanceChangesKey{}, changes), changes
+}
+
+// balanceSnapshotMiddleware takes the account balances just before and after
+// each call to a trading tool, adding what changed to the result and to the
+// audit log. Calls go ahead without a snapshot if the balances can't be
+// fetched, since the snapshot is only there to show what the call did.
+func balanceSnapshotMiddleware(cfg *config.Config) mcpserver.ToolHandlerMiddleware {
+	return func(next mcpserver.ToolHandlerFunc) mcpserver.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			cfg := cfg.ForContext(ctx)
+			if !tools.IsTrading(request.Params.Name) || !cfg.IsAuthenticated || !cfg.AllowWriteOperations {
+				return next(ctx, request)
+			}
+
+			before, err := balances.Snapshot(ctx, cfg.LunoClient)
+			if err != nil {
+				slog.WarnContext(ctx, "Failed to take balances before tool call", slog.String("tool", request.Params.Name), slog.Any("error", err))
+				return next(ctx, request)
+			}
+			result, err := next(ctx, request)
+			if err != nil || result == nil || result.IsError {
+				return result, err
+			}
+			after, snapshotErr := balances.Snapshot(ctx, cfg.LunoClient)
+			if snapshotErr != nil {
+				slog.WarnContext(ctx, "Failed to take balances after tool call", slog.String("tool", request.Params.Name), slog.Any("error", snapshotErr))
+				return result, nil
+			}
+
+			changes := balances.Diff(before, after)
+			if recorded, ok := ctx.Value(balanceChangesKey{}).(*[]balances.Change); ok {
+				*recorded = changes
+			}
+			result.Content = append(result.Content, mcp.NewTextContent(balances.Summary(changes)))
+			return result, nil
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/internal/audit"
+	"github.com/luno/luno-mcp/internal/balances"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBalanceSnapshotMiddleware(t *testing.T) {
+	zar := func(balance, reserved int64) *luno.GetBalancesResponse {
+		return &luno.GetBalancesResponse{Balance: []luno.AccountBalance{
+			{AccountId: "1", Asset: "ZAR", Balance: decimal.NewFromInt64(balance), Reserved: decimal.NewFromInt64(reserved)},
+		}}
+	}
+	client := sdk.NewMockLunoClient(t)
+	cfg := &config.Config{LunoClient: client, IsAuthenticated: true, AllowWriteOperations: true, Audit: audit.NewLog(10)}
+
+	var result *mcp.CallToolResult
+	handler := auditMiddleware(cfg)(balanceSnapshotMiddleware(cfg)(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return result, nil
+	}))
+	call := func(name string) *mcp.CallToolResult {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Name = name
+		got, err := handler(context.Background(), req)
+		require.NoError(t, err)
+		return got
+	}
+
+	client.EXPECT().GetBalances(mock.Anything, &luno.GetBalancesRequest{}).Return(zar(5000, 0), nil).Once()
+	client.EXPECT().GetBalances(mock.Anything, &luno.GetBalancesRequest{}).Return(zar(5000, 1000), nil).Once()
+	result = mcp.NewToolResultText(`{"order_id":"BXMC2CJ7HNB88U4"}`)
+	placed := call("create_order")
+	require.Len(t, placed.Content, 2)
+	assert.Equal(t, "Balance changes: ZAR 0 (reserved +1000, balance now 5000).", placed.Content[1].(mcp.TextContent).Text)
+
+	entries, _ := cfg.Audit.Recent("", 0, 1)
+	assert.Equal(t, []balances.Change{{AccountID: "1", Asset: "ZAR", Change: "0", ReservedChange: "+1000", Balance: "5000"}}, entries[0].BalanceChanges)
+
+	client.EXPECT().GetBalances(mock.Anything, &luno.GetBalancesRequest{}).Return(zar(5000, 1000), nil).Once()
+	result = mcp.NewToolResultError("Failed to create order: insufficient balance")
+	assert.Len(t, call("create_order").Content, 1, "failed calls have no snapshot after them")
+
+	client.EXPECT().GetBalances(mock.Anything, &luno.GetBalancesRequest{}).Return(nil, errors.New("luno unavailable")).Once()
+	result = mcp.NewToolResultText(`{"order_id":"BXMC2CJ7HNB88U5"}`)
+	assert.Len(t, call("create_order").Content, 1, "the call goes ahead without a snapshot")
+
+	result = mcp.NewToolResultText(`{"balance":[]}`)
+	assert.Len(t, call("get_balances").Content, 1, "only trading tools are snapshotted")
+}
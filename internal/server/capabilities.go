@@ -0,0 +1,197 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"maps"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/tools"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// noCredentialsNotice is added to the description of tools that need API
+	// credentials in sessions without them
+	noCredentialsNotice = " (currently unavailable: no API credentials)"
+
+	// Tool descriptions use this pair and its counter currency as examples
+	defaultExamplePair    = "XBTZAR"
+	defaultExampleCounter = "ZAR"
+
+	// examplePairTTL is how long a session's example pair is kept
+	examplePairTTL = time.Hour
+)
+
+// exampleCounterPattern matches the example counter currency on its own,
+// leaving it alone inside other words and identifiers such as ZAR_EFT
+var exampleCounterPattern = regexp.MustCompile(`\b` + defaultExampleCounter + `\b`)
+
+// examplePair is a market to use in tool descriptions
+type examplePair struct {
+	pair    string
+	counter string
+	at      time.Time
+}
+
+// capabilityFilter tailors the tool list to what each session can do. Tools
+// that need API credentials are marked unavailable in sessions without them,
+// or left out with cfg.HideUnavailableTools, and the pair examples in
+// descriptions are changed to a market in one of the account's currencies.
+type capabilityFilter struct {
+	cfg *config.Config
+
+	mu       sync.Mutex
+	examples map[string]examplePair
+}
+
+func newCapabilityFilter(cfg *config.Config) *capabilityFilter {
+	return &capabilityFilter{cfg: cfg, examples: make(map[string]examplePair)}
+}
+
+// filter is a mcpserver.ToolFilterFunc
+func (f *capabilityFilter) filter(ctx context.Context, list []mcp.Tool) []mcp.Tool {
+	ctx = withSessionOverlay(ctx, f.cfg)
+	cfg := f.cfg.ForContext(ctx)
+	example := f.examplePair(ctx, cfg)
+
+	out := make([]mcp.Tool, 0, len(list))
+	for _, t := range list {
+		if !cfg.IsAuthenticated && tools.NeedsCredentials(t.Name) {
+			if cfg.HideUnavailableTools {
+				continue
+			}
+			t.Description += cfg.Locale.Translate(noCredentialsNotice)
+		}
+		if example.pair != defaultExamplePair {
+			t = withExamplePair(t, example)
+		}
+		out = append(out, t)
+	}
+	return out
+}
+
+// examplePair returns the market to use as an example in the session's tool
+// descriptions: the default when the account holds its counter currency or
+// can't be looked up, otherwise a market in a currency the account holds,
+// preferably against XBT.
+func (f *capabilityFilter) examplePair(ctx context.Context, cfg *config.Config) examplePair {
+	def := examplePair{pair: defaultExamplePair, counter: defaultExampleCounter}
+	if !cfg.IsAuthenticated {
+		return def
+	}
+
+	key := ""
+	if session := mcpserver.ClientSessionFromContext(ctx); session != nil {
+		key = session.SessionID()
+	}
+	now := time.Now()
+	f.mu.Lock()
+	maps.DeleteFunc(f.examples, func(_ string, e examplePair) bool { return now.Sub(e.at) > examplePairTTL })
+	e, ok := f.examples[key]
+	f.mu.Unlock()
+	if ok {
+		return e
+	}
+
+	balances, err := cfg.LunoClient.GetBalances(ctx, &luno.GetBalancesRequest{})
+	if err != nil {
+		return def
+	}
+	list, err := tools.MarketCache(cfg).Markets(ctx)
+	if err != nil {
+		return def
+	}
+	held := make(map[string]bool)
+	for _, b := range balances.Balance {
+		held[b.Asset] = true
+	}
+
+	e = def
+	if !held[defaultExampleCounter] {
+		e = chooseExamplePair(list, held, def)
+	}
+	e.at = now
+	f.mu.Lock()
+	f.examples[key] = e
+	f.mu.Unlock()
+	return e
+}
+
+// forget drops the example pair chosen for a session, which may hold other
+// currencies once its credentials change
+func (f *capabilityFilter) forget(sessionID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.examples, sessionID)
+}
+
+// notifyToolsChanged tells the client of a session to list its tools again.
+// Sessions that have ended or not finished initializing are skipped: they
+// list their tools when they next connect.
+func notifyToolsChanged(server *mcpserver.MCPServer, sessionID string) {
+	err := server.SendNotificationToSpecificClient(sessionID, mcp.MethodNotificationToolsListChanged, nil)
+	if err != nil && !errors.Is(err, mcpserver.ErrSessionNotFound) && !errors.Is(err, mcpserver.ErrSessionNotInitialized) {
+		slog.Warn("Failed to send tool list change", slog.String("session_id", sessionID), slog.Any("error", err))
+	}
+}
+
+// chooseExamplePair returns the first market against XBT in a currency in
+// held, failing that the first market in two currencies in held, or def
+func chooseExamplePair(list []luno.MarketInfo, held map[string]bool, def examplePair) examplePair {
+	var fallback *luno.MarketInfo
+	for i, m := range list {
+		if m.TradingStatus == luno.TradingStatusSuspended || !held[m.CounterCurrency] {
+			continue
+		}
+		if m.BaseCurrency == "XBT" {
+			return examplePair{pair: m.MarketId, counter: m.CounterCurrency}
+		}
+		if fallback == nil && held[m.BaseCurrency] {
+			fallback = &list[i]
+		}
+	}
+	if fallback != nil {
+		return examplePair{pair: fallback.MarketId, counter: fallback.CounterCurrency}
+	}
+	return def
+}
+
+// withExamplePair returns a copy of t with the default example pair and
+// counter currency in its descriptions replaced by e's
+func withExamplePair(t mcp.Tool, e examplePair) mcp.Tool {
+	return withDescriptions(t, func(s string) string {
+		s = strings.ReplaceAll(s, defaultExamplePair, e.pair)
+		return exampleCounterPattern.ReplaceAllLiteralString(s, e.counter)
+	})
+}
+
+// withDescriptions returns a copy of t with replace applied to its description
+// and those of its parameters. The input schema of registered tools is shared,
+// so it is copied rather than changed.
+func withDescriptions(t mcp.Tool, replace func(string) string) mcp.Tool {
+	t.Description = replace(t.Description)
+	if len(t.InputSchema.Properties) == 0 {
+		return t
+	}
+	props := make(map[string]any, len(t.InputSchema.Properties))
+	for name, p := range t.InputSchema.Properties {
+		if prop, ok := p.(map[string]any); ok {
+			if desc, ok := prop["description"].(string); ok {
+				prop = maps.Clone(prop)
+				prop["description"] = replace(desc)
+			}
+			p = prop
+		}
+		props[name] = p
+	}
+	t.InputSchema.Properties = props
+	return t
+}
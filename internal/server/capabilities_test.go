@@ -0,0 +1,166 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/markets"
+	"github.com/luno/luno-mcp/internal/tools"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// listedTool is a tool as returned by tools/list
+type listedTool struct {
+	Description string `json:"description"`
+	InputSchema struct {
+		Properties map[string]struct {
+			Description string `json:"description"`
+		} `json:"properties"`
+	} `json:"inputSchema"`
+}
+
+// listTools lists the tools through the MCP server's HandleMessage entry point
+func listTools(t *testing.T, srv *mcpserver.MCPServer) map[string]listedTool {
+	t.Helper()
+
+	msg := `{"jsonrpc":"2.0","id":1,"method":"tools/list","params":{}}`
+	b, err := json.Marshal(srv.HandleMessage(context.Background(), json.RawMessage(msg)))
+	require.NoError(t, err)
+
+	var parsed struct {
+		Result struct {
+			Tools []struct {
+				Name string `json:"name"`
+				listedTool
+			} `json:"tools"`
+		} `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal(b, &parsed))
+	out := make(map[string]listedTool)
+	for _, tool := range parsed.Result.Tools {
+		out[tool.Name] = tool.listedTool
+	}
+	return out
+}
+
+func TestCapabilityFilterWithoutCredentials(t *testing.T) {
+	cfg := &config.Config{LunoClient: luno.NewClient(), AllowWriteOperations: true, AllowWithdrawals: true}
+	srv := NewMCPServer(testServerName, testVersion1, cfg)
+
+	listed := listTools(t, srv)
+	require.Len(t, listed, len(srv.ListTools()))
+	for name, tool := range listed {
+		if !tools.NeedsCredentials(name) {
+			assert.NotContains(t, tool.Description, noCredentialsNotice, name)
+			continue
+		}
+		assert.True(t, strings.HasSuffix(tool.Description, noCredentialsNotice), name)
+		// Tools running in the background are only available once their runner is set up
+		if resp := callTool(t, srv, name); !strings.Contains(resp, "not available on this server") {
+			assert.Contains(t, resp, tools.ErrAPICredentialsRequired, "%s needs credentials", name)
+		}
+	}
+	assert.Equal(t, "Trading pair (e.g., XBTZAR)", listed[tools.GetTickerToolID].InputSchema.Properties["pair"].Description)
+
+	cfg.HideUnavailableTools = true
+	hidden := listTools(t, srv)
+	assert.NotContains(t, hidden, tools.GetBalancesToolID)
+	assert.Contains(t, hidden, tools.GetTickerToolID)
+	for name := range hidden {
+		assert.False(t, tools.NeedsCredentials(name), name)
+	}
+}
+
+func TestCapabilityFilterExamplePair(t *testing.T) {
+	listedMarkets := &luno.MarketsResponse{Markets: []luno.MarketInfo{
+		{MarketId: "ETHNGN", BaseCurrency: "ETH", CounterCurrency: "NGN"},
+		{MarketId: "XBTNGN", BaseCurrency: "XBT", CounterCurrency: "NGN"},
+		{MarketId: "XBTZAR", BaseCurrency: "XBT", CounterCurrency: "ZAR"},
+	}}
+
+	tests := []struct {
+		name       string
+		assets     []string
+		expPair    string
+		expCounter string
+	}{
+		{name: "account in the example currency", assets: []string{"XBT", "ZAR"}, expPair: "XBTZAR", expCounter: "ZAR"},
+		{name: "account in another currency", assets: []string{"XBT", "NGN"}, expPair: "XBTNGN", expCounter: "NGN"},
+		{name: "no market in the account's currencies", assets: []string{"XBT", "EUR"}, expPair: "XBTZAR", expCounter: "ZAR"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client := sdk.NewMockLunoClient(t)
+			balances := &luno.GetBalancesResponse{}
+			for _, a := range tc.assets {
+				balances.Balance = append(balances.Balance, luno.AccountBalance{Asset: a})
+			}
+			client.EXPECT().GetBalances(mock.Anything, &luno.GetBalancesRequest{}).Return(balances, nil).Once()
+			client.EXPECT().Markets(mock.Anything, &luno.MarketsRequest{}).Return(listedMarkets, nil).Once()
+			cfg := &config.Config{LunoClient: client, IsAuthenticated: true, Markets: markets.NewCache(client)}
+			srv := NewMCPServer(testServerName, testVersion1, cfg)
+
+			listed := listTools(t, srv)
+			assert.Equal(t, "Trading pair (e.g., "+tc.expPair+")", listed[tools.GetTickerToolID].InputSchema.Properties["pair"].Description)
+			assert.Contains(t, listed[tools.CreateMarketOrderToolID].InputSchema.Properties["counter_volume"].Description,
+				"e.g. "+tc.expCounter+" for "+tc.expPair)
+			assert.NotContains(t, listed[tools.GetBalancesToolID].Description, noCredentialsNotice)
+
+			// The example is kept for the session and the registered tools are unchanged
+			listTools(t, srv)
+			pair := srv.ListTools()[tools.GetTickerToolID].Tool.InputSchema.Properties["pair"].(map[string]any)
+			assert.Equal(t, "Trading pair (e.g., XBTZAR)", pair["description"])
+		})
+	}
+}
+
+// notifySession is an initialized client session that keeps its notifications
+type notifySession struct {
+	id            string
+	notifications chan mcp.JSONRPCNotification
+}
+
+func (s *notifySession) SessionID() string { return s.id }
+func (s *notifySession) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return s.notifications
+}
+func (s *notifySession) Initialize()       {}
+func (s *notifySession) Initialized() bool { return true }
+
+func TestToolListChangedOnOverlayChange(t *testing.T) {
+	cfg := &config.Config{LunoClient: luno.NewClient(), Sessions: config.NewSessionOverlays()}
+	srv := NewMCPServer(testServerName, testVersion1, cfg)
+	session := &notifySession{id: "session-1", notifications: make(chan mcp.JSONRPCNotification, 10)}
+	require.NoError(t, srv.RegisterSession(context.Background(), session))
+
+	cfg.Sessions.Set("session-1", config.Overlay{LunoClient: luno.NewClient(), IsAuthenticated: true})
+	cfg.Sessions.Delete("session-1")
+	// Other sessions, and sessions that have ended, are not notified
+	cfg.Sessions.Set("session-2", config.Overlay{IsAuthenticated: true})
+
+	require.Len(t, session.notifications, 2)
+	for range 2 {
+		assert.Equal(t, mcp.MethodNotificationToolsListChanged, (<-session.notifications).Method)
+	}
+}
+
+func TestCapabilityFilterForget(t *testing.T) {
+	f := newCapabilityFilter(&config.Config{})
+	f.examples["session-1"] = examplePair{pair: "XBTNGN", counter: "NGN", at: time.Now()}
+	f.examples["session-2"] = examplePair{pair: "XBTNGN", counter: "NGN", at: time.Now()}
+
+	f.forget("session-1")
+	assert.NotContains(t, f.examples, "session-1", "the next listing looks the account up again")
+	assert.Contains(t, f.examples, "session-2")
+}
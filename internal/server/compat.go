@@ -0,0 +1,25 @@
+package server
+
+import (
+	"context"
+
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// apiNoticesMiddleware warns in the result of a tool call when a Luno API call
+// it made hit a deprecated endpoint or a response that no longer decodes
+func apiNoticesMiddleware(next mcpserver.ToolHandlerFunc) mcpserver.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx, notices := sdk.ContextWithAPINoticeCollector(ctx)
+		result, err := next(ctx, request)
+		if err != nil || result == nil {
+			return result, err
+		}
+		for _, n := range notices.Notices() {
+			result.Content = append(result.Content, mcp.NewTextContent("Warning: "+n.Message()))
+		}
+		return result, nil
+	}
+}
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPINoticesMiddleware(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/1/ticker" {
+			w.Header().Set("Sunset", "Wed, 01 Jul 2026 00:00:00 GMT")
+		}
+	}))
+	defer ts.Close()
+	hc := &http.Client{Transport: &sdk.MCPRoundTripper{Compat: sdk.NewAPICompat()}}
+
+	handler := apiNoticesMiddleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+request.Params.Name, nil)
+		require.NoError(t, err)
+		res, err := hc.Do(req)
+		require.NoError(t, err)
+		_ = res.Body.Close()
+		return mcp.NewToolResultText("ticker"), nil
+	})
+	call := func(path string) *mcp.CallToolResult {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Name = path
+		result, err := handler(context.Background(), req)
+		require.NoError(t, err)
+		return result
+	}
+
+	result := call("/api/1/ticker")
+	require.Len(t, result.Content, 2)
+	assert.Equal(t, "Warning: Luno reports GET /api/1/ticker as deprecated, to be removed Wed, 01 Jul 2026 00:00:00 GMT; "+
+		"results may change and luno-mcp may need upgrading.", result.Content[1].(mcp.TextContent).Text)
+
+	assert.Len(t, call("/api/1/tickers").Content, 1, "no warning without notices")
+}
@@ -0,0 +1,42 @@
+package server
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/resources"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// maxCompletions is the most values MCP allows in a completion result
+const maxCompletions = 100
+
+// marketCompletionProvider completes currency pair arguments of resource
+// templates, e.g. luno://markets/{pair}, from the market cache, and candle
+// durations from the durations Luno supports
+type marketCompletionProvider struct {
+	cfg *config.Config
+}
+
+// CompleteResourceArgument implements mcpserver.ResourceCompletionProvider
+func (p marketCompletionProvider) CompleteResourceArgument(ctx context.Context, uri string, argument mcp.CompleteArgument, _ mcp.CompleteContext) (*mcp.Completion, error) {
+	values := []string{}
+	if argument.Name == "pair" && p.cfg != nil && p.cfg.Markets != nil {
+		values = append(values, p.cfg.Markets.Complete(argument.Value)...)
+	}
+	if argument.Name == "duration" {
+		for _, d := range resources.CandleDurations {
+			if s := strconv.FormatInt(d, 10); strings.HasPrefix(s, argument.Value) {
+				values = append(values, s)
+			}
+		}
+	}
+
+	total := len(values)
+	if total > maxCompletions {
+		values = values[:maxCompletions]
+	}
+	return &mcp.Completion{Values: values, Total: total, HasMore: total > len(values)}, nil
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/markets"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarketCompletionProvider(t *testing.T) {
+	client := sdk.NewMockLunoClient(t)
+	list := []luno.MarketInfo{{MarketId: "ETHZAR"}, {MarketId: "XBTEUR"}, {MarketId: "XBTZAR"}}
+	for i := range 120 {
+		list = append(list, luno.MarketInfo{MarketId: fmt.Sprintf("TOK%03dZAR", i)})
+	}
+	client.EXPECT().Markets(context.Background(), &luno.MarketsRequest{}).Return(&luno.MarketsResponse{Markets: list}, nil)
+	cache := markets.NewCache(client)
+	require.NoError(t, cache.Refresh(context.Background()))
+
+	tests := []struct {
+		name            string
+		cfg             *config.Config
+		argument        mcp.CompleteArgument
+		expectedValues  []string
+		expectedTotal   int
+		expectedHasMore bool
+	}{
+		{
+			name:           "pair prefix",
+			cfg:            &config.Config{Markets: cache},
+			argument:       mcp.CompleteArgument{Name: "pair", Value: "xbt"},
+			expectedValues: []string{"XBTEUR", "XBTZAR"},
+			expectedTotal:  2,
+		},
+		{
+			name:           "candle duration prefix",
+			cfg:            &config.Config{},
+			argument:       mcp.CompleteArgument{Name: "duration", Value: "3"},
+			expectedValues: []string{"300", "3600"},
+			expectedTotal:  2,
+		},
+		{
+			name:           "other argument",
+			cfg:            &config.Config{Markets: cache},
+			argument:       mcp.CompleteArgument{Name: "id", Value: "xbt"},
+			expectedValues: []string{},
+		},
+		{
+			name:           "no market cache",
+			cfg:            &config.Config{},
+			argument:       mcp.CompleteArgument{Name: "pair", Value: "xbt"},
+			expectedValues: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := marketCompletionProvider{cfg: tt.cfg}
+			completion, err := p.CompleteResourceArgument(context.Background(), "luno://markets/{pair}", tt.argument, mcp.CompleteContext{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedValues, completion.Values)
+			assert.Equal(t, tt.expectedTotal, completion.Total)
+			assert.Equal(t, tt.expectedHasMore, completion.HasMore)
+		})
+	}
+
+	t.Run("results are capped", func(t *testing.T) {
+		p := marketCompletionProvider{cfg: &config.Config{Markets: cache}}
+		completion, err := p.CompleteResourceArgument(context.Background(), "luno://markets/{pair}", mcp.CompleteArgument{Name: "pair", Value: "TOK"}, mcp.CompleteContext{})
+		require.NoError(t, err)
+		assert.Len(t, completion.Values, maxCompletions)
+		assert.Equal(t, 120, completion.Total)
+		assert.True(t, completion.HasMore)
+	})
+}
@@ -0,0 +1,172 @@
+package server
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/config"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcceptsGzip(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected bool
+	}{
+		{header: "", expected: false},
+		{header: "identity", expected: false},
+		{header: "gzip", expected: true},
+		{header: "deflate, GZIP", expected: true},
+		{header: "br;q=1.0, gzip;q=0.5", expected: true},
+		{header: "gzip;q=0", expected: false},
+		{header: "*", expected: true},
+		{header: "*;q=0", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			assert.Equal(t, tt.expected, acceptsGzip(tt.header))
+		})
+	}
+}
+
+func TestCompressHandler(t *testing.T) {
+	body := strings.Repeat(`{"pair":"XBTZAR","price":"1000000"}`, 100)
+
+	tests := []struct {
+		name             string
+		acceptEncoding   string
+		handler          http.HandlerFunc
+		expectedStatus   int
+		expectedEncoding string
+		expectedBody     string
+	}{
+		{
+			name:           "identity when gzip is not accepted",
+			acceptEncoding: "",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = io.WriteString(w, body)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   body,
+		},
+		{
+			name:           "gzip when accepted",
+			acceptEncoding: "gzip",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+				_, _ = io.WriteString(w, body)
+			},
+			expectedStatus:   http.StatusOK,
+			expectedEncoding: "gzip",
+			expectedBody:     body,
+		},
+		{
+			name:           "no body is left uncompressed",
+			acceptEncoding: "gzip",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusAccepted)
+			},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:           "already encoded bodies are passed through",
+			acceptEncoding: "gzip",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Encoding", "br")
+				_, _ = io.WriteString(w, "raw")
+			},
+			expectedStatus:   http.StatusOK,
+			expectedEncoding: "br",
+			expectedBody:     "raw",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+			if tt.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			rec := httptest.NewRecorder()
+			compressHandler(tt.handler).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Equal(t, tt.expectedEncoding, rec.Header().Get("Content-Encoding"))
+			assert.Equal(t, "Accept-Encoding", rec.Header().Get("Vary"))
+
+			got := rec.Body.String()
+			if tt.expectedEncoding == "gzip" {
+				assert.Less(t, rec.Body.Len(), len(body))
+				got = gunzip(t, rec.Body)
+			}
+			assert.Equal(t, tt.expectedBody, got)
+		})
+	}
+}
+
+func TestCompressHandlerStreamsFlushes(t *testing.T) {
+	release := make(chan struct{})
+	ts := httptest.NewServer(compressHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, "event: message\ndata: first\n\n")
+		w.(http.Flusher).Flush()
+		<-release
+		_, _ = io.WriteString(w, "event: message\ndata: second\n\n")
+	})))
+	defer ts.Close()
+	defer close(release)
+
+	req, err := http.NewRequest(http.MethodGet, ts.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, "gzip", resp.Header.Get("Content-Encoding"))
+	assert.Equal(t, []string{"chunked"}, resp.TransferEncoding)
+	zr, err := gzip.NewReader(resp.Body)
+	require.NoError(t, err)
+	line, err := bufio.NewReader(zr).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "event: message\n", line, "flushed events arrive before the handler returns")
+}
+
+func TestStreamableHTTPCompression(t *testing.T) {
+	cfg := &config.Config{LunoClient: luno.NewClient()}
+	httpServer := mcpserver.NewStreamableHTTPServer(NewMCPServer("test-server", "1.0.0", cfg))
+	ts := httptest.NewServer(compressHandler(httpServer))
+	defer ts.Close()
+
+	initialize := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1.0.0"}}}`
+	req, err := http.NewRequest(http.MethodPost, ts.URL+streamableHTTPPath, strings.NewReader(initialize))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "gzip", resp.Header.Get("Content-Encoding"))
+	assert.Contains(t, gunzip(t, resp.Body), `"name":"test-server"`)
+}
+
+func gunzip(t *testing.T, r io.Reader) string {
+	t.Helper()
+	zr, err := gzip.NewReader(r)
+	require.NoError(t, err)
+	b, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	return string(b)
+}
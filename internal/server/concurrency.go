@@ -0,0 +1,36 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/limiter"
+	"github.com/luno/luno-mcp/internal/telemetry"
+	"github.com/luno/luno-mcp/internal/usage"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// concurrencyMiddleware queues tool calls over the configured concurrency
+// limits, failing them if no slot frees up within the queue timeout
+func concurrencyMiddleware(cfg *config.Config) mcpserver.ToolHandlerMiddleware {
+	return func(next mcpserver.ToolHandlerFunc) mcpserver.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			release, err := cfg.Limiter.Acquire(ctx, request.Params.Name)
+			if errors.Is(err, limiter.ErrQueueTimeout) {
+				usage.SessionFromContext(ctx).CallRejected()
+				telemetry.CallFromContext(ctx).Rejected()
+				slog.WarnContext(ctx, "Tool call rejected, too many calls in progress", slog.String("tool", request.Params.Name))
+				return mcp.NewToolResultError(fmt.Sprintf("Too many tool calls in progress: no slot freed up within %s. Wait for running calls to finish and try again.", cfg.Limiter.QueueTimeout())), nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			defer release()
+			return next(ctx, request)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/limiter"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcurrencyMiddleware(t *testing.T) {
+	cfg := &config.Config{Limiter: limiter.New(0, 1, 10*time.Millisecond)}
+
+	started := make(chan struct{})
+	finish := make(chan struct{})
+	handler := concurrencyMiddleware(cfg)(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		started <- struct{}{}
+		<-finish
+		return mcp.NewToolResultText("done"), nil
+	})
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "get_ticker"
+	done := make(chan *mcp.CallToolResult)
+	go func() {
+		result, _ := handler(context.Background(), request)
+		done <- result
+	}()
+	<-started
+
+	result, err := handler(context.Background(), request)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	require.Len(t, result.Content, 1)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "Too many tool calls in progress: no slot freed up within 10ms")
+
+	close(finish)
+	assert.False(t, (<-done).IsError)
+
+	go func() { <-started }()
+	result, err = handler(context.Background(), request)
+	require.NoError(t, err)
+	assert.False(t, result.IsError, "the slot is released when the call returns")
+}
+
+func TestConcurrencyMiddlewareCancelled(t *testing.T) {
+	cfg := &config.Config{Limiter: limiter.New(1, 0, 0)}
+	release, err := cfg.Limiter.Acquire(context.Background(), "get_ticker")
+	require.NoError(t, err)
+	defer release()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	handler := concurrencyMiddleware(cfg)(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		t.Fatal("handler called over the limit")
+		return nil, nil
+	})
+	_, err = handler(ctx, mcp.CallToolRequest{})
+	assert.ErrorIs(t, err, context.Canceled)
+}
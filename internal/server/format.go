@@ -0,0 +1,89 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/render"
+	"github.com/luno/luno-mcp/internal/tools"
+	"github.com/luno/luno-mcp/internal/validate"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// outputFormatParam is the argument every tool takes to choose the format of
+// its result
+const outputFormatParam = "output_format"
+
+// outputFormatToolFilter adds the output_format parameter to the listed tools.
+// fetch_more returns the rest of a result in the format it was started in, so
+// it does not take one.
+func outputFormatToolFilter(ctx context.Context, list []mcp.Tool) []mcp.Tool {
+	enum := make([]string, 0, len(render.Formats()))
+	for _, f := range render.Formats() {
+		enum = append(enum, string(f))
+	}
+	out := make([]mcp.Tool, len(list))
+	for i, t := range list {
+		if t.Name != tools.FetchMoreToolID {
+			props := maps.Clone(t.InputSchema.Properties)
+			if props == nil {
+				props = make(map[string]any, 1)
+			}
+			props[outputFormatParam] = map[string]any{
+				"type":        "string",
+				"description": "Format of the result: " + render.Help,
+				"enum":        enum,
+			}
+			t.InputSchema.Properties = props
+		}
+		out[i] = t
+	}
+	return out
+}
+
+// outputFormatMiddleware writes successful tool results in the format the call
+// asks for with output_format, or else the configured one. Tools build their
+// results as JSON; results that are not, such as plain messages, and error
+// results are left as they are.
+func outputFormatMiddleware(cfg *config.Config) mcpserver.ToolHandlerMiddleware {
+	return func(next mcpserver.ToolHandlerFunc) mcpserver.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if request.Params.Name == tools.FetchMoreToolID {
+				// The parts of a result are already in its format
+				return next(ctx, request)
+			}
+			format := cfg.OutputFormat
+			if s, ok := request.GetArguments()[outputFormatParam].(string); ok && s != "" {
+				f, err := render.Parse(s)
+				if err != nil {
+					// Checked before the call, which may place an order
+					return validate.Errors{{Param: outputFormatParam, Message: err.Error()}}.Result(), nil
+				}
+				format = f
+			}
+
+			result, err := next(ctx, request)
+			if err != nil || result == nil || result.IsError || format == "" || format == render.JSON {
+				return result, err
+			}
+			for i, c := range result.Content {
+				text, ok := c.(mcp.TextContent)
+				if !ok {
+					continue
+				}
+				out, ok, err := render.Render(format, text.Text)
+				if err != nil {
+					return mcp.NewToolResultError(fmt.Sprintf("Failed to write result as %s: %v", format, err)), nil
+				}
+				if ok {
+					text.Text = out
+					result.Content[i] = text
+				}
+			}
+			return result, nil
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/render"
+	"github.com/luno/luno-mcp/internal/tools"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputFormatMiddleware(t *testing.T) {
+	const doc = `{"pair": "XBTZAR", "bid": "800000.00"}`
+
+	tests := []struct {
+		name       string
+		configured render.Format
+		tool       string
+		args       map[string]any
+		result     *mcp.CallToolResult
+		expected   string
+		isError    bool
+		notCalled  bool
+	}{
+		{name: "json by default", tool: tools.GetTickerToolID, result: mcp.NewToolResultText(doc), expected: doc},
+		{name: "argument", tool: tools.GetTickerToolID, args: map[string]any{"output_format": "YAML"}, result: mcp.NewToolResultText(doc), expected: "pair: XBTZAR\nbid: \"800000.00\""},
+		{name: "configured", configured: render.CSV, tool: tools.GetTickerToolID, result: mcp.NewToolResultText(doc), expected: "pair,bid\nXBTZAR,800000.00"},
+		{name: "argument overrides configured", configured: render.CSV, tool: tools.GetTickerToolID, args: map[string]any{"output_format": "json"}, result: mcp.NewToolResultText(doc), expected: doc},
+		{name: "plain text", configured: render.YAML, tool: tools.CancelOrderToolID, result: mcp.NewToolResultText("Order cancelled"), expected: "Order cancelled"},
+		{name: "error result", configured: render.YAML, tool: tools.GetTickerToolID, result: mcp.NewToolResultError(`{"error": "boom"}`), expected: `{"error": "boom"}`, isError: true},
+		{name: "fetch_more", configured: render.YAML, tool: tools.FetchMoreToolID, args: map[string]any{"output_format": "xml"}, result: mcp.NewToolResultText(doc), expected: doc},
+		{
+			name:      "unsupported",
+			tool:      tools.CreateOrderToolID,
+			args:      map[string]any{"output_format": "xml"},
+			expected:  `Invalid parameters: output_format must be one of json, yaml, csv, markdown, got "xml"`,
+			isError:   true,
+			notCalled: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			called := false
+			handler := outputFormatMiddleware(&config.Config{OutputFormat: tc.configured})(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				called = true
+				return tc.result, nil
+			})
+			request := mcp.CallToolRequest{}
+			request.Params.Name = tc.tool
+			request.Params.Arguments = tc.args
+
+			result, err := handler(context.Background(), request)
+			require.NoError(t, err)
+			assert.Equal(t, !tc.notCalled, called)
+			assert.Equal(t, tc.isError, result.IsError)
+			assert.Equal(t, tc.expected, resultText(t, result))
+		})
+	}
+}
+
+func TestOutputFormatToolFilter(t *testing.T) {
+	srv := NewMCPServer(testServerName, testVersion1, &config.Config{LunoClient: luno.NewClient()})
+	listed := listTools(t, srv)
+
+	ticker := listed[tools.GetTickerToolID].InputSchema.Properties
+	require.Contains(t, ticker, "output_format")
+	assert.Equal(t, "Format of the result: "+render.Help, ticker["output_format"].Description)
+	assert.Contains(t, ticker, "pair", "the tool's own parameters are kept")
+	assert.NotContains(t, listed[tools.FetchMoreToolID].InputSchema.Properties, "output_format")
+
+	// The registered tools are unchanged
+	assert.NotContains(t, srv.ListTools()[tools.GetTickerToolID].Tool.InputSchema.Properties, "output_format")
+}
@@ -0,0 +1,131 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/luno/luno-mcp/internal/aliases"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/iceberg"
+	"github.com/luno/luno-mcp/internal/journal"
+	"github.com/luno/luno-mcp/internal/reference"
+	"github.com/luno/luno-mcp/internal/reports"
+	"github.com/luno/luno-mcp/internal/testutil"
+	"github.com/luno/luno-mcp/internal/tools"
+	"github.com/luno/luno-mcp/internal/trades"
+	"github.com/luno/luno-mcp/internal/twap"
+	"github.com/luno/luno-mcp/internal/usage"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// handlerTestServer returns a server with every tool enabled, calling client
+func handlerTestServer(t *testing.T, client *testutil.FakeLunoClient, authenticated bool) *mcpserver.MCPServer {
+	dir := t.TempDir()
+	cfg := &config.Config{
+		LunoClient:           client,
+		IsAuthenticated:      authenticated,
+		AllowWriteOperations: true,
+		AllowWithdrawals:     true,
+		TWAP:                 twap.NewManager(),
+		Iceberg:              iceberg.NewManager(),
+		Reports:              reports.NewScheduler(""),
+		Journal:              journal.New(filepath.Join(dir, "journal.json")),
+		Aliases:              aliases.New(filepath.Join(dir, "aliases.json")),
+		Trades:               trades.New(filepath.Join(dir, "trades.json")),
+		Usage:                usage.NewTracker(),
+		Reference: reference.SourceFunc(func(ctx context.Context, pair reference.Pair) (reference.Quote, error) {
+			return reference.Quote{}, errors.New("no reference prices in tests")
+		}),
+	}
+	return NewMCPServer(testServerName, testVersion1, cfg)
+}
+
+// callHandler calls the handler of the tool name directly, without the server's middleware
+func callHandler(t *testing.T, srv *mcpserver.MCPServer, name string, args map[string]any) *mcp.CallToolResult {
+	t.Helper()
+	tool := srv.GetTool(name)
+	require.NotNil(t, tool, "tool %s is not registered", name)
+	req := mcp.CallToolRequest{}
+	req.Params.Name = name
+	req.Params.Arguments = args
+	result, err := tool.Handler(context.Background(), req)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	return result
+}
+
+func resultText(t *testing.T, result *mcp.CallToolResult) string {
+	t.Helper()
+	require.NotEmpty(t, result.Content)
+	text, ok := result.Content[0].(mcp.TextContent)
+	require.True(t, ok, "expected text content, got %T", result.Content[0])
+	return text.Text
+}
+
+// TestToolHandlersWithoutCredentials checks that every tool needing API
+// credentials refuses to run without them, before calling the API
+func TestToolHandlersWithoutCredentials(t *testing.T) {
+	client := testutil.NewFakeLunoClient(t)
+	srv := handlerTestServer(t, client, false)
+
+	for _, name := range slices.Sorted(maps.Keys(srv.ListTools())) {
+		if !tools.NeedsCredentials(name) {
+			continue
+		}
+		t.Run(name, func(t *testing.T) {
+			result := callHandler(t, srv, name, map[string]any{})
+			assert.True(t, result.IsError)
+			assert.Equal(t, tools.ErrAPICredentialsRequired, resultText(t, result))
+		})
+	}
+	client.AssertNoCalls(t)
+}
+
+// TestToolHandlersInvalidArguments checks that every tool with parameters
+// rejects invalid arguments before calling the API
+func TestToolHandlersInvalidArguments(t *testing.T) {
+	client := testutil.NewFakeLunoClient(t)
+	srv := handlerTestServer(t, client, true)
+
+	for _, name := range slices.Sorted(maps.Keys(srv.ListTools())) {
+		args, want, ok := invalidArguments(srv.GetTool(name).Tool)
+		if !ok {
+			continue
+		}
+		t.Run(name, func(t *testing.T) {
+			result := callHandler(t, srv, name, args)
+			assert.True(t, result.IsError)
+			assert.Contains(t, resultText(t, result), want)
+		})
+	}
+	client.AssertNoCalls(t)
+}
+
+// invalidArguments returns arguments that tool's schema rejects and part of
+// the error expected for them. ok is false if tool has no parameters to get
+// wrong.
+func invalidArguments(tool mcp.Tool) (args map[string]any, want string, ok bool) {
+	schema := tool.InputSchema
+	if len(schema.Required) > 0 {
+		return map[string]any{}, schema.Required[0] + " is required", true
+	}
+	for _, name := range slices.Sorted(maps.Keys(schema.Properties)) {
+		prop, _ := schema.Properties[name].(map[string]any)
+		switch {
+		case prop["type"] == "number":
+			return map[string]any{name: "many"}, name + " must be a number", true
+		case prop["type"] == "boolean":
+			return map[string]any{name: "maybe"}, name + " must be true or false", true
+		case prop["enum"] != nil:
+			return map[string]any{name: "nonsense"}, name + " must be one of", true
+		}
+	}
+	return nil, "", false
+}
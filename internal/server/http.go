@@ -0,0 +1,94 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// healthPath answers liveness checks, such as a container's health check
+	healthPath = "/healthz"
+
+	// httpReadHeaderTimeout bounds how long a client has to send its request
+	// headers, so that slow clients can't hold connections open
+	httpReadHeaderTimeout = 10 * time.Second
+
+	// httpIdleTimeout closes keep-alive connections left idle this long
+	httpIdleTimeout = 2 * time.Minute
+
+	// httpShutdownTimeout is how long calls in progress have to finish once
+	// the server is asked to stop, within the 10 seconds Docker waits before
+	// killing a container
+	httpShutdownTimeout = 8 * time.Second
+)
+
+// ServeHTTP starts the server using the Streamable HTTP transport for running
+// in a container. The address is bound before ServeHTTP serves anything, so
+// that a port in use is reported at once, and healthPath answers health checks
+// alongside the MCP endpoint. When ctx is cancelled, calls in progress are
+// given httpShutdownTimeout to finish.
+func ServeHTTP(ctx context.Context, s *mcpserver.MCPServer, addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	return serveListener(ctx, s, ln, httpShutdownTimeout)
+}
+
+// serveListener serves the HTTP transport on ln until ctx is cancelled, then
+// gives calls in progress shutdownTimeout to finish
+func serveListener(ctx context.Context, s *mcpserver.MCPServer, ln net.Listener, shutdownTimeout time.Duration) error {
+	srv := &http.Server{
+		ReadHeaderTimeout: httpReadHeaderTimeout,
+		IdleTimeout:       httpIdleTimeout,
+	}
+	httpServer := mcpserver.NewStreamableHTTPServer(s,
+		mcpserver.WithStreamableHTTPServer(srv),
+		mcpserver.WithEndpointPath(streamableHTTPPath),
+	)
+	mux := http.NewServeMux()
+	mux.Handle(streamableHTTPPath, httpServer)
+	mux.HandleFunc(healthPath, handleHealth)
+	srv.Handler = compressHandler(mux)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.Serve(ln)
+	}()
+	slog.Info("HTTP server listening", slog.String("address", ln.Addr().String()), slog.String("path", streamableHTTPPath))
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	slog.Info("Shutting down HTTP server")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	err := httpServer.Shutdown(shutdownCtx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		slog.Warn("Calls still running at shutdown were stopped", slog.Duration("timeout", shutdownTimeout))
+		return srv.Close()
+	}
+	return err
+}
+
+// handleHealth reports that the server is up. It does not call the Luno API,
+// so that an outage there does not get the container restarted.
+func handleHealth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = w.Write([]byte("ok\n"))
+}
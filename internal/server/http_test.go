@@ -0,0 +1,61 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServeListener(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	baseURL := "http://" + ln.Addr().String()
+
+	// Shutdown waits for the client's connections, so keep it short and
+	// wait for longer
+	const shutdownTimeout = time.Second
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	served := make(chan error, 1)
+	go func() {
+		served <- serveListener(ctx, NewMCPServer(testServerName, testVersion1, &config.Config{LunoClient: luno.NewClient()}), ln, shutdownTimeout)
+	}()
+	client := &http.Client{Transport: &http.Transport{}}
+
+	res, err := client.Get(baseURL + healthPath)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(res.Body)
+	_ = res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "ok\n", string(body))
+
+	res, err = client.Post(baseURL+healthPath, "text/plain", nil)
+	require.NoError(t, err)
+	_ = res.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, res.StatusCode)
+
+	res, err = client.Post(baseURL+streamableHTTPPath, "application/json", strings.NewReader(stdioInitialize))
+	require.NoError(t, err)
+	body, _ = io.ReadAll(res.Body)
+	_ = res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Contains(t, string(body), testServerName)
+
+	client.CloseIdleConnections()
+	cancel()
+	select {
+	case err := <-served:
+		require.NoError(t, err)
+	case <-time.After(shutdownTimeout + 5*time.Second):
+		t.Fatal("the server is still running")
+	}
+}
@@ -0,0 +1,51 @@
+package server
+
+import (
+	"context"
+
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/i18n"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// localeToolFilter translates tool descriptions into the configured locale.
+// It runs before the capability filter, which looks for the English examples.
+func localeToolFilter(cfg *config.Config) mcpserver.ToolFilterFunc {
+	return func(ctx context.Context, list []mcp.Tool) []mcp.Tool {
+		if cfg.Locale == "" || cfg.Locale == i18n.English {
+			return list
+		}
+		out := make([]mcp.Tool, len(list))
+		for i, t := range list {
+			out[i] = withDescriptions(t, cfg.Locale.Translate)
+		}
+		return out
+	}
+}
+
+// localeMiddleware translates tool results into the configured locale: error
+// messages, and the fixed notes and messages in JSON results
+func localeMiddleware(cfg *config.Config) mcpserver.ToolHandlerMiddleware {
+	return func(next mcpserver.ToolHandlerFunc) mcpserver.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			result, err := next(ctx, request)
+			if err != nil || result == nil || cfg.Locale == "" || cfg.Locale == i18n.English {
+				return result, err
+			}
+			for i, c := range result.Content {
+				text, ok := c.(mcp.TextContent)
+				if !ok {
+					continue
+				}
+				if result.IsError {
+					text.Text = cfg.Locale.TranslateMessage(text.Text)
+				} else {
+					text.Text = cfg.Locale.TranslateJSON(text.Text)
+				}
+				result.Content[i] = text
+			}
+			return result, nil
+		}
+	}
+}
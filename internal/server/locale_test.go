@@ -0,0 +1,67 @@
+package server
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/i18n"
+	"github.com/luno/luno-mcp/internal/tools"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocale(t *testing.T) {
+	cfg := &config.Config{LunoClient: luno.NewClient(), Locale: i18n.Malay}
+	srv := NewMCPServer(testServerName, testVersion1, cfg)
+
+	listed := listTools(t, srv)
+	ticker := listed[tools.GetTickerToolID]
+	assert.Equal(t, "Dapatkan maklumat ticker bagi pasangan dagangan", ticker.Description)
+	assert.Equal(t, "Pasangan dagangan (cth., XBTZAR)", ticker.InputSchema.Properties["pair"].Description)
+	assert.Equal(t, "Dapatkan baki semua akaun Luno. Tetapkan convert_to untuk turut menilai setiap baki dalam satu mata wang pada harga dagangan terkini, berserta jumlah keseluruhan. (tidak tersedia buat masa ini: tiada kelayakan API)",
+		listed[tools.GetBalancesToolID].Description)
+	// Text without a translation is shown in English
+	assert.True(t, strings.HasPrefix(listed[tools.BacktestStrategyToolID].Description, "Backtest a built-in trading strategy"))
+
+	// The registered tools are unchanged
+	assert.Equal(t, "Get ticker information for a trading pair", srv.ListTools()[tools.GetTickerToolID].Tool.Description)
+
+	assert.Equal(t, "Operasi ini memerlukan kelayakan API. Sila tetapkan pemboleh ubah persekitaran LUNO_API_KEY_ID dan LUNO_API_SECRET.",
+		callTool(t, srv, tools.GetBalancesToolID))
+}
+
+// sourceConcatenation matches the join between the parts of a string
+// constant split over several lines
+var sourceConcatenation = regexp.MustCompile(`"\s*\+\s*"`)
+
+func TestLocaleCatalogsAreCurrent(t *testing.T) {
+	// Each key of a catalog must still be the English text of a string in the
+	// source, or it no longer translates anything
+	var src strings.Builder
+	err := filepath.WalkDir("..", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return err
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		src.WriteString(sourceConcatenation.ReplaceAllString(string(b), ""))
+		return nil
+	})
+	require.NoError(t, err)
+
+	for _, l := range i18n.Locales() {
+		for _, key := range i18n.Keys(l) {
+			quoted := strconv.Quote(key)
+			assert.Contains(t, src.String(), quoted[1:len(quoted)-1], "%s catalog", l)
+		}
+	}
+}
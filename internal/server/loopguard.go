@@ -0,0 +1,101 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/tools"
+	"github.com/luno/luno-mcp/internal/usage"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// pollingTools report state that is expected to change between identical
+// calls, such as an approval still pending or orders not yet reconciled
+var pollingTools = []string{
+	tools.GetApprovalStatusToolID, tools.GetExchangeStatusToolID, tools.GetServerInfoToolID,
+	tools.GetUsageStatsToolID, tools.GetSessionStatsToolID, tools.ReconcileToolID, tools.ReconcileOrdersToolID,
+	tools.CheckUpdatesToolID,
+	// Orders, balances and withdrawals change as trades and withdrawals are made
+	tools.ListOrdersToolID, tools.GetBalancesToolID, tools.ListTransactionsToolID, tools.ListTradesToolID,
+	tools.GetFiatWithdrawalToolID, tools.ListFiatWithdrawalsToolID, tools.GetTradeJournalToolID,
+}
+
+// sideEffectTools do something besides reading, such as writing a file or
+// saving a note, that a reused result would silently skip
+var sideEffectTools = []string{
+	tools.ExportTradesToolID, tools.ExportPortfolioToolID, tools.ExportSessionToolID,
+	tools.LogTradeNoteToolID, tools.ScheduleReportToolID, tools.ImportTradesToolID, tools.AliasAccountToolID,
+}
+
+// guardsRepeats reports whether the loop guard may answer repeated calls to a
+// tool. Trading tools are always called, since a repeated order is deliberate
+// or refused by the duplicate order check, and so are polling tools and tools
+// with side effects.
+func guardsRepeats(tool string) bool {
+	return !tools.IsTrading(tool) && !slices.Contains(pollingTools, tool) && !slices.Contains(sideEffectTools, tool)
+}
+
+// loopGuardMiddleware serves the previous result, with a warning, when a
+// session repeats an identical tool call more often than cfg.LoopGuard allows.
+// Only successful results are reused, so failed calls can be retried, and only
+// for the window after they were fetched.
+func loopGuardMiddleware(cfg *config.Config) mcpserver.ToolHandlerMiddleware {
+	return func(next mcpserver.ToolHandlerFunc) mcpserver.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if cfg.LoopGuard.Threshold() == 0 || !guardsRepeats(request.Params.Name) {
+				return next(ctx, request)
+			}
+
+			key, err := repeatKey(ctx, request)
+			if err != nil {
+				return next(ctx, request)
+			}
+
+			count, cached, ok := cfg.LoopGuard.Observe(key)
+			if ok {
+				slog.WarnContext(ctx, "Repeated tool call, reusing previous result",
+					slog.String("tool", request.Params.Name), slog.Int("count", count))
+				usage.SessionFromContext(ctx).RepeatReused()
+				return withRepeatWarning(cached.(*mcp.CallToolResult), count, cfg.LoopGuard.Window().String()), nil
+			}
+
+			result, err := next(ctx, request)
+			if err == nil && result != nil && !result.IsError {
+				cfg.LoopGuard.Store(key, result)
+			}
+			return result, err
+		}
+	}
+}
+
+// repeatKey identifies identical calls: the same tool with the same arguments
+// in the same session
+func repeatKey(ctx context.Context, request mcp.CallToolRequest) (string, error) {
+	args, err := json.Marshal(request.GetArguments())
+	if err != nil {
+		return "", err
+	}
+	var session string
+	if md, ok := sdk.RequestMetadataFromContext(ctx); ok {
+		session = md.SessionID
+	}
+	return session + "\x00" + request.Params.Name + "\x00" + string(args), nil
+}
+
+// withRepeatWarning returns a copy of result with a warning in front of its content
+func withRepeatWarning(result *mcp.CallToolResult, count int, window string) *mcp.CallToolResult {
+	warning := mcp.NewTextContent(fmt.Sprintf(
+		"Warning: this exact call was made %d times within %s, so Luno was not called again and this is the previous result. "+
+			"Repeating the call will not change it: use the result, change the arguments, or wait before asking for fresh data.",
+		count, window))
+
+	repeated := *result
+	repeated.Content = append([]mcp.Content{warning}, result.Content...)
+	return &repeated
+}
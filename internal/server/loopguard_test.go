@@ -0,0 +1,158 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/loopguard"
+	"github.com/luno/luno-mcp/internal/tools"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoopGuardMiddleware(t *testing.T) {
+	cfg := &config.Config{LoopGuard: loopguard.New(2, time.Minute)}
+
+	var calls int
+	handler := loopGuardMiddleware(cfg)(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		calls++
+		return mcp.NewToolResultText("ticker"), nil
+	})
+	call := func(session string, args map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		ctx := sdk.ContextWithRequestMetadata(context.Background(), sdk.RequestMetadata{SessionID: session})
+		req := mcp.CallToolRequest{}
+		req.Params.Name = "get_ticker"
+		req.Params.Arguments = args
+		result, err := handler(ctx, req)
+		require.NoError(t, err)
+		return result
+	}
+
+	xbt := map[string]any{"pair": "XBTZAR"}
+	call("a", xbt)
+	call("a", xbt)
+	assert.Equal(t, 2, calls)
+
+	repeated := call("a", xbt)
+	assert.Equal(t, 2, calls, "the third identical call is not made")
+	require.Len(t, repeated.Content, 2)
+	assert.Contains(t, repeated.Content[0].(mcp.TextContent).Text, "made 3 times within 1m0s")
+	assert.Equal(t, "ticker", repeated.Content[1].(mcp.TextContent).Text)
+
+	call("a", map[string]any{"pair": "ETHZAR"})
+	call("b", xbt)
+	assert.Equal(t, 4, calls, "other arguments and sessions are counted separately")
+
+	first := call("a", xbt)
+	assert.Len(t, first.Content, 2)
+	second := call("a", xbt)
+	assert.Len(t, second.Content, 2, "the stored result is not changed by warnings")
+}
+
+func TestLoopGuardMiddlewareDisabled(t *testing.T) {
+	for name, guard := range map[string]*loopguard.Guard{"nil": nil, "zero threshold": loopguard.New(0, time.Minute)} {
+		t.Run(name, func(t *testing.T) {
+			var calls int
+			handler := loopGuardMiddleware(&config.Config{LoopGuard: guard})(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				calls++
+				return mcp.NewToolResultText("ok"), nil
+			})
+			for range 10 {
+				_, err := handler(context.Background(), mcp.CallToolRequest{})
+				require.NoError(t, err)
+			}
+			assert.Equal(t, 10, calls)
+		})
+	}
+}
+
+func TestLoopGuardMiddlewareExemptTools(t *testing.T) {
+	for _, tool := range []string{
+		tools.CreateOrderToolID, tools.CancelOrderToolID, tools.GetApprovalStatusToolID, tools.ReconcileOrdersToolID,
+		tools.ListOrdersToolID, tools.GetBalancesToolID, tools.GetFiatWithdrawalToolID,
+		tools.ExportTradesToolID, tools.LogTradeNoteToolID, tools.ScheduleReportToolID,
+	} {
+		t.Run(tool, func(t *testing.T) {
+			var calls int
+			handler := loopGuardMiddleware(&config.Config{LoopGuard: loopguard.New(2, time.Minute)})(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				calls++
+				return mcp.NewToolResultText("pending"), nil
+			})
+			req := mcp.CallToolRequest{}
+			req.Params.Name = tool
+			req.Params.Arguments = map[string]any{"id": "1"}
+			for range 5 {
+				result, err := handler(context.Background(), req)
+				require.NoError(t, err)
+				assert.Len(t, result.Content, 1)
+			}
+			assert.Equal(t, 5, calls, "every call is made")
+		})
+	}
+}
+
+func TestLoopGuardMiddlewarePolling(t *testing.T) {
+	const window = 50 * time.Millisecond
+	var calls int
+	handler := loopGuardMiddleware(&config.Config{LoopGuard: loopguard.New(1, window)})(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		calls++
+		return mcp.NewToolResultText(fmt.Sprintf("ticker %d", calls)), nil
+	})
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "get_ticker"
+	req.Params.Arguments = map[string]any{"pair": "XBTZAR"}
+	last := func() string {
+		t.Helper()
+		result, err := handler(context.Background(), req)
+		require.NoError(t, err)
+		return result.Content[len(result.Content)-1].(mcp.TextContent).Text
+	}
+
+	assert.Equal(t, "ticker 1", last())
+	assert.Equal(t, "ticker 1", last(), "the repeat is answered from the previous result")
+
+	// A client polling faster than the window still gets fresh data once the
+	// reused result is a window old
+	deadline := time.Now().Add(10 * window)
+	for calls < 3 && time.Now().Before(deadline) {
+		last()
+		time.Sleep(window / 5)
+	}
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, "ticker 3", last())
+}
+
+func TestLoopGuardMiddlewareErrorsNotReused(t *testing.T) {
+	var calls int
+	handler := loopGuardMiddleware(&config.Config{LoopGuard: loopguard.New(2, time.Minute)})(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		calls++
+		if calls <= 3 {
+			return mcp.NewToolResultError("Luno is unavailable"), nil
+		}
+		return mcp.NewToolResultText("ticker"), nil
+	})
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "get_ticker"
+	req.Params.Arguments = map[string]any{"pair": "XBTZAR"}
+
+	for range 3 {
+		result, err := handler(context.Background(), req)
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+	}
+	result, err := handler(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, 4, calls, "failed calls are retried")
+	assert.False(t, result.IsError)
+
+	result, err = handler(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, 4, calls, "the successful result is reused")
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "this exact call was made 5 times")
+}
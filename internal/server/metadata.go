@@ -0,0 +1,47 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"log/slog"
+
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// requestMetadataMiddleware adds sdk.RequestMetadata to the context of every tool call,
+// so that Luno API calls and log lines can be attributed to the originating MCP session.
+func requestMetadataMiddleware(next mcpserver.ToolHandlerFunc) mcpserver.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = sdk.ContextWithRequestMetadata(ctx, newRequestMetadata(ctx))
+		slog.DebugContext(ctx, "Tool call", slog.String("tool", request.Params.Name))
+		return next(ctx, request)
+	}
+}
+
+// resourceMetadataMiddleware is requestMetadataMiddleware for resource reads
+func resourceMetadataMiddleware(next mcpserver.ResourceHandlerFunc) mcpserver.ResourceHandlerFunc {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		ctx = sdk.ContextWithRequestMetadata(ctx, newRequestMetadata(ctx))
+		slog.DebugContext(ctx, "Resource read", slog.String("uri", request.Params.URI))
+		return next(ctx, request)
+	}
+}
+
+// newRequestMetadata generates a request ID and records the client session from ctx
+func newRequestMetadata(ctx context.Context) sdk.RequestMetadata {
+	md := sdk.RequestMetadata{RequestID: rand.Text()}
+
+	session := mcpserver.ClientSessionFromContext(ctx)
+	if session == nil {
+		return md
+	}
+	md.SessionID = session.SessionID()
+	if s, ok := session.(mcpserver.SessionWithClientInfo); ok {
+		info := s.GetClientInfo()
+		md.ClientName = info.Name
+		md.ClientVersion = info.Version
+	}
+	return md
+}
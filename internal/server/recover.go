@@ -0,0 +1,54 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+
+	"github.com/luno/luno-mcp/internal/redact"
+	"github.com/luno/luno-mcp/internal/telemetry"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// recoverMiddleware turns a panic in a tool call into an error result, logging
+// the panic with its stack, so that a bug in one tool fails that call rather
+// than killing the server and every session with it. It is registered
+// innermost, around the tool handlers, so that the other middleware see the
+// error like any other, and again just inside requestMetadataMiddleware in
+// case the middleware itself panics.
+func recoverMiddleware(next mcpserver.ToolHandlerFunc) mcpserver.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (result *mcp.CallToolResult, err error) {
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+			// Panic values can hold anything the handler had to hand
+			panicked := redact.String(fmt.Sprint(r))
+			telemetry.CallFromContext(ctx).Panicked()
+			slog.ErrorContext(ctx, "Tool call panicked",
+				slog.String("tool", request.Params.Name),
+				slog.String("panic", panicked),
+				slog.String("stack", string(debug.Stack())))
+			result, err = panicResult(ctx, request.Params.Name, panicked), nil
+		}()
+		return next(ctx, request)
+	}
+}
+
+// panicResult is the error result of a tool call that panicked. It carries the
+// request ID of the call, which is logged with the stack.
+func panicResult(ctx context.Context, tool, panicked string) *mcp.CallToolResult {
+	md, _ := sdk.RequestMetadataFromContext(ctx)
+	result := mcp.NewToolResultError(fmt.Sprintf("Internal error in %s: %s. The call did not complete and the server is still running; "+
+		"other tools can be used, but retrying this call may fail the same way.", tool, panicked))
+	result.StructuredContent = map[string]any{
+		"error":      "internal_error",
+		"tool":       tool,
+		"request_id": md.RequestID,
+	}
+	return result
+}
@@ -0,0 +1,49 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/telemetry"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecoverMiddleware(t *testing.T) {
+	call := &telemetry.Call{}
+	ctx := telemetry.ContextWithCall(sdk.ContextWithRequestMetadata(context.Background(), sdk.RequestMetadata{RequestID: "req-1"}), call)
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "get_ticker"
+
+	result, err := recoverMiddleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var m map[string]int
+		m["boom"]++
+		return nil, nil
+	})(ctx, req)
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "Internal error in get_ticker: assignment to entry in nil map")
+	assert.Equal(t, map[string]any{"error": "internal_error", "tool": "get_ticker", "request_id": "req-1"}, result.StructuredContent)
+	assert.Equal(t, telemetry.Panic, call.Categorise(nil, true, false))
+
+	_, err = recoverMiddleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return nil, errors.New("not a panic")
+	})(ctx, req)
+	assert.EqualError(t, err, "not a panic", "errors are passed on as they are")
+}
+
+func TestToolPanicKeepsServerRunning(t *testing.T) {
+	srv, err := NewMCPServerWithToolsets(testServerName, testVersion1, &config.Config{LunoClient: luno.NewClient()}, nil)
+	require.NoError(t, err)
+	srv.AddTool(mcp.NewTool("explode"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		panic(errors.New("bad code path"))
+	})
+
+	assert.Contains(t, callTool(t, srv, "explode"), "Internal error in explode: bad code path")
+	assert.Contains(t, callTool(t, srv, "explode"), "Internal error in explode", "the server still answers calls")
+}
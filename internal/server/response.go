@@ -0,0 +1,46 @@
+package server
+
+import (
+	"context"
+
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/redact"
+	"github.com/luno/luno-mcp/internal/tools"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// responseBudgetMiddleware truncates tool results larger than the configured
+// response budget so that they can be read in parts with fetch_more
+func responseBudgetMiddleware(cfg *config.Config) mcpserver.ToolHandlerMiddleware {
+	return func(next mcpserver.ToolHandlerFunc) mcpserver.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			result, err := next(ctx, request)
+			if err != nil {
+				return result, err
+			}
+			return tools.TruncateResult(cfg.ForContext(ctx), result), nil
+		}
+	}
+}
+
+// redactErrorsMiddleware scrubs secrets from the text of error results, which
+// often carry messages of errors from the Luno client or the network
+func redactErrorsMiddleware(next mcpserver.ToolHandlerFunc) mcpserver.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := next(ctx, request)
+		if err != nil {
+			return result, redact.Error(err)
+		}
+		if result == nil || !result.IsError {
+			return result, nil
+		}
+		for i, c := range result.Content {
+			if text, ok := c.(mcp.TextContent); ok {
+				text.Text = redact.String(text.Text)
+				result.Content[i] = text
+			}
+		}
+		return result, nil
+	}
+}
@@ -0,0 +1,184 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/resources"
+	"github.com/luno/luno-mcp/internal/roles"
+	"github.com/luno/luno-mcp/internal/tools"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// errAccessTokenRequired is returned for resource reads without a valid access token
+var errAccessTokenRequired = errors.New("a valid access token is required")
+
+// accessControl restricts the tools each client may call to the role of its
+// access token, when cfg.AccessTokens is set. Every call is checked against
+// the token it carries. The tool list, which carries no token, is filtered
+// for the role of the token the session was initialized with.
+type accessControl struct {
+	cfg *config.Config
+
+	mu       sync.Mutex
+	sessions map[string]roles.Role
+}
+
+func newAccessControl(cfg *config.Config) *accessControl {
+	return &accessControl{cfg: cfg, sessions: make(map[string]roles.Role)}
+}
+
+// viewerTools are the tools without side effects, which any role may call.
+// Tools that write files, such as export_portfolio and log_trade_note, deliver
+// output elsewhere, such as schedule_report, or update what the server keeps,
+// such as reconcile_orders, are left out, as is any tool not listed here, so a
+// new tool needs the trader role until it is added.
+var viewerTools = []string{
+	tools.GetBalancesToolID, tools.GetTickerToolID, tools.GetTickersToolID, tools.GetOrderBookToolID,
+	tools.ListOrdersToolID, tools.ListTransactionsToolID, tools.GetTransactionToolID, tools.ListTradesToolID,
+	tools.GetCandlesToolID, tools.GetMarketsInfoToolID, tools.ExplainMarketToolID, tools.GetExchangeStatusToolID,
+	tools.SuggestPositionSizeToolID, tools.GetPricePremiumToolID, tools.GetTradeJournalToolID, tools.ListRootsToolID,
+	tools.GetServerInfoToolID, tools.FetchMoreToolID, tools.GetTopMoversToolID, tools.GetCorrelationsToolID,
+	tools.BacktestStrategyToolID, tools.QuoteBasketToolID, tools.DetectPatternsToolID, tools.GetKeyLevelsToolID,
+	tools.GetAccountInfoToolID, tools.GetUsageStatsToolID, tools.GetSessionStatsToolID, tools.ReconcileToolID, tools.GetTradeFlowToolID, tools.CheckUpdatesToolID, tools.GetApprovalStatusToolID,
+	tools.ListFiatWithdrawalsToolID, tools.GetFiatWithdrawalToolID,
+}
+
+// requiredRole returns the least role that may call tool
+func requiredRole(tool string) roles.Role {
+	switch {
+	case tool == tools.CreateFiatWithdrawalToolID:
+		return roles.Admin
+	case slices.Contains(viewerTools, tool):
+		return roles.Viewer
+	default:
+		return roles.Trader
+	}
+}
+
+// viewerResources are the market and account resources, which any role may
+// read. Others, such as the audit log and the trade journal, need the trader
+// role.
+var viewerResources = []string{
+	resources.WalletResourceURI, resources.TransactionsResourceURI, resources.AccountTemplateURI,
+	resources.MarketTemplateURI, resources.TickersResourceURI, resources.OrderBookTemplateURI, resources.CandlesTemplateURI,
+}
+
+// requiredResourceRole returns the least role that may read the resource at uri
+func requiredResourceRole(uri string) roles.Role {
+	for _, r := range viewerResources {
+		prefix, _, templated := strings.Cut(r, "{")
+		if uri == r || (templated && strings.HasPrefix(uri, prefix)) {
+			return roles.Viewer
+		}
+	}
+	return roles.Trader
+}
+
+// addHooks records the role of each session as it initializes
+func (a *accessControl) addHooks(hooks *mcpserver.Hooks) {
+	hooks.AddAfterInitialize(func(ctx context.Context, _ any, req *mcp.InitializeRequest, _ *mcp.InitializeResult) {
+		session := mcpserver.ClientSessionFromContext(ctx)
+		if session == nil {
+			return
+		}
+		role, ok := a.cfg.AccessTokens.FromHeader(req.Header)
+		if !ok {
+			slog.Warn("Session initialized without a valid access token", slog.String("session_id", session.SessionID()))
+			return
+		}
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		a.sessions[session.SessionID()] = role
+	})
+	hooks.AddOnUnregisterSession(func(_ context.Context, session mcpserver.ClientSession) {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		delete(a.sessions, session.SessionID())
+	})
+}
+
+// middleware refuses tool calls the role of their access token doesn't allow.
+// It runs inside auditMiddleware, so refused calls are in the audit log.
+func (a *accessControl) middleware(next mcpserver.ToolHandlerFunc) mcpserver.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if a.cfg.AccessTokens == nil {
+			return next(ctx, request)
+		}
+		tool := request.Params.Name
+		required := requiredRole(tool)
+		role, ok := a.cfg.AccessTokens.FromHeader(request.Header)
+		if ok && role.Allows(required) {
+			return next(ctx, request)
+		}
+		slog.WarnContext(ctx, "Tool call refused for the role of its access token",
+			slog.String("tool", tool), slog.String("role", string(role)), slog.String("required_role", string(required)))
+		return accessDeniedResult(tool, role, required), nil
+	}
+}
+
+// resourceMiddleware refuses resource reads without a valid access token, and
+// reads of resources the token's role doesn't allow
+func (a *accessControl) resourceMiddleware(next mcpserver.ResourceHandlerFunc) mcpserver.ResourceHandlerFunc {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		if a.cfg.AccessTokens == nil {
+			return next(ctx, request)
+		}
+		role, ok := a.cfg.AccessTokens.FromHeader(request.Header)
+		if !ok {
+			return nil, errAccessTokenRequired
+		}
+		if required := requiredResourceRole(request.Params.URI); !role.Allows(required) {
+			slog.WarnContext(ctx, "Resource read refused for the role of its access token",
+				slog.String("uri", request.Params.URI), slog.String("role", string(role)), slog.String("required_role", string(required)))
+			return nil, fmt.Errorf("access policy: %s requires the %s role or higher, and this client has the %s role", request.Params.URI, required, role)
+		}
+		return next(ctx, request)
+	}
+}
+
+// filter is a mcpserver.ToolFilterFunc leaving out the tools the session's
+// role may not call
+func (a *accessControl) filter(ctx context.Context, list []mcp.Tool) []mcp.Tool {
+	if a.cfg.AccessTokens == nil {
+		return list
+	}
+	var role roles.Role
+	if session := mcpserver.ClientSessionFromContext(ctx); session != nil {
+		a.mu.Lock()
+		role = a.sessions[session.SessionID()]
+		a.mu.Unlock()
+	}
+	out := make([]mcp.Tool, 0, len(list))
+	for _, t := range list {
+		if role.Allows(requiredRole(t.Name)) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// accessDeniedResult is the policy error for a tool call role may not make
+func accessDeniedResult(tool string, role, required roles.Role) *mcp.CallToolResult {
+	text := fmt.Sprintf("Access policy: %s requires the %s role or higher", tool, required)
+	if role == "" {
+		text += ", and this call carries no valid access token."
+	} else {
+		text += fmt.Sprintf(", and this client has the %s role.", role)
+	}
+	result := mcp.NewToolResultError(text + " Do not retry, and do not work around the policy with other tools.")
+	result.StructuredContent = map[string]any{
+		"error":         "access_policy",
+		"tool":          tool,
+		"role":          string(role),
+		"required_role": string(required),
+	}
+	return result
+}
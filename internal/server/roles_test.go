@@ -0,0 +1,164 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/roles"
+	"github.com/luno/luno-mcp/internal/tools"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAccessControl(t *testing.T) *accessControl {
+	t.Helper()
+	tokens, err := roles.ParseTokens("viewer-token:viewer,trader-token:trader,admin-token:admin")
+	require.NoError(t, err)
+	return newAccessControl(&config.Config{AccessTokens: tokens})
+}
+
+func TestAccessControlMiddleware(t *testing.T) {
+	access := newTestAccessControl(t)
+	handler := access.middleware(func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	})
+
+	testCases := []struct {
+		token   string
+		tool    string
+		allowed bool
+	}{
+		{token: "viewer-token", tool: "get_ticker", allowed: true},
+		{token: "viewer-token", tool: "create_order"},
+		{token: "viewer-token", tool: "schedule_report"},
+		{token: "trader-token", tool: "export_portfolio", allowed: true},
+		{token: "trader-token", tool: "create_order", allowed: true},
+		{token: "trader-token", tool: "create_fiat_withdrawal"},
+		{token: "admin-token", tool: "create_fiat_withdrawal", allowed: true},
+		{tool: "get_ticker"},
+		{token: "unknown-token", tool: "get_ticker"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.token+" "+tc.tool, func(t *testing.T) {
+			req := mcp.CallToolRequest{Header: http.Header{}}
+			req.Params.Name = tc.tool
+			if tc.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			result, err := handler(context.Background(), req)
+			require.NoError(t, err)
+			assert.Equal(t, !tc.allowed, result.IsError)
+			if !tc.allowed {
+				structured := result.StructuredContent.(map[string]any)
+				assert.Equal(t, "access_policy", structured["error"])
+				assert.Equal(t, string(requiredRole(tc.tool)), structured["required_role"])
+			}
+		})
+	}
+
+	access.cfg.AccessTokens = nil
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "create_fiat_withdrawal"
+	result, err := handler(context.Background(), req)
+	require.NoError(t, err)
+	assert.False(t, result.IsError, "any client may call any tool without access tokens")
+}
+
+func TestRequiredRoleStateChangingTools(t *testing.T) {
+	stateChanging := []string{
+		tools.CreateOrderToolID, tools.CancelOrderToolID, tools.ReplaceOrderToolID, tools.CreateOrdersBatchToolID,
+		tools.CreateMarketOrderToolID, tools.ExecuteTWAPToolID, tools.IcebergOrderToolID, tools.UndoLastActionToolID,
+		tools.CreateFiatWithdrawalToolID, tools.ScheduleReportToolID, tools.ExportTradesToolID, tools.ExportPortfolioToolID,
+		tools.ExportSessionToolID, tools.ImportTradesToolID, tools.AliasAccountToolID, tools.LogTradeNoteToolID,
+		tools.ReconcileOrdersToolID,
+	}
+	for _, tool := range stateChanging {
+		assert.NotEqual(t, roles.Viewer, requiredRole(tool), tool)
+	}
+	assert.Equal(t, roles.Trader, requiredRole("some_new_tool"), "unlisted tools are not open to viewers")
+	assert.Equal(t, roles.Viewer, requiredRole(tools.GetTickerToolID))
+	assert.Equal(t, roles.Admin, requiredRole(tools.CreateFiatWithdrawalToolID))
+}
+
+func TestAccessControlResourceMiddleware(t *testing.T) {
+	access := newTestAccessControl(t)
+	handler := access.resourceMiddleware(func(context.Context, mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return []mcp.ResourceContents{}, nil
+	})
+
+	testCases := []struct {
+		token   string
+		uri     string
+		allowed bool
+	}{
+		{token: "viewer-token", uri: "luno://wallets", allowed: true},
+		{token: "viewer-token", uri: "luno://accounts/12345", allowed: true},
+		{token: "viewer-token", uri: "luno://markets/XBTZAR", allowed: true},
+		{token: "viewer-token", uri: "luno://candles/XBTZAR/1h", allowed: true},
+		{token: "viewer-token", uri: "luno://audit/recent"},
+		{token: "viewer-token", uri: "luno://audit/recent?limit=10"},
+		{token: "viewer-token", uri: "luno://journal"},
+		{token: "trader-token", uri: "luno://audit/recent?cursor=5", allowed: true},
+		{token: "trader-token", uri: "luno://journal", allowed: true},
+		{uri: "luno://wallets"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.token+" "+tc.uri, func(t *testing.T) {
+			req := mcp.ReadResourceRequest{Header: http.Header{}}
+			req.Params.URI = tc.uri
+			if tc.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			_, err := handler(context.Background(), req)
+			if tc.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+
+	access.cfg.AccessTokens = nil
+	req := mcp.ReadResourceRequest{}
+	req.Params.URI = "luno://audit/recent"
+	_, err := handler(context.Background(), req)
+	assert.NoError(t, err, "any client may read any resource without access tokens")
+}
+
+func TestAccessControlFilter(t *testing.T) {
+	access := newTestAccessControl(t)
+	hooks := &mcpserver.Hooks{}
+	access.addHooks(hooks)
+	srv := mcpserver.NewMCPServer(testServerName, testVersion1)
+	list := []mcp.Tool{{Name: "get_ticker"}, {Name: "create_order"}, {Name: "create_fiat_withdrawal"}}
+
+	names := func(token string) []string {
+		session := mcpserver.NewInProcessSession("session-"+token, nil)
+		ctx := srv.WithContext(context.Background(), session)
+		req := &mcp.InitializeRequest{Header: http.Header{}}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		for _, hook := range hooks.OnAfterInitialize {
+			hook(ctx, 1, req, &mcp.InitializeResult{})
+		}
+		var out []string
+		for _, tool := range access.filter(ctx, list) {
+			out = append(out, tool.Name)
+		}
+		for _, hook := range hooks.OnUnregisterSession {
+			hook(ctx, session)
+		}
+		return out
+	}
+
+	assert.Equal(t, []string{"get_ticker"}, names("viewer-token"))
+	assert.Equal(t, []string{"get_ticker", "create_order"}, names("trader-token"))
+	assert.Equal(t, []string{"get_ticker", "create_order", "create_fiat_withdrawal"}, names("admin-token"))
+	assert.Empty(t, names(""))
+	assert.Empty(t, access.sessions)
+}
@@ -2,22 +2,100 @@ package server
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
-	"os"
+	"net/http"
+	"strings"
 
 	"github.com/luno/luno-mcp/internal/config"
 	"github.com/luno/luno-mcp/internal/resources"
 	"github.com/luno/luno-mcp/internal/tools"
+	"github.com/mark3labs/mcp-go/mcp"
 	mcpserver "github.com/mark3labs/mcp-go/server"
 )
 
-// NewMCPServer creates a new MCP server
+// Toolsets group related tools so that embedders can register a subset of them
+const (
+	ToolsetMarket       = "market"
+	ToolsetAccount      = "account"
+	ToolsetTrading      = "trading"
+	ToolsetTransactions = "transactions"
+	ToolsetExports      = "exports"
+	ToolsetWithdrawals  = "withdrawals"
+	ToolsetReports      = "reports"
+)
+
+// AllToolsets lists every toolset, in registration order
+var AllToolsets = []string{ToolsetMarket, ToolsetAccount, ToolsetTrading, ToolsetTransactions, ToolsetExports, ToolsetWithdrawals, ToolsetReports}
+
+var toolsetRegistrars = map[string]func(*mcpserver.MCPServer, *config.Config){
+	ToolsetMarket:       registerMarketTools,
+	ToolsetAccount:      registerAccountTools,
+	ToolsetTrading:      registerTradingTools,
+	ToolsetTransactions: registerTransactionTools,
+	ToolsetExports:      registerExportTools,
+	ToolsetWithdrawals:  registerWithdrawalTools,
+	ToolsetReports:      registerReportTools,
+}
+
+// NewMCPServer creates a new MCP server with all toolsets registered
 func NewMCPServer(name, version string, cfg *config.Config, hooks ...*mcpserver.Hooks) *mcpserver.MCPServer {
+	server, err := NewMCPServerWithToolsets(name, version, cfg, AllToolsets, hooks...)
+	if err != nil {
+		// AllToolsets only contains known toolsets
+		panic(err)
+	}
+	return server
+}
+
+// NewMCPServerWithToolsets creates a new MCP server with only the given toolsets registered.
+// Resources and the get_server_info, fetch_more, get_usage_stats, get_session_stats,
+// export_session and check_updates tools are always registered.
+func NewMCPServerWithToolsets(name, version string, cfg *config.Config, toolsets []string, hooks ...*mcpserver.Hooks) (*mcpserver.MCPServer, error) {
+	if err := validateToolsets(toolsets); err != nil {
+		return nil, err
+	}
+
+	capabilities := newCapabilityFilter(cfg)
+	access := newAccessControl(cfg)
+
 	// Prepare options for the server
 	options := []mcpserver.ServerOption{
 		mcpserver.WithResourceCapabilities(true, true),
 		mcpserver.WithToolCapabilities(true),
 		mcpserver.WithLogging(),
+		mcpserver.WithCompletions(),
+		mcpserver.WithResourceCompletionProvider(marketCompletionProvider{cfg: cfg}),
+		mcpserver.WithToolHandlerMiddleware(requestMetadataMiddleware),
+		mcpserver.WithToolHandlerMiddleware(recoverMiddleware),
+		mcpserver.WithToolHandlerMiddleware(revokedSessionMiddleware(cfg)),
+		mcpserver.WithToolHandlerMiddleware(sessionStatsMiddleware(cfg)),
+		mcpserver.WithToolHandlerMiddleware(transcriptMiddleware(cfg)),
+		mcpserver.WithToolHandlerMiddleware(telemetryMiddleware(cfg)),
+		mcpserver.WithToolHandlerMiddleware(auditMiddleware(cfg)),
+		mcpserver.WithToolHandlerMiddleware(access.middleware),
+		mcpserver.WithToolHandlerMiddleware(tradingHoursMiddleware(cfg)),
+		mcpserver.WithToolHandlerMiddleware(approvalMiddleware(cfg)),
+		mcpserver.WithToolHandlerMiddleware(loopGuardMiddleware(cfg)),
+		mcpserver.WithToolHandlerMiddleware(concurrencyMiddleware(cfg)),
+		mcpserver.WithResourceHandlerMiddleware(resourceMetadataMiddleware),
+		mcpserver.WithResourceHandlerMiddleware(revokedSessionResourceMiddleware(cfg)),
+		mcpserver.WithResourceHandlerMiddleware(access.resourceMiddleware),
+		mcpserver.WithToolHandlerMiddleware(sessionOverlayMiddleware(cfg)),
+		mcpserver.WithToolHandlerMiddleware(balanceSnapshotMiddleware(cfg)),
+		mcpserver.WithToolHandlerMiddleware(apiNoticesMiddleware),
+		mcpserver.WithToolHandlerMiddleware(responseBudgetMiddleware(cfg)),
+		mcpserver.WithToolHandlerMiddleware(injectionWarningsMiddleware),
+		mcpserver.WithToolHandlerMiddleware(outputFormatMiddleware(cfg)),
+		mcpserver.WithToolHandlerMiddleware(usageMiddleware(cfg)),
+		mcpserver.WithToolHandlerMiddleware(redactErrorsMiddleware),
+		mcpserver.WithToolHandlerMiddleware(localeMiddleware(cfg)),
+		mcpserver.WithToolHandlerMiddleware(recoverMiddleware),
+		mcpserver.WithResourceHandlerMiddleware(sessionOverlayResourceMiddleware(cfg)),
+		mcpserver.WithToolFilter(outputFormatToolFilter),
+		mcpserver.WithToolFilter(localeToolFilter(cfg)),
+		mcpserver.WithToolFilter(capabilities.filter),
+		mcpserver.WithToolFilter(access.filter),
 	}
 
 	// Add hooks if provided
@@ -25,6 +103,22 @@ func NewMCPServer(name, version string, cfg *config.Config, hooks ...*mcpserver.
 		options = append(options, mcpserver.WithHooks(hook))
 	}
 
+	// Hooks replace one another, so the server's own join a copy of the last
+	// ones given
+	if cfg.Tenants != nil || cfg.AccessTokens != nil {
+		var h mcpserver.Hooks
+		if len(hooks) > 0 {
+			h = *hooks[len(hooks)-1]
+		}
+		if cfg.Tenants != nil {
+			addTenantHooks(&h, cfg)
+		}
+		if cfg.AccessTokens != nil {
+			access.addHooks(&h)
+		}
+		options = append(options, mcpserver.WithHooks(&h))
+	}
+
 	// Create server with capabilities
 	server := mcpserver.NewMCPServer(
 		name,
@@ -32,13 +126,58 @@ func NewMCPServer(name, version string, cfg *config.Config, hooks ...*mcpserver.
 		options...,
 	)
 
+	// Allow tools to ask the client's model for completions, e.g. market commentary
+	server.EnableSampling()
+
+	// A session's tools depend on its credentials, so have its client list them
+	// again when they change
+	if cfg.Sessions != nil {
+		cfg.Sessions.Watch(func(sessionID string) {
+			capabilities.forget(sessionID)
+			notifyToolsChanged(server, sessionID)
+		})
+	}
+
 	// Register resources
 	registerResources(server, cfg)
+	server.AddResource(resources.NewServerInfoResource(), resources.HandleServerInfoResource(cfg, name, version))
 
 	// Register tools
-	registerTools(server, cfg)
+	server.AddTool(tools.NewGetServerInfoTool(), tools.HandleGetServerInfo(cfg, name, version))
+	server.AddTool(tools.NewFetchMoreTool(), tools.HandleFetchMore(cfg))
+	server.AddTool(tools.NewGetUsageStatsTool(), tools.HandleGetUsageStats(cfg))
+	server.AddTool(tools.NewGetSessionStatsTool(), tools.HandleGetSessionStats(cfg))
+	server.AddTool(tools.NewExportSessionTool(), tools.HandleExportSession(cfg))
+	server.AddTool(tools.NewCheckUpdatesTool(), tools.HandleCheckUpdates(cfg))
+	if cfg.Approvals != nil {
+		server.AddTool(tools.NewGetApprovalStatusTool(), tools.HandleGetApprovalStatus(cfg))
+	}
+	if err := RegisterToolsets(server, cfg, toolsets...); err != nil {
+		return nil, err
+	}
 
-	return server
+	return server, nil
+}
+
+// RegisterToolsets registers the tools in the given toolsets with the MCP server
+func RegisterToolsets(server *mcpserver.MCPServer, cfg *config.Config, toolsets ...string) error {
+	if err := validateToolsets(toolsets); err != nil {
+		return err
+	}
+	for _, name := range toolsets {
+		toolsetRegistrars[name](server, cfg)
+	}
+	return nil
+}
+
+// validateToolsets returns an error for the first unknown toolset name
+func validateToolsets(toolsets []string) error {
+	for _, name := range toolsets {
+		if _, ok := toolsetRegistrars[name]; !ok {
+			return fmt.Errorf("unknown toolset %q: must be one of %s", name, strings.Join(AllToolsets, ", "))
+		}
+	}
+	return nil
 }
 
 // registerResources registers all resources with the MCP server
@@ -54,83 +193,215 @@ func registerResources(server *mcpserver.MCPServer, cfg *config.Config) {
 	// Add account resource template
 	accountTemplate := resources.NewAccountTemplate()
 	server.AddResourceTemplate(accountTemplate, resources.HandleAccountTemplate(cfg))
+
+	// Add market resource template, with pair completions from the market cache
+	marketTemplate := resources.NewMarketTemplate()
+	server.AddResourceTemplate(marketTemplate, resources.HandleMarketTemplate(cfg))
+
+	// Add the order book and candles of a market
+	server.AddResourceTemplate(resources.NewOrderBookTemplate(), resources.HandleOrderBookTemplate(cfg))
+	server.AddResourceTemplate(resources.NewCandlesTemplate(), resources.HandleCandlesTemplate(cfg))
+
+	// Add the tickers of every market, kept fresh by RunTickers
+	server.AddResource(resources.NewTickersResource(), resources.HandleTickersResource(cfg))
+
+	// Add the audit log and trade journal, readable in pages through their templates
+	auditHandler := resources.HandleAuditResource(cfg)
+	server.AddResource(resources.NewAuditResource(), auditHandler)
+	server.AddResourceTemplate(resources.NewAuditTemplate(), mcpserver.ResourceTemplateHandlerFunc(auditHandler))
+
+	journalHandler := resources.HandleJournalResource(cfg)
+	server.AddResource(resources.NewJournalResource(), journalHandler)
+	server.AddResourceTemplate(resources.NewJournalTemplate(), mcpserver.ResourceTemplateHandlerFunc(journalHandler))
 }
 
-// registerTools registers all available tools with the MCP server.
-// It always registers trading tools for create and cancel orders; if cfg.AllowWriteOperations
-// is false those tools are wired to handlers that return an informative "write disabled"
-// response. The cfg parameter controls whether write-operation handlers accept requests or
-// are registered as disabled.
-func registerTools(server *mcpserver.MCPServer, cfg *config.Config) {
-	// Add balance tools
-	balancesTool := tools.NewGetBalancesTool()
-	server.AddTool(balancesTool, tools.HandleGetBalances(cfg))
+// RunTickers refreshes cfg.Tickers until ctx is cancelled, notifying clients
+// that the tickers resource was updated after each refresh
+func RunTickers(ctx context.Context, server *mcpserver.MCPServer, cfg *config.Config) {
+	cfg.Tickers.Run(ctx, func() {
+		server.SendNotificationToAllClients(mcp.MethodNotificationResourceUpdated, map[string]any{
+			"uri": resources.TickersResourceURI,
+		})
+	})
+}
 
-	// Add market tools
+// registerMarketTools registers the public market data tools
+func registerMarketTools(server *mcpserver.MCPServer, cfg *config.Config) {
 	tickerTool := tools.NewGetTickerTool()
 	server.AddTool(tickerTool, tools.HandleGetTicker(cfg))
 
 	orderBookTool := tools.NewGetOrderBookTool()
 	server.AddTool(orderBookTool, tools.HandleGetOrderBook(cfg))
 
-	// Add trading tools
+	listTradesTool := tools.NewListTradesTool()
+	server.AddTool(listTradesTool, tools.HandleListTrades(cfg))
+
+	getTickersTool := tools.NewGetTickersTool()
+	server.AddTool(getTickersTool, tools.HandleGetTickers(cfg))
+
+	getCandlesTool := tools.NewGetCandlesTool()
+	server.AddTool(getCandlesTool, tools.HandleGetCandles(cfg))
+
+	getMarketsInfoTool := tools.NewGetMarketsInfoTool()
+	server.AddTool(getMarketsInfoTool, tools.HandleGetMarketsInfo(cfg))
+
+	explainMarketTool := tools.NewExplainMarketTool()
+	server.AddTool(explainMarketTool, tools.HandleExplainMarket(cfg))
+
+	exchangeStatusTool := tools.NewGetExchangeStatusTool()
+	server.AddTool(exchangeStatusTool, tools.HandleGetExchangeStatus(cfg))
+
+	pricePremiumTool := tools.NewGetPricePremiumTool()
+	server.AddTool(pricePremiumTool, tools.HandleGetPricePremium(cfg))
+
+	topMoversTool := tools.NewGetTopMoversTool()
+	server.AddTool(topMoversTool, tools.HandleGetTopMovers(cfg))
+
+	correlationsTool := tools.NewGetCorrelationsTool()
+	server.AddTool(correlationsTool, tools.HandleGetCorrelations(cfg))
+
+	backtestTool := tools.NewBacktestStrategyTool()
+	server.AddTool(backtestTool, tools.HandleBacktestStrategy(cfg))
+
+	detectPatternsTool := tools.NewDetectPatternsTool()
+	server.AddTool(detectPatternsTool, tools.HandleDetectPatterns(cfg))
+
+	keyLevelsTool := tools.NewGetKeyLevelsTool()
+	server.AddTool(keyLevelsTool, tools.HandleGetKeyLevels(cfg))
+
+	tradeFlowTool := tools.NewGetTradeFlowTool()
+	server.AddTool(tradeFlowTool, tools.HandleGetTradeFlow(cfg))
+}
+
+// registerAccountTools registers the account balance, alias and info tools
+func registerAccountTools(server *mcpserver.MCPServer, cfg *config.Config) {
+	balancesTool := tools.NewGetBalancesTool()
+	server.AddTool(balancesTool, tools.HandleGetBalances(cfg))
+
+	aliasAccountTool := tools.NewAliasAccountTool()
+	server.AddTool(aliasAccountTool, tools.HandleAliasAccount(cfg))
+
+	accountInfoTool := tools.NewGetAccountInfoTool()
+	server.AddTool(accountInfoTool, tools.HandleGetAccountInfo(cfg))
+
+	reconcileTool := tools.NewReconcileTool()
+	server.AddTool(reconcileTool, tools.HandleReconcile(cfg))
+}
+
+// registerTradingTools registers the order tools.
+// It always registers trading tools for create and cancel orders; if cfg.AllowWriteOperations
+// is false those tools are wired to handlers that return an informative "write disabled"
+// response. The cfg parameter controls whether write-operation handlers accept requests or
+// are registered as disabled.
+func registerTradingTools(server *mcpserver.MCPServer, cfg *config.Config) {
 	// Write operation tools are always registered so clients know they exist.
 	// When disabled, their handlers return an informative error explaining how to enable them.
 	createOrderTool := tools.NewCreateOrderTool()
 	cancelOrderTool := tools.NewCancelOrderTool()
+	replaceOrderTool := tools.NewReplaceOrderTool()
+	executeTWAPTool := tools.NewExecuteTWAPTool()
+	icebergOrderTool := tools.NewIcebergOrderTool()
+	createOrdersBatchTool := tools.NewCreateOrdersBatchTool()
+	createMarketOrderTool := tools.NewCreateMarketOrderTool()
+	undoLastActionTool := tools.NewUndoLastActionTool()
 
 	if cfg.AllowWriteOperations {
-		slog.Info("Write operations enabled - registering create_order and cancel_order tools")
+		slog.Info("Write operations enabled - registering create_order, cancel_order, replace_order, execute_twap, iceberg_order, create_orders_batch, create_market_order and undo_last_action tools")
 		server.AddTool(createOrderTool, tools.HandleCreateOrder(cfg))
 		server.AddTool(cancelOrderTool, tools.HandleCancelOrder(cfg))
+		server.AddTool(replaceOrderTool, tools.HandleReplaceOrder(cfg))
+		server.AddTool(executeTWAPTool, tools.HandleExecuteTWAP(cfg))
+		server.AddTool(icebergOrderTool, tools.HandleIcebergOrder(cfg))
+		server.AddTool(createOrdersBatchTool, tools.HandleCreateOrdersBatch(cfg))
+		server.AddTool(createMarketOrderTool, tools.HandleCreateMarketOrder(cfg))
+		server.AddTool(undoLastActionTool, tools.HandleUndoLastAction(cfg))
 	} else {
-		slog.Info("Write operations disabled - create_order and cancel_order tools registered as disabled")
+		slog.Info("Write operations disabled - create_order, cancel_order, replace_order, execute_twap, iceberg_order, create_orders_batch, create_market_order and undo_last_action tools registered as disabled")
 		server.AddTool(createOrderTool, tools.HandleWriteOperationDisabled())
 		server.AddTool(cancelOrderTool, tools.HandleWriteOperationDisabled())
+		server.AddTool(replaceOrderTool, tools.HandleWriteOperationDisabled())
+		server.AddTool(executeTWAPTool, tools.HandleWriteOperationDisabled())
+		server.AddTool(icebergOrderTool, tools.HandleWriteOperationDisabled())
+		server.AddTool(createOrdersBatchTool, tools.HandleWriteOperationDisabled())
+		server.AddTool(createMarketOrderTool, tools.HandleWriteOperationDisabled())
+		server.AddTool(undoLastActionTool, tools.HandleWriteOperationDisabled())
 	}
 
 	listOrdersTool := tools.NewListOrdersTool()
 	server.AddTool(listOrdersTool, tools.HandleListOrders(cfg))
 
-	// Add transaction tools
+	reconcileOrdersTool := tools.NewReconcileOrdersTool()
+	server.AddTool(reconcileOrdersTool, tools.HandleReconcileOrders(cfg))
+
+	positionSizeTool := tools.NewSuggestPositionSizeTool()
+	server.AddTool(positionSizeTool, tools.HandleSuggestPositionSize(cfg))
+
+	quoteBasketTool := tools.NewQuoteBasketTool()
+	server.AddTool(quoteBasketTool, tools.HandleQuoteBasket(cfg))
+
+	// The trade journal is stored locally, so writing to it isn't a write operation
+	logTradeNoteTool := tools.NewLogTradeNoteTool()
+	server.AddTool(logTradeNoteTool, tools.HandleLogTradeNote(cfg))
+
+	getTradeJournalTool := tools.NewGetTradeJournalTool()
+	server.AddTool(getTradeJournalTool, tools.HandleGetTradeJournal(cfg))
+}
+
+// registerTransactionTools registers the account transaction tools
+func registerTransactionTools(server *mcpserver.MCPServer, cfg *config.Config) {
 	listTransactionsTool := tools.NewListTransactionsTool()
 	server.AddTool(listTransactionsTool, tools.HandleListTransactions(cfg))
 
 	getTransactionTool := tools.NewGetTransactionTool()
 	server.AddTool(getTransactionTool, tools.HandleGetTransaction(cfg))
 
-	// Add trades tools
-	listTradesTool := tools.NewListTradesTool()
-	server.AddTool(listTradesTool, tools.HandleListTrades(cfg))
+	importTradesTool := tools.NewImportTradesTool()
+	server.AddTool(importTradesTool, tools.HandleImportTrades(cfg))
+}
 
-	getTickersTool := tools.NewGetTickersTool()
-	server.AddTool(getTickersTool, tools.HandleGetTickers(cfg))
+// registerExportTools registers the tools that write files into client-granted roots
+func registerExportTools(server *mcpserver.MCPServer, cfg *config.Config) {
+	listRootsTool := tools.NewListRootsTool()
+	server.AddTool(listRootsTool, tools.HandleListRoots())
 
-	getCandlesTool := tools.NewGetCandlesTool()
-	server.AddTool(getCandlesTool, tools.HandleGetCandles(cfg))
+	exportTradesTool := tools.NewExportTradesTool()
+	server.AddTool(exportTradesTool, tools.HandleExportTrades(cfg))
 
-	getMarketsInfoTool := tools.NewGetMarketsInfoTool()
-	server.AddTool(getMarketsInfoTool, tools.HandleGetMarketsInfo(cfg))
+	exportPortfolioTool := tools.NewExportPortfolioTool()
+	server.AddTool(exportPortfolioTool, tools.HandleExportPortfolio(cfg))
 }
 
-// ServeStdio starts the server using the Stdio transport
-func ServeStdio(ctx context.Context, s *mcpserver.MCPServer) error {
-	stdioServer := mcpserver.NewStdioServer(s)
-
-	// Create context function that adds authentication
-	contextFunc := func(ctx context.Context) context.Context {
-		return ctx
+// registerWithdrawalTools registers the fiat withdrawal tools. Creating a withdrawal
+// needs both cfg.AllowWriteOperations and cfg.AllowWithdrawals; otherwise the tool
+// is registered with a handler explaining how to enable it.
+func registerWithdrawalTools(server *mcpserver.MCPServer, cfg *config.Config) {
+	createWithdrawalTool := tools.NewCreateFiatWithdrawalTool()
+	if cfg.AllowWriteOperations && cfg.AllowWithdrawals {
+		slog.Info("Withdrawals enabled - registering create_fiat_withdrawal tool")
+		server.AddTool(createWithdrawalTool, tools.HandleCreateFiatWithdrawal(cfg))
+	} else {
+		slog.Info("Withdrawals disabled - create_fiat_withdrawal tool registered as disabled")
+		server.AddTool(createWithdrawalTool, tools.HandleWithdrawalsDisabled())
 	}
 
-	stdioServer.SetContextFunc(contextFunc)
+	listWithdrawalsTool := tools.NewListFiatWithdrawalsTool()
+	server.AddTool(listWithdrawalsTool, tools.HandleListFiatWithdrawals(cfg))
+
+	getWithdrawalTool := tools.NewGetFiatWithdrawalTool()
+	server.AddTool(getWithdrawalTool, tools.HandleGetFiatWithdrawal(cfg))
+}
 
-	// Listen on stdin/stdout
-	return stdioServer.Listen(ctx, os.Stdin, os.Stdout)
+// registerReportTools registers the scheduled report tools
+func registerReportTools(server *mcpserver.MCPServer, cfg *config.Config) {
+	scheduleReportTool := tools.NewScheduleReportTool()
+	server.AddTool(scheduleReportTool, tools.HandleScheduleReport(cfg))
 }
 
 // ServeSSE starts the server using the SSE transport
 func ServeSSE(ctx context.Context, s *mcpserver.MCPServer, addr string) error {
-	sseServer := mcpserver.NewSSEServer(s)
+	srv := &http.Server{}
+	sseServer := mcpserver.NewSSEServer(s, mcpserver.WithHTTPServer(srv))
+	srv.Handler = compressHandler(sseServer)
 
 	slog.Info("SSE server listening on " + addr)
 	return serveHTTP(ctx, sseServer, addr)
@@ -138,12 +409,22 @@ func ServeSSE(ctx context.Context, s *mcpserver.MCPServer, addr string) error {
 
 // ServeStreamableHTTP starts the server using the Streamable HTTP transport
 func ServeStreamableHTTP(ctx context.Context, s *mcpserver.MCPServer, addr string) error {
-	httpServer := mcpserver.NewStreamableHTTPServer(s)
+	srv := &http.Server{}
+	httpServer := mcpserver.NewStreamableHTTPServer(s,
+		mcpserver.WithStreamableHTTPServer(srv),
+		mcpserver.WithEndpointPath(streamableHTTPPath),
+	)
+	mux := http.NewServeMux()
+	mux.Handle(streamableHTTPPath, httpServer)
+	srv.Handler = compressHandler(mux)
 
 	slog.Info("Streamable HTTP server listening on " + addr)
 	return serveHTTP(ctx, httpServer, addr)
 }
 
+// streamableHTTPPath is the endpoint the Streamable HTTP transport is served on
+const streamableHTTPPath = "/mcp"
+
 type httpServer interface {
 	Start(addr string) error
 	Shutdown(ctx context.Context) error
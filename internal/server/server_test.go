@@ -9,6 +9,7 @@ import (
 	"github.com/luno/luno-go"
 	"github.com/luno/luno-mcp/internal/config"
 	"github.com/luno/luno-mcp/internal/tools"
+	"github.com/luno/luno-mcp/sdk"
 	"github.com/mark3labs/mcp-go/mcp"
 	mcpserver "github.com/mark3labs/mcp-go/server"
 	"github.com/stretchr/testify/require"
@@ -20,7 +21,7 @@ const (
 	testServerMultiHooks = "test-server-multi-hooks"
 	testVersion1         = "1.0.0"
 	testVersion2         = "1.0.1"
-	testVersion3 = "1.0.2"
+	testVersion3         = "1.0.2"
 )
 
 func TestNewMCPServer(t *testing.T) {
@@ -38,7 +39,7 @@ func TestNewMCPServer(t *testing.T) {
 			version:           testVersion1,
 			hooks:             nil,
 			allowWriteOps:     false,
-			expectedToolCount: 12,
+			expectedToolCount: 49,
 		},
 		{
 			name:              "creates server with write ops enabled",
@@ -46,14 +47,14 @@ func TestNewMCPServer(t *testing.T) {
 			version:           testVersion1,
 			hooks:             nil,
 			allowWriteOps:     true,
-			expectedToolCount: 12,
+			expectedToolCount: 49,
 		},
 		{
 			name:              "creates server with single hook",
 			srvName:           testServerWithHooks,
 			version:           testVersion2,
 			allowWriteOps:     false,
-			expectedToolCount: 12,
+			expectedToolCount: 49,
 			hooks: []*mcpserver.Hooks{
 				func() *mcpserver.Hooks {
 					h := &mcpserver.Hooks{}
@@ -69,7 +70,7 @@ func TestNewMCPServer(t *testing.T) {
 			srvName:           testServerMultiHooks,
 			version:           testVersion3,
 			allowWriteOps:     false,
-			expectedToolCount: 12,
+			expectedToolCount: 49,
 			hooks: []*mcpserver.Hooks{
 				func() *mcpserver.Hooks { // Corresponds to original OnAnyHookFunc
 					h := &mcpserver.Hooks{}
@@ -154,7 +155,7 @@ func TestWriteOperationsControl(t *testing.T) {
 
 			// When disabled, verify the server routes calls to the disabled handler
 			if !tc.allowWriteOps {
-				for _, toolID := range []string{tools.CreateOrderToolID, tools.CancelOrderToolID} {
+				for _, toolID := range []string{tools.CreateOrderToolID, tools.CancelOrderToolID, tools.ReplaceOrderToolID} {
 					resp := callTool(t, srv, toolID)
 					require.Contains(t, resp, tools.ErrWriteOperationDisabled,
 						"%s: calling %s should return disabled error", tc.name, toolID)
@@ -197,6 +198,10 @@ func TestServeStreamableHTTPIntegration(t *testing.T) {
 	testServeHTTPTransport(t, "test-streamable-http-server", ServeStreamableHTTP)
 }
 
+func TestServeHTTPIntegration(t *testing.T) {
+	testServeHTTPTransport(t, "test-http-server", ServeHTTP)
+}
+
 func testServeHTTPTransport(t *testing.T, serverName string, serve func(context.Context, *mcpserver.MCPServer, string) error) {
 	t.Helper()
 
@@ -243,3 +248,217 @@ func testServeHTTPTransport(t *testing.T, serverName string, serve func(context.
 		})
 	}
 }
+
+func TestGetServerInfo(t *testing.T) {
+	tests := []struct {
+		name             string
+		allowWriteOps    bool
+		allowWithdrawals bool
+		expectedDisabled []string
+	}{
+		{
+			name:             "write tools reported as disabled",
+			allowWriteOps:    false,
+			expectedDisabled: []string{tools.CancelOrderToolID, tools.CreateFiatWithdrawalToolID, tools.CreateMarketOrderToolID, tools.CreateOrderToolID, tools.CreateOrdersBatchToolID, tools.ReplaceOrderToolID, tools.UndoLastActionToolID},
+		},
+		{
+			name:             "withdrawals disabled without write operations",
+			allowWithdrawals: true,
+			expectedDisabled: []string{tools.CancelOrderToolID, tools.CreateFiatWithdrawalToolID, tools.CreateMarketOrderToolID, tools.CreateOrderToolID, tools.CreateOrdersBatchToolID, tools.ReplaceOrderToolID, tools.UndoLastActionToolID},
+		},
+		{
+			name:             "withdrawals reported as disabled",
+			allowWriteOps:    true,
+			expectedDisabled: []string{tools.CreateFiatWithdrawalToolID},
+		},
+		{
+			name:             "write tools reported as enabled",
+			allowWriteOps:    true,
+			allowWithdrawals: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &config.Config{
+				LunoClient:           luno.NewClient(),
+				AllowWriteOperations: tc.allowWriteOps,
+				AllowWithdrawals:     tc.allowWithdrawals,
+				Domain:               "api.staging.luno.com",
+				Transport:            "stdio",
+			}
+			srv := NewMCPServer(testServerName, testVersion1, cfg)
+
+			msg := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":%q,"arguments":{}}}`, tools.GetServerInfoToolID)
+			b, err := json.Marshal(srv.HandleMessage(context.Background(), json.RawMessage(msg)))
+			require.NoError(t, err)
+
+			var parsed struct {
+				Result struct {
+					Content []struct {
+						Text string `json:"text"`
+					} `json:"content"`
+				} `json:"result"`
+			}
+			require.NoError(t, json.Unmarshal(b, &parsed))
+			require.NotEmpty(t, parsed.Result.Content)
+
+			var info tools.ServerInfo
+			require.NoError(t, json.Unmarshal([]byte(parsed.Result.Content[0].Text), &info))
+			require.Equal(t, testServerName, info.Name)
+			require.Equal(t, testVersion1, info.Version)
+			require.Equal(t, "stdio", info.Transport)
+			require.Equal(t, "api.staging.luno.com", info.LunoDomain)
+			require.Equal(t, tc.expectedDisabled, info.DisabledTools)
+			require.Equal(t, tc.allowWriteOps && tc.allowWithdrawals, info.WithdrawalsEnabled)
+			require.Contains(t, info.EnabledTools, tools.GetServerInfoToolID)
+			require.Len(t, info.EnabledTools, len(srv.ListTools())-len(tc.expectedDisabled))
+		})
+	}
+}
+
+func TestNewMCPServerWithToolsets(t *testing.T) {
+	tests := []struct {
+		name          string
+		toolsets      []string
+		expectedTools []string
+		expectErr     bool
+	}{
+		{
+			name:          "no toolsets registers only server info",
+			toolsets:      nil,
+			expectedTools: []string{tools.GetServerInfoToolID, tools.FetchMoreToolID, tools.GetUsageStatsToolID, tools.GetSessionStatsToolID, tools.ExportSessionToolID, tools.CheckUpdatesToolID},
+		},
+		{
+			name:     "account toolset",
+			toolsets: []string{ToolsetAccount},
+			expectedTools: []string{
+				tools.GetServerInfoToolID,
+				tools.FetchMoreToolID,
+				tools.GetUsageStatsToolID,
+				tools.GetSessionStatsToolID,
+				tools.ExportSessionToolID,
+				tools.CheckUpdatesToolID,
+				tools.GetBalancesToolID,
+				tools.AliasAccountToolID,
+				tools.GetAccountInfoToolID,
+				tools.ReconcileToolID,
+			},
+		},
+		{
+			name:     "transactions and exports toolsets",
+			toolsets: []string{ToolsetTransactions, ToolsetExports},
+			expectedTools: []string{
+				tools.GetServerInfoToolID,
+				tools.FetchMoreToolID,
+				tools.GetUsageStatsToolID,
+				tools.GetSessionStatsToolID,
+				tools.ExportSessionToolID,
+				tools.CheckUpdatesToolID,
+				tools.ListTransactionsToolID,
+				tools.GetTransactionToolID,
+				tools.ImportTradesToolID,
+				tools.ListRootsToolID,
+				tools.ExportTradesToolID,
+				tools.ExportPortfolioToolID,
+			},
+		},
+		{
+			name:      "unknown toolset",
+			toolsets:  []string{ToolsetMarket, "unknown"},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &config.Config{LunoClient: luno.NewClient()}
+
+			srv, err := NewMCPServerWithToolsets(testServerName, testVersion1, cfg, tc.toolsets)
+			if tc.expectErr {
+				require.Error(t, err)
+				require.Nil(t, srv)
+				return
+			}
+			require.NoError(t, err)
+
+			registered := srv.ListTools()
+			require.Len(t, registered, len(tc.expectedTools))
+			for _, name := range tc.expectedTools {
+				require.Contains(t, registered, name)
+			}
+		})
+	}
+}
+
+func TestRegisterToolsets(t *testing.T) {
+	cfg := &config.Config{LunoClient: luno.NewClient()}
+	srv, err := NewMCPServerWithToolsets(testServerName, testVersion1, cfg, nil)
+	require.NoError(t, err)
+
+	require.Error(t, RegisterToolsets(srv, cfg, "unknown"))
+	require.Len(t, srv.ListTools(), 6, "no toolset tools should be registered when a toolset is unknown")
+
+	require.NoError(t, RegisterToolsets(srv, cfg, AllToolsets...))
+	require.Len(t, srv.ListTools(), 49)
+}
+
+func TestRequestMetadataMiddleware(t *testing.T) {
+	var got sdk.RequestMetadata
+	handler := requestMetadataMiddleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		got, _ = sdk.RequestMetadataFromContext(ctx)
+		return mcp.NewToolResultText("ok"), nil
+	})
+
+	_, err := handler(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	first := got
+	require.NotEmpty(t, first.RequestID)
+	require.Empty(t, first.SessionID, "no session outside of a transport")
+
+	_, err = handler(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	require.NotEqual(t, first.RequestID, got.RequestID, "each call gets a new request ID")
+}
+
+func TestSessionOverlayMiddleware(t *testing.T) {
+	sessionClient := luno.NewClient()
+	cfg := &config.Config{LunoClient: luno.NewClient(), Sessions: config.NewSessionOverlays()}
+	cfg.Sessions.Set("session-1", config.Overlay{LunoClient: sessionClient, IsAuthenticated: true})
+	srv := NewMCPServer(testServerName, testVersion1, cfg)
+
+	var got *config.Config
+	handler := sessionOverlayMiddleware(cfg)(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		got = cfg.ForContext(ctx)
+		return mcp.NewToolResultText("ok"), nil
+	})
+
+	tests := []struct {
+		name         string
+		sessionID    string
+		expectClient sdk.LunoClient
+		expectAuth   bool
+	}{
+		{
+			name:         "session with overlay",
+			sessionID:    "session-1",
+			expectClient: sessionClient,
+			expectAuth:   true,
+		},
+		{
+			name:         "session without overlay",
+			sessionID:    "session-2",
+			expectClient: cfg.LunoClient,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := srv.WithContext(context.Background(), mcpserver.NewInProcessSession(tc.sessionID, nil))
+			_, err := handler(ctx, mcp.CallToolRequest{})
+			require.NoError(t, err)
+			require.Same(t, tc.expectClient, got.LunoClient)
+			require.Equal(t, tc.expectAuth, got.IsAuthenticated)
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// sessionOverlayMiddleware adds the calling session's config overlay, if any, to the
+// context of every tool call so that handlers pick it up through Config.ForContext
+func sessionOverlayMiddleware(cfg *config.Config) mcpserver.ToolHandlerMiddleware {
+	return func(next mcpserver.ToolHandlerFunc) mcpserver.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return next(withSessionOverlay(ctx, cfg), request)
+		}
+	}
+}
+
+// sessionOverlayResourceMiddleware is sessionOverlayMiddleware for resource reads
+func sessionOverlayResourceMiddleware(cfg *config.Config) mcpserver.ResourceHandlerMiddleware {
+	return func(next mcpserver.ResourceHandlerFunc) mcpserver.ResourceHandlerFunc {
+		return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			return next(withSessionOverlay(ctx, cfg), request)
+		}
+	}
+}
+
+// errSessionRevoked is returned for the calls of a session revoked through the admin API
+var errSessionRevoked = errors.New("this session has been revoked by the server operator")
+
+// revokedSessionMiddleware refuses the tool calls of revoked sessions
+func revokedSessionMiddleware(cfg *config.Config) mcpserver.ToolHandlerMiddleware {
+	return func(next mcpserver.ToolHandlerFunc) mcpserver.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if sessionRevoked(ctx, cfg) {
+				return mcp.NewToolResultError("This session has been revoked by the server operator and can't make further calls"), nil
+			}
+			return next(ctx, request)
+		}
+	}
+}
+
+// revokedSessionResourceMiddleware is revokedSessionMiddleware for resource reads
+func revokedSessionResourceMiddleware(cfg *config.Config) mcpserver.ResourceHandlerMiddleware {
+	return func(next mcpserver.ResourceHandlerFunc) mcpserver.ResourceHandlerFunc {
+		return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			if sessionRevoked(ctx, cfg) {
+				return nil, errSessionRevoked
+			}
+			return next(ctx, request)
+		}
+	}
+}
+
+func sessionRevoked(ctx context.Context, cfg *config.Config) bool {
+	session := mcpserver.ClientSessionFromContext(ctx)
+	return session != nil && cfg.Sessions.Revoked(session.SessionID())
+}
+
+func withSessionOverlay(ctx context.Context, cfg *config.Config) context.Context {
+	session := mcpserver.ClientSessionFromContext(ctx)
+	if session == nil {
+		return ctx
+	}
+	if o, ok := cfg.Sessions.Get(session.SessionID()); ok {
+		return config.ContextWithOverlay(ctx, o)
+	}
+	return ctx
+}
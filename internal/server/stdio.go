@@ -0,0 +1,195 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// stdinCloseGrace is how long tool calls still running when the client
+	// closes stdin have to finish before they are cancelled
+	stdinCloseGrace = 5 * time.Second
+
+	// parentCheckInterval is how often the stdio transport checks that the
+	// process that started it is still running
+	parentCheckInterval = time.Second
+
+	// writeRetries is how many times a write to stdout that would block is
+	// retried before the client is taken to be gone
+	writeRetries = 50
+)
+
+// The reasons a stdio session ends other than the server shutting down
+var (
+	errStdinClosed  = errors.New("client closed stdin")
+	errStdoutClosed = errors.New("client stopped reading stdout")
+	errParentExited = errors.New("parent process exited")
+)
+
+// ServeStdio starts the server using the Stdio transport. It returns nil once
+// ctx is cancelled or the client has gone away: when it closes stdin, stops
+// reading stdout or, on Unix, when the process that started the server exits,
+// so that a client restarting does not leave the server running. With a
+// pingInterval, the client is sent a ping that often once it has sent its
+// first message, which notices a client that has gone away between requests.
+func ServeStdio(ctx context.Context, s *mcpserver.MCPServer, pingInterval time.Duration) error {
+	// Writing to a closed stdout fails with EPIPE, rather than killing the
+	// process before it can cancel open orders
+	signal.Ignore(syscall.SIGPIPE)
+
+	return stdioTransport{
+		in:           os.Stdin,
+		out:          os.Stdout,
+		parent:       os.Getppid,
+		pingInterval: pingInterval,
+		closeGrace:   stdinCloseGrace,
+	}.serve(ctx, s)
+}
+
+// stdioTransport serves an MCP server over a pair of streams
+type stdioTransport struct {
+	in  io.Reader
+	out io.Writer
+	// parent returns the ID of the parent process, nil to not watch it
+	parent       func() int
+	pingInterval time.Duration
+	closeGrace   time.Duration
+}
+
+func (t stdioTransport) serve(ctx context.Context, s *mcpserver.MCPServer) error {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	in := &stdinReader{r: t.in, closed: func() {
+		// Let calls in progress finish and write their results first
+		time.AfterFunc(t.closeGrace, func() { cancel(errStdinClosed) })
+	}}
+	out := &stdoutWriter{w: t.out, closed: func() { cancel(errStdoutClosed) }}
+	if t.parent != nil {
+		go watchParent(ctx, t.parent, func() { cancel(errParentExited) })
+	}
+	if t.pingInterval > 0 {
+		go pingClient(ctx, out, in, t.pingInterval)
+	}
+
+	err := mcpserver.NewStdioServer(s).Listen(ctx, in, out)
+	if ctx.Err() != nil {
+		if cause := context.Cause(ctx); !errors.Is(cause, context.Canceled) {
+			slog.Info("Stopping stdio server", slog.String("reason", cause.Error()))
+		}
+		return nil
+	}
+	return err
+}
+
+// stdinReader reads the client's messages, calling closed once when the client
+// closes stdin or it can no longer be read
+type stdinReader struct {
+	r       io.Reader
+	closed  func()
+	once    sync.Once
+	started atomic.Bool
+}
+
+func (r *stdinReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.started.Store(true)
+	}
+	if err != nil {
+		r.once.Do(r.closed)
+	}
+	return n, err
+}
+
+// stdoutWriter writes messages to the client whole, one at a time, retrying
+// writes that are cut short. It calls closed once when stdout can no longer be
+// written to.
+type stdoutWriter struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closed func()
+	once   sync.Once
+}
+
+func (w *stdoutWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	written, retries := 0, 0
+	for written < len(p) {
+		n, err := w.w.Write(p[written:])
+		written += n
+		switch {
+		case err == nil && n == 0:
+			err = io.ErrShortWrite
+		case errors.Is(err, syscall.EAGAIN) && retries < writeRetries:
+			// A non-blocking stdout is full; wait for the client to read it
+			retries++
+			time.Sleep(time.Duration(retries) * time.Millisecond)
+			continue
+		}
+		if err != nil {
+			w.once.Do(w.closed)
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// watchParent calls exited once the parent process has exited. When it does,
+// the process is adopted by another, so its parent process ID changes.
+func watchParent(ctx context.Context, parent func() int, exited func()) {
+	ppid := parent()
+	if ppid <= 1 {
+		// Started by init, or the parent is unknown
+		return
+	}
+	ticker := time.NewTicker(parentCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if parent() != ppid {
+				exited()
+				return
+			}
+		}
+	}
+}
+
+// pingClient sends the client a ping every interval once it has sent a
+// message. Clients answer pings, and the answers are ignored; a ping that
+// can't be written ends the session through out.
+func pingClient(ctx context.Context, out io.Writer, in *stdinReader, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for id := 1; ; {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if !in.started.Load() {
+			continue
+		}
+		ping := fmt.Sprintf(`{"jsonrpc":"2.0","id":"luno-mcp-ping-%d","method":"ping"}`+"\n", id)
+		if _, err := out.Write([]byte(ping)); err != nil {
+			return
+		}
+		id++
+	}
+}
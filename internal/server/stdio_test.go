@@ -0,0 +1,214 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const stdioInitialize = `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}` + "\n"
+
+// stdioClient drives a stdioTransport through pipes
+type stdioClient struct {
+	stdin  *io.PipeWriter
+	lines  chan string
+	served chan error
+}
+
+func startStdio(t *testing.T, s *mcpserver.MCPServer, transport stdioTransport) *stdioClient {
+	t.Helper()
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	if transport.in == nil {
+		transport.in = inR
+	}
+	if transport.out == nil {
+		transport.out = outW
+	}
+	c := &stdioClient{stdin: inW, lines: make(chan string, 100), served: make(chan error, 1)}
+	go func() {
+		scanner := bufio.NewScanner(outR)
+		for scanner.Scan() {
+			c.lines <- scanner.Text()
+		}
+	}()
+	go func() {
+		c.served <- transport.serve(context.Background(), s)
+		_ = outW.Close()
+	}()
+	t.Cleanup(func() {
+		_ = inW.Close()
+		_ = outR.Close()
+	})
+	return c
+}
+
+func (c *stdioClient) send(t *testing.T, msg string) {
+	t.Helper()
+	_, err := io.WriteString(c.stdin, msg)
+	require.NoError(t, err)
+}
+
+// next returns the next line written by the server containing s
+func (c *stdioClient) next(t *testing.T, s string) string {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case line := <-c.lines:
+			if strings.Contains(line, s) {
+				return line
+			}
+		case <-timeout:
+			t.Fatalf("no line containing %s", s)
+		}
+	}
+}
+
+func (c *stdioClient) waitServed(t *testing.T) error {
+	t.Helper()
+	select {
+	case err := <-c.served:
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatal("the server is still running")
+		return nil
+	}
+}
+
+// blockingServer has a tool that runs until its call is cancelled. started is
+// closed once it is called.
+func blockingServer(cancelled *atomic.Bool) (s *mcpserver.MCPServer, started chan struct{}) {
+	s = mcpserver.NewMCPServer(testServerName, testVersion1)
+	started = make(chan struct{})
+	s.AddTool(mcp.NewTool("block"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		close(started)
+		<-ctx.Done()
+		cancelled.Store(true)
+		return nil, ctx.Err()
+	})
+	return s, started
+}
+
+const stdioCallBlock = `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"block","arguments":{}}}` + "\n"
+
+func TestStdioStdinClosed(t *testing.T) {
+	var cancelled atomic.Bool
+	s, started := blockingServer(&cancelled)
+	c := startStdio(t, s, stdioTransport{closeGrace: 10 * time.Millisecond})
+	c.send(t, stdioInitialize)
+	c.next(t, `"id":1`)
+	c.send(t, stdioCallBlock)
+	<-started
+
+	require.NoError(t, c.stdin.Close())
+	require.NoError(t, c.waitServed(t))
+	assert.True(t, cancelled.Load(), "calls still running are cancelled after the grace period")
+}
+
+func TestStdioStdoutClosed(t *testing.T) {
+	var cancelled atomic.Bool
+	out := &scriptedWriter{}
+	s, started := blockingServer(&cancelled)
+	c := startStdio(t, s, stdioTransport{out: out, closeGrace: time.Hour})
+	c.send(t, stdioInitialize)
+	c.send(t, stdioCallBlock)
+	<-started
+
+	// The client stops reading, so the answer to its ping can't be written
+	out.fail(syscall.EPIPE)
+	c.send(t, `{"jsonrpc":"2.0","id":3,"method":"ping"}`+"\n")
+
+	require.NoError(t, c.waitServed(t), "stdin is still open")
+	assert.True(t, cancelled.Load())
+}
+
+func TestStdioParentExited(t *testing.T) {
+	var ppid atomic.Int64
+	ppid.Store(4242)
+	c := startStdio(t, mcpserver.NewMCPServer(testServerName, testVersion1), stdioTransport{
+		parent:     func() int { return int(ppid.Load()) },
+		closeGrace: time.Hour,
+	})
+	c.send(t, stdioInitialize)
+	c.next(t, `"id":1`)
+
+	// Adopted by init
+	ppid.Store(1)
+	require.NoError(t, c.waitServed(t))
+}
+
+func TestStdioPing(t *testing.T) {
+	c := startStdio(t, mcpserver.NewMCPServer(testServerName, testVersion1), stdioTransport{
+		pingInterval: 10 * time.Millisecond,
+		closeGrace:   time.Millisecond,
+	})
+	time.Sleep(30 * time.Millisecond)
+	assert.Empty(t, c.lines, "no pings before the client has written")
+
+	c.send(t, stdioInitialize)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":"luno-mcp-ping-1","method":"ping"}`, c.next(t, `"method":"ping"`))
+	// The client's answer is not answered in turn
+	c.send(t, `{"jsonrpc":"2.0","id":"luno-mcp-ping-1","result":{}}`+"\n")
+	c.next(t, "luno-mcp-ping-2")
+
+	require.NoError(t, c.stdin.Close())
+	require.NoError(t, c.waitServed(t))
+}
+
+// scriptedWriter returns errs in turn, writing at most three bytes a call
+type scriptedWriter struct {
+	mu      sync.Mutex
+	errs    []error
+	written strings.Builder
+}
+
+func (w *scriptedWriter) fail(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.errs = append(w.errs, err)
+}
+
+func (w *scriptedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.errs) > 0 {
+		err := w.errs[0]
+		w.errs = w.errs[1:]
+		if err != nil {
+			return 0, err
+		}
+	}
+	n := min(len(p), 3)
+	w.written.Write(p[:n])
+	return n, nil
+}
+
+func TestStdoutWriter(t *testing.T) {
+	closed := 0
+	out := &scriptedWriter{errs: []error{nil, syscall.EAGAIN, nil}}
+	w := &stdoutWriter{w: out, closed: func() { closed++ }}
+
+	n, err := w.Write([]byte(`{"jsonrpc":"2.0"}` + "\n"))
+	require.NoError(t, err)
+	assert.Equal(t, 18, n)
+	assert.Equal(t, `{"jsonrpc":"2.0"}`+"\n", out.written.String(), "short writes and writes that would block are retried")
+	assert.Zero(t, closed)
+
+	out.fail(syscall.EPIPE)
+	_, err = w.Write([]byte("{}\n"))
+	assert.ErrorIs(t, err, syscall.EPIPE)
+	_, _ = w.Write([]byte("{}\n"))
+	assert.Equal(t, 1, closed)
+}
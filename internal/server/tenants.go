@@ -0,0 +1,63 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/tenants"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// tenantFetchTimeout bounds fetching a tenant's credentials when its session starts
+const tenantFetchTimeout = 15 * time.Second
+
+// addTenantHooks gives each session the Luno API credentials of the tenant
+// named in its initialize request's cfg.TenantHeader header, fetched from
+// cfg.Tenants. Sessions without a tenant, or whose credentials can't be
+// fetched, get an unauthenticated client: they never fall back to the
+// server's own credentials.
+func addTenantHooks(hooks *mcpserver.Hooks, cfg *config.Config) {
+	hooks.AddAfterInitialize(func(ctx context.Context, _ any, req *mcp.InitializeRequest, _ *mcp.InitializeResult) {
+		session := mcpserver.ClientSessionFromContext(ctx)
+		if session == nil || cfg.Sessions == nil {
+			return
+		}
+		sessionID := session.SessionID()
+		log := slog.With(slog.String("session_id", sessionID))
+
+		var creds tenants.Credentials
+		tenant := req.Header.Get(cfg.TenantHeader)
+		if tenant == "" {
+			log.Warn("Session has no tenant; it can only use public tools", slog.String("header", cfg.TenantHeader))
+		} else {
+			fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), tenantFetchTimeout)
+			var err error
+			creds, err = cfg.Tenants.Credentials(fetchCtx, tenant)
+			cancel()
+			if err != nil {
+				log.Warn("Failed to fetch tenant credentials; the session can only use public tools",
+					slog.String("tenant", tenant), slog.Any("error", err))
+			}
+		}
+
+		client, err := cfg.SessionClient(creds.KeyID, creds.Secret)
+		if err != nil {
+			log.Warn("Failed to create a Luno client for the tenant", slog.String("tenant", tenant), slog.Any("error", err))
+			creds = tenants.Credentials{}
+			client = luno.NewClient()
+		}
+		cfg.Sessions.Set(sessionID, config.Overlay{LunoClient: client, IsAuthenticated: creds.KeyID != "", Tenant: tenant})
+		if creds.KeyID != "" {
+			log.Info("Session using tenant credentials", slog.String("tenant", tenant))
+		}
+	})
+	hooks.AddOnUnregisterSession(func(_ context.Context, session mcpserver.ClientSession) {
+		if cfg.Sessions != nil {
+			cfg.Sessions.Delete(session.SessionID())
+		}
+	})
+}
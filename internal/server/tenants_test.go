@@ -0,0 +1,69 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/tenants"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantHooks(t *testing.T) {
+	t.Setenv(config.EnvLunoAPIKeyID, "")
+	t.Setenv(config.EnvLunoAPIKeySecret, "")
+	provider := tenants.ProviderFunc(func(_ context.Context, tenant string) (tenants.Credentials, error) {
+		if tenant != "acme" {
+			return tenants.Credentials{}, fmt.Errorf("%w %s", tenants.ErrUnknownTenant, tenant)
+		}
+		return tenants.Credentials{KeyID: "acme-key-id", Secret: "acme-secret"}, nil
+	})
+	cfg, err := config.Load(config.WithCredentialsProvider(provider))
+	require.NoError(t, err)
+	cfg.Sessions = config.NewSessionOverlays()
+
+	hooks := &mcpserver.Hooks{}
+	addTenantHooks(hooks, cfg)
+	srv := mcpserver.NewMCPServer(testServerName, testVersion1)
+
+	testCases := []struct {
+		name              string
+		tenant            string
+		wantAuthenticated bool
+	}{
+		{name: "known tenant", tenant: "acme", wantAuthenticated: true},
+		{name: "unknown tenant", tenant: "globex"},
+		{name: "invalid tenant", tenant: "../acme"},
+		{name: "no tenant"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			session := mcpserver.NewInProcessSession("session-"+tc.name, nil)
+			ctx := srv.WithContext(context.Background(), session)
+			req := &mcp.InitializeRequest{Header: http.Header{}}
+			if tc.tenant != "" {
+				req.Header.Set(tenants.DefaultHeader, tc.tenant)
+			}
+			for _, hook := range hooks.OnAfterInitialize {
+				hook(ctx, 1, req, &mcp.InitializeResult{})
+			}
+
+			o, ok := cfg.Sessions.Get(session.SessionID())
+			require.True(t, ok, "every session gets an overlay, so that none uses the server's credentials")
+			assert.NotNil(t, o.LunoClient)
+			assert.Equal(t, tc.wantAuthenticated, o.IsAuthenticated)
+			assert.Equal(t, tc.tenant, o.Tenant)
+
+			for _, hook := range hooks.OnUnregisterSession {
+				hook(ctx, session)
+			}
+			_, ok = cfg.Sessions.Get(session.SessionID())
+			assert.False(t, ok)
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/tools"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// tradingHoursMiddleware refuses calls to trading tools outside the windows in
+// cfg.TradingHours, except those that only cancel or check on orders. It runs
+// inside auditMiddleware, so refused calls are in the audit log with the
+// policy error.
+func tradingHoursMiddleware(cfg *config.Config) mcpserver.ToolHandlerMiddleware {
+	return func(next mcpserver.ToolHandlerFunc) mcpserver.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			now := time.Now()
+			if cfg.TradingHours.Allowed(now) || !tools.IsTradingCall(request.Params.Name, request.GetArguments()) {
+				return next(ctx, request)
+			}
+			slog.WarnContext(ctx, "Tool call refused outside trading windows",
+				slog.String("tool", request.Params.Name), slog.String("trading_windows", cfg.TradingHours.String()))
+			return tradingHoursResult(cfg, request.Params.Name, now), nil
+		}
+	}
+}
+
+// tradingHoursResult is the policy error for a trading tool called outside
+// the trading windows
+func tradingHoursResult(cfg *config.Config, tool string, now time.Time) *mcp.CallToolResult {
+	text := fmt.Sprintf("Trading policy: %s is not allowed now. Trading tools may only be called in these windows: %s.",
+		tool, cfg.TradingHours)
+	structured := map[string]any{
+		"error":           "trading_policy",
+		"tool":            tool,
+		"trading_windows": cfg.TradingHours.String(),
+	}
+	if next, ok := cfg.TradingHours.NextOpen(now); ok {
+		text += fmt.Sprintf(" The next window opens at %s.", next.Format(time.RFC3339))
+		structured["next_open"] = next.Format(time.RFC3339)
+	}
+	result := mcp.NewToolResultError(text + " Do not retry until then, and do not work around the policy with other tools.")
+	result.StructuredContent = structured
+	return result
+}
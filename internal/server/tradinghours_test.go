@@ -0,0 +1,66 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-mcp/internal/audit"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/tradinghours"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTradingHoursMiddleware(t *testing.T) {
+	// A window starting in two hours, so that now is outside it
+	start := time.Now().UTC().Add(2 * time.Hour)
+	spec := start.Format("15:04") + "-" + start.Add(time.Hour).Format("15:04")
+	policy, err := tradinghours.Parse(spec, time.UTC)
+	require.NoError(t, err)
+	cfg := &config.Config{Audit: audit.NewLog(10), TradingHours: policy}
+
+	called := 0
+	handler := auditMiddleware(cfg)(tradingHoursMiddleware(cfg)(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		called++
+		return mcp.NewToolResultText("ok"), nil
+	}))
+	call := func(name string, args ...map[string]any) *mcp.CallToolResult {
+		req := mcp.CallToolRequest{}
+		req.Params.Name = name
+		if len(args) > 0 {
+			req.Params.Arguments = args[0]
+		}
+		result, err := handler(context.Background(), req)
+		require.NoError(t, err)
+		return result
+	}
+
+	result := call("create_order")
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "Trading policy: create_order is not allowed now. Trading tools may only be called in these windows: "+spec+" (UTC).")
+	structured := result.StructuredContent.(map[string]any)
+	assert.Equal(t, "trading_policy", structured["error"])
+	assert.Equal(t, start.Truncate(time.Minute).Format(time.RFC3339), structured["next_open"])
+	assert.Equal(t, 0, called)
+
+	assert.False(t, call("get_ticker").IsError, "only trading tools are restricted")
+	assert.Equal(t, 1, called)
+
+	entries, _ := cfg.Audit.Recent("", 0, 10)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "create_order", entries[1].Tool)
+	assert.Contains(t, entries[1].Error, "Trading policy: create_order is not allowed now")
+
+	// Calls that only cancel or check on orders are allowed
+	assert.False(t, call("cancel_order", map[string]any{"order_id": "BXMC2CJ7HNB88U4"}).IsError)
+	assert.False(t, call("execute_twap", map[string]any{"action": "status", "id": "twap-1"}).IsError)
+	assert.False(t, call("iceberg_order", map[string]any{"action": "cancel", "id": "iceberg-1"}).IsError)
+	assert.Equal(t, 4, called)
+	assert.True(t, call("execute_twap", map[string]any{"pair": "XBTZAR", "type": "BUY", "volume": "0.1"}).IsError, "starting is restricted without an action")
+	assert.Equal(t, 4, called)
+
+	cfg.TradingHours = nil
+	assert.False(t, call("create_order").IsError, "trading is allowed at any time without windows")
+}
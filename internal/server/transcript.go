@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/transcript"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// transcriptMiddleware records each tool call, with the result the client
+// receives, in the transcript of its session for export_session. Like
+// sessionStatsMiddleware, it runs just inside requestMetadataMiddleware.
+func transcriptMiddleware(cfg *config.Config) mcpserver.ToolHandlerMiddleware {
+	return func(next mcpserver.ToolHandlerFunc) mcpserver.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if cfg.Transcripts == nil {
+				return next(ctx, request)
+			}
+			md, ok := sdk.RequestMetadataFromContext(ctx)
+			if !ok || md.SessionID == "" {
+				return next(ctx, request)
+			}
+
+			start := time.Now()
+			result, err := next(ctx, request)
+			call := transcript.Call{
+				Time:       start,
+				Tool:       request.Params.Name,
+				DurationMS: time.Since(start).Milliseconds(),
+			}
+			if args := request.GetArguments(); len(args) > 0 {
+				if b, marshalErr := json.Marshal(args); marshalErr == nil {
+					call.Arguments = string(b)
+				}
+			}
+			switch {
+			case err != nil:
+				call.Result, call.IsError = err.Error(), true
+			case result != nil:
+				call.Result, call.IsError = transcriptText(result), result.IsError
+			}
+			cfg.Transcripts.Record(md.SessionID, call)
+			return result, err
+		}
+	}
+}
+
+// transcriptText joins the text in result, which is what the client shows
+func transcriptText(result *mcp.CallToolResult) string {
+	var parts []string
+	for _, c := range result.Content {
+		switch c := c.(type) {
+		case mcp.TextContent:
+			parts = append(parts, c.Text)
+		case mcp.EmbeddedResource:
+			if text, ok := c.Resource.(mcp.TextResourceContents); ok {
+				parts = append(parts, text.Text)
+			}
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/redact"
+	"github.com/luno/luno-mcp/internal/transcript"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranscriptMiddleware(t *testing.T) {
+	redact.Register("transcript-secret-value")
+	cfg := &config.Config{Transcripts: transcript.NewStore()}
+	handler := transcriptMiddleware(cfg)(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		switch request.Params.Name {
+		case "get_ticker":
+			return mcp.NewToolResultError("Failed to get ticker: not found"), nil
+		case "cancel_order":
+			return nil, errors.New("connection reset")
+		}
+		return mcp.NewToolResultText("key transcript-secret-value"), nil
+	})
+
+	call := func(sessionID, name string, args map[string]any) {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Name = name
+		req.Params.Arguments = args
+		ctx := sdk.ContextWithRequestMetadata(context.Background(), sdk.RequestMetadata{SessionID: sessionID})
+		_, _ = handler(ctx, req)
+	}
+	call("s1", "list_trades", map[string]any{"pair": "XBTZAR"})
+	call("s1", "get_ticker", nil)
+	call("s1", "cancel_order", nil)
+	call("s2", "list_trades", nil)
+	call("", "list_trades", nil)
+
+	got, ok := cfg.Transcripts.Get("s1")
+	require.True(t, ok)
+	require.Len(t, got.Calls, 3)
+	assert.Equal(t, `{"pair":"XBTZAR"}`, got.Calls[0].Arguments)
+	assert.NotContains(t, got.Calls[0].Result, "transcript-secret-value")
+	assert.False(t, got.Calls[0].IsError)
+	assert.Equal(t, "Failed to get ticker: not found", got.Calls[1].Result)
+	assert.True(t, got.Calls[1].IsError)
+	assert.Equal(t, "connection reset", got.Calls[2].Result)
+	assert.True(t, got.Calls[2].IsError)
+
+	got, _ = cfg.Transcripts.Get("s2")
+	assert.Len(t, got.Calls, 1)
+	_, ok = cfg.Transcripts.Get("")
+	assert.False(t, ok, "calls without a session aren't recorded")
+}
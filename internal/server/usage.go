@@ -0,0 +1,114 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/telemetry"
+	"github.com/luno/luno-mcp/internal/usage"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// usageMiddleware measures the size of every tool call and its result in
+// cfg.Usage. It runs inside responseBudgetMiddleware so that it sees results
+// before they are truncated.
+func usageMiddleware(cfg *config.Config) mcpserver.ToolHandlerMiddleware {
+	return func(next mcpserver.ToolHandlerFunc) mcpserver.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			result, err := next(ctx, request)
+			if cfg.Usage == nil {
+				return result, err
+			}
+
+			call := usage.Call{
+				Tool:          request.Params.Name,
+				ResponseBytes: resultBytes(result),
+				Error:         err != nil || (result != nil && result.IsError),
+			}
+			if args := request.GetArguments(); len(args) > 0 {
+				if b, marshalErr := json.Marshal(args); marshalErr == nil {
+					call.RequestBytes = int64(len(b))
+				}
+			}
+			maxBytes := cfg.ForContext(ctx).MaxResponseBytes
+			call.Truncated = maxBytes > 0 && call.ResponseBytes > int64(maxBytes)
+			cfg.Usage.Record(call)
+
+			slog.DebugContext(ctx, "Tool call size", "tool", call.Tool,
+				"request_bytes", call.RequestBytes, "response_bytes", call.ResponseBytes,
+				"estimated_tokens", usage.EstimateTokens(call.ResponseBytes))
+			return result, err
+		}
+	}
+}
+
+// sessionStatsMiddleware adds the stats of the calling MCP session to the
+// context, for the code below to record Luno API calls, cache lookups and
+// limits hit, and records the tool call with the result the client receives.
+// It runs just inside requestMetadataMiddleware, which provides the session ID.
+func sessionStatsMiddleware(cfg *config.Config) mcpserver.ToolHandlerMiddleware {
+	return func(next mcpserver.ToolHandlerFunc) mcpserver.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if cfg.Usage == nil {
+				return next(ctx, request)
+			}
+			md, _ := sdk.RequestMetadataFromContext(ctx)
+			session := cfg.Usage.Session(md.SessionID)
+			result, err := next(usage.ContextWithSession(ctx, session), request)
+			session.ToolCall(request.Params.Name, resultBytes(result), err != nil || (result != nil && result.IsError))
+			return result, err
+		}
+	}
+}
+
+// telemetryMiddleware counts tool calls, and the categories of their errors,
+// for cfg.Telemetry when the operator has opted in. It runs outside
+// concurrencyMiddleware so that calls turned away by the limits are counted.
+func telemetryMiddleware(cfg *config.Config) mcpserver.ToolHandlerMiddleware {
+	return func(next mcpserver.ToolHandlerFunc) mcpserver.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if cfg.Telemetry == nil {
+				return next(ctx, request)
+			}
+			call := &telemetry.Call{}
+			result, err := next(telemetry.ContextWithCall(ctx, call), request)
+			isError := result != nil && result.IsError
+			cfg.Telemetry.Record(request.Params.Name, call.Categorise(err, isError, isError && validationFailed(result)))
+			return result, err
+		}
+	}
+}
+
+// validationFailed reports whether result refuses a call for its arguments
+func validationFailed(result *mcp.CallToolResult) bool {
+	content, ok := result.StructuredContent.(map[string]any)
+	if !ok {
+		return false
+	}
+	_, ok = content["validation_errors"]
+	return ok
+}
+
+// resultBytes is the size of the text in result, which is what ends up in the
+// model's context
+func resultBytes(result *mcp.CallToolResult) int64 {
+	if result == nil {
+		return 0
+	}
+	var n int64
+	for _, c := range result.Content {
+		switch c := c.(type) {
+		case mcp.TextContent:
+			n += int64(len(c.Text))
+		case mcp.EmbeddedResource:
+			if text, ok := c.Resource.(mcp.TextResourceContents); ok {
+				n += int64(len(text.Text))
+			}
+		}
+	}
+	return n
+}
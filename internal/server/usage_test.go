@@ -0,0 +1,138 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/telemetry"
+	"github.com/luno/luno-mcp/internal/usage"
+	"github.com/luno/luno-mcp/internal/validate"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageMiddleware(t *testing.T) {
+	cfg := &config.Config{MaxResponseBytes: 100, Usage: usage.NewTracker()}
+
+	call := func(name string, args map[string]any, result *mcp.CallToolResult, err error) {
+		t.Helper()
+		handler := usageMiddleware(cfg)(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return result, err
+		})
+		req := mcp.CallToolRequest{}
+		req.Params.Name = name
+		req.Params.Arguments = args
+		gotResult, gotErr := handler(context.Background(), req)
+		assert.Equal(t, result, gotResult)
+		assert.Equal(t, err, gotErr)
+	}
+
+	call("list_trades", map[string]any{"pair": "XBTZAR"}, mcp.NewToolResultText(strings.Repeat("x", 250)), nil)
+	call("list_trades", nil, mcp.NewToolResultText(strings.Repeat("x", 50)), nil)
+	call("get_ticker", nil, mcp.NewToolResultError("Failed to get ticker: not found"), nil)
+	call("get_ticker", nil, nil, errors.New("connection reset"))
+
+	stats, _ := cfg.Usage.Stats()
+	require.Len(t, stats, 2)
+	assert.Equal(t, usage.ToolStats{
+		Tool:               "list_trades",
+		Calls:              2,
+		RequestBytes:       int64(len(`{"pair":"XBTZAR"}`)),
+		ResponseBytes:      300,
+		AvgResponseBytes:   150,
+		MaxResponseBytes:   250,
+		EstimatedTokens:    75,
+		AvgEstimatedTokens: 38,
+		MaxEstimatedTokens: 63,
+		Truncated:          1,
+	}, stats[0])
+	assert.Equal(t, int64(2), stats[1].Errors)
+	assert.Equal(t, int64(len("Failed to get ticker: not found")), stats[1].ResponseBytes)
+}
+
+func TestUsageMiddlewareDisabled(t *testing.T) {
+	handler := usageMiddleware(&config.Config{})(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	})
+	result, err := handler(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, mcp.NewToolResultText("ok"), result)
+}
+
+func TestSessionStatsMiddleware(t *testing.T) {
+	cfg := &config.Config{Usage: usage.NewTracker()}
+	handler := sessionStatsMiddleware(cfg)(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		session := usage.SessionFromContext(ctx)
+		require.NotNil(t, session)
+		session.CacheLookup(true)
+		if request.Params.Name == "get_ticker" {
+			return mcp.NewToolResultError("Failed to get ticker: not found"), nil
+		}
+		return mcp.NewToolResultText("ok"), nil
+	})
+
+	call := func(sessionID, name string) {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Name = name
+		ctx := sdk.ContextWithRequestMetadata(context.Background(), sdk.RequestMetadata{SessionID: sessionID})
+		_, err := handler(ctx, req)
+		require.NoError(t, err)
+	}
+	call("s1", "list_trades")
+	call("s1", "get_ticker")
+	call("s2", "list_trades")
+
+	got := cfg.Usage.Session("s1").Stats()
+	assert.Equal(t, map[string]int64{"list_trades": 1, "get_ticker": 1}, got.ToolCalls)
+	assert.Equal(t, int64(1), got.Errors)
+	assert.Equal(t, int64(len("ok")+len("Failed to get ticker: not found")), got.ResponseBytes)
+	assert.Equal(t, int64(2), got.Cache.Hits)
+	assert.Equal(t, int64(1), cfg.Usage.Session("s2").Stats().Calls)
+}
+
+func TestTelemetryMiddleware(t *testing.T) {
+	var reports []telemetry.Report
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var report telemetry.Report
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&report))
+		reports = append(reports, report)
+	}))
+	defer collector.Close()
+	reporter, err := telemetry.New(collector.URL, testVersion1)
+	require.NoError(t, err)
+	cfg := &config.Config{Telemetry: reporter}
+
+	call := func(name string, handler mcpserver.ToolHandlerFunc) {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Name = name
+		_, _ = telemetryMiddleware(cfg)(handler)(context.Background(), req)
+	}
+	call("get_ticker", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	})
+	call("get_ticker", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		telemetry.CallFromContext(ctx).LunoResponse(http.StatusServiceUnavailable)
+		return mcp.NewToolResultError("Failed to get ticker: 503"), nil
+	})
+	call("create_order", func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return validate.Errors{{Param: "volume", Message: "is required"}}.Result(), nil
+	})
+
+	reporter.Close()
+	require.Len(t, reports, 1)
+	assert.Equal(t, map[string]telemetry.ToolCounts{
+		"get_ticker":   {Calls: 2, Errors: map[telemetry.Category]int64{telemetry.LunoUnavailable: 1}},
+		"create_order": {Calls: 1, Errors: map[telemetry.Category]int64{telemetry.InvalidArguments: 1}},
+	}, reports[0].Tools)
+}
@@ -0,0 +1,96 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+)
+
+// Call collects what is needed to categorise a tool call's error: the status
+// of the last failed Luno API call made for it, whether it was held back by
+// the concurrency limits and whether it panicked. Its methods do nothing on a
+// nil Call.
+type Call struct {
+	lunoStatus atomic.Int64
+	lunoFailed atomic.Bool
+	rejected   atomic.Bool
+	panicked   atomic.Bool
+}
+
+// Panicked records that the tool call panicked
+func (c *Call) Panicked() {
+	if c == nil {
+		return
+	}
+	c.panicked.Store(true)
+}
+
+// Rejected records that the tool call found no free slot under the
+// concurrency limits
+func (c *Call) Rejected() {
+	if c == nil {
+		return
+	}
+	c.rejected.Store(true)
+}
+
+// LunoResponse records the status of a Luno API call made for the tool call.
+// A status of zero means no response arrived.
+func (c *Call) LunoResponse(status int) {
+	if c == nil || (status > 0 && status < 400) {
+		return
+	}
+	c.lunoStatus.Store(int64(status))
+	c.lunoFailed.Store(true)
+}
+
+// Categorise returns the category of the error a tool call ended with: err if
+// the handler failed, or the Luno API call that failed when the result is an
+// error. invalid reports whether the call was refused for its arguments.
+func (c *Call) Categorise(err error, isError, invalid bool) Category {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return Cancelled
+	case errors.Is(err, context.DeadlineExceeded):
+		return Timeout
+	case err == nil && !isError:
+		return ""
+	case c != nil && c.panicked.Load():
+		return Panic
+	case invalid:
+		return InvalidArguments
+	case c == nil:
+		return Other
+	case c.rejected.Load():
+		return Busy
+	case !c.lunoFailed.Load():
+		return Other
+	}
+	switch status := int(c.lunoStatus.Load()); {
+	case status == 0:
+		return Network
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return LunoAuth
+	case status == http.StatusTooManyRequests:
+		return LunoRateLimited
+	case status >= 500:
+		return LunoUnavailable
+	default:
+		return LunoRejected
+	}
+}
+
+type callKey struct{}
+
+// ContextWithCall returns a copy of ctx carrying c, for the Luno client to
+// record to
+func ContextWithCall(ctx context.Context, c *Call) context.Context {
+	return context.WithValue(ctx, callKey{}, c)
+}
+
+// CallFromContext returns the call stored in ctx, or nil
+func CallFromContext(ctx context.Context) *Call {
+	c, _ := ctx.Value(callKey{}).(*Call)
+	return c
+}
@@ -0,0 +1,238 @@
+// Package telemetry reports how the server's tools are used to the
+// maintainers, when the operator opts in by setting a telemetry URL. Reports
+// hold only how many times each tool was called and the categories of the
+// errors it returned: never arguments, amounts, pairs, account or order IDs,
+// session IDs or anything else that identifies a user or deployment.
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultInterval is how often a report is sent
+	DefaultInterval = 24 * time.Hour
+
+	// sendTimeout bounds a single report, including the last one at shutdown
+	sendTimeout = 5 * time.Second
+
+	// schemaVersion is the version of the Report format
+	schemaVersion = 1
+)
+
+// Category is the kind of error a tool call ended with
+type Category string
+
+// The error categories reported. Errors are only ever reported by category,
+// never by message.
+const (
+	InvalidArguments Category = "invalid_arguments"
+	// LunoAuth is Luno refusing the API key, with 401 or 403
+	LunoAuth        Category = "luno_auth"
+	LunoRateLimited Category = "luno_rate_limited"
+	// LunoRejected is any other 4xx response from Luno
+	LunoRejected    Category = "luno_rejected"
+	LunoUnavailable Category = "luno_unavailable"
+	// Network is a Luno API call that got no response
+	Network Category = "network"
+	// Busy is a call that found no free slot under the concurrency limits
+	Busy Category = "busy"
+	// Panic is a bug in the server that the call was stopped by
+	Panic     Category = "panic"
+	Timeout   Category = "timeout"
+	Cancelled Category = "cancelled"
+	Other     Category = "other"
+)
+
+// Fields lists what a report holds, for operators checking what is sent
+var Fields = []string{"schema_version", "server_version", "period_seconds", "tools.calls", "tools.errors"}
+
+// Report is what is sent to the telemetry URL
+type Report struct {
+	SchemaVersion int    `json:"schema_version"`
+	ServerVersion string `json:"server_version"`
+	// PeriodSeconds is how long the counts were collected over
+	PeriodSeconds int64                 `json:"period_seconds"`
+	Tools         map[string]ToolCounts `json:"tools"`
+}
+
+// ToolCounts are a tool's calls, and its errors by category
+type ToolCounts struct {
+	Calls  int64              `json:"calls"`
+	Errors map[Category]int64 `json:"errors,omitempty"`
+}
+
+// Status describes telemetry for get_server_info
+type Status struct {
+	Enabled bool `json:"enabled"`
+	// URL is where reports are sent
+	URL             string   `json:"url,omitempty"`
+	IntervalSeconds int64    `json:"interval_seconds,omitempty"`
+	Fields          []string `json:"fields,omitempty"`
+	// LastReport is when a report was last sent successfully
+	LastReport *time.Time `json:"last_report,omitempty"`
+}
+
+// Reporter counts tool calls and sends them to the telemetry URL. It is safe
+// for concurrent use, and a nil Reporter is disabled: it counts nothing and
+// sends nothing.
+type Reporter struct {
+	url      string
+	version  string
+	interval time.Duration
+	client   *http.Client
+
+	mu         sync.Mutex
+	since      time.Time
+	tools      map[string]*ToolCounts
+	lastReport time.Time
+}
+
+// New creates a Reporter sending reports for the given server version to an
+// http or https URL
+func New(rawURL, version string) (*Reporter, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return nil, fmt.Errorf("invalid telemetry URL %q: must be an http or https URL", rawURL)
+	}
+	return &Reporter{
+		url:      rawURL,
+		version:  version,
+		interval: DefaultInterval,
+		client:   &http.Client{Timeout: sendTimeout},
+		since:    time.Now(),
+		tools:    make(map[string]*ToolCounts),
+	}, nil
+}
+
+// Record counts a call to tool, with the category of its error if it failed
+func (r *Reporter) Record(tool string, failed Category) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	counts, ok := r.tools[tool]
+	if !ok {
+		counts = &ToolCounts{}
+		r.tools[tool] = counts
+	}
+	counts.Calls++
+	if failed != "" {
+		if counts.Errors == nil {
+			counts.Errors = make(map[Category]int64)
+		}
+		counts.Errors[failed]++
+	}
+}
+
+// Status returns whether telemetry is enabled and what it sends
+func (r *Reporter) Status() Status {
+	if r == nil {
+		return Status{}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := Status{Enabled: true, URL: r.url, IntervalSeconds: int64(r.interval.Seconds()), Fields: Fields}
+	if !r.lastReport.IsZero() {
+		last := r.lastReport.UTC()
+		s.LastReport = &last
+	}
+	return s
+}
+
+// Run sends a report every interval until ctx is done
+func (r *Reporter) Run(ctx context.Context) {
+	if r == nil {
+		return
+	}
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.send(ctx)
+		}
+	}
+}
+
+// Close sends the calls counted since the last report, so that a server that
+// runs for less than the interval is counted too
+func (r *Reporter) Close() {
+	if r == nil {
+		return
+	}
+	r.send(context.Background())
+}
+
+// send reports the calls counted so far and starts counting afresh. The counts
+// are dropped if the report can't be sent, so that an unreachable URL does not
+// hold on to them.
+func (r *Reporter) send(ctx context.Context) {
+	report, ok := r.take()
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+	if err := r.post(ctx, report); err != nil {
+		slog.Debug("Failed to send telemetry", slog.Any("error", err))
+		return
+	}
+	r.mu.Lock()
+	r.lastReport = time.Now()
+	r.mu.Unlock()
+}
+
+// take returns the report of the calls counted so far, if there were any
+func (r *Reporter) take() (Report, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.tools) == 0 {
+		return Report{}, false
+	}
+	now := time.Now()
+	report := Report{
+		SchemaVersion: schemaVersion,
+		ServerVersion: r.version,
+		PeriodSeconds: int64(now.Sub(r.since).Seconds()),
+		Tools:         make(map[string]ToolCounts, len(r.tools)),
+	}
+	for tool, counts := range r.tools {
+		report.Tools[tool] = *counts
+	}
+	r.since = now
+	r.tools = make(map[string]*ToolCounts)
+	return report, true
+}
+
+func (r *Reporter) post(ctx context.Context, report Report) error {
+	body, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("telemetry URL returned %s", resp.Status)
+	}
+	return nil
+}
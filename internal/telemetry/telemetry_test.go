@@ -0,0 +1,108 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	_, err := New("ftp://example.com", "1.0.0")
+	assert.EqualError(t, err, `invalid telemetry URL "ftp://example.com": must be an http or https URL`)
+
+	var disabled *Reporter
+	disabled.Record("get_ticker", "")
+	disabled.Close()
+	assert.Equal(t, Status{}, disabled.Status())
+}
+
+func TestReporter(t *testing.T) {
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+	}))
+	defer srv.Close()
+
+	r, err := New(srv.URL, "1.2.3")
+	require.NoError(t, err)
+	assert.Equal(t, Status{Enabled: true, URL: srv.URL, IntervalSeconds: 86400, Fields: Fields}, r.Status())
+
+	r.Close()
+	assert.Empty(t, bodies, "nothing is sent before a call is made")
+
+	r.Record("get_ticker", "")
+	r.Record("get_ticker", LunoRateLimited)
+	r.Record("create_order", InvalidArguments)
+	r.Close()
+	require.Len(t, bodies, 1)
+
+	var report Report
+	require.NoError(t, json.Unmarshal([]byte(bodies[0]), &report))
+	assert.Equal(t, "1.2.3", report.ServerVersion)
+	assert.Equal(t, map[string]ToolCounts{
+		"get_ticker":   {Calls: 2, Errors: map[Category]int64{LunoRateLimited: 1}},
+		"create_order": {Calls: 1, Errors: map[Category]int64{InvalidArguments: 1}},
+	}, report.Tools)
+
+	var fields map[string]any
+	require.NoError(t, json.Unmarshal([]byte(bodies[0]), &fields))
+	assert.ElementsMatch(t, []string{"schema_version", "server_version", "period_seconds", "tools"}, slices.Collect(maps.Keys(fields)), "reports hold nothing else")
+	assert.NotNil(t, r.Status().LastReport)
+
+	r.Close()
+	assert.Len(t, bodies, 1, "counts are sent once")
+}
+
+func TestCategorise(t *testing.T) {
+	luno := func(status int) *Call {
+		c := &Call{}
+		c.LunoResponse(200)
+		c.LunoResponse(status)
+		return c
+	}
+	rejected := &Call{}
+	rejected.Rejected()
+	panicked := luno(500)
+	panicked.Panicked()
+
+	tests := []struct {
+		name     string
+		call     *Call
+		err      error
+		isError  bool
+		invalid  bool
+		expected Category
+	}{
+		{name: "success", call: luno(200)},
+		{name: "cancelled", call: &Call{}, err: context.Canceled, expected: Cancelled},
+		{name: "timeout", call: &Call{}, err: context.DeadlineExceeded, expected: Timeout},
+		{name: "handler error", call: &Call{}, err: errors.New("boom"), expected: Other},
+		{name: "invalid arguments", call: &Call{}, isError: true, invalid: true, expected: InvalidArguments},
+		{name: "busy", call: rejected, isError: true, expected: Busy},
+		{name: "panic", call: panicked, isError: true, expected: Panic},
+		{name: "no response", call: luno(0), isError: true, expected: Network},
+		{name: "unauthorised", call: luno(401), isError: true, expected: LunoAuth},
+		{name: "forbidden", call: luno(403), isError: true, expected: LunoAuth},
+		{name: "rate limited", call: luno(429), isError: true, expected: LunoRateLimited},
+		{name: "rejected", call: luno(400), isError: true, expected: LunoRejected},
+		{name: "unavailable", call: luno(503), isError: true, expected: LunoUnavailable},
+		{name: "refused by the tool", call: luno(200), isError: true, expected: Other},
+		{name: "no call", isError: true, expected: Other},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.call.Categorise(tc.err, tc.isError, tc.invalid))
+		})
+	}
+}
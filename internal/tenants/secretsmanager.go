@@ -0,0 +1,181 @@
+package tenants
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// secretsManagerService is the AWS service name requests are signed for
+const secretsManagerService = "secretsmanager"
+
+// AWSCredentials are the AWS access key requests to Secrets Manager are
+// signed with
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	// SessionToken is set for temporary credentials, e.g. from an IAM role
+	SessionToken string
+}
+
+// SecretsManagerProvider reads credentials from AWS Secrets Manager. The
+// secret named SecretID, with {tenant} replaced by the tenant, must be a JSON
+// string holding key_id and secret.
+type SecretsManagerProvider struct {
+	Region   string
+	SecretID string
+	AWS      AWSCredentials
+	// Endpoint overrides the regional Secrets Manager endpoint, e.g. for a
+	// VPC endpoint
+	Endpoint string
+	Client   *http.Client
+
+	now func() time.Time
+}
+
+// NewSecretsManagerProvider creates a SecretsManagerProvider for the secrets
+// named secretID in region
+func NewSecretsManagerProvider(region, secretID string, aws AWSCredentials) (*SecretsManagerProvider, error) {
+	if region == "" {
+		return nil, fmt.Errorf("an AWS region is required")
+	}
+	if aws.AccessKeyID == "" || aws.SecretAccessKey == "" {
+		return nil, fmt.Errorf("AWS access key ID and secret access key are required")
+	}
+	if !strings.Contains(secretID, "{tenant}") {
+		return nil, fmt.Errorf("invalid Secrets Manager secret name %q: must contain {tenant}", secretID)
+	}
+	return &SecretsManagerProvider{
+		Region:   region,
+		SecretID: secretID,
+		AWS:      aws,
+		Endpoint: fmt.Sprintf("https://secretsmanager.%s.amazonaws.com", region),
+		Client:   &http.Client{Timeout: requestTimeout},
+	}, nil
+}
+
+// Credentials implements Provider
+func (p *SecretsManagerProvider) Credentials(ctx context.Context, tenant string) (Credentials, error) {
+	if err := CheckTenant(tenant); err != nil {
+		return Credentials{}, err
+	}
+	body, err := json.Marshal(map[string]string{"SecretId": expand(p.SecretID, tenant)})
+	if err != nil {
+		return Credentials{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.Endpoint+"/", bytes.NewReader(body))
+	if err != nil {
+		return Credentials{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	now := time.Now
+	if p.now != nil {
+		now = p.now
+	}
+	p.sign(req, body, now().UTC())
+
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("secrets manager request failed: %w", err)
+	}
+	defer res.Body.Close()
+	resBody, err := io.ReadAll(io.LimitReader(res.Body, maxResponseBytes))
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to read secrets manager response: %w", err)
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		var awsErr struct {
+			Type string `json:"__type"`
+		}
+		_ = json.Unmarshal(resBody, &awsErr)
+		if strings.HasSuffix(awsErr.Type, "ResourceNotFoundException") {
+			return Credentials{}, fmt.Errorf("%w %s in Secrets Manager", ErrUnknownTenant, tenant)
+		}
+		return Credentials{}, fmt.Errorf("secrets manager returned %s %s for tenant %s", res.Status, awsErr.Type, tenant)
+	}
+
+	var secret struct {
+		SecretString string `json:"SecretString"`
+	}
+	if err := json.Unmarshal(resBody, &secret); err != nil {
+		return Credentials{}, fmt.Errorf("secrets manager returned invalid JSON: %w", err)
+	}
+	creds, err := parseSecret([]byte(secret.SecretString))
+	if err != nil {
+		return Credentials{}, fmt.Errorf("invalid Secrets Manager secret for tenant %s: %w", tenant, err)
+	}
+	return creds, nil
+}
+
+// sign adds an AWS Signature Version 4 Authorization header to req
+func (p *SecretsManagerProvider) sign(req *http.Request, body []byte, t time.Time) {
+	amzDate := t.Format("20060102T150405Z")
+	date := t.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	if p.AWS.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", p.AWS.SessionToken)
+	}
+
+	headers := []string{"content-type", "host", "x-amz-date", "x-amz-target"}
+	if p.AWS.SessionToken != "" {
+		headers = append(headers, "x-amz-security-token")
+	}
+	slices.Sort(headers)
+	var canonicalHeaders strings.Builder
+	for _, h := range headers {
+		value := req.Header.Get(h)
+		if h == "host" {
+			value = req.URL.Host
+		}
+		fmt.Fprintf(&canonicalHeaders, "%s:%s\n", h, strings.TrimSpace(value))
+	}
+	signedHeaders := strings.Join(headers, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		"/",
+		"",
+		canonicalHeaders.String(),
+		signedHeaders,
+		hexSHA256(body),
+	}, "\n")
+	scope := fmt.Sprintf("%s/%s/%s/aws4_request", date, p.Region, secretsManagerService)
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, hexSHA256([]byte(canonicalRequest))}, "\n")
+	signature := hex.EncodeToString(hmacSHA256(signingKey(p.AWS.SecretAccessKey, date, p.Region, secretsManagerService), stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		p.AWS.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// signingKey derives the Signature Version 4 key for a day, region and service
+func signingKey(secret, date, region, service string) []byte {
+	k := hmacSHA256([]byte("AWS4"+secret), date)
+	k = hmacSHA256(k, region)
+	k = hmacSHA256(k, service)
+	return hmacSHA256(k, "aws4_request")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func hexSHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,64 @@
+package tenants
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSigningKey(t *testing.T) {
+	// The example from AWS's Signature Version 4 documentation
+	key := signingKey("wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "20150830", "us-east-1", "iam")
+	assert.Equal(t, "c4afb1cc5771d871763a393e44b703571b55cc28424d1a5e86da6ed3c154a4b9", hex.EncodeToString(key))
+}
+
+func TestSecretsManagerProvider(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secretsmanager.GetSecretValue", r.Header.Get("X-Amz-Target"))
+		assert.Equal(t, "20261015T120000Z", r.Header.Get("X-Amz-Date"))
+		assert.Equal(t, "session-token", r.Header.Get("X-Amz-Security-Token"))
+		assert.Regexp(t, `^AWS4-HMAC-SHA256 Credential=AKID/20261015/eu-west-1/secretsmanager/aws4_request, `+
+			`SignedHeaders=content-type;host;x-amz-date;x-amz-security-token;x-amz-target, Signature=[0-9a-f]{64}$`, r.Header.Get("Authorization"))
+
+		var req struct{ SecretId string }
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		switch req.SecretId {
+		case "luno/acme":
+			_, _ = w.Write([]byte(`{"Name":"luno/acme","SecretString":"{\"key_id\":\"acme-id\",\"secret\":\"acme-secret\"}"}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type":"ResourceNotFoundException","message":"Secrets Manager can't find the specified secret."}`))
+		}
+	}))
+	defer srv.Close()
+
+	p, err := NewSecretsManagerProvider("eu-west-1", "luno/{tenant}", AWSCredentials{AccessKeyID: "AKID", SecretAccessKey: "secret", SessionToken: "session-token"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://secretsmanager.eu-west-1.amazonaws.com", p.Endpoint)
+	p.Endpoint = srv.URL
+	p.now = func() time.Time { return time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC) }
+
+	creds, err := p.Credentials(context.Background(), "acme")
+	require.NoError(t, err)
+	assert.Equal(t, Credentials{KeyID: "acme-id", Secret: "acme-secret"}, creds)
+
+	_, err = p.Credentials(context.Background(), "unknown")
+	require.ErrorIs(t, err, ErrUnknownTenant)
+}
+
+func TestNewSecretsManagerProviderErrors(t *testing.T) {
+	aws := AWSCredentials{AccessKeyID: "AKID", SecretAccessKey: "secret"}
+	_, err := NewSecretsManagerProvider("", "luno/{tenant}", aws)
+	assert.ErrorContains(t, err, "an AWS region is required")
+	_, err = NewSecretsManagerProvider("eu-west-1", "luno/{tenant}", AWSCredentials{})
+	assert.ErrorContains(t, err, "AWS access key ID and secret access key are required")
+	_, err = NewSecretsManagerProvider("eu-west-1", "luno/shared", aws)
+	assert.ErrorContains(t, err, "must contain {tenant}")
+}
@@ -0,0 +1,147 @@
+// Package tenants fetches the Luno API credentials of each tenant of a hosted
+// deployment from a secrets store, so that the keys of many accounts don't
+// have to be baked into the server's environment. Providers are pluggable:
+// anything implementing Provider can be configured, and VaultProvider and
+// SecretsManagerProvider read HashiCorp Vault and AWS Secrets Manager.
+package tenants
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultCacheTTL is how long fetched credentials are used before they
+	// are fetched again, picking up rotated keys
+	DefaultCacheTTL = 5 * time.Minute
+
+	// DefaultHeader is the HTTP header naming the tenant of a session
+	DefaultHeader = "X-Tenant-ID"
+
+	// requestTimeout bounds a single request to a secrets store
+	requestTimeout = 10 * time.Second
+
+	// maxResponseBytes is the largest secrets store response read
+	maxResponseBytes = 1 << 20
+)
+
+// ErrUnknownTenant is returned for a tenant the store has no credentials for
+var ErrUnknownTenant = errors.New("no Luno API credentials for tenant")
+
+// validTenant matches the tenant names accepted, which are put into secret
+// paths and so can't contain slashes or dots alone
+var validTenant = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,63}$`)
+
+// Credentials are a tenant's Luno API key
+type Credentials struct {
+	KeyID  string
+	Secret string
+}
+
+// Provider returns the credentials of a tenant. Implementations must be safe
+// for concurrent use.
+type Provider interface {
+	Credentials(ctx context.Context, tenant string) (Credentials, error)
+}
+
+// ProviderFunc adapts a function to a Provider
+type ProviderFunc func(ctx context.Context, tenant string) (Credentials, error)
+
+// Credentials implements Provider
+func (f ProviderFunc) Credentials(ctx context.Context, tenant string) (Credentials, error) {
+	return f(ctx, tenant)
+}
+
+// CheckTenant returns an error unless tenant is a valid tenant name: letters,
+// digits, '_', '.' and '-', starting with a letter or digit, up to 64 long
+func CheckTenant(tenant string) error {
+	if !validTenant.MatchString(tenant) {
+		return fmt.Errorf("invalid tenant %q", tenant)
+	}
+	return nil
+}
+
+// expand replaces {tenant} in template
+func expand(template, tenant string) string {
+	return strings.ReplaceAll(template, "{tenant}", tenant)
+}
+
+// parseSecret reads credentials from a secret's key/value pairs, which must
+// hold key_id and secret
+func parseSecret(data []byte) (Credentials, error) {
+	var fields struct {
+		KeyID  string `json:"key_id"`
+		Secret string `json:"secret"`
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return Credentials{}, errors.New("the secret is not a JSON object")
+	}
+	if fields.KeyID == "" || fields.Secret == "" {
+		return Credentials{}, errors.New("the secret must hold key_id and secret")
+	}
+	return Credentials{KeyID: fields.KeyID, Secret: fields.Secret}, nil
+}
+
+type cacheEntry struct {
+	creds   Credentials
+	expires time.Time
+}
+
+// Cache keeps the credentials returned by a Provider for a while, so that
+// sessions starting together share one fetch. Keys rotated in the store are
+// picked up once the cached ones expire; errors are not cached.
+type Cache struct {
+	provider Provider
+	ttl      time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+// NewCache caches the credentials from provider for ttl
+func NewCache(provider Provider, ttl time.Duration) *Cache {
+	return &Cache{provider: provider, ttl: ttl, entries: make(map[string]cacheEntry)}
+}
+
+// Credentials implements Provider, checking the tenant name first
+func (c *Cache) Credentials(ctx context.Context, tenant string) (Credentials, error) {
+	if err := CheckTenant(tenant); err != nil {
+		return Credentials{}, err
+	}
+	now := time.Now()
+	c.mu.Lock()
+	e, ok := c.entries[tenant]
+	c.mu.Unlock()
+	if ok && now.Before(e.expires) {
+		return e.creds, nil
+	}
+
+	creds, err := c.provider.Credentials(ctx, tenant)
+	if err != nil {
+		return Credentials{}, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Drop expired entries so that the cache doesn't keep every tenant seen
+	for t, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, t)
+		}
+	}
+	c.entries[tenant] = cacheEntry{creds: creds, expires: now.Add(c.ttl)}
+	return creds, nil
+}
+
+// Invalidate drops the cached credentials of tenant, so that the next
+// session fetches them again
+func (c *Cache) Invalidate(tenant string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, tenant)
+}
@@ -0,0 +1,84 @@
+package tenants
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+	fetches := 0
+	keyID := "key-1"
+	provider := ProviderFunc(func(_ context.Context, tenant string) (Credentials, error) {
+		fetches++
+		if tenant == "broken" {
+			return Credentials{}, errors.New("store unavailable")
+		}
+		return Credentials{KeyID: keyID, Secret: "secret"}, nil
+	})
+	cache := NewCache(provider, time.Hour)
+
+	creds, err := cache.Credentials(ctx, "acme")
+	require.NoError(t, err)
+	assert.Equal(t, "key-1", creds.KeyID)
+
+	keyID = "key-2"
+	creds, err = cache.Credentials(ctx, "acme")
+	require.NoError(t, err)
+	assert.Equal(t, "key-1", creds.KeyID, "cached until the TTL passes")
+	assert.Equal(t, 1, fetches)
+
+	cache.Invalidate("acme")
+	creds, err = cache.Credentials(ctx, "acme")
+	require.NoError(t, err)
+	assert.Equal(t, "key-2", creds.KeyID, "rotated keys are fetched once invalidated")
+
+	_, err = cache.Credentials(ctx, "broken")
+	require.Error(t, err)
+	_, err = cache.Credentials(ctx, "broken")
+	require.Error(t, err)
+	assert.Equal(t, 4, fetches, "errors are not cached")
+
+	_, err = cache.Credentials(ctx, "../other")
+	assert.EqualError(t, err, `invalid tenant "../other"`)
+	assert.Equal(t, 4, fetches)
+}
+
+func TestCacheExpiry(t *testing.T) {
+	fetches := 0
+	cache := NewCache(ProviderFunc(func(context.Context, string) (Credentials, error) {
+		fetches++
+		return Credentials{KeyID: "key", Secret: "secret"}, nil
+	}), 0)
+
+	for range 2 {
+		_, err := cache.Credentials(context.Background(), "acme")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, fetches)
+}
+
+func TestCheckTenant(t *testing.T) {
+	for _, tenant := range []string{"acme", "Acme-Corp_2", "tenant.eu"} {
+		assert.NoError(t, CheckTenant(tenant), tenant)
+	}
+	for _, tenant := range []string{"", ".", "..", "-acme", "acme/prod", "acme prod", string(make([]byte, 65))} {
+		assert.Error(t, CheckTenant(tenant), tenant)
+	}
+}
+
+func TestParseSecret(t *testing.T) {
+	creds, err := parseSecret([]byte(`{"key_id":"id","secret":"s","note":"ignored"}`))
+	require.NoError(t, err)
+	assert.Equal(t, Credentials{KeyID: "id", Secret: "s"}, creds)
+
+	_, err = parseSecret([]byte(`{"key_id":"id"}`))
+	assert.EqualError(t, err, "the secret must hold key_id and secret")
+	_, err = parseSecret([]byte(`not json`))
+	assert.EqualError(t, err, "the secret is not a JSON object")
+}
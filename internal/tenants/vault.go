@@ -0,0 +1,90 @@
+package tenants
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// VaultProvider reads credentials from a HashiCorp Vault KV version 2 secrets
+// engine. The secret at Path, with {tenant} replaced by the tenant, must hold
+// key_id and secret.
+type VaultProvider struct {
+	// Addr is Vault's address, e.g. https://vault.example.com:8200
+	Addr string
+	// Token authenticates to Vault; it needs read access to the secrets
+	Token string
+	// Mount is where the KV engine is mounted, e.g. secret
+	Mount string
+	// Path is the secret's path in the engine, e.g. luno/{tenant}
+	Path   string
+	Client *http.Client
+}
+
+// NewVaultProvider creates a VaultProvider for the KV engine at mount
+func NewVaultProvider(addr, token, mount, path string) (*VaultProvider, error) {
+	u, err := url.Parse(addr)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return nil, fmt.Errorf("invalid Vault address %q: must be an http or https URL", addr)
+	}
+	if token == "" {
+		return nil, fmt.Errorf("a Vault token is required")
+	}
+	if mount == "" || !strings.Contains(path, "{tenant}") {
+		return nil, fmt.Errorf("invalid Vault secret path %q: want mount/path containing {tenant}", mount+"/"+path)
+	}
+	return &VaultProvider{
+		Addr:   strings.TrimSuffix(addr, "/"),
+		Token:  token,
+		Mount:  strings.Trim(mount, "/"),
+		Path:   strings.Trim(path, "/"),
+		Client: &http.Client{Timeout: requestTimeout},
+	}, nil
+}
+
+// Credentials implements Provider
+func (v *VaultProvider) Credentials(ctx context.Context, tenant string) (Credentials, error) {
+	if err := CheckTenant(tenant); err != nil {
+		return Credentials{}, err
+	}
+	rawURL := fmt.Sprintf("%s/v1/%s/data/%s", v.Addr, v.Mount, expand(v.Path, tenant))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return Credentials{}, err
+	}
+	req.Header.Set("X-Vault-Token", v.Token)
+
+	client := v.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("vault request failed: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return Credentials{}, fmt.Errorf("%w %s in Vault", ErrUnknownTenant, tenant)
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return Credentials{}, fmt.Errorf("vault returned %s for tenant %s", res.Status, tenant)
+	}
+
+	var secret struct {
+		Data struct {
+			Data json.RawMessage `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(io.LimitReader(res.Body, maxResponseBytes)).Decode(&secret); err != nil {
+		return Credentials{}, fmt.Errorf("vault returned invalid JSON: %w", err)
+	}
+	creds, err := parseSecret(secret.Data.Data)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("invalid Vault secret for tenant %s: %w", tenant, err)
+	}
+	return creds, nil
+}
@@ -0,0 +1,48 @@
+package tenants
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVaultProvider(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "vault-token", r.Header.Get("X-Vault-Token"))
+		switch r.URL.Path {
+		case "/v1/secret/data/luno/acme":
+			_, _ = w.Write([]byte(`{"data":{"data":{"key_id":"acme-id","secret":"acme-secret"},"metadata":{"version":3}}}`))
+		case "/v1/secret/data/luno/empty":
+			_, _ = w.Write([]byte(`{"data":{"data":{}}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	v, err := NewVaultProvider(srv.URL+"/", "vault-token", "secret", "luno/{tenant}")
+	require.NoError(t, err)
+
+	creds, err := v.Credentials(context.Background(), "acme")
+	require.NoError(t, err)
+	assert.Equal(t, Credentials{KeyID: "acme-id", Secret: "acme-secret"}, creds)
+
+	_, err = v.Credentials(context.Background(), "unknown")
+	require.ErrorIs(t, err, ErrUnknownTenant)
+
+	_, err = v.Credentials(context.Background(), "empty")
+	assert.EqualError(t, err, "invalid Vault secret for tenant empty: the secret must hold key_id and secret")
+}
+
+func TestNewVaultProviderErrors(t *testing.T) {
+	_, err := NewVaultProvider("vault:8200", "token", "secret", "luno/{tenant}")
+	assert.ErrorContains(t, err, "invalid Vault address")
+	_, err = NewVaultProvider("https://vault:8200", "", "secret", "luno/{tenant}")
+	assert.ErrorContains(t, err, "a Vault token is required")
+	_, err = NewVaultProvider("https://vault:8200", "token", "secret", "luno/shared")
+	assert.ErrorContains(t, err, "containing {tenant}")
+}
@@ -0,0 +1,245 @@
+// Package testutil has helpers for testing code that calls the Luno API, such
+// as tool handlers.
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/sdk"
+)
+
+var _ sdk.LunoClient = (*FakeLunoClient)(nil)
+
+var lunoClientType = reflect.TypeFor[sdk.LunoClient]()
+
+// Call is a request made to a FakeLunoClient
+type Call struct {
+	// Method is the name of the sdk.LunoClient method called, e.g. GetTicker
+	Method  string
+	Request any
+}
+
+type response struct {
+	res any
+	err error
+}
+
+// FakeLunoClient is an sdk.LunoClient that returns scripted responses and
+// records the calls made to it.
+//
+// Responses are scripted for each method with Respond and returned in order,
+// the last one again for any later calls. Calling a method without a response
+// fails the test, as does finishing it with responses that were not used, so
+// a test with a FakeLunoClient checks both what is called and what is not.
+type FakeLunoClient struct {
+	t testing.TB
+
+	mu        sync.Mutex
+	responses map[string][]response
+	used      map[string]int
+	calls     []Call
+}
+
+// NewFakeLunoClient returns a FakeLunoClient for the test t with no responses
+func NewFakeLunoClient(t testing.TB) *FakeLunoClient {
+	f := &FakeLunoClient{t: t, responses: make(map[string][]response), used: make(map[string]int)}
+	t.Cleanup(func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		for method, responses := range f.responses {
+			if unused := len(responses) - f.used[method]; unused > 0 {
+				t.Errorf("FakeLunoClient: %d %s responses were not used", unused, method)
+			}
+		}
+	})
+	return f
+}
+
+// Respond adds a response for calls to method, the name of an sdk.LunoClient
+// method. res must be nil or the response type of method, e.g. a
+// *luno.GetTickerResponse for GetTicker.
+func (f *FakeLunoClient) Respond(method string, res any, err error) *FakeLunoClient {
+	f.t.Helper()
+	m, ok := lunoClientType.MethodByName(method)
+	if !ok || m.Type.NumOut() != 2 {
+		f.t.Fatalf("FakeLunoClient: %s is not a Luno API method", method)
+	}
+	if res != nil && reflect.TypeOf(res) != m.Type.Out(0) {
+		f.t.Fatalf("FakeLunoClient: %s returns %s, not %T", method, m.Type.Out(0), res)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.responses[method] = append(f.responses[method], response{res: res, err: err})
+	return f
+}
+
+// Fail makes every call to method return err
+func (f *FakeLunoClient) Fail(method string, err error) *FakeLunoClient {
+	f.t.Helper()
+	return f.Respond(method, nil, err)
+}
+
+// Calls returns the calls made so far, in order
+func (f *FakeLunoClient) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Call(nil), f.calls...)
+}
+
+// Requests returns the requests made to method so far, in order
+func (f *FakeLunoClient) Requests(method string) []any {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var reqs []any
+	for _, c := range f.calls {
+		if c.Method == method {
+			reqs = append(reqs, c.Request)
+		}
+	}
+	return reqs
+}
+
+// AssertCalled checks that method was called with req at least once
+func (f *FakeLunoClient) AssertCalled(t testing.TB, method string, req any) bool {
+	t.Helper()
+	reqs := f.Requests(method)
+	for _, r := range reqs {
+		if reflect.DeepEqual(r, req) {
+			return true
+		}
+	}
+	t.Errorf("FakeLunoClient: %s was not called with %+v; it was called with %+v", method, req, reqs)
+	return false
+}
+
+// AssertNoCalls checks that the API has not been called
+func (f *FakeLunoClient) AssertNoCalls(t testing.TB) bool {
+	t.Helper()
+	if calls := f.Calls(); len(calls) > 0 {
+		t.Errorf("FakeLunoClient: expected no calls, got %+v", calls)
+		return false
+	}
+	return true
+}
+
+func (f *FakeLunoClient) call(method string, req any) (any, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, Call{Method: method, Request: req})
+	responses := f.responses[method]
+	if len(responses) == 0 {
+		f.t.Errorf("FakeLunoClient: unexpected call to %s with %+v", method, req)
+		return nil, fmt.Errorf("no response scripted for %s", method)
+	}
+	i := f.used[method]
+	if i < len(responses) {
+		f.used[method]++
+	} else {
+		i = len(responses) - 1
+	}
+	return responses[i].res, responses[i].err
+}
+
+// respond makes a call to method and returns its response as a *Res
+func respond[Res any](f *FakeLunoClient, method string, req any) (*Res, error) {
+	res, err := f.call(method, req)
+	if res == nil {
+		return nil, err
+	}
+	return res.(*Res), err
+}
+
+func (f *FakeLunoClient) GetBalances(ctx context.Context, req *luno.GetBalancesRequest) (*luno.GetBalancesResponse, error) {
+	return respond[luno.GetBalancesResponse](f, "GetBalances", req)
+}
+
+func (f *FakeLunoClient) GetTicker(ctx context.Context, req *luno.GetTickerRequest) (*luno.GetTickerResponse, error) {
+	return respond[luno.GetTickerResponse](f, "GetTicker", req)
+}
+
+func (f *FakeLunoClient) GetOrderBook(ctx context.Context, req *luno.GetOrderBookRequest) (*luno.GetOrderBookResponse, error) {
+	return respond[luno.GetOrderBookResponse](f, "GetOrderBook", req)
+}
+
+func (f *FakeLunoClient) GetOrder(ctx context.Context, req *luno.GetOrderRequest) (*luno.GetOrderResponse, error) {
+	return respond[luno.GetOrderResponse](f, "GetOrder", req)
+}
+
+func (f *FakeLunoClient) PostLimitOrder(ctx context.Context, req *luno.PostLimitOrderRequest) (*luno.PostLimitOrderResponse, error) {
+	return respond[luno.PostLimitOrderResponse](f, "PostLimitOrder", req)
+}
+
+func (f *FakeLunoClient) PostMarketOrder(ctx context.Context, req *luno.PostMarketOrderRequest) (*luno.PostMarketOrderResponse, error) {
+	return respond[luno.PostMarketOrderResponse](f, "PostMarketOrder", req)
+}
+
+func (f *FakeLunoClient) StopOrder(ctx context.Context, req *luno.StopOrderRequest) (*luno.StopOrderResponse, error) {
+	return respond[luno.StopOrderResponse](f, "StopOrder", req)
+}
+
+func (f *FakeLunoClient) ListOrders(ctx context.Context, req *luno.ListOrdersRequest) (*luno.ListOrdersResponse, error) {
+	return respond[luno.ListOrdersResponse](f, "ListOrders", req)
+}
+
+func (f *FakeLunoClient) ListTransactions(ctx context.Context, req *luno.ListTransactionsRequest) (*luno.ListTransactionsResponse, error) {
+	return respond[luno.ListTransactionsResponse](f, "ListTransactions", req)
+}
+
+func (f *FakeLunoClient) ListTrades(ctx context.Context, req *luno.ListTradesRequest) (*luno.ListTradesResponse, error) {
+	return respond[luno.ListTradesResponse](f, "ListTrades", req)
+}
+
+func (f *FakeLunoClient) ListUserTrades(ctx context.Context, req *luno.ListUserTradesRequest) (*luno.ListUserTradesResponse, error) {
+	return respond[luno.ListUserTradesResponse](f, "ListUserTrades", req)
+}
+
+func (f *FakeLunoClient) GetCandles(ctx context.Context, req *luno.GetCandlesRequest) (*luno.GetCandlesResponse, error) {
+	return respond[luno.GetCandlesResponse](f, "GetCandles", req)
+}
+
+func (f *FakeLunoClient) GetTickers(ctx context.Context, req *luno.GetTickersRequest) (*luno.GetTickersResponse, error) {
+	return respond[luno.GetTickersResponse](f, "GetTickers", req)
+}
+
+func (f *FakeLunoClient) GetOrderBookFull(ctx context.Context, req *luno.GetOrderBookFullRequest) (*luno.GetOrderBookFullResponse, error) {
+	return respond[luno.GetOrderBookFullResponse](f, "GetOrderBookFull", req)
+}
+
+func (f *FakeLunoClient) Markets(ctx context.Context, req *luno.MarketsRequest) (*luno.MarketsResponse, error) {
+	return respond[luno.MarketsResponse](f, "Markets", req)
+}
+
+func (f *FakeLunoClient) ListBeneficiaries(ctx context.Context, req *luno.ListBeneficiariesRequest) (*luno.ListBeneficiariesResponse, error) {
+	return respond[luno.ListBeneficiariesResponse](f, "ListBeneficiaries", req)
+}
+
+func (f *FakeLunoClient) CreateWithdrawal(ctx context.Context, req *luno.CreateWithdrawalRequest) (*luno.CreateWithdrawalResponse, error) {
+	return respond[luno.CreateWithdrawalResponse](f, "CreateWithdrawal", req)
+}
+
+func (f *FakeLunoClient) ListWithdrawals(ctx context.Context, req *luno.ListWithdrawalsRequest) (*luno.ListWithdrawalsResponse, error) {
+	return respond[luno.ListWithdrawalsResponse](f, "ListWithdrawals", req)
+}
+
+func (f *FakeLunoClient) GetWithdrawal(ctx context.Context, req *luno.GetWithdrawalRequest) (*luno.GetWithdrawalResponse, error) {
+	return respond[luno.GetWithdrawalResponse](f, "GetWithdrawal", req)
+}
+
+func (f *FakeLunoClient) ListTransfers(ctx context.Context, req *luno.ListTransfersRequest) (*luno.ListTransfersResponse, error) {
+	return respond[luno.ListTransfersResponse](f, "ListTransfers", req)
+}
+
+func (f *FakeLunoClient) GetFeeInfo(ctx context.Context, req *luno.GetFeeInfoRequest) (*luno.GetFeeInfoResponse, error) {
+	return respond[luno.GetFeeInfoResponse](f, "GetFeeInfo", req)
+}
+
+func (f *FakeLunoClient) SetBaseURL(url string) {}
+
+func (f *FakeLunoClient) SetAuth(id, secret string) error { return nil }
+
+func (f *FakeLunoClient) SetDebug(debug bool) {}
@@ -0,0 +1,101 @@
+package testutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/luno/luno-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder is a testing.TB that records failures instead of failing the test
+type recorder struct {
+	testing.TB
+	errors   []string
+	cleanups []func()
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+	panic("fatal")
+}
+
+func (r *recorder) Cleanup(f func()) { r.cleanups = append(r.cleanups, f) }
+
+func (r *recorder) finish() {
+	for _, f := range r.cleanups {
+		f()
+	}
+}
+
+func TestFakeLunoClientResponses(t *testing.T) {
+	rec := &recorder{TB: t}
+	f := NewFakeLunoClient(rec)
+	apiErr := errors.New("api down")
+	f.Respond("GetTicker", &luno.GetTickerResponse{Pair: "XBTZAR"}, nil).
+		Respond("GetTicker", &luno.GetTickerResponse{Pair: "ETHZAR"}, nil).
+		Fail("ListOrders", apiErr)
+
+	ctx := context.Background()
+	for _, want := range []string{"XBTZAR", "ETHZAR", "ETHZAR"} {
+		res, err := f.GetTicker(ctx, &luno.GetTickerRequest{Pair: want})
+		require.NoError(t, err)
+		assert.Equal(t, want, res.Pair)
+	}
+	res, err := f.ListOrders(ctx, &luno.ListOrdersRequest{})
+	assert.Nil(t, res)
+	assert.Equal(t, apiErr, err)
+
+	assert.Len(t, f.Calls(), 4)
+	assert.Equal(t, []any{&luno.ListOrdersRequest{}}, f.Requests("ListOrders"))
+	assert.True(t, f.AssertCalled(rec, "GetTicker", &luno.GetTickerRequest{Pair: "ETHZAR"}))
+
+	rec.finish()
+	assert.Empty(t, rec.errors)
+}
+
+func TestFakeLunoClientFailures(t *testing.T) {
+	t.Run("unexpected call", func(t *testing.T) {
+		rec := &recorder{TB: t}
+		f := NewFakeLunoClient(rec)
+		_, err := f.GetBalances(context.Background(), &luno.GetBalancesRequest{})
+		assert.EqualError(t, err, "no response scripted for GetBalances")
+		require.Len(t, rec.errors, 1)
+		assert.Contains(t, rec.errors[0], "unexpected call to GetBalances")
+	})
+
+	t.Run("unused response", func(t *testing.T) {
+		rec := &recorder{TB: t}
+		NewFakeLunoClient(rec).Respond("Markets", &luno.MarketsResponse{}, nil)
+		rec.finish()
+		assert.Equal(t, []string{"FakeLunoClient: 1 Markets responses were not used"}, rec.errors)
+	})
+
+	t.Run("not called", func(t *testing.T) {
+		rec := &recorder{TB: t}
+		f := NewFakeLunoClient(rec).Respond("GetTicker", &luno.GetTickerResponse{}, nil)
+		assert.True(t, f.AssertNoCalls(rec))
+		assert.False(t, f.AssertCalled(rec, "GetTicker", &luno.GetTickerRequest{Pair: "XBTZAR"}))
+		assert.Len(t, rec.errors, 1)
+	})
+
+	t.Run("wrong response type", func(t *testing.T) {
+		rec := &recorder{TB: t}
+		f := NewFakeLunoClient(rec)
+		assert.Panics(t, func() { f.Respond("GetTicker", &luno.MarketsResponse{}, nil) })
+		assert.Panics(t, func() { f.Respond("GetPrices", nil, nil) })
+		assert.Equal(t, []string{
+			"FakeLunoClient: GetTicker returns *luno.GetTickerResponse, not *luno.MarketsResponse",
+			"FakeLunoClient: GetPrices is not a Luno API method",
+		}, rec.errors)
+	})
+}
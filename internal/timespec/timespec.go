@@ -0,0 +1,145 @@
+// Package timespec reads the times given to tool parameters. Besides Unix
+// milliseconds it accepts the forms people and models tend to write: dates
+// such as 2024-01-01, times ago such as 24h or 3 days, and periods such as
+// yesterday or last month. Calendar periods are in UTC, as Luno's times are.
+package timespec
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Help describes the forms Parse accepts, for tool parameter descriptions
+const Help = "Unix milliseconds, a date or time (2024-01-01, 2024-01-01T15:04:05Z), a time ago (24h, 7d, 2 weeks ago) " +
+	"or a period (today, yesterday, last month), in UTC"
+
+// Range is the time a spec refers to, from Start and before End. A spec for
+// an instant, such as a timestamp or a time ago, has Start equal to End.
+type Range struct {
+	Start time.Time
+	End   time.Time
+}
+
+var (
+	// maxAgo is the longest time ago accepted, well within time.Duration
+	maxAgo = 100 * 365 * 24 * time.Hour
+
+	// agoPart matches a number of units, repeated as in 1d12h
+	agoPart = regexp.MustCompile(`(\d+)\s*([a-z]+)\s*`)
+
+	units = map[string]time.Duration{
+		"s": time.Second, "sec": time.Second, "secs": time.Second, "second": time.Second, "seconds": time.Second,
+		"m": time.Minute, "min": time.Minute, "mins": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+		"h": time.Hour, "hr": time.Hour, "hrs": time.Hour, "hour": time.Hour, "hours": time.Hour,
+		"d": 24 * time.Hour, "day": 24 * time.Hour, "days": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour, "wk": 7 * 24 * time.Hour, "wks": 7 * 24 * time.Hour, "week": 7 * 24 * time.Hour, "weeks": 7 * 24 * time.Hour,
+	}
+
+	// instants are the layouts of dates with a time of day, read in UTC
+	// unless they give a zone
+	instants = []string{
+		time.RFC3339Nano,
+		"2006-01-02T15:04:05",
+		"2006-01-02T15:04",
+		"2006-01-02 15:04:05",
+		"2006-01-02 15:04",
+	}
+)
+
+// Parse reads s relative to now
+func Parse(s string, now time.Time) (Range, error) {
+	s = strings.ToLower(strings.Join(strings.Fields(s), " "))
+	now = now.UTC()
+	if s == "" {
+		return Range{}, errors.New("no time given")
+	}
+
+	if ms, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return instant(time.UnixMilli(ms)), nil
+	}
+	if r, ok := period(s, now); ok {
+		return r, nil
+	}
+	if d, ok := parseAgo(strings.TrimSuffix(s, " ago")); ok {
+		return instant(now.Add(-d)), nil
+	}
+	if rest, ok := strings.CutPrefix(s, "last "); ok {
+		if d, ok := parseAgo(rest); ok {
+			return Range{Start: now.Add(-d), End: now}, nil
+		}
+	}
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return Range{Start: t, End: t.AddDate(0, 0, 1)}, nil
+	}
+	if t, err := time.Parse("2006-01", s); err == nil {
+		return Range{Start: t, End: t.AddDate(0, 1, 0)}, nil
+	}
+	for _, layout := range instants {
+		if t, err := time.Parse(layout, strings.ToUpper(s)); err == nil {
+			return instant(t.UTC()), nil
+		}
+	}
+	return Range{}, fmt.Errorf("%q is not a time; use %s", s, Help)
+}
+
+func instant(t time.Time) Range {
+	return Range{Start: t, End: t}
+}
+
+// period reads the named periods, such as yesterday or last week
+func period(s string, now time.Time) (Range, bool) {
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	// Weeks start on Monday
+	week := day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	year := time.Date(now.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+
+	switch s {
+	case "now":
+		return instant(now), true
+	case "today":
+		return Range{Start: day, End: day.AddDate(0, 0, 1)}, true
+	case "yesterday":
+		return Range{Start: day.AddDate(0, 0, -1), End: day}, true
+	case "this week":
+		return Range{Start: week, End: week.AddDate(0, 0, 7)}, true
+	case "last week":
+		return Range{Start: week.AddDate(0, 0, -7), End: week}, true
+	case "this month":
+		return Range{Start: month, End: month.AddDate(0, 1, 0)}, true
+	case "last month":
+		return Range{Start: month.AddDate(0, -1, 0), End: month}, true
+	case "this year":
+		return Range{Start: year, End: year.AddDate(1, 0, 0)}, true
+	case "last year":
+		return Range{Start: year.AddDate(-1, 0, 0), End: year}, true
+	}
+	return Range{}, false
+}
+
+// parseAgo reads a length of time such as 24h, 1d12h or 3 days
+func parseAgo(s string) (time.Duration, bool) {
+	matches := agoPart.FindAllStringSubmatchIndex(s, -1)
+	if len(matches) == 0 {
+		return 0, false
+	}
+	var total time.Duration
+	next := 0
+	for _, m := range matches {
+		if m[0] != next {
+			return 0, false
+		}
+		next = m[1]
+		n, err := strconv.ParseInt(s[m[2]:m[3]], 10, 64)
+		unit, ok := units[s[m[4]:m[5]]]
+		if err != nil || !ok || n > int64((maxAgo-total)/unit) {
+			return 0, false
+		}
+		total += time.Duration(n) * unit
+	}
+	return total, next == len(s)
+}
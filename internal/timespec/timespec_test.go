@@ -0,0 +1,97 @@
+package timespec
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	// A Wednesday
+	now := time.Date(2026, 10, 14, 15, 30, 0, 0, time.UTC)
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		in    string
+		start time.Time
+		end   time.Time
+	}{
+		{in: "1704067200000", start: day(2024, 1, 1)},
+		{in: "now", start: now},
+		{in: "24h", start: now.Add(-24 * time.Hour)},
+		{in: "7d", start: now.AddDate(0, 0, -7)},
+		{in: "1d12h", start: now.Add(-36 * time.Hour)},
+		{in: "90m ago", start: now.Add(-90 * time.Minute)},
+		{in: " 2 Weeks  ago ", start: now.AddDate(0, 0, -14)},
+		{in: "3 days", start: now.AddDate(0, 0, -3)},
+		{in: "last 7 days", start: now.AddDate(0, 0, -7), end: now},
+		{in: "today", start: day(2026, 10, 14), end: day(2026, 10, 15)},
+		{in: "Yesterday", start: day(2026, 10, 13), end: day(2026, 10, 14)},
+		{in: "this week", start: day(2026, 10, 12), end: day(2026, 10, 19)},
+		{in: "last week", start: day(2026, 10, 5), end: day(2026, 10, 12)},
+		{in: "last month", start: day(2026, 9, 1), end: day(2026, 10, 1)},
+		{in: "this month", start: day(2026, 10, 1), end: day(2026, 11, 1)},
+		{in: "last year", start: day(2025, 1, 1), end: day(2026, 1, 1)},
+		{in: "2024-01-01", start: day(2024, 1, 1), end: day(2024, 1, 2)},
+		{in: "2024-02", start: day(2024, 2, 1), end: day(2024, 3, 1)},
+		{in: "2024-01-01T10:00:00Z", start: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)},
+		{in: "2024-01-01t12:00:00+02:00", start: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)},
+		{in: "2024-01-01 10:30", start: time.Date(2024, 1, 1, 10, 30, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := Parse(tt.in, now)
+			require.NoError(t, err)
+			end := tt.end
+			if end.IsZero() {
+				end = tt.start
+			}
+			assert.WithinDuration(t, tt.start, got.Start, 0)
+			assert.WithinDuration(t, end, got.End, 0)
+		})
+	}
+}
+
+func TestParseSundayWeek(t *testing.T) {
+	sunday := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	got, err := Parse("this week", sunday)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC), got.Start)
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, in := range []string{
+		"", "soon", "3 fortnights", "24h later", "h", "2024-13-01", "last", "99999999999 weeks",
+		"36500d36500d36500d", "last 5000w5000w5000w",
+	} {
+		_, err := Parse(in, time.Now())
+		assert.Error(t, err, in)
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	for _, seed := range []string{
+		"1700000000000", "-1", "2024-01-01", "2024-01-01T15:04:05+02:00", "24h", "1d12h ago", "last 3 days",
+		"yesterday", "LAST   MONTH", "36500d36500d36500d ago", "⏰ 2 weeks ago", "9223372036854775807",
+		strings.Repeat("1d", 10000),
+	} {
+		f.Add(seed)
+	}
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	f.Fuzz(func(t *testing.T, s string) {
+		r, err := Parse(s, now)
+		if err != nil {
+			return
+		}
+		if r.Start.After(r.End) {
+			t.Errorf("Parse(%q) = %v to %v, which ends before it starts", s, r.Start, r.End)
+		}
+		if strings.HasSuffix(strings.TrimSpace(s), "ago") && r.Start.After(now) {
+			t.Errorf("Parse(%q) = %v, which is in the future", s, r.Start)
+		}
+	})
+}
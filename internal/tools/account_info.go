@@ -0,0 +1,165 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// accountInfoNote explains the profile details the Luno API doesn't expose
+const accountInfoNote = "The Luno API does not expose the account's verification level, country or referral details; " +
+	"the user can find them in the Luno app under Profile. Luno opens an account for each currency it offers in the user's country, " +
+	"so a market whose currencies have no account is most likely not available to this user."
+
+// AccountInfo describes what the authenticated account can do
+type AccountInfo struct {
+	// WriteOperationsEnabled and WithdrawalsEnabled are this server's settings,
+	// not the account's
+	WriteOperationsEnabled bool `json:"write_operations_enabled"`
+	WithdrawalsEnabled     bool `json:"withdrawals_enabled"`
+	// Currencies are the currencies the account holds an account in
+	Currencies []string `json:"currencies"`
+	Accounts   int      `json:"accounts"`
+	// Fees is set when a pair was given and the API key can read fees
+	Fees    *AccountFees   `json:"fees,omitempty"`
+	Markets []MarketAccess `json:"markets"`
+	Notes   []string       `json:"notes"`
+}
+
+// AccountFees are the account's trading fees on one market
+type AccountFees struct {
+	Pair            string `json:"pair"`
+	MakerFee        string `json:"maker_fee"`
+	TakerFee        string `json:"taker_fee"`
+	ThirtyDayVolume string `json:"thirty_day_volume"`
+}
+
+// MarketAccess reports whether the account can trade on a market, and why not
+type MarketAccess struct {
+	Pair          string             `json:"pair"`
+	TradingStatus luno.TradingStatus `json:"trading_status"`
+	CanTrade      bool               `json:"can_trade"`
+	Reason        string             `json:"reason,omitempty"`
+}
+
+// NewGetAccountInfoTool creates a tool for describing what the account can do
+func NewGetAccountInfoTool() mcp.Tool {
+	return mcp.NewTool(
+		GetAccountInfoToolID,
+		mcp.WithDescription("Get what the account can do: the currencies it holds accounts in, which markets it can trade and why not, "+
+			"its trading fees on a market, and whether this server allows orders and withdrawals. "+
+			"Use this to explain why a market or feature is unavailable to the user."),
+		mcp.WithString(
+			"pair",
+			mcp.Description("Only check this market, and include the account's fees on it (e.g., XBTZAR)"),
+		),
+	)
+}
+
+// HandleGetAccountInfo handles the get_account_info tool
+func HandleGetAccountInfo(cfg *config.Config) server.ToolHandlerFunc {
+	tool := NewGetAccountInfoTool()
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		cfg := cfg.ForContext(ctx)
+		if !cfg.IsAuthenticated {
+			return mcp.NewToolResultError(ErrAPICredentialsRequired), nil
+		}
+
+		var args pairArgs
+		if errResult := bindArguments(request, tool, &args); errResult != nil {
+			return errResult, nil
+		}
+		var pair string
+		if args.Pair != "" {
+			pair = normalizeCurrencyPair(args.Pair)
+		}
+
+		balances, err := cfg.LunoClient.GetBalances(ctx, &luno.GetBalancesRequest{})
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to get balances: %v", err)), nil
+		}
+		held := make(map[string]bool)
+		var currencies []string
+		for _, b := range balances.Balance {
+			if !held[b.Asset] {
+				currencies = append(currencies, b.Asset)
+			}
+			held[b.Asset] = true
+		}
+		slices.Sort(currencies)
+
+		info := AccountInfo{
+			WriteOperationsEnabled: cfg.AllowWriteOperations,
+			WithdrawalsEnabled:     cfg.AllowWriteOperations && cfg.AllowWithdrawals,
+			Currencies:             currencies,
+			Accounts:               len(balances.Balance),
+			Markets:                []MarketAccess{},
+			Notes:                  []string{accountInfoNote},
+		}
+
+		list, err := MarketCache(cfg).Markets(ctx)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("getting markets", err), nil
+		}
+		for _, m := range list {
+			if pair != "" && m.MarketId != pair {
+				continue
+			}
+			info.Markets = append(info.Markets, marketAccess(m, held))
+		}
+		if pair != "" {
+			if len(info.Markets) == 0 {
+				return mcp.NewToolResultError(fmt.Sprintf("Unable to get account info for %s: %s", pair, unknownMarketError)), nil
+			}
+			fees, err := cfg.LunoClient.GetFeeInfo(ctx, &luno.GetFeeInfoRequest{Pair: pair})
+			if err != nil {
+				// Keys without the Perm_R_Orders permission can't read fees, which
+				// is worth reporting rather than failing on
+				slog.WarnContext(ctx, "Failed to get fee info", "pair", pair, "error", err)
+				info.Notes = append(info.Notes, fmt.Sprintf("The fees on %s could not be read (%v); the API key may not have permission to read orders.", pair, err))
+			} else {
+				info.Fees = &AccountFees{
+					Pair:            pair,
+					MakerFee:        fees.MakerFee,
+					TakerFee:        fees.TakerFee,
+					ThirtyDayVolume: fees.ThirtyDayVolume,
+				}
+			}
+		}
+
+		resultJSON, err := marshalJSON(info)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal account info: %v", err)), nil
+		}
+		return mcp.NewToolResultText(resultJSON), nil
+	}
+}
+
+// marketAccess reports whether an account holding the currencies in held can
+// trade on m
+func marketAccess(m luno.MarketInfo, held map[string]bool) MarketAccess {
+	access := MarketAccess{Pair: m.MarketId, TradingStatus: m.TradingStatus}
+	var missing []string
+	for _, c := range []string{m.BaseCurrency, m.CounterCurrency} {
+		if c != "" && !held[c] {
+			missing = append(missing, c)
+		}
+	}
+	switch {
+	case len(missing) > 0:
+		access.Reason = fmt.Sprintf("No %s account: Luno probably does not offer %s in the user's country.", strings.Join(missing, " or "), strings.Join(missing, " and "))
+	case m.TradingStatus != luno.TradingStatusActive:
+		access.CanTrade = m.TradingStatus == luno.TradingStatusPost_only
+		access.Reason = describeTradingStatus(m.TradingStatus)
+	default:
+		access.CanTrade = true
+	}
+	return access
+}
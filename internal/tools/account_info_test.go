@@ -0,0 +1,120 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/markets"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleGetAccountInfo(t *testing.T) {
+	ctx := context.Background()
+	balances := &luno.GetBalancesResponse{Balance: []luno.AccountBalance{
+		{AccountId: "1", Asset: "ZAR"},
+		{AccountId: "2", Asset: "XBT"},
+		{AccountId: "3", Asset: "XBT", Name: "Savings"},
+	}}
+	listed := &luno.MarketsResponse{Markets: []luno.MarketInfo{
+		{MarketId: "XBTZAR", BaseCurrency: "XBT", CounterCurrency: "ZAR", TradingStatus: luno.TradingStatusActive},
+		{MarketId: "XBTNGN", BaseCurrency: "XBT", CounterCurrency: "NGN", TradingStatus: luno.TradingStatusActive},
+		{MarketId: "XBTZARP", BaseCurrency: "XBT", CounterCurrency: "ZAR", TradingStatus: luno.TradingStatusPost_only},
+		{MarketId: "XBTZARS", BaseCurrency: "XBT", CounterCurrency: "ZAR", TradingStatus: luno.TradingStatusSuspended},
+	}}
+
+	tests := []struct {
+		name            string
+		args            map[string]any
+		isAuthenticated bool
+		noBalances      bool
+		feesErr         error
+		fees            *luno.GetFeeInfoResponse
+		expMarkets      []MarketAccess
+		expFees         *AccountFees
+		expNotes        int
+		errorContains   string
+	}{
+		{
+			name:            "all markets",
+			args:            map[string]any{},
+			isAuthenticated: true,
+			expMarkets: []MarketAccess{
+				{Pair: "XBTNGN", TradingStatus: luno.TradingStatusActive, Reason: "No NGN account: Luno probably does not offer NGN in the user's country."},
+				{Pair: "XBTZAR", TradingStatus: luno.TradingStatusActive, CanTrade: true},
+				{Pair: "XBTZARP", TradingStatus: luno.TradingStatusPost_only, CanTrade: true, Reason: describeTradingStatus(luno.TradingStatusPost_only)},
+				{Pair: "XBTZARS", TradingStatus: luno.TradingStatusSuspended, Reason: describeTradingStatus(luno.TradingStatusSuspended)},
+			},
+			expNotes: 1,
+		},
+		{
+			name:            "one market with fees",
+			args:            map[string]any{"pair": "xbt-zar"},
+			isAuthenticated: true,
+			fees:            &luno.GetFeeInfoResponse{MakerFee: "0.001", TakerFee: "0.002", ThirtyDayVolume: "1.5"},
+			expMarkets:      []MarketAccess{{Pair: "XBTZAR", TradingStatus: luno.TradingStatusActive, CanTrade: true}},
+			expFees:         &AccountFees{Pair: "XBTZAR", MakerFee: "0.001", TakerFee: "0.002", ThirtyDayVolume: "1.5"},
+			expNotes:        1,
+		},
+		{
+			name:            "fees not readable",
+			args:            map[string]any{"pair": "XBTZAR"},
+			isAuthenticated: true,
+			feesErr:         errors.New("permission denied"),
+			expMarkets:      []MarketAccess{{Pair: "XBTZAR", TradingStatus: luno.TradingStatusActive, CanTrade: true}},
+			expNotes:        2,
+		},
+		{
+			name:            "unknown market",
+			args:            map[string]any{"pair": "DOGEZAR"},
+			isAuthenticated: true,
+			errorContains:   "Unable to get account info for DOGEZAR",
+		},
+		{
+			name:            "not authenticated",
+			args:            map[string]any{},
+			isAuthenticated: false,
+			noBalances:      true,
+			errorContains:   ErrAPICredentialsRequired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := sdk.NewMockLunoClient(t)
+			if !tt.noBalances {
+				mockClient.EXPECT().GetBalances(ctx, &luno.GetBalancesRequest{}).Return(balances, nil)
+				mockClient.EXPECT().Markets(ctx, &luno.MarketsRequest{}).Return(listed, nil)
+			}
+			if tt.fees != nil || tt.feesErr != nil {
+				mockClient.EXPECT().GetFeeInfo(ctx, &luno.GetFeeInfoRequest{Pair: "XBTZAR"}).Return(tt.fees, tt.feesErr)
+			}
+			cfg := &config.Config{LunoClient: mockClient, IsAuthenticated: tt.isAuthenticated, AllowWriteOperations: true, Markets: markets.NewCache(mockClient)}
+
+			result, err := HandleGetAccountInfo(cfg)(ctx, createMockRequest(tt.args))
+			require.NoError(t, err)
+			text := getTextContentFromResult(t, result)
+			if tt.errorContains != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, text, tt.errorContains)
+				return
+			}
+			require.False(t, result.IsError, text)
+
+			var got AccountInfo
+			require.NoError(t, json.Unmarshal([]byte(text), &got))
+			assert.True(t, got.WriteOperationsEnabled)
+			assert.False(t, got.WithdrawalsEnabled)
+			assert.Equal(t, []string{"XBT", "ZAR"}, got.Currencies)
+			assert.Equal(t, 3, got.Accounts)
+			assert.Equal(t, tt.expMarkets, got.Markets)
+			assert.Equal(t, tt.expFees, got.Fees)
+			assert.Len(t, got.Notes, tt.expNotes)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// accountParams are the parameters for tools that act on one account. Either
+// can identify it: account_id also accepts an asset or account name.
+func accountParams() []mcp.ToolOption {
+	return []mcp.ToolOption{
+		mcp.WithString(
+			"account_id",
+			mcp.Description("Account ID. An alias set with alias_account, an asset code such as XBT or an account name is also accepted"),
+		),
+		mcp.WithString(
+			"asset",
+			mcp.Description("Asset of the account, e.g. XBT, when account_id is not known"),
+		),
+	}
+}
+
+// accountArgs are the arguments of accountParams, embedded in the arguments
+// of each tool that acts on one account
+type accountArgs struct {
+	AccountID string `json:"account_id"`
+	Asset     string `json:"asset"`
+}
+
+// resolveAccount returns the ID of the account identified by the account_id
+// or asset argument. Non-numeric values are looked up in the account aliases,
+// then matched against the asset and name of each account. If no account, or more than one, matches, the result
+// to return to the caller is given instead.
+func resolveAccount(ctx context.Context, cfg *config.Config, args accountArgs) (int64, *mcp.CallToolResult) {
+	ref := strings.TrimSpace(args.AccountID)
+	if ref == "" {
+		ref = strings.TrimSpace(args.Asset)
+	}
+	if ref == "" {
+		return 0, mcp.NewToolResultError("account_id is required: give an account ID, or an asset such as XBT or an account name to look it up")
+	}
+	if id, err := strconv.ParseInt(ref, 10, 64); err == nil {
+		return id, nil
+	}
+	if cfg.Aliases != nil {
+		alias, ok, err := cfg.Aliases.Lookup(cfg.Tenant, ref)
+		if err != nil {
+			return 0, mcp.NewToolResultErrorFromErr("reading account aliases", err)
+		}
+		if ok {
+			id, err := strconv.ParseInt(alias.AccountID, 10, 64)
+			if err != nil {
+				return 0, mcp.NewToolResultError(fmt.Sprintf("Alias %q has an invalid account ID %q", alias.Name, alias.AccountID))
+			}
+			return id, nil
+		}
+	}
+
+	balances, err := cfg.LunoClient.GetBalances(ctx, &luno.GetBalancesRequest{})
+	if err != nil {
+		return 0, mcp.NewToolResultError(fmt.Sprintf("Failed to look up account %q: %v", ref, err))
+	}
+
+	matches := matchAccounts(balances.Balance, ref)
+	switch len(matches) {
+	case 0:
+		return 0, mcp.NewToolResultError(fmt.Sprintf("No account matches %q. Your accounts are:\n%s", ref, describeAccounts(balances.Balance)))
+	case 1:
+		id, err := strconv.ParseInt(matches[0].AccountId, 10, 64)
+		if err != nil {
+			return 0, mcp.NewToolResultError(fmt.Sprintf("Account %q has an invalid ID %q", ref, matches[0].AccountId))
+		}
+		return id, nil
+	default:
+		return 0, mcp.NewToolResultError(fmt.Sprintf("%d accounts match %q. Call again with the account_id of one of them:\n%s", len(matches), ref, describeAccounts(matches)))
+	}
+}
+
+// matchAccounts returns the accounts holding asset ref, or failing that, the
+// accounts named ref, ignoring case
+func matchAccounts(accounts []luno.AccountBalance, ref string) []luno.AccountBalance {
+	asset := normalizeCurrency(ref)
+	byAsset := slices.DeleteFunc(slices.Clone(accounts), func(a luno.AccountBalance) bool { return a.Asset != asset })
+	if len(byAsset) > 0 {
+		return byAsset
+	}
+	return slices.DeleteFunc(slices.Clone(accounts), func(a luno.AccountBalance) bool { return !strings.EqualFold(a.Name, ref) })
+}
+
+// describeAccounts lists accounts one per line for choosing between them
+func describeAccounts(accounts []luno.AccountBalance) string {
+	var sb strings.Builder
+	for _, a := range accounts {
+		fmt.Fprintf(&sb, "- account_id %s: %s", a.AccountId, a.Asset)
+		if a.Name != "" {
+			fmt.Fprintf(&sb, " %q", a.Name)
+		}
+		fmt.Fprintf(&sb, ", balance %s\n", a.Balance)
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
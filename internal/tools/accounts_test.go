@@ -0,0 +1,88 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/aliases"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveAccount(t *testing.T) {
+	accounts := []luno.AccountBalance{
+		{AccountId: "100", Asset: "XBT", Name: "Trading", Balance: NewFromString(t, "0.5")},
+		{AccountId: "101", Asset: "XBT", Name: "Cold storage", Balance: NewFromString(t, "2")},
+		{AccountId: "200", Asset: "ZAR", Name: "ZAR Account", Balance: NewFromString(t, "1000.00")},
+		{AccountId: "300", Asset: "ETH", Name: "", Balance: NewFromString(t, "1")},
+	}
+
+	tests := []struct {
+		name           string
+		args           accountArgs
+		balancesErr    error
+		noLookup       bool
+		expID          int64
+		expErrContains []string
+	}{
+		{name: "numeric id is used as is", args: accountArgs{AccountID: "123"}, noLookup: true, expID: 123},
+		{name: "single account for asset", args: accountArgs{Asset: "zar"}, expID: 200},
+		{name: "asset in account_id", args: accountArgs{AccountID: "ETH"}, expID: 300},
+		{name: "account name", args: accountArgs{AccountID: "cold storage"}, expID: 101},
+		{name: "alias", args: accountArgs{AccountID: "HODL"}, noLookup: true, expID: 101},
+		{
+			name: "several accounts for asset",
+			args: accountArgs{Asset: "BTC"},
+			expErrContains: []string{
+				`2 accounts match "BTC"`,
+				`- account_id 100: XBT "Trading", balance 0.5`,
+				`- account_id 101: XBT "Cold storage", balance 2`,
+			},
+		},
+		{
+			name:           "no match lists accounts",
+			args:           accountArgs{AccountID: "Savings"},
+			expErrContains: []string{`No account matches "Savings"`, "- account_id 300: ETH, balance 1"},
+		},
+		{
+			name:           "lookup fails",
+			args:           accountArgs{Asset: "XBT"},
+			balancesErr:    errors.New(apiErrorStr),
+			expErrContains: []string{`Failed to look up account "XBT"`, apiErrorStr},
+		},
+		{name: "nothing given", args: accountArgs{}, noLookup: true, expErrContains: []string{"account_id is required"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := sdk.NewMockLunoClient(t)
+			if !tt.noLookup {
+				res := &luno.GetBalancesResponse{Balance: accounts}
+				if tt.balancesErr != nil {
+					res = nil
+				}
+				mockClient.EXPECT().GetBalances(context.Background(), &luno.GetBalancesRequest{}).Return(res, tt.balancesErr)
+			}
+			store := aliases.New("")
+			_, err := store.Set("", "hodl", "101")
+			require.NoError(t, err)
+			cfg := &config.Config{LunoClient: mockClient, IsAuthenticated: true, Aliases: store}
+
+			id, errResult := resolveAccount(context.Background(), cfg, tt.args)
+			if len(tt.expErrContains) > 0 {
+				require.NotNil(t, errResult)
+				assert.True(t, errResult.IsError)
+				text := getTextContentFromResult(t, errResult)
+				for _, s := range tt.expErrContains {
+					assert.Contains(t, text, s)
+				}
+				return
+			}
+			require.Nil(t, errResult)
+			assert.Equal(t, tt.expID, id)
+		})
+	}
+}
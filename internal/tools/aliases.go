@@ -0,0 +1,117 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/aliases"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// NewAliasAccountTool creates a tool for naming accounts
+func NewAliasAccountTool() mcp.Tool {
+	opts := []mcp.ToolOption{
+		mcp.WithDescription("Give an account a friendly name such as \"spending\" or \"hodl\" that can then be used as the account_id of any tool. " +
+			"Aliases are stored locally and shown in get_balances. Call with only alias and remove=true to delete an alias, " +
+			"or with no parameters to list the aliases."),
+		mcp.WithString(
+			"alias",
+			mcp.Description(fmt.Sprintf("The alias (at most %d bytes, not a number)", aliases.MaxNameLength)),
+		),
+		mcp.WithBoolean(
+			"remove",
+			mcp.Description("Delete the alias instead of setting it (default: false)"),
+		),
+	}
+	return mcp.NewTool(AliasAccountToolID, slices.Concat(opts, accountParams())...)
+}
+
+// aliasAccountArgs are the arguments of the alias_account tool
+type aliasAccountArgs struct {
+	accountArgs
+	Alias  string `json:"alias"`
+	Remove bool   `json:"remove"`
+}
+
+// HandleAliasAccount handles the alias_account tool
+func HandleAliasAccount(cfg *config.Config) server.ToolHandlerFunc {
+	tool := NewAliasAccountTool()
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		cfg := cfg.ForContext(ctx)
+		if cfg.Aliases == nil {
+			return mcp.NewToolResultError("Account aliases are not available on this server"), nil
+		}
+
+		var args aliasAccountArgs
+		if errResult := bindArguments(request, tool, &args); errResult != nil {
+			return errResult, nil
+		}
+		name := args.Alias
+		if name == "" {
+			list, err := cfg.Aliases.List(cfg.Tenant)
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr("reading account aliases", err), nil
+			}
+			return aliasesResult(list)
+		}
+
+		if args.Remove {
+			removed, err := cfg.Aliases.Remove(cfg.Tenant, name)
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr("removing account alias", err), nil
+			}
+			if !removed {
+				return mcp.NewToolResultError(fmt.Sprintf("There is no alias %q", name)), nil
+			}
+			return mcp.NewToolResultText(fmt.Sprintf("Removed alias %q", name)), nil
+		}
+
+		if args.AccountID == "" && args.Asset == "" {
+			return mcp.NewToolResultError("account_id is required to set an alias; set remove=true to delete it"), nil
+		}
+		id, errResult := resolveAccount(ctx, cfg, args.accountArgs)
+		if errResult != nil {
+			return errResult, nil
+		}
+		accountID := strconv.FormatInt(id, 10)
+
+		// Check the account exists, so aliases don't end up against a mistyped ID
+		if cfg.IsAuthenticated {
+			balances, err := cfg.LunoClient.GetBalances(ctx, &luno.GetBalancesRequest{})
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr(fmt.Sprintf("looking up account %s", accountID), err), nil
+			}
+			if !slices.ContainsFunc(balances.Balance, func(b luno.AccountBalance) bool { return b.AccountId == accountID }) {
+				return mcp.NewToolResultError(fmt.Sprintf("No account has ID %s. Your accounts are:\n%s", accountID, describeAccounts(balances.Balance))), nil
+			}
+		}
+
+		alias, err := cfg.Aliases.Set(cfg.Tenant, name, accountID)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("saving account alias", err), nil
+		}
+		return aliasesResult(alias)
+	}
+}
+
+// accountAliases returns the aliases of each account, or none if aliases are
+// not available
+func accountAliases(cfg *config.Config) (map[string][]string, error) {
+	if cfg.Aliases == nil {
+		return nil, nil
+	}
+	return cfg.Aliases.ByAccount(cfg.Tenant)
+}
+
+func aliasesResult(v any) (*mcp.CallToolResult, error) {
+	resultJSON, err := marshalJSON(v)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal account aliases: %v", err)), nil
+	}
+	return mcp.NewToolResultText(resultJSON), nil
+}
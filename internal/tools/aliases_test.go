@@ -0,0 +1,170 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/aliases"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleAliasAccount(t *testing.T) {
+	ctx := context.Background()
+	balances := &luno.GetBalancesResponse{Balance: []luno.AccountBalance{
+		{AccountId: "100", Asset: "XBT", Name: "Trading", Balance: NewFromString(t, "0.5")},
+		{AccountId: "200", Asset: "ZAR", Name: "ZAR Account", Balance: NewFromString(t, "1000.00")},
+	}}
+
+	tests := []struct {
+		name            string
+		args            map[string]any
+		isAuthenticated bool
+		balanceLookups  int
+		expAliases      map[string]string
+		expContains     string
+		errorContains   string
+	}{
+		{
+			name:            "alias an account id",
+			args:            map[string]any{"alias": "hodl", "account_id": "100"},
+			isAuthenticated: true,
+			balanceLookups:  1,
+			expAliases:      map[string]string{"spending": "200", "hodl": "100"},
+			expContains:     `"alias": "hodl"`,
+		},
+		{
+			name:            "alias an asset",
+			args:            map[string]any{"alias": "hodl", "asset": "BTC"},
+			isAuthenticated: true,
+			balanceLookups:  2,
+			expAliases:      map[string]string{"spending": "200", "hodl": "100"},
+			expContains:     `"account_id": "100"`,
+		},
+		{
+			name:        "account not checked without credentials",
+			args:        map[string]any{"alias": "hodl", "account_id": "999"},
+			expAliases:  map[string]string{"spending": "200", "hodl": "999"},
+			expContains: `"account_id": "999"`,
+		},
+		{
+			name:            "replace an alias",
+			args:            map[string]any{"alias": "Spending", "account_id": "100"},
+			isAuthenticated: true,
+			balanceLookups:  1,
+			expAliases:      map[string]string{"Spending": "100"},
+			expContains:     `"alias": "Spending"`,
+		},
+		{
+			name:        "remove an alias",
+			args:        map[string]any{"alias": "SPENDING", "remove": true},
+			expAliases:  map[string]string{},
+			expContains: `Removed alias "SPENDING"`,
+		},
+		{
+			name:        "list aliases",
+			args:        map[string]any{},
+			expAliases:  map[string]string{"spending": "200"},
+			expContains: `"alias": "spending"`,
+		},
+		{
+			name:            "unknown account",
+			args:            map[string]any{"alias": "hodl", "account_id": "999"},
+			isAuthenticated: true,
+			balanceLookups:  1,
+			expAliases:      map[string]string{"spending": "200"},
+			errorContains:   "No account has ID 999",
+		},
+		{
+			name:          "numeric alias",
+			args:          map[string]any{"alias": "42", "account_id": "100"},
+			expAliases:    map[string]string{"spending": "200"},
+			errorContains: "alias must not be a number",
+		},
+		{
+			name:          "missing account",
+			args:          map[string]any{"alias": "hodl"},
+			expAliases:    map[string]string{"spending": "200"},
+			errorContains: "account_id is required to set an alias",
+		},
+		{
+			name:          "remove unknown alias",
+			args:          map[string]any{"alias": "hodl", "remove": true},
+			expAliases:    map[string]string{"spending": "200"},
+			errorContains: `There is no alias "hodl"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := sdk.NewMockLunoClient(t)
+			if tt.balanceLookups > 0 {
+				mockClient.EXPECT().GetBalances(ctx, &luno.GetBalancesRequest{}).Return(balances, nil).Times(tt.balanceLookups)
+			}
+			store := aliases.New("")
+			_, err := store.Set("", "spending", "200")
+			require.NoError(t, err)
+			cfg := &config.Config{LunoClient: mockClient, IsAuthenticated: tt.isAuthenticated, Aliases: store}
+
+			result, err := HandleAliasAccount(cfg)(ctx, createMockRequest(tt.args))
+			require.NoError(t, err)
+			text := getTextContentFromResult(t, result)
+			if tt.errorContains != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, text, tt.errorContains)
+			} else {
+				require.False(t, result.IsError, text)
+				assert.Contains(t, text, tt.expContains)
+			}
+
+			list, err := store.List("")
+			require.NoError(t, err)
+			got := make(map[string]string)
+			for _, a := range list {
+				got[a.Name] = a.AccountID
+			}
+			assert.Equal(t, tt.expAliases, got)
+		})
+	}
+}
+
+func TestHandleAliasAccountUnavailable(t *testing.T) {
+	result, err := HandleAliasAccount(&config.Config{})(context.Background(), createMockRequest(map[string]any{}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, getTextContentFromResult(t, result), "not available")
+}
+
+func TestHandleGetBalancesAliases(t *testing.T) {
+	ctx := context.Background()
+	mockClient := sdk.NewMockLunoClient(t)
+	mockClient.EXPECT().GetBalances(ctx, &luno.GetBalancesRequest{}).Return(&luno.GetBalancesResponse{Balance: []luno.AccountBalance{
+		{AccountId: "100", Asset: "XBT", Name: "Trading", Balance: NewFromString(t, "0.5")},
+		{AccountId: "200", Asset: "ZAR", Name: "ZAR Account", Balance: NewFromString(t, "1000.00")},
+	}}, nil)
+	store := aliases.New("")
+	for _, name := range []string{"hodl", "cold"} {
+		_, err := store.Set("", name, "100")
+		require.NoError(t, err)
+	}
+	cfg := &config.Config{LunoClient: mockClient, IsAuthenticated: true, Aliases: store}
+
+	result, err := HandleGetBalances(cfg)(ctx, createMockRequest(map[string]any{}))
+	require.NoError(t, err)
+	text := getTextContentFromResult(t, result)
+	require.False(t, result.IsError, text)
+
+	var views []BalanceView
+	require.NoError(t, json.Unmarshal([]byte(text), &views))
+	require.Len(t, views, 2)
+	assert.Equal(t, []string{"cold", "hodl"}, views[0].Aliases)
+	assert.Empty(t, views[1].Aliases)
+
+	out, ok := result.StructuredContent.(BalancesOutput)
+	require.True(t, ok)
+	assert.Equal(t, []string{"cold", "hodl"}, out.Balances[0].Aliases)
+}
@@ -0,0 +1,277 @@
+package tools
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/journal"
+)
+
+// Amount is a decimal amount in the structured output of a tool. Value is the
+// exact decimal as Luno returns it, and Units is the same amount as an integer
+// number of the smallest unit, 10^-Decimals of Currency. Clients can use either
+// without parsing a float and losing precision.
+type Amount struct {
+	Value string `json:"value"`
+	// Currency is omitted when it is not known, e.g. for a pair missing from
+	// the market list
+	Currency string      `json:"currency,omitempty"`
+	Decimals int         `json:"decimals"`
+	Units    json.Number `json:"units"`
+}
+
+// NewAmount returns d in currency as an Amount, keeping d's scale
+func NewAmount(d decimal.Decimal, currency string) Amount {
+	value := d.String()
+	decimals := decimalScale(d)
+	units := strings.TrimLeft(strings.Replace(strings.TrimPrefix(value, "-"), ".", "", 1), "0")
+	switch {
+	case units == "":
+		units = "0"
+	case d.Sign() < 0:
+		units = "-" + units
+	}
+	return Amount{Value: value, Currency: currency, Decimals: decimals, Units: json.Number(units)}
+}
+
+// decimalScale returns the number of decimal places in d
+func decimalScale(d decimal.Decimal) int {
+	s := d.String()
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		return len(s) - i - 1
+	}
+	return 0
+}
+
+// BalancesOutput is the structured output of get_balances
+type BalancesOutput struct {
+	Balances []BalanceAmounts `json:"balances"`
+	// ConvertTo, TotalValue and UnpricedAssets are set when convert_to is given
+	ConvertTo      string   `json:"convert_to,omitempty"`
+	TotalValue     *Amount  `json:"total_value,omitempty"`
+	UnpricedAssets []string `json:"unpriced_assets,omitempty"`
+}
+
+// BalanceAmounts is one account's balance in BalancesOutput
+type BalanceAmounts struct {
+	AccountID string `json:"account_id"`
+	Asset     string `json:"asset"`
+	Name      string `json:"name"`
+	// Aliases are the names given to the account with alias_account
+	Aliases     []string `json:"aliases,omitempty"`
+	Balance     Amount   `json:"balance"`
+	Reserved    Amount   `json:"reserved"`
+	Unconfirmed Amount   `json:"unconfirmed"`
+	// Value is the balance in the convert_to currency, when it could be priced
+	Value *Amount `json:"value,omitempty"`
+}
+
+func newBalancesOutput(balances []luno.AccountBalance) BalancesOutput {
+	out := BalancesOutput{Balances: make([]BalanceAmounts, 0, len(balances))}
+	for _, b := range balances {
+		out.Balances = append(out.Balances, BalanceAmounts{
+			AccountID:   b.AccountId,
+			Asset:       b.Asset,
+			Name:        b.Name,
+			Balance:     NewAmount(b.Balance, b.Asset),
+			Reserved:    NewAmount(b.Reserved, b.Asset),
+			Unconfirmed: NewAmount(b.Unconfirmed, b.Asset),
+		})
+	}
+	return out
+}
+
+// TickerAmounts is a ticker in structured output. Prices are in the pair's
+// counter currency and volume in its base currency.
+type TickerAmounts struct {
+	Pair                string      `json:"pair"`
+	Timestamp           luno.Time   `json:"timestamp"`
+	Status              luno.Status `json:"status"`
+	Bid                 Amount      `json:"bid"`
+	Ask                 Amount      `json:"ask"`
+	LastTrade           Amount      `json:"last_trade"`
+	Rolling24HourVolume Amount      `json:"rolling_24_hour_volume"`
+}
+
+func newTickerAmounts(t luno.Ticker, currencies pairCurrencies) TickerAmounts {
+	base, counter := currencies(t.Pair)
+	return TickerAmounts{
+		Pair:                t.Pair,
+		Timestamp:           t.Timestamp,
+		Status:              t.Status,
+		Bid:                 NewAmount(t.Bid, counter),
+		Ask:                 NewAmount(t.Ask, counter),
+		LastTrade:           NewAmount(t.LastTrade, counter),
+		Rolling24HourVolume: NewAmount(t.Rolling24HourVolume, base),
+	}
+}
+
+// TickersOutput is the structured output of get_tickers
+type TickersOutput struct {
+	Tickers []TickerAmounts `json:"tickers"`
+	Errors  []PairError     `json:"errors,omitempty"`
+}
+
+// TradesOutput is the structured output of list_trades
+type TradesOutput struct {
+	Trades []TradeAmounts `json:"trades"`
+}
+
+// TradeAmounts is a public trade in TradesOutput
+type TradeAmounts struct {
+	Sequence  int64     `json:"sequence"`
+	Timestamp luno.Time `json:"timestamp"`
+	IsBuy     bool      `json:"is_buy"`
+	Price     Amount    `json:"price"`
+	Volume    Amount    `json:"volume"`
+}
+
+func newTradesOutput(pair string, trades []luno.PublicTrade, currencies pairCurrencies) TradesOutput {
+	base, counter := currencies(pair)
+	out := TradesOutput{Trades: make([]TradeAmounts, 0, len(trades))}
+	for _, t := range trades {
+		out.Trades = append(out.Trades, TradeAmounts{
+			Sequence:  t.Sequence,
+			Timestamp: t.Timestamp,
+			IsBuy:     t.IsBuy,
+			Price:     NewAmount(t.Price, counter),
+			Volume:    NewAmount(t.Volume, base),
+		})
+	}
+	return out
+}
+
+// TransactionsOutput is the structured output of list_transactions
+type TransactionsOutput struct {
+	AccountID    string               `json:"account_id"`
+	Transactions []TransactionAmounts `json:"transactions"`
+}
+
+// TransactionAmounts is an account transaction in structured output
+type TransactionAmounts struct {
+	RowIndex       int64     `json:"row_index"`
+	Timestamp      luno.Time `json:"timestamp"`
+	Description    string    `json:"description"`
+	Kind           luno.Kind `json:"kind,omitempty"`
+	Reference      string    `json:"reference,omitempty"`
+	Balance        Amount    `json:"balance"`
+	Available      Amount    `json:"available"`
+	BalanceDelta   Amount    `json:"balance_delta"`
+	AvailableDelta Amount    `json:"available_delta"`
+}
+
+func newTransactionAmounts(t luno.Transaction) TransactionAmounts {
+	return TransactionAmounts{
+		RowIndex:       t.RowIndex,
+		Timestamp:      t.Timestamp,
+		Description:    t.Description,
+		Kind:           t.Kind,
+		Reference:      t.Reference,
+		Balance:        NewAmount(t.Balance, t.Currency),
+		Available:      NewAmount(t.Available, t.Currency),
+		BalanceDelta:   NewAmount(t.BalanceDelta, t.Currency),
+		AvailableDelta: NewAmount(t.AvailableDelta, t.Currency),
+	}
+}
+
+// OrdersOutput is the structured output of list_orders
+type OrdersOutput struct {
+	Orders            []OrderAmounts      `json:"orders"`
+	OpenOrders        int                 `json:"open_orders"`
+	Pairs             []PairOrdersAmounts `json:"pairs,omitempty"`
+	NextCreatedBefore int64               `json:"next_created_before,omitempty"`
+}
+
+// PairOrdersAmounts totals the open orders for one pair in OrdersOutput
+type PairOrdersAmounts struct {
+	Pair         string `json:"pair"`
+	OpenOrders   int    `json:"open_orders"`
+	BaseVolume   Amount `json:"base_volume"`
+	CounterValue Amount `json:"counter_value"`
+}
+
+func newOrdersOutput(result ListOrdersResult, currencies pairCurrencies) OrdersOutput {
+	out := OrdersOutput{
+		Orders:            make([]OrderAmounts, 0, len(result.Orders)),
+		OpenOrders:        result.Summary.OpenOrders,
+		NextCreatedBefore: result.NextCreatedBefore,
+	}
+	for _, o := range result.Orders {
+		out.Orders = append(out.Orders, newOrderAmounts(o, currencies))
+	}
+	for _, p := range result.Summary.Pairs {
+		base, counter := currencies(p.Pair)
+		out.Pairs = append(out.Pairs, PairOrdersAmounts{
+			Pair:         p.Pair,
+			OpenOrders:   p.OpenOrders,
+			BaseVolume:   NewAmount(p.BaseVolume, base),
+			CounterValue: NewAmount(p.CounterValue, counter),
+		})
+	}
+	return out
+}
+
+// OrderAmounts is an order in OrdersOutput. Prices and counter amounts are in
+// the pair's counter currency, volumes and base amounts in its base currency.
+type OrderAmounts struct {
+	OrderID           string          `json:"order_id"`
+	Pair              string          `json:"pair"`
+	Type              luno.OrderType  `json:"type"`
+	State             luno.OrderState `json:"state"`
+	CreationTimestamp luno.Time       `json:"creation_timestamp"`
+	LimitPrice        Amount          `json:"limit_price"`
+	LimitVolume       Amount          `json:"limit_volume"`
+	Base              Amount          `json:"base"`
+	Counter           Amount          `json:"counter"`
+	FeeBase           Amount          `json:"fee_base"`
+	FeeCounter        Amount          `json:"fee_counter"`
+	RemainingVolume   *Amount         `json:"remaining_volume,omitempty"`
+	MarketPrice       *Amount         `json:"market_price,omitempty"`
+	DistancePercent   *float64        `json:"distance_percent,omitempty"`
+	Notes             []journal.Entry `json:"notes,omitempty"`
+}
+
+func newOrderAmounts(o OrderView, currencies pairCurrencies) OrderAmounts {
+	base, counter := currencies(o.Pair)
+	out := OrderAmounts{
+		OrderID:           o.OrderId,
+		Pair:              o.Pair,
+		Type:              o.Type,
+		State:             o.State,
+		CreationTimestamp: o.CreationTimestamp,
+		LimitPrice:        NewAmount(o.LimitPrice, counter),
+		LimitVolume:       NewAmount(o.LimitVolume, base),
+		Base:              NewAmount(o.Base, base),
+		Counter:           NewAmount(o.Counter, counter),
+		FeeBase:           NewAmount(o.FeeBase, base),
+		FeeCounter:        NewAmount(o.FeeCounter, counter),
+		DistancePercent:   o.DistancePercent,
+		Notes:             o.Notes,
+	}
+	if o.State == luno.OrderStatePending {
+		remaining := NewAmount(o.RemainingVolume, base)
+		out.RemainingVolume = &remaining
+	}
+	if o.DistancePercent != nil {
+		market := NewAmount(o.MarketPrice, counter)
+		out.MarketPrice = &market
+	}
+	return out
+}
+
+// pairCurrencies returns the base and counter currencies of pair, or empty
+// strings if the pair is not known
+type pairCurrencies func(pair string) (base, counter string)
+
+// cachedPairCurrencies looks up currencies in the cached market list. It never
+// calls the API, so currencies are left out until the list has been loaded.
+func cachedPairCurrencies(cfg *config.Config) pairCurrencies {
+	cache := MarketCache(cfg)
+	return func(pair string) (string, string) {
+		m, _ := cache.Market(pair)
+		return m.BaseCurrency, m.CounterCurrency
+	}
+}
@@ -0,0 +1,147 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/markets"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAmount(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{value: "0.00150000", expected: `{"value":"0.00150000","currency":"XBT","decimals":8,"units":150000}`},
+		{value: "1250000.00", expected: `{"value":"1250000.00","currency":"XBT","decimals":2,"units":125000000}`},
+		{value: "42", expected: `{"value":"42","currency":"XBT","decimals":0,"units":42}`},
+		{value: "-0.5", expected: `{"value":"-0.5","currency":"XBT","decimals":1,"units":-5}`},
+		{value: "0.000", expected: `{"value":"0.000","currency":"XBT","decimals":3,"units":0}`},
+		{value: "123456789012345678.123456789", expected: `{"value":"123456789012345678.123456789","currency":"XBT","decimals":9,"units":123456789012345678123456789}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			b, err := json.Marshal(NewAmount(NewFromString(t, tt.value), "XBT"))
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(b))
+		})
+	}
+
+	b, err := json.Marshal(NewAmount(NewFromString(t, "1"), ""))
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), "currency", "unknown currencies are left out")
+}
+
+func TestStructuredAmounts(t *testing.T) {
+	ctx := context.Background()
+	mockClient := sdk.NewMockLunoClient(t)
+	cfg := &config.Config{LunoClient: mockClient, Markets: markets.NewCache(mockClient), IsAuthenticated: true}
+	mockClient.EXPECT().Markets(ctx, &luno.MarketsRequest{}).Return(&luno.MarketsResponse{Markets: []luno.MarketInfo{
+		{MarketId: "XBTZAR", BaseCurrency: "XBT", CounterCurrency: "ZAR"},
+	}}, nil).Once()
+	require.NoError(t, cfg.Markets.Refresh(ctx))
+
+	t.Run("get_ticker", func(t *testing.T) {
+		mockClient.EXPECT().GetTicker(ctx, &luno.GetTickerRequest{Pair: "XBTZAR"}).Return(&luno.GetTickerResponse{
+			Pair:                "XBTZAR",
+			Bid:                 NewFromString(t, "1250000.00"),
+			Ask:                 NewFromString(t, "1250100.00"),
+			LastTrade:           NewFromString(t, "1250050.00"),
+			Rolling24HourVolume: NewFromString(t, "12.345678"),
+		}, nil).Once()
+
+		result, err := HandleGetTicker(cfg)(ctx, createMockRequest(map[string]any{"pair": "XBTZAR"}))
+		require.NoError(t, err)
+		require.False(t, result.IsError)
+
+		ticker, ok := result.StructuredContent.(TickerAmounts)
+		require.True(t, ok)
+		assert.Equal(t, Amount{Value: "1250000.00", Currency: "ZAR", Decimals: 2, Units: "125000000"}, ticker.Bid)
+		assert.Equal(t, Amount{Value: "12.345678", Currency: "XBT", Decimals: 6, Units: "12345678"}, ticker.Rolling24HourVolume)
+		assert.Contains(t, getTextContentFromResult(t, result), `"bid": "1250000.00"`, "the text result is unchanged")
+	})
+
+	t.Run("get_balances", func(t *testing.T) {
+		mockClient.EXPECT().GetBalances(ctx, &luno.GetBalancesRequest{}).Return(&luno.GetBalancesResponse{
+			Balance: []luno.AccountBalance{{
+				AccountId:   "123456",
+				Asset:       "XBT",
+				Balance:     NewFromString(t, "0.00150000"),
+				Reserved:    NewFromString(t, "0.00000000"),
+				Unconfirmed: NewFromString(t, "0.00000000"),
+			}},
+		}, nil).Once()
+
+		result, err := HandleGetBalances(cfg)(ctx, createMockRequest(nil))
+		require.NoError(t, err)
+		require.False(t, result.IsError)
+
+		b, err := json.Marshal(result.StructuredContent)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"balances":[{
+			"account_id":"123456","asset":"XBT","name":"",
+			"balance":{"value":"0.00150000","currency":"XBT","decimals":8,"units":150000},
+			"reserved":{"value":"0.00000000","currency":"XBT","decimals":8,"units":0},
+			"unconfirmed":{"value":"0.00000000","currency":"XBT","decimals":8,"units":0}
+		}]}`, string(b))
+	})
+
+	t.Run("list_orders", func(t *testing.T) {
+		mockClient.EXPECT().ListOrders(ctx, &luno.ListOrdersRequest{Limit: 100}).Return(&luno.ListOrdersResponse{
+			Orders: []luno.Order{{
+				OrderId:     "BXMC2CJ7HNB88U4",
+				Pair:        "XBTZAR",
+				Type:        luno.OrderTypeBid,
+				State:       luno.OrderStatePending,
+				LimitPrice:  NewFromString(t, "1200000.00"),
+				LimitVolume: NewFromString(t, "0.0100"),
+				Base:        NewFromString(t, "0.0040"),
+				Counter:     NewFromString(t, "4800.00"),
+				FeeBase:     NewFromString(t, "0.0000"),
+				FeeCounter:  NewFromString(t, "0.00"),
+			}},
+		}, nil).Once()
+		mockClient.EXPECT().GetTickers(ctx, &luno.GetTickersRequest{Pair: []string{"XBTZAR"}}).
+			Return(nil, assert.AnError).Once()
+
+		result, err := HandleListOrders(cfg)(ctx, createMockRequest(nil))
+		require.NoError(t, err)
+		require.False(t, result.IsError)
+
+		orders, ok := result.StructuredContent.(OrdersOutput)
+		require.True(t, ok)
+		require.Len(t, orders.Orders, 1)
+		order := orders.Orders[0]
+		assert.Equal(t, Amount{Value: "1200000.00", Currency: "ZAR", Decimals: 2, Units: "120000000"}, order.LimitPrice)
+		assert.Equal(t, &Amount{Value: "0.0060", Currency: "XBT", Decimals: 4, Units: "60"}, order.RemainingVolume)
+		assert.Nil(t, order.MarketPrice, "no market price without a ticker")
+		require.Len(t, orders.Pairs, 1)
+		assert.Equal(t, "XBT", orders.Pairs[0].BaseVolume.Currency)
+		assert.Equal(t, "ZAR", orders.Pairs[0].CounterValue.Currency)
+	})
+
+	t.Run("unknown pair has no currency", func(t *testing.T) {
+		mockClient.EXPECT().ListTrades(ctx, &luno.ListTradesRequest{Pair: "ABCDEF"}).Return(&luno.ListTradesResponse{
+			Trades: []luno.PublicTrade{{Sequence: 1, Price: NewFromString(t, "10.5"), Volume: NewFromString(t, "2")}},
+		}, nil).Once()
+
+		result, err := HandleListTrades(cfg)(ctx, createMockRequest(map[string]any{"pair": "ABCDEF"}))
+		require.NoError(t, err)
+		require.False(t, result.IsError)
+
+		trades, ok := result.StructuredContent.(TradesOutput)
+		require.True(t, ok)
+		assert.Equal(t, []TradeAmounts{{
+			Sequence: 1,
+			Price:    Amount{Value: "10.5", Decimals: 1, Units: "105"},
+			Volume:   Amount{Value: "2", Units: "2"},
+		}}, trades.Trades)
+	})
+}
@@ -0,0 +1,236 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/internal/approvals"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/validate"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// NewGetApprovalStatusTool creates a tool for checking on an action held for approval
+func NewGetApprovalStatusTool() mcp.Tool {
+	return mcp.NewTool(
+		GetApprovalStatusToolID,
+		mcp.WithDescription("Check on an order or withdrawal held for approval because of its value. "+
+			"While the status is pending, tell the user it is waiting for an approver and check again later rather than in a loop. "+
+			"Once approved, call the original tool again with exactly the same arguments to run it; rejected and expired actions must not be retried."),
+		mcp.WithString(
+			"approval_id",
+			mcp.Required(),
+			mcp.Description("ID of the approval request, from the pending_approval result of the held call"),
+		),
+	)
+}
+
+// ApprovalStatus is the get_approval_status result
+type ApprovalStatus struct {
+	approvals.Request
+	Next string `json:"next"`
+}
+
+// HandleGetApprovalStatus handles the get_approval_status tool. Sessions
+// only see their own requests.
+func HandleGetApprovalStatus(cfg *config.Config) server.ToolHandlerFunc {
+	tool := NewGetApprovalStatusTool()
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if cfg.Approvals == nil {
+			return mcp.NewToolResultError("Actions are not held for approval on this server"), nil
+		}
+		var args struct {
+			ApprovalID string `json:"approval_id"`
+		}
+		if errResult := bindArguments(request, tool, &args); errResult != nil {
+			return errResult, nil
+		}
+		r, ok := cfg.Approvals.Get(args.ApprovalID)
+		if !ok || r.SessionID != sessionID(ctx) {
+			return mcp.NewToolResultError(fmt.Sprintf("No approval request %s; it may have been dropped after it was decided", args.ApprovalID)), nil
+		}
+
+		resultJSON, err := marshalJSON(ApprovalStatus{Request: r, Next: ApprovalNext(r)})
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal approval status: %v", err)), nil
+		}
+		return mcp.NewToolResultText(resultJSON), nil
+	}
+}
+
+// ApprovalNext tells the caller what to do about a request in its status
+func ApprovalNext(r approvals.Request) string {
+	switch r.Status {
+	case approvals.StatusPending:
+		return fmt.Sprintf("Waiting for an approver. Tell the user, and check again with %s later.", GetApprovalStatusToolID)
+	case approvals.StatusApproved:
+		return fmt.Sprintf("Approved. Call %s again with exactly the same arguments to run it before %s.", r.Tool, r.ExpiresAt.Format("2006-01-02T15:04:05Z"))
+	case approvals.StatusRejected:
+		return "Rejected by the approver. Do not retry; tell the user, with the reason if given."
+	case approvals.StatusExpired:
+		return "Expired without being run. Do not retry unless the user asks to submit it for approval again."
+	default:
+		return "Already run. Check its result with list_orders or list_fiat_withdrawals."
+	}
+}
+
+// ActionAmounts returns what a call to tool with args is worth, for holding
+// high-value actions for approval. The arguments are converted and defaulted
+// as the tool's handler does, so a call is valued as it would run. The bool is
+// false for calls that aren't actions: tools that don't trade or withdraw,
+// previews without confirm, actions such as status checks, and calls with
+// invalid arguments, which the tool rejects itself. An error means the call is
+// an action but its worth couldn't be worked out.
+func ActionAmounts(ctx context.Context, cfg *config.Config, tool string, args map[string]any) ([]approvals.Amount, bool, error) {
+	switch tool {
+	case CreateOrderToolID:
+		var a createOrderArgs
+		if !decodeArgs(NewCreateOrderTool(), args, &a) {
+			return nil, false, nil
+		}
+		return orderAmounts(ctx, cfg, a.Pair, a.Volume, a.Price)
+
+	case ReplaceOrderToolID:
+		var a replaceOrderArgs
+		if !decodeArgs(NewReplaceOrderTool(), args, &a) || a.OrderID == "" || (a.Price == nil && a.Volume == nil) {
+			return nil, false, nil
+		}
+		// The replacement defaults to the original's price and unfilled volume
+		original, err := cfg.LunoClient.GetOrder(ctx, &luno.GetOrderRequest{Id: a.OrderID})
+		if err != nil {
+			return nil, true, fmt.Errorf("failed to get order %s: %w", a.OrderID, err)
+		}
+		if original.State != luno.OrderStatePending {
+			return nil, false, nil
+		}
+		volume, price := original.LimitVolume.Sub(original.Base), original.LimitPrice
+		if a.Volume != nil {
+			volume = *a.Volume
+		}
+		if a.Price != nil {
+			price = *a.Price
+		}
+		return orderAmounts(ctx, cfg, original.Pair, volume, price)
+
+	case CreateMarketOrderToolID:
+		var a marketOrderArgs
+		if !decodeArgs(NewCreateMarketOrderTool(), args, &a) || !a.Confirm {
+			return nil, false, nil
+		}
+		base, counter, ok, err := pairCurrenciesOf(ctx, cfg, a.Pair)
+		if !ok || err != nil {
+			return nil, ok, err
+		}
+		switch {
+		case a.Type == "BUY" && a.CounterVolume != nil:
+			return []approvals.Amount{{Currency: counter, Value: *a.CounterVolume}}, true, nil
+		case a.Type == "SELL" && a.BaseVolume != nil:
+			// Valued in the counter currency too, at the market price, so
+			// that counter currency thresholds apply to sells
+			price, err := marketPrice(ctx, cfg, normalizeCurrencyPair(a.Pair))
+			if err != nil {
+				return []approvals.Amount{{Currency: base, Value: *a.BaseVolume}}, true, fmt.Errorf("failed to get the market price of %s: %w", a.Pair, err)
+			}
+			return orderAmounts(ctx, cfg, a.Pair, *a.BaseVolume, price)
+		}
+		return nil, false, nil
+
+	case ExecuteTWAPToolID:
+		a := twapArgs{Action: twapActionStart}
+		if !decodeArgs(NewExecuteTWAPTool(), args, &a) || a.Action != twapActionStart {
+			return nil, false, nil
+		}
+		return orderAmounts(ctx, cfg, a.Pair, a.Volume, a.LimitPrice)
+
+	case IcebergOrderToolID:
+		a := icebergArgs{Action: icebergActionStart}
+		if !decodeArgs(NewIcebergOrderTool(), args, &a) || a.Action != icebergActionStart {
+			return nil, false, nil
+		}
+		return orderAmounts(ctx, cfg, a.Pair, a.Volume, a.Price)
+
+	case CreateOrdersBatchToolID:
+		var a ordersBatchArgs
+		var orders []BatchOrder
+		if !decodeArgs(NewCreateOrdersBatchTool(), args, &a) || !a.Confirm || json.Unmarshal([]byte(a.Orders), &orders) != nil {
+			return nil, false, nil
+		}
+		totals := make(map[string]decimal.Decimal)
+		var currencies []string
+		for _, o := range orders {
+			volume, err1 := decimal.NewFromString(o.Volume)
+			price, err2 := decimal.NewFromString(o.Price)
+			if err1 != nil || err2 != nil {
+				return nil, false, nil
+			}
+			amounts, ok, err := orderAmounts(ctx, cfg, o.Pair, volume, price)
+			if !ok || err != nil {
+				return nil, ok, err
+			}
+			for _, amount := range amounts {
+				if _, seen := totals[amount.Currency]; !seen {
+					currencies = append(currencies, amount.Currency)
+					totals[amount.Currency] = decimal.Zero()
+				}
+				totals[amount.Currency] = totals[amount.Currency].Add(amount.Value)
+			}
+		}
+		out := make([]approvals.Amount, 0, len(currencies))
+		for _, c := range currencies {
+			out = append(out, approvals.Amount{Currency: c, Value: totals[c]})
+		}
+		return out, true, nil
+
+	case CreateFiatWithdrawalToolID:
+		var a createWithdrawalArgs
+		if !decodeArgs(NewCreateFiatWithdrawalTool(), args, &a) || !a.Confirm {
+			return nil, false, nil
+		}
+		// Withdrawal types start with their currency, e.g. ZAR_EFT
+		currency, _, _ := strings.Cut(strings.ToUpper(a.Type), "_")
+		return []approvals.Amount{{Currency: currency, Value: a.Amount}}, true, nil
+	}
+	return nil, false, nil
+}
+
+// orderAmounts returns what an order of volume on pair at price is worth in
+// the pair's base and counter currencies. Orders without a price, such as
+// TWAP orders without a limit, are only valued in the base currency.
+func orderAmounts(ctx context.Context, cfg *config.Config, pair string, volume, price decimal.Decimal) ([]approvals.Amount, bool, error) {
+	base, counter, ok, err := pairCurrenciesOf(ctx, cfg, pair)
+	if !ok || err != nil {
+		return nil, ok, err
+	}
+	amounts := []approvals.Amount{{Currency: base, Value: volume}}
+	if price.Sign() > 0 {
+		amounts = append(amounts, approvals.Amount{Currency: counter, Value: volume.Mul(price)})
+	}
+	return amounts, true, nil
+}
+
+// pairCurrenciesOf looks up the currencies of pair. The bool is false for
+// unknown pairs, which the tools reject.
+func pairCurrenciesOf(ctx context.Context, cfg *config.Config, pair string) (base, counter string, ok bool, err error) {
+	m, ok, err := MarketCache(cfg).Lookup(ctx, normalizeCurrencyPair(pair))
+	if err != nil {
+		return "", "", true, fmt.Errorf("failed to look up the currencies of %s: %w", pair, err)
+	}
+	return m.BaseCurrency, m.CounterCurrency, ok, nil
+}
+
+// decodeArgs converts a call's arguments to the types of tool's schema, as
+// bindArguments does, and decodes them into v, reporting whether they fit.
+// Fields of v already set are defaults for arguments not given.
+func decodeArgs(tool mcp.Tool, args map[string]any, v any) bool {
+	converted, errs := validate.Arguments(tool.InputSchema, args)
+	if len(errs) > 0 {
+		return false
+	}
+	b, err := json.Marshal(converted)
+	return err == nil && json.Unmarshal(b, v) == nil
+}
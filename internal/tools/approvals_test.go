@@ -0,0 +1,237 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/approvals"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/markets"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActionAmounts(t *testing.T) {
+	ctx := context.Background()
+	mockClient := sdk.NewMockLunoClient(t)
+	cfg := &config.Config{LunoClient: mockClient, Markets: markets.NewCache(mockClient)}
+	mockClient.EXPECT().Markets(ctx, &luno.MarketsRequest{}).Return(&luno.MarketsResponse{Markets: []luno.MarketInfo{
+		{MarketId: "XBTZAR", BaseCurrency: "XBT", CounterCurrency: "ZAR"},
+		{MarketId: "ETHZAR", BaseCurrency: "ETH", CounterCurrency: "ZAR"},
+	}}, nil).Once()
+	require.NoError(t, cfg.Markets.Refresh(ctx))
+	mockClient.EXPECT().GetOrder(ctx, &luno.GetOrderRequest{Id: "BXOPEN"}).Return(&luno.GetOrderResponse{
+		OrderId:     "BXOPEN",
+		Pair:        "XBTZAR",
+		State:       luno.OrderStatePending,
+		LimitVolume: NewFromString(t, "1"),
+		LimitPrice:  NewFromString(t, "1000000"),
+		Base:        NewFromString(t, "0.25"),
+	}, nil)
+	mockClient.EXPECT().GetOrder(ctx, &luno.GetOrderRequest{Id: "BXDONE"}).Return(&luno.GetOrderResponse{
+		OrderId: "BXDONE",
+		Pair:    "XBTZAR",
+		State:   luno.OrderStateComplete,
+	}, nil)
+	mockClient.EXPECT().GetTicker(ctx, &luno.GetTickerRequest{Pair: "XBTZAR"}).Return(&luno.GetTickerResponse{
+		Bid: NewFromString(t, "990000"),
+		Ask: NewFromString(t, "1010000"),
+	}, nil)
+
+	amount := func(currency, value string) approvals.Amount {
+		return approvals.Amount{Currency: currency, Value: NewFromString(t, value)}
+	}
+	tests := []struct {
+		name       string
+		tool       string
+		args       map[string]any
+		expAction  bool
+		expAmounts []approvals.Amount
+	}{
+		{
+			name:       "limit order",
+			tool:       CreateOrderToolID,
+			args:       map[string]any{"pair": "XBTZAR", "type": "BUY", "volume": "0.5", "price": "1000000"},
+			expAction:  true,
+			expAmounts: []approvals.Amount{amount("XBT", "0.5"), amount("ZAR", "500000.0")},
+		},
+		{
+			name:       "replacement volume",
+			tool:       ReplaceOrderToolID,
+			args:       map[string]any{"order_id": "BXOPEN", "volume": "5"},
+			expAction:  true,
+			expAmounts: []approvals.Amount{amount("XBT", "5"), amount("ZAR", "5000000")},
+		},
+		{
+			name:       "replacement price of the unfilled volume",
+			tool:       ReplaceOrderToolID,
+			args:       map[string]any{"order_id": "BXOPEN", "price": "2000000"},
+			expAction:  true,
+			expAmounts: []approvals.Amount{amount("XBT", "0.75"), amount("ZAR", "1500000")},
+		},
+		{
+			name: "replacing a completed order",
+			tool: ReplaceOrderToolID,
+			args: map[string]any{"order_id": "BXDONE", "price": "2000000"},
+		},
+		{
+			name: "market order preview",
+			tool: CreateMarketOrderToolID,
+			args: map[string]any{"pair": "XBTZAR", "type": "BUY", "counter_volume": "20000"},
+		},
+		{
+			name:       "market order",
+			tool:       CreateMarketOrderToolID,
+			args:       map[string]any{"pair": "XBTZAR", "type": "BUY", "counter_volume": "20000", "confirm": true},
+			expAction:  true,
+			expAmounts: []approvals.Amount{amount("ZAR", "20000")},
+		},
+		{
+			name:       "market order confirmed as a string",
+			tool:       CreateMarketOrderToolID,
+			args:       map[string]any{"pair": "XBTZAR", "type": "BUY", "counter_volume": "20000", "confirm": "true"},
+			expAction:  true,
+			expAmounts: []approvals.Amount{amount("ZAR", "20000")},
+		},
+		{
+			name:       "market sell valued at the market price",
+			tool:       CreateMarketOrderToolID,
+			args:       map[string]any{"pair": "XBTZAR", "type": "SELL", "base_volume": "2", "confirm": true},
+			expAction:  true,
+			expAmounts: []approvals.Amount{amount("XBT", "2"), amount("ZAR", "2000000")},
+		},
+		{
+			name:       "TWAP without a limit",
+			tool:       ExecuteTWAPToolID,
+			args:       map[string]any{"action": "start", "pair": "XBTZAR", "side": "SELL", "volume": "2", "slices": 4, "duration": "1h"},
+			expAction:  true,
+			expAmounts: []approvals.Amount{amount("XBT", "2")},
+		},
+		{
+			name:       "TWAP without an action starts",
+			tool:       ExecuteTWAPToolID,
+			args:       map[string]any{"pair": "XBTZAR", "side": "BUY", "volume": "2", "limit_price": "1000000", "slices": 4, "duration": "1h"},
+			expAction:  true,
+			expAmounts: []approvals.Amount{amount("XBT", "2"), amount("ZAR", "2000000")},
+		},
+		{
+			name:       "iceberg without an action starts",
+			tool:       IcebergOrderToolID,
+			args:       map[string]any{"pair": "XBTZAR", "side": "BUY", "volume": "3", "price": "1000000", "visible_volume": "0.5"},
+			expAction:  true,
+			expAmounts: []approvals.Amount{amount("XBT", "3"), amount("ZAR", "3000000")},
+		},
+		{
+			name: "iceberg status",
+			tool: IcebergOrderToolID,
+			args: map[string]any{"action": "STATUS"},
+		},
+		{
+			name: "TWAP status",
+			tool: ExecuteTWAPToolID,
+			args: map[string]any{"action": "status", "id": "twap-1"},
+		},
+		{
+			name:       "batch",
+			tool:       CreateOrdersBatchToolID,
+			args:       map[string]any{"orders": `[{"pair": "XBTZAR", "type": "BUY", "volume": "0.1", "price": "1000000"}, {"pair": "ETHZAR", "type": "BUY", "volume": "1", "price": "50000"}]`, "confirm": true},
+			expAction:  true,
+			expAmounts: []approvals.Amount{amount("XBT", "0.1"), amount("ZAR", "150000"), amount("ETH", "1")},
+		},
+		{
+			name:       "batch confirmed as a string",
+			tool:       CreateOrdersBatchToolID,
+			args:       map[string]any{"orders": `[{"pair": "XBTZAR", "type": "BUY", "volume": "0.1", "price": "1000000"}]`, "confirm": "true"},
+			expAction:  true,
+			expAmounts: []approvals.Amount{amount("XBT", "0.1"), amount("ZAR", "100000")},
+		},
+		{
+			name:       "withdrawal confirmed as a string",
+			tool:       CreateFiatWithdrawalToolID,
+			args:       map[string]any{"type": "ZAR_EFT", "amount": "75000", "beneficiary_id": "123", "confirm": "true"},
+			expAction:  true,
+			expAmounts: []approvals.Amount{amount("ZAR", "75000")},
+		},
+		{
+			name:       "withdrawal",
+			tool:       CreateFiatWithdrawalToolID,
+			args:       map[string]any{"type": "ZAR_EFT", "amount": "75000", "beneficiary_id": "123", "confirm": true},
+			expAction:  true,
+			expAmounts: []approvals.Amount{amount("ZAR", "75000")},
+		},
+		{
+			name: "unknown pair",
+			tool: CreateOrderToolID,
+			args: map[string]any{"pair": "DOGEZAR", "type": "BUY", "volume": "1", "price": "1"},
+		},
+		{
+			name: "invalid arguments",
+			tool: CreateOrderToolID,
+			args: map[string]any{"pair": "XBTZAR", "type": "BUY", "volume": "lots", "price": "1"},
+		},
+		{
+			name: "not an action",
+			tool: CancelOrderToolID,
+			args: map[string]any{"order_id": "BXMC2CJ7HNB88U4"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amounts, action, err := ActionAmounts(ctx, cfg, tt.tool, tt.args)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expAction, action)
+			require.Len(t, amounts, len(tt.expAmounts))
+			for i, want := range tt.expAmounts {
+				assert.Equal(t, want.Currency, amounts[i].Currency)
+				assert.Zero(t, want.Value.Cmp(amounts[i].Value), "%s: want %s, got %s", want.Currency, want.Value, amounts[i].Value)
+			}
+		})
+	}
+
+	t.Run("markets unavailable", func(t *testing.T) {
+		failing := sdk.NewMockLunoClient(t)
+		failing.EXPECT().Markets(ctx, &luno.MarketsRequest{}).Return(nil, errors.New("unavailable"))
+		cfg := &config.Config{LunoClient: failing, Markets: markets.NewCache(failing)}
+		_, action, err := ActionAmounts(ctx, cfg, CreateOrderToolID, map[string]any{"pair": "XBTZAR", "type": "BUY", "volume": "1", "price": "1"})
+		assert.True(t, action, "orders that can't be valued are still actions")
+		assert.Error(t, err)
+	})
+}
+
+func TestHandleGetApprovalStatus(t *testing.T) {
+	ctx := context.Background()
+	thresholds, err := approvals.ParseThresholds("ZAR:1000")
+	require.NoError(t, err)
+	cfg := &config.Config{Approvals: approvals.NewQueue(thresholds, time.Minute)}
+	r, err := cfg.Approvals.Park("", CreateOrderToolID, map[string]any{"pair": "XBTZAR"}, nil, nil, "")
+	require.NoError(t, err)
+	other, err := cfg.Approvals.Park("another-session", CreateOrderToolID, map[string]any{"pair": "XBTZAR"}, nil, nil, "")
+	require.NoError(t, err)
+
+	result, err := HandleGetApprovalStatus(cfg)(ctx, createMockRequest(map[string]any{"approval_id": r.ID}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	var status ApprovalStatus
+	require.NoError(t, json.Unmarshal([]byte(getTextContentFromResult(t, result)), &status))
+	assert.Equal(t, approvals.StatusPending, status.Status)
+	assert.Contains(t, status.Next, "Waiting for an approver")
+
+	_, err = cfg.Approvals.Decide(r.ID, true, "admin", "")
+	require.NoError(t, err)
+	result, err = HandleGetApprovalStatus(cfg)(ctx, createMockRequest(map[string]any{"approval_id": r.ID}))
+	require.NoError(t, err)
+	assert.Contains(t, getTextContentFromResult(t, result), "exactly the same arguments")
+
+	result, err = HandleGetApprovalStatus(cfg)(ctx, createMockRequest(map[string]any{"approval_id": other.ID}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError, "sessions only see their own requests")
+
+	result, err = HandleGetApprovalStatus(&config.Config{})(ctx, createMockRequest(map[string]any{"approval_id": r.ID}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
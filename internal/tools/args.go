@@ -0,0 +1,42 @@
+package tools
+
+import (
+	"fmt"
+
+	"github.com/luno/luno-mcp/internal/validate"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// bindArguments checks the arguments of request against the input schema of
+// tool and decodes them into args, a pointer to the tool's argument struct.
+// Fields keep their value when their argument isn't given, so args should hold
+// the defaults. If the arguments are invalid, the result to return to the
+// caller is given instead.
+func bindArguments(request mcp.CallToolRequest, tool mcp.Tool, args any) *mcp.CallToolResult {
+	converted, errs := validate.Arguments(tool.InputSchema, request.GetArguments())
+	if len(errs) > 0 {
+		return errs.Result()
+	}
+	request.Params.Arguments = converted
+	if err := request.BindArguments(args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid parameters: %v", err))
+	}
+	return nil
+}
+
+// requireArguments checks that request gives each of names, for arguments
+// that are only needed for some calls of a tool, such as one of its actions.
+// If any are missing, the result to return to the caller is given.
+func requireArguments(request mcp.CallToolRequest, names ...string) *mcp.CallToolResult {
+	args := request.GetArguments()
+	var errs validate.Errors
+	for _, name := range names {
+		if v, ok := args[name]; !ok || v == nil || v == "" {
+			errs = append(errs, validate.Error{Param: name, Message: "is required"})
+		}
+	}
+	if len(errs) > 0 {
+		return errs.Result()
+	}
+	return nil
+}
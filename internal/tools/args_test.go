@@ -0,0 +1,70 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/internal/validate"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBindArguments(t *testing.T) {
+	tool := mcp.NewTool("test",
+		mcp.WithString("pair", mcp.Required()),
+		mcp.WithString("side", mcp.Enum("BUY", "SELL")),
+		mcp.WithString("volume", validate.Decimal()),
+		mcp.WithNumber("limit", mcp.Min(1)),
+		mcp.WithBoolean("confirm"),
+	)
+	type args struct {
+		Pair    string           `json:"pair"`
+		Side    string           `json:"side"`
+		Volume  *decimal.Decimal `json:"volume"`
+		Limit   int              `json:"limit"`
+		Confirm bool             `json:"confirm"`
+	}
+
+	t.Run("converts arguments", func(t *testing.T) {
+		got := args{Limit: 10}
+		errResult := bindArguments(createMockRequest(map[string]any{
+			"pair": "XBTZAR", "side": "buy", "volume": 0.5, "limit": "25", "confirm": "true",
+		}), tool, &got)
+		require.Nil(t, errResult)
+		require.NotNil(t, got.Volume)
+		assert.Equal(t, "0.5", got.Volume.String())
+		got.Volume = nil
+		assert.Equal(t, args{Pair: "XBTZAR", Side: "BUY", Limit: 25, Confirm: true}, got)
+	})
+
+	t.Run("keeps defaults", func(t *testing.T) {
+		got := args{Limit: 10}
+		require.Nil(t, bindArguments(createMockRequest(map[string]any{"pair": "XBTZAR", "side": ""}), tool, &got))
+		assert.Equal(t, args{Pair: "XBTZAR", Limit: 10}, got)
+	})
+
+	t.Run("reports invalid arguments", func(t *testing.T) {
+		var got args
+		errResult := bindArguments(createMockRequest(map[string]any{"limit": 0}), tool, &got)
+		require.NotNil(t, errResult)
+		assert.True(t, errResult.IsError)
+		assert.Equal(t, "Invalid parameters: limit must be at least 1, got 0; pair is required", getTextContentFromResult(t, errResult))
+	})
+
+	t.Run("reports arguments that don't fit", func(t *testing.T) {
+		var got args
+		errResult := bindArguments(createMockRequest(map[string]any{"pair": "XBTZAR", "limit": 2.5}), tool, &got)
+		require.NotNil(t, errResult)
+		assert.Contains(t, getTextContentFromResult(t, errResult), "Invalid parameters:")
+	})
+}
+
+func TestRequireArguments(t *testing.T) {
+	request := createMockRequest(map[string]any{"id": "abc", "pair": ""})
+	assert.Nil(t, requireArguments(request, "id"))
+
+	errResult := requireArguments(request, "id", "pair", "side")
+	require.NotNil(t, errResult)
+	assert.Equal(t, "Invalid parameters: pair is required; side is required", getTextContentFromResult(t, errResult))
+}
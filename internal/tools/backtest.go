@@ -0,0 +1,219 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/luno/luno-mcp/internal/backtest"
+	"github.com/luno/luno-mcp/internal/candles"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/validate"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Defaults for backtest_strategy
+const (
+	defaultBacktestDays     = 90
+	defaultBacktestCapital  = 1000
+	defaultBacktestFast     = 10
+	defaultBacktestSlow     = 30
+	defaultBacktestRSI      = 14
+	defaultBacktestRSILower = 30
+	defaultBacktestRSIUpper = 70
+	defaultBacktestDCA      = 7
+)
+
+// BacktestResult is the result of the backtest_strategy tool
+type BacktestResult struct {
+	Pair           string `json:"pair"`
+	CandleDuration int64  `json:"candle_duration"`
+	*backtest.Result
+	Notes []string `json:"notes"`
+}
+
+// NewBacktestStrategyTool creates a tool for replaying a strategy over past candles
+func NewBacktestStrategyTool() mcp.Tool {
+	return mcp.NewTool(
+		BacktestStrategyToolID,
+		mcp.WithDescription("Backtest a built-in trading strategy on a market's historical candles and return its trades, P&L, "+
+			"max drawdown and win rate, compared with buying and holding. "+
+			"Strategies: sma_crossover buys when the fast moving average crosses above the slow one and sells when it crosses below; "+
+			"rsi buys when the RSI falls below rsi_lower and sells when it rises above rsi_upper; "+
+			"dca buys a fixed amount every dca_interval candles. Results are computed on the server and are deterministic."),
+		mcp.WithString(
+			"pair",
+			mcp.Required(),
+			mcp.Description(ErrTradingPairDesc),
+		),
+		mcp.WithString(
+			"strategy",
+			mcp.Required(),
+			mcp.Enum(backtest.Strategies...),
+			mcp.Description("Strategy to backtest"),
+		),
+		mcp.WithString(
+			"since",
+			mcp.Description(timeParamDesc(fmt.Sprintf("Start of the range, by default %d days before until", defaultBacktestDays))),
+		),
+		mcp.WithString(
+			"until",
+			mcp.Description(timeParamDesc("End of the range, by default now. A period such as last month ends when the period does")),
+		),
+		mcp.WithNumber(
+			"duration",
+			mcp.Description("Candle duration in seconds (default: 86400 for daily candles, or e.g. 3600 for hourly)"),
+			mcp.Min(60),
+		),
+		mcp.WithNumber(
+			"capital",
+			mcp.Description(fmt.Sprintf("Starting balance in the pair's counter currency (default: %d)", defaultBacktestCapital)),
+			validate.ExclusiveMin(0),
+		),
+		mcp.WithNumber(
+			"fee_percent",
+			mcp.Description("Trading fee charged on each trade, as a percentage (default: 0)"),
+			mcp.Min(0),
+			validate.ExclusiveMax(100),
+		),
+		mcp.WithNumber(
+			"fast_period",
+			mcp.Description(fmt.Sprintf("sma_crossover: fast moving average length in candles (default: %d)", defaultBacktestFast)),
+		),
+		mcp.WithNumber(
+			"slow_period",
+			mcp.Description(fmt.Sprintf("sma_crossover: slow moving average length in candles (default: %d)", defaultBacktestSlow)),
+		),
+		mcp.WithNumber(
+			"rsi_period",
+			mcp.Description(fmt.Sprintf("rsi: RSI length in candles (default: %d)", defaultBacktestRSI)),
+		),
+		mcp.WithNumber(
+			"rsi_lower",
+			mcp.Description(fmt.Sprintf("rsi: buy when the RSI falls below this (default: %d)", defaultBacktestRSILower)),
+		),
+		mcp.WithNumber(
+			"rsi_upper",
+			mcp.Description(fmt.Sprintf("rsi: sell when the RSI rises above this (default: %d)", defaultBacktestRSIUpper)),
+		),
+		mcp.WithNumber(
+			"dca_interval",
+			mcp.Description(fmt.Sprintf("dca: candles between buys (default: %d)", defaultBacktestDCA)),
+		),
+		mcp.WithNumber(
+			"dca_amount",
+			mcp.Description("dca: counter amount spent on each buy. Defaults to spreading the capital evenly over the range."),
+		),
+	)
+}
+
+// backtestArgs are the arguments of the backtest_strategy tool
+type backtestArgs struct {
+	Pair        string  `json:"pair"`
+	Strategy    string  `json:"strategy"`
+	Since       string  `json:"since"`
+	Until       string  `json:"until"`
+	Duration    int64   `json:"duration"`
+	Capital     float64 `json:"capital"`
+	FeePercent  float64 `json:"fee_percent"`
+	FastPeriod  int     `json:"fast_period"`
+	SlowPeriod  int     `json:"slow_period"`
+	RSIPeriod   int     `json:"rsi_period"`
+	RSILower    float64 `json:"rsi_lower"`
+	RSIUpper    float64 `json:"rsi_upper"`
+	DCAInterval int     `json:"dca_interval"`
+	DCAAmount   float64 `json:"dca_amount"`
+}
+
+// HandleBacktestStrategy handles the backtest_strategy tool
+func HandleBacktestStrategy(cfg *config.Config) server.ToolHandlerFunc {
+	tool := NewBacktestStrategyTool()
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		cfg := cfg.ForContext(ctx)
+		args := backtestArgs{
+			Duration:    86400,
+			Capital:     defaultBacktestCapital,
+			FastPeriod:  defaultBacktestFast,
+			SlowPeriod:  defaultBacktestSlow,
+			RSIPeriod:   defaultBacktestRSI,
+			RSILower:    defaultBacktestRSILower,
+			RSIUpper:    defaultBacktestRSIUpper,
+			DCAInterval: defaultBacktestDCA,
+		}
+		if errResult := bindArguments(request, tool, &args); errResult != nil {
+			return errResult, nil
+		}
+		pair := normalizeCurrencyPair(args.Pair)
+
+		params := backtest.Params{
+			Strategy:    args.Strategy,
+			Capital:     args.Capital,
+			FeePercent:  args.FeePercent,
+			FastPeriod:  args.FastPeriod,
+			SlowPeriod:  args.SlowPeriod,
+			RSIPeriod:   args.RSIPeriod,
+			RSILower:    args.RSILower,
+			RSIUpper:    args.RSIUpper,
+			DCAInterval: args.DCAInterval,
+			DCAAmount:   args.DCAAmount,
+		}
+		if err := params.Validate(); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid strategy parameters", err), nil
+		}
+
+		now := time.Now()
+		untilRange, hasUntil, errResult := parseTime("until", args.Until, now)
+		if errResult != nil {
+			return errResult, nil
+		}
+		sinceRange, hasSince, errResult := parseTime("since", args.Since, now)
+		if errResult != nil {
+			return errResult, nil
+		}
+		until := now
+		if hasUntil && untilRange.End.Before(now) {
+			until = untilRange.End
+		}
+		since := until.Add(-defaultBacktestDays * 24 * time.Hour)
+		if hasSince {
+			since = sinceRange.Start
+		}
+		if !since.Before(until) {
+			return mcp.NewToolResultError("since must be before until"), nil
+		}
+		duration := args.Duration
+		if n := int64(until.Sub(since) / (time.Duration(duration) * time.Second)); n > candles.MaxCandles {
+			return mcp.NewToolResultError(fmt.Sprintf("The range holds %d candles of %d seconds, more than the %d that can be fetched at once. Shorten the range or use a longer duration.",
+				n, duration, candles.MaxCandles)), nil
+		}
+
+		cs, err := getCandles(ctx, cfg, pair, duration, since, until)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("getting candles", err), nil
+		}
+
+		run, err := backtest.Run(cs, params)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("running backtest", err), nil
+		}
+		result := BacktestResult{
+			Pair:           pair,
+			CandleDuration: duration,
+			Result:         run,
+			Notes: []string{
+				"Trades fill at candle closes; slippage, spreads and order minimums are not modelled.",
+				"Past performance does not predict future results.",
+			},
+		}
+		if run.OpenVolume > 0 {
+			result.Notes = append(result.Notes, "The position still open at the end is valued at the last close.")
+		}
+
+		resultJSON, err := marshalJSON(result)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal backtest: %v", err)), nil
+		}
+		return mcp.NewToolResultText(resultJSON), nil
+	}
+}
@@ -0,0 +1,136 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleBacktestStrategy(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	until := start.Add(10 * 24 * time.Hour)
+	candles := &luno.GetCandlesResponse{}
+	for i, c := range []string{"100", "50", "100", "50", "100", "50", "100", "50", "100", "50", "100"} {
+		candles.Candles = append(candles.Candles, luno.Candle{
+			Timestamp: luno.Time(start.Add(time.Duration(i) * 24 * time.Hour)),
+			Close:     NewFromString(t, c),
+		})
+	}
+
+	tests := []struct {
+		name          string
+		args          map[string]any
+		candlesErr    error
+		noCandles     bool
+		expTrades     int
+		expFinalValue float64
+		errorContains string
+	}{
+		{
+			name: "dca over a range",
+			args: map[string]any{
+				"pair": "XBTZAR", "strategy": "dca", "since": float64(start.UnixMilli()), "until": float64(until.UnixMilli()),
+				"dca_interval": 2, "capital": 500,
+			},
+			expTrades:     5,
+			expFinalValue: 250,
+		},
+		{
+			name: "dca over dates",
+			args: map[string]any{
+				"pair": "XBTZAR", "strategy": "dca", "since": "2026-06-01", "until": "2026-06-10",
+				"dca_interval": 2, "capital": 500,
+			},
+			expTrades:     5,
+			expFinalValue: 250,
+		},
+		{
+			name:          "invalid until",
+			args:          map[string]any{"pair": "XBTZAR", "strategy": "dca", "until": "later"},
+			noCandles:     true,
+			errorContains: `Invalid until: "later" is not a time`,
+		},
+		{
+			name: "sma crossover",
+			args: map[string]any{
+				"pair": "XBTZAR", "strategy": "SMA_CROSSOVER", "since": float64(start.UnixMilli()), "until": float64(until.UnixMilli()),
+				"fast_period": 1, "slow_period": 2,
+			},
+			expTrades:     8,
+			expFinalValue: 62.5,
+		},
+		{
+			name:          "invalid parameters",
+			args:          map[string]any{"pair": "XBTZAR", "strategy": "rsi", "rsi_lower": 80},
+			noCandles:     true,
+			errorContains: "RSI bounds",
+		},
+		{
+			name:          "unknown strategy",
+			args:          map[string]any{"pair": "XBTZAR", "strategy": "grid"},
+			noCandles:     true,
+			errorContains: `strategy must be one of sma_crossover, rsi, dca, got "grid"`,
+		},
+		{
+			name:          "range too long",
+			args:          map[string]any{"pair": "XBTZAR", "strategy": "dca", "duration": 60},
+			noCandles:     true,
+			errorContains: "more than the 10000 that can be fetched at once",
+		},
+		{
+			name:          "candles fail",
+			args:          map[string]any{"pair": "XBTZAR", "strategy": "dca"},
+			candlesErr:    errors.New(apiErrorStr),
+			errorContains: "getting candles",
+		},
+		{
+			name:          "too few candles",
+			args:          map[string]any{"pair": "XBTZAR", "strategy": "sma_crossover", "since": float64(start.UnixMilli()), "until": float64(until.UnixMilli())},
+			errorContains: "10 candles is too few for sma_crossover",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := sdk.NewMockLunoClient(t)
+			if !tt.noCandles {
+				res := candles
+				if tt.candlesErr != nil {
+					res = nil
+				}
+				mockClient.EXPECT().GetCandles(ctx, mock.MatchedBy(func(r *luno.GetCandlesRequest) bool {
+					return r.Pair == "XBTZAR" && r.Duration == 86400
+				})).Return(res, tt.candlesErr)
+			}
+			cfg := &config.Config{LunoClient: mockClient}
+
+			result, err := HandleBacktestStrategy(cfg)(ctx, createMockRequest(tt.args))
+			require.NoError(t, err)
+			text := getTextContentFromResult(t, result)
+			if tt.errorContains != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, text, tt.errorContains)
+				return
+			}
+			require.False(t, result.IsError, text)
+
+			var got BacktestResult
+			require.NoError(t, json.Unmarshal([]byte(text), &got))
+			assert.Equal(t, "XBTZAR", got.Pair)
+			assert.Equal(t, 10, got.Candles, "the candle at until is left out")
+			assert.Len(t, got.Trades, tt.expTrades)
+			assert.Equal(t, tt.expFinalValue, got.FinalValue)
+			assert.NotEmpty(t, got.Notes)
+		})
+	}
+}
@@ -0,0 +1,261 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/validate"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// maxBasketItems bounds how many assets quote_basket prices at once
+	maxBasketItems = 20
+	// defaultBasketFeePercent is the taker fee quote_basket assumes when none is given
+	defaultBasketFeePercent = "0.1"
+)
+
+// BatchOrder is a limit order as quote_basket suggests it and create_orders_batch
+// accepts it
+type BatchOrder struct {
+	Pair   string `json:"pair"`
+	Type   string `json:"type"`
+	Volume string `json:"volume"`
+	Price  string `json:"price"`
+}
+
+// BasketItem is the quote for one asset of a basket
+type BasketItem struct {
+	Asset string `json:"asset"`
+	Pair  string `json:"pair"`
+	// Amount is what is spent on the asset, fees included
+	Amount       string `json:"amount"`
+	BestAsk      string `json:"best_ask"`
+	AveragePrice string `json:"average_price"`
+	// Volume is the asset obtainable after fees, at the market's volume precision
+	Volume          string  `json:"volume"`
+	EstimatedFee    string  `json:"estimated_fee"`
+	SlippagePercent float64 `json:"slippage_percent"`
+}
+
+// BasketQuote is the result of the quote_basket tool
+type BasketQuote struct {
+	Currency   string       `json:"currency"`
+	FeePercent string       `json:"fee_percent"`
+	Items      []BasketItem `json:"items"`
+	TotalCost  string       `json:"total_cost"`
+	TotalFees  string       `json:"total_fees"`
+	// Orders are limit orders that would buy each item now, for create_orders_batch
+	Orders []BatchOrder `json:"orders"`
+	Errors []PairError  `json:"errors,omitempty"`
+	Notes  []string     `json:"notes"`
+	PartialResult
+}
+
+// basketItem is a parsed item of the items parameter
+type basketItem struct {
+	asset  string
+	amount decimal.Decimal
+}
+
+// NewQuoteBasketTool creates a tool for pricing several purchases at once
+func NewQuoteBasketTool() mcp.Tool {
+	return mcp.NewTool(
+		QuoteBasketToolID,
+		mcp.WithDescription("Quote buying several assets at once, each for a set amount of one currency. Returns the price, "+
+			"the quantity obtainable after fees from the current order book, and the total cost and fees of the basket, "+
+			"with the limit orders to place them with create_orders_batch. Nothing is ordered."),
+		mcp.WithString(
+			"items",
+			mcp.Required(),
+			mcp.Description(fmt.Sprintf("Comma-separated asset:amount items giving how much of currency to spend on each asset, e.g. XBT:1000,ETH:500 (at most %d)", maxBasketItems)),
+		),
+		mcp.WithString(
+			"currency",
+			mcp.Required(),
+			mcp.Description("Currency the amounts are in and paid with, e.g. ZAR"),
+		),
+		mcp.WithString(
+			"fee_percent",
+			mcp.Description(fmt.Sprintf("Taker fee to estimate with, as a percentage (default: %s). Fees depend on your Luno fee tier.", defaultBasketFeePercent)),
+			validate.Decimal(),
+			mcp.Min(0),
+			validate.ExclusiveMax(100),
+		),
+	)
+}
+
+// basketArgs are the arguments of the quote_basket tool
+type basketArgs struct {
+	Items      string `json:"items"`
+	Currency   string `json:"currency"`
+	FeePercent string `json:"fee_percent"`
+}
+
+// HandleQuoteBasket handles the quote_basket tool
+func HandleQuoteBasket(cfg *config.Config) server.ToolHandlerFunc {
+	tool := NewQuoteBasketTool()
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		cfg := cfg.ForContext(ctx)
+		args := basketArgs{FeePercent: defaultBasketFeePercent}
+		if errResult := bindArguments(request, tool, &args); errResult != nil {
+			return errResult, nil
+		}
+		currency := normalizeCurrency(args.Currency)
+		items, err := parseBasketItems(args.Items)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		feePercent, err := decimal.NewFromString(args.FeePercent)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Invalid fee_percent: %v", err)), nil
+		}
+
+		quote := BasketQuote{
+			Currency:   currency,
+			FeePercent: feePercent.String(),
+			Items:      []BasketItem{},
+			Orders:     []BatchOrder{},
+			Notes: []string{
+				"Quantities are estimated from the order book at the time of the quote and will change as the market moves.",
+				"Fees are estimated at fee_percent of each amount; the fee charged depends on your Luno fee tier.",
+			},
+		}
+		totalCost, totalFees := decimal.Zero(), decimal.Zero()
+		fan := newFanOut(ctx)
+		for _, it := range items {
+			pair := it.asset + currency
+			if fan.expired() {
+				quote.timeOut(pair)
+				continue
+			}
+			item, order, err := quoteBasketItem(ctx, cfg, pair, it, feePercent)
+			if err != nil && fan.timedOut(err) {
+				quote.timeOut(pair)
+				continue
+			}
+			if err != nil {
+				quote.Errors = append(quote.Errors, PairError{Pair: pair, Error: err.Error()})
+				continue
+			}
+			quote.Items = append(quote.Items, item)
+			quote.Orders = append(quote.Orders, order)
+			totalCost = totalCost.Add(it.amount)
+			fee, _ := decimal.NewFromString(item.EstimatedFee)
+			totalFees = totalFees.Add(fee)
+		}
+		if len(quote.Items) == 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("None of the items could be quoted: %s", formatPairErrors(withTimedOut(quote.Errors, quote.PartialResult)))), nil
+		}
+		if quote.Partial {
+			quote.Notes = append(quote.Notes, "The quote is partial: items listed in timed_out were not priced in time and have no order.")
+		}
+		quote.TotalCost = totalCost.String()
+		quote.TotalFees = totalFees.String()
+
+		resultJSON, err := marshalJSON(quote)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal basket quote: %v", err)), nil
+		}
+		return mcp.NewToolResultText(resultJSON), nil
+	}
+}
+
+// parseBasketItems parses items like "XBT:1000,ETH:500"
+func parseBasketItems(s string) ([]basketItem, error) {
+	var items []basketItem
+	for part := range strings.SplitSeq(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		asset, amountStr, ok := strings.Cut(part, ":")
+		if !ok {
+			return nil, fmt.Errorf("item %q must be asset:amount, e.g. XBT:1000", part)
+		}
+		asset = normalizeCurrency(strings.TrimSpace(asset))
+		amount, err := decimal.NewFromString(strings.TrimSpace(amountStr))
+		if err != nil || amount.Sign() <= 0 {
+			return nil, fmt.Errorf("item %q must have an amount more than 0", part)
+		}
+		if slices.ContainsFunc(items, func(i basketItem) bool { return i.asset == asset }) {
+			return nil, fmt.Errorf("%s is in the basket more than once", asset)
+		}
+		items = append(items, basketItem{asset: asset, amount: amount})
+	}
+	if len(items) == 0 || len(items) > maxBasketItems {
+		return nil, fmt.Errorf("give between 1 and %d items", maxBasketItems)
+	}
+	return items, nil
+}
+
+// quoteBasketItem prices spending it.amount on pair by walking the asks of its
+// order book, and suggests a limit order at the highest price walked
+func quoteBasketItem(ctx context.Context, cfg *config.Config, pair string, it basketItem, feePercent decimal.Decimal) (BasketItem, BatchOrder, error) {
+	market, ok, err := MarketCache(cfg).Lookup(ctx, pair)
+	if err != nil {
+		return BasketItem{}, BatchOrder{}, fmt.Errorf("looking up market: %w", err)
+	}
+	if !ok {
+		return BasketItem{}, BatchOrder{}, errors.New(unknownMarketError)
+	}
+	if market.TradingStatus == luno.TradingStatusSuspended {
+		return BasketItem{}, BatchOrder{}, fmt.Errorf("trading on %s is suspended", pair)
+	}
+	book, err := cfg.LunoClient.GetOrderBook(ctx, &luno.GetOrderBookRequest{Pair: pair})
+	if err != nil {
+		return BasketItem{}, BatchOrder{}, fmt.Errorf("getting order book: %w", err)
+	}
+	if len(book.Asks) == 0 {
+		return BasketItem{}, BatchOrder{}, fmt.Errorf("no one is selling on %s", pair)
+	}
+
+	// Work at extra precision and truncate to the market's once done
+	scale := int(market.VolumeScale) + 8
+	remaining := it.amount
+	gross := decimal.Zero()
+	var worst decimal.Decimal
+	for _, ask := range book.Asks {
+		worst = ask.Price
+		cost := ask.Volume.Mul(ask.Price)
+		if cost.Cmp(remaining) >= 0 {
+			gross = gross.Add(remaining.Div(ask.Price, scale))
+			remaining = decimal.Zero()
+			break
+		}
+		gross = gross.Add(ask.Volume)
+		remaining = remaining.Sub(cost)
+	}
+	if remaining.Sign() > 0 {
+		return BasketItem{}, BatchOrder{}, fmt.Errorf("the order book of %s is too thin to spend %s", pair, it.amount)
+	}
+
+	hundred := decimal.NewFromInt64(100)
+	fee := it.amount.Mul(feePercent).Div(hundred, decimalScale(it.amount)+2)
+	net := gross.Sub(gross.Mul(feePercent).Div(hundred, scale)).ToScale(int(market.VolumeScale))
+	if net.Sign() <= 0 || (market.MinVolume.Sign() > 0 && net.Cmp(market.MinVolume) < 0) {
+		return BasketItem{}, BatchOrder{}, fmt.Errorf("%s %s buys less than the %s minimum of %s", it.amount, market.CounterCurrency, pair, market.MinVolume)
+	}
+	best := book.Asks[0].Price
+	average := it.amount.Div(gross, int(market.PriceScale))
+
+	item := BasketItem{
+		Asset:           it.asset,
+		Pair:            pair,
+		Amount:          it.amount.String(),
+		BestAsk:         best.String(),
+		AveragePrice:    average.String(),
+		Volume:          net.String(),
+		EstimatedFee:    fee.String(),
+		SlippagePercent: percentOf(average.Sub(best), best),
+	}
+	order := BatchOrder{Pair: pair, Type: "BUY", Volume: net.String(), Price: worst.String()}
+	return item, order, nil
+}
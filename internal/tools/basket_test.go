@@ -0,0 +1,165 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/markets"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleQuoteBasket(t *testing.T) {
+	ctx := context.Background()
+	listed := &luno.MarketsResponse{Markets: []luno.MarketInfo{
+		{MarketId: "XBTZAR", CounterCurrency: "ZAR", VolumeScale: 6, MinVolume: NewFromString(t, "0.0005")},
+		{MarketId: "ETHZAR", CounterCurrency: "ZAR", VolumeScale: 4, MinVolume: NewFromString(t, "0.001")},
+		{MarketId: "SOLZAR", CounterCurrency: "ZAR", VolumeScale: 2, TradingStatus: luno.TradingStatusSuspended},
+	}}
+	xbtBook := &luno.GetOrderBookResponse{Asks: []luno.OrderBookEntry{
+		{Price: NewFromString(t, "1000000"), Volume: NewFromString(t, "0.0005")},
+		{Price: NewFromString(t, "1010000"), Volume: NewFromString(t, "1")},
+	}}
+	ethBook := &luno.GetOrderBookResponse{Asks: []luno.OrderBookEntry{
+		{Price: NewFromString(t, "50000"), Volume: NewFromString(t, "10")},
+	}}
+
+	tests := []struct {
+		name          string
+		args          map[string]any
+		mockSetup     func(*sdk.MockLunoClient)
+		expItems      []BasketItem
+		expOrders     []BatchOrder
+		expTotalCost  string
+		expTotalFees  string
+		expErrors     []PairError
+		errorContains string
+	}{
+		{
+			name: "two items",
+			args: map[string]any{"items": "xbt:1000, ETH:500", "currency": "zar"},
+			mockSetup: func(c *sdk.MockLunoClient) {
+				c.EXPECT().GetOrderBook(ctx, &luno.GetOrderBookRequest{Pair: "XBTZAR"}).Return(xbtBook, nil)
+				c.EXPECT().GetOrderBook(ctx, &luno.GetOrderBookRequest{Pair: "ETHZAR"}).Return(ethBook, nil)
+			},
+			expItems: []BasketItem{
+				{
+					Asset: "XBT", Pair: "XBTZAR", Amount: "1000", BestAsk: "1000000", AveragePrice: "1004975",
+					Volume: "0.000994", EstimatedFee: "1.00", SlippagePercent: 0.4975,
+				},
+				{
+					Asset: "ETH", Pair: "ETHZAR", Amount: "500", BestAsk: "50000", AveragePrice: "50000",
+					Volume: "0.0099", EstimatedFee: "0.50",
+				},
+			},
+			expOrders: []BatchOrder{
+				{Pair: "XBTZAR", Type: "BUY", Volume: "0.000994", Price: "1010000"},
+				{Pair: "ETHZAR", Type: "BUY", Volume: "0.0099", Price: "50000"},
+			},
+			expTotalCost: "1500",
+			expTotalFees: "1.50",
+		},
+		{
+			name: "items that cannot be quoted are reported",
+			args: map[string]any{"items": "ETH:500,LTC:100,SOL:100,XBT:5000000", "currency": "ZAR", "fee_percent": "0"},
+			mockSetup: func(c *sdk.MockLunoClient) {
+				c.EXPECT().GetOrderBook(ctx, &luno.GetOrderBookRequest{Pair: "ETHZAR"}).Return(ethBook, nil)
+				c.EXPECT().GetOrderBook(ctx, &luno.GetOrderBookRequest{Pair: "XBTZAR"}).Return(xbtBook, nil)
+			},
+			expItems: []BasketItem{
+				{Asset: "ETH", Pair: "ETHZAR", Amount: "500", BestAsk: "50000", AveragePrice: "50000", Volume: "0.0100", EstimatedFee: "0.00"},
+			},
+			expOrders:    []BatchOrder{{Pair: "ETHZAR", Type: "BUY", Volume: "0.0100", Price: "50000"}},
+			expTotalCost: "500",
+			expTotalFees: "0.00",
+			expErrors: []PairError{
+				{Pair: "LTCZAR", Error: unknownMarketError},
+				{Pair: "SOLZAR", Error: "trading on SOLZAR is suspended"},
+				{Pair: "XBTZAR", Error: "the order book of XBTZAR is too thin to spend 5000000"},
+			},
+		},
+		{
+			name: "below the market minimum",
+			args: map[string]any{"items": "ETH:10", "currency": "ZAR"},
+			mockSetup: func(c *sdk.MockLunoClient) {
+				c.EXPECT().GetOrderBook(ctx, &luno.GetOrderBookRequest{Pair: "ETHZAR"}).Return(ethBook, nil)
+			},
+			errorContains: "10 ZAR buys less than the ETHZAR minimum of 0.001",
+		},
+		{
+			name: "order book error",
+			args: map[string]any{"items": "ETH:500", "currency": "ZAR"},
+			mockSetup: func(c *sdk.MockLunoClient) {
+				c.EXPECT().GetOrderBook(ctx, &luno.GetOrderBookRequest{Pair: "ETHZAR"}).Return(nil, errors.New(apiErrorStr))
+			},
+			errorContains: apiErrorStr,
+		},
+		{
+			name:          "item without amount",
+			args:          map[string]any{"items": "XBT", "currency": "ZAR"},
+			errorContains: `item "XBT" must be asset:amount`,
+		},
+		{
+			name:          "negative amount",
+			args:          map[string]any{"items": "XBT:-5", "currency": "ZAR"},
+			errorContains: "must have an amount more than 0",
+		},
+		{
+			name:          "duplicate asset",
+			args:          map[string]any{"items": "XBT:5,xbt:10", "currency": "ZAR"},
+			errorContains: "XBT is in the basket more than once",
+		},
+		{
+			name:          "no items",
+			args:          map[string]any{"items": " , ", "currency": "ZAR"},
+			errorContains: "give between 1 and 20 items",
+		},
+		{
+			name:          "missing currency",
+			args:          map[string]any{"items": "XBT:1000"},
+			errorContains: "currency",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := sdk.NewMockLunoClient(t)
+			cfg := &config.Config{LunoClient: mockClient, Markets: markets.NewCache(mockClient)}
+			mockClient.EXPECT().Markets(ctx, &luno.MarketsRequest{}).Return(listed, nil).Maybe()
+			if tt.mockSetup != nil {
+				tt.mockSetup(mockClient)
+			}
+
+			result, err := HandleQuoteBasket(cfg)(ctx, createMockRequest(tt.args))
+			require.NoError(t, err)
+			text := getTextContentFromResult(t, result)
+			if tt.errorContains != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, text, tt.errorContains)
+				return
+			}
+			require.False(t, result.IsError, text)
+
+			var got BasketQuote
+			require.NoError(t, json.Unmarshal([]byte(text), &got))
+			assert.Equal(t, "ZAR", got.Currency)
+			require.Len(t, got.Items, len(tt.expItems))
+			for i, exp := range tt.expItems {
+				item := got.Items[i]
+				assert.InDelta(t, exp.SlippagePercent, item.SlippagePercent, 1e-4)
+				item.SlippagePercent = exp.SlippagePercent
+				assert.Equal(t, exp, item)
+			}
+			assert.Equal(t, tt.expOrders, got.Orders)
+			assert.Equal(t, tt.expTotalCost, got.TotalCost)
+			assert.Equal(t, tt.expTotalFees, got.TotalFees)
+			assert.Equal(t, tt.expErrors, got.Errors)
+			assert.NotEmpty(t, got.Notes)
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// NewCheckUpdatesTool creates a tool for checking for a newer luno-mcp release
+func NewCheckUpdatesTool() mcp.Tool {
+	return mcp.NewTool(
+		CheckUpdatesToolID,
+		mcp.WithDescription("Check GitHub for a newer release of this luno-mcp server, returning the running and latest versions "+
+			"and where to download the latest. Results are reused for an hour."),
+	)
+}
+
+// HandleCheckUpdates handles the check_updates tool
+func HandleCheckUpdates(cfg *config.Config) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		cfg := cfg.ForContext(ctx)
+		if cfg.Updates == nil {
+			return mcp.NewToolResultError("Update checks are disabled on this server (DISABLE_UPDATE_CHECK or --disable-update-check)"), nil
+		}
+		result, err := cfg.Updates.Check(ctx)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to check for updates: %v", err)), nil
+		}
+
+		resultJSON, err := marshalJSON(result)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal update check: %v", err)), nil
+		}
+		return mcp.NewToolResultText(resultJSON), nil
+	}
+}
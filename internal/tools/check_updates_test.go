@@ -0,0 +1,36 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/updates"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleCheckUpdates(t *testing.T) {
+	result, err := HandleCheckUpdates(&config.Config{})(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, getTextContentFromResult(t, result), "Update checks are disabled")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"tag_name":"v0.2.0","html_url":"https://github.com/luno/luno-mcp/releases/tag/v0.2.0"}`))
+	}))
+	defer srv.Close()
+	cfg := &config.Config{Updates: updates.NewChecker(srv.URL, "0.1.0")}
+
+	result, err = HandleCheckUpdates(cfg)(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	var got updates.Result
+	require.NoError(t, json.Unmarshal([]byte(getTextContentFromResult(t, result)), &got))
+	assert.True(t, got.UpdateAvailable)
+	assert.Equal(t, "0.2.0", got.LatestVersion)
+}
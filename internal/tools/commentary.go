@@ -0,0 +1,233 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// commentaryCandleDuration is the candle size used for the 24 hour stats (1 hour)
+	commentaryCandleDuration = 3600
+	// commentaryDepthLevels is the number of order book levels summed for depth
+	commentaryDepthLevels = 10
+	// commentaryMaxTokens bounds the size of the sampled commentary
+	commentaryMaxTokens = 400
+
+	commentarySystemPrompt = "You are a concise cryptocurrency market analyst. " +
+		"Summarise the provided Luno market metrics in a short narrative of at most five sentences. " +
+		"Only use the numbers provided, do not speculate about future prices and do not give financial advice."
+)
+
+// MarketMetrics holds the raw metrics gathered for a market commentary
+type MarketMetrics struct {
+	Pair          string  `json:"pair"`
+	Status        string  `json:"status"`
+	LastTrade     string  `json:"last_trade"`
+	Bid           string  `json:"bid"`
+	Ask           string  `json:"ask"`
+	Spread        string  `json:"spread"`
+	SpreadPercent float64 `json:"spread_percent"`
+	Volume24h     string  `json:"volume_24h"`
+
+	Open24h          string  `json:"open_24h,omitempty"`
+	High24h          string  `json:"high_24h,omitempty"`
+	Low24h           string  `json:"low_24h,omitempty"`
+	ChangePercent24h float64 `json:"change_percent_24h"`
+	CandleCount      int     `json:"candle_count"`
+
+	BidDepth           string  `json:"bid_depth"`
+	AskDepth           string  `json:"ask_depth"`
+	OrderBookImbalance float64 `json:"order_book_imbalance"`
+}
+
+// MarketCommentary is the result of the explain_market tool
+type MarketCommentary struct {
+	Metrics         MarketMetrics `json:"metrics"`
+	Commentary      string        `json:"commentary,omitempty"`
+	Model           string        `json:"model,omitempty"`
+	CommentaryError string        `json:"commentary_error,omitempty"`
+}
+
+// Sampler sends sampling requests to the connected MCP client
+type Sampler interface {
+	RequestSampling(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error)
+}
+
+// NewExplainMarketTool creates a new tool for generating a market commentary
+func NewExplainMarketTool() mcp.Tool {
+	return mcp.NewTool(
+		ExplainMarketToolID,
+		mcp.WithDescription("Explain the current state of a market. Gathers ticker, 24 hour candle stats and "+
+			"order book metrics, then asks the client's model (via MCP sampling) for a short narrative summary. "+
+			"Returns the raw metrics even if the client does not support sampling."),
+		mcp.WithString(
+			"pair",
+			mcp.Required(),
+			mcp.Description(ErrTradingPairDesc),
+		),
+	)
+}
+
+// HandleExplainMarket handles the explain_market tool
+func HandleExplainMarket(cfg *config.Config) server.ToolHandlerFunc {
+	tool := NewExplainMarketTool()
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		cfg := cfg.ForContext(ctx)
+		var args pairArgs
+		if errResult := bindArguments(request, tool, &args); errResult != nil {
+			return errResult, nil
+		}
+		pair := normalizeCurrencyPair(args.Pair)
+
+		metrics, err := gatherMarketMetrics(ctx, cfg, pair)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("gathering market metrics", err), nil
+		}
+
+		result := MarketCommentary{Metrics: *metrics}
+
+		if srv := server.ServerFromContext(ctx); srv != nil {
+			commentary, model, err := generateCommentary(ctx, srv, metrics)
+			if err != nil {
+				slog.Debug("Market commentary sampling failed", "pair", pair, "error", err)
+				result.CommentaryError = err.Error()
+			} else {
+				result.Commentary = commentary
+				result.Model = model
+			}
+		} else {
+			result.CommentaryError = "sampling is not available outside an MCP session"
+		}
+
+		resultJSON, err := marshalJSON(result)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal market commentary: %v", err)), nil
+		}
+
+		return mcp.NewToolResultText(resultJSON), nil
+	}
+}
+
+// gatherMarketMetrics collects the ticker, candle and order book metrics for a pair
+func gatherMarketMetrics(ctx context.Context, cfg *config.Config, pair string) (*MarketMetrics, error) {
+	ticker, err := cfg.LunoClient.GetTicker(ctx, &luno.GetTickerRequest{Pair: pair})
+	if err != nil {
+		return nil, fmt.Errorf("getting ticker for %s: %w", pair, err)
+	}
+
+	candles, err := cfg.LunoClient.GetCandles(ctx, &luno.GetCandlesRequest{
+		Pair:     pair,
+		Since:    luno.Time(time.Now().Add(-24 * time.Hour)),
+		Duration: commentaryCandleDuration,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("getting candles for %s: %w", pair, err)
+	}
+
+	orderBook, err := cfg.LunoClient.GetOrderBook(ctx, &luno.GetOrderBookRequest{Pair: pair})
+	if err != nil {
+		return nil, fmt.Errorf("getting order book for %s: %w", pair, err)
+	}
+
+	metrics := &MarketMetrics{
+		Pair:      pair,
+		Status:    string(ticker.Status),
+		LastTrade: ticker.LastTrade.String(),
+		Bid:       ticker.Bid.String(),
+		Ask:       ticker.Ask.String(),
+		Volume24h: ticker.Rolling24HourVolume.String(),
+	}
+
+	spread := ticker.Ask.Sub(ticker.Bid)
+	metrics.Spread = spread.String()
+	metrics.SpreadPercent = percentOf(spread, ticker.Ask.Add(ticker.Bid).DivInt64(2))
+
+	metrics.CandleCount = len(candles.Candles)
+	if len(candles.Candles) > 0 {
+		first := candles.Candles[0]
+		last := candles.Candles[len(candles.Candles)-1]
+		high, low := first.High, first.Low
+		for _, c := range candles.Candles[1:] {
+			if c.High.Cmp(high) > 0 {
+				high = c.High
+			}
+			if c.Low.Cmp(low) < 0 {
+				low = c.Low
+			}
+		}
+		metrics.Open24h = first.Open.String()
+		metrics.High24h = high.String()
+		metrics.Low24h = low.String()
+		metrics.ChangePercent24h = percentOf(last.Close.Sub(first.Open), first.Open)
+	}
+
+	bidDepth := sumVolume(orderBook.Bids, commentaryDepthLevels)
+	askDepth := sumVolume(orderBook.Asks, commentaryDepthLevels)
+	metrics.BidDepth = bidDepth.String()
+	metrics.AskDepth = askDepth.String()
+	if total := bidDepth.Add(askDepth); total.Sign() != 0 {
+		metrics.OrderBookImbalance = bidDepth.Sub(askDepth).Float64() / total.Float64()
+	}
+
+	return metrics, nil
+}
+
+// generateCommentary asks the client's model for a narrative summary of the metrics
+func generateCommentary(ctx context.Context, sampler Sampler, metrics *MarketMetrics) (string, string, error) {
+	metricsJSON, err := json.MarshalIndent(metrics, "", "  ")
+	if err != nil {
+		return "", "", fmt.Errorf("marshalling metrics: %w", err)
+	}
+
+	res, err := sampler.RequestSampling(ctx, mcp.CreateMessageRequest{
+		CreateMessageParams: mcp.CreateMessageParams{
+			Messages: []mcp.SamplingMessage{
+				{
+					Role:    mcp.RoleUser,
+					Content: mcp.NewTextContent("Market metrics:\n" + string(metricsJSON)),
+				},
+			},
+			SystemPrompt: commentarySystemPrompt,
+			MaxTokens:    commentaryMaxTokens,
+		},
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	switch content := res.Content.(type) {
+	case mcp.TextContent:
+		return content.Text, res.Model, nil
+	case *mcp.TextContent:
+		return content.Text, res.Model, nil
+	default:
+		return "", "", errors.New("client returned non-text sampling content")
+	}
+}
+
+// sumVolume sums the volume of the first n order book entries
+func sumVolume(entries []luno.OrderBookEntry, n int) decimal.Decimal {
+	total := decimal.Zero()
+	for i := 0; i < n && i < len(entries); i++ {
+		total = total.Add(entries[i].Volume)
+	}
+	return total
+}
+
+// percentOf returns value as a percentage of base, or 0 if base is zero
+func percentOf(value, base decimal.Decimal) float64 {
+	if base.Sign() == 0 {
+		return 0
+	}
+	return value.Float64() / base.Float64() * 100
+}
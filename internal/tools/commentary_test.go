@@ -0,0 +1,159 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSampler struct {
+	result  *mcp.CreateMessageResult
+	err     error
+	request mcp.CreateMessageRequest
+}
+
+func (f *fakeSampler) RequestSampling(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+	f.request = request
+	return f.result, f.err
+}
+
+func expectMarketMetricsCalls(t *testing.T, mockClient *sdk.MockLunoClient) {
+	mockClient.EXPECT().GetTicker(mock.Anything, &luno.GetTickerRequest{Pair: "XBTZAR"}).
+		Return(&luno.GetTickerResponse{
+			Pair:                "XBTZAR",
+			Ask:                 NewFromString(t, "101"),
+			Bid:                 NewFromString(t, "99"),
+			LastTrade:           NewFromString(t, "100"),
+			Rolling24HourVolume: NewFromString(t, "12.5"),
+			Status:              luno.StatusActive,
+		}, nil)
+	mockClient.EXPECT().GetCandles(mock.Anything, mock.Anything).
+		Return(&luno.GetCandlesResponse{
+			Candles: []luno.Candle{
+				{Open: NewFromString(t, "80"), High: NewFromString(t, "90"), Low: NewFromString(t, "75"), Close: NewFromString(t, "88")},
+				{Open: NewFromString(t, "88"), High: NewFromString(t, "105"), Low: NewFromString(t, "85"), Close: NewFromString(t, "100")},
+			},
+		}, nil)
+	mockClient.EXPECT().GetOrderBook(mock.Anything, &luno.GetOrderBookRequest{Pair: "XBTZAR"}).
+		Return(&luno.GetOrderBookResponse{
+			Bids: []luno.OrderBookEntry{{Price: NewFromString(t, "99"), Volume: NewFromString(t, "3")}},
+			Asks: []luno.OrderBookEntry{{Price: NewFromString(t, "101"), Volume: NewFromString(t, "1")}},
+		}, nil)
+}
+
+func TestHandleExplainMarket(t *testing.T) {
+	tests := []struct {
+		name          string
+		requestParams map[string]any
+		mockSetup     func(*testing.T, *sdk.MockLunoClient)
+		expectedError bool
+		errorContains string
+	}{
+		{
+			name:          "returns metrics without sampling outside a session",
+			requestParams: map[string]any{"pair": "btc-zar"},
+			mockSetup:     expectMarketMetricsCalls,
+		},
+		{
+			name:          "missing pair",
+			requestParams: map[string]any{},
+			mockSetup:     func(t *testing.T, mockClient *sdk.MockLunoClient) {},
+			expectedError: true,
+			errorContains: gettingPairFromRequestStr,
+		},
+		{
+			name:          "ticker API error",
+			requestParams: map[string]any{"pair": "XBTZAR"},
+			mockSetup: func(t *testing.T, mockClient *sdk.MockLunoClient) {
+				mockClient.EXPECT().GetTicker(mock.Anything, mock.Anything).
+					Return(nil, errors.New(apiErrorStr))
+			},
+			expectedError: true,
+			errorContains: "gathering market metrics",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := sdk.NewMockLunoClient(t)
+			tt.mockSetup(t, mockClient)
+
+			cfg := &config.Config{LunoClient: mockClient}
+			result, err := HandleExplainMarket(cfg)(context.Background(), createMockRequest(tt.requestParams))
+			require.NoError(t, err)
+
+			text := getTextContentFromResult(t, result)
+			if tt.expectedError {
+				assert.True(t, result.IsError)
+				assert.Contains(t, text, tt.errorContains)
+				return
+			}
+
+			var commentary MarketCommentary
+			require.NoError(t, json.Unmarshal([]byte(text), &commentary))
+			assert.Equal(t, "XBTZAR", commentary.Metrics.Pair)
+			assert.Equal(t, "2", commentary.Metrics.Spread)
+			assert.InDelta(t, 2.0, commentary.Metrics.SpreadPercent, 0.0001)
+			assert.Equal(t, "105", commentary.Metrics.High24h)
+			assert.Equal(t, "75", commentary.Metrics.Low24h)
+			assert.InDelta(t, 25.0, commentary.Metrics.ChangePercent24h, 0.0001)
+			assert.InDelta(t, 0.5, commentary.Metrics.OrderBookImbalance, 0.0001)
+			assert.NotEmpty(t, commentary.CommentaryError)
+		})
+	}
+}
+
+func TestGenerateCommentary(t *testing.T) {
+	tests := []struct {
+		name          string
+		sampler       *fakeSampler
+		expected      string
+		expectedModel string
+		expectedError bool
+	}{
+		{
+			name: "returns sampled text",
+			sampler: &fakeSampler{result: &mcp.CreateMessageResult{
+				SamplingMessage: mcp.SamplingMessage{Role: mcp.RoleAssistant, Content: mcp.NewTextContent("Calm market.")},
+				Model:           "test-model",
+			}},
+			expected:      "Calm market.",
+			expectedModel: "test-model",
+		},
+		{
+			name:          "sampling not supported",
+			sampler:       &fakeSampler{err: errors.New("session does not support sampling")},
+			expectedError: true,
+		},
+		{
+			name: "non text content",
+			sampler: &fakeSampler{result: &mcp.CreateMessageResult{
+				SamplingMessage: mcp.SamplingMessage{Role: mcp.RoleAssistant, Content: mcp.NewImageContent("abc", "image/png")},
+			}},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, model, err := generateCommentary(context.Background(), tt.sampler, &MarketMetrics{Pair: "XBTZAR"})
+			if tt.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, text)
+			assert.Equal(t, tt.expectedModel, model)
+			assert.Equal(t, commentarySystemPrompt, tt.sampler.request.SystemPrompt)
+		})
+	}
+}
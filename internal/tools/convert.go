@@ -0,0 +1,79 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/sdk"
+)
+
+// inverseValueScale is the number of decimal places kept when a value is found
+// by dividing by the price of a market quoted in the asset, e.g. ZAR in XBT
+const inverseValueScale = 8
+
+// converter values amounts of any asset in one currency at the latest trade
+// prices
+type converter struct {
+	target string
+	// direct holds the price of each asset in a market quoted in target
+	direct map[string]decimal.Decimal
+	// inverse holds the price of target in each asset it is quoted in
+	inverse map[string]decimal.Decimal
+}
+
+// newConverter loads the latest prices for converting to target. It fails if
+// Luno has no market to convert to target through.
+func newConverter(ctx context.Context, client sdk.LunoClient, target string) (*converter, error) {
+	tickers, err := client.GetTickers(ctx, &luno.GetTickersRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("getting prices: %w", err)
+	}
+
+	c := &converter{
+		target:  target,
+		direct:  make(map[string]decimal.Decimal),
+		inverse: make(map[string]decimal.Decimal),
+	}
+	for _, t := range tickers.Tickers {
+		if t.LastTrade.Sign() <= 0 {
+			continue
+		}
+		if base, ok := strings.CutSuffix(t.Pair, target); ok && base != "" {
+			c.direct[base] = t.LastTrade
+		} else if counter, ok := strings.CutPrefix(t.Pair, target); ok && counter != "" {
+			c.inverse[counter] = t.LastTrade
+		}
+	}
+	if len(c.direct) == 0 && len(c.inverse) == 0 {
+		return nil, fmt.Errorf("no Luno market to convert to %s", target)
+	}
+	return c, nil
+}
+
+// value returns amount of asset in the target currency, or false if there is
+// no price for asset
+func (c *converter) value(asset string, amount decimal.Decimal) (decimal.Decimal, bool) {
+	if asset == c.target {
+		return amount, true
+	}
+	if price, ok := c.direct[asset]; ok {
+		return amount.Mul(price).ToScale(decimalScale(price)), true
+	}
+	if price, ok := c.inverse[asset]; ok {
+		return amount.Div(price, inverseValueScale), true
+	}
+	return decimal.Decimal{}, false
+}
+
+// normalizeCurrency upper-cases a currency code and maps common aliases to
+// Luno's codes, e.g. BTC to XBT
+func normalizeCurrency(currency string) string {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "BTC" || currency == "BITCOIN" {
+		return "XBT"
+	}
+	return currency
+}
@@ -0,0 +1,219 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// defaultCorrelationDays is the window get_correlations uses by default
+	defaultCorrelationDays = 30
+	// maxCorrelationPairs bounds how many pairs get_correlations compares
+	maxCorrelationPairs = 10
+	// maxCandlesPerRequest is the most candles Luno returns for one request
+	maxCandlesPerRequest = 1000
+	// minCorrelationReturns is the fewest returns a correlation is computed from
+	minCorrelationReturns = 3
+	// correlationScale is the number of decimal places correlations are rounded to
+	correlationScale = 4
+)
+
+// CorrelationsResult is the result of the get_correlations tool
+type CorrelationsResult struct {
+	Pairs          []string  `json:"pairs"`
+	CandleDuration int64     `json:"candle_duration"`
+	Since          time.Time `json:"since"`
+	// Observations is how many returns, at times all pairs traded, the
+	// correlations are computed from
+	Observations int `json:"observations"`
+	// Matrix holds the correlation of the returns of each pair with every other,
+	// from -1 to 1
+	Matrix map[string]map[string]float64 `json:"matrix"`
+	Notes  []string                      `json:"notes,omitempty"`
+	Errors []PairError                   `json:"errors,omitempty"`
+	PartialResult
+}
+
+// NewGetCorrelationsTool creates a tool for comparing how markets move together
+func NewGetCorrelationsTool() mcp.Tool {
+	return mcp.NewTool(
+		GetCorrelationsToolID,
+		mcp.WithDescription("Get the pairwise correlation of returns between markets over a recent window, from candle closes. "+
+			"Values near 1 move together, near -1 move opposite and near 0 are unrelated, which helps answer portfolio diversification questions."),
+		mcp.WithString(
+			"pairs",
+			mcp.Required(),
+			mcp.Description(fmt.Sprintf("Comma-separated trading pairs to compare, e.g. XBTZAR,ETHZAR,SOLZAR (2 to %d)", maxCorrelationPairs)),
+		),
+		mcp.WithNumber(
+			"days",
+			mcp.Description(fmt.Sprintf("Number of days of history to use (default: %d)", defaultCorrelationDays)),
+			mcp.Min(1),
+		),
+		mcp.WithNumber(
+			"duration",
+			mcp.Description("Candle duration in seconds that returns are measured over (default: 86400 for daily returns, or 3600 for hourly)"),
+			mcp.Min(60),
+		),
+	)
+}
+
+// correlationsArgs are the arguments of the get_correlations tool
+type correlationsArgs struct {
+	Pairs    string `json:"pairs"`
+	Days     int    `json:"days"`
+	Duration int64  `json:"duration"`
+}
+
+// HandleGetCorrelations handles the get_correlations tool
+func HandleGetCorrelations(cfg *config.Config) server.ToolHandlerFunc {
+	tool := NewGetCorrelationsTool()
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		cfg := cfg.ForContext(ctx)
+		args := correlationsArgs{Days: defaultCorrelationDays, Duration: 86400}
+		if errResult := bindArguments(request, tool, &args); errResult != nil {
+			return errResult, nil
+		}
+		pairs := parsePairList(args.Pairs)
+		if len(pairs) < 2 || len(pairs) > maxCorrelationPairs {
+			return mcp.NewToolResultError(fmt.Sprintf("Give between 2 and %d different pairs to compare", maxCorrelationPairs)), nil
+		}
+		days, duration := args.Days, args.Duration
+		window := time.Duration(days) * 24 * time.Hour
+		candleCount := int64(window / (time.Duration(duration) * time.Second))
+		if candleCount > maxCandlesPerRequest {
+			return mcp.NewToolResultError(fmt.Sprintf("%d days of %d second candles is %d candles, more than the %d Luno returns. Use fewer days or a longer duration.",
+				days, duration, candleCount, maxCandlesPerRequest)), nil
+		}
+		if candleCount <= minCorrelationReturns {
+			return mcp.NewToolResultError(fmt.Sprintf("%d days of %d second candles is too few to correlate. Use more days or a shorter duration.", days, duration)), nil
+		}
+
+		pairs, rejected, err := validatePairs(ctx, MarketCache(cfg), pairs)
+		if err != nil {
+			// Let the candles endpoint decide rather than failing on the lookup
+			slog.WarnContext(ctx, "Failed to validate pairs against markets", "error", err)
+		}
+
+		since := time.Now().Add(-window).Truncate(time.Second)
+		result := CorrelationsResult{CandleDuration: duration, Since: since.UTC(), Errors: rejected}
+		closes := make(map[string]map[int64]float64, len(pairs))
+		fan := newFanOut(ctx)
+		for _, pair := range pairs {
+			if fan.expired() {
+				result.timeOut(pair)
+				continue
+			}
+			candles, err := getCandles(ctx, cfg, pair, duration, since, time.Now())
+			if err != nil && fan.timedOut(err) {
+				result.timeOut(pair)
+				continue
+			}
+			if err != nil {
+				result.Errors = append(result.Errors, PairError{Pair: pair, Error: fmt.Sprintf("getting candles: %v", err)})
+				continue
+			}
+			byTime := make(map[int64]float64, len(candles))
+			for _, c := range candles {
+				if c.Close.Sign() > 0 {
+					byTime[time.Time(c.Timestamp).Unix()] = c.Close.Float64()
+				}
+			}
+			result.Pairs = append(result.Pairs, pair)
+			closes[pair] = byTime
+		}
+		if len(result.Pairs) < 2 {
+			return mcp.NewToolResultError(fmt.Sprintf("Fewer than 2 pairs have candles to compare: %s", formatPairErrors(withTimedOut(result.Errors, result.PartialResult)))), nil
+		}
+
+		returns := alignedReturns(result.Pairs, closes)
+		result.Observations = len(returns[result.Pairs[0]])
+		if result.Observations < minCorrelationReturns {
+			return mcp.NewToolResultError(fmt.Sprintf("Only %d returns were found at times all pairs traded, too few to correlate. Use more days or a longer duration.",
+				result.Observations)), nil
+		}
+
+		result.Matrix = make(map[string]map[string]float64, len(result.Pairs))
+		for _, a := range result.Pairs {
+			result.Matrix[a] = make(map[string]float64, len(result.Pairs))
+			for _, b := range result.Pairs {
+				result.Matrix[a][b] = correlation(returns[a], returns[b])
+			}
+			if stdDev(returns[a]) == 0 {
+				result.Notes = append(result.Notes, fmt.Sprintf("The price of %s did not change, so its correlations are reported as 0", a))
+			}
+		}
+
+		resultJSON, err := marshalJSON(result)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal correlations: %v", err)), nil
+		}
+		return mcp.NewToolResultText(resultJSON), nil
+	}
+}
+
+// alignedReturns returns, for each pair, the returns between consecutive candle
+// times at which every pair has a close
+func alignedReturns(pairs []string, closes map[string]map[int64]float64) map[string][]float64 {
+	var times []int64
+	for t := range closes[pairs[0]] {
+		if !slices.ContainsFunc(pairs[1:], func(p string) bool { _, ok := closes[p][t]; return !ok }) {
+			times = append(times, t)
+		}
+	}
+	slices.Sort(times)
+
+	returns := make(map[string][]float64, len(pairs))
+	for _, pair := range pairs {
+		returns[pair] = []float64{}
+		for i := 1; i < len(times); i++ {
+			prev, cur := closes[pair][times[i-1]], closes[pair][times[i]]
+			returns[pair] = append(returns[pair], cur/prev-1)
+		}
+	}
+	return returns
+}
+
+// correlation returns the Pearson correlation of x and y, rounded to
+// correlationScale places, or 0 if either does not vary
+func correlation(x, y []float64) float64 {
+	mx, my := mean(x), mean(y)
+	var cov, vx, vy float64
+	for i := range x {
+		dx, dy := x[i]-mx, y[i]-my
+		cov += dx * dy
+		vx += dx * dx
+		vy += dy * dy
+	}
+	if vx == 0 || vy == 0 {
+		return 0
+	}
+	r := cov / math.Sqrt(vx*vy)
+	scale := math.Pow10(correlationScale)
+	return math.Max(-1, math.Min(1, math.Round(r*scale)/scale))
+}
+
+func mean(x []float64) float64 {
+	var sum float64
+	for _, v := range x {
+		sum += v
+	}
+	return sum / float64(len(x))
+}
+
+func stdDev(x []float64) float64 {
+	m := mean(x)
+	var sum float64
+	for _, v := range x {
+		sum += (v - m) * (v - m)
+	}
+	return math.Sqrt(sum / float64(len(x)))
+}
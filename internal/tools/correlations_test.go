@@ -0,0 +1,141 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleGetCorrelations(t *testing.T) {
+	ctx := context.Background()
+	markets := &luno.MarketsResponse{Markets: []luno.MarketInfo{
+		{MarketId: "XBTZAR"}, {MarketId: "ETHZAR"}, {MarketId: "USDCZAR"},
+	}}
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	candles := func(closes ...string) *luno.GetCandlesResponse {
+		res := &luno.GetCandlesResponse{}
+		for i, c := range closes {
+			res.Candles = append(res.Candles, luno.Candle{
+				Timestamp: luno.Time(start.Add(time.Duration(i) * 24 * time.Hour)),
+				Close:     NewFromString(t, c),
+			})
+		}
+		return res
+	}
+	forPair := func(pair string) any {
+		return mock.MatchedBy(func(r *luno.GetCandlesRequest) bool { return r.Pair == pair && r.Duration == 86400 })
+	}
+
+	tests := []struct {
+		name          string
+		args          map[string]any
+		mockSetup     func(*sdk.MockLunoClient)
+		expMatrix     map[string]map[string]float64
+		expObs        int
+		expErrors     []string
+		expNotes      int
+		errorContains string
+	}{
+		{
+			name: "correlated and opposite",
+			args: map[string]any{"pairs": "XBTZAR,ETHZAR,USDCZAR"},
+			mockSetup: func(c *sdk.MockLunoClient) {
+				c.EXPECT().Markets(ctx, &luno.MarketsRequest{}).Return(markets, nil)
+				c.EXPECT().GetCandles(ctx, forPair("XBTZAR")).Return(candles("100", "110", "99", "108.9", "100"), nil)
+				c.EXPECT().GetCandles(ctx, forPair("ETHZAR")).Return(candles("10", "11", "9.9", "10.89", "10"), nil)
+				c.EXPECT().GetCandles(ctx, forPair("USDCZAR")).Return(candles("20", "18", "19.8", "17.82", "20"), nil)
+			},
+			expMatrix: map[string]map[string]float64{
+				"XBTZAR":  {"XBTZAR": 1, "ETHZAR": 1, "USDCZAR": -0.9899},
+				"ETHZAR":  {"XBTZAR": 1, "ETHZAR": 1, "USDCZAR": -0.9899},
+				"USDCZAR": {"XBTZAR": -0.9899, "ETHZAR": -0.9899, "USDCZAR": 1},
+			},
+			expObs: 4,
+		},
+		{
+			name: "returns only at common times, unknown and failing pairs reported",
+			args: map[string]any{"pairs": "xbt-zar,ETHZAR,USDCZAR,DOGEZAR"},
+			mockSetup: func(c *sdk.MockLunoClient) {
+				c.EXPECT().Markets(ctx, &luno.MarketsRequest{}).Return(markets, nil)
+				gappy := candles("100", "110", "99", "108.9", "100")
+				gappy.Candles = append(gappy.Candles[:1], gappy.Candles[2:]...)
+				c.EXPECT().GetCandles(ctx, forPair("XBTZAR")).Return(gappy, nil)
+				c.EXPECT().GetCandles(ctx, forPair("ETHZAR")).Return(candles("10", "10", "10", "10", "10"), nil)
+				c.EXPECT().GetCandles(ctx, forPair("USDCZAR")).Return(nil, errors.New(apiErrorStr))
+			},
+			expMatrix: map[string]map[string]float64{
+				"XBTZAR": {"XBTZAR": 1, "ETHZAR": 0},
+				"ETHZAR": {"XBTZAR": 0, "ETHZAR": 0},
+			},
+			expObs:    3,
+			expErrors: []string{"DOGEZAR", "USDCZAR"},
+			expNotes:  1,
+		},
+		{
+			name: "too few common candles",
+			args: map[string]any{"pairs": "XBTZAR,ETHZAR"},
+			mockSetup: func(c *sdk.MockLunoClient) {
+				c.EXPECT().Markets(ctx, &luno.MarketsRequest{}).Return(markets, nil)
+				c.EXPECT().GetCandles(ctx, forPair("XBTZAR")).Return(candles("100", "110"), nil)
+				c.EXPECT().GetCandles(ctx, forPair("ETHZAR")).Return(candles("10", "11", "12"), nil)
+			},
+			errorContains: "Only 1 returns were found",
+		},
+		{
+			name:          "one pair",
+			args:          map[string]any{"pairs": "XBTZAR,xbtzar"},
+			errorContains: "Give between 2 and 10 different pairs",
+		},
+		{
+			name:          "too many candles",
+			args:          map[string]any{"pairs": "XBTZAR,ETHZAR", "days": 90, "duration": 3600},
+			errorContains: "2160 candles, more than the 1000 Luno returns",
+		},
+		{
+			name:          "missing pairs",
+			args:          map[string]any{},
+			errorContains: "pairs is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := sdk.NewMockLunoClient(t)
+			if tt.mockSetup != nil {
+				tt.mockSetup(mockClient)
+			}
+			cfg := &config.Config{LunoClient: mockClient}
+
+			result, err := HandleGetCorrelations(cfg)(ctx, createMockRequest(tt.args))
+			require.NoError(t, err)
+			text := getTextContentFromResult(t, result)
+			if tt.errorContains != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, text, tt.errorContains)
+				return
+			}
+			require.False(t, result.IsError, text)
+
+			var got CorrelationsResult
+			require.NoError(t, json.Unmarshal([]byte(text), &got))
+			assert.Equal(t, tt.expMatrix, got.Matrix)
+			assert.Equal(t, tt.expObs, got.Observations)
+			assert.Equal(t, int64(86400), got.CandleDuration)
+			var errPairs []string
+			for _, e := range got.Errors {
+				errPairs = append(errPairs, e.Pair)
+			}
+			assert.Equal(t, tt.expErrors, errPairs)
+			assert.Len(t, got.Notes, tt.expNotes)
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// allowDuplicateParam is the argument that places a limit order matching an
+// open or recent order
+const allowDuplicateParam = "allow_duplicate"
+
+// withAllowDuplicate adds the allow_duplicate argument to a tool that places
+// limit orders
+func withAllowDuplicate() mcp.ToolOption {
+	return mcp.WithBoolean(
+		allowDuplicateParam,
+		mcp.Description("Set to true to place an order with the same pair, side, price and volume as an open order or one placed in the last few minutes. "+
+			"Only set it once the user has confirmed they want another identical order, not when retrying a call that timed out (default: false)"),
+	)
+}
+
+// checkDuplicateOrder rejects a limit order matching one of the latest orders
+// on pair that is still open or was placed within cfg.DuplicateOrderWindow,
+// unless allowDuplicate is set. A create_order call that times out may still
+// have placed its order, so retrying it would place a second one. Luno
+// accepts duplicates, so the check is skipped if the orders can't be listed.
+func checkDuplicateOrder(ctx context.Context, cfg *config.Config, pair string, side luno.OrderType, volume, price decimal.Decimal, allowDuplicate bool) error {
+	if allowDuplicate || cfg.DuplicateOrderWindow <= 0 {
+		return nil
+	}
+	res, err := cfg.LunoClient.ListOrders(ctx, &luno.ListOrdersRequest{Pair: pair})
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to list orders for duplicate check", "pair", pair, "error", err)
+		return nil
+	}
+
+	now := time.Now()
+	for _, o := range res.Orders {
+		if o.Type != side || o.LimitPrice.Cmp(price) != 0 || o.LimitVolume.Cmp(volume) != 0 {
+			continue
+		}
+		age := now.Sub(time.Time(o.CreationTimestamp))
+		var when string
+		switch {
+		case o.State == luno.OrderStatePending:
+			when = "which is still open"
+		case age <= cfg.DuplicateOrderWindow:
+			when = fmt.Sprintf("placed %s ago", age.Truncate(time.Second))
+		default:
+			continue
+		}
+		return fmt.Errorf("it matches order %s (%s %s %s at %s), %s. "+
+			"If an earlier call timed out or failed unclearly, its order may have been placed after all; check with list_orders rather than retrying. "+
+			"To place another identical order, confirm with the user and call again with %s=true",
+			o.OrderId, side, volume, pair, price, when, allowDuplicateParam)
+	}
+	return nil
+}
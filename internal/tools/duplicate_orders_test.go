@@ -0,0 +1,115 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckDuplicateOrder(t *testing.T) {
+	order := func(id string, side luno.OrderType, volume, price string, state luno.OrderState, age time.Duration) luno.Order {
+		return luno.Order{
+			OrderId:           id,
+			Pair:              "XBTZAR",
+			Type:              side,
+			LimitVolume:       NewFromString(t, volume),
+			LimitPrice:        NewFromString(t, price),
+			State:             state,
+			CreationTimestamp: luno.Time(time.Now().Add(-age)),
+		}
+	}
+
+	tests := []struct {
+		name           string
+		orders         []luno.Order
+		listErr        error
+		allowDuplicate bool
+		window         time.Duration
+		errorContains  string
+	}{
+		{
+			name:          "matches an open order",
+			orders:        []luno.Order{order("BXOPEN", luno.OrderTypeBid, "0.01", "1000000", luno.OrderStatePending, 2*time.Hour)},
+			window:        10 * time.Minute,
+			errorContains: "it matches order BXOPEN (BID 0.01 XBTZAR at 1000000), which is still open",
+		},
+		{
+			name:          "matches a recent order",
+			orders:        []luno.Order{order("BXRECENT", luno.OrderTypeBid, "0.010", "1000000", luno.OrderStateComplete, 3*time.Minute)},
+			window:        10 * time.Minute,
+			errorContains: "it matches order BXRECENT (BID 0.01 XBTZAR at 1000000), placed 3m",
+		},
+		{
+			name:   "old completed order",
+			orders: []luno.Order{order("BXOLD", luno.OrderTypeBid, "0.01", "1000000", luno.OrderStateComplete, time.Hour)},
+			window: 10 * time.Minute,
+		},
+		{
+			name: "different side, price or volume",
+			orders: []luno.Order{
+				order("BXASK", luno.OrderTypeAsk, "0.01", "1000000", luno.OrderStatePending, time.Minute),
+				order("BXPRICE", luno.OrderTypeBid, "0.01", "1000001", luno.OrderStatePending, time.Minute),
+				order("BXVOLUME", luno.OrderTypeBid, "0.02", "1000000", luno.OrderStatePending, time.Minute),
+			},
+			window: 10 * time.Minute,
+		},
+		{name: "allowed duplicate", allowDuplicate: true, window: 10 * time.Minute},
+		{name: "check disabled"},
+		{name: "orders unavailable", listErr: errors.New("connection reset"), window: 10 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := sdk.NewMockLunoClient(t)
+			if tt.orders != nil || tt.listErr != nil {
+				mockClient.EXPECT().ListOrders(mock.Anything, &luno.ListOrdersRequest{Pair: "XBTZAR"}).
+					Return(&luno.ListOrdersResponse{Orders: tt.orders}, tt.listErr)
+			}
+			cfg := &config.Config{LunoClient: mockClient, DuplicateOrderWindow: tt.window}
+
+			err := checkDuplicateOrder(context.Background(), cfg, "XBTZAR", luno.OrderTypeBid,
+				NewFromString(t, "0.01"), NewFromString(t, "1000000"), tt.allowDuplicate)
+			if tt.errorContains == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorContains)
+			assert.Contains(t, err.Error(), "allow_duplicate=true")
+		})
+	}
+}
+
+func TestHandleCreateOrderDuplicate(t *testing.T) {
+	mockClient := sdk.NewMockLunoClient(t)
+	mockClient.EXPECT().Markets(mock.Anything, &luno.MarketsRequest{}).
+		Return(&luno.MarketsResponse{Markets: []luno.MarketInfo{{MarketId: "XBTZAR", TradingStatus: luno.TradingStatusActive}}}, nil)
+	mockClient.EXPECT().ListOrders(mock.Anything, &luno.ListOrdersRequest{Pair: "XBTZAR"}).
+		Return(&luno.ListOrdersResponse{Orders: []luno.Order{{
+			OrderId:     "BXOPEN",
+			Pair:        "XBTZAR",
+			Type:        luno.OrderTypeAsk,
+			LimitVolume: NewFromString(t, "0.01"),
+			LimitPrice:  NewFromString(t, "1000000"),
+			State:       luno.OrderStatePending,
+		}}}, nil)
+	cfg := &config.Config{LunoClient: mockClient, IsAuthenticated: true, DuplicateOrderWindow: config.DefaultDuplicateOrderWindow}
+
+	// PostLimitOrder is not expected, so the order must not be placed
+	result, err := HandleCreateOrder(cfg)(context.Background(), createMockRequest(map[string]any{
+		"pair": "XBTZAR", "type": "SELL", "volume": "0.01", "price": "1000000",
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	text := getTextContentFromResult(t, result)
+	assert.Contains(t, text, "Unable to create order: it matches order BXOPEN")
+	assert.Contains(t, text, "allow_duplicate=true")
+}
@@ -0,0 +1,155 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// API health states reported by get_exchange_status
+const (
+	APIStatusOK          = "ok"
+	APIStatusDegraded    = "degraded"
+	APIStatusUnavailable = "unavailable"
+)
+
+// slowAPIThreshold is the response time above which the API is reported as degraded
+const slowAPIThreshold = 2 * time.Second
+
+// ExchangeStatus is the result of the get_exchange_status tool
+type ExchangeStatus struct {
+	API APIHealth `json:"api"`
+	// Active, PostOnly and Disabled count the markets in each trading status
+	Active   int `json:"active"`
+	PostOnly int `json:"post_only"`
+	Disabled int `json:"disabled"`
+	// MarketsAsOf is set when the markets come from the cache because the API
+	// could not be reached
+	MarketsAsOf *time.Time     `json:"markets_as_of,omitempty"`
+	Markets     []MarketStatus `json:"markets"`
+	Errors      []PairError    `json:"errors,omitempty"`
+}
+
+// APIHealth describes how the Luno API responded to a market list request
+type APIHealth struct {
+	Status    string `json:"status"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// MarketStatus is the trading status of one market
+type MarketStatus struct {
+	Pair          string             `json:"pair"`
+	TradingStatus luno.TradingStatus `json:"trading_status"`
+	Description   string             `json:"description"`
+}
+
+// NewGetExchangeStatusTool creates a new tool for checking exchange and market status
+func NewGetExchangeStatusTool() mcp.Tool {
+	return mcp.NewTool(
+		GetExchangeStatusToolID,
+		mcp.WithDescription("Check whether the Luno API is healthy and which markets are active, post-only or disabled. Use this to explain why orders are being rejected, e.g. during maintenance windows."),
+		mcp.WithString(
+			"pair",
+			mcp.Description("Comma-separated market pairs to report (e.g., XBTZAR,ETHZAR). Defaults to all markets."),
+		),
+	)
+}
+
+// HandleGetExchangeStatus handles the get_exchange_status tool
+func HandleGetExchangeStatus(cfg *config.Config) server.ToolHandlerFunc {
+	tool := NewGetExchangeStatusTool()
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		cfg := cfg.ForContext(ctx)
+		var args pairArgs
+		if errResult := bindArguments(request, tool, &args); errResult != nil {
+			return errResult, nil
+		}
+		status := ExchangeStatus{Markets: []MarketStatus{}}
+
+		start := time.Now()
+		res, err := cfg.LunoClient.Markets(ctx, &luno.MarketsRequest{})
+		latency := time.Since(start)
+		status.API = APIHealth{Status: APIStatusOK, LatencyMS: latency.Milliseconds()}
+
+		var list []luno.MarketInfo
+		switch {
+		case err != nil:
+			status.API.Status = APIStatusUnavailable
+			status.API.Error = err.Error()
+			// Fall back to the last known market list, if any
+			var asOf time.Time
+			list, asOf = MarketCache(cfg).Cached()
+			if !asOf.IsZero() {
+				status.MarketsAsOf = &asOf
+			}
+		case latency > slowAPIThreshold:
+			status.API.Status = APIStatusDegraded
+			list = res.Markets
+		default:
+			list = res.Markets
+		}
+
+		byPair := make(map[string]luno.MarketInfo, len(list))
+		pairs := make([]string, 0, len(list))
+		for _, m := range list {
+			byPair[m.MarketId] = m
+			pairs = append(pairs, m.MarketId)
+		}
+		slices.Sort(pairs)
+
+		if requested := parsePairList(args.Pair); len(requested) > 0 {
+			pairs = nil
+			for _, pair := range requested {
+				if _, ok := byPair[pair]; ok {
+					pairs = append(pairs, pair)
+				} else if len(list) > 0 {
+					status.Errors = append(status.Errors, PairError{Pair: pair, Error: unknownMarketError})
+				}
+			}
+		}
+
+		for _, pair := range pairs {
+			m := byPair[pair]
+			switch m.TradingStatus {
+			case luno.TradingStatusActive:
+				status.Active++
+			case luno.TradingStatusPost_only:
+				status.PostOnly++
+			case luno.TradingStatusSuspended:
+				status.Disabled++
+			}
+			status.Markets = append(status.Markets, MarketStatus{
+				Pair:          pair,
+				TradingStatus: m.TradingStatus,
+				Description:   describeTradingStatus(m.TradingStatus),
+			})
+		}
+
+		resultJSON, err := marshalJSON(status)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal exchange status: %v", err)), nil
+		}
+		return mcp.NewToolResultText(resultJSON), nil
+	}
+}
+
+// describeTradingStatus explains what a trading status means for new orders
+func describeTradingStatus(s luno.TradingStatus) string {
+	switch s {
+	case luno.TradingStatusActive:
+		return "Trading normally."
+	case luno.TradingStatusPost_only:
+		return "Post-only: only limit orders that rest on the order book are accepted. Market orders and limit orders that would trade immediately are rejected."
+	case luno.TradingStatusSuspended:
+		return "Disabled: trading is suspended and new orders are rejected, usually during maintenance."
+	default:
+		return "Unknown trading status; orders may be rejected."
+	}
+}
@@ -0,0 +1,105 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/markets"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleGetExchangeStatus(t *testing.T) {
+	listed := &luno.MarketsResponse{Markets: []luno.MarketInfo{
+		{MarketId: "XBTZAR", TradingStatus: luno.TradingStatusActive},
+		{MarketId: "ETHZAR", TradingStatus: luno.TradingStatusPost_only},
+		{MarketId: "XBTEUR", TradingStatus: luno.TradingStatusSuspended},
+	}}
+
+	tests := []struct {
+		name            string
+		requestParams   map[string]any
+		cached          bool
+		apiErr          error
+		expectedAPI     string
+		expectedPairs   []string
+		expectedCounts  [3]int
+		expectedErrors  []PairError
+		expectedCacheAt bool
+	}{
+		{
+			name:           "all markets",
+			requestParams:  map[string]any{},
+			expectedAPI:    APIStatusOK,
+			expectedPairs:  []string{"ETHZAR", "XBTEUR", "XBTZAR"},
+			expectedCounts: [3]int{1, 1, 1},
+		},
+		{
+			name:           "requested pairs",
+			requestParams:  map[string]any{"pair": "eth-zar, DOGEZAR"},
+			expectedAPI:    APIStatusOK,
+			expectedPairs:  []string{"ETHZAR"},
+			expectedCounts: [3]int{0, 1, 0},
+			expectedErrors: []PairError{{Pair: "DOGEZAR", Error: unknownMarketError}},
+		},
+		{
+			name:            "api unavailable falls back to cached markets",
+			requestParams:   map[string]any{"pair": "XBTEUR"},
+			cached:          true,
+			apiErr:          errors.New(apiErrorStr),
+			expectedAPI:     APIStatusUnavailable,
+			expectedPairs:   []string{"XBTEUR"},
+			expectedCounts:  [3]int{0, 0, 1},
+			expectedCacheAt: true,
+		},
+		{
+			name:          "api unavailable without cached markets",
+			requestParams: map[string]any{"pair": "XBTZAR"},
+			apiErr:        errors.New(apiErrorStr),
+			expectedAPI:   APIStatusUnavailable,
+			expectedPairs: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			mockClient := sdk.NewMockLunoClient(t)
+			cfg := &config.Config{LunoClient: mockClient, Markets: markets.NewCache(mockClient)}
+			if tt.cached {
+				mockClient.EXPECT().Markets(ctx, &luno.MarketsRequest{}).Return(listed, nil).Once()
+				require.NoError(t, cfg.Markets.Refresh(ctx))
+			}
+			var res *luno.MarketsResponse
+			if tt.apiErr == nil {
+				res = listed
+			}
+			mockClient.EXPECT().Markets(ctx, &luno.MarketsRequest{}).Return(res, tt.apiErr).Once()
+
+			result, err := HandleGetExchangeStatus(cfg)(ctx, createMockRequest(tt.requestParams))
+			require.NoError(t, err)
+			require.False(t, result.IsError)
+
+			var status ExchangeStatus
+			require.NoError(t, json.Unmarshal([]byte(getTextContentFromResult(t, result)), &status))
+			assert.Equal(t, tt.expectedAPI, status.API.Status)
+			if tt.apiErr != nil {
+				assert.Equal(t, apiErrorStr, status.API.Error)
+			}
+			pairs := []string{}
+			for _, m := range status.Markets {
+				pairs = append(pairs, m.Pair)
+				assert.NotEmpty(t, m.Description)
+			}
+			assert.Equal(t, tt.expectedPairs, pairs)
+			assert.Equal(t, tt.expectedCounts, [3]int{status.Active, status.PostOnly, status.Disabled})
+			assert.Equal(t, tt.expectedErrors, status.Errors)
+			assert.Equal(t, tt.expectedCacheAt, status.MarketsAsOf != nil)
+		})
+	}
+}
@@ -0,0 +1,336 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ExportResult describes a file written by an export tool
+type ExportResult struct {
+	Path string `json:"path"`
+	Rows int    `json:"rows"`
+}
+
+// NewExportTradesTool creates a new tool for exporting recent trades to CSV
+func NewExportTradesTool() mcp.Tool {
+	return mcp.NewTool(
+		ExportTradesToolID,
+		mcp.WithDescription("Export recent trades for a currency pair as CSV. "+
+			"The file is written into a root granted by the client (see list_roots); "+
+			"if the client has not granted any roots the CSV is returned inline."),
+		mcp.WithString(
+			"pair",
+			mcp.Required(),
+			mcp.Description(ErrTradingPairDesc),
+		),
+		mcp.WithString(
+			"since",
+			mcp.Description(timeParamDesc("Export trades executed after this time")),
+		),
+		mcp.WithString(
+			"root",
+			mcp.Description("URI of the granted root to write to (defaults to the first granted root)"),
+		),
+		mcp.WithString(
+			"filename",
+			mcp.Description("File name relative to the root (defaults to trades-<PAIR>-<timestamp>.csv)"),
+		),
+	)
+}
+
+// rootFileArgs are the arguments naming a file in a root granted by the client
+type rootFileArgs struct {
+	Root     string `json:"root"`
+	Filename string `json:"filename"`
+}
+
+// exportTradesArgs are the arguments of the export_trades tool
+type exportTradesArgs struct {
+	rootFileArgs
+	Pair  string `json:"pair"`
+	Since string `json:"since"`
+}
+
+// HandleExportTrades handles the export_trades tool
+func HandleExportTrades(cfg *config.Config) server.ToolHandlerFunc {
+	tool := NewExportTradesTool()
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		cfg := cfg.ForContext(ctx)
+		var args exportTradesArgs
+		if errResult := bindArguments(request, tool, &args); errResult != nil {
+			return errResult, nil
+		}
+		pair := normalizeCurrencyPair(args.Pair)
+
+		req := &luno.ListTradesRequest{Pair: pair}
+		since, ok, errResult := parseTime("since", args.Since, time.Now())
+		if errResult != nil {
+			return errResult, nil
+		}
+		if ok {
+			req.Since = luno.Time(since.Start)
+		}
+
+		trades, err := cfg.LunoClient.ListTrades(ctx, req)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("listing trades", err), nil
+		}
+
+		data, err := tradesToCSV(trades.Trades)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("encoding trades", err), nil
+		}
+
+		filename := args.Filename
+		if filename == "" {
+			filename = fmt.Sprintf("trades-%s-%s.csv", pair, time.Now().UTC().Format("20060102T150405Z"))
+		}
+		return exportResult(ctx, args.Root, filename, data, len(trades.Trades))
+	}
+}
+
+// PortfolioSnapshot is the snapshot written by export_portfolio
+type PortfolioSnapshot struct {
+	GeneratedAt time.Time     `json:"generated_at"`
+	Balances    []BalanceView `json:"balances"`
+	ConvertTo   string        `json:"convert_to"`
+	// TotalValue is the value of every priced balance in ConvertTo
+	TotalValue string `json:"total_value"`
+	// UnpricedAssets have no market to value them in ConvertTo and are left out of TotalValue
+	UnpricedAssets []string        `json:"unpriced_assets,omitempty"`
+	OpenOrders     []SnapshotOrder `json:"open_orders"`
+}
+
+// SnapshotOrder is an open order in a PortfolioSnapshot
+type SnapshotOrder struct {
+	OrderID     string         `json:"order_id"`
+	Pair        string         `json:"pair"`
+	Type        luno.OrderType `json:"type"`
+	LimitPrice  string         `json:"limit_price"`
+	LimitVolume string         `json:"limit_volume"`
+	// FilledBase and FilledCounter are what the order has traded so far
+	FilledBase    string    `json:"filled_base"`
+	FilledCounter string    `json:"filled_counter"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// NewExportPortfolioTool creates a new tool for exporting a snapshot of the account
+func NewExportPortfolioTool() mcp.Tool {
+	return mcp.NewTool(
+		ExportPortfolioToolID,
+		mcp.WithDescription("Export a timestamped snapshot of the account's balances, their value in one currency and its open orders, as CSV or JSON. "+
+			"The file is written into a root granted by the client (see list_roots); "+
+			"if the client has not granted any roots the snapshot is returned inline."),
+		mcp.WithString(
+			"format",
+			mcp.Description("File format: csv (default) or json"),
+			mcp.Enum("csv", "json"),
+		),
+		mcp.WithString(
+			"convert_to",
+			mcp.Description("Currency to value the balances in (default: ZAR)"),
+		),
+		mcp.WithString(
+			"root",
+			mcp.Description("URI of the granted root to write to (defaults to the first granted root)"),
+		),
+		mcp.WithString(
+			"filename",
+			mcp.Description("File name relative to the root (defaults to portfolio-<timestamp>.<format>)"),
+		),
+	)
+}
+
+// exportPortfolioArgs are the arguments of the export_portfolio tool
+type exportPortfolioArgs struct {
+	rootFileArgs
+	Format    string `json:"format"`
+	ConvertTo string `json:"convert_to"`
+}
+
+// HandleExportPortfolio handles the export_portfolio tool
+func HandleExportPortfolio(cfg *config.Config) server.ToolHandlerFunc {
+	tool := NewExportPortfolioTool()
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		cfg := cfg.ForContext(ctx)
+		if !cfg.IsAuthenticated {
+			return mcp.NewToolResultError(ErrAPICredentialsRequired), nil
+		}
+		args := exportPortfolioArgs{Format: "csv", ConvertTo: "ZAR"}
+		if errResult := bindArguments(request, tool, &args); errResult != nil {
+			return errResult, nil
+		}
+		format := args.Format
+		convertTo := normalizeCurrency(args.ConvertTo)
+
+		balances, err := cfg.LunoClient.GetBalances(ctx, &luno.GetBalancesRequest{})
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to get balances: %v", err)), nil
+		}
+		aliases, err := accountAliases(cfg)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("reading account aliases", err), nil
+		}
+		conv, err := newConverter(ctx, cfg.LunoClient, convertTo)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("converting balances", err), nil
+		}
+		open, err := cfg.LunoClient.ListOrders(ctx, &luno.ListOrdersRequest{State: luno.OrderStatePending})
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("listing open orders", err), nil
+		}
+
+		now := time.Now().UTC()
+		snapshot := PortfolioSnapshot{
+			GeneratedAt: now,
+			Balances:    make([]BalanceView, 0, len(balances.Balance)),
+			ConvertTo:   convertTo,
+			OpenOrders:  make([]SnapshotOrder, 0, len(open.Orders)),
+		}
+		total := decimal.Zero()
+		for _, b := range balances.Balance {
+			view := BalanceView{
+				AccountID:   b.AccountId,
+				Asset:       b.Asset,
+				Balance:     b.Balance.String(),
+				Reserved:    b.Reserved.String(),
+				Unconfirmed: b.Unconfirmed.String(),
+				Name:        b.Name,
+				Aliases:     aliases[b.AccountId],
+			}
+			if value, ok := conv.value(b.Asset, b.Balance); ok {
+				total = total.Add(value)
+				view.Value = value.String()
+			} else if !slices.Contains(snapshot.UnpricedAssets, b.Asset) {
+				snapshot.UnpricedAssets = append(snapshot.UnpricedAssets, b.Asset)
+			}
+			snapshot.Balances = append(snapshot.Balances, view)
+		}
+		snapshot.TotalValue = total.String()
+		for _, o := range open.Orders {
+			snapshot.OpenOrders = append(snapshot.OpenOrders, SnapshotOrder{
+				OrderID:       o.OrderId,
+				Pair:          o.Pair,
+				Type:          o.Type,
+				LimitPrice:    o.LimitPrice.String(),
+				LimitVolume:   o.LimitVolume.String(),
+				FilledBase:    o.Base.String(),
+				FilledCounter: o.Counter.String(),
+				CreatedAt:     time.Time(o.CreationTimestamp).UTC(),
+			})
+		}
+
+		var data []byte
+		if format == "json" {
+			data, err = json.MarshalIndent(snapshot, "", "  ")
+		} else {
+			data, err = portfolioToCSV(snapshot)
+		}
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("encoding portfolio", err), nil
+		}
+
+		filename := args.Filename
+		if filename == "" {
+			filename = fmt.Sprintf("portfolio-%s.%s", now.Format("20060102T150405Z"), format)
+		}
+		return exportResult(ctx, args.Root, filename, data, len(snapshot.Balances)+len(snapshot.OpenOrders))
+	}
+}
+
+// exportResult writes data into a granted root, falling back to returning it inline when
+// the client has not granted any roots.
+func exportResult(ctx context.Context, rootURI, filename string, data []byte, rows int) (*mcp.CallToolResult, error) {
+	path, err := writeToRoot(ctx, rootsListerFromContext(ctx), rootURI, filename, data)
+	if err != nil {
+		// An explicitly requested root that fails is an error; otherwise fall back to inline output
+		if rootURI != "" {
+			return mcp.NewToolResultErrorFromErr("writing export", err), nil
+		}
+		slog.Debug("Returning export inline", "filename", filename, "reason", err)
+		return mcp.NewToolResultText(fmt.Sprintf("No file roots available (%v). Export returned inline:\n\n%s", err, data)), nil
+	}
+
+	resultJSON, err := marshalJSON(ExportResult{Path: path, Rows: rows})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal export result: %v", err)), nil
+	}
+
+	return mcp.NewToolResultText(resultJSON), nil
+}
+
+// tradesToCSV encodes public trades as CSV with a header row
+func tradesToCSV(trades []luno.PublicTrade) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	if err := w.Write([]string{"sequence", "timestamp", "price", "volume", "is_buy"}); err != nil {
+		return nil, err
+	}
+	for _, t := range trades {
+		record := []string{
+			strconv.FormatInt(t.Sequence, 10),
+			time.Time(t.Timestamp).UTC().Format(time.RFC3339),
+			t.Price.String(),
+			t.Volume.String(),
+			strconv.FormatBool(t.IsBuy),
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// portfolioToCSV encodes a snapshot as CSV with a header row. Each row is a
+// balance, an open order or the total, as named in its record column, so the
+// file can be filtered in a spreadsheet.
+func portfolioToCSV(s PortfolioSnapshot) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	header := []string{
+		"record", "snapshot_time", "account_id", "asset", "name", "balance", "reserved", "unconfirmed", "value", "value_currency",
+		"order_id", "pair", "type", "limit_price", "limit_volume", "filled_base", "filled_counter", "created_at",
+	}
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+	at := s.GeneratedAt.Format(time.RFC3339)
+	for _, b := range s.Balances {
+		record := []string{"balance", at, b.AccountID, b.Asset, b.Name, b.Balance, b.Reserved, b.Unconfirmed, b.Value, s.ConvertTo,
+			"", "", "", "", "", "", "", ""}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	for _, o := range s.OpenOrders {
+		record := []string{"open_order", at, "", "", "", "", "", "", "", "",
+			o.OrderID, o.Pair, string(o.Type), o.LimitPrice, o.LimitVolume, o.FilledBase, o.FilledCounter, o.CreatedAt.Format(time.RFC3339)}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	total := []string{"total", at, "", "", "", "", "", "", s.TotalValue, s.ConvertTo, "", "", "", "", "", "", "", ""}
+	if err := w.Write(total); err != nil {
+		return nil, err
+	}
+
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
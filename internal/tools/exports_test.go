@@ -0,0 +1,206 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTradesToCSV(t *testing.T) {
+	tests := []struct {
+		name     string
+		trades   []luno.PublicTrade
+		expected string
+	}{
+		{
+			name:     "header only for no trades",
+			expected: "sequence,timestamp,price,volume,is_buy\n",
+		},
+		{
+			name: "single trade",
+			trades: []luno.PublicTrade{
+				{
+					Sequence:  42,
+					Timestamp: luno.Time(time.UnixMilli(testTimestamp)),
+					Price:     NewFromString(t, "50000.5"),
+					Volume:    NewFromString(t, "0.01"),
+					IsBuy:     true,
+				},
+			},
+			expected: "sequence,timestamp,price,volume,is_buy\n42,2022-01-01T00:00:00Z,50000.5,0.01,true\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tradesToCSV(tt.trades)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, string(data))
+		})
+	}
+}
+
+func TestHandleExportTrades(t *testing.T) {
+	tests := []struct {
+		name          string
+		requestParams map[string]any
+		mockSetup     func(*testing.T, *sdk.MockLunoClient)
+		expectedError bool
+		contains      string
+	}{
+		{
+			name:          "returns csv inline without a session",
+			requestParams: map[string]any{"pair": "XBTZAR"},
+			mockSetup: func(t *testing.T, mockClient *sdk.MockLunoClient) {
+				mockClient.EXPECT().ListTrades(mock.Anything, &luno.ListTradesRequest{Pair: "XBTZAR"}).
+					Return(&luno.ListTradesResponse{}, nil)
+			},
+			contains: "Export returned inline",
+		},
+		{
+			name:          "explicit root without a session",
+			requestParams: map[string]any{"pair": "XBTZAR", "root": "file:///tmp"},
+			mockSetup: func(t *testing.T, mockClient *sdk.MockLunoClient) {
+				mockClient.EXPECT().ListTrades(mock.Anything, mock.Anything).
+					Return(&luno.ListTradesResponse{}, nil)
+			},
+			expectedError: true,
+			contains:      "writing export",
+		},
+		{
+			name:          "invalid since",
+			requestParams: map[string]any{"pair": "XBTZAR", "since": "someday"},
+			mockSetup:     func(t *testing.T, mockClient *sdk.MockLunoClient) {},
+			expectedError: true,
+			contains:      `Invalid since: "someday" is not a time`,
+		},
+		{
+			name:          "API error",
+			requestParams: map[string]any{"pair": "XBTZAR"},
+			mockSetup: func(t *testing.T, mockClient *sdk.MockLunoClient) {
+				mockClient.EXPECT().ListTrades(mock.Anything, mock.Anything).
+					Return(nil, errors.New(apiErrorStr))
+			},
+			expectedError: true,
+			contains:      "listing trades",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := sdk.NewMockLunoClient(t)
+			tt.mockSetup(t, mockClient)
+
+			cfg := &config.Config{LunoClient: mockClient}
+			result, err := HandleExportTrades(cfg)(context.Background(), createMockRequest(tt.requestParams))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedError, result.IsError)
+			assert.Contains(t, getTextContentFromResult(t, result), tt.contains)
+		})
+	}
+}
+
+func TestPortfolioToCSV(t *testing.T) {
+	at := time.UnixMilli(testTimestamp).UTC()
+	data, err := portfolioToCSV(PortfolioSnapshot{
+		GeneratedAt: at,
+		Balances:    []BalanceView{{AccountID: "1", Asset: "XBT", Name: "Bitcoin", Balance: "0.5", Reserved: "0", Unconfirmed: "0", Value: "500000"}},
+		ConvertTo:   "ZAR",
+		TotalValue:  "500000",
+		OpenOrders:  []SnapshotOrder{{OrderID: "BXMC2CJ7HNB88U4", Pair: "XBTZAR", Type: luno.OrderTypeBid, LimitPrice: "900000", LimitVolume: "0.1", FilledBase: "0", FilledCounter: "0", CreatedAt: at}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "record,snapshot_time,account_id,asset,name,balance,reserved,unconfirmed,value,value_currency,"+
+		"order_id,pair,type,limit_price,limit_volume,filled_base,filled_counter,created_at\n"+
+		"balance,2022-01-01T00:00:00Z,1,XBT,Bitcoin,0.5,0,0,500000,ZAR,,,,,,,,\n"+
+		"open_order,2022-01-01T00:00:00Z,,,,,,,,,BXMC2CJ7HNB88U4,XBTZAR,BID,900000,0.1,0,0,2022-01-01T00:00:00Z\n"+
+		"total,2022-01-01T00:00:00Z,,,,,,,500000,ZAR,,,,,,,,\n", string(data))
+}
+
+func TestHandleExportPortfolio(t *testing.T) {
+	portfolio := func(t *testing.T, mockClient *sdk.MockLunoClient) {
+		mockClient.EXPECT().GetBalances(mock.Anything, mock.Anything).
+			Return(&luno.GetBalancesResponse{Balance: []luno.AccountBalance{
+				{AccountId: "1", Asset: "XBT", Balance: NewFromString(t, "0.5")},
+				{AccountId: "2", Asset: "ZAR", Balance: NewFromString(t, "100")},
+				{AccountId: "3", Asset: "UNKNOWN", Balance: NewFromString(t, "1")},
+			}}, nil)
+		mockClient.EXPECT().GetTickers(mock.Anything, mock.Anything).
+			Return(&luno.GetTickersResponse{Tickers: []luno.Ticker{{Pair: "XBTZAR", LastTrade: NewFromString(t, "1000000")}}}, nil)
+		mockClient.EXPECT().ListOrders(mock.Anything, &luno.ListOrdersRequest{State: luno.OrderStatePending}).
+			Return(&luno.ListOrdersResponse{Orders: []luno.Order{{OrderId: "BXMC2CJ7HNB88U4", Pair: "XBTZAR", Type: luno.OrderTypeAsk}}}, nil)
+	}
+
+	tests := []struct {
+		name            string
+		requestParams   map[string]any
+		isAuthenticated bool
+		mockSetup       func(*testing.T, *sdk.MockLunoClient)
+		expectedError   bool
+		contains        []string
+	}{
+		{
+			name:            "returns csv inline without a session",
+			isAuthenticated: true,
+			mockSetup:       portfolio,
+			contains:        []string{"Export returned inline", "balance,", "XBT,,0.5,0,0,500000,ZAR", "open_order,", "total,", ",500100,ZAR"},
+		},
+		{
+			name:            "json",
+			requestParams:   map[string]any{"format": "JSON"},
+			isAuthenticated: true,
+			mockSetup:       portfolio,
+			contains:        []string{`"total_value": "500100"`, `"unpriced_assets": [`, `"order_id": "BXMC2CJ7HNB88U4"`},
+		},
+		{
+			name:          "requires credentials",
+			mockSetup:     func(t *testing.T, mockClient *sdk.MockLunoClient) {},
+			expectedError: true,
+			contains:      []string{ErrAPICredentialsRequired},
+		},
+		{
+			name:            "invalid format",
+			requestParams:   map[string]any{"format": "xlsx"},
+			isAuthenticated: true,
+			mockSetup:       func(t *testing.T, mockClient *sdk.MockLunoClient) {},
+			expectedError:   true,
+			contains:        []string{`format must be one of csv, json, got "xlsx"`},
+		},
+		{
+			name:            "orders API error",
+			isAuthenticated: true,
+			mockSetup: func(t *testing.T, mockClient *sdk.MockLunoClient) {
+				mockClient.EXPECT().GetBalances(mock.Anything, mock.Anything).Return(&luno.GetBalancesResponse{}, nil)
+				mockClient.EXPECT().GetTickers(mock.Anything, mock.Anything).
+					Return(&luno.GetTickersResponse{Tickers: []luno.Ticker{{Pair: "XBTZAR", LastTrade: NewFromString(t, "1000000")}}}, nil)
+				mockClient.EXPECT().ListOrders(mock.Anything, mock.Anything).Return(nil, errors.New(apiErrorStr))
+			},
+			expectedError: true,
+			contains:      []string{"listing open orders"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := sdk.NewMockLunoClient(t)
+			tt.mockSetup(t, mockClient)
+
+			cfg := &config.Config{LunoClient: mockClient, IsAuthenticated: tt.isAuthenticated}
+			result, err := HandleExportPortfolio(cfg)(context.Background(), createMockRequest(tt.requestParams))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedError, result.IsError)
+			text := getTextContentFromResult(t, result)
+			for _, s := range tt.contains {
+				assert.Contains(t, text, s)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/continuation"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// NewFetchMoreTool creates a new tool for reading the rest of a truncated result
+func NewFetchMoreTool() mcp.Tool {
+	return mcp.NewTool(
+		FetchMoreToolID,
+		mcp.WithDescription("Get the next part of a tool result that was truncated for being too large. "+
+			"Truncated results end with a note giving the cursor to pass here."),
+		mcp.WithString(
+			"cursor",
+			mcp.Required(),
+			mcp.Description("Cursor from the end of the truncated result"),
+		),
+	)
+}
+
+// fetchMoreArgs are the arguments of the fetch_more tool
+type fetchMoreArgs struct {
+	Cursor string `json:"cursor"`
+}
+
+// HandleFetchMore handles the fetch_more tool. The returned text is truncated
+// again if it is still over budget, with a new cursor for the part after it.
+func HandleFetchMore(cfg *config.Config) server.ToolHandlerFunc {
+	tool := NewFetchMoreTool()
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		cfg := cfg.ForContext(ctx)
+		var args fetchMoreArgs
+		if errResult := bindArguments(request, tool, &args); errResult != nil {
+			return errResult, nil
+		}
+		cursor := args.Cursor
+		if cfg.Continuations == nil {
+			return mcp.NewToolResultError("Truncated results are not kept by this server"), nil
+		}
+
+		text, ok := cfg.Continuations.Take(cursor)
+		if !ok {
+			return mcp.NewToolResultError(fmt.Sprintf("Unknown or expired cursor %q. Cursors can only be used once and expire after %s; call the original tool again.",
+				cursor, continuation.DefaultTTL)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	}
+}
+
+// TruncateResult cuts a text result that is over cfg.MaxResponseBytes down to
+// size, keeping the rest in cfg.Continuations and ending the text with the
+// cursor to fetch it, and drops any structured content. Text after the first,
+// such as warnings, is kept whole and counted against the budget, so only the
+// first text is cut. Other results are returned unchanged.
+func TruncateResult(cfg *config.Config, result *mcp.CallToolResult) *mcp.CallToolResult {
+	if result == nil || cfg.MaxResponseBytes <= 0 || cfg.Continuations == nil || len(result.Content) == 0 {
+		return result
+	}
+	content, ok := result.Content[0].(mcp.TextContent)
+	if !ok {
+		return result
+	}
+	var reserved int
+	for _, c := range result.Content[1:] {
+		text, ok := c.(mcp.TextContent)
+		if !ok {
+			return result
+		}
+		reserved += len(text.Text)
+	}
+	if len(content.Text)+reserved <= cfg.MaxResponseBytes {
+		return result
+	}
+
+	head, rest := continuation.Split(content.Text, max(cfg.MaxResponseBytes-reserved, 1))
+	cursor := cfg.Continuations.Put(rest)
+	content.Text = head + fmt.Sprintf("\n\n[Truncated: %d more bytes. Call %s with cursor %q to get the next part.]",
+		len(rest), FetchMoreToolID, cursor)
+
+	truncated := *result
+	truncated.Content = append([]mcp.Content{content}, result.Content[1:]...)
+	// The structured copy of the result would blow the budget just the same
+	truncated.StructuredContent = nil
+	return &truncated
+}
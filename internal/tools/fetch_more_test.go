@@ -0,0 +1,139 @@
+package tools
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/continuation"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var cursorPattern = regexp.MustCompile(`cursor "([A-Z0-9]+)"`)
+
+func TestTruncateResult(t *testing.T) {
+	long := strings.Repeat("0123456789\n", 10)
+
+	tests := []struct {
+		name             string
+		maxResponseBytes int
+		result           *mcp.CallToolResult
+		expectTruncated  bool
+	}{
+		{
+			name:             "within budget",
+			maxResponseBytes: 1000,
+			result:           mcp.NewToolResultText(long),
+		},
+		{
+			name:             "over budget",
+			maxResponseBytes: 50,
+			result:           mcp.NewToolResultText(long),
+			expectTruncated:  true,
+		},
+		{
+			name:             "structured content is dropped",
+			maxResponseBytes: 50,
+			result:           mcp.NewToolResultStructured(map[string]any{"text": long}, long),
+			expectTruncated:  true,
+		},
+		{
+			name:             "errors are truncated too",
+			maxResponseBytes: 50,
+			result:           mcp.NewToolResultError(long),
+			expectTruncated:  true,
+		},
+		{
+			name:   "disabled",
+			result: mcp.NewToolResultText(long),
+		},
+		{
+			name:             "non-text content",
+			maxResponseBytes: 10,
+			result:           mcp.NewToolResultImage(long, "aW1hZ2U=", "image/png"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{MaxResponseBytes: tt.maxResponseBytes, Continuations: continuation.NewStore(time.Minute)}
+			result := TruncateResult(cfg, tt.result)
+			if !tt.expectTruncated {
+				assert.Same(t, tt.result, result)
+				return
+			}
+
+			text := getTextContentFromResult(t, result)
+			assert.Equal(t, tt.result.IsError, result.IsError)
+			assert.True(t, strings.HasPrefix(text, long[:44]), "the head ends at the last line break in budget")
+			assert.Contains(t, text, "[Truncated: 66 more bytes. Call fetch_more with cursor")
+			assert.Nil(t, result.StructuredContent)
+			assert.Equal(t, long, getTextContentFromResult(t, tt.result), "the original result is not modified")
+		})
+	}
+}
+
+func TestTruncateResultKeepsWarnings(t *testing.T) {
+	long := strings.Repeat("0123456789\n", 10)
+	warning := "Warning: check this."
+	cfg := &config.Config{MaxResponseBytes: 50 + len(warning), Continuations: continuation.NewStore(time.Minute)}
+
+	result := TruncateResult(cfg, &mcp.CallToolResult{Content: []mcp.Content{mcp.NewTextContent(long), mcp.NewTextContent(warning)}})
+	require.Len(t, result.Content, 2)
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.True(t, strings.HasPrefix(text, long[:44]), "the warning's size is left out of the head's budget")
+	assert.Contains(t, text, "[Truncated: 66 more bytes.")
+	assert.Equal(t, warning, result.Content[1].(mcp.TextContent).Text, "the warning is kept whole")
+
+	small := &mcp.CallToolResult{Content: []mcp.Content{mcp.NewTextContent(long[:40]), mcp.NewTextContent(warning)}}
+	assert.Same(t, small, TruncateResult(cfg, small), "results within budget with their warnings are unchanged")
+}
+
+func TestHandleFetchMore(t *testing.T) {
+	long := strings.Repeat("0123456789\n", 10)
+	cfg := &config.Config{MaxResponseBytes: 50, Continuations: continuation.NewStore(time.Minute)}
+	handler := HandleFetchMore(cfg)
+
+	// Read the whole result back the way the server does, truncating each part
+	var parts []string
+	result := TruncateResult(cfg, mcp.NewToolResultText(long))
+	for {
+		text := getTextContentFromResult(t, result)
+		match := cursorPattern.FindStringSubmatch(text)
+		if match == nil {
+			parts = append(parts, text)
+			break
+		}
+		parts = append(parts, text[:strings.Index(text, "\n\n[Truncated")])
+
+		var err error
+		result, err = handler(context.Background(), createMockRequest(map[string]any{"cursor": match[1]}))
+		require.NoError(t, err)
+		require.False(t, result.IsError)
+		result = TruncateResult(cfg, result)
+
+		reused, err := handler(context.Background(), createMockRequest(map[string]any{"cursor": match[1]}))
+		require.NoError(t, err)
+		assert.True(t, reused.IsError, "cursors can only be used once")
+	}
+	assert.Len(t, parts, 3)
+	assert.Equal(t, long, strings.Join(parts, ""))
+
+	t.Run("unknown cursor", func(t *testing.T) {
+		result, err := handler(context.Background(), createMockRequest(map[string]any{"cursor": "NOPE"}))
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Contains(t, getTextContentFromResult(t, result), `Unknown or expired cursor "NOPE"`)
+	})
+
+	t.Run("missing cursor", func(t *testing.T) {
+		result, err := handler(context.Background(), createMockRequest(map[string]any{}))
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+	})
+}
@@ -0,0 +1,140 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/luno/luno-mcp/internal/filter"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Sort orders for list tools
+const (
+	sortAsc  = "asc"
+	sortDesc = "desc"
+)
+
+// listParams are the filter, sort_by, order and fields parameters of a list
+// tool. what names the results, and example is a filter over their fields.
+func listParams(what, example string) []mcp.ToolOption {
+	return []mcp.ToolOption{
+		mcp.WithString(
+			"filter",
+			mcp.Description(fmt.Sprintf("Only return the %s matching this expression, e.g. %s. "+
+				"Compare fields of the result with ==, !=, >, >=, <, <= or contains, and combine comparisons with AND, OR, NOT and parentheses. "+
+				"Quote strings; amounts are compared as numbers and timestamps as Unix milliseconds. "+
+				"The filter applies to the page fetched from Luno.", what, example)),
+		),
+		mcp.WithString(
+			"sort_by",
+			mcp.Description(fmt.Sprintf("Field of the result to sort the %s by, e.g. volume or timestamp (default: the order Luno returns)", what)),
+		),
+		mcp.WithString(
+			"order",
+			mcp.Description("Sort order for sort_by (default: asc)"),
+			mcp.Enum(sortAsc, sortDesc),
+		),
+		mcp.WithString(
+			"fields",
+			mcp.Description(fmt.Sprintf("Comma-separated fields of each of the %s to return, e.g. timestamp,price,volume (default: all)", what)),
+		),
+	}
+}
+
+// listArgs are the arguments of the list parameters, embedded in the
+// arguments of each list tool
+type listArgs struct {
+	Filter string `json:"filter"`
+	SortBy string `json:"sort_by"`
+	Order  string `json:"order"`
+	Fields string `json:"fields"`
+}
+
+// listQuery is how a list tool's results are narrowed, sorted and trimmed
+type listQuery struct {
+	filter *filter.Expr
+	sortBy string
+	desc   bool
+	fields []string
+}
+
+// parseListQuery reads the list parameters. If they are invalid, the result
+// to return to the caller is given instead.
+func parseListQuery(args listArgs) (listQuery, *mcp.CallToolResult) {
+	var q listQuery
+	if args.Filter != "" {
+		e, err := filter.Parse(args.Filter)
+		if err != nil {
+			return listQuery{}, mcp.NewToolResultError(fmt.Sprintf("Invalid filter: %v", err))
+		}
+		q.filter = e
+	}
+	q.sortBy = strings.ToLower(strings.TrimSpace(args.SortBy))
+	q.desc = args.Order == sortDesc
+	q.fields = filter.ParseFields(args.Fields)
+	return q, nil
+}
+
+// applyFilter returns the items matching the query's filter
+func applyFilter[T any](q listQuery, items []T) ([]T, *mcp.CallToolResult) {
+	if q.filter == nil {
+		return items, nil
+	}
+	out, err := filter.Apply(q.filter, items)
+	if err != nil {
+		return nil, mcp.NewToolResultError(fmt.Sprintf("Invalid filter: %v", err))
+	}
+	return out, nil
+}
+
+// applySort returns a sorted copy of items if the query sorts them
+func applySort[T any](q listQuery, items []T) ([]T, *mcp.CallToolResult) {
+	if q.sortBy == "" {
+		return items, nil
+	}
+	sorted := append([]T(nil), items...)
+	if err := filter.Sort(sorted, q.sortBy, q.desc); err != nil {
+		return nil, mcp.NewToolResultError(fmt.Sprintf("Invalid sort_by: %v", err))
+	}
+	return sorted, nil
+}
+
+// listResult returns a list tool's result, with the items under key trimmed
+// to the query's fields. A trimmed result is returned as text only, since the
+// structured content, if any, is the complete result.
+func listResult(q listQuery, v any, key string, structured any) (*mcp.CallToolResult, error) {
+	if len(q.fields) == 0 {
+		resultJSON, err := marshalJSON(v)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal %s: %v", key, err)), nil
+		}
+		if structured == nil {
+			return mcp.NewToolResultText(resultJSON), nil
+		}
+		return mcp.NewToolResultStructured(structured, resultJSON), nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal %s: %v", key, err)), nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var rec map[string]any
+	if err := dec.Decode(&rec); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal %s: %v", key, err)), nil
+	}
+	items, _ := rec[key].([]any)
+	trimmed, err := filter.Project(items, q.fields)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid fields: %v", err)), nil
+	}
+	rec[key] = trimmed
+	resultJSON, err := marshalJSON(rec)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal %s: %v", key, err)), nil
+	}
+	return mcp.NewToolResultText(resultJSON), nil
+}
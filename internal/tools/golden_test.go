@@ -0,0 +1,203 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/testutil"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/golden")
+
+// TestGoldenResults checks tool results against the files in testdata/golden.
+// Clients and prompts depend on the shape of these results, so a change to
+// them should show up in review as a change to a golden file. Run
+//
+//	go test ./internal/tools -run TestGoldenResults -update
+//
+// to rewrite the files after a deliberate change.
+func TestGoldenResults(t *testing.T) {
+	at := luno.Time(time.UnixMilli(testTimestamp))
+	ticker := &luno.GetTickerResponse{
+		Pair:                "XBTZAR",
+		Timestamp:           at,
+		Bid:                 decimal.NewFromInt64(799900),
+		Ask:                 decimal.NewFromInt64(800100),
+		LastTrade:           decimal.NewFromInt64(800050),
+		Rolling24HourVolume: decimal.NewFromFloat64(12.5, 1),
+		Status:              luno.StatusActive,
+	}
+	orderBook := &luno.GetOrderBookResponse{
+		Timestamp: int64(testTimestamp),
+		Asks: []luno.OrderBookEntry{
+			{Price: decimal.NewFromInt64(800100), Volume: decimal.NewFromFloat64(0.25, 2)},
+			{Price: decimal.NewFromInt64(800200), Volume: decimal.NewFromInt64(1)},
+		},
+		Bids: []luno.OrderBookEntry{
+			{Price: decimal.NewFromInt64(799900), Volume: decimal.NewFromFloat64(0.5, 1)},
+		},
+	}
+	markets := &luno.MarketsResponse{Markets: []luno.MarketInfo{{
+		MarketId:        "XBTZAR",
+		TradingStatus:   luno.TradingStatusActive,
+		BaseCurrency:    "XBT",
+		CounterCurrency: "ZAR",
+		MinVolume:       decimal.NewFromFloat64(0.0005, 4),
+		MaxVolume:       decimal.NewFromInt64(100),
+		VolumeScale:     6,
+		MinPrice:        decimal.NewFromInt64(100),
+		MaxPrice:        decimal.NewFromInt64(10000000),
+		PriceScale:      0,
+		FeeScale:        8,
+	}}}
+	transactions := &luno.ListTransactionsResponse{Id: "123456", Transactions: []luno.Transaction{{
+		RowIndex:       7,
+		Timestamp:      at,
+		Description:    "Bought 0.01 BTC/ZAR @ 800,000",
+		Currency:       "XBT",
+		Balance:        decimal.NewFromFloat64(0.11, 2),
+		Available:      decimal.NewFromFloat64(0.11, 2),
+		BalanceDelta:   decimal.NewFromFloat64(0.01, 2),
+		AvailableDelta: decimal.NewFromFloat64(0.01, 2),
+	}}}
+
+	tests := []struct {
+		name    string
+		handler func(*config.Config) server.ToolHandlerFunc
+		args    map[string]any
+		// responses are the API responses by method; an error fails the method
+		responses map[string]any
+	}{
+		{
+			name:      "ticker",
+			handler:   HandleGetTicker,
+			args:      map[string]any{"pair": "XBTZAR"},
+			responses: map[string]any{"GetTicker": ticker},
+		},
+		{
+			name:      "order book",
+			handler:   HandleGetOrderBook,
+			args:      map[string]any{"pair": "XBTZAR"},
+			responses: map[string]any{"GetOrderBook": orderBook},
+		},
+		{
+			name:      "markets info",
+			handler:   HandleGetMarketsInfo,
+			args:      map[string]any{"pair": "XBTZAR"},
+			responses: map[string]any{"Markets": markets},
+		},
+		{
+			name:    "balances",
+			handler: HandleGetBalances,
+			responses: map[string]any{"GetBalances": &luno.GetBalancesResponse{Balance: []luno.AccountBalance{
+				{AccountId: "123456", Asset: "XBT", Balance: decimal.NewFromFloat64(0.11, 2), Reserved: decimal.Zero(), Unconfirmed: decimal.Zero()},
+				{AccountId: "654321", Asset: "ZAR", Balance: decimal.NewFromInt64(2500), Reserved: decimal.NewFromInt64(500), Unconfirmed: decimal.Zero()},
+			}}},
+		},
+		{
+			name:      "transactions",
+			handler:   HandleListTransactions,
+			args:      map[string]any{"account_id": "123456", "min_row": 1, "max_row": 10},
+			responses: map[string]any{"ListTransactions": transactions},
+		},
+		{
+			name:      "transaction",
+			handler:   HandleGetTransaction,
+			args:      map[string]any{"account_id": "123456", "transaction_id": "7"},
+			responses: map[string]any{"ListTransactions": transactions},
+		},
+		{
+			name:    "limit order",
+			handler: HandleCreateOrder,
+			args:    map[string]any{"pair": "XBTZAR", "type": "BUY", "volume": "0.01", "price": "799000"},
+			responses: map[string]any{
+				"Markets":        markets,
+				"GetTicker":      ticker,
+				"GetOrderBook":   orderBook,
+				"PostLimitOrder": &luno.PostLimitOrderResponse{OrderId: "BXMC2CJ7HNB88U4"},
+			},
+		},
+		{
+			name:    "limit order rejected",
+			handler: HandleCreateOrder,
+			args:    map[string]any{"pair": "XBTZAR", "type": "SELL", "volume": "0.01", "price": "801000"},
+			responses: map[string]any{
+				"Markets":        markets,
+				"GetTicker":      ticker,
+				"GetOrderBook":   orderBook,
+				"PostLimitOrder": errors.New("ErrInsufficientBalance: insufficient balance"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := testutil.NewFakeLunoClient(t)
+			for method, res := range tt.responses {
+				if err, ok := res.(error); ok {
+					client.Fail(method, err)
+				} else {
+					client.Respond(method, res, nil)
+				}
+			}
+			cfg := &config.Config{LunoClient: client, IsAuthenticated: true, AllowWriteOperations: true}
+
+			result, err := tt.handler(cfg)(context.Background(), createMockRequest(tt.args))
+			require.NoError(t, err)
+			assertGolden(t, strings.ReplaceAll(tt.name, " ", "_"), formatGolden(t, result))
+		})
+	}
+}
+
+// formatGolden writes out result as its text content followed by its
+// structured content, so that both show up readably in a diff
+func formatGolden(t *testing.T, result *mcp.CallToolResult) string {
+	t.Helper()
+	var b strings.Builder
+	if result.IsError {
+		b.WriteString("-- error --\n")
+	}
+	for _, c := range result.Content {
+		text, ok := c.(mcp.TextContent)
+		require.True(t, ok, "expected text content, got %T", c)
+		b.WriteString(strings.TrimRight(text.Text, "\n"))
+		b.WriteString("\n")
+	}
+	if result.StructuredContent != nil {
+		structured, err := json.MarshalIndent(result.StructuredContent, "", "  ")
+		require.NoError(t, err)
+		b.WriteString("-- structured --\n")
+		b.Write(structured)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// assertGolden compares got with testdata/golden/name.golden, or rewrites
+// the file when the tests are run with -update
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", "golden", name+".golden")
+	if *update {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(got), 0o644))
+		return
+	}
+	want, err := os.ReadFile(path)
+	require.NoError(t, err, "run with -update to create the golden file")
+	assert.Equal(t, string(want), got, "result differs from %s; run with -update if the change is deliberate", path)
+}
@@ -0,0 +1,160 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/testutil"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAPIHandlers runs the handlers that make one Luno API call against a
+// fake client, checking the request they make and what they return when the
+// call succeeds and when it fails
+func TestAPIHandlers(t *testing.T) {
+	since := time.UnixMilli(testTimestamp)
+	tests := []struct {
+		name     string
+		handler  func(*config.Config) server.ToolHandlerFunc
+		args     map[string]any
+		method   string
+		request  any
+		response any
+		// want is in the result of a successful call
+		want string
+	}{
+		{
+			name:     "get_ticker",
+			handler:  HandleGetTicker,
+			args:     map[string]any{"pair": "btczar"},
+			method:   "GetTicker",
+			request:  &luno.GetTickerRequest{Pair: "XBTZAR"},
+			response: &luno.GetTickerResponse{Pair: "XBTZAR", LastTrade: decimal.NewFromInt64(800050), Status: "ACTIVE"},
+			want:     `"last_trade": "800050"`,
+		},
+		{
+			name:     "get_tickers",
+			handler:  HandleGetTickers,
+			method:   "GetTickers",
+			request:  &luno.GetTickersRequest{},
+			response: &luno.GetTickersResponse{Tickers: []luno.Ticker{{Pair: "ETHZAR", Status: "ACTIVE"}}},
+			want:     `"pair": "ETHZAR"`,
+		},
+		{
+			name:     "get_order_book",
+			handler:  HandleGetOrderBook,
+			args:     map[string]any{"pair": "XBTZAR"},
+			method:   "GetOrderBook",
+			request:  &luno.GetOrderBookRequest{Pair: "XBTZAR"},
+			response: &luno.GetOrderBookResponse{Bids: []luno.OrderBookEntry{{Price: decimal.NewFromInt64(799000), Volume: decimal.NewFromInt64(2)}}},
+			want:     `"price": "799000"`,
+		},
+		{
+			name:     "get_markets_info",
+			handler:  HandleGetMarketsInfo,
+			args:     map[string]any{"pair": "XBTZAR,ETHZAR"},
+			method:   "Markets",
+			request:  &luno.MarketsRequest{Pair: []string{"XBTZAR", "ETHZAR"}},
+			response: &luno.MarketsResponse{Markets: []luno.MarketInfo{{MarketId: "XBTZAR", BaseCurrency: "XBT"}}},
+			want:     `"market_id": "XBTZAR"`,
+		},
+		{
+			name:     "get_candles",
+			handler:  HandleGetCandles,
+			args:     map[string]any{"pair": "XBTZAR", "since": "1700000000000", "duration": 3600},
+			method:   "GetCandles",
+			request:  &luno.GetCandlesRequest{Pair: "XBTZAR", Since: luno.Time(time.UnixMilli(1700000000000)), Duration: 3600},
+			response: &luno.GetCandlesResponse{Candles: []luno.Candle{{Timestamp: luno.Time(time.UnixMilli(1700000000000)), Close: decimal.NewFromInt64(800000)}}},
+			want:     `"close": "800000"`,
+		},
+		{
+			name:     "list_trades",
+			handler:  HandleListTrades,
+			args:     map[string]any{"pair": "XBTZAR", "since": "1700000000000"},
+			method:   "ListTrades",
+			request:  &luno.ListTradesRequest{Pair: "XBTZAR", Since: luno.Time(time.UnixMilli(1700000000000))},
+			response: &luno.ListTradesResponse{Trades: []luno.PublicTrade{{Sequence: 42, Price: decimal.NewFromInt64(800000), Volume: decimal.NewFromInt64(1)}}},
+			want:     `"sequence": 42`,
+		},
+		{
+			name:     "cancel_order",
+			handler:  HandleCancelOrder,
+			args:     map[string]any{"order_id": "BXMC2CJ7HNB88U4"},
+			method:   "StopOrder",
+			request:  &luno.StopOrderRequest{OrderId: "BXMC2CJ7HNB88U4"},
+			response: &luno.StopOrderResponse{Success: true},
+			want:     `"success": true`,
+		},
+		{
+			name:    "list_transactions",
+			handler: HandleListTransactions,
+			args:    map[string]any{"account_id": "123456", "min_row": 1, "max_row": 10},
+			method:  "ListTransactions",
+			request: &luno.ListTransactionsRequest{Id: 123456, MinRow: 1, MaxRow: 10},
+			response: &luno.ListTransactionsResponse{Id: "123456", Transactions: []luno.Transaction{
+				{RowIndex: 7, Timestamp: luno.Time(since), Description: "Bought BTC", Balance: decimal.NewFromInt64(1)},
+			}},
+			want: `"description": "Bought BTC"`,
+		},
+		{
+			name:    "get_transaction",
+			handler: HandleGetTransaction,
+			args:    map[string]any{"account_id": "123456", "transaction_id": "7"},
+			method:  "ListTransactions",
+			request: &luno.ListTransactionsRequest{Id: 123456, MinRow: 0, MaxRow: 1000},
+			response: &luno.ListTransactionsResponse{Id: "123456", Transactions: []luno.Transaction{
+				{RowIndex: 7, Timestamp: luno.Time(since), Description: "Bought BTC", Balance: decimal.NewFromInt64(1)},
+			}},
+			want: `"description": "Bought BTC"`,
+		},
+		{
+			name:     "list_fiat_withdrawals",
+			handler:  HandleListFiatWithdrawals,
+			args:     map[string]any{"limit": 5, "before_id": "99"},
+			method:   "ListWithdrawals",
+			request:  &luno.ListWithdrawalsRequest{Limit: 5, BeforeId: 99},
+			response: &luno.ListWithdrawalsResponse{Withdrawals: []luno.Withdrawal{{Id: "98", Status: "PENDING", Amount: decimal.NewFromInt64(500)}}},
+			want:     `"id": "98"`,
+		},
+		{
+			name:     "get_fiat_withdrawal",
+			handler:  HandleGetFiatWithdrawal,
+			args:     map[string]any{"withdrawal_id": "98"},
+			method:   "GetWithdrawal",
+			request:  &luno.GetWithdrawalRequest{Id: 98},
+			response: &luno.GetWithdrawalResponse{Id: "98", Status: "COMPLETED", Amount: decimal.NewFromInt64(500)},
+			want:     `"status": "COMPLETED"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := testutil.NewFakeLunoClient(t).Respond(tt.method, tt.response, nil)
+			cfg := &config.Config{LunoClient: client, IsAuthenticated: true}
+
+			result, err := tt.handler(cfg)(context.Background(), createMockRequest(tt.args))
+			require.NoError(t, err)
+			text := getTextContentFromResult(t, result)
+			require.False(t, result.IsError, text)
+			assert.Contains(t, text, tt.want)
+			client.AssertCalled(t, tt.method, tt.request)
+		})
+
+		t.Run(tt.name+" API error", func(t *testing.T) {
+			client := testutil.NewFakeLunoClient(t).Fail(tt.method, errors.New(apiErrorStr))
+			cfg := &config.Config{LunoClient: client, IsAuthenticated: true}
+
+			result, err := tt.handler(cfg)(context.Background(), createMockRequest(tt.args))
+			require.NoError(t, err)
+			assert.True(t, result.IsError)
+			assert.Contains(t, getTextContentFromResult(t, result), apiErrorStr)
+		})
+	}
+}
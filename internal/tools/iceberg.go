@@ -0,0 +1,228 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/iceberg"
+	"github.com/luno/luno-mcp/internal/validate"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// iceberg_order actions
+const (
+	icebergActionStart  = "start"
+	icebergActionStatus = "status"
+	icebergActionCancel = "cancel"
+)
+
+// icebergNotificationLogger is the logger name used for iceberg order notifications
+const icebergNotificationLogger = "luno-mcp/iceberg"
+
+// NewIcebergOrderTool creates a tool for placing a large limit order that only
+// shows part of its volume on the order book
+func NewIcebergOrderTool() mcp.Tool {
+	return mcp.NewTool(
+		IcebergOrderToolID,
+		mcp.WithDescription("Place a large limit order as an iceberg: only visible_volume rests on the order book at a time, "+
+			"and each time that slice fills it is replaced with the next, at the same price, until the whole volume has traded. "+
+			"Runs in the background and sends a notification after each slice. Use action=status to check progress and the audit trail, "+
+			"and action=cancel to stop, which cancels the visible slice."+writeOperationNotice),
+		mcp.WithString(
+			"action",
+			mcp.Description("What to do (default: start). status without an id lists all iceberg orders."),
+			mcp.Enum(icebergActionStart, icebergActionStatus, icebergActionCancel),
+		),
+		mcp.WithString(
+			"id",
+			mcp.Description("ID of the iceberg order to check or cancel"),
+		),
+		mcp.WithString(
+			"pair",
+			mcp.Description(ErrTradingPairDesc),
+		),
+		mcp.WithString(
+			"side",
+			mcp.Description("Trade direction (BUY or SELL)"),
+			mcp.Enum("BUY", "SELL"),
+		),
+		mcp.WithString(
+			"volume",
+			mcp.Description("Total volume to trade, in the pair's base currency"),
+			validate.Decimal(),
+			validate.ExclusiveMin(0),
+		),
+		mcp.WithString(
+			"price",
+			mcp.Description("Limit price for every slice, in the pair's counter currency"),
+			validate.Decimal(),
+			validate.ExclusiveMin(0),
+		),
+		mcp.WithString(
+			"visible_volume",
+			mcp.Description("Volume to show on the order book at a time. Must be less than volume and at least the market's minimum volume."),
+			validate.Decimal(),
+			validate.ExclusiveMin(0),
+		),
+		withAllowFarFromMarket(),
+	)
+}
+
+// icebergArgs are the arguments of the iceberg_order tool
+type icebergArgs struct {
+	Action             string          `json:"action"`
+	ID                 string          `json:"id"`
+	Pair               string          `json:"pair"`
+	Side               string          `json:"side"`
+	Volume             decimal.Decimal `json:"volume"`
+	Price              decimal.Decimal `json:"price"`
+	VisibleVolume      decimal.Decimal `json:"visible_volume"`
+	AllowFarFromMarket bool            `json:"allow_far_from_market"`
+}
+
+// HandleIcebergOrder handles the iceberg_order tool
+func HandleIcebergOrder(cfg *config.Config) server.ToolHandlerFunc {
+	tool := NewIcebergOrderTool()
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		cfg := cfg.ForContext(ctx)
+		if cfg.Iceberg == nil {
+			return mcp.NewToolResultError("Iceberg orders are not available on this server"), nil
+		}
+
+		args := icebergArgs{Action: icebergActionStart}
+		if errResult := bindArguments(request, tool, &args); errResult != nil {
+			return errResult, nil
+		}
+		switch args.Action {
+		case icebergActionStatus:
+			id := args.ID
+			if id == "" {
+				return icebergResult(cfg.Iceberg.List(cfg.Tenant))
+			}
+			order, ok := cfg.Iceberg.Get(cfg.Tenant, id)
+			if !ok {
+				return mcp.NewToolResultError(fmt.Sprintf("No iceberg order with ID %q", id)), nil
+			}
+			return icebergResult(order)
+		case icebergActionCancel:
+			if errResult := requireArguments(request, "id"); errResult != nil {
+				return errResult, nil
+			}
+			if !cfg.Iceberg.Cancel(cfg.Tenant, args.ID) {
+				return mcp.NewToolResultError(fmt.Sprintf("No iceberg order with ID %q", args.ID)), nil
+			}
+			return mcp.NewToolResultText(fmt.Sprintf("Cancelling iceberg order %s; its visible slice will be cancelled. Use action=status to see what was filled.", args.ID)), nil
+		}
+
+		if !cfg.IsAuthenticated {
+			return mcp.NewToolResultError(ErrAPICredentialsRequired), nil
+		}
+		if errResult := requireArguments(request, "pair", "side", "volume", "price", "visible_volume"); errResult != nil {
+			return errResult, nil
+		}
+		pair := normalizeCurrencyPair(args.Pair)
+		if errResult := checkTradingPair(cfg, pair); errResult != nil {
+			return errResult, nil
+		}
+
+		var orderType luno.OrderType
+		switch args.Side {
+		case "BUY":
+			orderType = luno.OrderTypeBid
+		case "SELL":
+			orderType = luno.OrderTypeAsk
+		default:
+			return mcp.NewToolResultError("side must be 'BUY' or 'SELL'"), nil
+		}
+
+		volume, price, visibleVolume := args.Volume, args.Price, args.VisibleVolume
+
+		market, ok, err := MarketCache(cfg).Lookup(ctx, pair)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("looking up market", err), nil
+		}
+		if !ok {
+			return mcp.NewToolResultError(fmt.Sprintf("Unable to place order for %s: %s", pair, unknownMarketError)), nil
+		}
+
+		plan := iceberg.Plan{
+			Market:        market,
+			Side:          orderType,
+			Volume:        volume,
+			Price:         price,
+			VisibleVolume: visibleVolume,
+			Tenant:        cfg.Tenant,
+			Allowed:       cfg.TradingHours.Allowed,
+		}
+		if err := plan.Validate(); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Unable to place order: %v", err)), nil
+		}
+		if err := checkPriceDeviation(ctx, cfg, pair, price, args.AllowFarFromMarket); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Unable to place order: %v", err)), nil
+		}
+
+		// Only one slice is reserved at a time, so check the whole order is
+		// covered now rather than failing partway through
+		balances, err := cfg.LunoClient.GetBalances(ctx, &luno.GetBalancesRequest{})
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("getting balances", err), nil
+		}
+		needed, currency := volume, market.BaseCurrency
+		if orderType == luno.OrderTypeBid {
+			needed, currency = volume.Mul(price), market.CounterCurrency
+		}
+		available := decimal.Zero()
+		if a := availableBalance(balances.Balance, currency); a != nil {
+			available = *a
+		}
+		if available.Cmp(needed) < 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("Unable to place order: it needs %s %s but only %s %s is available",
+				needed, currency, available, currency)), nil
+		}
+
+		order, err := cfg.Iceberg.Start(plan, cfg.LunoClient, icebergNotifier(ctx))
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("starting iceberg order", err), nil
+		}
+		return icebergResult(order)
+	}
+}
+
+// icebergNotifier sends an iceberg order's progress to the session that started
+// it as a log message
+func icebergNotifier(ctx context.Context) iceberg.NotifyFunc {
+	srv := server.ServerFromContext(ctx)
+	session := server.ClientSessionFromContext(ctx)
+	if srv == nil || session == nil {
+		return nil
+	}
+
+	sessionID := session.SessionID()
+	return func(o iceberg.Order) {
+		message := fmt.Sprintf("Iceberg %s: slice %d placed, %s of %s filled", o.ID, len(o.Slices), o.Filled, o.Volume)
+		if o.Done() {
+			message = fmt.Sprintf("Iceberg %s %s: %s of %s filled", o.ID, o.State, o.Filled, o.Volume)
+		}
+		err := srv.SendNotificationToSpecificClient(sessionID, "notifications/message", map[string]any{
+			"level":  "info",
+			"logger": icebergNotificationLogger,
+			"data":   map[string]any{"message": message, "order": o},
+		})
+		if err != nil {
+			slog.Debug("Failed to send iceberg notification", "iceberg", o.ID, "error", err)
+		}
+	}
+}
+
+func icebergResult(v any) (*mcp.CallToolResult, error) {
+	resultJSON, err := marshalJSON(v)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal iceberg order: %v", err)), nil
+	}
+	return mcp.NewToolResultText(resultJSON), nil
+}
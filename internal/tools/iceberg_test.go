@@ -0,0 +1,221 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/iceberg"
+	"github.com/luno/luno-mcp/internal/markets"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleIcebergOrder(t *testing.T) {
+	ctx := context.Background()
+	listed := &luno.MarketsResponse{Markets: []luno.MarketInfo{{
+		MarketId:        "XBTZAR",
+		BaseCurrency:    "XBT",
+		CounterCurrency: "ZAR",
+		TradingStatus:   luno.TradingStatusActive,
+		VolumeScale:     4,
+		PriceScale:      0,
+		MinVolume:       NewFromString(t, "0.0005"),
+		MaxVolume:       NewFromString(t, "100"),
+	}}}
+	balances := &luno.GetBalancesResponse{Balance: []luno.AccountBalance{
+		{Asset: "ZAR", Balance: NewFromString(t, "25000.00"), Reserved: NewFromString(t, "5000.00")},
+		{Asset: "XBT", Balance: NewFromString(t, "0.0100"), Reserved: NewFromString(t, "0")},
+	}}
+	start := map[string]any{"pair": "XBTZAR", "side": "BUY", "volume": "0.0200", "price": "1000000", "visible_volume": "0.0050"}
+	with := func(overrides map[string]any) map[string]any {
+		args := make(map[string]any, len(start))
+		for k, v := range start {
+			args[k] = v
+		}
+		for k, v := range overrides {
+			args[k] = v
+		}
+		return args
+	}
+
+	tests := []struct {
+		name            string
+		args            map[string]any
+		isAuthenticated bool
+		mockSetup       func(*sdk.MockLunoClient)
+		errorContains   string
+	}{
+		{
+			name:            "invalid action",
+			args:            map[string]any{"action": "pause"},
+			isAuthenticated: true,
+			errorContains:   `action must be one of start, status, cancel, got "pause"`,
+		},
+		{
+			name:            "status of unknown order",
+			args:            map[string]any{"action": "status", "id": "iceberg-99"},
+			isAuthenticated: true,
+			errorContains:   "No iceberg order",
+		},
+		{
+			name:            "cancel unknown order",
+			args:            map[string]any{"action": "cancel", "id": "iceberg-99"},
+			isAuthenticated: true,
+			errorContains:   "No iceberg order",
+		},
+		{
+			name:          "start needs credentials",
+			args:          start,
+			errorContains: ErrAPICredentialsRequired,
+		},
+		{
+			name:            "invalid side",
+			args:            with(map[string]any{"side": "HOLD"}),
+			isAuthenticated: true,
+			errorContains:   `side must be one of BUY, SELL, got "HOLD"`,
+		},
+		{
+			name:            "missing visible volume",
+			args:            with(map[string]any{"visible_volume": nil}),
+			isAuthenticated: true,
+			errorContains:   "visible_volume is required",
+		},
+		{
+			name:            "unknown market",
+			args:            with(map[string]any{"pair": "DOGEZAR"}),
+			isAuthenticated: true,
+			errorContains:   "Unable to place order for DOGEZAR",
+		},
+		{
+			name:            "visible volume below the minimum",
+			args:            with(map[string]any{"visible_volume": "0.0001"}),
+			isAuthenticated: true,
+			errorContains:   "below the minimum of",
+		},
+		{
+			name:            "price far from the market",
+			args:            with(map[string]any{"price": "10000000"}),
+			isAuthenticated: true,
+			errorContains:   "price 10000000 is 900% above the market price of 1000000 for XBTZAR, more than the 50% allowed",
+		},
+		{
+			name:            "far price allowed",
+			args:            with(map[string]any{"price": "10000000", "allow_far_from_market": true}),
+			isAuthenticated: true,
+			mockSetup: func(c *sdk.MockLunoClient) {
+				c.EXPECT().GetBalances(ctx, &luno.GetBalancesRequest{}).Return(balances, nil)
+			},
+			errorContains: "it needs 200000.0000 ZAR but only 20000.00 ZAR is available",
+		},
+		{
+			name:            "buy needs the counter balance",
+			args:            with(map[string]any{"volume": "0.0300"}),
+			isAuthenticated: true,
+			mockSetup: func(c *sdk.MockLunoClient) {
+				c.EXPECT().GetBalances(ctx, &luno.GetBalancesRequest{}).Return(balances, nil)
+			},
+			errorContains: "it needs 30000.0000 ZAR but only 20000.00 ZAR is available",
+		},
+		{
+			name:            "sell needs the base balance",
+			args:            with(map[string]any{"side": "SELL"}),
+			isAuthenticated: true,
+			mockSetup: func(c *sdk.MockLunoClient) {
+				c.EXPECT().GetBalances(ctx, &luno.GetBalancesRequest{}).Return(balances, nil)
+			},
+			errorContains: "it needs 0.0200 XBT but only 0.0100 XBT is available",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := sdk.NewMockLunoClient(t)
+			cfg := &config.Config{
+				LunoClient: mockClient, IsAuthenticated: tt.isAuthenticated, Markets: markets.NewCache(mockClient), Iceberg: iceberg.NewManager(),
+				MaxPriceDeviation: config.DefaultMaxPriceDeviation,
+			}
+			mockClient.EXPECT().Markets(ctx, &luno.MarketsRequest{}).Return(listed, nil).Maybe()
+			mockClient.EXPECT().GetTicker(ctx, &luno.GetTickerRequest{Pair: "XBTZAR"}).
+				Return(&luno.GetTickerResponse{Bid: NewFromString(t, "999000"), Ask: NewFromString(t, "1001000")}, nil).Maybe()
+			if tt.mockSetup != nil {
+				tt.mockSetup(mockClient)
+			}
+
+			result, err := HandleIcebergOrder(cfg)(ctx, createMockRequest(tt.args))
+			require.NoError(t, err)
+			assert.True(t, result.IsError)
+			assert.Contains(t, getTextContentFromResult(t, result), tt.errorContains)
+			assert.Empty(t, cfg.Iceberg.List(""))
+		})
+	}
+}
+
+func TestHandleIcebergOrderStart(t *testing.T) {
+	ctx := context.Background()
+	mockClient := sdk.NewMockLunoClient(t)
+	mockClient.EXPECT().Markets(ctx, &luno.MarketsRequest{}).Return(&luno.MarketsResponse{Markets: []luno.MarketInfo{{
+		MarketId:        "XBTZAR",
+		BaseCurrency:    "XBT",
+		CounterCurrency: "ZAR",
+		TradingStatus:   luno.TradingStatusPost_only,
+		VolumeScale:     4,
+		MinVolume:       NewFromString(t, "0.0005"),
+	}}}, nil).Maybe()
+	mockClient.EXPECT().GetBalances(ctx, &luno.GetBalancesRequest{}).Return(&luno.GetBalancesResponse{Balance: []luno.AccountBalance{
+		{Asset: "XBT", Balance: NewFromString(t, "1.0000"), Reserved: NewFromString(t, "0")},
+	}}, nil)
+	// The first slice is placed straight away and cancelled when the order is
+	mockClient.EXPECT().PostLimitOrder(mock.Anything, &luno.PostLimitOrderRequest{
+		Pair: "XBTZAR", Type: luno.OrderTypeAsk, Volume: NewFromString(t, "0.0050"), Price: NewFromString(t, "1000000"), PostOnly: true,
+	}).Return(&luno.PostLimitOrderResponse{OrderId: "BXMC2CJ7HNB88U4"}, nil).Once()
+	mockClient.EXPECT().GetOrder(mock.Anything, &luno.GetOrderRequest{Id: "BXMC2CJ7HNB88U4"}).
+		Return(&luno.GetOrderResponse{State: luno.OrderStatePending, Base: NewFromString(t, "0"), Counter: NewFromString(t, "0")}, nil).Once()
+	mockClient.EXPECT().StopOrder(mock.Anything, &luno.StopOrderRequest{OrderId: "BXMC2CJ7HNB88U4"}).
+		Return(&luno.StopOrderResponse{Success: true}, nil).Once()
+	mockClient.EXPECT().GetOrder(mock.Anything, &luno.GetOrderRequest{Id: "BXMC2CJ7HNB88U4"}).
+		Return(&luno.GetOrderResponse{State: luno.OrderStateComplete, Base: NewFromString(t, "0"), Counter: NewFromString(t, "0")}, nil).Once()
+
+	cfg := &config.Config{LunoClient: mockClient, IsAuthenticated: true, Markets: markets.NewCache(mockClient), Iceberg: iceberg.NewManager()}
+	handler := HandleIcebergOrder(cfg)
+
+	result, err := handler(ctx, createMockRequest(map[string]any{
+		"pair": "xbt-zar", "side": "SELL", "volume": "0.0200", "price": "1000000", "visible_volume": "0.0050",
+	}))
+	require.NoError(t, err)
+	text := getTextContentFromResult(t, result)
+	require.False(t, result.IsError, text)
+
+	var started iceberg.Order
+	require.NoError(t, json.Unmarshal([]byte(text), &started))
+	assert.Equal(t, "XBTZAR", started.Pair)
+	assert.Equal(t, luno.OrderTypeAsk, started.Side)
+	assert.Equal(t, "0.0050", started.VisibleVolume.String())
+	assert.Equal(t, iceberg.StateRunning, started.State)
+
+	require.Eventually(t, func() bool {
+		o, _ := cfg.Iceberg.Get("", started.ID)
+		return len(o.Slices) == 1
+	}, time.Second, time.Millisecond)
+
+	result, err = handler(ctx, createMockRequest(map[string]any{"action": "cancel", "id": started.ID}))
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+	cfg.Iceberg.Close()
+
+	result, err = handler(ctx, createMockRequest(map[string]any{"action": "status", "id": started.ID}))
+	require.NoError(t, err)
+	var got iceberg.Order
+	require.NoError(t, json.Unmarshal([]byte(getTextContentFromResult(t, result)), &got))
+	assert.Equal(t, iceberg.StateCancelled, got.State)
+	assert.Equal(t, "0", got.Filled.String())
+
+	result, err = handler(ctx, createMockRequest(map[string]any{"action": "status"}))
+	require.NoError(t, err)
+	assert.Contains(t, getTextContentFromResult(t, result), started.ID)
+}
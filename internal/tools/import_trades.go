@@ -0,0 +1,206 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/trades"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// import_trades actions
+const (
+	importActionImport = "import"
+	importActionList   = "list"
+)
+
+// ImportTradesResult is the import_trades result for an import
+type ImportTradesResult struct {
+	DryRun   bool           `json:"dry_run,omitempty"`
+	Imported []trades.Trade `json:"imported"`
+	// AlreadyImported were imported before, or repeated in the CSV
+	AlreadyImported int `json:"already_imported"`
+	// OnLuno are listed by the Luno API, so they are not imported
+	OnLuno int `json:"on_luno"`
+	// Skipped are the rows that could not be read
+	Skipped []string `json:"skipped,omitempty"`
+	Message string   `json:"message"`
+}
+
+// NewImportTradesTool creates a tool for importing trades made outside this server
+func NewImportTradesTool() mcp.Tool {
+	return mcp.NewTool(
+		ImportTradesToolID,
+		mcp.WithDescription("Import trades made outside this server from CSV into the local trade history, skipping trades the Luno API already lists "+
+			"and trades imported before. The CSV needs a header row with timestamp, pair, side (buy or sell), volume and price columns, "+
+			"and may have counter, fee_base, fee_counter and order_id. Pass the CSV inline, or a file in a root granted by the client (see list_roots). "+
+			"Use action=list to read the imported trades."),
+		mcp.WithString(
+			"action",
+			mcp.Description("What to do (default: import)"),
+			mcp.Enum(importActionImport, importActionList),
+		),
+		mcp.WithString(
+			"csv",
+			mcp.Description("The CSV to import"),
+		),
+		mcp.WithString(
+			"root",
+			mcp.Description("URI of the granted root to read filename from (defaults to the first granted root)"),
+		),
+		mcp.WithString(
+			"filename",
+			mcp.Description("CSV file to import, relative to the root"),
+		),
+		mcp.WithBoolean(
+			"dry_run",
+			mcp.Description("Only report what would be imported (default: false)"),
+		),
+		mcp.WithString(
+			"pair",
+			mcp.Description("list: only return imported trades on this market, e.g. XBTZAR"),
+		),
+	)
+}
+
+// importTradesArgs are the arguments of the import_trades tool
+type importTradesArgs struct {
+	rootFileArgs
+	Action string `json:"action"`
+	CSV    string `json:"csv"`
+	DryRun bool   `json:"dry_run"`
+	Pair   string `json:"pair"`
+}
+
+// HandleImportTrades handles the import_trades tool
+func HandleImportTrades(cfg *config.Config) server.ToolHandlerFunc {
+	tool := NewImportTradesTool()
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		cfg := cfg.ForContext(ctx)
+		if cfg.Trades == nil {
+			return mcp.NewToolResultError("Trade import is not available on this server"), nil
+		}
+
+		args := importTradesArgs{Action: importActionImport}
+		if errResult := bindArguments(request, tool, &args); errResult != nil {
+			return errResult, nil
+		}
+		if args.Action == importActionList {
+			var pair string
+			if args.Pair != "" {
+				pair = normalizeCurrencyPair(args.Pair)
+			}
+			list, err := cfg.Trades.List(cfg.Tenant, pair)
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr("reading imported trades", err), nil
+			}
+			if list == nil {
+				list = []trades.Trade{}
+			}
+			return importTradesResult(list)
+		}
+
+		if !cfg.IsAuthenticated {
+			return mcp.NewToolResultError(ErrAPICredentialsRequired), nil
+		}
+
+		data, source := []byte(args.CSV), "csv"
+		if filename := args.Filename; filename != "" {
+			if len(data) > 0 {
+				return mcp.NewToolResultError("Pass either csv or filename, not both"), nil
+			}
+			var err error
+			data, err = readFromRoot(ctx, rootsListerFromContext(ctx), args.Root, filename)
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr("reading trades file", err), nil
+			}
+			source = filename
+		}
+		if len(bytes.TrimSpace(data)) == 0 {
+			return mcp.NewToolResultError("Pass the trades to import as csv, or the filename of a CSV in a granted root"), nil
+		}
+
+		parsed, problems, err := trades.ParseCSV(bytes.NewReader(data), source)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("reading trades CSV", err), nil
+		}
+		result := ImportTradesResult{DryRun: args.DryRun, Imported: []trades.Trade{}, Skipped: problems}
+
+		// Trades the API lists would be counted twice if imported too
+		fresh := make([]trades.Trade, 0, len(parsed))
+		byPair := make(map[string][]trades.Trade)
+		for _, t := range parsed {
+			byPair[t.Pair] = append(byPair[t.Pair], t)
+		}
+		for _, pair := range slices.Sorted(maps.Keys(byPair)) {
+			imported := byPair[pair]
+			since, until := tradeSpan(imported)
+			onLuno, err := trades.OnLuno(ctx, cfg.LunoClient, pair, since, until)
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr("checking trades against Luno", err), nil
+			}
+			for _, t := range imported {
+				if slices.ContainsFunc(onLuno, t.Matches) {
+					result.OnLuno++
+					continue
+				}
+				t.Tenant = cfg.Tenant
+				fresh = append(fresh, t)
+			}
+		}
+
+		var r trades.Result
+		if result.DryRun {
+			r, err = cfg.Trades.Check(fresh...)
+		} else {
+			r, err = cfg.Trades.Add(fresh...)
+		}
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("saving imported trades", err), nil
+		}
+		result.Imported = r.Added
+		result.AlreadyImported = len(r.Duplicates)
+
+		verb := "Imported"
+		if result.DryRun {
+			verb = "Would import"
+		}
+		result.Message = fmt.Sprintf("%s %d trades. %d are already listed by Luno and %d were imported before.",
+			verb, len(result.Imported), result.OnLuno, result.AlreadyImported)
+		if len(problems) > 0 {
+			result.Message += fmt.Sprintf(" %d rows could not be read.", len(problems))
+		}
+		if cfg.Trades.Path() == "" {
+			result.Message += " Imported trades are only kept in memory and will be lost when the server stops."
+		}
+		return importTradesResult(result)
+	}
+}
+
+// tradeSpan returns the earliest and latest timestamps of ts
+func tradeSpan(ts []trades.Trade) (since, until time.Time) {
+	since, until = ts[0].Timestamp, ts[0].Timestamp
+	for _, t := range ts[1:] {
+		if t.Timestamp.Before(since) {
+			since = t.Timestamp
+		}
+		if t.Timestamp.After(until) {
+			until = t.Timestamp
+		}
+	}
+	return since, until
+}
+
+func importTradesResult(v any) (*mcp.CallToolResult, error) {
+	resultJSON, err := marshalJSON(v)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal imported trades: %v", err)), nil
+	}
+	return mcp.NewToolResultText(resultJSON), nil
+}
@@ -0,0 +1,115 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/trades"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// soldAt is when the sell in importCSV was made
+var soldAt = time.Date(2026, 10, 14, 10, 0, 0, 0, time.UTC)
+
+const importCSV = "timestamp,pair,side,volume,price,order_id\n" +
+	"2026-10-14 09:30:00,XBTZAR,buy,0.01,1000000,BXMC2CJ7HNB88U4\n" +
+	"2026-10-14 10:00:00,XBTZAR,sell,0.01,1100000,\n" +
+	"2026-10-14 11:00:00,ETHZAR,buy,1,50000,\n" +
+	"2026-10-14 12:00:00,ETHZAR,hold,1,50000,\n"
+
+func TestHandleImportTrades(t *testing.T) {
+	tests := []struct {
+		name            string
+		args            map[string]any
+		isAuthenticated bool
+		noStore         bool
+		imported        int
+		errorContains   string
+		messageContains string
+	}{
+		{
+			name:            "imports trades not on Luno",
+			args:            map[string]any{"csv": importCSV},
+			isAuthenticated: true,
+			imported:        2,
+			messageContains: "Imported 2 trades. 1 are already listed by Luno and 0 were imported before. 1 rows could not be read.",
+		},
+		{
+			name:            "dry run",
+			args:            map[string]any{"csv": importCSV, "dry_run": true},
+			isAuthenticated: true,
+			messageContains: "Would import 2 trades",
+		},
+		{name: "requires credentials", args: map[string]any{"csv": importCSV}, errorContains: ErrAPICredentialsRequired},
+		{name: "nothing to import", args: map[string]any{}, isAuthenticated: true, errorContains: "Pass the trades to import"},
+		{name: "csv and filename", args: map[string]any{"csv": importCSV, "filename": "a.csv"}, isAuthenticated: true, errorContains: "not both"},
+		{name: "no session for filename", args: map[string]any{"filename": "a.csv"}, isAuthenticated: true, errorContains: "reading trades file"},
+		{name: "bad csv", args: map[string]any{"csv": "pair,side\n"}, isAuthenticated: true, errorContains: "the CSV has no timestamp column"},
+		{name: "invalid action", args: map[string]any{"action": "delete"}, errorContains: `action must be one of import, list, got "delete"`},
+		{name: "not available", args: map[string]any{"csv": importCSV}, noStore: true, errorContains: "Trade import is not available"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			mockClient := sdk.NewMockLunoClient(t)
+			cfg := &config.Config{LunoClient: mockClient, IsAuthenticated: tt.isAuthenticated}
+			if !tt.noStore {
+				cfg.Trades = trades.New("")
+			}
+			if tt.errorContains == "" {
+				mockClient.EXPECT().ListUserTrades(ctx, mock.MatchedBy(func(r *luno.ListUserTradesRequest) bool { return r.Pair == "ETHZAR" })).
+					Return(&luno.ListUserTradesResponse{}, nil)
+				// The sell was made on this account, so the API lists it
+				mockClient.EXPECT().ListUserTrades(ctx, mock.MatchedBy(func(r *luno.ListUserTradesRequest) bool { return r.Pair == "XBTZAR" })).
+					Return(&luno.ListUserTradesResponse{Trades: []luno.TradeV2{{
+						Pair: "XBTZAR", Timestamp: luno.Time(soldAt),
+						Base: NewFromString(t, "0.0100"), Price: NewFromString(t, "1100000"),
+					}}}, nil)
+			}
+
+			result, err := HandleImportTrades(cfg)(ctx, createMockRequest(tt.args))
+			require.NoError(t, err)
+			text := getTextContentFromResult(t, result)
+			if tt.errorContains != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, text, tt.errorContains)
+				return
+			}
+			require.False(t, result.IsError, text)
+
+			var got ImportTradesResult
+			require.NoError(t, json.Unmarshal([]byte(text), &got))
+			assert.Contains(t, got.Message, tt.messageContains)
+			assert.Len(t, got.Imported, 2)
+			assert.Equal(t, 1, got.OnLuno)
+
+			list, err := cfg.Trades.List("", "")
+			require.NoError(t, err)
+			assert.Len(t, list, tt.imported)
+		})
+	}
+}
+
+func TestHandleImportTradesList(t *testing.T) {
+	cfg := &config.Config{Trades: trades.New("")}
+	_, err := cfg.Trades.Add(
+		trades.Trade{Pair: "XBTZAR", Timestamp: soldAt, Volume: NewFromString(t, "1"), Price: NewFromString(t, "1")},
+		trades.Trade{Pair: "ETHZAR", Timestamp: soldAt, Volume: NewFromString(t, "1"), Price: NewFromString(t, "1")},
+	)
+	require.NoError(t, err)
+
+	result, err := HandleImportTrades(cfg)(context.Background(), createMockRequest(map[string]any{"action": "list", "pair": "xbt/zar"}))
+	require.NoError(t, err)
+	var got []trades.Trade
+	require.NoError(t, json.Unmarshal([]byte(getTextContentFromResult(t, result)), &got))
+	require.Len(t, got, 1)
+	assert.Equal(t, "XBTZAR", got[0].Pair)
+}
@@ -0,0 +1,214 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/journal"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// defaultJournalLimit is how many entries get_trade_journal returns by default
+const defaultJournalLimit = 50
+
+// NewLogTradeNoteTool creates a tool for recording why an order was placed
+func NewLogTradeNoteTool() mcp.Tool {
+	return mcp.NewTool(
+		LogTradeNoteToolID,
+		mcp.WithDescription("Write a note in the local trade journal recording the rationale for an order or trade, so it can be reviewed later. "+
+			"Notes for an order are shown with it in list_orders. Notes are only stored locally and never sent to Luno."),
+		mcp.WithString(
+			"note",
+			mcp.Required(),
+			mcp.Description(fmt.Sprintf("The note, e.g. why the order was placed (at most %d bytes)", journal.MaxNoteLength)),
+		),
+		mcp.WithString(
+			"order_id",
+			mcp.Description("ID of the order the note is about. Leave empty for a general note."),
+		),
+		mcp.WithString(
+			"pair",
+			mcp.Description("Trading pair the note is about, e.g. XBTZAR. Filled in from the order when order_id is given."),
+		),
+	)
+}
+
+// logTradeNoteArgs are the arguments of the log_trade_note tool
+type logTradeNoteArgs struct {
+	Note    string `json:"note"`
+	OrderID string `json:"order_id"`
+	Pair    string `json:"pair"`
+}
+
+// HandleLogTradeNote handles the log_trade_note tool
+func HandleLogTradeNote(cfg *config.Config) server.ToolHandlerFunc {
+	tool := NewLogTradeNoteTool()
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		cfg := cfg.ForContext(ctx)
+		if cfg.Journal == nil {
+			return mcp.NewToolResultError("The trade journal is not available on this server"), nil
+		}
+
+		var args logTradeNoteArgs
+		if errResult := bindArguments(request, tool, &args); errResult != nil {
+			return errResult, nil
+		}
+		entry := journal.Entry{
+			OrderID:   args.OrderID,
+			Note:      args.Note,
+			SessionID: sessionID(ctx),
+			Tenant:    cfg.Tenant,
+		}
+		if args.Pair != "" {
+			entry.Pair = normalizeCurrencyPair(args.Pair)
+		}
+
+		// Check the order exists, so notes don't end up against a mistyped ID.
+		// Orders of imported trades may not be on this Luno account.
+		imported, err := importedOrderPair(cfg, entry.OrderID)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("reading imported trades", err), nil
+		}
+		if imported != "" && entry.Pair == "" {
+			entry.Pair = imported
+		}
+		if entry.OrderID != "" && imported == "" && cfg.IsAuthenticated {
+			order, err := cfg.LunoClient.GetOrder(ctx, &luno.GetOrderRequest{Id: entry.OrderID})
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr(fmt.Sprintf("looking up order %s", entry.OrderID), err), nil
+			}
+			if entry.Pair == "" {
+				entry.Pair = order.Pair
+			}
+		}
+
+		entry, err = cfg.Journal.Add(entry)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("saving trade note", err), nil
+		}
+		return journalResult(entry)
+	}
+}
+
+// NewGetTradeJournalTool creates a tool for reading the trade journal
+func NewGetTradeJournalTool() mcp.Tool {
+	return mcp.NewTool(
+		GetTradeJournalToolID,
+		mcp.WithDescription("Read notes from the local trade journal, newest first, to review why orders and trades were made."),
+		mcp.WithString(
+			"order_id",
+			mcp.Description("Only return notes about this order"),
+		),
+		mcp.WithString(
+			"pair",
+			mcp.Description("Only return notes about this trading pair"),
+		),
+		mcp.WithBoolean(
+			"current_session",
+			mcp.Description("Only return notes written in this session (default: false)"),
+		),
+		mcp.WithNumber(
+			"limit",
+			mcp.Description(fmt.Sprintf("Maximum number of notes to return (default: %d)", defaultJournalLimit)),
+			mcp.Min(1),
+		),
+	)
+}
+
+// getTradeJournalArgs are the arguments of the get_trade_journal tool
+type getTradeJournalArgs struct {
+	OrderID        string `json:"order_id"`
+	Pair           string `json:"pair"`
+	CurrentSession bool   `json:"current_session"`
+	Limit          int    `json:"limit"`
+}
+
+// HandleGetTradeJournal handles the get_trade_journal tool
+func HandleGetTradeJournal(cfg *config.Config) server.ToolHandlerFunc {
+	tool := NewGetTradeJournalTool()
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		cfg := cfg.ForContext(ctx)
+		if cfg.Journal == nil {
+			return mcp.NewToolResultError("The trade journal is not available on this server"), nil
+		}
+
+		args := getTradeJournalArgs{Limit: defaultJournalLimit}
+		if errResult := bindArguments(request, tool, &args); errResult != nil {
+			return errResult, nil
+		}
+		limit := args.Limit
+		filter := journal.Filter{OrderID: args.OrderID, Tenant: cfg.Tenant}
+		if args.Pair != "" {
+			filter.Pair = normalizeCurrencyPair(args.Pair)
+		}
+		if args.CurrentSession {
+			filter.SessionID = sessionID(ctx)
+			if filter.SessionID == "" {
+				return mcp.NewToolResultError("current_session needs a client session"), nil
+			}
+		}
+
+		entries, err := cfg.Journal.List(filter)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("reading trade journal", err), nil
+		}
+		newest := make([]journal.Entry, 0, min(limit, len(entries)))
+		for i := len(entries) - 1; i >= 0 && len(newest) < limit; i-- {
+			newest = append(newest, entries[i])
+		}
+		return journalResult(newest)
+	}
+}
+
+// importedOrderPair returns the pair of the imported trades of an order, or an
+// empty string if no imported trade is from it
+func importedOrderPair(cfg *config.Config, orderID string) (string, error) {
+	if orderID == "" || cfg.Trades == nil {
+		return "", nil
+	}
+	ts, err := cfg.Trades.ByOrder(cfg.Tenant, orderID)
+	if err != nil || len(ts) == 0 {
+		return "", err
+	}
+	return ts[0].Pair, nil
+}
+
+// attachJournalNotes adds the trade journal's notes to each order that has any.
+// Orders are still returned if the journal can't be read.
+func attachJournalNotes(cfg *config.Config, orders []OrderView) {
+	if cfg.Journal == nil || len(orders) == 0 {
+		return
+	}
+	ids := make([]string, len(orders))
+	for i, o := range orders {
+		ids[i] = o.OrderId
+	}
+	notes, err := cfg.Journal.ByOrder(cfg.Tenant, ids)
+	if err != nil {
+		slog.Warn("Failed to read trade journal notes for orders", "error", err)
+		return
+	}
+	for i := range orders {
+		orders[i].Notes = notes[orders[i].OrderId]
+	}
+}
+
+// sessionID returns the ID of the MCP session ctx belongs to, if any
+func sessionID(ctx context.Context) string {
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		return session.SessionID()
+	}
+	return ""
+}
+
+func journalResult(v any) (*mcp.CallToolResult, error) {
+	resultJSON, err := marshalJSON(v)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal trade journal: %v", err)), nil
+	}
+	return mcp.NewToolResultText(resultJSON), nil
+}
@@ -0,0 +1,193 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/journal"
+	"github.com/luno/luno-mcp/internal/trades"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleLogTradeNote(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name            string
+		args            map[string]any
+		isAuthenticated bool
+		mockSetup       func(*sdk.MockLunoClient)
+		expected        journal.Entry
+		errorContains   string
+	}{
+		{
+			name:            "note about an order takes its pair",
+			args:            map[string]any{"order_id": "BXMC2CJ7HNB88U4", "note": "Support held at 1m"},
+			isAuthenticated: true,
+			mockSetup: func(c *sdk.MockLunoClient) {
+				c.EXPECT().GetOrder(ctx, &luno.GetOrderRequest{Id: "BXMC2CJ7HNB88U4"}).Return(&luno.GetOrderResponse{Pair: "XBTZAR"}, nil)
+			},
+			expected: journal.Entry{ID: "note-1", OrderID: "BXMC2CJ7HNB88U4", Pair: "XBTZAR", Note: "Support held at 1m"},
+		},
+		{
+			name:     "general note",
+			args:     map[string]any{"pair": "eth-zar", "note": "Waiting for a retest"},
+			expected: journal.Entry{ID: "note-1", Pair: "ETHZAR", Note: "Waiting for a retest"},
+		},
+		{
+			name:     "order not checked without credentials",
+			args:     map[string]any{"order_id": "BXMC2CJ7HNB88U4", "note": "Support held at 1m"},
+			expected: journal.Entry{ID: "note-1", OrderID: "BXMC2CJ7HNB88U4", Note: "Support held at 1m"},
+		},
+		{
+			name:            "imported order is not looked up",
+			args:            map[string]any{"order_id": "BXIMPORTED1", "note": "Bought on the website"},
+			isAuthenticated: true,
+			expected:        journal.Entry{ID: "note-1", OrderID: "BXIMPORTED1", Pair: "ETHZAR", Note: "Bought on the website"},
+		},
+		{
+			name:          "missing note",
+			args:          map[string]any{"order_id": "BXMC2CJ7HNB88U4"},
+			errorContains: "note is required",
+		},
+		{
+			name:          "empty note",
+			args:          map[string]any{"note": "  "},
+			errorContains: "note must not be empty",
+		},
+		{
+			name:            "unknown order",
+			args:            map[string]any{"order_id": "BXNOPE", "note": "Support held at 1m"},
+			isAuthenticated: true,
+			mockSetup: func(c *sdk.MockLunoClient) {
+				c.EXPECT().GetOrder(ctx, &luno.GetOrderRequest{Id: "BXNOPE"}).Return(nil, errors.New("order not found"))
+			},
+			errorContains: "looking up order BXNOPE",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := sdk.NewMockLunoClient(t)
+			if tt.mockSetup != nil {
+				tt.mockSetup(mockClient)
+			}
+			cfg := &config.Config{LunoClient: mockClient, IsAuthenticated: tt.isAuthenticated, Journal: journal.New(""), Trades: trades.New("")}
+			_, err := cfg.Trades.Add(trades.Trade{OrderID: "BXIMPORTED1", Pair: "ETHZAR", Volume: decimal.NewFromInt64(1), Price: decimal.NewFromInt64(1)})
+			require.NoError(t, err)
+
+			result, err := HandleLogTradeNote(cfg)(ctx, createMockRequest(tt.args))
+			require.NoError(t, err)
+			text := getTextContentFromResult(t, result)
+			entries, listErr := cfg.Journal.List(journal.Filter{})
+			require.NoError(t, listErr)
+
+			if tt.errorContains != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, text, tt.errorContains)
+				assert.Empty(t, entries)
+				return
+			}
+			require.False(t, result.IsError, text)
+			var got journal.Entry
+			require.NoError(t, json.Unmarshal([]byte(text), &got))
+			got.CreatedAt = tt.expected.CreatedAt
+			assert.Equal(t, tt.expected, got)
+			assert.Len(t, entries, 1)
+		})
+	}
+}
+
+func TestHandleGetTradeJournal(t *testing.T) {
+	srv := server.NewMCPServer("test", "1.0.0")
+	ctx := srv.WithContext(context.Background(), server.NewInProcessSession("session-1", nil))
+	otherCtx := srv.WithContext(context.Background(), server.NewInProcessSession("session-2", nil))
+
+	cfg := &config.Config{Journal: journal.New("")}
+	logNote := HandleLogTradeNote(cfg)
+	for _, n := range []struct {
+		ctx  context.Context
+		args map[string]any
+	}{
+		{otherCtx, map[string]any{"pair": "XBTZAR", "order_id": "BXMC2CJ7HNB88U4", "note": "Opened on the breakout"}},
+		{ctx, map[string]any{"pair": "ETHZAR", "note": "Watching ETH"}},
+		{ctx, map[string]any{"pair": "XBTZAR", "order_id": "BXMC2CJ7HNB88U4", "note": "Took profit"}},
+	} {
+		result, err := logNote(n.ctx, createMockRequest(n.args))
+		require.NoError(t, err)
+		require.False(t, result.IsError, getTextContentFromResult(t, result))
+	}
+
+	tests := []struct {
+		name          string
+		ctx           context.Context
+		args          map[string]any
+		expectedNotes []string
+		errorContains string
+	}{
+		{name: "newest first", ctx: ctx, args: map[string]any{}, expectedNotes: []string{"Took profit", "Watching ETH", "Opened on the breakout"}},
+		{name: "limit", ctx: ctx, args: map[string]any{"limit": 1}, expectedNotes: []string{"Took profit"}},
+		{name: "by order", ctx: ctx, args: map[string]any{"order_id": "BXMC2CJ7HNB88U4"}, expectedNotes: []string{"Took profit", "Opened on the breakout"}},
+		{name: "by pair", ctx: ctx, args: map[string]any{"pair": "eth-zar"}, expectedNotes: []string{"Watching ETH"}},
+		{name: "current session", ctx: otherCtx, args: map[string]any{"current_session": true}, expectedNotes: []string{"Opened on the breakout"}},
+		{name: "current session without one", ctx: context.Background(), args: map[string]any{"current_session": true}, errorContains: "needs a client session"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := HandleGetTradeJournal(cfg)(tt.ctx, createMockRequest(tt.args))
+			require.NoError(t, err)
+			text := getTextContentFromResult(t, result)
+			if tt.errorContains != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, text, tt.errorContains)
+				return
+			}
+			require.False(t, result.IsError, text)
+
+			var entries []journal.Entry
+			require.NoError(t, json.Unmarshal([]byte(text), &entries))
+			notes := make([]string, len(entries))
+			for i, e := range entries {
+				notes[i] = e.Note
+			}
+			assert.Equal(t, tt.expectedNotes, notes)
+		})
+	}
+}
+
+func TestHandleListOrdersJournalNotes(t *testing.T) {
+	ctx := context.Background()
+	mockClient := sdk.NewMockLunoClient(t)
+	mockClient.EXPECT().ListOrders(ctx, &luno.ListOrdersRequest{Limit: 100}).Return(&luno.ListOrdersResponse{Orders: []luno.Order{
+		{OrderId: "BXMC2CJ7HNB88U4", Pair: "XBTZAR", State: luno.OrderStateComplete},
+		{OrderId: "BXHW6PFRRXKFSB4", Pair: "XBTZAR", State: luno.OrderStateComplete},
+	}}, nil)
+
+	cfg := &config.Config{LunoClient: mockClient, IsAuthenticated: true, Journal: journal.New("")}
+	_, err := cfg.Journal.Add(journal.Entry{OrderID: "BXMC2CJ7HNB88U4", Note: "Breakout above 1m"})
+	require.NoError(t, err)
+
+	result, err := HandleListOrders(cfg)(ctx, createMockRequest(map[string]any{}))
+	require.NoError(t, err)
+	text := getTextContentFromResult(t, result)
+	require.False(t, result.IsError, text)
+
+	var got ListOrdersResult
+	require.NoError(t, json.Unmarshal([]byte(text), &got))
+	require.Len(t, got.Orders, 2)
+	require.Len(t, got.Orders[0].Notes, 1)
+	assert.Equal(t, "Breakout above 1m", got.Orders[0].Notes[0].Note)
+	assert.Empty(t, got.Orders[1].Notes)
+
+	structured, ok := result.StructuredContent.(OrdersOutput)
+	require.True(t, ok)
+	assert.Len(t, structured.Orders[0].Notes, 1)
+}
@@ -0,0 +1,164 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/levels"
+	"github.com/luno/luno-mcp/internal/validate"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Defaults for get_key_levels
+const (
+	defaultLevelsDuration  = 3600
+	defaultLevelsCandles   = 500
+	defaultLevelsTolerance = 0.5
+	defaultLevelsLimit     = 10
+	maxLevelsLimit         = 50
+)
+
+// KeyLevelsResult is the result of the get_key_levels tool
+type KeyLevelsResult struct {
+	Pair string `json:"pair"`
+	// Price is the price the levels are measured from: the order book mid
+	// price, or the last close without the order book
+	Price          float64        `json:"price"`
+	CandleDuration int64          `json:"candle_duration"`
+	Candles        int            `json:"candles"`
+	Since          time.Time      `json:"since"`
+	Levels         []levels.Level `json:"levels"`
+	Notes          []string       `json:"notes"`
+}
+
+// NewGetKeyLevelsTool creates a tool for estimating support and resistance levels
+func NewGetKeyLevelsTool() mcp.Tool {
+	return mcp.NewTool(
+		GetKeyLevelsToolID,
+		mcp.WithDescription("Estimate a market's support and resistance levels, ranked by strength, with the methods that found each one. "+
+			"Methods: swing (prices where candles repeatedly turned), volume_profile (prices where the most volume traded) and "+
+			"order_book (prices where open orders cluster). Each method adds up to 1 to a level's score, so levels found by several methods rank higher."),
+		mcp.WithString(
+			"pair",
+			mcp.Required(),
+			mcp.Description(ErrTradingPairDesc),
+		),
+		mcp.WithNumber(
+			"duration",
+			mcp.Description(fmt.Sprintf("Candle duration in seconds (default: %d for hourly candles, or e.g. 86400 for daily)", defaultLevelsDuration)),
+			mcp.Min(60),
+		),
+		mcp.WithNumber(
+			"candles",
+			mcp.Description(fmt.Sprintf("Number of recent candles to use (default: %d, at most %d)", defaultLevelsCandles, maxCandlesPerRequest)),
+			mcp.Min(1),
+			mcp.Max(maxCandlesPerRequest),
+		),
+		mcp.WithNumber(
+			"tolerance_percent",
+			mcp.Description(fmt.Sprintf("Prices within this percentage of the price count as one level (default: %g)", defaultLevelsTolerance)),
+			validate.ExclusiveMin(0),
+			validate.ExclusiveMax(10),
+		),
+		mcp.WithNumber(
+			"limit",
+			mcp.Description(fmt.Sprintf("Most levels to return (default: %d, at most %d)", defaultLevelsLimit, maxLevelsLimit)),
+			mcp.Min(1),
+			mcp.Max(maxLevelsLimit),
+		),
+		mcp.WithBoolean(
+			"include_order_book",
+			mcp.Description("Look for clusters of open orders in the order book (default: true)"),
+		),
+	)
+}
+
+// levelsArgs are the arguments of the get_key_levels tool
+type levelsArgs struct {
+	Pair             string  `json:"pair"`
+	Duration         int64   `json:"duration"`
+	Candles          int     `json:"candles"`
+	TolerancePercent float64 `json:"tolerance_percent"`
+	Limit            int     `json:"limit"`
+	IncludeOrderBook bool    `json:"include_order_book"`
+}
+
+// HandleGetKeyLevels handles the get_key_levels tool
+func HandleGetKeyLevels(cfg *config.Config) server.ToolHandlerFunc {
+	tool := NewGetKeyLevelsTool()
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		cfg := cfg.ForContext(ctx)
+		args := levelsArgs{
+			Duration:         defaultLevelsDuration,
+			Candles:          defaultLevelsCandles,
+			TolerancePercent: defaultLevelsTolerance,
+			Limit:            defaultLevelsLimit,
+			IncludeOrderBook: true,
+		}
+		if errResult := bindArguments(request, tool, &args); errResult != nil {
+			return errResult, nil
+		}
+		pair := normalizeCurrencyPair(args.Pair)
+		duration, count, tolerance, limit := args.Duration, args.Candles, args.TolerancePercent, args.Limit
+
+		since := time.Now().Add(-time.Duration(count) * time.Duration(duration) * time.Second)
+		candles, err := getCandles(ctx, cfg, pair, duration, since, time.Now())
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("getting candles", err), nil
+		}
+
+		result := KeyLevelsResult{
+			Pair:           pair,
+			CandleDuration: duration,
+			Candles:        len(candles),
+			Since:          since.UTC(),
+			Notes: []string{
+				"Levels are estimated from past prices and the current order book; prices can move through them.",
+			},
+		}
+		var bids, asks []luno.OrderBookEntry
+		if args.IncludeOrderBook {
+			book, err := cfg.LunoClient.GetOrderBookFull(ctx, &luno.GetOrderBookFullRequest{Pair: pair})
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr("getting order book", err), nil
+			}
+			bids, asks = book.Bids, book.Asks
+			if len(bids) > 0 && len(asks) > 0 {
+				result.Price = (bids[0].Price.Float64() + asks[0].Price.Float64()) / 2
+			}
+			result.Notes = append(result.Notes, "Orders can be placed and cancelled at any time, so order_book levels can disappear quickly.")
+		}
+		if result.Price == 0 && len(candles) > 0 {
+			result.Price = slices.MaxFunc(candles, func(a, b luno.Candle) int {
+				return time.Time(a.Timestamp).Compare(time.Time(b.Timestamp))
+			}).Close.Float64()
+		}
+		if result.Price <= 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("%s has no recent candles or orders to find levels in", pair)), nil
+		}
+
+		result.Levels, err = levels.Find(candles, bids, asks, levels.Params{
+			Price:     result.Price,
+			Tolerance: tolerance / 100,
+			Limit:     limit,
+		})
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("finding levels", err), nil
+		}
+		if len(result.Levels) == 0 {
+			result.Levels = []levels.Level{}
+			result.Notes = append(result.Notes, "No levels were found; try more candles or a larger tolerance_percent.")
+		}
+
+		resultJSON, err := marshalJSON(result)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal key levels: %v", err)), nil
+		}
+		return mcp.NewToolResultText(resultJSON), nil
+	}
+}
@@ -0,0 +1,124 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/levels"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleGetKeyLevels(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	candles := &luno.GetCandlesResponse{}
+	// Swing highs at 110 and lows at 90, closing at 101
+	for i, hl := range [][2]string{
+		{"100", "95"}, {"103", "96"}, {"110", "99"}, {"104", "95"}, {"101", "90"},
+		{"102", "94"}, {"110", "100"}, {"103", "96"}, {"100", "90"}, {"101", "94"}, {"102", "96"},
+	} {
+		candles.Candles = append(candles.Candles, luno.Candle{
+			Timestamp: luno.Time(start.Add(time.Duration(i) * time.Hour)),
+			High:      NewFromString(t, hl[0]),
+			Low:       NewFromString(t, hl[1]),
+			Close:     NewFromString(t, "101"),
+		})
+	}
+	book := &luno.GetOrderBookFullResponse{
+		Bids: []luno.OrderBookEntry{
+			{Price: NewFromString(t, "99"), Volume: NewFromString(t, "0.1")},
+			{Price: NewFromString(t, "95"), Volume: NewFromString(t, "20")},
+		},
+		Asks: []luno.OrderBookEntry{{Price: NewFromString(t, "101"), Volume: NewFromString(t, "0.1")}},
+	}
+
+	tests := []struct {
+		name          string
+		args          map[string]any
+		candlesErr    error
+		noCandles     bool
+		book          *luno.GetOrderBookFullResponse
+		bookErr       error
+		expPrice      float64
+		expLevels     []float64
+		errorContains string
+	}{
+		{
+			name:      "candles and order book",
+			args:      map[string]any{"pair": "xbtzar"},
+			book:      book,
+			expPrice:  100,
+			expLevels: []float64{95.25, 90, 110},
+		},
+		{
+			name:      "without order book",
+			args:      map[string]any{"pair": "XBTZAR", "include_order_book": false, "limit": 1},
+			expPrice:  101,
+			expLevels: []float64{110},
+		},
+		{
+			name:          "order book fails",
+			args:          map[string]any{"pair": "XBTZAR"},
+			bookErr:       errors.New(apiErrorStr),
+			errorContains: "getting order book",
+		},
+		{
+			name:          "candles fail",
+			args:          map[string]any{"pair": "XBTZAR"},
+			candlesErr:    errors.New(apiErrorStr),
+			errorContains: "getting candles",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := sdk.NewMockLunoClient(t)
+			if !tt.noCandles {
+				res := candles
+				if tt.candlesErr != nil {
+					res = nil
+				}
+				mockClient.EXPECT().GetCandles(ctx, mock.MatchedBy(func(r *luno.GetCandlesRequest) bool {
+					return r.Pair == "XBTZAR" && r.Duration == 3600
+				})).Return(res, tt.candlesErr)
+			}
+			if tt.book != nil || tt.bookErr != nil {
+				mockClient.EXPECT().GetOrderBookFull(ctx, &luno.GetOrderBookFullRequest{Pair: "XBTZAR"}).Return(tt.book, tt.bookErr)
+			}
+			cfg := &config.Config{LunoClient: mockClient}
+
+			result, err := HandleGetKeyLevels(cfg)(ctx, createMockRequest(tt.args))
+			require.NoError(t, err)
+			text := getTextContentFromResult(t, result)
+			if tt.errorContains != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, text, tt.errorContains)
+				return
+			}
+			require.False(t, result.IsError, text)
+
+			var got KeyLevelsResult
+			require.NoError(t, json.Unmarshal([]byte(text), &got))
+			assert.Equal(t, "XBTZAR", got.Pair)
+			assert.Equal(t, tt.expPrice, got.Price)
+			assert.Equal(t, 11, got.Candles)
+			var prices []float64
+			for _, l := range got.Levels {
+				prices = append(prices, l.Price)
+			}
+			assert.Equal(t, tt.expLevels, prices)
+			swingHigh := got.Levels[len(got.Levels)-1]
+			assert.Equal(t, levels.Resistance, swingHigh.Kind)
+			assert.Equal(t, []string{levels.Swing}, swingHigh.Methods)
+			assert.Equal(t, 2, swingHigh.Touches)
+		})
+	}
+}
@@ -0,0 +1,519 @@
+package tools
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/orders"
+	"github.com/luno/luno-mcp/internal/validate"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// defaultMarketOrderScale is the scale used for volumes and prices of markets
+// that don't report theirs
+const defaultMarketOrderScale = 8
+
+// How create_market_order places an order
+const (
+	MarketExecution = "market"
+	// LimitExecution is an immediate-or-cancel limit order, used when the
+	// slippage is above max_slippage_bps
+	LimitExecution = "limit"
+)
+
+// slippageActionRefuse is the slippage_action that refuses orders above
+// max_slippage_bps instead of placing them as limit orders
+const slippageActionRefuse = "refuse"
+
+// previewKey signs market order preview IDs, so that only previews made by
+// this process can be confirmed
+var previewKey = func() []byte {
+	key := make([]byte, sha256.Size)
+	_, _ = rand.Read(key)
+	return key
+}()
+
+// marketPreviewID identifies a preview of a market order in a session. A
+// confirmed order must carry the ID of a preview of the same order.
+func marketPreviewID(session, pair, orderType string, volume decimal.Decimal, maxSlippage float64, refuse bool) string {
+	mac := hmac.New(sha256.New, previewKey)
+	mac.Write([]byte(strings.Join([]string{
+		session, pair, orderType, volume.String(), strconv.FormatFloat(maxSlippage, 'g', -1, 64), strconv.FormatBool(refuse),
+	}, "\x00")))
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// MarketOrderPreview is the expected fill of a market order, from walking the
+// order book as it was when the order was checked
+type MarketOrderPreview struct {
+	Pair string `json:"pair"`
+	Type string `json:"type"`
+	// BaseVolume is the volume to sell, or the volume a buy is expected to receive
+	BaseVolume string `json:"base_volume"`
+	// CounterVolume is the amount to spend, or the amount a sell is expected to receive
+	CounterVolume string `json:"counter_volume"`
+	BestPrice     string `json:"best_price"`
+	MidPrice      string `json:"mid_price"`
+	AveragePrice  string `json:"average_price"`
+	// WorstPrice is the price of the last order book level the order reaches
+	WorstPrice  string `json:"worst_price"`
+	PriceLevels int    `json:"price_levels"`
+	// SlippageBps is how much worse the average price is than the mid price,
+	// in basis points
+	SlippageBps float64 `json:"slippage_bps"`
+	// MaxSlippageBps is the slippage the order was limited to, if any
+	MaxSlippageBps float64 `json:"max_slippage_bps,omitempty"`
+	// Execution is how the order is placed, MarketExecution or LimitExecution
+	Execution string `json:"execution"`
+	// LimitPrice and LimitVolume are the limit order placed with LimitExecution
+	LimitPrice  string `json:"limit_price,omitempty"`
+	LimitVolume string `json:"limit_volume,omitempty"`
+	// LimitFillVolume is the base volume the order book holds up to LimitPrice,
+	// which is about how much the limit order is expected to fill
+	LimitFillVolume string   `json:"limit_fill_volume,omitempty"`
+	Notes           []string `json:"notes"`
+}
+
+// CreateMarketOrderResult is returned by create_market_order. PreviewID is
+// only set on previews, and OrderID when the order was confirmed and placed.
+type CreateMarketOrderResult struct {
+	Confirmed bool               `json:"confirmed"`
+	Preview   MarketOrderPreview `json:"preview"`
+	PreviewID string             `json:"preview_id,omitempty"`
+	OrderID   string             `json:"order_id,omitempty"`
+	Message   string             `json:"message"`
+}
+
+// bookFill is the result of walking one side of the order book
+type bookFill struct {
+	base    decimal.Decimal
+	counter decimal.Decimal
+	worst   decimal.Decimal
+	levels  int
+}
+
+// NewCreateMarketOrderTool creates a tool for placing market orders
+func NewCreateMarketOrderTool() mcp.Tool {
+	return mcp.NewTool(
+		CreateMarketOrderToolID,
+		mcp.WithDescription("Create a market order, which trades immediately against the order book. "+
+			"Without confirm=true this only returns a preview of the expected average price, total and slippage against the mid price "+
+			"from walking the current order book; show it to the user and call again with the same arguments, confirm=true and the preview's preview_id to place the order."+writeOperationNotice),
+		mcp.WithString(
+			"pair",
+			mcp.Required(),
+			mcp.Description("Trading pair (e.g., XBTZAR)"),
+		),
+		mcp.WithString(
+			"type",
+			mcp.Required(),
+			mcp.Description("Order type (BUY or SELL)"),
+			mcp.Enum("BUY", "SELL"),
+		),
+		mcp.WithString(
+			"counter_volume",
+			mcp.Description("For BUY orders: amount of the counter currency to spend (e.g. ZAR for XBTZAR) as a decimal string"),
+			validate.Decimal(),
+			validate.ExclusiveMin(0),
+		),
+		mcp.WithString(
+			"base_volume",
+			mcp.Description("For SELL orders: amount of the base currency to sell (e.g. XBT for XBTZAR) as a decimal string"),
+			validate.Decimal(),
+			validate.ExclusiveMin(0),
+		),
+		mcp.WithNumber(
+			"max_slippage_bps",
+			mcp.Description("Most slippage against the mid price to accept, in basis points (e.g. 50 for 0.5%). "+
+				"Above it the order is placed as an immediate-or-cancel limit order at that slippage, or refused (default: no limit)"),
+			mcp.Min(0),
+			validate.ExclusiveMax(10000),
+		),
+		mcp.WithString(
+			"slippage_action",
+			mcp.Description("What to do when the slippage is above max_slippage_bps: limit places an immediate-or-cancel limit order that only fills up to the slippage, refuse places nothing (default: limit)"),
+			mcp.Enum("limit", slippageActionRefuse),
+		),
+		mcp.WithBoolean(
+			"confirm",
+			mcp.Description("Set to true to place the order after the user has reviewed the preview (default: false)"),
+		),
+		mcp.WithString(
+			"preview_id",
+			mcp.Description("preview_id from the preview of this order, required with confirm=true"),
+		),
+	)
+}
+
+// marketOrderArgs are the arguments of the create_market_order tool
+type marketOrderArgs struct {
+	Pair           string           `json:"pair"`
+	Type           string           `json:"type"`
+	CounterVolume  *decimal.Decimal `json:"counter_volume"`
+	BaseVolume     *decimal.Decimal `json:"base_volume"`
+	MaxSlippageBPS float64          `json:"max_slippage_bps"`
+	SlippageAction string           `json:"slippage_action"`
+	Confirm        bool             `json:"confirm"`
+	PreviewID      string           `json:"preview_id"`
+}
+
+// HandleCreateMarketOrder handles the create_market_order tool
+func HandleCreateMarketOrder(cfg *config.Config) server.ToolHandlerFunc {
+	tool := NewCreateMarketOrderTool()
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		cfg := cfg.ForContext(ctx)
+		if !cfg.IsAuthenticated {
+			return mcp.NewToolResultError(ErrAPICredentialsRequired), nil
+		}
+
+		var args marketOrderArgs
+		if errResult := bindArguments(request, tool, &args); errResult != nil {
+			return errResult, nil
+		}
+		pair, orderType := normalizeCurrencyPair(args.Pair), args.Type
+		if errResult := checkTradingPair(cfg, pair); errResult != nil {
+			return errResult, nil
+		}
+
+		volumeParam, volumeArg := "base_volume", args.BaseVolume
+		if orderType == "BUY" {
+			volumeParam, volumeArg = "counter_volume", args.CounterVolume
+		}
+		if volumeArg == nil {
+			return mcp.NewToolResultError(fmt.Sprintf("%s orders need %s", orderType, volumeParam)), nil
+		}
+		volume := *volumeArg
+		maxSlippage := args.MaxSlippageBPS
+		refuse := args.SlippageAction == slippageActionRefuse
+
+		// A confirmed order must have been previewed first, with the same
+		// arguments in the same session
+		previewID := marketPreviewID(sessionID(ctx), pair, orderType, volume, maxSlippage, refuse)
+		if args.Confirm {
+			if args.PreviewID == "" {
+				return mcp.NewToolResultError("Unable to create order: confirm=true needs the preview_id of a preview of this order. " +
+					"Call again without confirm to preview it, show the preview to the user, then confirm with its preview_id."), nil
+			}
+			if !hmac.Equal([]byte(args.PreviewID), []byte(previewID)) {
+				return mcp.NewToolResultError("Unable to create order: preview_id is not from a preview of this order in this session. " +
+					"Preview the order again with the same arguments and confirm with the new preview_id."), nil
+			}
+		}
+
+		// Luno validates the order anyway, so carry on if the markets can't be listed
+		market, ok, err := MarketCache(cfg).Lookup(ctx, pair)
+		switch {
+		case err != nil:
+			slog.WarnContext(ctx, "Failed to look up market for order validation", "pair", pair, "error", err)
+		case !ok:
+			return mcp.NewToolResultError(fmt.Sprintf("Unable to create order for %s: %s", pair, unknownMarketError)), nil
+		case market.TradingStatus == luno.TradingStatusSuspended:
+			return mcp.NewToolResultError(fmt.Sprintf("Unable to create order: trading on %s is suspended and new orders are rejected until it resumes, use get_exchange_status to check market status", pair)), nil
+		case market.TradingStatus == luno.TradingStatusPost_only:
+			return mcp.NewToolResultError(fmt.Sprintf("Unable to create order: %s is post-only, so market orders are rejected. Use create_order to place a limit order instead.", pair)), nil
+		}
+
+		book, err := cfg.LunoClient.GetOrderBook(ctx, &luno.GetOrderBookRequest{Pair: pair})
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("getting order book", err), nil
+		}
+		preview, err := previewMarketOrder(market, pair, orderType, volume, book, maxSlippage)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Unable to create order: %v", err)), nil
+		}
+		if preview.Execution == LimitExecution && refuse {
+			return mcp.NewToolResultError(fmt.Sprintf("Unable to create order: the expected slippage of %g bps is above max_slippage_bps of %g. "+
+				"The order book is too thin for this size; try a smaller order or use create_order for a limit order.", preview.SlippageBps, maxSlippage)), nil
+		}
+
+		result := CreateMarketOrderResult{Preview: preview}
+		if !args.Confirm {
+			result.PreviewID = previewID
+			result.Message = "No order was placed. Review the preview with the user, then call again with the same arguments, confirm=true and this preview_id to place it."
+			return marketOrderResult(result)
+		}
+
+		if preview.Execution == LimitExecution {
+			return placeSlippageLimitOrder(ctx, cfg, market, result)
+		}
+
+		req := &luno.PostMarketOrderRequest{Pair: pair}
+		if orderType == "BUY" {
+			req.Type = luno.OrderTypeBuy
+			req.CounterVolume = volume
+		} else {
+			req.Type = luno.OrderTypeSell
+			req.BaseVolume = volume
+		}
+		slog.InfoContext(ctx, "Creating market order",
+			"pair", pair,
+			"type", req.Type,
+			volumeParam, volume.String())
+
+		order, err := cfg.LunoClient.PostMarketOrder(ctx, req)
+		if err != nil {
+			errorMsg := fmt.Sprintf("Failed to create market order: %v", err)
+			if statusLine := marketStatusLine(market); statusLine != "" {
+				errorMsg += "\n\n" + statusLine
+			}
+			return mcp.NewToolResultError(errorMsg), nil
+		}
+
+		trackOrder(ctx, cfg, CreateMarketOrderToolID, orders.Order{
+			OrderID:      order.OrderId,
+			Pair:         pair,
+			Type:         req.Type,
+			Volume:       volume,
+			Confirmation: previewConfirmation(preview),
+		})
+		result.Confirmed = true
+		result.OrderID = order.OrderId
+		result.Message = fmt.Sprintf("Market order %s placed. Use list_trades to see the price it filled at.", order.OrderId)
+		return marketOrderResult(result)
+	}
+}
+
+// previewMarketOrder walks the side of book a market order takes from. Buys
+// spend volume of the counter currency and sells sell volume of the base.
+// When the slippage is above maxSlippage, more than 0, the preview is of an
+// immediate-or-cancel limit order at maxSlippage from the mid price instead.
+func previewMarketOrder(m luno.MarketInfo, pair, orderType string, volume decimal.Decimal, book *luno.GetOrderBookResponse, maxSlippage float64) (MarketOrderPreview, error) {
+	volumeScale, priceScale := int(m.VolumeScale), int(m.PriceScale)
+	if volumeScale == 0 {
+		volumeScale = defaultMarketOrderScale
+	}
+	if priceScale == 0 {
+		priceScale = defaultMarketOrderScale
+	}
+
+	entries, other := book.Asks, book.Bids
+	if orderType == "SELL" {
+		entries, other = book.Bids, book.Asks
+	}
+	if len(entries) == 0 {
+		return MarketOrderPreview{}, fmt.Errorf("there are no orders on %s to trade with", pair)
+	}
+	fill, err := walkOrderBook(entries, volume, orderType == "BUY", volumeScale+defaultMarketOrderScale)
+	if err != nil {
+		return MarketOrderPreview{}, fmt.Errorf("%w for %s %s on %s", err, orderType, volume, pair)
+	}
+	if fill.base.Sign() <= 0 {
+		return MarketOrderPreview{}, fmt.Errorf("%s is too little to trade on %s", volume, pair)
+	}
+	if m.MinVolume.Sign() > 0 && fill.base.Cmp(m.MinVolume) < 0 {
+		return MarketOrderPreview{}, fmt.Errorf("the order trades about %s, below the minimum volume of %s for %s", fill.base.ToScale(volumeScale), m.MinVolume, pair)
+	}
+	if m.MaxVolume.Sign() > 0 && fill.base.Cmp(m.MaxVolume) > 0 {
+		return MarketOrderPreview{}, fmt.Errorf("the order trades about %s, above the maximum volume of %s for %s", fill.base.ToScale(volumeScale), m.MaxVolume, pair)
+	}
+
+	best := entries[0].Price
+	preview := MarketOrderPreview{
+		Pair:          pair,
+		Type:          orderType,
+		BaseVolume:    fill.base.ToScale(volumeScale).String(),
+		CounterVolume: fill.counter.String(),
+		BestPrice:     best.String(),
+		AveragePrice:  fill.counter.Div(fill.base, priceScale).String(),
+		WorstPrice:    fill.worst.String(),
+		PriceLevels:   fill.levels,
+		Execution:     MarketExecution,
+		Notes: []string{
+			"Estimated from the order book when this was checked; it can move before the order is placed.",
+			"Trading fees are not included.",
+		},
+	}
+
+	mid := best
+	if len(other) > 0 {
+		mid = best.Add(other[0].Price).Div(decimal.NewFromInt64(2), priceScale+1)
+	} else {
+		preview.Notes = append(preview.Notes, "The other side of the order book is empty, so slippage is measured from the best price.")
+	}
+	preview.MidPrice = mid.String()
+	// Slippage is the cost of the fill against mid: paying more on a buy, or
+	// receiving less on a sell
+	slippage := fill.counter.Sub(fill.base.Mul(mid))
+	if orderType == "SELL" {
+		slippage = slippage.Neg()
+	}
+	preview.SlippageBps = math.Round(percentOf(slippage, fill.base.Mul(mid))*10000) / 100
+	if maxSlippage <= 0 {
+		return preview, nil
+	}
+	preview.MaxSlippageBps = maxSlippage
+	if preview.SlippageBps <= maxSlippage {
+		return preview, nil
+	}
+
+	// The limit is maxSlippage from mid, rounded towards mid to the market's
+	// price scale so the order never slips more than asked
+	factor := decimal.NewFromFloat64(maxSlippage/10000, defaultMarketOrderScale)
+	limit := mid.Add(mid.Mul(factor)).ToScale(priceScale)
+	limitVolume := volume
+	if orderType == "BUY" {
+		limitVolume = volume.Div(limit, volumeScale)
+	} else {
+		limit = mid.Sub(mid.Mul(factor))
+		if rounded := limit.ToScale(priceScale); rounded.Cmp(limit) < 0 {
+			limit = rounded.Add(decimal.New(big.NewInt(1), priceScale))
+		} else {
+			limit = rounded
+		}
+	}
+	if (orderType == "BUY" && limit.Cmp(best) < 0) || (orderType == "SELL" && limit.Cmp(best) > 0) {
+		return MarketOrderPreview{}, fmt.Errorf("the best price of %s is already more than max_slippage_bps of %g from the mid price of %s, so nothing would trade", best, maxSlippage, mid)
+	}
+	if limitVolume.Sign() <= 0 || (m.MinVolume.Sign() > 0 && limitVolume.Cmp(m.MinVolume) < 0) {
+		return MarketOrderPreview{}, fmt.Errorf("a limit order of %s at %s is below the minimum volume of %s for %s", limitVolume, limit, m.MinVolume, pair)
+	}
+
+	available := decimal.Zero()
+	for _, e := range entries {
+		if (orderType == "BUY" && e.Price.Cmp(limit) > 0) || (orderType == "SELL" && e.Price.Cmp(limit) < 0) {
+			break
+		}
+		available = available.Add(e.Volume)
+	}
+	if available.Cmp(limitVolume) > 0 {
+		available = limitVolume
+	}
+	preview.Execution = LimitExecution
+	preview.LimitPrice = limit.String()
+	preview.LimitVolume = limitVolume.String()
+	preview.LimitFillVolume = available.String()
+	preview.Notes = append(preview.Notes, fmt.Sprintf("The expected slippage of %g bps is above max_slippage_bps of %g, so this is placed as an immediate-or-cancel "+
+		"limit order for %s at %s: it only trades at that price or better and whatever does not fill at once is cancelled.",
+		preview.SlippageBps, maxSlippage, limitVolume, limit))
+	return preview, nil
+}
+
+// placeSlippageLimitOrder places the immediate-or-cancel limit order of a
+// market order whose slippage is above its max_slippage_bps
+func placeSlippageLimitOrder(ctx context.Context, cfg *config.Config, market luno.MarketInfo, result CreateMarketOrderResult) (*mcp.CallToolResult, error) {
+	p := result.Preview
+	price, err := decimal.NewFromString(p.LimitPrice)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid limit price: %v", err)), nil
+	}
+	volume, err := decimal.NewFromString(p.LimitVolume)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid limit volume: %v", err)), nil
+	}
+	req := &luno.PostLimitOrderRequest{
+		Pair:        p.Pair,
+		Type:        luno.OrderTypeBid,
+		Volume:      volume,
+		Price:       price,
+		TimeInForce: luno.TimeInForceIoc,
+	}
+	if p.Type == "SELL" {
+		req.Type = luno.OrderTypeAsk
+	}
+	slog.InfoContext(ctx, "Creating limit order for market order above max slippage",
+		"pair", p.Pair,
+		"type", req.Type,
+		"volume", volume.String(),
+		"price", price.String(),
+		"slippage_bps", p.SlippageBps)
+
+	order, err := cfg.LunoClient.PostLimitOrder(ctx, req)
+	if err != nil {
+		errorMsg := fmt.Sprintf("Failed to create limit order: %v", err)
+		if statusLine := marketStatusLine(market); statusLine != "" {
+			errorMsg += "\n\n" + statusLine
+		}
+		return mcp.NewToolResultError(errorMsg), nil
+	}
+
+	trackOrder(ctx, cfg, CreateMarketOrderToolID, orders.Order{
+		OrderID:      order.OrderId,
+		Pair:         p.Pair,
+		Type:         req.Type,
+		Volume:       volume,
+		Price:        price,
+		Confirmation: previewConfirmation(p),
+	})
+	result.Confirmed = true
+	result.OrderID = order.OrderId
+	result.Message = fmt.Sprintf("The slippage was above max_slippage_bps, so immediate-or-cancel limit order %s was placed at %s; "+
+		"whatever did not fill at once was cancelled. Use list_trades to see what it filled.", order.OrderId, price)
+	return marketOrderResult(result)
+}
+
+// previewConfirmation is what the user confirmed of a market order preview,
+// kept with the tracked order
+func previewConfirmation(p MarketOrderPreview) map[string]string {
+	c := map[string]string{
+		"execution":      p.Execution,
+		"average_price":  p.AveragePrice,
+		"mid_price":      p.MidPrice,
+		"base_volume":    p.BaseVolume,
+		"counter_volume": p.CounterVolume,
+		"slippage_bps":   strconv.FormatFloat(p.SlippageBps, 'f', -1, 64),
+	}
+	if p.MaxSlippageBps > 0 {
+		c["max_slippage_bps"] = strconv.FormatFloat(p.MaxSlippageBps, 'f', -1, 64)
+	}
+	return c
+}
+
+// walkOrderBook fills volume against entries in order, spending volume of the
+// counter currency when spend is true or selling volume of the base otherwise.
+// Base volumes bought are computed at scale.
+func walkOrderBook(entries []luno.OrderBookEntry, volume decimal.Decimal, spend bool, scale int) (bookFill, error) {
+	fill := bookFill{base: decimal.Zero(), counter: decimal.Zero()}
+	remaining := volume
+	for _, e := range entries {
+		if remaining.Sign() <= 0 {
+			break
+		}
+		fill.worst = e.Price
+		fill.levels++
+		cost := e.Volume.Mul(e.Price)
+		switch {
+		case spend && cost.Cmp(remaining) >= 0:
+			fill.base = fill.base.Add(remaining.Div(e.Price, scale))
+			fill.counter = fill.counter.Add(remaining)
+			remaining = decimal.Zero()
+		case !spend && e.Volume.Cmp(remaining) >= 0:
+			fill.base = fill.base.Add(remaining)
+			fill.counter = fill.counter.Add(remaining.Mul(e.Price))
+			remaining = decimal.Zero()
+		case spend:
+			fill.base = fill.base.Add(e.Volume)
+			fill.counter = fill.counter.Add(cost)
+			remaining = remaining.Sub(cost)
+		default:
+			fill.base = fill.base.Add(e.Volume)
+			fill.counter = fill.counter.Add(cost)
+			remaining = remaining.Sub(e.Volume)
+		}
+	}
+	if remaining.Sign() > 0 {
+		return bookFill{}, errors.New("the order book is too thin")
+	}
+	return fill, nil
+}
+
+func marketOrderResult(result CreateMarketOrderResult) (*mcp.CallToolResult, error) {
+	resultJSON, err := marshalJSON(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal market order: %v", err)), nil
+	}
+	return mcp.NewToolResultText(resultJSON), nil
+}
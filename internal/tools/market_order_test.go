@@ -0,0 +1,307 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/markets"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleCreateMarketOrder(t *testing.T) {
+	ctx := context.Background()
+	listed := &luno.MarketsResponse{Markets: []luno.MarketInfo{
+		{MarketId: "XBTZAR", VolumeScale: 4, PriceScale: 2, MinVolume: NewFromString(t, "0.0005")},
+		{MarketId: "ETHZAR", VolumeScale: 4, PriceScale: 2, TradingStatus: luno.TradingStatusPost_only},
+	}}
+	book := &luno.GetOrderBookResponse{
+		Asks: []luno.OrderBookEntry{
+			{Price: NewFromString(t, "100"), Volume: NewFromString(t, "1")},
+			{Price: NewFromString(t, "110"), Volume: NewFromString(t, "1")},
+			{Price: NewFromString(t, "120"), Volume: NewFromString(t, "10")},
+		},
+		Bids: []luno.OrderBookEntry{
+			{Price: NewFromString(t, "90"), Volume: NewFromString(t, "1")},
+			{Price: NewFromString(t, "80"), Volume: NewFromString(t, "5")},
+		},
+	}
+	buyPreview := MarketOrderPreview{
+		Pair:          "XBTZAR",
+		Type:          "BUY",
+		BaseVolume:    "1.5000",
+		CounterVolume: "155",
+		BestPrice:     "100",
+		MidPrice:      "95.000",
+		AveragePrice:  "103.33",
+		WorstPrice:    "110",
+		PriceLevels:   2,
+		SlippageBps:   877.19,
+		Execution:     MarketExecution,
+	}
+	buyLimit := &luno.PostLimitOrderRequest{
+		Pair:        "XBTZAR",
+		Type:        luno.OrderTypeBid,
+		Volume:      NewFromString(t, "1.5107"),
+		Price:       NewFromString(t, "102.60"),
+		TimeInForce: luno.TimeInForceIoc,
+	}
+	// previewID is the preview_id of a preview of buying with 155 on XBTZAR
+	previewID := func(maxSlippage float64, refuse bool) string {
+		return marketPreviewID("", "XBTZAR", "BUY", NewFromString(t, "155"), maxSlippage, refuse)
+	}
+	limitedBuyPreview := buyPreview
+	limitedBuyPreview.MaxSlippageBps = 800
+	limitedBuyPreview.Execution = LimitExecution
+	limitedBuyPreview.LimitPrice = "102.60"
+	limitedBuyPreview.LimitVolume = "1.5107"
+	limitedBuyPreview.LimitFillVolume = "1"
+
+	tests := []struct {
+		name            string
+		args            map[string]any
+		isAuthenticated bool
+		noBook          bool
+		mockSetup       func(*sdk.MockLunoClient)
+		expResult       *CreateMarketOrderResult
+		errorContains   string
+	}{
+		{
+			name:            "buy preview",
+			args:            map[string]any{"pair": "xbt-zar", "type": "BUY", "counter_volume": "155"},
+			isAuthenticated: true,
+			expResult:       &CreateMarketOrderResult{Preview: buyPreview},
+		},
+		{
+			name:            "sell preview",
+			args:            map[string]any{"pair": "XBTZAR", "type": "SELL", "base_volume": "3"},
+			isAuthenticated: true,
+			expResult: &CreateMarketOrderResult{Preview: MarketOrderPreview{
+				Pair:          "XBTZAR",
+				Type:          "SELL",
+				BaseVolume:    "3.0000",
+				CounterVolume: "250",
+				BestPrice:     "90",
+				MidPrice:      "95.000",
+				AveragePrice:  "83.33",
+				WorstPrice:    "80",
+				PriceLevels:   2,
+				SlippageBps:   1228.07,
+				Execution:     MarketExecution,
+			}},
+		},
+		{
+			name:            "slippage below max",
+			args:            map[string]any{"pair": "XBTZAR", "type": "BUY", "counter_volume": "155", "max_slippage_bps": 1000},
+			isAuthenticated: true,
+			expResult: &CreateMarketOrderResult{Preview: func() MarketOrderPreview {
+				p := buyPreview
+				p.MaxSlippageBps = 1000
+				return p
+			}()},
+		},
+		{
+			name:            "slippage above max becomes a limit order",
+			args:            map[string]any{"pair": "XBTZAR", "type": "BUY", "counter_volume": "155", "max_slippage_bps": 800},
+			isAuthenticated: true,
+			expResult:       &CreateMarketOrderResult{Preview: limitedBuyPreview},
+		},
+		{
+			name:            "sell above max becomes a limit order",
+			args:            map[string]any{"pair": "XBTZAR", "type": "SELL", "base_volume": "3", "max_slippage_bps": 1000},
+			isAuthenticated: true,
+			expResult: &CreateMarketOrderResult{Preview: MarketOrderPreview{
+				Pair:            "XBTZAR",
+				Type:            "SELL",
+				BaseVolume:      "3.0000",
+				CounterVolume:   "250",
+				BestPrice:       "90",
+				MidPrice:        "95.000",
+				AveragePrice:    "83.33",
+				WorstPrice:      "80",
+				PriceLevels:     2,
+				SlippageBps:     1228.07,
+				MaxSlippageBps:  1000,
+				Execution:       LimitExecution,
+				LimitPrice:      "85.50",
+				LimitVolume:     "3",
+				LimitFillVolume: "1",
+			}},
+		},
+		{
+			name:            "confirmed limit order",
+			args:            map[string]any{"pair": "XBTZAR", "type": "BUY", "counter_volume": "155", "max_slippage_bps": 800, "confirm": true, "preview_id": previewID(800, false)},
+			isAuthenticated: true,
+			mockSetup: func(c *sdk.MockLunoClient) {
+				c.EXPECT().PostLimitOrder(ctx, buyLimit).Return(&luno.PostLimitOrderResponse{OrderId: "BXLIM1"}, nil)
+			},
+			expResult: &CreateMarketOrderResult{Confirmed: true, Preview: limitedBuyPreview, OrderID: "BXLIM1"},
+		},
+		{
+			name:            "slippage above max refused",
+			args:            map[string]any{"pair": "XBTZAR", "type": "BUY", "counter_volume": "155", "max_slippage_bps": 800, "slippage_action": "refuse", "confirm": true, "preview_id": previewID(800, true)},
+			isAuthenticated: true,
+			errorContains:   "the expected slippage of 877.19 bps is above max_slippage_bps of 800",
+		},
+		{
+			name:            "spread wider than max",
+			args:            map[string]any{"pair": "XBTZAR", "type": "BUY", "counter_volume": "155", "max_slippage_bps": 500},
+			isAuthenticated: true,
+			errorContains:   "the best price of 100 is already more than max_slippage_bps of 500 from the mid price",
+		},
+		{
+			name:            "confirmed buy",
+			args:            map[string]any{"pair": "XBTZAR", "type": "BUY", "counter_volume": "155", "confirm": true, "preview_id": previewID(0, false)},
+			isAuthenticated: true,
+			mockSetup: func(c *sdk.MockLunoClient) {
+				c.EXPECT().PostMarketOrder(ctx, &luno.PostMarketOrderRequest{
+					Pair:          "XBTZAR",
+					Type:          luno.OrderTypeBuy,
+					CounterVolume: NewFromString(t, "155"),
+				}).Return(&luno.PostMarketOrderResponse{OrderId: "BXMC1"}, nil)
+			},
+			expResult: &CreateMarketOrderResult{Confirmed: true, Preview: buyPreview, OrderID: "BXMC1"},
+		},
+		{
+			name:            "order rejected",
+			args:            map[string]any{"pair": "XBTZAR", "type": "BUY", "counter_volume": "155", "confirm": true, "preview_id": previewID(0, false)},
+			isAuthenticated: true,
+			mockSetup: func(c *sdk.MockLunoClient) {
+				c.EXPECT().PostMarketOrder(ctx, &luno.PostMarketOrderRequest{
+					Pair:          "XBTZAR",
+					Type:          luno.OrderTypeBuy,
+					CounterVolume: NewFromString(t, "155"),
+				}).Return(nil, errors.New("insufficient balance"))
+			},
+			errorContains: "Failed to create market order: insufficient balance",
+		},
+		{
+			name:            "confirmed without a preview",
+			args:            map[string]any{"pair": "XBTZAR", "type": "BUY", "counter_volume": "155", "confirm": true},
+			isAuthenticated: true,
+			noBook:          true,
+			errorContains:   "confirm=true needs the preview_id of a preview of this order",
+		},
+		{
+			name:            "confirmed with the preview of another order",
+			args:            map[string]any{"pair": "XBTZAR", "type": "BUY", "counter_volume": "1550", "confirm": true, "preview_id": previewID(0, false)},
+			isAuthenticated: true,
+			noBook:          true,
+			errorContains:   "preview_id is not from a preview of this order in this session",
+		},
+		{
+			name:            "book too thin",
+			args:            map[string]any{"pair": "XBTZAR", "type": "BUY", "counter_volume": "10000"},
+			isAuthenticated: true,
+			errorContains:   "the order book is too thin for BUY 10000 on XBTZAR",
+		},
+		{
+			name:            "below minimum volume",
+			args:            map[string]any{"pair": "XBTZAR", "type": "BUY", "counter_volume": "0.01"},
+			isAuthenticated: true,
+			errorContains:   "below the minimum volume of 0.0005",
+		},
+		{
+			name:            "post-only market",
+			args:            map[string]any{"pair": "ETHZAR", "type": "SELL", "base_volume": "1"},
+			isAuthenticated: true,
+			noBook:          true,
+			errorContains:   "post-only, so market orders are rejected",
+		},
+		{
+			name:            "sell without base volume",
+			args:            map[string]any{"pair": "XBTZAR", "type": "SELL", "counter_volume": "100"},
+			isAuthenticated: true,
+			noBook:          true,
+			errorContains:   "SELL orders need base_volume",
+		},
+		{
+			name:            "not authenticated",
+			args:            map[string]any{"pair": "XBTZAR", "type": "BUY", "counter_volume": "155"},
+			isAuthenticated: false,
+			noBook:          true,
+			errorContains:   ErrAPICredentialsRequired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := sdk.NewMockLunoClient(t)
+			mockClient.EXPECT().Markets(ctx, &luno.MarketsRequest{}).Return(listed, nil).Maybe()
+			if !tt.noBook {
+				mockClient.EXPECT().GetOrderBook(ctx, &luno.GetOrderBookRequest{Pair: "XBTZAR"}).Return(book, nil)
+			}
+			if tt.mockSetup != nil {
+				tt.mockSetup(mockClient)
+			}
+			cfg := &config.Config{LunoClient: mockClient, IsAuthenticated: tt.isAuthenticated, Markets: markets.NewCache(mockClient)}
+
+			result, err := HandleCreateMarketOrder(cfg)(ctx, createMockRequest(tt.args))
+			require.NoError(t, err)
+			text := getTextContentFromResult(t, result)
+			if tt.errorContains != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, text, tt.errorContains)
+				return
+			}
+			require.False(t, result.IsError, text)
+
+			var got CreateMarketOrderResult
+			require.NoError(t, json.Unmarshal([]byte(text), &got))
+			assert.NotEmpty(t, got.Preview.Notes)
+			assert.NotEmpty(t, got.Message)
+			got.Preview.Notes, got.Message = nil, ""
+			assert.Equal(t, !got.Confirmed, got.PreviewID != "", "only previews have a preview_id")
+			got.PreviewID = ""
+			assert.Equal(t, *tt.expResult, got)
+		})
+	}
+}
+
+func TestHandleCreateMarketOrderPreviewID(t *testing.T) {
+	srv := server.NewMCPServer("test", "1.0.0")
+	ctx := srv.WithContext(context.Background(), server.NewInProcessSession("session-1", nil))
+	otherCtx := srv.WithContext(context.Background(), server.NewInProcessSession("session-2", nil))
+
+	mockClient := sdk.NewMockLunoClient(t)
+	mockClient.EXPECT().Markets(mock.Anything, &luno.MarketsRequest{}).Return(&luno.MarketsResponse{Markets: []luno.MarketInfo{
+		{MarketId: "XBTZAR", VolumeScale: 4, PriceScale: 2, MinVolume: NewFromString(t, "0.0005")},
+	}}, nil).Maybe()
+	mockClient.EXPECT().GetOrderBook(mock.Anything, &luno.GetOrderBookRequest{Pair: "XBTZAR"}).Return(&luno.GetOrderBookResponse{
+		Asks: []luno.OrderBookEntry{{Price: NewFromString(t, "100"), Volume: NewFromString(t, "10")}},
+		Bids: []luno.OrderBookEntry{{Price: NewFromString(t, "90"), Volume: NewFromString(t, "10")}},
+	}, nil)
+	mockClient.EXPECT().PostMarketOrder(mock.Anything, &luno.PostMarketOrderRequest{
+		Pair: "XBTZAR", Type: luno.OrderTypeBuy, CounterVolume: NewFromString(t, "150"),
+	}).Return(&luno.PostMarketOrderResponse{OrderId: "BXMC1"}, nil).Once()
+	handler := HandleCreateMarketOrder(&config.Config{LunoClient: mockClient, IsAuthenticated: true, Markets: markets.NewCache(mockClient)})
+	call := func(ctx context.Context, args map[string]any) (CreateMarketOrderResult, string) {
+		t.Helper()
+		result, err := handler(ctx, createMockRequest(args))
+		require.NoError(t, err)
+		text := getTextContentFromResult(t, result)
+		var got CreateMarketOrderResult
+		if !result.IsError {
+			require.NoError(t, json.Unmarshal([]byte(text), &got))
+		}
+		return got, text
+	}
+
+	preview, _ := call(ctx, map[string]any{"pair": "XBTZAR", "type": "BUY", "counter_volume": "150"})
+	require.NotEmpty(t, preview.PreviewID)
+
+	confirm := map[string]any{"pair": "XBTZAR", "type": "BUY", "counter_volume": "150", "confirm": true, "preview_id": preview.PreviewID}
+	_, text := call(otherCtx, confirm)
+	assert.Contains(t, text, "preview_id is not from a preview of this order in this session", "previews are kept to their session")
+
+	placed, text := call(ctx, confirm)
+	require.True(t, placed.Confirmed, text)
+	assert.Equal(t, "BXMC1", placed.OrderID)
+}
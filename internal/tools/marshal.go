@@ -0,0 +1,45 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+)
+
+// maxPooledJSONBytes is the largest buffer kept for reuse, so that one very
+// large result doesn't keep its memory in the pool
+const maxPooledJSONBytes = 1 << 20
+
+// jsonEncoder writes indented JSON to a buffer it keeps between uses
+type jsonEncoder struct {
+	buf bytes.Buffer
+	enc *json.Encoder
+}
+
+var jsonEncoders = sync.Pool{
+	New: func() any {
+		e := &jsonEncoder{}
+		e.enc = json.NewEncoder(&e.buf)
+		e.enc.SetIndent("", "  ")
+		return e
+	},
+}
+
+// marshalJSON returns v as JSON indented by two spaces, as
+// json.MarshalIndent(v, "", "  ") does. Most tool results are marshalled
+// this way on every call, so the encoder and its buffers are reused, leaving
+// the returned string as the only copy made.
+func marshalJSON(v any) (string, error) {
+	e := jsonEncoders.Get().(*jsonEncoder)
+	defer func() {
+		if e.buf.Cap() <= maxPooledJSONBytes {
+			e.buf.Reset()
+			jsonEncoders.Put(e)
+		}
+	}()
+	if err := e.enc.Encode(v); err != nil {
+		return "", err
+	}
+	// Encode ends the JSON with a newline, which MarshalIndent doesn't
+	return string(bytes.TrimSuffix(e.buf.Bytes(), []byte("\n"))), nil
+}
@@ -0,0 +1,101 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		v    any
+	}{
+		{name: "order book", v: largeOrderBook(3)},
+		{name: "html characters", v: map[string]string{"description": "<b>Tom & Jerry</b>"}},
+		{name: "empty list", v: []luno.Order{}},
+		{name: "nil", v: nil},
+		{name: "string", v: "XBTZAR"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, err := json.MarshalIndent(tt.v, "", "  ")
+			require.NoError(t, err)
+			got, err := marshalJSON(tt.v)
+			require.NoError(t, err)
+			assert.Equal(t, string(want), got)
+		})
+	}
+
+	t.Run("unsupported value", func(t *testing.T) {
+		_, err := marshalJSON(map[string]any{"f": func() {}})
+		require.Error(t, err)
+		got, err := marshalJSON([]int{1})
+		require.NoError(t, err)
+		assert.Equal(t, "[\n  1\n]", got, "a failed call leaves nothing behind in the pool")
+	})
+}
+
+// largeOrderBook returns an order book with levels bids and asks
+func largeOrderBook(levels int) *luno.GetOrderBookResponse {
+	book := &luno.GetOrderBookResponse{Timestamp: testTimestamp}
+	for i := range levels {
+		book.Asks = append(book.Asks, luno.OrderBookEntry{Price: decimal.NewFromInt64(int64(800100 + i)), Volume: decimal.NewFromFloat64(0.125, 6)})
+		book.Bids = append(book.Bids, luno.OrderBookEntry{Price: decimal.NewFromInt64(int64(799900 - i)), Volume: decimal.NewFromFloat64(0.5, 6)})
+	}
+	return book
+}
+
+func BenchmarkMarshalJSON(b *testing.B) {
+	book := largeOrderBook(1000)
+	b.Run("marshalJSON", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := marshalJSON(book); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("MarshalIndent", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			data, err := json.MarshalIndent(book, "", "  ")
+			if err != nil {
+				b.Fatal(err)
+			}
+			_ = string(data)
+		}
+	})
+}
+
+// orderBookClient returns the same order book for every call
+type orderBookClient struct {
+	sdk.LunoClient
+	book *luno.GetOrderBookResponse
+}
+
+func (c orderBookClient) GetOrderBook(ctx context.Context, req *luno.GetOrderBookRequest) (*luno.GetOrderBookResponse, error) {
+	return c.book, nil
+}
+
+func BenchmarkGetOrderBook(b *testing.B) {
+	cfg := &config.Config{LunoClient: orderBookClient{book: largeOrderBook(1000)}}
+	handler := HandleGetOrderBook(cfg)
+	request := createMockRequest(map[string]any{"pair": "XBTZAR"})
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		result, err := handler(ctx, request)
+		if err != nil || result.IsError {
+			b.Fatal(err, result)
+		}
+	}
+}
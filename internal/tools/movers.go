@@ -0,0 +1,175 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// defaultMoversLimit is how many gainers and losers get_top_movers returns by default
+	defaultMoversLimit = 5
+	// maxMoversLimit bounds the limit parameter of get_top_movers
+	maxMoversLimit = 20
+	// moversCandleDuration is the candle size used to find the price 24 hours ago (1 hour)
+	moversCandleDuration = 3600
+)
+
+// Mover is a market's 24 hour price change in get_top_movers
+type Mover struct {
+	Pair             string  `json:"pair"`
+	LastTrade        string  `json:"last_trade"`
+	Open24h          string  `json:"open_24h"`
+	ChangePercent24h float64 `json:"change_percent_24h"`
+	Volume24h        string  `json:"volume_24h"`
+}
+
+// MoversResult is the result of the get_top_movers tool
+type MoversResult struct {
+	QuoteCurrency string  `json:"quote_currency"`
+	Gainers       []Mover `json:"gainers"`
+	Losers        []Mover `json:"losers"`
+	// MarketsCompared is how many markets had a price change to compare
+	MarketsCompared int `json:"markets_compared"`
+	// Skipped are markets in the quote currency that could not be compared
+	Skipped []PairError `json:"skipped,omitempty"`
+	PartialResult
+}
+
+// NewGetTopMoversTool creates a tool for finding the markets that moved most in 24 hours
+func NewGetTopMoversTool() mcp.Tool {
+	return mcp.NewTool(
+		GetTopMoversToolID,
+		mcp.WithDescription("Get the Luno markets in one quote currency with the largest price rises and falls over the last 24 hours, "+
+			"sorted by percentage change"),
+		mcp.WithString(
+			"quote_currency",
+			mcp.Required(),
+			mcp.Description("Quote (counter) currency of the markets to compare, e.g. ZAR or USDC"),
+		),
+		mcp.WithNumber(
+			"limit",
+			mcp.Description(fmt.Sprintf("Maximum number of gainers and of losers to return (default: %d, max: %d)", defaultMoversLimit, maxMoversLimit)),
+			mcp.Min(1),
+			mcp.Max(maxMoversLimit),
+		),
+	)
+}
+
+// moversArgs are the arguments of the get_top_movers tool
+type moversArgs struct {
+	QuoteCurrency string `json:"quote_currency"`
+	Limit         int    `json:"limit"`
+}
+
+// HandleGetTopMovers handles the get_top_movers tool
+func HandleGetTopMovers(cfg *config.Config) server.ToolHandlerFunc {
+	tool := NewGetTopMoversTool()
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		cfg := cfg.ForContext(ctx)
+		args := moversArgs{Limit: defaultMoversLimit}
+		if errResult := bindArguments(request, tool, &args); errResult != nil {
+			return errResult, nil
+		}
+		quote, limit := normalizeCurrency(args.QuoteCurrency), args.Limit
+
+		list, err := MarketCache(cfg).Markets(ctx)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("listing markets", err), nil
+		}
+		counters := make(map[string]string, len(list))
+		var quotes []string
+		for _, m := range list {
+			counters[m.MarketId] = m.CounterCurrency
+			if !slices.Contains(quotes, m.CounterCurrency) {
+				quotes = append(quotes, m.CounterCurrency)
+			}
+		}
+
+		tickers, err := cfg.LunoClient.GetTickers(ctx, &luno.GetTickersRequest{})
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("getting tickers", err), nil
+		}
+
+		result := MoversResult{QuoteCurrency: quote, Gainers: []Mover{}, Losers: []Mover{}}
+		var movers []Mover
+		since := luno.Time(time.Now().Add(-24 * time.Hour))
+		fan := newFanOut(ctx)
+		for _, t := range tickers.Tickers {
+			if counters[t.Pair] != quote {
+				continue
+			}
+			if t.LastTrade.Sign() <= 0 {
+				result.Skipped = append(result.Skipped, PairError{Pair: t.Pair, Error: "no trades"})
+				continue
+			}
+			if fan.expired() {
+				result.timeOut(t.Pair)
+				continue
+			}
+			candles, err := cfg.LunoClient.GetCandles(ctx, &luno.GetCandlesRequest{
+				Pair:     t.Pair,
+				Since:    since,
+				Duration: moversCandleDuration,
+			})
+			if err != nil && fan.timedOut(err) {
+				result.timeOut(t.Pair)
+				continue
+			}
+			if err != nil {
+				result.Skipped = append(result.Skipped, PairError{Pair: t.Pair, Error: fmt.Sprintf("getting candles: %v", err)})
+				continue
+			}
+			if len(candles.Candles) == 0 || candles.Candles[0].Open.Sign() <= 0 {
+				result.Skipped = append(result.Skipped, PairError{Pair: t.Pair, Error: "no trades in the last 24 hours"})
+				continue
+			}
+			open := candles.Candles[0].Open
+			movers = append(movers, Mover{
+				Pair:             t.Pair,
+				LastTrade:        t.LastTrade.String(),
+				Open24h:          open.String(),
+				ChangePercent24h: percentOf(t.LastTrade.Sub(open), open),
+				Volume24h:        t.Rolling24HourVolume.String(),
+			})
+		}
+		if len(movers) == 0 && len(result.Skipped) == 0 && !result.Partial {
+			slices.Sort(quotes)
+			return mcp.NewToolResultError(fmt.Sprintf("No Luno markets are quoted in %s. Quote currencies are: %s", quote, strings.Join(quotes, ", "))), nil
+		}
+
+		slices.SortStableFunc(movers, func(a, b Mover) int {
+			switch {
+			case a.ChangePercent24h > b.ChangePercent24h:
+				return -1
+			case a.ChangePercent24h < b.ChangePercent24h:
+				return 1
+			}
+			return strings.Compare(a.Pair, b.Pair)
+		})
+		result.MarketsCompared = len(movers)
+		for _, m := range movers {
+			if m.ChangePercent24h > 0 && len(result.Gainers) < limit {
+				result.Gainers = append(result.Gainers, m)
+			}
+		}
+		for _, m := range slices.Backward(movers) {
+			if m.ChangePercent24h < 0 && len(result.Losers) < limit {
+				result.Losers = append(result.Losers, m)
+			}
+		}
+
+		resultJSON, err := marshalJSON(result)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal top movers: %v", err)), nil
+		}
+		return mcp.NewToolResultText(resultJSON), nil
+	}
+}
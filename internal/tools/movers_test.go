@@ -0,0 +1,177 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleGetTopMovers(t *testing.T) {
+	ctx := context.Background()
+	markets := &luno.MarketsResponse{Markets: []luno.MarketInfo{
+		{MarketId: "XBTZAR", CounterCurrency: "ZAR"},
+		{MarketId: "ETHZAR", CounterCurrency: "ZAR"},
+		{MarketId: "SOLZAR", CounterCurrency: "ZAR"},
+		{MarketId: "XRPZAR", CounterCurrency: "ZAR"},
+		{MarketId: "LTCZAR", CounterCurrency: "ZAR"},
+		{MarketId: "XBTEUR", CounterCurrency: "EUR"},
+	}}
+	tickers := &luno.GetTickersResponse{Tickers: []luno.Ticker{
+		{Pair: "XBTZAR", LastTrade: NewFromString(t, "1100000"), Rolling24HourVolume: NewFromString(t, "12.5")},
+		{Pair: "ETHZAR", LastTrade: NewFromString(t, "45000"), Rolling24HourVolume: NewFromString(t, "80")},
+		{Pair: "SOLZAR", LastTrade: NewFromString(t, "3000"), Rolling24HourVolume: NewFromString(t, "400")},
+		{Pair: "XRPZAR", LastTrade: NewFromString(t, "10")},
+		{Pair: "LTCZAR", LastTrade: NewFromString(t, "0")},
+		{Pair: "XBTEUR", LastTrade: NewFromString(t, "60000")},
+	}}
+	opens := map[string]string{"XBTZAR": "1000000", "ETHZAR": "50000", "SOLZAR": "2900"}
+
+	tests := []struct {
+		name          string
+		args          map[string]any
+		mockSetup     func(*sdk.MockLunoClient)
+		expGainers    []string
+		expLosers     []string
+		expSkipped    []string
+		errorContains string
+	}{
+		{
+			name: "gainers and losers",
+			args: map[string]any{"quote_currency": "zar"},
+			mockSetup: func(c *sdk.MockLunoClient) {
+				c.EXPECT().Markets(ctx, &luno.MarketsRequest{}).Return(markets, nil)
+				c.EXPECT().GetTickers(ctx, &luno.GetTickersRequest{}).Return(tickers, nil)
+				for pair, open := range opens {
+					c.EXPECT().GetCandles(ctx, mock.MatchedBy(func(r *luno.GetCandlesRequest) bool { return r.Pair == pair })).
+						Return(&luno.GetCandlesResponse{Candles: []luno.Candle{{Open: NewFromString(t, open)}}}, nil)
+				}
+				c.EXPECT().GetCandles(ctx, mock.MatchedBy(func(r *luno.GetCandlesRequest) bool { return r.Pair == "XRPZAR" })).
+					Return(nil, errors.New(apiErrorStr))
+			},
+			expGainers: []string{"XBTZAR", "SOLZAR"},
+			expLosers:  []string{"ETHZAR"},
+			expSkipped: []string{"XRPZAR", "LTCZAR"},
+		},
+		{
+			name: "limit",
+			args: map[string]any{"quote_currency": "ZAR", "limit": 1},
+			mockSetup: func(c *sdk.MockLunoClient) {
+				c.EXPECT().Markets(ctx, &luno.MarketsRequest{}).Return(markets, nil)
+				c.EXPECT().GetTickers(ctx, &luno.GetTickersRequest{}).Return(tickers, nil)
+				for pair, open := range opens {
+					c.EXPECT().GetCandles(ctx, mock.MatchedBy(func(r *luno.GetCandlesRequest) bool { return r.Pair == pair })).
+						Return(&luno.GetCandlesResponse{Candles: []luno.Candle{{Open: NewFromString(t, open)}}}, nil)
+				}
+				c.EXPECT().GetCandles(ctx, mock.MatchedBy(func(r *luno.GetCandlesRequest) bool { return r.Pair == "XRPZAR" })).
+					Return(&luno.GetCandlesResponse{}, nil)
+			},
+			expGainers: []string{"XBTZAR"},
+			expLosers:  []string{"ETHZAR"},
+			expSkipped: []string{"XRPZAR", "LTCZAR"},
+		},
+		{
+			name: "unknown quote currency",
+			args: map[string]any{"quote_currency": "GBP"},
+			mockSetup: func(c *sdk.MockLunoClient) {
+				c.EXPECT().Markets(ctx, &luno.MarketsRequest{}).Return(markets, nil)
+				c.EXPECT().GetTickers(ctx, &luno.GetTickersRequest{}).Return(tickers, nil)
+			},
+			errorContains: "No Luno markets are quoted in GBP. Quote currencies are: EUR, ZAR",
+		},
+		{
+			name: "tickers fail",
+			args: map[string]any{"quote_currency": "ZAR"},
+			mockSetup: func(c *sdk.MockLunoClient) {
+				c.EXPECT().Markets(ctx, &luno.MarketsRequest{}).Return(markets, nil)
+				c.EXPECT().GetTickers(ctx, &luno.GetTickersRequest{}).Return(nil, errors.New(apiErrorStr))
+			},
+			errorContains: "getting tickers",
+		},
+		{
+			name:          "missing quote currency",
+			args:          map[string]any{},
+			errorContains: "quote_currency is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := sdk.NewMockLunoClient(t)
+			if tt.mockSetup != nil {
+				tt.mockSetup(mockClient)
+			}
+			cfg := &config.Config{LunoClient: mockClient}
+
+			result, err := HandleGetTopMovers(cfg)(ctx, createMockRequest(tt.args))
+			require.NoError(t, err)
+			text := getTextContentFromResult(t, result)
+			if tt.errorContains != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, text, tt.errorContains)
+				return
+			}
+			require.False(t, result.IsError, text)
+
+			var got MoversResult
+			require.NoError(t, json.Unmarshal([]byte(text), &got))
+			assert.Equal(t, "ZAR", got.QuoteCurrency)
+			assert.Equal(t, 3, got.MarketsCompared)
+			assert.Equal(t, tt.expGainers, moverPairs(got.Gainers))
+			assert.Equal(t, tt.expLosers, moverPairs(got.Losers))
+			var skipped []string
+			for _, s := range got.Skipped {
+				skipped = append(skipped, s.Pair)
+			}
+			assert.ElementsMatch(t, tt.expSkipped, skipped)
+			assert.InDelta(t, 10.0, got.Gainers[0].ChangePercent24h, 1e-9)
+			assert.InDelta(t, -10.0, got.Losers[0].ChangePercent24h, 1e-9)
+		})
+	}
+}
+
+func TestHandleGetTopMoversPartial(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), fanOutMargin+500*time.Millisecond)
+	defer cancel()
+	mockClient := sdk.NewMockLunoClient(t)
+	mockClient.EXPECT().Markets(ctx, &luno.MarketsRequest{}).Return(&luno.MarketsResponse{Markets: []luno.MarketInfo{
+		{MarketId: "XBTZAR", CounterCurrency: "ZAR"},
+		{MarketId: "ETHZAR", CounterCurrency: "ZAR"},
+		{MarketId: "SOLZAR", CounterCurrency: "ZAR"},
+	}}, nil)
+	mockClient.EXPECT().GetTickers(ctx, &luno.GetTickersRequest{}).Return(&luno.GetTickersResponse{Tickers: []luno.Ticker{
+		{Pair: "XBTZAR", LastTrade: NewFromString(t, "1100000")},
+		{Pair: "ETHZAR", LastTrade: NewFromString(t, "45000")},
+		{Pair: "SOLZAR", LastTrade: NewFromString(t, "3000")},
+	}}, nil)
+	mockClient.EXPECT().GetCandles(ctx, mock.MatchedBy(func(r *luno.GetCandlesRequest) bool { return r.Pair == "XBTZAR" })).
+		Run(func(context.Context, *luno.GetCandlesRequest) { time.Sleep(600 * time.Millisecond) }).
+		Return(&luno.GetCandlesResponse{Candles: []luno.Candle{{Open: NewFromString(t, "1000000")}}}, nil)
+
+	result, err := HandleGetTopMovers(&config.Config{LunoClient: mockClient})(ctx, createMockRequest(map[string]any{"quote_currency": "ZAR"}))
+	require.NoError(t, err)
+	text := getTextContentFromResult(t, result)
+	require.False(t, result.IsError, text)
+
+	var got MoversResult
+	require.NoError(t, json.Unmarshal([]byte(text), &got))
+	assert.True(t, got.Partial)
+	assert.Equal(t, []string{"ETHZAR", "SOLZAR"}, got.TimedOut)
+	assert.Equal(t, []string{"XBTZAR"}, moverPairs(got.Gainers))
+}
+
+func moverPairs(movers []Mover) []string {
+	pairs := make([]string, 0, len(movers))
+	for _, m := range movers {
+		pairs = append(pairs, m.Pair)
+	}
+	return pairs
+}
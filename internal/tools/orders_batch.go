@@ -0,0 +1,288 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/markets"
+	"github.com/luno/luno-mcp/internal/orders"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// maxBatchOrders bounds how many orders create_orders_batch places at once
+const maxBatchOrders = 20
+
+// Outcomes of a create_orders_batch call
+const (
+	BatchStatusPreview        = "preview"
+	BatchStatusInvalid        = "invalid"
+	BatchStatusPlaced         = "placed"
+	BatchStatusRolledBack     = "rolled_back"
+	BatchStatusRollbackFailed = "rollback_incomplete"
+)
+
+// Outcomes of each order of a create_orders_batch call
+const (
+	BatchOrderStatusValid      = "valid"
+	BatchOrderStatusInvalid    = "invalid"
+	BatchOrderStatusPlaced     = "placed"
+	BatchOrderStatusFailed     = "failed"
+	BatchOrderStatusNotPlaced  = "not_placed"
+	BatchOrderStatusCancelled  = "cancelled"
+	BatchOrderStatusCancelFail = "cancel_failed"
+)
+
+// BatchOrderReport is what happened to one order of a batch
+type BatchOrderReport struct {
+	BatchOrder
+	// Notional is volume times price, in the pair's counter currency
+	Notional string `json:"notional,omitempty"`
+	Status   string `json:"status"`
+	OrderID  string `json:"order_id,omitempty"`
+	// FilledVolume is how much of a rolled back order traded before it was cancelled
+	FilledVolume string `json:"filled_volume,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+// CreateOrdersBatchResult reports exactly what a create_orders_batch call did
+type CreateOrdersBatchResult struct {
+	Confirmed bool               `json:"confirmed"`
+	Status    string             `json:"status"`
+	Orders    []BatchOrderReport `json:"orders"`
+	Message   string             `json:"message"`
+}
+
+// batchOrder is a checked order of a batch
+type batchOrder struct {
+	pair     string
+	side     luno.OrderType
+	volume   decimal.Decimal
+	price    decimal.Decimal
+	postOnly bool
+}
+
+// NewCreateOrdersBatchTool creates a tool for placing several limit orders as one
+func NewCreateOrdersBatchTool() mcp.Tool {
+	return mcp.NewTool(
+		CreateOrdersBatchToolID,
+		mcp.WithDescription("Place several limit orders as one: either all of them are placed or none are left open. "+
+			"Without confirm=true this only checks the orders and returns a preview; show it to the user and call again with confirm=true to place them. "+
+			"Orders are placed one at a time, and if one is rejected the orders already placed are cancelled. "+
+			"Cancelling cannot undo trades, so the report gives the volume each cancelled order filled. "+
+			"The orders of a quote_basket result can be passed as they are."+writeOperationNotice),
+		mcp.WithString(
+			"orders",
+			mcp.Required(),
+			mcp.Description(fmt.Sprintf(`JSON array of orders, each {"pair": "XBTZAR", "type": "BUY" or "SELL", "volume": "0.001", "price": "1000000"} with volume and price as decimal strings (at most %d)`, maxBatchOrders)),
+		),
+		mcp.WithBoolean(
+			"confirm",
+			mcp.Description("Set to true to place the orders after the user has reviewed the preview (default: false)"),
+		),
+		withAllowFarFromMarket(),
+	)
+}
+
+// ordersBatchArgs are the arguments of the create_orders_batch tool
+type ordersBatchArgs struct {
+	Orders             string `json:"orders"`
+	Confirm            bool   `json:"confirm"`
+	AllowFarFromMarket bool   `json:"allow_far_from_market"`
+}
+
+// HandleCreateOrdersBatch handles the create_orders_batch tool
+func HandleCreateOrdersBatch(cfg *config.Config) server.ToolHandlerFunc {
+	tool := NewCreateOrdersBatchTool()
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		cfg := cfg.ForContext(ctx)
+		if !cfg.IsAuthenticated {
+			return mcp.NewToolResultError(ErrAPICredentialsRequired), nil
+		}
+
+		var args ordersBatchArgs
+		if errResult := bindArguments(request, tool, &args); errResult != nil {
+			return errResult, nil
+		}
+		var requested []BatchOrder
+		if err := json.Unmarshal([]byte(args.Orders), &requested); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("orders must be a JSON array of orders: %v", err)), nil
+		}
+		if len(requested) == 0 || len(requested) > maxBatchOrders {
+			return mcp.NewToolResultError(fmt.Sprintf("give between 1 and %d orders", maxBatchOrders)), nil
+		}
+
+		result := CreateOrdersBatchResult{Status: BatchStatusPreview}
+		batch := make([]batchOrder, len(requested))
+		for i, o := range requested {
+			report, order, err := checkBatchOrder(ctx, cfg, o, args.AllowFarFromMarket)
+			if err != nil {
+				report.Status = BatchOrderStatusInvalid
+				report.Error = err.Error()
+				result.Status = BatchStatusInvalid
+			}
+			result.Orders = append(result.Orders, report)
+			batch[i] = order
+		}
+		if result.Status == BatchStatusInvalid {
+			result.Message = "No orders were placed because some of them are invalid. Fix them and preview the batch again."
+			return batchResult(result, true)
+		}
+		if !args.Confirm {
+			result.Message = "No orders were placed. Review the preview with the user, then call again with confirm=true to place them."
+			return batchResult(result, false)
+		}
+
+		result.Confirmed = true
+		for i, o := range batch {
+			slog.InfoContext(ctx, "Placing batch order",
+				"index", i,
+				"pair", o.pair,
+				"type", o.side,
+				"volume", o.volume.String(),
+				"price", o.price.String())
+
+			placed, err := cfg.LunoClient.PostLimitOrder(ctx, &luno.PostLimitOrderRequest{
+				Pair:     o.pair,
+				Type:     o.side,
+				Volume:   o.volume,
+				Price:    o.price,
+				PostOnly: o.postOnly,
+			})
+			if err != nil {
+				result.Orders[i].Status = BatchOrderStatusFailed
+				result.Orders[i].Error = err.Error()
+				for j := i + 1; j < len(batch); j++ {
+					result.Orders[j].Status = BatchOrderStatusNotPlaced
+				}
+				rollbackBatch(ctx, cfg, &result, i)
+				return batchResult(result, true)
+			}
+			result.Orders[i].Status = BatchOrderStatusPlaced
+			result.Orders[i].OrderID = placed.OrderId
+			trackOrder(ctx, cfg, CreateOrdersBatchToolID, orders.Order{
+				OrderID: placed.OrderId,
+				Pair:    o.pair,
+				Type:    o.side,
+				Volume:  o.volume,
+				Price:   o.price,
+			})
+		}
+
+		result.Status = BatchStatusPlaced
+		result.Message = fmt.Sprintf("All %d orders were placed.", len(batch))
+		return batchResult(result, false)
+	}
+}
+
+// checkBatchOrder parses o and checks it against its market and, unless
+// allowFar is set, its market price, returning its preview report
+func checkBatchOrder(ctx context.Context, cfg *config.Config, o BatchOrder, allowFar bool) (BatchOrderReport, batchOrder, error) {
+	o.Pair = normalizeCurrencyPair(o.Pair)
+	o.Type = strings.ToUpper(strings.TrimSpace(o.Type))
+	report := BatchOrderReport{BatchOrder: o, Status: BatchOrderStatusValid}
+	order := batchOrder{pair: o.Pair}
+	if !pairAllowed(cfg, o.Pair) {
+		return report, order, pairNotAllowedError(cfg, o.Pair)
+	}
+
+	switch o.Type {
+	case "BUY":
+		order.side = luno.OrderTypeBid
+	case "SELL":
+		order.side = luno.OrderTypeAsk
+	default:
+		return report, order, errors.New("order type must be 'BUY' or 'SELL'")
+	}
+	var err error
+	if order.volume, err = decimal.NewFromString(o.Volume); err != nil || order.volume.Sign() <= 0 {
+		return report, order, fmt.Errorf("invalid volume %q", o.Volume)
+	}
+	if order.price, err = decimal.NewFromString(o.Price); err != nil || order.price.Sign() <= 0 {
+		return report, order, fmt.Errorf("invalid price %q", o.Price)
+	}
+	report.Notional = order.volume.Mul(order.price).String()
+
+	// Luno validates the orders anyway, so carry on if the markets can't be listed
+	market, ok, err := MarketCache(cfg).Lookup(ctx, o.Pair)
+	switch {
+	case err != nil:
+		slog.WarnContext(ctx, "Failed to look up market for batch order validation", "pair", o.Pair, "error", err)
+	case !ok:
+		return report, order, fmt.Errorf("%s: %s", o.Pair, unknownMarketError)
+	default:
+		if err := markets.CheckOrder(market, order.volume, order.price); err != nil {
+			return report, order, err
+		}
+		order.postOnly = market.TradingStatus == luno.TradingStatusPost_only
+	}
+	if err := checkPriceDeviation(ctx, cfg, o.Pair, order.price, allowFar); err != nil {
+		return report, order, err
+	}
+	return report, order, nil
+}
+
+// rollbackBatch cancels the orders placed before the order at failed, and
+// records how much of each traded before it was cancelled
+func rollbackBatch(ctx context.Context, cfg *config.Config, result *CreateOrdersBatchResult, failed int) {
+	// Finish the rollback even if the request is cancelled, so that orders
+	// aren't left open
+	ctx = context.WithoutCancel(ctx)
+	result.Status = BatchStatusRolledBack
+	var filled []string
+	for i := range failed {
+		report := &result.Orders[i]
+		if _, err := cfg.LunoClient.StopOrder(ctx, &luno.StopOrderRequest{OrderId: report.OrderID}); err != nil {
+			slog.ErrorContext(ctx, "Failed to cancel batch order during rollback", "order_id", report.OrderID, "error", err)
+			report.Status = BatchOrderStatusCancelFail
+			report.Error = err.Error()
+			result.Status = BatchStatusRollbackFailed
+			continue
+		}
+		report.Status = BatchOrderStatusCancelled
+		order, err := cfg.LunoClient.GetOrder(ctx, &luno.GetOrderRequest{Id: report.OrderID})
+		if err != nil {
+			report.Error = fmt.Sprintf("cancelled, but its fill could not be read: %v", err)
+			continue
+		}
+		report.FilledVolume = order.Base.String()
+		if order.Base.Sign() > 0 {
+			filled = append(filled, report.OrderID)
+		}
+	}
+
+	failedOrder := result.Orders[failed]
+	if result.Status == BatchStatusRollbackFailed {
+		result.Message = fmt.Sprintf("Order %d (%s) was rejected: %s. Some of the orders already placed could not be cancelled and are still open; "+
+			"cancel them with cancel_order.", failed+1, failedOrder.Pair, failedOrder.Error)
+		return
+	}
+	result.Message = fmt.Sprintf("Order %d (%s) was rejected: %s. ", failed+1, failedOrder.Pair, failedOrder.Error)
+	if failed == 0 {
+		result.Message += "No orders were placed."
+		return
+	}
+	result.Message += "The orders already placed were cancelled."
+	if len(filled) > 0 {
+		result.Message += fmt.Sprintf(" Some traded before they were cancelled (%s); see filled_volume.", strings.Join(filled, ", "))
+	}
+}
+
+// batchResult renders the result, marking it as an error when the batch was not placed
+func batchResult(result CreateOrdersBatchResult, isError bool) (*mcp.CallToolResult, error) {
+	resultJSON, err := marshalJSON(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	if isError {
+		return mcp.NewToolResultError(resultJSON), nil
+	}
+	return mcp.NewToolResultText(resultJSON), nil
+}
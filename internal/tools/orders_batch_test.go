@@ -0,0 +1,200 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/markets"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleCreateOrdersBatch(t *testing.T) {
+	ctx := context.Background()
+	listed := &luno.MarketsResponse{Markets: []luno.MarketInfo{
+		{MarketId: "XBTZAR", VolumeScale: 4, PriceScale: 0, MinVolume: NewFromString(t, "0.0005")},
+		{MarketId: "ETHZAR", VolumeScale: 4, PriceScale: 0, TradingStatus: luno.TradingStatusPost_only},
+		{MarketId: "SOLZAR", VolumeScale: 2, PriceScale: 0, TradingStatus: luno.TradingStatusSuspended},
+	}}
+	threeOrders := `[
+		{"pair": "XBTZAR", "type": "BUY", "volume": "0.001", "price": "1000000"},
+		{"pair": "eth-zar", "type": "sell", "volume": "0.5", "price": "50000"},
+		{"pair": "XBTZAR", "type": "SELL", "volume": "0.002", "price": "1100000"}
+	]`
+	xbtBuy := &luno.PostLimitOrderRequest{Pair: "XBTZAR", Type: luno.OrderTypeBid, Volume: NewFromString(t, "0.001"), Price: NewFromString(t, "1000000")}
+	ethSell := &luno.PostLimitOrderRequest{Pair: "ETHZAR", Type: luno.OrderTypeAsk, Volume: NewFromString(t, "0.5"), Price: NewFromString(t, "50000"), PostOnly: true}
+	xbtSell := &luno.PostLimitOrderRequest{Pair: "XBTZAR", Type: luno.OrderTypeAsk, Volume: NewFromString(t, "0.002"), Price: NewFromString(t, "1100000")}
+
+	tests := []struct {
+		name            string
+		args            map[string]any
+		isAuthenticated bool
+		mockSetup       func(*sdk.MockLunoClient)
+		expIsError      bool
+		expStatus       string
+		expOrders       []BatchOrderReport
+		expMessage      string
+		errorContains   string
+	}{
+		{
+			name:            "preview",
+			args:            map[string]any{"orders": threeOrders},
+			isAuthenticated: true,
+			expStatus:       BatchStatusPreview,
+			expOrders: []BatchOrderReport{
+				{BatchOrder: BatchOrder{Pair: "XBTZAR", Type: "BUY", Volume: "0.001", Price: "1000000"}, Notional: "1000.000", Status: BatchOrderStatusValid},
+				{BatchOrder: BatchOrder{Pair: "ETHZAR", Type: "SELL", Volume: "0.5", Price: "50000"}, Notional: "25000.0", Status: BatchOrderStatusValid},
+				{BatchOrder: BatchOrder{Pair: "XBTZAR", Type: "SELL", Volume: "0.002", Price: "1100000"}, Notional: "2200.000", Status: BatchOrderStatusValid},
+			},
+			expMessage: "call again with confirm=true",
+		},
+		{
+			name:            "all placed",
+			args:            map[string]any{"orders": threeOrders, "confirm": true},
+			isAuthenticated: true,
+			mockSetup: func(c *sdk.MockLunoClient) {
+				c.EXPECT().PostLimitOrder(ctx, xbtBuy).Return(&luno.PostLimitOrderResponse{OrderId: "BX1"}, nil).Once()
+				c.EXPECT().PostLimitOrder(ctx, ethSell).Return(&luno.PostLimitOrderResponse{OrderId: "BX2"}, nil).Once()
+				c.EXPECT().PostLimitOrder(ctx, xbtSell).Return(&luno.PostLimitOrderResponse{OrderId: "BX3"}, nil).Once()
+			},
+			expStatus: BatchStatusPlaced,
+			expOrders: []BatchOrderReport{
+				{BatchOrder: BatchOrder{Pair: "XBTZAR", Type: "BUY", Volume: "0.001", Price: "1000000"}, Notional: "1000.000", Status: BatchOrderStatusPlaced, OrderID: "BX1"},
+				{BatchOrder: BatchOrder{Pair: "ETHZAR", Type: "SELL", Volume: "0.5", Price: "50000"}, Notional: "25000.0", Status: BatchOrderStatusPlaced, OrderID: "BX2"},
+				{BatchOrder: BatchOrder{Pair: "XBTZAR", Type: "SELL", Volume: "0.002", Price: "1100000"}, Notional: "2200.000", Status: BatchOrderStatusPlaced, OrderID: "BX3"},
+			},
+			expMessage: "All 3 orders were placed.",
+		},
+		{
+			name:            "rejected order rolls back those placed",
+			args:            map[string]any{"orders": threeOrders, "confirm": true},
+			isAuthenticated: true,
+			mockSetup: func(c *sdk.MockLunoClient) {
+				c.EXPECT().PostLimitOrder(ctx, xbtBuy).Return(&luno.PostLimitOrderResponse{OrderId: "BX1"}, nil).Once()
+				c.EXPECT().PostLimitOrder(ctx, ethSell).Return(nil, errors.New("insufficient balance")).Once()
+				c.EXPECT().StopOrder(mock.Anything, &luno.StopOrderRequest{OrderId: "BX1"}).Return(&luno.StopOrderResponse{Success: true}, nil).Once()
+				c.EXPECT().GetOrder(mock.Anything, &luno.GetOrderRequest{Id: "BX1"}).
+					Return(&luno.GetOrderResponse{OrderId: "BX1", Base: NewFromString(t, "0.0004")}, nil).Once()
+			},
+			expIsError: true,
+			expStatus:  BatchStatusRolledBack,
+			expOrders: []BatchOrderReport{
+				{BatchOrder: BatchOrder{Pair: "XBTZAR", Type: "BUY", Volume: "0.001", Price: "1000000"}, Notional: "1000.000", Status: BatchOrderStatusCancelled, OrderID: "BX1", FilledVolume: "0.0004"},
+				{BatchOrder: BatchOrder{Pair: "ETHZAR", Type: "SELL", Volume: "0.5", Price: "50000"}, Notional: "25000.0", Status: BatchOrderStatusFailed, Error: "insufficient balance"},
+				{BatchOrder: BatchOrder{Pair: "XBTZAR", Type: "SELL", Volume: "0.002", Price: "1100000"}, Notional: "2200.000", Status: BatchOrderStatusNotPlaced},
+			},
+			expMessage: "Order 2 (ETHZAR) was rejected: insufficient balance. The orders already placed were cancelled. Some traded before they were cancelled (BX1); see filled_volume.",
+		},
+		{
+			name:            "first order rejected",
+			args:            map[string]any{"orders": threeOrders, "confirm": true},
+			isAuthenticated: true,
+			mockSetup: func(c *sdk.MockLunoClient) {
+				c.EXPECT().PostLimitOrder(ctx, xbtBuy).Return(nil, errors.New(apiErrorStr)).Once()
+			},
+			expIsError: true,
+			expStatus:  BatchStatusRolledBack,
+			expOrders: []BatchOrderReport{
+				{BatchOrder: BatchOrder{Pair: "XBTZAR", Type: "BUY", Volume: "0.001", Price: "1000000"}, Notional: "1000.000", Status: BatchOrderStatusFailed, Error: apiErrorStr},
+				{BatchOrder: BatchOrder{Pair: "ETHZAR", Type: "SELL", Volume: "0.5", Price: "50000"}, Notional: "25000.0", Status: BatchOrderStatusNotPlaced},
+				{BatchOrder: BatchOrder{Pair: "XBTZAR", Type: "SELL", Volume: "0.002", Price: "1100000"}, Notional: "2200.000", Status: BatchOrderStatusNotPlaced},
+			},
+			expMessage: "No orders were placed.",
+		},
+		{
+			name:            "rollback that cannot cancel",
+			args:            map[string]any{"orders": threeOrders, "confirm": true},
+			isAuthenticated: true,
+			mockSetup: func(c *sdk.MockLunoClient) {
+				c.EXPECT().PostLimitOrder(ctx, xbtBuy).Return(&luno.PostLimitOrderResponse{OrderId: "BX1"}, nil).Once()
+				c.EXPECT().PostLimitOrder(ctx, ethSell).Return(&luno.PostLimitOrderResponse{OrderId: "BX2"}, nil).Once()
+				c.EXPECT().PostLimitOrder(ctx, xbtSell).Return(nil, errors.New("rate limited")).Once()
+				c.EXPECT().StopOrder(mock.Anything, &luno.StopOrderRequest{OrderId: "BX1"}).Return(&luno.StopOrderResponse{Success: true}, nil).Once()
+				c.EXPECT().GetOrder(mock.Anything, &luno.GetOrderRequest{Id: "BX1"}).
+					Return(&luno.GetOrderResponse{OrderId: "BX1", Base: NewFromString(t, "0")}, nil).Once()
+				c.EXPECT().StopOrder(mock.Anything, &luno.StopOrderRequest{OrderId: "BX2"}).Return(nil, errors.New(apiErrorStr)).Once()
+			},
+			expIsError: true,
+			expStatus:  BatchStatusRollbackFailed,
+			expOrders: []BatchOrderReport{
+				{BatchOrder: BatchOrder{Pair: "XBTZAR", Type: "BUY", Volume: "0.001", Price: "1000000"}, Notional: "1000.000", Status: BatchOrderStatusCancelled, OrderID: "BX1", FilledVolume: "0"},
+				{BatchOrder: BatchOrder{Pair: "ETHZAR", Type: "SELL", Volume: "0.5", Price: "50000"}, Notional: "25000.0", Status: BatchOrderStatusCancelFail, OrderID: "BX2", Error: apiErrorStr},
+				{BatchOrder: BatchOrder{Pair: "XBTZAR", Type: "SELL", Volume: "0.002", Price: "1100000"}, Notional: "2200.000", Status: BatchOrderStatusFailed, Error: "rate limited"},
+			},
+			expMessage: "could not be cancelled and are still open",
+		},
+		{
+			name: "invalid orders are not placed",
+			args: map[string]any{"orders": `[
+				{"pair": "XBTZAR", "type": "BUY", "volume": "0.0001", "price": "1000000"},
+				{"pair": "SOLZAR", "type": "BUY", "volume": "1", "price": "3000"},
+				{"pair": "ABCZAR", "type": "BUY", "volume": "1", "price": "1"},
+				{"pair": "XBTZAR", "type": "HOLD", "volume": "1", "price": "1"},
+				{"pair": "XBTZAR", "type": "BUY", "volume": "lots", "price": "1"},
+				{"pair": "XBTZAR", "type": "BUY", "volume": "0.001", "price": "1000000"}
+			]`, "confirm": true},
+			isAuthenticated: true,
+			expIsError:      true,
+			expStatus:       BatchStatusInvalid,
+			expOrders: []BatchOrderReport{
+				{BatchOrder: BatchOrder{Pair: "XBTZAR", Type: "BUY", Volume: "0.0001", Price: "1000000"}, Notional: "100.0000", Status: BatchOrderStatusInvalid, Error: "volume 0.0001 is below the minimum of 0.0005 for XBTZAR"},
+				{BatchOrder: BatchOrder{Pair: "SOLZAR", Type: "BUY", Volume: "1", Price: "3000"}, Notional: "3000", Status: BatchOrderStatusInvalid, Error: "trading on SOLZAR is suspended and new orders are rejected until it resumes, use get_exchange_status to check market status"},
+				{BatchOrder: BatchOrder{Pair: "ABCZAR", Type: "BUY", Volume: "1", Price: "1"}, Notional: "1", Status: BatchOrderStatusInvalid, Error: "ABCZAR: " + unknownMarketError},
+				{BatchOrder: BatchOrder{Pair: "XBTZAR", Type: "HOLD", Volume: "1", Price: "1"}, Status: BatchOrderStatusInvalid, Error: "order type must be 'BUY' or 'SELL'"},
+				{BatchOrder: BatchOrder{Pair: "XBTZAR", Type: "BUY", Volume: "lots", Price: "1"}, Status: BatchOrderStatusInvalid, Error: `invalid volume "lots"`},
+				{BatchOrder: BatchOrder{Pair: "XBTZAR", Type: "BUY", Volume: "0.001", Price: "1000000"}, Notional: "1000.000", Status: BatchOrderStatusValid},
+			},
+			expMessage: "No orders were placed because some of them are invalid",
+		},
+		{
+			name:          "not authenticated",
+			args:          map[string]any{"orders": threeOrders},
+			errorContains: ErrAPICredentialsRequired,
+		},
+		{
+			name:            "orders not JSON",
+			args:            map[string]any{"orders": "XBTZAR BUY 0.001"},
+			isAuthenticated: true,
+			errorContains:   "orders must be a JSON array of orders",
+		},
+		{
+			name:            "no orders",
+			args:            map[string]any{"orders": "[]"},
+			isAuthenticated: true,
+			errorContains:   "give between 1 and 20 orders",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := sdk.NewMockLunoClient(t)
+			cfg := &config.Config{LunoClient: mockClient, IsAuthenticated: tt.isAuthenticated, Markets: markets.NewCache(mockClient)}
+			mockClient.EXPECT().Markets(ctx, &luno.MarketsRequest{}).Return(listed, nil).Maybe()
+			if tt.mockSetup != nil {
+				tt.mockSetup(mockClient)
+			}
+
+			result, err := HandleCreateOrdersBatch(cfg)(ctx, createMockRequest(tt.args))
+			require.NoError(t, err)
+			text := getTextContentFromResult(t, result)
+			if tt.errorContains != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, text, tt.errorContains)
+				return
+			}
+			assert.Equal(t, tt.expIsError, result.IsError, text)
+
+			var got CreateOrdersBatchResult
+			require.NoError(t, json.Unmarshal([]byte(text), &got))
+			assert.Equal(t, tt.expStatus, got.Status)
+			assert.Equal(t, tt.expOrders, got.Orders)
+			assert.Contains(t, got.Message, tt.expMessage)
+		})
+	}
+}
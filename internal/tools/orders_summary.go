@@ -0,0 +1,116 @@
+package tools
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/internal/journal"
+	"github.com/luno/luno-mcp/sdk"
+)
+
+// OrderView is an order returned by list_orders. Open orders include how far their
+// limit price is from the current market.
+type OrderView struct {
+	luno.Order
+	// RemainingVolume is the unfilled base volume of an open order
+	RemainingVolume decimal.Decimal `json:"remaining_volume,omitzero"`
+	// MarketPrice is the best price on the other side of the book: the ask for bids, the bid for asks
+	MarketPrice decimal.Decimal `json:"market_price,omitzero"`
+	// DistancePercent is the limit price's distance from MarketPrice, negative when below it
+	DistancePercent *float64 `json:"distance_percent,omitempty"`
+	// Notes are the trade journal notes written about the order
+	Notes []journal.Entry `json:"notes,omitempty"`
+}
+
+// OrdersSummary totals the open orders in a list_orders result
+type OrdersSummary struct {
+	Orders     int                 `json:"orders"`
+	OpenOrders int                 `json:"open_orders"`
+	Pairs      []PairOrdersSummary `json:"pairs,omitempty"`
+}
+
+// PairOrdersSummary totals the open orders for one pair
+type PairOrdersSummary struct {
+	Pair       string `json:"pair"`
+	OpenOrders int    `json:"open_orders"`
+	// BaseVolume is the unfilled base volume across open orders
+	BaseVolume decimal.Decimal `json:"base_volume"`
+	// CounterValue is the unfilled volume valued at each order's limit price
+	CounterValue decimal.Decimal `json:"counter_value"`
+}
+
+// summarizeOrders builds the views and summary for a list of orders. Market prices
+// are looked up for pairs with open orders; if that fails the distances are omitted.
+func summarizeOrders(ctx context.Context, client sdk.LunoClient, orders []luno.Order) ([]OrderView, OrdersSummary) {
+	views := make([]OrderView, len(orders))
+	summary := OrdersSummary{Orders: len(orders)}
+
+	var openPairs []string
+	for _, o := range orders {
+		if o.State == luno.OrderStatePending && !slices.Contains(openPairs, o.Pair) {
+			openPairs = append(openPairs, o.Pair)
+		}
+	}
+	slices.Sort(openPairs)
+	tickers := openPairTickers(ctx, client, openPairs)
+
+	totals := make(map[string]*PairOrdersSummary, len(openPairs))
+	for _, pair := range openPairs {
+		totals[pair] = &PairOrdersSummary{Pair: pair, BaseVolume: decimal.Zero(), CounterValue: decimal.Zero()}
+	}
+
+	for i, o := range orders {
+		views[i] = OrderView{Order: o}
+		if o.State != luno.OrderStatePending {
+			continue
+		}
+		summary.OpenOrders++
+
+		remaining := o.LimitVolume.Sub(o.Base)
+		views[i].RemainingVolume = remaining
+
+		t := totals[o.Pair]
+		t.OpenOrders++
+		t.BaseVolume = t.BaseVolume.Add(remaining)
+		t.CounterValue = t.CounterValue.Add(remaining.Mul(o.LimitPrice))
+
+		ticker, ok := tickers[o.Pair]
+		if !ok {
+			continue
+		}
+		market := ticker.Bid
+		if o.Type == luno.OrderTypeBid {
+			market = ticker.Ask
+		}
+		if market.Sign() > 0 {
+			distance := percentOf(o.LimitPrice.Sub(market), market)
+			views[i].MarketPrice = market
+			views[i].DistancePercent = &distance
+		}
+	}
+
+	for _, pair := range openPairs {
+		summary.Pairs = append(summary.Pairs, *totals[pair])
+	}
+	return views, summary
+}
+
+// openPairTickers returns the current tickers for pairs, keyed by pair
+func openPairTickers(ctx context.Context, client sdk.LunoClient, pairs []string) map[string]luno.Ticker {
+	if len(pairs) == 0 {
+		return nil
+	}
+	res, err := client.GetTickers(ctx, &luno.GetTickersRequest{Pair: pairs})
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to get tickers for open orders", "error", err)
+		return nil
+	}
+	tickers := make(map[string]luno.Ticker, len(res.Tickers))
+	for _, t := range res.Tickers {
+		tickers[t.Pair] = t
+	}
+	return tickers
+}
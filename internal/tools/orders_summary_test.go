@@ -0,0 +1,116 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarizeOrders(t *testing.T) {
+	orders := []luno.Order{
+		{
+			OrderId:     "bid",
+			Pair:        "XBTZAR",
+			Type:        luno.OrderTypeBid,
+			State:       luno.OrderStatePending,
+			LimitPrice:  NewFromString(t, "900000"),
+			LimitVolume: NewFromString(t, "0.1"),
+			Base:        NewFromString(t, "0.04"),
+		},
+		{
+			OrderId:     "ask",
+			Pair:        "XBTZAR",
+			Type:        luno.OrderTypeAsk,
+			State:       luno.OrderStatePending,
+			LimitPrice:  NewFromString(t, "1100000"),
+			LimitVolume: NewFromString(t, "0.02"),
+			Base:        NewFromString(t, "0"),
+		},
+		{
+			OrderId:     "eth",
+			Pair:        "ETHZAR",
+			Type:        luno.OrderTypeAsk,
+			State:       luno.OrderStatePending,
+			LimitPrice:  NewFromString(t, "50000"),
+			LimitVolume: NewFromString(t, "1"),
+			Base:        NewFromString(t, "0"),
+		},
+		{
+			OrderId:     "done",
+			Pair:        "XBTZAR",
+			Type:        luno.OrderTypeBid,
+			State:       luno.OrderStateComplete,
+			LimitPrice:  NewFromString(t, "800000"),
+			LimitVolume: NewFromString(t, "1"),
+			Base:        NewFromString(t, "1"),
+		},
+	}
+
+	tests := []struct {
+		name            string
+		tickersErr      error
+		expectDistances bool
+	}{
+		{
+			name:            "open orders include market distance",
+			expectDistances: true,
+		},
+		{
+			name:       "ticker failure omits market distance",
+			tickersErr: errors.New(apiErrorStr),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client := sdk.NewMockLunoClient(t)
+			var res *luno.GetTickersResponse
+			if tc.tickersErr == nil {
+				res = &luno.GetTickersResponse{Tickers: []luno.Ticker{
+					{Pair: "ETHZAR", Bid: NewFromString(t, "40000"), Ask: NewFromString(t, "40100")},
+					{Pair: "XBTZAR", Bid: NewFromString(t, "990000"), Ask: NewFromString(t, "1000000")},
+				}}
+			}
+			client.EXPECT().GetTickers(context.Background(), &luno.GetTickersRequest{Pair: []string{"ETHZAR", "XBTZAR"}}).
+				Return(res, tc.tickersErr)
+
+			views, summary := summarizeOrders(context.Background(), client, orders)
+
+			assert.Equal(t, 4, summary.Orders)
+			assert.Equal(t, 3, summary.OpenOrders)
+			require.Len(t, summary.Pairs, 2)
+			assert.Equal(t, "ETHZAR", summary.Pairs[0].Pair)
+			assert.Equal(t, "XBTZAR", summary.Pairs[1].Pair)
+			assert.Equal(t, 2, summary.Pairs[1].OpenOrders)
+			assert.Equal(t, "0.08", summary.Pairs[1].BaseVolume.String())
+			// 0.06 * 900000 + 0.02 * 1100000
+			assert.Equal(t, 76000.0, summary.Pairs[1].CounterValue.Float64())
+
+			require.Len(t, views, 4)
+			assert.Equal(t, "0.06", views[0].RemainingVolume.String())
+			assert.Nil(t, views[3].DistancePercent, "complete orders have no distance")
+			if !tc.expectDistances {
+				assert.Nil(t, views[0].DistancePercent)
+				return
+			}
+			// Bids are compared to the ask, asks to the bid
+			require.NotNil(t, views[0].DistancePercent)
+			assert.InDelta(t, -10.0, *views[0].DistancePercent, 1e-9)
+			require.NotNil(t, views[1].DistancePercent)
+			assert.InDelta(t, 11.1111, *views[1].DistancePercent, 1e-4)
+			assert.InDelta(t, 25.0, *views[2].DistancePercent, 1e-9)
+		})
+	}
+}
+
+func TestSummarizeOrdersWithoutOpenOrders(t *testing.T) {
+	client := sdk.NewMockLunoClient(t)
+	views, summary := summarizeOrders(context.Background(), client, nil)
+	assert.Empty(t, views)
+	assert.Equal(t, OrdersSummary{}, summary)
+}
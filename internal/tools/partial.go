@@ -0,0 +1,72 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const (
+	// fanOutBudget is how long a tool making a Luno call per pair spends on
+	// them before returning what it has
+	fanOutBudget = 20 * time.Second
+
+	// fanOutMargin is kept back from the caller's deadline to build the result
+	fanOutMargin = time.Second
+)
+
+// PartialResult marks a result that is missing pairs because time ran out. It
+// is embedded in the results of tools that make a Luno call per pair, so that
+// a slow market costs the pairs not yet fetched rather than the whole call.
+type PartialResult struct {
+	Partial bool `json:"partial,omitempty"`
+	// TimedOut lists the pairs that were not fetched, or whose call timed
+	// out, before the deadline
+	TimedOut []string `json:"timed_out,omitempty"`
+}
+
+// timeOut records pairs left out because time ran out
+func (p *PartialResult) timeOut(pairs ...string) {
+	p.Partial = true
+	p.TimedOut = append(p.TimedOut, pairs...)
+}
+
+// fanOut tracks the time left for a tool's per-pair calls: fanOutBudget from
+// the start of the call, or until just before the caller's deadline if that
+// is sooner
+type fanOut struct {
+	ctx      context.Context
+	deadline time.Time
+}
+
+func newFanOut(ctx context.Context) fanOut {
+	deadline := time.Now().Add(fanOutBudget)
+	if d, ok := ctx.Deadline(); ok && d.Add(-fanOutMargin).Before(deadline) {
+		deadline = d.Add(-fanOutMargin)
+	}
+	return fanOut{ctx: ctx, deadline: deadline}
+}
+
+// expired reports whether there is no time left to start another call
+func (f fanOut) expired() bool {
+	return f.ctx.Err() != nil || !time.Now().Before(f.deadline)
+}
+
+// timedOut reports whether a failed call was cut short by a deadline, either
+// the caller's or the HTTP client's
+func (f fanOut) timedOut(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) || f.ctx.Err() != nil {
+		return true
+	}
+	var timeout interface{ Timeout() bool }
+	return errors.As(err, &timeout) && timeout.Timeout()
+}
+
+// withTimedOut adds the pairs that timed out to errs, for an error message
+// when too few pairs were fetched to return a result at all
+func withTimedOut(errs []PairError, p PartialResult) []PairError {
+	for _, pair := range p.TimedOut {
+		errs = append(errs, PairError{Pair: pair, Error: "timed out"})
+	}
+	return errs
+}
@@ -0,0 +1,36 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string { return "i/o timeout" }
+func (timeoutError) Timeout() bool { return true }
+
+func TestFanOut(t *testing.T) {
+	fan := newFanOut(context.Background())
+	assert.False(t, fan.expired())
+	assert.WithinDuration(t, time.Now().Add(fanOutBudget), fan.deadline, time.Second)
+	assert.False(t, fan.timedOut(errors.New("luno: 404")))
+	assert.True(t, fan.timedOut(fmt.Errorf("getting candles: %w", context.DeadlineExceeded)))
+	assert.True(t, fan.timedOut(&url.Error{Op: "Get", URL: "https://api.luno.com", Err: timeoutError{}}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), fanOutMargin/2)
+	defer cancel()
+	assert.True(t, newFanOut(ctx).expired(), "no time is left once inside the margin")
+
+	ctx, cancel = context.WithCancel(context.Background())
+	fan = newFanOut(ctx)
+	cancel()
+	assert.True(t, fan.expired())
+	assert.True(t, fan.timedOut(errors.New("anything")))
+}
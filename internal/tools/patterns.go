@@ -0,0 +1,142 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/patterns"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Defaults for detect_patterns
+const (
+	defaultPatternDuration = 3600
+	defaultPatternCandles  = 100
+)
+
+// DetectPatternsResult is the result of the detect_patterns tool
+type DetectPatternsResult struct {
+	Pair           string `json:"pair"`
+	CandleDuration int64  `json:"candle_duration"`
+	// Candles is how many completed candles were scanned, from Since
+	Candles  int              `json:"candles"`
+	Since    time.Time        `json:"since"`
+	Patterns []patterns.Match `json:"patterns"`
+	Notes    []string         `json:"notes"`
+}
+
+// NewDetectPatternsTool creates a tool for finding candlestick patterns
+func NewDetectPatternsTool() mcp.Tool {
+	return mcp.NewTool(
+		DetectPatternsToolID,
+		mcp.WithDescription("Scan a market's recent candles for candlestick patterns and return each one found with its time, direction and confidence. "+
+			"Use this rather than reading patterns from raw candles. Patterns: engulfing (bullish or bearish), doji, hammer and three_white_soldiers. "+
+			"Confidence is from 0 to 1 and measures how closely the candles match the pattern, not how likely the price is to move."),
+		mcp.WithString(
+			"pair",
+			mcp.Required(),
+			mcp.Description(ErrTradingPairDesc),
+		),
+		mcp.WithNumber(
+			"duration",
+			mcp.Description(fmt.Sprintf("Candle duration in seconds (default: %d for hourly candles, or e.g. 86400 for daily)", defaultPatternDuration)),
+			mcp.Min(60),
+		),
+		mcp.WithNumber(
+			"candles",
+			mcp.Description(fmt.Sprintf("Number of recent candles to scan (default: %d, at most %d)", defaultPatternCandles, maxCandlesPerRequest)),
+			mcp.Min(1),
+			mcp.Max(maxCandlesPerRequest),
+		),
+		mcp.WithString(
+			"patterns",
+			mcp.Description("Comma-separated patterns to look for (default: all of "+strings.Join(patterns.Patterns, ", ")+")"),
+		),
+		mcp.WithNumber(
+			"min_confidence",
+			mcp.Description("Only return patterns with at least this confidence, from 0 to 1 (default: 0)"),
+			mcp.Min(0),
+			mcp.Max(1),
+		),
+	)
+}
+
+// patternsArgs are the arguments of the detect_patterns tool
+type patternsArgs struct {
+	Pair          string  `json:"pair"`
+	Duration      int64   `json:"duration"`
+	Candles       int     `json:"candles"`
+	Patterns      string  `json:"patterns"`
+	MinConfidence float64 `json:"min_confidence"`
+}
+
+// HandleDetectPatterns handles the detect_patterns tool
+func HandleDetectPatterns(cfg *config.Config) server.ToolHandlerFunc {
+	tool := NewDetectPatternsTool()
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		cfg := cfg.ForContext(ctx)
+		args := patternsArgs{Duration: defaultPatternDuration, Candles: defaultPatternCandles}
+		if errResult := bindArguments(request, tool, &args); errResult != nil {
+			return errResult, nil
+		}
+		pair := normalizeCurrencyPair(args.Pair)
+		duration, count, minConfidence := args.Duration, args.Candles, args.MinConfidence
+
+		var names []string
+		for name := range strings.SplitSeq(args.Patterns, ",") {
+			if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+				names = append(names, name)
+			}
+		}
+		for _, name := range names {
+			if !slices.Contains(patterns.Patterns, name) {
+				return mcp.NewToolResultError(fmt.Sprintf("unknown pattern %q, use one of %s", name, strings.Join(patterns.Patterns, ", "))), nil
+			}
+		}
+
+		now := time.Now()
+		length := time.Duration(duration) * time.Second
+		since := now.Add(-time.Duration(count) * length)
+		candles, err := getCandles(ctx, cfg, pair, duration, since, now)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("getting candles", err), nil
+		}
+		// The latest candle is still forming, and may change shape before it closes
+		candles = slices.DeleteFunc(candles, func(c luno.Candle) bool {
+			return time.Time(c.Timestamp).Add(length).After(now)
+		})
+
+		found, err := patterns.Detect(candles, names...)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("detecting patterns", err), nil
+		}
+		found = slices.DeleteFunc(found, func(m patterns.Match) bool { return m.Confidence < minConfidence })
+
+		result := DetectPatternsResult{
+			Pair:           pair,
+			CandleDuration: duration,
+			Candles:        len(candles),
+			Since:          since.UTC(),
+			Patterns:       found,
+			Notes: []string{
+				"Only completed candles are scanned; the candle still forming is left out.",
+				"Patterns are shapes in past prices, not predictions. Confirm them with other analysis before trading.",
+			},
+		}
+		if len(candles) == 0 {
+			result.Notes = append(result.Notes, "No completed candles were found; the market may not have traded in this range.")
+		}
+
+		resultJSON, err := marshalJSON(result)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal patterns: %v", err)), nil
+		}
+		return mcp.NewToolResultText(resultJSON), nil
+	}
+}
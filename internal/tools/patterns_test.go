@@ -0,0 +1,121 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/patterns"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleDetectPatterns(t *testing.T) {
+	ctx := context.Background()
+	// Hourly candles falling into a bullish engulfing, then the candle still forming
+	current := time.Now().Truncate(time.Hour)
+	var candles luno.GetCandlesResponse
+	for i, ohlc := range [][4]string{
+		{"125", "125", "120", "120"},
+		{"120", "120", "115", "115"},
+		{"115", "115", "110", "110"},
+		{"110", "110", "105", "105"},
+		{"105", "105", "100", "100"},
+		{"99", "108", "99", "107"},
+		{"107", "107", "107", "107"},
+	} {
+		candles.Candles = append(candles.Candles, luno.Candle{
+			Timestamp: luno.Time(current.Add(time.Duration(i-6) * time.Hour)),
+			Open:      NewFromString(t, ohlc[0]),
+			High:      NewFromString(t, ohlc[1]),
+			Low:       NewFromString(t, ohlc[2]),
+			Close:     NewFromString(t, ohlc[3]),
+		})
+	}
+
+	tests := []struct {
+		name          string
+		args          map[string]any
+		candlesErr    error
+		noCandles     bool
+		expPatterns   []string
+		errorContains string
+	}{
+		{
+			name:        "finds patterns in completed candles",
+			args:        map[string]any{"pair": "xbtzar"},
+			expPatterns: []string{patterns.Engulfing},
+		},
+		{
+			name:        "filtered by pattern",
+			args:        map[string]any{"pair": "XBTZAR", "patterns": "Doji, hammer"},
+			expPatterns: []string{},
+		},
+		{
+			name:        "filtered by confidence",
+			args:        map[string]any{"pair": "XBTZAR", "min_confidence": 0.9},
+			expPatterns: []string{},
+		},
+		{
+			name:          "unknown pattern",
+			args:          map[string]any{"pair": "XBTZAR", "patterns": "engulfing,cup_and_handle"},
+			noCandles:     true,
+			errorContains: `unknown pattern "cup_and_handle"`,
+		},
+		{
+			name:          "candles fail",
+			args:          map[string]any{"pair": "XBTZAR"},
+			candlesErr:    errors.New(apiErrorStr),
+			errorContains: "getting candles",
+		},
+		{
+			name:          "missing pair",
+			args:          map[string]any{},
+			noCandles:     true,
+			errorContains: "pair",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := sdk.NewMockLunoClient(t)
+			if !tt.noCandles {
+				res := &candles
+				if tt.candlesErr != nil {
+					res = nil
+				}
+				mockClient.EXPECT().GetCandles(ctx, mock.MatchedBy(func(r *luno.GetCandlesRequest) bool {
+					return r.Pair == "XBTZAR" && r.Duration == 3600
+				})).Return(res, tt.candlesErr)
+			}
+			cfg := &config.Config{LunoClient: mockClient}
+
+			result, err := HandleDetectPatterns(cfg)(ctx, createMockRequest(tt.args))
+			require.NoError(t, err)
+			text := getTextContentFromResult(t, result)
+			if tt.errorContains != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, text, tt.errorContains)
+				return
+			}
+			require.False(t, result.IsError, text)
+
+			var got DetectPatternsResult
+			require.NoError(t, json.Unmarshal([]byte(text), &got))
+			assert.Equal(t, "XBTZAR", got.Pair)
+			assert.Equal(t, 6, got.Candles, "the candle still forming is left out")
+			names := []string{}
+			for _, m := range got.Patterns {
+				names = append(names, m.Pattern)
+			}
+			assert.Equal(t, tt.expPatterns, names)
+			assert.NotEmpty(t, got.Notes)
+		})
+	}
+}
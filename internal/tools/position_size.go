@@ -0,0 +1,305 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/validate"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// percentScale is the number of decimal places kept from percentage arguments
+const percentScale = 4
+
+// PositionSize is the result of the suggest_position_size tool. Prices and
+// money amounts are in the pair's counter currency, volumes in its base currency.
+type PositionSize struct {
+	Pair       string          `json:"pair"`
+	Side       string          `json:"side"`
+	EntryPrice decimal.Decimal `json:"entry_price"`
+	StopPrice  decimal.Decimal `json:"stop_price"`
+	// StopDistance is what each unit of base currency loses if the stop is hit
+	StopDistance decimal.Decimal `json:"stop_distance"`
+	Balance      decimal.Decimal `json:"balance"`
+	RiskPercent  float64         `json:"risk_percent"`
+	// RiskAmount is the most the position may lose at the stop
+	RiskAmount decimal.Decimal `json:"risk_amount"`
+	// Volume is the suggested order volume, rounded down to the market's volume
+	// scale. It is zero when even the market minimum would risk too much.
+	Volume   decimal.Decimal `json:"volume"`
+	Notional decimal.Decimal `json:"notional"`
+	// ExpectedLoss is what Volume loses at the stop, at most RiskAmount
+	ExpectedLoss decimal.Decimal `json:"expected_loss"`
+	// RewardRiskRatio compares the gain at target_price to the loss at the stop
+	RewardRiskRatio *float64        `json:"reward_risk_ratio,omitempty"`
+	MinVolume       decimal.Decimal `json:"min_volume"`
+	MaxVolume       decimal.Decimal `json:"max_volume"`
+	// LimitedBy is set when Volume is below the risk-based size: "balance",
+	// "holdings" or "max_volume"
+	LimitedBy string   `json:"limited_by,omitempty"`
+	Notes     []string `json:"notes,omitempty"`
+}
+
+// NewSuggestPositionSizeTool creates a new tool for sizing an order by risk
+func NewSuggestPositionSizeTool() mcp.Tool {
+	return mcp.NewTool(
+		SuggestPositionSizeToolID,
+		mcp.WithDescription("Suggest an order volume that risks a given percentage of a balance if the price reaches a stop. "+
+			"The volume is rounded down to the market's volume scale and checked against its minimum and maximum. "+
+			"Use the result as the volume for create_order. Nothing is traded."),
+		mcp.WithString(
+			"pair",
+			mcp.Required(),
+			mcp.Description(ErrTradingPairDesc),
+		),
+		mcp.WithString(
+			"side",
+			mcp.Required(),
+			mcp.Description("Trade direction (BUY or SELL). A BUY's stop is below the entry price, a SELL's above it."),
+			mcp.Enum("BUY", "SELL"),
+		),
+		mcp.WithNumber(
+			"risk_percent",
+			mcp.Required(),
+			mcp.Description("Percentage of the balance to lose if the stop is hit (e.g., 1 for 1%)"),
+			validate.ExclusiveMin(0),
+			mcp.Max(100),
+		),
+		mcp.WithString(
+			"stop_price",
+			mcp.Description("Price at which the position would be closed at a loss. Give this or stop_distance_percent."),
+			validate.Decimal(),
+			validate.ExclusiveMin(0),
+		),
+		mcp.WithNumber(
+			"stop_distance_percent",
+			mcp.Description("Distance from the entry price to the stop, as a percentage of the entry price. Give this or stop_price."),
+			validate.ExclusiveMin(0),
+			validate.ExclusiveMax(100),
+		),
+		mcp.WithString(
+			"entry_price",
+			mcp.Description("Expected entry price. Defaults to the current ask for BUY and bid for SELL."),
+			validate.Decimal(),
+			validate.ExclusiveMin(0),
+		),
+		mcp.WithString(
+			"target_price",
+			mcp.Description("Optional take-profit price, used to report the reward to risk ratio"),
+			validate.Decimal(),
+			validate.ExclusiveMin(0),
+		),
+		mcp.WithString(
+			"balance",
+			mcp.Description("Balance to size the position against, in the pair's counter currency (e.g., ZAR for XBTZAR). "+
+				"Defaults to your available counter currency balance."),
+			validate.Decimal(),
+			validate.ExclusiveMin(0),
+		),
+	)
+}
+
+// positionSizeArgs are the arguments of the suggest_position_size tool
+type positionSizeArgs struct {
+	Pair                string           `json:"pair"`
+	Side                string           `json:"side"`
+	RiskPercent         float64          `json:"risk_percent"`
+	StopPrice           *decimal.Decimal `json:"stop_price"`
+	StopDistancePercent float64          `json:"stop_distance_percent"`
+	EntryPrice          *decimal.Decimal `json:"entry_price"`
+	TargetPrice         *decimal.Decimal `json:"target_price"`
+	Balance             *decimal.Decimal `json:"balance"`
+}
+
+// HandleSuggestPositionSize handles the suggest_position_size tool
+func HandleSuggestPositionSize(cfg *config.Config) server.ToolHandlerFunc {
+	tool := NewSuggestPositionSizeTool()
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		cfg := cfg.ForContext(ctx)
+		var args positionSizeArgs
+		if errResult := bindArguments(request, tool, &args); errResult != nil {
+			return errResult, nil
+		}
+		pair := normalizeCurrencyPair(args.Pair)
+		side, riskPercent := args.Side, args.RiskPercent
+		stopPrice, stopPercent := args.StopPrice, args.StopDistancePercent
+		switch {
+		case stopPrice != nil && stopPercent != 0:
+			return mcp.NewToolResultError("Give either stop_price or stop_distance_percent, not both"), nil
+		case stopPrice == nil && stopPercent == 0:
+			return mcp.NewToolResultError("A stop is needed to size the position: give stop_price or stop_distance_percent"), nil
+		}
+
+		entryPrice, targetPrice, balance := args.EntryPrice, args.TargetPrice, args.Balance
+		if balance == nil && !cfg.IsAuthenticated {
+			return mcp.NewToolResultError("balance is required when API credentials are not configured"), nil
+		}
+
+		market, ok, err := MarketCache(cfg).Lookup(ctx, pair)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("looking up market", err), nil
+		}
+		if !ok {
+			return mcp.NewToolResultError(fmt.Sprintf("Unable to size a position for %s: %s", pair, unknownMarketError)), nil
+		}
+
+		if entryPrice == nil {
+			ticker, err := cfg.LunoClient.GetTicker(ctx, &luno.GetTickerRequest{Pair: pair})
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr("getting ticker for the entry price", err), nil
+			}
+			price := ticker.Ask
+			if side == "SELL" {
+				price = ticker.Bid
+			}
+			entryPrice = &price
+		}
+		if entryPrice.Sign() <= 0 {
+			return mcp.NewToolResultError("entry_price must be positive"), nil
+		}
+
+		// Available balances, used for the default balance and to cap the volume
+		var counterAvailable, baseAvailable *decimal.Decimal
+		if cfg.IsAuthenticated {
+			balances, err := cfg.LunoClient.GetBalances(ctx, &luno.GetBalancesRequest{})
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr("getting balances", err), nil
+			}
+			counterAvailable = availableBalance(balances.Balance, market.CounterCurrency)
+			baseAvailable = availableBalance(balances.Balance, market.BaseCurrency)
+		}
+		if balance == nil {
+			balance = counterAvailable
+		}
+		if balance.Sign() <= 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("There is no %s balance to size the position against", market.CounterCurrency)), nil
+		}
+
+		stop := stopPriceFor(side, *entryPrice, stopPrice, stopPercent)
+		distance := entryPrice.Sub(stop)
+		if side == "SELL" {
+			distance = distance.Neg()
+		}
+		if distance.Sign() <= 0 || stop.Sign() <= 0 {
+			if side == "BUY" {
+				return mcp.NewToolResultError(fmt.Sprintf("The stop price for a BUY must be below the entry price %s and above zero", entryPrice)), nil
+			}
+			return mcp.NewToolResultError(fmt.Sprintf("The stop price for a SELL must be above the entry price %s", entryPrice)), nil
+		}
+
+		balanceScale := decimalScale(*balance)
+		riskAmount := balance.Mul(decimal.NewFromFloat64(riskPercent, percentScale)).DivInt64(100).ToScale(balanceScale)
+		volumeScale := int(market.VolumeScale)
+
+		result := PositionSize{
+			Pair:         pair,
+			Side:         side,
+			EntryPrice:   *entryPrice,
+			StopPrice:    stop,
+			StopDistance: distance,
+			Balance:      *balance,
+			RiskPercent:  riskPercent,
+			RiskAmount:   riskAmount,
+			MinVolume:    market.MinVolume,
+			MaxVolume:    market.MaxVolume,
+		}
+
+		volume := riskAmount.Div(distance, volumeScale)
+		limit := func(max decimal.Decimal, reason string) {
+			if volume.Cmp(max) > 0 {
+				volume = max.ToScale(volumeScale)
+				result.LimitedBy = reason
+			}
+		}
+		if market.MaxVolume.Sign() > 0 {
+			limit(market.MaxVolume, "max_volume")
+		}
+		// Buys can spend at most the available balance, or the given one if that
+		// is not known. Sells can only sell what is held.
+		switch {
+		case side == "BUY" && counterAvailable != nil:
+			limit(counterAvailable.Div(*entryPrice, volumeScale), "balance")
+		case side == "BUY":
+			limit(balance.Div(*entryPrice, volumeScale), "balance")
+		case baseAvailable != nil:
+			limit(*baseAvailable, "holdings")
+		default:
+			result.Notes = append(result.Notes, fmt.Sprintf("Check that you hold the %s to sell.", market.BaseCurrency))
+		}
+
+		if volume.Cmp(market.MinVolume) < 0 || volume.Sign() <= 0 {
+			minLoss := market.MinVolume.Mul(distance).ToScale(balanceScale)
+			if result.LimitedBy != "" {
+				result.Notes = append(result.Notes, fmt.Sprintf("The %s left room for less than the market minimum of %s %s.",
+					limitedByDescription(result.LimitedBy), market.MinVolume, market.BaseCurrency))
+			} else {
+				result.Notes = append(result.Notes, fmt.Sprintf("The market minimum of %s %s would lose %s %s at the stop, more than the %s %s risk allowed. Raise risk_percent or move the stop closer to size a trade.",
+					market.MinVolume, market.BaseCurrency, minLoss, market.CounterCurrency, riskAmount, market.CounterCurrency))
+			}
+			volume = decimal.Zero().ToScale(volumeScale)
+		}
+
+		result.Volume = volume
+		result.Notional = volume.Mul(*entryPrice).ToScale(balanceScale)
+		result.ExpectedLoss = volume.Mul(distance).ToScale(balanceScale)
+		if targetPrice != nil {
+			reward := targetPrice.Sub(*entryPrice)
+			if side == "SELL" {
+				reward = reward.Neg()
+			}
+			if reward.Sign() <= 0 {
+				result.Notes = append(result.Notes, "target_price is on the losing side of the entry price, so no reward to risk ratio is given.")
+			} else {
+				ratio := reward.Div(distance, percentScale).Float64()
+				result.RewardRiskRatio = &ratio
+			}
+		}
+		result.Notes = append(result.Notes, "Trading fees and slippage past the stop are not included.")
+
+		resultJSON, err := marshalJSON(result)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal position size: %v", err)), nil
+		}
+		return mcp.NewToolResultText(resultJSON), nil
+	}
+}
+
+// stopPriceFor returns the stop price, either as given or stopPercent away from
+// entry on the losing side
+func stopPriceFor(side string, entry decimal.Decimal, stopPrice *decimal.Decimal, stopPercent float64) decimal.Decimal {
+	if stopPrice != nil {
+		return *stopPrice
+	}
+	offset := entry.Mul(decimal.NewFromFloat64(stopPercent, percentScale)).DivInt64(100).ToScale(decimalScale(entry))
+	if side == "SELL" {
+		return entry.Add(offset)
+	}
+	return entry.Sub(offset)
+}
+
+// availableBalance returns the balance of currency not reserved by open orders,
+// summed across its accounts
+func availableBalance(balances []luno.AccountBalance, currency string) *decimal.Decimal {
+	total := decimal.Zero()
+	for _, b := range balances {
+		if b.Asset == currency {
+			total = total.Add(b.Balance.Sub(b.Reserved))
+		}
+	}
+	return &total
+}
+
+func limitedByDescription(limitedBy string) string {
+	switch limitedBy {
+	case "max_volume":
+		return "market maximum volume"
+	case "holdings":
+		return "available balance to sell"
+	default:
+		return "available balance"
+	}
+}
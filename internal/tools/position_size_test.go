@@ -0,0 +1,173 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/markets"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleSuggestPositionSize(t *testing.T) {
+	ctx := context.Background()
+	listed := &luno.MarketsResponse{Markets: []luno.MarketInfo{{
+		MarketId:        "XBTZAR",
+		BaseCurrency:    "XBT",
+		CounterCurrency: "ZAR",
+		VolumeScale:     4,
+		PriceScale:      0,
+		MinVolume:       NewFromString(t, "0.0005"),
+		MaxVolume:       NewFromString(t, "100"),
+	}}}
+	balances := &luno.GetBalancesResponse{Balance: []luno.AccountBalance{
+		{Asset: "ZAR", Balance: NewFromString(t, "1500.00"), Reserved: NewFromString(t, "500.00")},
+		{Asset: "XBT", Balance: NewFromString(t, "0.00100000"), Reserved: NewFromString(t, "0.00000000")},
+	}}
+
+	tests := []struct {
+		name            string
+		args            map[string]any
+		isAuthenticated bool
+		mockSetup       func(*sdk.MockLunoClient)
+		expected        map[string]any
+		expectedNote    string
+		errorContains   string
+	}{
+		{
+			name: "buy sized by risk",
+			args: map[string]any{"pair": "XBTZAR", "side": "BUY", "risk_percent": 1.0, "entry_price": "1000000", "stop_price": "950000", "balance": "10000.00"},
+			expected: map[string]any{
+				"stop_distance": "50000",
+				"risk_amount":   "100.00",
+				"volume":        "0.0020",
+				"notional":      "2000.00",
+				"expected_loss": "100.00",
+			},
+		},
+		{
+			name: "sell entry from bid and stop distance",
+			args: map[string]any{"pair": "XBTZAR", "side": "SELL", "risk_percent": 2.0, "stop_distance_percent": 5.0, "balance": "50000", "target_price": "900000"},
+			mockSetup: func(c *sdk.MockLunoClient) {
+				c.EXPECT().GetTicker(ctx, &luno.GetTickerRequest{Pair: "XBTZAR"}).
+					Return(&luno.GetTickerResponse{Bid: NewFromString(t, "1000000"), Ask: NewFromString(t, "1000100")}, nil)
+			},
+			expected: map[string]any{
+				"entry_price":       "1000000",
+				"stop_price":        "1050000",
+				"risk_amount":       "1000",
+				"volume":            "0.0200",
+				"reward_risk_ratio": 2.0,
+			},
+			expectedNote: "Check that you hold the XBT to sell.",
+		},
+		{
+			name:            "buy capped by available balance",
+			args:            map[string]any{"pair": "XBTZAR", "side": "BUY", "risk_percent": 10.0, "entry_price": "1000000", "stop_price": "990000"},
+			isAuthenticated: true,
+			mockSetup: func(c *sdk.MockLunoClient) {
+				c.EXPECT().GetBalances(ctx, &luno.GetBalancesRequest{}).Return(balances, nil)
+			},
+			expected: map[string]any{
+				"balance":     "1000.00",
+				"risk_amount": "100.00",
+				"volume":      "0.0010",
+				"limited_by":  "balance",
+			},
+		},
+		{
+			name:            "sell capped by holdings",
+			args:            map[string]any{"pair": "XBTZAR", "side": "SELL", "risk_percent": 50.0, "entry_price": "1000000", "stop_price": "1100000", "balance": "100000"},
+			isAuthenticated: true,
+			mockSetup: func(c *sdk.MockLunoClient) {
+				c.EXPECT().GetBalances(ctx, &luno.GetBalancesRequest{}).Return(balances, nil)
+			},
+			expected: map[string]any{
+				"volume":     "0.0010",
+				"limited_by": "holdings",
+			},
+		},
+		{
+			name: "below market minimum",
+			args: map[string]any{"pair": "XBTZAR", "side": "BUY", "risk_percent": 0.5, "entry_price": "1000000", "stop_price": "900000", "balance": "1000"},
+			expected: map[string]any{
+				"volume":        "0.0000",
+				"expected_loss": "0",
+			},
+			expectedNote: "The market minimum of 0.0005 XBT would lose 50 ZAR at the stop, more than the 5 ZAR risk allowed. Raise risk_percent or move the stop closer to size a trade.",
+		},
+		{
+			name:          "stop on the wrong side",
+			args:          map[string]any{"pair": "XBTZAR", "side": "BUY", "risk_percent": 1.0, "entry_price": "1000000", "stop_price": "1100000", "balance": "1000"},
+			errorContains: "The stop price for a BUY must be below the entry price 1000000",
+		},
+		{
+			name:          "no stop",
+			args:          map[string]any{"pair": "XBTZAR", "side": "BUY", "risk_percent": 1.0, "balance": "1000"},
+			errorContains: "A stop is needed",
+		},
+		{
+			name:          "both stops",
+			args:          map[string]any{"pair": "XBTZAR", "side": "BUY", "risk_percent": 1.0, "stop_price": "900000", "stop_distance_percent": 5.0, "balance": "1000"},
+			errorContains: "not both",
+		},
+		{
+			name:          "invalid price",
+			args:          map[string]any{"pair": "XBTZAR", "side": "BUY", "risk_percent": 1.0, "stop_price": "cheap", "balance": "1000"},
+			errorContains: `stop_price must be a decimal number such as 0.25, got "cheap"`,
+		},
+		{
+			name:          "balance needed without credentials",
+			args:          map[string]any{"pair": "XBTZAR", "side": "BUY", "risk_percent": 1.0, "stop_price": "900000"},
+			errorContains: "balance is required",
+		},
+		{
+			name:          "unknown pair",
+			args:          map[string]any{"pair": "ABCZAR", "side": "BUY", "risk_percent": 1.0, "stop_price": "900000", "balance": "1000"},
+			errorContains: unknownMarketError,
+		},
+		{
+			name: "ticker error",
+			args: map[string]any{"pair": "XBTZAR", "side": "BUY", "risk_percent": 1.0, "stop_distance_percent": 5.0, "balance": "1000"},
+			mockSetup: func(c *sdk.MockLunoClient) {
+				c.EXPECT().GetTicker(ctx, &luno.GetTickerRequest{Pair: "XBTZAR"}).Return(nil, errors.New(apiErrorStr))
+			},
+			errorContains: apiErrorStr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := sdk.NewMockLunoClient(t)
+			cfg := &config.Config{LunoClient: mockClient, IsAuthenticated: tt.isAuthenticated, Markets: markets.NewCache(mockClient)}
+			mockClient.EXPECT().Markets(ctx, &luno.MarketsRequest{}).Return(listed, nil).Maybe()
+			if tt.mockSetup != nil {
+				tt.mockSetup(mockClient)
+			}
+
+			result, err := HandleSuggestPositionSize(cfg)(ctx, createMockRequest(tt.args))
+			require.NoError(t, err)
+			text := getTextContentFromResult(t, result)
+			if tt.errorContains != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, text, tt.errorContains)
+				return
+			}
+			require.False(t, result.IsError, text)
+
+			var got map[string]any
+			require.NoError(t, json.Unmarshal([]byte(text), &got))
+			for k, v := range tt.expected {
+				assert.Equal(t, v, got[k], k)
+			}
+			if tt.expectedNote != "" {
+				assert.Contains(t, got["notes"], tt.expectedNote)
+			}
+		})
+	}
+}
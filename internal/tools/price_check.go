@@ -0,0 +1,71 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// allowFarFromMarketParam is the argument that places limit orders priced
+// further from the market than cfg.MaxPriceDeviation
+const allowFarFromMarketParam = "allow_far_from_market"
+
+// withAllowFarFromMarket adds the allow_far_from_market argument to a tool
+// that places limit orders
+func withAllowFarFromMarket() mcp.ToolOption {
+	return mcp.WithBoolean(
+		allowFarFromMarketParam,
+		mcp.Description(fmt.Sprintf("Set to true to place a limit order priced further from the market price than the server allows (%s, %g%% by default). "+
+			"Only set it once the user has confirmed the price is intended (default: false)", config.EnvMaxPriceDeviation, config.DefaultMaxPriceDeviation)),
+	)
+}
+
+// checkPriceDeviation rejects a limit price on pair that is further from the
+// market price than cfg.MaxPriceDeviation, which usually means a slipped digit,
+// unless allowFar is set. The market price is the mid price, or the last trade
+// for a market with an empty side. Luno accepts such orders, so the check is
+// skipped if the ticker can't be read.
+func checkPriceDeviation(ctx context.Context, cfg *config.Config, pair string, price decimal.Decimal, allowFar bool) error {
+	if allowFar || cfg.MaxPriceDeviation <= 0 {
+		return nil
+	}
+	market, err := marketPrice(ctx, cfg, pair)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to get ticker for price check", "pair", pair, "error", err)
+		return nil
+	}
+	if market.Sign() <= 0 {
+		return nil
+	}
+
+	deviation := percentOf(price.Sub(market), market)
+	if math.Abs(deviation) <= cfg.MaxPriceDeviation {
+		return nil
+	}
+	direction := "above"
+	if deviation < 0 {
+		direction = "below"
+	}
+	return fmt.Errorf("price %s is %.0f%% %s the market price of %s for %s, more than the %g%% allowed. "+
+		"Check the price for a slipped digit or misplaced decimal point; if it is intended, confirm with the user and call again with %s=true",
+		price, math.Abs(deviation), direction, market, pair, cfg.MaxPriceDeviation, allowFarFromMarketParam)
+}
+
+// marketPrice returns the market price of pair: the mid price, or the last
+// trade for a market with an empty side. It is zero if neither is known.
+func marketPrice(ctx context.Context, cfg *config.Config, pair string) (decimal.Decimal, error) {
+	ticker, err := cfg.LunoClient.GetTicker(ctx, &luno.GetTickerRequest{Pair: pair})
+	if err != nil {
+		return decimal.Decimal{}, err
+	}
+	if ticker.Bid.Sign() > 0 && ticker.Ask.Sign() > 0 {
+		return ticker.Bid.Add(ticker.Ask).DivInt64(2), nil
+	}
+	return ticker.LastTrade, nil
+}
@@ -0,0 +1,91 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckPriceDeviation(t *testing.T) {
+	ticker := &luno.GetTickerResponse{
+		Pair:      "XBTZAR",
+		Bid:       NewFromString(t, "999000"),
+		Ask:       NewFromString(t, "1001000"),
+		LastTrade: NewFromString(t, "1000500"),
+	}
+
+	tests := []struct {
+		name          string
+		price         string
+		allowFar      bool
+		maxDeviation  float64
+		ticker        *luno.GetTickerResponse
+		tickerErr     error
+		errorContains string
+	}{
+		{name: "near the market", price: "1100000", maxDeviation: 50, ticker: ticker},
+		{name: "at the limit", price: "1500000", maxDeviation: 50, ticker: ticker},
+		{
+			name: "ten times the market", price: "10000000", maxDeviation: 50, ticker: ticker,
+			errorContains: "price 10000000 is 900% above the market price of 1000000 for XBTZAR, more than the 50% allowed",
+		},
+		{
+			name: "a tenth of the market", price: "100000", maxDeviation: 50, ticker: ticker,
+			errorContains: "price 100000 is 90% below the market price",
+		},
+		{name: "allowed far from the market", price: "10000000", allowFar: true, maxDeviation: 50},
+		{name: "check disabled", price: "10000000"},
+		{
+			name: "last trade without an order book", price: "2000000", maxDeviation: 50,
+			ticker:        &luno.GetTickerResponse{Pair: "XBTZAR", Bid: NewFromString(t, "999000"), LastTrade: NewFromString(t, "1000000")},
+			errorContains: "100% above the market price of 1000000",
+		},
+		{name: "no market price", price: "2000000", maxDeviation: 50, ticker: &luno.GetTickerResponse{Pair: "XBTZAR"}},
+		{name: "ticker unavailable", price: "10000000", maxDeviation: 50, tickerErr: errors.New("connection reset")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := sdk.NewMockLunoClient(t)
+			if tt.ticker != nil || tt.tickerErr != nil {
+				mockClient.EXPECT().GetTicker(mock.Anything, &luno.GetTickerRequest{Pair: "XBTZAR"}).Return(tt.ticker, tt.tickerErr)
+			}
+			cfg := &config.Config{LunoClient: mockClient, MaxPriceDeviation: tt.maxDeviation}
+
+			err := checkPriceDeviation(context.Background(), cfg, "XBTZAR", NewFromString(t, tt.price), tt.allowFar)
+			if tt.errorContains == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorContains)
+			assert.Contains(t, err.Error(), "allow_far_from_market=true")
+		})
+	}
+}
+
+func TestHandleCreateOrderFarFromMarket(t *testing.T) {
+	mockClient := sdk.NewMockLunoClient(t)
+	mockClient.EXPECT().Markets(mock.Anything, &luno.MarketsRequest{}).
+		Return(&luno.MarketsResponse{Markets: []luno.MarketInfo{{MarketId: "XBTZAR", TradingStatus: luno.TradingStatusActive}}}, nil)
+	mockClient.EXPECT().GetTicker(mock.Anything, &luno.GetTickerRequest{Pair: "XBTZAR"}).
+		Return(&luno.GetTickerResponse{Pair: "XBTZAR", Bid: NewFromString(t, "999000"), Ask: NewFromString(t, "1001000")}, nil)
+	cfg := &config.Config{LunoClient: mockClient, IsAuthenticated: true, MaxPriceDeviation: config.DefaultMaxPriceDeviation}
+
+	// PostLimitOrder is not expected, so the order must not be placed
+	result, err := HandleCreateOrder(cfg)(context.Background(), createMockRequest(map[string]any{
+		"pair": "XBTZAR", "type": "BUY", "volume": "0.01", "price": "10000000",
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	text := getTextContentFromResult(t, result)
+	assert.Contains(t, text, "Unable to create order: price 10000000 is 900% above the market price")
+	assert.Contains(t, text, "allow_far_from_market=true")
+}
@@ -0,0 +1,130 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/reference"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// PricePremium is the result of the get_price_premium tool. Prices are in the
+// pair's counter currency.
+type PricePremium struct {
+	Pair      string          `json:"pair"`
+	Bid       decimal.Decimal `json:"bid"`
+	Ask       decimal.Decimal `json:"ask"`
+	LastTrade decimal.Decimal `json:"last_trade"`
+	// Mid is halfway between Bid and Ask, or LastTrade when the book is one-sided
+	Mid decimal.Decimal `json:"mid"`
+
+	ReferencePrice  decimal.Decimal `json:"reference_price"`
+	ReferenceSource string          `json:"reference_source"`
+	ReferenceTime   time.Time       `json:"reference_time"`
+
+	// Premium is Mid minus ReferencePrice: positive when Luno is more expensive
+	// than the reference, negative when it trades at a discount
+	Premium        decimal.Decimal `json:"premium"`
+	PremiumPercent float64         `json:"premium_percent"`
+	// BidPremiumPercent and AskPremiumPercent compare what a sell and a buy on
+	// Luno would get with the reference price
+	BidPremiumPercent float64 `json:"bid_premium_percent"`
+	AskPremiumPercent float64 `json:"ask_premium_percent"`
+}
+
+// NewGetPricePremiumTool creates a new tool for comparing Luno's price with an external reference
+func NewGetPricePremiumTool() mcp.Tool {
+	return mcp.NewTool(
+		GetPricePremiumToolID,
+		mcp.WithDescription("Compare Luno's price for a pair with the configured external reference price, e.g. a global index, "+
+			"and report the premium or discount. Useful where Luno prices differ significantly from other markets, such as NGN and ZAR pairs."),
+		mcp.WithString(
+			"pair",
+			mcp.Required(),
+			mcp.Description(ErrTradingPairDesc),
+		),
+	)
+}
+
+// HandleGetPricePremium handles the get_price_premium tool
+func HandleGetPricePremium(cfg *config.Config) server.ToolHandlerFunc {
+	tool := NewGetPricePremiumTool()
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		cfg := cfg.ForContext(ctx)
+		if cfg.Reference == nil {
+			return mcp.NewToolResultError(fmt.Sprintf("No reference price feed is configured. Set %s or the --reference-price-url flag to the URL of a JSON price feed.", config.EnvReferencePriceURL)), nil
+		}
+
+		var args pairArgs
+		if errResult := bindArguments(request, tool, &args); errResult != nil {
+			return errResult, nil
+		}
+		pair := normalizeCurrencyPair(args.Pair)
+
+		market, ok, err := MarketCache(cfg).Lookup(ctx, pair)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("looking up market", err), nil
+		}
+		if !ok {
+			return mcp.NewToolResultError(fmt.Sprintf("Unable to compare prices for %s: %s", pair, unknownMarketError)), nil
+		}
+
+		ticker, err := cfg.LunoClient.GetTicker(ctx, &luno.GetTickerRequest{Pair: pair})
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("getting ticker", err), nil
+		}
+
+		quote, err := cfg.Reference.Quote(ctx, reference.Pair{
+			Pair:    pair,
+			Base:    market.BaseCurrency,
+			Counter: market.CounterCurrency,
+		})
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("getting reference price", err), nil
+		}
+		if quote.Price.Sign() <= 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("The reference price for %s must be positive, got %s", pair, quote.Price)), nil
+		}
+
+		result := newPricePremium(luno.Ticker(*ticker), quote)
+		resultJSON, err := marshalJSON(result)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal price premium: %v", err)), nil
+		}
+		return mcp.NewToolResultText(resultJSON), nil
+	}
+}
+
+// newPricePremium compares ticker with quote
+func newPricePremium(ticker luno.Ticker, quote reference.Quote) PricePremium {
+	mid := ticker.LastTrade
+	if ticker.Bid.Sign() > 0 && ticker.Ask.Sign() > 0 {
+		sum := ticker.Bid.Add(ticker.Ask)
+		// One more decimal place keeps the halving exact
+		mid = sum.ToScale(decimalScale(sum) + 1).DivInt64(2)
+	}
+	p := PricePremium{
+		Pair:            ticker.Pair,
+		Bid:             ticker.Bid,
+		Ask:             ticker.Ask,
+		LastTrade:       ticker.LastTrade,
+		Mid:             mid,
+		ReferencePrice:  quote.Price,
+		ReferenceSource: quote.Source,
+		ReferenceTime:   quote.Time,
+		Premium:         mid.Sub(quote.Price),
+		PremiumPercent:  percentOf(mid.Sub(quote.Price), quote.Price),
+	}
+	if ticker.Bid.Sign() > 0 {
+		p.BidPremiumPercent = percentOf(ticker.Bid.Sub(quote.Price), quote.Price)
+	}
+	if ticker.Ask.Sign() > 0 {
+		p.AskPremiumPercent = percentOf(ticker.Ask.Sub(quote.Price), quote.Price)
+	}
+	return p
+}
@@ -0,0 +1,150 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/markets"
+	"github.com/luno/luno-mcp/internal/reference"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleGetPricePremium(t *testing.T) {
+	ctx := context.Background()
+	listed := &luno.MarketsResponse{Markets: []luno.MarketInfo{
+		{MarketId: "XBTNGN", BaseCurrency: "XBT", CounterCurrency: "NGN"},
+	}}
+
+	tests := []struct {
+		name            string
+		args            map[string]any
+		source          reference.Source
+		mockSetup       func(*sdk.MockLunoClient)
+		expected        map[string]any
+		expectedPercent float64
+		errorContains   string
+	}{
+		{
+			name:   "premium from mid price",
+			args:   map[string]any{"pair": "xbt/ngn"},
+			source: fixedSource(t, "100000000", reference.Pair{Pair: "XBTNGN", Base: "XBT", Counter: "NGN"}),
+			mockSetup: func(c *sdk.MockLunoClient) {
+				c.EXPECT().GetTicker(ctx, &luno.GetTickerRequest{Pair: "XBTNGN"}).Return(&luno.GetTickerResponse{
+					Pair:      "XBTNGN",
+					Bid:       NewFromString(t, "104000000"),
+					Ask:       NewFromString(t, "104000001"),
+					LastTrade: NewFromString(t, "103500000"),
+				}, nil)
+			},
+			expected: map[string]any{
+				"pair":             "XBTNGN",
+				"mid":              "104000000.5",
+				"reference_price":  "100000000",
+				"reference_source": "test-index",
+				"reference_time":   "2026-10-15T12:00:00Z",
+				"premium":          "4000000.5",
+			},
+			expectedPercent: 4.0000005,
+		},
+		{
+			name:   "discount from last trade on a one-sided book",
+			args:   map[string]any{"pair": "XBTNGN"},
+			source: fixedSource(t, "100000000", reference.Pair{Pair: "XBTNGN", Base: "XBT", Counter: "NGN"}),
+			mockSetup: func(c *sdk.MockLunoClient) {
+				c.EXPECT().GetTicker(ctx, &luno.GetTickerRequest{Pair: "XBTNGN"}).Return(&luno.GetTickerResponse{
+					Pair:      "XBTNGN",
+					Bid:       NewFromString(t, "97000000"),
+					Ask:       NewFromString(t, "0"),
+					LastTrade: NewFromString(t, "98000000"),
+				}, nil)
+			},
+			expected: map[string]any{
+				"mid":                 "98000000",
+				"premium":             "-2000000",
+				"ask_premium_percent": 0.0,
+			},
+			expectedPercent: -2,
+		},
+		{
+			name:          "not configured",
+			args:          map[string]any{"pair": "XBTNGN"},
+			errorContains: "No reference price feed is configured",
+		},
+		{
+			name:          "unknown market",
+			args:          map[string]any{"pair": "DOGENGN"},
+			source:        fixedSource(t, "1", reference.Pair{}),
+			errorContains: "Unable to compare prices for DOGENGN",
+		},
+		{
+			name: "reference error",
+			args: map[string]any{"pair": "XBTNGN"},
+			source: reference.SourceFunc(func(context.Context, reference.Pair) (reference.Quote, error) {
+				return reference.Quote{}, errors.New("feed unavailable")
+			}),
+			mockSetup: func(c *sdk.MockLunoClient) {
+				c.EXPECT().GetTicker(ctx, &luno.GetTickerRequest{Pair: "XBTNGN"}).Return(&luno.GetTickerResponse{Pair: "XBTNGN"}, nil)
+			},
+			errorContains: "feed unavailable",
+		},
+		{
+			name:   "ticker error",
+			args:   map[string]any{"pair": "XBTNGN"},
+			source: fixedSource(t, "1", reference.Pair{}),
+			mockSetup: func(c *sdk.MockLunoClient) {
+				c.EXPECT().GetTicker(ctx, &luno.GetTickerRequest{Pair: "XBTNGN"}).Return(nil, errors.New(apiErrorStr))
+			},
+			errorContains: apiErrorStr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := sdk.NewMockLunoClient(t)
+			cfg := &config.Config{LunoClient: mockClient, Markets: markets.NewCache(mockClient), Reference: tt.source}
+			mockClient.EXPECT().Markets(ctx, &luno.MarketsRequest{}).Return(listed, nil).Maybe()
+			if tt.mockSetup != nil {
+				tt.mockSetup(mockClient)
+			}
+
+			result, err := HandleGetPricePremium(cfg)(ctx, createMockRequest(tt.args))
+			require.NoError(t, err)
+			text := getTextContentFromResult(t, result)
+			if tt.errorContains != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, text, tt.errorContains)
+				return
+			}
+			require.False(t, result.IsError, text)
+
+			var got map[string]any
+			require.NoError(t, json.Unmarshal([]byte(text), &got))
+			for k, v := range tt.expected {
+				assert.Equal(t, v, got[k], k)
+			}
+			assert.InDelta(t, tt.expectedPercent, got["premium_percent"], 1e-9)
+		})
+	}
+}
+
+// fixedSource returns a reference source quoting price, checking that it is
+// asked for pair when one is given
+func fixedSource(t *testing.T, price string, pair reference.Pair) reference.Source {
+	return reference.SourceFunc(func(_ context.Context, got reference.Pair) (reference.Quote, error) {
+		if pair != (reference.Pair{}) {
+			assert.Equal(t, pair, got)
+		}
+		return reference.Quote{
+			Price:  NewFromString(t, price),
+			Source: "test-index",
+			Time:   time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC),
+		}, nil
+	})
+}
@@ -0,0 +1,155 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/internal/audit"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/orders"
+	"github.com/luno/luno-mcp/internal/reconcile"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	defaultReconcileHours = 24
+	maxReconcileHours     = 720
+)
+
+// reconcileNotes explains what the server can and can't compare
+var reconcileNotes = []string{
+	"Recorded trades are the fills of orders placed through this server; recorded transfers are fiat withdrawals confirmed through it. " +
+		"Deposits, crypto sends and anything done in the Luno app or another API client show up as unexplained.",
+	"Withdrawals are read from the audit log, which is kept in memory and capped, so those made before the server started are unexplained.",
+	"A withdrawal still being processed may not have a transaction yet, and a fill near the start of the window may be counted on one side only.",
+	"This server has no paper trading or DCA plans, so there are no simulated trades to compare.",
+}
+
+// ReconcileResult is the reconcile result
+type ReconcileResult struct {
+	*reconcile.Report
+	Message string   `json:"message"`
+	Notes   []string `json:"notes"`
+}
+
+// NewReconcileTool creates a tool for comparing recorded activity with the account
+func NewReconcileTool() mcp.Tool {
+	return mcp.NewTool(
+		ReconcileToolID,
+		mcp.WithDescription("Compare the trades and withdrawals made through this server with the account's transactions and orders on Luno over a window. "+
+			"Reports, per currency, the balance change from trades, transfers and fees against what the server recorded, "+
+			"and lists discrepancies such as orders placed elsewhere, fills the server missed and unexplained transfers."),
+		mcp.WithNumber(
+			"hours",
+			mcp.Description(fmt.Sprintf("How many hours back to compare (default: %d, max: %d)", defaultReconcileHours, maxReconcileHours)),
+			mcp.Min(1),
+			mcp.Max(maxReconcileHours),
+		),
+	)
+}
+
+// reconcileArgs are the arguments of the reconcile tool
+type reconcileArgs struct {
+	Hours int `json:"hours"`
+}
+
+// HandleReconcile handles the reconcile tool
+func HandleReconcile(cfg *config.Config) server.ToolHandlerFunc {
+	tool := NewReconcileTool()
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		cfg := cfg.ForContext(ctx)
+		if !cfg.IsAuthenticated {
+			return mcp.NewToolResultError(ErrAPICredentialsRequired), nil
+		}
+		args := reconcileArgs{Hours: defaultReconcileHours}
+		if errResult := bindArguments(request, tool, &args); errResult != nil {
+			return errResult, nil
+		}
+		hours := args.Hours
+
+		now := time.Now()
+		in := reconcile.Input{
+			Since:       now.Add(-time.Duration(hours) * time.Hour),
+			Now:         now,
+			Withdrawals: recordedWithdrawals(cfg.Audit, cfg.Tenant),
+			Market:      MarketCache(cfg).Lookup,
+		}
+		if cfg.Orders != nil {
+			tracked, err := cfg.Orders.List(cfg.Tenant)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to read tracked orders: %v", err)), nil
+			}
+			in.Tracked = tracked
+		}
+
+		r, err := reconcile.Build(ctx, cfg.LunoClient, in)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to reconcile: %v", err)), nil
+		}
+		result := ReconcileResult{Report: r, Notes: reconcileNotes}
+		switch n := len(r.Discrepancies); n {
+		case 0:
+			result.Message = fmt.Sprintf("Everything on the account in the last %d hours matches what this server recorded.", hours)
+		case 1:
+			result.Message = fmt.Sprintf("Found 1 discrepancy in the last %d hours.", hours)
+		default:
+			result.Message = fmt.Sprintf("Found %d discrepancies in the last %d hours.", n, hours)
+		}
+		if cfg.Orders == nil || cfg.Orders.Path() == "" {
+			result.Message += " " + trackedOrdersNote(cfg.Orders)
+		}
+
+		resultJSON, err := marshalJSON(result)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal reconciliation: %v", err)), nil
+		}
+		return mcp.NewToolResultText(resultJSON), nil
+	}
+}
+
+func trackedOrdersNote(s *orders.Store) string {
+	if s == nil {
+		return "Order tracking is not available on this server, so every trade is unexplained."
+	}
+	return "Orders are only tracked in memory, so those placed before the server started are unexplained."
+}
+
+// recordedWithdrawals returns the fiat withdrawals of tenant confirmed through
+// the server that are still in the audit log
+func recordedWithdrawals(log *audit.Log, tenant string) []reconcile.Withdrawal {
+	if log == nil {
+		return nil
+	}
+	var withdrawals []reconcile.Withdrawal
+	for before := int64(0); ; {
+		entries, next := log.Recent(tenant, before, audit.DefaultCapacity)
+		for _, e := range entries {
+			if e.Tool != CreateFiatWithdrawalToolID || e.Error != "" {
+				continue
+			}
+			var args struct {
+				Type    string `json:"type"`
+				Amount  string `json:"amount"`
+				Confirm bool   `json:"confirm"`
+			}
+			if json.Unmarshal([]byte(e.Arguments), &args) != nil || !args.Confirm {
+				continue
+			}
+			amount, err := decimal.NewFromString(strings.TrimSpace(args.Amount))
+			if err != nil {
+				continue
+			}
+			currency, _, _ := strings.Cut(strings.ToUpper(strings.TrimSpace(args.Type)), "_")
+			withdrawals = append(withdrawals, reconcile.Withdrawal{Currency: currency, Amount: amount, Time: e.Time})
+		}
+		if next == 0 {
+			return withdrawals
+		}
+		before = next
+	}
+}
@@ -0,0 +1,83 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/audit"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/orders"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordedWithdrawals(t *testing.T) {
+	log := audit.NewLog(0)
+	at := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	log.Record(audit.Entry{Tool: CreateFiatWithdrawalToolID, Time: at, Arguments: `{"type":"MYR_IBG","amount":"70","confirm":true}`})
+	log.Record(audit.Entry{Tool: CreateFiatWithdrawalToolID, Time: at.Add(time.Hour), Arguments: `{"type":"zar_eft","amount":"200","confirm":true}`})
+	// Previews, failures and other tools are skipped
+	log.Record(audit.Entry{Tool: CreateFiatWithdrawalToolID, Time: at, Arguments: `{"type":"ZAR_EFT","amount":"300"}`})
+	log.Record(audit.Entry{Tool: CreateFiatWithdrawalToolID, Time: at, Arguments: `{"type":"ZAR_EFT","amount":"400","confirm":true}`, Error: "Failed"})
+	log.Record(audit.Entry{Tool: GetBalancesToolID, Time: at})
+
+	assert.Nil(t, recordedWithdrawals(nil, ""))
+	got := recordedWithdrawals(log, "")
+	require.Len(t, got, 2)
+	assert.Equal(t, "ZAR", got[0].Currency)
+	assert.Equal(t, "200", got[0].Amount.String())
+	assert.Equal(t, at.Add(time.Hour), got[0].Time)
+	assert.Equal(t, "MYR", got[1].Currency)
+}
+
+func TestHandleReconcile(t *testing.T) {
+	tests := []struct {
+		name            string
+		isAuthenticated bool
+		args            map[string]any
+		noStore         bool
+		errorContains   string
+		messageContains string
+	}{
+		{name: "nothing to reconcile", isAuthenticated: true, messageContains: "matches what this server recorded. Orders are only tracked in memory"},
+		{name: "without order tracking", isAuthenticated: true, noStore: true, args: map[string]any{"hours": float64(48)},
+			messageContains: "in the last 48 hours matches what this server recorded. Order tracking is not available"},
+		{name: "requires credentials", errorContains: ErrAPICredentialsRequired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			mockClient := sdk.NewMockLunoClient(t)
+			cfg := &config.Config{LunoClient: mockClient, IsAuthenticated: tt.isAuthenticated}
+			if !tt.noStore {
+				cfg.Orders = orders.New("")
+			}
+			if tt.errorContains == "" {
+				mockClient.EXPECT().GetBalances(ctx, &luno.GetBalancesRequest{}).Return(&luno.GetBalancesResponse{}, nil)
+				mockClient.EXPECT().ListOrders(ctx, mock.Anything).Return(&luno.ListOrdersResponse{}, nil)
+			}
+
+			result, err := HandleReconcile(cfg)(ctx, createMockRequest(tt.args))
+			require.NoError(t, err)
+			text := getTextContentFromResult(t, result)
+			if tt.errorContains != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, text, tt.errorContains)
+				return
+			}
+			require.False(t, result.IsError, text)
+
+			var got ReconcileResult
+			require.NoError(t, json.Unmarshal([]byte(text), &got))
+			assert.Empty(t, got.Discrepancies)
+			assert.Contains(t, got.Message, tt.messageContains)
+			assert.NotEmpty(t, got.Notes)
+		})
+	}
+}
@@ -0,0 +1,250 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/markets"
+	"github.com/luno/luno-mcp/internal/orders"
+	"github.com/luno/luno-mcp/internal/validate"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Outcomes of a replace_order call
+const (
+	ReplaceStatusReplaced             = "replaced"
+	ReplaceStatusAlreadyComplete      = "already_complete"
+	ReplaceStatusFilledBeforeCancel   = "filled_before_cancel"
+	ReplaceStatusCancelledNotReplaced = "cancelled_not_replaced"
+)
+
+// ReplaceOrderResult reports exactly what a replace_order call did
+type ReplaceOrderResult struct {
+	Status          string `json:"status"`
+	OriginalOrderID string `json:"original_order_id"`
+	// OriginalVolume is the limit volume of the original order
+	OriginalVolume decimal.Decimal `json:"original_volume"`
+	// FilledVolume is how much of the original order filled before it was cancelled
+	FilledVolume decimal.Decimal `json:"filled_volume"`
+	NewOrderID   string          `json:"new_order_id,omitempty"`
+	NewPrice     decimal.Decimal `json:"new_price,omitzero"`
+	NewVolume    decimal.Decimal `json:"new_volume,omitzero"`
+	// MarketStatus is the trading status of the order's market, when known
+	MarketStatus luno.TradingStatus `json:"market_status,omitempty"`
+	Message      string             `json:"message"`
+}
+
+// NewReplaceOrderTool creates a tool that cancels an open limit order and places a new one
+func NewReplaceOrderTool() mcp.Tool {
+	return mcp.NewTool(
+		ReplaceOrderToolID,
+		mcp.WithDescription("Replace an open limit order with a new price and/or volume by cancelling it and placing a new order on the same pair and side. "+
+			"The replacement is checked against the market's limits before the original is cancelled. "+
+			"If the original order partially fills before the cancel takes effect, the new order defaults to the unfilled volume, and a given volume is reduced by what filled. "+
+			"The result reports what was filled, cancelled and placed."+writeOperationNotice),
+		mcp.WithString(
+			"order_id",
+			mcp.Required(),
+			mcp.Description("ID of the open order to replace"),
+		),
+		mcp.WithString(
+			"price",
+			mcp.Description("New limit price as a decimal string (default: the original price)"),
+			validate.Decimal(),
+			validate.ExclusiveMin(0),
+		),
+		mcp.WithString(
+			"volume",
+			mcp.Description("New order volume as a decimal string (default: the original order's unfilled volume)"),
+			validate.Decimal(),
+			validate.ExclusiveMin(0),
+		),
+		withAllowFarFromMarket(),
+	)
+}
+
+// replaceOrderArgs are the arguments of the replace_order tool
+type replaceOrderArgs struct {
+	OrderID            string           `json:"order_id"`
+	Price              *decimal.Decimal `json:"price"`
+	Volume             *decimal.Decimal `json:"volume"`
+	AllowFarFromMarket bool             `json:"allow_far_from_market"`
+}
+
+// HandleReplaceOrder handles the replace_order tool
+func HandleReplaceOrder(cfg *config.Config) server.ToolHandlerFunc {
+	tool := NewReplaceOrderTool()
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		cfg := cfg.ForContext(ctx)
+		if !cfg.IsAuthenticated {
+			return mcp.NewToolResultError(ErrAPICredentialsRequired), nil
+		}
+
+		var args replaceOrderArgs
+		if errResult := bindArguments(request, tool, &args); errResult != nil {
+			return errResult, nil
+		}
+		orderID := args.OrderID
+		if args.Price == nil && args.Volume == nil {
+			return mcp.NewToolResultError("At least one of price or volume is required"), nil
+		}
+
+		original, err := cfg.LunoClient.GetOrder(ctx, &luno.GetOrderRequest{Id: orderID})
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to get order %s: %v", orderID, err)), nil
+		}
+		if original.Type != luno.OrderTypeBid && original.Type != luno.OrderTypeAsk {
+			return mcp.NewToolResultError(fmt.Sprintf("Order %s is a %s order; only limit orders can be replaced", orderID, original.Type)), nil
+		}
+
+		result := ReplaceOrderResult{
+			OriginalOrderID: orderID,
+			OriginalVolume:  original.LimitVolume,
+			FilledVolume:    original.Base,
+		}
+
+		if original.State != luno.OrderStatePending {
+			result.Status = ReplaceStatusAlreadyComplete
+			result.Message = "The order is already complete, so nothing was cancelled or placed."
+			return replaceOrderResult(result, true)
+		}
+
+		// Don't cancel an order on a pair outside the trading pairs, since its
+		// replacement couldn't be placed
+		if errResult := checkTradingPair(cfg, original.Pair); errResult != nil {
+			return errResult, nil
+		}
+
+		// Don't cancel the order if its replacement is bound to be rejected. Luno
+		// checks the order anyway, so carry on if the markets can't be listed.
+		market, ok, err := MarketCache(cfg).Lookup(ctx, original.Pair)
+		if err != nil {
+			slog.WarnContext(ctx, "Failed to look up market for order replacement", "pair", original.Pair, "error", err)
+		} else if ok && market.TradingStatus == luno.TradingStatusSuspended {
+			return mcp.NewToolResultError(fmt.Sprintf("Order %s was not replaced: trading on %s is suspended, so a replacement would be rejected. "+
+				"The original order was left open; use get_exchange_status to check market status.", orderID, original.Pair)), nil
+		}
+		result.MarketStatus = market.TradingStatus
+		postOnly := market.TradingStatus == luno.TradingStatusPost_only
+
+		// Check the replacement as it stands now against the market's limits, as
+		// create_order does, so that the original isn't cancelled for nothing
+		newPrice, newVolume := original.LimitPrice, original.LimitVolume.Sub(original.Base)
+		if args.Price != nil {
+			newPrice = *args.Price
+		}
+		if args.Volume != nil {
+			newVolume = *args.Volume
+		}
+		if err == nil && ok {
+			if err := markets.CheckOrder(market, newVolume, newPrice); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Order %s was not replaced: %v. The original order was left open.", orderID, err)), nil
+			}
+		}
+
+		// Only a new price is checked; the original was checked when placed
+		if args.Price != nil {
+			if err := checkPriceDeviation(ctx, cfg, original.Pair, *args.Price, args.AllowFarFromMarket); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Order %s was not replaced: %v. The original order was left open.", orderID, err)), nil
+			}
+		}
+
+		if _, err := cfg.LunoClient.StopOrder(ctx, &luno.StopOrderRequest{OrderId: orderID}); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to cancel order %s: %v. No replacement order was placed.", orderID, err)), nil
+		}
+
+		// The order may have traded between reading it and cancelling it
+		cancelled, err := cfg.LunoClient.GetOrder(ctx, &luno.GetOrderRequest{Id: orderID})
+		if err != nil {
+			result.Status = ReplaceStatusCancelledNotReplaced
+			result.Message = fmt.Sprintf("The order was cancelled but its final fill could not be read (%v), so no replacement was placed to avoid over-trading.", err)
+			return replaceOrderResult(result, true)
+		}
+		result.FilledVolume = cancelled.Base
+
+		remaining := cancelled.LimitVolume.Sub(cancelled.Base)
+		if remaining.Sign() <= 0 {
+			result.Status = ReplaceStatusFilledBeforeCancel
+			result.Message = "The order filled completely before it could be cancelled, so no replacement was placed."
+			return replaceOrderResult(result, true)
+		}
+
+		// Whatever filled while the order was being cancelled has already traded,
+		// so it comes off the replacement: the unfilled volume by default, and an
+		// explicit volume, which was asked for before the fill, likewise
+		raceFill := cancelled.Base.Sub(original.Base)
+		if args.Volume == nil {
+			newVolume = remaining
+		} else if raceFill.Sign() > 0 {
+			newVolume = newVolume.Sub(raceFill)
+			if newVolume.Sign() <= 0 {
+				result.Status = ReplaceStatusFilledBeforeCancel
+				result.Message = fmt.Sprintf("The order filled %s while it was being cancelled, which covers the requested volume of %s, so no replacement was placed.",
+					raceFill, *args.Volume)
+				return replaceOrderResult(result, true)
+			}
+		}
+		result.NewPrice = newPrice
+		result.NewVolume = newVolume
+
+		slog.InfoContext(ctx, "Replacing order",
+			"order_id", orderID,
+			"pair", original.Pair,
+			"filled", cancelled.Base.String(),
+			"price", newPrice.String(),
+			"volume", newVolume.String())
+
+		placed, err := cfg.LunoClient.PostLimitOrder(ctx, &luno.PostLimitOrderRequest{
+			Pair:     original.Pair,
+			Type:     original.Type,
+			Price:    newPrice,
+			Volume:   newVolume,
+			PostOnly: postOnly,
+		})
+		if err != nil {
+			result.Status = ReplaceStatusCancelledNotReplaced
+			result.Message = fmt.Sprintf("The original order was cancelled but the replacement failed: %v", err)
+			if postOnly {
+				result.Message += ". The market is post-only, so replacements that would trade immediately are rejected."
+			}
+			return replaceOrderResult(result, true)
+		}
+
+		trackOrder(ctx, cfg, ReplaceOrderToolID, orders.Order{
+			OrderID:      placed.OrderId,
+			Pair:         original.Pair,
+			Type:         original.Type,
+			Volume:       newVolume,
+			Price:        newPrice,
+			Confirmation: map[string]string{"replaces_order_id": orderID},
+		})
+		result.Status = ReplaceStatusReplaced
+		result.NewOrderID = placed.OrderId
+		result.Message = "The original order was cancelled and the replacement placed."
+		switch {
+		case args.Volume != nil && raceFill.Sign() > 0:
+			result.Message = fmt.Sprintf("The original order filled %s while it was being cancelled, so the replacement was placed for %s rather than the requested %s.",
+				raceFill, newVolume, *args.Volume)
+		case cancelled.Base.Sign() > 0:
+			result.Message = fmt.Sprintf("The original order filled %s before it was cancelled; the replacement was placed.", cancelled.Base.String())
+		}
+		return replaceOrderResult(result, false)
+	}
+}
+
+// replaceOrderResult renders the result, marking it as an error when no replacement was placed
+func replaceOrderResult(result ReplaceOrderResult, isError bool) (*mcp.CallToolResult, error) {
+	resultJSON, err := marshalJSON(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
+	}
+	if isError {
+		return mcp.NewToolResultError(resultJSON), nil
+	}
+	return mcp.NewToolResultText(resultJSON), nil
+}
@@ -0,0 +1,345 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleReplaceOrder(t *testing.T) {
+	pending := &luno.GetOrderResponse{
+		OrderId:     "BXMC2CJ7HNB88U4",
+		Pair:        "XBTZAR",
+		Type:        luno.OrderTypeBid,
+		State:       luno.OrderStatePending,
+		LimitPrice:  NewFromString(t, "1000000"),
+		LimitVolume: NewFromString(t, "0.5"),
+		Base:        NewFromString(t, "0"),
+	}
+	cancelledWith := func(base string) *luno.GetOrderResponse {
+		o := *pending
+		o.State = luno.OrderStateComplete
+		o.Base = NewFromString(t, base)
+		return &o
+	}
+	marketsWith := func(status luno.TradingStatus) *luno.MarketsResponse {
+		return &luno.MarketsResponse{Markets: []luno.MarketInfo{{MarketId: "XBTZAR", TradingStatus: status}}}
+	}
+	getOrder := &luno.GetOrderRequest{Id: "BXMC2CJ7HNB88U4"}
+	stopOrder := &luno.StopOrderRequest{OrderId: "BXMC2CJ7HNB88U4"}
+
+	tests := []struct {
+		name            string
+		requestParams   map[string]any
+		mockSetup       func(*sdk.MockLunoClient)
+		isAuthenticated bool
+		expectedError   bool
+		errorContains   string
+		expectedStatus  string
+		expectedResult  func(*testing.T, ReplaceOrderResult)
+	}{
+		{
+			name:          "replace at new price keeps unfilled volume",
+			requestParams: map[string]any{"order_id": "BXMC2CJ7HNB88U4", "price": "990000"},
+			mockSetup: func(m *sdk.MockLunoClient) {
+				m.EXPECT().GetOrder(context.Background(), getOrder).Return(pending, nil).Once()
+				m.EXPECT().StopOrder(context.Background(), stopOrder).Return(&luno.StopOrderResponse{Success: true}, nil)
+				m.EXPECT().GetOrder(context.Background(), getOrder).Return(cancelledWith("0"), nil).Once()
+				m.EXPECT().PostLimitOrder(context.Background(), &luno.PostLimitOrderRequest{
+					Pair:   "XBTZAR",
+					Type:   luno.OrderTypeBid,
+					Price:  NewFromString(t, "990000"),
+					Volume: NewFromString(t, "0.5"),
+				}).Return(&luno.PostLimitOrderResponse{OrderId: "BXNEW"}, nil)
+			},
+			isAuthenticated: true,
+			expectedStatus:  ReplaceStatusReplaced,
+			expectedResult: func(t *testing.T, r ReplaceOrderResult) {
+				assert.Equal(t, "BXNEW", r.NewOrderID)
+				assert.Equal(t, "0.5", r.NewVolume.String())
+				assert.Equal(t, luno.TradingStatusActive, r.MarketStatus)
+			},
+		},
+		{
+			name:          "post-only market places a post-only replacement",
+			requestParams: map[string]any{"order_id": "BXMC2CJ7HNB88U4", "price": "990000"},
+			mockSetup: func(m *sdk.MockLunoClient) {
+				m.EXPECT().Markets(context.Background(), &luno.MarketsRequest{}).Return(marketsWith(luno.TradingStatusPost_only), nil)
+				m.EXPECT().GetOrder(context.Background(), getOrder).Return(pending, nil).Once()
+				m.EXPECT().StopOrder(context.Background(), stopOrder).Return(&luno.StopOrderResponse{Success: true}, nil)
+				m.EXPECT().GetOrder(context.Background(), getOrder).Return(cancelledWith("0"), nil).Once()
+				m.EXPECT().PostLimitOrder(context.Background(), &luno.PostLimitOrderRequest{
+					Pair:     "XBTZAR",
+					Type:     luno.OrderTypeBid,
+					Price:    NewFromString(t, "990000"),
+					Volume:   NewFromString(t, "0.5"),
+					PostOnly: true,
+				}).Return(&luno.PostLimitOrderResponse{OrderId: "BXNEW"}, nil)
+			},
+			isAuthenticated: true,
+			expectedStatus:  ReplaceStatusReplaced,
+			expectedResult: func(t *testing.T, r ReplaceOrderResult) {
+				assert.Equal(t, luno.TradingStatusPost_only, r.MarketStatus)
+			},
+		},
+		{
+			name:          "suspended market leaves the order open",
+			requestParams: map[string]any{"order_id": "BXMC2CJ7HNB88U4", "price": "990000"},
+			mockSetup: func(m *sdk.MockLunoClient) {
+				m.EXPECT().Markets(context.Background(), &luno.MarketsRequest{}).Return(marketsWith(luno.TradingStatusSuspended), nil)
+				m.EXPECT().GetOrder(context.Background(), getOrder).Return(pending, nil).Once()
+			},
+			isAuthenticated: true,
+			expectedError:   true,
+			errorContains:   "trading on XBTZAR is suspended, so a replacement would be rejected. The original order was left open",
+		},
+		{
+			name:          "markets lookup failure does not block the replacement",
+			requestParams: map[string]any{"order_id": "BXMC2CJ7HNB88U4", "price": "990000"},
+			mockSetup: func(m *sdk.MockLunoClient) {
+				m.EXPECT().Markets(context.Background(), &luno.MarketsRequest{}).Return(nil, errors.New(apiErrorStr))
+				m.EXPECT().GetOrder(context.Background(), getOrder).Return(pending, nil).Once()
+				m.EXPECT().StopOrder(context.Background(), stopOrder).Return(&luno.StopOrderResponse{Success: true}, nil)
+				m.EXPECT().GetOrder(context.Background(), getOrder).Return(cancelledWith("0"), nil).Once()
+				m.EXPECT().PostLimitOrder(context.Background(), &luno.PostLimitOrderRequest{
+					Pair:   "XBTZAR",
+					Type:   luno.OrderTypeBid,
+					Price:  NewFromString(t, "990000"),
+					Volume: NewFromString(t, "0.5"),
+				}).Return(&luno.PostLimitOrderResponse{OrderId: "BXNEW"}, nil)
+			},
+			isAuthenticated: true,
+			expectedStatus:  ReplaceStatusReplaced,
+		},
+		{
+			name:          "partial fill during cancel shrinks replacement",
+			requestParams: map[string]any{"order_id": "BXMC2CJ7HNB88U4", "price": "990000"},
+			mockSetup: func(m *sdk.MockLunoClient) {
+				m.EXPECT().GetOrder(context.Background(), getOrder).Return(pending, nil).Once()
+				m.EXPECT().StopOrder(context.Background(), stopOrder).Return(&luno.StopOrderResponse{Success: true}, nil)
+				m.EXPECT().GetOrder(context.Background(), getOrder).Return(cancelledWith("0.2"), nil).Once()
+				m.EXPECT().PostLimitOrder(context.Background(), &luno.PostLimitOrderRequest{
+					Pair:   "XBTZAR",
+					Type:   luno.OrderTypeBid,
+					Price:  NewFromString(t, "990000"),
+					Volume: NewFromString(t, "0.3"),
+				}).Return(&luno.PostLimitOrderResponse{OrderId: "BXNEW"}, nil)
+			},
+			isAuthenticated: true,
+			expectedStatus:  ReplaceStatusReplaced,
+			expectedResult: func(t *testing.T, r ReplaceOrderResult) {
+				assert.Equal(t, "0.2", r.FilledVolume.String())
+				assert.Equal(t, "0.3", r.NewVolume.String())
+				assert.Contains(t, r.Message, "filled 0.2")
+			},
+		},
+		{
+			name:          "explicit volume keeps original price",
+			requestParams: map[string]any{"order_id": "BXMC2CJ7HNB88U4", "volume": "0.1"},
+			mockSetup: func(m *sdk.MockLunoClient) {
+				m.EXPECT().GetOrder(context.Background(), getOrder).Return(pending, nil).Once()
+				m.EXPECT().StopOrder(context.Background(), stopOrder).Return(&luno.StopOrderResponse{Success: true}, nil)
+				m.EXPECT().GetOrder(context.Background(), getOrder).Return(cancelledWith("0"), nil).Once()
+				m.EXPECT().PostLimitOrder(context.Background(), &luno.PostLimitOrderRequest{
+					Pair:   "XBTZAR",
+					Type:   luno.OrderTypeBid,
+					Price:  NewFromString(t, "1000000"),
+					Volume: NewFromString(t, "0.1"),
+				}).Return(&luno.PostLimitOrderResponse{OrderId: "BXNEW"}, nil)
+			},
+			isAuthenticated: true,
+			expectedStatus:  ReplaceStatusReplaced,
+		},
+		{
+			name:          "partial fill during cancel shrinks explicit volume",
+			requestParams: map[string]any{"order_id": "BXMC2CJ7HNB88U4", "volume": "0.4"},
+			mockSetup: func(m *sdk.MockLunoClient) {
+				m.EXPECT().GetOrder(context.Background(), getOrder).Return(pending, nil).Once()
+				m.EXPECT().StopOrder(context.Background(), stopOrder).Return(&luno.StopOrderResponse{Success: true}, nil)
+				m.EXPECT().GetOrder(context.Background(), getOrder).Return(cancelledWith("0.1"), nil).Once()
+				m.EXPECT().PostLimitOrder(context.Background(), &luno.PostLimitOrderRequest{
+					Pair:   "XBTZAR",
+					Type:   luno.OrderTypeBid,
+					Price:  NewFromString(t, "1000000"),
+					Volume: NewFromString(t, "0.3"),
+				}).Return(&luno.PostLimitOrderResponse{OrderId: "BXNEW"}, nil)
+			},
+			isAuthenticated: true,
+			expectedStatus:  ReplaceStatusReplaced,
+			expectedResult: func(t *testing.T, r ReplaceOrderResult) {
+				assert.Equal(t, "0.3", r.NewVolume.String())
+				assert.Contains(t, r.Message, "placed for 0.3 rather than the requested 0.4")
+			},
+		},
+		{
+			name:          "fill during cancel covers explicit volume",
+			requestParams: map[string]any{"order_id": "BXMC2CJ7HNB88U4", "volume": "0.1"},
+			mockSetup: func(m *sdk.MockLunoClient) {
+				m.EXPECT().GetOrder(context.Background(), getOrder).Return(pending, nil).Once()
+				m.EXPECT().StopOrder(context.Background(), stopOrder).Return(&luno.StopOrderResponse{Success: true}, nil)
+				m.EXPECT().GetOrder(context.Background(), getOrder).Return(cancelledWith("0.2"), nil).Once()
+			},
+			isAuthenticated: true,
+			expectedError:   true,
+			expectedStatus:  ReplaceStatusFilledBeforeCancel,
+			expectedResult: func(t *testing.T, r ReplaceOrderResult) {
+				assert.Equal(t, "0.2", r.FilledVolume.String())
+				assert.Contains(t, r.Message, "covers the requested volume of 0.1")
+			},
+		},
+		{
+			name:          "replacement outside market limits leaves the order open",
+			requestParams: map[string]any{"order_id": "BXMC2CJ7HNB88U4", "volume": "0.00001"},
+			mockSetup: func(m *sdk.MockLunoClient) {
+				m.EXPECT().GetOrder(context.Background(), getOrder).Return(pending, nil).Once()
+				m.EXPECT().Markets(context.Background(), &luno.MarketsRequest{}).Return(&luno.MarketsResponse{Markets: []luno.MarketInfo{{
+					MarketId:      "XBTZAR",
+					TradingStatus: luno.TradingStatusActive,
+					MinVolume:     NewFromString(t, "0.0005"),
+				}}}, nil)
+			},
+			isAuthenticated: true,
+			expectedError:   true,
+			errorContains:   "was not replaced: volume 0.00001 is below the minimum of 0.0005 for XBTZAR. The original order was left open.",
+		},
+		{
+			name:          "fully filled before cancel",
+			requestParams: map[string]any{"order_id": "BXMC2CJ7HNB88U4", "price": "990000"},
+			mockSetup: func(m *sdk.MockLunoClient) {
+				m.EXPECT().GetOrder(context.Background(), getOrder).Return(pending, nil).Once()
+				m.EXPECT().StopOrder(context.Background(), stopOrder).Return(&luno.StopOrderResponse{Success: true}, nil)
+				m.EXPECT().GetOrder(context.Background(), getOrder).Return(cancelledWith("0.5"), nil).Once()
+			},
+			isAuthenticated: true,
+			expectedError:   true,
+			expectedStatus:  ReplaceStatusFilledBeforeCancel,
+		},
+		{
+			name:          "order already complete",
+			requestParams: map[string]any{"order_id": "BXMC2CJ7HNB88U4", "price": "990000"},
+			mockSetup: func(m *sdk.MockLunoClient) {
+				m.EXPECT().GetOrder(context.Background(), getOrder).Return(cancelledWith("0.5"), nil)
+			},
+			isAuthenticated: true,
+			expectedError:   true,
+			expectedStatus:  ReplaceStatusAlreadyComplete,
+		},
+		{
+			name:          "replacement fails after cancel",
+			requestParams: map[string]any{"order_id": "BXMC2CJ7HNB88U4", "price": "990000"},
+			mockSetup: func(m *sdk.MockLunoClient) {
+				m.EXPECT().GetOrder(context.Background(), getOrder).Return(pending, nil).Once()
+				m.EXPECT().StopOrder(context.Background(), stopOrder).Return(&luno.StopOrderResponse{Success: true}, nil)
+				m.EXPECT().GetOrder(context.Background(), getOrder).Return(cancelledWith("0"), nil).Once()
+				m.EXPECT().PostLimitOrder(context.Background(), &luno.PostLimitOrderRequest{
+					Pair:   "XBTZAR",
+					Type:   luno.OrderTypeBid,
+					Price:  NewFromString(t, "990000"),
+					Volume: NewFromString(t, "0.5"),
+				}).Return(nil, errors.New(apiErrorStr))
+			},
+			isAuthenticated: true,
+			expectedError:   true,
+			expectedStatus:  ReplaceStatusCancelledNotReplaced,
+		},
+		{
+			name:          "reading final fill fails",
+			requestParams: map[string]any{"order_id": "BXMC2CJ7HNB88U4", "price": "990000"},
+			mockSetup: func(m *sdk.MockLunoClient) {
+				m.EXPECT().GetOrder(context.Background(), getOrder).Return(pending, nil).Once()
+				m.EXPECT().StopOrder(context.Background(), stopOrder).Return(&luno.StopOrderResponse{Success: true}, nil)
+				m.EXPECT().GetOrder(context.Background(), getOrder).Return(nil, errors.New(apiErrorStr)).Once()
+			},
+			isAuthenticated: true,
+			expectedError:   true,
+			expectedStatus:  ReplaceStatusCancelledNotReplaced,
+		},
+		{
+			name:          "cancel fails",
+			requestParams: map[string]any{"order_id": "BXMC2CJ7HNB88U4", "price": "990000"},
+			mockSetup: func(m *sdk.MockLunoClient) {
+				m.EXPECT().GetOrder(context.Background(), getOrder).Return(pending, nil)
+				m.EXPECT().StopOrder(context.Background(), stopOrder).Return(nil, errors.New(apiErrorStr))
+			},
+			isAuthenticated: true,
+			expectedError:   true,
+			errorContains:   "No replacement order was placed",
+		},
+		{
+			name:          "market order cannot be replaced",
+			requestParams: map[string]any{"order_id": "BXMC2CJ7HNB88U4", "price": "990000"},
+			mockSetup: func(m *sdk.MockLunoClient) {
+				o := *pending
+				o.Type = luno.OrderTypeBuy
+				m.EXPECT().GetOrder(context.Background(), getOrder).Return(&o, nil)
+			},
+			isAuthenticated: true,
+			expectedError:   true,
+			errorContains:   "only limit orders can be replaced",
+		},
+		{
+			name:            "missing price and volume",
+			requestParams:   map[string]any{"order_id": "BXMC2CJ7HNB88U4"},
+			mockSetup:       func(m *sdk.MockLunoClient) {},
+			isAuthenticated: true,
+			expectedError:   true,
+			errorContains:   "At least one of price or volume is required",
+		},
+		{
+			name:            "invalid price",
+			requestParams:   map[string]any{"order_id": "BXMC2CJ7HNB88U4", "price": "abc"},
+			mockSetup:       func(m *sdk.MockLunoClient) {},
+			isAuthenticated: true,
+			expectedError:   true,
+			errorContains:   `price must be a decimal number such as 0.25, got "abc"`,
+		},
+		{
+			name:            "unauthenticated",
+			requestParams:   map[string]any{"order_id": "BXMC2CJ7HNB88U4", "price": "990000"},
+			mockSetup:       func(m *sdk.MockLunoClient) {},
+			isAuthenticated: false,
+			expectedError:   true,
+			errorContains:   ErrAPICredentialsRequired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := sdk.NewMockLunoClient(t)
+			tt.mockSetup(mockClient)
+			mockClient.EXPECT().Markets(context.Background(), &luno.MarketsRequest{}).Return(marketsWith(luno.TradingStatusActive), nil).Maybe()
+
+			cfg := &config.Config{
+				LunoClient:      mockClient,
+				IsAuthenticated: tt.isAuthenticated,
+			}
+
+			result, err := HandleReplaceOrder(cfg)(context.Background(), createMockRequest(tt.requestParams))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedError, result.IsError)
+
+			text := getTextContentFromResult(t, result)
+			if tt.errorContains != "" {
+				assert.Contains(t, text, tt.errorContains)
+			}
+			if tt.expectedStatus == "" {
+				return
+			}
+
+			var res ReplaceOrderResult
+			require.NoError(t, json.Unmarshal([]byte(text), &res))
+			assert.Equal(t, tt.expectedStatus, res.Status)
+			assert.Equal(t, "BXMC2CJ7HNB88U4", res.OriginalOrderID)
+			if tt.expectedResult != nil {
+				tt.expectedResult(t, res)
+			}
+		})
+	}
+}
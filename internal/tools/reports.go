@@ -0,0 +1,170 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/reports"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// schedule_report actions
+const (
+	reportActionCreate = "create"
+	reportActionList   = "list"
+	reportActionDelete = "delete"
+)
+
+// defaultDigestFilename is the file written into a granted root when no filename is given
+const defaultDigestFilename = "portfolio-digest.json"
+
+// NewScheduleReportTool creates a tool for managing scheduled portfolio digests
+func NewScheduleReportTool() mcp.Tool {
+	return mcp.NewTool(
+		ScheduleReportToolID,
+		mcp.WithDescription("Schedule a daily or weekly portfolio digest (valuation, 24h change, open orders and executed trades), "+
+			"list the scheduled digests, or delete one. Digests can be sent as an MCP notification, POSTed as JSON to a webhook URL, "+
+			"or written to a file in a root granted by the client (see list_roots). Times are in UTC."),
+		mcp.WithString(
+			"action",
+			mcp.Description("What to do (default: create)"),
+			mcp.Enum(reportActionCreate, reportActionList, reportActionDelete),
+		),
+		mcp.WithString(
+			"id",
+			mcp.Description("ID of the schedule to delete"),
+		),
+		mcp.WithString(
+			"frequency",
+			mcp.Description("How often to send the digest (default: daily)"),
+			mcp.Enum(string(reports.FrequencyDaily), string(reports.FrequencyWeekly)),
+		),
+		mcp.WithString(
+			"time",
+			mcp.Description("Time of day to send the digest, as HH:MM in UTC (default: 08:00)"),
+		),
+		mcp.WithString(
+			"weekday",
+			mcp.Description("Day to send weekly digests on, e.g. monday (default: monday)"),
+		),
+		mcp.WithString(
+			"quote_currency",
+			mcp.Description("Currency to value the portfolio in (default: ZAR)"),
+		),
+		mcp.WithString(
+			"destination",
+			mcp.Description("Where to send the digest (default: notification)"),
+			mcp.Enum(string(reports.DestinationNotification), string(reports.DestinationWebhook), string(reports.DestinationFile)),
+		),
+		mcp.WithString(
+			"webhook_url",
+			mcp.Description("URL to POST the digest to, for the webhook destination"),
+		),
+		mcp.WithString(
+			"root",
+			mcp.Description("URI of the granted root to write to, for the file destination (defaults to the first granted root)"),
+		),
+		mcp.WithString(
+			"filename",
+			mcp.Description("File name relative to the root, for the file destination (default: "+defaultDigestFilename+")"),
+		),
+	)
+}
+
+// scheduleReportArgs are the arguments of the schedule_report tool
+type scheduleReportArgs struct {
+	rootFileArgs
+	Action        string                  `json:"action"`
+	ID            string                  `json:"id"`
+	Frequency     reports.Frequency       `json:"frequency"`
+	Time          string                  `json:"time"`
+	Weekday       string                  `json:"weekday"`
+	QuoteCurrency string                  `json:"quote_currency"`
+	Destination   reports.DestinationType `json:"destination"`
+	WebhookURL    string                  `json:"webhook_url"`
+}
+
+// HandleScheduleReport handles the schedule_report tool
+func HandleScheduleReport(cfg *config.Config) server.ToolHandlerFunc {
+	tool := NewScheduleReportTool()
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		cfg := cfg.ForContext(ctx)
+		if cfg.Reports == nil {
+			return mcp.NewToolResultError("Report scheduling is not available on this server"), nil
+		}
+
+		args := scheduleReportArgs{
+			rootFileArgs:  rootFileArgs{Filename: defaultDigestFilename},
+			Action:        reportActionCreate,
+			Frequency:     reports.FrequencyDaily,
+			Time:          "08:00",
+			QuoteCurrency: "ZAR",
+			Destination:   reports.DestinationNotification,
+		}
+		if errResult := bindArguments(request, tool, &args); errResult != nil {
+			return errResult, nil
+		}
+		switch args.Action {
+		case reportActionList:
+			return scheduleReportResult(cfg.Reports.List(cfg.Tenant))
+		case reportActionDelete:
+			if errResult := requireArguments(request, "id"); errResult != nil {
+				return errResult, nil
+			}
+			if !cfg.Reports.Remove(cfg.Tenant, args.ID) {
+				return mcp.NewToolResultError(fmt.Sprintf("No scheduled report with ID %q", args.ID)), nil
+			}
+			return mcp.NewToolResultText(fmt.Sprintf("Deleted scheduled report %s", args.ID)), nil
+		}
+
+		if !cfg.IsAuthenticated {
+			return mcp.NewToolResultError(ErrAPICredentialsRequired), nil
+		}
+
+		schedule := reports.Schedule{
+			Frequency:     args.Frequency,
+			Weekday:       args.Weekday,
+			At:            args.Time,
+			QuoteCurrency: args.QuoteCurrency,
+			Tenant:        cfg.Tenant,
+			Destination: reports.Destination{
+				Type: args.Destination,
+				URL:  args.WebhookURL,
+			},
+		}
+
+		if schedule.Destination.Type == reports.DestinationFile {
+			roots, err := listGrantedRoots(ctx, rootsListerFromContext(ctx))
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr("listing roots", err), nil
+			}
+			path, err := resolveRootPath(roots, args.Root, args.Filename)
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr("resolving report file", err), nil
+			}
+			schedule.Destination.Path = path
+		}
+
+		// Digests are built with the caller's client so per-session credentials are kept
+		var notifier reports.Notifier
+		if srv := server.ServerFromContext(ctx); srv != nil {
+			notifier = srv
+		}
+		added, err := cfg.Reports.Add(schedule, cfg.LunoClient, notifier)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("scheduling report", err), nil
+		}
+
+		return scheduleReportResult(added)
+	}
+}
+
+func scheduleReportResult(v any) (*mcp.CallToolResult, error) {
+	resultJSON, err := marshalJSON(v)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal schedule: %v", err)), nil
+	}
+	return mcp.NewToolResultText(resultJSON), nil
+}
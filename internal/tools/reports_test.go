@@ -0,0 +1,108 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/reports"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleScheduleReport(t *testing.T) {
+	cfg := &config.Config{
+		LunoClient:      sdk.NewMockLunoClient(t),
+		IsAuthenticated: true,
+		Reports:         reports.NewScheduler(""),
+	}
+	handler := HandleScheduleReport(cfg)
+
+	call := func(params map[string]any) (string, bool) {
+		t.Helper()
+		result, err := handler(context.Background(), createMockRequest(params))
+		require.NoError(t, err)
+		return getTextContentFromResult(t, result), result.IsError
+	}
+
+	text, isError := call(map[string]any{"action": "list"})
+	require.False(t, isError)
+	assert.JSONEq(t, "[]", text)
+
+	text, isError = call(map[string]any{
+		"frequency":   "weekly",
+		"weekday":     "friday",
+		"time":        "17:30",
+		"destination": "webhook",
+		"webhook_url": "https://example.com/digest",
+	})
+	require.False(t, isError, text)
+	var created reports.Schedule
+	require.NoError(t, json.Unmarshal([]byte(text), &created))
+	assert.Equal(t, reports.FrequencyWeekly, created.Frequency)
+	assert.Equal(t, "friday", created.Weekday)
+	assert.Equal(t, "ZAR", created.QuoteCurrency)
+	assert.Equal(t, "https://example.com/digest", created.Destination.URL)
+
+	text, isError = call(map[string]any{"action": "list"})
+	require.False(t, isError)
+	assert.Contains(t, text, created.ID)
+
+	text, isError = call(map[string]any{"action": "delete", "id": created.ID})
+	require.False(t, isError)
+	assert.Contains(t, text, created.ID)
+	assert.Empty(t, cfg.Reports.List(""))
+
+	tests := []struct {
+		name          string
+		params        map[string]any
+		errorContains string
+	}{
+		{
+			name:          "delete unknown schedule",
+			params:        map[string]any{"action": "delete", "id": "report-99"},
+			errorContains: "No scheduled report",
+		},
+		{
+			name:          "invalid action",
+			params:        map[string]any{"action": "pause"},
+			errorContains: `action must be one of create, list, delete, got "pause"`,
+		},
+		{
+			name:          "notification without a client session",
+			params:        map[string]any{},
+			errorContains: "connected MCP client",
+		},
+		{
+			name:          "file without granted roots",
+			params:        map[string]any{"destination": "file"},
+			errorContains: "listing roots",
+		},
+		{
+			name:          "invalid schedule",
+			params:        map[string]any{"destination": "webhook", "webhook_url": "https://example.com", "time": "25:00"},
+			errorContains: "invalid time",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, isError := call(tt.params)
+			assert.True(t, isError)
+			assert.Contains(t, text, tt.errorContains)
+		})
+	}
+}
+
+func TestHandleScheduleReportUnavailable(t *testing.T) {
+	result, err := HandleScheduleReport(&config.Config{IsAuthenticated: true})(context.Background(), createMockRequest(map[string]any{}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	result, err = HandleScheduleReport(&config.Config{Reports: reports.NewScheduler("")})(context.Background(), createMockRequest(map[string]any{}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, getTextContentFromResult(t, result), ErrAPICredentialsRequired)
+}
@@ -0,0 +1,196 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/luno/luno-mcp/internal/fsutil"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ErrNoRootsGranted is returned when the client has not granted any file roots
+var ErrNoRootsGranted = errors.New("the client has not granted any file roots")
+
+// RootsLister requests the list of roots granted by the connected MCP client
+type RootsLister interface {
+	RequestRoots(ctx context.Context, request mcp.ListRootsRequest) (*mcp.ListRootsResult, error)
+}
+
+// NewListRootsTool creates a new tool for listing the roots granted by the client
+func NewListRootsTool() mcp.Tool {
+	return mcp.NewTool(
+		ListRootsToolID,
+		mcp.WithDescription("List the file system roots the client has granted this server. "+
+			"Export tools can write files into these directories."),
+	)
+}
+
+// HandleListRoots handles the list_roots tool
+func HandleListRoots() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		roots, err := listGrantedRoots(ctx, rootsListerFromContext(ctx))
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("listing roots", err), nil
+		}
+
+		resultJSON, err := marshalJSON(roots)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal roots: %v", err)), nil
+		}
+
+		return mcp.NewToolResultText(resultJSON), nil
+	}
+}
+
+// rootsListerFromContext returns the MCP server for the current request, or nil if there is none
+func rootsListerFromContext(ctx context.Context) RootsLister {
+	if srv := server.ServerFromContext(ctx); srv != nil {
+		return srv
+	}
+	return nil
+}
+
+// listGrantedRoots returns the file roots granted by the client
+func listGrantedRoots(ctx context.Context, lister RootsLister) ([]mcp.Root, error) {
+	if lister == nil {
+		return nil, server.ErrNoClientSession
+	}
+
+	res, err := lister.RequestRoots(ctx, mcp.ListRootsRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Roots, nil
+}
+
+// resolveRootPath returns the local path for filename inside the granted root identified by rootURI.
+// If rootURI is empty the first granted root is used. The filename must be a local path that
+// does not escape the root.
+func resolveRootPath(roots []mcp.Root, rootURI, filename string) (string, error) {
+	if len(roots) == 0 {
+		return "", ErrNoRootsGranted
+	}
+
+	if !filepath.IsLocal(filename) {
+		return "", fmt.Errorf("invalid file name %q: must be a relative path inside the root", filename)
+	}
+
+	root := roots[0]
+	if rootURI != "" {
+		found := false
+		for _, r := range roots {
+			if r.URI == rootURI {
+				root = r
+				found = true
+				break
+			}
+		}
+		if !found {
+			return "", fmt.Errorf("root %q has not been granted by the client", rootURI)
+		}
+	}
+
+	dir, err := rootDir(root.URI)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, filename), nil
+}
+
+// rootDir converts a file:// root URI into a local directory path
+func rootDir(rootURI string) (string, error) {
+	u, err := url.Parse(rootURI)
+	if err != nil {
+		return "", fmt.Errorf("invalid root URI %q: %w", rootURI, err)
+	}
+	if u.Scheme != "file" {
+		return "", fmt.Errorf("unsupported root URI %q: only file:// roots are supported", rootURI)
+	}
+	return filepath.FromSlash(fileURIPath(u, runtime.GOOS == "windows")), nil
+}
+
+// fileURIPath returns the path a file URI names, with forward slashes. On
+// Windows, file:///C:/Users/me names C:/Users/me, as does file://C:/Users/me
+// which some clients send, and file://server/share names the network share
+// //server/share.
+func fileURIPath(u *url.URL, windows bool) string {
+	if !windows {
+		return u.Path
+	}
+	switch {
+	case isDriveLetter(u.Host):
+		return u.Host + u.Path
+	case u.Host != "" && !strings.EqualFold(u.Host, "localhost"):
+		return "//" + u.Host + u.Path
+	case len(u.Path) >= 3 && u.Path[0] == '/' && isDriveLetter(u.Path[1:3]):
+		return u.Path[1:]
+	}
+	return u.Path
+}
+
+// isDriveLetter reports whether s is a Windows drive such as C:
+func isDriveLetter(s string) bool {
+	return len(s) == 2 && s[1] == ':' && ('a' <= s[0] && s[0] <= 'z' || 'A' <= s[0] && s[0] <= 'Z')
+}
+
+// writeToRoot writes data to filename inside a granted root and returns the path written
+func writeToRoot(ctx context.Context, lister RootsLister, rootURI, filename string, data []byte) (string, error) {
+	roots, err := listGrantedRoots(ctx, lister)
+	if err != nil {
+		return "", err
+	}
+
+	path, err := resolveRootPath(roots, rootURI, filename)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("creating directory for %s: %w", path, err)
+	}
+
+	if err := fsutil.WriteFile(path, data, 0o600); err != nil {
+		return "", fmt.Errorf("writing %s: %w", path, err)
+	}
+
+	return path, nil
+}
+
+// maxReadBytes caps the size of a file read from a granted root
+const maxReadBytes = 10 << 20
+
+// readFromRoot reads filename inside a granted root
+func readFromRoot(ctx context.Context, lister RootsLister, rootURI, filename string) ([]byte, error) {
+	roots, err := listGrantedRoots(ctx, lister)
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := resolveRootPath(roots, rootURI, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	if info.Size() > maxReadBytes {
+		return nil, fmt.Errorf("%s is larger than %d MB", path, maxReadBytes>>20)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return data, nil
+}
@@ -0,0 +1,186 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRootsLister struct {
+	roots []mcp.Root
+	err   error
+}
+
+func (f *fakeRootsLister) RequestRoots(ctx context.Context, request mcp.ListRootsRequest) (*mcp.ListRootsResult, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &mcp.ListRootsResult{Roots: f.roots}, nil
+}
+
+func TestResolveRootPath(t *testing.T) {
+	roots := []mcp.Root{
+		{URI: "file:///tmp/exports", Name: "exports"},
+		{URI: "file:///home/user/reports", Name: "reports"},
+	}
+
+	tests := []struct {
+		name          string
+		roots         []mcp.Root
+		rootURI       string
+		filename      string
+		expected      string
+		errorContains string
+	}{
+		{
+			name:     "defaults to first root",
+			roots:    roots,
+			filename: "trades.csv",
+			expected: filepath.FromSlash("/tmp/exports/trades.csv"),
+		},
+		{
+			name:     "selects requested root",
+			roots:    roots,
+			rootURI:  "file:///home/user/reports",
+			filename: "2024/trades.csv",
+			expected: filepath.FromSlash("/home/user/reports/2024/trades.csv"),
+		},
+		{
+			name:          "no roots granted",
+			filename:      "trades.csv",
+			errorContains: ErrNoRootsGranted.Error(),
+		},
+		{
+			name:          "root not granted",
+			roots:         roots,
+			rootURI:       "file:///etc",
+			filename:      "trades.csv",
+			errorContains: "has not been granted",
+		},
+		{
+			name:          "filename escapes root",
+			roots:         roots,
+			filename:      "../secrets.csv",
+			errorContains: "invalid file name",
+		},
+		{
+			name:          "absolute filename",
+			roots:         roots,
+			filename:      "/etc/passwd",
+			errorContains: "invalid file name",
+		},
+		{
+			name:          "non file root",
+			roots:         []mcp.Root{{URI: "https://example.com"}},
+			filename:      "trades.csv",
+			errorContains: "only file:// roots are supported",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, err := resolveRootPath(tt.roots, tt.rootURI, tt.filename)
+			if tt.errorContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, path)
+		})
+	}
+}
+
+func TestFileURIPath(t *testing.T) {
+	tests := []struct {
+		uri      string
+		windows  string
+		elsewise string
+	}{
+		{uri: "file:///home/user/reports", windows: "/home/user/reports", elsewise: "/home/user/reports"},
+		{uri: "file:///C:/Users/me/My%20Reports", windows: "C:/Users/me/My Reports", elsewise: "/C:/Users/me/My Reports"},
+		{uri: "file://C:/Users/me", windows: "C:/Users/me", elsewise: "/Users/me"},
+		{uri: "file://localhost/D:/exports", windows: "D:/exports", elsewise: "/D:/exports"},
+		{uri: "file://fileserver/share/exports", windows: "//fileserver/share/exports", elsewise: "/share/exports"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			u, err := url.Parse(tt.uri)
+			require.NoError(t, err)
+			assert.Equal(t, tt.windows, fileURIPath(u, true))
+			assert.Equal(t, tt.elsewise, fileURIPath(u, false))
+		})
+	}
+}
+
+func TestWriteToRoot(t *testing.T) {
+	dir := t.TempDir()
+	rootURI := "file://" + filepath.ToSlash(dir)
+
+	tests := []struct {
+		name          string
+		lister        RootsLister
+		filename      string
+		errorContains string
+	}{
+		{
+			name:     "writes file into root",
+			lister:   &fakeRootsLister{roots: []mcp.Root{{URI: rootURI}}},
+			filename: "nested/out.csv",
+		},
+		{
+			name:          "no session",
+			lister:        nil,
+			filename:      "out.csv",
+			errorContains: "no active client session",
+		},
+		{
+			name:          "client does not support roots",
+			lister:        &fakeRootsLister{err: errors.New("session does not support roots")},
+			filename:      "out.csv",
+			errorContains: "does not support roots",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, err := writeToRoot(context.Background(), tt.lister, "", tt.filename, []byte("a,b\n"))
+			if tt.errorContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, filepath.Join(dir, tt.filename), path)
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, "a,b\n", string(data))
+		})
+	}
+}
+
+func TestReadFromRoot(t *testing.T) {
+	dir := t.TempDir()
+	lister := &fakeRootsLister{roots: []mcp.Root{{URI: "file://" + filepath.ToSlash(dir)}}}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "trades.csv"), []byte("a,b\n"), 0o600))
+
+	data, err := readFromRoot(context.Background(), lister, "", "trades.csv")
+	require.NoError(t, err)
+	assert.Equal(t, "a,b\n", string(data))
+
+	_, err = readFromRoot(context.Background(), lister, "", "missing.csv")
+	assert.ErrorContains(t, err, "no such file")
+	_, err = readFromRoot(context.Background(), lister, "", "../trades.csv")
+	assert.ErrorContains(t, err, "must be a relative path inside the root")
+	_, err = readFromRoot(context.Background(), nil, "", "trades.csv")
+	assert.ErrorContains(t, err, "no active client session")
+}
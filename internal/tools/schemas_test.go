@@ -0,0 +1,94 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/luno/luno-mcp/internal/validate"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestToolSchemas checks the limits tools declare in their schemas, which
+// their handlers enforce when binding their arguments
+func TestToolSchemas(t *testing.T) {
+	tests := []struct {
+		name string
+		tool mcp.Tool
+		args map[string]any
+		want string
+	}{
+		{name: "levels tolerance", tool: NewGetKeyLevelsTool(), args: map[string]any{"pair": "XBTZAR", "tolerance_percent": 0},
+			want: "tolerance_percent must be more than 0, got 0"},
+		{name: "levels limit", tool: NewGetKeyLevelsTool(), args: map[string]any{"pair": "XBTZAR", "limit": 100},
+			want: "limit must be at most 50, got 100"},
+		{name: "levels candles", tool: NewGetKeyLevelsTool(), args: map[string]any{"pair": "XBTZAR", "candles": 2000},
+			want: "candles must be at most 1000, got 2000"},
+		{name: "patterns duration", tool: NewDetectPatternsTool(), args: map[string]any{"pair": "XBTZAR", "duration": 30},
+			want: "duration must be at least 60, got 30"},
+		{name: "patterns confidence", tool: NewDetectPatternsTool(), args: map[string]any{"pair": "XBTZAR", "min_confidence": 2},
+			want: "min_confidence must be at most 1, got 2"},
+		{name: "backtest duration", tool: NewBacktestStrategyTool(), args: map[string]any{"pair": "XBTZAR", "strategy": "dca", "duration": 1},
+			want: "duration must be at least 60, got 1"},
+		{name: "backtest strategy", tool: NewBacktestStrategyTool(), args: map[string]any{"pair": "XBTZAR", "strategy": "martingale"},
+			want: `strategy must be one of sma_crossover, rsi, dca, got "martingale"`},
+		{name: "correlation days", tool: NewGetCorrelationsTool(), args: map[string]any{"pairs": "XBTZAR,ETHZAR", "days": 0},
+			want: "days must be at least 1, got 0"},
+		{name: "movers limit", tool: NewGetTopMoversTool(), args: map[string]any{"quote_currency": "ZAR", "limit": 50},
+			want: "limit must be at most 20, got 50"},
+		{name: "reconcile hours", tool: NewReconcileTool(), args: map[string]any{"hours": 721},
+			want: "hours must be at most 720, got 721"},
+		{name: "journal limit", tool: NewGetTradeJournalTool(), args: map[string]any{"limit": 0},
+			want: "limit must be at least 1, got 0"},
+		{name: "list orders limit", tool: NewListOrdersTool(), args: map[string]any{"limit": 0},
+			want: "limit must be at least 1, got 0"},
+		{name: "usage stats limit", tool: NewGetUsageStatsTool(), args: map[string]any{"limit": 0},
+			want: "limit must be at least 1, got 0"},
+		{name: "basket fee", tool: NewQuoteBasketTool(), args: map[string]any{"items": "XBT:1000", "currency": "ZAR", "fee_percent": "100"},
+			want: "fee_percent must be less than 100, got 100"},
+		{name: "position risk", tool: NewSuggestPositionSizeTool(), args: map[string]any{"pair": "XBTZAR", "side": "BUY", "risk_percent": 150.0},
+			want: "risk_percent must be at most 100, got 150"},
+		{name: "position stop distance", tool: NewSuggestPositionSizeTool(), args: map[string]any{"pair": "XBTZAR", "side": "BUY", "risk_percent": 1.0, "stop_distance_percent": 100},
+			want: "stop_distance_percent must be less than 100, got 100"},
+		{name: "market order slippage", tool: NewCreateMarketOrderTool(), args: map[string]any{"pair": "XBTZAR", "type": "BUY", "counter_volume": "155", "max_slippage_bps": -5},
+			want: "max_slippage_bps must be at least 0, got -5"},
+		{name: "market order volume", tool: NewCreateMarketOrderTool(), args: map[string]any{"pair": "XBTZAR", "type": "BUY", "counter_volume": "0"},
+			want: "counter_volume must be more than 0, got 0"},
+		{name: "order price", tool: NewCreateOrderTool(), args: map[string]any{"pair": "XBTZAR", "type": "BUY", "volume": "1", "price": "cheap"},
+			want: `price must be a decimal number such as 0.25, got "cheap"`},
+		{name: "order type", tool: NewCreateOrderTool(), args: map[string]any{"pair": "XBTZAR", "type": "HOLD", "volume": "1", "price": "1"},
+			want: `type must be one of BUY, SELL, got "HOLD"`},
+		{name: "withdrawal amount", tool: NewCreateFiatWithdrawalTool(), args: map[string]any{"type": "ZAR_EFT", "amount": "0", "beneficiary_id": "1234"},
+			want: "amount must be more than 0, got 0"},
+		{name: "withdrawal cents", tool: NewCreateFiatWithdrawalTool(), args: map[string]any{"type": "ZAR_EFT", "amount": "10.005", "beneficiary_id": "1234"},
+			want: `amount must have at most 2 decimal places, got "10.005"`},
+		{name: "withdrawal beneficiary", tool: NewCreateFiatWithdrawalTool(), args: map[string]any{"type": "ZAR_EFT", "amount": "10", "beneficiary_id": "abc"},
+			want: `beneficiary_id "abc" is not in the expected format`},
+		{name: "twap slices", tool: NewExecuteTWAPTool(), args: map[string]any{"slices": 1},
+			want: "slices must be at least 2, got 1"},
+		{name: "trade flow large volume", tool: NewGetTradeFlowTool(), args: map[string]any{"pair": "XBTZAR", "large_volume": "-1"},
+			want: "large_volume must be more than 0, got -1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, errs := validate.Arguments(tt.tool.InputSchema, tt.args)
+			assert.ErrorContains(t, errs, tt.want)
+		})
+	}
+}
+
+// TestToolSchemasAccept checks that the usual arguments of tools pass their schemas
+func TestToolSchemasAccept(t *testing.T) {
+	for _, tt := range []struct {
+		tool mcp.Tool
+		args map[string]any
+	}{
+		{NewGetKeyLevelsTool(), map[string]any{"pair": "XBTZAR", "limit": 10, "tolerance_percent": 0.5}},
+		{NewCreateOrderTool(), map[string]any{"pair": "XBTZAR", "type": "SELL", "volume": "0.0005", "price": "1200000"}},
+		{NewCreateFiatWithdrawalTool(), map[string]any{"type": "ZAR_EFT", "amount": "100.50", "beneficiary_id": "1234"}},
+		{NewSuggestPositionSizeTool(), map[string]any{"pair": "XBTZAR", "side": "BUY", "risk_percent": 1, "stop_distance_percent": 5}},
+	} {
+		_, errs := validate.Arguments(tt.tool.InputSchema, tt.args)
+		assert.Empty(t, errs, tt.tool.Name)
+	}
+}
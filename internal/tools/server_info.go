@@ -0,0 +1,220 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/i18n"
+	"github.com/luno/luno-mcp/internal/render"
+	"github.com/luno/luno-mcp/internal/telemetry"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// lunoRequestsPerMinute is the default client-side rate limit applied by luno-go
+const lunoRequestsPerMinute = 300
+
+// writeOperationTools are the tools that are only enabled with --allow-write-operations
+var writeOperationTools = []string{CreateOrderToolID, CancelOrderToolID, ReplaceOrderToolID, CreateOrdersBatchToolID, CreateMarketOrderToolID, UndoLastActionToolID}
+
+// credentialTools are the tools that only work with API credentials
+var credentialTools = []string{
+	GetBalancesToolID, GetAccountInfoToolID, ReconcileToolID,
+	CreateOrderToolID, CancelOrderToolID, ReplaceOrderToolID, CreateOrdersBatchToolID, CreateMarketOrderToolID,
+	ExecuteTWAPToolID, IcebergOrderToolID, ListOrdersToolID, ReconcileOrdersToolID, UndoLastActionToolID,
+	ListTransactionsToolID, GetTransactionToolID, ImportTradesToolID, ExportPortfolioToolID,
+	CreateFiatWithdrawalToolID, ListFiatWithdrawalsToolID, GetFiatWithdrawalToolID,
+	ScheduleReportToolID,
+}
+
+// tradingTools are the tools that place or cancel orders or move funds, which
+// are restricted by the trading windows
+var tradingTools = []string{
+	CreateOrderToolID, CancelOrderToolID, ReplaceOrderToolID, CreateOrdersBatchToolID, CreateMarketOrderToolID,
+	ExecuteTWAPToolID, IcebergOrderToolID, CreateFiatWithdrawalToolID, UndoLastActionToolID,
+}
+
+// IsTrading reports whether a tool places or cancels orders or moves funds
+func IsTrading(toolID string) bool {
+	return slices.Contains(tradingTools, toolID)
+}
+
+// IsTradingCall reports whether a call to a tool with args places orders or
+// moves funds. Calls that only cancel or check on orders, such as
+// cancel_order and the cancel and status actions of execute_twap and
+// iceberg_order, are not, so they stay allowed outside the trading windows.
+func IsTradingCall(toolID string, args map[string]any) bool {
+	switch toolID {
+	case CancelOrderToolID:
+		return false
+	case ExecuteTWAPToolID:
+		a := twapArgs{Action: twapActionStart}
+		return !decodeArgs(NewExecuteTWAPTool(), args, &a) || a.Action == twapActionStart
+	case IcebergOrderToolID:
+		a := icebergArgs{Action: icebergActionStart}
+		return !decodeArgs(NewIcebergOrderTool(), args, &a) || a.Action == icebergActionStart
+	}
+	return IsTrading(toolID)
+}
+
+// NeedsCredentials reports whether a tool only works with API credentials
+func NeedsCredentials(toolID string) bool {
+	return slices.Contains(credentialTools, toolID)
+}
+
+// ServerInfo describes what a running deployment can do
+type ServerInfo struct {
+	Name                   string        `json:"name"`
+	Version                string        `json:"version"`
+	Transport              string        `json:"transport,omitempty"`
+	LunoDomain             string        `json:"luno_domain"`
+	Authenticated          bool          `json:"authenticated"`
+	WriteOperationsEnabled bool          `json:"write_operations_enabled"`
+	WithdrawalsEnabled     bool          `json:"withdrawals_enabled"`
+	Locale                 i18n.Locale   `json:"locale"`
+	OutputFormat           render.Format `json:"output_format"`
+	EnabledTools           []string      `json:"enabled_tools"`
+	DisabledTools          []string      `json:"disabled_tools,omitempty"`
+	Limits                 ServerLimits  `json:"limits"`
+	// APINotices are deprecations and response changes seen from the Luno API
+	APINotices []sdk.APINotice `json:"api_notices,omitempty"`
+	// Clock is set once a Luno response has been seen
+	Clock *ServerClock `json:"clock,omitempty"`
+	// Telemetry is whether anonymous usage reports are sent, where and what
+	// they hold
+	Telemetry telemetry.Status `json:"telemetry"`
+	// TradingWindows are the times trading tools may be called, with their
+	// time zone, empty if they are not restricted
+	TradingWindows string `json:"trading_windows,omitempty"`
+	// TradingPairs are the only pairs orders may be placed on, empty if orders
+	// may be placed on any pair
+	TradingPairs []string `json:"trading_pairs,omitempty"`
+}
+
+// ServerClock is how far the local clock is from Luno's
+type ServerClock struct {
+	// SkewSeconds is how far Luno's clock is ahead of the local clock, negative if behind
+	SkewSeconds    int64     `json:"skew_seconds"`
+	MaxSkewSeconds int64     `json:"max_skew_seconds"`
+	Drifted        bool      `json:"drifted"`
+	CheckedAt      time.Time `json:"checked_at"`
+}
+
+// ServerLimits holds the limits configured for the deployment
+type ServerLimits struct {
+	LunoRequestsPerMinute int `json:"luno_requests_per_minute"`
+	// MaxResponseBytes is the size above which results are truncated, zero if they never are
+	MaxResponseBytes int `json:"max_response_bytes"`
+	// MaxConcurrentCalls and MaxConcurrentCallsPerTool bound the tool calls
+	// running at once, zero if they are not limited
+	MaxConcurrentCalls        int `json:"max_concurrent_calls"`
+	MaxConcurrentCallsPerTool int `json:"max_concurrent_calls_per_tool"`
+	// CallQueueTimeoutSeconds is how long calls over a limit wait, zero if they wait indefinitely
+	CallQueueTimeoutSeconds int `json:"call_queue_timeout_seconds"`
+	// MaxPriceDeviationPercent is how far from the market price a limit price
+	// can be without allow_far_from_market, zero if it isn't checked
+	MaxPriceDeviationPercent float64 `json:"max_price_deviation_percent"`
+	// DuplicateOrderWindowSeconds is how long a placed order makes a matching
+	// one a likely duplicate, zero if duplicates aren't checked
+	DuplicateOrderWindowSeconds int `json:"duplicate_order_window_seconds"`
+}
+
+// BuildServerInfo collects the server info for the server handling the request in ctx
+func BuildServerInfo(ctx context.Context, cfg *config.Config, name, version string) ServerInfo {
+	info := ServerInfo{
+		Name:                   name,
+		Version:                version,
+		Transport:              cfg.Transport,
+		LunoDomain:             cfg.Domain,
+		Authenticated:          cfg.IsAuthenticated,
+		WriteOperationsEnabled: cfg.AllowWriteOperations,
+		WithdrawalsEnabled:     cfg.AllowWriteOperations && cfg.AllowWithdrawals,
+		Locale:                 cfg.Locale,
+		OutputFormat:           cfg.OutputFormat,
+		EnabledTools:           []string{},
+		Limits: ServerLimits{
+			LunoRequestsPerMinute:       lunoRequestsPerMinute,
+			MaxResponseBytes:            cfg.MaxResponseBytes,
+			MaxConcurrentCalls:          cfg.Limiter.MaxCalls(),
+			MaxConcurrentCallsPerTool:   cfg.Limiter.MaxCallsPerTool(),
+			CallQueueTimeoutSeconds:     int(cfg.Limiter.QueueTimeout().Seconds()),
+			MaxPriceDeviationPercent:    cfg.MaxPriceDeviation,
+			DuplicateOrderWindowSeconds: int(cfg.DuplicateOrderWindow.Seconds()),
+		},
+		APINotices: cfg.APICompat.Notices(),
+		Telemetry:  cfg.Telemetry.Status(),
+
+		TradingWindows: cfg.TradingHours.String(),
+		TradingPairs:   cfg.TradingPairs,
+	}
+	if info.LunoDomain == "" {
+		info.LunoDomain = config.DefaultLunoDomain
+	}
+	if info.Locale == "" {
+		info.Locale = i18n.Default
+	}
+	if info.OutputFormat == "" {
+		info.OutputFormat = render.JSON
+	}
+	if skew, checkedAt := cfg.Clock.Skew(); !checkedAt.IsZero() {
+		info.Clock = &ServerClock{
+			SkewSeconds:    int64(skew.Seconds()),
+			MaxSkewSeconds: int64(cfg.Clock.Max().Seconds()),
+			Drifted:        cfg.Clock.Drifted(),
+			CheckedAt:      checkedAt.UTC(),
+		}
+	}
+
+	var registered []string
+	if srv := server.ServerFromContext(ctx); srv != nil {
+		for toolName := range srv.ListTools() {
+			registered = append(registered, toolName)
+		}
+	}
+	slices.Sort(registered)
+
+	for _, toolName := range registered {
+		if toolDisabled(cfg, toolName) {
+			info.DisabledTools = append(info.DisabledTools, toolName)
+			continue
+		}
+		info.EnabledTools = append(info.EnabledTools, toolName)
+	}
+
+	return info
+}
+
+// toolDisabled reports whether a tool is registered with a handler that refuses calls
+func toolDisabled(cfg *config.Config, toolName string) bool {
+	if toolName == CreateFiatWithdrawalToolID {
+		return !cfg.AllowWriteOperations || !cfg.AllowWithdrawals
+	}
+	return !cfg.AllowWriteOperations && slices.Contains(writeOperationTools, toolName)
+}
+
+// NewGetServerInfoTool creates a new tool for reporting the server's version and capabilities
+func NewGetServerInfoTool() mcp.Tool {
+	return mcp.NewTool(
+		GetServerInfoToolID,
+		mcp.WithDescription("Get the server version, enabled tools, configured limits, transport, "+
+			"authentication state and Luno API domain of this deployment, any Luno API deprecations seen, how far the local clock is from Luno's, "+
+			"and whether anonymous usage telemetry is sent"),
+	)
+}
+
+// HandleGetServerInfo handles the get_server_info tool
+func HandleGetServerInfo(cfg *config.Config, name, version string) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		cfg := cfg.ForContext(ctx)
+		resultJSON, err := marshalJSON(BuildServerInfo(ctx, cfg, name, version))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal server info: %v", err)), nil
+		}
+
+		return mcp.NewToolResultText(resultJSON), nil
+	}
+}
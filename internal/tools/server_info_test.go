@@ -0,0 +1,97 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/limiter"
+	"github.com/luno/luno-mcp/internal/telemetry"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildServerInfo(t *testing.T) {
+	tests := []struct {
+		name           string
+		cfg            *config.Config
+		expectedDomain string
+		expectedLimits ServerLimits
+	}{
+		{
+			name:           "defaults domain when unset",
+			cfg:            &config.Config{},
+			expectedDomain: config.DefaultLunoDomain,
+			expectedLimits: ServerLimits{LunoRequestsPerMinute: lunoRequestsPerMinute},
+		},
+		{
+			name:           "reports configured domain and auth state",
+			cfg:            &config.Config{Domain: "api.staging.luno.com", IsAuthenticated: true, Transport: "sse"},
+			expectedDomain: "api.staging.luno.com",
+			expectedLimits: ServerLimits{LunoRequestsPerMinute: lunoRequestsPerMinute},
+		},
+		{
+			name:           "reports concurrency limits",
+			cfg:            &config.Config{MaxResponseBytes: 1000, MaxPriceDeviation: 20, DuplicateOrderWindow: 5 * time.Minute, Limiter: limiter.New(16, 4, 30*time.Second)},
+			expectedDomain: config.DefaultLunoDomain,
+			expectedLimits: ServerLimits{
+				LunoRequestsPerMinute:       lunoRequestsPerMinute,
+				MaxResponseBytes:            1000,
+				MaxConcurrentCalls:          16,
+				MaxConcurrentCallsPerTool:   4,
+				CallQueueTimeoutSeconds:     30,
+				MaxPriceDeviationPercent:    20,
+				DuplicateOrderWindowSeconds: 300,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := BuildServerInfo(context.Background(), tt.cfg, "luno-mcp", "1.2.3")
+			assert.Equal(t, "luno-mcp", info.Name)
+			assert.Equal(t, "1.2.3", info.Version)
+			assert.Equal(t, tt.expectedDomain, info.LunoDomain)
+			assert.Equal(t, tt.cfg.IsAuthenticated, info.Authenticated)
+			assert.Equal(t, tt.cfg.Transport, info.Transport)
+			assert.Equal(t, tt.expectedLimits, info.Limits)
+			assert.Empty(t, info.EnabledTools, "no tools are visible without a server in context")
+		})
+	}
+}
+
+func TestBuildServerInfoClock(t *testing.T) {
+	cfg := &config.Config{Clock: sdk.NewClockSkew(sdk.DefaultMaxClockSkew)}
+	assert.Nil(t, BuildServerInfo(context.Background(), cfg, "luno-mcp", "1.2.3").Clock, "no clock before a response is seen")
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	}))
+	defer ts.Close()
+	res, err := (&http.Client{Transport: &sdk.MCPRoundTripper{Clock: cfg.Clock}}).Get(ts.URL)
+	require.NoError(t, err)
+	_ = res.Body.Close()
+
+	clock := BuildServerInfo(context.Background(), cfg, "luno-mcp", "1.2.3").Clock
+	require.NotNil(t, clock)
+	assert.InDelta(t, 60, clock.SkewSeconds, 2)
+	assert.Equal(t, int64(30), clock.MaxSkewSeconds)
+	assert.True(t, clock.Drifted)
+	assert.False(t, clock.CheckedAt.IsZero())
+}
+
+func TestBuildServerInfoTelemetry(t *testing.T) {
+	info := BuildServerInfo(context.Background(), &config.Config{}, "luno-mcp", "1.2.3")
+	assert.Equal(t, telemetry.Status{}, info.Telemetry, "telemetry is off unless opted in to")
+
+	reporter, err := telemetry.New("https://example.com/telemetry", "1.2.3")
+	require.NoError(t, err)
+	info = BuildServerInfo(context.Background(), &config.Config{Telemetry: reporter}, "luno-mcp", "1.2.3")
+	assert.True(t, info.Telemetry.Enabled)
+	assert.Equal(t, "https://example.com/telemetry", info.Telemetry.URL)
+	assert.Equal(t, telemetry.Fields, info.Telemetry.Fields)
+}
@@ -0,0 +1,49 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/usage"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// sessionStatsNotes explain what SessionStats counts
+var sessionStatsNotes = []string{
+	"Counts cover this session's tool calls before this one; response sizes are of the results as returned, after truncation.",
+	"Cache hits are lookups served from the server's market and ticker caches, or repeated calls answered with the previous result.",
+	"Luno API calls made in the background, such as later TWAP and iceberg slices, are not counted.",
+}
+
+// SessionStats is the get_session_stats result
+type SessionStats struct {
+	usage.SessionStats
+	Notes []string `json:"notes"`
+}
+
+// NewGetSessionStatsTool creates a tool for reporting what the current session did
+func NewGetSessionStatsTool() mcp.Tool {
+	return mcp.NewTool(
+		GetSessionStatsToolID,
+		mcp.WithDescription("Get what this session has done so far: calls to each tool, Luno API calls and bytes transferred, "+
+			"cache hit rate, rate limits hit, and orders placed or cancelled. Use this to debug repeated or expensive calls."),
+	)
+}
+
+// HandleGetSessionStats handles the get_session_stats tool
+func HandleGetSessionStats(cfg *config.Config) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		session := usage.SessionFromContext(ctx)
+		if cfg.Usage == nil || session == nil {
+			return mcp.NewToolResultError("Session stats are not available on this server"), nil
+		}
+
+		resultJSON, err := marshalJSON(SessionStats{SessionStats: session.Stats(), Notes: sessionStatsNotes})
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal session stats: %v", err)), nil
+		}
+		return mcp.NewToolResultText(resultJSON), nil
+	}
+}
@@ -0,0 +1,61 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/usage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleGetSessionStats(t *testing.T) {
+	tests := []struct {
+		name          string
+		noTracker     bool
+		noSession     bool
+		errorContains string
+	}{
+		{name: "current session"},
+		{name: "no session", noSession: true, errorContains: "Session stats are not available on this server"},
+		{name: "not available", noTracker: true, errorContains: "Session stats are not available on this server"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			ctx := context.Background()
+			if !tt.noTracker {
+				cfg.Usage = usage.NewTracker()
+			}
+			if !tt.noSession {
+				session := usage.NewTracker().Session("s1")
+				session.ToolCall("get_ticker", 400, false)
+				session.CacheLookup(true)
+				session.Traded(usage.OrderPlaced)
+				ctx = usage.ContextWithSession(ctx, session)
+			}
+
+			result, err := HandleGetSessionStats(cfg)(ctx, createMockRequest(map[string]any{}))
+			require.NoError(t, err)
+			text := getTextContentFromResult(t, result)
+			if tt.errorContains != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, text, tt.errorContains)
+				return
+			}
+			require.False(t, result.IsError, text)
+
+			var got SessionStats
+			require.NoError(t, json.Unmarshal([]byte(text), &got))
+			assert.Equal(t, "s1", got.SessionID)
+			assert.Equal(t, map[string]int64{"get_ticker": 1}, got.ToolCalls)
+			assert.Equal(t, int64(100), got.EstimatedTokens)
+			assert.InDelta(t, 1.0, got.Cache.HitRate, 0)
+			assert.Equal(t, int64(1), got.Trading.OrdersPlaced)
+			assert.NotEmpty(t, got.Notes)
+		})
+	}
+}
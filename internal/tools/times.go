@@ -0,0 +1,28 @@
+package tools
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/luno/luno-mcp/internal/timespec"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// parseTime reads the time parameter name, given as s, relative to now. s
+// can be in any form timespec.Parse accepts, including Unix milliseconds.
+// ok is false when the parameter isn't given.
+func parseTime(name, s string, now time.Time) (r timespec.Range, ok bool, errResult *mcp.CallToolResult) {
+	if s == "" || s == "0" {
+		return timespec.Range{}, false, nil
+	}
+	r, err := timespec.Parse(s, now)
+	if err != nil {
+		return timespec.Range{}, false, mcp.NewToolResultError(fmt.Sprintf("Invalid %s: %v", name, err))
+	}
+	return r, true, nil
+}
+
+// timeParamDesc describes a time parameter
+func timeParamDesc(what string) string {
+	return what + ". Accepts " + timespec.Help + "."
+}
@@ -2,16 +2,20 @@ package tools
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log/slog"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/luno/luno-go"
 	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/internal/candles"
 	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/markets"
+	"github.com/luno/luno-mcp/internal/orders"
+	"github.com/luno/luno-mcp/internal/validate"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
@@ -20,26 +24,67 @@ import (
 const (
 	ErrAPICredentialsRequired = "API credentials are required for this operation. Please set LUNO_API_KEY_ID and LUNO_API_SECRET environment variables."
 	ErrWriteOperationDisabled = "Write operations are disabled. To enable, restart the server with the --allow-write-operations flag or set the ALLOW_WRITE_OPERATIONS=true environment variable."
+	ErrWithdrawalsDisabled    = "Withdrawals are disabled. To enable, restart the server with both the --allow-write-operations and --allow-withdrawals flags or set ALLOW_WRITE_OPERATIONS=true and ALLOW_WITHDRAWALS=true."
 	ErrTradingPairRequired    = "Trading pair is required"
 	ErrTradingPairDesc        = "Trading pair (e.g., XBTZAR)"
 
+	unknownMarketError   = "unknown market, use get_markets_info to list valid pairs"
 	writeOperationNotice = " This is a write operation that must be explicitly enabled via the --allow-write-operations flag or ALLOW_WRITE_OPERATIONS environment variable."
 )
 
 // Tool IDs
 const (
-	GetBalancesToolID      = "get_balances"
-	GetTickerToolID        = "get_ticker"
-	GetTickersToolID       = "get_tickers"
-	GetOrderBookToolID     = "get_order_book"
-	CreateOrderToolID      = "create_order"
-	CancelOrderToolID      = "cancel_order"
-	ListOrdersToolID       = "list_orders"
-	ListTransactionsToolID = "list_transactions"
-	GetTransactionToolID   = "get_transaction"
-	ListTradesToolID       = "list_trades"
-	GetCandlesToolID       = "get_candles"
-	GetMarketsInfoToolID   = "get_markets_info"
+	GetBalancesToolID         = "get_balances"
+	GetTickerToolID           = "get_ticker"
+	GetTickersToolID          = "get_tickers"
+	GetOrderBookToolID        = "get_order_book"
+	CreateOrderToolID         = "create_order"
+	CancelOrderToolID         = "cancel_order"
+	ListOrdersToolID          = "list_orders"
+	ListTransactionsToolID    = "list_transactions"
+	GetTransactionToolID      = "get_transaction"
+	ListTradesToolID          = "list_trades"
+	GetCandlesToolID          = "get_candles"
+	GetMarketsInfoToolID      = "get_markets_info"
+	ExplainMarketToolID       = "explain_market"
+	GetExchangeStatusToolID   = "get_exchange_status"
+	SuggestPositionSizeToolID = "suggest_position_size"
+	GetPricePremiumToolID     = "get_price_premium"
+	ExecuteTWAPToolID         = "execute_twap"
+	IcebergOrderToolID        = "iceberg_order"
+	LogTradeNoteToolID        = "log_trade_note"
+	GetTradeJournalToolID     = "get_trade_journal"
+	ListRootsToolID           = "list_roots"
+	ExportTradesToolID        = "export_trades"
+	GetServerInfoToolID       = "get_server_info"
+	ReplaceOrderToolID        = "replace_order"
+	FetchMoreToolID           = "fetch_more"
+	AliasAccountToolID        = "alias_account"
+	GetTopMoversToolID        = "get_top_movers"
+	GetCorrelationsToolID     = "get_correlations"
+	BacktestStrategyToolID    = "backtest_strategy"
+	QuoteBasketToolID         = "quote_basket"
+	CreateOrdersBatchToolID   = "create_orders_batch"
+	DetectPatternsToolID      = "detect_patterns"
+	GetKeyLevelsToolID        = "get_key_levels"
+	CreateMarketOrderToolID   = "create_market_order"
+	GetAccountInfoToolID      = "get_account_info"
+	GetUsageStatsToolID       = "get_usage_stats"
+	GetSessionStatsToolID     = "get_session_stats"
+	ReconcileOrdersToolID     = "reconcile_orders"
+	ReconcileToolID           = "reconcile"
+	ExportPortfolioToolID     = "export_portfolio"
+	ImportTradesToolID        = "import_trades"
+	GetTradeFlowToolID        = "get_trade_flow"
+	CheckUpdatesToolID        = "check_updates"
+	UndoLastActionToolID      = "undo_last_action"
+	GetApprovalStatusToolID   = "get_approval_status"
+	ExportSessionToolID       = "export_session"
+
+	CreateFiatWithdrawalToolID = "create_fiat_withdrawal"
+	ListFiatWithdrawalsToolID  = "list_fiat_withdrawals"
+	GetFiatWithdrawalToolID    = "get_fiat_withdrawal"
+	ScheduleReportToolID       = "schedule_report"
 )
 
 // ===== Balance Tools =====
@@ -48,50 +93,124 @@ const (
 func NewGetBalancesTool() mcp.Tool {
 	return mcp.NewTool(
 		GetBalancesToolID,
-		mcp.WithDescription("Get balances for all Luno accounts"),
+		mcp.WithDescription("Get balances for all Luno accounts. Set convert_to to also value each balance in one currency "+
+			"at the latest trade prices, with a grand total."),
+		mcp.WithString(
+			"convert_to",
+			mcp.Description("Currency to value the balances in, e.g. ZAR or USDC"),
+		),
 	)
 }
 
+// BalancesResult is the get_balances result when balances are converted to one currency
+type BalancesResult struct {
+	Balances  []BalanceView `json:"balances"`
+	ConvertTo string        `json:"convert_to"`
+	// TotalValue is the value of every priced balance in ConvertTo
+	TotalValue string `json:"total_value"`
+	// UnpricedAssets have no market to value them in ConvertTo and are left out of TotalValue
+	UnpricedAssets []string `json:"unpriced_assets,omitempty"`
+}
+
+// BalanceView is an account balance returned by get_balances
+type BalanceView struct {
+	AccountID   string `json:"account_id"`
+	Asset       string `json:"asset"`
+	Balance     string `json:"balance"`
+	Reserved    string `json:"reserved"`
+	Unconfirmed string `json:"unconfirmed"`
+	Name        string `json:"name"`
+	// Aliases are the names given to the account with alias_account
+	Aliases []string `json:"aliases,omitempty"`
+	// Value is the balance in the convert_to currency, when it could be priced
+	Value string `json:"value,omitempty"`
+}
+
+// balancesArgs are the arguments of the get_balances tool
+type balancesArgs struct {
+	ConvertTo string `json:"convert_to"`
+}
+
 // HandleGetBalances handles the get_balances tool
 func HandleGetBalances(cfg *config.Config) server.ToolHandlerFunc {
+	tool := NewGetBalancesTool()
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		cfg := cfg.ForContext(ctx)
 		if !cfg.IsAuthenticated {
 			return mcp.NewToolResultError(ErrAPICredentialsRequired), nil
 		}
+		var args balancesArgs
+		if errResult := bindArguments(request, tool, &args); errResult != nil {
+			return errResult, nil
+		}
 
 		balances, err := cfg.LunoClient.GetBalances(ctx, &luno.GetBalancesRequest{})
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("Failed to get balances: %v", err)), nil
 		}
 
-		// Enhance the response with additional information
-		type EnhancedBalance struct {
-			AccountID   string `json:"account_id"`
-			Asset       string `json:"asset"`
-			Balance     string `json:"balance"`
-			Reserved    string `json:"reserved"`
-			Unconfirmed string `json:"unconfirmed"`
-			Name        string `json:"name"`
+		aliases, err := accountAliases(cfg)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("reading account aliases", err), nil
 		}
-
-		enhancedBalances := make([]EnhancedBalance, 0, len(balances.Balance))
+		views := make([]BalanceView, 0, len(balances.Balance))
 		for _, balance := range balances.Balance {
-			enhancedBalances = append(enhancedBalances, EnhancedBalance{
+			views = append(views, BalanceView{
 				AccountID:   balance.AccountId,
 				Asset:       balance.Asset,
 				Balance:     balance.Balance.String(),
 				Reserved:    balance.Reserved.String(),
 				Unconfirmed: balance.Unconfirmed.String(),
 				Name:        balance.Name,
+				Aliases:     aliases[balance.AccountId],
 			})
 		}
+		structured := newBalancesOutput(balances.Balance)
+		for i := range structured.Balances {
+			structured.Balances[i].Aliases = aliases[structured.Balances[i].AccountID]
+		}
 
-		resultJSON, err := json.MarshalIndent(enhancedBalances, "", "  ")
+		convertTo := args.ConvertTo
+		if convertTo == "" {
+			resultJSON, err := marshalJSON(views)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal balances: %v", err)), nil
+			}
+			return mcp.NewToolResultStructured(structured, resultJSON), nil
+		}
+
+		convertTo = normalizeCurrency(convertTo)
+		conv, err := newConverter(ctx, cfg.LunoClient, convertTo)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("converting balances", err), nil
+		}
+
+		result := BalancesResult{Balances: views, ConvertTo: convertTo}
+		total := decimal.Zero()
+		for i, balance := range balances.Balance {
+			value, ok := conv.value(balance.Asset, balance.Balance)
+			if !ok {
+				if !slices.Contains(result.UnpricedAssets, balance.Asset) {
+					result.UnpricedAssets = append(result.UnpricedAssets, balance.Asset)
+				}
+				continue
+			}
+			total = total.Add(value)
+			result.Balances[i].Value = value.String()
+			amount := NewAmount(value, convertTo)
+			structured.Balances[i].Value = &amount
+		}
+		result.TotalValue = total.String()
+		totalAmount := NewAmount(total, convertTo)
+		structured.ConvertTo = convertTo
+		structured.TotalValue = &totalAmount
+		structured.UnpricedAssets = result.UnpricedAssets
+
+		resultJSON, err := marshalJSON(result)
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal balances: %v", err)), nil
 		}
-
-		return mcp.NewToolResultText(string(resultJSON)), nil
+		return mcp.NewToolResultStructured(structured, resultJSON), nil
 	}
 }
 
@@ -110,16 +229,21 @@ func NewGetTickerTool() mcp.Tool {
 	)
 }
 
+// pairArgs are the arguments of the tools that only take a pair
+type pairArgs struct {
+	Pair string `json:"pair"`
+}
+
 // HandleGetTicker handles the get_ticker tool
 func HandleGetTicker(cfg *config.Config) server.ToolHandlerFunc {
+	tool := NewGetTickerTool()
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		pair, err := request.RequireString("pair")
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("getting pair from request", err), nil
+		cfg := cfg.ForContext(ctx)
+		var args pairArgs
+		if errResult := bindArguments(request, tool, &args); errResult != nil {
+			return errResult, nil
 		}
-
-		// Normalize currency pair
-		pair = normalizeCurrencyPair(pair)
+		pair := normalizeCurrencyPair(args.Pair)
 
 		ticker, err := cfg.LunoClient.GetTicker(ctx, &luno.GetTickerRequest{
 			Pair: pair,
@@ -128,12 +252,13 @@ func HandleGetTicker(cfg *config.Config) server.ToolHandlerFunc {
 			return mcp.NewToolResultErrorFromErr("getting ticker", err), nil
 		}
 
-		resultJSON, err := json.MarshalIndent(ticker, "", "  ")
+		resultJSON, err := marshalJSON(ticker)
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal ticker: %v", err)), nil
 		}
 
-		return mcp.NewToolResultText(string(resultJSON)), nil
+		structured := newTickerAmounts(luno.Ticker(*ticker), cachedPairCurrencies(cfg))
+		return mcp.NewToolResultStructured(structured, resultJSON), nil
 	}
 }
 
@@ -152,14 +277,14 @@ func NewGetOrderBookTool() mcp.Tool {
 
 // HandleGetOrderBook handles the get_order_book tool
 func HandleGetOrderBook(cfg *config.Config) server.ToolHandlerFunc {
+	tool := NewGetOrderBookTool()
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		pair, err := request.RequireString("pair")
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("getting pair from request", err), nil
+		cfg := cfg.ForContext(ctx)
+		var args pairArgs
+		if errResult := bindArguments(request, tool, &args); errResult != nil {
+			return errResult, nil
 		}
-
-		// Normalize currency pair
-		pair = normalizeCurrencyPair(pair)
+		pair := normalizeCurrencyPair(args.Pair)
 
 		orderBook, err := cfg.LunoClient.GetOrderBook(ctx, &luno.GetOrderBookRequest{
 			Pair: pair,
@@ -168,12 +293,12 @@ func HandleGetOrderBook(cfg *config.Config) server.ToolHandlerFunc {
 			return mcp.NewToolResultErrorFromErr("getting order book", err), nil
 		}
 
-		resultJSON, err := json.MarshalIndent(orderBook, "", "  ")
+		resultJSON, err := marshalJSON(orderBook)
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal order book: %v", err)), nil
 		}
 
-		return mcp.NewToolResultText(string(resultJSON)), nil
+		return mcp.NewToolResultText(resultJSON), nil
 	}
 }
 
@@ -181,7 +306,7 @@ func HandleGetOrderBook(cfg *config.Config) server.ToolHandlerFunc {
 func NewGetTickersTool() mcp.Tool {
 	return mcp.NewTool(
 		GetTickersToolID,
-		mcp.WithDescription("List tickers for all currency pairs"),
+		mcp.WithDescription("List tickers for all currency pairs. Unknown pairs are reported in an errors section instead of failing the whole request."),
 		mcp.WithString(
 			"pair",
 			mcp.Description("Return tickers for multiple markets (e.g., XBTZAR,ETHZAR)"),
@@ -189,15 +314,40 @@ func NewGetTickersTool() mcp.Tool {
 	)
 }
 
+// PairError reports a requested currency pair that could not be returned
+type PairError struct {
+	Pair  string `json:"pair"`
+	Error string `json:"error"`
+}
+
+// TickersResult is the result of the get_tickers tool
+type TickersResult struct {
+	Tickers []luno.Ticker `json:"tickers"`
+	// Errors lists requested pairs that were rejected or not returned
+	Errors []PairError `json:"errors,omitempty"`
+}
+
 // HandleGetTickers handles the get_tickers tool
 func HandleGetTickers(cfg *config.Config) server.ToolHandlerFunc {
+	tool := NewGetTickersTool()
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		pairsStr := request.GetString("pair", "")
-		var pairs []string
-		if pairsStr != "" {
-			pairs = strings.Split(pairsStr, ",")
-			for i, p := range pairs {
-				pairs[i] = normalizeCurrencyPair(p)
+		cfg := cfg.ForContext(ctx)
+		var args pairArgs
+		if errResult := bindArguments(request, tool, &args); errResult != nil {
+			return errResult, nil
+		}
+		pairs := parsePairList(args.Pair)
+
+		var rejected []PairError
+		if len(pairs) > 0 {
+			var err error
+			pairs, rejected, err = validatePairs(ctx, MarketCache(cfg), pairs)
+			if err != nil {
+				// Let the tickers endpoint decide rather than failing on the lookup
+				slog.WarnContext(ctx, "Failed to validate pairs against markets", "error", err)
+			}
+			if len(pairs) == 0 {
+				return mcp.NewToolResultError(fmt.Sprintf("None of the requested pairs are valid: %s", formatPairErrors(rejected))), nil
 			}
 		}
 
@@ -208,12 +358,24 @@ func HandleGetTickers(cfg *config.Config) server.ToolHandlerFunc {
 			return mcp.NewToolResultErrorFromErr("getting tickers", err), nil
 		}
 
-		resultJSON, err := json.MarshalIndent(tickers, "", "  ")
+		result := TickersResult{Tickers: tickers.Tickers, Errors: rejected}
+		for _, pair := range pairs {
+			if !slices.ContainsFunc(tickers.Tickers, func(t luno.Ticker) bool { return t.Pair == pair }) {
+				result.Errors = append(result.Errors, PairError{Pair: pair, Error: "no ticker returned"})
+			}
+		}
+
+		resultJSON, err := marshalJSON(result)
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal tickers: %v", err)), nil
 		}
 
-		return mcp.NewToolResultText(string(resultJSON)), nil
+		currencies := cachedPairCurrencies(cfg)
+		structured := TickersOutput{Tickers: make([]TickerAmounts, 0, len(result.Tickers)), Errors: result.Errors}
+		for _, t := range result.Tickers {
+			structured.Tickers = append(structured.Tickers, newTickerAmounts(t, currencies))
+		}
+		return mcp.NewToolResultStructured(structured, resultJSON), nil
 	}
 }
 
@@ -227,9 +389,9 @@ func NewGetCandlesTool() mcp.Tool {
 			mcp.Required(),
 			mcp.Description(ErrTradingPairDesc),
 		),
-		mcp.WithNumber(
+		mcp.WithString(
 			"since",
-			mcp.Description("Filter to candles starting on or after this timestamp (Unix milliseconds). Defaults to 24 hours ago."),
+			mcp.Description(timeParamDesc("Return candles starting on or after this time, by default 24 hours ago")),
 		),
 		mcp.WithNumber(
 			"duration",
@@ -239,48 +401,56 @@ func NewGetCandlesTool() mcp.Tool {
 	)
 }
 
+// candlesArgs are the arguments of the get_candles tool
+type candlesArgs struct {
+	Pair     string `json:"pair"`
+	Since    string `json:"since"`
+	Duration int64  `json:"duration"`
+}
+
 // HandleGetCandles handles the get_candles tool
 func HandleGetCandles(cfg *config.Config) server.ToolHandlerFunc {
+	tool := NewGetCandlesTool()
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		pair, err := request.RequireString("pair")
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("getting pair from request", err), nil
+		cfg := cfg.ForContext(ctx)
+		var args candlesArgs
+		if errResult := bindArguments(request, tool, &args); errResult != nil {
+			return errResult, nil
 		}
-		pair = normalizeCurrencyPair(pair)
+		pair, duration := normalizeCurrencyPair(args.Pair), args.Duration
 
-		sinceFloat := request.GetFloat("since", 0)
-		var since luno.Time
-		if sinceFloat == 0 {
-			// Default to 24 hours ago if since is not provided or is 0
-			since = luno.Time(time.Now().Add(-24 * time.Hour))
-		} else {
-			since = luno.Time(time.UnixMilli(int64(sinceFloat)))
+		now := time.Now()
+		since := now.Add(-24 * time.Hour)
+		r, ok, errResult := parseTime("since", args.Since, now)
+		if errResult != nil {
+			return errResult, nil
 		}
-
-		durationFloat, err := request.RequireFloat("duration")
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("getting duration from request", err), nil
+		if ok {
+			since = r.Start
 		}
-		duration := int64(durationFloat)
 
-		candles, err := cfg.LunoClient.GetCandles(ctx, &luno.GetCandlesRequest{
-			Pair:     pair,
-			Since:    since,
-			Duration: duration,
-		})
+		// As many candles as Luno returns for one request, most of them cached
+		until := since.Add(candles.PageSize * time.Duration(duration) * time.Second)
+		cs, err := getCandles(ctx, cfg, pair, duration, since, until)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("getting candles", err), nil
 		}
 
-		resultJSON, err := json.MarshalIndent(candles, "", "  ")
+		resultJSON, err := marshalJSON(luno.GetCandlesResponse{Candles: cs, Duration: duration, Pair: pair})
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal candles: %v", err)), nil
 		}
 
-		return mcp.NewToolResultText(string(resultJSON)), nil
+		return mcp.NewToolResultText(resultJSON), nil
 	}
 }
 
+// getCandles returns the candles of pair from since and before until, from the
+// candle cache where they have been fetched before
+func getCandles(ctx context.Context, cfg *config.Config, pair string, duration int64, since, until time.Time) ([]luno.Candle, error) {
+	return cfg.Candles.Get(ctx, cfg.LunoClient, pair, duration, since, until)
+}
+
 // NewGetMarketsInfoTool creates a new tool for getting market information
 func NewGetMarketsInfoTool() mcp.Tool {
 	return mcp.NewTool(
@@ -295,11 +465,16 @@ func NewGetMarketsInfoTool() mcp.Tool {
 
 // HandleGetMarketsInfo handles the get_markets_info tool
 func HandleGetMarketsInfo(cfg *config.Config) server.ToolHandlerFunc {
+	tool := NewGetMarketsInfoTool()
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		pairsStr := request.GetString("pair", "")
+		cfg := cfg.ForContext(ctx)
+		var args pairArgs
+		if errResult := bindArguments(request, tool, &args); errResult != nil {
+			return errResult, nil
+		}
 		var pairs []string
-		if pairsStr != "" {
-			pairs = strings.Split(pairsStr, ",")
+		if args.Pair != "" {
+			pairs = strings.Split(args.Pair, ",")
 			for i, p := range pairs {
 				pairs[i] = normalizeCurrencyPair(p)
 			}
@@ -312,12 +487,12 @@ func HandleGetMarketsInfo(cfg *config.Config) server.ToolHandlerFunc {
 			return mcp.NewToolResultErrorFromErr("getting markets info", err), nil
 		}
 
-		resultJSON, err := json.MarshalIndent(markets, "", "  ")
+		resultJSON, err := marshalJSON(markets)
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal markets info: %v", err)), nil
 		}
 
-		return mcp.NewToolResultText(string(resultJSON)), nil
+		return mcp.NewToolResultText(resultJSON), nil
 	}
 }
 
@@ -350,61 +525,59 @@ func NewCreateOrderTool() mcp.Tool {
 			"volume",
 			mcp.Required(),
 			mcp.Description("Order volume (amount of cryptocurrency to buy or sell)"),
+			validate.Decimal(),
+			validate.ExclusiveMin(0),
 		),
 		mcp.WithString(
 			"price",
 			mcp.Required(),
 			mcp.Description("Limit price as a decimal string"),
+			validate.Decimal(),
+			validate.ExclusiveMin(0),
 		),
+		withAllowFarFromMarket(),
+		withAllowDuplicate(),
 	)
 }
 
-// HandleCreateOrder handles the create_order tool for limit orders
-// TODO: Add HandleCreateMarketOrder function for market orders
+// createOrderArgs are the arguments of the create_order tool
+type createOrderArgs struct {
+	Pair               string          `json:"pair"`
+	Type               string          `json:"type"`
+	Volume             decimal.Decimal `json:"volume"`
+	Price              decimal.Decimal `json:"price"`
+	AllowFarFromMarket bool            `json:"allow_far_from_market"`
+	AllowDuplicate     bool            `json:"allow_duplicate"`
+}
+
+// HandleCreateOrder handles the create_order tool for limit orders. Market
+// orders are placed by HandleCreateMarketOrder.
 func HandleCreateOrder(cfg *config.Config) server.ToolHandlerFunc {
+	tool := NewCreateOrderTool()
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		cfg := cfg.ForContext(ctx)
 		if !cfg.IsAuthenticated {
 			return mcp.NewToolResultError(ErrAPICredentialsRequired), nil
 		}
 
-		pair, err := request.RequireString("pair")
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("getting pair from request", err), nil
+		var args createOrderArgs
+		if errResult := bindArguments(request, tool, &args); errResult != nil {
+			return errResult, nil
 		}
-		slog.Debug("Processing trading pair", "originalPair", pair)
+		slog.Debug("Processing trading pair", "originalPair", args.Pair)
 
 		// Normalize the pair - this should handle BTC->XBT conversion automatically
-		pair = normalizeCurrencyPair(pair)
-		slog.Debug("Normalized trading pair", "originalPair", pair, "normalizedPair", pair)
-
-		orderType, err := request.RequireString("type")
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("getting type from request", err), nil
+		pair := normalizeCurrencyPair(args.Pair)
+		slog.Debug("Normalized trading pair", "originalPair", args.Pair, "normalizedPair", pair)
+		if errResult := checkTradingPair(cfg, pair); errResult != nil {
+			return errResult, nil
 		}
+
+		orderType := args.Type
 		if orderType != "BUY" && orderType != "SELL" {
 			return mcp.NewToolResultError("Order type must be 'BUY' or 'SELL'"), nil
 		}
-
-		volumeStr, err := request.RequireString("volume")
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("getting volume from request", err), nil
-		}
-
-		priceStr, err := request.RequireString("price")
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("getting price from request", err), nil
-		}
-
-		// Validate numeric values
-		volumeDec, err := decimal.NewFromString(volumeStr)
-		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Invalid volume format: %v", err)), nil
-		}
-
-		priceDec, err := decimal.NewFromString(priceStr)
-		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Invalid price format: %v", err)), nil
-		}
+		volumeDec, priceDec := args.Volume, args.Price
 
 		// Map BUY/SELL to BID/ASK for limit orders
 		var lunoOrderType luno.OrderType
@@ -414,6 +587,27 @@ func HandleCreateOrder(cfg *config.Config) server.ToolHandlerFunc {
 			lunoOrderType = luno.OrderTypeAsk
 		}
 
+		// Check the order against the cached market limits. Luno validates the order
+		// anyway, so carry on if the markets can't be listed.
+		market, ok, err := MarketCache(cfg).Lookup(ctx, pair)
+		switch {
+		case err != nil:
+			slog.WarnContext(ctx, "Failed to look up market for order validation", "pair", pair, "error", err)
+		case !ok:
+			return mcp.NewToolResultError(fmt.Sprintf("Unable to create order for %s: %s", pair, unknownMarketError)), nil
+		default:
+			if err := markets.CheckOrder(market, volumeDec, priceDec); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Unable to create order: %v", err)), nil
+			}
+		}
+		if err := checkPriceDeviation(ctx, cfg, pair, priceDec, args.AllowFarFromMarket); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Unable to create order: %v", err)), nil
+		}
+		if err := checkDuplicateOrder(ctx, cfg, pair, lunoOrderType, volumeDec, priceDec, args.AllowDuplicate); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Unable to create order: %v", err)), nil
+		}
+		statusLine := marketStatusLine(market)
+
 		// Get market info - we already validated the pair, but this provides additional info
 		marketInfoString, err := GetMarketInfo(ctx, cfg, pair)
 		if err != nil {
@@ -434,6 +628,9 @@ func HandleCreateOrder(cfg *config.Config) server.ToolHandlerFunc {
 			Type:   lunoOrderType,
 			Volume: volumeDec,
 			Price:  priceDec,
+			// Post-only markets reject orders that would trade immediately, so
+			// ask for that explicitly rather than relying on the market status
+			PostOnly: market.TradingStatus == luno.TradingStatusPost_only,
 		}
 
 		order, err := cfg.LunoClient.PostLimitOrder(ctx, createReq)
@@ -443,18 +640,31 @@ func HandleCreateOrder(cfg *config.Config) server.ToolHandlerFunc {
 				"Here's what we know about this market:\n%s\n\n"+
 				"This may be due to insufficient balance, market conditions, or API limits.",
 				err, marketInfoString)
+			if statusLine != "" {
+				errorMsg += "\n\n" + statusLine
+			}
 
 			return mcp.NewToolResultError(errorMsg), nil
 		}
 
 		// Order succeeded
-		resultJSON, err := json.MarshalIndent(order, "", "  ")
+		trackOrder(ctx, cfg, CreateOrderToolID, orders.Order{
+			OrderID: order.OrderId,
+			Pair:    pair,
+			Type:    lunoOrderType,
+			Volume:  volumeDec,
+			Price:   priceDec,
+		})
+		resultJSON, err := marshalJSON(order)
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal order result: %v", err)), nil
 		}
 
 		successMsg := fmt.Sprintf("Order created successfully!\n\n%s\n\n%s",
-			string(resultJSON), marketInfoString)
+			resultJSON, marketInfoString)
+		if statusLine != "" {
+			successMsg += "\n" + statusLine
+		}
 		return mcp.NewToolResultText(successMsg), nil
 	}
 }
@@ -473,31 +683,38 @@ func NewCancelOrderTool() mcp.Tool {
 	)
 }
 
+// orderIDArgs are the arguments of the tools that take only an order ID
+type orderIDArgs struct {
+	OrderID string `json:"order_id"`
+}
+
 // HandleCancelOrder handles the cancel_order tool
 func HandleCancelOrder(cfg *config.Config) server.ToolHandlerFunc {
+	tool := NewCancelOrderTool()
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		cfg := cfg.ForContext(ctx)
 		if !cfg.IsAuthenticated {
 			return mcp.NewToolResultError(ErrAPICredentialsRequired), nil
 		}
 
-		orderID, err := request.RequireString("order_id")
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("getting order_id from request", err), nil
+		var args orderIDArgs
+		if errResult := bindArguments(request, tool, &args); errResult != nil {
+			return errResult, nil
 		}
 
 		result, err := cfg.LunoClient.StopOrder(ctx, &luno.StopOrderRequest{
-			OrderId: orderID,
+			OrderId: args.OrderID,
 		})
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("Failed to cancel order: %v", err)), nil
 		}
 
-		resultJSON, err := json.MarshalIndent(result, "", "  ")
+		resultJSON, err := marshalJSON(result)
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal result: %v", err)), nil
 		}
 
-		return mcp.NewToolResultText(string(resultJSON)), nil
+		return mcp.NewToolResultText(resultJSON), nil
 	}
 }
 
@@ -505,38 +722,99 @@ func HandleCancelOrder(cfg *config.Config) server.ToolHandlerFunc {
 func NewListOrdersTool() mcp.Tool {
 	return mcp.NewTool(
 		ListOrdersToolID,
-		mcp.WithDescription("List open orders"),
-		mcp.WithString(
-			"pair",
-			mcp.Description("Trading pair (e.g., XBTZAR)"),
-		),
-		mcp.WithNumber(
-			"limit",
-			mcp.Description("Maximum number of orders to return (default: 100)"),
-		),
+		slices.Concat(
+			[]mcp.ToolOption{
+				mcp.WithDescription("List orders, newest first, with a summary of open order exposure, each open order's distance from the market price and any trade journal notes. Use state to review open or historical orders and next_created_before from the result to fetch the next page."),
+				mcp.WithString(
+					"pair",
+					mcp.Description("Trading pair (e.g., XBTZAR)"),
+				),
+				mcp.WithString(
+					"state",
+					mcp.Description("Filter by order state: PENDING (open), COMPLETE (filled or cancelled) or CANCELLED (complete but not fully filled)"),
+					mcp.Enum(string(luno.OrderStatePending), string(luno.OrderStateComplete), orderStateCancelled),
+				),
+				mcp.WithString(
+					"created_before",
+					mcp.Description(timeParamDesc("Only return orders created before this time")),
+				),
+				mcp.WithNumber(
+					"limit",
+					mcp.Description("Maximum number of orders to return (default: 100)"),
+					mcp.Min(1),
+				),
+			},
+			listParams("orders", "`type == 'BID' AND limit_price >= 1000000`"),
+		)...,
 	)
 }
 
+// orderStateCancelled is a list_orders state filter for complete orders that were not
+// fully filled. The Luno API reports these as COMPLETE.
+const orderStateCancelled = "CANCELLED"
+
+// ListOrdersResult is the result of the list_orders tool
+type ListOrdersResult struct {
+	Orders  []OrderView   `json:"orders"`
+	Summary OrdersSummary `json:"summary"`
+	// NextCreatedBefore is the created_before value for the next page, set when the page is full
+	NextCreatedBefore int64 `json:"next_created_before,omitempty"`
+}
+
+// listOrdersArgs are the arguments of the list_orders tool
+type listOrdersArgs struct {
+	listArgs
+	Pair          string `json:"pair"`
+	State         string `json:"state"`
+	CreatedBefore string `json:"created_before"`
+	Limit         int64  `json:"limit"`
+}
+
 // HandleListOrders handles the list_orders tool
 func HandleListOrders(cfg *config.Config) server.ToolHandlerFunc {
+	tool := NewListOrdersTool()
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		cfg := cfg.ForContext(ctx)
 		if !cfg.IsAuthenticated {
 			return mcp.NewToolResultError(ErrAPICredentialsRequired), nil
 		}
 
-		// Get the pair if provided, otherwise it will be an empty string.
-		// An empty pair string will result in fetching orders for all pairs.
-		pair := request.GetString("pair", "")
+		args := listOrdersArgs{Limit: 100}
+		if errResult := bindArguments(request, tool, &args); errResult != nil {
+			return errResult, nil
+		}
+
+		// An empty pair results in fetching orders for all pairs
+		pair := args.Pair
 		if pair != "" {
 			pair = normalizeCurrencyPair(pair)
 		}
 
-		// Default to 100 if not present
-		limit := request.GetFloat("limit", 100)
+		state := args.State
+		var apiState luno.OrderState
+		switch state {
+		case string(luno.OrderStatePending), string(luno.OrderStateComplete):
+			apiState = luno.OrderState(state)
+		case orderStateCancelled:
+			apiState = luno.OrderStateComplete
+		}
+
+		q, errResult := parseListQuery(args.listArgs)
+		if errResult != nil {
+			return errResult, nil
+		}
 
 		listReq := &luno.ListOrdersRequest{
 			Pair:  pair,
-			Limit: int64(limit),
+			Limit: args.Limit,
+			State: apiState,
+		}
+		createdBefore, ok, errResult := parseTime("created_before", args.CreatedBefore, time.Now())
+		if errResult != nil {
+			return errResult, nil
+		}
+		if ok {
+			listReq.CreatedBefore = createdBefore.Start.UnixMilli()
 		}
 
 		orders, err := cfg.LunoClient.ListOrders(ctx, listReq)
@@ -544,79 +822,141 @@ func HandleListOrders(cfg *config.Config) server.ToolHandlerFunc {
 			return mcp.NewToolResultError(fmt.Sprintf("Failed to list orders: %v", err)), nil
 		}
 
-		resultJSON, err := json.MarshalIndent(orders, "", "  ")
-		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal orders: %v", err)), nil
+		filtered := orders.Orders
+		if state == orderStateCancelled {
+			filtered = slices.DeleteFunc(slices.Clone(orders.Orders), func(o luno.Order) bool {
+				return !isCancelledOrder(o)
+			})
+		}
+		if filtered, errResult = applyFilter(q, filtered); errResult != nil {
+			return errResult, nil
+		}
+
+		var result ListOrdersResult
+		result.Orders, result.Summary = summarizeOrders(ctx, cfg.LunoClient, filtered)
+		attachJournalNotes(cfg, result.Orders)
+		if n := len(orders.Orders); n > 0 && int64(n) >= listReq.Limit {
+			result.NextCreatedBefore = time.Time(orders.Orders[n-1].CreationTimestamp).UnixMilli()
+		}
+		// Sorting the views lets orders be sorted by their distance from the market too
+		if result.Orders, errResult = applySort(q, result.Orders); errResult != nil {
+			return errResult, nil
 		}
 
-		return mcp.NewToolResultText(string(resultJSON)), nil
+		return listResult(q, result, "orders", newOrdersOutput(result, cachedPairCurrencies(cfg)))
 	}
 }
 
+// isCancelledOrder reports whether a complete order left the book before it was fully filled
+func isCancelledOrder(o luno.Order) bool {
+	return o.State == luno.OrderStateComplete && o.LimitVolume.Sign() > 0 && o.Base.Cmp(o.LimitVolume) < 0
+}
+
 // ===== Transaction Tools =====
 
 // NewListTransactionsTool creates a new tool for listing transactions
 func NewListTransactionsTool() mcp.Tool {
 	return mcp.NewTool(
 		ListTransactionsToolID,
-		mcp.WithDescription("List transactions for an account"),
-		mcp.WithString(
-			"account_id",
-			mcp.Required(),
-			mcp.Description("Account ID"),
-		),
-		mcp.WithNumber(
-			"min_row",
-			mcp.Description("Minimum row ID to return (for pagination, inclusive)"),
-		),
-		mcp.WithNumber(
-			"max_row",
-			mcp.Description("Maximum row ID to return (for pagination, exclusive)"),
-		),
+		slices.Concat(
+			[]mcp.ToolOption{mcp.WithDescription("List transactions for an account, given by account ID, asset or account name")},
+			accountParams(),
+			[]mcp.ToolOption{
+				mcp.WithNumber(
+					"min_row",
+					mcp.Description("Minimum row ID to return (for pagination, inclusive)"),
+				),
+				mcp.WithNumber(
+					"max_row",
+					mcp.Description("Maximum row ID to return (for pagination, exclusive)"),
+				),
+				mcp.WithString(
+					"since",
+					mcp.Description(timeParamDesc("Only return the transactions in the rows fetched made at or after this time")),
+				),
+				mcp.WithString(
+					"before",
+					mcp.Description(timeParamDesc("Only return the transactions in the rows fetched made before this time")),
+				),
+			},
+			listParams("transactions", "`balance_delta > 1000 AND kind == 'TRANSFER'`"),
+		)...,
 	)
 }
 
+// listTransactionsArgs are the arguments of the list_transactions tool
+type listTransactionsArgs struct {
+	accountArgs
+	listArgs
+	MinRow int64  `json:"min_row"`
+	MaxRow int64  `json:"max_row"`
+	Since  string `json:"since"`
+	Before string `json:"before"`
+}
+
 // HandleListTransactions handles the list_transactions tool
 func HandleListTransactions(cfg *config.Config) server.ToolHandlerFunc {
+	tool := NewListTransactionsTool()
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		cfg := cfg.ForContext(ctx)
 		if !cfg.IsAuthenticated {
 			return mcp.NewToolResultError(ErrAPICredentialsRequired), nil
 		}
 
-		accountIDStr, err := request.RequireString("account_id")
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("getting account_id from request", err), nil
+		args := listTransactionsArgs{MinRow: 1, MaxRow: 100}
+		if errResult := bindArguments(request, tool, &args); errResult != nil {
+			return errResult, nil
 		}
 
-		// Convert account ID from string to int64
-		accountID, err := strconv.ParseInt(accountIDStr, 10, 64)
-		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Invalid account ID format: %v. Please provide a valid numeric account ID.", err)), nil
+		accountID, errResult := resolveAccount(ctx, cfg, args.accountArgs)
+		if errResult != nil {
+			return errResult, nil
 		}
 
-		listReq := &luno.ListTransactionsRequest{
-			Id: accountID,
+		q, errResult := parseListQuery(args.listArgs)
+		if errResult != nil {
+			return errResult, nil
 		}
 
-		// Default to 1 if not present
-		minRow := request.GetInt("min_row", 1)
-		listReq.MinRow = int64(minRow)
+		listReq := &luno.ListTransactionsRequest{
+			Id:     accountID,
+			MinRow: args.MinRow,
+			MaxRow: args.MaxRow,
+		}
 
-		// Default to 100 if not present
-		maxRow := request.GetInt("max_row", 100)
-		listReq.MaxRow = int64(maxRow)
+		now := time.Now()
+		since, hasSince, errResult := parseTime("since", args.Since, now)
+		if errResult != nil {
+			return errResult, nil
+		}
+		before, hasBefore, errResult := parseTime("before", args.Before, now)
+		if errResult != nil {
+			return errResult, nil
+		}
 
 		transactions, err := cfg.LunoClient.ListTransactions(ctx, listReq)
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("Failed to list transactions: %v", err)), nil
 		}
-
-		resultJSON, err := json.MarshalIndent(transactions, "", "  ")
-		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal transactions: %v", err)), nil
+		listed := *transactions
+		if hasSince || hasBefore {
+			listed.Transactions = slices.DeleteFunc(slices.Clone(listed.Transactions), func(t luno.Transaction) bool {
+				ts := time.Time(t.Timestamp)
+				return (hasSince && ts.Before(since.Start)) || (hasBefore && !ts.Before(before.Start))
+			})
+		}
+		if listed.Transactions, errResult = applyFilter(q, listed.Transactions); errResult != nil {
+			return errResult, nil
+		}
+		if listed.Transactions, errResult = applySort(q, listed.Transactions); errResult != nil {
+			return errResult, nil
 		}
 
-		return mcp.NewToolResultText(string(resultJSON)), nil
+		structured := TransactionsOutput{AccountID: strconv.FormatInt(accountID, 10), Transactions: make([]TransactionAmounts, 0, len(listed.Transactions))}
+		for _, t := range listed.Transactions {
+			structured.Transactions = append(structured.Transactions, newTransactionAmounts(t))
+		}
+		return listResult(q, listed, "transactions", structured)
 	}
 }
 
@@ -624,42 +964,46 @@ func HandleListTransactions(cfg *config.Config) server.ToolHandlerFunc {
 func NewGetTransactionTool() mcp.Tool {
 	return mcp.NewTool(
 		GetTransactionToolID,
-		mcp.WithDescription("Get details of a specific transaction"),
-		mcp.WithString(
-			"account_id",
-			mcp.Required(),
-			mcp.Description("Account ID"),
-		),
-		mcp.WithString(
-			"transaction_id",
-			mcp.Required(),
-			mcp.Description("Transaction ID"),
-		),
+		slices.Concat(
+			[]mcp.ToolOption{mcp.WithDescription("Get details of a specific transaction in an account, given by account ID, asset or account name")},
+			accountParams(),
+			[]mcp.ToolOption{
+				mcp.WithString(
+					"transaction_id",
+					mcp.Required(),
+					mcp.Description("Transaction ID"),
+				),
+			},
+		)...,
 	)
 }
 
+// getTransactionArgs are the arguments of the get_transaction tool
+type getTransactionArgs struct {
+	accountArgs
+	TransactionID string `json:"transaction_id"`
+}
+
 // HandleGetTransaction handles the get_transaction tool
 func HandleGetTransaction(cfg *config.Config) server.ToolHandlerFunc {
+	tool := NewGetTransactionTool()
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		cfg := cfg.ForContext(ctx)
 		if !cfg.IsAuthenticated {
 			return mcp.NewToolResultError(ErrAPICredentialsRequired), nil
 		}
 
-		accountIDStr, err := request.RequireString("account_id")
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("getting account_id from request", err), nil
+		var args getTransactionArgs
+		if errResult := bindArguments(request, tool, &args); errResult != nil {
+			return errResult, nil
 		}
 
-		// Convert account ID from string to int64
-		accountID, err := strconv.ParseInt(accountIDStr, 10, 64)
-		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Invalid account ID format: %v. Please provide a valid numeric account ID.", err)), nil
+		accountID, errResult := resolveAccount(ctx, cfg, args.accountArgs)
+		if errResult != nil {
+			return errResult, nil
 		}
 
-		transactionIDStr, err := request.RequireString("transaction_id")
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("getting transaction_id from request", err), nil
-		}
+		transactionIDStr := args.TransactionID
 
 		// Attempt to convert transaction ID to int64 for comparison
 		transactionID, err := strconv.ParseInt(transactionIDStr, 10, 64)
@@ -692,12 +1036,12 @@ func HandleGetTransaction(cfg *config.Config) server.ToolHandlerFunc {
 			return mcp.NewToolResultError(fmt.Sprintf("Transaction not found: %s", transactionIDStr)), nil
 		}
 
-		resultJSON, err := json.MarshalIndent(transaction, "", "  ")
+		resultJSON, err := marshalJSON(transaction)
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal transaction: %v", err)), nil
 		}
 
-		return mcp.NewToolResultText(string(resultJSON)), nil
+		return mcp.NewToolResultStructured(newTransactionAmounts(*transaction), resultJSON), nil
 	}
 }
 
@@ -707,66 +1051,174 @@ func HandleGetTransaction(cfg *config.Config) server.ToolHandlerFunc {
 func NewListTradesTool() mcp.Tool {
 	return mcp.NewTool(
 		ListTradesToolID,
-		mcp.WithDescription("List recent trades for a currency pair"),
-		mcp.WithString(
-			"pair",
-			mcp.Required(),
-			mcp.Description(ErrTradingPairDesc),
-		),
-		mcp.WithString(
-			"since",
-			mcp.Description("Fetch trades executed after this timestamp (Unix milliseconds)"),
-		),
+		slices.Concat(
+			[]mcp.ToolOption{
+				mcp.WithDescription("List recent trades for a currency pair. Set aggregate to summarise them in buckets with open, high, low and close prices, " +
+					"volume by side and VWAP instead, since active markets trade too often to read trade by trade."),
+				mcp.WithString(
+					"pair",
+					mcp.Required(),
+					mcp.Description(ErrTradingPairDesc),
+				),
+				mcp.WithString(
+					"since",
+					mcp.Description(timeParamDesc("Fetch trades executed after this time")),
+				),
+				mcp.WithString(
+					"aggregate",
+					mcp.Description("Summarise the trades in buckets of this length, oldest first. filter applies to the trades; sort_by and fields to the buckets."),
+					mcp.Enum("1m", "5m", "15m", "1h"),
+				),
+			},
+			listParams("trades", "`is_buy == true AND volume >= 0.5`"),
+		)...,
 	)
 }
 
+// listTradesArgs are the arguments of the list_trades tool
+type listTradesArgs struct {
+	listArgs
+	Pair      string `json:"pair"`
+	Since     string `json:"since"`
+	Aggregate string `json:"aggregate"`
+}
+
 // HandleListTrades handles the list_trades tool
 func HandleListTrades(cfg *config.Config) server.ToolHandlerFunc {
+	tool := NewListTradesTool()
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		cfg := cfg.ForContext(ctx)
 		// This is a public endpoint, so no authentication check is needed here.
 		// However, the LunoClient.ListTrades method might still require authentication
 		// depending on the underlying luno-go library implementation.
 		// For now, we assume it can be called unauthenticated.
 
-		pair, err := request.RequireString("pair")
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("getting pair from request", err), nil
+		var args listTradesArgs
+		if errResult := bindArguments(request, tool, &args); errResult != nil {
+			return errResult, nil
+		}
+		pair := normalizeCurrencyPair(args.Pair)
+
+		q, errResult := parseListQuery(args.listArgs)
+		if errResult != nil {
+			return errResult, nil
 		}
 
-		// Normalize currency pair
-		pair = normalizeCurrencyPair(pair)
+		aggregate := args.Aggregate
+		bucketSize := tradeBucketSizes[aggregate]
 
 		req := &luno.ListTradesRequest{
 			Pair: pair,
 		}
 
-		sinceStr := request.GetString("since", "")
-		if sinceStr != "" {
-			// Try to parse the since timestamp
-			sinceInt, err := strconv.ParseInt(sinceStr, 10, 64)
-			if err != nil {
-				return mcp.NewToolResultError(fmt.Sprintf("Invalid 'since' timestamp format: %v. Please provide a valid Unix millisecond timestamp.", err)), nil
-			}
-			req.Since = luno.Time(time.UnixMilli(sinceInt))
+		since, ok, errResult := parseTime("since", args.Since, time.Now())
+		if errResult != nil {
+			return errResult, nil
+		}
+		if ok {
+			req.Since = luno.Time(since.Start)
 		}
 
 		trades, err := cfg.LunoClient.ListTrades(ctx, req)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("listing trades", err), nil
 		}
+		listed := *trades
+		if listed.Trades, errResult = applyFilter(q, listed.Trades); errResult != nil {
+			return errResult, nil
+		}
 
-		resultJSON, err := json.MarshalIndent(trades, "", "  ")
-		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal trades: %v", err)), nil
+		if aggregate != "" {
+			result := TradeBucketsResult{Pair: pair, Aggregate: aggregate, Trades: len(listed.Trades), Buckets: bucketTrades(listed.Trades, bucketSize)}
+			if result.Buckets, errResult = applySort(q, result.Buckets); errResult != nil {
+				return errResult, nil
+			}
+			return listResult(q, result, "buckets", nil)
+		}
+
+		if listed.Trades, errResult = applySort(q, listed.Trades); errResult != nil {
+			return errResult, nil
 		}
 
-		return mcp.NewToolResultText(string(resultJSON)), nil
+		structured := newTradesOutput(pair, listed.Trades, cachedPairCurrencies(cfg))
+		return listResult(q, listed, "trades", structured)
 	}
 }
 
 // ===== Helper Functions =====
 
+// parsePairList splits a comma-separated list of pairs, normalizing each one and
+// dropping blanks and duplicates
+func parsePairList(pairsStr string) []string {
+	var pairs []string
+	for _, p := range strings.Split(pairsStr, ",") {
+		if strings.TrimSpace(p) == "" {
+			continue
+		}
+		pair := normalizeCurrencyPair(strings.TrimSpace(p))
+		if !slices.Contains(pairs, pair) {
+			pairs = append(pairs, pair)
+		}
+	}
+	return pairs
+}
+
+// MarketCache returns the shared market cache, or a cache for this request when
+// the config has none
+func MarketCache(cfg *config.Config) *markets.Cache {
+	if cfg.Markets != nil {
+		return cfg.Markets
+	}
+	return markets.NewCache(cfg.LunoClient)
+}
+
+// validatePairs checks pairs against the markets listed by Luno, returning the known
+// pairs and an error entry for each unknown one. If the markets can't be listed all
+// pairs are returned as valid along with the error.
+func validatePairs(ctx context.Context, cache *markets.Cache, pairs []string) ([]string, []PairError, error) {
+	var valid []string
+	var rejected []PairError
+	for _, pair := range pairs {
+		_, ok, err := cache.Lookup(ctx, pair)
+		if err != nil {
+			return pairs, nil, err
+		}
+		if ok {
+			valid = append(valid, pair)
+		} else {
+			rejected = append(rejected, PairError{Pair: pair, Error: unknownMarketError})
+		}
+	}
+	return valid, rejected, nil
+}
+
+// marketStatusLine describes the market's trading status for order output. It
+// is empty when the status is not known.
+func marketStatusLine(m luno.MarketInfo) string {
+	if m.TradingStatus == "" {
+		return ""
+	}
+	return fmt.Sprintf("Market status for %s: %s. %s", m.MarketId, m.TradingStatus, describeTradingStatus(m.TradingStatus))
+}
+
+// formatPairErrors renders pair errors as a single line
+func formatPairErrors(errs []PairError) string {
+	parts := make([]string, len(errs))
+	for i, e := range errs {
+		parts[i] = fmt.Sprintf("%s (%s)", e.Pair, e.Error)
+	}
+	return strings.Join(parts, ", ")
+}
+
 // normalizeCurrencyPair converts common currency pair formats to Luno's expected format
+// currencyCodes maps common symbols to Luno's codes. The replacements are made
+// in one pass, so that the result doesn't depend on their order.
+var currencyCodes = strings.NewReplacer(
+	"BITCOIN", "XBT",
+	"BTC", "XBT", // Bitcoin is XBT on Luno
+	// Add other mappings if needed in the future
+)
+
 func normalizeCurrencyPair(pair string) string {
 	// Log input for debugging
 	originalPair := pair
@@ -778,17 +1230,7 @@ func normalizeCurrencyPair(pair string) string {
 	pair = strings.ToUpper(pair)
 
 	// Apply currency code standardization
-	// Known mappings between common symbols and Luno's expected format
-	currencyMappings := map[string]string{
-		"BTC":     "XBT", // Bitcoin is XBT on Luno
-		"BITCOIN": "XBT",
-		// Add other mappings if needed in the future
-	}
-
-	// Apply all mappings
-	for common, luno := range currencyMappings {
-		pair = strings.Replace(pair, common, luno, -1)
-	}
+	pair = currencyCodes.Replace(pair)
 
 	// Log the normalization for debugging
 	slog.Debug("Currency pair normalization", "original", originalPair, "normalized", pair)
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -15,6 +16,7 @@ import (
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // NewFromString is a test helper that creates a decimal from a string, failing the test on error.
@@ -37,7 +39,7 @@ func NewFromFloat64(t *testing.T, f float64) decimal.Decimal {
 const (
 	apiErrorStr               = "API error"
 	missingPairParameterStr   = "missing pair parameter"
-	gettingPairFromRequestStr = "getting pair from request"
+	gettingPairFromRequestStr = "pair is required"
 	invalidPairStr            = "invalid pair"
 	testTimestamp             = 1640995200000 // January 1, 2022 00:00:00 UTC
 )
@@ -74,6 +76,24 @@ func TestNormalizeCurrencyPair(t *testing.T) {
 	}
 }
 
+func FuzzNormalizeCurrencyPair(f *testing.F) {
+	for _, seed := range []string{
+		"btc/zar", "BTC-_/GBP", "Bitcoin to Rand", "BBITCOINC", "₿TC🚀ZAR", "xbt\u005fzar", "\xff\xfe",
+		strings.Repeat("BTC/", 10000),
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, pair string) {
+		got := normalizeCurrencyPair(pair)
+		if strings.ContainsAny(got, "-_/") {
+			t.Errorf("normalizeCurrencyPair(%q) = %q, which has separators", pair, got)
+		}
+		if again := normalizeCurrencyPair(pair); again != got {
+			t.Errorf("normalizeCurrencyPair(%q) gave %q, then %q", pair, got, again)
+		}
+	})
+}
+
 func TestToolCreation(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -115,7 +135,7 @@ func TestToolCreation(t *testing.T) {
 			name:     "ListOrders tool",
 			toolFunc: NewListOrdersTool,
 			toolName: ListOrdersToolID,
-			params:   []string{"pair", "limit"},
+			params:   []string{"pair", "state", "created_before", "limit"},
 		},
 		{
 			name:     "ListTransactions tool",
@@ -334,6 +354,100 @@ func TestHandleGetBalances(t *testing.T) {
 	}
 }
 
+func TestHandleGetBalancesConvertTo(t *testing.T) {
+	balances := &luno.GetBalancesResponse{
+		Balance: []luno.AccountBalance{
+			{AccountId: "1", Asset: "XBT", Balance: NewFromString(t, "0.5"), Name: "XBT Account"},
+			{AccountId: "2", Asset: "ZAR", Balance: NewFromString(t, "1000.00"), Name: "ZAR Account"},
+			{AccountId: "3", Asset: "USDC", Balance: NewFromString(t, "20.00"), Name: "USDC Account"},
+			{AccountId: "4", Asset: "FOO", Balance: NewFromString(t, "7"), Name: "FOO Account"},
+		},
+	}
+	tickers := &luno.GetTickersResponse{
+		Tickers: []luno.Ticker{
+			{Pair: "XBTZAR", LastTrade: NewFromString(t, "1000000.00")},
+			{Pair: "USDCZAR", LastTrade: NewFromString(t, "18.50")},
+			{Pair: "XBTUSDC", LastTrade: NewFromString(t, "60000.00")},
+			{Pair: "ETHZAR", LastTrade: NewFromString(t, "0")},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		convertTo      string
+		tickersErr     error
+		expTotal       string
+		expValues      []string
+		expUnpriced    []string
+		expErrContains string
+	}{
+		{
+			name:        "direct markets",
+			convertTo:   "zar",
+			expTotal:    "501370.00",
+			expValues:   []string{"500000.00", "1000.00", "370.00", ""},
+			expUnpriced: []string{"FOO"},
+		},
+		{
+			name:        "inverse markets with alias",
+			convertTo:   "BTC",
+			expTotal:    "0.50133333",
+			expValues:   []string{"0.5", "0.00100000", "0.00033333", ""},
+			expUnpriced: []string{"FOO"},
+		},
+		{
+			name:           "no market for currency",
+			convertTo:      "JPY",
+			expErrContains: "no Luno market to convert to JPY",
+		},
+		{
+			name:           "tickers error",
+			convertTo:      "ZAR",
+			tickersErr:     errors.New(apiErrorStr),
+			expErrContains: apiErrorStr,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := sdk.NewMockLunoClient(t)
+			mockClient.EXPECT().GetBalances(context.Background(), &luno.GetBalancesRequest{}).Return(balances, nil)
+			if tt.tickersErr != nil {
+				mockClient.EXPECT().GetTickers(context.Background(), &luno.GetTickersRequest{}).Return(nil, tt.tickersErr)
+			} else {
+				mockClient.EXPECT().GetTickers(context.Background(), &luno.GetTickersRequest{}).Return(tickers, nil)
+			}
+
+			cfg := &config.Config{LunoClient: mockClient, IsAuthenticated: true}
+			result, err := HandleGetBalances(cfg)(context.Background(), createMockRequest(map[string]any{"convert_to": tt.convertTo}))
+			require.NoError(t, err)
+
+			text := getTextContentFromResult(t, result)
+			if tt.expErrContains != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, text, tt.expErrContains)
+				return
+			}
+			require.False(t, result.IsError, text)
+
+			var got BalancesResult
+			require.NoError(t, json.Unmarshal([]byte(text), &got))
+			assert.Equal(t, tt.expTotal, got.TotalValue)
+			assert.Equal(t, tt.expUnpriced, got.UnpricedAssets)
+			var values []string
+			for _, b := range got.Balances {
+				values = append(values, b.Value)
+			}
+			assert.Equal(t, tt.expValues, values)
+
+			structured, ok := result.StructuredContent.(BalancesOutput)
+			require.True(t, ok)
+			require.NotNil(t, structured.TotalValue)
+			assert.Equal(t, tt.expTotal, structured.TotalValue.Value)
+			assert.Nil(t, structured.Balances[3].Value)
+		})
+	}
+}
+
 func TestHandleGetTicker(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -556,7 +670,7 @@ func TestHandleCancelOrder(t *testing.T) {
 			mockSetup:       func(t *testing.T, mockClient *sdk.MockLunoClient) { /* No mock setup needed for this case */ },
 			isAuthenticated: true,
 			expectedError:   true,
-			errorContains:   "getting order_id from request",
+			errorContains:   "order_id is required",
 		},
 		{
 			name: "CancelOrder API error",
@@ -649,6 +763,8 @@ func TestHandleListOrders(t *testing.T) {
 					Pair:  "XBTZAR",
 					Limit: 50,
 				}).Return(mockResponse, nil)
+				mockClient.EXPECT().GetTickers(context.Background(), &luno.GetTickersRequest{Pair: []string{"XBTZAR"}}).
+					Return(&luno.GetTickersResponse{Tickers: []luno.Ticker{{Pair: "XBTZAR", Bid: decimal.NewFromInt64(790000), Ask: decimal.NewFromInt64(800000)}}}, nil)
 			},
 			isAuthenticated: true,
 			expectedError:   false,
@@ -746,6 +862,176 @@ func TestHandleListOrders(t *testing.T) {
 	}
 }
 
+func TestHandleListOrdersFilters(t *testing.T) {
+	order := func(id string, created int64, limitVolume, base string) luno.Order {
+		return luno.Order{
+			OrderId:           id,
+			CreationTimestamp: luno.Time(time.UnixMilli(created)),
+			State:             luno.OrderStateComplete,
+			LimitVolume:       NewFromString(t, limitVolume),
+			Base:              NewFromString(t, base),
+			Pair:              "XBTZAR",
+		}
+	}
+	filled := order("filled", testTimestamp, "0.1", "0.1")
+	cancelled := order("cancelled", testTimestamp-1000, "0.1", "0.04")
+
+	tests := []struct {
+		name          string
+		requestParams map[string]any
+		expectedReq   *luno.ListOrdersRequest
+		response      []luno.Order
+		expectedIDs   []string
+		expectedNext  int64
+		errorContains string
+	}{
+		{
+			name:          "complete orders before a timestamp",
+			requestParams: map[string]any{"state": "complete", "created_before": float64(testTimestamp + 1)},
+			expectedReq:   &luno.ListOrdersRequest{Limit: 100, State: luno.OrderStateComplete, CreatedBefore: testTimestamp + 1},
+			response:      []luno.Order{filled, cancelled},
+			expectedIDs:   []string{"filled", "cancelled"},
+		},
+		{
+			name:          "complete orders before a date",
+			requestParams: map[string]any{"state": "complete", "created_before": "2024-01-01"},
+			expectedReq:   &luno.ListOrdersRequest{Limit: 100, State: luno.OrderStateComplete, CreatedBefore: 1704067200000},
+			response:      []luno.Order{filled, cancelled},
+			expectedIDs:   []string{"filled", "cancelled"},
+		},
+		{
+			name:          "invalid created_before",
+			requestParams: map[string]any{"created_before": "the other day"},
+			errorContains: `Invalid created_before: "the other day" is not a time`,
+		},
+		{
+			name:          "cancelled orders are filtered from complete orders",
+			requestParams: map[string]any{"state": "CANCELLED", "pair": "XBTZAR"},
+			expectedReq:   &luno.ListOrdersRequest{Pair: "XBTZAR", Limit: 100, State: luno.OrderStateComplete},
+			response:      []luno.Order{filled, cancelled},
+			expectedIDs:   []string{"cancelled"},
+		},
+		{
+			name:          "full page returns next cursor",
+			requestParams: map[string]any{"limit": float64(2)},
+			expectedReq:   &luno.ListOrdersRequest{Limit: 2},
+			response:      []luno.Order{filled, cancelled},
+			expectedIDs:   []string{"filled", "cancelled"},
+			expectedNext:  testTimestamp - 1000,
+		},
+		{
+			name:          "filter expression",
+			requestParams: map[string]any{"filter": "base < 0.1 AND order_id contains 'cancel'"},
+			expectedReq:   &luno.ListOrdersRequest{Limit: 100},
+			response:      []luno.Order{filled, cancelled},
+			expectedIDs:   []string{"cancelled"},
+		},
+		{
+			name:          "sorted oldest first",
+			requestParams: map[string]any{"sort_by": "creation_timestamp"},
+			expectedReq:   &luno.ListOrdersRequest{Limit: 100},
+			response:      []luno.Order{filled, cancelled},
+			expectedIDs:   []string{"cancelled", "filled"},
+		},
+		{
+			name:          "filter on unknown field",
+			requestParams: map[string]any{"filter": "amount > 1"},
+			expectedReq:   &luno.ListOrdersRequest{Limit: 100},
+			response:      []luno.Order{filled, cancelled},
+			errorContains: `Invalid filter: the results have no field "amount"`,
+		},
+		{
+			name:          "invalid filter",
+			requestParams: map[string]any{"filter": "state == PENDING"},
+			errorContains: "Invalid filter: expected a value after state ==",
+		},
+		{
+			name:          "invalid state",
+			requestParams: map[string]any{"state": "OPEN"},
+			errorContains: `state must be one of PENDING, COMPLETE, CANCELLED, got "OPEN"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := sdk.NewMockLunoClient(t)
+			if tt.expectedReq != nil {
+				mockClient.EXPECT().ListOrders(context.Background(), tt.expectedReq).
+					Return(&luno.ListOrdersResponse{Orders: tt.response}, nil)
+			}
+
+			cfg := &config.Config{LunoClient: mockClient, IsAuthenticated: true}
+			result, err := HandleListOrders(cfg)(context.Background(), createMockRequest(tt.requestParams))
+			assert.NoError(t, err)
+
+			text := getTextContentFromResult(t, result)
+			if tt.errorContains != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, text, tt.errorContains)
+				return
+			}
+
+			var got ListOrdersResult
+			assert.NoError(t, json.Unmarshal([]byte(text), &got))
+			ids := []string{}
+			for _, o := range got.Orders {
+				ids = append(ids, o.OrderId)
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+			assert.Equal(t, tt.expectedNext, got.NextCreatedBefore)
+		})
+	}
+}
+
+func TestHandleListTransactionsFilter(t *testing.T) {
+	mockClient := sdk.NewMockLunoClient(t)
+	mockClient.EXPECT().ListTransactions(context.Background(), &luno.ListTransactionsRequest{Id: 123456, MinRow: 1, MaxRow: 100}).
+		Return(&luno.ListTransactionsResponse{Id: "123456", Transactions: []luno.Transaction{
+			{RowIndex: 1, BalanceDelta: NewFromString(t, "1500"), Kind: luno.KindTransfer},
+			{RowIndex: 2, BalanceDelta: NewFromString(t, "-2.5"), Kind: luno.KindFee},
+			{RowIndex: 3, BalanceDelta: NewFromString(t, "200"), Kind: luno.KindTransfer},
+		}}, nil)
+
+	cfg := &config.Config{LunoClient: mockClient, IsAuthenticated: true}
+	request := createMockRequest(map[string]any{"account_id": "123456", "filter": "balance_delta > 1000 AND kind == 'transfer'"})
+	result, err := HandleListTransactions(cfg)(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError, getTextContentFromResult(t, result))
+
+	var got luno.ListTransactionsResponse
+	require.NoError(t, json.Unmarshal([]byte(getTextContentFromResult(t, result)), &got))
+	require.Len(t, got.Transactions, 1)
+	assert.Equal(t, int64(1), got.Transactions[0].RowIndex)
+	assert.Len(t, result.StructuredContent.(TransactionsOutput).Transactions, 1)
+}
+
+func TestHandleListTransactionsTimeRange(t *testing.T) {
+	at := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	mockClient := sdk.NewMockLunoClient(t)
+	mockClient.EXPECT().ListTransactions(context.Background(), &luno.ListTransactionsRequest{Id: 123456, MinRow: 1, MaxRow: 100}).
+		Return(&luno.ListTransactionsResponse{Id: "123456", Transactions: []luno.Transaction{
+			{RowIndex: 1, Timestamp: luno.Time(at.AddDate(0, 0, -2))},
+			{RowIndex: 2, Timestamp: luno.Time(at)},
+			{RowIndex: 3, Timestamp: luno.Time(at.Add(13 * time.Hour))},
+		}}, nil)
+
+	cfg := &config.Config{LunoClient: mockClient, IsAuthenticated: true}
+	request := createMockRequest(map[string]any{"account_id": "123456", "since": "2026-10-13", "before": "2026-10-15"})
+	result, err := HandleListTransactions(cfg)(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError, getTextContentFromResult(t, result))
+
+	var got luno.ListTransactionsResponse
+	require.NoError(t, json.Unmarshal([]byte(getTextContentFromResult(t, result)), &got))
+	require.Len(t, got.Transactions, 1)
+	assert.Equal(t, int64(2), got.Transactions[0].RowIndex)
+
+	result, err = HandleListTransactions(cfg)(context.Background(), createMockRequest(map[string]any{"account_id": "123456", "before": "whenever"}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, getTextContentFromResult(t, result), "Invalid before")
+}
+
 func TestHandleListTransactions(t *testing.T) {
 	tests := []struct {
 		name            string
@@ -795,17 +1081,20 @@ func TestHandleListTransactions(t *testing.T) {
 			mockSetup:       func(t *testing.T, mockClient *sdk.MockLunoClient) { /* No mock setup needed for this case */ },
 			isAuthenticated: true,
 			expectedError:   true,
-			errorContains:   "getting account_id from request",
+			errorContains:   "account_id is required",
 		},
 		{
-			name: "invalid account_id format",
+			name: "unknown account",
 			requestParams: map[string]any{
 				"account_id": "not_a_number",
 			},
-			mockSetup:       func(t *testing.T, mockClient *sdk.MockLunoClient) { /* No mock setup needed for this case */ },
+			mockSetup: func(t *testing.T, mockClient *sdk.MockLunoClient) {
+				mockClient.EXPECT().GetBalances(context.Background(), &luno.GetBalancesRequest{}).
+					Return(&luno.GetBalancesResponse{Balance: []luno.AccountBalance{{AccountId: "123456", Asset: "XBT"}}}, nil)
+			},
 			isAuthenticated: true,
 			expectedError:   true,
-			errorContains:   "Invalid account ID format",
+			errorContains:   `No account matches "not_a_number"`,
 		},
 		{
 			name: "ListTransactions API error",
@@ -951,7 +1240,7 @@ func TestHandleGetTransaction(t *testing.T) {
 			mockSetup:       func(t *testing.T, mockClient *sdk.MockLunoClient) { /* No mock setup needed */ },
 			isAuthenticated: true,
 			expectedError:   true,
-			errorContains:   "getting account_id from request",
+			errorContains:   "account_id is required",
 		},
 		{
 			name: "missing transaction_id parameter",
@@ -961,18 +1250,21 @@ func TestHandleGetTransaction(t *testing.T) {
 			mockSetup:       func(t *testing.T, mockClient *sdk.MockLunoClient) { /* No mock setup needed */ },
 			isAuthenticated: true,
 			expectedError:   true,
-			errorContains:   "getting transaction_id from request",
+			errorContains:   "transaction_id is required",
 		},
 		{
-			name: "invalid account_id format",
+			name: "unknown account",
 			requestParams: map[string]any{
 				"account_id":     "not_a_number",
 				"transaction_id": "5",
 			},
-			mockSetup:       func(t *testing.T, mockClient *sdk.MockLunoClient) { /* No mock setup needed */ },
+			mockSetup: func(t *testing.T, mockClient *sdk.MockLunoClient) {
+				mockClient.EXPECT().GetBalances(context.Background(), &luno.GetBalancesRequest{}).
+					Return(&luno.GetBalancesResponse{Balance: []luno.AccountBalance{{AccountId: "123456", Asset: "XBT"}}}, nil)
+			},
 			isAuthenticated: true,
 			expectedError:   true,
-			errorContains:   "Invalid account ID format",
+			errorContains:   `No account matches "not_a_number"`,
 		},
 		{
 			name: "invalid transaction_id format",
@@ -1031,6 +1323,78 @@ func TestHandleGetTransaction(t *testing.T) {
 	}
 }
 
+func TestHandleListTradesFilter(t *testing.T) {
+	mockClient := sdk.NewMockLunoClient(t)
+	mockClient.EXPECT().ListTrades(context.Background(), &luno.ListTradesRequest{Pair: "XBTZAR"}).
+		Return(&luno.ListTradesResponse{Trades: []luno.PublicTrade{
+			{Sequence: 1, IsBuy: true, Volume: NewFromString(t, "0.5"), Price: NewFromString(t, "800000")},
+			{Sequence: 2, IsBuy: false, Volume: NewFromString(t, "2"), Price: NewFromString(t, "800100")},
+			{Sequence: 3, IsBuy: true, Volume: NewFromString(t, "0.01"), Price: NewFromString(t, "800200")},
+		}}, nil)
+
+	cfg := &config.Config{LunoClient: mockClient}
+	request := createMockRequest(map[string]any{"pair": "XBTZAR", "filter": "is_buy == true AND volume >= 0.5"})
+	result, err := HandleListTrades(cfg)(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError, getTextContentFromResult(t, result))
+
+	var got luno.ListTradesResponse
+	require.NoError(t, json.Unmarshal([]byte(getTextContentFromResult(t, result)), &got))
+	require.Len(t, got.Trades, 1)
+	assert.Equal(t, int64(1), got.Trades[0].Sequence)
+}
+
+func TestHandleListTradesSortAndFields(t *testing.T) {
+	mockClient := sdk.NewMockLunoClient(t)
+	mockClient.EXPECT().ListTrades(context.Background(), &luno.ListTradesRequest{Pair: "XBTZAR"}).
+		Return(&luno.ListTradesResponse{Trades: []luno.PublicTrade{
+			{Sequence: 1, Volume: NewFromString(t, "0.5"), Price: NewFromString(t, "800000")},
+			{Sequence: 2, Volume: NewFromString(t, "2"), Price: NewFromString(t, "800100")},
+			{Sequence: 3, Volume: NewFromString(t, "0.01"), Price: NewFromString(t, "800200")},
+		}}, nil)
+
+	cfg := &config.Config{LunoClient: mockClient}
+	request := createMockRequest(map[string]any{"pair": "XBTZAR", "sort_by": "volume", "order": "desc", "fields": "sequence,volume"})
+	result, err := HandleListTrades(cfg)(context.Background(), request)
+	require.NoError(t, err)
+	text := getTextContentFromResult(t, result)
+	require.False(t, result.IsError, text)
+	assert.Nil(t, result.StructuredContent, "trimmed results are text only")
+	assert.JSONEq(t, `{"trades": [
+		{"sequence": 2, "volume": "2"},
+		{"sequence": 1, "volume": "0.5"},
+		{"sequence": 3, "volume": "0.01"}
+	]}`, text)
+}
+
+func TestHandleListTradesInvalidListParams(t *testing.T) {
+	tests := []struct {
+		name          string
+		args          map[string]any
+		notListed     bool
+		errorContains string
+	}{
+		{name: "unknown sort field", args: map[string]any{"sort_by": "size"}, errorContains: `Invalid sort_by: the results have no field "size" to sort by`},
+		{name: "unknown field", args: map[string]any{"fields": "sequence,size"}, errorContains: `Invalid fields: the results have no field "size"`},
+		{name: "invalid order", args: map[string]any{"sort_by": "volume", "order": "largest"}, notListed: true, errorContains: `order must be one of asc, desc, got "largest"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := sdk.NewMockLunoClient(t)
+			if !tt.notListed {
+				mockClient.EXPECT().ListTrades(context.Background(), &luno.ListTradesRequest{Pair: "XBTZAR"}).
+					Return(&luno.ListTradesResponse{Trades: []luno.PublicTrade{{Sequence: 1, Volume: NewFromString(t, "0.5")}}}, nil)
+			}
+			tt.args["pair"] = "XBTZAR"
+			result, err := HandleListTrades(&config.Config{LunoClient: mockClient})(context.Background(), createMockRequest(tt.args))
+			require.NoError(t, err)
+			assert.True(t, result.IsError)
+			assert.Contains(t, getTextContentFromResult(t, result), tt.errorContains)
+		})
+	}
+}
+
 func TestHandleListTrades(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -1103,7 +1467,7 @@ func TestHandleListTrades(t *testing.T) {
 			},
 			mockSetup:     func(t *testing.T, mockClient *sdk.MockLunoClient) { /* No mock setup needed */ },
 			expectedError: true,
-			errorContains: "Invalid 'since' timestamp format",
+			errorContains: `Invalid since: "not_a_number" is not a time`,
 		},
 		{
 			name: "ListTrades API error",
@@ -1171,6 +1535,23 @@ func createMockRequest(params map[string]any) mcp.CallToolRequest {
 }
 
 func TestHandleCreateOrder(t *testing.T) {
+	markets := &luno.MarketsResponse{
+		Markets: []luno.MarketInfo{
+			{
+				MarketId:      "XBTZAR",
+				TradingStatus: luno.TradingStatusActive,
+				MinVolume:     NewFromString(t, "0.0005"),
+				MaxVolume:     NewFromString(t, "100"),
+				MinPrice:      NewFromString(t, "1"),
+				MaxPrice:      NewFromString(t, "10000000"),
+				VolumeScale:   6,
+				PriceScale:    0,
+			},
+			{MarketId: "XBTEUR", TradingStatus: luno.TradingStatusSuspended},
+			{MarketId: "ETHZAR", TradingStatus: luno.TradingStatusPost_only},
+		},
+	}
+
 	tests := []struct {
 		name            string
 		requestParams   map[string]any
@@ -1178,6 +1559,7 @@ func TestHandleCreateOrder(t *testing.T) {
 		isAuthenticated bool
 		expectedError   bool
 		errorContains   string
+		expectedStatus  string
 	}{
 		{
 			name: "successful create order",
@@ -1191,6 +1573,8 @@ func TestHandleCreateOrder(t *testing.T) {
 				vol := NewFromString(t, "0.01")
 				price := NewFromString(t, "1000000")
 
+				mockClient.EXPECT().Markets(context.Background(), &luno.MarketsRequest{}).Return(markets, nil)
+
 				// Mock GetTicker call from GetMarketInfo
 				mockTickerResponse := &luno.GetTickerResponse{
 					Pair:                "XBTZAR",
@@ -1230,6 +1614,27 @@ func TestHandleCreateOrder(t *testing.T) {
 			},
 			isAuthenticated: true,
 			expectedError:   false,
+			expectedStatus:  "Market status for XBTZAR: ACTIVE. Trading normally.",
+		},
+		{
+			name:          "post-only market places a post-only order",
+			requestParams: map[string]any{"pair": "ETHZAR", "type": "SELL", "volume": "1", "price": "50000"},
+			mockSetup: func(t *testing.T, mockClient *sdk.MockLunoClient) {
+				mockClient.EXPECT().Markets(context.Background(), &luno.MarketsRequest{}).Return(markets, nil)
+				mockClient.EXPECT().GetTicker(context.Background(), &luno.GetTickerRequest{Pair: "ETHZAR"}).
+					Return(&luno.GetTickerResponse{Pair: "ETHZAR"}, nil)
+				mockClient.EXPECT().GetOrderBook(context.Background(), &luno.GetOrderBookRequest{Pair: "ETHZAR"}).
+					Return(&luno.GetOrderBookResponse{}, nil)
+				mockClient.EXPECT().PostLimitOrder(context.Background(), &luno.PostLimitOrderRequest{
+					Pair:     "ETHZAR",
+					Type:     luno.OrderTypeAsk,
+					Volume:   NewFromString(t, "1"),
+					Price:    NewFromString(t, "50000"),
+					PostOnly: true,
+				}).Return(&luno.PostLimitOrderResponse{OrderId: "BXMC2SEAS4KF5S2"}, nil)
+			},
+			isAuthenticated: true,
+			expectedStatus:  "Market status for ETHZAR: POST_ONLY. Post-only:",
 		},
 		{
 			name: "CreateOrder PostLimitOrder API error",
@@ -1243,6 +1648,8 @@ func TestHandleCreateOrder(t *testing.T) {
 				vol := NewFromString(t, "0.01")
 				price := NewFromString(t, "1000000")
 
+				mockClient.EXPECT().Markets(context.Background(), &luno.MarketsRequest{}).Return(markets, nil)
+
 				// Mock GetTicker call from GetMarketInfo
 				mockTickerResponse := &luno.GetTickerResponse{
 					Pair:                "XBTZAR",
@@ -1290,6 +1697,7 @@ func TestHandleCreateOrder(t *testing.T) {
 				"price":  "1000000",
 			},
 			mockSetup: func(t *testing.T, mockClient *sdk.MockLunoClient) {
+				mockClient.EXPECT().Markets(context.Background(), &luno.MarketsRequest{}).Return(markets, nil)
 				mockClient.EXPECT().GetTicker(mock.Anything, mock.Anything).Return(nil, errors.New("API error"))
 			},
 			isAuthenticated: true,
@@ -1305,6 +1713,7 @@ func TestHandleCreateOrder(t *testing.T) {
 				"price":  "1000000",
 			},
 			mockSetup: func(t *testing.T, mockClient *sdk.MockLunoClient) {
+				mockClient.EXPECT().Markets(context.Background(), &luno.MarketsRequest{}).Return(markets, nil)
 				mockClient.EXPECT().GetTicker(mock.Anything, mock.Anything).Return(&luno.GetTickerResponse{Pair: "XBTZAR"}, nil)
 				mockClient.EXPECT().GetOrderBook(mock.Anything, mock.Anything).Return(nil, errors.New("API error"))
 			},
@@ -1322,7 +1731,7 @@ func TestHandleCreateOrder(t *testing.T) {
 			mockSetup:       func(t *testing.T, mockClient *sdk.MockLunoClient) { /* No mock setup needed */ },
 			isAuthenticated: true,
 			expectedError:   true,
-			errorContains:   "required argument \"pair\" not found",
+			errorContains:   "pair is required",
 		},
 		{
 			name: "invalid volume for create order",
@@ -1335,7 +1744,58 @@ func TestHandleCreateOrder(t *testing.T) {
 			mockSetup:       func(t *testing.T, mockClient *sdk.MockLunoClient) { /* No mock setup needed */ },
 			isAuthenticated: true,
 			expectedError:   true,
-			errorContains:   "Invalid volume format",
+			errorContains:   `volume must be a decimal number such as 0.25, got "invalid_volume"`,
+		},
+		{
+			name:          "markets lookup failure does not block the order",
+			requestParams: map[string]any{"pair": "XBTZAR", "type": "BUY", "volume": "0.01", "price": "1000000"},
+			mockSetup: func(t *testing.T, mockClient *sdk.MockLunoClient) {
+				mockClient.EXPECT().Markets(context.Background(), &luno.MarketsRequest{}).Return(nil, errors.New(apiErrorStr))
+				mockClient.EXPECT().GetTicker(mock.Anything, mock.Anything).Return(nil, errors.New("API error"))
+			},
+			isAuthenticated: true,
+			expectedError:   true,
+			errorContains:   "Failed to retrieve market information for pair XBTZAR",
+		},
+		{
+			name:          "unknown market",
+			requestParams: map[string]any{"pair": "XBTZRA", "type": "BUY", "volume": "0.01", "price": "1000000"},
+			mockSetup: func(t *testing.T, mockClient *sdk.MockLunoClient) {
+				mockClient.EXPECT().Markets(context.Background(), &luno.MarketsRequest{}).Return(markets, nil)
+			},
+			isAuthenticated: true,
+			expectedError:   true,
+			errorContains:   "Unable to create order for XBTZRA: unknown market",
+		},
+		{
+			name:          "suspended market",
+			requestParams: map[string]any{"pair": "XBTEUR", "type": "BUY", "volume": "0.01", "price": "50000"},
+			mockSetup: func(t *testing.T, mockClient *sdk.MockLunoClient) {
+				mockClient.EXPECT().Markets(context.Background(), &luno.MarketsRequest{}).Return(markets, nil)
+			},
+			isAuthenticated: true,
+			expectedError:   true,
+			errorContains:   "trading on XBTEUR is suspended",
+		},
+		{
+			name:          "volume below market minimum",
+			requestParams: map[string]any{"pair": "XBTZAR", "type": "SELL", "volume": "0.0001", "price": "1000000"},
+			mockSetup: func(t *testing.T, mockClient *sdk.MockLunoClient) {
+				mockClient.EXPECT().Markets(context.Background(), &luno.MarketsRequest{}).Return(markets, nil)
+			},
+			isAuthenticated: true,
+			expectedError:   true,
+			errorContains:   "volume 0.0001 is below the minimum of 0.0005 for XBTZAR",
+		},
+		{
+			name:          "price with too many decimal places",
+			requestParams: map[string]any{"pair": "XBTZAR", "type": "BUY", "volume": "0.01", "price": "1000000.5"},
+			mockSetup: func(t *testing.T, mockClient *sdk.MockLunoClient) {
+				mockClient.EXPECT().Markets(context.Background(), &luno.MarketsRequest{}).Return(markets, nil)
+			},
+			isAuthenticated: true,
+			expectedError:   true,
+			errorContains:   "price 1000000.5 has more than the 0 decimal places allowed for XBTZAR",
 		},
 		{
 			name:            "unauthenticated create order",
@@ -1374,18 +1834,28 @@ func TestHandleCreateOrder(t *testing.T) {
 				assert.NotEmpty(t, textContent)
 				assert.Contains(t, textContent, "Order created successfully!")
 				assert.Contains(t, textContent, "BXMC2SEAS4KF5S2")
+				assert.Contains(t, textContent, tt.expectedStatus)
 			}
 		})
 	}
 }
 
 func TestHandleGetTickers(t *testing.T) {
+	markets := &luno.MarketsResponse{
+		Markets: []luno.MarketInfo{
+			{MarketId: "XBTZAR"},
+			{MarketId: "ETHZAR"},
+		},
+	}
+
 	tests := []struct {
-		name          string
-		requestParams map[string]any
-		mockSetup     func(*testing.T, *sdk.MockLunoClient)
-		expectedError bool
-		errorContains string
+		name           string
+		requestParams  map[string]any
+		mockSetup      func(*testing.T, *sdk.MockLunoClient)
+		expectedError  bool
+		errorContains  string
+		expectedPairs  []string
+		expectedErrors []PairError
 	}{
 		{
 			name: "successful get tickers with pair",
@@ -1399,10 +1869,12 @@ func TestHandleGetTickers(t *testing.T) {
 						{Pair: "ETHZAR"},
 					},
 				}
+				mockClient.EXPECT().Markets(context.Background(), &luno.MarketsRequest{}).Return(markets, nil)
 				mockClient.EXPECT().GetTickers(context.Background(), &luno.GetTickersRequest{Pair: []string{"XBTZAR", "ETHZAR"}}).
 					Return(mockResponse, nil)
 			},
 			expectedError: false,
+			expectedPairs: []string{"XBTZAR", "ETHZAR"},
 		},
 		{
 			name:          "successful get tickers without pair",
@@ -1415,15 +1887,56 @@ func TestHandleGetTickers(t *testing.T) {
 					Return(mockResponse, nil)
 			},
 			expectedError: false,
+			expectedPairs: []string{},
 		},
 		{
-			name: "GetTickers API error",
+			name: "invalid pair is reported alongside valid results",
+			requestParams: map[string]any{
+				"pair": "btc-zar, XBTZRA,ETHZAR,",
+			},
+			mockSetup: func(t *testing.T, mockClient *sdk.MockLunoClient) {
+				mockClient.EXPECT().Markets(context.Background(), &luno.MarketsRequest{}).Return(markets, nil)
+				mockClient.EXPECT().GetTickers(context.Background(), &luno.GetTickersRequest{Pair: []string{"XBTZAR", "ETHZAR"}}).
+					Return(&luno.GetTickersResponse{Tickers: []luno.Ticker{{Pair: "XBTZAR"}}}, nil)
+			},
+			expectedPairs: []string{"XBTZAR"},
+			expectedErrors: []PairError{
+				{Pair: "XBTZRA", Error: "unknown market, use get_markets_info to list valid pairs"},
+				{Pair: "ETHZAR", Error: "no ticker returned"},
+			},
+		},
+		{
+			name: "all pairs invalid",
 			requestParams: map[string]any{
 				"pair": "INVALID",
 			},
 			mockSetup: func(t *testing.T, mockClient *sdk.MockLunoClient) {
-				mockClient.EXPECT().GetTickers(context.Background(), &luno.GetTickersRequest{Pair: []string{"INVALID"}}).
-					Return(nil, errors.New(invalidPairStr))
+				mockClient.EXPECT().Markets(context.Background(), &luno.MarketsRequest{}).Return(markets, nil)
+			},
+			expectedError: true,
+			errorContains: "None of the requested pairs are valid: INVALID (unknown market",
+		},
+		{
+			name: "markets lookup failure falls back to tickers endpoint",
+			requestParams: map[string]any{
+				"pair": "XBTZAR",
+			},
+			mockSetup: func(t *testing.T, mockClient *sdk.MockLunoClient) {
+				mockClient.EXPECT().Markets(context.Background(), &luno.MarketsRequest{}).Return(nil, errors.New(apiErrorStr))
+				mockClient.EXPECT().GetTickers(context.Background(), &luno.GetTickersRequest{Pair: []string{"XBTZAR"}}).
+					Return(&luno.GetTickersResponse{Tickers: []luno.Ticker{{Pair: "XBTZAR"}}}, nil)
+			},
+			expectedPairs: []string{"XBTZAR"},
+		},
+		{
+			name: "GetTickers API error",
+			requestParams: map[string]any{
+				"pair": "XBTZAR",
+			},
+			mockSetup: func(t *testing.T, mockClient *sdk.MockLunoClient) {
+				mockClient.EXPECT().Markets(context.Background(), &luno.MarketsRequest{}).Return(markets, nil)
+				mockClient.EXPECT().GetTickers(context.Background(), &luno.GetTickersRequest{Pair: []string{"XBTZAR"}}).
+					Return(nil, errors.New(apiErrorStr))
 			},
 			expectedError: true,
 			errorContains: "getting tickers",
@@ -1447,11 +1960,18 @@ func TestHandleGetTickers(t *testing.T) {
 					errorMsg := getTextContentFromResult(t, result)
 					assert.Contains(t, errorMsg, tt.errorContains)
 				}
-			} else {
-				assert.False(t, result.IsError)
-				textContent := getTextContentFromResult(t, result)
-				assert.NotEmpty(t, textContent)
+				return
+			}
+
+			assert.False(t, result.IsError)
+			var tickers TickersResult
+			assert.NoError(t, json.Unmarshal([]byte(getTextContentFromResult(t, result)), &tickers))
+			pairs := []string{}
+			for _, ticker := range tickers.Tickers {
+				pairs = append(pairs, ticker.Pair)
 			}
+			assert.Equal(t, tt.expectedPairs, pairs)
+			assert.Equal(t, tt.expectedErrors, tickers.Errors)
 		})
 	}
 }
@@ -1492,7 +2012,7 @@ func TestHandleGetCandles(t *testing.T) {
 			},
 			mockSetup:     func(t *testing.T, mockClient *sdk.MockLunoClient) {},
 			expectedError: true,
-			errorContains: "getting duration from request",
+			errorContains: "duration is required",
 		},
 		{
 			name: "GetCandles API error",
@@ -0,0 +1,77 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/orders"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ReconcileOrdersResult is the reconcile_orders result
+type ReconcileOrdersResult struct {
+	orders.Reconciliation
+	Message string `json:"message"`
+}
+
+// trackOrder saves an order that tool has just placed, so that it can be
+// checked after a restart. Failing to save it is logged, not returned, since
+// the order has been placed either way.
+func trackOrder(ctx context.Context, cfg *config.Config, tool string, o orders.Order) {
+	if cfg.Orders == nil {
+		return
+	}
+	md, _ := sdk.RequestMetadataFromContext(ctx)
+	o.Tool = tool
+	o.SessionID = md.SessionID
+	o.RequestID = md.RequestID
+	o.Tenant = cfg.Tenant
+	if err := cfg.Orders.Add(o); err != nil {
+		slog.WarnContext(ctx, "Failed to save tracked order", "order_id", o.OrderID, "error", err)
+	}
+}
+
+// NewReconcileOrdersTool creates a tool for checking tracked orders against Luno
+func NewReconcileOrdersTool() mcp.Tool {
+	return mcp.NewTool(
+		ReconcileOrdersToolID,
+		mcp.WithDescription("Check the orders placed through this server that were still open, including those placed before it restarted, "+
+			"against Luno. Returns the orders still open, those that filled and those cancelled without trading since they were last checked."),
+	)
+}
+
+// HandleReconcileOrders handles the reconcile_orders tool
+func HandleReconcileOrders(cfg *config.Config) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		cfg := cfg.ForContext(ctx)
+		if !cfg.IsAuthenticated {
+			return mcp.NewToolResultError(ErrAPICredentialsRequired), nil
+		}
+		if cfg.Orders == nil {
+			return mcp.NewToolResultError("Order tracking is not available on this server"), nil
+		}
+
+		r, err := orders.Reconcile(ctx, cfg.Orders, cfg.LunoClient, cfg.Tenant)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to check tracked orders: %v", err)), nil
+		}
+		result := ReconcileOrdersResult{
+			Reconciliation: r,
+			Message: fmt.Sprintf("%d open, %d filled and %d cancelled since last checked.",
+				len(r.Open), len(r.Filled), len(r.Cancelled)),
+		}
+		if cfg.Orders.Path() == "" {
+			result.Message += " Orders are only tracked in memory, so those placed before the server started are not included."
+		}
+
+		resultJSON, err := marshalJSON(result)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal reconciliation: %v", err)), nil
+		}
+		return mcp.NewToolResultText(resultJSON), nil
+	}
+}
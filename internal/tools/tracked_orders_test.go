@@ -0,0 +1,81 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/orders"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrackOrder(t *testing.T) {
+	cfg := &config.Config{Orders: orders.New("")}
+	ctx := sdk.ContextWithRequestMetadata(context.Background(), sdk.RequestMetadata{SessionID: "s1"})
+
+	trackOrder(ctx, cfg, CreateOrderToolID, orders.Order{OrderID: "BXMC2CJ7HNB88U4", Pair: "XBTZAR", Type: luno.OrderTypeBid})
+	trackOrder(ctx, &config.Config{}, CreateOrderToolID, orders.Order{OrderID: "BXJ8GD6Y5CN8Q2R"})
+
+	list, err := cfg.Orders.List("")
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, CreateOrderToolID, list[0].Tool)
+	assert.Equal(t, "s1", list[0].SessionID)
+	assert.Equal(t, luno.OrderStatePending, list[0].State)
+}
+
+func TestHandleReconcileOrders(t *testing.T) {
+	tests := []struct {
+		name            string
+		isAuthenticated bool
+		noStore         bool
+		errorContains   string
+	}{
+		{name: "reconciles open orders", isAuthenticated: true},
+		{name: "requires credentials", errorContains: ErrAPICredentialsRequired},
+		{name: "not available", isAuthenticated: true, noStore: true, errorContains: "Order tracking is not available on this server"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			mockClient := sdk.NewMockLunoClient(t)
+			cfg := &config.Config{LunoClient: mockClient, IsAuthenticated: tt.isAuthenticated}
+			if !tt.noStore {
+				cfg.Orders = orders.New("")
+				require.NoError(t, cfg.Orders.Add(orders.Order{OrderID: "BXMC2CJ7HNB88U4", Pair: "XBTZAR"}))
+				require.NoError(t, cfg.Orders.Add(orders.Order{OrderID: "BXJ8GD6Y5CN8Q2R", Pair: "ETHZAR"}))
+			}
+			if tt.errorContains == "" {
+				mockClient.EXPECT().ListOrders(ctx, &luno.ListOrdersRequest{State: luno.OrderStatePending}).
+					Return(&luno.ListOrdersResponse{Orders: []luno.Order{{OrderId: "BXMC2CJ7HNB88U4", State: luno.OrderStatePending}}}, nil)
+				mockClient.EXPECT().GetOrder(ctx, &luno.GetOrderRequest{Id: "BXJ8GD6Y5CN8Q2R"}).
+					Return(&luno.GetOrderResponse{OrderId: "BXJ8GD6Y5CN8Q2R", State: luno.OrderStateComplete, Base: decimal.NewFromInt64(2)}, nil)
+			}
+
+			result, err := HandleReconcileOrders(cfg)(ctx, createMockRequest(map[string]any{}))
+			require.NoError(t, err)
+			text := getTextContentFromResult(t, result)
+			if tt.errorContains != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, text, tt.errorContains)
+				return
+			}
+			require.False(t, result.IsError, text)
+
+			var got ReconcileOrdersResult
+			require.NoError(t, json.Unmarshal([]byte(text), &got))
+			require.Len(t, got.Open, 1)
+			assert.Equal(t, "BXMC2CJ7HNB88U4", got.Open[0].OrderID)
+			require.Len(t, got.Filled, 1)
+			assert.Equal(t, "BXJ8GD6Y5CN8Q2R", got.Filled[0].OrderID)
+			assert.Contains(t, got.Message, "1 open, 1 filled and 0 cancelled")
+			assert.Contains(t, got.Message, "only tracked in memory")
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package tools
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+)
+
+// tradeBucketSizes are the list_trades aggregate intervals
+var tradeBucketSizes = map[string]time.Duration{
+	"1m":  time.Minute,
+	"5m":  5 * time.Minute,
+	"15m": 15 * time.Minute,
+	"1h":  time.Hour,
+}
+
+// TradeBucketsResult is the list_trades result when trades are aggregated
+type TradeBucketsResult struct {
+	Pair      string `json:"pair"`
+	Aggregate string `json:"aggregate"`
+	// Trades is how many trades were aggregated
+	Trades  int           `json:"trades"`
+	Buckets []TradeBucket `json:"buckets"`
+}
+
+// TradeBucket summarises the trades in one interval, like a candle. Buckets
+// without trades are left out.
+type TradeBucket struct {
+	// Timestamp is the start of the interval
+	Timestamp luno.Time       `json:"timestamp"`
+	Open      decimal.Decimal `json:"open"`
+	High      decimal.Decimal `json:"high"`
+	Low       decimal.Decimal `json:"low"`
+	Close     decimal.Decimal `json:"close"`
+	// Volume is the base volume traded, split by the taker's side into
+	// BuyVolume and SellVolume
+	Volume     decimal.Decimal `json:"volume"`
+	BuyVolume  decimal.Decimal `json:"buy_volume"`
+	SellVolume decimal.Decimal `json:"sell_volume"`
+	// VWAP is the volume-weighted average price
+	VWAP   decimal.Decimal `json:"vwap"`
+	Trades int             `json:"trades"`
+}
+
+// bucketTrades summarises trades in intervals of size, oldest first
+func bucketTrades(trades []luno.PublicTrade, size time.Duration) []TradeBucket {
+	sorted := slices.Clone(trades)
+	slices.SortStableFunc(sorted, func(a, b luno.PublicTrade) int {
+		return cmp.Or(time.Time(a.Timestamp).Compare(time.Time(b.Timestamp)), cmp.Compare(a.Sequence, b.Sequence))
+	})
+
+	buckets := []TradeBucket{}
+	var notional []decimal.Decimal
+	var scales []int
+	for _, t := range sorted {
+		start := time.Time(t.Timestamp).Truncate(size)
+		if n := len(buckets); n == 0 || !time.Time(buckets[n-1].Timestamp).Equal(start) {
+			buckets = append(buckets, TradeBucket{
+				Timestamp:  luno.Time(start),
+				Open:       t.Price,
+				High:       t.Price,
+				Low:        t.Price,
+				Volume:     decimal.Zero(),
+				BuyVolume:  decimal.Zero(),
+				SellVolume: decimal.Zero(),
+			})
+			notional = append(notional, decimal.Zero())
+			scales = append(scales, 0)
+		}
+		i := len(buckets) - 1
+		b := &buckets[i]
+		if t.Price.Cmp(b.High) > 0 {
+			b.High = t.Price
+		}
+		if t.Price.Cmp(b.Low) < 0 {
+			b.Low = t.Price
+		}
+		b.Close = t.Price
+		b.Volume = b.Volume.Add(t.Volume)
+		if t.IsBuy {
+			b.BuyVolume = b.BuyVolume.Add(t.Volume)
+		} else {
+			b.SellVolume = b.SellVolume.Add(t.Volume)
+		}
+		b.Trades++
+		notional[i] = notional[i].Add(t.Price.Mul(t.Volume))
+		scales[i] = max(scales[i], decimalScale(t.Price))
+	}
+	for i := range buckets {
+		if buckets[i].Volume.Sign() > 0 {
+			buckets[i].VWAP = notional[i].Div(buckets[i].Volume, scales[i])
+		} else {
+			buckets[i].VWAP = buckets[i].Close
+		}
+	}
+	return buckets
+}
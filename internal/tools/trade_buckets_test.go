@@ -0,0 +1,89 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBucketTrades(t *testing.T) {
+	at := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	trade := func(seq int64, offset time.Duration, isBuy bool, price, volume string) luno.PublicTrade {
+		return luno.PublicTrade{Sequence: seq, Timestamp: luno.Time(at.Add(offset)), IsBuy: isBuy, Price: NewFromString(t, price), Volume: NewFromString(t, volume)}
+	}
+	// Newest first, as Luno lists them
+	trades := []luno.PublicTrade{
+		trade(5, 6*time.Minute, true, "101", "1"),
+		trade(4, 4*time.Minute+59*time.Second, false, "99", "2"),
+		trade(3, 2*time.Minute, true, "104", "1"),
+		trade(2, 30*time.Second, true, "100", "3"),
+		trade(1, 30*time.Second, false, "102", "1"),
+	}
+
+	buckets := bucketTrades(trades, 5*time.Minute)
+	require.Len(t, buckets, 2)
+
+	first := buckets[0]
+	assert.Equal(t, at, time.Time(first.Timestamp))
+	assert.Equal(t, "102", first.Open.String(), "trades at the same time open in sequence order")
+	assert.Equal(t, "104", first.High.String())
+	assert.Equal(t, "99", first.Low.String())
+	assert.Equal(t, "99", first.Close.String())
+	assert.Equal(t, "7", first.Volume.String())
+	assert.Equal(t, "4", first.BuyVolume.String())
+	assert.Equal(t, "3", first.SellVolume.String())
+	// (102 + 300 + 104 + 198) / 7
+	assert.Equal(t, "100", first.VWAP.String())
+	assert.Equal(t, 4, first.Trades)
+
+	second := buckets[1]
+	assert.Equal(t, at.Add(5*time.Minute), time.Time(second.Timestamp))
+	assert.Equal(t, "101", second.Open.String())
+	assert.Equal(t, "101", second.Close.String())
+	assert.Equal(t, 1, second.Trades)
+
+	assert.Empty(t, bucketTrades(nil, time.Minute))
+}
+
+func TestHandleListTradesAggregate(t *testing.T) {
+	at := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	mockClient := sdk.NewMockLunoClient(t)
+	mockClient.EXPECT().ListTrades(context.Background(), &luno.ListTradesRequest{Pair: "XBTZAR"}).
+		Return(&luno.ListTradesResponse{Trades: []luno.PublicTrade{
+			{Sequence: 3, Timestamp: luno.Time(at.Add(61 * time.Second)), IsBuy: true, Price: NewFromString(t, "800200"), Volume: NewFromString(t, "0.5")},
+			{Sequence: 2, Timestamp: luno.Time(at.Add(10 * time.Second)), Price: NewFromString(t, "800100"), Volume: NewFromString(t, "2")},
+			{Sequence: 1, Timestamp: luno.Time(at), IsBuy: true, Price: NewFromString(t, "800000"), Volume: NewFromString(t, "0.01")},
+		}}, nil)
+
+	cfg := &config.Config{LunoClient: mockClient}
+	request := createMockRequest(map[string]any{"pair": "XBTZAR", "aggregate": "1m", "filter": "volume > 0.1", "sort_by": "volume", "order": "desc"})
+	result, err := HandleListTrades(cfg)(context.Background(), request)
+	require.NoError(t, err)
+	text := getTextContentFromResult(t, result)
+	require.False(t, result.IsError, text)
+
+	var got TradeBucketsResult
+	require.NoError(t, json.Unmarshal([]byte(text), &got))
+	assert.Equal(t, "XBTZAR", got.Pair)
+	assert.Equal(t, "1m", got.Aggregate)
+	assert.Equal(t, 2, got.Trades)
+	require.Len(t, got.Buckets, 2)
+	assert.Equal(t, "2", got.Buckets[0].Volume.String())
+	assert.Equal(t, at, time.Time(got.Buckets[0].Timestamp))
+	assert.Equal(t, "0.5", got.Buckets[1].BuyVolume.String())
+}
+
+func TestHandleListTradesInvalidAggregate(t *testing.T) {
+	result, err := HandleListTrades(&config.Config{LunoClient: sdk.NewMockLunoClient(t)})(context.Background(),
+		createMockRequest(map[string]any{"pair": "XBTZAR", "aggregate": "2m"}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, getTextContentFromResult(t, result), `aggregate must be one of 1m, 5m, 15m, 1h, got "2m"`)
+}
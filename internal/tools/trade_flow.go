@@ -0,0 +1,203 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/flow"
+	"github.com/luno/luno-mcp/internal/validate"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// defaultFlowWindows are the get_trade_flow windows when none are given
+	defaultFlowWindows = "5m,15m,1h"
+	// maxFlowWindows is the most windows get_trade_flow measures at once
+	maxFlowWindows = 6
+	// maxFlowWindow is the longest window, since Luno only lists the last 24 hours of trades
+	maxFlowWindow = 24 * time.Hour
+	// tradesPerPage is the most trades ListTrades returns
+	tradesPerPage = 100
+	// maxFlowPages is the most pages of trades get_trade_flow fetches
+	maxFlowPages = 20
+)
+
+// TradeFlowResult is the result of the get_trade_flow tool
+type TradeFlowResult struct {
+	Pair string `json:"pair"`
+	*flow.Result
+	// Trades is how many trades were fetched
+	Trades int      `json:"trades"`
+	Notes  []string `json:"notes"`
+}
+
+// NewGetTradeFlowTool creates a tool for measuring buy and sell pressure
+func NewGetTradeFlowTool() mcp.Tool {
+	return mcp.NewTool(
+		GetTradeFlowToolID,
+		mcp.WithDescription("Measure the order flow of a market from its recent public trades: volume bought and sold by takers, "+
+			"the buy ratio and net flow, and how many large trades went each way, over one or more windows ending now. "+
+			"A buy or sell bias means takers bought or sold at least 60% of the volume."),
+		mcp.WithString(
+			"pair",
+			mcp.Required(),
+			mcp.Description(ErrTradingPairDesc),
+		),
+		mcp.WithString(
+			"windows",
+			mcp.Description(fmt.Sprintf("Comma-separated window lengths, e.g. 1m,30m,4h (default: %s, at most %d windows of up to 24h)", defaultFlowWindows, maxFlowWindows)),
+		),
+		mcp.WithString(
+			"large_volume",
+			mcp.Description("Smallest base volume of a large trade (default: the volume of the largest 10% of trades in the longest window)"),
+			validate.Decimal(),
+			validate.ExclusiveMin(0),
+		),
+	)
+}
+
+// tradeFlowArgs are the arguments of the get_trade_flow tool
+type tradeFlowArgs struct {
+	Pair        string          `json:"pair"`
+	Windows     string          `json:"windows"`
+	LargeVolume decimal.Decimal `json:"large_volume"`
+}
+
+// HandleGetTradeFlow handles the get_trade_flow tool
+func HandleGetTradeFlow(cfg *config.Config) server.ToolHandlerFunc {
+	tool := NewGetTradeFlowTool()
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		cfg := cfg.ForContext(ctx)
+		// A zero large volume picks one from the trades
+		args := tradeFlowArgs{Windows: defaultFlowWindows, LargeVolume: decimal.Zero()}
+		if errResult := bindArguments(request, tool, &args); errResult != nil {
+			return errResult, nil
+		}
+		pair := normalizeCurrencyPair(args.Pair)
+
+		windows, err := parseFlowWindows(args.Windows)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Invalid windows: %v", err)), nil
+		}
+		params := flow.Params{Windows: windows, LargeVolume: args.LargeVolume}
+
+		now := time.Now()
+		since := now.Add(-slices.Max(windows))
+		trades, completeSince, err := recentTrades(ctx, cfg.LunoClient, pair, since)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("listing trades", err), nil
+		}
+		params.CompleteSince = completeSince
+
+		res, err := flow.Analyze(trades, now, params)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("measuring trade flow", err), nil
+		}
+		result := TradeFlowResult{
+			Pair:   pair,
+			Result: res,
+			Trades: len(trades),
+			Notes: []string{
+				"Sides are the taker's: buys lifted asks and sells hit bids. Order flow describes recent trading, not where the price will go.",
+			},
+		}
+		if completeSince.After(since) {
+			result.Notes = append(result.Notes, fmt.Sprintf("The market traded too often to fetch every trade; windows starting before %s are incomplete.",
+				completeSince.UTC().Format(time.RFC3339)))
+		}
+
+		resultJSON, err := marshalJSON(result)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal trade flow: %v", err)), nil
+		}
+		return mcp.NewToolResultText(resultJSON), nil
+	}
+}
+
+// parseFlowWindows reads a comma-separated list of window lengths
+func parseFlowWindows(s string) ([]time.Duration, error) {
+	var windows []time.Duration
+	for part := range strings.SplitSeq(s, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if part == "" {
+			continue
+		}
+		d, err := time.ParseDuration(part)
+		if err != nil || d < time.Minute || d > maxFlowWindow {
+			return nil, fmt.Errorf("%q must be a length from 1m to 24h, e.g. 5m or 1h", part)
+		}
+		if !slices.Contains(windows, d) {
+			windows = append(windows, d)
+		}
+	}
+	if len(windows) == 0 {
+		return nil, fmt.Errorf("list at least one window, e.g. %s", defaultFlowWindows)
+	}
+	if len(windows) > maxFlowWindows {
+		return nil, fmt.Errorf("at most %d windows can be measured at once", maxFlowWindows)
+	}
+	slices.Sort(windows)
+	return windows, nil
+}
+
+// recentTrades returns the public trades on pair since since, and the time
+// from which they are complete. It fetches the newest page first; if that
+// does not reach back to since, it pages forward from since until it meets
+// the newest page, up to maxFlowPages. Trades between the last page fetched
+// and the newest page are then missing, so only trades from the start of the
+// newest page are complete.
+func recentTrades(ctx context.Context, client sdk.LunoClient, pair string, since time.Time) ([]luno.PublicTrade, time.Time, error) {
+	newest, err := client.ListTrades(ctx, &luno.ListTradesRequest{Pair: pair})
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	pages := [][]luno.PublicTrade{newest.Trades}
+	if len(newest.Trades) < tradesPerPage {
+		return flow.Merge(pages...), since, nil
+	}
+	newestStart := oldestTrade(newest.Trades)
+	if !newestStart.After(since) {
+		return flow.Merge(pages...), since, nil
+	}
+
+	cursor := since
+	for range maxFlowPages - 1 {
+		page, err := client.ListTrades(ctx, &luno.ListTradesRequest{Pair: pair, Since: luno.Time(cursor)})
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		pages = append(pages, page.Trades)
+		if len(page.Trades) == 0 {
+			break
+		}
+		latest := slices.MaxFunc(page.Trades, func(a, b luno.PublicTrade) int {
+			return time.Time(a.Timestamp).Compare(time.Time(b.Timestamp))
+		})
+		if len(page.Trades) < tradesPerPage || !time.Time(latest.Timestamp).Before(newestStart) {
+			return flow.Merge(pages...), since, nil
+		}
+		if !time.Time(latest.Timestamp).After(cursor) {
+			break
+		}
+		cursor = time.Time(latest.Timestamp)
+	}
+	return flow.Merge(pages...), newestStart, nil
+}
+
+func oldestTrade(trades []luno.PublicTrade) time.Time {
+	oldest := time.Time(trades[0].Timestamp)
+	for _, t := range trades[1:] {
+		if ts := time.Time(t.Timestamp); ts.Before(oldest) {
+			oldest = ts
+		}
+	}
+	return oldest
+}
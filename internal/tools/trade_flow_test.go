@@ -0,0 +1,151 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFlowWindows(t *testing.T) {
+	got, err := parseFlowWindows(" 1h, 5m,,5M ,15m")
+	require.NoError(t, err)
+	assert.Equal(t, []time.Duration{5 * time.Minute, 15 * time.Minute, time.Hour}, got)
+
+	for s, want := range map[string]string{
+		"":                        "list at least one window",
+		"30s":                     `"30s" must be a length from 1m to 24h`,
+		"25h":                     `"25h" must be a length from 1m to 24h`,
+		"soon":                    `"soon" must be a length`,
+		"1m,2m,3m,4m,5m,6m,7m":    "at most 6 windows",
+		"1m,2m,3m,4m,5m,6m,6m,6m": "",
+	} {
+		_, err := parseFlowWindows(s)
+		if want == "" {
+			assert.NoError(t, err, s)
+			continue
+		}
+		assert.ErrorContains(t, err, want, s)
+	}
+}
+
+// fullPage returns tradesPerPage trades, one a second, the newest at latest
+func fullPage(t *testing.T, firstSeq int64, latest time.Time) []luno.PublicTrade {
+	var page []luno.PublicTrade
+	for i := range tradesPerPage {
+		page = append(page, luno.PublicTrade{
+			Sequence:  firstSeq + int64(tradesPerPage-1-i),
+			Timestamp: luno.Time(latest.Add(-time.Duration(i) * time.Second)),
+			Price:     NewFromString(t, "100"),
+			Volume:    NewFromString(t, "1"),
+		})
+	}
+	return page
+}
+
+func TestRecentTrades(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+	since := now.Add(-time.Hour)
+
+	t.Run("newest page reaches back far enough", func(t *testing.T) {
+		m := sdk.NewMockLunoClient(t)
+		m.EXPECT().ListTrades(ctx, &luno.ListTradesRequest{Pair: "XBTZAR"}).
+			Return(&luno.ListTradesResponse{Trades: []luno.PublicTrade{{Sequence: 1, Timestamp: luno.Time(now)}}}, nil)
+
+		trades, complete, err := recentTrades(ctx, m, "XBTZAR", since)
+		require.NoError(t, err)
+		assert.Len(t, trades, 1)
+		assert.Equal(t, since, complete)
+	})
+
+	t.Run("pages forward to meet the newest page", func(t *testing.T) {
+		m := sdk.NewMockLunoClient(t)
+		newest := fullPage(t, 1000, now)
+		m.EXPECT().ListTrades(ctx, &luno.ListTradesRequest{Pair: "XBTZAR"}).
+			Return(&luno.ListTradesResponse{Trades: newest}, nil)
+		m.EXPECT().ListTrades(ctx, &luno.ListTradesRequest{Pair: "XBTZAR", Since: luno.Time(since)}).
+			Return(&luno.ListTradesResponse{Trades: fullPage(t, 1, since.Add(30*time.Minute))}, nil)
+		m.EXPECT().ListTrades(ctx, &luno.ListTradesRequest{Pair: "XBTZAR", Since: luno.Time(since.Add(30 * time.Minute))}).
+			Return(&luno.ListTradesResponse{Trades: fullPage(t, 950, now.Add(-50*time.Second))}, nil)
+
+		trades, complete, err := recentTrades(ctx, m, "XBTZAR", since)
+		require.NoError(t, err)
+		assert.Equal(t, since, complete)
+		assert.Len(t, trades, 250, "overlapping trades are merged")
+	})
+
+	t.Run("gives up after too many pages", func(t *testing.T) {
+		m := sdk.NewMockLunoClient(t)
+		newest := fullPage(t, 100000, now)
+		m.EXPECT().ListTrades(ctx, &luno.ListTradesRequest{Pair: "XBTZAR"}).
+			Return(&luno.ListTradesResponse{Trades: newest}, nil).Once()
+		cursor := since
+		for i := range maxFlowPages - 1 {
+			m.EXPECT().ListTrades(ctx, &luno.ListTradesRequest{Pair: "XBTZAR", Since: luno.Time(cursor)}).
+				Return(&luno.ListTradesResponse{Trades: fullPage(t, int64(i*tradesPerPage+1), cursor.Add(tradesPerPage*time.Second))}, nil).Once()
+			cursor = cursor.Add(tradesPerPage * time.Second)
+		}
+
+		trades, complete, err := recentTrades(ctx, m, "XBTZAR", since)
+		require.NoError(t, err)
+		assert.Equal(t, oldestTrade(newest), complete)
+		assert.Len(t, trades, maxFlowPages*tradesPerPage)
+	})
+}
+
+func TestHandleGetTradeFlow(t *testing.T) {
+	tests := []struct {
+		name          string
+		args          map[string]any
+		listed        bool
+		errorContains string
+	}{
+		{name: "default windows", args: map[string]any{"pair": "btczar"}, listed: true},
+		{name: "invalid windows", args: map[string]any{"pair": "XBTZAR", "windows": "2d"}, errorContains: `Invalid windows: "2d" must be a length`},
+		{name: "invalid large volume", args: map[string]any{"pair": "XBTZAR", "large_volume": "lots"}, errorContains: `large_volume must be a decimal number such as 0.25, got "lots"`},
+		{name: "missing pair", args: map[string]any{}, errorContains: gettingPairFromRequestStr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := sdk.NewMockLunoClient(t)
+			if tt.listed {
+				now := time.Now()
+				m.EXPECT().ListTrades(mock.Anything, &luno.ListTradesRequest{Pair: "XBTZAR"}).
+					Return(&luno.ListTradesResponse{Trades: []luno.PublicTrade{
+						{Sequence: 2, Timestamp: luno.Time(now.Add(-time.Minute)), IsBuy: true, Price: NewFromString(t, "100"), Volume: NewFromString(t, "3")},
+						{Sequence: 1, Timestamp: luno.Time(now.Add(-30 * time.Minute)), Price: NewFromString(t, "100"), Volume: NewFromString(t, "1")},
+					}}, nil)
+			}
+
+			result, err := HandleGetTradeFlow(&config.Config{LunoClient: m})(context.Background(), createMockRequest(tt.args))
+			require.NoError(t, err)
+			text := getTextContentFromResult(t, result)
+			if tt.errorContains != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, text, tt.errorContains)
+				return
+			}
+			require.False(t, result.IsError, text)
+
+			var got TradeFlowResult
+			require.NoError(t, json.Unmarshal([]byte(text), &got))
+			assert.Equal(t, "XBTZAR", got.Pair)
+			assert.Equal(t, 2, got.Trades)
+			require.Len(t, got.Windows, 3)
+			assert.Equal(t, []string{"5m", "15m", "1h"}, []string{got.Windows[0].Window, got.Windows[1].Window, got.Windows[2].Window})
+			assert.Equal(t, "buy", got.Windows[0].Bias)
+			assert.Equal(t, "2", got.Windows[2].NetVolume.String())
+			assert.True(t, got.Windows[2].Complete)
+			assert.Len(t, got.Notes, 1)
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package tools
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// pairAllowed reports whether orders may be placed on pair, a normalized
+// pair, under cfg.TradingPairs
+func pairAllowed(cfg *config.Config, pair string) bool {
+	return len(cfg.TradingPairs) == 0 || slices.ContainsFunc(cfg.TradingPairs, func(p string) bool {
+		return normalizeCurrencyPair(p) == pair
+	})
+}
+
+// pairNotAllowedError explains that pair is outside cfg.TradingPairs
+func pairNotAllowedError(cfg *config.Config, pair string) error {
+	return fmt.Errorf("trading policy: %s is not an allowed trading pair; orders can only be placed on %s",
+		pair, strings.Join(cfg.TradingPairs, ", "))
+}
+
+// checkTradingPair returns a policy error result if orders may not be placed
+// on pair, and nil if they may
+func checkTradingPair(cfg *config.Config, pair string) *mcp.CallToolResult {
+	if pairAllowed(cfg, pair) {
+		return nil
+	}
+	result := mcp.NewToolResultError(fmt.Sprintf("Trading policy: %s is not an allowed trading pair. Orders can only be placed on %s. "+
+		"Do not place the order on another pair instead unless the user asks for it.", pair, strings.Join(cfg.TradingPairs, ", ")))
+	result.StructuredContent = map[string]any{
+		"error":         "pair_not_allowed",
+		"pair":          pair,
+		"trading_pairs": cfg.TradingPairs,
+	}
+	return result
+}
@@ -0,0 +1,65 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTradingPairs(t *testing.T) {
+	cfg := &config.Config{IsAuthenticated: true, TradingPairs: []string{"XBTZAR", "ETH-ZAR"}}
+	assert.True(t, pairAllowed(cfg, "XBTZAR"))
+	assert.True(t, pairAllowed(cfg, "ETHZAR"))
+	assert.False(t, pairAllowed(cfg, "SOLZAR"))
+	assert.True(t, pairAllowed(&config.Config{}, "SOLZAR"), "any pair is allowed without a list")
+
+	t.Run("create_order", func(t *testing.T) {
+		cfg := *cfg
+		cfg.LunoClient = sdk.NewMockLunoClient(t)
+		result, err := HandleCreateOrder(&cfg)(context.Background(), createMockRequest(map[string]any{
+			"pair": "sol/zar", "type": "BUY", "volume": "1", "price": "3000",
+		}))
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Contains(t, getTextContentFromResult(t, result), "Trading policy: SOLZAR is not an allowed trading pair. Orders can only be placed on XBTZAR, ETH-ZAR.")
+		assert.Equal(t, "pair_not_allowed", result.StructuredContent.(map[string]any)["error"])
+	})
+
+	t.Run("create_market_order", func(t *testing.T) {
+		cfg := *cfg
+		cfg.LunoClient = sdk.NewMockLunoClient(t)
+		result, err := HandleCreateMarketOrder(&cfg)(context.Background(), createMockRequest(map[string]any{
+			"pair": "SOLZAR", "type": "BUY", "counter_volume": "100",
+		}))
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Contains(t, getTextContentFromResult(t, result), "SOLZAR is not an allowed trading pair")
+	})
+
+	t.Run("create_orders_batch", func(t *testing.T) {
+		cfg := *cfg
+		client := sdk.NewMockLunoClient(t)
+		client.EXPECT().Markets(context.Background(), &luno.MarketsRequest{}).Return(&luno.MarketsResponse{Markets: []luno.MarketInfo{
+			{MarketId: "XBTZAR", VolumeScale: 4, PriceScale: 0},
+		}}, nil).Maybe()
+		cfg.LunoClient = client
+		result, err := HandleCreateOrdersBatch(&cfg)(context.Background(), createMockRequest(map[string]any{
+			"orders": `[{"pair": "XBTZAR", "type": "BUY", "volume": "0.001", "price": "1000000"},
+				{"pair": "SOLZAR", "type": "BUY", "volume": "1", "price": "3000"}]`,
+		}))
+		require.NoError(t, err)
+		var got CreateOrdersBatchResult
+		require.NoError(t, json.Unmarshal([]byte(getTextContentFromResult(t, result)), &got))
+		assert.Equal(t, BatchStatusInvalid, got.Status)
+		require.Len(t, got.Orders, 2)
+		assert.Equal(t, BatchOrderStatusValid, got.Orders[0].Status)
+		assert.Equal(t, BatchOrderStatusInvalid, got.Orders[1].Status)
+		assert.Equal(t, "trading policy: SOLZAR is not an allowed trading pair; orders can only be placed on XBTZAR, ETH-ZAR", got.Orders[1].Error)
+	})
+}
@@ -0,0 +1,77 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// NewExportSessionTool creates a tool for exporting what this session did
+func NewExportSessionTool() mcp.Tool {
+	return mcp.NewTool(
+		ExportSessionToolID,
+		mcp.WithDescription("Export a transcript of this session's tool calls, with their arguments and results and secrets redacted, "+
+			"as Markdown or JSON, for the user to share exactly what was done, e.g. when reporting an issue. "+
+			"The file is written into a root granted by the client (see list_roots); "+
+			"if the client has not granted any roots the transcript is returned inline."),
+		mcp.WithString(
+			"format",
+			mcp.Description("File format: markdown (default) or json"),
+			mcp.Enum("markdown", "json"),
+		),
+		mcp.WithString(
+			"root",
+			mcp.Description("URI of the granted root to write to (defaults to the first granted root)"),
+		),
+		mcp.WithString(
+			"filename",
+			mcp.Description("File name relative to the root (defaults to session-<timestamp>.md or .json)"),
+		),
+	)
+}
+
+// exportSessionArgs are the arguments of the export_session tool
+type exportSessionArgs struct {
+	rootFileArgs
+	Format string `json:"format"`
+}
+
+// HandleExportSession handles the export_session tool. Sessions only export
+// their own transcript, which holds the calls made before this one.
+func HandleExportSession(cfg *config.Config) server.ToolHandlerFunc {
+	tool := NewExportSessionTool()
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := exportSessionArgs{Format: "markdown"}
+		if errResult := bindArguments(request, tool, &args); errResult != nil {
+			return errResult, nil
+		}
+		if cfg.Transcripts == nil {
+			return mcp.NewToolResultError("Session transcripts are not available on this server"), nil
+		}
+		t, ok := cfg.Transcripts.Get(sessionID(ctx))
+		if !ok {
+			return mcp.NewToolResultError("This session has made no tool calls to export yet"), nil
+		}
+
+		now := time.Now().UTC()
+		ext, data := "md", t.Markdown(now)
+		if args.Format == "json" {
+			var err error
+			ext = "json"
+			if data, err = json.MarshalIndent(t, "", "  "); err != nil {
+				return mcp.NewToolResultErrorFromErr("encoding transcript", err), nil
+			}
+		}
+
+		filename := args.Filename
+		if filename == "" {
+			filename = fmt.Sprintf("session-%s.%s", now.Format("20060102T150405Z"), ext)
+		}
+		return exportResult(ctx, args.Root, filename, data, len(t.Calls))
+	}
+}
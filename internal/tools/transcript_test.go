@@ -0,0 +1,53 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/transcript"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleExportSession(t *testing.T) {
+	srv := server.NewMCPServer("test", "1.0.0")
+	ctx := srv.WithContext(context.Background(), server.NewInProcessSession("session-1", nil))
+	otherCtx := srv.WithContext(context.Background(), server.NewInProcessSession("session-2", nil))
+
+	cfg := &config.Config{Transcripts: transcript.NewStore()}
+	cfg.Transcripts.Record("session-1", transcript.Call{Time: time.Now(), Tool: GetTickerToolID, Arguments: `{"pair":"XBTZAR"}`, Result: `{"bid":"1000000"}`})
+	handler := HandleExportSession(cfg)
+
+	result, err := handler(ctx, createMockRequest(nil))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	text := getTextContentFromResult(t, result)
+	assert.Contains(t, text, "Export returned inline")
+	assert.Contains(t, text, "# Session transcript")
+	assert.Contains(t, text, "## 1. get_ticker")
+
+	result, err = handler(ctx, createMockRequest(map[string]any{"format": "json"}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	text = getTextContentFromResult(t, result)
+	var got transcript.Transcript
+	require.NoError(t, json.Unmarshal([]byte(text[strings.Index(text, "{"):]), &got))
+	assert.Equal(t, "session-1", got.SessionID)
+	require.Len(t, got.Calls, 1)
+	assert.Equal(t, GetTickerToolID, got.Calls[0].Tool)
+
+	// Sessions only see their own transcript
+	result, err = handler(otherCtx, createMockRequest(nil))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, getTextContentFromResult(t, result), "no tool calls to export")
+
+	result, err = HandleExportSession(&config.Config{})(ctx, createMockRequest(nil))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
@@ -0,0 +1,246 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/twap"
+	"github.com/luno/luno-mcp/internal/validate"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// execute_twap actions
+const (
+	twapActionStart  = "start"
+	twapActionStatus = "status"
+	twapActionCancel = "cancel"
+)
+
+// twapNotificationLogger is the logger name used for TWAP progress notifications
+const twapNotificationLogger = "luno-mcp/twap"
+
+// NewExecuteTWAPTool creates a tool for executing a large order in slices over time
+func NewExecuteTWAPTool() mcp.Tool {
+	return mcp.NewTool(
+		ExecuteTWAPToolID,
+		mcp.WithDescription("Execute a large order as a series of smaller limit orders spread evenly over a time window (TWAP), "+
+			"to reduce its market impact. Each slice takes the best price on the other side of the book but never goes beyond limit_price; "+
+			"whatever a slice has not filled by the next one is cancelled and spread over the remaining slices. "+
+			"Runs in the background and sends a notification after each slice. Use action=status to check progress and action=cancel to stop, "+
+			"which cancels the open slice."+writeOperationNotice),
+		mcp.WithString(
+			"action",
+			mcp.Description("What to do (default: start). status without an id lists all executions."),
+			mcp.Enum(twapActionStart, twapActionStatus, twapActionCancel),
+		),
+		mcp.WithString(
+			"id",
+			mcp.Description("ID of the execution to check or cancel"),
+		),
+		mcp.WithString(
+			"pair",
+			mcp.Description(ErrTradingPairDesc),
+		),
+		mcp.WithString(
+			"side",
+			mcp.Description("Trade direction (BUY or SELL)"),
+			mcp.Enum("BUY", "SELL"),
+		),
+		mcp.WithString(
+			"volume",
+			mcp.Description("Total volume to trade, in the pair's base currency"),
+			validate.Decimal(),
+			validate.ExclusiveMin(0),
+		),
+		mcp.WithString(
+			"limit_price",
+			mcp.Description("Worst price to trade at: the most to pay when buying, the least to accept when selling"),
+			validate.Decimal(),
+			validate.ExclusiveMin(0),
+		),
+		mcp.WithNumber(
+			"slices",
+			mcp.Description(fmt.Sprintf("Number of orders to split the volume into, %d to %d", twap.MinSlices, twap.MaxSlices)),
+			mcp.Min(twap.MinSlices),
+			mcp.Max(twap.MaxSlices),
+		),
+		mcp.WithString(
+			"duration",
+			mcp.Description(fmt.Sprintf("Time window to spread the slices over, e.g. 30m or 2h (at most %s). Slices must be at least %s apart.",
+				twap.MaxDuration, twap.MinInterval)),
+		),
+	)
+}
+
+// twapArgs are the arguments of the execute_twap tool
+type twapArgs struct {
+	Action     string          `json:"action"`
+	ID         string          `json:"id"`
+	Pair       string          `json:"pair"`
+	Side       string          `json:"side"`
+	Volume     decimal.Decimal `json:"volume"`
+	LimitPrice decimal.Decimal `json:"limit_price"`
+	Slices     int             `json:"slices"`
+	Duration   string          `json:"duration"`
+}
+
+// HandleExecuteTWAP handles the execute_twap tool
+func HandleExecuteTWAP(cfg *config.Config) server.ToolHandlerFunc {
+	tool := NewExecuteTWAPTool()
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		cfg := cfg.ForContext(ctx)
+		if cfg.TWAP == nil {
+			return mcp.NewToolResultError("TWAP execution is not available on this server"), nil
+		}
+
+		args := twapArgs{Action: twapActionStart}
+		if errResult := bindArguments(request, tool, &args); errResult != nil {
+			return errResult, nil
+		}
+		switch args.Action {
+		case twapActionStatus:
+			id := args.ID
+			if id == "" {
+				return twapResult(cfg.TWAP.List(cfg.Tenant))
+			}
+			execution, ok := cfg.TWAP.Get(cfg.Tenant, id)
+			if !ok {
+				return mcp.NewToolResultError(fmt.Sprintf("No TWAP execution with ID %q", id)), nil
+			}
+			return twapResult(execution)
+		case twapActionCancel:
+			if errResult := requireArguments(request, "id"); errResult != nil {
+				return errResult, nil
+			}
+			if !cfg.TWAP.Cancel(cfg.Tenant, args.ID) {
+				return mcp.NewToolResultError(fmt.Sprintf("No TWAP execution with ID %q", args.ID)), nil
+			}
+			return mcp.NewToolResultText(fmt.Sprintf("Cancelling TWAP execution %s; its open slice will be cancelled. Use action=status to see what was filled.", args.ID)), nil
+		}
+
+		if !cfg.IsAuthenticated {
+			return mcp.NewToolResultError(ErrAPICredentialsRequired), nil
+		}
+		if errResult := requireArguments(request, "pair", "side", "volume", "limit_price", "slices", "duration"); errResult != nil {
+			return errResult, nil
+		}
+		pair := normalizeCurrencyPair(args.Pair)
+		if errResult := checkTradingPair(cfg, pair); errResult != nil {
+			return errResult, nil
+		}
+
+		var orderType luno.OrderType
+		switch args.Side {
+		case "BUY":
+			orderType = luno.OrderTypeBid
+		case "SELL":
+			orderType = luno.OrderTypeAsk
+		default:
+			return mcp.NewToolResultError("side must be 'BUY' or 'SELL'"), nil
+		}
+
+		volume, limitPrice := args.Volume, args.LimitPrice
+		duration, err := time.ParseDuration(args.Duration)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid duration %q: use a duration such as 30m or 2h", args.Duration)), nil
+		}
+
+		market, ok, err := MarketCache(cfg).Lookup(ctx, pair)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("looking up market", err), nil
+		}
+		if !ok {
+			return mcp.NewToolResultError(fmt.Sprintf("Unable to execute order for %s: %s", pair, unknownMarketError)), nil
+		}
+
+		plan := twap.Plan{
+			Market:     market,
+			Side:       orderType,
+			Volume:     volume,
+			Slices:     args.Slices,
+			Duration:   duration,
+			LimitPrice: limitPrice,
+			Tenant:     cfg.Tenant,
+			Allowed:    cfg.TradingHours.Allowed,
+		}
+		if err := plan.Validate(); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Unable to execute order: %v", err)), nil
+		}
+
+		// The whole order must be covered up front, so slices don't start failing halfway
+		balances, err := cfg.LunoClient.GetBalances(ctx, &luno.GetBalancesRequest{})
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("getting balances", err), nil
+		}
+		needed, currency := volume, market.BaseCurrency
+		if orderType == luno.OrderTypeBid {
+			needed, currency = volume.Mul(limitPrice), market.CounterCurrency
+		}
+		available := decimal.Zero()
+		if a := availableBalance(balances.Balance, currency); a != nil {
+			available = *a
+		}
+		if available.Cmp(needed) < 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("Unable to execute order: it needs up to %s %s but only %s %s is available",
+				needed, currency, available, currency)), nil
+		}
+
+		execution, err := cfg.TWAP.Start(plan, cfg.LunoClient, twapNotifier(ctx, request))
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("starting TWAP execution", err), nil
+		}
+		return twapResult(execution)
+	}
+}
+
+// twapNotifier sends an execution's progress to the session that started it, as
+// a log message and, if the client asked for them, progress notifications
+func twapNotifier(ctx context.Context, request mcp.CallToolRequest) twap.NotifyFunc {
+	srv := server.ServerFromContext(ctx)
+	session := server.ClientSessionFromContext(ctx)
+	if srv == nil || session == nil {
+		return nil
+	}
+	var progressToken mcp.ProgressToken
+	if request.Params.Meta != nil {
+		progressToken = request.Params.Meta.ProgressToken
+	}
+
+	sessionID := session.SessionID()
+	return func(e twap.Execution) {
+		message := fmt.Sprintf("TWAP %s: %d of %d slices placed, %s of %s filled", e.ID, len(e.Orders), e.Slices, e.Filled, e.Volume)
+		if e.Done() {
+			message = fmt.Sprintf("TWAP %s %s: %s of %s filled", e.ID, e.State, e.Filled, e.Volume)
+		}
+		err := srv.SendNotificationToSpecificClient(sessionID, "notifications/message", map[string]any{
+			"level":  "info",
+			"logger": twapNotificationLogger,
+			"data":   map[string]any{"message": message, "execution": e},
+		})
+		if err != nil {
+			slog.Debug("Failed to send TWAP notification", "execution", e.ID, "error", err)
+		}
+		if progressToken != nil && !e.Done() {
+			_ = srv.SendNotificationToSpecificClient(sessionID, "notifications/progress", map[string]any{
+				"progressToken": progressToken,
+				"progress":      len(e.Orders),
+				"total":         e.Slices,
+				"message":       message,
+			})
+		}
+	}
+}
+
+func twapResult(v any) (*mcp.CallToolResult, error) {
+	resultJSON, err := marshalJSON(v)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal TWAP execution: %v", err)), nil
+	}
+	return mcp.NewToolResultText(resultJSON), nil
+}
@@ -0,0 +1,211 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/markets"
+	"github.com/luno/luno-mcp/internal/twap"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleExecuteTWAP(t *testing.T) {
+	ctx := context.Background()
+	listed := &luno.MarketsResponse{Markets: []luno.MarketInfo{{
+		MarketId:        "XBTZAR",
+		BaseCurrency:    "XBT",
+		CounterCurrency: "ZAR",
+		TradingStatus:   luno.TradingStatusActive,
+		VolumeScale:     4,
+		PriceScale:      0,
+		MinVolume:       NewFromString(t, "0.0005"),
+		MaxVolume:       NewFromString(t, "100"),
+	}}}
+	balances := &luno.GetBalancesResponse{Balance: []luno.AccountBalance{
+		{Asset: "ZAR", Balance: NewFromString(t, "25000.00"), Reserved: NewFromString(t, "5000.00")},
+		{Asset: "XBT", Balance: NewFromString(t, "0.0100"), Reserved: NewFromString(t, "0")},
+	}}
+	start := map[string]any{"pair": "XBTZAR", "side": "BUY", "volume": "0.0200", "limit_price": "1000000", "slices": 4, "duration": "1h"}
+	with := func(overrides map[string]any) map[string]any {
+		args := make(map[string]any, len(start))
+		for k, v := range start {
+			args[k] = v
+		}
+		for k, v := range overrides {
+			args[k] = v
+		}
+		return args
+	}
+
+	tests := []struct {
+		name            string
+		args            map[string]any
+		isAuthenticated bool
+		mockSetup       func(*sdk.MockLunoClient)
+		errorContains   string
+	}{
+		{
+			name:            "invalid action",
+			args:            map[string]any{"action": "pause"},
+			isAuthenticated: true,
+			errorContains:   `action must be one of start, status, cancel, got "pause"`,
+		},
+		{
+			name:            "status of unknown execution",
+			args:            map[string]any{"action": "status", "id": "twap-99"},
+			isAuthenticated: true,
+			errorContains:   "No TWAP execution",
+		},
+		{
+			name:            "cancel unknown execution",
+			args:            map[string]any{"action": "cancel", "id": "twap-99"},
+			isAuthenticated: true,
+			errorContains:   "No TWAP execution",
+		},
+		{
+			name:          "start needs credentials",
+			args:          start,
+			errorContains: ErrAPICredentialsRequired,
+		},
+		{
+			name:            "invalid side",
+			args:            with(map[string]any{"side": "HOLD"}),
+			isAuthenticated: true,
+			errorContains:   `side must be one of BUY, SELL, got "HOLD"`,
+		},
+		{
+			name:            "invalid volume",
+			args:            with(map[string]any{"volume": "lots"}),
+			isAuthenticated: true,
+			errorContains:   `volume must be a decimal number such as 0.25, got "lots"`,
+		},
+		{
+			name:            "invalid duration",
+			args:            with(map[string]any{"duration": "an hour"}),
+			isAuthenticated: true,
+			errorContains:   `invalid duration "an hour"`,
+		},
+		{
+			name:            "unknown market",
+			args:            with(map[string]any{"pair": "DOGEZAR"}),
+			isAuthenticated: true,
+			errorContains:   "Unable to execute order for DOGEZAR",
+		},
+		{
+			name:            "slices too close together",
+			args:            with(map[string]any{"slices": 100, "duration": "10m", "volume": "1"}),
+			isAuthenticated: true,
+			errorContains:   "at least 10s apart",
+		},
+		{
+			name:            "slices below the minimum volume",
+			args:            with(map[string]any{"volume": "0.0010"}),
+			isAuthenticated: true,
+			errorContains:   "below the minimum of",
+		},
+		{
+			name:            "buy needs the counter balance",
+			args:            with(map[string]any{"volume": "0.0300"}),
+			isAuthenticated: true,
+			mockSetup: func(c *sdk.MockLunoClient) {
+				c.EXPECT().GetBalances(ctx, &luno.GetBalancesRequest{}).Return(balances, nil)
+			},
+			errorContains: "it needs up to 30000.0000 ZAR but only 20000.00 ZAR is available",
+		},
+		{
+			name:            "sell needs the base balance",
+			args:            with(map[string]any{"side": "SELL"}),
+			isAuthenticated: true,
+			mockSetup: func(c *sdk.MockLunoClient) {
+				c.EXPECT().GetBalances(ctx, &luno.GetBalancesRequest{}).Return(balances, nil)
+			},
+			errorContains: "it needs up to 0.0200 XBT but only 0.0100 XBT is available",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := sdk.NewMockLunoClient(t)
+			cfg := &config.Config{LunoClient: mockClient, IsAuthenticated: tt.isAuthenticated, Markets: markets.NewCache(mockClient), TWAP: twap.NewManager()}
+			mockClient.EXPECT().Markets(ctx, &luno.MarketsRequest{}).Return(listed, nil).Maybe()
+			if tt.mockSetup != nil {
+				tt.mockSetup(mockClient)
+			}
+
+			result, err := HandleExecuteTWAP(cfg)(ctx, createMockRequest(tt.args))
+			require.NoError(t, err)
+			assert.True(t, result.IsError)
+			assert.Contains(t, getTextContentFromResult(t, result), tt.errorContains)
+			assert.Empty(t, cfg.TWAP.List(""))
+		})
+	}
+}
+
+func TestHandleExecuteTWAPStart(t *testing.T) {
+	ctx := context.Background()
+	mockClient := sdk.NewMockLunoClient(t)
+	mockClient.EXPECT().Markets(ctx, &luno.MarketsRequest{}).Return(&luno.MarketsResponse{Markets: []luno.MarketInfo{{
+		MarketId:        "XBTZAR",
+		BaseCurrency:    "XBT",
+		CounterCurrency: "ZAR",
+		TradingStatus:   luno.TradingStatusActive,
+		VolumeScale:     4,
+		MinVolume:       NewFromString(t, "0.0005"),
+	}}}, nil).Maybe()
+	mockClient.EXPECT().GetBalances(ctx, &luno.GetBalancesRequest{}).Return(&luno.GetBalancesResponse{Balance: []luno.AccountBalance{
+		{Asset: "ZAR", Balance: NewFromString(t, "50000.00"), Reserved: NewFromString(t, "0")},
+	}}, nil)
+	// The first slice is placed straight away and cancelled when the manager closes
+	mockClient.EXPECT().GetTicker(mock.Anything, &luno.GetTickerRequest{Pair: "XBTZAR"}).
+		Return(&luno.GetTickerResponse{Bid: NewFromString(t, "999000"), Ask: NewFromString(t, "999500")}, nil).Once()
+	mockClient.EXPECT().PostLimitOrder(mock.Anything, &luno.PostLimitOrderRequest{
+		Pair: "XBTZAR", Type: luno.OrderTypeBid, Volume: NewFromString(t, "0.0050"), Price: NewFromString(t, "999500"),
+	}).Return(&luno.PostLimitOrderResponse{OrderId: "BXMC2CJ7HNB88U4"}, nil).Once()
+	mockClient.EXPECT().GetOrder(mock.Anything, &luno.GetOrderRequest{Id: "BXMC2CJ7HNB88U4"}).
+		Return(&luno.GetOrderResponse{State: luno.OrderStateComplete, Base: NewFromString(t, "0.0050"), Counter: NewFromString(t, "4997.50")}, nil).Maybe()
+
+	cfg := &config.Config{LunoClient: mockClient, IsAuthenticated: true, Markets: markets.NewCache(mockClient), TWAP: twap.NewManager()}
+	handler := HandleExecuteTWAP(cfg)
+
+	result, err := handler(ctx, createMockRequest(map[string]any{
+		"pair": "xbt-zar", "side": "BUY", "volume": "0.0200", "limit_price": "1000000", "slices": 4, "duration": "1h",
+	}))
+	require.NoError(t, err)
+	text := getTextContentFromResult(t, result)
+	require.False(t, result.IsError, text)
+
+	var started twap.Execution
+	require.NoError(t, json.Unmarshal([]byte(text), &started))
+	assert.Equal(t, "XBTZAR", started.Pair)
+	assert.Equal(t, luno.OrderTypeBid, started.Side)
+	assert.Equal(t, "15m0s", started.Interval)
+	assert.Equal(t, twap.StateRunning, started.State)
+
+	require.Eventually(t, func() bool {
+		e, _ := cfg.TWAP.Get("", started.ID)
+		return len(e.Orders) == 1
+	}, time.Second, time.Millisecond)
+
+	result, err = handler(ctx, createMockRequest(map[string]any{"action": "cancel", "id": started.ID}))
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+	cfg.TWAP.Close()
+
+	result, err = handler(ctx, createMockRequest(map[string]any{"action": "status", "id": started.ID}))
+	require.NoError(t, err)
+	var got twap.Execution
+	require.NoError(t, json.Unmarshal([]byte(getTextContentFromResult(t, result)), &got))
+	assert.Equal(t, twap.StateCancelled, got.State)
+	assert.Equal(t, "0.0050", got.Filled.String())
+
+	result, err = handler(ctx, createMockRequest(map[string]any{"action": "status"}))
+	require.NoError(t, err)
+	assert.Contains(t, getTextContentFromResult(t, result), started.ID)
+}
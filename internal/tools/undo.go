@@ -0,0 +1,178 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/orders"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// undoWindow is how long after an action it can be undone
+const undoWindow = 5 * time.Minute
+
+// Statuses of the orders in UndoResult
+const (
+	UndoStatusCancelled        = "cancelled"
+	UndoStatusAlreadyCancelled = "already_cancelled"
+	UndoStatusFilled           = "filled"
+	UndoStatusPartiallyFilled  = "partially_filled"
+	UndoStatusFailed           = "failed"
+)
+
+// undoableTools are the tools whose orders can be undone by cancelling them
+// while nothing has traded. Market orders fill as they are placed, and
+// replace_order has already cancelled the order it replaced.
+var undoableTools = []string{CreateOrderToolID, CreateOrdersBatchToolID}
+
+// UndoResult is the result of the undo_last_action tool
+type UndoResult struct {
+	// Tool is the tool call undone, and RequestID its request
+	Tool      string      `json:"tool"`
+	RequestID string      `json:"request_id,omitempty"`
+	PlacedAt  time.Time   `json:"placed_at"`
+	Orders    []UndoOrder `json:"orders"`
+	Message   string      `json:"message"`
+}
+
+// UndoOrder is an order of the undone action and what happened to it
+type UndoOrder struct {
+	OrderID string `json:"order_id"`
+	Pair    string `json:"pair"`
+	Status  string `json:"status"`
+	// Filled is the volume traded before the undo
+	Filled string `json:"filled,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// NewUndoLastActionTool creates a tool for undoing the session's last order placement
+func NewUndoLastActionTool() mcp.Tool {
+	return mcp.NewTool(
+		UndoLastActionToolID,
+		mcp.WithDescription(fmt.Sprintf("Undo the last create_order or create_orders_batch call of this session, within %d minutes of it, "+
+			"by cancelling its orders that have not traded. Orders that have partly or fully filled are left as they are and reported. "+
+			"Market orders, replaced orders, TWAP and iceberg orders can't be undone.", int(undoWindow.Minutes()))+writeOperationNotice),
+	)
+}
+
+// HandleUndoLastAction handles the undo_last_action tool
+func HandleUndoLastAction(cfg *config.Config) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		cfg := cfg.ForContext(ctx)
+		if !cfg.IsAuthenticated {
+			return mcp.NewToolResultError(ErrAPICredentialsRequired), nil
+		}
+		if cfg.Orders == nil {
+			return mcp.NewToolResultError("Order tracking is not available on this server, so there is no last action to undo"), nil
+		}
+
+		md, _ := sdk.RequestMetadataFromContext(ctx)
+		tracked, err := cfg.Orders.List(cfg.Tenant)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to read tracked orders: %v", err)), nil
+		}
+		action := lastAction(tracked, md.SessionID)
+		if len(action) == 0 {
+			return mcp.NewToolResultError("There is nothing to undo: no orders have been placed in this session"), nil
+		}
+		last := action[0]
+		if !slices.Contains(undoableTools, last.Tool) {
+			return mcp.NewToolResultError(fmt.Sprintf("The last action, %s placing order %s, can't be undone: %s", last.Tool, last.OrderID, notUndoable(last.Tool))), nil
+		}
+		if age := time.Since(last.PlacedAt); age > undoWindow {
+			return mcp.NewToolResultError(fmt.Sprintf("The last action, %s at %s, was %s ago; only actions from the last %s can be undone. "+
+				"Use cancel_order to cancel its orders if that is still wanted.",
+				last.Tool, last.PlacedAt.UTC().Format(time.RFC3339), age.Truncate(time.Second), undoWindow)), nil
+		}
+
+		result := UndoResult{Tool: last.Tool, RequestID: last.RequestID, PlacedAt: last.PlacedAt.UTC(), Orders: []UndoOrder{}}
+		cancelled := 0
+		for _, o := range action {
+			undone := undoOrder(ctx, cfg, o)
+			if undone.Status == UndoStatusCancelled {
+				cancelled++
+			}
+			result.Orders = append(result.Orders, undone)
+		}
+		result.Message = fmt.Sprintf("Cancelled %d of the %d orders placed by %s.", cancelled, len(action), last.Tool)
+		if cancelled < len(action) {
+			result.Message += " Orders that had traded, were already cancelled or failed to cancel are listed with their status."
+		}
+
+		resultJSON, err := marshalJSON(result)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal undo result: %v", err)), nil
+		}
+		return mcp.NewToolResultText(resultJSON), nil
+	}
+}
+
+// lastAction returns the orders placed by the session's most recent tool call
+// that placed any, newest first
+func lastAction(tracked []orders.Order, sessionID string) []orders.Order {
+	var newest *orders.Order
+	for i, o := range tracked {
+		if o.SessionID == sessionID && (newest == nil || o.PlacedAt.After(newest.PlacedAt)) {
+			newest = &tracked[i]
+		}
+	}
+	if newest == nil {
+		return nil
+	}
+	action := []orders.Order{*newest}
+	if newest.RequestID == "" {
+		return action
+	}
+	for _, o := range tracked {
+		if o.OrderID != newest.OrderID && o.SessionID == sessionID && o.RequestID == newest.RequestID {
+			action = append(action, o)
+		}
+	}
+	return action
+}
+
+// notUndoable explains why orders placed by tool can't be undone
+func notUndoable(tool string) string {
+	switch tool {
+	case CreateMarketOrderToolID:
+		return "market orders trade as they are placed"
+	case ReplaceOrderToolID:
+		return "the order it replaced was cancelled and can't be restored; cancel the replacement with cancel_order if needed"
+	default:
+		return fmt.Sprintf("%s places orders over time; stop it with its own tools", tool)
+	}
+}
+
+// undoOrder cancels o if it has not traded
+func undoOrder(ctx context.Context, cfg *config.Config, o orders.Order) UndoOrder {
+	undone := UndoOrder{OrderID: o.OrderID, Pair: o.Pair}
+	current, err := cfg.LunoClient.GetOrder(ctx, &luno.GetOrderRequest{Id: o.OrderID})
+	if err != nil {
+		undone.Status, undone.Error = UndoStatusFailed, fmt.Sprintf("getting order: %v", err)
+		return undone
+	}
+	if current.Base.Sign() > 0 {
+		undone.Filled = current.Base.String()
+		undone.Status = UndoStatusPartiallyFilled
+		if current.State == luno.OrderStateComplete {
+			undone.Status = UndoStatusFilled
+		}
+		return undone
+	}
+	if current.State == luno.OrderStateComplete {
+		undone.Status = UndoStatusAlreadyCancelled
+		return undone
+	}
+	if _, err := cfg.LunoClient.StopOrder(ctx, &luno.StopOrderRequest{OrderId: o.OrderID}); err != nil {
+		undone.Status, undone.Error = UndoStatusFailed, fmt.Sprintf("cancelling order: %v", err)
+		return undone
+	}
+	undone.Status = UndoStatusCancelled
+	return undone
+}
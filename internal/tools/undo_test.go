@@ -0,0 +1,116 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/orders"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleUndoLastAction(t *testing.T) {
+	ctx := sdk.ContextWithRequestMetadata(context.Background(), sdk.RequestMetadata{SessionID: "session-1", RequestID: "req-3"})
+	now := time.Now()
+	placed := func(id, tool, session, request string, at time.Time) orders.Order {
+		return orders.Order{OrderID: id, Pair: "XBTZAR", Tool: tool, SessionID: session, RequestID: request, PlacedAt: at}
+	}
+	pending := &luno.GetOrderResponse{State: luno.OrderStatePending, Base: decimal.Zero()}
+
+	tests := []struct {
+		name          string
+		tracked       []orders.Order
+		mockSetup     func(*sdk.MockLunoClient)
+		expStatuses   map[string]string
+		expMessage    string
+		errorContains string
+	}{
+		{
+			name: "cancels the orders of the last batch",
+			tracked: []orders.Order{
+				placed("BX1", CreateOrderToolID, "session-1", "req-1", now.Add(-2*time.Minute)),
+				placed("BX2", CreateOrdersBatchToolID, "session-1", "req-2", now.Add(-time.Minute)),
+				placed("BX3", CreateOrdersBatchToolID, "session-1", "req-2", now.Add(-time.Minute)),
+				placed("BX4", CreateOrdersBatchToolID, "session-1", "req-2", now.Add(-time.Minute)),
+				placed("BX5", CreateOrderToolID, "session-2", "req-9", now),
+			},
+			mockSetup: func(c *sdk.MockLunoClient) {
+				c.EXPECT().GetOrder(ctx, &luno.GetOrderRequest{Id: "BX2"}).Return(pending, nil)
+				c.EXPECT().StopOrder(ctx, &luno.StopOrderRequest{OrderId: "BX2"}).Return(&luno.StopOrderResponse{Success: true}, nil)
+				c.EXPECT().GetOrder(ctx, &luno.GetOrderRequest{Id: "BX3"}).
+					Return(&luno.GetOrderResponse{State: luno.OrderStatePending, Base: decimal.NewFromFloat64(0.001, 3)}, nil)
+				c.EXPECT().GetOrder(ctx, &luno.GetOrderRequest{Id: "BX4"}).Return(pending, nil)
+				c.EXPECT().StopOrder(ctx, &luno.StopOrderRequest{OrderId: "BX4"}).Return(nil, errors.New(apiErrorStr))
+			},
+			expStatuses: map[string]string{"BX2": UndoStatusCancelled, "BX3": UndoStatusPartiallyFilled, "BX4": UndoStatusFailed},
+			expMessage:  "Cancelled 1 of the 3 orders placed by create_orders_batch. Orders that had traded, were already cancelled or failed to cancel are listed with their status.",
+		},
+		{
+			name:    "already cancelled",
+			tracked: []orders.Order{placed("BX1", CreateOrderToolID, "session-1", "req-1", now)},
+			mockSetup: func(c *sdk.MockLunoClient) {
+				c.EXPECT().GetOrder(ctx, &luno.GetOrderRequest{Id: "BX1"}).Return(&luno.GetOrderResponse{State: luno.OrderStateComplete, Base: decimal.Zero()}, nil)
+			},
+			expStatuses: map[string]string{"BX1": UndoStatusAlreadyCancelled},
+			expMessage:  "Cancelled 0 of the 1 orders placed by create_order. Orders that had traded, were already cancelled or failed to cancel are listed with their status.",
+		},
+		{
+			name:          "nothing placed in the session",
+			tracked:       []orders.Order{placed("BX5", CreateOrderToolID, "session-2", "req-9", now)},
+			errorContains: "There is nothing to undo",
+		},
+		{
+			name:          "market orders can't be undone",
+			tracked:       []orders.Order{placed("BX1", CreateMarketOrderToolID, "session-1", "req-1", now)},
+			errorContains: "The last action, create_market_order placing order BX1, can't be undone: market orders trade as they are placed",
+		},
+		{
+			name:          "too old",
+			tracked:       []orders.Order{placed("BX1", CreateOrderToolID, "session-1", "req-1", now.Add(-time.Hour))},
+			errorContains: "only actions from the last 5m0s can be undone",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := sdk.NewMockLunoClient(t)
+			if tt.mockSetup != nil {
+				tt.mockSetup(mockClient)
+			}
+			// Written to a file, since Add stamps orders with the current time
+			path := filepath.Join(t.TempDir(), "orders.json")
+			data, err := json.Marshal(tt.tracked)
+			require.NoError(t, err)
+			require.NoError(t, os.WriteFile(path, data, 0o600))
+			cfg := &config.Config{LunoClient: mockClient, IsAuthenticated: true, Orders: orders.New(path)}
+
+			result, err := HandleUndoLastAction(cfg)(ctx, createMockRequest(map[string]any{}))
+			require.NoError(t, err)
+			text := getTextContentFromResult(t, result)
+			if tt.errorContains != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, text, tt.errorContains)
+				return
+			}
+			require.False(t, result.IsError, text)
+
+			var got UndoResult
+			require.NoError(t, json.Unmarshal([]byte(text), &got))
+			statuses := map[string]string{}
+			for _, o := range got.Orders {
+				statuses[o.OrderID] = o.Status
+			}
+			assert.Equal(t, tt.expStatuses, statuses)
+			assert.Equal(t, tt.expMessage, got.Message)
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/usage"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// usageStatsNote explains how the figures in UsageStats are measured
+const usageStatsNote = "Sizes are of the result text before truncation; tokens are estimated at 4 bytes each and vary by model. " +
+	"Results over max_response_bytes are truncated and continued with fetch_more."
+
+// UsageStats is the get_usage_stats result
+type UsageStats struct {
+	Since time.Time `json:"since"`
+	// MaxResponseBytes is the size above which results are truncated, zero if they never are
+	MaxResponseBytes int               `json:"max_response_bytes"`
+	Calls            int64             `json:"calls"`
+	ResponseBytes    int64             `json:"response_bytes"`
+	EstimatedTokens  int64             `json:"estimated_tokens"`
+	Tools            []usage.ToolStats `json:"tools"`
+	Notes            []string          `json:"notes"`
+}
+
+// NewGetUsageStatsTool creates a tool for reporting the size of tool results
+func NewGetUsageStatsTool() mcp.Tool {
+	return mcp.NewTool(
+		GetUsageStatsToolID,
+		mcp.WithDescription("Get the number of calls to each tool since the server started, with the size of their arguments and results "+
+			"in bytes and estimated tokens, largest first. Use this to find the tools that take up the most of the context window."),
+		mcp.WithNumber(
+			"limit",
+			mcp.Description("Most tools to return (default: all)"),
+			mcp.Min(1),
+		),
+		mcp.WithBoolean(
+			"reset",
+			mcp.Description("Start counting again after returning the stats (default: false)"),
+		),
+	)
+}
+
+// usageStatsArgs are the arguments of the get_usage_stats tool
+type usageStatsArgs struct {
+	Limit int  `json:"limit"`
+	Reset bool `json:"reset"`
+}
+
+// HandleGetUsageStats handles the get_usage_stats tool
+func HandleGetUsageStats(cfg *config.Config) server.ToolHandlerFunc {
+	tool := NewGetUsageStatsTool()
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		cfg := cfg.ForContext(ctx)
+		if cfg.Usage == nil {
+			return mcp.NewToolResultError("Usage stats are not available on this server"), nil
+		}
+		args := usageStatsArgs{Limit: math.MaxInt}
+		if errResult := bindArguments(request, tool, &args); errResult != nil {
+			return errResult, nil
+		}
+		limit := args.Limit
+
+		toolStats, since := cfg.Usage.Stats()
+		if args.Reset {
+			cfg.Usage.Reset()
+		}
+		stats := UsageStats{
+			Since:            since.UTC(),
+			MaxResponseBytes: cfg.MaxResponseBytes,
+			Tools:            toolStats,
+			Notes:            []string{usageStatsNote},
+		}
+		for _, s := range toolStats {
+			stats.Calls += s.Calls
+			stats.ResponseBytes += s.ResponseBytes
+		}
+		stats.EstimatedTokens = usage.EstimateTokens(stats.ResponseBytes)
+		if len(stats.Tools) > limit {
+			stats.Tools = stats.Tools[:limit]
+		}
+
+		resultJSON, err := marshalJSON(stats)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal usage stats: %v", err)), nil
+		}
+		return mcp.NewToolResultText(resultJSON), nil
+	}
+}
@@ -0,0 +1,70 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/usage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleGetUsageStats(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		args          map[string]any
+		noTracker     bool
+		expTools      []string
+		expCalls      int64
+		expReset      bool
+		errorContains string
+	}{
+		{name: "all tools", args: map[string]any{}, expTools: []string{"list_trades", "get_ticker"}, expCalls: 3},
+		{name: "limited", args: map[string]any{"limit": 1}, expTools: []string{"list_trades"}, expCalls: 3},
+		{name: "reset", args: map[string]any{"reset": true}, expTools: []string{"list_trades", "get_ticker"}, expCalls: 3, expReset: true},
+		{name: "not available", args: map[string]any{}, noTracker: true, errorContains: "Usage stats are not available on this server"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{MaxResponseBytes: 20_000}
+			if !tt.noTracker {
+				cfg.Usage = usage.NewTracker()
+				cfg.Usage.Record(usage.Call{Tool: "get_ticker", ResponseBytes: 300})
+				cfg.Usage.Record(usage.Call{Tool: "list_trades", ResponseBytes: 30_000, Truncated: true})
+				cfg.Usage.Record(usage.Call{Tool: "list_trades", ResponseBytes: 1_000})
+			}
+
+			result, err := HandleGetUsageStats(cfg)(ctx, createMockRequest(tt.args))
+			require.NoError(t, err)
+			text := getTextContentFromResult(t, result)
+			if tt.errorContains != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, text, tt.errorContains)
+				return
+			}
+			require.False(t, result.IsError, text)
+
+			var got UsageStats
+			require.NoError(t, json.Unmarshal([]byte(text), &got))
+			assert.Equal(t, 20_000, got.MaxResponseBytes)
+			assert.Equal(t, tt.expCalls, got.Calls)
+			assert.Equal(t, int64(31_300), got.ResponseBytes)
+			assert.Equal(t, int64(7_825), got.EstimatedTokens)
+			var names []string
+			for _, s := range got.Tools {
+				names = append(names, s.Tool)
+			}
+			assert.Equal(t, tt.expTools, names)
+			assert.Equal(t, int64(1), got.Tools[0].Truncated)
+			assert.NotEmpty(t, got.Notes)
+
+			after, _ := cfg.Usage.Stats()
+			assert.Equal(t, tt.expReset, len(after) == 0)
+		})
+	}
+}
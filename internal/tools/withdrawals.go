@@ -0,0 +1,407 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/events"
+	"github.com/luno/luno-mcp/internal/validate"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Processing time and fee guidance shown in withdrawal previews. Luno only reports
+// the exact fee once a withdrawal has been created.
+const (
+	standardProcessingTime = "Usually 1-3 business days, depending on the receiving bank"
+	fastProcessingTime     = "Usually within minutes"
+	standardFeeInfo        = "Luno's standard withdrawal fee for this currency applies, the same as withdrawing in the Luno app. The exact fee is reported once the withdrawal is created."
+	fastFeeInfo            = "Fast withdrawals carry an additional fee, the same as a fast withdrawal in the Luno app. The exact fee is reported once the withdrawal is created."
+)
+
+// withdrawalNotificationLogger is the logger name of monitored withdrawal notifications
+const withdrawalNotificationLogger = "luno-mcp/withdrawals"
+
+// BeneficiaryView is a bank account withdrawals can be paid to, with the account number masked
+type BeneficiaryView struct {
+	ID                      string `json:"id"`
+	Recipient               string `json:"recipient"`
+	BankName                string `json:"bank_name"`
+	AccountNumber           string `json:"account_number"`
+	SupportsFastWithdrawals bool   `json:"supports_fast_withdrawals"`
+}
+
+// WithdrawalPreview describes a withdrawal before it is submitted
+type WithdrawalPreview struct {
+	Type           string          `json:"type"`
+	Currency       string          `json:"currency"`
+	Amount         decimal.Decimal `json:"amount"`
+	Beneficiary    BeneficiaryView `json:"beneficiary"`
+	Fast           bool            `json:"fast"`
+	FeeInfo        string          `json:"fee_info"`
+	ProcessingTime string          `json:"processing_time"`
+	ExternalID     string          `json:"external_id,omitempty"`
+}
+
+// CreateFiatWithdrawalResult is returned by create_fiat_withdrawal. Withdrawal is only
+// set when the withdrawal was confirmed and submitted.
+type CreateFiatWithdrawalResult struct {
+	Confirmed  bool                           `json:"confirmed"`
+	Preview    WithdrawalPreview              `json:"preview"`
+	Withdrawal *luno.CreateWithdrawalResponse `json:"withdrawal,omitempty"`
+	// Monitoring is set when the withdrawal is being watched for completion
+	Monitoring bool   `json:"monitoring,omitempty"`
+	Message    string `json:"message"`
+}
+
+// HandleWithdrawalsDisabled always responds with an MCP tool error containing ErrWithdrawalsDisabled
+func HandleWithdrawalsDisabled() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultError(ErrWithdrawalsDisabled), nil
+	}
+}
+
+// NewCreateFiatWithdrawalTool creates a tool for withdrawing fiat to a saved beneficiary
+func NewCreateFiatWithdrawalTool() mcp.Tool {
+	return mcp.NewTool(
+		CreateFiatWithdrawalToolID,
+		mcp.WithDescription("Withdraw fiat to one of the account's saved bank beneficiaries. "+
+			"Without confirm=true this only returns a preview with the beneficiary, fee and processing time; "+
+			"show it to the user and call again with confirm=true to submit. "+
+			"Withdrawals must be enabled with --allow-write-operations and --allow-withdrawals (or ALLOW_WRITE_OPERATIONS and ALLOW_WITHDRAWALS)."),
+		mcp.WithString(
+			"type",
+			mcp.Required(),
+			mcp.Description("Withdrawal method, which determines the currency (e.g., ZAR_EFT)"),
+		),
+		mcp.WithString(
+			"amount",
+			mcp.Required(),
+			mcp.Description("Amount to withdraw as a decimal string, with at most 2 decimal places"),
+			validate.MaxDecimals(2),
+			validate.ExclusiveMin(0),
+		),
+		mcp.WithString(
+			"beneficiary_id",
+			mcp.Required(),
+			mcp.Description("ID of the bank beneficiary to pay out to. An invalid ID returns the list of saved beneficiaries."),
+			mcp.Pattern(`^\d+$`),
+		),
+		mcp.WithBoolean(
+			"fast",
+			mcp.Description("Request a fast withdrawal, which carries an extra fee (ZAR_EFT only; default: false)"),
+		),
+		mcp.WithString(
+			"external_id",
+			mcp.Description("Optional unique ID for this withdrawal, used by Luno to reject duplicates"),
+		),
+		mcp.WithBoolean(
+			"confirm",
+			mcp.Description("Set to true to submit the withdrawal after the user has reviewed the preview (default: false)"),
+		),
+		mcp.WithBoolean(
+			"monitor",
+			mcp.Description("Once submitted, watch the withdrawal in the background and send a notification when it completes or fails (default: false)"),
+		),
+	)
+}
+
+// createWithdrawalArgs are the arguments of the create_fiat_withdrawal tool
+type createWithdrawalArgs struct {
+	Type          string          `json:"type"`
+	Amount        decimal.Decimal `json:"amount"`
+	BeneficiaryID string          `json:"beneficiary_id"`
+	Fast          bool            `json:"fast"`
+	ExternalID    string          `json:"external_id"`
+	Confirm       bool            `json:"confirm"`
+	Monitor       bool            `json:"monitor"`
+}
+
+// HandleCreateFiatWithdrawal handles the create_fiat_withdrawal tool
+func HandleCreateFiatWithdrawal(cfg *config.Config) server.ToolHandlerFunc {
+	tool := NewCreateFiatWithdrawalTool()
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		cfg := cfg.ForContext(ctx)
+		if !cfg.IsAuthenticated {
+			return mcp.NewToolResultError(ErrAPICredentialsRequired), nil
+		}
+
+		var args createWithdrawalArgs
+		if errResult := bindArguments(request, tool, &args); errResult != nil {
+			return errResult, nil
+		}
+		withdrawalType := strings.ToUpper(strings.TrimSpace(args.Type))
+		amount := args.Amount
+		beneficiaryID, err := strconv.ParseInt(args.BeneficiaryID, 10, 64)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Invalid beneficiary ID format: %v. Please provide a valid numeric beneficiary ID.", err)), nil
+		}
+		fast, externalID := args.Fast, args.ExternalID
+
+		beneficiaries, err := cfg.LunoClient.ListBeneficiaries(ctx, &luno.ListBeneficiariesRequest{})
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to list beneficiaries: %v", err)), nil
+		}
+		var views []BeneficiaryView
+		var beneficiary *BeneficiaryView
+		for _, b := range beneficiaries.Beneficiaries {
+			view := BeneficiaryView{
+				ID:                      b.Id,
+				Recipient:               b.BankRecipient,
+				BankName:                b.BankName,
+				AccountNumber:           maskAccountNumber(b.BankAccountNumber),
+				SupportsFastWithdrawals: b.SupportsFastWithdrawals,
+			}
+			views = append(views, view)
+			if b.Id == strconv.FormatInt(beneficiaryID, 10) {
+				beneficiary = &view
+			}
+		}
+		if beneficiary == nil {
+			viewsJSON, err := json.MarshalIndent(views, "", "  ")
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal beneficiaries: %v", err)), nil
+			}
+			return mcp.NewToolResultError(fmt.Sprintf("Beneficiary %d not found. Saved beneficiaries:\n%s", beneficiaryID, viewsJSON)), nil
+		}
+		if fast && !beneficiary.SupportsFastWithdrawals {
+			return mcp.NewToolResultError(fmt.Sprintf("Beneficiary %d does not support fast withdrawals", beneficiaryID)), nil
+		}
+
+		currency, _, _ := strings.Cut(withdrawalType, "_")
+		preview := WithdrawalPreview{
+			Type:           withdrawalType,
+			Currency:       currency,
+			Amount:         amount,
+			Beneficiary:    *beneficiary,
+			Fast:           fast,
+			FeeInfo:        standardFeeInfo,
+			ProcessingTime: standardProcessingTime,
+			ExternalID:     externalID,
+		}
+		if fast {
+			preview.FeeInfo = fastFeeInfo
+			preview.ProcessingTime = fastProcessingTime
+		}
+
+		result := CreateFiatWithdrawalResult{Preview: preview}
+		if !args.Confirm {
+			result.Message = "No withdrawal was made. Review the preview with the user, then call again with confirm=true to submit it."
+			return withdrawalResult(result)
+		}
+
+		slog.InfoContext(ctx, "Creating fiat withdrawal",
+			"type", withdrawalType,
+			"amount", amount.String(),
+			"beneficiary_id", beneficiaryID,
+			"fast", fast)
+
+		withdrawal, err := cfg.LunoClient.CreateWithdrawal(ctx, &luno.CreateWithdrawalRequest{
+			Type:          withdrawalType,
+			Amount:        amount,
+			BeneficiaryId: beneficiaryID,
+			Fast:          fast,
+			ExternalId:    externalID,
+		})
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to create withdrawal: %v", err)), nil
+		}
+
+		result.Confirmed = true
+		result.Withdrawal = withdrawal
+		result.Message = fmt.Sprintf("Withdrawal %s of %s submitted with a fee of %s.",
+			withdrawal.Id,
+			config.FormatCurrency(withdrawal.Amount, withdrawal.Currency),
+			config.FormatCurrency(withdrawal.Fee, withdrawal.Currency))
+		if args.Monitor {
+			result.Message += " " + monitorWithdrawal(ctx, cfg, withdrawal.Id, &result)
+		}
+		return withdrawalResult(result)
+	}
+}
+
+// NewListFiatWithdrawalsTool creates a tool for listing fiat withdrawals
+func NewListFiatWithdrawalsTool() mcp.Tool {
+	return mcp.NewTool(
+		ListFiatWithdrawalsToolID,
+		slices.Concat(
+			[]mcp.ToolOption{
+				mcp.WithDescription("List fiat withdrawals, most recent first"),
+				mcp.WithNumber(
+					"limit",
+					mcp.Description("Maximum number of withdrawals to return"),
+					mcp.Min(0),
+				),
+				mcp.WithString(
+					"before_id",
+					mcp.Description("Only return withdrawals at or before this withdrawal ID (for pagination)"),
+				),
+			},
+			listParams("withdrawals", "`status == 'PENDING' AND amount > 5000`"),
+		)...,
+	)
+}
+
+// listWithdrawalsArgs are the arguments of the list_fiat_withdrawals tool
+type listWithdrawalsArgs struct {
+	listArgs
+	Limit    int64  `json:"limit"`
+	BeforeID string `json:"before_id"`
+}
+
+// HandleListFiatWithdrawals handles the list_fiat_withdrawals tool
+func HandleListFiatWithdrawals(cfg *config.Config) server.ToolHandlerFunc {
+	tool := NewListFiatWithdrawalsTool()
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		cfg := cfg.ForContext(ctx)
+		if !cfg.IsAuthenticated {
+			return mcp.NewToolResultError(ErrAPICredentialsRequired), nil
+		}
+
+		var args listWithdrawalsArgs
+		if errResult := bindArguments(request, tool, &args); errResult != nil {
+			return errResult, nil
+		}
+		q, errResult := parseListQuery(args.listArgs)
+		if errResult != nil {
+			return errResult, nil
+		}
+		listReq := &luno.ListWithdrawalsRequest{Limit: args.Limit}
+		if beforeIDStr := args.BeforeID; beforeIDStr != "" {
+			beforeID, err := strconv.ParseInt(beforeIDStr, 10, 64)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid before_id format: %v. Please provide a valid numeric withdrawal ID.", err)), nil
+			}
+			listReq.BeforeId = beforeID
+		}
+
+		withdrawals, err := cfg.LunoClient.ListWithdrawals(ctx, listReq)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to list withdrawals: %v", err)), nil
+		}
+
+		listed := *withdrawals
+		if listed.Withdrawals, errResult = applyFilter(q, listed.Withdrawals); errResult != nil {
+			return errResult, nil
+		}
+		if listed.Withdrawals, errResult = applySort(q, listed.Withdrawals); errResult != nil {
+			return errResult, nil
+		}
+		return listResult(q, listed, "withdrawals", nil)
+	}
+}
+
+// NewGetFiatWithdrawalTool creates a tool for getting a single fiat withdrawal
+func NewGetFiatWithdrawalTool() mcp.Tool {
+	return mcp.NewTool(
+		GetFiatWithdrawalToolID,
+		mcp.WithDescription("Get the status, amount and fee of a fiat withdrawal"),
+		mcp.WithString(
+			"withdrawal_id",
+			mcp.Required(),
+			mcp.Description("Withdrawal ID"),
+		),
+	)
+}
+
+// getWithdrawalArgs are the arguments of the get_fiat_withdrawal tool
+type getWithdrawalArgs struct {
+	WithdrawalID string `json:"withdrawal_id"`
+}
+
+// HandleGetFiatWithdrawal handles the get_fiat_withdrawal tool
+func HandleGetFiatWithdrawal(cfg *config.Config) server.ToolHandlerFunc {
+	tool := NewGetFiatWithdrawalTool()
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		cfg := cfg.ForContext(ctx)
+		if !cfg.IsAuthenticated {
+			return mcp.NewToolResultError(ErrAPICredentialsRequired), nil
+		}
+
+		var args getWithdrawalArgs
+		if errResult := bindArguments(request, tool, &args); errResult != nil {
+			return errResult, nil
+		}
+		withdrawalID, err := strconv.ParseInt(args.WithdrawalID, 10, 64)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Invalid withdrawal ID format: %v. Please provide a valid numeric withdrawal ID.", err)), nil
+		}
+
+		withdrawal, err := cfg.LunoClient.GetWithdrawal(ctx, &luno.GetWithdrawalRequest{Id: withdrawalID})
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to get withdrawal: %v", err)), nil
+		}
+
+		resultJSON, err := marshalJSON(withdrawal)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal withdrawal: %v", err)), nil
+		}
+
+		return mcp.NewToolResultText(resultJSON), nil
+	}
+}
+
+// monitorWithdrawal starts watching a submitted withdrawal for the session that
+// made it, returning a sentence for the result message
+func monitorWithdrawal(ctx context.Context, cfg *config.Config, id string, result *CreateFiatWithdrawalResult) string {
+	notify := withdrawalNotifier(ctx)
+	if cfg.Withdrawals == nil || notify == nil {
+		return "It can't be monitored from this session; use get_fiat_withdrawal to check on it."
+	}
+	if err := cfg.Withdrawals.Watch(id, cfg.LunoClient, notify); err != nil {
+		slog.WarnContext(ctx, "Failed to monitor withdrawal", "withdrawal_id", id, "error", err)
+		return fmt.Sprintf("It could not be monitored (%v); use get_fiat_withdrawal to check on it.", err)
+	}
+	result.Monitoring = true
+	return "You will be notified when it completes or fails."
+}
+
+// withdrawalNotifier sends a monitored withdrawal's progress to the session that
+// made it as a log message
+func withdrawalNotifier(ctx context.Context) events.WithdrawalNotifyFunc {
+	srv := server.ServerFromContext(ctx)
+	session := server.ClientSessionFromContext(ctx)
+	if srv == nil || session == nil {
+		return nil
+	}
+
+	sessionID := session.SessionID()
+	return func(u events.WithdrawalUpdate) {
+		level := "info"
+		if u.Done && u.Withdrawal != nil && u.Withdrawal.Status != luno.StatusComplete && u.Withdrawal.Status != luno.StatusCompleted {
+			level = "warning"
+		}
+		err := srv.SendNotificationToSpecificClient(sessionID, "notifications/message", map[string]any{
+			"level":  level,
+			"logger": withdrawalNotificationLogger,
+			"data":   map[string]any{"message": u.Message, "withdrawal": u},
+		})
+		if err != nil {
+			slog.Debug("Failed to send withdrawal notification", "withdrawal_id", u.WithdrawalID, "error", err)
+		}
+	}
+}
+
+func withdrawalResult(result CreateFiatWithdrawalResult) (*mcp.CallToolResult, error) {
+	resultJSON, err := marshalJSON(result)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Failed to marshal withdrawal: %v", err)), nil
+	}
+	return mcp.NewToolResultText(resultJSON), nil
+}
+
+// maskAccountNumber hides all but the last four digits of a bank account number
+func maskAccountNumber(s string) string {
+	if len(s) <= 4 {
+		return strings.Repeat("*", len(s))
+	}
+	return strings.Repeat("*", len(s)-4) + s[len(s)-4:]
+}
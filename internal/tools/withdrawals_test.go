@@ -0,0 +1,381 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/events"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleCreateFiatWithdrawal(t *testing.T) {
+	beneficiaries := &luno.ListBeneficiariesResponse{}
+	require.NoError(t, json.Unmarshal([]byte(`{"beneficiaries":[
+		{"id":"1234","bank_recipient":"A Person","bank_name":"FNB","bank_account_number":"62000012345","supports_fast_withdrawals":true},
+		{"id":"5678","bank_recipient":"A Person","bank_name":"Tyme Bank","bank_account_number":"51000054321","supports_fast_withdrawals":false}
+	]}`), beneficiaries))
+
+	tests := []struct {
+		name            string
+		requestParams   map[string]any
+		mockSetup       func(*sdk.MockLunoClient)
+		isAuthenticated bool
+		expectedError   bool
+		errorContains   string
+		expectedResult  func(*testing.T, CreateFiatWithdrawalResult)
+	}{
+		{
+			name:          "preview without confirm",
+			requestParams: map[string]any{"type": "zar_eft", "amount": "1000", "beneficiary_id": "1234"},
+			mockSetup: func(m *sdk.MockLunoClient) {
+				m.EXPECT().ListBeneficiaries(context.Background(), &luno.ListBeneficiariesRequest{}).Return(beneficiaries, nil)
+			},
+			isAuthenticated: true,
+			expectedResult: func(t *testing.T, r CreateFiatWithdrawalResult) {
+				assert.False(t, r.Confirmed)
+				assert.Nil(t, r.Withdrawal)
+				assert.Equal(t, "ZAR_EFT", r.Preview.Type)
+				assert.Equal(t, "ZAR", r.Preview.Currency)
+				assert.Equal(t, "*******2345", r.Preview.Beneficiary.AccountNumber)
+				assert.Equal(t, standardProcessingTime, r.Preview.ProcessingTime)
+				assert.Contains(t, r.Message, "confirm=true")
+			},
+		},
+		{
+			name:          "fast preview",
+			requestParams: map[string]any{"type": "ZAR_EFT", "amount": "1000", "beneficiary_id": "1234", "fast": true},
+			mockSetup: func(m *sdk.MockLunoClient) {
+				m.EXPECT().ListBeneficiaries(context.Background(), &luno.ListBeneficiariesRequest{}).Return(beneficiaries, nil)
+			},
+			isAuthenticated: true,
+			expectedResult: func(t *testing.T, r CreateFiatWithdrawalResult) {
+				assert.True(t, r.Preview.Fast)
+				assert.Equal(t, fastFeeInfo, r.Preview.FeeInfo)
+				assert.Equal(t, fastProcessingTime, r.Preview.ProcessingTime)
+			},
+		},
+		{
+			name:          "confirmed withdrawal",
+			requestParams: map[string]any{"type": "ZAR_EFT", "amount": "1000", "beneficiary_id": "1234", "external_id": "rent-2026-10", "confirm": true},
+			mockSetup: func(m *sdk.MockLunoClient) {
+				m.EXPECT().ListBeneficiaries(context.Background(), &luno.ListBeneficiariesRequest{}).Return(beneficiaries, nil)
+				m.EXPECT().CreateWithdrawal(context.Background(), &luno.CreateWithdrawalRequest{
+					Type:          "ZAR_EFT",
+					Amount:        NewFromString(t, "1000"),
+					BeneficiaryId: 1234,
+					ExternalId:    "rent-2026-10",
+				}).Return(&luno.CreateWithdrawalResponse{
+					Id:       "999",
+					Amount:   NewFromString(t, "1000"),
+					Fee:      NewFromString(t, "8.5"),
+					Currency: "ZAR",
+					Status:   luno.StatusPending,
+				}, nil)
+			},
+			isAuthenticated: true,
+			expectedResult: func(t *testing.T, r CreateFiatWithdrawalResult) {
+				assert.True(t, r.Confirmed)
+				require.NotNil(t, r.Withdrawal)
+				assert.Equal(t, "999", r.Withdrawal.Id)
+				assert.Contains(t, r.Message, "8.5 ZAR")
+			},
+		},
+		{
+			name:          "monitor without a session",
+			requestParams: map[string]any{"type": "ZAR_EFT", "amount": "1000", "beneficiary_id": "1234", "confirm": true, "monitor": true},
+			mockSetup: func(m *sdk.MockLunoClient) {
+				m.EXPECT().ListBeneficiaries(context.Background(), &luno.ListBeneficiariesRequest{}).Return(beneficiaries, nil)
+				m.EXPECT().CreateWithdrawal(context.Background(), &luno.CreateWithdrawalRequest{
+					Type:          "ZAR_EFT",
+					Amount:        NewFromString(t, "1000"),
+					BeneficiaryId: 1234,
+				}).Return(&luno.CreateWithdrawalResponse{Id: "999", Amount: NewFromString(t, "1000"), Currency: "ZAR"}, nil)
+			},
+			isAuthenticated: true,
+			expectedResult: func(t *testing.T, r CreateFiatWithdrawalResult) {
+				assert.True(t, r.Confirmed)
+				assert.False(t, r.Monitoring)
+				assert.Contains(t, r.Message, "can't be monitored from this session")
+			},
+		},
+		{
+			name:          "unknown beneficiary lists saved beneficiaries",
+			requestParams: map[string]any{"type": "ZAR_EFT", "amount": "1000", "beneficiary_id": "42", "confirm": true},
+			mockSetup: func(m *sdk.MockLunoClient) {
+				m.EXPECT().ListBeneficiaries(context.Background(), &luno.ListBeneficiariesRequest{}).Return(beneficiaries, nil)
+			},
+			isAuthenticated: true,
+			expectedError:   true,
+			errorContains:   "Tyme Bank",
+		},
+		{
+			name:          "fast withdrawal to unsupported bank",
+			requestParams: map[string]any{"type": "ZAR_EFT", "amount": "1000", "beneficiary_id": "5678", "fast": true},
+			mockSetup: func(m *sdk.MockLunoClient) {
+				m.EXPECT().ListBeneficiaries(context.Background(), &luno.ListBeneficiariesRequest{}).Return(beneficiaries, nil)
+			},
+			isAuthenticated: true,
+			expectedError:   true,
+			errorContains:   "does not support fast withdrawals",
+		},
+		{
+			name:          "withdrawal API error",
+			requestParams: map[string]any{"type": "ZAR_EFT", "amount": "1000", "beneficiary_id": "1234", "confirm": true},
+			mockSetup: func(m *sdk.MockLunoClient) {
+				m.EXPECT().ListBeneficiaries(context.Background(), &luno.ListBeneficiariesRequest{}).Return(beneficiaries, nil)
+				m.EXPECT().CreateWithdrawal(context.Background(), &luno.CreateWithdrawalRequest{
+					Type:          "ZAR_EFT",
+					Amount:        NewFromString(t, "1000"),
+					BeneficiaryId: 1234,
+				}).Return(nil, errors.New(apiErrorStr))
+			},
+			isAuthenticated: true,
+			expectedError:   true,
+			errorContains:   "Failed to create withdrawal",
+		},
+		{
+			name:            "invalid beneficiary id",
+			requestParams:   map[string]any{"type": "ZAR_EFT", "amount": "1000", "beneficiary_id": "abc"},
+			mockSetup:       func(m *sdk.MockLunoClient) {},
+			isAuthenticated: true,
+			expectedError:   true,
+			errorContains:   `beneficiary_id "abc" is not in the expected format`,
+		},
+		{
+			name:            "unauthenticated",
+			requestParams:   map[string]any{"type": "ZAR_EFT", "amount": "1000", "beneficiary_id": "1234"},
+			mockSetup:       func(m *sdk.MockLunoClient) {},
+			isAuthenticated: false,
+			expectedError:   true,
+			errorContains:   ErrAPICredentialsRequired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := sdk.NewMockLunoClient(t)
+			tt.mockSetup(mockClient)
+
+			cfg := &config.Config{
+				LunoClient:      mockClient,
+				IsAuthenticated: tt.isAuthenticated,
+			}
+
+			result, err := HandleCreateFiatWithdrawal(cfg)(context.Background(), createMockRequest(tt.requestParams))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedError, result.IsError)
+
+			text := getTextContentFromResult(t, result)
+			if tt.expectedError {
+				assert.Contains(t, text, tt.errorContains)
+				return
+			}
+
+			var res CreateFiatWithdrawalResult
+			require.NoError(t, json.Unmarshal([]byte(text), &res))
+			tt.expectedResult(t, res)
+		})
+	}
+}
+
+func TestHandleCreateFiatWithdrawalMonitor(t *testing.T) {
+	beneficiaries := &luno.ListBeneficiariesResponse{}
+	require.NoError(t, json.Unmarshal([]byte(`{"beneficiaries":[{"id":"1234","bank_account_number":"62000012345"}]}`), beneficiaries))
+	mockClient := sdk.NewMockLunoClient(t)
+	mockClient.EXPECT().ListBeneficiaries(mock.Anything, &luno.ListBeneficiariesRequest{}).Return(beneficiaries, nil)
+	mockClient.EXPECT().CreateWithdrawal(mock.Anything, mock.Anything).
+		Return(&luno.CreateWithdrawalResponse{Id: "999", Amount: NewFromString(t, "1000"), Currency: "ZAR"}, nil)
+
+	monitor := events.NewWithdrawalMonitor(time.Hour)
+	defer monitor.Close()
+	cfg := &config.Config{LunoClient: mockClient, IsAuthenticated: true, Withdrawals: monitor}
+
+	// The notifier needs the server and session in the context, which only
+	// HandleMessage sets up
+	srv := server.NewMCPServer("test", "1.0.0")
+	srv.AddTool(NewCreateFiatWithdrawalTool(), HandleCreateFiatWithdrawal(cfg))
+	ctx := srv.WithContext(context.Background(), server.NewInProcessSession("session-1", nil))
+	msg := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"create_fiat_withdrawal","arguments":` +
+		`{"type":"ZAR_EFT","amount":"1000","beneficiary_id":"1234","confirm":true,"monitor":true}}}`
+	resp, ok := srv.HandleMessage(ctx, json.RawMessage(msg)).(mcp.JSONRPCResponse)
+	require.True(t, ok)
+	result, ok := resp.Result.(*mcp.CallToolResult)
+	require.True(t, ok)
+	text := getTextContentFromResult(t, result)
+	require.False(t, result.IsError, text)
+
+	var res CreateFiatWithdrawalResult
+	require.NoError(t, json.Unmarshal([]byte(text), &res))
+	assert.True(t, res.Monitoring)
+	assert.Contains(t, res.Message, "You will be notified when it completes or fails.")
+	assert.True(t, monitor.Watching("999"))
+}
+
+func TestHandleListFiatWithdrawals(t *testing.T) {
+	tests := []struct {
+		name            string
+		requestParams   map[string]any
+		mockSetup       func(*sdk.MockLunoClient)
+		isAuthenticated bool
+		expectedError   bool
+		errorContains   string
+		expectedText    string
+	}{
+		{
+			name:          "list with pagination",
+			requestParams: map[string]any{"limit": float64(10), "before_id": "999"},
+			mockSetup: func(m *sdk.MockLunoClient) {
+				m.EXPECT().ListWithdrawals(context.Background(), &luno.ListWithdrawalsRequest{Limit: 10, BeforeId: 999}).
+					Return(&luno.ListWithdrawalsResponse{Withdrawals: []luno.Withdrawal{{Id: "998", Currency: "ZAR"}}}, nil)
+			},
+			isAuthenticated: true,
+		},
+		{
+			name:          "filtered, sorted and trimmed",
+			requestParams: map[string]any{"filter": "currency == 'zar'", "sort_by": "amount", "order": "desc", "fields": "id,amount"},
+			mockSetup: func(m *sdk.MockLunoClient) {
+				m.EXPECT().ListWithdrawals(context.Background(), &luno.ListWithdrawalsRequest{}).
+					Return(&luno.ListWithdrawalsResponse{Withdrawals: []luno.Withdrawal{
+						{Id: "1", Currency: "ZAR", Amount: decimal.NewFromInt64(500)},
+						{Id: "2", Currency: "MYR", Amount: decimal.NewFromInt64(900)},
+						{Id: "3", Currency: "ZAR", Amount: decimal.NewFromInt64(5000)},
+					}}, nil)
+			},
+			isAuthenticated: true,
+			expectedText:    `{"withdrawals": [{"id": "3", "amount": "5000"}, {"id": "1", "amount": "500"}]}`,
+		},
+		{
+			name:            "invalid before_id",
+			requestParams:   map[string]any{"before_id": "abc"},
+			mockSetup:       func(m *sdk.MockLunoClient) {},
+			isAuthenticated: true,
+			expectedError:   true,
+			errorContains:   "Invalid before_id format",
+		},
+		{
+			name:          "API error",
+			requestParams: map[string]any{},
+			mockSetup: func(m *sdk.MockLunoClient) {
+				m.EXPECT().ListWithdrawals(context.Background(), &luno.ListWithdrawalsRequest{}).Return(nil, errors.New(apiErrorStr))
+			},
+			isAuthenticated: true,
+			expectedError:   true,
+			errorContains:   "Failed to list withdrawals",
+		},
+		{
+			name:            "unauthenticated",
+			requestParams:   map[string]any{},
+			mockSetup:       func(m *sdk.MockLunoClient) {},
+			isAuthenticated: false,
+			expectedError:   true,
+			errorContains:   ErrAPICredentialsRequired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := sdk.NewMockLunoClient(t)
+			tt.mockSetup(mockClient)
+
+			cfg := &config.Config{
+				LunoClient:      mockClient,
+				IsAuthenticated: tt.isAuthenticated,
+			}
+
+			result, err := HandleListFiatWithdrawals(cfg)(context.Background(), createMockRequest(tt.requestParams))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedError, result.IsError)
+
+			text := getTextContentFromResult(t, result)
+			if tt.expectedError {
+				assert.Contains(t, text, tt.errorContains)
+				return
+			}
+			if tt.expectedText != "" {
+				assert.JSONEq(t, tt.expectedText, text)
+				return
+			}
+			assert.Contains(t, text, `"998"`)
+		})
+	}
+}
+
+func TestHandleGetFiatWithdrawal(t *testing.T) {
+	tests := []struct {
+		name            string
+		requestParams   map[string]any
+		mockSetup       func(*sdk.MockLunoClient)
+		isAuthenticated bool
+		expectedError   bool
+		errorContains   string
+	}{
+		{
+			name:          "get withdrawal",
+			requestParams: map[string]any{"withdrawal_id": "999"},
+			mockSetup: func(m *sdk.MockLunoClient) {
+				m.EXPECT().GetWithdrawal(context.Background(), &luno.GetWithdrawalRequest{Id: 999}).
+					Return(&luno.GetWithdrawalResponse{Id: "999", Status: luno.StatusComplete}, nil)
+			},
+			isAuthenticated: true,
+		},
+		{
+			name:            "invalid withdrawal id",
+			requestParams:   map[string]any{"withdrawal_id": "abc"},
+			mockSetup:       func(m *sdk.MockLunoClient) {},
+			isAuthenticated: true,
+			expectedError:   true,
+			errorContains:   "Invalid withdrawal ID format",
+		},
+		{
+			name:          "API error",
+			requestParams: map[string]any{"withdrawal_id": "999"},
+			mockSetup: func(m *sdk.MockLunoClient) {
+				m.EXPECT().GetWithdrawal(context.Background(), &luno.GetWithdrawalRequest{Id: 999}).Return(nil, errors.New(apiErrorStr))
+			},
+			isAuthenticated: true,
+			expectedError:   true,
+			errorContains:   "Failed to get withdrawal",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := sdk.NewMockLunoClient(t)
+			tt.mockSetup(mockClient)
+
+			cfg := &config.Config{
+				LunoClient:      mockClient,
+				IsAuthenticated: tt.isAuthenticated,
+			}
+
+			result, err := HandleGetFiatWithdrawal(cfg)(context.Background(), createMockRequest(tt.requestParams))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedError, result.IsError)
+
+			text := getTextContentFromResult(t, result)
+			if tt.expectedError {
+				assert.Contains(t, text, tt.errorContains)
+				return
+			}
+			assert.Contains(t, text, string(luno.StatusComplete))
+		})
+	}
+}
+
+func TestHandleWithdrawalsDisabled(t *testing.T) {
+	result, err := HandleWithdrawalsDisabled()(context.Background(), createMockRequest(nil))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Equal(t, ErrWithdrawalsDisabled, getTextContentFromResult(t, result))
+}
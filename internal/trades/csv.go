@@ -0,0 +1,174 @@
+package trades
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/luno/luno-go/decimal"
+)
+
+// MaxRows is the most trades read from one CSV
+const MaxRows = 10000
+
+// columns are the names accepted for each column, after lower-casing and
+// replacing spaces with underscores
+var columns = map[string][]string{
+	"timestamp":   {"timestamp", "timestamp_(utc)", "time", "date", "datetime", "created_at"},
+	"pair":        {"pair", "market", "symbol"},
+	"side":        {"side", "type", "direction"},
+	"volume":      {"volume", "base", "amount", "quantity"},
+	"price":       {"price"},
+	"counter":     {"counter", "total", "value"},
+	"fee_base":    {"fee_base"},
+	"fee_counter": {"fee_counter", "fee"},
+	"order_id":    {"order_id", "order"},
+}
+
+// requiredColumns are the columns every CSV must have
+var requiredColumns = []string{"timestamp", "pair", "side", "volume", "price"}
+
+// timeLayouts are the timestamp formats accepted, besides Unix seconds and milliseconds
+var timeLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02 15:04", "2006-01-02"}
+
+// ParseCSV reads trades from CSV with a header row. Rows that can't be read
+// are skipped and described in problems; err is only set when the CSV as a
+// whole can't be read.
+func ParseCSV(r io.Reader, source string) (trades []Trade, problems []string, err error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil, errors.New("the CSV is empty")
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading CSV header: %w", err)
+	}
+	index := make(map[string]int)
+	for i, name := range header {
+		name = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))), " ", "_")
+		for col, names := range columns {
+			if _, ok := index[col]; !ok && slices.Contains(names, name) {
+				index[col] = i
+			}
+		}
+	}
+	for _, col := range requiredColumns {
+		if _, ok := index[col]; !ok {
+			return nil, nil, fmt.Errorf("the CSV has no %s column (accepted names: %s)", col, strings.Join(columns[col], ", "))
+		}
+	}
+
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return trades, problems, nil
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("reading CSV: %w", err)
+		}
+		if len(trades) == MaxRows {
+			return nil, nil, fmt.Errorf("the CSV has more than %d trades; split it into smaller files", MaxRows)
+		}
+		field := func(col string) string {
+			i, ok := index[col]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+		if strings.Join(record, "") == "" {
+			continue
+		}
+		t, err := parseRow(field)
+		if err != nil {
+			line, _ := cr.FieldPos(0)
+			problems = append(problems, fmt.Sprintf("line %d: %v", line, err))
+			continue
+		}
+		t.Source = source
+		trades = append(trades, t)
+	}
+}
+
+func parseRow(field func(string) string) (Trade, error) {
+	var t Trade
+	var err error
+	if t.Timestamp, err = parseTime(field("timestamp")); err != nil {
+		return Trade{}, err
+	}
+	t.Pair = strings.NewReplacer("/", "", "-", "", "_", "", " ", "").Replace(strings.ToUpper(field("pair")))
+	if t.Pair == "" {
+		return Trade{}, errors.New("pair is empty")
+	}
+	switch side := strings.ToUpper(field("side")); side {
+	case "BUY", "BID":
+		t.IsBuy = true
+	case "SELL", "ASK":
+	default:
+		return Trade{}, fmt.Errorf("side %q must be buy or sell", side)
+	}
+	if t.Volume, err = parseAmount("volume", field("volume"), true); err != nil {
+		return Trade{}, err
+	}
+	if t.Price, err = parseAmount("price", field("price"), true); err != nil {
+		return Trade{}, err
+	}
+	if t.Counter, err = parseAmount("counter", field("counter"), false); err != nil {
+		return Trade{}, err
+	}
+	if t.Counter.Sign() == 0 {
+		t.Counter = t.Volume.Mul(t.Price)
+	}
+	if t.FeeBase, err = parseAmount("fee_base", field("fee_base"), false); err != nil {
+		return Trade{}, err
+	}
+	if t.FeeCounter, err = parseAmount("fee_counter", field("fee_counter"), false); err != nil {
+		return Trade{}, err
+	}
+	t.OrderID = field("order_id")
+	return t, nil
+}
+
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, errors.New("timestamp is empty")
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if len(s) >= 13 {
+			return time.UnixMilli(n).UTC(), nil
+		}
+		return time.Unix(n, 0).UTC(), nil
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("timestamp %q is not RFC 3339, YYYY-MM-DD HH:MM:SS (UTC) or Unix time", s)
+}
+
+func parseAmount(name, s string, required bool) (decimal.Decimal, error) {
+	s = strings.NewReplacer(",", "", " ", "").Replace(s)
+	if s == "" {
+		if required {
+			return decimal.Decimal{}, fmt.Errorf("%s is empty", name)
+		}
+		return decimal.Zero(), nil
+	}
+	d, err := decimal.NewFromString(s)
+	if err != nil {
+		return decimal.Decimal{}, fmt.Errorf("%s %q is not a number", name, s)
+	}
+	if d.Sign() < 0 || (required && d.Sign() == 0) {
+		return decimal.Decimal{}, fmt.Errorf("%s must be greater than zero", name)
+	}
+	return d, nil
+}
@@ -0,0 +1,63 @@
+package trades
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCSV(t *testing.T) {
+	csv := "\ufeffTimestamp (UTC),Market,Type,Amount,Price,Total,Fee,Order ID\n" +
+		"2026-10-14 09:30:00,XBT/ZAR,Buy,0.01,1000000,\"10,000\",25,BXMC2CJ7HNB88U4\n" +
+		"1792000000000,ethzar,SELL,2,50000,,,\n" +
+		"\n" +
+		"2026-10-14T10:00:00Z,XBTZAR,hold,1,1,,,\n" +
+		"yesterday,XBTZAR,buy,1,1,,,\n" +
+		"2026-10-14,XBTZAR,buy,0,1,,,\n" +
+		"2026-10-14,XBTZAR,buy,1,abc,,,\n"
+
+	trades, problems, err := ParseCSV(strings.NewReader(csv), "luno.csv")
+	require.NoError(t, err)
+	require.Len(t, trades, 2)
+
+	assert.Equal(t, "XBTZAR", trades[0].Pair)
+	assert.True(t, trades[0].IsBuy)
+	assert.Equal(t, time.Date(2026, 10, 14, 9, 30, 0, 0, time.UTC), trades[0].Timestamp)
+	assert.Equal(t, "10000", trades[0].Counter.String())
+	assert.Equal(t, "25", trades[0].FeeCounter.String())
+	assert.Equal(t, "BXMC2CJ7HNB88U4", trades[0].OrderID)
+	assert.Equal(t, "luno.csv", trades[0].Source)
+
+	assert.Equal(t, "ETHZAR", trades[1].Pair)
+	assert.False(t, trades[1].IsBuy)
+	assert.Equal(t, time.UnixMilli(1792000000000).UTC(), trades[1].Timestamp)
+	assert.Equal(t, "100000", trades[1].Counter.String(), "counter defaults to volume times price")
+
+	assert.Equal(t, []string{
+		`line 5: side "HOLD" must be buy or sell`,
+		`line 6: timestamp "yesterday" is not RFC 3339, YYYY-MM-DD HH:MM:SS (UTC) or Unix time`,
+		"line 7: volume must be greater than zero",
+		`line 8: price "abc" is not a number`,
+	}, problems)
+}
+
+func TestParseCSVErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		csv           string
+		errorContains string
+	}{
+		{name: "empty", errorContains: "the CSV is empty"},
+		{name: "missing column", csv: "timestamp,pair,side,volume\n", errorContains: "the CSV has no price column"},
+		{name: "bad quoting", csv: "timestamp,pair,side,volume,price\n\"2026", errorContains: "reading CSV"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := ParseCSV(strings.NewReader(tt.csv), "")
+			assert.ErrorContains(t, err, tt.errorContains)
+		})
+	}
+}
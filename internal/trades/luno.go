@@ -0,0 +1,42 @@
+package trades
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/sdk"
+)
+
+const (
+	// pageSize is how many trades are listed per request
+	pageSize = 1000
+	// maxPages caps the requests made to list the trades on one market
+	maxPages = 10
+)
+
+// OnLuno returns the account's trades on pair between since and until, as
+// listed by the Luno API
+func OnLuno(ctx context.Context, client sdk.LunoClient, pair string, since, until time.Time) ([]Trade, error) {
+	var out []Trade
+	req := &luno.ListUserTradesRequest{Pair: pair, Since: luno.Time(since.Add(-MatchWindow)), Limit: pageSize}
+	for range maxPages {
+		res, err := client.ListUserTrades(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("listing %s trades: %w", pair, err)
+		}
+		for _, t := range res.Trades {
+			out = append(out, FromLuno(t))
+		}
+		if len(res.Trades) < pageSize {
+			return out, nil
+		}
+		last := res.Trades[len(res.Trades)-1]
+		if time.Time(last.Timestamp).After(until.Add(MatchWindow)) {
+			return out, nil
+		}
+		req.AfterSeq = last.Sequence + 1
+	}
+	return nil, fmt.Errorf("the account has more than %d %s trades in the imported period; import a shorter period", maxPages*pageSize, pair)
+}
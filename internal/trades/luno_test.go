@@ -0,0 +1,44 @@
+package trades
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOnLuno(t *testing.T) {
+	ctx := context.Background()
+	since, until := at.Add(-time.Hour), at
+
+	full := make([]luno.TradeV2, pageSize)
+	for i := range full {
+		full[i] = luno.TradeV2{Pair: "XBTZAR", Sequence: int64(i + 1), Timestamp: luno.Time(since), Base: decimal.NewFromInt64(1)}
+	}
+	client := sdk.NewMockLunoClient(t)
+	client.EXPECT().ListUserTrades(ctx, &luno.ListUserTradesRequest{Pair: "XBTZAR", Since: luno.Time(since.Add(-MatchWindow)), Limit: pageSize}).
+		Return(&luno.ListUserTradesResponse{Trades: full}, nil).Once()
+	client.EXPECT().ListUserTrades(ctx, &luno.ListUserTradesRequest{Pair: "XBTZAR", Since: luno.Time(since.Add(-MatchWindow)), Limit: pageSize, AfterSeq: pageSize + 1}).
+		Return(&luno.ListUserTradesResponse{Trades: []luno.TradeV2{{Pair: "XBTZAR", Sequence: pageSize + 1, Timestamp: luno.Time(until)}}}, nil).Once()
+
+	got, err := OnLuno(ctx, client, "XBTZAR", since, until)
+	require.NoError(t, err)
+	assert.Len(t, got, pageSize+1)
+	assert.Equal(t, until, got[pageSize].Timestamp)
+}
+
+func TestOnLunoError(t *testing.T) {
+	ctx := context.Background()
+	client := sdk.NewMockLunoClient(t)
+	client.EXPECT().ListUserTrades(ctx, &luno.ListUserTradesRequest{Pair: "XBTZAR", Since: luno.Time(at.Add(-MatchWindow)), Limit: pageSize}).
+		Return(nil, errors.New("unauthorised")).Once()
+
+	_, err := OnLuno(ctx, client, "XBTZAR", at, at)
+	assert.ErrorContains(t, err, "listing XBTZAR trades: unauthorised")
+}
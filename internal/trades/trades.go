@@ -0,0 +1,231 @@
+// Package trades keeps the trades made outside the server, such as on the Luno
+// website, that the user has imported, so they can be reviewed alongside the
+// trades the server can see.
+package trades
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/internal/fsutil"
+	"github.com/luno/luno-mcp/internal/vault"
+)
+
+// MatchWindow is how far apart the timestamps of two trades can be for them
+// to be the same trade, since exports are often rounded to the second
+const MatchWindow = time.Second
+
+// Trade is an imported trade
+type Trade struct {
+	ID    string          `json:"id"`
+	Pair  string          `json:"pair"`
+	IsBuy bool            `json:"is_buy"`
+	Price decimal.Decimal `json:"price"`
+	// Volume is the base amount traded and Counter the counter amount
+	Volume     decimal.Decimal `json:"volume"`
+	Counter    decimal.Decimal `json:"counter"`
+	FeeBase    decimal.Decimal `json:"fee_base,omitzero"`
+	FeeCounter decimal.Decimal `json:"fee_counter,omitzero"`
+	OrderID    string          `json:"order_id,omitempty"`
+	Timestamp  time.Time       `json:"timestamp"`
+	// Source is where the trade was imported from, e.g. a file name
+	Source     string    `json:"source,omitempty"`
+	ImportedAt time.Time `json:"imported_at"`
+	// Tenant is the tenant that imported the trade, in multi-tenant
+	// deployments
+	Tenant string `json:"tenant,omitempty"`
+}
+
+// FromLuno converts a trade listed by the Luno API
+func FromLuno(t luno.TradeV2) Trade {
+	return Trade{
+		Pair:       t.Pair,
+		IsBuy:      t.IsBuy,
+		Price:      t.Price,
+		Volume:     t.Base,
+		Counter:    t.Counter,
+		FeeBase:    t.FeeBase,
+		FeeCounter: t.FeeCounter,
+		OrderID:    t.OrderId,
+		Timestamp:  time.Time(t.Timestamp).UTC(),
+	}
+}
+
+// Matches reports whether t and o are the same trade: on the same market and
+// side, for the same volume and price, within MatchWindow of each other
+func (t Trade) Matches(o Trade) bool {
+	d := t.Timestamp.Sub(o.Timestamp)
+	return t.Pair == o.Pair && t.IsBuy == o.IsBuy &&
+		d <= MatchWindow && d >= -MatchWindow &&
+		t.Volume.Cmp(o.Volume) == 0 && t.Price.Cmp(o.Price) == 0
+}
+
+// Result is what adding trades to a Store did
+type Result struct {
+	Added []Trade `json:"added"`
+	// Duplicates were already imported, or repeated in the trades added
+	Duplicates []Trade `json:"duplicates,omitempty"`
+}
+
+// Store holds imported trades in a JSON file. The file is read on first use
+// and rewritten whenever trades are added. It is safe for concurrent use.
+type Store struct {
+	path   string
+	cipher *vault.Cipher
+
+	mu     sync.Mutex
+	loaded bool
+	trades []Trade
+
+	now func() time.Time
+}
+
+// New creates a store kept at path. An empty path keeps trades in memory only.
+func New(path string) *Store {
+	return &Store{path: path, now: time.Now}
+}
+
+// SetCipher encrypts the file with c. It must be called before the store is
+// used; a file written without encryption is encrypted when it is first read.
+func (s *Store) SetCipher(c *vault.Cipher) {
+	s.cipher = c
+}
+
+// Path returns where the trades are stored, or an empty string if they are
+// only kept in memory
+func (s *Store) Path() string {
+	return s.path
+}
+
+// FileName is the name of the file the trades are kept in by default
+const FileName = "imported-trades.json"
+
+// DefaultPath returns the default location of the trades in the user's config
+// directory, or an empty string if there isn't one
+func DefaultPath() string {
+	return fsutil.ConfigPath(FileName)
+}
+
+// Add saves the trades that their tenant has not already imported, assigning
+// their IDs and import time
+func (s *Store) Add(trades ...Trade) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return Result{}, err
+	}
+	r := s.split(trades)
+	if len(r.Added) == 0 {
+		return r, nil
+	}
+	all := append(slices.Clone(s.trades), r.Added...)
+	if err := s.save(all); err != nil {
+		return Result{}, err
+	}
+	s.trades = all
+	return r, nil
+}
+
+// Check returns what Add would do with trades, without saving them
+func (s *Store) Check(trades ...Trade) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return Result{}, err
+	}
+	return s.split(trades), nil
+}
+
+// split separates trades into those not yet imported and duplicates. The
+// caller must hold s.mu.
+func (s *Store) split(trades []Trade) Result {
+	r := Result{Added: []Trade{}}
+	now := s.now().UTC()
+	for _, t := range trades {
+		matches := func(e Trade) bool { return e.Tenant == t.Tenant && e.Matches(t) }
+		if slices.ContainsFunc(s.trades, matches) || slices.ContainsFunc(r.Added, matches) {
+			r.Duplicates = append(r.Duplicates, t)
+			continue
+		}
+		t.ID = "import-" + strconv.Itoa(len(s.trades)+len(r.Added)+1)
+		t.ImportedAt = now
+		r.Added = append(r.Added, t)
+	}
+	return r
+}
+
+// List returns the trades tenant imported on pair, or on every market if pair
+// is empty, oldest first
+func (s *Store) List(tenant, pair string) ([]Trade, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	out := slices.DeleteFunc(slices.Clone(s.trades), func(t Trade) bool { return t.Tenant != tenant || (pair != "" && t.Pair != pair) })
+	slices.SortStableFunc(out, func(a, b Trade) int { return a.Timestamp.Compare(b.Timestamp) })
+	return out, nil
+}
+
+// ByOrder returns the trades tenant imported of an order
+func (s *Store) ByOrder(tenant, orderID string) ([]Trade, error) {
+	all, err := s.List(tenant, "")
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(all, func(t Trade) bool { return t.OrderID != orderID }), nil
+}
+
+// load reads the trades file the first time it is needed. The caller must
+// hold s.mu.
+func (s *Store) load() error {
+	if s.loaded || s.path == "" {
+		s.loaded = true
+		return nil
+	}
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		s.loaded = true
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading imported trades: %w", err)
+	}
+	sealed := vault.IsSealed(data)
+	if data, err = s.cipher.Open(data); err != nil {
+		return fmt.Errorf("reading imported trades %s: %w", s.path, err)
+	}
+	var trades []Trade
+	if err := json.Unmarshal(data, &trades); err != nil {
+		return fmt.Errorf("reading imported trades %s: %w", s.path, err)
+	}
+	s.trades = trades
+	s.loaded = true
+	if s.cipher.Enabled() && !sealed {
+		return s.save(trades)
+	}
+	return nil
+}
+
+// save replaces the trades file with trades. The caller must hold s.mu.
+func (s *Store) save(trades []Trade) error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(trades, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding imported trades: %w", err)
+	}
+	if err := fsutil.WriteFile(s.path, append(s.cipher.Seal(data), '\n'), 0o600); err != nil {
+		return fmt.Errorf("writing imported trades: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package trades
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/internal/vault"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var at = time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+
+func trade(pair string, isBuy bool, ts time.Time, volume, price int64) Trade {
+	return Trade{
+		Pair:      pair,
+		IsBuy:     isBuy,
+		Timestamp: ts,
+		Volume:    decimal.NewFromInt64(volume),
+		Price:     decimal.NewFromInt64(price),
+	}
+}
+
+func TestMatches(t *testing.T) {
+	base := trade("XBTZAR", true, at, 1, 1000)
+	assert.True(t, base.Matches(trade("XBTZAR", true, at.Add(MatchWindow), 1, 1000)))
+	assert.True(t, base.Matches(FromLuno(luno.TradeV2{Pair: "XBTZAR", IsBuy: true, Timestamp: luno.Time(at.Add(-500 * time.Millisecond)),
+		Base: decimal.NewFromFloat64(1, 2), Price: decimal.NewFromInt64(1000)})), "scale doesn't matter")
+	assert.False(t, base.Matches(trade("XBTZAR", true, at.Add(2*time.Second), 1, 1000)))
+	assert.False(t, base.Matches(trade("XBTZAR", false, at, 1, 1000)))
+	assert.False(t, base.Matches(trade("ETHZAR", true, at, 1, 1000)))
+	assert.False(t, base.Matches(trade("XBTZAR", true, at, 2, 1000)))
+	assert.False(t, base.Matches(trade("XBTZAR", true, at, 1, 1001)))
+}
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "luno-mcp", "imported-trades.json")
+	s := New(path)
+	s.now = func() time.Time { return at }
+
+	first := trade("XBTZAR", true, at.Add(-time.Hour), 1, 1000)
+	first.OrderID = "BXMC2CJ7HNB88U4"
+	r, err := s.Add(first, trade("ETHZAR", false, at.Add(-2*time.Hour), 2, 50), first)
+	require.NoError(t, err)
+	require.Len(t, r.Added, 2)
+	assert.Equal(t, "import-1", r.Added[0].ID)
+	assert.Equal(t, at, r.Added[0].ImportedAt)
+	assert.Len(t, r.Duplicates, 1, "repeats within the import are dropped")
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// Checking doesn't save, and trades imported before are duplicates
+	reopened := New(path)
+	r, err = reopened.Check(first, trade("XBTZAR", false, at, 1, 1000))
+	require.NoError(t, err)
+	require.Len(t, r.Added, 1)
+	assert.Equal(t, "import-3", r.Added[0].ID)
+	assert.Len(t, r.Duplicates, 1)
+
+	list, err := reopened.List("", "")
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "ETHZAR", list[0].Pair, "oldest first")
+	list, err = reopened.List("", "XBTZAR")
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "1", list[0].Volume.String())
+
+	byOrder, err := reopened.ByOrder("", "BXMC2CJ7HNB88U4")
+	require.NoError(t, err)
+	assert.Len(t, byOrder, 1)
+}
+
+func TestStoreTenants(t *testing.T) {
+	s := New(filepath.Join(t.TempDir(), "imported-trades.json"))
+	mine := trade("XBTZAR", true, at, 1, 1000)
+	mine.Tenant = "acme"
+	mine.OrderID = "BXMC2CJ7HNB88U4"
+	theirs := mine
+	theirs.Tenant = "globex"
+
+	r, err := s.Add(mine, theirs)
+	require.NoError(t, err)
+	assert.Len(t, r.Added, 2, "the same trade imported by another tenant is not a duplicate")
+
+	list, err := s.List("acme", "")
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "acme", list[0].Tenant)
+	list, err = s.List("", "")
+	require.NoError(t, err)
+	assert.Empty(t, list)
+	byOrder, err := s.ByOrder("globex", "BXMC2CJ7HNB88U4")
+	require.NoError(t, err)
+	require.Len(t, byOrder, 1)
+	assert.Equal(t, "globex", byOrder[0].Tenant)
+}
+
+func TestStoreCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "imported-trades.json")
+	require.NoError(t, os.WriteFile(path, []byte("not json"), 0o600))
+	_, err := New(path).List("", "")
+	assert.ErrorContains(t, err, "reading imported trades")
+}
+
+func TestStoreEncrypted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "imported-trades.json")
+	c, err := vault.New(bytes.Repeat([]byte{1}, vault.KeySize))
+	require.NoError(t, err)
+
+	s := New(path)
+	s.SetCipher(c)
+	_, err = s.Add(Trade{Pair: "XBTZAR", IsBuy: true, Volume: decimal.NewFromFloat64(0.1, 1), Price: decimal.NewFromInt64(1_000_000), Timestamp: time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.True(t, vault.IsSealed(data))
+
+	_, err = New(path).List("", "")
+	assert.ErrorIs(t, err, vault.ErrEncrypted)
+}
@@ -0,0 +1,188 @@
+// Package tradinghours restricts the tools that trade or move funds to the
+// times of the week the operator allows, such as weekdays only or not
+// overnight.
+package tradinghours
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const minutesPerDay = 24 * 60
+
+var dayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// Window is a time of day on some days of the week. A window whose end is not
+// after its start runs past midnight into the next day.
+type Window struct {
+	days [7]bool
+	// start and end are minutes after midnight
+	start, end int
+}
+
+// contains reports whether t, in the policy's time zone, is in the window
+func (w Window) contains(t time.Time) bool {
+	minute := t.Hour()*60 + t.Minute()
+	if w.start < w.end {
+		return w.days[t.Weekday()] && minute >= w.start && minute < w.end
+	}
+	// Past midnight, the window belongs to the day it started on
+	yesterday := (t.Weekday() + 6) % 7
+	return (w.days[t.Weekday()] && minute >= w.start) || (w.days[yesterday] && minute < w.end)
+}
+
+// Policy is the windows trading is allowed in. A nil Policy allows trading at
+// any time.
+type Policy struct {
+	spec     string
+	windows  []Window
+	location *time.Location
+}
+
+// Parse parses windows separated by semicolons, each being days, a time range
+// or both, such as "Mon-Fri 06:00-24:00; Sat 09:00-13:00" or "22:00-02:00".
+// Days are three letter names, ranges of them like Mon-Fri, lists like
+// Sat,Sun, or "weekdays" and "weekends"; a window without days is every day
+// and one without times is the whole day. Times are read in location. An
+// empty spec returns a nil Policy.
+func Parse(spec string, location *time.Location) (*Policy, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, nil
+	}
+	p := &Policy{spec: strings.TrimSpace(spec), location: location}
+	for part := range strings.SplitSeq(spec, ";") {
+		w, err := parseWindow(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		p.windows = append(p.windows, w)
+	}
+	return p, nil
+}
+
+func parseWindow(s string) (Window, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 || len(fields) > 2 {
+		return Window{}, fmt.Errorf("invalid trading window %q: want days, a time range like 06:00-22:00, or both", s)
+	}
+	w := Window{start: 0, end: minutesPerDay}
+	days, times := "", ""
+	switch {
+	case len(fields) == 2:
+		days, times = fields[0], fields[1]
+	case strings.Contains(fields[0], ":"):
+		times = fields[0]
+	default:
+		days = fields[0]
+	}
+
+	if days == "" {
+		for d := range w.days {
+			w.days[d] = true
+		}
+	} else if err := parseDays(days, &w.days); err != nil {
+		return Window{}, fmt.Errorf("invalid trading window %q: %w", s, err)
+	}
+
+	if times != "" {
+		from, to, ok := strings.Cut(times, "-")
+		if !ok {
+			return Window{}, fmt.Errorf("invalid trading window %q: times must be a range like 06:00-22:00", s)
+		}
+		var err error
+		if w.start, err = parseClock(from); err != nil {
+			return Window{}, fmt.Errorf("invalid trading window %q: %w", s, err)
+		}
+		if w.end, err = parseClock(to); err != nil {
+			return Window{}, fmt.Errorf("invalid trading window %q: %w", s, err)
+		}
+		if w.start == w.end || w.start == minutesPerDay {
+			return Window{}, fmt.Errorf("invalid trading window %q: the window is empty", s)
+		}
+	}
+	return w, nil
+}
+
+func parseDays(s string, days *[7]bool) error {
+	switch strings.ToLower(s) {
+	case "weekdays":
+		s = "mon-fri"
+	case "weekends":
+		s = "sat,sun"
+	}
+	for part := range strings.SplitSeq(strings.ToLower(s), ",") {
+		from, to, isRange := strings.Cut(part, "-")
+		first, ok := dayNames[from]
+		if !ok {
+			return fmt.Errorf("unknown day %q", from)
+		}
+		last := first
+		if isRange {
+			if last, ok = dayNames[to]; !ok {
+				return fmt.Errorf("unknown day %q", to)
+			}
+		}
+		for d := first; ; d = (d + 1) % 7 {
+			days[d] = true
+			if d == last {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// parseClock parses HH:MM, from 00:00 to 24:00, into minutes after midnight
+func parseClock(s string) (int, error) {
+	h, m, ok := strings.Cut(s, ":")
+	hour, hourErr := strconv.Atoi(h)
+	minute, minuteErr := strconv.Atoi(m)
+	if !ok || len(m) != 2 || hourErr != nil || minuteErr != nil ||
+		hour < 0 || minute < 0 || minute > 59 || hour*60+minute > minutesPerDay {
+		return 0, fmt.Errorf("invalid time %q: want HH:MM from 00:00 to 24:00", s)
+	}
+	return hour*60 + minute, nil
+}
+
+// Allowed reports whether trading is allowed at t
+func (p *Policy) Allowed(t time.Time) bool {
+	if p == nil {
+		return true
+	}
+	t = t.In(p.location)
+	for _, w := range p.windows {
+		if w.contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextOpen returns the next time after t that trading is allowed, to the
+// minute, and false if there is none within a week
+func (p *Policy) NextOpen(t time.Time) (time.Time, bool) {
+	if p == nil {
+		return t, true
+	}
+	next := t.In(p.location).Truncate(time.Minute)
+	for range 7 * minutesPerDay {
+		next = next.Add(time.Minute)
+		if p.Allowed(next) {
+			return next, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// String returns the windows as configured, with their time zone
+func (p *Policy) String() string {
+	if p == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s (%s)", p.spec, p.location)
+}
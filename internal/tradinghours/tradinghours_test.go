@@ -0,0 +1,100 @@
+package tradinghours
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyAllowed(t *testing.T) {
+	// 2026-10-12 is a Monday
+	at := func(day int, clock string) time.Time {
+		tm, err := time.Parse("2006-01-02 15:04", fmt.Sprintf("2026-10-%02d %s", day, clock))
+		require.NoError(t, err)
+		return tm
+	}
+
+	tests := []struct {
+		spec    string
+		allowed []time.Time
+		refused []time.Time
+	}{
+		{
+			spec:    "weekdays",
+			allowed: []time.Time{at(12, "00:00"), at(16, "23:59")},
+			refused: []time.Time{at(17, "12:00"), at(18, "12:00")},
+		},
+		{
+			spec:    "06:00-24:00",
+			allowed: []time.Time{at(12, "06:00"), at(18, "23:59")},
+			refused: []time.Time{at(12, "00:00"), at(12, "05:59")},
+		},
+		{
+			spec:    "Mon-Fri 22:00-02:00",
+			allowed: []time.Time{at(12, "22:00"), at(13, "01:59"), at(17, "01:00")},
+			refused: []time.Time{at(12, "01:00"), at(12, "02:00"), at(17, "22:00")},
+		},
+		{
+			spec:    "Sat,Sun 09:00-13:00; Fri-Mon 18:00-19:00",
+			allowed: []time.Time{at(17, "09:00"), at(16, "18:30"), at(12, "18:30")},
+			refused: []time.Time{at(17, "13:00"), at(14, "18:30")},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.spec, func(t *testing.T) {
+			p, err := Parse(tc.spec, time.UTC)
+			require.NoError(t, err)
+			for _, tm := range tc.allowed {
+				assert.True(t, p.Allowed(tm), "%s", tm.Format(time.RFC1123))
+			}
+			for _, tm := range tc.refused {
+				assert.False(t, p.Allowed(tm), "%s", tm.Format(time.RFC1123))
+			}
+		})
+	}
+}
+
+func TestPolicyTimeZone(t *testing.T) {
+	loc := time.FixedZone("SAST", 2*60*60)
+	p, err := Parse("06:00-18:00", loc)
+	require.NoError(t, err)
+	assert.False(t, p.Allowed(time.Date(2026, 10, 12, 3, 30, 0, 0, time.UTC)), "05:30 in SAST")
+	assert.True(t, p.Allowed(time.Date(2026, 10, 12, 4, 0, 0, 0, time.UTC)), "06:00 in SAST")
+	assert.Equal(t, "06:00-18:00 (SAST)", p.String())
+}
+
+func TestPolicyNextOpen(t *testing.T) {
+	p, err := Parse("Mon-Fri 06:00-24:00", time.UTC)
+	require.NoError(t, err)
+
+	next, ok := p.NextOpen(time.Date(2026, 10, 17, 10, 15, 30, 0, time.UTC))
+	require.True(t, ok)
+	assert.Equal(t, time.Date(2026, 10, 19, 6, 0, 0, 0, time.UTC), next)
+
+	var none *Policy
+	assert.True(t, none.Allowed(time.Now()))
+	assert.Empty(t, none.String())
+}
+
+func TestParseErrors(t *testing.T) {
+	p, err := Parse("  ", time.UTC)
+	require.NoError(t, err)
+	assert.Nil(t, p)
+
+	tests := map[string]string{
+		"Funday":               `invalid trading window "Funday": unknown day "funday"`,
+		"Mon-Fri 06:00":        `invalid trading window "Mon-Fri 06:00": times must be a range like 06:00-22:00`,
+		"25:00-26:00":          `invalid trading window "25:00-26:00": invalid time "25:00": want HH:MM from 00:00 to 24:00`,
+		"6-7":                  `invalid trading window "6-7": unknown day "6"`,
+		"09:00-09:00":          `invalid trading window "09:00-09:00": the window is empty`,
+		"Mon 09:00-10:00 Tue":  `invalid trading window "Mon 09:00-10:00 Tue": want days, a time range like 06:00-22:00, or both`,
+		"Mon 09:00-10:00;;Tue": `invalid trading window "": want days, a time range like 06:00-22:00, or both`,
+	}
+	for spec, expected := range tests {
+		_, err := Parse(spec, time.UTC)
+		assert.EqualError(t, err, expected, spec)
+	}
+}
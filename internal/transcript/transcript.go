@@ -0,0 +1,156 @@
+// Package transcript records the tool calls made in each MCP session, with
+// their results, so that a user can share exactly what an agent did, for
+// example when reporting an issue. Secrets are redacted as calls are recorded.
+package transcript
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luno/luno-mcp/internal/redact"
+)
+
+const (
+	// IdleTTL is how long the transcript of a session that makes no calls is kept
+	IdleTTL = 24 * time.Hour
+
+	// MaxCalls is how many calls a transcript keeps, dropping the oldest first
+	MaxCalls = 500
+
+	// MaxResultBytes caps the result text kept for each call
+	MaxResultBytes = 8 * 1024
+)
+
+// Call is a tool call in a transcript
+type Call struct {
+	Time time.Time `json:"time"`
+	Tool string    `json:"tool"`
+	// Arguments are the call's arguments as JSON
+	Arguments  string `json:"arguments,omitempty"`
+	Result     string `json:"result"`
+	IsError    bool   `json:"is_error,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// Transcript is what one session did
+type Transcript struct {
+	SessionID string    `json:"session_id"`
+	Started   time.Time `json:"started"`
+	// Dropped counts the oldest calls left out to keep the transcript to MaxCalls
+	Dropped int    `json:"dropped,omitempty"`
+	Calls   []Call `json:"calls"`
+
+	lastCall time.Time
+}
+
+// Store holds the transcripts of the sessions active within IdleTTL. It is
+// safe for concurrent use.
+type Store struct {
+	mu       sync.Mutex
+	sessions map[string]*Transcript
+}
+
+// NewStore creates an empty Store
+func NewStore() *Store {
+	return &Store{sessions: make(map[string]*Transcript)}
+}
+
+// Record adds c to the transcript of the session with id, redacting secrets
+// from its arguments and result and shortening long results
+func (s *Store) Record(id string, c Call) {
+	c.Time = c.Time.UTC()
+	c.Arguments = redact.String(c.Arguments)
+	c.Result = truncate(redact.String(c.Result))
+
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	maps.DeleteFunc(s.sessions, func(_ string, t *Transcript) bool { return now.Sub(t.lastCall) > IdleTTL })
+	t, ok := s.sessions[id]
+	if !ok {
+		t = &Transcript{SessionID: id, Started: c.Time}
+		s.sessions[id] = t
+	}
+	if len(t.Calls) == MaxCalls {
+		t.Calls = append(t.Calls[:0], t.Calls[1:]...)
+		t.Dropped++
+	}
+	t.Calls = append(t.Calls, c)
+	t.lastCall = now
+}
+
+// Get returns a copy of the transcript of the session with id
+func (s *Store) Get(id string) (Transcript, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.sessions[id]
+	if !ok || time.Since(t.lastCall) > IdleTTL {
+		return Transcript{}, false
+	}
+	out := *t
+	out.Calls = append([]Call(nil), t.Calls...)
+	return out, true
+}
+
+// Markdown writes t as a readable Markdown document, exported at now
+func (t Transcript) Markdown(now time.Time) []byte {
+	var b bytes.Buffer
+	b.WriteString("# Session transcript\n\n")
+	fmt.Fprintf(&b, "- Session: `%s`\n", t.SessionID)
+	fmt.Fprintf(&b, "- Started: %s\n", t.Started.Format(time.RFC3339))
+	fmt.Fprintf(&b, "- Exported: %s\n", now.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "- Tool calls: %d\n", len(t.Calls)+t.Dropped)
+	b.WriteString("\nSecrets are redacted, and results longer than ")
+	fmt.Fprintf(&b, "%d bytes are shortened.", MaxResultBytes)
+	if t.Dropped > 0 {
+		fmt.Fprintf(&b, " The first %d calls are left out; only the last %d are kept.", t.Dropped, MaxCalls)
+	}
+	b.WriteString("\n\n")
+
+	for i, c := range t.Calls {
+		fmt.Fprintf(&b, "## %d. %s\n\n", t.Dropped+i+1, c.Tool)
+		fmt.Fprintf(&b, "%s, took %d ms\n\n", c.Time.Format(time.RFC3339), c.DurationMS)
+		if c.Arguments != "" {
+			b.WriteString("Arguments:\n\n")
+			writeBlock(&b, "json", indentJSON(c.Arguments))
+		}
+		if c.IsError {
+			b.WriteString("Error:\n\n")
+		} else {
+			b.WriteString("Result:\n\n")
+		}
+		writeBlock(&b, "", c.Result)
+	}
+	return b.Bytes()
+}
+
+// writeBlock writes text as a fenced code block, with a fence longer than any
+// run of backticks in text
+func writeBlock(b *bytes.Buffer, lang, text string) {
+	fence := "```"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	fmt.Fprintf(b, "%s%s\n%s\n%s\n\n", fence, lang, strings.TrimRight(text, "\n"), fence)
+}
+
+// indentJSON indents s if it is valid JSON, and returns it unchanged otherwise
+func indentJSON(s string) string {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(s), "", "  "); err != nil {
+		return s
+	}
+	return buf.String()
+}
+
+func truncate(s string) string {
+	if len(s) <= MaxResultBytes {
+		return s
+	}
+	return s[:MaxResultBytes] + "…"
+}
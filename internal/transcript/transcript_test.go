@@ -0,0 +1,76 @@
+package transcript
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-mcp/internal/redact"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreRecord(t *testing.T) {
+	redact.Register("transcript-secret-value")
+	s := NewStore()
+	at := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	s.Record("session-1", Call{Time: at, Tool: "get_ticker", Arguments: `{"pair":"XBTZAR"}`, Result: `{"bid":"1000000"}`, DurationMS: 120})
+	s.Record("session-1", Call{Time: at.Add(time.Minute), Tool: "create_order", Arguments: `{"note":"transcript-secret-value"}`, Result: strings.Repeat("x", MaxResultBytes+10), IsError: true})
+	s.Record("session-2", Call{Time: at, Tool: "get_balances"})
+
+	tr, ok := s.Get("session-1")
+	require.True(t, ok)
+	assert.Equal(t, "session-1", tr.SessionID)
+	assert.Equal(t, at, tr.Started)
+	require.Len(t, tr.Calls, 2)
+	assert.NotContains(t, tr.Calls[1].Arguments, "transcript-secret-value")
+	assert.Len(t, tr.Calls[1].Result, MaxResultBytes+len("…"))
+
+	// Get returns a copy
+	tr.Calls[0].Tool = "changed"
+	tr, _ = s.Get("session-1")
+	assert.Equal(t, "get_ticker", tr.Calls[0].Tool)
+
+	_, ok = s.Get("session-3")
+	assert.False(t, ok)
+}
+
+func TestStoreRecordDropsOldest(t *testing.T) {
+	s := NewStore()
+	for range MaxCalls + 3 {
+		s.Record("session-1", Call{Tool: "get_ticker"})
+	}
+	tr, _ := s.Get("session-1")
+	assert.Len(t, tr.Calls, MaxCalls)
+	assert.Equal(t, 3, tr.Dropped)
+	assert.Contains(t, string(tr.Markdown(time.Now())), "The first 3 calls are left out")
+}
+
+func TestTranscriptMarkdown(t *testing.T) {
+	at := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	tr := Transcript{
+		SessionID: "session-1",
+		Started:   at,
+		Calls: []Call{
+			{Time: at, Tool: "get_ticker", Arguments: `{"pair":"XBTZAR"}`, Result: "Ticker:\n```\nbid 1000000\n```", DurationMS: 120},
+			{Time: at.Add(time.Minute), Tool: "create_order", Result: "Insufficient balance", IsError: true, DurationMS: 80},
+		},
+	}
+	want := "# Session transcript\n\n" +
+		"- Session: `session-1`\n" +
+		"- Started: 2026-03-02T10:00:00Z\n" +
+		"- Exported: 2026-03-02T11:00:00Z\n" +
+		"- Tool calls: 2\n\n" +
+		"Secrets are redacted, and results longer than 8192 bytes are shortened.\n\n" +
+		"## 1. get_ticker\n\n" +
+		"2026-03-02T10:00:00Z, took 120 ms\n\n" +
+		"Arguments:\n\n" +
+		"```json\n{\n  \"pair\": \"XBTZAR\"\n}\n```\n\n" +
+		"Result:\n\n" +
+		"````\nTicker:\n```\nbid 1000000\n```\n````\n\n" +
+		"## 2. create_order\n\n" +
+		"2026-03-02T10:01:00Z, took 80 ms\n\n" +
+		"Error:\n\n" +
+		"```\nInsufficient balance\n```\n\n"
+	assert.Equal(t, want, string(tr.Markdown(at.Add(time.Hour))))
+}
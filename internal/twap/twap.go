@@ -0,0 +1,519 @@
+// Package twap executes large orders as a series of smaller limit orders spread
+// evenly over a time window (time-weighted average price), to reduce the market
+// impact of a single big order.
+package twap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/internal/markets"
+	"github.com/luno/luno-mcp/sdk"
+)
+
+// Limits on how an execution is split
+const (
+	MinSlices = 2
+	MaxSlices = 100
+	// MinInterval is the shortest time between slices, so that each slice has a
+	// chance to fill and the API is not hammered
+	MinInterval = 10 * time.Second
+	MaxDuration = 24 * time.Hour
+)
+
+// settleTimeout bounds cancelling the open slice once an execution is stopped
+const settleTimeout = 30 * time.Second
+
+// State is the state of an execution
+type State string
+
+const (
+	StateRunning   State = "running"
+	StateCompleted State = "completed"
+	StateCancelled State = "cancelled"
+	StateFailed    State = "failed"
+)
+
+// Plan describes an order to execute over time
+type Plan struct {
+	Market luno.MarketInfo
+	// Side is luno.OrderTypeBid to buy or luno.OrderTypeAsk to sell
+	Side   luno.OrderType
+	Volume decimal.Decimal
+	Slices int
+	// Duration is the window the slices are spread over. The last slice is
+	// cancelled at the end of the window if it has not filled.
+	Duration time.Duration
+	// LimitPrice is the worst price any slice is placed at: the most paid when
+	// buying and the least accepted when selling
+	LimitPrice decimal.Decimal
+	// Tenant owns the execution in multi-tenant deployments. Only calls for
+	// the same tenant see or cancel it.
+	Tenant string
+	// Allowed reports whether slices may be placed at a time, such as
+	// tradinghours.Policy.Allowed. Slices due when it is false are skipped and
+	// their volume spread over the later ones. Nil allows any time.
+	Allowed func(time.Time) bool
+}
+
+// Interval is the time between slices
+func (p Plan) Interval() time.Duration {
+	if p.Slices <= 0 {
+		return 0
+	}
+	return p.Duration / time.Duration(p.Slices)
+}
+
+// Validate checks that every slice of p is an order the market accepts
+func (p Plan) Validate() error {
+	m := p.Market
+	if p.Side != luno.OrderTypeBid && p.Side != luno.OrderTypeAsk {
+		return fmt.Errorf("invalid side %q", p.Side)
+	}
+	if p.Slices < MinSlices || p.Slices > MaxSlices {
+		return fmt.Errorf("slices must be between %d and %d", MinSlices, MaxSlices)
+	}
+	if p.Duration > MaxDuration {
+		return fmt.Errorf("duration must be at most %s", MaxDuration)
+	}
+	if p.Interval() < MinInterval {
+		return fmt.Errorf("slices must be at least %s apart: use a longer duration or fewer slices", MinInterval)
+	}
+	if p.Volume.Sign() <= 0 {
+		return errors.New("volume must be positive")
+	}
+	if p.LimitPrice.Sign() <= 0 {
+		return errors.New("limit price must be positive")
+	}
+	if err := markets.CheckScales(m, p.Volume, p.LimitPrice); err != nil {
+		return err
+	}
+	// Slices are equal but for the last, which takes the remainder and so is
+	// the largest
+	slice := p.sliceVolume(p.Volume, p.Slices)
+	last := p.Volume.Sub(slice.MulInt64(int64(p.Slices - 1)))
+	for _, v := range []decimal.Decimal{slice, last} {
+		err := markets.CheckOrder(m, v, p.LimitPrice)
+		switch {
+		case errors.Is(err, markets.ErrVolumeBelowMinimum):
+			return fmt.Errorf("slices are too small: %w: use fewer slices", err)
+		case errors.Is(err, markets.ErrVolumeAboveMaximum):
+			return fmt.Errorf("slices are too large: %w: use more slices", err)
+		case err != nil:
+			return err
+		}
+	}
+	return nil
+}
+
+// sliceVolume splits remaining evenly over n slices, rounded down to the
+// market's volume scale. The last slice takes whatever remains.
+func (p Plan) sliceVolume(remaining decimal.Decimal, n int) decimal.Decimal {
+	if n <= 1 {
+		return remaining
+	}
+	// Division keeps the scale, which is remaining's own when the market
+	// didn't report its volume scale
+	if scale := int(p.Market.VolumeScale); scale > 0 {
+		remaining = remaining.ToScale(scale)
+	}
+	return remaining.DivInt64(int64(n))
+}
+
+// slicePrice returns the price to place the next slice at. Slices take the best
+// price on the other side of the book, or join their own side on post-only
+// markets, but are never placed beyond LimitPrice.
+func (p Plan) slicePrice(t *luno.GetTickerResponse) decimal.Decimal {
+	postOnly := p.Market.TradingStatus == luno.TradingStatusPost_only
+	if p.Side == luno.OrderTypeBid {
+		price := t.Ask
+		if postOnly {
+			price = t.Bid
+		}
+		if price.Sign() <= 0 || price.Cmp(p.LimitPrice) > 0 {
+			return p.LimitPrice
+		}
+		return price
+	}
+	price := t.Bid
+	if postOnly {
+		price = t.Ask
+	}
+	if price.Sign() <= 0 || price.Cmp(p.LimitPrice) < 0 {
+		return p.LimitPrice
+	}
+	return price
+}
+
+// Slice is one limit order placed by an execution
+type Slice struct {
+	OrderID  string          `json:"order_id"`
+	Volume   decimal.Decimal `json:"volume"`
+	Price    decimal.Decimal `json:"price"`
+	PlacedAt time.Time       `json:"placed_at"`
+	State    luno.OrderState `json:"state"`
+	// Filled and FilledCounter are the base volume traded and the counter
+	// amount it cost or raised
+	Filled        decimal.Decimal `json:"filled"`
+	FilledCounter decimal.Decimal `json:"filled_counter"`
+}
+
+// Execution is the progress of a plan
+type Execution struct {
+	ID         string          `json:"id"`
+	Pair       string          `json:"pair"`
+	Side       luno.OrderType  `json:"side"`
+	Volume     decimal.Decimal `json:"volume"`
+	LimitPrice decimal.Decimal `json:"limit_price"`
+	Slices     int             `json:"slices"`
+	Duration   string          `json:"duration"`
+	Interval   string          `json:"interval"`
+	State      State           `json:"state"`
+	StartedAt  time.Time       `json:"started_at"`
+	FinishedAt time.Time       `json:"finished_at,omitzero"`
+	Tenant     string          `json:"tenant,omitempty"`
+	// Filled is the base volume traded so far, FilledCounter what it cost or raised
+	Filled        decimal.Decimal `json:"filled"`
+	FilledCounter decimal.Decimal `json:"filled_counter"`
+	// AveragePrice is FilledCounter divided by Filled, omitted until something fills
+	AveragePrice *decimal.Decimal `json:"average_price,omitempty"`
+	Orders       []Slice          `json:"orders"`
+	// Skipped is how many slices were due outside the trading windows
+	Skipped int    `json:"skipped_slices,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Done reports whether the execution has stopped
+func (e Execution) Done() bool {
+	return e.State != StateRunning
+}
+
+// NotifyFunc is told about an execution's progress after each slice and when it finishes
+type NotifyFunc func(e Execution)
+
+// Manager runs executions in the background. It is safe for concurrent use.
+// Executions are kept in memory and stop with the process; their resting orders
+// are cancelled when they are stopped with Cancel or Close.
+type Manager struct {
+	mu         sync.Mutex
+	executions map[string]*execution
+	lastID     int
+	wg         sync.WaitGroup
+
+	now   func() time.Time
+	after func(time.Duration) <-chan time.Time
+}
+
+type execution struct {
+	Execution
+	plan   Plan
+	cancel context.CancelFunc
+}
+
+// NewManager creates a Manager with no executions
+func NewManager() *Manager {
+	return &Manager{
+		executions: make(map[string]*execution),
+		now:        time.Now,
+		after:      time.After,
+	}
+}
+
+// Start validates plan and starts executing it with client in the background.
+// notify, if not nil, is called after each slice and when the execution ends.
+func (m *Manager) Start(plan Plan, client sdk.LunoClient, notify NotifyFunc) (Execution, error) {
+	if client == nil {
+		return Execution{}, errors.New("a Luno client is required")
+	}
+	if err := plan.Validate(); err != nil {
+		return Execution{}, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.mu.Lock()
+	m.lastID++
+	e := &execution{
+		Execution: Execution{
+			ID:            "twap-" + strconv.Itoa(m.lastID),
+			Pair:          plan.Market.MarketId,
+			Side:          plan.Side,
+			Volume:        plan.Volume,
+			LimitPrice:    plan.LimitPrice,
+			Slices:        plan.Slices,
+			Duration:      plan.Duration.String(),
+			Interval:      plan.Interval().String(),
+			State:         StateRunning,
+			StartedAt:     m.now(),
+			Tenant:        plan.Tenant,
+			Filled:        decimal.Zero(),
+			FilledCounter: decimal.Zero(),
+			Orders:        []Slice{},
+		},
+		plan:   plan,
+		cancel: cancel,
+	}
+	m.executions[e.ID] = e
+	snapshot := e.snapshot()
+	m.mu.Unlock()
+
+	slog.Info("TWAP execution started", "execution", e.ID, "pair", e.Pair, "side", e.Side,
+		"volume", e.Volume.String(), "limit_price", e.LimitPrice.String(), "slices", e.Slices, "duration", e.Duration)
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		m.run(ctx, e, client, notify)
+	}()
+	return snapshot, nil
+}
+
+// Get returns an execution of tenant by ID
+func (m *Manager) Get(tenant, id string) (Execution, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.executions[id]
+	if !ok || e.Tenant != tenant {
+		return Execution{}, false
+	}
+	return e.snapshot(), true
+}
+
+// List returns the executions of tenant ordered by start time
+func (m *Manager) List(tenant string) []Execution {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	list := make([]Execution, 0, len(m.executions))
+	for _, e := range m.executions {
+		if e.Tenant == tenant {
+			list = append(list, e.snapshot())
+		}
+	}
+	slices.SortFunc(list, func(a, b Execution) int {
+		if c := a.StartedAt.Compare(b.StartedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return list
+}
+
+// Cancel stops a running execution of tenant, cancelling its open slice. It
+// reports whether the execution exists.
+func (m *Manager) Cancel(tenant, id string) bool {
+	m.mu.Lock()
+	e, ok := m.executions[id]
+	ok = ok && e.Tenant == tenant
+	m.mu.Unlock()
+	if ok {
+		e.cancel()
+	}
+	return ok
+}
+
+// Close cancels every running execution and waits for them to stop
+func (m *Manager) Close() {
+	m.mu.Lock()
+	for _, e := range m.executions {
+		e.cancel()
+	}
+	m.mu.Unlock()
+	m.wg.Wait()
+}
+
+// run places the slices of e until they are all placed, the execution is
+// cancelled or an API call fails
+func (m *Manager) run(ctx context.Context, e *execution, client sdk.LunoClient, notify NotifyFunc) {
+	plan := e.plan
+	var (
+		open string
+		err  error
+	)
+	finish := func(state State) {
+		// The open slice is cancelled even if ctx is, so nothing is left resting
+		settleCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), settleTimeout)
+		defer cancel()
+		if open != "" {
+			if settleErr := m.settle(settleCtx, e, client, open); settleErr != nil && err == nil {
+				err = settleErr
+				state = StateFailed
+			}
+		}
+		m.mu.Lock()
+		e.State = state
+		e.FinishedAt = m.now()
+		if err != nil {
+			e.Error = err.Error()
+		}
+		snapshot := e.snapshot()
+		m.mu.Unlock()
+
+		slog.Info("TWAP execution finished", "execution", e.ID, "state", state,
+			"filled", snapshot.Filled.String(), "filled_counter", snapshot.FilledCounter.String(), "error", snapshot.Error)
+		if notify != nil {
+			notify(snapshot)
+		}
+	}
+	// fail stops the execution after an API error, unless the error is only
+	// because the execution was cancelled
+	fail := func(failure error) {
+		if ctx.Err() != nil {
+			finish(StateCancelled)
+			return
+		}
+		err = failure
+		finish(StateFailed)
+	}
+
+	for i := 0; i < plan.Slices; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				finish(StateCancelled)
+				return
+			case <-m.after(plan.Interval()):
+			}
+		}
+		if ctx.Err() != nil {
+			finish(StateCancelled)
+			return
+		}
+
+		// Whatever the previous slice did not fill is spread over the rest
+		if open != "" {
+			if settleErr := m.settle(ctx, e, client, open); settleErr != nil {
+				fail(settleErr)
+				return
+			}
+			open = ""
+		}
+
+		if plan.Allowed != nil && !plan.Allowed(m.now()) {
+			m.mu.Lock()
+			e.Skipped++
+			m.mu.Unlock()
+			slog.Info("TWAP slice skipped outside trading windows", "execution", e.ID, "slice", i+1, "of", plan.Slices)
+			continue
+		}
+
+		m.mu.Lock()
+		remaining := plan.Volume.Sub(e.Filled)
+		m.mu.Unlock()
+		if remaining.Sign() <= 0 {
+			break
+		}
+		volume := plan.sliceVolume(remaining, plan.Slices-i)
+		if minVolume := plan.Market.MinVolume; minVolume.Sign() > 0 && volume.Cmp(minVolume) < 0 {
+			if remaining.Cmp(minVolume) < 0 {
+				// Too little is left to place, stop here
+				break
+			}
+			volume = minVolume
+		}
+		if maxVolume := plan.Market.MaxVolume; maxVolume.Sign() > 0 && volume.Cmp(maxVolume) > 0 {
+			volume = maxVolume
+		}
+
+		ticker, tickerErr := client.GetTicker(ctx, &luno.GetTickerRequest{Pair: plan.Market.MarketId})
+		if tickerErr != nil {
+			fail(fmt.Errorf("getting ticker for slice %d: %w", i+1, tickerErr))
+			return
+		}
+		price := plan.slicePrice(ticker)
+
+		res, postErr := client.PostLimitOrder(ctx, &luno.PostLimitOrderRequest{
+			Pair:     plan.Market.MarketId,
+			Type:     plan.Side,
+			Volume:   volume,
+			Price:    price,
+			PostOnly: plan.Market.TradingStatus == luno.TradingStatusPost_only,
+		})
+		if postErr != nil {
+			fail(fmt.Errorf("placing slice %d: %w", i+1, postErr))
+			return
+		}
+		open = res.OrderId
+
+		m.mu.Lock()
+		e.Orders = append(e.Orders, Slice{
+			OrderID:       res.OrderId,
+			Volume:        volume,
+			Price:         price,
+			PlacedAt:      m.now(),
+			State:         luno.OrderStatePending,
+			Filled:        decimal.Zero(),
+			FilledCounter: decimal.Zero(),
+		})
+		snapshot := e.snapshot()
+		m.mu.Unlock()
+
+		slog.Info("TWAP slice placed", "execution", e.ID, "slice", i+1, "of", plan.Slices,
+			"order_id", res.OrderId, "volume", volume.String(), "price", price.String())
+		if notify != nil {
+			notify(snapshot)
+		}
+	}
+
+	// Give the last slice the rest of the window to fill
+	if open != "" {
+		select {
+		case <-ctx.Done():
+			finish(StateCancelled)
+			return
+		case <-m.after(plan.Interval()):
+		}
+	}
+	finish(StateCompleted)
+}
+
+// settle cancels orderID if it is still resting and records what it filled
+func (m *Manager) settle(ctx context.Context, e *execution, client sdk.LunoClient, orderID string) error {
+	order, err := client.GetOrder(ctx, &luno.GetOrderRequest{Id: orderID})
+	if err != nil {
+		return fmt.Errorf("checking order %s: %w", orderID, err)
+	}
+	if order.State == luno.OrderStatePending {
+		if _, err := client.StopOrder(ctx, &luno.StopOrderRequest{OrderId: orderID}); err != nil {
+			return fmt.Errorf("cancelling order %s: %w", orderID, err)
+		}
+		slog.Info("TWAP slice cancelled", "execution", e.ID, "order_id", orderID)
+		// Fetch the order again in case it filled before it was cancelled
+		if order, err = client.GetOrder(ctx, &luno.GetOrderRequest{Id: orderID}); err != nil {
+			return fmt.Errorf("checking order %s: %w", orderID, err)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range e.Orders {
+		s := &e.Orders[i]
+		if s.OrderID != orderID {
+			continue
+		}
+		s.State = order.State
+		s.Filled = order.Base
+		s.FilledCounter = order.Counter
+		e.Filled = e.Filled.Add(order.Base)
+		e.FilledCounter = e.FilledCounter.Add(order.Counter)
+	}
+	return nil
+}
+
+// snapshot copies e. The caller must hold the manager's lock.
+func (e *execution) snapshot() Execution {
+	out := e.Execution
+	out.Orders = slices.Clone(e.Orders)
+	if e.Filled.Sign() > 0 {
+		avg := e.FilledCounter.Div(e.Filled, int(e.plan.Market.PriceScale)+2)
+		out.AveragePrice = &avg
+	}
+	return out
+}
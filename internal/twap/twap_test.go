@@ -0,0 +1,301 @@
+package twap
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/luno/luno-mcp/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func dec(t *testing.T, s string) decimal.Decimal {
+	t.Helper()
+	d, err := decimal.NewFromString(s)
+	require.NoError(t, err)
+	return d
+}
+
+func testMarket(t *testing.T) luno.MarketInfo {
+	return luno.MarketInfo{
+		MarketId:      "XBTZAR",
+		TradingStatus: luno.TradingStatusActive,
+		VolumeScale:   4,
+		PriceScale:    0,
+		MinVolume:     dec(t, "0.0005"),
+		MaxVolume:     dec(t, "10"),
+	}
+}
+
+func testPlan(t *testing.T) Plan {
+	return Plan{
+		Market:     testMarket(t),
+		Side:       luno.OrderTypeBid,
+		Volume:     dec(t, "0.0300"),
+		Slices:     3,
+		Duration:   3 * time.Minute,
+		LimitPrice: dec(t, "1010000"),
+	}
+}
+
+// newTestManager returns a Manager whose slice intervals pass immediately
+func newTestManager() *Manager {
+	m := NewManager()
+	m.now = func() time.Time { return time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC) }
+	m.after = func(time.Duration) <-chan time.Time {
+		ch := make(chan time.Time, 1)
+		ch <- time.Time{}
+		return ch
+	}
+	return m
+}
+
+func TestPlanValidate(t *testing.T) {
+	tests := []struct {
+		name          string
+		modify        func(*Plan)
+		expectedError string
+	}{
+		{name: "valid", modify: func(*Plan) {}},
+		{name: "suspended market", modify: func(p *Plan) { p.Market.TradingStatus = luno.TradingStatusSuspended }, expectedError: "suspended"},
+		{name: "invalid side", modify: func(p *Plan) { p.Side = "BUY" }, expectedError: "invalid side"},
+		{name: "too few slices", modify: func(p *Plan) { p.Slices = 1 }, expectedError: "slices must be between 2 and 100"},
+		{name: "too many slices", modify: func(p *Plan) { p.Slices = 101 }, expectedError: "slices must be between 2 and 100"},
+		{name: "too long", modify: func(p *Plan) { p.Duration = 25 * time.Hour }, expectedError: "duration must be at most"},
+		{name: "slices too close", modify: func(p *Plan) { p.Duration = 20 * time.Second }, expectedError: "at least 10s apart"},
+		{name: "zero volume", modify: func(p *Plan) { p.Volume = decimal.Zero() }, expectedError: "volume must be positive"},
+		{name: "zero limit price", modify: func(p *Plan) { p.LimitPrice = decimal.Zero() }, expectedError: "limit price must be positive"},
+		{name: "limit price scale", modify: func(p *Plan) { p.LimitPrice = dec(t, "1000.5") }, expectedError: "more than the 0 decimal places"},
+		{name: "volume scale", modify: func(p *Plan) { p.Volume = dec(t, "0.03001") }, expectedError: "more than the 4 decimal places"},
+		{name: "slices below minimum", modify: func(p *Plan) { p.Volume = dec(t, "0.0010") }, expectedError: "slices are too small: volume 0.0003 is below the minimum of 0.0005 for XBTZAR: use fewer slices"},
+		{name: "slices above maximum", modify: func(p *Plan) { p.Volume = dec(t, "40") }, expectedError: "slices are too large: volume 13.3333 is above the maximum of 10 for XBTZAR: use more slices"},
+		{name: "last slice above maximum", modify: func(p *Plan) { p.Volume = dec(t, "30.0002") }, expectedError: "slices are too large: volume 10.0002 is above the maximum of 10"},
+		{name: "limit price below minimum", modify: func(p *Plan) { p.Market.MinPrice = dec(t, "2000000") }, expectedError: "price 1010000 is below the minimum of 2000000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := testPlan(t)
+			tt.modify(&p)
+			err := p.Validate()
+			if tt.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.expectedError)
+		})
+	}
+}
+
+func TestPlanSlicePrice(t *testing.T) {
+	ticker := &luno.GetTickerResponse{Bid: dec(t, "1000000"), Ask: dec(t, "1000100")}
+	tests := []struct {
+		name     string
+		side     luno.OrderType
+		status   luno.TradingStatus
+		limit    string
+		ticker   *luno.GetTickerResponse
+		expected string
+	}{
+		{name: "buy takes the ask", side: luno.OrderTypeBid, limit: "1010000", ticker: ticker, expected: "1000100"},
+		{name: "buy capped at limit", side: luno.OrderTypeBid, limit: "1000050", ticker: ticker, expected: "1000050"},
+		{name: "buy on empty book", side: luno.OrderTypeBid, limit: "1010000", ticker: &luno.GetTickerResponse{}, expected: "1010000"},
+		{name: "buy post-only joins the bid", side: luno.OrderTypeBid, status: luno.TradingStatusPost_only, limit: "1010000", ticker: ticker, expected: "1000000"},
+		{name: "sell takes the bid", side: luno.OrderTypeAsk, limit: "990000", ticker: ticker, expected: "1000000"},
+		{name: "sell floored at limit", side: luno.OrderTypeAsk, limit: "1000050", ticker: ticker, expected: "1000050"},
+		{name: "sell post-only joins the ask", side: luno.OrderTypeAsk, status: luno.TradingStatusPost_only, limit: "990000", ticker: ticker, expected: "1000100"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := testPlan(t)
+			p.Side = tt.side
+			p.Market.TradingStatus = tt.status
+			p.LimitPrice = dec(t, tt.limit)
+			assert.Equal(t, tt.expected, p.slicePrice(tt.ticker).String())
+		})
+	}
+}
+
+func TestManagerRun(t *testing.T) {
+	client := sdk.NewMockLunoClient(t)
+	ticker := &luno.GetTickerResponse{Bid: dec(t, "999900"), Ask: dec(t, "1000000")}
+	client.EXPECT().GetTicker(mock.Anything, &luno.GetTickerRequest{Pair: "XBTZAR"}).Return(ticker, nil).Times(3)
+
+	// The first slice fills, the second only partly and is cancelled, and the
+	// third picks up what the second missed
+	for _, o := range []struct{ id, volume string }{{"1", "0.0100"}, {"2", "0.0100"}, {"3", "0.0160"}} {
+		client.EXPECT().PostLimitOrder(mock.Anything, &luno.PostLimitOrderRequest{
+			Pair: "XBTZAR", Type: luno.OrderTypeBid, Volume: dec(t, o.volume), Price: dec(t, "1000000"),
+		}).Return(&luno.PostLimitOrderResponse{OrderId: o.id}, nil).Once()
+	}
+	client.EXPECT().GetOrder(mock.Anything, &luno.GetOrderRequest{Id: "1"}).
+		Return(&luno.GetOrderResponse{State: luno.OrderStateComplete, Base: dec(t, "0.0100"), Counter: dec(t, "10000.00")}, nil).Once()
+	client.EXPECT().GetOrder(mock.Anything, &luno.GetOrderRequest{Id: "2"}).
+		Return(&luno.GetOrderResponse{State: luno.OrderStatePending, Base: dec(t, "0.0040"), Counter: dec(t, "4000.00")}, nil).Once()
+	client.EXPECT().StopOrder(mock.Anything, &luno.StopOrderRequest{OrderId: "2"}).Return(&luno.StopOrderResponse{Success: true}, nil).Once()
+	client.EXPECT().GetOrder(mock.Anything, &luno.GetOrderRequest{Id: "2"}).
+		Return(&luno.GetOrderResponse{State: luno.OrderStateComplete, Base: dec(t, "0.0040"), Counter: dec(t, "4000.00")}, nil).Once()
+	client.EXPECT().GetOrder(mock.Anything, &luno.GetOrderRequest{Id: "3"}).
+		Return(&luno.GetOrderResponse{State: luno.OrderStateComplete, Base: dec(t, "0.0160"), Counter: dec(t, "16000.00")}, nil).Once()
+
+	m := newTestManager()
+	var (
+		mu       sync.Mutex
+		notified []Execution
+	)
+	started, err := m.Start(testPlan(t), client, func(e Execution) {
+		mu.Lock()
+		defer mu.Unlock()
+		notified = append(notified, e)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "twap-1", started.ID)
+	assert.Equal(t, StateRunning, started.State)
+	assert.Equal(t, "1m0s", started.Interval)
+
+	m.wg.Wait()
+
+	got, ok := m.Get("", "twap-1")
+	require.True(t, ok)
+	assert.Equal(t, StateCompleted, got.State)
+	assert.Empty(t, got.Error)
+	assert.Equal(t, "0.0300", got.Filled.String())
+	assert.Equal(t, "30000.00", got.FilledCounter.String())
+	require.NotNil(t, got.AveragePrice)
+	assert.Equal(t, "1000000.00", got.AveragePrice.String())
+	require.Len(t, got.Orders, 3)
+	assert.Equal(t, "0.0040", got.Orders[1].Filled.String())
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, notified, 4, "one notification per slice and one at the end")
+	assert.Len(t, notified[0].Orders, 1)
+	assert.Equal(t, StateCompleted, notified[3].State)
+	assert.Equal(t, []Execution{got}, m.List(""))
+}
+
+func TestManagerOutsideTradingWindows(t *testing.T) {
+	client := sdk.NewMockLunoClient(t)
+	ticker := &luno.GetTickerResponse{Bid: dec(t, "999900"), Ask: dec(t, "1000000")}
+	client.EXPECT().GetTicker(mock.Anything, &luno.GetTickerRequest{Pair: "XBTZAR"}).Return(ticker, nil).Times(2)
+
+	// The second slice is due outside the windows, so the third places what
+	// it would have
+	for _, o := range []struct{ id, volume string }{{"1", "0.0100"}, {"3", "0.0200"}} {
+		client.EXPECT().PostLimitOrder(mock.Anything, &luno.PostLimitOrderRequest{
+			Pair: "XBTZAR", Type: luno.OrderTypeBid, Volume: dec(t, o.volume), Price: dec(t, "1000000"),
+		}).Return(&luno.PostLimitOrderResponse{OrderId: o.id}, nil).Once()
+	}
+	client.EXPECT().GetOrder(mock.Anything, &luno.GetOrderRequest{Id: "1"}).
+		Return(&luno.GetOrderResponse{State: luno.OrderStateComplete, Base: dec(t, "0.0100"), Counter: dec(t, "10000.00")}, nil).Once()
+	client.EXPECT().GetOrder(mock.Anything, &luno.GetOrderRequest{Id: "3"}).
+		Return(&luno.GetOrderResponse{State: luno.OrderStateComplete, Base: dec(t, "0.0200"), Counter: dec(t, "20000.00")}, nil).Once()
+
+	m := newTestManager()
+	plan := testPlan(t)
+	var checks int
+	plan.Allowed = func(time.Time) bool {
+		checks++
+		return checks != 2
+	}
+	_, err := m.Start(plan, client, nil)
+	require.NoError(t, err)
+	m.wg.Wait()
+
+	got, ok := m.Get("", "twap-1")
+	require.True(t, ok)
+	assert.Equal(t, StateCompleted, got.State)
+	assert.Equal(t, 1, got.Skipped)
+	assert.Equal(t, "0.0300", got.Filled.String())
+	assert.Len(t, got.Orders, 2)
+}
+
+func TestManagerCancel(t *testing.T) {
+	client := sdk.NewMockLunoClient(t)
+	client.EXPECT().GetTicker(mock.Anything, mock.Anything).Return(&luno.GetTickerResponse{Ask: dec(t, "1000000")}, nil).Once()
+	client.EXPECT().PostLimitOrder(mock.Anything, mock.Anything).Return(&luno.PostLimitOrderResponse{OrderId: "1"}, nil).Once()
+	client.EXPECT().GetOrder(mock.Anything, &luno.GetOrderRequest{Id: "1"}).
+		Return(&luno.GetOrderResponse{State: luno.OrderStatePending, Base: dec(t, "0"), Counter: dec(t, "0")}, nil).Once()
+	client.EXPECT().StopOrder(mock.Anything, &luno.StopOrderRequest{OrderId: "1"}).Return(&luno.StopOrderResponse{Success: true}, nil).Once()
+	client.EXPECT().GetOrder(mock.Anything, &luno.GetOrderRequest{Id: "1"}).
+		Return(&luno.GetOrderResponse{State: luno.OrderStateComplete, Base: dec(t, "0"), Counter: dec(t, "0")}, nil).Once()
+
+	m := newTestManager()
+	// Intervals never pass, so the first slice stays open until cancelled
+	m.after = func(time.Duration) <-chan time.Time { return nil }
+	placed := make(chan struct{})
+	_, err := m.Start(testPlan(t), client, func(e Execution) {
+		if len(e.Orders) == 1 && !e.Done() {
+			close(placed)
+		}
+	})
+	require.NoError(t, err)
+
+	<-placed
+	assert.True(t, m.Cancel("", "twap-1"))
+	assert.False(t, m.Cancel("", "twap-2"))
+	m.Close()
+
+	got, ok := m.Get("", "twap-1")
+	require.True(t, ok)
+	assert.Equal(t, StateCancelled, got.State)
+	assert.Nil(t, got.AveragePrice)
+	assert.Equal(t, luno.OrderStateComplete, got.Orders[0].State)
+}
+
+func TestManagerFailure(t *testing.T) {
+	client := sdk.NewMockLunoClient(t)
+	client.EXPECT().GetTicker(mock.Anything, mock.Anything).Return(&luno.GetTickerResponse{Ask: dec(t, "1000000")}, nil).Once()
+	client.EXPECT().PostLimitOrder(mock.Anything, mock.Anything).Return(nil, errors.New("insufficient balance")).Once()
+
+	m := newTestManager()
+	_, err := m.Start(testPlan(t), client, nil)
+	require.NoError(t, err)
+	m.wg.Wait()
+
+	got, ok := m.Get("", "twap-1")
+	require.True(t, ok)
+	assert.Equal(t, StateFailed, got.State)
+	assert.Equal(t, "placing slice 1: insufficient balance", got.Error)
+	assert.Empty(t, got.Orders)
+}
+
+func TestManagerTenants(t *testing.T) {
+	client := sdk.NewMockLunoClient(t)
+	client.EXPECT().GetTicker(mock.Anything, mock.Anything).Return(&luno.GetTickerResponse{Ask: dec(t, "1000000")}, nil).Once()
+	client.EXPECT().PostLimitOrder(mock.Anything, mock.Anything).Return(nil, errors.New("insufficient balance")).Once()
+
+	m := newTestManager()
+	p := testPlan(t)
+	p.Tenant = "acme"
+	started, err := m.Start(p, client, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "acme", started.Tenant)
+	m.wg.Wait()
+
+	_, ok := m.Get("acme", "twap-1")
+	assert.True(t, ok)
+	_, ok = m.Get("globex", "twap-1")
+	assert.False(t, ok, "other tenants can't see the execution")
+	assert.Empty(t, m.List(""))
+	assert.Len(t, m.List("acme"), 1)
+	assert.False(t, m.Cancel("globex", "twap-1"), "other tenants can't cancel the execution")
+}
+
+func TestManagerStartInvalid(t *testing.T) {
+	m := NewManager()
+	_, err := m.Start(testPlan(t), nil, nil)
+	assert.ErrorContains(t, err, "a Luno client is required")
+
+	p := testPlan(t)
+	p.Slices = 0
+	_, err = m.Start(p, sdk.NewMockLunoClient(t), nil)
+	assert.ErrorContains(t, err, "slices must be between")
+	assert.Empty(t, m.List(""))
+}
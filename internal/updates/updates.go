@@ -0,0 +1,174 @@
+// Package updates checks GitHub for newer releases of luno-mcp.
+package updates
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// ReleasesURL is the GitHub API endpoint for the latest luno-mcp release
+	ReleasesURL = "https://api.github.com/repos/luno/luno-mcp/releases/latest"
+
+	// cacheTTL is how long a check is reused for. Unauthenticated GitHub API
+	// calls are limited to 60 an hour.
+	cacheTTL = time.Hour
+
+	// checkTimeout bounds a single check
+	checkTimeout = 10 * time.Second
+)
+
+// Result is what a check found
+type Result struct {
+	CurrentVersion string `json:"current_version"`
+	LatestVersion  string `json:"latest_version"`
+	// UpdateAvailable is false when the current version is not a release
+	// version, such as a development build
+	UpdateAvailable bool      `json:"update_available"`
+	ReleaseURL      string    `json:"release_url,omitempty"`
+	PublishedAt     time.Time `json:"published_at,omitzero"`
+	CheckedAt       time.Time `json:"checked_at"`
+}
+
+// release is the part of a GitHub release used
+type release struct {
+	TagName     string    `json:"tag_name"`
+	HTMLURL     string    `json:"html_url"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// Checker looks up the latest release, reusing the result for an hour. It is
+// safe for concurrent use.
+type Checker struct {
+	url     string
+	current string
+	client  *http.Client
+
+	mu   sync.Mutex
+	last *Result
+}
+
+// NewChecker creates a Checker comparing version with the latest release at
+// url, usually ReleasesURL
+func NewChecker(url, version string) *Checker {
+	return &Checker{url: url, current: version, client: &http.Client{Timeout: checkTimeout}}
+}
+
+// Check returns the latest release and whether it is newer than the running
+// version
+func (c *Checker) Check(ctx context.Context) (Result, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.last != nil && time.Since(c.last.CheckedAt) < cacheTTL {
+		return *c.last, nil
+	}
+
+	rel, err := c.latest(ctx)
+	if err != nil {
+		return Result{}, err
+	}
+	result := Result{
+		CurrentVersion: c.current,
+		LatestVersion:  strings.TrimPrefix(rel.TagName, "v"),
+		ReleaseURL:     rel.HTMLURL,
+		PublishedAt:    rel.PublishedAt,
+		CheckedAt:      time.Now().UTC(),
+	}
+	result.UpdateAvailable = Newer(rel.TagName, c.current)
+	c.last = &result
+	return result, nil
+}
+
+func (c *Checker) latest(ctx context.Context) (release, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return release{}, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("User-Agent", "luno-mcp/"+c.current)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return release{}, fmt.Errorf("checking for updates: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return release{}, fmt.Errorf("checking for updates: GitHub returned %s", resp.Status)
+	}
+	var rel release
+	if err := json.NewDecoder(resp.Body).Decode(&rel); err != nil {
+		return release{}, fmt.Errorf("checking for updates: %w", err)
+	}
+	if rel.TagName == "" {
+		return release{}, fmt.Errorf("checking for updates: the latest release has no tag")
+	}
+	return rel, nil
+}
+
+// Newer reports whether version a is a later release than b. Versions are
+// major.minor.patch with an optional leading v; anything after a - or + is
+// ignored, and a version that is not in this form is never newer or older.
+func Newer(a, b string) bool {
+	va, ok := parse(a)
+	if !ok {
+		return false
+	}
+	vb, ok := parse(b)
+	if !ok {
+		return false
+	}
+	for i := range va {
+		if va[i] != vb[i] {
+			return va[i] > vb[i]
+		}
+	}
+	return false
+}
+
+func parse(v string) ([3]int, bool) {
+	v = strings.TrimPrefix(v, "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	parts := strings.Split(v, ".")
+	var out [3]int
+	if len(parts) != len(out) {
+		return out, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return out, false
+		}
+		out[i] = n
+	}
+	return out, true
+}
+
+// Advise checks for a newer release, logging a warning with where to get it
+// when there is one. Failed checks are only logged at debug level, since the
+// server works the same without them.
+func (c *Checker) Advise(ctx context.Context) {
+	if c == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+	result, err := c.Check(ctx)
+	if err != nil {
+		slog.Debug("Update check failed", slog.Any("error", err))
+		return
+	}
+	if result.UpdateAvailable {
+		slog.Warn("A newer version of luno-mcp is available",
+			slog.String("current_version", result.CurrentVersion),
+			slog.String("latest_version", result.LatestVersion),
+			slog.String("release_url", result.ReleaseURL))
+	}
+}
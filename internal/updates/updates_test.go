@@ -0,0 +1,73 @@
+package updates
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewer(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected bool
+	}{
+		{a: "v0.2.0", b: "0.1.0", expected: true},
+		{a: "0.1.10", b: "0.1.9", expected: true},
+		{a: "1.0.0", b: "0.9.9", expected: true},
+		{a: "v0.1.0", b: "0.1.0"},
+		{a: "0.1.0", b: "0.2.0"},
+		{a: "1.0.0-rc.1", b: "0.9.0", expected: true},
+		{a: "v1.0.0", b: "embedded"},
+		{a: "latest", b: "0.1.0"},
+		{a: "1.0", b: "0.1.0"},
+	}
+	for _, tc := range tests {
+		assert.Equal(t, tc.expected, Newer(tc.a, tc.b), "%s newer than %s", tc.a, tc.b)
+	}
+}
+
+func TestChecker(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		assert.Contains(t, r.Header.Get("User-Agent"), "luno-mcp/")
+		_, _ = w.Write([]byte(`{"tag_name":"v0.2.0","html_url":"https://github.com/luno/luno-mcp/releases/tag/v0.2.0","published_at":"2026-10-01T09:00:00Z"}`))
+	}))
+	defer srv.Close()
+
+	c := NewChecker(srv.URL, "0.1.0")
+	result, err := c.Check(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "0.1.0", result.CurrentVersion)
+	assert.Equal(t, "0.2.0", result.LatestVersion)
+	assert.True(t, result.UpdateAvailable)
+	assert.Equal(t, "https://github.com/luno/luno-mcp/releases/tag/v0.2.0", result.ReleaseURL)
+	assert.Equal(t, time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC), result.PublishedAt)
+
+	_, err = c.Check(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls, "checks are reused for an hour")
+
+	c = NewChecker(srv.URL, "0.2.0")
+	result, err = c.Check(context.Background())
+	require.NoError(t, err)
+	assert.False(t, result.UpdateAvailable)
+}
+
+func TestCheckerError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	_, err := NewChecker(srv.URL, "0.1.0").Check(context.Background())
+	assert.EqualError(t, err, "checking for updates: GitHub returned 403 Forbidden")
+
+	var disabled *Checker
+	disabled.Advise(context.Background())
+}
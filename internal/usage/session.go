@@ -0,0 +1,267 @@
+package usage
+
+import (
+	"cmp"
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// SessionIdleTTL is how long the stats of a session that makes no calls are kept
+const SessionIdleTTL = 24 * time.Hour
+
+// Trade is a kind of trading activity counted in SessionStats
+type Trade int
+
+const (
+	OrderPlaced Trade = iota
+	OrderCancelled
+	WithdrawalCreated
+)
+
+// SessionStats is what one MCP session did
+type SessionStats struct {
+	SessionID string    `json:"session_id"`
+	Started   time.Time `json:"started"`
+	LastCall  time.Time `json:"last_call"`
+	// ToolCalls counts the calls to each tool
+	ToolCalls     map[string]int64 `json:"tool_calls"`
+	Calls         int64            `json:"calls"`
+	Errors        int64            `json:"errors"`
+	ResponseBytes int64            `json:"response_bytes"`
+	// EstimatedTokens is a rough count of the tokens in the session's results
+	EstimatedTokens int64        `json:"estimated_tokens"`
+	API             APIStats     `json:"luno_api"`
+	Cache           CacheStats   `json:"cache"`
+	RateLimits      RateLimits   `json:"rate_limits"`
+	Trading         TradingStats `json:"trading"`
+}
+
+// APIStats are the Luno API calls made for a session
+type APIStats struct {
+	Calls         int64 `json:"calls"`
+	Errors        int64 `json:"errors"`
+	BytesSent     int64 `json:"bytes_sent"`
+	BytesReceived int64 `json:"bytes_received"`
+}
+
+// CacheStats are the lookups served from the server's caches, and those that
+// had to call Luno
+type CacheStats struct {
+	Hits    int64   `json:"hits"`
+	Misses  int64   `json:"misses"`
+	HitRate float64 `json:"hit_rate"`
+}
+
+// RateLimits counts the calls held back by limits
+type RateLimits struct {
+	// LunoRejected are Luno API calls refused with 429 Too Many Requests
+	LunoRejected int64 `json:"luno_rejected"`
+	// CallsRejected are tool calls that found no free slot in time
+	CallsRejected int64 `json:"calls_rejected"`
+	// RepeatsReused are repeated tool calls answered with the previous result
+	RepeatsReused int64 `json:"repeats_reused"`
+}
+
+// TradingStats counts the session's successful trading requests to Luno
+type TradingStats struct {
+	OrdersPlaced       int64 `json:"orders_placed"`
+	OrdersCancelled    int64 `json:"orders_cancelled"`
+	WithdrawalsCreated int64 `json:"withdrawals_created"`
+}
+
+// Session collects the stats of one MCP session. It is safe for concurrent use,
+// and its methods do nothing on a nil Session, so that code can record to the
+// session in its context without checking for one.
+type Session struct {
+	mu    sync.Mutex
+	stats SessionStats
+}
+
+// ToolCall records a tool call and the size of its result
+func (s *Session) ToolCall(tool string, responseBytes int64, failed bool) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats.ToolCalls[tool]++
+	s.stats.Calls++
+	if failed {
+		s.stats.Errors++
+	}
+	s.stats.ResponseBytes += responseBytes
+}
+
+// APICall records a Luno API call, sending sent bytes, that got status back.
+// A status of zero means no response arrived.
+func (s *Session) APICall(sent int64, status int) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats.API.Calls++
+	s.stats.API.BytesSent += sent
+	if status == 0 || status >= 400 {
+		s.stats.API.Errors++
+	}
+	if status == 429 {
+		s.stats.RateLimits.LunoRejected++
+	}
+}
+
+// APIBytesReceived records n bytes of Luno API responses read
+func (s *Session) APIBytesReceived(n int64) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats.API.BytesReceived += n
+}
+
+// CacheLookup records a lookup in a cache, and whether it was served without
+// calling Luno
+func (s *Session) CacheLookup(hit bool) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if hit {
+		s.stats.Cache.Hits++
+	} else {
+		s.stats.Cache.Misses++
+	}
+}
+
+// CallRejected records a tool call refused by the concurrency limits
+func (s *Session) CallRejected() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats.RateLimits.CallsRejected++
+}
+
+// RepeatReused records a repeated tool call answered with the previous result,
+// which is also a cache hit
+func (s *Session) RepeatReused() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats.RateLimits.RepeatsReused++
+	s.stats.Cache.Hits++
+}
+
+// Traded records a successful trading request
+func (s *Session) Traded(t Trade) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch t {
+	case OrderPlaced:
+		s.stats.Trading.OrdersPlaced++
+	case OrderCancelled:
+		s.stats.Trading.OrdersCancelled++
+	case WithdrawalCreated:
+		s.stats.Trading.WithdrawalsCreated++
+	}
+}
+
+// Stats returns a copy of the session's stats
+func (s *Session) Stats() SessionStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := s.stats
+	out.ToolCalls = maps.Clone(s.stats.ToolCalls)
+	out.Started, out.LastCall = out.Started.UTC(), out.LastCall.UTC()
+	out.EstimatedTokens = EstimateTokens(out.ResponseBytes)
+	if lookups := out.Cache.Hits + out.Cache.Misses; lookups > 0 {
+		out.Cache.HitRate = float64(out.Cache.Hits) / float64(lookups)
+	}
+	return out
+}
+
+// touch marks the session as used now
+func (s *Session) touch(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats.LastCall = now
+}
+
+func (s *Session) lastCall() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats.LastCall
+}
+
+// Session returns the stats of the session with id, starting them if needed.
+// Sessions idle for longer than SessionIdleTTL are dropped.
+func (t *Tracker) Session(id string) *Session {
+	now := time.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	maps.DeleteFunc(t.sessions, func(_ string, s *Session) bool { return now.Sub(s.lastCall()) > SessionIdleTTL })
+	s, ok := t.sessions[id]
+	if !ok {
+		s = &Session{stats: SessionStats{SessionID: id, Started: now, ToolCalls: make(map[string]int64)}}
+		t.sessions[id] = s
+	}
+	s.touch(now)
+	return s
+}
+
+// Sessions returns the stats of the sessions active within SessionIdleTTL,
+// most recently active first
+func (t *Tracker) Sessions() []SessionStats {
+	now := time.Now()
+	t.mu.Lock()
+	sessions := slices.Collect(maps.Values(t.sessions))
+	t.mu.Unlock()
+	stats := make([]SessionStats, 0, len(sessions))
+	for _, s := range sessions {
+		if st := s.Stats(); now.Sub(st.LastCall) <= SessionIdleTTL {
+			stats = append(stats, st)
+		}
+	}
+	slices.SortFunc(stats, func(a, b SessionStats) int {
+		return cmp.Or(b.LastCall.Compare(a.LastCall), cmp.Compare(a.SessionID, b.SessionID))
+	})
+	return stats
+}
+
+// LookupSession returns the stats of the session with id, without starting
+// them or counting the lookup as activity
+func (t *Tracker) LookupSession(id string) (SessionStats, bool) {
+	t.mu.Lock()
+	s, ok := t.sessions[id]
+	t.mu.Unlock()
+	if !ok {
+		return SessionStats{}, false
+	}
+	st := s.Stats()
+	return st, time.Since(st.LastCall) <= SessionIdleTTL
+}
+
+type sessionKey struct{}
+
+// ContextWithSession returns a copy of ctx carrying s, for the code handling a
+// call to record to
+func ContextWithSession(ctx context.Context, s *Session) context.Context {
+	return context.WithValue(ctx, sessionKey{}, s)
+}
+
+// SessionFromContext returns the session stored in ctx, or nil
+func SessionFromContext(ctx context.Context) *Session {
+	s, _ := ctx.Value(sessionKey{}).(*Session)
+	return s
+}
@@ -0,0 +1,90 @@
+package usage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSession(t *testing.T) {
+	tracker := NewTracker()
+	s := tracker.Session("s1")
+	require.Same(t, s, tracker.Session("s1"))
+	require.NotSame(t, s, tracker.Session("s2"))
+
+	s.ToolCall("get_ticker", 100, false)
+	s.ToolCall("get_ticker", 200, true)
+	s.ToolCall("create_order", 50, false)
+	s.APICall(0, 200)
+	s.APICall(40, 429)
+	s.APICall(40, 0)
+	s.APIBytesReceived(1_000)
+	s.CacheLookup(true)
+	s.CacheLookup(false)
+	s.RepeatReused()
+	s.CallRejected()
+	s.Traded(OrderPlaced)
+	s.Traded(OrderCancelled)
+	s.Traded(WithdrawalCreated)
+
+	got := s.Stats()
+	assert.Equal(t, "s1", got.SessionID)
+	assert.False(t, got.Started.IsZero())
+	assert.Equal(t, map[string]int64{"get_ticker": 2, "create_order": 1}, got.ToolCalls)
+	assert.Equal(t, int64(3), got.Calls)
+	assert.Equal(t, int64(1), got.Errors)
+	assert.Equal(t, int64(350), got.ResponseBytes)
+	assert.Equal(t, int64(88), got.EstimatedTokens)
+	assert.Equal(t, APIStats{Calls: 3, Errors: 2, BytesSent: 80, BytesReceived: 1_000}, got.API)
+	assert.Equal(t, CacheStats{Hits: 2, Misses: 1, HitRate: 2.0 / 3}, got.Cache)
+	assert.Equal(t, RateLimits{LunoRejected: 1, CallsRejected: 1, RepeatsReused: 1}, got.RateLimits)
+	assert.Equal(t, TradingStats{OrdersPlaced: 1, OrdersCancelled: 1, WithdrawalsCreated: 1}, got.Trading)
+
+	got.ToolCalls["get_ticker"] = 10
+	assert.Equal(t, int64(2), s.Stats().ToolCalls["get_ticker"], "stats are a copy")
+
+	tracker.Reset()
+	assert.Same(t, s, tracker.Session("s1"), "reset keeps session stats")
+}
+
+func TestSessionExpiry(t *testing.T) {
+	tracker := NewTracker()
+	idle := tracker.Session("idle")
+	idle.touch(time.Now().Add(-SessionIdleTTL - time.Minute))
+
+	tracker.Session("active")
+	assert.NotSame(t, idle, tracker.Session("idle"), "idle sessions are dropped")
+}
+
+func TestTrackerSessions(t *testing.T) {
+	tracker := NewTracker()
+	tracker.Session("first").ToolCall("get_ticker", 10, false)
+	tracker.Session("second").touch(time.Now().Add(time.Minute))
+	tracker.Session("idle").touch(time.Now().Add(-SessionIdleTTL - time.Minute))
+
+	sessions := tracker.Sessions()
+	require.Len(t, sessions, 2)
+	assert.Equal(t, "second", sessions[0].SessionID)
+	assert.Equal(t, "first", sessions[1].SessionID)
+	assert.Equal(t, int64(1), sessions[1].Calls)
+
+	st, ok := tracker.LookupSession("first")
+	require.True(t, ok)
+	assert.Equal(t, int64(1), st.Calls)
+	_, ok = tracker.LookupSession("idle")
+	assert.False(t, ok)
+	_, ok = tracker.LookupSession("unknown")
+	assert.False(t, ok)
+}
+
+func TestSessionContext(t *testing.T) {
+	ctx := context.Background()
+	assert.Nil(t, SessionFromContext(ctx))
+	SessionFromContext(ctx).ToolCall("get_ticker", 10, false)
+
+	s := NewTracker().Session("s1")
+	assert.Same(t, s, SessionFromContext(ContextWithSession(ctx, s)))
+}
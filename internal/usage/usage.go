@@ -0,0 +1,111 @@
+// Package usage measures how the server is used: the size of tool calls and
+// their results, so that operators can find the tools that fill up the
+// model's context window, and what each MCP session did.
+package usage
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"time"
+)
+
+// bytesPerToken is roughly how many bytes of JSON and English text make up a
+// token for common tokenizers
+const bytesPerToken = 4
+
+// EstimateTokens returns a rough estimate of the tokens in n bytes of text
+func EstimateTokens(n int64) int64 {
+	return (n + bytesPerToken - 1) / bytesPerToken
+}
+
+// ToolStats are the totals of a tool's calls
+type ToolStats struct {
+	Tool   string `json:"tool"`
+	Calls  int64  `json:"calls"`
+	Errors int64  `json:"errors"`
+	// RequestBytes is the size of the calls' arguments as JSON
+	RequestBytes int64 `json:"request_bytes"`
+	// ResponseBytes is the size of the results' text before truncation
+	ResponseBytes      int64 `json:"response_bytes"`
+	AvgResponseBytes   int64 `json:"avg_response_bytes"`
+	MaxResponseBytes   int64 `json:"max_response_bytes"`
+	EstimatedTokens    int64 `json:"estimated_tokens"`
+	AvgEstimatedTokens int64 `json:"avg_estimated_tokens"`
+	MaxEstimatedTokens int64 `json:"max_estimated_tokens"`
+	// Truncated counts the results that were cut short for fetch_more
+	Truncated int64 `json:"truncated"`
+}
+
+// Call is the size of a single tool call
+type Call struct {
+	Tool          string
+	RequestBytes  int64
+	ResponseBytes int64
+	Error         bool
+	Truncated     bool
+}
+
+// Tracker adds up the size of tool calls by tool, and keeps the stats of
+// each session. It is safe for concurrent use.
+type Tracker struct {
+	mu       sync.Mutex
+	since    time.Time
+	tools    map[string]*ToolStats
+	sessions map[string]*Session
+}
+
+// NewTracker creates an empty Tracker
+func NewTracker() *Tracker {
+	return &Tracker{since: time.Now(), tools: make(map[string]*ToolStats), sessions: make(map[string]*Session)}
+}
+
+// Record adds c to the totals of its tool
+func (t *Tracker) Record(c Call) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s, ok := t.tools[c.Tool]
+	if !ok {
+		s = &ToolStats{Tool: c.Tool}
+		t.tools[c.Tool] = s
+	}
+	s.Calls++
+	if c.Error {
+		s.Errors++
+	}
+	if c.Truncated {
+		s.Truncated++
+	}
+	s.RequestBytes += c.RequestBytes
+	s.ResponseBytes += c.ResponseBytes
+	s.MaxResponseBytes = max(s.MaxResponseBytes, c.ResponseBytes)
+}
+
+// Stats returns the totals of every tool called since the Tracker was
+// created, largest total response first
+func (t *Tracker) Stats() (stats []ToolStats, since time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	stats = make([]ToolStats, 0, len(t.tools))
+	for _, s := range t.tools {
+		out := *s
+		out.AvgResponseBytes = out.ResponseBytes / out.Calls
+		out.EstimatedTokens = EstimateTokens(out.ResponseBytes)
+		out.AvgEstimatedTokens = EstimateTokens(out.AvgResponseBytes)
+		out.MaxEstimatedTokens = EstimateTokens(out.MaxResponseBytes)
+		stats = append(stats, out)
+	}
+	slices.SortFunc(stats, func(a, b ToolStats) int {
+		return cmp.Or(cmp.Compare(b.ResponseBytes, a.ResponseBytes), cmp.Compare(a.Tool, b.Tool))
+	})
+	return stats, t.since
+}
+
+// Reset clears the totals by tool and starts counting again. Session stats
+// are kept.
+func (t *Tracker) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.since = time.Now()
+	clear(t.tools)
+}
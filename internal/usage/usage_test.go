@@ -0,0 +1,56 @@
+package usage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	assert.Equal(t, int64(0), EstimateTokens(0))
+	assert.Equal(t, int64(1), EstimateTokens(1))
+	assert.Equal(t, int64(1), EstimateTokens(4))
+	assert.Equal(t, int64(250), EstimateTokens(1000))
+}
+
+func TestTracker(t *testing.T) {
+	tr := NewTracker()
+	tr.Record(Call{Tool: "get_ticker", RequestBytes: 20, ResponseBytes: 400})
+	tr.Record(Call{Tool: "list_trades", RequestBytes: 20, ResponseBytes: 30_000, Truncated: true})
+	tr.Record(Call{Tool: "list_trades", RequestBytes: 40, ResponseBytes: 10_000})
+	tr.Record(Call{Tool: "get_ticker", RequestBytes: 20, ResponseBytes: 100, Error: true})
+
+	stats, since := tr.Stats()
+	require.Len(t, stats, 2)
+	assert.WithinDuration(t, time.Now(), since, time.Minute)
+	assert.Equal(t, ToolStats{
+		Tool:               "list_trades",
+		Calls:              2,
+		RequestBytes:       60,
+		ResponseBytes:      40_000,
+		AvgResponseBytes:   20_000,
+		MaxResponseBytes:   30_000,
+		EstimatedTokens:    10_000,
+		AvgEstimatedTokens: 5_000,
+		MaxEstimatedTokens: 7_500,
+		Truncated:          1,
+	}, stats[0])
+	assert.Equal(t, ToolStats{
+		Tool:               "get_ticker",
+		Calls:              2,
+		Errors:             1,
+		RequestBytes:       40,
+		ResponseBytes:      500,
+		AvgResponseBytes:   250,
+		MaxResponseBytes:   400,
+		EstimatedTokens:    125,
+		AvgEstimatedTokens: 63,
+		MaxEstimatedTokens: 100,
+	}, stats[1])
+
+	tr.Reset()
+	stats, _ = tr.Stats()
+	assert.Empty(t, stats)
+}
@@ -0,0 +1,306 @@
+// Package validate checks the arguments of tool calls against the tool's input
+// schema, so that every tool rejects missing, mistyped and out of range
+// parameters before its handler runs, and reports them the same way.
+//
+// The schema keywords checked are required, type, enum, minimum, maximum,
+// exclusiveMinimum, exclusiveMaximum, minLength, maxLength and pattern, plus
+// the decimal format and maxDecimalPlaces set with Decimal and MaxDecimals.
+// Arguments are read leniently, as models often quote numbers or leave them
+// unquoted: numeric strings are accepted for numbers, numbers for strings,
+// and "true" and "false" for booleans. They are converted to the declared
+// type, and enum values to the case the schema gives them in, so handlers see
+// what the schema promises.
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/luno/luno-go/decimal"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// FormatDecimal is the format of string properties holding decimal numbers
+const FormatDecimal = "decimal"
+
+// Decimal requires a string property to hold a decimal number, such as an
+// amount or price. Bounds such as mcp.Min apply to its value.
+func Decimal() mcp.PropertyOption {
+	return func(schema map[string]any) {
+		schema["format"] = FormatDecimal
+	}
+}
+
+// MaxDecimals limits a decimal property to places decimal places
+func MaxDecimals(places int) mcp.PropertyOption {
+	return func(schema map[string]any) {
+		schema["format"] = FormatDecimal
+		schema["maxDecimalPlaces"] = places
+	}
+}
+
+// ExclusiveMin requires a number or decimal property to be more than min
+func ExclusiveMin(min float64) mcp.PropertyOption {
+	return func(schema map[string]any) {
+		schema["exclusiveMinimum"] = min
+	}
+}
+
+// ExclusiveMax requires a number or decimal property to be less than max
+func ExclusiveMax(max float64) mcp.PropertyOption {
+	return func(schema map[string]any) {
+		schema["exclusiveMaximum"] = max
+	}
+}
+
+// Error is a problem with one parameter
+type Error struct {
+	Param   string `json:"param"`
+	Message string `json:"message"`
+}
+
+// Errors are the problems with a tool call's arguments
+type Errors []Error
+
+func (e Errors) Error() string {
+	parts := make([]string, len(e))
+	for i, err := range e {
+		parts[i] = err.Param + " " + err.Message
+	}
+	return "Invalid parameters: " + strings.Join(parts, "; ")
+}
+
+// Result returns the tool error result reporting e, with the errors also
+// given as structured content
+func (e Errors) Result() *mcp.CallToolResult {
+	result := mcp.NewToolResultError(e.Error())
+	result.StructuredContent = map[string]any{"validation_errors": e}
+	return result
+}
+
+// Arguments checks args against schema. It returns the arguments converted to
+// the types the schema declares, and the problems found, in parameter order.
+// Arguments that are null or empty strings are left out, as if not given.
+// args itself is not modified.
+func Arguments(schema mcp.ToolInputSchema, args map[string]any) (map[string]any, Errors) {
+	out := maps.Clone(args)
+	if out == nil {
+		out = map[string]any{}
+	}
+	var errs Errors
+	for _, name := range slices.Sorted(maps.Keys(schema.Properties)) {
+		prop, _ := schema.Properties[name].(map[string]any)
+		v, present := out[name]
+		if !present || v == nil || v == "" {
+			if slices.Contains(schema.Required, name) {
+				errs = append(errs, Error{Param: name, Message: "is required"})
+			}
+			// Empty arguments are taken as not given
+			delete(out, name)
+			continue
+		}
+		converted, msg := check(prop, v)
+		if msg != "" {
+			errs = append(errs, Error{Param: name, Message: msg})
+			continue
+		}
+		out[name] = converted
+	}
+	for _, name := range schema.Required {
+		if _, declared := schema.Properties[name]; !declared && out[name] == nil {
+			errs = append(errs, Error{Param: name, Message: "is required"})
+		}
+	}
+	return out, errs
+}
+
+// check converts v to the type of prop and checks it against prop's
+// constraints, returning a message describing the problem if there is one
+func check(prop map[string]any, v any) (any, string) {
+	switch prop["type"] {
+	case "string":
+		s, ok := toString(v)
+		if !ok {
+			return nil, "must be a string"
+		}
+		if enum := stringList(prop["enum"]); len(enum) > 0 {
+			i := slices.IndexFunc(enum, func(e string) bool { return strings.EqualFold(e, strings.TrimSpace(s)) })
+			if i < 0 {
+				return nil, fmt.Sprintf("must be one of %s, got %q", strings.Join(enum, ", "), s)
+			}
+			s = enum[i]
+		}
+		if prop["format"] == FormatDecimal {
+			s = strings.TrimSpace(s)
+		}
+		return s, checkString(prop, s)
+	case "number", "integer":
+		n, ok := toNumber(v)
+		if !ok {
+			return nil, "must be a number"
+		}
+		if prop["type"] == "integer" && n != float64(int64(n)) {
+			return nil, "must be a whole number"
+		}
+		return n, checkBounds(prop, toDecimal(n), formatNumber(n))
+	case "boolean":
+		switch b := v.(type) {
+		case bool:
+			return b, ""
+		case string:
+			if parsed, err := strconv.ParseBool(strings.TrimSpace(b)); err == nil {
+				return parsed, ""
+			}
+		}
+		return nil, "must be true or false"
+	case "array":
+		if _, ok := v.([]any); !ok {
+			return nil, "must be a list"
+		}
+	case "object":
+		if _, ok := v.(map[string]any); !ok {
+			return nil, "must be an object"
+		}
+	}
+	return v, ""
+}
+
+func checkString(prop map[string]any, s string) string {
+	if n, ok := number(prop["minLength"]); ok && float64(len([]rune(s))) < n {
+		return fmt.Sprintf("must be at least %s characters", formatNumber(n))
+	}
+	if n, ok := number(prop["maxLength"]); ok && float64(len([]rune(s))) > n {
+		return fmt.Sprintf("must be at most %s characters", formatNumber(n))
+	}
+	if pattern, ok := prop["pattern"].(string); ok {
+		re, err := regexp.Compile(pattern)
+		if err == nil && !re.MatchString(s) {
+			return fmt.Sprintf("%q is not in the expected format", s)
+		}
+	}
+	if prop["format"] == FormatDecimal {
+		d, err := decimal.NewFromString(s)
+		if err != nil {
+			return fmt.Sprintf("must be a decimal number such as 0.25, got %q", s)
+		}
+		if places, ok := number(prop["maxDecimalPlaces"]); ok && float64(decimalPlaces(d)) > places {
+			return fmt.Sprintf("must have at most %s decimal places, got %q", formatNumber(places), s)
+		}
+		return checkBounds(prop, d, s)
+	}
+	return ""
+}
+
+// checkBounds checks d, written as shown, against the minimum and maximum
+// keywords of prop
+func checkBounds(prop map[string]any, d decimal.Decimal, shown string) string {
+	bound := func(key string) (decimal.Decimal, string, bool) {
+		n, ok := number(prop[key])
+		if !ok {
+			return decimal.Decimal{}, "", false
+		}
+		return toDecimal(n), formatNumber(n), true
+	}
+	if min, s, ok := bound("minimum"); ok && d.Cmp(min) < 0 {
+		return fmt.Sprintf("must be at least %s, got %s", s, shown)
+	}
+	if min, s, ok := bound("exclusiveMinimum"); ok && d.Cmp(min) <= 0 {
+		return fmt.Sprintf("must be more than %s, got %s", s, shown)
+	}
+	if max, s, ok := bound("maximum"); ok && d.Cmp(max) > 0 {
+		return fmt.Sprintf("must be at most %s, got %s", s, shown)
+	}
+	if max, s, ok := bound("exclusiveMaximum"); ok && d.Cmp(max) >= 0 {
+		return fmt.Sprintf("must be less than %s, got %s", s, shown)
+	}
+	return ""
+}
+
+// toString reads a string argument. Numbers are written out in full, and
+// lists and objects as JSON, for parameters that take JSON.
+func toString(v any) (string, bool) {
+	switch s := v.(type) {
+	case string:
+		return s, true
+	case float64:
+		return formatNumber(s), true
+	case json.Number:
+		return s.String(), true
+	case []any, map[string]any:
+		data, err := json.Marshal(s)
+		return string(data), err == nil
+	}
+	return "", false
+}
+
+// toNumber reads a number argument, given as a number or a numeric string.
+// NaN and infinities, which ParseFloat accepts, are not numbers here.
+func toNumber(v any) (float64, bool) {
+	if s, ok := v.(string); ok {
+		n, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		return n, err == nil && !math.IsNaN(n) && !math.IsInf(n, 0)
+	}
+	return number(v)
+}
+
+func number(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func stringList(v any) []string {
+	switch l := v.(type) {
+	case []string:
+		return l
+	case []any:
+		out := make([]string, 0, len(l))
+		for _, e := range l {
+			if s, ok := e.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// toDecimal converts n exactly as it is written by formatNumber
+func toDecimal(n float64) decimal.Decimal {
+	d, err := decimal.NewFromString(formatNumber(n))
+	if err != nil {
+		// Infinities and NaN aren't valid JSON, so they can't be arguments
+		return decimal.Zero()
+	}
+	return d
+}
+
+func formatNumber(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
+
+// decimalPlaces returns the number of decimal places d is written with,
+// ignoring trailing zeros
+func decimalPlaces(d decimal.Decimal) int {
+	s := d.String()
+	i := strings.IndexByte(s, '.')
+	if i < 0 {
+		return 0
+	}
+	return len(strings.TrimRight(s[i+1:], "0"))
+}
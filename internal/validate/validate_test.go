@@ -0,0 +1,149 @@
+package validate
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/luno/luno-go/decimal"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var schema = mcp.NewTool("test",
+	mcp.WithString("pair", mcp.Required(), mcp.Pattern(`^[A-Z]+$`)),
+	mcp.WithString("side", mcp.Enum("BUY", "SELL")),
+	mcp.WithString("amount", MaxDecimals(2), ExclusiveMin(0)),
+	mcp.WithString("price", Decimal(), mcp.Min(1), mcp.Max(1000)),
+	mcp.WithString("note", mcp.MinLength(2), mcp.MaxLength(5)),
+	mcp.WithNumber("limit", mcp.Min(1), mcp.Max(100)),
+	mcp.WithNumber("percent", ExclusiveMin(0), ExclusiveMax(100)),
+	mcp.WithBoolean("confirm"),
+	mcp.WithArray("pairs"),
+	mcp.WithObject("options"),
+).InputSchema
+
+func TestArguments(t *testing.T) {
+	args := map[string]any{
+		"pair":    "XBTZAR",
+		"side":    " buy",
+		"amount":  "10.50",
+		"price":   " 999.99 ",
+		"note":    "hello",
+		"limit":   "25",
+		"percent": 99.5,
+		"confirm": "true",
+		"pairs":   []any{"XBTZAR"},
+		"other":   "kept",
+	}
+	got, errs := Arguments(schema, args)
+	require.Empty(t, errs)
+	assert.Equal(t, map[string]any{
+		"pair":    "XBTZAR",
+		"side":    "BUY",
+		"amount":  "10.50",
+		"price":   "999.99",
+		"note":    "hello",
+		"limit":   float64(25),
+		"percent": 99.5,
+		"confirm": true,
+		"pairs":   []any{"XBTZAR"},
+		"other":   "kept",
+	}, got)
+	assert.Equal(t, "25", args["limit"], "the arguments given are not changed")
+
+	got, errs = Arguments(schema, map[string]any{"pair": "XBTZAR", "amount": 5.5, "limit": 3.0})
+	require.Empty(t, errs)
+	assert.Equal(t, "5.5", got["amount"], "numbers are accepted for strings")
+}
+
+func TestArgumentsErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args map[string]any
+		want Errors
+	}{
+		{name: "missing", args: nil, want: Errors{{Param: "pair", Message: "is required"}}},
+		{name: "empty", args: map[string]any{"pair": ""}, want: Errors{{Param: "pair", Message: "is required"}}},
+		{name: "pattern", args: map[string]any{"pair": "xbt/zar"}, want: Errors{{Param: "pair", Message: `"xbt/zar" is not in the expected format`}}},
+		{name: "enum", args: map[string]any{"pair": "XBTZAR", "side": "HOLD"}, want: Errors{{Param: "side", Message: `must be one of BUY, SELL, got "HOLD"`}}},
+		{name: "not a decimal", args: map[string]any{"pair": "XBTZAR", "amount": "ten"}, want: Errors{{Param: "amount", Message: `must be a decimal number such as 0.25, got "ten"`}}},
+		{name: "decimal places", args: map[string]any{"pair": "XBTZAR", "amount": "1.234"}, want: Errors{{Param: "amount", Message: `must have at most 2 decimal places, got "1.234"`}}},
+		{name: "exclusive minimum", args: map[string]any{"pair": "XBTZAR", "amount": "0"}, want: Errors{{Param: "amount", Message: "must be more than 0, got 0"}}},
+		{name: "decimal maximum", args: map[string]any{"pair": "XBTZAR", "price": "1000.01"}, want: Errors{{Param: "price", Message: "must be at most 1000, got 1000.01"}}},
+		{name: "too short", args: map[string]any{"pair": "XBTZAR", "note": "a"}, want: Errors{{Param: "note", Message: "must be at least 2 characters"}}},
+		{name: "too long", args: map[string]any{"pair": "XBTZAR", "note": "abcdef"}, want: Errors{{Param: "note", Message: "must be at most 5 characters"}}},
+		{name: "not a number", args: map[string]any{"pair": "XBTZAR", "limit": "many"}, want: Errors{{Param: "limit", Message: "must be a number"}}},
+		{name: "not a finite number", args: map[string]any{"pair": "XBTZAR", "limit": "Inf"}, want: Errors{{Param: "limit", Message: "must be a number"}}},
+		{name: "minimum", args: map[string]any{"pair": "XBTZAR", "limit": 0}, want: Errors{{Param: "limit", Message: "must be at least 1, got 0"}}},
+		{name: "exclusive maximum", args: map[string]any{"pair": "XBTZAR", "percent": 100}, want: Errors{{Param: "percent", Message: "must be less than 100, got 100"}}},
+		{name: "boolean", args: map[string]any{"pair": "XBTZAR", "confirm": "yes please"}, want: Errors{{Param: "confirm", Message: "must be true or false"}}},
+		{name: "array", args: map[string]any{"pair": "XBTZAR", "pairs": "XBTZAR"}, want: Errors{{Param: "pairs", Message: "must be a list"}}},
+		{name: "object", args: map[string]any{"pair": "XBTZAR", "options": []any{}}, want: Errors{{Param: "options", Message: "must be an object"}}},
+		{
+			name: "several",
+			args: map[string]any{"limit": 500, "side": "buy", "percent": -1},
+			want: Errors{
+				{Param: "limit", Message: "must be at most 100, got 500"},
+				{Param: "pair", Message: "is required"},
+				{Param: "percent", Message: "must be more than 0, got -1"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, errs := Arguments(schema, tt.args)
+			assert.Equal(t, tt.want, errs)
+		})
+	}
+}
+
+func FuzzArguments(f *testing.F) {
+	for _, seed := range []string{
+		"0.25", " 10.50 ", "1e3", "NaN", "-Inf", "0x1p-2", "1_000", "½", "٣", "💯", "true",
+		"1." + strings.Repeat("9", 10000),
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		args := map[string]any{"pair": s, "side": s, "amount": s, "price": s, "note": s, "limit": s, "percent": s, "confirm": s}
+		got, errs := Arguments(schema, args)
+		failed := map[string]bool{}
+		for _, err := range errs {
+			failed[err.Param] = true
+		}
+		for _, name := range []string{"amount", "price"} {
+			if v, ok := got[name]; ok && !failed[name] {
+				if _, err := decimal.NewFromString(v.(string)); err != nil {
+					t.Errorf("%s %q was accepted as %q, which is not a decimal", name, s, v)
+				}
+			}
+		}
+		for _, name := range []string{"limit", "percent"} {
+			if v, ok := got[name]; ok && !failed[name] {
+				if n := v.(float64); math.IsNaN(n) || math.IsInf(n, 0) {
+					t.Errorf("%s %q was accepted as %v", name, s, n)
+				}
+			}
+		}
+		// Handlers decode the arguments from JSON
+		if len(errs) == 0 {
+			_, err := json.Marshal(got)
+			require.NoError(t, err)
+		}
+	})
+}
+
+func TestErrorsResult(t *testing.T) {
+	errs := Errors{{Param: "limit", Message: "must be at least 1, got 0"}, {Param: "pair", Message: "is required"}}
+	assert.EqualError(t, errs, "Invalid parameters: limit must be at least 1, got 0; pair is required")
+
+	result := errs.Result()
+	assert.True(t, result.IsError)
+	require.Len(t, result.Content, 1)
+	assert.Equal(t, errs.Error(), result.Content[0].(mcp.TextContent).Text)
+	assert.Equal(t, map[string]any{"validation_errors": errs}, result.StructuredContent)
+}
@@ -0,0 +1,151 @@
+package vault
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/luno/luno-mcp/internal/fsutil"
+)
+
+// Where the storage key comes from
+const (
+	ModeOff        = "off"
+	ModePassphrase = "passphrase"
+	ModeKeychain   = "keychain"
+)
+
+// KeyFileName is the name of the file describing the storage key, kept
+// alongside the encrypted files
+const KeyFileName = "storage-key.json"
+
+// MinPassphraseLength is the shortest passphrase accepted
+const MinPassphraseLength = 12
+
+// passphraseIterations is the PBKDF2-SHA256 work factor for new key files.
+// Replaced in tests.
+var passphraseIterations = 600_000
+
+// checkText is sealed into the key file to tell a wrong key from damaged data
+const checkText = "luno-mcp storage key"
+
+// keyFile describes how the storage key is derived. It holds no secrets.
+type keyFile struct {
+	Version    int    `json:"version"`
+	Mode       string `json:"mode"`
+	KDF        string `json:"kdf,omitempty"`
+	Iterations int    `json:"iterations,omitempty"`
+	Salt       []byte `json:"salt,omitempty"`
+	// Check is checkText sealed with the key
+	Check string `json:"check"`
+}
+
+// FromPassphrase returns a Cipher with a key derived from passphrase. The key
+// file at keyPath holds the salt, and is created with a new one the first time.
+func FromPassphrase(passphrase, keyPath string) (*Cipher, error) {
+	if len(passphrase) < MinPassphraseLength {
+		return nil, fmt.Errorf("the storage passphrase must be at least %d characters", MinPassphraseLength)
+	}
+	kf, err := readKeyFile(keyPath, ModePassphrase)
+	if err != nil {
+		return nil, err
+	}
+	if kf == nil {
+		kf = &keyFile{Version: 1, Mode: ModePassphrase, KDF: "pbkdf2-sha256", Iterations: passphraseIterations, Salt: make([]byte, 16)}
+		_, _ = rand.Read(kf.Salt)
+	}
+	key, err := pbkdf2.Key(sha256.New, passphrase, kf.Salt, kf.Iterations, KeySize)
+	if err != nil {
+		return nil, err
+	}
+	c, err := checkKey(kf, key, keyPath)
+	if errors.Is(err, ErrWrongKey) {
+		return nil, errors.New("wrong storage passphrase")
+	}
+	return c, err
+}
+
+// FromKeychain returns a Cipher with the key kept in kc, creating a new key the
+// first time. The key file at keyPath records that the key is in use, so that
+// a key missing from the keychain later is reported rather than replaced.
+func FromKeychain(kc Keychain, keyPath string) (*Cipher, error) {
+	kf, err := readKeyFile(keyPath, ModeKeychain)
+	if err != nil {
+		return nil, err
+	}
+	key, err := kc.Get()
+	switch {
+	case errors.Is(err, ErrNotFound) && kf == nil:
+		key = make([]byte, KeySize)
+		_, _ = rand.Read(key)
+		if err := kc.Set(key); err != nil {
+			return nil, fmt.Errorf("storing the storage key in the keychain: %w", err)
+		}
+		kf = &keyFile{Version: 1, Mode: ModeKeychain}
+		return checkKey(kf, key, keyPath)
+	case errors.Is(err, ErrNotFound):
+		return nil, fmt.Errorf("the storage key is missing from the keychain, so the files encrypted with it can't be read; remove %s and the encrypted files to start again", keyPath)
+	case err != nil:
+		return nil, fmt.Errorf("reading the storage key from the keychain: %w", err)
+	}
+	if kf == nil {
+		// A key left in the keychain from before is used again
+		kf = &keyFile{Version: 1, Mode: ModeKeychain}
+	}
+	c, err := checkKey(kf, key, keyPath)
+	if errors.Is(err, ErrWrongKey) {
+		return nil, errors.New("the storage key in the keychain is not the one the files were encrypted with")
+	}
+	return c, err
+}
+
+// readKeyFile reads the key file at path, or returns nil if there is none
+func readKeyFile(path, mode string) (*keyFile, error) {
+	if path == "" {
+		return nil, errors.New("there is no directory to keep the storage key file in; set LUNO_MCP_DATA_DIR")
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading storage key file: %w", err)
+	}
+	var kf keyFile
+	if err := json.Unmarshal(data, &kf); err != nil {
+		return nil, fmt.Errorf("reading storage key file %s: %w", path, err)
+	}
+	if kf.Mode != mode {
+		return nil, fmt.Errorf("the files were encrypted with STORAGE_ENCRYPTION=%s, not %s", kf.Mode, mode)
+	}
+	return &kf, nil
+}
+
+// checkKey returns a Cipher for key, checking it against kf. A key file
+// without a check is completed and written to path.
+func checkKey(kf *keyFile, key []byte, path string) (*Cipher, error) {
+	c, err := New(key)
+	if err != nil {
+		return nil, err
+	}
+	if kf.Check != "" {
+		if _, err := c.Open([]byte(kf.Check)); err != nil {
+			return nil, err
+		}
+		return c, nil
+	}
+
+	kf.Check = string(c.Seal([]byte(checkText)))
+	data, err := json.MarshalIndent(kf, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := fsutil.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return nil, fmt.Errorf("writing storage key file: %w", err)
+	}
+	return c, nil
+}
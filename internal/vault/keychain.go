@@ -0,0 +1,109 @@
+package vault
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// The keychain item holding the storage key
+const (
+	keychainService = "luno-mcp"
+	keychainAccount = "storage-key"
+)
+
+// ErrNotFound is returned by Keychain.Get when there is no storage key
+var ErrNotFound = errors.New("no storage key in the keychain")
+
+// Keychain keeps the storage key in the OS's secret store
+type Keychain interface {
+	// Get returns the storage key, or ErrNotFound
+	Get() ([]byte, error)
+	// Set stores the storage key
+	Set(key []byte) error
+}
+
+// SystemKeychain returns the keychain of the OS: the login keychain on macOS
+// and the Secret Service, such as GNOME Keyring or KWallet, through
+// secret-tool on Linux
+func SystemKeychain() (Keychain, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		return macKeychain{}, nil
+	case "linux", "freebsd", "openbsd", "netbsd":
+		if _, err := exec.LookPath("secret-tool"); err != nil {
+			return nil, errors.New("STORAGE_ENCRYPTION=keychain needs secret-tool (libsecret-tools); install it or use STORAGE_ENCRYPTION=passphrase")
+		}
+		return secretToolKeychain{}, nil
+	default:
+		return nil, fmt.Errorf("STORAGE_ENCRYPTION=keychain is not supported on %s; use STORAGE_ENCRYPTION=passphrase", runtime.GOOS)
+	}
+}
+
+// macKeychain keeps the key in the macOS login keychain with security(1)
+type macKeychain struct{}
+
+// macItemNotFound is the exit status of security when there is no such item
+const macItemNotFound = 44
+
+func (macKeychain) Get() ([]byte, error) {
+	out, err := exec.Command("security", "find-generic-password", "-s", keychainService, "-a", keychainAccount, "-w").Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == macItemNotFound {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, commandError(err)
+	}
+	return decodeKey(out)
+}
+
+func (macKeychain) Set(key []byte) error {
+	// security only takes the password as an argument
+	err := exec.Command("security", "add-generic-password", "-U", "-s", keychainService, "-a", keychainAccount,
+		"-l", "Luno MCP storage key", "-w", base64.StdEncoding.EncodeToString(key)).Run()
+	return commandError(err)
+}
+
+// secretToolKeychain keeps the key in the Secret Service with secret-tool(1)
+type secretToolKeychain struct{}
+
+func (secretToolKeychain) Get() ([]byte, error) {
+	out, err := exec.Command("secret-tool", "lookup", "service", keychainService, "account", keychainAccount).Output()
+	// secret-tool exits with 1 and prints nothing when there is no such item
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(bytes.TrimSpace(out)) == 0 && len(bytes.TrimSpace(exitErr.Stderr)) == 0 {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, commandError(err)
+	}
+	return decodeKey(out)
+}
+
+func (secretToolKeychain) Set(key []byte) error {
+	cmd := exec.Command("secret-tool", "store", "--label=Luno MCP storage key", "service", keychainService, "account", keychainAccount)
+	cmd.Stdin = strings.NewReader(base64.StdEncoding.EncodeToString(key))
+	return commandError(cmd.Run())
+}
+
+func decodeKey(out []byte) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(out)))
+	if err != nil || len(key) != KeySize {
+		return nil, errors.New("the storage key in the keychain is not valid")
+	}
+	return key, nil
+}
+
+// commandError adds what a keychain command wrote to stderr to its error
+func commandError(err error) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(bytes.TrimSpace(exitErr.Stderr)) > 0 {
+		return fmt.Errorf("%w: %s", err, bytes.TrimSpace(exitErr.Stderr))
+	}
+	return err
+}
@@ -0,0 +1,102 @@
+// Package vault encrypts the files the server keeps locally that hold account
+// IDs, notes about trades and trading history. Files are sealed with AES-256-GCM
+// under a key derived from a passphrase or kept in the OS keychain.
+//
+// A sealed file, or line of a JSON-lines file, is a text line starting with
+// "luno-mcp-encrypted:v1:". Data without the prefix is read as it is, so files
+// written before encryption was enabled can still be read and are sealed when
+// they are next written.
+package vault
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// prefix marks sealed data
+const prefix = "luno-mcp-encrypted:v1:"
+
+// KeySize is the length of storage keys in bytes
+const KeySize = 32
+
+var (
+	// ErrEncrypted is returned when reading sealed data without a key
+	ErrEncrypted = errors.New("the file is encrypted; set STORAGE_ENCRYPTION to read it")
+	// ErrWrongKey is returned when sealed data can't be opened with the key
+	ErrWrongKey = errors.New("the file was encrypted with a different key, or has been modified")
+)
+
+// Cipher seals and opens data with a storage key. A nil Cipher leaves data
+// as it is.
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// New returns a Cipher for a KeySize byte key
+func New(key []byte) (*Cipher, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("storage key must be %d bytes, got %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Cipher{aead: aead}, nil
+}
+
+// Enabled reports whether c encrypts data
+func (c *Cipher) Enabled() bool {
+	return c != nil
+}
+
+// Seal encrypts plaintext into a single line of text, without a newline
+func (c *Cipher) Seal(plaintext []byte) []byte {
+	if c == nil {
+		return plaintext
+	}
+	nonce := make([]byte, c.aead.NonceSize())
+	_, _ = rand.Read(nonce)
+	sealed := c.aead.Seal(nonce, nonce, plaintext, nil)
+
+	out := make([]byte, len(prefix)+base64.RawStdEncoding.EncodedLen(len(sealed)))
+	copy(out, prefix)
+	base64.RawStdEncoding.Encode(out[len(prefix):], sealed)
+	return out
+}
+
+// Open decrypts data written by Seal. Data that was not sealed is returned as
+// it is.
+func (c *Cipher) Open(data []byte) ([]byte, error) {
+	data = bytes.TrimSpace(data)
+	if !IsSealed(data) {
+		return data, nil
+	}
+	if c == nil {
+		return nil, ErrEncrypted
+	}
+	sealed := make([]byte, base64.RawStdEncoding.DecodedLen(len(data)-len(prefix)))
+	n, err := base64.RawStdEncoding.Decode(sealed, data[len(prefix):])
+	if err != nil || n < c.aead.NonceSize() {
+		return nil, ErrWrongKey
+	}
+	nonce, ciphertext := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():n]
+	plaintext, err := c.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, ErrWrongKey
+	}
+	return plaintext, nil
+}
+
+// IsSealed reports whether data was written by Seal
+func IsSealed(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(data), []byte(prefix))
+}
@@ -0,0 +1,131 @@
+package vault
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	passphraseIterations = 1000
+}
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, KeySize)
+}
+
+func TestCipher(t *testing.T) {
+	c, err := New(testKey(1))
+	require.NoError(t, err)
+	plaintext := []byte(`[{"account_id":"1234567890"}]`)
+
+	sealed := c.Seal(plaintext)
+	assert.True(t, IsSealed(sealed))
+	assert.NotContains(t, string(sealed), "1234567890")
+	assert.NotContains(t, string(sealed), "\n")
+	assert.NotEqual(t, sealed, c.Seal(plaintext), "every seal has its own nonce")
+
+	opened, err := c.Open(append(sealed, '\n'))
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, opened)
+
+	opened, err = c.Open(plaintext)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, opened, "data written before encryption is read as it is")
+
+	other, err := New(testKey(2))
+	require.NoError(t, err)
+	_, err = other.Open(sealed)
+	assert.ErrorIs(t, err, ErrWrongKey)
+
+	tampered := bytes.Clone(sealed)
+	tampered[len(tampered)-2] ^= 'A' ^ 'B'
+	_, err = c.Open(tampered)
+	assert.ErrorIs(t, err, ErrWrongKey)
+
+	var none *Cipher
+	assert.Equal(t, plaintext, none.Seal(plaintext))
+	_, err = none.Open(sealed)
+	assert.ErrorIs(t, err, ErrEncrypted)
+
+	_, err = New([]byte("short"))
+	assert.EqualError(t, err, "storage key must be 32 bytes, got 5")
+}
+
+func TestFromPassphrase(t *testing.T) {
+	keyPath := filepath.Join(t.TempDir(), KeyFileName)
+	const passphrase = "correct horse battery staple"
+
+	c, err := FromPassphrase(passphrase, keyPath)
+	require.NoError(t, err)
+	sealed := c.Seal([]byte("notes"))
+
+	var kf keyFile
+	data, err := os.ReadFile(keyPath)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &kf))
+	assert.Equal(t, ModePassphrase, kf.Mode)
+	assert.Len(t, kf.Salt, 16)
+	assert.NotContains(t, string(data), passphrase)
+
+	again, err := FromPassphrase(passphrase, keyPath)
+	require.NoError(t, err)
+	opened, err := again.Open(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "notes", string(opened))
+
+	_, err = FromPassphrase("wrong horse battery staple", keyPath)
+	assert.EqualError(t, err, "wrong storage passphrase")
+	_, err = FromPassphrase("short", keyPath)
+	assert.EqualError(t, err, "the storage passphrase must be at least 12 characters")
+	_, err = FromKeychain(&fakeKeychain{}, keyPath)
+	assert.EqualError(t, err, "the files were encrypted with STORAGE_ENCRYPTION=passphrase, not keychain")
+	_, err = FromPassphrase(passphrase, "")
+	assert.ErrorContains(t, err, "set LUNO_MCP_DATA_DIR")
+}
+
+type fakeKeychain struct {
+	key []byte
+}
+
+func (k *fakeKeychain) Get() ([]byte, error) {
+	if k.key == nil {
+		return nil, ErrNotFound
+	}
+	return k.key, nil
+}
+
+func (k *fakeKeychain) Set(key []byte) error {
+	k.key = key
+	return nil
+}
+
+func TestFromKeychain(t *testing.T) {
+	keyPath := filepath.Join(t.TempDir(), KeyFileName)
+	kc := &fakeKeychain{}
+
+	c, err := FromKeychain(kc, keyPath)
+	require.NoError(t, err)
+	require.Len(t, kc.key, KeySize, "a new key is stored in the keychain")
+	data, err := os.ReadFile(keyPath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "salt")
+
+	again, err := FromKeychain(kc, keyPath)
+	require.NoError(t, err)
+	opened, err := again.Open(c.Seal([]byte("orders")))
+	require.NoError(t, err)
+	assert.Equal(t, "orders", string(opened))
+
+	_, err = FromKeychain(&fakeKeychain{key: testKey(3)}, keyPath)
+	assert.EqualError(t, err, "the storage key in the keychain is not the one the files were encrypted with")
+
+	_, err = FromKeychain(&fakeKeychain{}, keyPath)
+	assert.ErrorContains(t, err, "the storage key is missing from the keychain")
+	assert.FileExists(t, keyPath, "the key file is kept")
+}
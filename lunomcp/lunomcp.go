@@ -0,0 +1,251 @@
+// Package lunomcp lets Go programs embed the Luno MCP server in-process.
+//
+// Load a configuration with LoadConfig and pass it to NewServer:
+//
+//	cfg, err := lunomcp.LoadConfig(lunomcp.WithCredentials(keyID, secret))
+//	if err != nil {
+//		return err
+//	}
+//	srv, err := lunomcp.NewServer(cfg, lunomcp.WithToolsets(lunomcp.ToolsetMarket))
+//	if err != nil {
+//		return err
+//	}
+//
+// The returned *server.MCPServer can be served with any mcp-go transport or
+// have further tools added to it.
+package lunomcp
+
+import (
+	"context"
+	"errors"
+
+	"github.com/luno/luno-mcp/internal/config"
+	"github.com/luno/luno-mcp/internal/events"
+	"github.com/luno/luno-mcp/internal/orders"
+	"github.com/luno/luno-mcp/internal/reference"
+	internalserver "github.com/luno/luno-mcp/internal/server"
+	"github.com/luno/luno-mcp/internal/tenants"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Default server name and version reported to MCP clients
+const (
+	DefaultName    = "luno-mcp"
+	DefaultVersion = "embedded"
+)
+
+// Toolsets that can be passed to WithToolsets and RegisterToolsets
+const (
+	ToolsetMarket       = internalserver.ToolsetMarket
+	ToolsetAccount      = internalserver.ToolsetAccount
+	ToolsetTrading      = internalserver.ToolsetTrading
+	ToolsetTransactions = internalserver.ToolsetTransactions
+	ToolsetExports      = internalserver.ToolsetExports
+	ToolsetWithdrawals  = internalserver.ToolsetWithdrawals
+	ToolsetReports      = internalserver.ToolsetReports
+)
+
+// Config is the server configuration, built with LoadConfig
+type Config = config.Config
+
+// ConfigOption configures how LoadConfig builds a Config
+type ConfigOption = config.Option
+
+// Overlay overrides parts of a Config for a single MCP session, see Config.Sessions
+type Overlay = config.Overlay
+
+// CredentialsSource returns the Luno API key ID and secret
+type CredentialsSource = config.CredentialsSource
+
+// ReferenceSource returns the external prices get_price_premium compares Luno
+// prices with, see WithReferenceSource
+type ReferenceSource = reference.Source
+
+// ReferenceSourceFunc adapts a function to a ReferenceSource
+type ReferenceSourceFunc = reference.SourceFunc
+
+// ReferencePair identifies the market a reference price is requested for
+type ReferencePair = reference.Pair
+
+// ReferenceQuote is a reference price in the pair's counter currency
+type ReferenceQuote = reference.Quote
+
+// CredentialsProvider returns the Luno API credentials of a tenant, see
+// WithCredentialsProvider
+type CredentialsProvider = tenants.Provider
+
+// CredentialsProviderFunc adapts a function to a CredentialsProvider
+type CredentialsProviderFunc = tenants.ProviderFunc
+
+// TenantCredentials are a tenant's Luno API key
+type TenantCredentials = tenants.Credentials
+
+// ErrUnknownTenant is returned by a CredentialsProvider for a tenant it has
+// no credentials for
+var ErrUnknownTenant = tenants.ErrUnknownTenant
+
+// Options for LoadConfig. See the config package for details.
+var (
+	WithDomain                    = config.WithDomain
+	WithAppInfo                   = config.WithAppInfo
+	WithHTTPClient                = config.WithHTTPClient
+	WithCredentials               = config.WithCredentials
+	WithCredentialsSource         = config.WithCredentialsSource
+	WithDebug                     = config.WithDebug
+	WithAllowWriteOperations      = config.WithAllowWriteOperations
+	WithAllowWithdrawals          = config.WithAllowWithdrawals
+	WithHideUnavailableTools      = config.WithHideUnavailableTools
+	WithLocale                    = config.WithLocale
+	WithWebhook                   = config.WithWebhook
+	WithMaxResponseBytes          = config.WithMaxResponseBytes
+	WithMaxPriceDeviation         = config.WithMaxPriceDeviation
+	WithDuplicateOrderWindow      = config.WithDuplicateOrderWindow
+	WithMaxConcurrentCalls        = config.WithMaxConcurrentCalls
+	WithMaxConcurrentCallsPerTool = config.WithMaxConcurrentCallsPerTool
+	WithCallQueueTimeout          = config.WithCallQueueTimeout
+	WithRepeatCallThreshold       = config.WithRepeatCallThreshold
+	WithRepeatCallWindow          = config.WithRepeatCallWindow
+	WithTickersRefreshInterval    = config.WithTickersRefreshInterval
+	WithMiddleware                = config.WithMiddleware
+	WithTransport                 = config.WithTransport
+	WithReferencePriceURL         = config.WithReferencePriceURL
+	WithReferenceSource           = config.WithReferenceSource
+	WithTradeJournalPath          = config.WithTradeJournalPath
+	WithAccountAliasesPath        = config.WithAccountAliasesPath
+	WithTrackedOrdersPath         = config.WithTrackedOrdersPath
+	WithImportedTradesPath        = config.WithImportedTradesPath
+	WithCandleCacheDir            = config.WithCandleCacheDir
+	WithDepositAlerts             = config.WithDepositAlerts
+	WithTelemetryURL              = config.WithTelemetryURL
+	WithUpdateCheck               = config.WithUpdateCheck
+	WithTradingWindows            = config.WithTradingWindows
+	WithTradingPairs              = config.WithTradingPairs
+	WithChaos                     = config.WithChaos
+	WithAdmin                     = config.WithAdmin
+	WithCredentialsProviderURL    = config.WithCredentialsProviderURL
+	WithCredentialsProvider       = config.WithCredentialsProvider
+	WithTenantHeader              = config.WithTenantHeader
+	WithAccessTokens              = config.WithAccessTokens
+	WithApprovals                 = config.WithApprovals
+	WithApprovalCommand           = config.WithApprovalCommand
+	WithAuditLogPath              = config.WithAuditLogPath
+	WithAuditSigningKeyPath       = config.WithAuditSigningKeyPath
+)
+
+// LoadConfig builds a Config. Anything not set through opts is read from
+// the same environment variables the luno-mcp binary uses.
+func LoadConfig(opts ...ConfigOption) (*Config, error) {
+	return config.Load(opts...)
+}
+
+// Option configures NewServer
+type Option func(*serverOptions)
+
+type serverOptions struct {
+	name     string
+	version  string
+	toolsets []string
+	hooks    []*server.Hooks
+}
+
+// WithName sets the server name and version reported to MCP clients
+func WithName(name, version string) Option {
+	return func(o *serverOptions) {
+		o.name = name
+		o.version = version
+	}
+}
+
+// WithToolsets restricts the registered tools to the given toolsets (default: all toolsets)
+func WithToolsets(toolsets ...string) Option {
+	return func(o *serverOptions) {
+		o.toolsets = toolsets
+	}
+}
+
+// WithHooks adds MCP server hooks
+func WithHooks(hooks ...*server.Hooks) Option {
+	return func(o *serverOptions) {
+		o.hooks = append(o.hooks, hooks...)
+	}
+}
+
+// NewServer creates a Luno MCP server for cfg
+func NewServer(cfg *Config, opts ...Option) (*server.MCPServer, error) {
+	if cfg == nil {
+		return nil, errors.New("config is required")
+	}
+	if cfg.LunoClient == nil {
+		return nil, errors.New("config has no Luno client, use LoadConfig to create it")
+	}
+
+	o := serverOptions{
+		name:     DefaultName,
+		version:  DefaultVersion,
+		toolsets: internalserver.AllToolsets,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return internalserver.NewMCPServerWithToolsets(o.name, o.version, cfg, o.toolsets, o.hooks...)
+}
+
+// RegisterToolsets adds the tools in the given toolsets to an existing MCP server
+func RegisterToolsets(s *server.MCPServer, cfg *Config, toolsets ...string) error {
+	return internalserver.RegisterToolsets(s, cfg, toolsets...)
+}
+
+// WatchEvents polls the account and sends events to the configured webhook until
+// ctx is cancelled. It returns immediately when no webhook is configured or cfg
+// has no API credentials.
+func WatchEvents(ctx context.Context, cfg *Config) {
+	if !cfg.Events.Enabled() || !cfg.IsAuthenticated {
+		return
+	}
+	events.NewWatcher(cfg.LunoClient, cfg.Events).Run(ctx)
+}
+
+// WatchDeposits polls the account for the deposits selected with
+// WithDepositAlerts until ctx is cancelled, sending them to the clients of s and
+// the configured webhook. It returns immediately when no deposit alerts are set
+// or cfg has no API credentials.
+func WatchDeposits(ctx context.Context, cfg *Config, s *server.MCPServer) {
+	if len(cfg.DepositAlerts) == 0 || !cfg.IsAuthenticated {
+		return
+	}
+	events.NewDepositWatcher(cfg.LunoClient, cfg.Events, s, cfg.DepositAlerts).Run(ctx)
+}
+
+// WatchOrders checks the orders placed through the server, including those
+// placed before it restarted, against Luno until ctx is cancelled, telling the
+// clients of s when they fill or are cancelled. It returns immediately when cfg
+// has no API credentials.
+func WatchOrders(ctx context.Context, cfg *Config, s *server.MCPServer) {
+	if !cfg.IsAuthenticated || cfg.Orders == nil {
+		return
+	}
+	orders.NewMonitor(cfg.Orders, cfg.LunoClient, s).Run(ctx)
+}
+
+// ReportTelemetry sends the anonymous tool usage counts opted in to with
+// WithTelemetryURL once a day until ctx is cancelled, and what is left when it
+// is. It returns immediately when no telemetry URL is set.
+func ReportTelemetry(ctx context.Context, cfg *Config) {
+	if cfg.Telemetry == nil {
+		return
+	}
+	defer cfg.Telemetry.Close()
+	cfg.Telemetry.Run(ctx)
+}
+
+// ServeAdmin serves the admin API set up with WithAdmin, for inspecting and
+// revoking the sessions of s, changing concurrency limits and reading usage
+// totals, until ctx is cancelled. It returns immediately when no admin
+// address is set.
+func ServeAdmin(ctx context.Context, cfg *Config, s *server.MCPServer) error {
+	if cfg.AdminAddr == "" {
+		return nil
+	}
+	return internalserver.ServeAdmin(ctx, s, cfg)
+}
@@ -0,0 +1,86 @@
+package lunomcp
+
+import (
+	"testing"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-mcp/internal/tools"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewServer(t *testing.T) {
+	tests := []struct {
+		name          string
+		cfg           *Config
+		opts          []Option
+		expectedTools int
+		expectErr     bool
+	}{
+		{
+			name:          "all toolsets by default",
+			cfg:           &Config{LunoClient: luno.NewClient()},
+			expectedTools: 49,
+		},
+		{
+			name:          "market toolset only",
+			cfg:           &Config{LunoClient: luno.NewClient()},
+			opts:          []Option{WithName("embedded-test", "0.0.1"), WithToolsets(ToolsetMarket)},
+			expectedTools: 21,
+		},
+		{
+			name:      "nil config",
+			cfg:       nil,
+			expectErr: true,
+		},
+		{
+			name:      "config without client",
+			cfg:       &Config{},
+			expectErr: true,
+		},
+		{
+			name:      "unknown toolset",
+			cfg:       &Config{LunoClient: luno.NewClient()},
+			opts:      []Option{WithToolsets("unknown")},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			srv, err := NewServer(tc.cfg, tc.opts...)
+			if tc.expectErr {
+				require.Error(t, err)
+				require.Nil(t, srv)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, srv.ListTools(), tc.expectedTools)
+		})
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	cfg, err := LoadConfig(
+		WithCredentials("", ""),
+		WithDomain("api.staging.luno.com"),
+		WithAllowWriteOperations(true),
+	)
+	require.NoError(t, err)
+	require.False(t, cfg.IsAuthenticated)
+	require.True(t, cfg.AllowWriteOperations)
+	require.Equal(t, "api.staging.luno.com", cfg.Domain)
+
+	srv, err := NewServer(cfg, WithToolsets(ToolsetTrading))
+	require.NoError(t, err)
+	require.Contains(t, srv.ListTools(), tools.CreateOrderToolID)
+}
+
+func TestRegisterToolsets(t *testing.T) {
+	cfg := &Config{LunoClient: luno.NewClient()}
+	srv, err := NewServer(cfg, WithToolsets())
+	require.NoError(t, err)
+	require.Len(t, srv.ListTools(), 6)
+
+	require.NoError(t, RegisterToolsets(srv, cfg, ToolsetAccount))
+	require.Contains(t, srv.ListTools(), tools.GetBalancesToolID)
+}
@@ -0,0 +1,145 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/luno/luno-go"
+)
+
+// DefaultChaos is the ChaosConfig used for a chaos spec of "on"
+var DefaultChaos = ChaosConfig{MaxLatency: time.Second, RateLimitRate: 0.05, ErrorRate: 0.05}
+
+// errTooManyRequests matches the error luno-go returns for HTTP 429 responses
+var errTooManyRequests = errors.New("luno: too many requests")
+
+// chaosErrors are the transient server errors the Chaos middleware picks from
+var chaosErrors = []luno.Error{
+	{Code: "ErrInternal", Message: "internal server error"},
+	{Code: "ErrUnavailable", Message: "service temporarily unavailable"},
+	{Code: "ErrTimeout", Message: "upstream request timed out"},
+}
+
+// ChaosConfig is the latency and failures the Chaos middleware adds to calls
+type ChaosConfig struct {
+	// MinLatency and MaxLatency bound the delay added before each call
+	MinLatency time.Duration
+	MaxLatency time.Duration
+	// RateLimitRate is the share of calls, from 0 to 1, failed as if Luno
+	// had rate limited them
+	RateLimitRate float64
+	// ErrorRate is the share of calls, from 0 to 1, failed with a transient
+	// server error
+	ErrorRate float64
+}
+
+// ParseChaos parses a comma separated list of settings such as
+// "latency=200ms-2s,rate_limit=0.1,errors=0.05". Latency is a duration or a
+// range of them, and rate_limit and errors are the share of calls to fail.
+// A spec of "on" or "true" returns DefaultChaos.
+func ParseChaos(spec string) (ChaosConfig, error) {
+	switch strings.ToLower(strings.TrimSpace(spec)) {
+	case "on", "true", "1":
+		return DefaultChaos, nil
+	}
+	var c ChaosConfig
+	for part := range strings.SplitSeq(spec, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return ChaosConfig{}, fmt.Errorf("invalid chaos setting %q: want key=value", part)
+		}
+		var err error
+		switch strings.ToLower(key) {
+		case "latency":
+			c.MinLatency, c.MaxLatency, err = parseLatency(value)
+		case "rate_limit":
+			c.RateLimitRate, err = parseRate(value)
+		case "errors":
+			c.ErrorRate, err = parseRate(value)
+		default:
+			err = errors.New("unknown setting, want latency, rate_limit or errors")
+		}
+		if err != nil {
+			return ChaosConfig{}, fmt.Errorf("invalid chaos setting %q: %w", part, err)
+		}
+	}
+	if c.RateLimitRate+c.ErrorRate > 1 {
+		return ChaosConfig{}, errors.New("invalid chaos settings: rate_limit and errors add up to more than 1")
+	}
+	return c, nil
+}
+
+func parseLatency(s string) (time.Duration, time.Duration, error) {
+	from, to, isRange := strings.Cut(s, "-")
+	lo, err := time.ParseDuration(from)
+	if err != nil || lo < 0 {
+		return 0, 0, fmt.Errorf("invalid latency %q", from)
+	}
+	if !isRange {
+		return lo, lo, nil
+	}
+	hi, err := time.ParseDuration(to)
+	if err != nil || hi < lo {
+		return 0, 0, fmt.Errorf("invalid latency %q: want a duration no less than %s", to, lo)
+	}
+	return lo, hi, nil
+}
+
+func parseRate(s string) (float64, error) {
+	rate, err := strconv.ParseFloat(s, 64)
+	if err != nil || rate < 0 || rate > 1 {
+		return 0, fmt.Errorf("invalid rate %q: want a number from 0 to 1", s)
+	}
+	return rate, nil
+}
+
+// String returns the settings in the format read by ParseChaos
+func (c ChaosConfig) String() string {
+	latency := c.MinLatency.String()
+	if c.MaxLatency != c.MinLatency {
+		latency += "-" + c.MaxLatency.String()
+	}
+	return fmt.Sprintf("latency=%s,rate_limit=%g,errors=%g", latency, c.RateLimitRate, c.ErrorRate)
+}
+
+// Chaos delays calls and fails some of them before they are sent, with the
+// errors luno-go returns for rate limits and server errors, for testing how
+// agents and their prompts cope with a slow or unreliable API. Failed calls,
+// including writes, never reach Luno.
+func Chaos(cfg ChaosConfig) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (any, error) {
+			latency := cfg.MinLatency
+			if cfg.MaxLatency > cfg.MinLatency {
+				latency += rand.N(cfg.MaxLatency - cfg.MinLatency + 1)
+			}
+			if latency > 0 {
+				t := time.NewTimer(latency)
+				select {
+				case <-ctx.Done():
+					t.Stop()
+					return nil, ctx.Err()
+				case <-t.C:
+				}
+			}
+
+			roll := rand.Float64()
+			switch {
+			case roll < cfg.RateLimitRate:
+				slog.InfoContext(ctx, "Chaos: failing Luno API call as rate limited", "method", call.Method)
+				return nil, errTooManyRequests
+			case roll < cfg.RateLimitRate+cfg.ErrorRate:
+				err := chaosErrors[rand.N(len(chaosErrors))]
+				slog.InfoContext(ctx, "Chaos: failing Luno API call with a server error", "method", call.Method, "error", err.Code)
+				return nil, err
+			}
+			return next(ctx, call)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseChaos(t *testing.T) {
+	tests := map[string]ChaosConfig{
+		"on":                                   DefaultChaos,
+		"latency=250ms":                        {MinLatency: 250 * time.Millisecond, MaxLatency: 250 * time.Millisecond},
+		"latency=200ms-2s, rate_limit=0.1":     {MinLatency: 200 * time.Millisecond, MaxLatency: 2 * time.Second, RateLimitRate: 0.1},
+		"errors=0.05,rate_limit=0.2,latency=0": {RateLimitRate: 0.2, ErrorRate: 0.05},
+	}
+	for spec, expected := range tests {
+		c, err := ParseChaos(spec)
+		require.NoError(t, err, spec)
+		assert.Equal(t, expected, c, spec)
+	}
+	assert.Equal(t, "latency=0s-1s,rate_limit=0.05,errors=0.05", DefaultChaos.String())
+
+	errs := map[string]string{
+		"slow":                      `invalid chaos setting "slow": want key=value`,
+		"jitter=1s":                 `invalid chaos setting "jitter=1s": unknown setting, want latency, rate_limit or errors`,
+		"latency=2s-1s":             `invalid chaos setting "latency=2s-1s": invalid latency "1s": want a duration no less than 2s`,
+		"errors=1.5":                `invalid chaos setting "errors=1.5": invalid rate "1.5": want a number from 0 to 1`,
+		"errors=0.6,rate_limit=0.6": "invalid chaos settings: rate_limit and errors add up to more than 1",
+	}
+	for spec, expected := range errs {
+		_, err := ParseChaos(spec)
+		assert.EqualError(t, err, expected, spec)
+	}
+}
+
+func TestChaosFailures(t *testing.T) {
+	client := NewMockLunoClient(t)
+
+	_, err := Wrap(client, Chaos(ChaosConfig{RateLimitRate: 1})).GetTicker(context.Background(), &luno.GetTickerRequest{Pair: "XBTZAR"})
+	require.ErrorIs(t, err, errTooManyRequests)
+
+	_, err = Wrap(client, Chaos(ChaosConfig{ErrorRate: 1})).PostLimitOrder(context.Background(), &luno.PostLimitOrderRequest{Pair: "XBTZAR"})
+	var lunoErr luno.Error
+	require.True(t, errors.As(err, &lunoErr), "got %v", err)
+	assert.Contains(t, chaosErrors, lunoErr)
+}
+
+func TestChaosLatency(t *testing.T) {
+	client := NewMockLunoClient(t)
+	client.EXPECT().GetTicker(mock.Anything, mock.Anything).Return(&luno.GetTickerResponse{Pair: "XBTZAR"}, nil)
+
+	wrapped := Wrap(client, Chaos(ChaosConfig{MinLatency: 20 * time.Millisecond, MaxLatency: 30 * time.Millisecond}))
+	start := time.Now()
+	res, err := wrapped.GetTicker(context.Background(), &luno.GetTickerRequest{Pair: "XBTZAR"})
+	require.NoError(t, err)
+	assert.Equal(t, "XBTZAR", res.Pair)
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = Wrap(client, Chaos(ChaosConfig{MinLatency: time.Hour, MaxLatency: time.Hour})).GetTicker(ctx, &luno.GetTickerRequest{Pair: "XBTZAR"})
+	require.ErrorIs(t, err, context.Canceled)
+}
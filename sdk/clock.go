@@ -0,0 +1,93 @@
+package sdk
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultMaxClockSkew is how far the local clock can drift from Luno's before
+// ClockSkew warns
+const DefaultMaxClockSkew = 30 * time.Second
+
+// ClockSkew measures how far the local clock is from Luno's, using the Date
+// header of every Luno response, and warns when it drifts beyond a maximum.
+// A drifting clock can make authenticated requests fail and timestamps in
+// results misleading. A nil ClockSkew measures nothing. It is safe for
+// concurrent use.
+type ClockSkew struct {
+	max time.Duration
+	now func() time.Time
+
+	mu        sync.Mutex
+	skew      time.Duration
+	checkedAt time.Time
+	drifted   bool
+}
+
+// NewClockSkew returns a ClockSkew that warns when the clocks are more than max apart
+func NewClockSkew(max time.Duration) *ClockSkew {
+	return &ClockSkew{max: max, now: time.Now}
+}
+
+// Max returns the skew above which the clock is considered to have drifted
+func (c *ClockSkew) Max() time.Duration {
+	if c == nil {
+		return 0
+	}
+	return c.max
+}
+
+// Skew returns how far Luno's clock was ahead of the local clock, negative if
+// it was behind, and when that was last measured. The time is zero if no
+// response has been seen yet.
+func (c *ClockSkew) Skew() (time.Duration, time.Time) {
+	if c == nil {
+		return 0, time.Time{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.skew, c.checkedAt
+}
+
+// Drifted reports whether the last measured skew was beyond the maximum
+func (c *ClockSkew) Drifted() bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.drifted
+}
+
+// observe measures the skew from the Date header of res, warning when the
+// clock drifts beyond the maximum and noting when it is back in sync
+func (c *ClockSkew) observe(ctx context.Context, res *http.Response) {
+	if c == nil {
+		return
+	}
+	date, err := http.ParseTime(res.Header.Get("Date"))
+	if err != nil {
+		return
+	}
+
+	now := c.now()
+	// The Date header only has second precision
+	skew := date.Sub(now.Truncate(time.Second)).Round(time.Second)
+	drifted := c.max > 0 && (skew > c.max || skew < -c.max)
+
+	c.mu.Lock()
+	wasDrifted := c.drifted
+	c.skew, c.checkedAt, c.drifted = skew, now, drifted
+	c.mu.Unlock()
+
+	switch {
+	case drifted && !wasDrifted:
+		slog.WarnContext(ctx, "Local clock is out of sync with Luno; authenticated requests may fail until it is corrected",
+			slog.Duration("skew", skew), slog.Duration("max_skew", c.max))
+	case !drifted && wasDrifted:
+		slog.InfoContext(ctx, "Local clock is back in sync with Luno", slog.Duration("skew", skew))
+	}
+}
@@ -0,0 +1,83 @@
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClockSkew(t *testing.T) {
+	var buf bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	defer slog.SetDefault(defaultLogger)
+
+	lunoTime := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := NewClockSkew(30 * time.Second)
+
+	skew, checkedAt := c.Skew()
+	assert.Zero(t, skew)
+	assert.True(t, checkedAt.IsZero())
+
+	observe := func(local time.Time) {
+		t.Helper()
+		c.now = func() time.Time { return local }
+		res := &http.Response{Header: http.Header{"Date": {lunoTime.Format(http.TimeFormat)}}}
+		c.observe(context.Background(), res)
+	}
+
+	observe(lunoTime.Add(400 * time.Millisecond))
+	skew, checkedAt = c.Skew()
+	assert.Zero(t, skew, "sub-second differences are below the Date header's precision")
+	assert.Equal(t, lunoTime.Add(400*time.Millisecond), checkedAt)
+	assert.False(t, c.Drifted())
+
+	observe(lunoTime.Add(-45 * time.Second))
+	skew, _ = c.Skew()
+	assert.Equal(t, 45*time.Second, skew)
+	assert.True(t, c.Drifted())
+	assert.Contains(t, buf.String(), "Local clock is out of sync with Luno")
+
+	buf.Reset()
+	observe(lunoTime.Add(-50 * time.Second))
+	assert.Empty(t, buf.String(), "the warning is only logged when the clock starts drifting")
+
+	observe(lunoTime.Add(2 * time.Second))
+	skew, _ = c.Skew()
+	assert.Equal(t, -2*time.Second, skew)
+	assert.False(t, c.Drifted())
+	assert.Contains(t, buf.String(), "Local clock is back in sync with Luno")
+}
+
+func TestMCPRoundTripperClock(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat))
+	}))
+	defer ts.Close()
+
+	c := NewClockSkew(DefaultMaxClockSkew)
+	req, err := http.NewRequest(http.MethodGet, ts.URL, nil)
+	require.NoError(t, err)
+	res, err := (&MCPRoundTripper{Clock: c}).RoundTrip(req)
+	require.NoError(t, err)
+	_ = res.Body.Close()
+
+	skew, _ := c.Skew()
+	assert.InDelta(t, -time.Hour, skew, float64(2*time.Second))
+	assert.True(t, c.Drifted())
+}
+
+func TestClockSkewNil(t *testing.T) {
+	var c *ClockSkew
+	c.observe(context.Background(), &http.Response{Header: http.Header{"Date": {time.Now().Format(http.TimeFormat)}}})
+	_, checkedAt := c.Skew()
+	assert.True(t, checkedAt.IsZero())
+	assert.False(t, c.Drifted())
+}
@@ -0,0 +1,172 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrUnexpectedResponse is wrapped by errors for Luno responses that could not
+// be decoded, usually because the API changed shape
+var ErrUnexpectedResponse = errors.New("unexpected Luno API response format; the API may have changed and luno-mcp may need upgrading")
+
+// APINotice is a sign that the Luno API is changing under the server: a
+// deprecated endpoint, or a response that no longer decodes
+type APINotice struct {
+	// Endpoint is the HTTP method and path for deprecation notices, e.g.
+	// "GET /api/1/ticker", and the LunoClient method for decode errors
+	Endpoint string `json:"endpoint"`
+	// Deprecation and Sunset are the values of the response headers of the
+	// same name, see RFC 9745 and RFC 8594
+	Deprecation string `json:"deprecation,omitempty"`
+	Sunset      string `json:"sunset,omitempty"`
+	// Warning is the value of a Warning response header
+	Warning string `json:"warning,omitempty"`
+	// Error is the decode error of a response that no longer matches the client
+	Error    string    `json:"error,omitempty"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// Message describes the notice for an agent or user
+func (n APINotice) Message() string {
+	if n.Error != "" {
+		return fmt.Sprintf("Luno returned a %s response this server could not read (%s); the API may have changed and luno-mcp may need upgrading.", n.Endpoint, n.Error)
+	}
+	msg := "Luno reports " + n.Endpoint + " as deprecated"
+	if n.Sunset != "" {
+		msg += ", to be removed " + n.Sunset
+	}
+	if n.Warning != "" {
+		msg += " (" + n.Warning + ")"
+	}
+	return msg + "; results may change and luno-mcp may need upgrading."
+}
+
+// APICompat collects APINotices seen on Luno API calls. A nil APICompat
+// ignores them. It is safe for concurrent use.
+type APICompat struct {
+	mu      sync.Mutex
+	notices map[string]APINotice
+}
+
+// NewAPICompat creates an empty APICompat
+func NewAPICompat() *APICompat {
+	return &APICompat{notices: make(map[string]APINotice)}
+}
+
+// Notices returns every notice seen, ordered by endpoint
+func (c *APICompat) Notices() []APINotice {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	out := make([]APINotice, 0, len(c.notices))
+	for _, n := range c.notices {
+		out = append(out, n)
+	}
+	slices.SortFunc(out, func(a, b APINotice) int { return strings.Compare(a.Endpoint, b.Endpoint) })
+	return out
+}
+
+// record keeps n, logging it the first time its endpoint is seen, and adds it
+// to the notices collected for ctx
+func (c *APICompat) record(ctx context.Context, n APINotice) {
+	if col, ok := ctx.Value(apiNoticesKey{}).(*APINoticeCollector); ok {
+		col.add(n)
+	}
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	_, seen := c.notices[n.Endpoint]
+	c.notices[n.Endpoint] = n
+	c.mu.Unlock()
+
+	if !seen {
+		slog.WarnContext(ctx, "Luno API change detected", slog.String("notice", n.Message()))
+	}
+}
+
+// observe records a notice if res marks its endpoint as deprecated
+func (c *APICompat) observe(req *http.Request, res *http.Response) {
+	n := APINotice{
+		Deprecation: res.Header.Get("Deprecation"),
+		Sunset:      res.Header.Get("Sunset"),
+		Warning:     res.Header.Get("Warning"),
+	}
+	// Most responses have no notice, so only they pay for building one
+	if n.Deprecation == "" && n.Sunset == "" && n.Warning == "" {
+		return
+	}
+	n.Endpoint = req.Method + " " + req.URL.Path
+	n.LastSeen = time.Now().UTC()
+	c.record(req.Context(), n)
+}
+
+type apiNoticesKey struct{}
+
+// APINoticeCollector gathers the APINotices of the Luno calls made with one context
+type APINoticeCollector struct {
+	mu      sync.Mutex
+	notices []APINotice
+}
+
+// ContextWithAPINoticeCollector returns a copy of ctx that collects the
+// APINotices of Luno calls made with it
+func ContextWithAPINoticeCollector(ctx context.Context) (context.Context, *APINoticeCollector) {
+	col := &APINoticeCollector{}
+	return context.WithValue(ctx, apiNoticesKey{}, col), col
+}
+
+// Notices returns the notices collected, once per endpoint
+func (c *APINoticeCollector) Notices() []APINotice {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.notices)
+}
+
+func (c *APINoticeCollector) add(n APINotice) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if slices.ContainsFunc(c.notices, func(o APINotice) bool { return o.Endpoint == n.Endpoint }) {
+		return
+	}
+	c.notices = append(c.notices, n)
+}
+
+// DetectAPIChanges wraps errors decoding Luno responses in ErrUnexpectedResponse
+// and records them in compat, so that a change in the shape of the API is
+// reported as such rather than as an opaque JSON error
+func DetectAPIChanges(compat *APICompat) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (any, error) {
+			res, err := next(ctx, call)
+			if err == nil || !isDecodeError(err) {
+				return res, err
+			}
+			compat.record(ctx, APINotice{
+				Endpoint: call.Method,
+				Error:    err.Error(),
+				LastSeen: time.Now().UTC(),
+			})
+			return res, fmt.Errorf("%s: %w: %w", call.Method, ErrUnexpectedResponse, err)
+		}
+	}
+}
+
+// isDecodeError reports whether err came from decoding a response body
+func isDecodeError(err error) bool {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	return errors.As(err, &syntaxErr) || errors.As(err, &typeErr)
+}
@@ -0,0 +1,75 @@
+package sdk
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/luno/luno-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCompatClient(t *testing.T, handler http.HandlerFunc) (LunoClient, *APICompat) {
+	t.Helper()
+	ts := httptest.NewServer(handler)
+	t.Cleanup(ts.Close)
+
+	compat := NewAPICompat()
+	client := luno.NewClient()
+	client.SetBaseURL(ts.URL)
+	client.SetHTTPClient(&http.Client{Transport: &MCPRoundTripper{Compat: compat}})
+	return Wrap(client, DetectAPIChanges(compat)), compat
+}
+
+func TestAPICompatDeprecation(t *testing.T) {
+	client, compat := newCompatClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "@1767225600")
+		w.Header().Set("Sunset", "Wed, 01 Jul 2026 00:00:00 GMT")
+		_, _ = w.Write([]byte(`{"pair":"XBTZAR","last_trade":"1000000.00"}`))
+	})
+
+	ctx, collected := ContextWithAPINoticeCollector(context.Background())
+	_, err := client.GetTicker(ctx, &luno.GetTickerRequest{Pair: "XBTZAR"})
+	require.NoError(t, err)
+	_, err = client.GetTicker(ctx, &luno.GetTickerRequest{Pair: "XBTZAR"})
+	require.NoError(t, err)
+
+	notices := compat.Notices()
+	require.Len(t, notices, 1)
+	assert.Equal(t, "GET /api/1/ticker", notices[0].Endpoint)
+	assert.Equal(t, "@1767225600", notices[0].Deprecation)
+	assert.Contains(t, notices[0].Message(), "deprecated, to be removed Wed, 01 Jul 2026")
+	require.Len(t, collected.Notices(), 1, "each endpoint is collected once")
+	assert.Equal(t, "GET /api/1/ticker", collected.Notices()[0].Endpoint)
+}
+
+func TestDetectAPIChanges(t *testing.T) {
+	client, compat := newCompatClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"pair":{"base":"XBT","counter":"ZAR"}}`))
+	})
+
+	ctx, collected := ContextWithAPINoticeCollector(context.Background())
+	_, err := client.GetTicker(ctx, &luno.GetTickerRequest{Pair: "XBTZAR"})
+	require.ErrorIs(t, err, ErrUnexpectedResponse)
+	assert.Contains(t, err.Error(), "GetTicker: unexpected Luno API response format")
+
+	notices := compat.Notices()
+	require.Len(t, notices, 1)
+	assert.Equal(t, "GetTicker", notices[0].Endpoint)
+	assert.Contains(t, notices[0].Error, "cannot unmarshal")
+	assert.Len(t, collected.Notices(), 1)
+}
+
+func TestDetectAPIChangesOtherErrors(t *testing.T) {
+	client, compat := newCompatClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":"Market not found","error_code":"ErrMarketNotFound"}`))
+	})
+
+	_, err := client.GetTicker(context.Background(), &luno.GetTickerRequest{Pair: "FOOBAR"})
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrUnexpectedResponse)
+	assert.Empty(t, compat.Notices())
+}
@@ -0,0 +1,131 @@
+package sdk
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/luno/luno-mcp/internal/redact"
+)
+
+// maxDebugBodyBytes is how much of a request or response body HTTPDebug logs
+const maxDebugBodyBytes = 4096
+
+// HTTPDebug logs the Luno API requests made through an MCPRoundTripper at debug
+// level: method, path, status, latency and bodies, with credentials redacted.
+// It replaces luno-go's SetDebug, which prints raw traffic, and can be switched
+// on and off at runtime. A nil HTTPDebug is always off. It is safe for
+// concurrent use.
+type HTTPDebug struct {
+	// Logger receives the log records, slog.Default() if nil
+	Logger *slog.Logger
+
+	enabled atomic.Bool
+}
+
+// NewHTTPDebug returns an HTTPDebug that starts enabled or disabled
+func NewHTTPDebug(enabled bool) *HTTPDebug {
+	d := &HTTPDebug{}
+	d.enabled.Store(enabled)
+	return d
+}
+
+// Enabled reports whether requests are logged
+func (d *HTTPDebug) Enabled() bool {
+	return d != nil && d.enabled.Load()
+}
+
+// SetEnabled switches request logging on or off
+func (d *HTTPDebug) SetEnabled(enabled bool) {
+	if d != nil {
+		d.enabled.Store(enabled)
+	}
+}
+
+func (d *HTTPDebug) logger() *slog.Logger {
+	if d.Logger != nil {
+		return d.Logger
+	}
+	return slog.Default()
+}
+
+// roundTrip sends req with next and logs the exchange
+func (d *HTTPDebug) roundTrip(next http.RoundTripper, req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	path := req.URL.Path
+	if req.URL.RawQuery != "" {
+		path += "?" + redact.String(req.URL.RawQuery)
+	}
+	attrs := []any{"method", req.Method, "path", path}
+	if body := requestBody(req); body != "" {
+		attrs = append(attrs, "request_body", body)
+	}
+
+	start := time.Now()
+	res, err := next.RoundTrip(req)
+	latency := time.Since(start)
+	attrs = append(attrs, "latency", latency)
+	if err != nil {
+		attrs = append(attrs, "error", redact.Error(err))
+		d.logger().DebugContext(ctx, fmt.Sprintf("Luno API %s %s failed after %s", req.Method, req.URL.Path, latency.Round(time.Millisecond)), attrs...)
+		return nil, err
+	}
+
+	attrs = append(attrs, "status", res.StatusCode)
+	if body := responseBody(res); body != "" {
+		attrs = append(attrs, "response_body", body)
+	}
+	d.logger().DebugContext(ctx, fmt.Sprintf("Luno API %s %s: %s in %s", req.Method, req.URL.Path, res.Status, latency.Round(time.Millisecond)), attrs...)
+	return res, nil
+}
+
+// requestBody returns the start of req's body without consuming it. Bodies
+// that cannot be read again are not logged.
+func requestBody(req *http.Request) string {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody == nil {
+		return ""
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return ""
+	}
+	defer body.Close()
+	b, truncated := readPrefix(body)
+	return debugBody(b, truncated)
+}
+
+// responseBody returns the start of res's body, leaving the whole body for the
+// caller to read
+func responseBody(res *http.Response) string {
+	if res.Body == nil || res.Body == http.NoBody {
+		return ""
+	}
+	b, truncated := readPrefix(res.Body)
+	res.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(b), res.Body), res.Body}
+	return debugBody(b, truncated)
+}
+
+// readPrefix reads up to one byte more than maxDebugBodyBytes from r, reporting
+// whether there was more to read
+func readPrefix(r io.Reader) ([]byte, bool) {
+	b, _ := io.ReadAll(io.LimitReader(r, maxDebugBodyBytes+1))
+	return b, len(b) > maxDebugBodyBytes
+}
+
+func debugBody(b []byte, truncated bool) string {
+	if len(b) > maxDebugBodyBytes {
+		b = b[:maxDebugBodyBytes]
+	}
+	s := redact.String(string(b))
+	if truncated {
+		s += "... (truncated)"
+	}
+	return s
+}
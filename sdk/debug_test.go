@@ -0,0 +1,114 @@
+package sdk
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/luno/luno-mcp/internal/redact"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPDebug(t *testing.T) {
+	const secret = "debug-secret-0123456789"
+	redact.Register(secret)
+
+	response := `{"order_id":"BXMC2CJ7HNB88U4"}`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, response)
+	}))
+	defer ts.Close()
+
+	tests := []struct {
+		name     string
+		enabled  bool
+		expected []string
+	}{
+		{
+			name:    "disabled logs nothing",
+			enabled: false,
+		},
+		{
+			name:    "enabled logs the exchange",
+			enabled: true,
+			expected: []string{
+				`msg="Luno API POST /api/1/postorder: 200 OK in`,
+				"method=POST",
+				`path="/api/1/postorder?pair=XBTZAR"`,
+				"status=200",
+				"latency=",
+				`request_body="type=BID&volume=0.1&api_secret=[REDACTED]"`,
+				`response_body="{\"order_id\":\"BXMC2CJ7HNB88U4\"}"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			debug := NewHTTPDebug(tt.enabled)
+			debug.Logger = slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+			req, err := http.NewRequest(http.MethodPost, ts.URL+"/api/1/postorder?pair=XBTZAR",
+				strings.NewReader("type=BID&volume=0.1&api_secret="+secret))
+			require.NoError(t, err)
+			req.SetBasicAuth("key-id", secret)
+
+			res, err := (&MCPRoundTripper{Debug: debug}).RoundTrip(req)
+			require.NoError(t, err)
+			body, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			_ = res.Body.Close()
+
+			assert.Equal(t, response, string(body), "the caller still reads the whole response")
+			if len(tt.expected) == 0 {
+				assert.Empty(t, buf.String())
+			}
+			for _, s := range tt.expected {
+				assert.Contains(t, buf.String(), s)
+			}
+			assert.NotContains(t, buf.String(), secret)
+		})
+	}
+}
+
+func TestHTTPDebugTruncatesBodies(t *testing.T) {
+	response := strings.Repeat("a", maxDebugBodyBytes+100)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, response)
+	}))
+	defer ts.Close()
+
+	var buf bytes.Buffer
+	debug := NewHTTPDebug(true)
+	debug.Logger = slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	req, err := http.NewRequest(http.MethodGet, ts.URL, nil)
+	require.NoError(t, err)
+	res, err := (&MCPRoundTripper{Debug: debug}).RoundTrip(req)
+	require.NoError(t, err)
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	_ = res.Body.Close()
+
+	assert.Equal(t, response, string(body))
+	assert.Contains(t, buf.String(), strings.Repeat("a", maxDebugBodyBytes)+"... (truncated)")
+	assert.NotContains(t, buf.String(), strings.Repeat("a", maxDebugBodyBytes+1))
+}
+
+func TestHTTPDebugSwitch(t *testing.T) {
+	var nilDebug *HTTPDebug
+	assert.False(t, nilDebug.Enabled())
+	nilDebug.SetEnabled(true)
+
+	debug := NewHTTPDebug(false)
+	debug.SetEnabled(true)
+	assert.True(t, debug.Enabled())
+	debug.SetEnabled(false)
+	assert.False(t, debug.Enabled())
+}
@@ -6,24 +6,32 @@ import (
 	"github.com/luno/luno-go"
 )
 
-// compile-time check that *luno.Client implements our interface
 var _ LunoClient = (*luno.Client)(nil)
 
-// LunoClient defines the interface for Luno API operations
-// This interface allows us to mock the Luno client for testing
+// LunoClient is the subset of the Luno API used by the MCP server. It is
+// implemented by *luno.Client and by the middleware chain returned by Wrap.
 type LunoClient interface {
 	GetBalances(ctx context.Context, req *luno.GetBalancesRequest) (*luno.GetBalancesResponse, error)
 	GetTicker(ctx context.Context, req *luno.GetTickerRequest) (*luno.GetTickerResponse, error)
 	GetOrderBook(ctx context.Context, req *luno.GetOrderBookRequest) (*luno.GetOrderBookResponse, error)
+	GetOrder(ctx context.Context, req *luno.GetOrderRequest) (*luno.GetOrderResponse, error)
 	PostLimitOrder(ctx context.Context, req *luno.PostLimitOrderRequest) (*luno.PostLimitOrderResponse, error)
+	PostMarketOrder(ctx context.Context, req *luno.PostMarketOrderRequest) (*luno.PostMarketOrderResponse, error)
 	StopOrder(ctx context.Context, req *luno.StopOrderRequest) (*luno.StopOrderResponse, error)
 	ListOrders(ctx context.Context, req *luno.ListOrdersRequest) (*luno.ListOrdersResponse, error)
 	ListTransactions(ctx context.Context, req *luno.ListTransactionsRequest) (*luno.ListTransactionsResponse, error)
 	ListTrades(ctx context.Context, req *luno.ListTradesRequest) (*luno.ListTradesResponse, error)
+	ListUserTrades(ctx context.Context, req *luno.ListUserTradesRequest) (*luno.ListUserTradesResponse, error)
 	GetCandles(ctx context.Context, req *luno.GetCandlesRequest) (*luno.GetCandlesResponse, error)
 	GetTickers(ctx context.Context, req *luno.GetTickersRequest) (*luno.GetTickersResponse, error)
 	GetOrderBookFull(ctx context.Context, req *luno.GetOrderBookFullRequest) (*luno.GetOrderBookFullResponse, error)
 	Markets(ctx context.Context, req *luno.MarketsRequest) (*luno.MarketsResponse, error)
+	ListBeneficiaries(ctx context.Context, req *luno.ListBeneficiariesRequest) (*luno.ListBeneficiariesResponse, error)
+	CreateWithdrawal(ctx context.Context, req *luno.CreateWithdrawalRequest) (*luno.CreateWithdrawalResponse, error)
+	ListWithdrawals(ctx context.Context, req *luno.ListWithdrawalsRequest) (*luno.ListWithdrawalsResponse, error)
+	GetWithdrawal(ctx context.Context, req *luno.GetWithdrawalRequest) (*luno.GetWithdrawalResponse, error)
+	ListTransfers(ctx context.Context, req *luno.ListTransfersRequest) (*luno.ListTransfersResponse, error)
+	GetFeeInfo(ctx context.Context, req *luno.GetFeeInfoRequest) (*luno.GetFeeInfoResponse, error)
 	SetBaseURL(url string)
 	SetAuth(id, secret string) error
 	SetDebug(debug bool)
@@ -0,0 +1,49 @@
+package sdk
+
+import (
+	"context"
+	"log/slog"
+)
+
+// RequestIDHeader is the header MCPRoundTripper uses to send the request ID to Luno
+const RequestIDHeader = "X-Request-ID"
+
+// RequestMetadata identifies the MCP request that caused a Luno API call
+type RequestMetadata struct {
+	// RequestID is generated for every tool call
+	RequestID string
+	// SessionID is the MCP session the request arrived on
+	SessionID string
+	// ClientName and ClientVersion are reported by the MCP client when it initializes
+	ClientName    string
+	ClientVersion string
+}
+
+// LogAttrs returns the non-empty metadata fields as log attributes
+func (m RequestMetadata) LogAttrs() []slog.Attr {
+	var attrs []slog.Attr
+	for _, a := range []slog.Attr{
+		slog.String("request_id", m.RequestID),
+		slog.String("session_id", m.SessionID),
+		slog.String("client_name", m.ClientName),
+		slog.String("client_version", m.ClientVersion),
+	} {
+		if a.Value.String() != "" {
+			attrs = append(attrs, a)
+		}
+	}
+	return attrs
+}
+
+type requestMetadataKey struct{}
+
+// ContextWithRequestMetadata returns a copy of ctx carrying md
+func ContextWithRequestMetadata(ctx context.Context, md RequestMetadata) context.Context {
+	return context.WithValue(ctx, requestMetadataKey{}, md)
+}
+
+// RequestMetadataFromContext returns the metadata stored in ctx, if any
+func RequestMetadataFromContext(ctx context.Context) (RequestMetadata, bool) {
+	md, ok := ctx.Value(requestMetadataKey{}).(RequestMetadata)
+	return md, ok
+}
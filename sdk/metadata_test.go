@@ -0,0 +1,29 @@
+package sdk
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestMetadataContext(t *testing.T) {
+	_, ok := RequestMetadataFromContext(context.Background())
+	require.False(t, ok)
+
+	md := RequestMetadata{RequestID: "req-1", SessionID: "session-1", ClientName: "claude-ai"}
+	got, ok := RequestMetadataFromContext(ContextWithRequestMetadata(context.Background(), md))
+	require.True(t, ok)
+	require.Equal(t, md, got)
+}
+
+func TestRequestMetadataLogAttrs(t *testing.T) {
+	md := RequestMetadata{RequestID: "req-1", ClientName: "cursor"}
+	require.Equal(t, []slog.Attr{
+		slog.String("request_id", "req-1"),
+		slog.String("client_name", "cursor"),
+	}, md.LogAttrs())
+
+	require.Empty(t, RequestMetadata{}.LogAttrs())
+}
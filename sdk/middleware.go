@@ -0,0 +1,353 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/luno/luno-go"
+)
+
+// ErrDryRun is returned by the DryRun middleware instead of sending a write call to Luno
+var ErrDryRun = errors.New("dry run: request was not sent to Luno")
+
+// Call is a single LunoClient API call passing through a middleware chain
+type Call struct {
+	// Method is the LunoClient method name, e.g. "GetTicker"
+	Method string
+	// Request is the request passed to the method, e.g. *luno.GetTickerRequest
+	Request any
+	// Write is true for calls that change account state, e.g. placing an order
+	Write bool
+
+	do func(ctx context.Context) (any, error)
+}
+
+// Handler executes a Call and returns the method's response
+type Handler func(ctx context.Context, call *Call) (any, error)
+
+// Middleware wraps a Handler with cross-cutting behaviour
+type Middleware func(next Handler) Handler
+
+// writeMethods are the LunoClient methods that change account state
+var writeMethods = []string{"PostLimitOrder", "PostMarketOrder", "StopOrder", "CreateWithdrawal"}
+
+// Wrap returns a LunoClient that passes every API call through mws before
+// calling client. The first middleware is the outermost.
+func Wrap(client LunoClient, mws ...Middleware) LunoClient {
+	if len(mws) == 0 {
+		return client
+	}
+	h := Handler(func(ctx context.Context, call *Call) (any, error) {
+		return call.do(ctx)
+	})
+	for _, mw := range slices.Backward(mws) {
+		h = mw(h)
+	}
+	return &middlewareClient{LunoClient: client, handler: h}
+}
+
+// middlewareClient routes the API methods of the embedded LunoClient through a handler.
+// Configuration methods (SetBaseURL, SetAuth, SetDebug) go straight to the embedded client.
+type middlewareClient struct {
+	LunoClient
+	handler Handler
+}
+
+var _ LunoClient = (*middlewareClient)(nil)
+
+// invoke runs do through the middleware chain and converts the response back to T
+func invoke[T any](ctx context.Context, c *middlewareClient, method string, req any, do func(context.Context) (T, error)) (T, error) {
+	call := &Call{
+		Method:  method,
+		Request: req,
+		Write:   slices.Contains(writeMethods, method),
+		do: func(ctx context.Context) (any, error) {
+			return do(ctx)
+		},
+	}
+
+	var zero T
+	res, err := c.handler(ctx, call)
+	if err != nil || res == nil {
+		return zero, err
+	}
+	typed, ok := res.(T)
+	if !ok {
+		return zero, fmt.Errorf("middleware returned %T for %s, expected %T", res, method, zero)
+	}
+	return typed, nil
+}
+
+func (c *middlewareClient) GetBalances(ctx context.Context, req *luno.GetBalancesRequest) (*luno.GetBalancesResponse, error) {
+	return invoke(ctx, c, "GetBalances", req, func(ctx context.Context) (*luno.GetBalancesResponse, error) {
+		return c.LunoClient.GetBalances(ctx, req)
+	})
+}
+
+func (c *middlewareClient) GetTicker(ctx context.Context, req *luno.GetTickerRequest) (*luno.GetTickerResponse, error) {
+	return invoke(ctx, c, "GetTicker", req, func(ctx context.Context) (*luno.GetTickerResponse, error) {
+		return c.LunoClient.GetTicker(ctx, req)
+	})
+}
+
+func (c *middlewareClient) GetOrderBook(ctx context.Context, req *luno.GetOrderBookRequest) (*luno.GetOrderBookResponse, error) {
+	return invoke(ctx, c, "GetOrderBook", req, func(ctx context.Context) (*luno.GetOrderBookResponse, error) {
+		return c.LunoClient.GetOrderBook(ctx, req)
+	})
+}
+
+func (c *middlewareClient) GetOrder(ctx context.Context, req *luno.GetOrderRequest) (*luno.GetOrderResponse, error) {
+	return invoke(ctx, c, "GetOrder", req, func(ctx context.Context) (*luno.GetOrderResponse, error) {
+		return c.LunoClient.GetOrder(ctx, req)
+	})
+}
+
+func (c *middlewareClient) PostLimitOrder(ctx context.Context, req *luno.PostLimitOrderRequest) (*luno.PostLimitOrderResponse, error) {
+	return invoke(ctx, c, "PostLimitOrder", req, func(ctx context.Context) (*luno.PostLimitOrderResponse, error) {
+		return c.LunoClient.PostLimitOrder(ctx, req)
+	})
+}
+
+func (c *middlewareClient) PostMarketOrder(ctx context.Context, req *luno.PostMarketOrderRequest) (*luno.PostMarketOrderResponse, error) {
+	return invoke(ctx, c, "PostMarketOrder", req, func(ctx context.Context) (*luno.PostMarketOrderResponse, error) {
+		return c.LunoClient.PostMarketOrder(ctx, req)
+	})
+}
+
+func (c *middlewareClient) StopOrder(ctx context.Context, req *luno.StopOrderRequest) (*luno.StopOrderResponse, error) {
+	return invoke(ctx, c, "StopOrder", req, func(ctx context.Context) (*luno.StopOrderResponse, error) {
+		return c.LunoClient.StopOrder(ctx, req)
+	})
+}
+
+func (c *middlewareClient) ListOrders(ctx context.Context, req *luno.ListOrdersRequest) (*luno.ListOrdersResponse, error) {
+	return invoke(ctx, c, "ListOrders", req, func(ctx context.Context) (*luno.ListOrdersResponse, error) {
+		return c.LunoClient.ListOrders(ctx, req)
+	})
+}
+
+func (c *middlewareClient) ListTransactions(ctx context.Context, req *luno.ListTransactionsRequest) (*luno.ListTransactionsResponse, error) {
+	return invoke(ctx, c, "ListTransactions", req, func(ctx context.Context) (*luno.ListTransactionsResponse, error) {
+		return c.LunoClient.ListTransactions(ctx, req)
+	})
+}
+
+func (c *middlewareClient) ListTrades(ctx context.Context, req *luno.ListTradesRequest) (*luno.ListTradesResponse, error) {
+	return invoke(ctx, c, "ListTrades", req, func(ctx context.Context) (*luno.ListTradesResponse, error) {
+		return c.LunoClient.ListTrades(ctx, req)
+	})
+}
+
+func (c *middlewareClient) ListUserTrades(ctx context.Context, req *luno.ListUserTradesRequest) (*luno.ListUserTradesResponse, error) {
+	return invoke(ctx, c, "ListUserTrades", req, func(ctx context.Context) (*luno.ListUserTradesResponse, error) {
+		return c.LunoClient.ListUserTrades(ctx, req)
+	})
+}
+
+func (c *middlewareClient) GetCandles(ctx context.Context, req *luno.GetCandlesRequest) (*luno.GetCandlesResponse, error) {
+	return invoke(ctx, c, "GetCandles", req, func(ctx context.Context) (*luno.GetCandlesResponse, error) {
+		return c.LunoClient.GetCandles(ctx, req)
+	})
+}
+
+func (c *middlewareClient) GetTickers(ctx context.Context, req *luno.GetTickersRequest) (*luno.GetTickersResponse, error) {
+	return invoke(ctx, c, "GetTickers", req, func(ctx context.Context) (*luno.GetTickersResponse, error) {
+		return c.LunoClient.GetTickers(ctx, req)
+	})
+}
+
+func (c *middlewareClient) GetOrderBookFull(ctx context.Context, req *luno.GetOrderBookFullRequest) (*luno.GetOrderBookFullResponse, error) {
+	return invoke(ctx, c, "GetOrderBookFull", req, func(ctx context.Context) (*luno.GetOrderBookFullResponse, error) {
+		return c.LunoClient.GetOrderBookFull(ctx, req)
+	})
+}
+
+func (c *middlewareClient) Markets(ctx context.Context, req *luno.MarketsRequest) (*luno.MarketsResponse, error) {
+	return invoke(ctx, c, "Markets", req, func(ctx context.Context) (*luno.MarketsResponse, error) {
+		return c.LunoClient.Markets(ctx, req)
+	})
+}
+
+func (c *middlewareClient) ListBeneficiaries(ctx context.Context, req *luno.ListBeneficiariesRequest) (*luno.ListBeneficiariesResponse, error) {
+	return invoke(ctx, c, "ListBeneficiaries", req, func(ctx context.Context) (*luno.ListBeneficiariesResponse, error) {
+		return c.LunoClient.ListBeneficiaries(ctx, req)
+	})
+}
+
+func (c *middlewareClient) CreateWithdrawal(ctx context.Context, req *luno.CreateWithdrawalRequest) (*luno.CreateWithdrawalResponse, error) {
+	return invoke(ctx, c, "CreateWithdrawal", req, func(ctx context.Context) (*luno.CreateWithdrawalResponse, error) {
+		return c.LunoClient.CreateWithdrawal(ctx, req)
+	})
+}
+
+func (c *middlewareClient) ListWithdrawals(ctx context.Context, req *luno.ListWithdrawalsRequest) (*luno.ListWithdrawalsResponse, error) {
+	return invoke(ctx, c, "ListWithdrawals", req, func(ctx context.Context) (*luno.ListWithdrawalsResponse, error) {
+		return c.LunoClient.ListWithdrawals(ctx, req)
+	})
+}
+
+func (c *middlewareClient) GetWithdrawal(ctx context.Context, req *luno.GetWithdrawalRequest) (*luno.GetWithdrawalResponse, error) {
+	return invoke(ctx, c, "GetWithdrawal", req, func(ctx context.Context) (*luno.GetWithdrawalResponse, error) {
+		return c.LunoClient.GetWithdrawal(ctx, req)
+	})
+}
+
+func (c *middlewareClient) ListTransfers(ctx context.Context, req *luno.ListTransfersRequest) (*luno.ListTransfersResponse, error) {
+	return invoke(ctx, c, "ListTransfers", req, func(ctx context.Context) (*luno.ListTransfersResponse, error) {
+		return c.LunoClient.ListTransfers(ctx, req)
+	})
+}
+
+func (c *middlewareClient) GetFeeInfo(ctx context.Context, req *luno.GetFeeInfoRequest) (*luno.GetFeeInfoResponse, error) {
+	return invoke(ctx, c, "GetFeeInfo", req, func(ctx context.Context) (*luno.GetFeeInfoResponse, error) {
+		return c.LunoClient.GetFeeInfo(ctx, req)
+	})
+}
+
+// Logging logs every call with its duration at debug level, and failed calls at warn level.
+// A nil logger logs to slog.Default() at the time of the call.
+func Logging(logger *slog.Logger) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (any, error) {
+			logger := logger
+			if logger == nil {
+				logger = slog.Default()
+			}
+			start := time.Now()
+			res, err := next(ctx, call)
+			if err != nil {
+				logger.WarnContext(ctx, "Luno API call failed", "method", call.Method, "duration", time.Since(start), "error", err)
+			} else {
+				logger.DebugContext(ctx, "Luno API call", "method", call.Method, "duration", time.Since(start))
+			}
+			return res, err
+		}
+	}
+}
+
+// RateLimit waits for limiter before every call, e.g. a *rate.Limiter shared with other clients
+func RateLimit(limiter luno.Limiter) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (any, error) {
+			if err := limiter.Wait(ctx); err != nil {
+				return nil, err
+			}
+			return next(ctx, call)
+		}
+	}
+}
+
+// DryRun stops write calls from reaching Luno, returning ErrDryRun instead.
+// Read calls are passed through unchanged.
+func DryRun() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (any, error) {
+			if call.Write {
+				return nil, fmt.Errorf("%s: %w", call.Method, ErrDryRun)
+			}
+			return next(ctx, call)
+		}
+	}
+}
+
+type cacheEntry struct {
+	res     any
+	expires time.Time
+}
+
+// Cache caches successful responses of the given read methods for ttl, keyed by method and
+// request. Write calls are never cached. Cached responses are shared between callers and
+// must not be modified.
+func Cache(ttl time.Duration, methods ...string) Middleware {
+	var (
+		mu      sync.Mutex
+		entries = make(map[string]cacheEntry)
+	)
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (any, error) {
+			if call.Write || !slices.Contains(methods, call.Method) {
+				return next(ctx, call)
+			}
+
+			reqKey, err := json.Marshal(call.Request)
+			if err != nil {
+				return next(ctx, call)
+			}
+			key := call.Method + ":" + string(reqKey)
+
+			mu.Lock()
+			entry, ok := entries[key]
+			mu.Unlock()
+			if ok && time.Now().Before(entry.expires) {
+				return entry.res, nil
+			}
+
+			res, err := next(ctx, call)
+			if err != nil {
+				return res, err
+			}
+
+			mu.Lock()
+			entries[key] = cacheEntry{res: res, expires: time.Now().Add(ttl)}
+			mu.Unlock()
+			return res, nil
+		}
+	}
+}
+
+// MethodStats are the call counts and total latency for one LunoClient method
+type MethodStats struct {
+	Calls         int64         `json:"calls"`
+	Errors        int64         `json:"errors"`
+	TotalDuration time.Duration `json:"total_duration"`
+}
+
+// Metrics records per-method call statistics. The zero value is ready to use.
+type Metrics struct {
+	mu    sync.Mutex
+	stats map[string]MethodStats
+}
+
+// Middleware returns a Middleware that records calls in m
+func (m *Metrics) Middleware() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (any, error) {
+			start := time.Now()
+			res, err := next(ctx, call)
+			m.record(call.Method, time.Since(start), err)
+			return res, err
+		}
+	}
+}
+
+func (m *Metrics) record(method string, d time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stats == nil {
+		m.stats = make(map[string]MethodStats)
+	}
+	s := m.stats[method]
+	s.Calls++
+	s.TotalDuration += d
+	if err != nil {
+		s.Errors++
+	}
+	m.stats[method] = s
+}
+
+// Snapshot returns a copy of the statistics recorded so far, keyed by method
+func (m *Metrics) Snapshot() map[string]MethodStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make(map[string]MethodStats, len(m.stats))
+	for k, v := range m.stats {
+		out[k] = v
+	}
+	return out
+}
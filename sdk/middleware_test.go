@@ -0,0 +1,198 @@
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-go"
+	"github.com/luno/luno-go/decimal"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeLimiter struct {
+	waits int
+	err   error
+}
+
+func (l *fakeLimiter) Wait(context.Context) error {
+	l.waits++
+	return l.err
+}
+
+func TestWrapNoMiddleware(t *testing.T) {
+	client := NewMockLunoClient(t)
+	require.Same(t, client, Wrap(client))
+}
+
+func TestWrapOrder(t *testing.T) {
+	var order []string
+	record := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, call *Call) (any, error) {
+				order = append(order, name+":"+call.Method)
+				return next(ctx, call)
+			}
+		}
+	}
+
+	client := NewMockLunoClient(t)
+	client.EXPECT().GetTicker(mock.Anything, &luno.GetTickerRequest{Pair: "XBTZAR"}).
+		Return(&luno.GetTickerResponse{Pair: "XBTZAR", Bid: decimal.NewFromInt64(100)}, nil)
+
+	wrapped := Wrap(client, record("outer"), record("inner"))
+	res, err := wrapped.GetTicker(context.Background(), &luno.GetTickerRequest{Pair: "XBTZAR"})
+	require.NoError(t, err)
+	require.Equal(t, "XBTZAR", res.Pair)
+	require.Equal(t, []string{"outer:GetTicker", "inner:GetTicker"}, order)
+}
+
+func TestWrapPassesThroughConfiguration(t *testing.T) {
+	client := NewMockLunoClient(t)
+	client.EXPECT().SetBaseURL("https://api.staging.luno.com").Return()
+	client.EXPECT().SetDebug(true).Return()
+	client.EXPECT().SetAuth("id", "secret").Return(nil)
+
+	wrapped := Wrap(client, DryRun())
+	wrapped.SetBaseURL("https://api.staging.luno.com")
+	wrapped.SetDebug(true)
+	require.NoError(t, wrapped.SetAuth("id", "secret"))
+}
+
+func TestWrapUnexpectedResponseType(t *testing.T) {
+	client := NewMockLunoClient(t)
+	bad := func(Handler) Handler {
+		return func(context.Context, *Call) (any, error) {
+			return "not a ticker", nil
+		}
+	}
+
+	_, err := Wrap(client, bad).GetTicker(context.Background(), &luno.GetTickerRequest{Pair: "XBTZAR"})
+	require.ErrorContains(t, err, "middleware returned string for GetTicker")
+}
+
+func TestDryRun(t *testing.T) {
+	client := NewMockLunoClient(t)
+	client.EXPECT().ListOrders(mock.Anything, mock.Anything).Return(&luno.ListOrdersResponse{}, nil)
+	wrapped := Wrap(client, DryRun())
+
+	_, err := wrapped.PostLimitOrder(context.Background(), &luno.PostLimitOrderRequest{Pair: "XBTZAR"})
+	require.ErrorIs(t, err, ErrDryRun)
+
+	_, err = wrapped.StopOrder(context.Background(), &luno.StopOrderRequest{OrderId: "BXMC2CJ7HNB88U4"})
+	require.ErrorIs(t, err, ErrDryRun)
+
+	_, err = wrapped.ListOrders(context.Background(), &luno.ListOrdersRequest{})
+	require.NoError(t, err)
+}
+
+func TestRateLimit(t *testing.T) {
+	tests := []struct {
+		name       string
+		limiterErr error
+		expectErr  bool
+	}{
+		{
+			name: "waits before calling",
+		},
+		{
+			name:       "limiter error stops the call",
+			limiterErr: context.DeadlineExceeded,
+			expectErr:  true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client := NewMockLunoClient(t)
+			if !tc.expectErr {
+				client.EXPECT().GetBalances(mock.Anything, mock.Anything).Return(&luno.GetBalancesResponse{}, nil)
+			}
+			limiter := &fakeLimiter{err: tc.limiterErr}
+
+			_, err := Wrap(client, RateLimit(limiter)).GetBalances(context.Background(), &luno.GetBalancesRequest{})
+			require.Equal(t, 1, limiter.waits)
+			if tc.expectErr {
+				require.ErrorIs(t, err, tc.limiterErr)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCache(t *testing.T) {
+	client := NewMockLunoClient(t)
+	client.EXPECT().GetTickers(mock.Anything, mock.Anything).
+		Return(&luno.GetTickersResponse{Tickers: []luno.Ticker{{Pair: "XBTZAR"}}}, nil).Once()
+	client.EXPECT().GetTicker(mock.Anything, mock.Anything).
+		Return(&luno.GetTickerResponse{Pair: "XBTZAR"}, nil).Twice()
+	client.EXPECT().Markets(mock.Anything, mock.Anything).
+		Return(nil, errors.New("unavailable")).Twice()
+
+	wrapped := Wrap(client, Cache(time.Minute, "GetTickers", "Markets"))
+	ctx := context.Background()
+
+	for range 2 {
+		res, err := wrapped.GetTickers(ctx, &luno.GetTickersRequest{})
+		require.NoError(t, err)
+		require.Len(t, res.Tickers, 1)
+	}
+
+	// Methods that aren't listed are not cached
+	for range 2 {
+		_, err := wrapped.GetTicker(ctx, &luno.GetTickerRequest{Pair: "XBTZAR"})
+		require.NoError(t, err)
+	}
+
+	// Errors are not cached
+	for range 2 {
+		_, err := wrapped.Markets(ctx, &luno.MarketsRequest{})
+		require.Error(t, err)
+	}
+}
+
+func TestCacheExpiry(t *testing.T) {
+	client := NewMockLunoClient(t)
+	client.EXPECT().GetTickers(mock.Anything, mock.Anything).Return(&luno.GetTickersResponse{}, nil).Twice()
+
+	wrapped := Wrap(client, Cache(time.Millisecond, "GetTickers"))
+	_, err := wrapped.GetTickers(context.Background(), &luno.GetTickersRequest{})
+	require.NoError(t, err)
+	time.Sleep(5 * time.Millisecond)
+	_, err = wrapped.GetTickers(context.Background(), &luno.GetTickersRequest{})
+	require.NoError(t, err)
+}
+
+func TestMetrics(t *testing.T) {
+	client := NewMockLunoClient(t)
+	client.EXPECT().GetBalances(mock.Anything, mock.Anything).Return(&luno.GetBalancesResponse{}, nil).Once()
+	client.EXPECT().GetBalances(mock.Anything, mock.Anything).Return(nil, errors.New("boom")).Once()
+
+	var m Metrics
+	wrapped := Wrap(client, m.Middleware())
+	_, _ = wrapped.GetBalances(context.Background(), &luno.GetBalancesRequest{})
+	_, _ = wrapped.GetBalances(context.Background(), &luno.GetBalancesRequest{})
+
+	stats := m.Snapshot()
+	require.Len(t, stats, 1)
+	require.EqualValues(t, 2, stats["GetBalances"].Calls)
+	require.EqualValues(t, 1, stats["GetBalances"].Errors)
+}
+
+func TestLogging(t *testing.T) {
+	client := NewMockLunoClient(t)
+	client.EXPECT().ListTrades(mock.Anything, mock.Anything).Return(nil, errors.New("boom"))
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	_, err := Wrap(client, Logging(logger)).ListTrades(context.Background(), &luno.ListTradesRequest{Pair: "XBTZAR"})
+	require.Error(t, err)
+	require.Contains(t, buf.String(), "Luno API call failed")
+	require.Contains(t, buf.String(), "method=ListTrades")
+}
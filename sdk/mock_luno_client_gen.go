@@ -39,6 +39,74 @@ func (_m *MockLunoClient) EXPECT() *MockLunoClient_Expecter {
 	return &MockLunoClient_Expecter{mock: &_m.Mock}
 }
 
+// CreateWithdrawal provides a mock function for the type MockLunoClient
+func (_mock *MockLunoClient) CreateWithdrawal(ctx context.Context, req *luno.CreateWithdrawalRequest) (*luno.CreateWithdrawalResponse, error) {
+	ret := _mock.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateWithdrawal")
+	}
+
+	var r0 *luno.CreateWithdrawalResponse
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *luno.CreateWithdrawalRequest) (*luno.CreateWithdrawalResponse, error)); ok {
+		return returnFunc(ctx, req)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *luno.CreateWithdrawalRequest) *luno.CreateWithdrawalResponse); ok {
+		r0 = returnFunc(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*luno.CreateWithdrawalResponse)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *luno.CreateWithdrawalRequest) error); ok {
+		r1 = returnFunc(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockLunoClient_CreateWithdrawal_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateWithdrawal'
+type MockLunoClient_CreateWithdrawal_Call struct {
+	*mock.Call
+}
+
+// CreateWithdrawal is a helper method to define mock.On call
+//   - ctx context.Context
+//   - req *luno.CreateWithdrawalRequest
+func (_e *MockLunoClient_Expecter) CreateWithdrawal(ctx interface{}, req interface{}) *MockLunoClient_CreateWithdrawal_Call {
+	return &MockLunoClient_CreateWithdrawal_Call{Call: _e.mock.On("CreateWithdrawal", ctx, req)}
+}
+
+func (_c *MockLunoClient_CreateWithdrawal_Call) Run(run func(ctx context.Context, req *luno.CreateWithdrawalRequest)) *MockLunoClient_CreateWithdrawal_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *luno.CreateWithdrawalRequest
+		if args[1] != nil {
+			arg1 = args[1].(*luno.CreateWithdrawalRequest)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockLunoClient_CreateWithdrawal_Call) Return(createWithdrawalResponse *luno.CreateWithdrawalResponse, err error) *MockLunoClient_CreateWithdrawal_Call {
+	_c.Call.Return(createWithdrawalResponse, err)
+	return _c
+}
+
+func (_c *MockLunoClient_CreateWithdrawal_Call) RunAndReturn(run func(ctx context.Context, req *luno.CreateWithdrawalRequest) (*luno.CreateWithdrawalResponse, error)) *MockLunoClient_CreateWithdrawal_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetBalances provides a mock function for the type MockLunoClient
 func (_mock *MockLunoClient) GetBalances(ctx context.Context, req *luno.GetBalancesRequest) (*luno.GetBalancesResponse, error) {
 	ret := _mock.Called(ctx, req)
@@ -107,6 +175,210 @@ func (_c *MockLunoClient_GetBalances_Call) RunAndReturn(run func(ctx context.Con
 	return _c
 }
 
+// GetCandles provides a mock function for the type MockLunoClient
+func (_mock *MockLunoClient) GetCandles(ctx context.Context, req *luno.GetCandlesRequest) (*luno.GetCandlesResponse, error) {
+	ret := _mock.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCandles")
+	}
+
+	var r0 *luno.GetCandlesResponse
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *luno.GetCandlesRequest) (*luno.GetCandlesResponse, error)); ok {
+		return returnFunc(ctx, req)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *luno.GetCandlesRequest) *luno.GetCandlesResponse); ok {
+		r0 = returnFunc(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*luno.GetCandlesResponse)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *luno.GetCandlesRequest) error); ok {
+		r1 = returnFunc(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockLunoClient_GetCandles_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetCandles'
+type MockLunoClient_GetCandles_Call struct {
+	*mock.Call
+}
+
+// GetCandles is a helper method to define mock.On call
+//   - ctx context.Context
+//   - req *luno.GetCandlesRequest
+func (_e *MockLunoClient_Expecter) GetCandles(ctx interface{}, req interface{}) *MockLunoClient_GetCandles_Call {
+	return &MockLunoClient_GetCandles_Call{Call: _e.mock.On("GetCandles", ctx, req)}
+}
+
+func (_c *MockLunoClient_GetCandles_Call) Run(run func(ctx context.Context, req *luno.GetCandlesRequest)) *MockLunoClient_GetCandles_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *luno.GetCandlesRequest
+		if args[1] != nil {
+			arg1 = args[1].(*luno.GetCandlesRequest)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockLunoClient_GetCandles_Call) Return(getCandlesResponse *luno.GetCandlesResponse, err error) *MockLunoClient_GetCandles_Call {
+	_c.Call.Return(getCandlesResponse, err)
+	return _c
+}
+
+func (_c *MockLunoClient_GetCandles_Call) RunAndReturn(run func(ctx context.Context, req *luno.GetCandlesRequest) (*luno.GetCandlesResponse, error)) *MockLunoClient_GetCandles_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetFeeInfo provides a mock function for the type MockLunoClient
+func (_mock *MockLunoClient) GetFeeInfo(ctx context.Context, req *luno.GetFeeInfoRequest) (*luno.GetFeeInfoResponse, error) {
+	ret := _mock.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetFeeInfo")
+	}
+
+	var r0 *luno.GetFeeInfoResponse
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *luno.GetFeeInfoRequest) (*luno.GetFeeInfoResponse, error)); ok {
+		return returnFunc(ctx, req)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *luno.GetFeeInfoRequest) *luno.GetFeeInfoResponse); ok {
+		r0 = returnFunc(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*luno.GetFeeInfoResponse)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *luno.GetFeeInfoRequest) error); ok {
+		r1 = returnFunc(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockLunoClient_GetFeeInfo_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetFeeInfo'
+type MockLunoClient_GetFeeInfo_Call struct {
+	*mock.Call
+}
+
+// GetFeeInfo is a helper method to define mock.On call
+//   - ctx context.Context
+//   - req *luno.GetFeeInfoRequest
+func (_e *MockLunoClient_Expecter) GetFeeInfo(ctx interface{}, req interface{}) *MockLunoClient_GetFeeInfo_Call {
+	return &MockLunoClient_GetFeeInfo_Call{Call: _e.mock.On("GetFeeInfo", ctx, req)}
+}
+
+func (_c *MockLunoClient_GetFeeInfo_Call) Run(run func(ctx context.Context, req *luno.GetFeeInfoRequest)) *MockLunoClient_GetFeeInfo_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *luno.GetFeeInfoRequest
+		if args[1] != nil {
+			arg1 = args[1].(*luno.GetFeeInfoRequest)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockLunoClient_GetFeeInfo_Call) Return(getFeeInfoResponse *luno.GetFeeInfoResponse, err error) *MockLunoClient_GetFeeInfo_Call {
+	_c.Call.Return(getFeeInfoResponse, err)
+	return _c
+}
+
+func (_c *MockLunoClient_GetFeeInfo_Call) RunAndReturn(run func(ctx context.Context, req *luno.GetFeeInfoRequest) (*luno.GetFeeInfoResponse, error)) *MockLunoClient_GetFeeInfo_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetOrder provides a mock function for the type MockLunoClient
+func (_mock *MockLunoClient) GetOrder(ctx context.Context, req *luno.GetOrderRequest) (*luno.GetOrderResponse, error) {
+	ret := _mock.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOrder")
+	}
+
+	var r0 *luno.GetOrderResponse
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *luno.GetOrderRequest) (*luno.GetOrderResponse, error)); ok {
+		return returnFunc(ctx, req)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *luno.GetOrderRequest) *luno.GetOrderResponse); ok {
+		r0 = returnFunc(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*luno.GetOrderResponse)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *luno.GetOrderRequest) error); ok {
+		r1 = returnFunc(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockLunoClient_GetOrder_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetOrder'
+type MockLunoClient_GetOrder_Call struct {
+	*mock.Call
+}
+
+// GetOrder is a helper method to define mock.On call
+//   - ctx context.Context
+//   - req *luno.GetOrderRequest
+func (_e *MockLunoClient_Expecter) GetOrder(ctx interface{}, req interface{}) *MockLunoClient_GetOrder_Call {
+	return &MockLunoClient_GetOrder_Call{Call: _e.mock.On("GetOrder", ctx, req)}
+}
+
+func (_c *MockLunoClient_GetOrder_Call) Run(run func(ctx context.Context, req *luno.GetOrderRequest)) *MockLunoClient_GetOrder_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *luno.GetOrderRequest
+		if args[1] != nil {
+			arg1 = args[1].(*luno.GetOrderRequest)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockLunoClient_GetOrder_Call) Return(getOrderResponse *luno.GetOrderResponse, err error) *MockLunoClient_GetOrder_Call {
+	_c.Call.Return(getOrderResponse, err)
+	return _c
+}
+
+func (_c *MockLunoClient_GetOrder_Call) RunAndReturn(run func(ctx context.Context, req *luno.GetOrderRequest) (*luno.GetOrderResponse, error)) *MockLunoClient_GetOrder_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetOrderBook provides a mock function for the type MockLunoClient
 func (_mock *MockLunoClient) GetOrderBook(ctx context.Context, req *luno.GetOrderBookRequest) (*luno.GetOrderBookResponse, error) {
 	ret := _mock.Called(ctx, req)
@@ -175,6 +447,74 @@ func (_c *MockLunoClient_GetOrderBook_Call) RunAndReturn(run func(ctx context.Co
 	return _c
 }
 
+// GetOrderBookFull provides a mock function for the type MockLunoClient
+func (_mock *MockLunoClient) GetOrderBookFull(ctx context.Context, req *luno.GetOrderBookFullRequest) (*luno.GetOrderBookFullResponse, error) {
+	ret := _mock.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOrderBookFull")
+	}
+
+	var r0 *luno.GetOrderBookFullResponse
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *luno.GetOrderBookFullRequest) (*luno.GetOrderBookFullResponse, error)); ok {
+		return returnFunc(ctx, req)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *luno.GetOrderBookFullRequest) *luno.GetOrderBookFullResponse); ok {
+		r0 = returnFunc(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*luno.GetOrderBookFullResponse)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *luno.GetOrderBookFullRequest) error); ok {
+		r1 = returnFunc(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockLunoClient_GetOrderBookFull_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetOrderBookFull'
+type MockLunoClient_GetOrderBookFull_Call struct {
+	*mock.Call
+}
+
+// GetOrderBookFull is a helper method to define mock.On call
+//   - ctx context.Context
+//   - req *luno.GetOrderBookFullRequest
+func (_e *MockLunoClient_Expecter) GetOrderBookFull(ctx interface{}, req interface{}) *MockLunoClient_GetOrderBookFull_Call {
+	return &MockLunoClient_GetOrderBookFull_Call{Call: _e.mock.On("GetOrderBookFull", ctx, req)}
+}
+
+func (_c *MockLunoClient_GetOrderBookFull_Call) Run(run func(ctx context.Context, req *luno.GetOrderBookFullRequest)) *MockLunoClient_GetOrderBookFull_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *luno.GetOrderBookFullRequest
+		if args[1] != nil {
+			arg1 = args[1].(*luno.GetOrderBookFullRequest)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockLunoClient_GetOrderBookFull_Call) Return(getOrderBookFullResponse *luno.GetOrderBookFullResponse, err error) *MockLunoClient_GetOrderBookFull_Call {
+	_c.Call.Return(getOrderBookFullResponse, err)
+	return _c
+}
+
+func (_c *MockLunoClient_GetOrderBookFull_Call) RunAndReturn(run func(ctx context.Context, req *luno.GetOrderBookFullRequest) (*luno.GetOrderBookFullResponse, error)) *MockLunoClient_GetOrderBookFull_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetTicker provides a mock function for the type MockLunoClient
 func (_mock *MockLunoClient) GetTicker(ctx context.Context, req *luno.GetTickerRequest) (*luno.GetTickerResponse, error) {
 	ret := _mock.Called(ctx, req)
@@ -210,20 +550,224 @@ type MockLunoClient_GetTicker_Call struct {
 
 // GetTicker is a helper method to define mock.On call
 //   - ctx context.Context
-//   - req *luno.GetTickerRequest
-func (_e *MockLunoClient_Expecter) GetTicker(ctx interface{}, req interface{}) *MockLunoClient_GetTicker_Call {
-	return &MockLunoClient_GetTicker_Call{Call: _e.mock.On("GetTicker", ctx, req)}
+//   - req *luno.GetTickerRequest
+func (_e *MockLunoClient_Expecter) GetTicker(ctx interface{}, req interface{}) *MockLunoClient_GetTicker_Call {
+	return &MockLunoClient_GetTicker_Call{Call: _e.mock.On("GetTicker", ctx, req)}
+}
+
+func (_c *MockLunoClient_GetTicker_Call) Run(run func(ctx context.Context, req *luno.GetTickerRequest)) *MockLunoClient_GetTicker_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *luno.GetTickerRequest
+		if args[1] != nil {
+			arg1 = args[1].(*luno.GetTickerRequest)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockLunoClient_GetTicker_Call) Return(getTickerResponse *luno.GetTickerResponse, err error) *MockLunoClient_GetTicker_Call {
+	_c.Call.Return(getTickerResponse, err)
+	return _c
+}
+
+func (_c *MockLunoClient_GetTicker_Call) RunAndReturn(run func(ctx context.Context, req *luno.GetTickerRequest) (*luno.GetTickerResponse, error)) *MockLunoClient_GetTicker_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetTickers provides a mock function for the type MockLunoClient
+func (_mock *MockLunoClient) GetTickers(ctx context.Context, req *luno.GetTickersRequest) (*luno.GetTickersResponse, error) {
+	ret := _mock.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTickers")
+	}
+
+	var r0 *luno.GetTickersResponse
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *luno.GetTickersRequest) (*luno.GetTickersResponse, error)); ok {
+		return returnFunc(ctx, req)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *luno.GetTickersRequest) *luno.GetTickersResponse); ok {
+		r0 = returnFunc(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*luno.GetTickersResponse)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *luno.GetTickersRequest) error); ok {
+		r1 = returnFunc(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockLunoClient_GetTickers_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetTickers'
+type MockLunoClient_GetTickers_Call struct {
+	*mock.Call
+}
+
+// GetTickers is a helper method to define mock.On call
+//   - ctx context.Context
+//   - req *luno.GetTickersRequest
+func (_e *MockLunoClient_Expecter) GetTickers(ctx interface{}, req interface{}) *MockLunoClient_GetTickers_Call {
+	return &MockLunoClient_GetTickers_Call{Call: _e.mock.On("GetTickers", ctx, req)}
+}
+
+func (_c *MockLunoClient_GetTickers_Call) Run(run func(ctx context.Context, req *luno.GetTickersRequest)) *MockLunoClient_GetTickers_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *luno.GetTickersRequest
+		if args[1] != nil {
+			arg1 = args[1].(*luno.GetTickersRequest)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockLunoClient_GetTickers_Call) Return(getTickersResponse *luno.GetTickersResponse, err error) *MockLunoClient_GetTickers_Call {
+	_c.Call.Return(getTickersResponse, err)
+	return _c
+}
+
+func (_c *MockLunoClient_GetTickers_Call) RunAndReturn(run func(ctx context.Context, req *luno.GetTickersRequest) (*luno.GetTickersResponse, error)) *MockLunoClient_GetTickers_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetWithdrawal provides a mock function for the type MockLunoClient
+func (_mock *MockLunoClient) GetWithdrawal(ctx context.Context, req *luno.GetWithdrawalRequest) (*luno.GetWithdrawalResponse, error) {
+	ret := _mock.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetWithdrawal")
+	}
+
+	var r0 *luno.GetWithdrawalResponse
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *luno.GetWithdrawalRequest) (*luno.GetWithdrawalResponse, error)); ok {
+		return returnFunc(ctx, req)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *luno.GetWithdrawalRequest) *luno.GetWithdrawalResponse); ok {
+		r0 = returnFunc(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*luno.GetWithdrawalResponse)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *luno.GetWithdrawalRequest) error); ok {
+		r1 = returnFunc(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockLunoClient_GetWithdrawal_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetWithdrawal'
+type MockLunoClient_GetWithdrawal_Call struct {
+	*mock.Call
+}
+
+// GetWithdrawal is a helper method to define mock.On call
+//   - ctx context.Context
+//   - req *luno.GetWithdrawalRequest
+func (_e *MockLunoClient_Expecter) GetWithdrawal(ctx interface{}, req interface{}) *MockLunoClient_GetWithdrawal_Call {
+	return &MockLunoClient_GetWithdrawal_Call{Call: _e.mock.On("GetWithdrawal", ctx, req)}
+}
+
+func (_c *MockLunoClient_GetWithdrawal_Call) Run(run func(ctx context.Context, req *luno.GetWithdrawalRequest)) *MockLunoClient_GetWithdrawal_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *luno.GetWithdrawalRequest
+		if args[1] != nil {
+			arg1 = args[1].(*luno.GetWithdrawalRequest)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockLunoClient_GetWithdrawal_Call) Return(getWithdrawalResponse *luno.GetWithdrawalResponse, err error) *MockLunoClient_GetWithdrawal_Call {
+	_c.Call.Return(getWithdrawalResponse, err)
+	return _c
+}
+
+func (_c *MockLunoClient_GetWithdrawal_Call) RunAndReturn(run func(ctx context.Context, req *luno.GetWithdrawalRequest) (*luno.GetWithdrawalResponse, error)) *MockLunoClient_GetWithdrawal_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListBeneficiaries provides a mock function for the type MockLunoClient
+func (_mock *MockLunoClient) ListBeneficiaries(ctx context.Context, req *luno.ListBeneficiariesRequest) (*luno.ListBeneficiariesResponse, error) {
+	ret := _mock.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListBeneficiaries")
+	}
+
+	var r0 *luno.ListBeneficiariesResponse
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *luno.ListBeneficiariesRequest) (*luno.ListBeneficiariesResponse, error)); ok {
+		return returnFunc(ctx, req)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *luno.ListBeneficiariesRequest) *luno.ListBeneficiariesResponse); ok {
+		r0 = returnFunc(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*luno.ListBeneficiariesResponse)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *luno.ListBeneficiariesRequest) error); ok {
+		r1 = returnFunc(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockLunoClient_ListBeneficiaries_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListBeneficiaries'
+type MockLunoClient_ListBeneficiaries_Call struct {
+	*mock.Call
+}
+
+// ListBeneficiaries is a helper method to define mock.On call
+//   - ctx context.Context
+//   - req *luno.ListBeneficiariesRequest
+func (_e *MockLunoClient_Expecter) ListBeneficiaries(ctx interface{}, req interface{}) *MockLunoClient_ListBeneficiaries_Call {
+	return &MockLunoClient_ListBeneficiaries_Call{Call: _e.mock.On("ListBeneficiaries", ctx, req)}
 }
 
-func (_c *MockLunoClient_GetTicker_Call) Run(run func(ctx context.Context, req *luno.GetTickerRequest)) *MockLunoClient_GetTicker_Call {
+func (_c *MockLunoClient_ListBeneficiaries_Call) Run(run func(ctx context.Context, req *luno.ListBeneficiariesRequest)) *MockLunoClient_ListBeneficiaries_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
 			arg0 = args[0].(context.Context)
 		}
-		var arg1 *luno.GetTickerRequest
+		var arg1 *luno.ListBeneficiariesRequest
 		if args[1] != nil {
-			arg1 = args[1].(*luno.GetTickerRequest)
+			arg1 = args[1].(*luno.ListBeneficiariesRequest)
 		}
 		run(
 			arg0,
@@ -233,12 +777,12 @@ func (_c *MockLunoClient_GetTicker_Call) Run(run func(ctx context.Context, req *
 	return _c
 }
 
-func (_c *MockLunoClient_GetTicker_Call) Return(getTickerResponse *luno.GetTickerResponse, err error) *MockLunoClient_GetTicker_Call {
-	_c.Call.Return(getTickerResponse, err)
+func (_c *MockLunoClient_ListBeneficiaries_Call) Return(listBeneficiariesResponse *luno.ListBeneficiariesResponse, err error) *MockLunoClient_ListBeneficiaries_Call {
+	_c.Call.Return(listBeneficiariesResponse, err)
 	return _c
 }
 
-func (_c *MockLunoClient_GetTicker_Call) RunAndReturn(run func(ctx context.Context, req *luno.GetTickerRequest) (*luno.GetTickerResponse, error)) *MockLunoClient_GetTicker_Call {
+func (_c *MockLunoClient_ListBeneficiaries_Call) RunAndReturn(run func(ctx context.Context, req *luno.ListBeneficiariesRequest) (*luno.ListBeneficiariesResponse, error)) *MockLunoClient_ListBeneficiaries_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -447,27 +991,27 @@ func (_c *MockLunoClient_ListTransactions_Call) RunAndReturn(run func(ctx contex
 	return _c
 }
 
-// PostLimitOrder provides a mock function for the type MockLunoClient
-func (_mock *MockLunoClient) PostLimitOrder(ctx context.Context, req *luno.PostLimitOrderRequest) (*luno.PostLimitOrderResponse, error) {
+// ListTransfers provides a mock function for the type MockLunoClient
+func (_mock *MockLunoClient) ListTransfers(ctx context.Context, req *luno.ListTransfersRequest) (*luno.ListTransfersResponse, error) {
 	ret := _mock.Called(ctx, req)
 
 	if len(ret) == 0 {
-		panic("no return value specified for PostLimitOrder")
+		panic("no return value specified for ListTransfers")
 	}
 
-	var r0 *luno.PostLimitOrderResponse
+	var r0 *luno.ListTransfersResponse
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *luno.PostLimitOrderRequest) (*luno.PostLimitOrderResponse, error)); ok {
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *luno.ListTransfersRequest) (*luno.ListTransfersResponse, error)); ok {
 		return returnFunc(ctx, req)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *luno.PostLimitOrderRequest) *luno.PostLimitOrderResponse); ok {
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *luno.ListTransfersRequest) *luno.ListTransfersResponse); ok {
 		r0 = returnFunc(ctx, req)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*luno.PostLimitOrderResponse)
+			r0 = ret.Get(0).(*luno.ListTransfersResponse)
 		}
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, *luno.PostLimitOrderRequest) error); ok {
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *luno.ListTransfersRequest) error); ok {
 		r1 = returnFunc(ctx, req)
 	} else {
 		r1 = ret.Error(1)
@@ -475,27 +1019,27 @@ func (_mock *MockLunoClient) PostLimitOrder(ctx context.Context, req *luno.PostL
 	return r0, r1
 }
 
-// MockLunoClient_PostLimitOrder_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PostLimitOrder'
-type MockLunoClient_PostLimitOrder_Call struct {
+// MockLunoClient_ListTransfers_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListTransfers'
+type MockLunoClient_ListTransfers_Call struct {
 	*mock.Call
 }
 
-// PostLimitOrder is a helper method to define mock.On call
+// ListTransfers is a helper method to define mock.On call
 //   - ctx context.Context
-//   - req *luno.PostLimitOrderRequest
-func (_e *MockLunoClient_Expecter) PostLimitOrder(ctx interface{}, req interface{}) *MockLunoClient_PostLimitOrder_Call {
-	return &MockLunoClient_PostLimitOrder_Call{Call: _e.mock.On("PostLimitOrder", ctx, req)}
+//   - req *luno.ListTransfersRequest
+func (_e *MockLunoClient_Expecter) ListTransfers(ctx interface{}, req interface{}) *MockLunoClient_ListTransfers_Call {
+	return &MockLunoClient_ListTransfers_Call{Call: _e.mock.On("ListTransfers", ctx, req)}
 }
 
-func (_c *MockLunoClient_PostLimitOrder_Call) Run(run func(ctx context.Context, req *luno.PostLimitOrderRequest)) *MockLunoClient_PostLimitOrder_Call {
+func (_c *MockLunoClient_ListTransfers_Call) Run(run func(ctx context.Context, req *luno.ListTransfersRequest)) *MockLunoClient_ListTransfers_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
 			arg0 = args[0].(context.Context)
 		}
-		var arg1 *luno.PostLimitOrderRequest
+		var arg1 *luno.ListTransfersRequest
 		if args[1] != nil {
-			arg1 = args[1].(*luno.PostLimitOrderRequest)
+			arg1 = args[1].(*luno.ListTransfersRequest)
 		}
 		run(
 			arg0,
@@ -505,37 +1049,37 @@ func (_c *MockLunoClient_PostLimitOrder_Call) Run(run func(ctx context.Context,
 	return _c
 }
 
-func (_c *MockLunoClient_PostLimitOrder_Call) Return(postLimitOrderResponse *luno.PostLimitOrderResponse, err error) *MockLunoClient_PostLimitOrder_Call {
-	_c.Call.Return(postLimitOrderResponse, err)
+func (_c *MockLunoClient_ListTransfers_Call) Return(listTransfersResponse *luno.ListTransfersResponse, err error) *MockLunoClient_ListTransfers_Call {
+	_c.Call.Return(listTransfersResponse, err)
 	return _c
 }
 
-func (_c *MockLunoClient_PostLimitOrder_Call) RunAndReturn(run func(ctx context.Context, req *luno.PostLimitOrderRequest) (*luno.PostLimitOrderResponse, error)) *MockLunoClient_PostLimitOrder_Call {
+func (_c *MockLunoClient_ListTransfers_Call) RunAndReturn(run func(ctx context.Context, req *luno.ListTransfersRequest) (*luno.ListTransfersResponse, error)) *MockLunoClient_ListTransfers_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// StopOrder provides a mock function for the type MockLunoClient
-func (_mock *MockLunoClient) StopOrder(ctx context.Context, req *luno.StopOrderRequest) (*luno.StopOrderResponse, error) {
+// ListUserTrades provides a mock function for the type MockLunoClient
+func (_mock *MockLunoClient) ListUserTrades(ctx context.Context, req *luno.ListUserTradesRequest) (*luno.ListUserTradesResponse, error) {
 	ret := _mock.Called(ctx, req)
 
 	if len(ret) == 0 {
-		panic("no return value specified for StopOrder")
+		panic("no return value specified for ListUserTrades")
 	}
 
-	var r0 *luno.StopOrderResponse
+	var r0 *luno.ListUserTradesResponse
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *luno.StopOrderRequest) (*luno.StopOrderResponse, error)); ok {
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *luno.ListUserTradesRequest) (*luno.ListUserTradesResponse, error)); ok {
 		return returnFunc(ctx, req)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *luno.StopOrderRequest) *luno.StopOrderResponse); ok {
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *luno.ListUserTradesRequest) *luno.ListUserTradesResponse); ok {
 		r0 = returnFunc(ctx, req)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*luno.StopOrderResponse)
+			r0 = ret.Get(0).(*luno.ListUserTradesResponse)
 		}
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, *luno.StopOrderRequest) error); ok {
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *luno.ListUserTradesRequest) error); ok {
 		r1 = returnFunc(ctx, req)
 	} else {
 		r1 = ret.Error(1)
@@ -543,27 +1087,27 @@ func (_mock *MockLunoClient) StopOrder(ctx context.Context, req *luno.StopOrderR
 	return r0, r1
 }
 
-// MockLunoClient_StopOrder_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'StopOrder'
-type MockLunoClient_StopOrder_Call struct {
+// MockLunoClient_ListUserTrades_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListUserTrades'
+type MockLunoClient_ListUserTrades_Call struct {
 	*mock.Call
 }
 
-// StopOrder is a helper method to define mock.On call
+// ListUserTrades is a helper method to define mock.On call
 //   - ctx context.Context
-//   - req *luno.StopOrderRequest
-func (_e *MockLunoClient_Expecter) StopOrder(ctx interface{}, req interface{}) *MockLunoClient_StopOrder_Call {
-	return &MockLunoClient_StopOrder_Call{Call: _e.mock.On("StopOrder", ctx, req)}
+//   - req *luno.ListUserTradesRequest
+func (_e *MockLunoClient_Expecter) ListUserTrades(ctx interface{}, req interface{}) *MockLunoClient_ListUserTrades_Call {
+	return &MockLunoClient_ListUserTrades_Call{Call: _e.mock.On("ListUserTrades", ctx, req)}
 }
 
-func (_c *MockLunoClient_StopOrder_Call) Run(run func(ctx context.Context, req *luno.StopOrderRequest)) *MockLunoClient_StopOrder_Call {
+func (_c *MockLunoClient_ListUserTrades_Call) Run(run func(ctx context.Context, req *luno.ListUserTradesRequest)) *MockLunoClient_ListUserTrades_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
 			arg0 = args[0].(context.Context)
 		}
-		var arg1 *luno.StopOrderRequest
+		var arg1 *luno.ListUserTradesRequest
 		if args[1] != nil {
-			arg1 = args[1].(*luno.StopOrderRequest)
+			arg1 = args[1].(*luno.ListUserTradesRequest)
 		}
 		run(
 			arg0,
@@ -573,37 +1117,37 @@ func (_c *MockLunoClient_StopOrder_Call) Run(run func(ctx context.Context, req *
 	return _c
 }
 
-func (_c *MockLunoClient_StopOrder_Call) Return(stopOrderResponse *luno.StopOrderResponse, err error) *MockLunoClient_StopOrder_Call {
-	_c.Call.Return(stopOrderResponse, err)
+func (_c *MockLunoClient_ListUserTrades_Call) Return(listUserTradesResponse *luno.ListUserTradesResponse, err error) *MockLunoClient_ListUserTrades_Call {
+	_c.Call.Return(listUserTradesResponse, err)
 	return _c
 }
 
-func (_c *MockLunoClient_StopOrder_Call) RunAndReturn(run func(ctx context.Context, req *luno.StopOrderRequest) (*luno.StopOrderResponse, error)) *MockLunoClient_StopOrder_Call {
+func (_c *MockLunoClient_ListUserTrades_Call) RunAndReturn(run func(ctx context.Context, req *luno.ListUserTradesRequest) (*luno.ListUserTradesResponse, error)) *MockLunoClient_ListUserTrades_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetCandles provides a mock function for the type MockLunoClient
-func (_mock *MockLunoClient) GetCandles(ctx context.Context, req *luno.GetCandlesRequest) (*luno.GetCandlesResponse, error) {
+// ListWithdrawals provides a mock function for the type MockLunoClient
+func (_mock *MockLunoClient) ListWithdrawals(ctx context.Context, req *luno.ListWithdrawalsRequest) (*luno.ListWithdrawalsResponse, error) {
 	ret := _mock.Called(ctx, req)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetCandles")
+		panic("no return value specified for ListWithdrawals")
 	}
 
-	var r0 *luno.GetCandlesResponse
+	var r0 *luno.ListWithdrawalsResponse
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *luno.GetCandlesRequest) (*luno.GetCandlesResponse, error)); ok {
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *luno.ListWithdrawalsRequest) (*luno.ListWithdrawalsResponse, error)); ok {
 		return returnFunc(ctx, req)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *luno.GetCandlesRequest) *luno.GetCandlesResponse); ok {
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *luno.ListWithdrawalsRequest) *luno.ListWithdrawalsResponse); ok {
 		r0 = returnFunc(ctx, req)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*luno.GetCandlesResponse)
+			r0 = ret.Get(0).(*luno.ListWithdrawalsResponse)
 		}
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, *luno.GetCandlesRequest) error); ok {
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *luno.ListWithdrawalsRequest) error); ok {
 		r1 = returnFunc(ctx, req)
 	} else {
 		r1 = ret.Error(1)
@@ -611,27 +1155,27 @@ func (_mock *MockLunoClient) GetCandles(ctx context.Context, req *luno.GetCandle
 	return r0, r1
 }
 
-// MockLunoClient_GetCandles_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetCandles'
-type MockLunoClient_GetCandles_Call struct {
+// MockLunoClient_ListWithdrawals_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListWithdrawals'
+type MockLunoClient_ListWithdrawals_Call struct {
 	*mock.Call
 }
 
-// GetCandles is a helper method to define mock.On call
+// ListWithdrawals is a helper method to define mock.On call
 //   - ctx context.Context
-//   - req *luno.GetCandlesRequest
-func (_e *MockLunoClient_Expecter) GetCandles(ctx interface{}, req interface{}) *MockLunoClient_GetCandles_Call {
-	return &MockLunoClient_GetCandles_Call{Call: _e.mock.On("GetCandles", ctx, req)}
+//   - req *luno.ListWithdrawalsRequest
+func (_e *MockLunoClient_Expecter) ListWithdrawals(ctx interface{}, req interface{}) *MockLunoClient_ListWithdrawals_Call {
+	return &MockLunoClient_ListWithdrawals_Call{Call: _e.mock.On("ListWithdrawals", ctx, req)}
 }
 
-func (_c *MockLunoClient_GetCandles_Call) Run(run func(ctx context.Context, req *luno.GetCandlesRequest)) *MockLunoClient_GetCandles_Call {
+func (_c *MockLunoClient_ListWithdrawals_Call) Run(run func(ctx context.Context, req *luno.ListWithdrawalsRequest)) *MockLunoClient_ListWithdrawals_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
 			arg0 = args[0].(context.Context)
 		}
-		var arg1 *luno.GetCandlesRequest
+		var arg1 *luno.ListWithdrawalsRequest
 		if args[1] != nil {
-			arg1 = args[1].(*luno.GetCandlesRequest)
+			arg1 = args[1].(*luno.ListWithdrawalsRequest)
 		}
 		run(
 			arg0,
@@ -641,37 +1185,37 @@ func (_c *MockLunoClient_GetCandles_Call) Run(run func(ctx context.Context, req
 	return _c
 }
 
-func (_c *MockLunoClient_GetCandles_Call) Return(getCandlesResponse *luno.GetCandlesResponse, err error) *MockLunoClient_GetCandles_Call {
-	_c.Call.Return(getCandlesResponse, err)
+func (_c *MockLunoClient_ListWithdrawals_Call) Return(listWithdrawalsResponse *luno.ListWithdrawalsResponse, err error) *MockLunoClient_ListWithdrawals_Call {
+	_c.Call.Return(listWithdrawalsResponse, err)
 	return _c
 }
 
-func (_c *MockLunoClient_GetCandles_Call) RunAndReturn(run func(ctx context.Context, req *luno.GetCandlesRequest) (*luno.GetCandlesResponse, error)) *MockLunoClient_GetCandles_Call {
+func (_c *MockLunoClient_ListWithdrawals_Call) RunAndReturn(run func(ctx context.Context, req *luno.ListWithdrawalsRequest) (*luno.ListWithdrawalsResponse, error)) *MockLunoClient_ListWithdrawals_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetTickers provides a mock function for the type MockLunoClient
-func (_mock *MockLunoClient) GetTickers(ctx context.Context, req *luno.GetTickersRequest) (*luno.GetTickersResponse, error) {
+// Markets provides a mock function for the type MockLunoClient
+func (_mock *MockLunoClient) Markets(ctx context.Context, req *luno.MarketsRequest) (*luno.MarketsResponse, error) {
 	ret := _mock.Called(ctx, req)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetTickers")
+		panic("no return value specified for Markets")
 	}
 
-	var r0 *luno.GetTickersResponse
+	var r0 *luno.MarketsResponse
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *luno.GetTickersRequest) (*luno.GetTickersResponse, error)); ok {
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *luno.MarketsRequest) (*luno.MarketsResponse, error)); ok {
 		return returnFunc(ctx, req)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *luno.GetTickersRequest) *luno.GetTickersResponse); ok {
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *luno.MarketsRequest) *luno.MarketsResponse); ok {
 		r0 = returnFunc(ctx, req)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*luno.GetTickersResponse)
+			r0 = ret.Get(0).(*luno.MarketsResponse)
 		}
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, *luno.GetTickersRequest) error); ok {
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *luno.MarketsRequest) error); ok {
 		r1 = returnFunc(ctx, req)
 	} else {
 		r1 = ret.Error(1)
@@ -679,27 +1223,27 @@ func (_mock *MockLunoClient) GetTickers(ctx context.Context, req *luno.GetTicker
 	return r0, r1
 }
 
-// MockLunoClient_GetTickers_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetTickers'
-type MockLunoClient_GetTickers_Call struct {
+// MockLunoClient_Markets_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Markets'
+type MockLunoClient_Markets_Call struct {
 	*mock.Call
 }
 
-// GetTickers is a helper method to define mock.On call
+// Markets is a helper method to define mock.On call
 //   - ctx context.Context
-//   - req *luno.GetTickersRequest
-func (_e *MockLunoClient_Expecter) GetTickers(ctx interface{}, req interface{}) *MockLunoClient_GetTickers_Call {
-	return &MockLunoClient_GetTickers_Call{Call: _e.mock.On("GetTickers", ctx, req)}
+//   - req *luno.MarketsRequest
+func (_e *MockLunoClient_Expecter) Markets(ctx interface{}, req interface{}) *MockLunoClient_Markets_Call {
+	return &MockLunoClient_Markets_Call{Call: _e.mock.On("Markets", ctx, req)}
 }
 
-func (_c *MockLunoClient_GetTickers_Call) Run(run func(ctx context.Context, req *luno.GetTickersRequest)) *MockLunoClient_GetTickers_Call {
+func (_c *MockLunoClient_Markets_Call) Run(run func(ctx context.Context, req *luno.MarketsRequest)) *MockLunoClient_Markets_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
 			arg0 = args[0].(context.Context)
 		}
-		var arg1 *luno.GetTickersRequest
+		var arg1 *luno.MarketsRequest
 		if args[1] != nil {
-			arg1 = args[1].(*luno.GetTickersRequest)
+			arg1 = args[1].(*luno.MarketsRequest)
 		}
 		run(
 			arg0,
@@ -709,37 +1253,37 @@ func (_c *MockLunoClient_GetTickers_Call) Run(run func(ctx context.Context, req
 	return _c
 }
 
-func (_c *MockLunoClient_GetTickers_Call) Return(getTickersResponse *luno.GetTickersResponse, err error) *MockLunoClient_GetTickers_Call {
-	_c.Call.Return(getTickersResponse, err)
+func (_c *MockLunoClient_Markets_Call) Return(marketsResponse *luno.MarketsResponse, err error) *MockLunoClient_Markets_Call {
+	_c.Call.Return(marketsResponse, err)
 	return _c
 }
 
-func (_c *MockLunoClient_GetTickers_Call) RunAndReturn(run func(ctx context.Context, req *luno.GetTickersRequest) (*luno.GetTickersResponse, error)) *MockLunoClient_GetTickers_Call {
+func (_c *MockLunoClient_Markets_Call) RunAndReturn(run func(ctx context.Context, req *luno.MarketsRequest) (*luno.MarketsResponse, error)) *MockLunoClient_Markets_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetOrderBookFull provides a mock function for the type MockLunoClient
-func (_mock *MockLunoClient) GetOrderBookFull(ctx context.Context, req *luno.GetOrderBookFullRequest) (*luno.GetOrderBookFullResponse, error) {
+// PostLimitOrder provides a mock function for the type MockLunoClient
+func (_mock *MockLunoClient) PostLimitOrder(ctx context.Context, req *luno.PostLimitOrderRequest) (*luno.PostLimitOrderResponse, error) {
 	ret := _mock.Called(ctx, req)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetOrderBookFull")
+		panic("no return value specified for PostLimitOrder")
 	}
 
-	var r0 *luno.GetOrderBookFullResponse
+	var r0 *luno.PostLimitOrderResponse
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *luno.GetOrderBookFullRequest) (*luno.GetOrderBookFullResponse, error)); ok {
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *luno.PostLimitOrderRequest) (*luno.PostLimitOrderResponse, error)); ok {
 		return returnFunc(ctx, req)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *luno.GetOrderBookFullRequest) *luno.GetOrderBookFullResponse); ok {
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *luno.PostLimitOrderRequest) *luno.PostLimitOrderResponse); ok {
 		r0 = returnFunc(ctx, req)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*luno.GetOrderBookFullResponse)
+			r0 = ret.Get(0).(*luno.PostLimitOrderResponse)
 		}
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, *luno.GetOrderBookFullRequest) error); ok {
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *luno.PostLimitOrderRequest) error); ok {
 		r1 = returnFunc(ctx, req)
 	} else {
 		r1 = ret.Error(1)
@@ -747,27 +1291,27 @@ func (_mock *MockLunoClient) GetOrderBookFull(ctx context.Context, req *luno.Get
 	return r0, r1
 }
 
-// MockLunoClient_GetOrderBookFull_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetOrderBookFull'
-type MockLunoClient_GetOrderBookFull_Call struct {
+// MockLunoClient_PostLimitOrder_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PostLimitOrder'
+type MockLunoClient_PostLimitOrder_Call struct {
 	*mock.Call
 }
 
-// GetOrderBookFull is a helper method to define mock.On call
+// PostLimitOrder is a helper method to define mock.On call
 //   - ctx context.Context
-//   - req *luno.GetOrderBookFullRequest
-func (_e *MockLunoClient_Expecter) GetOrderBookFull(ctx interface{}, req interface{}) *MockLunoClient_GetOrderBookFull_Call {
-	return &MockLunoClient_GetOrderBookFull_Call{Call: _e.mock.On("GetOrderBookFull", ctx, req)}
+//   - req *luno.PostLimitOrderRequest
+func (_e *MockLunoClient_Expecter) PostLimitOrder(ctx interface{}, req interface{}) *MockLunoClient_PostLimitOrder_Call {
+	return &MockLunoClient_PostLimitOrder_Call{Call: _e.mock.On("PostLimitOrder", ctx, req)}
 }
 
-func (_c *MockLunoClient_GetOrderBookFull_Call) Run(run func(ctx context.Context, req *luno.GetOrderBookFullRequest)) *MockLunoClient_GetOrderBookFull_Call {
+func (_c *MockLunoClient_PostLimitOrder_Call) Run(run func(ctx context.Context, req *luno.PostLimitOrderRequest)) *MockLunoClient_PostLimitOrder_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
 			arg0 = args[0].(context.Context)
 		}
-		var arg1 *luno.GetOrderBookFullRequest
+		var arg1 *luno.PostLimitOrderRequest
 		if args[1] != nil {
-			arg1 = args[1].(*luno.GetOrderBookFullRequest)
+			arg1 = args[1].(*luno.PostLimitOrderRequest)
 		}
 		run(
 			arg0,
@@ -777,37 +1321,37 @@ func (_c *MockLunoClient_GetOrderBookFull_Call) Run(run func(ctx context.Context
 	return _c
 }
 
-func (_c *MockLunoClient_GetOrderBookFull_Call) Return(getOrderBookFullResponse *luno.GetOrderBookFullResponse, err error) *MockLunoClient_GetOrderBookFull_Call {
-	_c.Call.Return(getOrderBookFullResponse, err)
+func (_c *MockLunoClient_PostLimitOrder_Call) Return(postLimitOrderResponse *luno.PostLimitOrderResponse, err error) *MockLunoClient_PostLimitOrder_Call {
+	_c.Call.Return(postLimitOrderResponse, err)
 	return _c
 }
 
-func (_c *MockLunoClient_GetOrderBookFull_Call) RunAndReturn(run func(ctx context.Context, req *luno.GetOrderBookFullRequest) (*luno.GetOrderBookFullResponse, error)) *MockLunoClient_GetOrderBookFull_Call {
+func (_c *MockLunoClient_PostLimitOrder_Call) RunAndReturn(run func(ctx context.Context, req *luno.PostLimitOrderRequest) (*luno.PostLimitOrderResponse, error)) *MockLunoClient_PostLimitOrder_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// Markets provides a mock function for the type MockLunoClient
-func (_mock *MockLunoClient) Markets(ctx context.Context, req *luno.MarketsRequest) (*luno.MarketsResponse, error) {
+// PostMarketOrder provides a mock function for the type MockLunoClient
+func (_mock *MockLunoClient) PostMarketOrder(ctx context.Context, req *luno.PostMarketOrderRequest) (*luno.PostMarketOrderResponse, error) {
 	ret := _mock.Called(ctx, req)
 
 	if len(ret) == 0 {
-		panic("no return value specified for Markets")
+		panic("no return value specified for PostMarketOrder")
 	}
 
-	var r0 *luno.MarketsResponse
+	var r0 *luno.PostMarketOrderResponse
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *luno.MarketsRequest) (*luno.MarketsResponse, error)); ok {
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *luno.PostMarketOrderRequest) (*luno.PostMarketOrderResponse, error)); ok {
 		return returnFunc(ctx, req)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *luno.MarketsRequest) *luno.MarketsResponse); ok {
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *luno.PostMarketOrderRequest) *luno.PostMarketOrderResponse); ok {
 		r0 = returnFunc(ctx, req)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*luno.MarketsResponse)
+			r0 = ret.Get(0).(*luno.PostMarketOrderResponse)
 		}
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, *luno.MarketsRequest) error); ok {
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *luno.PostMarketOrderRequest) error); ok {
 		r1 = returnFunc(ctx, req)
 	} else {
 		r1 = ret.Error(1)
@@ -815,27 +1359,27 @@ func (_mock *MockLunoClient) Markets(ctx context.Context, req *luno.MarketsReque
 	return r0, r1
 }
 
-// MockLunoClient_Markets_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Markets'
-type MockLunoClient_Markets_Call struct {
+// MockLunoClient_PostMarketOrder_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PostMarketOrder'
+type MockLunoClient_PostMarketOrder_Call struct {
 	*mock.Call
 }
 
-// Markets is a helper method to define mock.On call
+// PostMarketOrder is a helper method to define mock.On call
 //   - ctx context.Context
-//   - req *luno.MarketsRequest
-func (_e *MockLunoClient_Expecter) Markets(ctx interface{}, req interface{}) *MockLunoClient_Markets_Call {
-	return &MockLunoClient_Markets_Call{Call: _e.mock.On("Markets", ctx, req)}
+//   - req *luno.PostMarketOrderRequest
+func (_e *MockLunoClient_Expecter) PostMarketOrder(ctx interface{}, req interface{}) *MockLunoClient_PostMarketOrder_Call {
+	return &MockLunoClient_PostMarketOrder_Call{Call: _e.mock.On("PostMarketOrder", ctx, req)}
 }
 
-func (_c *MockLunoClient_Markets_Call) Run(run func(ctx context.Context, req *luno.MarketsRequest)) *MockLunoClient_Markets_Call {
+func (_c *MockLunoClient_PostMarketOrder_Call) Run(run func(ctx context.Context, req *luno.PostMarketOrderRequest)) *MockLunoClient_PostMarketOrder_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
 			arg0 = args[0].(context.Context)
 		}
-		var arg1 *luno.MarketsRequest
+		var arg1 *luno.PostMarketOrderRequest
 		if args[1] != nil {
-			arg1 = args[1].(*luno.MarketsRequest)
+			arg1 = args[1].(*luno.PostMarketOrderRequest)
 		}
 		run(
 			arg0,
@@ -845,12 +1389,12 @@ func (_c *MockLunoClient_Markets_Call) Run(run func(ctx context.Context, req *lu
 	return _c
 }
 
-func (_c *MockLunoClient_Markets_Call) Return(marketsResponse *luno.MarketsResponse, err error) *MockLunoClient_Markets_Call {
-	_c.Call.Return(marketsResponse, err)
+func (_c *MockLunoClient_PostMarketOrder_Call) Return(postMarketOrderResponse *luno.PostMarketOrderResponse, err error) *MockLunoClient_PostMarketOrder_Call {
+	_c.Call.Return(postMarketOrderResponse, err)
 	return _c
 }
 
-func (_c *MockLunoClient_Markets_Call) RunAndReturn(run func(ctx context.Context, req *luno.MarketsRequest) (*luno.MarketsResponse, error)) *MockLunoClient_Markets_Call {
+func (_c *MockLunoClient_PostMarketOrder_Call) RunAndReturn(run func(ctx context.Context, req *luno.PostMarketOrderRequest) (*luno.PostMarketOrderResponse, error)) *MockLunoClient_PostMarketOrder_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -869,7 +1413,6 @@ func (_mock *MockLunoClient) SetAuth(id string, secret string) error {
 	} else {
 		r0 = ret.Error(0)
 	}
-
 	return r0
 }
 
@@ -887,17 +1430,28 @@ func (_e *MockLunoClient_Expecter) SetAuth(id interface{}, secret interface{}) *
 
 func (_c *MockLunoClient_SetAuth_Call) Run(run func(id string, secret string)) *MockLunoClient_SetAuth_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string), args[1].(string))
+		var arg0 string
+		if args[0] != nil {
+			arg0 = args[0].(string)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
 	})
 	return _c
 }
 
-func (_c *MockLunoClient_SetAuth_Call) Return(_a0 error) *MockLunoClient_SetAuth_Call {
-	_c.Call.Return(_a0)
+func (_c *MockLunoClient_SetAuth_Call) Return(err error) *MockLunoClient_SetAuth_Call {
+	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *MockLunoClient_SetAuth_Call) RunAndReturn(run func(string, string) error) *MockLunoClient_SetAuth_Call {
+func (_c *MockLunoClient_SetAuth_Call) RunAndReturn(run func(id string, secret string) error) *MockLunoClient_SetAuth_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -905,6 +1459,7 @@ func (_c *MockLunoClient_SetAuth_Call) RunAndReturn(run func(string, string) err
 // SetBaseURL provides a mock function for the type MockLunoClient
 func (_mock *MockLunoClient) SetBaseURL(url string) {
 	_mock.Called(url)
+	return
 }
 
 // MockLunoClient_SetBaseURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetBaseURL'
@@ -920,7 +1475,13 @@ func (_e *MockLunoClient_Expecter) SetBaseURL(url interface{}) *MockLunoClient_S
 
 func (_c *MockLunoClient_SetBaseURL_Call) Run(run func(url string)) *MockLunoClient_SetBaseURL_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		var arg0 string
+		if args[0] != nil {
+			arg0 = args[0].(string)
+		}
+		run(
+			arg0,
+		)
 	})
 	return _c
 }
@@ -930,14 +1491,15 @@ func (_c *MockLunoClient_SetBaseURL_Call) Return() *MockLunoClient_SetBaseURL_Ca
 	return _c
 }
 
-func (_c *MockLunoClient_SetBaseURL_Call) RunAndReturn(run func(string)) *MockLunoClient_SetBaseURL_Call {
-	_c.Call.Return(run)
+func (_c *MockLunoClient_SetBaseURL_Call) RunAndReturn(run func(url string)) *MockLunoClient_SetBaseURL_Call {
+	_c.Run(run)
 	return _c
 }
 
 // SetDebug provides a mock function for the type MockLunoClient
 func (_mock *MockLunoClient) SetDebug(debug bool) {
 	_mock.Called(debug)
+	return
 }
 
 // MockLunoClient_SetDebug_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetDebug'
@@ -953,7 +1515,13 @@ func (_e *MockLunoClient_Expecter) SetDebug(debug interface{}) *MockLunoClient_S
 
 func (_c *MockLunoClient_SetDebug_Call) Run(run func(debug bool)) *MockLunoClient_SetDebug_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(bool))
+		var arg0 bool
+		if args[0] != nil {
+			arg0 = args[0].(bool)
+		}
+		run(
+			arg0,
+		)
 	})
 	return _c
 }
@@ -963,7 +1531,75 @@ func (_c *MockLunoClient_SetDebug_Call) Return() *MockLunoClient_SetDebug_Call {
 	return _c
 }
 
-func (_c *MockLunoClient_SetDebug_Call) RunAndReturn(run func(bool)) *MockLunoClient_SetDebug_Call {
+func (_c *MockLunoClient_SetDebug_Call) RunAndReturn(run func(debug bool)) *MockLunoClient_SetDebug_Call {
+	_c.Run(run)
+	return _c
+}
+
+// StopOrder provides a mock function for the type MockLunoClient
+func (_mock *MockLunoClient) StopOrder(ctx context.Context, req *luno.StopOrderRequest) (*luno.StopOrderResponse, error) {
+	ret := _mock.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StopOrder")
+	}
+
+	var r0 *luno.StopOrderResponse
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *luno.StopOrderRequest) (*luno.StopOrderResponse, error)); ok {
+		return returnFunc(ctx, req)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *luno.StopOrderRequest) *luno.StopOrderResponse); ok {
+		r0 = returnFunc(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*luno.StopOrderResponse)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *luno.StopOrderRequest) error); ok {
+		r1 = returnFunc(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockLunoClient_StopOrder_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'StopOrder'
+type MockLunoClient_StopOrder_Call struct {
+	*mock.Call
+}
+
+// StopOrder is a helper method to define mock.On call
+//   - ctx context.Context
+//   - req *luno.StopOrderRequest
+func (_e *MockLunoClient_Expecter) StopOrder(ctx interface{}, req interface{}) *MockLunoClient_StopOrder_Call {
+	return &MockLunoClient_StopOrder_Call{Call: _e.mock.On("StopOrder", ctx, req)}
+}
+
+func (_c *MockLunoClient_StopOrder_Call) Run(run func(ctx context.Context, req *luno.StopOrderRequest)) *MockLunoClient_StopOrder_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *luno.StopOrderRequest
+		if args[1] != nil {
+			arg1 = args[1].(*luno.StopOrderRequest)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockLunoClient_StopOrder_Call) Return(stopOrderResponse *luno.StopOrderResponse, err error) *MockLunoClient_StopOrder_Call {
+	_c.Call.Return(stopOrderResponse, err)
+	return _c
+}
+
+func (_c *MockLunoClient_StopOrder_Call) RunAndReturn(run func(ctx context.Context, req *luno.StopOrderRequest) (*luno.StopOrderResponse, error)) *MockLunoClient_StopOrder_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -0,0 +1,210 @@
+package sdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/luno/luno-go"
+)
+
+// route is a Luno API endpoint used by LunoClient and the type its response
+// is decoded into
+type route struct {
+	method   string
+	segments []string
+	response reflect.Type
+}
+
+func newRoute(method, pattern string, response reflect.Type) route {
+	return route{method: method, segments: strings.Split(pattern, "/"), response: response}
+}
+
+// routes are the endpoints behind the LunoClient methods, following luno-go
+var routes = []route{
+	newRoute(http.MethodGet, "/api/1/balance", reflect.TypeFor[luno.GetBalancesResponse]()),
+	newRoute(http.MethodGet, "/api/1/ticker", reflect.TypeFor[luno.GetTickerResponse]()),
+	newRoute(http.MethodGet, "/api/1/tickers", reflect.TypeFor[luno.GetTickersResponse]()),
+	newRoute(http.MethodGet, "/api/1/orderbook_top", reflect.TypeFor[luno.GetOrderBookResponse]()),
+	newRoute(http.MethodGet, "/api/1/orderbook", reflect.TypeFor[luno.GetOrderBookFullResponse]()),
+	newRoute(http.MethodGet, "/api/1/orders/{id}", reflect.TypeFor[luno.GetOrderResponse]()),
+	newRoute(http.MethodPost, "/api/1/postorder", reflect.TypeFor[luno.PostLimitOrderResponse]()),
+	newRoute(http.MethodPost, "/api/1/marketorder", reflect.TypeFor[luno.PostMarketOrderResponse]()),
+	newRoute(http.MethodPost, "/api/1/stoporder", reflect.TypeFor[luno.StopOrderResponse]()),
+	newRoute(http.MethodGet, "/api/1/listorders", reflect.TypeFor[luno.ListOrdersResponse]()),
+	newRoute(http.MethodGet, "/api/1/accounts/{id}/transactions", reflect.TypeFor[luno.ListTransactionsResponse]()),
+	newRoute(http.MethodGet, "/api/1/trades", reflect.TypeFor[luno.ListTradesResponse]()),
+	newRoute(http.MethodGet, "/api/1/listtrades", reflect.TypeFor[luno.ListUserTradesResponse]()),
+	newRoute(http.MethodGet, "/api/exchange/1/candles", reflect.TypeFor[luno.GetCandlesResponse]()),
+	newRoute(http.MethodGet, "/api/exchange/1/markets", reflect.TypeFor[luno.MarketsResponse]()),
+	newRoute(http.MethodGet, "/api/1/beneficiaries", reflect.TypeFor[luno.ListBeneficiariesResponse]()),
+	newRoute(http.MethodPost, "/api/1/withdrawals", reflect.TypeFor[luno.CreateWithdrawalResponse]()),
+	newRoute(http.MethodGet, "/api/1/withdrawals", reflect.TypeFor[luno.ListWithdrawalsResponse]()),
+	newRoute(http.MethodGet, "/api/1/withdrawals/{id}", reflect.TypeFor[luno.GetWithdrawalResponse]()),
+	newRoute(http.MethodGet, "/api/exchange/1/transfers", reflect.TypeFor[luno.ListTransfersResponse]()),
+	newRoute(http.MethodGet, "/api/1/fee_info", reflect.TypeFor[luno.GetFeeInfoResponse]()),
+}
+
+// match reports whether the request method and path are for r
+func (r route) match(method, path string) bool {
+	if method != r.method {
+		return false
+	}
+	segments := strings.Split(path, "/")
+	if len(segments) != len(r.segments) {
+		return false
+	}
+	for i, s := range r.segments {
+		if s != segments[i] && !strings.HasPrefix(s, "{") {
+			return false
+		}
+	}
+	return true
+}
+
+// schema is the JSON shape a Go type decodes from: the fields of an object,
+// the elements of an array, or neither for a value decoded as a whole
+type schema struct {
+	fields map[string]*schema
+	elem   *schema
+}
+
+var (
+	schemasMu sync.Mutex
+	schemas   = make(map[reflect.Type]*schema)
+)
+
+// schemaFor returns the schema of t, building it the first time
+func schemaFor(t reflect.Type) *schema {
+	schemasMu.Lock()
+	defer schemasMu.Unlock()
+	if s, ok := schemas[t]; ok {
+		return s
+	}
+	s := buildSchema(t)
+	schemas[t] = s
+	return s
+}
+
+var unmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+
+func buildSchema(t reflect.Type) *schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if reflect.PointerTo(t).Implements(unmarshalerType) {
+		return &schema{}
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		s := &schema{fields: make(map[string]*schema)}
+		addFields(s, t)
+		return s
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &schema{}
+		}
+		return &schema{elem: buildSchema(t.Elem())}
+	default:
+		return &schema{}
+	}
+}
+
+// addFields adds the JSON fields of struct t to s, including those of embedded structs
+func addFields(s *schema, t reflect.Type) {
+	for f := range t.Fields() {
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			addFields(s, f.Type)
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		s.fields[name] = buildSchema(f.Type)
+	}
+}
+
+// schemaDiff is how a response differs from its schema, as dotted field paths
+type schemaDiff struct {
+	unexpected []string
+	missing    []string
+}
+
+// compare adds the differences between the decoded JSON value v and s to d.
+// Only the first element of arrays is compared.
+func (s *schema) compare(v any, path string, d *schemaDiff) {
+	switch v := v.(type) {
+	case map[string]any:
+		if s.fields == nil {
+			return
+		}
+		for name, value := range v {
+			field, ok := s.fields[name]
+			if !ok {
+				d.unexpected = append(d.unexpected, path+name)
+				continue
+			}
+			field.compare(value, path+name+".", d)
+		}
+		for name := range s.fields {
+			if _, ok := v[name]; !ok {
+				d.missing = append(d.missing, path+name)
+			}
+		}
+	case []any:
+		if s.elem != nil && len(v) > 0 {
+			s.elem.compare(v[0], strings.TrimSuffix(path, ".")+"[].", d)
+		}
+	}
+}
+
+// checkSchema logs at debug level the fields of a successful JSON response
+// that are missing from, or not in, the type luno-go decodes it into. Renamed
+// or removed fields otherwise decode silently as zero values.
+func checkSchema(logger *slog.Logger, req *http.Request, res *http.Response) {
+	ctx := req.Context()
+	if res.StatusCode != http.StatusOK || res.Body == nil || !logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	i := slices.IndexFunc(routes, func(r route) bool { return r.match(req.Method, req.URL.Path) })
+	if i < 0 {
+		return
+	}
+
+	body, err := io.ReadAll(res.Body)
+	res.Body = struct {
+		io.Reader
+		io.Closer
+	}{bytes.NewReader(body), res.Body}
+	if err != nil {
+		return
+	}
+	var v any
+	if json.Unmarshal(body, &v) != nil {
+		return
+	}
+
+	var d schemaDiff
+	schemaFor(routes[i].response).compare(v, "", &d)
+	if len(d.unexpected) == 0 && len(d.missing) == 0 {
+		return
+	}
+	slices.Sort(d.unexpected)
+	slices.Sort(d.missing)
+	logger.DebugContext(ctx, "Luno API response does not match the expected schema",
+		"method", req.Method, "path", req.URL.Path,
+		"unexpected_fields", d.unexpected, "missing_fields", d.missing)
+}
@@ -0,0 +1,102 @@
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/luno/luno-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaCompare(t *testing.T) {
+	tests := []struct {
+		name       string
+		response   reflect.Type
+		body       string
+		unexpected []string
+		missing    []string
+	}{
+		{
+			name:     "matching ticker",
+			response: reflect.TypeFor[luno.GetTickerResponse](),
+			body:     `{"pair":"XBTZAR","ask":"1","bid":"1","last_trade":"1","rolling_24_hour_volume":"2","status":"ACTIVE","timestamp":1}`,
+		},
+		{
+			name:       "renamed field",
+			response:   reflect.TypeFor[luno.GetTickerResponse](),
+			body:       `{"pair":"XBTZAR","ask":"1","bid":"1","last_trade_price":"1","rolling_24_hour_volume":"2","status":"ACTIVE","timestamp":1}`,
+			unexpected: []string{"last_trade_price"},
+			missing:    []string{"last_trade"},
+		},
+		{
+			name:       "nested array elements",
+			response:   reflect.TypeFor[luno.GetBalancesResponse](),
+			body:       `{"balance":[{"account_id":"1","asset":"XBT","balance":"1","name":"","reserved":"0","unconfirmed":"0","staked":"0"}]}`,
+			unexpected: []string{"balance[].staked"},
+		},
+		{
+			name:     "empty array",
+			response: reflect.TypeFor[luno.GetBalancesResponse](),
+			body:     `{"balance":[]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v any
+			require.NoError(t, json.Unmarshal([]byte(tt.body), &v))
+			var d schemaDiff
+			schemaFor(tt.response).compare(v, "", &d)
+			assert.Equal(t, tt.unexpected, d.unexpected)
+			assert.Equal(t, tt.missing, d.missing)
+		})
+	}
+}
+
+func TestRouteMatch(t *testing.T) {
+	r := newRoute(http.MethodGet, "/api/1/accounts/{id}/transactions", nil)
+	assert.True(t, r.match(http.MethodGet, "/api/1/accounts/123/transactions"))
+	assert.False(t, r.match(http.MethodPost, "/api/1/accounts/123/transactions"))
+	assert.False(t, r.match(http.MethodGet, "/api/1/accounts/123/pending"))
+	assert.False(t, r.match(http.MethodGet, "/api/1/accounts/123"))
+}
+
+func TestMCPRoundTripperSchemaCheck(t *testing.T) {
+	const body = `{"pair":"XBTZAR","last_trade_price":"1000000.00"}`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, body)
+	}))
+	defer ts.Close()
+
+	for _, level := range []slog.Level{slog.LevelDebug, slog.LevelInfo} {
+		t.Run(level.String(), func(t *testing.T) {
+			var buf bytes.Buffer
+			debug := NewHTTPDebug(false)
+			debug.Logger = slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: level}))
+
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, ts.URL+"/api/1/ticker?pair=XBTZAR", nil)
+			require.NoError(t, err)
+			res, err := (&MCPRoundTripper{Debug: debug}).RoundTrip(req)
+			require.NoError(t, err)
+			got, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			_ = res.Body.Close()
+			assert.Equal(t, body, string(got), "the body is still readable")
+
+			if level == slog.LevelInfo {
+				assert.Empty(t, buf.String())
+				return
+			}
+			assert.Contains(t, buf.String(), `msg="Luno API response does not match the expected schema"`)
+			assert.Contains(t, buf.String(), "unexpected_fields=[last_trade_price]")
+			assert.Contains(t, buf.String(), "missing_fields=\"[ask bid last_trade rolling_24_hour_volume status timestamp]\"")
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package sdk
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// MCPRoundTripper is an http.RoundTripper used by the Luno client. It identifies
+// requests made through the MCP server by prefixing the User-Agent header with
+// the application name and version, and sends the MCP request ID from the
+// request context in the X-Request-ID header. When Debug is enabled, requests
+// and responses are logged. Responses marking an endpoint as deprecated are
+// recorded in Compat, and with debug logging on, responses that do not match
+// the fields luno-go expects are logged. Calls are counted in the stats of the
+// MCP session in the request context.
+type MCPRoundTripper struct {
+	// Next is the underlying transport, http.DefaultTransport if nil
+	Next http.RoundTripper
+	// AppName and AppVersion are added to the User-Agent header when set
+	AppName    string
+	AppVersion string
+	// Debug logs requests and responses while enabled
+	Debug *HTTPDebug
+	// Compat records deprecation notices in responses
+	Compat *APICompat
+	// Clock measures clock skew from the Date header of responses
+	Clock *ClockSkew
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *MCPRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := t.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	md, hasMetadata := RequestMetadataFromContext(req.Context())
+	hasMetadata = hasMetadata && md.RequestID != ""
+	if t.AppName == "" && !hasMetadata {
+		return t.send(next, req)
+	}
+
+	// RoundTrippers must not modify the caller's request
+	req = req.Clone(req.Context())
+
+	if hasMetadata {
+		req.Header.Set(RequestIDHeader, md.RequestID)
+	}
+
+	if t.AppName != "" {
+		req.Header.Set("User-Agent", t.userAgent(req.Header.Get("User-Agent")))
+	}
+
+	return t.send(next, req)
+}
+
+// userAgent returns the User-Agent header with the application name and
+// version in front of ua, built with a single allocation
+func (t *MCPRoundTripper) userAgent(ua string) string {
+	var b strings.Builder
+	b.Grow(len(t.AppName) + len(t.AppVersion) + len(ua) + 2)
+	b.WriteString(t.AppName)
+	if t.AppVersion != "" {
+		b.WriteByte('/')
+		b.WriteString(t.AppVersion)
+	}
+	if ua != "" {
+		b.WriteByte(' ')
+		b.WriteString(ua)
+	}
+	return b.String()
+}
+
+// send passes req to next, logging it if debugging is enabled
+func (t *MCPRoundTripper) send(next http.RoundTripper, req *http.Request) (*http.Response, error) {
+	var res *http.Response
+	var err error
+	if t.Debug.Enabled() {
+		res, err = t.Debug.roundTrip(next, req)
+	} else {
+		res, err = next.RoundTrip(req)
+	}
+	if err == nil {
+		t.Compat.observe(req, res)
+		t.Clock.observe(req.Context(), res)
+		checkSchema(t.logger(), req, res)
+	}
+	recordUsage(req, res)
+	return res, err
+}
+
+func (t *MCPRoundTripper) logger() *slog.Logger {
+	if t.Debug != nil {
+		return t.Debug.logger()
+	}
+	return slog.Default()
+}
@@ -0,0 +1,165 @@
+package sdk
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luno/luno-mcp/internal/usage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMCPRoundTripper(t *testing.T) {
+	tests := []struct {
+		name       string
+		appName    string
+		appVersion string
+		userAgent  string
+		expected   string
+	}{
+		{
+			name:      "no app name leaves user agent unchanged",
+			userAgent: "LunoGoSDK/0.1.0",
+			expected:  "LunoGoSDK/0.1.0",
+		},
+		{
+			name:       "prefixes app name and version",
+			appName:    "luno-mcp",
+			appVersion: "0.1.0",
+			userAgent:  "LunoGoSDK/0.1.0",
+			expected:   "luno-mcp/0.1.0 LunoGoSDK/0.1.0",
+		},
+		{
+			name:     "app name without version or existing user agent",
+			appName:  "luno-mcp",
+			expected: "luno-mcp",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header.Get("User-Agent")
+			}))
+			defer ts.Close()
+
+			rt := &MCPRoundTripper{AppName: tt.appName, AppVersion: tt.appVersion}
+			req, err := http.NewRequest(http.MethodGet, ts.URL, nil)
+			require.NoError(t, err)
+			req.Header.Set("User-Agent", tt.userAgent)
+
+			res, err := rt.RoundTrip(req)
+			require.NoError(t, err)
+			_ = res.Body.Close()
+
+			if tt.expected != "" {
+				assert.Equal(t, tt.expected, got)
+			}
+			assert.Equal(t, tt.userAgent, req.Header.Get("User-Agent"), "original request should not be modified")
+		})
+	}
+}
+
+func TestMCPRoundTripperRequestID(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata *RequestMetadata
+		expected string
+	}{
+		{
+			name:     "no metadata sends no request ID",
+			expected: "",
+		},
+		{
+			name:     "metadata without request ID",
+			metadata: &RequestMetadata{SessionID: "session-1"},
+			expected: "",
+		},
+		{
+			name:     "request ID from context",
+			metadata: &RequestMetadata{RequestID: "req-123", SessionID: "session-1"},
+			expected: "req-123",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header.Get(RequestIDHeader)
+			}))
+			defer ts.Close()
+
+			ctx := context.Background()
+			if tt.metadata != nil {
+				ctx = ContextWithRequestMetadata(ctx, *tt.metadata)
+			}
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL, nil)
+			require.NoError(t, err)
+
+			res, err := (&MCPRoundTripper{}).RoundTrip(req)
+			require.NoError(t, err)
+			_ = res.Body.Close()
+
+			assert.Equal(t, tt.expected, got)
+			assert.Empty(t, req.Header.Get(RequestIDHeader), "original request should not be modified")
+		})
+	}
+}
+
+// staticTransport returns the same response to every request without using
+// the network
+type staticTransport struct {
+	header http.Header
+	body   string
+}
+
+func (t staticTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     t.header,
+		Body:       io.NopCloser(strings.NewReader(t.body)),
+		Request:    req,
+	}, nil
+}
+
+func BenchmarkMCPRoundTripper(b *testing.B) {
+	next := staticTransport{
+		header: http.Header{"Date": {time.Now().UTC().Format(http.TimeFormat)}, "Content-Type": {"application/json"}},
+		body:   `{"pair":"XBTZAR","last_trade":"800000"}`,
+	}
+	rt := &MCPRoundTripper{
+		Next:       next,
+		AppName:    "luno-mcp",
+		AppVersion: "1.0.0",
+		Debug:      NewHTTPDebug(false),
+		Compat:     NewAPICompat(),
+		Clock:      NewClockSkew(DefaultMaxClockSkew),
+	}
+	ctx := ContextWithRequestMetadata(context.Background(), RequestMetadata{RequestID: "42", SessionID: "s1"})
+	ctx = usage.ContextWithSession(ctx, usage.NewTracker().Session("s1"))
+
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		b.Run(method, func(b *testing.B) {
+			req, err := http.NewRequestWithContext(ctx, method, "https://api.luno.com/api/1/ticker?pair=XBTZAR", nil)
+			require.NoError(b, err)
+			req.Header.Set("User-Agent", "LunoGoSDK/0.1.0")
+
+			b.ReportAllocs()
+			for b.Loop() {
+				res, err := rt.RoundTrip(req)
+				if err != nil {
+					b.Fatal(err)
+				}
+				_, _ = io.Copy(io.Discard, res.Body)
+				_ = res.Body.Close()
+			}
+		})
+	}
+}
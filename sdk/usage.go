@@ -0,0 +1,54 @@
+package sdk
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/luno/luno-mcp/internal/telemetry"
+	"github.com/luno/luno-mcp/internal/usage"
+)
+
+// tradeEndpoints are the Luno API calls counted as trading activity when they succeed
+var tradeEndpoints = map[string]usage.Trade{
+	"POST /api/1/postorder":   usage.OrderPlaced,
+	"POST /api/1/marketorder": usage.OrderPlaced,
+	"POST /api/1/stoporder":   usage.OrderCancelled,
+	"POST /api/1/withdrawals": usage.WithdrawalCreated,
+}
+
+// recordUsage adds a Luno API call to the stats of the MCP session in the
+// request context, if any, and to the tool call's telemetry. The response body
+// is wrapped to count the bytes read from it.
+func recordUsage(req *http.Request, res *http.Response) {
+	var status int
+	if res != nil {
+		status = res.StatusCode
+	}
+	telemetry.CallFromContext(req.Context()).LunoResponse(status)
+	session := usage.SessionFromContext(req.Context())
+	if session == nil {
+		return
+	}
+	session.APICall(max(req.ContentLength, 0), status)
+	if res == nil {
+		return
+	}
+	if trade, ok := tradeEndpoints[req.Method+" "+req.URL.Path]; ok && status >= 200 && status < 300 {
+		session.Traded(trade)
+	}
+	if res.Body != nil {
+		res.Body = &countingBody{ReadCloser: res.Body, session: session}
+	}
+}
+
+// countingBody records the bytes read from a response body in a session
+type countingBody struct {
+	io.ReadCloser
+	session *usage.Session
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.session.APIBytesReceived(int64(n))
+	return n, err
+}
@@ -0,0 +1,59 @@
+package sdk
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/luno/luno-mcp/internal/telemetry"
+	"github.com/luno/luno-mcp/internal/usage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMCPRoundTripperRecordsUsage(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/1/ticker":
+			_, _ = w.Write([]byte(`{"pair":"XBTZAR"}`))
+		case "/api/1/postorder":
+			_, _ = w.Write([]byte(`{"order_id":"BXMC2CJ7HNB88U4"}`))
+		case "/api/1/stoporder":
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer ts.Close()
+
+	session := usage.NewTracker().Session("s1")
+	telemetryCall := &telemetry.Call{}
+	ctx := telemetry.ContextWithCall(usage.ContextWithSession(context.Background(), session), telemetryCall)
+	rt := &MCPRoundTripper{}
+	call := func(method, path, body string) {
+		t.Helper()
+		req, err := http.NewRequestWithContext(ctx, method, ts.URL+path, strings.NewReader(body))
+		require.NoError(t, err)
+		res, err := rt.RoundTrip(req)
+		require.NoError(t, err)
+		_, err = io.ReadAll(res.Body)
+		require.NoError(t, err)
+		_ = res.Body.Close()
+	}
+
+	call(http.MethodGet, "/api/1/ticker", "")
+	call(http.MethodPost, "/api/1/postorder", "pair=XBTZAR&type=BID")
+	call(http.MethodPost, "/api/1/stoporder", "order_id=BXMC2CJ7HNB88U4")
+
+	got := session.Stats()
+	assert.Equal(t, usage.APIStats{
+		Calls:         3,
+		Errors:        1,
+		BytesSent:     int64(len("pair=XBTZAR&type=BID") + len("order_id=BXMC2CJ7HNB88U4")),
+		BytesReceived: int64(len(`{"pair":"XBTZAR"}`) + len(`{"order_id":"BXMC2CJ7HNB88U4"}`)),
+	}, got.API)
+	assert.Equal(t, int64(1), got.RateLimits.LunoRejected)
+	assert.Equal(t, usage.TradingStats{OrdersPlaced: 1}, got.Trading, "rejected cancellations are not counted")
+	assert.Equal(t, telemetry.LunoRateLimited, telemetryCall.Categorise(nil, true, false))
+}